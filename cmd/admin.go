@@ -9,12 +9,14 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"text/tabwriter"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/auth/oauth2"
 	"code.gitea.io/gitea/modules/generate"
 	"code.gitea.io/gitea/modules/git"
+	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 
@@ -30,6 +32,7 @@ var (
 			subcmdCreateUser,
 			subcmdChangePassword,
 			subcmdRepoSyncReleases,
+			subcmdReindexIssues,
 			subcmdRegenerate,
 			subcmdAuth,
 		},
@@ -77,6 +80,11 @@ var (
 				Name:  "access-token",
 				Usage: "Generate access token for the user",
 			},
+			cli.StringFlag{
+				Name:  "access-token-scopes",
+				Usage: "Comma separated scopes of the generated access token",
+				Value: "all",
+			},
 		},
 	}
 
@@ -104,6 +112,12 @@ var (
 		Action: runRepoSyncReleases,
 	}
 
+	subcmdReindexIssues = cli.Command{
+		Name:   "reindex-issues",
+		Usage:  "Rebuild the issue indexer from the database, Gitea should be stopped while it runs",
+		Action: runReindexIssues,
+	}
+
 	subcmdRegenerate = cli.Command{
 		Name:  "regenerate",
 		Usage: "Regenerate specific files",
@@ -323,9 +337,14 @@ func runCreateUser(c *cli.Context) error {
 	}
 
 	if c.Bool("access-token") {
+		scope, err := models.NewAccessTokenScope(strings.Split(c.String("access-token-scopes"), ",")...)
+		if err != nil {
+			return err
+		}
 		t := &models.AccessToken{
-			Name: "gitea-admin",
-			UID:  u.ID,
+			Name:  "gitea-admin",
+			UID:   u.ID,
+			Scope: scope,
 		}
 
 		if err := models.NewAccessToken(t); err != nil {
@@ -400,6 +419,16 @@ func getReleaseCount(id int64) (int64, error) {
 	)
 }
 
+func runReindexIssues(c *cli.Context) error {
+	if err := initDB(); err != nil {
+		return err
+	}
+	setting.NewIndexerService()
+
+	log.Trace("Reindexing issues (this may take a while)")
+	return issue_indexer.ReindexIssues()
+}
+
 func runRegenerateHooks(c *cli.Context) error {
 	if err := initDB(); err != nil {
 		return err
@@ -0,0 +1,95 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+
+	"github.com/unknwon/cae/zip"
+	"github.com/urfave/cli"
+)
+
+// CmdDumpRepository represents the available dump-repo sub-command.
+var CmdDumpRepository = cli.Command{
+	Name:  "dump-repo",
+	Usage: "Dump a repository with its wiki, issues, pull requests, labels, milestones and releases",
+	Description: `Dump-repo compresses the git data and the metadata of a repository into a zip file.
+It can be imported on another instance with restore-repo, or by migrating its unpacked directory`,
+	Action: runDumpRepository,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "owner_name",
+			Usage: "Owner of the repository",
+		},
+		cli.StringFlag{
+			Name:  "repo_name",
+			Usage: "Name of the repository",
+		},
+		cli.StringFlag{
+			Name:  "file, f",
+			Value: fmt.Sprintf("gitea-repo-dump-%d.zip", time.Now().Unix()),
+			Usage: "Name of the dump file which will be created.",
+		},
+		cli.StringFlag{
+			Name:  "tempdir, t",
+			Value: os.TempDir(),
+			Usage: "Temporary dir path",
+		},
+	},
+}
+
+func runDumpRepository(ctx *cli.Context) error {
+	if !ctx.IsSet("owner_name") || !ctx.IsSet("repo_name") {
+		return fmt.Errorf("owner_name and repo_name are required")
+	}
+
+	if err := initDB(); err != nil {
+		return err
+	}
+	setting.NewServices()
+	if err := storage.Init(); err != nil {
+		return fmt.Errorf("storage.Init: %v", err)
+	}
+
+	repo, err := models.GetRepositoryByOwnerAndName(ctx.String("owner_name"), ctx.String("repo_name"))
+	if err != nil {
+		return fmt.Errorf("GetRepositoryByOwnerAndName: %v", err)
+	}
+
+	tmpWorkDir, err := ioutil.TempDir(ctx.String("tempdir"), "gitea-repo-dump-")
+	if err != nil {
+		return fmt.Errorf("Failed to create tmp work directory: %v", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpWorkDir); err != nil {
+			log.Error("Failed to remove %s: %v", tmpWorkDir, err)
+		}
+	}()
+
+	fmt.Printf("Dumping repository %s...\n", repo.FullName())
+	if err = migrations.DumpRepository(repo, tmpWorkDir); err != nil {
+		return fmt.Errorf("DumpRepository: %v", err)
+	}
+
+	fileName := ctx.String("file")
+	if err = zip.PackTo(tmpWorkDir, fileName); err != nil {
+		return fmt.Errorf("Failed to create %s: %v", fileName, err)
+	}
+	if err = os.Chmod(fileName, 0600); err != nil {
+		fmt.Printf("Can't change file access permissions mask to 0600: %v\n", err)
+	}
+
+	fmt.Printf("Finish dumping in file %s\n", fileName)
+	return nil
+}
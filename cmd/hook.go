@@ -8,6 +8,7 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"strconv"
@@ -30,6 +31,7 @@ var (
 			subcmdHookPreReceive,
 			subcmdHookUpdate,
 			subcmdHookPostReceive,
+			subcmdHookProcReceive,
 		},
 	}
 
@@ -51,6 +53,12 @@ var (
 		Description: "This command should only be called by Git",
 		Action:      runHookPostReceive,
 	}
+	subcmdHookProcReceive = cli.Command{
+		Name:        "proc-receive",
+		Usage:       "Delegate proc-receive Git hook",
+		Description: "This command should only be called by Git",
+		Action:      runHookProcReceive,
+	}
 )
 
 func runHookPreReceive(c *cli.Context) error {
@@ -66,18 +74,15 @@ func runHookPreReceive(c *cli.Context) error {
 	reponame := os.Getenv(models.EnvRepoName)
 	userID, _ := strconv.ParseInt(os.Getenv(models.EnvPusherID), 10, 64)
 	prID, _ := strconv.ParseInt(os.Getenv(models.ProtectedBranchPRID), 10, 64)
+	isRestrictedPush := os.Getenv(models.EnvIsRestrictedPush) == "true"
 
+	gitQuotaChecked := false
 	buf := bytes.NewBuffer(nil)
 	scanner := bufio.NewScanner(os.Stdin)
 	for scanner.Scan() {
 		buf.Write(scanner.Bytes())
 		buf.WriteByte('\n')
 
-		// TODO: support news feeds for wiki
-		if isWiki {
-			continue
-		}
-
 		fields := bytes.Fields(scanner.Bytes())
 		if len(fields) != 3 {
 			continue
@@ -87,24 +92,33 @@ func runHookPreReceive(c *cli.Context) error {
 		newCommitID := string(fields[1])
 		refFullName := string(fields[2])
 
-		// If the ref is a branch, check if it's protected
-		if strings.HasPrefix(refFullName, git.BranchPrefix) {
-			statusCode, msg := private.HookPreReceive(username, reponame, private.HookOptions{
-				OldCommitID:                     oldCommitID,
-				NewCommitID:                     newCommitID,
-				RefFullName:                     refFullName,
-				UserID:                          userID,
-				GitAlternativeObjectDirectories: os.Getenv(private.GitAlternativeObjectDirectories),
-				GitObjectDirectory:              os.Getenv(private.GitObjectDirectory),
-				GitQuarantinePath:               os.Getenv(private.GitQuarantinePath),
-				ProtectedBranchID:               prID,
-			})
-			switch statusCode {
-			case http.StatusInternalServerError:
-				fail("Internal Server Error", msg)
-			case http.StatusForbidden:
-				fail(msg, "")
-			}
+		// The quarantined objects are those of the whole push, the git quota is
+		// checked once with the first ref which is not deleted
+		checkGitQuota := !gitQuotaChecked && newCommitID != git.EmptySHA
+		if checkGitQuota {
+			gitQuotaChecked = true
+		}
+
+		// Every ref accepted by receive-pack is checked, Gitea knows which of
+		// them are protected and which checks apply to them
+		statusCode, msg := private.HookPreReceive(username, reponame, private.HookOptions{
+			OldCommitID:                     oldCommitID,
+			NewCommitID:                     newCommitID,
+			RefFullName:                     refFullName,
+			UserID:                          userID,
+			GitAlternativeObjectDirectories: os.Getenv(private.GitAlternativeObjectDirectories),
+			GitObjectDirectory:              os.Getenv(private.GitObjectDirectory),
+			GitQuarantinePath:               os.Getenv(private.GitQuarantinePath),
+			ProtectedBranchID:               prID,
+			IsRestrictedPush:                isRestrictedPush,
+			IsWiki:                          isWiki,
+			CheckGitQuota:                   checkGitQuota,
+		})
+		switch statusCode {
+		case http.StatusInternalServerError:
+			fail("Internal Server Error", msg)
+		case http.StatusForbidden:
+			fail(msg, "")
 		}
 	}
 
@@ -182,5 +196,197 @@ func runHookPostReceive(c *cli.Context) error {
 		fmt.Fprintln(os.Stderr, "")
 	}
 
+	if pushOptions := getGitPushOptions(); !isWiki && len(pushOptions) > 0 {
+		if msg := private.HookApplyPushOptions(repoUser, repoName, private.HookPushOptions{
+			UserID:         pusherID,
+			GitPushOptions: pushOptions,
+		}); len(msg) > 0 {
+			fmt.Fprintf(os.Stderr, "\nThe push options have not been applied: %s\n\n", msg)
+		}
+	}
+
+	return nil
+}
+
+// getGitPushOptions returns the options given with git push -o, which git
+// passes to the pre-receive and post-receive hooks
+func getGitPushOptions() map[string]string {
+	count, _ := strconv.Atoi(os.Getenv("GIT_PUSH_OPTION_COUNT"))
+	options := make(map[string]string, count)
+	for i := 0; i < count; i++ {
+		kv := strings.SplitN(os.Getenv(fmt.Sprintf("GIT_PUSH_OPTION_%d", i)), "=", 2)
+		if len(kv) == 2 {
+			options[kv[0]] = kv[1]
+		} else {
+			options[kv[0]] = ""
+		}
+	}
+	return options
+}
+
+// runHookProcReceive speaks the pkt-line protocol of the proc-receive hook
+// which is run for the references pushed to refs/for/
+func runHookProcReceive(c *cli.Context) error {
+	setup("hooks/proc-receive.log")
+
+	reader := bufio.NewReader(os.Stdin)
+
+	// Version negotiation
+	line, err := readPktLine(reader)
+	if err != nil {
+		fail("Internal Server Error", "Unable to read the version: %v", err)
+	}
+	fields := strings.Split(line, "\x00")
+	if fields[0] != "version=1" {
+		fail("Internal Server Error", "Unsupported proc-receive protocol: %s", fields[0])
+	}
+	hasPushOptions := false
+	if len(fields) > 1 {
+		for _, feature := range strings.Fields(fields[1]) {
+			if feature == "push-options" {
+				hasPushOptions = true
+			}
+		}
+	}
+	if _, err = readPktLines(reader); err != nil {
+		fail("Internal Server Error", "Unable to read the version: %v", err)
+	}
+	if hasPushOptions {
+		writePktLine("version=1\x00push-options")
+	} else {
+		writePktLine("version=1")
+	}
+	writeFlushPkt()
+
+	// Commands and push options
+	commands, err := readPktLines(reader)
+	if err != nil {
+		fail("Internal Server Error", "Unable to read the commands: %v", err)
+	}
+	opts := private.HookProcReceiveOptions{
+		OldCommitIDs:   make([]string, 0, len(commands)),
+		NewCommitIDs:   make([]string, 0, len(commands)),
+		RefFullNames:   make([]string, 0, len(commands)),
+		GitPushOptions: make(map[string]string),
+	}
+	for _, command := range commands {
+		fields := strings.Fields(command)
+		if len(fields) != 3 {
+			fail("Internal Server Error", "Invalid command: %s", command)
+		}
+		opts.OldCommitIDs = append(opts.OldCommitIDs, fields[0])
+		opts.NewCommitIDs = append(opts.NewCommitIDs, fields[1])
+		opts.RefFullNames = append(opts.RefFullNames, fields[2])
+	}
+	if hasPushOptions {
+		options, err := readPktLines(reader)
+		if err != nil {
+			fail("Internal Server Error", "Unable to read the push options: %v", err)
+		}
+		for _, option := range options {
+			kv := strings.SplitN(option, "=", 2)
+			if len(kv) == 2 {
+				opts.GitPushOptions[kv[0]] = kv[1]
+			} else {
+				opts.GitPushOptions[kv[0]] = ""
+			}
+		}
+	}
+
+	// Pushes which do not come from Gitea are left to git
+	if len(os.Getenv("SSH_ORIGINAL_COMMAND")) == 0 {
+		for _, refFullName := range opts.RefFullNames {
+			writePktLine("ok " + refFullName)
+			writePktLine("option fall-through")
+		}
+		writeFlushPkt()
+		return nil
+	}
+
+	if os.Getenv(models.EnvRepoIsWiki) == "true" {
+		for _, refFullName := range opts.RefFullNames {
+			writePktLine("ng " + refFullName + " pull requests can not be created for wikis")
+		}
+		writeFlushPkt()
+		return nil
+	}
+
+	opts.UserID, _ = strconv.ParseInt(os.Getenv(models.EnvPusherID), 10, 64)
+	results, msg := private.HookProcReceive(os.Getenv(models.EnvRepoUsername), os.Getenv(models.EnvRepoName), opts)
+	if results == nil {
+		for _, refFullName := range opts.RefFullNames {
+			writePktLine("ng " + refFullName + " " + msg)
+		}
+		writeFlushPkt()
+		return nil
+	}
+
+	for _, result := range results {
+		switch {
+		case len(result.Err) > 0:
+			writePktLine("ng " + result.OriginalRef + " " + result.Err)
+		case result.IsNotMatched:
+			writePktLine("ok " + result.OriginalRef)
+			writePktLine("option fall-through")
+		default:
+			writePktLine("ok " + result.OriginalRef)
+			writePktLine("option refname " + result.Ref)
+			if result.OldOID != git.EmptySHA {
+				writePktLine("option old-oid " + result.OldOID)
+			}
+			writePktLine("option new-oid " + result.NewOID)
+			if result.IsForcePush {
+				writePktLine("option forced-update")
+			}
+		}
+	}
+	writeFlushPkt()
+
 	return nil
 }
+
+// readPktLine reads a pkt-line, an empty string is returned for a flush-pkt
+func readPktLine(r io.Reader) (string, error) {
+	lenBuf := make([]byte, 4)
+	if _, err := io.ReadFull(r, lenBuf); err != nil {
+		return "", err
+	}
+	length, err := strconv.ParseUint(string(lenBuf), 16, 16)
+	if err != nil {
+		return "", fmt.Errorf("invalid pkt-line length %q: %v", lenBuf, err)
+	}
+	if length == 0 {
+		return "", nil
+	}
+	if length < 4 {
+		return "", fmt.Errorf("invalid pkt-line length %d", length)
+	}
+	data := make([]byte, length-4)
+	if _, err = io.ReadFull(r, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// readPktLines reads the pkt-lines up to the next flush-pkt
+func readPktLines(r io.Reader) ([]string, error) {
+	var lines []string
+	for {
+		line, err := readPktLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 {
+			return lines, nil
+		}
+		lines = append(lines, line)
+	}
+}
+
+func writePktLine(line string) {
+	fmt.Fprintf(os.Stdout, "%04x%s\n", len(line)+5, line)
+}
+
+func writeFlushPkt() {
+	fmt.Fprint(os.Stdout, "0000")
+}
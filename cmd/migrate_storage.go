@@ -0,0 +1,97 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+
+	"github.com/urfave/cli"
+)
+
+// CmdMigrateStorage represents the available migrate-storage sub-command.
+var CmdMigrateStorage = cli.Command{
+	Name:        "migrate-storage",
+	Usage:       "Migrate the files from the local disk to the configured storage",
+	Description: "This is a command for copying the LFS objects, attachments or avatars kept on the local disk to the storage configured in the [storage] section, e.g. before switching to an S3 compatible object storage.",
+	Action:      runMigrateStorage,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "type, t",
+			Value: "",
+			Usage: "Kind of files to migrate: lfs, attachments, avatars or repo-avatars",
+		},
+		cli.StringFlag{
+			Name:  "path, p",
+			Value: "",
+			Usage: "Local directory to migrate the files from, defaults to the directory configured for the kind of files",
+		},
+	},
+}
+
+func runMigrateStorage(ctx *cli.Context) error {
+	setting.NewContext()
+	setting.NewStorageService()
+	if setting.Storage.Type == setting.LocalStorageType {
+		fmt.Println("The files are already kept on the local disk, configure another STORAGE_TYPE in the [storage] section first")
+		return nil
+	}
+	if err := storage.Init(); err != nil {
+		return fmt.Errorf("storage.Init: %v", err)
+	}
+
+	var dst storage.ObjectStorage
+	var srcPath string
+	switch ctx.String("type") {
+	case "lfs":
+		dst, srcPath = storage.LFS, setting.LFS.ContentPath
+	case "attachments":
+		dst, srcPath = storage.Attachments, setting.AttachmentPath
+	case "avatars":
+		dst, srcPath = storage.Avatars, setting.AvatarUploadPath
+	case "repo-avatars":
+		dst, srcPath = storage.RepoAvatars, setting.RepositoryAvatarUploadPath
+	default:
+		return fmt.Errorf("unknown type %q, it must be one of lfs, attachments, avatars or repo-avatars", ctx.String("type"))
+	}
+	if ctx.IsSet("path") {
+		srcPath = ctx.String("path")
+	}
+
+	src, err := storage.NewLocalStorage(srcPath)
+	if err != nil {
+		return fmt.Errorf("NewLocalStorage: %v", err)
+	}
+
+	var count int
+	err = filepath.Walk(srcPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		relPath, err := filepath.Rel(srcPath, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if _, err = storage.Copy(dst, relPath, src, relPath); err != nil {
+			return fmt.Errorf("copy %s: %v", relPath, err)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Migrated %d files from %s\n", count, srcPath)
+	return nil
+}
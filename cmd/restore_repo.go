@@ -0,0 +1,129 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+
+	"github.com/unknwon/cae/zip"
+	"github.com/urfave/cli"
+)
+
+// CmdRestoreRepository represents the available restore-repo sub-command.
+var CmdRestoreRepository = cli.Command{
+	Name:        "restore-repo",
+	Usage:       "Restore a repository from a dump created by dump-repo",
+	Description: "Restore-repo creates a new repository with the git data and the metadata of a dump file created by dump-repo",
+	Action:      runRestoreRepository,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "file, f",
+			Usage: "Name of the dump file",
+		},
+		cli.StringFlag{
+			Name:  "owner_name",
+			Usage: "Owner of the new repository",
+		},
+		cli.StringFlag{
+			Name:  "repo_name",
+			Usage: "Name of the new repository, defaults to the name of the dumped repository",
+		},
+		cli.StringFlag{
+			Name:  "doer",
+			Usage: "User restoring the repository, defaults to the owner unless it is an organization",
+		},
+		cli.StringFlag{
+			Name:  "tempdir, t",
+			Value: os.TempDir(),
+			Usage: "Temporary dir path",
+		},
+	},
+}
+
+func runRestoreRepository(ctx *cli.Context) error {
+	if !ctx.IsSet("file") || !ctx.IsSet("owner_name") {
+		return fmt.Errorf("file and owner_name are required")
+	}
+
+	if err := initDB(); err != nil {
+		return err
+	}
+	setting.NewServices()
+	if err := storage.Init(); err != nil {
+		return fmt.Errorf("storage.Init: %v", err)
+	}
+
+	owner, err := models.GetUserByName(ctx.String("owner_name"))
+	if err != nil {
+		return fmt.Errorf("GetUserByName: %v", err)
+	}
+	doer := owner
+	if ctx.IsSet("doer") {
+		doer, err = models.GetUserByName(ctx.String("doer"))
+		if err != nil {
+			return fmt.Errorf("GetUserByName: %v", err)
+		}
+	} else if owner.IsOrganization() {
+		return fmt.Errorf("doer is required when the owner is an organization")
+	}
+
+	tmpWorkDir, err := ioutil.TempDir(ctx.String("tempdir"), "gitea-repo-restore-")
+	if err != nil {
+		return fmt.Errorf("Failed to create tmp work directory: %v", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpWorkDir); err != nil {
+			log.Error("Failed to remove %s: %v", tmpWorkDir, err)
+		}
+	}()
+
+	if err = zip.ExtractTo(ctx.String("file"), tmpWorkDir); err != nil {
+		return fmt.Errorf("Failed to extract %s: %v", ctx.String("file"), err)
+	}
+
+	restorer, err := migrations.NewRepositoryRestorer(tmpWorkDir)
+	if err != nil {
+		return err
+	}
+	info, err := restorer.GetRepoInfo()
+	if err != nil {
+		return fmt.Errorf("GetRepoInfo: %v", err)
+	}
+
+	var opts = migrations.MigrateOptions{
+		RemoteURL:    tmpWorkDir,
+		Name:         info.Name,
+		Description:  info.Description,
+		OriginalURL:  info.OriginalURL,
+		Wiki:         true,
+		Issues:       true,
+		Milestones:   true,
+		Labels:       true,
+		Releases:     true,
+		Comments:     true,
+		PullRequests: true,
+		Private:      info.IsPrivate,
+	}
+	if ctx.IsSet("repo_name") {
+		opts.Name = ctx.String("repo_name")
+	}
+
+	fmt.Printf("Restoring repository %s/%s...\n", owner.Name, opts.Name)
+	repo, err := migrations.MigrateRepository(doer, owner.Name, opts)
+	if err != nil {
+		return fmt.Errorf("MigrateRepository: %v", err)
+	}
+
+	fmt.Printf("Finish restoring repository %s\n", repo.FullName())
+	return nil
+}
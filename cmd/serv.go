@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/pprof"
 	"code.gitea.io/gitea/modules/private"
@@ -190,6 +191,7 @@ func runServ(c *cli.Context) error {
 	os.Setenv(models.EnvPusherName, results.UserName)
 	os.Setenv(models.EnvPusherID, strconv.FormatInt(results.UserID, 10))
 	os.Setenv(models.ProtectedBranchRepoID, strconv.FormatInt(results.RepoID, 10))
+	os.Setenv(models.EnvIsRestrictedPush, strconv.FormatBool(results.IsRestrictedPush))
 	os.Setenv(models.ProtectedBranchPRID, fmt.Sprintf("%d", 0))
 
 	//LFS token authentication
@@ -240,6 +242,7 @@ func runServ(c *cli.Context) error {
 	}
 
 	gitcmd.Dir = setting.RepoRootPath
+	gitcmd.Env = git.ServeOSEnviron()
 	gitcmd.Stdout = os.Stdout
 	gitcmd.Stdin = os.Stdin
 	gitcmd.Stderr = os.Stderr
@@ -43,3 +43,32 @@ func TestAPIGetBranch(t *testing.T) {
 		testAPIGetBranch(t, test.BranchName, test.Exists)
 	}
 }
+
+func TestAPIStaleBranches(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/stale_branches?days=0&token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var branches []*api.StaleBranch
+	DecodeJSON(t, resp, &branches)
+	names := make([]string, 0, len(branches))
+	for _, branch := range branches {
+		assert.True(t, branch.IsMerged)
+		names = append(names, branch.Name)
+	}
+	assert.Contains(t, names, "develop")
+	assert.NotContains(t, names, "master")
+
+	req = NewRequestWithJSON(t, "DELETE", "/api/v1/repos/user2/repo1/stale_branches?token="+token, &api.DeleteStaleBranchesOption{
+		Branches: []string{"develop", "master"},
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var deleted []string
+	DecodeJSON(t, resp, &deleted)
+	assert.Equal(t, []string{"develop"}, deleted)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/branches/develop?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
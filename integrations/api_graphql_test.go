@@ -0,0 +1,116 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type graphQLResult struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func TestAPIGraphQL(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	query := `query($name: String!) { repository(owner: "user2", name: $name) { name private } }`
+	req := NewRequestWithJSON(t, "POST", "/api/graphql?token="+token, map[string]interface{}{
+		"query":     query,
+		"variables": map[string]interface{}{"name": "repo2"},
+	})
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var result graphQLResult
+	DecodeJSON(t, resp, &result)
+	assert.Empty(t, result.Errors)
+	assert.EqualValues(t, map[string]interface{}{"name": "repo2", "private": true}, result.Data["repository"])
+
+	// the private repository is hidden from anonymous users
+	req = NewRequestWithJSON(t, "POST", "/api/graphql", map[string]interface{}{
+		"query":     query,
+		"variables": map[string]interface{}{"name": "repo2"},
+	})
+	resp = MakeRequest(t, req, http.StatusOK)
+	result = graphQLResult{}
+	DecodeJSON(t, resp, &result)
+	assert.Nil(t, result.Data["repository"])
+
+	req = NewRequest(t, "GET", "/api/graphql/schema")
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "type Repository {")
+}
+
+func TestAPIGraphQLLimits(t *testing.T) {
+	prepareTestEnv(t)
+
+	for query, message := range map[string]string{
+		`{ repository(owner: "user2", name: "repo1") { owner { repositories { owner { repositories { owner { repositories { owner { repositories { owner { login } } } } } } } } } } }`: "the query exceeds the maximum depth of 10",
+		`{ searchUsers(query: "user", first: 50) { repositories(first: 50) { issues(first: 50) { title } } } }`:                                                                         "the query exceeds the maximum complexity of 5000",
+	} {
+		req := NewRequestWithJSON(t, "POST", "/api/graphql", map[string]interface{}{"query": query})
+		resp := MakeRequest(t, req, http.StatusOK)
+		var result graphQLResult
+		DecodeJSON(t, resp, &result)
+		assert.Nil(t, result.Data, query)
+		if assert.Len(t, result.Errors, 1, query) {
+			assert.Equal(t, message, result.Errors[0].Message, query)
+		}
+	}
+}
+
+func TestAPIGraphQLPersistedQuery(t *testing.T) {
+	prepareTestEnv(t)
+
+	query := `{ repository(owner: "user2", name: "repo1") { name } }`
+	sum := sha256.Sum256([]byte(query))
+	extensions := map[string]interface{}{
+		"persistedQuery": map[string]interface{}{
+			"version":    1,
+			"sha256Hash": hex.EncodeToString(sum[:]),
+		},
+	}
+
+	req := NewRequestWithJSON(t, "POST", "/api/graphql", map[string]interface{}{"extensions": extensions})
+	resp := MakeRequest(t, req, http.StatusOK)
+	var result graphQLResult
+	DecodeJSON(t, resp, &result)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "PersistedQueryNotFound", result.Errors[0].Message)
+	}
+
+	// the queries of anonymous users are not persisted
+	req = NewRequestWithJSON(t, "POST", "/api/graphql", map[string]interface{}{"query": query, "extensions": extensions})
+	MakeRequest(t, req, http.StatusOK)
+	req = NewRequestWithJSON(t, "POST", "/api/graphql", map[string]interface{}{"extensions": extensions})
+	resp = MakeRequest(t, req, http.StatusOK)
+	result = graphQLResult{}
+	DecodeJSON(t, resp, &result)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "PersistedQueryNotFound", result.Errors[0].Message)
+	}
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	req = NewRequestWithJSON(t, "POST", "/api/graphql?token="+token, map[string]interface{}{"query": query, "extensions": extensions})
+	session.MakeRequest(t, req, http.StatusOK)
+
+	req = NewRequestWithJSON(t, "POST", "/api/graphql", map[string]interface{}{"extensions": extensions})
+	resp = MakeRequest(t, req, http.StatusOK)
+	result = graphQLResult{}
+	DecodeJSON(t, resp, &result)
+	assert.Empty(t, result.Errors)
+	assert.EqualValues(t, map[string]interface{}{"name": "repo1"}, result.Data["repository"])
+}
@@ -43,11 +43,13 @@ func (ctx APITestContext) GitPath() string {
 
 func doAPICreateRepository(ctx APITestContext, empty bool, callback ...func(*testing.T, api.Repository)) func(*testing.T) {
 	return func(t *testing.T) {
+		autoInit := !empty
+		private := true
 		createRepoOption := &api.CreateRepoOption{
-			AutoInit:    !empty,
+			AutoInit:    &autoInit,
 			Description: "Temporary repo",
 			Name:        ctx.Reponame,
-			Private:     true,
+			Private:     &private,
 			Gitignores:  "",
 			License:     "WTFPL",
 			Readme:      "Default",
@@ -137,6 +139,18 @@ func doAPIDeleteRepository(ctx APITestContext) func(*testing.T) {
 	}
 }
 
+func doAPIArchiveRepository(ctx APITestContext, archived bool) func(*testing.T) {
+	return func(t *testing.T) {
+		urlStr := fmt.Sprintf("/api/v1/repos/%s/%s?token=%s", ctx.Username, ctx.Reponame, ctx.Token)
+
+		req := NewRequestWithJSON(t, "PATCH", urlStr, &api.EditRepoOption{Archived: &archived})
+		resp := ctx.Session.MakeRequest(t, req, http.StatusOK)
+		var repo api.Repository
+		DecodeJSON(t, resp, &repo)
+		assert.Equal(t, archived, repo.Archived)
+	}
+}
+
 func doAPICreateUserKey(ctx APITestContext, keyname, keyFile string, callback ...func(*testing.T, api.PublicKey)) func(*testing.T) {
 	return func(t *testing.T) {
 		urlStr := fmt.Sprintf("/api/v1/user/keys?token=%s", ctx.Token)
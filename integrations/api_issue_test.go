@@ -62,3 +62,34 @@ func TestAPICreateIssue(t *testing.T) {
 		Title:      title,
 	})
 }
+
+func TestAPIMoveIssueToUnreadableRepo(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	urlStr := fmt.Sprintf("/api/v1/repos/user2/repo1/issues/1/move?token=%s", token)
+
+	// a private repository the user can not read looks like a missing one
+	req := NewRequestWithJSON(t, "POST", urlStr, &api.MoveIssueOption{Owner: "user10", Repo: "repo6"})
+	session.MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.MoveIssueOption{Owner: "user10", Repo: "missing"})
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	models.AssertExistsAndLoadBean(t, &models.Issue{RepoID: 1, Index: 1})
+}
+
+func TestAPIDeleteIssueInArchivedRepo(t *testing.T) {
+	prepareTestEnv(t)
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	repo.IsArchived = true
+	assert.NoError(t, models.UpdateRepository(repo, false))
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	req := NewRequestf(t, "DELETE", "/api/v1/repos/user2/repo1/issues/1?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	models.AssertExistsAndLoadBean(t, &models.Issue{RepoID: 1, Index: 1})
+}
@@ -7,6 +7,7 @@ package integrations
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 
 	"code.gitea.io/gitea/models"
@@ -118,3 +119,51 @@ func TestAPICreateReleaseToDefaultBranchOnExistingTag(t *testing.T) {
 
 	createNewReleaseUsingAPI(t, session, token, owner, repo, "v0.0.1", "", "v0.0.1", "test")
 }
+
+func TestAPIUploadReleaseAttachmentInChunks(t *testing.T) {
+	prepareTestEnv(t)
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: repo.OwnerID}).(*models.User)
+	session := loginUser(t, owner.LowerName)
+	token := getTokenForLoggedInUser(t, session)
+
+	release := createNewReleaseUsingAPI(t, session, token, owner, repo, "v0.0.1", "", "v0.0.1", "test")
+	content := "PK\x03\x04" + strings.Repeat("release", 100)
+
+	urlStr := fmt.Sprintf("/api/v1/repos/%s/%s/releases/%d/assets/uploads?token=%s", owner.Name, repo.Name, release.ID, token)
+	req := NewRequestWithJSON(t, "POST", urlStr, &api.CreateAttachmentUploadOptions{
+		Name: "release.zip",
+		Size: int64(len(content)),
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var attachUpload api.AttachmentUpload
+	DecodeJSON(t, resp, &attachUpload)
+	assert.EqualValues(t, 0, attachUpload.Offset)
+
+	chunkURL := func(offset int) string {
+		return fmt.Sprintf("/api/v1/repos/%s/%s/releases/%d/assets/uploads/%s?offset=%d&token=%s",
+			owner.Name, repo.Name, release.ID, attachUpload.UUID, offset, token)
+	}
+	req = NewRequestWithBody(t, "PATCH", chunkURL(0), strings.NewReader(content[:300]))
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &attachUpload)
+	assert.EqualValues(t, 300, attachUpload.Offset)
+
+	// the chunk of a wrong offset is rejected, the upload is resumed from its offset
+	req = NewRequestWithBody(t, "PATCH", chunkURL(200), strings.NewReader(content[200:]))
+	session.MakeRequest(t, req, http.StatusConflict)
+	req = NewRequest(t, "GET", chunkURL(0))
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &attachUpload)
+	assert.EqualValues(t, 300, attachUpload.Offset)
+
+	req = NewRequestWithBody(t, "PATCH", chunkURL(300), strings.NewReader(content[300:]))
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var attach api.Attachment
+	DecodeJSON(t, resp, &attach)
+	assert.Equal(t, "release.zip", attach.Name)
+	assert.EqualValues(t, len(content), attach.Size)
+	models.AssertExistsAndLoadBean(t, &models.Attachment{ID: attach.ID, ReleaseID: release.ID})
+	models.AssertNotExistsBean(t, &models.AttachmentUpload{UUID: attachUpload.UUID})
+}
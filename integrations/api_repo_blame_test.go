@@ -0,0 +1,36 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIReposBlame(t *testing.T) {
+	prepareTestEnv(t)
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	session := loginUser(t, user.Name)
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/%s/repo1/blame/README.md?ref=65f1bf27bc3bf70f64657658635e66094edbcb4d&token="+token, user.Name)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var blame api.FileBlame
+	DecodeJSON(t, resp, &blame)
+	assert.Equal(t, "README.md", blame.Path)
+	assert.Equal(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", blame.SHA)
+	if assert.NotEmpty(t, blame.Ranges) {
+		assert.Equal(t, 1, blame.Ranges[0].StartingLine)
+		assert.Equal(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", blame.Ranges[0].Commit.SHA)
+	}
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/%s/repo1/blame/does-not-exist?token="+token, user.Name)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
@@ -0,0 +1,41 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPICompareRefs(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	const commitID = "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+	for _, basehead := range []string{"master...v1.1", "v1.1...65f1bf2", "user2:master..." + commitID} {
+		req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/compare/%s?token=%s", basehead, token)
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		var compare api.Compare
+		DecodeJSON(t, resp, &compare)
+		assert.Equal(t, commitID, compare.BaseCommit, basehead)
+		assert.Equal(t, commitID, compare.HeadCommit, basehead)
+		assert.Equal(t, commitID, compare.MergeBase, basehead)
+		assert.Empty(t, compare.Commits, basehead)
+		assert.Empty(t, compare.Files, basehead)
+	}
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/compare/master...v1.1.diff?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	assert.Empty(t, resp.Body.String())
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/compare/master...missing?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
@@ -148,6 +148,58 @@ func TestAPIRepoEdit(t *testing.T) {
 		req = NewRequestWithJSON(t, "PATCH", url, &origRepoEditOption)
 		_ = session.MakeRequest(t, req, http.StatusOK)
 
+		// Test switching repo1 to an external tracker and an external wiki
+		hasIssues := true
+		hasWiki := true
+		repoEditOption = &api.EditRepoOption{
+			HasIssues: &hasIssues,
+			ExternalTracker: &api.ExternalTracker{
+				ExternalTrackerURL:    "http://www.somewebsite.com",
+				ExternalTrackerFormat: "http://www.somewebsite.com/{user}/{repo}?issue={index}",
+				ExternalTrackerStyle:  "alphanumeric",
+			},
+			HasWiki: &hasWiki,
+			ExternalWiki: &api.ExternalWiki{
+				ExternalWikiURL: "http://www.somewebsite.com",
+			},
+		}
+		url = fmt.Sprintf("/api/v1/repos/%s/%s?token=%s", user2.Name, repo1.Name, token2)
+		req = NewRequestWithJSON(t, "PATCH", url, &repoEditOption)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		repo = api.Repository{}
+		DecodeJSON(t, resp, &repo)
+		assert.True(t, repo.HasIssues)
+		assert.Nil(t, repo.InternalTracker)
+		assert.Equal(t, *repoEditOption.ExternalTracker, *repo.ExternalTracker)
+		assert.True(t, repo.HasWiki)
+		assert.Equal(t, *repoEditOption.ExternalWiki, *repo.ExternalWiki)
+		repo1edited = models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+		assert.Equal(t, "alphanumeric", repo1edited.ComposeMetas()["style"])
+
+		// Test rejecting an invalid external tracker style
+		repoEditOption.ExternalTracker.ExternalTrackerStyle = "roman"
+		req = NewRequestWithJSON(t, "PATCH", url, &repoEditOption)
+		_ = session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+		// Test switching back to the built-in tracker and wiki with new settings
+		repoEditOption = &api.EditRepoOption{
+			HasIssues: &hasIssues,
+			InternalTracker: &api.InternalTracker{
+				EnableTimeTracker:                false,
+				AllowOnlyContributorsToTrackTime: false,
+				EnableIssueDependencies:          true,
+			},
+			HasWiki: &hasWiki,
+		}
+		req = NewRequestWithJSON(t, "PATCH", url, &repoEditOption)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		repo = api.Repository{}
+		DecodeJSON(t, resp, &repo)
+		assert.Nil(t, repo.ExternalTracker)
+		assert.Equal(t, *repoEditOption.InternalTracker, *repo.InternalTracker)
+		assert.Nil(t, repo.ExternalWiki)
+		assert.True(t, repo.HasWiki)
+
 		// Test editing a non-existing repo
 		name := "repodoesnotexist"
 		url = fmt.Sprintf("/api/v1/repos/%s/%s?token=%s", user2.Name, name, token2)
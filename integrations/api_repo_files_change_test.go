@@ -0,0 +1,130 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getChangeFilesOptions() *api.ChangeFilesOptions {
+	newContent := base64.StdEncoding.EncodeToString([]byte("This is new text"))
+	updatedContent := base64.StdEncoding.EncodeToString([]byte("This is updated text"))
+	return &api.ChangeFilesOptions{
+		FileOptions: api.FileOptions{
+			BranchName:    "master",
+			NewBranchName: "master",
+			Message:       "Changing several files",
+			Author: api.Identity{
+				Name:  "John Doe",
+				Email: "johndoe@example.com",
+			},
+			Committer: api.Identity{
+				Name:  "Jane Doe",
+				Email: "janedoe@example.com",
+			},
+		},
+		Files: []*api.ChangeFileOperation{
+			{
+				Operation: "create",
+				Path:      "new/file1.txt",
+				Content:   newContent,
+			},
+			{
+				Operation: "create",
+				Path:      "new/dir/file2.txt",
+				Content:   newContent,
+			},
+			{
+				Operation: "update",
+				Path:      "README.md",
+				Content:   updatedContent,
+				SHA:       "4b4851ad51df6a7d9f25c979345979eaeb5b349f",
+			},
+		},
+	}
+}
+
+func TestAPIChangeFiles(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		user2 := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)             // owner of the repo1
+		user4 := models.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User)             // owner of neither repos
+		repo1 := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository) // public repo
+
+		session := loginUser(t, user2.Name)
+		token2 := getTokenForLoggedInUser(t, session)
+		session = loginUser(t, user4.Name)
+		token4 := getTokenForLoggedInUser(t, session)
+		session = emptyTestSession(t)
+
+		// Test changing files in repo1 which user4 does not own
+		url := fmt.Sprintf("/api/v1/repos/%s/%s/contents?token=%s", user2.Name, repo1.Name, token4)
+		req := NewRequestWithJSON(t, "POST", url, getChangeFilesOptions())
+		session.MakeRequest(t, req, http.StatusForbidden)
+
+		// Test changing files in a new branch of repo1, all in a single commit
+		changeFilesOptions := getChangeFilesOptions()
+		changeFilesOptions.NewBranchName = "new_branch"
+		url = fmt.Sprintf("/api/v1/repos/%s/%s/contents?token=%s", user2.Name, repo1.Name, token2)
+		req = NewRequestWithJSON(t, "POST", url, changeFilesOptions)
+		resp := session.MakeRequest(t, req, http.StatusCreated)
+		var filesResponse api.FilesResponse
+		DecodeJSON(t, resp, &filesResponse)
+		gitRepo, _ := git.OpenRepository(repo1.RepoPath())
+		commitID, _ := gitRepo.GetBranchCommitID("new_branch")
+		assert.EqualValues(t, commitID, filesResponse.Commit.SHA)
+		if assert.Len(t, filesResponse.Files, 3) {
+			assert.EqualValues(t, "new/file1.txt", filesResponse.Files[0].Path)
+			assert.EqualValues(t, "new/dir/file2.txt", filesResponse.Files[1].Path)
+			assert.EqualValues(t, "README.md", filesResponse.Files[2].Path)
+		}
+		commit, _ := gitRepo.GetBranchCommit("new_branch")
+		parent, _ := commit.Parent(0)
+		masterCommitID, _ := gitRepo.GetBranchCommitID("master")
+		assert.EqualValues(t, masterCommitID, parent.ID.String())
+
+		// Test deleting a file and creating another one in master
+		changeFilesOptions = getChangeFilesOptions()
+		changeFilesOptions.Files = []*api.ChangeFileOperation{
+			{
+				Operation: "delete",
+				Path:      "README.md",
+				SHA:       "4b4851ad51df6a7d9f25c979345979eaeb5b349f",
+			},
+			{
+				Operation: "create",
+				Path:      "README.txt",
+				Content:   base64.StdEncoding.EncodeToString([]byte("Moved")),
+			},
+		}
+		req = NewRequestWithJSON(t, "POST", url, changeFilesOptions)
+		resp = session.MakeRequest(t, req, http.StatusCreated)
+		filesResponse = api.FilesResponse{}
+		DecodeJSON(t, resp, &filesResponse)
+		if assert.Len(t, filesResponse.Files, 2) {
+			assert.Nil(t, filesResponse.Files[0])
+			assert.EqualValues(t, "README.txt", filesResponse.Files[1].Path)
+		}
+
+		// Test a wrong SHA, none of the files are changed
+		changeFilesOptions = getChangeFilesOptions()
+		changeFilesOptions.Files[2].Path = "README.txt"
+		changeFilesOptions.Files[2].SHA = "badsha"
+		req = NewRequestWithJSON(t, "POST", url, changeFilesOptions)
+		session.MakeRequest(t, req, http.StatusInternalServerError)
+		commit, _ = gitRepo.GetBranchCommit("master")
+		_, err := commit.GetTreeEntryByPath("new/file1.txt")
+		assert.True(t, git.IsErrNotExist(err))
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"testing"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 
 	"github.com/stretchr/testify/assert"
@@ -390,3 +391,33 @@ func testAPIRepoCreateConflict(t *testing.T, u *url.URL) {
 		assert.Equal(t, respJSON["message"], "The repository with the same name already exists.")
 	})
 }
+
+func TestAPIRepoCreateDefaultBranch(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session)
+
+		autoInit := true
+		req := NewRequestWithJSON(t, "POST", "/api/v1/user/repos?token="+token, &api.CreateRepoOption{
+			Name:          "repo-default-branch",
+			AutoInit:      &autoInit,
+			DefaultBranch: "main",
+		})
+		resp := session.MakeRequest(t, req, http.StatusCreated)
+		var repo api.Repository
+		DecodeJSON(t, resp, &repo)
+		assert.Equal(t, "main", repo.DefaultBranch)
+
+		req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo-default-branch/branches/main?token=%s", token)
+		session.MakeRequest(t, req, http.StatusOK)
+
+		// without a default branch, the one of the instance is used
+		req = NewRequestWithJSON(t, "POST", "/api/v1/user/repos?token="+token, &api.CreateRepoOption{
+			Name:     "repo-instance-default-branch",
+			AutoInit: &autoInit,
+		})
+		resp = session.MakeRequest(t, req, http.StatusCreated)
+		DecodeJSON(t, resp, &repo)
+		assert.Equal(t, setting.Repository.DefaultBranch, repo.DefaultBranch)
+	})
+}
@@ -10,6 +10,8 @@ import (
 
 	"code.gitea.io/gitea/models"
 	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
 )
 
 // TestAPICreateAndDeleteToken tests that token that was just created can be deleted
@@ -17,19 +19,22 @@ func TestAPICreateAndDeleteToken(t *testing.T) {
 	prepareTestEnv(t)
 	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 1}).(*models.User)
 
-	req := NewRequestWithJSON(t, "POST", "/api/v1/users/user1/tokens", map[string]string{
-		"name": "test-key-1",
+	req := NewRequestWithJSON(t, "POST", "/api/v1/users/user1/tokens", map[string]interface{}{
+		"name":   "test-key-1",
+		"scopes": []string{"write:repo", "read:repo"},
 	})
 	req = AddBasicAuthHeader(req, user.Name)
 	resp := MakeRequest(t, req, http.StatusCreated)
 
 	var newAccessToken api.AccessToken
 	DecodeJSON(t, resp, &newAccessToken)
+	assert.Equal(t, []string{"write:repo"}, newAccessToken.Scopes)
 	models.AssertExistsAndLoadBean(t, &models.AccessToken{
 		ID:    newAccessToken.ID,
 		Name:  newAccessToken.Name,
 		Token: newAccessToken.Token,
 		UID:   user.ID,
+		Scope: models.AccessTokenScopeWriteRepo,
 	})
 
 	req = NewRequestf(t, "DELETE", "/api/v1/users/user1/tokens/%d", newAccessToken.ID)
@@ -48,3 +53,75 @@ func TestAPIDeleteMissingToken(t *testing.T) {
 	req = AddBasicAuthHeader(req, user.Name)
 	MakeRequest(t, req, http.StatusNotFound)
 }
+
+// TestAPICreateTokenWithoutScope ensures that a token needs at least one scope
+func TestAPICreateTokenWithoutScope(t *testing.T) {
+	prepareTestEnv(t)
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 1}).(*models.User)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/users/user1/tokens", map[string]string{
+		"name": "test-key-2",
+	})
+	req = AddBasicAuthHeader(req, user.Name)
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/users/user1/tokens", map[string]interface{}{
+		"name":   "test-key-2",
+		"scopes": []string{"write:everything"},
+	})
+	req = AddBasicAuthHeader(req, user.Name)
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+}
+
+// TestAPITokenScopes tests that the routes require the scopes of their group
+func TestAPITokenScopes(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session, models.AccessTokenScopeReadRepo, models.AccessTokenScopeReadIssue)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1?token=%s", token)
+	MakeRequest(t, req, http.StatusOK)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/issues?token=%s", token)
+	MakeRequest(t, req, http.StatusOK)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/issues?token="+token, &api.CreateIssueOption{
+		Title: "scoped issue",
+	})
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1?token="+token, &api.EditRepoOption{})
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequestf(t, "GET", "/api/v1/user/emails?token=%s", token)
+	MakeRequest(t, req, http.StatusForbidden)
+
+	token = getTokenForLoggedInUser(t, session, models.AccessTokenScopeWriteIssue)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/issues?token="+token, &api.CreateIssueOption{
+		Title: "scoped issue",
+	})
+	MakeRequest(t, req, http.StatusCreated)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1?token=%s", token)
+	MakeRequest(t, req, http.StatusForbidden)
+}
+
+// TestAPICreateTokenWithToken ensures that a scoped token sent as the password
+// can not create a token with more scopes
+func TestAPICreateTokenWithToken(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session, models.AccessTokenScopeWriteUser)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/users/user2/tokens", map[string]interface{}{
+		"name":   "escalated",
+		"scopes": []string{"all"},
+	})
+	req.SetBasicAuth("user2", token)
+	MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/users/user2/tokens", map[string]interface{}{
+		"name":   "escalated",
+		"scopes": []string{"write:user"},
+	})
+	req.SetBasicAuth(token, "x-oauth-basic")
+	MakeRequest(t, req, http.StatusForbidden)
+
+	models.AssertNotExistsBean(t, &models.AccessToken{UID: 2, Name: "escalated"})
+}
@@ -163,3 +163,37 @@ func TestEditFileToNewBranch(t *testing.T) {
 		testEditFileToNewBranch(t, session, "user2", "repo1", "master", "feature/test", "README.md", "Hello, World (Edited)\n")
 	})
 }
+
+func TestEditFileByFork(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user4")
+
+		req := NewRequest(t, "GET", "/user2/repo1/_edit/master/README.md")
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		lastCommit := htmlDoc.GetInputValueByName("last_commit")
+		assert.NotEmpty(t, lastCommit)
+		assert.Contains(t, htmlDoc.doc.Find(".ui.info.message").Text(), "user4/repo1")
+
+		// The edit is committed to a new branch of a fork of the repository
+		req = NewRequestWithValues(t, "POST", "/user2/repo1/_edit/master/README.md", map[string]string{
+			"_csrf":           htmlDoc.GetCSRF(),
+			"last_commit":     lastCommit,
+			"tree_path":       "README.md",
+			"content":         "Hello, World (Forked)\n",
+			"commit_choice":   "direct",
+			"new_branch_name": "user4-patch-1",
+		})
+		resp = session.MakeRequest(t, req, http.StatusFound)
+		assert.EqualValues(t, "/user2/repo1/compare/master...user4:user4-patch-1", resp.Header().Get("Location"))
+
+		req = NewRequest(t, "GET", "/user4/repo1/raw/branch/user4-patch-1/README.md")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		assert.EqualValues(t, "Hello, World (Forked)\n", resp.Body.String())
+
+		// The repository itself is left unchanged
+		req = NewRequest(t, "GET", "/user2/repo1/raw/branch/master/README.md")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		assert.NotEqual(t, "Hello, World (Forked)\n", resp.Body.String())
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -20,6 +21,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/ssh"
 
+	"github.com/mcuadros/go-version"
 	"github.com/stretchr/testify/assert"
 	"github.com/unknwon/com"
 )
@@ -84,6 +86,24 @@ func doGitClone(dstLocalPath string, u *url.URL) func(*testing.T) {
 	}
 }
 
+func doGitPartialClone(dstLocalPath string, u *url.URL) func(*testing.T) {
+	return func(t *testing.T) {
+		gitVersion, err := git.BinVersion()
+		assert.NoError(t, err)
+		if version.Compare(gitVersion, "2.22", "<") {
+			t.Skip("Partial clones are not supported by git " + gitVersion)
+		}
+		assert.NoError(t, git.Clone(u.String(), dstLocalPath, git.CloneRepoOptions{
+			Filter: "blob:none",
+		}))
+		// The blobs of the checked out files are fetched by the checkout
+		assert.True(t, com.IsExist(filepath.Join(dstLocalPath, "README.md")))
+		promisor, err := git.NewCommand("config", "remote.origin.promisor").RunInDir(dstLocalPath)
+		assert.NoError(t, err)
+		assert.EqualValues(t, "true", strings.TrimSpace(promisor))
+	}
+}
+
 func doGitCloneFail(dstLocalPath string, u *url.URL) func(*testing.T) {
 	return func(t *testing.T) {
 		assert.Error(t, git.Clone(u.String(), dstLocalPath, git.CloneRepoOptions{}))
@@ -138,6 +158,13 @@ func doGitCreateBranch(dstPath, branch string) func(*testing.T) {
 	}
 }
 
+func doGitCreateTag(dstPath, tag string) func(*testing.T) {
+	return func(t *testing.T) {
+		_, err := git.NewCommand("tag", tag).RunInDir(dstPath)
+		assert.NoError(t, err)
+	}
+}
+
 func doGitCheckoutBranch(dstPath string, args ...string) func(*testing.T) {
 	return func(t *testing.T) {
 		_, err := git.NewCommand(append([]string{"checkout"}, args...)...).RunInDir(dstPath)
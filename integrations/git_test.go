@@ -19,6 +19,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 
 	"github.com/stretchr/testify/assert"
@@ -61,6 +62,8 @@ func testGit(t *testing.T, u *url.URL) {
 		u.User = url.UserPassword(username, userPassword)
 
 		t.Run("Clone", doGitClone(dstPath, u))
+		t.Run("PartialClone", doGitPartialClone(dstPath+"-partial", u))
+		defer os.RemoveAll(dstPath + "-partial")
 
 		little, big := standardCommitAndPushTest(t, dstPath)
 		littleLFS, bigLFS := lfsCommitAndPushTest(t, dstPath)
@@ -68,6 +71,13 @@ func testGit(t *testing.T, u *url.URL) {
 		mediaTest(t, &httpContext, little, big, littleLFS, bigLFS)
 
 		t.Run("BranchProtectMerge", doBranchProtectPRMerge(&httpContext, dstPath))
+		t.Run("ProtectedTagPush", doProtectedTagPush(&httpContext, dstPath))
+		t.Run("ArchivedRepoPush", doArchivedRepoPush(httpContext, dstPath))
+		t.Run("GitQuotaPush", doGitQuotaPush(httpContext, dstPath))
+		t.Run("GitQuotaMultipleRefsPush", doGitQuotaMultipleRefsPush(httpContext, dstPath))
+		t.Run("PushPolicyPush", doPushPolicyPush(dstPath))
+		t.Run("PushPolicyWikiPush", doPushPolicyWikiPush(httpContext, dstPath, u))
+		t.Run("GitQuotaWikiPush", doGitQuotaWikiPush(httpContext, dstPath, u))
 		t.Run("MergeFork", func(t *testing.T) {
 			t.Run("CreatePRAndMerge", doMergeFork(httpContext, forkedUserCtx, "master", httpContext.Username+":master"))
 			t.Run("DeleteRepository", doAPIDeleteRepository(httpContext))
@@ -362,6 +372,195 @@ func doProtectBranch(ctx APITestContext, branch string, userToWhitelist string)
 	}
 }
 
+func doProtectedTagPush(baseCtx *APITestContext, dstPath string) func(t *testing.T) {
+	return func(t *testing.T) {
+		PrintCurrentTest(t)
+		ctx := NewAPITestContext(t, baseCtx.Username, baseCtx.Reponame)
+		t.Run("PushTag", func(t *testing.T) {
+			doGitCreateTag(dstPath, "v1-protected")(t)
+			doGitPushTestRepository(dstPath, "origin", "v1-protected")(t)
+		})
+		t.Run("ProtectTagNoWhitelist", doProtectTag(ctx, "v*"))
+		t.Run("FailToCreateProtectedTag", func(t *testing.T) {
+			doGitCreateTag(dstPath, "v2-protected")(t)
+			doGitPushTestRepositoryFail(dstPath, "origin", "v2-protected")(t)
+		})
+		t.Run("FailToMoveProtectedTag", doGitPushTestRepositoryFail(dstPath, "-f", "origin", "v1-protected~1:refs/tags/v1-protected"))
+		t.Run("FailToDeleteProtectedTag", doGitPushTestRepositoryFail(dstPath, "origin", ":refs/tags/v1-protected"))
+		t.Run("PushUnprotectedTag", doGitPushTestRepository(dstPath, "origin", "v2-protected:refs/tags/unprotected"))
+	}
+}
+
+func doArchivedRepoPush(ctx APITestContext, dstPath string) func(t *testing.T) {
+	return func(t *testing.T) {
+		PrintCurrentTest(t)
+		t.Run("Archive", doAPIArchiveRepository(ctx, true))
+		t.Run("FailToPushBranch", doGitPushTestRepositoryFail(dstPath, "origin", "master:archived"))
+		t.Run("FailToPushTag", func(t *testing.T) {
+			doGitCreateTag(dstPath, "archived")(t)
+			doGitPushTestRepositoryFail(dstPath, "origin", "archived")(t)
+		})
+		t.Run("Unarchive", doAPIArchiveRepository(ctx, false))
+		t.Run("PushTag", doGitPushTestRepository(dstPath, "origin", "archived"))
+	}
+}
+
+func doGitQuotaPush(ctx APITestContext, dstPath string) func(t *testing.T) {
+	return func(t *testing.T) {
+		PrintCurrentTest(t)
+		owner, err := models.GetUserByName(ctx.Username)
+		assert.NoError(t, err)
+		owner.MaxGitSize = 1
+		assert.NoError(t, models.UpdateUserCols(owner, "max_git_size"))
+		defer func() {
+			owner.MaxGitSize = -1
+			assert.NoError(t, models.UpdateUserCols(owner, "max_git_size"))
+		}()
+
+		t.Run("GenerateCommit", func(t *testing.T) {
+			_, err := generateCommitWithNewData(littleSize, dstPath, "user2@example.com", "User Two", "quota-data-file-")
+			assert.NoError(t, err)
+		})
+		t.Run("FailToPushBranch", doGitPushTestRepositoryFail(dstPath, "origin", "HEAD:quota"))
+		t.Run("FailToPushPullRequestRef", doGitPushTestRepositoryFail(dstPath, "origin", "HEAD:refs/for/master"))
+		t.Run("FailToPushOtherRef", doGitPushTestRepositoryFail(dstPath, "origin", "HEAD:refs/quota/test"))
+		t.Run("ResetCommit", func(t *testing.T) {
+			_, err := git.NewCommand("reset", "--hard", "HEAD~1").RunInDir(dstPath)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func doGitQuotaMultipleRefsPush(ctx APITestContext, dstPath string) func(t *testing.T) {
+	return func(t *testing.T) {
+		PrintCurrentTest(t)
+		const dataSize = 64 * 1024
+
+		t.Run("GenerateCommit", func(t *testing.T) {
+			_, err := generateCommitWithNewData(dataSize, dstPath, "user2@example.com", "User Two", "quota-data-file-")
+			assert.NoError(t, err)
+		})
+
+		// The quota leaves room for the objects of the push once, but not twice
+		owner, err := models.GetUserByName(ctx.Username)
+		assert.NoError(t, err)
+		usage, err := owner.GetQuotaUsage()
+		assert.NoError(t, err)
+		owner.MaxGitSize = usage.GitSize + dataSize*3/2
+		assert.NoError(t, models.UpdateUserCols(owner, "max_git_size"))
+		defer func() {
+			owner.MaxGitSize = -1
+			assert.NoError(t, models.UpdateUserCols(owner, "max_git_size"))
+		}()
+
+		t.Run("PushBranches", doGitPushTestRepository(dstPath, "origin", "HEAD:quota-1", "HEAD:quota-2", "HEAD:quota-3"))
+		t.Run("ResetCommit", func(t *testing.T) {
+			_, err := git.NewCommand("reset", "--hard", "HEAD~1").RunInDir(dstPath)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func doGitQuotaWikiPush(ctx APITestContext, dstPath string, u *url.URL) func(t *testing.T) {
+	return func(t *testing.T) {
+		PrintCurrentTest(t)
+		owner, err := models.GetUserByName(ctx.Username)
+		assert.NoError(t, err)
+		owner.MaxGitSize = 1
+		assert.NoError(t, models.UpdateUserCols(owner, "max_git_size"))
+		defer func() {
+			owner.MaxGitSize = -1
+			assert.NoError(t, models.UpdateUserCols(owner, "max_git_size"))
+		}()
+
+		t.Run("GenerateCommit", func(t *testing.T) {
+			_, err := generateCommitWithNewData(littleSize, dstPath, "user2@example.com", "User Two", "quota-data-file-")
+			assert.NoError(t, err)
+		})
+		wikiURL := *u
+		wikiURL.Path = fmt.Sprintf("%s/%s.wiki.git", ctx.Username, ctx.Reponame)
+		t.Run("FailToPushWikiBranch", doGitPushTestRepositoryFail(dstPath, wikiURL.String(), "HEAD:refs/heads/quota"))
+		t.Run("ResetCommit", func(t *testing.T) {
+			_, err := git.NewCommand("reset", "--hard", "HEAD~1").RunInDir(dstPath)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func doPushPolicyPush(dstPath string) func(t *testing.T) {
+	return func(t *testing.T) {
+		PrintCurrentTest(t)
+		defer func(patterns []string) {
+			setting.Repository.PushPolicy.ForbiddenFilePatterns = patterns
+		}(setting.Repository.PushPolicy.ForbiddenFilePatterns)
+		setting.Repository.PushPolicy.ForbiddenFilePatterns = []string{"policy-data-file-*"}
+
+		t.Run("GenerateCommit", func(t *testing.T) {
+			_, err := generateCommitWithNewData(littleSize, dstPath, "user2@example.com", "User Two", "policy-data-file-")
+			assert.NoError(t, err)
+		})
+		t.Run("FailToPushBranch", doGitPushTestRepositoryFail(dstPath, "origin", "HEAD:policy"))
+		t.Run("FailToPushPullRequestRef", doGitPushTestRepositoryFail(dstPath, "origin", "HEAD:refs/for/master"))
+		t.Run("ResetCommit", func(t *testing.T) {
+			_, err := git.NewCommand("reset", "--hard", "HEAD~1").RunInDir(dstPath)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func doPushPolicyWikiPush(ctx APITestContext, dstPath string, u *url.URL) func(t *testing.T) {
+	return func(t *testing.T) {
+		PrintCurrentTest(t)
+		defer func(patterns []string) {
+			setting.Repository.PushPolicy.ForbiddenFilePatterns = patterns
+		}(setting.Repository.PushPolicy.ForbiddenFilePatterns)
+		setting.Repository.PushPolicy.ForbiddenFilePatterns = []string{"policy-data-file-*"}
+
+		t.Run("CreateWiki", func(t *testing.T) {
+			link := fmt.Sprintf("/%s/%s/wiki/_new", url.PathEscape(ctx.Username), url.PathEscape(ctx.Reponame))
+			req := NewRequestWithValues(t, "POST", link, map[string]string{
+				"_csrf":   GetCSRF(t, ctx.Session, link),
+				"title":   "Home",
+				"content": "Wiki of the push policy",
+			})
+			ctx.Session.MakeRequest(t, req, http.StatusFound)
+		})
+		t.Run("GenerateCommit", func(t *testing.T) {
+			_, err := generateCommitWithNewData(littleSize, dstPath, "user2@example.com", "User Two", "policy-data-file-")
+			assert.NoError(t, err)
+		})
+
+		wikiURL := *u
+		wikiURL.Path = fmt.Sprintf("%s/%s.wiki.git", ctx.Username, ctx.Reponame)
+		t.Run("FailToPushWikiBranch", doGitPushTestRepositoryFail(dstPath, wikiURL.String(), "HEAD:refs/heads/policy"))
+		t.Run("PushWikiBranchWithoutPolicy", func(t *testing.T) {
+			setting.Repository.PushPolicy.ForbiddenFilePatterns = nil
+			doGitPushTestRepository(dstPath, wikiURL.String(), "HEAD:refs/heads/policy")(t)
+		})
+		t.Run("ResetCommit", func(t *testing.T) {
+			_, err := git.NewCommand("reset", "--hard", "HEAD~1").RunInDir(dstPath)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func doProtectTag(ctx APITestContext, namePattern string) func(t *testing.T) {
+	return func(t *testing.T) {
+		link := fmt.Sprintf("/%s/%s/settings/tags", url.PathEscape(ctx.Username), url.PathEscape(ctx.Reponame))
+		req := NewRequestWithValues(t, "POST", link, map[string]string{
+			"_csrf":        GetCSRF(t, ctx.Session, link),
+			"name_pattern": namePattern,
+		})
+		ctx.Session.MakeRequest(t, req, http.StatusFound)
+
+		repo, err := models.GetRepositoryByOwnerAndName(ctx.Username, ctx.Reponame)
+		assert.NoError(t, err)
+		pts, err := models.GetProtectedTags(repo.ID)
+		assert.NoError(t, err)
+		assert.Len(t, pts, 1)
+	}
+}
+
 func doMergeFork(ctx, baseCtx APITestContext, baseBranch, headBranch string) func(t *testing.T) {
 	return func(t *testing.T) {
 		var pr api.PullRequest
@@ -283,15 +283,25 @@ func loginUserWithPassword(t testing.TB, userName, password string) *TestSession
 	return session
 }
 
-func getTokenForLoggedInUser(t testing.TB, session *TestSession) string {
+// getTokenForLoggedInUser creates an access token with the given scopes, or
+// with all the scopes if none is given
+func getTokenForLoggedInUser(t testing.TB, session *TestSession, scopes ...models.AccessTokenScope) string {
 	t.Helper()
+	if len(scopes) == 0 {
+		scopes = []models.AccessTokenScope{models.AccessTokenScopeAll}
+	}
 	req := NewRequest(t, "GET", "/user/settings/applications")
 	resp := session.MakeRequest(t, req, http.StatusOK)
 	doc := NewHTMLParser(t, resp.Body)
-	req = NewRequestWithValues(t, "POST", "/user/settings/applications", map[string]string{
-		"_csrf": doc.GetCSRF(),
-		"name":  "api-testing-token",
-	})
+	values := url.Values{
+		"_csrf": {doc.GetCSRF()},
+		"name":  {"api-testing-token"},
+	}
+	for _, scope := range scopes {
+		values.Add("scopes", string(scope))
+	}
+	req = NewRequestWithBody(t, "POST", "/user/settings/applications", bytes.NewBufferString(values.Encode()))
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	resp = session.MakeRequest(t, req, http.StatusFound)
 	req = NewRequest(t, "GET", "/user/settings/applications")
 	resp = session.MakeRequest(t, req, http.StatusOK)
@@ -108,6 +108,32 @@ func TestNoLoginViewIssue(t *testing.T) {
 	MakeRequest(t, req, http.StatusOK)
 }
 
+func TestViewMovedIssue(t *testing.T) {
+	prepareTestEnv(t)
+
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{RepoID: 1, Index: 1}).(*models.Issue)
+	// repo2 is private
+	newRepo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 2}).(*models.Repository)
+	models.AssertSuccessfulInsert(t, &models.RepoUnit{RepoID: newRepo.ID, Type: models.UnitTypeIssues, Config: new(models.IssuesConfig)})
+	_, err := models.MoveIssue(doer, issue, newRepo)
+	assert.NoError(t, err)
+	moved := models.AssertExistsAndLoadBean(t, &models.Issue{ID: issue.ID}).(*models.Issue)
+	assert.NoError(t, moved.LoadRepo())
+
+	// the users who can not read the issue get the stub, which does not
+	// disclose its location
+	req := NewRequest(t, "GET", "/user2/repo1/issues/1")
+	resp := MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "This issue has been moved to another repository")
+	assert.NotContains(t, resp.Body.String(), newRepo.FullName())
+
+	session := loginUser(t, "user2")
+	req = NewRequest(t, "GET", "/user2/repo1/issues/1")
+	resp = session.MakeRequest(t, req, http.StatusFound)
+	assert.EqualValues(t, moved.HTMLURL(), test.RedirectURL(resp))
+}
+
 func testNewIssue(t *testing.T, session *TestSession, user, repo, title, content string) string {
 
 	req := NewRequest(t, "GET", path.Join(user, repo, "issues", "new"))
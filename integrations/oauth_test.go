@@ -6,6 +6,7 @@ package integrations
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"code.gitea.io/gitea/modules/setting"
@@ -238,6 +239,167 @@ func TestRefreshTokenInvalidation(t *testing.T) {
 
 	// test with invalidation
 	setting.OAuth2.InvalidateRefreshTokens = true
-	MakeRequest(t, refreshReq, 200)
+	resp = MakeRequest(t, refreshReq, 200)
+	rotated := new(response)
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), rotated))
+	MakeRequest(t, refreshReq, 400)
+
+	// the reuse of a refresh token revokes the grant and all its tokens
+	refreshReq = NewRequestWithValues(t, "POST", "/login/oauth/access_token", map[string]string{
+		"grant_type":    "refresh_token",
+		"client_id":     "da7da3ba-9a13-4167-856f-3899de0b0138",
+		"client_secret": "4MK8Na6R55smdCY0WuCCumZ6hjRPnGY5saWVRHHjJiA=",
+		"redirect_uri":  "a",
+		"refresh_token": rotated.RefreshToken,
+	})
 	MakeRequest(t, refreshReq, 400)
+	setting.OAuth2.InvalidateRefreshTokens = false
+}
+
+func TestRefreshTokenOfAnotherClient(t *testing.T) {
+	prepareTestEnv(t)
+	req := NewRequestWithValues(t, "POST", "/login/oauth/access_token", map[string]string{
+		"grant_type":    "authorization_code",
+		"client_id":     "da7da3ba-9a13-4167-856f-3899de0b0138",
+		"client_secret": "4MK8Na6R55smdCY0WuCCumZ6hjRPnGY5saWVRHHjJiA=",
+		"redirect_uri":  "a",
+		"code":          "authcode",
+		"code_verifier": "N1Zo9-8Rfwhkt68r1r29ty8YwIraXR8eh_1Qwxg7yQXsonBt",
+	})
+	resp := MakeRequest(t, req, 200)
+	parsed := new(struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+	})
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), parsed))
+
+	// the refresh token was not issued to the public client
+	req = NewRequestWithValues(t, "POST", "/login/oauth/access_token", map[string]string{
+		"grant_type":    "refresh_token",
+		"client_id":     "ce5a1322-42a7-11ea-b77f-2e728ce88125",
+		"refresh_token": parsed.RefreshToken,
+	})
+	MakeRequest(t, req, 400)
+
+	// an access token is not a refresh token
+	req = NewRequestWithValues(t, "POST", "/login/oauth/access_token", map[string]string{
+		"grant_type":    "refresh_token",
+		"client_id":     "da7da3ba-9a13-4167-856f-3899de0b0138",
+		"client_secret": "4MK8Na6R55smdCY0WuCCumZ6hjRPnGY5saWVRHHjJiA=",
+		"refresh_token": parsed.AccessToken,
+	})
+	MakeRequest(t, req, 400)
+}
+
+func TestPublicClientRequiresPKCE(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+
+	req := NewRequest(t, "GET", "/login/oauth/authorize?client_id=ce5a1322-42a7-11ea-b77f-2e728ce88125&redirect_uri=b&response_type=code&state=thestate")
+	resp := session.MakeRequest(t, req, 302)
+	u, err := resp.Result().Location()
+	assert.NoError(t, err)
+	assert.Equal(t, "invalid_request", u.Query().Get("error"))
+
+	req = NewRequest(t, "GET", "/login/oauth/authorize?client_id=ce5a1322-42a7-11ea-b77f-2e728ce88125&redirect_uri=b&response_type=code&state=thestate"+
+		"&code_challenge_method=plain&code_challenge=N1Zo9-8Rfwhkt68r1r29ty8YwIraXR8eh_1Qwxg7yQXsonBt")
+	resp = session.MakeRequest(t, req, 302)
+	u, err = resp.Result().Location()
+	assert.NoError(t, err)
+	assert.Equal(t, "invalid_request", u.Query().Get("error"))
+
+	req = NewRequest(t, "GET", "/login/oauth/authorize?client_id=ce5a1322-42a7-11ea-b77f-2e728ce88125&redirect_uri=b&response_type=code&state=thestate"+
+		"&code_challenge_method=S256&code_challenge=CjvyTLSdR47G5zYenDA-eDWW4lRrO8yvjcWwbD_deOg")
+	resp = session.MakeRequest(t, req, 200)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	req = NewRequestWithValues(t, "POST", "/login/oauth/grant", map[string]string{
+		"_csrf":        htmlDoc.GetCSRF(),
+		"client_id":    "ce5a1322-42a7-11ea-b77f-2e728ce88125",
+		"redirect_uri": "b",
+		"state":        "thestate",
+	})
+	resp = session.MakeRequest(t, req, 302)
+	u, err = resp.Result().Location()
+	assert.NoError(t, err)
+	code := u.Query().Get("code")
+	assert.NotEmpty(t, code)
+
+	// the public client has no secret but must send the code verifier
+	req = NewRequestWithValues(t, "POST", "/login/oauth/access_token", map[string]string{
+		"grant_type":   "authorization_code",
+		"client_id":    "ce5a1322-42a7-11ea-b77f-2e728ce88125",
+		"redirect_uri": "b",
+		"code":         code,
+	})
+	MakeRequest(t, req, 400)
+	req = NewRequestWithValues(t, "POST", "/login/oauth/access_token", map[string]string{
+		"grant_type":    "authorization_code",
+		"client_id":     "ce5a1322-42a7-11ea-b77f-2e728ce88125",
+		"redirect_uri":  "b",
+		"code":          code,
+		"code_verifier": "N1Zo9-8Rfwhkt68r1r29ty8YwIraXR8eh_1Qwxg7yQXsonBt",
+	})
+	resp = MakeRequest(t, req, 200)
+	parsed := new(struct {
+		RefreshToken string `json:"refresh_token"`
+	})
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), parsed))
+
+	// the public client refreshes the access token without authenticating,
+	// and may omit its client id
+	req = NewRequestWithValues(t, "POST", "/login/oauth/access_token", map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": parsed.RefreshToken,
+	})
+	MakeRequest(t, req, 200)
+}
+
+func TestDeviceAuthorizationGrant(t *testing.T) {
+	prepareTestEnv(t)
+	req := NewRequestWithValues(t, "POST", "/login/oauth/device_authorization", map[string]string{
+		"client_id": "ce5a1322-42a7-11ea-b77f-2e728ce88125",
+	})
+	resp := MakeRequest(t, req, 200)
+	var device struct {
+		DeviceCode              string `json:"device_code"`
+		UserCode                string `json:"user_code"`
+		VerificationURIComplete string `json:"verification_uri_complete"`
+	}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &device))
+	assert.NotEmpty(t, device.DeviceCode)
+
+	tokenReq := NewRequestWithValues(t, "POST", "/login/oauth/access_token", map[string]string{
+		"grant_type":  "urn:ietf:params:oauth:grant-type:device_code",
+		"client_id":   "ce5a1322-42a7-11ea-b77f-2e728ce88125",
+		"device_code": device.DeviceCode,
+	})
+	resp = MakeRequest(t, tokenReq, 400)
+	assert.Contains(t, resp.Body.String(), "authorization_pending")
+	resp = MakeRequest(t, tokenReq, 400)
+	assert.Contains(t, resp.Body.String(), "slow_down")
+
+	session := loginUser(t, "user2")
+	req = NewRequest(t, "GET", "/login/device?user_code="+strings.ToLower(device.UserCode))
+	resp = session.MakeRequest(t, req, 200)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	htmlDoc.AssertElement(t, "#authorize-device", true)
+	req = NewRequestWithValues(t, "POST", "/login/device", map[string]string{
+		"_csrf":     htmlDoc.GetCSRF(),
+		"user_code": device.UserCode,
+		"granted":   "true",
+	})
+	session.MakeRequest(t, req, 302)
+
+	resp = MakeRequest(t, tokenReq, 200)
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &token))
+	req = NewRequest(t, "GET", "/api/v1/user")
+	req.Header.Set("Authorization", "bearer "+token.AccessToken)
+	resp = MakeRequest(t, req, 200)
+	assert.Contains(t, resp.Body.String(), `"login":"user2"`)
+
+	// the device code can be used only once
+	MakeRequest(t, tokenReq, 400)
 }
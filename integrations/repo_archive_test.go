@@ -0,0 +1,40 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoArchive(t *testing.T) {
+	prepareTestEnv(t)
+
+	// the branches may contain the characters of the quoted strings
+	_, err := git.NewCommand("branch", `a";b`, "master").RunInDir(models.RepoPath("user2", "repo20"))
+	assert.NoError(t, err)
+
+	session := loginUser(t, "user2")
+	for uri, disposition := range map[string]string{
+		"master.zip":      "attachment; filename=repo20-master.zip",
+		"master/a/c.zip":  "attachment; filename=repo20-master-c.zip",
+		`a";b.tar.gz`:     `attachment; filename="repo20-a\";b.tar.gz"`,
+		`a";b/a/c.tar.gz`: `attachment; filename="repo20-a\";b-c.tar.gz"`,
+	} {
+		req := NewRequest(t, "GET", "/user2/repo20/archive/"+uri)
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, disposition, resp.Header().Get("Content-Disposition"), uri)
+	}
+
+	for _, uri := range []string{"unknown.zip", "deadbeef.zip", "master/unknown.zip", "master/link_b.zip"} {
+		req := NewRequest(t, "GET", "/user2/repo20/archive/"+uri)
+		session.MakeRequest(t, req, http.StatusNotFound)
+	}
+}
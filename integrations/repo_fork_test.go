@@ -8,9 +8,11 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -72,3 +74,40 @@ func TestRepoForkToOrg(t *testing.T) {
 	_, exists := htmlDoc.doc.Find("a.ui.button[href^=\"/repo/fork/\"]").Attr("href")
 	assert.False(t, exists, "Forking should not be allowed anymore")
 }
+
+func TestRepoForkSync(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user1")
+		testRepoFork(t, session, "user2", "repo1", "user1", "repo1")
+
+		// The upstream repository has a new commit
+		testEditFile(t, loginUser(t, "user2"), "user2", "repo1", "master", "README.md", "Hello, World (Edited)\n")
+
+		req := NewRequest(t, "GET", "/user1/repo1/branches")
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		assert.Contains(t, htmlDoc.doc.Find(".upstream-divergence").Text(), "1 commits behind, 0 commits ahead of")
+		link, exists := htmlDoc.doc.Find(".upstream-divergence form").Attr("action")
+		assert.True(t, exists, "The template has changed")
+
+		req = NewRequestWithValues(t, "POST", link, map[string]string{
+			"_csrf":  htmlDoc.GetCSRF(),
+			"branch": "master",
+		})
+		session.MakeRequest(t, req, http.StatusFound)
+
+		req = NewRequest(t, "GET", "/user1/repo1/raw/branch/master/README.md")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		assert.EqualValues(t, "Hello, World (Edited)\n", resp.Body.String())
+
+		token := getTokenForLoggedInUser(t, session)
+		req = NewRequestf(t, "GET", "/api/v1/repos/user1/repo1/sync_fork?token=%s", token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		var info api.ForkSyncInfo
+		DecodeJSON(t, resp, &info)
+		assert.EqualValues(t, api.ForkSyncInfo{Branch: "master", UpstreamBranch: "master"}, info)
+
+		req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user1/repo1/sync_fork?token="+token, &api.SyncForkOption{})
+		session.MakeRequest(t, req, http.StatusConflict)
+	})
+}
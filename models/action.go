@@ -13,6 +13,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 
@@ -55,11 +56,7 @@ const (
 )
 
 var (
-	// Same as GitHub. See
-	// https://help.github.com/articles/closing-issues-via-commit-messages
-	issueCloseKeywords  = []string{"close", "closes", "closed", "fix", "fixes", "fixed", "resolve", "resolves", "resolved"}
-	issueReopenKeywords = []string{"reopen", "reopens", "reopened"}
-
+	issueKeywordsOnce                             sync.Once
 	issueCloseKeywordsPat, issueReopenKeywordsPat *regexp.Regexp
 	issueReferenceKeywordsPat                     *regexp.Regexp
 )
@@ -68,15 +65,45 @@ const issueRefRegexpStr = `(?:([0-9a-zA-Z-_\.]+)/([0-9a-zA-Z-_\.]+))?(#[0-9]+)+`
 const issueRefRegexpStrNoKeyword = `(?:\s|^|\(|\[)(?:([0-9a-zA-Z-_\.]+)/([0-9a-zA-Z-_\.]+))?(#[0-9]+)(?:\s|$|\)|\]|:|\.(\s|$))`
 
 func assembleKeywordsPattern(words []string) string {
-	return fmt.Sprintf(`(?i)(?:%s)(?::?) %s`, strings.Join(words, "|"), issueRefRegexpStr)
+	quoted := make([]string, 0, len(words))
+	for _, word := range words {
+		if word = strings.TrimSpace(word); len(word) > 0 {
+			quoted = append(quoted, regexp.QuoteMeta(word))
+		}
+	}
+	if len(quoted) == 0 {
+		// Never matches, the keywords have been disabled.
+		return `[^\s\S]`
+	}
+	return fmt.Sprintf(`(?i)(?:%s)(?::?) %s`, strings.Join(quoted, "|"), issueRefRegexpStr)
 }
 
 func init() {
-	issueCloseKeywordsPat = regexp.MustCompile(assembleKeywordsPattern(issueCloseKeywords))
-	issueReopenKeywordsPat = regexp.MustCompile(assembleKeywordsPattern(issueReopenKeywords))
 	issueReferenceKeywordsPat = regexp.MustCompile(issueRefRegexpStrNoKeyword)
 }
 
+// initIssueKeywordsPatterns compiles the close and reopen keywords, which
+// are only known after the settings have been loaded.
+func initIssueKeywordsPatterns() {
+	issueKeywordsOnce.Do(func() {
+		closeKeywords, reopenKeywords := issueKeywords()
+		issueCloseKeywordsPat = regexp.MustCompile(assembleKeywordsPattern(closeKeywords))
+		issueReopenKeywordsPat = regexp.MustCompile(assembleKeywordsPattern(reopenKeywords))
+	})
+}
+
+// issueKeywords returns the configured close and reopen keywords of all the
+// locales, the language of a commit message is not known.
+func issueKeywords() (closeKeywords, reopenKeywords []string) {
+	closeKeywords = append(closeKeywords, setting.Repository.Issue.CloseKeywords...)
+	reopenKeywords = append(reopenKeywords, setting.Repository.Issue.ReopenKeywords...)
+	for _, keywords := range setting.Repository.Issue.LocaleKeywords {
+		closeKeywords = append(closeKeywords, keywords.CloseKeywords...)
+		reopenKeywords = append(reopenKeywords, keywords.ReopenKeywords...)
+	}
+	return closeKeywords, reopenKeywords
+}
+
 // Action represents user operation type and other information to
 // repository. It implemented interface base.Actioner so that can be
 // used in template render.
@@ -595,7 +622,7 @@ func UpdateIssuesCommit(doer *User, repo *Repository, commits []*PushCommit, bra
 			refMarked[issue.ID] = true
 
 			message := fmt.Sprintf(`<a href="%s/commit/%s">%s</a>`, repo.Link(), c.Sha1, html.EscapeString(c.Message))
-			if err = CreateRefComment(doer, refRepo, issue, message, c.Sha1); err != nil {
+			if err = CreateRefComment(doer, refRepo, issue, message, c.Sha1, repo.ID); err != nil {
 				return err
 			}
 		}
@@ -605,62 +632,90 @@ func UpdateIssuesCommit(doer *User, repo *Repository, commits []*PushCommit, bra
 		if repo.DefaultBranch != branchName && !repo.CloseIssuesViaCommitInAnyBranch {
 			continue
 		}
-		refMarked = make(map[int64]bool)
-		for _, m := range issueCloseKeywordsPat.FindAllStringSubmatch(c.Message, -1) {
-			if len(m[3]) == 0 {
-				continue
-			}
-			ref := m[3]
+		if err := updateIssuesByKeywords(doer, repo, c.Message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-			// issue is from another repo
-			if len(m[1]) > 0 && len(m[2]) > 0 {
-				refRepo, err = GetRepositoryFromMatch(m[1], m[2])
-				if err != nil {
-					continue
-				}
-			} else {
-				refRepo = repo
-			}
+// UpdateIssuesPullRequest checks if issues are manipulated by the title or
+// description of a merged pull request.
+func UpdateIssuesPullRequest(doer *User, pr *PullRequest) error {
+	if err := pr.loadIssue(x); err != nil {
+		return err
+	}
+	if err := pr.GetBaseRepo(); err != nil {
+		return err
+	}
+	if pr.BaseRepo.DefaultBranch != pr.BaseBranch && !pr.BaseRepo.CloseIssuesViaCommitInAnyBranch {
+		return nil
+	}
+	return updateIssuesByKeywords(doer, pr.BaseRepo, pr.Issue.Title+"\n"+pr.Issue.Content)
+}
 
-			perm, err := GetUserRepoPermission(refRepo, doer)
-			if err != nil {
-				return err
-			}
-			// only close issues in another repo if user has push access
-			if perm.CanWrite(UnitTypeCode) {
-				if err := changeIssueStatus(refRepo, doer, ref, refMarked, true); err != nil {
-					return err
-				}
-			}
+// updateIssuesByKeywords closes and reopens the issues referenced with one
+// of the configured keywords in message.
+func updateIssuesByKeywords(doer *User, repo *Repository, message string) error {
+	initIssueKeywordsPatterns()
+
+	var refRepo *Repository
+	var err error
+	refMarked := make(map[int64]bool)
+	for _, m := range issueCloseKeywordsPat.FindAllStringSubmatch(message, -1) {
+		if len(m[3]) == 0 {
+			continue
 		}
+		ref := m[3]
 
-		// It is conflict to have close and reopen at same time, so refsMarked doesn't need to reinit here.
-		for _, m := range issueReopenKeywordsPat.FindAllStringSubmatch(c.Message, -1) {
-			if len(m[3]) == 0 {
+		// issue is from another repo
+		if len(m[1]) > 0 && len(m[2]) > 0 {
+			refRepo, err = GetRepositoryFromMatch(m[1], m[2])
+			if err != nil {
 				continue
 			}
-			ref := m[3]
+		} else {
+			refRepo = repo
+		}
 
-			// issue is from another repo
-			if len(m[1]) > 0 && len(m[2]) > 0 {
-				refRepo, err = GetRepositoryFromMatch(m[1], m[2])
-				if err != nil {
-					continue
-				}
-			} else {
-				refRepo = repo
+		perm, err := GetUserRepoPermission(refRepo, doer)
+		if err != nil {
+			return err
+		}
+		// only close issues in another repo if user has push access
+		if perm.CanWrite(UnitTypeCode) {
+			if err := changeIssueStatus(refRepo, doer, ref, refMarked, true); err != nil {
+				return err
 			}
+		}
+	}
+
+	// It is conflict to have close and reopen at same time, so refsMarked doesn't need to reinit here.
+	for _, m := range issueReopenKeywordsPat.FindAllStringSubmatch(message, -1) {
+		if len(m[3]) == 0 {
+			continue
+		}
+		ref := m[3]
 
-			perm, err := GetUserRepoPermission(refRepo, doer)
+		// issue is from another repo
+		if len(m[1]) > 0 && len(m[2]) > 0 {
+			refRepo, err = GetRepositoryFromMatch(m[1], m[2])
 			if err != nil {
-				return err
+				continue
 			}
+		} else {
+			refRepo = repo
+		}
 
-			// only reopen issues in another repo if user has push access
-			if perm.CanWrite(UnitTypeCode) {
-				if err := changeIssueStatus(refRepo, doer, ref, refMarked, false); err != nil {
-					return err
-				}
+		perm, err := GetUserRepoPermission(refRepo, doer)
+		if err != nil {
+			return err
+		}
+
+		// only reopen issues in another repo if user has push access
+		if perm.CanWrite(UnitTypeCode) {
+			if err := changeIssueStatus(refRepo, doer, ref, refMarked, false); err != nil {
+				return err
 			}
 		}
 	}
@@ -3,6 +3,7 @@ package models
 import (
 	"fmt"
 	"path"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -201,6 +202,50 @@ func TestRegExp_issueReferenceKeywordsPat(t *testing.T) {
 	}
 }
 
+func TestRegExp_assembleKeywordsPattern(t *testing.T) {
+	pat := regexp.MustCompile(assembleKeywordsPattern([]string{"fixes", "schließt", "c++"}))
+	for _, testCase := range []string{
+		"fixes #2",
+		"Fixes: #2",
+		"SCHLIEßT #2",
+		"c++ user2/repo1#2",
+	} {
+		assert.True(t, pat.MatchString(testCase), testCase)
+	}
+	for _, testCase := range []string{
+		"closes #2",
+		"ccc #2",
+	} {
+		assert.False(t, pat.MatchString(testCase), testCase)
+	}
+
+	pat = regexp.MustCompile(assembleKeywordsPattern([]string{""}))
+	assert.False(t, pat.MatchString("fixes #2"))
+	assert.False(t, pat.MatchString(" #2"))
+}
+
+func TestIssueKeywords(t *testing.T) {
+	oldIssue := setting.Repository.Issue
+	defer func() {
+		setting.Repository.Issue = oldIssue
+	}()
+	setting.Repository.Issue.CloseKeywords = []string{"fixes"}
+	setting.Repository.Issue.ReopenKeywords = []string{"reopens"}
+	setting.Repository.Issue.LocaleKeywords = map[string]setting.IssueKeywords{
+		"de-DE": {CloseKeywords: []string{"behebt"}, ReopenKeywords: []string{"öffnet"}},
+	}
+
+	closeKeywords, reopenKeywords := issueKeywords()
+	assert.Equal(t, []string{"fixes", "behebt"}, closeKeywords)
+	assert.Equal(t, []string{"reopens", "öffnet"}, reopenKeywords)
+	assert.Equal(t, []string{"fixes"}, setting.Repository.Issue.CloseKeywords)
+
+	// the keywords of the locales are used even if the default ones are disabled
+	setting.Repository.Issue.CloseKeywords = nil
+	closeKeywords, _ = issueKeywords()
+	assert.Equal(t, []string{"behebt"}, closeKeywords)
+}
+
 func Test_getIssueFromRef(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
@@ -332,6 +377,20 @@ func TestUpdateIssuesCommit_Colon(t *testing.T) {
 	CheckConsistencyFor(t, &Action{})
 }
 
+func TestUpdateIssuesPullRequest(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 1}).(*PullRequest)
+	pr.Issue = AssertExistsAndLoadBean(t, &Issue{ID: pr.IssueID}).(*Issue)
+	pr.Issue.Content = "This pull request resolves #1"
+
+	issueBean := &Issue{RepoID: 1, Index: 1}
+	AssertNotExistsBean(t, issueBean, "is_closed=1")
+	assert.NoError(t, UpdateIssuesPullRequest(user, pr))
+	AssertExistsAndLoadBean(t, issueBean, "is_closed=1")
+	CheckConsistencyFor(t, &Action{})
+}
+
 func TestUpdateIssuesCommit_Issue5957(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
@@ -5,12 +5,15 @@
 package models
 
 import (
+	"bytes"
 	"fmt"
 	"io"
-	"os"
+	"net/url"
 	"path"
+	"time"
 
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
 
@@ -55,15 +58,35 @@ func (a *Attachment) APIFormat() *api.Attachment {
 	}
 }
 
-// AttachmentLocalPath returns where attachment is stored in local file
-// system based on given UUID.
-func AttachmentLocalPath(uuid string) string {
-	return path.Join(setting.AttachmentPath, uuid[0:1], uuid[1:2], uuid)
+// AttachmentRelativePath returns the relative path of the attachment in the
+// attachments storage based on given UUID.
+func AttachmentRelativePath(uuid string) string {
+	return path.Join(uuid[0:1], uuid[1:2], uuid)
 }
 
-// LocalPath returns where attachment is stored in local file system.
-func (a *Attachment) LocalPath() string {
-	return AttachmentLocalPath(a.UUID)
+// RelativePath returns the relative path of the attachment in the attachments
+// storage.
+func (a *Attachment) RelativePath() string {
+	return AttachmentRelativePath(a.UUID)
+}
+
+// Open opens the content of the attachment for reading
+func (a *Attachment) Open() (storage.Object, error) {
+	return storage.Attachments.Open(a.RelativePath())
+}
+
+// attachmentDirectURLExpiry is the duration the direct download URLs of the
+// attachments are valid for
+const attachmentDirectURLExpiry = 5 * time.Minute
+
+// DirectURL returns a URL downloading the attachment directly from the
+// storage, it is nil if the storage does not support it or if it is disabled
+func (a *Attachment) DirectURL() (*url.URL, error) {
+	s, ok := storage.Attachments.(storage.URLStorage)
+	if !setting.Storage.ServeDirect || !ok {
+		return nil, nil
+	}
+	return s.URL(a.RelativePath(), a.Name, attachmentDirectURLExpiry)
 }
 
 // DownloadURL returns the download url of the attached file
@@ -75,29 +98,11 @@ func (a *Attachment) DownloadURL() string {
 func NewAttachment(attach *Attachment, buf []byte, file io.Reader) (_ *Attachment, err error) {
 	attach.UUID = gouuid.NewV4().String()
 
-	localPath := attach.LocalPath()
-	if err = os.MkdirAll(path.Dir(localPath), os.ModePerm); err != nil {
-		return nil, fmt.Errorf("MkdirAll: %v", err)
-	}
-
-	fw, err := os.Create(localPath)
+	size, err := storage.Attachments.Save(attach.RelativePath(), io.MultiReader(bytes.NewReader(buf), file), -1)
 	if err != nil {
-		return nil, fmt.Errorf("Create: %v", err)
-	}
-	defer fw.Close()
-
-	if _, err = fw.Write(buf); err != nil {
-		return nil, fmt.Errorf("Write: %v", err)
-	} else if _, err = io.Copy(fw, file); err != nil {
-		return nil, fmt.Errorf("Copy: %v", err)
-	}
-
-	// Update file size
-	var fi os.FileInfo
-	if fi, err = fw.Stat(); err != nil {
-		return nil, fmt.Errorf("file size: %v", err)
+		return nil, fmt.Errorf("Save: %v", err)
 	}
-	attach.Size = fi.Size()
+	attach.Size = size
 
 	if _, err := x.Insert(attach); err != nil {
 		return nil, err
@@ -209,7 +214,7 @@ func DeleteAttachments(attachments []*Attachment, remove bool) (int, error) {
 
 	if remove {
 		for i, a := range attachments {
-			if err := os.Remove(a.LocalPath()); err != nil {
+			if err := storage.Attachments.Delete(a.RelativePath()); err != nil {
 				return i, err
 			}
 		}
@@ -0,0 +1,218 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/sync"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	gouuid "github.com/satori/go.uuid"
+)
+
+// attachmentUploadPool makes sure the chunks of an upload are written one at a time
+var attachmentUploadPool = sync.NewExclusivePool()
+
+// AttachmentUpload represents an attachment of a release uploaded in chunks,
+// the chunks are appended to a temporary file until its size is reached. An
+// interrupted upload is resumed from its offset.
+type AttachmentUpload struct {
+	ID           int64  `xorm:"pk autoincr"`
+	UUID         string `xorm:"uuid UNIQUE"`
+	ReleaseID    int64  `xorm:"INDEX"`
+	UploaderID   int64  `xorm:"INDEX"`
+	Name         string
+	Size         int64              `xorm:"NOT NULL DEFAULT 0"`
+	UploadedSize int64              `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix  timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// APIFormat converts the upload to its API format
+func (upload *AttachmentUpload) APIFormat() *api.AttachmentUpload {
+	return &api.AttachmentUpload{
+		UUID:    upload.UUID,
+		Name:    upload.Name,
+		Size:    upload.Size,
+		Offset:  upload.UploadedSize,
+		Created: upload.CreatedUnix.AsTime(),
+		Updated: upload.UpdatedUnix.AsTime(),
+	}
+}
+
+// LocalPath returns the path of the temporary file of the upload
+func (upload *AttachmentUpload) LocalPath() string {
+	return path.Join(setting.Repository.Upload.TempPath, "attachments", upload.UUID[0:1], upload.UUID[1:2], upload.UUID)
+}
+
+// IsComplete returns whether all the content of the attachment has been
+// uploaded
+func (upload *AttachmentUpload) IsComplete() bool {
+	return upload.UploadedSize == upload.Size
+}
+
+// NewAttachmentUpload starts the upload of an attachment
+func NewAttachmentUpload(upload *AttachmentUpload) error {
+	upload.UUID = gouuid.NewV4().String()
+	upload.UploadedSize = 0
+
+	localPath := upload.LocalPath()
+	if err := os.MkdirAll(path.Dir(localPath), os.ModePerm); err != nil {
+		return fmt.Errorf("MkdirAll: %v", err)
+	}
+	fw, err := os.Create(localPath)
+	if err != nil {
+		return fmt.Errorf("Create: %v", err)
+	}
+	if err = fw.Close(); err != nil {
+		return fmt.Errorf("Close: %v", err)
+	}
+
+	_, err = x.Insert(upload)
+	return err
+}
+
+// GetAttachmentUploadByUUID returns the upload of the UUID
+func GetAttachmentUploadByUUID(uuid string) (*AttachmentUpload, error) {
+	upload := &AttachmentUpload{UUID: uuid}
+	has, err := x.Get(upload)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrAttachmentUploadNotExist{uuid}
+	}
+	return upload, nil
+}
+
+// WriteChunk appends the content of the reader to the upload, the offset must
+// be the one of the upload. The content which has been written is kept if the
+// reader fails so that the upload can be resumed.
+func (upload *AttachmentUpload) WriteChunk(offset int64, r io.Reader) error {
+	attachmentUploadPool.CheckIn(upload.UUID)
+	defer attachmentUploadPool.CheckOut(upload.UUID)
+
+	// Another request may have written a chunk since the upload was loaded
+	current, err := GetAttachmentUploadByUUID(upload.UUID)
+	if err != nil {
+		return err
+	}
+	upload.UploadedSize = current.UploadedSize
+	if offset != upload.UploadedSize {
+		return ErrAttachmentUploadOffset{UUID: upload.UUID, Offset: upload.UploadedSize}
+	}
+
+	fw, err := os.OpenFile(upload.LocalPath(), os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("OpenFile: %v", err)
+	}
+	defer fw.Close()
+
+	// The content written after the offset by an interrupted request is
+	// discarded since it has not been acknowledged
+	if err = fw.Truncate(upload.UploadedSize); err != nil {
+		return fmt.Errorf("Truncate: %v", err)
+	}
+	if _, err = fw.Seek(upload.UploadedSize, io.SeekStart); err != nil {
+		return fmt.Errorf("Seek: %v", err)
+	}
+	// One more byte than remaining is read to detect a content too large
+	written, copyErr := io.Copy(fw, io.LimitReader(r, upload.Size-upload.UploadedSize+1))
+	if written > upload.Size-upload.UploadedSize {
+		return ErrAttachmentUploadTooLarge{UUID: upload.UUID, Size: upload.Size}
+	}
+	if err = fw.Sync(); err != nil {
+		return fmt.Errorf("Sync: %v", err)
+	}
+
+	if written > 0 {
+		// The size is only updated from the offset the chunk was written at,
+		// in case the upload is resumed by another instance
+		affected, err := x.ID(upload.ID).And("uploaded_size = ?", offset).Cols("uploaded_size").
+			Update(&AttachmentUpload{UploadedSize: offset + written})
+		if err != nil {
+			return err
+		} else if affected == 0 {
+			return ErrAttachmentUploadOffset{UUID: upload.UUID, Offset: upload.UploadedSize}
+		}
+		upload.UploadedSize = offset + written
+	}
+	if copyErr != nil {
+		return fmt.Errorf("Copy: %v", copyErr)
+	}
+	return nil
+}
+
+// Complete stores the content of the complete upload as an attachment of its
+// release, the upload is deleted
+func (upload *AttachmentUpload) Complete() (*Attachment, error) {
+	attachmentUploadPool.CheckIn(upload.UUID)
+	defer attachmentUploadPool.CheckOut(upload.UUID)
+
+	// The upload may have been completed by another request meanwhile
+	if _, err := GetAttachmentUploadByUUID(upload.UUID); err != nil {
+		return nil, err
+	}
+	if !upload.IsComplete() {
+		return nil, ErrAttachmentUploadOffset{UUID: upload.UUID, Offset: upload.UploadedSize}
+	}
+
+	fr, err := os.Open(upload.LocalPath())
+	if err != nil {
+		return nil, fmt.Errorf("Open: %v", err)
+	}
+	defer fr.Close()
+
+	attach, err := NewAttachment(&Attachment{
+		UploaderID: upload.UploaderID,
+		Name:       upload.Name,
+		ReleaseID:  upload.ReleaseID,
+	}, nil, fr)
+	if err != nil {
+		return nil, fmt.Errorf("NewAttachment: %v", err)
+	}
+	if err = DeleteAttachmentUpload(upload); err != nil {
+		log.Error("DeleteAttachmentUpload[%s]: %v", upload.UUID, err)
+	}
+	return attach, nil
+}
+
+// DeleteAttachmentUpload deletes the upload and its temporary file
+func DeleteAttachmentUpload(upload *AttachmentUpload) error {
+	if _, err := x.ID(upload.ID).Delete(new(AttachmentUpload)); err != nil {
+		return err
+	}
+	if err := os.Remove(upload.LocalPath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove upload: %v", err)
+	}
+	return nil
+}
+
+// DeleteStaleAttachmentUploads deletes the uploads of attachments which have
+// not been resumed for longer than the configured duration
+func DeleteStaleAttachmentUploads() {
+	log.Trace("Doing: DeleteStaleAttachmentUploads")
+
+	olderThan := time.Now().Add(-setting.Cron.AttachmentUploadsCleanup.OlderThan)
+	uploads := make([]*AttachmentUpload, 0, 10)
+	if err := x.Where("updated_unix < ?", olderThan.Unix()).Find(&uploads); err != nil {
+		log.Error("DeleteStaleAttachmentUploads: %v", err)
+		return
+	}
+	for _, upload := range uploads {
+		if err := DeleteAttachmentUpload(upload); err != nil {
+			log.Error("DeleteAttachmentUpload[%s]: %v", upload.UUID, err)
+		}
+	}
+
+	log.Trace("Finished: DeleteStaleAttachmentUploads")
+}
@@ -0,0 +1,86 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttachmentUpload(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	attachUpload := &AttachmentUpload{
+		ReleaseID:  1,
+		UploaderID: 2,
+		Name:       "release.tar.gz",
+		Size:       11,
+	}
+	assert.NoError(t, NewAttachmentUpload(attachUpload))
+	assert.False(t, attachUpload.IsComplete())
+
+	assert.NoError(t, attachUpload.WriteChunk(0, strings.NewReader("hello")))
+	attachUpload, err := GetAttachmentUploadByUUID(attachUpload.UUID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, attachUpload.UploadedSize)
+
+	// The chunks are appended in order
+	err = attachUpload.WriteChunk(0, strings.NewReader("hello"))
+	assert.True(t, IsErrAttachmentUploadOffset(err))
+	err = attachUpload.WriteChunk(5, strings.NewReader(" world!"))
+	assert.True(t, IsErrAttachmentUploadTooLarge(err))
+	_, err = attachUpload.Complete()
+	assert.True(t, IsErrAttachmentUploadOffset(err))
+
+	assert.NoError(t, attachUpload.WriteChunk(5, strings.NewReader(" world")))
+	assert.True(t, attachUpload.IsComplete())
+	attach, err := attachUpload.Complete()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, attach.ReleaseID)
+	assert.EqualValues(t, 11, attach.Size)
+	assert.Equal(t, "release.tar.gz", attach.Name)
+
+	obj, err := attach.Open()
+	assert.NoError(t, err)
+	content, err := ioutil.ReadAll(obj)
+	assert.NoError(t, err)
+	assert.NoError(t, obj.Close())
+	assert.Equal(t, "hello world", string(content))
+
+	_, err = GetAttachmentUploadByUUID(attachUpload.UUID)
+	assert.True(t, IsErrAttachmentUploadNotExist(err))
+	_, err = os.Stat(attachUpload.LocalPath())
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestAttachmentUpload_WriteChunkStale(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	attachUpload := &AttachmentUpload{
+		ReleaseID:  1,
+		UploaderID: 2,
+		Name:       "release.tar.gz",
+		Size:       10,
+	}
+	assert.NoError(t, NewAttachmentUpload(attachUpload))
+	stale, err := GetAttachmentUploadByUUID(attachUpload.UUID)
+	assert.NoError(t, err)
+
+	// A chunk written at an offset already written by another request is refused
+	assert.NoError(t, attachUpload.WriteChunk(0, strings.NewReader("hello")))
+	err = stale.WriteChunk(0, strings.NewReader("world"))
+	assert.True(t, IsErrAttachmentUploadOffset(err))
+	assert.EqualValues(t, 5, stale.UploadedSize)
+
+	assert.NoError(t, stale.WriteChunk(5, strings.NewReader("world")))
+	_, err = stale.Complete()
+	assert.NoError(t, err)
+	_, err = attachUpload.Complete()
+	assert.True(t, IsErrAttachmentUploadNotExist(err))
+}
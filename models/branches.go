@@ -26,21 +26,29 @@ const (
 
 // ProtectedBranch struct
 type ProtectedBranch struct {
-	ID                        int64  `xorm:"pk autoincr"`
-	RepoID                    int64  `xorm:"UNIQUE(s)"`
-	BranchName                string `xorm:"UNIQUE(s)"`
-	CanPush                   bool   `xorm:"NOT NULL DEFAULT false"`
-	EnableWhitelist           bool
-	WhitelistUserIDs          []int64            `xorm:"JSON TEXT"`
-	WhitelistTeamIDs          []int64            `xorm:"JSON TEXT"`
-	EnableMergeWhitelist      bool               `xorm:"NOT NULL DEFAULT false"`
-	MergeWhitelistUserIDs     []int64            `xorm:"JSON TEXT"`
-	MergeWhitelistTeamIDs     []int64            `xorm:"JSON TEXT"`
-	ApprovalsWhitelistUserIDs []int64            `xorm:"JSON TEXT"`
-	ApprovalsWhitelistTeamIDs []int64            `xorm:"JSON TEXT"`
-	RequiredApprovals         int64              `xorm:"NOT NULL DEFAULT 0"`
-	CreatedUnix               timeutil.TimeStamp `xorm:"created"`
-	UpdatedUnix               timeutil.TimeStamp `xorm:"updated"`
+	ID                             int64  `xorm:"pk autoincr"`
+	RepoID                         int64  `xorm:"UNIQUE(s)"`
+	BranchName                     string `xorm:"UNIQUE(s)"`
+	CanPush                        bool   `xorm:"NOT NULL DEFAULT false"`
+	EnableWhitelist                bool
+	WhitelistUserIDs               []int64            `xorm:"JSON TEXT"`
+	WhitelistTeamIDs               []int64            `xorm:"JSON TEXT"`
+	EnableMergeWhitelist           bool               `xorm:"NOT NULL DEFAULT false"`
+	MergeWhitelistUserIDs          []int64            `xorm:"JSON TEXT"`
+	MergeWhitelistTeamIDs          []int64            `xorm:"JSON TEXT"`
+	EnableApprovalsWhitelist       bool               `xorm:"NOT NULL DEFAULT false"`
+	ApprovalsWhitelistUserIDs      []int64            `xorm:"JSON TEXT"`
+	ApprovalsWhitelistTeamIDs      []int64            `xorm:"JSON TEXT"`
+	RequiredApprovals              int64              `xorm:"NOT NULL DEFAULT 0"`
+	RequireCodeOwnerApproval       bool               `xorm:"NOT NULL DEFAULT false"`
+	EnableMergeQueue               bool               `xorm:"NOT NULL DEFAULT false"`
+	DismissStaleApprovals          bool               `xorm:"NOT NULL DEFAULT false"`
+	EnableStatusCheck              bool               `xorm:"NOT NULL DEFAULT false"`
+	StatusCheckContexts            []string           `xorm:"JSON TEXT"`
+	BlockOnUnresolvedConversations bool               `xorm:"NOT NULL DEFAULT false"`
+	BlockOnOutdatedBranch          bool               `xorm:"NOT NULL DEFAULT false"`
+	CreatedUnix                    timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix                    timeutil.TimeStamp `xorm:"updated"`
 }
 
 // IsProtected returns if the branch is protected
@@ -94,13 +102,120 @@ func (protectBranch *ProtectedBranch) CanUserMerge(userID int64) bool {
 
 // HasEnoughApprovals returns true if pr has enough granted approvals.
 func (protectBranch *ProtectedBranch) HasEnoughApprovals(pr *PullRequest) bool {
+	if protectBranch.RequireCodeOwnerApproval && !protectBranch.HasCodeOwnerApprovals(pr) {
+		return false
+	}
 	if protectBranch.RequiredApprovals == 0 {
 		return true
 	}
 	return protectBranch.GetGrantedApprovalsCount(pr) >= protectBranch.RequiredApprovals
 }
 
-// GetGrantedApprovalsCount returns the number of granted approvals for pr. A granted approval must be authored by a user in an approval whitelist.
+// HasCodeOwnerApprovals returns true if every CODEOWNERS rule owning files changed by pr has been approved by one of its owners.
+func (protectBranch *ProtectedBranch) HasCodeOwnerApprovals(pr *PullRequest) bool {
+	missing, err := GetMissingCodeOwnerApprovals(pr)
+	if err != nil {
+		log.Error("GetMissingCodeOwnerApprovals: %v", err)
+		return false
+	}
+	return len(missing) == 0
+}
+
+// GetMissingCodeOwnerApprovals returns the CODEOWNERS rules owning files changed by pr which have not been approved by one of their owners.
+func GetMissingCodeOwnerApprovals(pr *PullRequest) ([]*CodeOwnerRule, error) {
+	rules, err := pr.GetCodeOwnerRules()
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+	reviews, err := GetReviewersByPullID(pr.IssueID)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := make([]*CodeOwnerRule, 0, len(rules))
+	for _, rule := range rules {
+		approved := false
+		for _, review := range reviews {
+			if review.Type == ReviewTypeApprove && !review.Dismissed && rule.IsOwner(review.ID) {
+				approved = true
+				break
+			}
+		}
+		if !approved {
+			missing = append(missing, rule)
+		}
+	}
+	return missing, nil
+}
+
+// GetMissingStatusCheckContexts returns the required status check contexts
+// which have not succeeded for the head commit of pr.
+func (protectBranch *ProtectedBranch) GetMissingStatusCheckContexts(pr *PullRequest) ([]string, error) {
+	if !protectBranch.EnableStatusCheck || len(protectBranch.StatusCheckContexts) == 0 {
+		return nil, nil
+	}
+	statuses, err := pr.GetLatestCommitStatuses()
+	if err != nil {
+		return nil, err
+	}
+	runs, err := pr.GetLatestCheckRuns()
+	if err != nil {
+		return nil, err
+	}
+	return protectBranch.missingStatusCheckContexts(statuses, runs), nil
+}
+
+// GetMissingCommitStatusCheckContexts returns the required status checks
+// which have not succeeded for the commit of the repository.
+func (protectBranch *ProtectedBranch) GetMissingCommitStatusCheckContexts(repo *Repository, commitID string) ([]string, error) {
+	if !protectBranch.EnableStatusCheck || len(protectBranch.StatusCheckContexts) == 0 {
+		return nil, nil
+	}
+	statuses, err := GetLatestCommitStatus(repo, commitID, 0)
+	if err != nil {
+		return nil, err
+	}
+	runs, err := GetLatestCheckRuns(repo.ID, commitID)
+	if err != nil {
+		return nil, err
+	}
+	return protectBranch.missingStatusCheckContexts(statuses, runs), nil
+}
+
+func (protectBranch *ProtectedBranch) missingStatusCheckContexts(statuses []*CommitStatus, runs []*CheckRun) []string {
+	succeeded := make(map[string]bool, len(statuses))
+	for _, status := range statuses {
+		succeeded[status.Context] = status.State == CommitStatusSuccess
+	}
+	// Check runs are required by their names
+	for _, run := range runs {
+		if run.IsSuccessful() {
+			succeeded[run.Name] = true
+		}
+	}
+	missing := make([]string, 0, len(protectBranch.StatusCheckContexts))
+	for _, context := range protectBranch.StatusCheckContexts {
+		if !succeeded[context] {
+			missing = append(missing, context)
+		}
+	}
+	return missing
+}
+
+// GetUnresolvedConversationsCount returns the number of unresolved
+// conversations of pr if they block merging into the branch.
+func (protectBranch *ProtectedBranch) GetUnresolvedConversationsCount(pr *PullRequest) (int64, error) {
+	if !protectBranch.BlockOnUnresolvedConversations {
+		return 0, nil
+	}
+	if err := pr.LoadIssue(); err != nil {
+		return 0, err
+	}
+	return CountUnresolvedConversations(pr.Issue)
+}
+
+// GetGrantedApprovalsCount returns the number of granted approvals for pr. A granted approval must be authored by a user in an approval whitelist,
+// or by a user with write access to the code of the repository if the approval whitelist is disabled.
 func (protectBranch *ProtectedBranch) GetGrantedApprovalsCount(pr *PullRequest) int64 {
 	reviews, err := GetReviewersByPullID(pr.IssueID)
 	if err != nil {
@@ -108,10 +223,14 @@ func (protectBranch *ProtectedBranch) GetGrantedApprovalsCount(pr *PullRequest)
 		return 0
 	}
 
+	if !protectBranch.EnableApprovalsWhitelist {
+		return protectBranch.getWriterApprovalsCount(reviews)
+	}
+
 	approvals := int64(0)
 	userIDs := make([]int64, 0)
 	for _, review := range reviews {
-		if review.Type != ReviewTypeApprove {
+		if review.Type != ReviewTypeApprove || review.Dismissed {
 			continue
 		}
 		if base.Int64sContains(protectBranch.ApprovalsWhitelistUserIDs, review.ID) {
@@ -128,6 +247,31 @@ func (protectBranch *ProtectedBranch) GetGrantedApprovalsCount(pr *PullRequest)
 	return approvalTeamCount + approvals
 }
 
+// getWriterApprovalsCount returns the number of approvals of users with write access to the code of the repository
+func (protectBranch *ProtectedBranch) getWriterApprovalsCount(reviews []*PullReviewersWithType) int64 {
+	repo, err := GetRepositoryByID(protectBranch.RepoID)
+	if err != nil {
+		log.Error("GetRepositoryByID: %v", err)
+		return 0
+	}
+
+	approvals := int64(0)
+	for _, review := range reviews {
+		if review.Type != ReviewTypeApprove || review.Dismissed {
+			continue
+		}
+		perm, err := GetUserRepoPermission(repo, &review.User)
+		if err != nil {
+			log.Error("GetUserRepoPermission: %v", err)
+			return 0
+		}
+		if perm.CanWrite(UnitTypeCode) {
+			approvals++
+		}
+	}
+	return approvals
+}
+
 // GetProtectedBranchByRepoID getting protected branch by repo ID
 func GetProtectedBranchByRepoID(repoID int64) ([]*ProtectedBranch, error) {
 	protectedBranches := make([]*ProtectedBranch, 0)
@@ -78,3 +78,57 @@ func getDeletedBranch(t *testing.T, branch *DeletedBranch) *DeletedBranch {
 
 	return deletedBranch
 }
+
+func TestProtectedBranch_GetGrantedApprovalsCount(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 1}).(*PullRequest)
+	protectBranch := &ProtectedBranch{RepoID: 1, BranchName: "master", RequiredApprovals: 1}
+	assert.EqualValues(t, 1, protectBranch.GetGrantedApprovalsCount(pr))
+	assert.True(t, protectBranch.HasEnoughApprovals(pr))
+
+	// Only whitelisted reviewers count if the whitelist is enabled
+	protectBranch.EnableApprovalsWhitelist = true
+	assert.EqualValues(t, 0, protectBranch.GetGrantedApprovalsCount(pr))
+	assert.False(t, protectBranch.HasEnoughApprovals(pr))
+	protectBranch.ApprovalsWhitelistUserIDs = []int64{1}
+	assert.EqualValues(t, 1, protectBranch.GetGrantedApprovalsCount(pr))
+
+	// The approving reviewer has no write access
+	pr = AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+	protectBranch = &ProtectedBranch{RepoID: 1, BranchName: "master", RequiredApprovals: 1}
+	assert.EqualValues(t, 0, protectBranch.GetGrantedApprovalsCount(pr))
+}
+
+func TestProtectedBranch_GetMissingStatusCheckContexts(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 1}).(*PullRequest)
+	pr.HeadBranch = "master"
+	protectBranch := &ProtectedBranch{RepoID: 1, BranchName: "master", StatusCheckContexts: []string{"ci/build", "ci/test"}}
+
+	// No contexts are required if status checks are disabled
+	missing, err := protectBranch.GetMissingStatusCheckContexts(pr)
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+
+	protectBranch.EnableStatusCheck = true
+	missing, err = protectBranch.GetMissingStatusCheckContexts(pr)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"ci/build", "ci/test"}, missing)
+
+	sha := "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+	AssertSuccessfulInsert(t, &CommitStatus{Index: 1, RepoID: 1, SHA: sha, Context: "ci/build", ContextHash: hashCommitStatusContext("ci/build"), State: CommitStatusSuccess})
+	AssertSuccessfulInsert(t, &CommitStatus{Index: 2, RepoID: 1, SHA: sha, Context: "ci/test", ContextHash: hashCommitStatusContext("ci/test"), State: CommitStatusFailure})
+	missing, err = protectBranch.GetMissingStatusCheckContexts(pr)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"ci/test"}, missing)
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	missing, err = protectBranch.GetMissingCommitStatusCheckContexts(repo, sha)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"ci/test"}, missing)
+	missing, err = protectBranch.GetMissingCommitStatusCheckContexts(repo, "0000000000000000000000000000000000000000")
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"ci/build", "ci/test"}, missing)
+}
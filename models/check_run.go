@@ -0,0 +1,341 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// CheckRunStatus represents the progress of a check run
+type CheckRunStatus string
+
+const (
+	// CheckRunQueued is for check runs which have not been started yet
+	CheckRunQueued CheckRunStatus = "queued"
+	// CheckRunInProgress is for running check runs
+	CheckRunInProgress CheckRunStatus = "in_progress"
+	// CheckRunCompleted is for finished check runs, which have a conclusion
+	CheckRunCompleted CheckRunStatus = "completed"
+)
+
+// IsValid returns true if the status is known
+func (s CheckRunStatus) IsValid() bool {
+	return s == CheckRunQueued || s == CheckRunInProgress || s == CheckRunCompleted
+}
+
+// CheckRunConclusion represents the result of a completed check run
+type CheckRunConclusion string
+
+const (
+	// CheckRunSuccess is for check runs which succeeded
+	CheckRunSuccess CheckRunConclusion = "success"
+	// CheckRunFailure is for check runs which failed
+	CheckRunFailure CheckRunConclusion = "failure"
+	// CheckRunNeutral is for check runs whose result does not matter
+	CheckRunNeutral CheckRunConclusion = "neutral"
+	// CheckRunCancelled is for check runs which have been cancelled
+	CheckRunCancelled CheckRunConclusion = "cancelled"
+	// CheckRunTimedOut is for check runs which took too long
+	CheckRunTimedOut CheckRunConclusion = "timed_out"
+	// CheckRunActionRequired is for check runs which need the attention of a user
+	CheckRunActionRequired CheckRunConclusion = "action_required"
+	// CheckRunSkipped is for check runs which have not been run
+	CheckRunSkipped CheckRunConclusion = "skipped"
+)
+
+// IsValid returns true if the conclusion is known
+func (c CheckRunConclusion) IsValid() bool {
+	switch c {
+	case CheckRunSuccess, CheckRunFailure, CheckRunNeutral, CheckRunCancelled,
+		CheckRunTimedOut, CheckRunActionRequired, CheckRunSkipped:
+		return true
+	}
+	return false
+}
+
+// CheckRunAnnotationLevel represents the severity of an annotation
+type CheckRunAnnotationLevel string
+
+const (
+	// CheckRunAnnotationNotice is for informative annotations
+	CheckRunAnnotationNotice CheckRunAnnotationLevel = "notice"
+	// CheckRunAnnotationWarning is for warnings
+	CheckRunAnnotationWarning CheckRunAnnotationLevel = "warning"
+	// CheckRunAnnotationFailure is for errors
+	CheckRunAnnotationFailure CheckRunAnnotationLevel = "failure"
+)
+
+// IsValid returns true if the level is known
+func (l CheckRunAnnotationLevel) IsValid() bool {
+	return l == CheckRunAnnotationNotice || l == CheckRunAnnotationWarning || l == CheckRunAnnotationFailure
+}
+
+// CheckRun represents a run of an external check of a commit, such as a CI
+// job, with a summary of its result and annotations of the checked files.
+// Unlike commit statuses, a check run is updated while it is running.
+type CheckRun struct {
+	ID         int64              `xorm:"pk autoincr"`
+	RepoID     int64              `xorm:"INDEX(repo_sha)"`
+	Repo       *Repository        `xorm:"-"`
+	SHA        string             `xorm:"VARCHAR(64) NOT NULL INDEX(repo_sha)"`
+	Name       string             `xorm:"NOT NULL"`
+	Status     CheckRunStatus     `xorm:"VARCHAR(20) NOT NULL"`
+	Conclusion CheckRunConclusion `xorm:"VARCHAR(20)"`
+	ExternalID string
+	DetailsURL string `xorm:"TEXT"`
+	Title      string
+	Summary    string `xorm:"TEXT"`
+	CreatorID  int64
+	Creator    *User `xorm:"-"`
+
+	StartedUnix   timeutil.TimeStamp
+	CompletedUnix timeutil.TimeStamp
+	CreatedUnix   timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix   timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// CheckRunAnnotation represents a message of a check run about lines of a file
+type CheckRunAnnotation struct {
+	ID         int64                   `xorm:"pk autoincr"`
+	CheckRunID int64                   `xorm:"INDEX NOT NULL"`
+	CheckRun   *CheckRun               `xorm:"-"`
+	Path       string                  `xorm:"TEXT NOT NULL"`
+	StartLine  int                     `xorm:"NOT NULL"`
+	EndLine    int                     `xorm:"NOT NULL"`
+	Level      CheckRunAnnotationLevel `xorm:"VARCHAR(20) NOT NULL"`
+	Title      string
+	Message    string `xorm:"TEXT"`
+}
+
+// IsSuccessful returns true if the check run has completed without failing
+func (run *CheckRun) IsSuccessful() bool {
+	return run.Status == CheckRunCompleted &&
+		(run.Conclusion == CheckRunSuccess || run.Conclusion == CheckRunNeutral || run.Conclusion == CheckRunSkipped)
+}
+
+// IsFailed returns true if the check run has completed with a result which
+// needs attention
+func (run *CheckRun) IsFailed() bool {
+	return run.Status == CheckRunCompleted && !run.IsSuccessful()
+}
+
+func (run *CheckRun) loadAttributes(e Engine) (err error) {
+	if run.Repo == nil {
+		run.Repo, err = getRepositoryByID(e, run.RepoID)
+		if err != nil {
+			return fmt.Errorf("getRepositoryByID [%d]: %v", run.RepoID, err)
+		}
+	}
+	if run.Creator == nil && run.CreatorID > 0 {
+		run.Creator, err = getUserByID(e, run.CreatorID)
+		if err != nil && !IsErrUserNotExist(err) {
+			return fmt.Errorf("getUserByID [%d]: %v", run.CreatorID, err)
+		}
+	}
+	return nil
+}
+
+// APIURL returns the API URL of the check run
+func (run *CheckRun) APIURL() string {
+	_ = run.loadAttributes(x)
+	return fmt.Sprintf("%sapi/v1/repos/%s/check-runs/%d", setting.AppURL, run.Repo.FullName(), run.ID)
+}
+
+// APIFormat converts the check run to the API format
+func (run *CheckRun) APIFormat() *api.CheckRun {
+	_ = run.loadAttributes(x)
+	apiRun := &api.CheckRun{
+		ID:         run.ID,
+		URL:        run.APIURL(),
+		HeadSHA:    run.SHA,
+		Name:       run.Name,
+		Status:     string(run.Status),
+		Conclusion: string(run.Conclusion),
+		ExternalID: run.ExternalID,
+		DetailsURL: run.DetailsURL,
+		Output: &api.CheckRunOutput{
+			Title:   run.Title,
+			Summary: run.Summary,
+		},
+		Created: run.CreatedUnix.AsTime(),
+		Updated: run.UpdatedUnix.AsTime(),
+	}
+	if run.StartedUnix > 0 {
+		t := run.StartedUnix.AsTime()
+		apiRun.Started = &t
+	}
+	if run.CompletedUnix > 0 {
+		t := run.CompletedUnix.AsTime()
+		apiRun.Completed = &t
+	}
+	if run.Creator != nil {
+		apiRun.Creator = run.Creator.APIFormat()
+	}
+	return apiRun
+}
+
+// APIFormat converts the annotation to the API format
+func (a *CheckRunAnnotation) APIFormat() *api.CheckRunAnnotation {
+	return &api.CheckRunAnnotation{
+		Path:      a.Path,
+		StartLine: a.StartLine,
+		EndLine:   a.EndLine,
+		Level:     string(a.Level),
+		Title:     a.Title,
+		Message:   a.Message,
+	}
+}
+
+// LastLine returns the line the annotation is shown below
+func (a *CheckRunAnnotation) LastLine() int {
+	if a.EndLine > a.StartLine {
+		return a.EndLine
+	}
+	return a.StartLine
+}
+
+// CreateCheckRun creates a new check run of the commit of the repository
+// with its initial annotations.
+func CreateCheckRun(repo *Repository, creator *User, run *CheckRun, annotations []*CheckRunAnnotation) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	run.RepoID = repo.ID
+	run.Repo = repo
+	run.CreatorID = creator.ID
+	run.Creator = creator
+	if len(run.Status) == 0 {
+		run.Status = CheckRunQueued
+	}
+	if _, err := sess.Insert(run); err != nil {
+		return fmt.Errorf("Insert: %v", err)
+	}
+	if err := insertCheckRunAnnotations(sess, run, annotations); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// UpdateCheckRun updates the check run and appends the annotations to the
+// existing ones.
+func UpdateCheckRun(run *CheckRun, annotations []*CheckRunAnnotation) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := sess.ID(run.ID).AllCols().Update(run); err != nil {
+		return fmt.Errorf("Update: %v", err)
+	}
+	if err := insertCheckRunAnnotations(sess, run, annotations); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+func insertCheckRunAnnotations(e Engine, run *CheckRun, annotations []*CheckRunAnnotation) error {
+	if len(annotations) == 0 {
+		return nil
+	}
+	for _, a := range annotations {
+		a.CheckRunID = run.ID
+	}
+	if _, err := e.Insert(annotations); err != nil {
+		return fmt.Errorf("insert annotations: %v", err)
+	}
+	return nil
+}
+
+// GetCheckRunByID returns the check run of the repository with the id
+func GetCheckRunByID(repoID, id int64) (*CheckRun, error) {
+	run := new(CheckRun)
+	has, err := x.ID(id).And("repo_id = ?", repoID).Get(run)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrCheckRunNotExist{ID: id}
+	}
+	return run, nil
+}
+
+// GetLatestCheckRuns returns the latest check run of each name of the commit
+// of the repository. Check runs which have been reported again with the same
+// name replace the earlier ones.
+func GetLatestCheckRuns(repoID int64, sha string) ([]*CheckRun, error) {
+	runs := make([]*CheckRun, 0, 10)
+	if err := x.Where("repo_id = ? AND sha = ?", repoID, sha).Desc("id").Find(&runs); err != nil {
+		return nil, err
+	}
+
+	latest := make([]*CheckRun, 0, len(runs))
+	seen := make(map[string]bool, len(runs))
+	for _, run := range runs {
+		if !seen[run.Name] {
+			seen[run.Name] = true
+			latest = append(latest, run)
+		}
+	}
+	return latest, nil
+}
+
+// GetLatestCheckRuns returns the latest check run of each name of the head
+// commit of the pull request
+func (pr *PullRequest) GetLatestCheckRuns() ([]*CheckRun, error) {
+	if err := pr.GetHeadRepo(); err != nil {
+		return nil, err
+	}
+	if pr.HeadRepo == nil {
+		return nil, ErrPullRequestHeadRepoMissing{pr.ID, pr.HeadRepoID}
+	}
+	headGitRepo, err := git.OpenRepository(pr.HeadRepo.RepoPath())
+	if err != nil {
+		return nil, err
+	}
+	headCommitID, err := headGitRepo.GetRefCommitID(pr.GetHeadRef())
+	if err != nil {
+		return nil, err
+	}
+	return GetLatestCheckRuns(pr.HeadRepoID, headCommitID)
+}
+
+// GetCheckRunAnnotations returns the annotations of the check run
+func GetCheckRunAnnotations(checkRunID int64) ([]*CheckRunAnnotation, error) {
+	annotations := make([]*CheckRunAnnotation, 0, 10)
+	return annotations, x.Where("check_run_id = ?", checkRunID).Asc("id").Find(&annotations)
+}
+
+// GetCheckRunAnnotationsByPath returns the annotations of the check runs,
+// mapped by the paths of the files they are about.
+func GetCheckRunAnnotationsByPath(runs []*CheckRun) (map[string][]*CheckRunAnnotation, error) {
+	if len(runs) == 0 {
+		return nil, nil
+	}
+	byID := make(map[int64]*CheckRun, len(runs))
+	ids := make([]int64, 0, len(runs))
+	for _, run := range runs {
+		byID[run.ID] = run
+		ids = append(ids, run.ID)
+	}
+
+	annotations := make([]*CheckRunAnnotation, 0, 10)
+	if err := x.In("check_run_id", ids).Asc("id").Find(&annotations); err != nil {
+		return nil, err
+	}
+	byPath := make(map[string][]*CheckRunAnnotation)
+	for _, a := range annotations {
+		a.CheckRun = byID[a.CheckRunID]
+		byPath[a.Path] = append(byPath[a.Path], a)
+	}
+	return byPath, nil
+}
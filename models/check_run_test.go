@@ -0,0 +1,84 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRuns(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	sha := "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+
+	lint := &CheckRun{SHA: sha, Name: "lint", Status: CheckRunInProgress}
+	assert.NoError(t, CreateCheckRun(repo, doer, lint, []*CheckRunAnnotation{
+		{Path: "README.md", StartLine: 1, EndLine: 1, Level: CheckRunAnnotationWarning, Message: "line too long"},
+	}))
+	assert.NoError(t, CreateCheckRun(repo, doer, &CheckRun{SHA: sha, Name: "test", Status: CheckRunCompleted, Conclusion: CheckRunFailure}, nil))
+	// The same name reported again replaces the earlier run
+	test := &CheckRun{SHA: sha, Name: "test", Status: CheckRunCompleted, Conclusion: CheckRunSuccess}
+	assert.NoError(t, CreateCheckRun(repo, doer, test, nil))
+
+	runs, err := GetLatestCheckRuns(repo.ID, sha)
+	assert.NoError(t, err)
+	if assert.Len(t, runs, 2) {
+		assert.Equal(t, test.ID, runs[0].ID)
+		assert.True(t, runs[0].IsSuccessful())
+		assert.Equal(t, lint.ID, runs[1].ID)
+		assert.False(t, runs[1].IsSuccessful())
+		assert.False(t, runs[1].IsFailed())
+	}
+
+	lint.Status = CheckRunCompleted
+	lint.Conclusion = CheckRunActionRequired
+	assert.NoError(t, UpdateCheckRun(lint, []*CheckRunAnnotation{
+		{Path: "main.go", StartLine: 3, EndLine: 5, Level: CheckRunAnnotationFailure, Message: "unused variable"},
+	}))
+	lint, err = GetCheckRunByID(repo.ID, lint.ID)
+	assert.NoError(t, err)
+	assert.True(t, lint.IsFailed())
+
+	annotations, err := GetCheckRunAnnotations(lint.ID)
+	assert.NoError(t, err)
+	assert.Len(t, annotations, 2)
+
+	byPath, err := GetCheckRunAnnotationsByPath(runs)
+	assert.NoError(t, err)
+	if assert.Len(t, byPath["main.go"], 1) {
+		assert.Equal(t, 5, byPath["main.go"][0].LastLine())
+		assert.Equal(t, "lint", byPath["main.go"][0].CheckRun.Name)
+	}
+
+	_, err = GetCheckRunByID(2, lint.ID)
+	assert.True(t, IsErrCheckRunNotExist(err))
+}
+
+func TestDeleteRepository_CheckRuns(t *testing.T) {
+	PrepareTestEnv(t)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	annotations := func() []*CheckRunAnnotation {
+		return []*CheckRunAnnotation{
+			{Path: "README.md", StartLine: 1, EndLine: 1, Level: CheckRunAnnotationWarning, Message: "line too long"},
+		}
+	}
+
+	deleted := AssertExistsAndLoadBean(t, &Repository{ID: 16}).(*Repository)
+	run := &CheckRun{SHA: "69554a64c1e6030f051e5c3f94bfbd773cd6a324", Name: "lint", Status: CheckRunInProgress}
+	assert.NoError(t, CreateCheckRun(deleted, doer, run, annotations()))
+	kept := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	otherRun := &CheckRun{SHA: "65f1bf27bc3bf70f64657658635e66094edbcb4d", Name: "lint", Status: CheckRunInProgress}
+	assert.NoError(t, CreateCheckRun(kept, doer, otherRun, annotations()))
+
+	assert.NoError(t, DeleteRepository(doer, deleted.OwnerID, deleted.ID))
+	AssertNotExistsBean(t, &CheckRun{RepoID: deleted.ID})
+	AssertNotExistsBean(t, &CheckRunAnnotation{CheckRunID: run.ID})
+	AssertExistsAndLoadBean(t, &CheckRun{ID: otherRun.ID})
+	AssertExistsAndLoadBean(t, &CheckRunAnnotation{CheckRunID: otherRun.ID})
+	CheckConsistencyFor(t, &Repository{ID: deleted.ID}, &User{ID: deleted.OwnerID})
+}
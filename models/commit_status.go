@@ -268,7 +268,14 @@ func NewCommitStatus(opts NewCommitStatusOptions) error {
 		return fmt.Errorf("Insert CommitStatus[%s, %s]: %v", repoPath, opts.SHA, err)
 	}
 
-	return sess.Commit()
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+
+	if err = checkScheduledAutoMergesByHeadRepo(x, opts.Repo.ID); err != nil {
+		log.Error("checkScheduledAutoMergesByHeadRepo[%d]: %v", opts.Repo.ID, err)
+	}
+	return nil
 }
 
 // SignCommitWithStatuses represents a commit with validation of signature and status state.
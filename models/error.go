@@ -7,6 +7,7 @@ package models
 
 import (
 	"fmt"
+	"strings"
 
 	"code.gitea.io/gitea/modules/git"
 )
@@ -534,6 +535,21 @@ func (err ErrAccessTokenEmpty) Error() string {
 	return fmt.Sprintf("access token is empty")
 }
 
+// ErrInvalidAccessTokenScope represents a "InvalidAccessTokenScope" kind of error.
+type ErrInvalidAccessTokenScope struct {
+	Scope string
+}
+
+// IsErrInvalidAccessTokenScope checks if an error is a ErrInvalidAccessTokenScope.
+func IsErrInvalidAccessTokenScope(err error) bool {
+	_, ok := err.(ErrInvalidAccessTokenScope)
+	return ok
+}
+
+func (err ErrInvalidAccessTokenScope) Error() string {
+	return fmt.Sprintf("invalid access token scope [scope: %s]", err.Scope)
+}
+
 // ________                            .__                __  .__
 // \_____  \_______  _________    ____ |__|____________ _/  |_|__| ____   ____
 //  /   |   \_  __ \/ ___\__  \  /    \|  \___   /\__  \\   __\  |/  _ \ /    \
@@ -674,6 +690,67 @@ func (err ErrRepoAlreadyExist) Error() string {
 	return fmt.Sprintf("repository already exists [uname: %s, name: %s]", err.Uname, err.Name)
 }
 
+// ErrRepoTransferInProgress represents a "RepoTransferInProgress" kind of error.
+type ErrRepoTransferInProgress struct {
+	Uname string
+	Name  string
+}
+
+// IsErrRepoTransferInProgress checks if an error is a ErrRepoTransferInProgress.
+func IsErrRepoTransferInProgress(err error) bool {
+	_, ok := err.(ErrRepoTransferInProgress)
+	return ok
+}
+
+func (err ErrRepoTransferInProgress) Error() string {
+	return fmt.Sprintf("repository is already being transferred [uname: %s, name: %s]", err.Uname, err.Name)
+}
+
+// ErrNoPendingRepoTransfer represents a "NoPendingRepoTransfer" kind of error.
+type ErrNoPendingRepoTransfer struct {
+	RepoID int64
+}
+
+// IsErrNoPendingRepoTransfer checks if an error is a ErrNoPendingRepoTransfer.
+func IsErrNoPendingRepoTransfer(err error) bool {
+	_, ok := err.(ErrNoPendingRepoTransfer)
+	return ok
+}
+
+func (err ErrNoPendingRepoTransfer) Error() string {
+	return fmt.Sprintf("repository is not being transferred [repo_id: %d]", err.RepoID)
+}
+
+// ErrRepoHousekeepingNotExist represents a "RepoHousekeepingNotExist" kind of error.
+type ErrRepoHousekeepingNotExist struct {
+	RepoID int64
+}
+
+// IsErrRepoHousekeepingNotExist checks if an error is a ErrRepoHousekeepingNotExist.
+func IsErrRepoHousekeepingNotExist(err error) bool {
+	_, ok := err.(ErrRepoHousekeepingNotExist)
+	return ok
+}
+
+func (err ErrRepoHousekeepingNotExist) Error() string {
+	return fmt.Sprintf("repository housekeeping has never run [repo_id: %d]", err.RepoID)
+}
+
+// ErrRepoHousekeepingInProgress represents a "RepoHousekeepingInProgress" kind of error.
+type ErrRepoHousekeepingInProgress struct {
+	RepoID int64
+}
+
+// IsErrRepoHousekeepingInProgress checks if an error is a ErrRepoHousekeepingInProgress.
+func IsErrRepoHousekeepingInProgress(err error) bool {
+	_, ok := err.(ErrRepoHousekeepingInProgress)
+	return ok
+}
+
+func (err ErrRepoHousekeepingInProgress) Error() string {
+	return fmt.Sprintf("repository housekeeping is in progress [repo_id: %d]", err.RepoID)
+}
+
 // ErrForkAlreadyExist represents a "ForkAlreadyExist" kind of error.
 type ErrForkAlreadyExist struct {
 	Uname    string
@@ -1058,6 +1135,83 @@ func (err ErrIssueNotExist) Error() string {
 	return fmt.Sprintf("issue does not exist [id: %d, repo_id: %d, index: %d]", err.ID, err.RepoID, err.Index)
 }
 
+// ErrIssueTitleEmpty represents a "IssueTitleEmpty" kind of error.
+type ErrIssueTitleEmpty struct {
+	ID int64
+}
+
+// IsErrIssueTitleEmpty checks if an error is a ErrIssueTitleEmpty.
+func IsErrIssueTitleEmpty(err error) bool {
+	_, ok := err.(ErrIssueTitleEmpty)
+	return ok
+}
+
+func (err ErrIssueTitleEmpty) Error() string {
+	return fmt.Sprintf("issue title is empty [id: %d]", err.ID)
+}
+
+// ErrIssueRedirectNotExist represents a "IssueRedirectNotExist" kind of error.
+type ErrIssueRedirectNotExist struct {
+	RepoID int64
+	Index  int64
+}
+
+// IsErrIssueRedirectNotExist checks if an error is a ErrIssueRedirectNotExist.
+func IsErrIssueRedirectNotExist(err error) bool {
+	_, ok := err.(ErrIssueRedirectNotExist)
+	return ok
+}
+
+func (err ErrIssueRedirectNotExist) Error() string {
+	return fmt.Sprintf("issue redirect does not exist [repo_id: %d, index: %d]", err.RepoID, err.Index)
+}
+
+// ErrIssueMoveNotAllowed represents a "IssueMoveNotAllowed" kind of error.
+type ErrIssueMoveNotAllowed struct {
+	IssueID int64
+	Reason  string
+}
+
+// IsErrIssueMoveNotAllowed checks if an error is a ErrIssueMoveNotAllowed.
+func IsErrIssueMoveNotAllowed(err error) bool {
+	_, ok := err.(ErrIssueMoveNotAllowed)
+	return ok
+}
+
+func (err ErrIssueMoveNotAllowed) Error() string {
+	return fmt.Sprintf("issue cannot be moved [id: %d]: %s", err.IssueID, err.Reason)
+}
+
+// ErrIssueContentHistoryNotExist represents a "IssueContentHistoryNotExist" kind of error.
+type ErrIssueContentHistoryNotExist struct {
+	ID int64
+}
+
+// IsErrIssueContentHistoryNotExist checks if an error is a ErrIssueContentHistoryNotExist.
+func IsErrIssueContentHistoryNotExist(err error) bool {
+	_, ok := err.(ErrIssueContentHistoryNotExist)
+	return ok
+}
+
+func (err ErrIssueContentHistoryNotExist) Error() string {
+	return fmt.Sprintf("issue content history does not exist [id: %d]", err.ID)
+}
+
+// ErrIssueReminderNotExist represents a "IssueReminderNotExist" kind of error.
+type ErrIssueReminderNotExist struct {
+	ID int64
+}
+
+// IsErrIssueReminderNotExist checks if an error is a ErrIssueReminderNotExist.
+func IsErrIssueReminderNotExist(err error) bool {
+	_, ok := err.(ErrIssueReminderNotExist)
+	return ok
+}
+
+func (err ErrIssueReminderNotExist) Error() string {
+	return fmt.Sprintf("issue reminder does not exist [id: %d]", err.ID)
+}
+
 // __________      .__  .__ __________                                     __
 // \______   \__ __|  | |  |\______   \ ____  ________ __   ____   _______/  |_
 //  |     ___/  |  \  | |  | |       _// __ \/ ____/  |  \_/ __ \ /  ___/\   __\
@@ -1143,6 +1297,371 @@ func (err ErrInvalidMergeStyle) Error() string {
 		err.ID, err.Style)
 }
 
+// ErrMergeConflicts represents an error if merging the pull request causes conflicts
+type ErrMergeConflicts struct {
+	Style  MergeStyle
+	StdErr string
+}
+
+// IsErrMergeConflicts checks if an error is a ErrMergeConflicts.
+func IsErrMergeConflicts(err error) bool {
+	_, ok := err.(ErrMergeConflicts)
+	return ok
+}
+
+func (err ErrMergeConflicts) Error() string {
+	return fmt.Sprintf("merge conflicts [style: %s]: %s", err.Style, err.StdErr)
+}
+
+// ErrRebaseConflicts represents an error if rebasing the pull request causes conflicts
+type ErrRebaseConflicts struct {
+	Style  MergeStyle
+	StdErr string
+}
+
+// IsErrRebaseConflicts checks if an error is a ErrRebaseConflicts.
+func IsErrRebaseConflicts(err error) bool {
+	_, ok := err.(ErrRebaseConflicts)
+	return ok
+}
+
+func (err ErrRebaseConflicts) Error() string {
+	return fmt.Sprintf("rebase conflicts [style: %s]: %s", err.Style, err.StdErr)
+}
+
+// ErrMergeNotFastForward represents an error if the base branch can not be
+// fast-forwarded to the head branch because they have diverged
+type ErrMergeNotFastForward struct {
+	BaseBranch string
+	HeadBranch string
+}
+
+// IsErrMergeNotFastForward checks if an error is a ErrMergeNotFastForward.
+func IsErrMergeNotFastForward(err error) bool {
+	_, ok := err.(ErrMergeNotFastForward)
+	return ok
+}
+
+func (err ErrMergeNotFastForward) Error() string {
+	return fmt.Sprintf("base branch can not be fast-forwarded [base: %s, head: %s]", err.BaseBranch, err.HeadBranch)
+}
+
+// ErrRequiredStatusChecksMissing represents an error if required status
+// checks of the protected base branch have not succeeded
+type ErrRequiredStatusChecksMissing struct {
+	Contexts []string
+}
+
+// IsErrRequiredStatusChecksMissing checks if an error is a ErrRequiredStatusChecksMissing.
+func IsErrRequiredStatusChecksMissing(err error) bool {
+	_, ok := err.(ErrRequiredStatusChecksMissing)
+	return ok
+}
+
+func (err ErrRequiredStatusChecksMissing) Error() string {
+	return fmt.Sprintf("required status checks have not succeeded [contexts: %s]", strings.Join(err.Contexts, ", "))
+}
+
+// ErrUnresolvedConversations represents an error if a pull request has
+// unresolved conversations and the protected base branch requires them to be resolved
+type ErrUnresolvedConversations struct {
+	Count int64
+}
+
+// IsErrUnresolvedConversations checks if an error is a ErrUnresolvedConversations.
+func IsErrUnresolvedConversations(err error) bool {
+	_, ok := err.(ErrUnresolvedConversations)
+	return ok
+}
+
+func (err ErrUnresolvedConversations) Error() string {
+	return fmt.Sprintf("pull request has unresolved conversations [count: %d]", err.Count)
+}
+
+// ErrPullRequestOutOfDate represents an error if the head of a pull request
+// is behind its protected base branch and outdated pull requests are blocked
+type ErrPullRequestOutOfDate struct {
+	Behind int64
+}
+
+// IsErrPullRequestOutOfDate checks if an error is a ErrPullRequestOutOfDate.
+func IsErrPullRequestOutOfDate(err error) bool {
+	_, ok := err.(ErrPullRequestOutOfDate)
+	return ok
+}
+
+func (err ErrPullRequestOutOfDate) Error() string {
+	return fmt.Sprintf("pull request is out of date with its base branch [behind: %d]", err.Behind)
+}
+
+// ErrPullRequestUpToDate represents an error if the head of a pull request
+// already contains all the commits of its base branch
+type ErrPullRequestUpToDate struct {
+	ID int64
+}
+
+// IsErrPullRequestUpToDate checks if an error is a ErrPullRequestUpToDate.
+func IsErrPullRequestUpToDate(err error) bool {
+	_, ok := err.(ErrPullRequestUpToDate)
+	return ok
+}
+
+func (err ErrPullRequestUpToDate) Error() string {
+	return fmt.Sprintf("pull request is up to date with its base branch [id: %d]", err.ID)
+}
+
+// ErrPullRequestSizeExceeded represents an error if a pull request exceeds
+// the size limits of the repository and oversized pull requests are blocked
+type ErrPullRequestSizeExceeded struct {
+	ChangedFiles int
+	ChangedLines int
+}
+
+// IsErrPullRequestSizeExceeded checks if an error is a ErrPullRequestSizeExceeded.
+func IsErrPullRequestSizeExceeded(err error) bool {
+	_, ok := err.(ErrPullRequestSizeExceeded)
+	return ok
+}
+
+func (err ErrPullRequestSizeExceeded) Error() string {
+	return fmt.Sprintf("pull request exceeds the size limits [changed files: %d, changed lines: %d]", err.ChangedFiles, err.ChangedLines)
+}
+
+// ErrMergeConflictNotResolvable represents an error if a merge conflict can
+// not be resolved by editing the content of the file
+type ErrMergeConflictNotResolvable struct {
+	Path string
+}
+
+// IsErrMergeConflictNotResolvable checks if an error is a ErrMergeConflictNotResolvable.
+func IsErrMergeConflictNotResolvable(err error) bool {
+	_, ok := err.(ErrMergeConflictNotResolvable)
+	return ok
+}
+
+func (err ErrMergeConflictNotResolvable) Error() string {
+	return fmt.Sprintf("merge conflict can not be resolved by editing the file [path: %s]", err.Path)
+}
+
+// ErrMergeConflictNotResolved represents an error if no resolution or a
+// resolution still containing conflict markers is given for a conflicting file
+type ErrMergeConflictNotResolved struct {
+	Path string
+}
+
+// IsErrMergeConflictNotResolved checks if an error is a ErrMergeConflictNotResolved.
+func IsErrMergeConflictNotResolved(err error) bool {
+	_, ok := err.(ErrMergeConflictNotResolved)
+	return ok
+}
+
+func (err ErrMergeConflictNotResolved) Error() string {
+	return fmt.Sprintf("merge conflict has not been resolved [path: %s]", err.Path)
+}
+
+// ErrCherryPickConflicts represents an error if applying or reverting the
+// changes of a commit on a branch causes conflicts
+type ErrCherryPickConflicts struct {
+	CommitID string
+	Revert   bool
+	Paths    []string
+}
+
+// IsErrCherryPickConflicts checks if an error is a ErrCherryPickConflicts.
+func IsErrCherryPickConflicts(err error) bool {
+	_, ok := err.(ErrCherryPickConflicts)
+	return ok
+}
+
+func (err ErrCherryPickConflicts) Error() string {
+	if err.Revert {
+		return fmt.Sprintf("revert causes conflicts [commit_id: %s, paths: %s]", err.CommitID, strings.Join(err.Paths, ", "))
+	}
+	return fmt.Sprintf("cherry-pick causes conflicts [commit_id: %s, paths: %s]", err.CommitID, strings.Join(err.Paths, ", "))
+}
+
+// ErrCherryPickNotPossible represents an error if a commit can not be cherry-picked or reverted
+type ErrCherryPickNotPossible struct {
+	CommitID string
+	Revert   bool
+	Reason   string
+}
+
+// IsErrCherryPickNotPossible checks if an error is a ErrCherryPickNotPossible.
+func IsErrCherryPickNotPossible(err error) bool {
+	_, ok := err.(ErrCherryPickNotPossible)
+	return ok
+}
+
+func (err ErrCherryPickNotPossible) Error() string {
+	if err.Revert {
+		return fmt.Sprintf("commit can not be reverted [commit_id: %s]: %s", err.CommitID, err.Reason)
+	}
+	return fmt.Sprintf("commit can not be cherry-picked [commit_id: %s]: %s", err.CommitID, err.Reason)
+}
+
+// ErrPushPolicyViolation represents an error if a push violates the push
+// policy of the instance
+type ErrPushPolicyViolation struct {
+	Reason string
+}
+
+// IsErrPushPolicyViolation checks if an error is a ErrPushPolicyViolation.
+func IsErrPushPolicyViolation(err error) bool {
+	_, ok := err.(ErrPushPolicyViolation)
+	return ok
+}
+
+func (err ErrPushPolicyViolation) Error() string {
+	return fmt.Sprintf("push rejected by policy: %s", err.Reason)
+}
+
+// ErrInvalidPushOption represents an error if a push option can not be applied
+// to the repository
+type ErrInvalidPushOption struct {
+	Key    string
+	Value  string
+	Reason string
+}
+
+// IsErrInvalidPushOption checks if an error is a ErrInvalidPushOption.
+func IsErrInvalidPushOption(err error) bool {
+	_, ok := err.(ErrInvalidPushOption)
+	return ok
+}
+
+func (err ErrInvalidPushOption) Error() string {
+	return fmt.Sprintf("invalid push option %s=%s: %s", err.Key, err.Value, err.Reason)
+}
+
+// ErrForkSyncNotPossible represents an error if a branch of a repository can
+// not be synchronized with the upstream repository
+type ErrForkSyncNotPossible struct {
+	RepoID int64
+	Branch string
+	Reason string
+}
+
+// IsErrForkSyncNotPossible checks if an error is a ErrForkSyncNotPossible.
+func IsErrForkSyncNotPossible(err error) bool {
+	_, ok := err.(ErrForkSyncNotPossible)
+	return ok
+}
+
+func (err ErrForkSyncNotPossible) Error() string {
+	return fmt.Sprintf("branch can not be synchronized with upstream [repo_id: %d, branch: %s]: %s", err.RepoID, err.Branch, err.Reason)
+}
+
+// ErrForkUpToDate represents an error if a branch of a fork already contains
+// all the commits of its upstream branch
+type ErrForkUpToDate struct {
+	RepoID int64
+	Branch string
+}
+
+// IsErrForkUpToDate checks if an error is a ErrForkUpToDate.
+func IsErrForkUpToDate(err error) bool {
+	_, ok := err.(ErrForkUpToDate)
+	return ok
+}
+
+func (err ErrForkUpToDate) Error() string {
+	return fmt.Sprintf("branch is up to date with upstream [repo_id: %d, branch: %s]", err.RepoID, err.Branch)
+}
+
+// ErrForkSyncConflicts represents an error if merging the upstream branch into
+// a branch of a fork causes conflicts
+type ErrForkSyncConflicts struct {
+	Branch string
+	Paths  []string
+}
+
+// IsErrForkSyncConflicts checks if an error is a ErrForkSyncConflicts.
+func IsErrForkSyncConflicts(err error) bool {
+	_, ok := err.(ErrForkSyncConflicts)
+	return ok
+}
+
+func (err ErrForkSyncConflicts) Error() string {
+	return fmt.Sprintf("merging upstream causes conflicts [branch: %s, paths: %s]", err.Branch, strings.Join(err.Paths, ", "))
+}
+
+// ErrMergeQueueEntryNotExist represents a "MergeQueueEntryNotExist" kind of error.
+type ErrMergeQueueEntryNotExist struct {
+	PullID int64
+}
+
+// IsErrMergeQueueEntryNotExist checks if an error is a ErrMergeQueueEntryNotExist.
+func IsErrMergeQueueEntryNotExist(err error) bool {
+	_, ok := err.(ErrMergeQueueEntryNotExist)
+	return ok
+}
+
+func (err ErrMergeQueueEntryNotExist) Error() string {
+	return fmt.Sprintf("pull request is not in the merge queue [pull_id: %d]", err.PullID)
+}
+
+// ErrMergeQueueEntryAlreadyExist represents a "MergeQueueEntryAlreadyExist" kind of error.
+type ErrMergeQueueEntryAlreadyExist struct {
+	PullID int64
+}
+
+// IsErrMergeQueueEntryAlreadyExist checks if an error is a ErrMergeQueueEntryAlreadyExist.
+func IsErrMergeQueueEntryAlreadyExist(err error) bool {
+	_, ok := err.(ErrMergeQueueEntryAlreadyExist)
+	return ok
+}
+
+func (err ErrMergeQueueEntryAlreadyExist) Error() string {
+	return fmt.Sprintf("pull request is already in the merge queue [pull_id: %d]", err.PullID)
+}
+
+// ErrPullAutoMergeNotExist represents a "PullAutoMergeNotExist" kind of error.
+type ErrPullAutoMergeNotExist struct {
+	PullID int64
+}
+
+// IsErrPullAutoMergeNotExist checks if an error is a ErrPullAutoMergeNotExist.
+func IsErrPullAutoMergeNotExist(err error) bool {
+	_, ok := err.(ErrPullAutoMergeNotExist)
+	return ok
+}
+
+func (err ErrPullAutoMergeNotExist) Error() string {
+	return fmt.Sprintf("pull request is not scheduled for automatic merge [pull_id: %d]", err.PullID)
+}
+
+// ErrPullAutoMergeAlreadyScheduled represents a "PullAutoMergeAlreadyScheduled" kind of error.
+type ErrPullAutoMergeAlreadyScheduled struct {
+	PullID int64
+}
+
+// IsErrPullAutoMergeAlreadyScheduled checks if an error is a ErrPullAutoMergeAlreadyScheduled.
+func IsErrPullAutoMergeAlreadyScheduled(err error) bool {
+	_, ok := err.(ErrPullAutoMergeAlreadyScheduled)
+	return ok
+}
+
+func (err ErrPullAutoMergeAlreadyScheduled) Error() string {
+	return fmt.Sprintf("pull request is already scheduled for automatic merge [pull_id: %d]", err.PullID)
+}
+
+// ErrMergeQueueDisabled represents a "MergeQueueDisabled" kind of error.
+type ErrMergeQueueDisabled struct {
+	RepoID     int64
+	BaseBranch string
+}
+
+// IsErrMergeQueueDisabled checks if an error is a ErrMergeQueueDisabled.
+func IsErrMergeQueueDisabled(err error) bool {
+	_, ok := err.(ErrMergeQueueDisabled)
+	return ok
+}
+
+func (err ErrMergeQueueDisabled) Error() string {
+	return fmt.Sprintf("merge queue is not enabled for the branch [repo_id: %d, branch: %s]", err.RepoID, err.BaseBranch)
+}
+
 // _________                                       __
 // \_   ___ \  ____   _____   _____   ____   _____/  |_
 // /    \  \/ /  _ \ /     \ /     \_/ __ \ /    \   __\
@@ -1166,6 +1685,114 @@ func (err ErrCommentNotExist) Error() string {
 	return fmt.Sprintf("comment does not exist [id: %d, issue_id: %d]", err.ID, err.IssueID)
 }
 
+// ErrCheckRunNotExist represents a "CheckRunNotExist" kind of error.
+type ErrCheckRunNotExist struct {
+	ID int64
+}
+
+// IsErrCheckRunNotExist checks if an error is a ErrCheckRunNotExist.
+func IsErrCheckRunNotExist(err error) bool {
+	_, ok := err.(ErrCheckRunNotExist)
+	return ok
+}
+
+func (err ErrCheckRunNotExist) Error() string {
+	return fmt.Sprintf("check run does not exist [id: %d]", err.ID)
+}
+
+// ErrPushMirrorNotExist represents a "PushMirrorNotExist" kind of error.
+type ErrPushMirrorNotExist struct {
+	ID     int64
+	RepoID int64
+}
+
+// IsErrPushMirrorNotExist checks if an error is a ErrPushMirrorNotExist.
+func IsErrPushMirrorNotExist(err error) bool {
+	_, ok := err.(ErrPushMirrorNotExist)
+	return ok
+}
+
+func (err ErrPushMirrorNotExist) Error() string {
+	return fmt.Sprintf("push mirror does not exist [id: %d, repo_id: %d]", err.ID, err.RepoID)
+}
+
+// ErrMirrorSSHKeyNotExist represents a "MirrorSSHKeyNotExist" kind of error.
+type ErrMirrorSSHKeyNotExist struct {
+	RepoID int64
+}
+
+// IsErrMirrorSSHKeyNotExist checks if an error is a ErrMirrorSSHKeyNotExist.
+func IsErrMirrorSSHKeyNotExist(err error) bool {
+	_, ok := err.(ErrMirrorSSHKeyNotExist)
+	return ok
+}
+
+func (err ErrMirrorSSHKeyNotExist) Error() string {
+	return fmt.Sprintf("mirror SSH key does not exist [repo_id: %d]", err.RepoID)
+}
+
+// ErrProtectedTagNotExist represents a "ProtectedTagNotExist" kind of error.
+type ErrProtectedTagNotExist struct {
+	ID     int64
+	RepoID int64
+}
+
+// IsErrProtectedTagNotExist checks if an error is a ErrProtectedTagNotExist.
+func IsErrProtectedTagNotExist(err error) bool {
+	_, ok := err.(ErrProtectedTagNotExist)
+	return ok
+}
+
+func (err ErrProtectedTagNotExist) Error() string {
+	return fmt.Sprintf("protected tag does not exist [id: %d, repo_id: %d]", err.ID, err.RepoID)
+}
+
+// ErrInvalidTagPattern represents a "InvalidTagPattern" kind of error.
+type ErrInvalidTagPattern struct {
+	Pattern string
+}
+
+// IsErrInvalidTagPattern checks if an error is a ErrInvalidTagPattern.
+func IsErrInvalidTagPattern(err error) bool {
+	_, ok := err.(ErrInvalidTagPattern)
+	return ok
+}
+
+func (err ErrInvalidTagPattern) Error() string {
+	return fmt.Sprintf("invalid tag pattern [pattern: %s]", err.Pattern)
+}
+
+// ErrProtectedTagName represents a "ProtectedTagName" kind of error.
+type ErrProtectedTagName struct {
+	TagName string
+}
+
+// IsErrProtectedTagName checks if an error is a ErrProtectedTagName.
+func IsErrProtectedTagName(err error) bool {
+	_, ok := err.(ErrProtectedTagName)
+	return ok
+}
+
+func (err ErrProtectedTagName) Error() string {
+	return fmt.Sprintf("tag is protected [name: %s]", err.TagName)
+}
+
+// ErrQuotaExceeded represents a "QuotaExceeded" kind of error.
+type ErrQuotaExceeded struct {
+	Kind  QuotaKind
+	Limit int64
+}
+
+// IsErrQuotaExceeded checks if an error is a ErrQuotaExceeded.
+func IsErrQuotaExceeded(err error) bool {
+	_, ok := err.(ErrQuotaExceeded)
+	return ok
+}
+
+func (err ErrQuotaExceeded) Error() string {
+	return fmt.Sprintf("quota exceeded [kind: %s, limit: %d]", err.Kind, err.Limit)
+}
+
 //  _________ __                                __         .__
 //  /   _____//  |_  ____ ________  _  _______ _/  |_  ____ |  |__
 //  \_____  \\   __\/  _ \\____ \ \/ \/ /\__  \\   __\/ ___\|  |  \
@@ -1233,6 +1860,21 @@ func (err ErrLabelNotExist) Error() string {
 	return fmt.Sprintf("label does not exist [label_id: %d, repo_id: %d]", err.LabelID, err.RepoID)
 }
 
+// ErrLabelExclusiveScopeConflict represents a "LabelExclusiveScopeConflict" kind of error.
+type ErrLabelExclusiveScopeConflict struct {
+	Scope string
+}
+
+// IsErrLabelExclusiveScopeConflict checks if an error is a ErrLabelExclusiveScopeConflict.
+func IsErrLabelExclusiveScopeConflict(err error) bool {
+	_, ok := err.(ErrLabelExclusiveScopeConflict)
+	return ok
+}
+
+func (err ErrLabelExclusiveScopeConflict) Error() string {
+	return fmt.Sprintf("more than one label of exclusive scope [scope: %s]", err.Scope)
+}
+
 //    _____  .__.__                   __
 //   /     \ |__|  |   ____   _______/  |_  ____   ____   ____
 //  /  \ /  \|  |  | _/ __ \ /  ___/\   __\/  _ \ /    \_/ __ \
@@ -1279,6 +1921,53 @@ func (err ErrAttachmentNotExist) Error() string {
 	return fmt.Sprintf("attachment does not exist [id: %d, uuid: %s]", err.ID, err.UUID)
 }
 
+// ErrAttachmentUploadNotExist represents a "AttachmentUploadNotExist" kind of error.
+type ErrAttachmentUploadNotExist struct {
+	UUID string
+}
+
+// IsErrAttachmentUploadNotExist checks if an error is a ErrAttachmentUploadNotExist.
+func IsErrAttachmentUploadNotExist(err error) bool {
+	_, ok := err.(ErrAttachmentUploadNotExist)
+	return ok
+}
+
+func (err ErrAttachmentUploadNotExist) Error() string {
+	return fmt.Sprintf("attachment upload does not exist [uuid: %s]", err.UUID)
+}
+
+// ErrAttachmentUploadOffset represents a "AttachmentUploadOffset" kind of error.
+type ErrAttachmentUploadOffset struct {
+	UUID   string
+	Offset int64
+}
+
+// IsErrAttachmentUploadOffset checks if an error is a ErrAttachmentUploadOffset.
+func IsErrAttachmentUploadOffset(err error) bool {
+	_, ok := err.(ErrAttachmentUploadOffset)
+	return ok
+}
+
+func (err ErrAttachmentUploadOffset) Error() string {
+	return fmt.Sprintf("attachment upload is at another offset [uuid: %s, offset: %d]", err.UUID, err.Offset)
+}
+
+// ErrAttachmentUploadTooLarge represents a "AttachmentUploadTooLarge" kind of error.
+type ErrAttachmentUploadTooLarge struct {
+	UUID string
+	Size int64
+}
+
+// IsErrAttachmentUploadTooLarge checks if an error is a ErrAttachmentUploadTooLarge.
+func IsErrAttachmentUploadTooLarge(err error) bool {
+	_, ok := err.(ErrAttachmentUploadTooLarge)
+	return ok
+}
+
+func (err ErrAttachmentUploadTooLarge) Error() string {
+	return fmt.Sprintf("attachment upload exceeds its size [uuid: %s, size: %d]", err.UUID, err.Size)
+}
+
 // .____                 .__           _________
 // |    |    ____   ____ |__| ____    /   _____/ ____  __ _________   ____  ____
 // |    |   /  _ \ / ___\|  |/    \   \_____  \ /  _ \|  |  \_  __ \_/ ___\/ __ \
@@ -1566,6 +2255,67 @@ func (err ErrReviewNotExist) Error() string {
 	return fmt.Sprintf("review does not exist [id: %d]", err.ID)
 }
 
+// ErrCannotReRequestReview represents a "CannotReRequestReview" kind of error.
+type ErrCannotReRequestReview struct {
+	IssueID    int64
+	ReviewerID int64
+}
+
+// IsErrCannotReRequestReview checks if an error is a ErrCannotReRequestReview.
+func IsErrCannotReRequestReview(err error) bool {
+	_, ok := err.(ErrCannotReRequestReview)
+	return ok
+}
+
+func (err ErrCannotReRequestReview) Error() string {
+	return fmt.Sprintf("review can not be requested again [issue_id: %d, reviewer_id: %d]", err.IssueID, err.ReviewerID)
+}
+
+// ErrReviewEmpty represents a "ReviewEmpty" kind of error.
+type ErrReviewEmpty struct{}
+
+// IsErrReviewEmpty checks if an error is a ErrReviewEmpty.
+func IsErrReviewEmpty(err error) bool {
+	_, ok := err.(ErrReviewEmpty)
+	return ok
+}
+
+func (err ErrReviewEmpty) Error() string {
+	return "review has neither content nor code comments"
+}
+
+// ErrInvalidCodeCommentRange represents a "InvalidCodeCommentRange" kind of error.
+type ErrInvalidCodeCommentRange struct {
+	StartLine int64
+	Line      int64
+}
+
+// IsErrInvalidCodeCommentRange checks if an error is a ErrInvalidCodeCommentRange.
+func IsErrInvalidCodeCommentRange(err error) bool {
+	_, ok := err.(ErrInvalidCodeCommentRange)
+	return ok
+}
+
+func (err ErrInvalidCodeCommentRange) Error() string {
+	return fmt.Sprintf("invalid code comment line range [start_line: %d, line: %d]", err.StartLine, err.Line)
+}
+
+// ErrSuggestionNotApplicable represents a "SuggestionNotApplicable" kind of error.
+type ErrSuggestionNotApplicable struct {
+	CommentID int64
+	Reason    string
+}
+
+// IsErrSuggestionNotApplicable checks if an error is a ErrSuggestionNotApplicable.
+func IsErrSuggestionNotApplicable(err error) bool {
+	_, ok := err.(ErrSuggestionNotApplicable)
+	return ok
+}
+
+func (err ErrSuggestionNotApplicable) Error() string {
+	return fmt.Sprintf("suggestion can not be applied [comment_id: %d]: %s", err.CommentID, err.Reason)
+}
+
 //  ________      _____          __  .__
 //  \_____  \    /  _  \  __ ___/  |_|  |__
 //   /   |   \  /  /_\  \|  |  \   __\  |  \
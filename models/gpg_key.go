@@ -17,6 +17,8 @@ import (
 
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 
 	"github.com/go-xorm/xorm"
@@ -355,10 +357,29 @@ func DeleteGPGKey(doer *User, id int64) (err error) {
 
 // CommitVerification represents a commit validation of signature
 type CommitVerification struct {
-	Verified    bool
+	Verified bool
+	// TrustStatus is whether the verified signature is trusted according to
+	// the trust model of the instance
+	TrustStatus string
 	Reason      string
 	SigningUser *User
 	SigningKey  *GPGKey
+	// SigningSSHKey is the SSH key which made the signature, if it is an SSH
+	// signature
+	SigningSSHKey *PublicKey
+	// IsInstanceKey is true if the commit has been signed by a key of Gitea
+	IsInstanceKey bool
+}
+
+// The trust statuses of the verified signatures
+const (
+	TrustStatusTrusted   = "trusted"
+	TrustStatusUntrusted = "untrusted"
+)
+
+// IsTrusted returns whether the signature is verified and trusted
+func (verification *CommitVerification) IsTrusted() bool {
+	return verification.Verified && verification.TrustStatus == TrustStatusTrusted
 }
 
 // SignCommit represents a commit with validation of signature.
@@ -424,58 +445,211 @@ func verifySign(s *packet.Signature, h hash.Hash, k *GPGKey) error {
 	return pkey.VerifySignature(h, s)
 }
 
-// ParseCommitWithSignature check if signature is good against keystore.
-func ParseCommitWithSignature(c *git.Commit) *CommitVerification {
-	if c.Signature != nil && c.Committer != nil {
-		//Parsing signature
-		sig, err := extractSignature(c.Signature.Signature)
-		if err != nil { //Skipping failed to extract sign
-			log.Error("SignatureRead err: %v", err)
+// newCommitVerification returns the verification of a signature made by the key
+// of the user with the ID, signerMatch is whether the key is one of the
+// committer or tagger of the signed object. The trust model of the instance
+// decides whether the signature is trusted.
+func newCommitVerification(repo *Repository, user *User, keyID string, signer *git.Signature, signerMatch bool) *CommitVerification {
+	verification := &CommitVerification{
+		Verified:    true,
+		TrustStatus: TrustStatusUntrusted,
+		SigningUser: user,
+	}
+	if signerMatch {
+		verification.Reason = fmt.Sprintf("%s <%s> / %s", signer.Name, signer.Email, keyID)
+	} else {
+		verification.Reason = fmt.Sprintf("%s / %s", user.Name, keyID)
+	}
+
+	trusted := signerMatch
+	switch setting.Repository.Signing.TrustModel {
+	case "collaborator", "collaboratorcommitter":
+		if !signerMatch && setting.Repository.Signing.TrustModel == "collaboratorcommitter" {
+			break
+		}
+		mode, err := AccessLevel(user, repo)
+		if err != nil {
+			log.Error("AccessLevel: %v", err)
+		}
+		trusted = err == nil && mode >= AccessModeWrite
+	}
+	if trusted {
+		verification.TrustStatus = TrustStatusTrusted
+	}
+	return verification
+}
+
+// verifyWithInstanceKey verifies the signature of the payload against the
+// keys Gitea can sign commits with, which are the ones whose secret key is in
+// the GPG keyring of Gitea. It returns nil if none of them made the signature.
+func verifyWithInstanceKey(signature *git.CommitGPGSignature, signer *git.Signature, sig *packet.Signature) *CommitVerification {
+	if sig.IssuerKeyId == nil {
+		return nil
+	}
+	keyID := fmt.Sprintf("%016X", *sig.IssuerKeyId)
+	if _, _, err := process.GetManager().Exec("verifyWithInstanceKey", "gpg", "--batch", "--list-secret-keys", keyID); err != nil {
+		return nil
+	}
+	armored, _, err := process.GetManager().Exec("verifyWithInstanceKey", "gpg", "--batch", "--armor", "--export", keyID)
+	if err != nil || len(armored) == 0 {
+		return nil
+	}
+	keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armored))
+	if err != nil {
+		log.Error("ReadArmoredKeyRing[%s]: %v", keyID, err)
+		return nil
+	}
+
+	for _, k := range keyring.KeysById(*sig.IssuerKeyId, nil) {
+		// The hash can not be reused as the verification writes to it
+		hash, err := populateHash(sig.Hash, []byte(signature.Payload))
+		if err != nil {
+			log.Error("PopulateHash: %v", err)
+			return nil
+		}
+		if err := k.PublicKey.VerifySignature(hash, sig); err == nil {
 			return &CommitVerification{
-				Verified: false,
-				Reason:   "gpg.error.extract_sign",
+				Verified:    true,
+				TrustStatus: TrustStatusTrusted,
+				Reason:      fmt.Sprintf("%s <%s> / %s", signer.Name, signer.Email, keyID),
+				SigningUser: &User{
+					Name:  signer.Name,
+					Email: signer.Email,
+				},
+				SigningKey: &GPGKey{
+					KeyID:   keyID,
+					CanSign: true,
+				},
+				IsInstanceKey: true,
 			}
 		}
+	}
+	return nil
+}
 
-		//Find Committer account
-		committer, err := GetUserByEmail(c.Committer.Email) //This find the user by primary email or activated email so commit will not be valid if email is not
-		if err != nil {                                     //Skipping not user for commiter
-			// We can expect this to often be an ErrUserNotExist. in the case
-			// it is not, however, it is important to log it.
-			if !IsErrUserNotExist(err) {
-				log.Error("GetUserByEmail: %v", err)
+// verifyWithIssuerKey verifies the signature of the payload against the
+// registered key with the ID of the issuer of the signature, whichever user it
+// belongs to. It returns nil if there is none or it did not make the signature.
+func verifyWithIssuerKey(repo *Repository, signature *git.CommitGPGSignature, signer *git.Signature, sig *packet.Signature) *CommitVerification {
+	if sig.IssuerKeyId == nil {
+		return nil
+	}
+	keys := make([]*GPGKey, 0, 1)
+	if err := x.Where("key_id = ?", fmt.Sprintf("%016X", *sig.IssuerKeyId)).Find(&keys); err != nil {
+		log.Error("Find GPG keys: %v", err)
+		return nil
+	}
+
+	for _, k := range keys {
+		// The keys without any activated email do not identify their owner, the
+		// emails of the subkeys are those of their primary key
+		emails := k.Emails
+		if len(k.PrimaryKeyID) > 0 {
+			primaryKey := new(GPGKey)
+			if has, err := x.Where("key_id = ?", k.PrimaryKeyID).Get(primaryKey); err != nil {
+				log.Error("Get primary GPG key[%s]: %v", k.PrimaryKeyID, err)
+				continue
+			} else if has {
+				emails = primaryKey.Emails
 			}
-			return &CommitVerification{
-				Verified: false,
-				Reason:   "gpg.error.no_committer_account",
+		}
+		activated := false
+		for _, e := range emails {
+			if e.IsActivated {
+				activated = true
+				break
 			}
 		}
+		if !activated {
+			continue
+		}
 
-		keys, err := ListGPGKeys(committer.ID)
-		if err != nil { //Skipping failed to get gpg keys of user
-			log.Error("ListGPGKeys: %v", err)
-			return &CommitVerification{
-				Verified: false,
-				Reason:   "gpg.error.failed_retrieval_gpg_keys",
-			}
+		hash, err := populateHash(sig.Hash, []byte(signature.Payload))
+		if err != nil {
+			log.Error("PopulateHash: %v", err)
+			return nil
+		}
+		if err := verifySign(sig, hash, k); err != nil {
+			continue
+		}
+		owner, err := GetUserByID(k.OwnerID)
+		if err != nil {
+			log.Error("GetUserByID[%d]: %v", k.OwnerID, err)
+			continue
 		}
+		verification := newCommitVerification(repo, owner, k.KeyID, signer, false)
+		verification.SigningKey = k
+		return verification
+	}
+	return nil
+}
 
-		for _, k := range keys {
-			//Pre-check (& optimization) that emails attached to key can be attached to the commiter email and can validate
-			canValidate := false
-			lowerCommiterEmail := strings.ToLower(c.Committer.Email)
-			for _, e := range k.Emails {
-				if e.IsActivated && strings.ToLower(e.Email) == lowerCommiterEmail {
-					canValidate = true
-					break
-				}
-			}
-			if !canValidate {
-				continue //Skip this key
+// verifySignature checks the GPG signature of the payload made by the signer,
+// the committer or tagger of the signed object. The keys of the signer are
+// tried first, then the keys of Gitea and the keys of the other users. The SSH
+// signatures are checked against the SSH keys of the users.
+func verifySignature(repo *Repository, signature *git.CommitGPGSignature, signer *git.Signature) *CommitVerification {
+	if isSSHSignature(signature.Signature) {
+		return verifySSHSignature(repo, signature, signer)
+	}
+
+	//Parsing signature
+	sig, err := extractSignature(signature.Signature)
+	if err != nil { //Skipping failed to extract sign
+		log.Error("SignatureRead err: %v", err)
+		return &CommitVerification{
+			Verified: false,
+			Reason:   "gpg.error.extract_sign",
+		}
+	}
+
+	//Find Committer account
+	committer, err := GetUserByEmail(signer.Email) //This find the user by primary email or activated email so commit will not be valid if email is not
+	if err != nil {                                //Skipping not user for commiter
+		// We can expect this to often be an ErrUserNotExist. in the case
+		// it is not, however, it is important to log it.
+		if !IsErrUserNotExist(err) {
+			log.Error("GetUserByEmail: %v", err)
+		}
+		if verification := verifyWithInstanceKey(signature, signer, sig); verification != nil {
+			return verification
+		}
+		if verification := verifyWithIssuerKey(repo, signature, signer, sig); verification != nil {
+			return verification
+		}
+		return &CommitVerification{
+			Verified: false,
+			Reason:   "gpg.error.no_committer_account",
+		}
+	}
+
+	keys, err := ListGPGKeys(committer.ID)
+	if err != nil { //Skipping failed to get gpg keys of user
+		log.Error("ListGPGKeys: %v", err)
+		return &CommitVerification{
+			Verified: false,
+			Reason:   "gpg.error.failed_retrieval_gpg_keys",
+		}
+	}
+
+	lowerCommiterEmail := strings.ToLower(signer.Email)
+	for _, k := range keys {
+		//Pre-check (& optimization) that emails attached to key can be attached to the commiter email and can validate
+		canValidate := false
+		for _, e := range k.Emails {
+			if e.IsActivated && strings.ToLower(e.Email) == lowerCommiterEmail {
+				canValidate = true
+				break
 			}
+		}
+		if !canValidate {
+			continue //Skip this key
+		}
 
+		//And test also SubsKey
+		for _, key := range append([]*GPGKey{k}, k.SubsKey...) {
 			//Generating hash of commit
-			hash, err := populateHash(sig.Hash, []byte(c.Signature.Payload))
+			hash, err := populateHash(sig.Hash, []byte(signature.Payload))
 			if err != nil { //Skipping ailed to generate hash
 				log.Error("PopulateHash: %v", err)
 				return &CommitVerification{
@@ -483,42 +657,31 @@ func ParseCommitWithSignature(c *git.Commit) *CommitVerification {
 					Reason:   "gpg.error.generate_hash",
 				}
 			}
-			//We get PK
-			if err := verifySign(sig, hash, k); err == nil {
-				return &CommitVerification{ //Everything is ok
-					Verified:    true,
-					Reason:      fmt.Sprintf("%s <%s> / %s", c.Committer.Name, c.Committer.Email, k.KeyID),
-					SigningUser: committer,
-					SigningKey:  k,
-				}
+			if err := verifySign(sig, hash, key); err == nil {
+				//Everything is ok
+				verification := newCommitVerification(repo, committer, key.KeyID, signer, true)
+				verification.SigningKey = key
+				return verification
 			}
-			//And test also SubsKey
-			for _, sk := range k.SubsKey {
-
-				//Generating hash of commit
-				hash, err := populateHash(sig.Hash, []byte(c.Signature.Payload))
-				if err != nil { //Skipping ailed to generate hash
-					log.Error("PopulateHash: %v", err)
-					return &CommitVerification{
-						Verified: false,
-						Reason:   "gpg.error.generate_hash",
-					}
-				}
-				if err := verifySign(sig, hash, sk); err == nil {
-					return &CommitVerification{ //Everything is ok
-						Verified:    true,
-						Reason:      fmt.Sprintf("%s <%s> / %s", c.Committer.Name, c.Committer.Email, sk.KeyID),
-						SigningUser: committer,
-						SigningKey:  sk,
-					}
-				}
-			}
-		}
-		return &CommitVerification{ //Default at this stage
-			Verified: false,
-			Reason:   "gpg.error.no_gpg_keys_found",
 		}
 	}
+	if verification := verifyWithInstanceKey(signature, signer, sig); verification != nil {
+		return verification
+	}
+	if verification := verifyWithIssuerKey(repo, signature, signer, sig); verification != nil {
+		return verification
+	}
+	return &CommitVerification{ //Default at this stage
+		Verified: false,
+		Reason:   "gpg.error.no_gpg_keys_found",
+	}
+}
+
+// ParseCommitWithSignature check if signature is good against keystore.
+func ParseCommitWithSignature(repo *Repository, c *git.Commit) *CommitVerification {
+	if c.Signature != nil && c.Committer != nil {
+		return verifySignature(repo, c.Signature, c.Committer)
+	}
 
 	return &CommitVerification{
 		Verified: false,                         //Default value
@@ -526,8 +689,21 @@ func ParseCommitWithSignature(c *git.Commit) *CommitVerification {
 	}
 }
 
+// ParseTagWithSignature checks if the signature of the annotated tag is good
+// against the keystore.
+func ParseTagWithSignature(repo *Repository, t *git.Tag) *CommitVerification {
+	if t.Signature != nil && t.Tagger != nil {
+		return verifySignature(repo, t.Signature, t.Tagger)
+	}
+
+	return &CommitVerification{
+		Verified: false,
+		Reason:   "gpg.error.not_signed_tag",
+	}
+}
+
 // ParseCommitsWithSignature checks if signaute of commits are corresponding to users gpg keys.
-func ParseCommitsWithSignature(oldCommits *list.List) *list.List {
+func ParseCommitsWithSignature(repo *Repository, oldCommits *list.List) *list.List {
 	var (
 		newCommits = list.New()
 		e          = oldCommits.Front()
@@ -536,7 +712,7 @@ func ParseCommitsWithSignature(oldCommits *list.List) *list.List {
 		c := e.Value.(UserCommit)
 		newCommits.PushBack(SignCommit{
 			UserCommit:   &c,
-			Verification: ParseCommitWithSignature(c.Commit),
+			Verification: ParseCommitWithSignature(repo, c.Commit),
 		})
 		e = e.Next()
 	}
@@ -8,6 +8,8 @@ import (
 	"testing"
 	"time"
 
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 
 	"github.com/stretchr/testify/assert"
@@ -376,3 +378,41 @@ epiDVQ==
 	expire := getExpiryTime(ekey)
 	assert.Equal(t, time.Unix(1586105389, 0), expire)
 }
+
+func TestNewCommitVerification(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer func(trustModel string) {
+		setting.Repository.Signing.TrustModel = trustModel
+	}(setting.Repository.Signing.TrustModel)
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	owner := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	reader := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	signer := &git.Signature{Name: "User Two", Email: "user2@example.com"}
+
+	var kases = []struct {
+		trustModel  string
+		user        *User
+		signerMatch bool
+		trusted     bool
+	}{
+		{"committer", owner, true, true},
+		{"committer", owner, false, false},
+		{"committer", reader, true, true},
+		{"collaborator", owner, false, true},
+		{"collaborator", reader, true, false},
+		{"collaboratorcommitter", owner, true, true},
+		{"collaboratorcommitter", owner, false, false},
+		{"collaboratorcommitter", reader, true, false},
+	}
+	for _, kase := range kases {
+		setting.Repository.Signing.TrustModel = kase.trustModel
+		verification := newCommitVerification(repo, kase.user, "5D1A77E8F4E8A3A2", signer, kase.signerMatch)
+		assert.True(t, verification.Verified)
+		assert.Equal(t, kase.trusted, verification.IsTrusted(), "%s %s %v", kase.trustModel, kase.user.Name, kase.signerMatch)
+	}
+
+	verification := newCommitVerification(repo, reader, "5D1A77E8F4E8A3A2", signer, false)
+	assert.Equal(t, TrustStatusUntrusted, verification.TrustStatus)
+	assert.Equal(t, "user4 / 5D1A77E8F4E8A3A2", verification.Reason)
+}
@@ -55,6 +55,10 @@ type Issue struct {
 	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
 	ClosedUnix  timeutil.TimeStamp `xorm:"INDEX"`
 
+	// FirstResponseUnix is the time of the first comment which has not been
+	// written by the poster
+	FirstResponseUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+
 	Attachments      []*Attachment `xorm:"-"`
 	Comments         []*Comment    `xorm:"-"`
 	Reactions        ReactionList  `xorm:"-"`
@@ -251,9 +255,9 @@ func (issue *Issue) loadAttributes(e Engine) (err error) {
 	}
 
 	if issue.Milestone == nil && issue.MilestoneID > 0 {
-		issue.Milestone, err = getMilestoneByRepoID(e, issue.RepoID, issue.MilestoneID)
+		issue.Milestone, err = getAssignableMilestone(e, issue.RepoID, issue.MilestoneID)
 		if err != nil && !IsErrMilestoneNotExist(err) {
-			return fmt.Errorf("getMilestoneByRepoID [repo_id: %d, milestone_id: %d]: %v", issue.RepoID, issue.MilestoneID, err)
+			return fmt.Errorf("getAssignableMilestone [repo_id: %d, milestone_id: %d]: %v", issue.RepoID, issue.MilestoneID, err)
 		}
 	}
 
@@ -655,6 +659,10 @@ func (issue *Issue) ReplaceLabels(labels []*Label, doer *User) (err error) {
 		return err
 	}
 
+	if err = checkExclusiveLabels(labels); err != nil {
+		return err
+	}
+
 	if err = issue.loadLabels(sess); err != nil {
 		return err
 	}
@@ -939,9 +947,26 @@ func (issue *Issue) ChangeContent(doer *User, content string) (err error) {
 	oldContent := issue.Content
 	issue.Content = content
 
-	if err = UpdateIssueCols(issue, "content"); err != nil {
-		return fmt.Errorf("UpdateIssueCols: %v", err)
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if err = updateIssueCols(sess, issue, "content"); err != nil {
+		return fmt.Errorf("updateIssueCols: %v", err)
+	}
+	if err = saveIssueContentHistory(sess, doer, issue.ID, 0, issue.PosterID, issue.CreatedUnix, oldContent, content); err != nil {
+		return fmt.Errorf("saveIssueContentHistory: %v", err)
+	}
+	if err = issue.addCrossReferences(sess, doer, nil); err != nil {
+		return fmt.Errorf("addCrossReferences: %v", err)
+	}
+
+	if err = sess.Commit(); err != nil {
+		return err
 	}
+	sess.Close()
 
 	mode, _ := AccessLevel(issue.Poster, issue.Repo)
 	if issue.IsPull {
@@ -1035,7 +1060,7 @@ func newIssue(e *xorm.Session, doer *User, opts NewIssueOptions) (err error) {
 	opts.Issue.Title = strings.TrimSpace(opts.Issue.Title)
 
 	if opts.Issue.MilestoneID > 0 {
-		milestone, err := getMilestoneByRepoID(e, opts.Issue.RepoID, opts.Issue.MilestoneID)
+		milestone, err := getAssignableMilestone(e, opts.Issue.RepoID, opts.Issue.MilestoneID)
 		if err != nil && !IsErrMilestoneNotExist(err) {
 			return fmt.Errorf("getMilestoneByID: %v", err)
 		}
@@ -1083,10 +1108,17 @@ func newIssue(e *xorm.Session, doer *User, opts NewIssueOptions) (err error) {
 
 	// Milestone and assignee validation should happen before insert actual object.
 
+	// Indexes of deleted issues must not be handed out again
+	maxDeletedIndex, err := getMaxDeletedIssueIndex(e, opts.Issue.RepoID)
+	if err != nil {
+		return err
+	}
+	indexExpr := fmt.Sprintf("CASE WHEN coalesce(MAX(`index`),0) > %[1]d THEN coalesce(MAX(`index`),0) ELSE %[1]d END+1", maxDeletedIndex)
+
 	// There's no good way to identify a duplicate key error in database/sql; brute force some retries
 	dupIndexAttempts := issueMaxDupIndexAttempts
 	for {
-		_, err := e.SetExpr("`index`", "coalesce(MAX(`index`),0)+1").
+		_, err := e.SetExpr("`index`", indexExpr).
 			Where("repo_id=?", opts.Issue.RepoID).
 			Insert(opts.Issue)
 		if err == nil {
@@ -1138,6 +1170,10 @@ func newIssue(e *xorm.Session, doer *User, opts NewIssueOptions) (err error) {
 			return fmt.Errorf("find all labels [label_ids: %v]: %v", opts.LabelIDs, err)
 		}
 
+		if err = checkExclusiveLabels(labels); err != nil {
+			return err
+		}
+
 		if err = opts.Issue.loadPoster(e); err != nil {
 			return err
 		}
@@ -1172,6 +1208,11 @@ func newIssue(e *xorm.Session, doer *User, opts NewIssueOptions) (err error) {
 		}
 	}
 
+	opts.Issue.Repo = opts.Repo
+	if err = opts.Issue.addCrossReferences(e, doer, nil); err != nil {
+		return fmt.Errorf("addCrossReferences: %v", err)
+	}
+
 	return opts.Issue.loadAttributes(e)
 }
 
@@ -1230,11 +1271,15 @@ func NewIssue(repo *Repository, issue *Issue, labelIDs []int64, assigneeIDs []in
 
 // GetIssueByIndex returns raw issue without loading attributes by index in a repository.
 func GetIssueByIndex(repoID, index int64) (*Issue, error) {
+	return getIssueByIndex(x, repoID, index)
+}
+
+func getIssueByIndex(e Engine, repoID, index int64) (*Issue, error) {
 	issue := &Issue{
 		RepoID: repoID,
 		Index:  index,
 	}
-	has, err := x.Get(issue)
+	has, err := e.Get(issue)
 	if err != nil {
 		return nil, err
 	} else if !has {
@@ -1312,6 +1357,7 @@ type IssuesOptions struct {
 	LabelIDs    []int64
 	SortType    string
 	IssueIDs    []int64
+	Filters     *IssueSearchFilters
 }
 
 // sortIssuesSession sort an issues-related session based on the provided
@@ -1334,6 +1380,11 @@ func sortIssuesSession(sess *xorm.Session, sortType string) {
 		sess.Asc("issue.deadline_unix")
 	case "farduedate":
 		sess.Desc("issue.deadline_unix")
+	case "labelpriority":
+		// Issues without labels come last, the others by their highest label priority
+		sess.OrderBy("CASE WHEN EXISTS (SELECT 1 FROM issue_label WHERE issue_label.issue_id = issue.id) THEN 0 ELSE 1 END, " +
+			"(SELECT MAX(label.priority) FROM label INNER JOIN issue_label ON issue_label.label_id = label.id WHERE issue_label.issue_id = issue.id) DESC").
+			Desc("issue.created_unix")
 	default:
 		sess.Desc("issue.created_unix")
 	}
@@ -1398,6 +1449,10 @@ func (opts *IssuesOptions) setupSession(sess *xorm.Session) {
 				fmt.Sprintf("issue.id = il%[1]d.issue_id AND il%[1]d.label_id = %[2]d", i, labelID))
 		}
 	}
+
+	if !opts.Filters.IsEmpty() {
+		sess.And(opts.Filters.toCond())
+	}
 }
 
 // CountIssuesByRepo map from repoID to number of issues matching the options
@@ -1551,6 +1606,7 @@ type IssueStatsOptions struct {
 	PosterID    int64
 	IsPull      util.OptionalBool
 	IssueIDs    []int64
+	Filters     *IssueSearchFilters
 }
 
 // GetIssueStats returns issue statistic information by given conditions.
@@ -1603,6 +1659,10 @@ func GetIssueStats(opts *IssueStatsOptions) (*IssueStats, error) {
 			sess.And("issue.is_pull=?", false)
 		}
 
+		if !opts.Filters.IsEmpty() {
+			sess.And(opts.Filters.toCond())
+		}
+
 		return sess
 	}
 
@@ -1775,6 +1835,28 @@ func SearchIssueIDsByKeyword(kw string, repoID int64, limit, start int) (int64,
 	return total, ids, nil
 }
 
+// SearchIssueIDsByTitleWords returns the ids of the issues of a repository whose
+// title contains any of the words of the given title with at least three characters,
+// the most recently updated issues come first.
+func SearchIssueIDsByTitleWords(title string, repoID int64, limit int) ([]int64, error) {
+	var wordsCond = builder.NewCond()
+	for _, word := range strings.Fields(title) {
+		if len([]rune(word)) >= 3 {
+			wordsCond = wordsCond.Or(builder.Like{"name", word})
+		}
+	}
+
+	var ids = make([]int64, 0, limit)
+	if !wordsCond.IsValid() {
+		return ids, nil
+	}
+	return ids, x.Table("issue").Cols("id").
+		Where(builder.Eq{"repo_id": repoID}.And(wordsCond)).
+		Desc("updated_unix", "id").
+		Limit(limit).
+		Find(&ids)
+}
+
 func updateIssue(e Engine, issue *Issue) error {
 	_, err := e.ID(issue.ID).AllCols().Update(issue)
 	if err != nil {
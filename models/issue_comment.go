@@ -81,8 +81,59 @@ const (
 	CommentTypeLock
 	// Unlocks a previously locked issue
 	CommentTypeUnlock
+	// Issue was moved here from another repository
+	CommentTypeIssueMovedFrom
+	// Issue was moved to another repository
+	CommentTypeIssueMovedTo
+	// Review of the pull request was requested
+	CommentTypeReviewRequest
+	// Approval of the pull request was dismissed
+	CommentTypeDismissReview
+	// Base branch of the pull request was changed
+	CommentTypeChangeTargetBranch
 )
 
+var commentStrings = []string{
+	"comment",
+	"reopen",
+	"close",
+	"issue_ref",
+	"commit_ref",
+	"comment_ref",
+	"pull_ref",
+	"label",
+	"milestone",
+	"assignees",
+	"change_title",
+	"delete_branch",
+	"start_tracking",
+	"stop_tracking",
+	"add_time_manual",
+	"cancel_tracking",
+	"added_deadline",
+	"modified_deadline",
+	"removed_deadline",
+	"add_dependency",
+	"remove_dependency",
+	"code",
+	"review",
+	"lock",
+	"unlock",
+	"issue_moved_from",
+	"issue_moved_to",
+	"review_request",
+	"dismiss_review",
+	"change_target_branch",
+}
+
+// String returns the name of the comment type, which is used by the API.
+func (t CommentType) String() string {
+	if t < 0 || int(t) >= len(commentStrings) {
+		return "unknown"
+	}
+	return commentStrings[t]
+}
+
 // CommentTag defines comment tag type
 type CommentTag int
 
@@ -115,11 +166,14 @@ type Comment struct {
 	Assignee         *User `xorm:"-"`
 	OldTitle         string
 	NewTitle         string
+	OldRef           string
+	NewRef           string
 	DependentIssueID int64
 	DependentIssue   *Issue `xorm:"-"`
 
 	CommitID        int64
 	Line            int64 // - previous line / + proposed line
+	StartLine       int64 // first line of a multi-line code comment on the same side, 0 otherwise
 	TreePath        string
 	Content         string `xorm:"TEXT"`
 	RenderedContent string `xorm:"-"`
@@ -133,6 +187,14 @@ type Comment struct {
 	// Reference issue in commit message
 	CommitSHA string `xorm:"VARCHAR(40)"`
 
+	// Reference issue, pull request or comment which mentions the issue
+	RefRepoID    int64 `xorm:"index"`
+	RefIssueID   int64 `xorm:"index"`
+	RefCommentID int64 `xorm:"index"`
+	RefIsPull    bool
+	RefRepo      *Repository `xorm:"-"`
+	RefIssue     *Issue      `xorm:"-"`
+
 	Attachments []*Attachment `xorm:"-"`
 	Reactions   ReactionList  `xorm:"-"`
 
@@ -142,6 +204,10 @@ type Comment struct {
 	Review      *Review `xorm:"-"`
 	ReviewID    int64   `xorm:"index"`
 	Invalidated bool
+
+	// ResolveDoerID is the user who marked the conversation of the code comment as resolved
+	ResolveDoerID int64
+	ResolveDoer   *User `xorm:"-"`
 }
 
 // LoadIssue loads issue from database
@@ -266,6 +332,36 @@ func (c *Comment) APIFormat() *api.Comment {
 	}
 }
 
+// APIFormatReviewComment converts a code Comment to the api.PullReviewComment format
+func (c *Comment) APIFormatReviewComment() *api.PullReviewComment {
+	apiComment := &api.PullReviewComment{
+		ID:             c.ID,
+		Body:           c.Content,
+		Reviewer:       c.Poster.APIFormat(),
+		ReviewID:       c.ReviewID,
+		Path:           c.TreePath,
+		CommitID:       c.CommitSHA,
+		DiffHunk:       c.Patch,
+		HTMLURL:        c.HTMLURL(),
+		PullRequestURL: c.PRURL(),
+		Created:        c.CreatedUnix.AsTime(),
+		Updated:        c.UpdatedUnix.AsTime(),
+	}
+	if c.ResolveDoer != nil {
+		apiComment.Resolver = c.ResolveDoer.APIFormat()
+	}
+	line, startLine := int64(c.UnsignedLine()), int64(c.UnsignedStartLine())
+	if !c.IsMultiLine() {
+		startLine = 0
+	}
+	if c.Line < 0 {
+		apiComment.OldLineNum, apiComment.StartOldLineNum = line, startLine
+	} else {
+		apiComment.NewLineNum, apiComment.StartNewLineNum = line, startLine
+	}
+	return apiComment
+}
+
 // CommentHashTag returns unique hash tag for comment id.
 func CommentHashTag(id int64) string {
 	return fmt.Sprintf("issuecomment-%d", id)
@@ -379,6 +475,28 @@ func (c *Comment) LoadDepIssueDetails() (err error) {
 	return err
 }
 
+// CanSeeDependentIssue returns true if the user is allowed to read the issue
+// linked by a dependency or a move, which may be in another repository.
+func (c *Comment) CanSeeDependentIssue(user *User) (bool, error) {
+	if err := c.LoadDepIssueDetails(); err != nil {
+		if IsErrIssueNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if c.DependentIssue == nil {
+		return false, nil
+	}
+	if err := c.DependentIssue.loadRepo(x); err != nil {
+		return false, err
+	}
+	perm, err := getUserRepoPermission(x, c.DependentIssue.Repo, user)
+	if err != nil {
+		return false, err
+	}
+	return perm.CanReadIssuesOrPulls(c.DependentIssue.IsPull), nil
+}
+
 // MailParticipants sends new comment emails to repository watchers
 // and mentioned people.
 func (c *Comment) MailParticipants(opType ActionType, issue *Issue) (err error) {
@@ -392,7 +510,7 @@ func (c *Comment) mailParticipants(e Engine, opType ActionType, issue *Issue) (e
 	}
 
 	if len(c.Content) > 0 {
-		if err = mailIssueCommentToParticipants(e, issue, c.Poster, c.Content, c, mentions); err != nil {
+		if err = mailIssueCommentToParticipants(e, issue, c.Poster, opType, c.Content, c, mentions); err != nil {
 			log.Error("mailIssueCommentToParticipants: %v", err)
 		}
 	}
@@ -400,12 +518,12 @@ func (c *Comment) mailParticipants(e Engine, opType ActionType, issue *Issue) (e
 	switch opType {
 	case ActionCloseIssue:
 		ct := fmt.Sprintf("Closed #%d.", issue.Index)
-		if err = mailIssueCommentToParticipants(e, issue, c.Poster, ct, c, mentions); err != nil {
+		if err = mailIssueCommentToParticipants(e, issue, c.Poster, opType, ct, c, mentions); err != nil {
 			log.Error("mailIssueCommentToParticipants: %v", err)
 		}
 	case ActionReopenIssue:
 		ct := fmt.Sprintf("Reopened #%d.", issue.Index)
-		if err = mailIssueCommentToParticipants(e, issue, c.Poster, ct, c, mentions); err != nil {
+		if err = mailIssueCommentToParticipants(e, issue, c.Poster, opType, ct, c, mentions); err != nil {
 			log.Error("mailIssueCommentToParticipants: %v", err)
 		}
 	}
@@ -486,6 +604,57 @@ func (c *Comment) UnsignedLine() uint64 {
 	return uint64(c.Line)
 }
 
+// IsResolved returns true if the conversation of the code comment has been marked as resolved
+func (c *Comment) IsResolved() bool {
+	return c.ResolveDoerID != 0
+}
+
+func (c *Comment) loadResolveDoer(e Engine) (err error) {
+	if c.ResolveDoerID == 0 || c.ResolveDoer != nil {
+		return nil
+	}
+	c.ResolveDoer, err = getUserByID(e, c.ResolveDoerID)
+	if IsErrUserNotExist(err) {
+		c.ResolveDoer = NewGhostUser()
+		err = nil
+	}
+	return err
+}
+
+// LoadResolveDoer loads the user who resolved the conversation of the code comment
+func (c *Comment) LoadResolveDoer() error {
+	return c.loadResolveDoer(x)
+}
+
+// IsMultiLine returns true if the code comment spans several lines
+func (c *Comment) IsMultiLine() bool {
+	return c.StartLine != 0 && c.StartLine != c.Line
+}
+
+// UnsignedStartLine returns the first LOC of the code comment without + or -,
+// it is the LOC of the comment if it only spans one line.
+func (c *Comment) UnsignedStartLine() uint64 {
+	if !c.IsMultiLine() {
+		return c.UnsignedLine()
+	}
+	if c.StartLine < 0 {
+		return uint64(c.StartLine * -1)
+	}
+	return uint64(c.StartLine)
+}
+
+// IsInLineRange returns true if the signed line is one of the lines commented by the code comment
+func (c *Comment) IsInLineRange(line int64) bool {
+	if line == 0 || (line < 0) != (c.Line < 0) {
+		return false
+	}
+	unsigned := uint64(line)
+	if line < 0 {
+		unsigned = uint64(line * -1)
+	}
+	return c.UnsignedStartLine() <= unsigned && unsigned <= c.UnsignedLine()
+}
+
 // CodeCommentURL returns the url to a comment in code
 func (c *Comment) CodeCommentURL() string {
 	err := c.LoadIssue()
@@ -520,13 +689,20 @@ func createComment(e *xorm.Session, opts *CreateCommentOptions) (_ *Comment, err
 		CommitID:         opts.CommitID,
 		CommitSHA:        opts.CommitSHA,
 		Line:             opts.LineNum,
+		StartLine:        opts.StartLineNum,
 		Content:          opts.Content,
 		OldTitle:         opts.OldTitle,
 		NewTitle:         opts.NewTitle,
+		OldRef:           opts.OldRef,
+		NewRef:           opts.NewRef,
 		DependentIssueID: opts.DependentIssueID,
 		TreePath:         opts.TreePath,
 		ReviewID:         opts.ReviewID,
 		Patch:            opts.Patch,
+		RefRepoID:        opts.RefRepoID,
+		RefIssueID:       opts.RefIssueID,
+		RefCommentID:     opts.RefCommentID,
+		RefIsPull:        opts.RefIsPull,
 	}
 	if _, err = e.Insert(comment); err != nil {
 		return nil, err
@@ -540,6 +716,18 @@ func createComment(e *xorm.Session, opts *CreateCommentOptions) (_ *Comment, err
 		return nil, err
 	}
 
+	if opts.Type == CommentTypeComment {
+		if err = opts.Issue.addCrossReferences(e, opts.Doer, comment); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Type == CommentTypeComment || opts.Type == CommentTypeReview {
+		if err = opts.Issue.updateFirstResponse(e, opts.Doer); err != nil {
+			return nil, err
+		}
+	}
+
 	return comment, nil
 }
 
@@ -786,14 +974,21 @@ type CreateCommentOptions struct {
 	RemovedAssignee  bool
 	OldTitle         string
 	NewTitle         string
+	OldRef           string
+	NewRef           string
 	CommitID         int64
 	CommitSHA        string
 	Patch            string
 	LineNum          int64
+	StartLineNum     int64
 	TreePath         string
 	ReviewID         int64
 	Content          string
 	Attachments      []string // UUIDs of attachments
+	RefRepoID        int64
+	RefIssueID       int64
+	RefCommentID     int64
+	RefIsPull        bool
 }
 
 // CreateComment creates comment of issue or commit.
@@ -845,8 +1040,9 @@ func CreateIssueComment(doer *User, repo *Repository, issue *Issue, content stri
 	return comment, nil
 }
 
-// CreateRefComment creates a commit reference comment to issue.
-func CreateRefComment(doer *User, repo *Repository, issue *Issue, content, commitSHA string) error {
+// CreateRefComment creates a commit reference comment to issue, commitRepoID
+// is the ID of the repository of the commit.
+func CreateRefComment(doer *User, repo *Repository, issue *Issue, content, commitSHA string, commitRepoID int64) error {
 	if len(commitSHA) == 0 {
 		return fmt.Errorf("cannot create reference with empty commit SHA")
 	}
@@ -870,6 +1066,7 @@ func CreateRefComment(doer *User, repo *Repository, issue *Issue, content, commi
 		Issue:     issue,
 		CommitSHA: commitSHA,
 		Content:   content,
+		RefRepoID: commitRepoID,
 	})
 	return err
 }
@@ -893,6 +1090,10 @@ type FindCommentsOptions struct {
 	ReviewID int64
 	Since    int64
 	Type     CommentType
+	// Page and PageSize select a page of the comments, all of them are
+	// returned if PageSize is not positive
+	Page     int
+	PageSize int
 }
 
 func (opts *FindCommentsOptions) toConds() builder.Cond {
@@ -921,6 +1122,12 @@ func findComments(e Engine, opts FindCommentsOptions) ([]*Comment, error) {
 	if opts.RepoID > 0 {
 		sess.Join("INNER", "issue", "issue.id = comment.issue_id")
 	}
+	if opts.PageSize > 0 {
+		if opts.Page <= 0 {
+			opts.Page = 1
+		}
+		sess.Limit(opts.PageSize, (opts.Page-1)*opts.PageSize)
+	}
 	return comments, sess.
 		Asc("comment.created_unix").
 		Asc("comment.id").
@@ -934,10 +1141,33 @@ func FindComments(opts FindCommentsOptions) ([]*Comment, error) {
 
 // UpdateComment updates information of comment.
 func UpdateComment(doer *User, c *Comment, oldContent string) error {
-	if _, err := x.ID(c.ID).AllCols().Update(c); err != nil {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
 		return err
 	}
 
+	if _, err := sess.ID(c.ID).AllCols().Update(c); err != nil {
+		return err
+	}
+	if err := saveIssueContentHistory(sess, doer, c.IssueID, c.ID, c.PosterID, c.CreatedUnix, oldContent, c.Content); err != nil {
+		return fmt.Errorf("saveIssueContentHistory: %v", err)
+	}
+	if c.Type == CommentTypeComment {
+		issue, err := getIssueByID(sess, c.IssueID)
+		if err != nil {
+			return err
+		}
+		if err = issue.addCrossReferences(sess, doer, c); err != nil {
+			return fmt.Errorf("addCrossReferences: %v", err)
+		}
+	}
+
+	if err := sess.Commit(); err != nil {
+		return err
+	}
+	sess.Close()
+
 	if err := c.LoadPoster(); err != nil {
 		return err
 	}
@@ -995,6 +1225,12 @@ func DeleteComment(doer *User, comment *Comment) error {
 	if _, err := sess.Where("comment_id = ?", comment.ID).Cols("is_deleted").Update(&Action{IsDeleted: true}); err != nil {
 		return err
 	}
+	if _, err := sess.Where("comment_id = ?", comment.ID).Delete(new(IssueContentHistory)); err != nil {
+		return err
+	}
+	if err := deleteCommentCrossReferences(sess, comment.ID); err != nil {
+		return err
+	}
 
 	if err := sess.Commit(); err != nil {
 		return err
@@ -1097,8 +1333,11 @@ func fetchCodeCommentsByReview(e Engine, issue *Issue, currentUser *User, review
 			}
 			comment.Review = re
 		}
+		if err := comment.loadResolveDoer(e); err != nil {
+			return nil, err
+		}
 
-		comment.RenderedContent = string(markdown.Render([]byte(comment.Content), issue.Repo.Link(),
+		comment.RenderedContent = string(markdown.Render([]byte(comment.ContentWithoutSuggestion()), issue.Repo.Link(),
 			issue.Repo.ComposeMetas()))
 		if pathToLineToComment[comment.TreePath] == nil {
 			pathToLineToComment[comment.TreePath] = make(map[int64][]*Comment)
@@ -0,0 +1,64 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// suggestionPattern matches a ```suggestion block of a code comment
+var suggestionPattern = regexp.MustCompile("(?ms)^```suggestion[ \t]*\r?\n(.*?)^```[ \t]*\r?$")
+
+// Suggestion represents a replacement of the commented line which has been
+// suggested by a code comment.
+type Suggestion struct {
+	// Original is the commented line
+	Original string
+	// Replacement are the lines replacing the commented line, the line is
+	// removed if there is no replacement.
+	Replacement []string
+}
+
+// Suggestion returns the change suggested by the code comment, or nil if the
+// comment has no suggestion or does not comment on a line of the new file.
+// Only the first suggestion of a comment is used.
+func (c *Comment) Suggestion() *Suggestion {
+	if c.Type != CommentTypeCode || c.Line <= 0 || len(c.Patch) == 0 {
+		return nil
+	}
+	match := suggestionPattern.FindStringSubmatch(c.Content)
+	if match == nil {
+		return nil
+	}
+
+	// The patch of a code comment ends with the commented line
+	patchLines := strings.Split(strings.TrimRight(c.Patch, "\n"), "\n")
+	original := patchLines[len(patchLines)-1]
+	if len(original) == 0 || (original[0] != '+' && original[0] != ' ') {
+		return nil
+	}
+
+	suggestion := &Suggestion{
+		Original:    strings.TrimSuffix(original[1:], "\r"),
+		Replacement: make([]string, 0, 5),
+	}
+	if len(match[1]) > 0 {
+		for _, line := range strings.Split(strings.TrimSuffix(match[1], "\n"), "\n") {
+			suggestion.Replacement = append(suggestion.Replacement, strings.TrimSuffix(line, "\r"))
+		}
+	}
+	return suggestion
+}
+
+// ContentWithoutSuggestion returns the content of the comment without its
+// suggestion, which is rendered as a diff instead.
+func (c *Comment) ContentWithoutSuggestion() string {
+	if c.Suggestion() == nil {
+		return c.Content
+	}
+	loc := suggestionPattern.FindStringIndex(c.Content)
+	return strings.TrimSpace(c.Content[:loc[0]] + c.Content[loc[1]:])
+}
@@ -0,0 +1,55 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComment_Suggestion(t *testing.T) {
+	patch := "diff --git a/README.md b/README.md\n--- a/README.md\n+++ b/README.md\n@@ -1,2 +1,2 @@\n # repo1\n+Descriptio for repo1\n"
+	comment := &Comment{
+		Type:    CommentTypeCode,
+		Line:    2,
+		Patch:   patch,
+		Content: "Typo:\n```suggestion\nDescription for repo1\n```\n",
+	}
+	suggestion := comment.Suggestion()
+	if assert.NotNil(t, suggestion) {
+		assert.Equal(t, "Descriptio for repo1", suggestion.Original)
+		assert.Equal(t, []string{"Description for repo1"}, suggestion.Replacement)
+	}
+	assert.Equal(t, "Typo:", comment.ContentWithoutSuggestion())
+
+	// An empty suggestion removes the line
+	comment.Content = "```suggestion\n```"
+	suggestion = comment.Suggestion()
+	if assert.NotNil(t, suggestion) {
+		assert.Len(t, suggestion.Replacement, 0)
+	}
+	assert.Equal(t, "", comment.ContentWithoutSuggestion())
+
+	// Multiple lines with CRLF line endings
+	comment.Content = "```suggestion\r\nfirst\r\nsecond\r\n```\r\n"
+	suggestion = comment.Suggestion()
+	if assert.NotNil(t, suggestion) {
+		assert.Equal(t, []string{"first", "second"}, suggestion.Replacement)
+	}
+
+	// Suggestions are only supported on lines of the new file
+	comment.Line = -2
+	assert.Nil(t, comment.Suggestion())
+
+	comment.Line = 2
+	comment.Content = "```go\nfmt.Println()\n```"
+	assert.Nil(t, comment.Suggestion())
+	assert.Equal(t, comment.Content, comment.ContentWithoutSuggestion())
+
+	comment.Type = CommentTypeComment
+	comment.Content = "```suggestion\nfirst\n```"
+	assert.Nil(t, comment.Suggestion())
+}
@@ -40,6 +40,34 @@ func TestCreateComment(t *testing.T) {
 	AssertInt64InRange(t, now, then, int64(updatedIssue.UpdatedUnix))
 }
 
+func TestFindComments_Page(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	for _, test := range []struct {
+		Page, PageSize     int
+		ExpectedCommentIDs []int64
+	}{
+		{0, 0, []int64{2, 3}},
+		{1, 1, []int64{2}},
+		{2, 1, []int64{3}},
+		{3, 1, []int64{}},
+		{0, 5, []int64{2, 3}},
+	} {
+		comments, err := FindComments(FindCommentsOptions{
+			IssueID:  1,
+			Type:     CommentTypeComment,
+			Page:     test.Page,
+			PageSize: test.PageSize,
+		})
+		assert.NoError(t, err)
+		if assert.Len(t, comments, len(test.ExpectedCommentIDs)) {
+			for i, comment := range comments {
+				assert.EqualValues(t, test.ExpectedCommentIDs[i], comment.ID)
+			}
+		}
+	}
+}
+
 func TestFetchCodeComments(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 
@@ -57,3 +85,24 @@ func TestFetchCodeComments(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, res, 1)
 }
+
+func TestComment_IsInLineRange(t *testing.T) {
+	comment := &Comment{Line: 8, StartLine: 5}
+	assert.True(t, comment.IsMultiLine())
+	assert.EqualValues(t, 5, comment.UnsignedStartLine())
+	assert.True(t, comment.IsInLineRange(5))
+	assert.True(t, comment.IsInLineRange(8))
+	assert.False(t, comment.IsInLineRange(4))
+	assert.False(t, comment.IsInLineRange(9))
+	assert.False(t, comment.IsInLineRange(-6))
+
+	comment = &Comment{Line: -8, StartLine: -5}
+	assert.True(t, comment.IsInLineRange(-6))
+	assert.False(t, comment.IsInLineRange(6))
+
+	comment = &Comment{Line: 8}
+	assert.False(t, comment.IsMultiLine())
+	assert.EqualValues(t, 8, comment.UnsignedStartLine())
+	assert.True(t, comment.IsInLineRange(8))
+	assert.False(t, comment.IsInLineRange(7))
+}
@@ -0,0 +1,157 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// IssueContentHistory represents a revision of the content of an issue or
+// comment. The revision with IsFirstCreated set holds the content the issue
+// or comment has been created with.
+type IssueContentHistory struct {
+	ID             int64              `xorm:"pk autoincr"`
+	PosterID       int64              `xorm:"INDEX"`
+	Poster         *User              `xorm:"-"`
+	IssueID        int64              `xorm:"INDEX"`
+	CommentID      int64              `xorm:"INDEX"` // 0 for the content of the issue itself
+	EditedUnix     timeutil.TimeStamp `xorm:"INDEX"`
+	ContentText    string             `xorm:"LONGTEXT"`
+	IsFirstCreated bool
+}
+
+// LoadPoster loads the user who has written the revision
+func (h *IssueContentHistory) LoadPoster() (err error) {
+	if h.Poster != nil {
+		return nil
+	}
+	h.Poster, err = getUserByID(x, h.PosterID)
+	if err != nil {
+		if !IsErrUserNotExist(err) {
+			return err
+		}
+		h.PosterID = -1
+		h.Poster = NewGhostUser()
+	}
+	return nil
+}
+
+// APIFormat converts a IssueContentHistory to api.IssueContentRevision
+func (h *IssueContentHistory) APIFormat() *api.IssueContentRevision {
+	return &api.IssueContentRevision{
+		ID:      h.ID,
+		Editor:  h.Poster.APIFormat(),
+		Body:    h.ContentText,
+		IsFirst: h.IsFirstCreated,
+		Created: h.EditedUnix.AsTime(),
+	}
+}
+
+// GetPrevious returns the revision this revision has been edited from,
+// nil is returned for the first revision.
+func (h *IssueContentHistory) GetPrevious() (*IssueContentHistory, error) {
+	prev := new(IssueContentHistory)
+	has, err := x.Where("issue_id=? AND comment_id=? AND id<?", h.IssueID, h.CommentID, h.ID).
+		Desc("id").Get(prev)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return prev, nil
+}
+
+// saveIssueContentHistory records a new revision of an issue or comment. On
+// the first edit the content it has been created with is recorded as well.
+func saveIssueContentHistory(e Engine, doer *User, issueID, commentID, origPosterID int64, origCreated timeutil.TimeStamp, oldContent, newContent string) error {
+	if oldContent == newContent {
+		return nil
+	}
+
+	has, err := e.Where("issue_id=? AND comment_id=?", issueID, commentID).Exist(new(IssueContentHistory))
+	if err != nil {
+		return err
+	}
+	if !has {
+		if _, err = e.Insert(&IssueContentHistory{
+			PosterID:       origPosterID,
+			IssueID:        issueID,
+			CommentID:      commentID,
+			EditedUnix:     origCreated,
+			ContentText:    oldContent,
+			IsFirstCreated: true,
+		}); err != nil {
+			return err
+		}
+	}
+
+	_, err = e.Insert(&IssueContentHistory{
+		PosterID:    doer.ID,
+		IssueID:     issueID,
+		CommentID:   commentID,
+		EditedUnix:  timeutil.TimeStampNow(),
+		ContentText: newContent,
+	})
+	return err
+}
+
+// GetIssueContentHistoryByID returns the revision with the given ID
+func GetIssueContentHistoryByID(id int64) (*IssueContentHistory, error) {
+	h := new(IssueContentHistory)
+	has, err := x.ID(id).Get(h)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrIssueContentHistoryNotExist{id}
+	}
+	return h, nil
+}
+
+// GetIssueContentHistoryList returns the revisions of an issue or, if
+// commentID is not 0, of a comment, newest first.
+func GetIssueContentHistoryList(issueID, commentID int64) ([]*IssueContentHistory, error) {
+	list := make([]*IssueContentHistory, 0, 5)
+	if err := x.Where("issue_id=? AND comment_id=?", issueID, commentID).
+		Desc("id").Find(&list); err != nil {
+		return nil, err
+	}
+	for _, h := range list {
+		if err := h.LoadPoster(); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// GetIssueContentHistoryCounts returns the number of edits of the issue and its
+// comments, keyed by comment ID where the issue itself has the key 0.
+func GetIssueContentHistoryCounts(issueID int64) (map[int64]int, error) {
+	type historyCount struct {
+		CommentID int64
+		Count     int
+	}
+	counts := make([]historyCount, 0, 5)
+	if err := x.Table("issue_content_history").
+		Select("comment_id, count(*) AS count").
+		Where("issue_id=? AND is_first_created=?", issueID, false).
+		GroupBy("comment_id").
+		Find(&counts); err != nil {
+		return nil, err
+	}
+
+	res := make(map[int64]int, len(counts))
+	for _, c := range counts {
+		res[c.CommentID] = c.Count
+	}
+	return res, nil
+}
+
+// PurgeIssueContentHistory deletes all revisions of an issue or, if
+// commentID is not 0, of a comment.
+func PurgeIssueContentHistory(issueID, commentID int64) error {
+	_, err := x.Where("issue_id=? AND comment_id=?", issueID, commentID).Delete(new(IssueContentHistory))
+	return err
+}
@@ -0,0 +1,56 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueContentHistory(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.NoError(t, issue.LoadAttributes())
+	origContent := issue.Content
+
+	assert.NoError(t, issue.ChangeContent(doer, "first edit"))
+	assert.NoError(t, issue.ChangeContent(doer, "second edit"))
+	// unchanged content does not create a revision
+	assert.NoError(t, issue.ChangeContent(doer, "second edit"))
+
+	list, err := GetIssueContentHistoryList(issue.ID, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, list, 3) {
+		assert.Equal(t, "second edit", list[0].ContentText)
+		assert.Equal(t, "first edit", list[1].ContentText)
+		assert.Equal(t, origContent, list[2].ContentText)
+		assert.True(t, list[2].IsFirstCreated)
+		assert.EqualValues(t, issue.PosterID, list[2].PosterID)
+
+		prev, err := list[0].GetPrevious()
+		assert.NoError(t, err)
+		assert.EqualValues(t, list[1].ID, prev.ID)
+		prev, err = list[2].GetPrevious()
+		assert.NoError(t, err)
+		assert.Nil(t, prev)
+	}
+
+	comment := AssertExistsAndLoadBean(t, &Comment{ID: 2}).(*Comment)
+	oldContent := comment.Content
+	comment.Content = "edited comment"
+	assert.NoError(t, UpdateComment(doer, comment, oldContent))
+
+	counts, err := GetIssueContentHistoryCounts(issue.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]int{0: 2, comment.ID: 1}, counts)
+
+	// purging the history of the issue keeps the one of the comment
+	assert.NoError(t, PurgeIssueContentHistory(issue.ID, 0))
+	AssertNotExistsBean(t, &IssueContentHistory{IssueID: issue.ID, CommentID: 0}, "comment_id = 0")
+	AssertExistsAndLoadBean(t, &IssueContentHistory{IssueID: issue.ID, CommentID: comment.ID, IsFirstCreated: true})
+}
@@ -0,0 +1,110 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"xorm.io/builder"
+)
+
+// conversationCond returns the condition of the code comments which belong
+// to the same conversation as the comment, i.e. the comments of the same line.
+func conversationCond(comment *Comment) builder.Cond {
+	return builder.Eq{
+		"issue_id":    comment.IssueID,
+		"type":        CommentTypeCode,
+		"tree_path":   comment.TreePath,
+		"line":        comment.Line,
+		"invalidated": false,
+	}
+}
+
+// MarkConversation marks the conversation of the code comment as resolved by
+// the doer or as unresolved.
+func MarkConversation(comment *Comment, doer *User, isResolve bool) error {
+	if comment.Type != CommentTypeCode {
+		return fmt.Errorf("comment %d is not a code comment", comment.ID)
+	}
+
+	var resolveDoerID int64
+	if isResolve {
+		resolveDoerID = doer.ID
+	}
+	if _, err := x.Table("comment").
+		Where(conversationCond(comment)).
+		Update(map[string]interface{}{"resolve_doer_id": resolveDoerID}); err != nil {
+		return err
+	}
+	comment.ResolveDoerID = resolveDoerID
+	comment.ResolveDoer = nil
+	return nil
+}
+
+// CanMarkConversation returns true if the doer can mark the conversations of
+// the pull request as resolved, which are the poster of the pull request and
+// the users who can write to the pull requests of the repository.
+func CanMarkConversation(issue *Issue, doer *User) (bool, error) {
+	if doer == nil || issue == nil || !issue.IsPull {
+		return false, nil
+	}
+	if doer.ID == issue.PosterID {
+		return true, nil
+	}
+	if err := issue.LoadRepo(); err != nil {
+		return false, err
+	}
+	perm, err := GetUserRepoPermission(issue.Repo, doer)
+	if err != nil {
+		return false, err
+	}
+	return perm.CanAccess(AccessModeWrite, UnitTypePullRequests), nil
+}
+
+// CountUnresolvedConversations returns the number of conversations of the
+// pull request which have not been marked as resolved. A conversation is
+// resolved if its first comment is, comments of pending reviews and outdated
+// comments are not taken into account.
+func CountUnresolvedConversations(issue *Issue) (int64, error) {
+	comments := make([]*Comment, 0, 10)
+	if err := x.Where(builder.Eq{
+		"issue_id":    issue.ID,
+		"type":        CommentTypeCode,
+		"invalidated": false,
+	}).
+		Asc("created_unix").
+		Asc("id").
+		Find(&comments); err != nil {
+		return 0, err
+	}
+
+	reviewIDs := make([]int64, 0, len(comments))
+	for _, comment := range comments {
+		if comment.ReviewID != 0 {
+			reviewIDs = append(reviewIDs, comment.ReviewID)
+		}
+	}
+	reviews := make(map[int64]*Review, len(reviewIDs))
+	if err := x.In("id", reviewIDs).Find(&reviews); err != nil {
+		return 0, err
+	}
+
+	var count int64
+	seen := make(map[string]bool, len(comments))
+	for _, comment := range comments {
+		if review, ok := reviews[comment.ReviewID]; ok && review.Type == ReviewTypePending {
+			continue
+		}
+		key := fmt.Sprintf("%s:%d", comment.TreePath, comment.Line)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if !comment.IsResolved() {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkConversation(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 2}).(*Issue)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	comment := AssertExistsAndLoadBean(t, &Comment{ID: 5}).(*Comment)
+
+	// Comments of pending reviews and outdated comments are not counted
+	count, err := CountUnresolvedConversations(issue)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	assert.NoError(t, MarkConversation(comment, doer, true))
+	assert.True(t, comment.IsResolved())
+	AssertExistsAndLoadBean(t, &Comment{ID: 5, ResolveDoerID: doer.ID})
+	outdated := AssertExistsAndLoadBean(t, &Comment{ID: 6}).(*Comment)
+	assert.False(t, outdated.IsResolved())
+	count, err = CountUnresolvedConversations(issue)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+
+	assert.NoError(t, MarkConversation(comment, doer, false))
+	assert.False(t, comment.IsResolved())
+	AssertExistsAndLoadBean(t, &Comment{ID: 5}, "resolve_doer_id = 0")
+	count, err = CountUnresolvedConversations(issue)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+}
+
+func TestCanMarkConversation(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 2}).(*Issue)
+	for userID, expected := range map[int64]bool{1: true, 2: true, 4: false} {
+		user := AssertExistsAndLoadBean(t, &User{ID: userID}).(*User)
+		canMark, err := CanMarkConversation(issue, user)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, canMark, "user %d", userID)
+	}
+
+	canMark, err := CanMarkConversation(issue, nil)
+	assert.NoError(t, err)
+	assert.False(t, canMark)
+}
@@ -0,0 +1,193 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/go-xorm/xorm"
+	"xorm.io/builder"
+)
+
+// DeletedIssue keeps a record of an issue or pull request which has been
+// deleted permanently. It serves as audit log and makes sure the index of
+// the deleted issue is never handed out again.
+type DeletedIssue struct {
+	ID          int64 `xorm:"pk autoincr"`
+	RepoID      int64 `xorm:"INDEX"`
+	Index       int64
+	IsPull      bool
+	Title       string `xorm:"name"`
+	PosterID    int64
+	DeletedByID int64
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+func getMaxDeletedIssueIndex(e Engine, repoID int64) (int64, error) {
+	var maxIndex int64
+	if _, err := e.Table("deleted_issue").Select("coalesce(MAX(`index`),0)").
+		Where("repo_id=?", repoID).Get(&maxIndex); err != nil {
+		return 0, err
+	}
+	return maxIndex, nil
+}
+
+// DeleteIssue deletes an issue or pull request permanently together with its
+// comments, attachments, reactions and all references from other issues.
+// The index is not reused by issues created later on.
+func DeleteIssue(doer *User, issue *Issue) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	attachmentPaths, err := deleteIssue(sess, doer, issue)
+	if err != nil {
+		return err
+	}
+
+	if err = sess.Commit(); err != nil {
+		return fmt.Errorf("Commit: %v", err)
+	}
+
+	for i := range attachmentPaths {
+		removeStorageWithNotice(x, storage.Attachments, "Delete issue attachment", attachmentPaths[i])
+	}
+
+	if issue.IsPull {
+		refName := fmt.Sprintf("refs/pull/%d/head", issue.Index)
+		if _, err := git.NewCommand("update-ref", "-d", refName).RunInDir(issue.Repo.RepoPath()); err != nil {
+			log.Error("Delete reference %s of %s: %v", refName, issue.Repo.FullName(), err)
+		}
+	}
+
+	log.Info("Issue #%d of repository %s has been deleted by %s", issue.Index, issue.Repo.FullName(), doer.Name)
+	return nil
+}
+
+func deleteIssue(e *xorm.Session, doer *User, issue *Issue) ([]string, error) {
+	if err := issue.loadRepo(e); err != nil {
+		return nil, err
+	}
+
+	if err := issue.getLabels(e); err != nil {
+		return nil, err
+	}
+	for _, label := range issue.Labels {
+		label.NumIssues--
+		if issue.IsClosed {
+			label.NumClosedIssues--
+		}
+		if err := updateLabel(e, label); err != nil {
+			return nil, err
+		}
+	}
+
+	if issue.MilestoneID > 0 {
+		m, err := getAssignableMilestone(e, issue.RepoID, issue.MilestoneID)
+		if err != nil && !IsErrMilestoneNotExist(err) {
+			return nil, err
+		} else if err == nil {
+			m.NumIssues--
+			if issue.IsClosed {
+				m.NumClosedIssues--
+			}
+			if err = updateMilestone(e, m); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	attachments := make([]*Attachment, 0, 5)
+	if err := e.Where("issue_id=?", issue.ID).Find(&attachments); err != nil {
+		return nil, err
+	}
+	attachmentPaths := make([]string, 0, len(attachments))
+	for _, a := range attachments {
+		attachmentPaths = append(attachmentPaths, a.RelativePath())
+	}
+
+	// Remove the data of the pull request which is keyed by its own ID
+	pullCond := builder.Select("id").From("pull_request").Where(builder.Eq{"issue_id": issue.ID})
+	if _, err := e.In("pull_id", pullCond).Delete(new(PullViewedFile)); err != nil {
+		return nil, err
+	}
+	if _, err := e.In("pull_id", pullCond).Delete(new(MergeQueueEntry)); err != nil {
+		return nil, err
+	}
+	if _, err := e.In("pull_id", pullCond).Delete(new(PullAutoMerge)); err != nil {
+		return nil, err
+	}
+
+	if err := deleteBeans(e,
+		&Comment{IssueID: issue.ID},
+		&Attachment{IssueID: issue.ID},
+		&IssueLabel{IssueID: issue.ID},
+		&IssueAssignees{IssueID: issue.ID},
+		&IssueUser{IssueID: issue.ID},
+		&IssueWatch{IssueID: issue.ID},
+		&Reaction{IssueID: issue.ID},
+		&Stopwatch{IssueID: issue.ID},
+		&TrackedTime{IssueID: issue.ID},
+		&Notification{IssueID: issue.ID},
+		&Review{IssueID: issue.ID},
+		&PullRequest{IssueID: issue.ID},
+		&IssueRedirect{RedirectIssueID: issue.ID},
+		&IssueContentHistory{IssueID: issue.ID},
+		&IssueReminder{IssueID: issue.ID},
+	); err != nil {
+		return nil, fmt.Errorf("deleteBeans: %v", err)
+	}
+
+	// Remove the references from other issues.
+	if _, err := e.Where("issue_id=? OR dependency_id=?", issue.ID, issue.ID).
+		Delete(new(IssueDependency)); err != nil {
+		return nil, err
+	}
+
+	// Remove the cross references to other issues.
+	if _, err := e.Where("ref_issue_id=?", issue.ID).Delete(new(Comment)); err != nil {
+		return nil, err
+	}
+	if _, err := e.Where("dependent_issue_id=?", issue.ID).Delete(new(Comment)); err != nil {
+		return nil, err
+	}
+
+	if _, err := e.ID(issue.ID).Delete(new(Issue)); err != nil {
+		return nil, err
+	}
+
+	if issue.IsPull {
+		if _, err := e.Exec("UPDATE `repository` SET num_pulls = num_pulls - 1 WHERE id = ?", issue.RepoID); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := e.Exec("UPDATE `repository` SET num_issues = num_issues - 1 WHERE id = ?", issue.RepoID); err != nil {
+			return nil, err
+		}
+	}
+	if err := issue.updateClosedNum(e); err != nil {
+		return nil, err
+	}
+
+	if _, err := e.Insert(&DeletedIssue{
+		RepoID:      issue.RepoID,
+		Index:       issue.Index,
+		IsPull:      issue.IsPull,
+		Title:       issue.Title,
+		PosterID:    issue.PosterID,
+		DeletedByID: doer.ID,
+	}); err != nil {
+		return nil, err
+	}
+
+	return attachmentPaths, nil
+}
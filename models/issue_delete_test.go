@@ -0,0 +1,66 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteIssue(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.NoError(t, DeleteIssue(doer, issue))
+
+	AssertNotExistsBean(t, &Issue{ID: 1})
+	AssertNotExistsBean(t, &Comment{IssueID: 1})
+	AssertNotExistsBean(t, &IssueLabel{IssueID: 1})
+	AssertNotExistsBean(t, &Attachment{IssueID: 1})
+	AssertNotExistsBean(t, &Reaction{IssueID: 1})
+	AssertExistsAndLoadBean(t, &DeletedIssue{RepoID: 1, Index: 1, DeletedByID: doer.ID})
+
+	label := AssertExistsAndLoadBean(t, &Label{ID: 1}).(*Label)
+	assert.EqualValues(t, 1, label.NumIssues)
+
+	// the index of the last issue must not be handed out again
+	issue = AssertExistsAndLoadBean(t, &Issue{ID: 5}).(*Issue)
+	assert.NoError(t, DeleteIssue(doer, issue))
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	newIssue := &Issue{
+		RepoID:   repo.ID,
+		PosterID: doer.ID,
+		Title:    "newissue",
+	}
+	assert.NoError(t, NewIssue(repo, newIssue, nil, nil, nil))
+	assert.EqualValues(t, 5, newIssue.Index)
+
+	CheckConsistencyForAll(t)
+}
+
+func TestDeleteIssue_PullRequest(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 1}).(*PullRequest)
+	assert.NoError(t, SetPullFileViewed(doer.ID, pr.ID, "README.md", "d56b2d1d4bcbb8c9f5a7d8a1a2c1fbd3c4d9e2f0", true))
+	_, err := AddToMergeQueue(pr, doer, MergeStyleMerge, "")
+	assert.NoError(t, err)
+	_, err = ScheduleAutoMerge(doer, pr, MergeStyleMerge, "")
+	assert.NoError(t, err)
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: pr.IssueID}).(*Issue)
+	assert.NoError(t, DeleteIssue(doer, issue))
+
+	AssertNotExistsBean(t, &PullRequest{ID: pr.ID})
+	AssertNotExistsBean(t, &PullViewedFile{PullID: pr.ID})
+	AssertNotExistsBean(t, &MergeQueueEntry{PullID: pr.ID})
+	AssertNotExistsBean(t, &PullAutoMerge{PullID: pr.ID})
+
+	CheckConsistencyForAll(t)
+}
@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 
+	"code.gitea.io/gitea/modules/log"
 	api "code.gitea.io/gitea/modules/structs"
 
 	"github.com/go-xorm/xorm"
@@ -65,12 +66,18 @@ type Label struct {
 	Name            string
 	Description     string
 	Color           string `xorm:"VARCHAR(7)"`
+	Priority        int    `xorm:"NOT NULL DEFAULT 0"`
 	NumIssues       int
 	NumClosedIssues int
 	NumOpenIssues   int  `xorm:"-"`
 	IsChecked       bool `xorm:"-"`
 	QueryString     string
 	IsSelected      bool
+
+	// Targets for the time until the first response to and the resolution of
+	// issues with the label, 0 means no target.
+	ResponseTargetHours   int64 `xorm:"NOT NULL DEFAULT 0"`
+	ResolutionTargetHours int64 `xorm:"NOT NULL DEFAULT 0"`
 }
 
 // APIFormat converts a Label to the api.Label format
@@ -80,7 +87,41 @@ func (label *Label) APIFormat() *api.Label {
 		Name:        label.Name,
 		Color:       strings.TrimLeft(label.Color, "#"),
 		Description: label.Description,
+		Priority:    label.Priority,
+
+		ResponseTargetHours:   label.ResponseTargetHours,
+		ResolutionTargetHours: label.ResolutionTargetHours,
+	}
+}
+
+// labelScopeSeparator separates the scope from the value in the name of a scoped label
+const labelScopeSeparator = "::"
+
+// ExclusiveScope returns the scope of a label named "scope::value", or an empty
+// string if the label is not scoped. An issue can only have one label of a scope.
+func (label *Label) ExclusiveScope() string {
+	idx := strings.LastIndex(label.Name, labelScopeSeparator)
+	if idx <= 0 || idx+len(labelScopeSeparator) == len(label.Name) {
+		return ""
 	}
+	return label.Name[:idx]
+}
+
+// checkExclusiveLabels returns an error if more than one of the labels belongs
+// to the same exclusive scope.
+func checkExclusiveLabels(labels []*Label) error {
+	scopes := make(map[string]struct{}, len(labels))
+	for _, label := range labels {
+		scope := label.ExclusiveScope()
+		if len(scope) == 0 {
+			continue
+		}
+		if _, ok := scopes[scope]; ok {
+			return ErrLabelExclusiveScopeConflict{Scope: scope}
+		}
+		scopes[scope] = struct{}{}
+	}
+	return nil
 }
 
 // CalOpenIssues calculates the open issues of label.
@@ -133,8 +174,12 @@ func newLabel(e Engine, label *Label) error {
 }
 
 // NewLabel creates a new label for a repository
-func NewLabel(label *Label) error {
-	return newLabel(x, label)
+func NewLabel(label *Label, doer *User) error {
+	if err := newLabel(x, label); err != nil {
+		return err
+	}
+	prepareLabelWebhooks(label, doer, api.HookLabelCreated, nil)
+	return nil
 }
 
 // NewLabels creates new labels for a repository.
@@ -245,6 +290,8 @@ func GetLabelsByRepoID(repoID int64, sortType string) ([]*Label, error) {
 		sess.Asc("num_issues")
 	case "mostissues":
 		sess.Desc("num_issues")
+	case "priority":
+		sess.Desc("priority").Asc("name")
 	default:
 		sess.Asc("name")
 	}
@@ -256,6 +303,7 @@ func getLabelsByIssueID(e Engine, issueID int64) ([]*Label, error) {
 	var labels []*Label
 	return labels, e.Where("issue_label.issue_id = ?", issueID).
 		Join("LEFT", "issue_label", "issue_label.label_id = label.id").
+		Desc("label.priority").
 		Asc("label.name").
 		Find(&labels)
 }
@@ -271,13 +319,32 @@ func updateLabel(e Engine, l *Label) error {
 }
 
 // UpdateLabel updates label information.
-func UpdateLabel(l *Label) error {
-	return updateLabel(x, l)
+func UpdateLabel(l *Label, doer *User) error {
+	old, err := GetLabelByID(l.ID)
+	if err != nil {
+		return err
+	}
+	if err = updateLabel(x, l); err != nil {
+		return err
+	}
+
+	var changes *api.ChangesPayload
+	if old.Name != l.Name || old.Color != l.Color {
+		changes = &api.ChangesPayload{}
+		if old.Name != l.Name {
+			changes.Name = &api.ChangesFromPayload{From: old.Name}
+		}
+		if old.Color != l.Color {
+			changes.Color = &api.ChangesFromPayload{From: strings.TrimLeft(old.Color, "#")}
+		}
+	}
+	prepareLabelWebhooks(l, doer, api.HookLabelEdited, changes)
+	return nil
 }
 
 // DeleteLabel delete a label of given repository.
-func DeleteLabel(repoID, labelID int64) error {
-	_, err := GetLabelInRepoByID(repoID, labelID)
+func DeleteLabel(repoID, labelID int64, doer *User) error {
+	label, err := GetLabelInRepoByID(repoID, labelID)
 	if err != nil {
 		if IsErrLabelNotExist(err) {
 			return nil
@@ -304,7 +371,33 @@ func DeleteLabel(repoID, labelID int64) error {
 		return err
 	}
 
-	return sess.Commit()
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+	prepareLabelWebhooks(label, doer, api.HookLabelDeleted, nil)
+	return nil
+}
+
+// prepareLabelWebhooks sends the label event to the webhooks of the label's repository
+func prepareLabelWebhooks(label *Label, doer *User, action api.HookLabelAction, changes *api.ChangesPayload) {
+	repo, err := GetRepositoryByID(label.RepoID)
+	if err != nil {
+		log.Error("GetRepositoryByID [repo_id: %d]: %v", label.RepoID, err)
+		return
+	}
+
+	mode, _ := AccessLevel(doer, repo)
+	if err = PrepareWebhooks(repo, HookEventLabel, &api.LabelPayload{
+		Action:     action,
+		Label:      label.APIFormat(),
+		Changes:    changes,
+		Repository: repo.APIFormat(mode),
+		Sender:     doer.APIFormat(),
+	}); err != nil {
+		log.Error("PrepareWebhooks [label_id: %d]: %v", label.ID, err)
+	} else {
+		go HookQueue.Add(repo.ID)
+	}
 }
 
 // .___                            .____          ___.          .__
@@ -331,7 +424,33 @@ func HasIssueLabel(issueID, labelID int64) bool {
 	return hasIssueLabel(x, issueID, labelID)
 }
 
+// deleteExclusiveScopeIssueLabels removes the other labels of the scope of the given label from the issue
+func deleteExclusiveScopeIssueLabels(e *xorm.Session, issue *Issue, label *Label, doer *User) error {
+	scope := label.ExclusiveScope()
+	if len(scope) == 0 {
+		return nil
+	}
+
+	labels, err := getLabelsByIssueID(e, issue.ID)
+	if err != nil {
+		return err
+	}
+	for _, l := range labels {
+		if l.ID == label.ID || l.ExclusiveScope() != scope {
+			continue
+		}
+		if err = deleteIssueLabel(e, issue, l, doer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func newIssueLabel(e *xorm.Session, issue *Issue, label *Label, doer *User) (err error) {
+	if err = deleteExclusiveScopeIssueLabels(e, issue, label, doer); err != nil {
+		return err
+	}
+
 	if _, err = e.Insert(&IssueLabel{
 		IssueID: issue.ID,
 		LabelID: label.ID,
@@ -374,6 +493,10 @@ func NewIssueLabel(issue *Issue, label *Label, doer *User) (err error) {
 }
 
 func newIssueLabels(e *xorm.Session, issue *Issue, labels []*Label, doer *User) (err error) {
+	if err = checkExclusiveLabels(labels); err != nil {
+		return err
+	}
+
 	for i := range labels {
 		if hasIssueLabel(e, issue.ID, labels[i].ID) {
 			continue
@@ -0,0 +1,118 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strings"
+
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// DefaultLabel represents a label which is created in every new repository of
+// an organization, or of the whole instance if OrgID is 0.
+type DefaultLabel struct {
+	ID          int64 `xorm:"pk autoincr"`
+	OrgID       int64 `xorm:"INDEX"`
+	Name        string
+	Description string
+	Color       string `xorm:"VARCHAR(7)"`
+	Priority    int    `xorm:"NOT NULL DEFAULT 0"`
+}
+
+// APIFormat converts a DefaultLabel to the api.Label format
+func (label *DefaultLabel) APIFormat() *api.Label {
+	return &api.Label{
+		ID:          label.ID,
+		Name:        label.Name,
+		Color:       strings.TrimLeft(label.Color, "#"),
+		Description: label.Description,
+		Priority:    label.Priority,
+	}
+}
+
+// NewDefaultLabel creates a new default label for an organization or the instance
+func NewDefaultLabel(label *DefaultLabel) error {
+	_, err := x.Insert(label)
+	return err
+}
+
+// GetDefaultLabel returns the default label of an organization, or of the
+// instance if orgID is 0, by given ID.
+func GetDefaultLabel(orgID, id int64) (*DefaultLabel, error) {
+	label := new(DefaultLabel)
+	has, err := x.Where("id = ? AND org_id = ?", id, orgID).Get(label)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrLabelNotExist{LabelID: id}
+	}
+	return label, nil
+}
+
+func getDefaultLabels(e Engine, orgID int64) ([]*DefaultLabel, error) {
+	labels := make([]*DefaultLabel, 0, 10)
+	return labels, e.Where("org_id = ?", orgID).Asc("name").Find(&labels)
+}
+
+// GetDefaultLabels returns the default labels of an organization, or of the
+// instance if orgID is 0.
+func GetDefaultLabels(orgID int64) ([]*DefaultLabel, error) {
+	return getDefaultLabels(x, orgID)
+}
+
+// UpdateDefaultLabel updates a default label
+func UpdateDefaultLabel(label *DefaultLabel) error {
+	_, err := x.ID(label.ID).AllCols().Update(label)
+	return err
+}
+
+// DeleteDefaultLabel deletes a default label of an organization, or of the
+// instance if orgID is 0. Labels which have already been created in
+// repositories are kept.
+func DeleteDefaultLabel(orgID, id int64) error {
+	_, err := x.Where("id = ? AND org_id = ?", id, orgID).Delete(new(DefaultLabel))
+	return err
+}
+
+// initializeDefaultLabels creates the default labels of the instance and of the
+// owner of a new repository in the repository. A default label of the
+// organization replaces the default label of the instance with the same name.
+func initializeDefaultLabels(e Engine, repo *Repository) error {
+	labels, err := getDefaultLabels(e, 0)
+	if err != nil {
+		return err
+	}
+	if repo.Owner.IsOrganization() {
+		orgLabels, err := getDefaultLabels(e, repo.OwnerID)
+		if err != nil {
+			return err
+		}
+		labels = append(labels, orgLabels...)
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+
+	byName := make(map[string]*Label, len(labels))
+	names := make([]string, 0, len(labels))
+	for _, l := range labels {
+		if _, ok := byName[l.Name]; !ok {
+			names = append(names, l.Name)
+		}
+		byName[l.Name] = &Label{
+			RepoID:      repo.ID,
+			Name:        l.Name,
+			Description: l.Description,
+			Color:       l.Color,
+			Priority:    l.Priority,
+		}
+	}
+	for _, name := range names {
+		if err = newLabel(e, byName[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
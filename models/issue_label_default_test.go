@@ -0,0 +1,65 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultLabels(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	instanceLabel := &DefaultLabel{Name: "bug", Color: "#ee0701"}
+	assert.NoError(t, NewDefaultLabel(instanceLabel))
+	orgLabel := &DefaultLabel{OrgID: 3, Name: "bug", Color: "#000000", Priority: 2}
+	assert.NoError(t, NewDefaultLabel(orgLabel))
+	assert.NoError(t, NewDefaultLabel(&DefaultLabel{OrgID: 3, Name: "ux", Color: "#ffffff"}))
+
+	labels, err := GetDefaultLabels(0)
+	assert.NoError(t, err)
+	if assert.Len(t, labels, 1) {
+		assert.EqualValues(t, instanceLabel.ID, labels[0].ID)
+	}
+	labels, err = GetDefaultLabels(3)
+	assert.NoError(t, err)
+	assert.Len(t, labels, 2)
+
+	_, err = GetDefaultLabel(0, orgLabel.ID)
+	assert.True(t, IsErrLabelNotExist(err))
+	label, err := GetDefaultLabel(3, orgLabel.ID)
+	assert.NoError(t, err)
+	label.Description = "Something is not working"
+	assert.NoError(t, UpdateDefaultLabel(label))
+	AssertExistsAndLoadBean(t, &DefaultLabel{ID: orgLabel.ID, Description: "Something is not working"})
+
+	// the label of the organization replaces the label of the instance
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+	assert.NoError(t, repo.GetOwner())
+	assert.NoError(t, initializeDefaultLabels(x, repo))
+	repoLabels, err := GetLabelsByRepoID(repo.ID, "")
+	assert.NoError(t, err)
+	if assert.Len(t, repoLabels, 2) {
+		assert.EqualValues(t, "bug", repoLabels[0].Name)
+		assert.EqualValues(t, "#000000", repoLabels[0].Color)
+		assert.EqualValues(t, 2, repoLabels[0].Priority)
+		assert.EqualValues(t, "Something is not working", repoLabels[0].Description)
+		assert.EqualValues(t, "ux", repoLabels[1].Name)
+	}
+
+	// a repository of a user only gets the labels of the instance
+	repo = AssertExistsAndLoadBean(t, &Repository{ID: 2}).(*Repository)
+	assert.NoError(t, repo.GetOwner())
+	assert.NoError(t, initializeDefaultLabels(x, repo))
+	repoLabels, err = GetLabelsByRepoID(repo.ID, "")
+	assert.NoError(t, err)
+	if assert.Len(t, repoLabels, 1) {
+		assert.EqualValues(t, "#ee0701", repoLabels[0].Color)
+	}
+
+	assert.NoError(t, DeleteDefaultLabel(3, orgLabel.ID))
+	AssertNotExistsBean(t, &DefaultLabel{ID: orgLabel.ID})
+}
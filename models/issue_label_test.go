@@ -41,6 +41,19 @@ func TestLabel_ForegroundColor(t *testing.T) {
 	assert.Equal(t, template.CSS("#fff"), label.ForegroundColor())
 }
 
+func TestLabel_ExclusiveScope(t *testing.T) {
+	for name, scope := range map[string]string{
+		"label":             "",
+		"kind::bug":         "kind",
+		"team::ui::desktop": "team::ui",
+		"::bug":             "",
+		"kind::":            "",
+		"kind:bug":          "",
+	} {
+		assert.Equal(t, scope, (&Label{Name: name}).ExclusiveScope(), name)
+	}
+}
+
 func TestNewLabels(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	labels := []*Label{
@@ -141,6 +154,7 @@ func TestGetLabelsByRepoID(t *testing.T) {
 	testSuccess(1, "leastissues", []int64{2, 1})
 	testSuccess(1, "mostissues", []int64{1, 2})
 	testSuccess(1, "reversealphabetically", []int64{2, 1})
+	testSuccess(1, "priority", []int64{2, 1})
 	testSuccess(1, "default", []int64{1, 2})
 }
 
@@ -159,10 +173,11 @@ func TestGetLabelsByIssueID(t *testing.T) {
 
 func TestUpdateLabel(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
 	label := AssertExistsAndLoadBean(t, &Label{ID: 1}).(*Label)
 	label.Color = "#ffff00"
 	label.Name = "newLabelName"
-	assert.NoError(t, UpdateLabel(label))
+	assert.NoError(t, UpdateLabel(label, doer))
 	newLabel := AssertExistsAndLoadBean(t, &Label{ID: 1}).(*Label)
 	assert.Equal(t, *label, *newLabel)
 	CheckConsistencyFor(t, &Label{}, &Repository{})
@@ -170,14 +185,15 @@ func TestUpdateLabel(t *testing.T) {
 
 func TestDeleteLabel(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
 	label := AssertExistsAndLoadBean(t, &Label{ID: 1}).(*Label)
-	assert.NoError(t, DeleteLabel(label.RepoID, label.ID))
+	assert.NoError(t, DeleteLabel(label.RepoID, label.ID, doer))
 	AssertNotExistsBean(t, &Label{ID: label.ID, RepoID: label.RepoID})
 
-	assert.NoError(t, DeleteLabel(label.RepoID, label.ID))
+	assert.NoError(t, DeleteLabel(label.RepoID, label.ID, doer))
 	AssertNotExistsBean(t, &Label{ID: label.ID, RepoID: label.RepoID})
 
-	assert.NoError(t, DeleteLabel(NonexistentID, NonexistentID))
+	assert.NoError(t, DeleteLabel(NonexistentID, NonexistentID, doer))
 	CheckConsistencyFor(t, &Label{}, &Repository{})
 }
 
@@ -276,3 +292,34 @@ func TestDeleteIssueLabel(t *testing.T) {
 
 	CheckConsistencyFor(t, &Issue{}, &Label{})
 }
+
+func TestNewIssueLabel_ExclusiveScope(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	label1 := AssertExistsAndLoadBean(t, &Label{ID: 1}).(*Label)
+	label2 := AssertExistsAndLoadBean(t, &Label{ID: 2}).(*Label)
+	label1.Name = "kind::bug"
+	label2.Name = "kind::feature"
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.NoError(t, UpdateLabel(label1, doer))
+	assert.NoError(t, UpdateLabel(label2, doer))
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+
+	err := NewIssueLabels(issue, []*Label{label1, label2}, doer)
+	assert.True(t, IsErrLabelExclusiveScopeConflict(err))
+	err = issue.ReplaceLabels([]*Label{label1, label2}, doer)
+	assert.True(t, IsErrLabelExclusiveScopeConflict(err))
+
+	// adding a label of a scope removes the other label of the scope
+	assert.NoError(t, NewIssueLabel(issue, label2, doer))
+	AssertExistsAndLoadBean(t, &IssueLabel{IssueID: issue.ID, LabelID: label2.ID})
+	AssertNotExistsBean(t, &IssueLabel{IssueID: issue.ID, LabelID: label1.ID})
+	AssertExistsAndLoadBean(t, &Comment{
+		Type:     CommentTypeLabel,
+		PosterID: doer.ID,
+		IssueID:  issue.ID,
+		LabelID:  label1.ID,
+		Content:  "",
+	})
+
+	CheckConsistencyFor(t, &Issue{}, &Label{})
+}
@@ -136,6 +136,7 @@ func (issues IssueList) loadLabels(e Engine) error {
 		rows, err := e.Table("label").
 			Join("LEFT", "issue_label", "issue_label.label_id = label.id").
 			In("issue_label.issue_id", issueIDs[:limit]).
+			Desc("label.priority").
 			Asc("label.name").
 			Rows(new(LabelIssue))
 		if err != nil {
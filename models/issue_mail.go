@@ -21,14 +21,14 @@ func (issue *Issue) mailSubject() string {
 
 // mailIssueCommentToParticipants can be used for both new issue creation and comment.
 // This function sends two list of emails:
-// 1. Repository watchers and users who are participated in comments.
+// 1. Repository watchers interested in the action and users who are participated in comments.
 // 2. Users who are not in 1. but get mentioned in current issue/comment.
-func mailIssueCommentToParticipants(e Engine, issue *Issue, doer *User, content string, comment *Comment, mentions []string) error {
+func mailIssueCommentToParticipants(e Engine, issue *Issue, doer *User, opType ActionType, content string, comment *Comment, mentions []string) error {
 	if !setting.Service.EnableNotifyMail {
 		return nil
 	}
 
-	watchers, err := getWatchers(e, issue.RepoID)
+	watchers, err := getWatchers(e, issue.RepoID, watchModesOfAction(opType)...)
 	if err != nil {
 		return fmt.Errorf("getWatchers [repo_id: %d]: %v", issue.RepoID, err)
 	}
@@ -130,7 +130,7 @@ func (issue *Issue) mailParticipants(e Engine, doer *User, opType ActionType) (e
 	}
 
 	if len(issue.Content) > 0 {
-		if err = mailIssueCommentToParticipants(e, issue, doer, issue.Content, nil, mentions); err != nil {
+		if err = mailIssueCommentToParticipants(e, issue, doer, opType, issue.Content, nil, mentions); err != nil {
 			log.Error("mailIssueCommentToParticipants: %v", err)
 		}
 	}
@@ -139,18 +139,18 @@ func (issue *Issue) mailParticipants(e Engine, doer *User, opType ActionType) (e
 	case ActionCreateIssue, ActionCreatePullRequest:
 		if len(issue.Content) == 0 {
 			ct := fmt.Sprintf("Created #%d.", issue.Index)
-			if err = mailIssueCommentToParticipants(e, issue, doer, ct, nil, mentions); err != nil {
+			if err = mailIssueCommentToParticipants(e, issue, doer, opType, ct, nil, mentions); err != nil {
 				log.Error("mailIssueCommentToParticipants: %v", err)
 			}
 		}
 	case ActionCloseIssue, ActionClosePullRequest:
 		ct := fmt.Sprintf("Closed #%d.", issue.Index)
-		if err = mailIssueCommentToParticipants(e, issue, doer, ct, nil, mentions); err != nil {
+		if err = mailIssueCommentToParticipants(e, issue, doer, opType, ct, nil, mentions); err != nil {
 			log.Error("mailIssueCommentToParticipants: %v", err)
 		}
 	case ActionReopenIssue, ActionReopenPullRequest:
 		ct := fmt.Sprintf("Reopened #%d.", issue.Index)
-		if err = mailIssueCommentToParticipants(e, issue, doer, ct, nil, mentions); err != nil {
+		if err = mailIssueCommentToParticipants(e, issue, doer, opType, ct, nil, mentions); err != nil {
 			log.Error("mailIssueCommentToParticipants: %v", err)
 		}
 	}
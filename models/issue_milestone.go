@@ -6,6 +6,8 @@ package models
 
 import (
 	"fmt"
+	"math"
+	"time"
 
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
@@ -13,12 +15,15 @@ import (
 	"code.gitea.io/gitea/modules/timeutil"
 
 	"github.com/go-xorm/xorm"
+	"xorm.io/builder"
 )
 
-// Milestone represents a milestone of repository.
+// Milestone represents a milestone of repository or, if RepoID is 0, of an
+// organization which all repositories of the organization can use.
 type Milestone struct {
 	ID              int64 `xorm:"pk autoincr"`
 	RepoID          int64 `xorm:"INDEX"`
+	OrgID           int64 `xorm:"INDEX"`
 	Name            string
 	Content         string `xorm:"TEXT"`
 	RenderedContent string `xorm:"-"`
@@ -86,7 +91,7 @@ func (m *Milestone) APIFormat() *api.Milestone {
 	return apiMilestone
 }
 
-// NewMilestone creates new milestone of repository.
+// NewMilestone creates new milestone of repository or organization.
 func NewMilestone(m *Milestone) (err error) {
 	sess := x.NewSession()
 	defer sess.Close()
@@ -98,6 +103,9 @@ func NewMilestone(m *Milestone) (err error) {
 		return err
 	}
 
+	if m.RepoID == 0 {
+		return sess.Commit()
+	}
 	if _, err = sess.Exec("UPDATE `repository` SET num_milestones = num_milestones + 1 WHERE id = ?", m.RepoID); err != nil {
 		return err
 	}
@@ -123,6 +131,53 @@ func GetMilestoneByRepoID(repoID, id int64) (*Milestone, error) {
 	return getMilestoneByRepoID(x, repoID, id)
 }
 
+func getMilestoneByOrgID(e Engine, orgID, id int64) (*Milestone, error) {
+	m := new(Milestone)
+	has, err := e.Where("id=? AND org_id=? AND repo_id=0", id, orgID).Get(m)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrMilestoneNotExist{ID: id}
+	}
+	return m, nil
+}
+
+// GetMilestoneByOrgID returns the milestone of an organization.
+func GetMilestoneByOrgID(orgID, id int64) (*Milestone, error) {
+	return getMilestoneByOrgID(x, orgID, id)
+}
+
+// getAssignableMilestone returns the milestone if issues of the repository
+// can be assigned to it, which is the case for milestones of the repository
+// and of the organization owning the repository.
+func getAssignableMilestone(e Engine, repoID, id int64) (*Milestone, error) {
+	m := new(Milestone)
+	has, err := e.ID(id).Get(m)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrMilestoneNotExist{id, repoID}
+	}
+	if m.RepoID == repoID {
+		return m, nil
+	}
+	if m.RepoID == 0 && m.OrgID > 0 {
+		has, err = e.Where("id=? AND owner_id=?", repoID, m.OrgID).Exist(new(Repository))
+		if err != nil {
+			return nil, err
+		} else if has {
+			return m, nil
+		}
+	}
+	return nil, ErrMilestoneNotExist{id, repoID}
+}
+
+// GetAssignableMilestone returns the milestone of the repository or of the
+// organization owning it.
+func GetAssignableMilestone(repoID, id int64) (*Milestone, error) {
+	return getAssignableMilestone(x, repoID, id)
+}
+
 // GetMilestoneByID returns the milestone via id .
 func GetMilestoneByID(id int64) (*Milestone, error) {
 	var m Milestone
@@ -192,8 +247,16 @@ func (milestones MilestoneList) getMilestoneIDs() []int64 {
 
 // GetMilestonesByRepoID returns all opened milestones of a repository.
 func GetMilestonesByRepoID(repoID int64, state api.StateType) (MilestoneList, error) {
+	return getMilestonesByCond(builder.Eq{"repo_id": repoID}, state)
+}
+
+// GetMilestonesByOrgID returns the milestones of an organization.
+func GetMilestonesByOrgID(orgID int64, state api.StateType) (MilestoneList, error) {
+	return getMilestonesByCond(builder.Eq{"org_id": orgID, "repo_id": 0}, state)
+}
 
-	sess := x.Where("repo_id = ?", repoID)
+func getMilestonesByCond(cond builder.Cond, state api.StateType) (MilestoneList, error) {
+	sess := x.Where(cond)
 
 	switch state {
 	case api.StateClosed:
@@ -279,6 +342,11 @@ func MilestoneStats(repoID int64) (open int64, closed int64, err error) {
 
 // ChangeMilestoneStatus changes the milestone open/closed status.
 func ChangeMilestoneStatus(m *Milestone, isClosed bool) (err error) {
+	if m.RepoID == 0 {
+		m.IsClosed = isClosed
+		return updateMilestone(x, m)
+	}
+
 	repo, err := GetRepositoryByID(m.RepoID)
 	if err != nil {
 		return err
@@ -317,7 +385,7 @@ func changeMilestoneIssueStats(e *xorm.Session, issue *Issue) error {
 		return nil
 	}
 
-	m, err := getMilestoneByRepoID(e, issue.RepoID, issue.MilestoneID)
+	m, err := getAssignableMilestone(e, issue.RepoID, issue.MilestoneID)
 	if err != nil {
 		return err
 	}
@@ -335,7 +403,7 @@ func changeMilestoneIssueStats(e *xorm.Session, issue *Issue) error {
 
 func changeMilestoneAssign(e *xorm.Session, doer *User, issue *Issue, oldMilestoneID int64) error {
 	if oldMilestoneID > 0 {
-		m, err := getMilestoneByRepoID(e, issue.RepoID, oldMilestoneID)
+		m, err := getAssignableMilestone(e, issue.RepoID, oldMilestoneID)
 		if err != nil {
 			return err
 		}
@@ -351,7 +419,7 @@ func changeMilestoneAssign(e *xorm.Session, doer *User, issue *Issue, oldMilesto
 	}
 
 	if issue.MilestoneID > 0 {
-		m, err := getMilestoneByRepoID(e, issue.RepoID, issue.MilestoneID)
+		m, err := getAssignableMilestone(e, issue.RepoID, issue.MilestoneID)
 		if err != nil {
 			return err
 		}
@@ -482,3 +550,228 @@ func DeleteMilestoneByRepoID(repoID, id int64) error {
 	}
 	return sess.Commit()
 }
+
+// DeleteOrgMilestone deletes a milestone of an organization.
+func DeleteOrgMilestone(orgID, id int64) error {
+	m, err := GetMilestoneByOrgID(orgID, id)
+	if err != nil {
+		if IsErrMilestoneNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err = sess.ID(m.ID).Delete(new(Milestone)); err != nil {
+		return err
+	}
+
+	if _, err = sess.Exec("UPDATE `issue` SET milestone_id = 0 WHERE milestone_id = ?", m.ID); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// getOrgMilestoneIDsOfRepo returns the IDs of the organization milestones
+// issues of the repository are assigned to.
+func getOrgMilestoneIDsOfRepo(e Engine, repoID int64) ([]int64, error) {
+	ids := make([]int64, 0, 5)
+	return ids, e.Table("issue").
+		Join("INNER", "milestone", "issue.milestone_id = milestone.id").
+		Where("issue.repo_id = ? AND milestone.repo_id = 0", repoID).
+		Distinct("issue.milestone_id").
+		Find(&ids)
+}
+
+// removeRepoFromOrgMilestones unsets the organization milestones of all
+// issues of a repository.
+func removeRepoFromOrgMilestones(e Engine, repoID int64) error {
+	ids, err := getOrgMilestoneIDsOfRepo(e, repoID)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	if _, err = e.Table("issue").
+		Where("repo_id = ?", repoID).
+		In("milestone_id", ids).
+		Update(map[string]interface{}{"milestone_id": 0}); err != nil {
+		return err
+	}
+	return updateMilestoneCounters(e, ids...)
+}
+
+// updateMilestoneCounters recalculates the number of issues of milestones.
+func updateMilestoneCounters(e Engine, ids ...int64) error {
+	for _, id := range ids {
+		m := new(Milestone)
+		if has, err := e.ID(id).Get(m); err != nil {
+			return err
+		} else if !has {
+			continue
+		}
+
+		numIssues, err := e.Where("milestone_id = ?", id).Count(new(Issue))
+		if err != nil {
+			return err
+		}
+		numClosedIssues, err := e.Where("milestone_id = ? AND is_closed = ?", id, true).Count(new(Issue))
+		if err != nil {
+			return err
+		}
+		m.NumIssues = int(numIssues)
+		m.NumClosedIssues = int(numClosedIssues)
+		if err = updateMilestone(e, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MaxMilestoneBurndownDays is the maximum number of days a burndown chart covers
+const MaxMilestoneBurndownDays = 365
+
+// MilestoneBurndownDay represents the issue counts of a milestone at the end of a day
+type MilestoneBurndownDay struct {
+	Date   time.Time
+	Open   int
+	Closed int
+}
+
+// MilestoneBurndown represents the progress of a milestone over time
+type MilestoneBurndown struct {
+	MilestoneID int64
+	Days        []*MilestoneBurndownDay
+	// EstimatedCompletion is 0 if no estimation could be made
+	EstimatedCompletion timeutil.TimeStamp
+}
+
+// APIFormat converts a MilestoneBurndown to api.MilestoneBurndown
+func (b *MilestoneBurndown) APIFormat() *api.MilestoneBurndown {
+	apiBurndown := &api.MilestoneBurndown{
+		MilestoneID: b.MilestoneID,
+		Days:        make([]*api.MilestoneBurndownDay, len(b.Days)),
+	}
+	for i, day := range b.Days {
+		apiBurndown.Days[i] = &api.MilestoneBurndownDay{
+			Date:   day.Date.Format("2006-01-02"),
+			Open:   day.Open,
+			Closed: day.Closed,
+		}
+	}
+	if b.EstimatedCompletion != 0 {
+		apiBurndown.EstimatedCompletion = b.EstimatedCompletion.AsTimePtr()
+	}
+	return apiBurndown
+}
+
+// getMilestoneBurndown computes the number of open and closed issues of a
+// milestone at the end of every day, starting with the day the oldest issue
+// has been created and ending today or on the day the milestone has been
+// closed. As it is not recorded when an issue has been added to the
+// milestone, the creation time of the issue is used instead.
+func getMilestoneBurndown(e Engine, m *Milestone, now time.Time) (*MilestoneBurndown, error) {
+	issues := make([]*Issue, 0, m.NumIssues)
+	if err := e.Where("milestone_id=?", m.ID).
+		Cols("created_unix", "is_closed", "closed_unix").
+		Find(&issues); err != nil {
+		return nil, err
+	}
+
+	burndown := &MilestoneBurndown{
+		MilestoneID: m.ID,
+		Days:        make([]*MilestoneBurndownDay, 0),
+	}
+	if len(issues) == 0 {
+		return burndown, nil
+	}
+
+	startOfDay := func(t time.Time) time.Time {
+		t = t.In(setting.DefaultUILocation)
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+
+	end := startOfDay(now)
+	if m.IsClosed && m.ClosedDateUnix != 0 && m.ClosedDateUnix.AsTime().Before(now) {
+		end = startOfDay(m.ClosedDateUnix.AsTime())
+	}
+	start := end
+	for _, issue := range issues {
+		if created := startOfDay(issue.CreatedUnix.AsTime()); created.Before(start) {
+			start = created
+		}
+	}
+	if earliest := end.AddDate(0, 0, -(MaxMilestoneBurndownDays - 1)); start.Before(earliest) {
+		start = earliest
+	}
+
+	var numDays int
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		burndown.Days = append(burndown.Days, &MilestoneBurndownDay{Date: day})
+		numDays++
+	}
+
+	// dayIndex returns the index of the day the given time falls on, -1 if it
+	// is before the first day and numDays if it is after the last one.
+	dayIndex := func(t time.Time) int {
+		if t.Before(start) {
+			return -1
+		}
+		for i := 1; i < numDays; i++ {
+			if t.Before(burndown.Days[i].Date) {
+				return i - 1
+			}
+		}
+		if t.Before(end.AddDate(0, 0, 1)) {
+			return numDays - 1
+		}
+		return numDays
+	}
+
+	// The changes of the counts on each day, the first entry holds the
+	// counts before the first day.
+	openDelta := make([]int, numDays+2)
+	closedDelta := make([]int, numDays+2)
+	for _, issue := range issues {
+		created := dayIndex(issue.CreatedUnix.AsTime())
+		openDelta[created+1]++
+		if !issue.IsClosed {
+			continue
+		}
+		closedAt := issue.ClosedUnix
+		if closedAt < issue.CreatedUnix {
+			closedAt = issue.CreatedUnix
+		}
+		closed := dayIndex(closedAt.AsTime())
+		openDelta[closed+1]--
+		closedDelta[closed+1]++
+	}
+
+	open, closed := openDelta[0], closedDelta[0]
+	closedBefore := closed
+	for i, day := range burndown.Days {
+		open += openDelta[i+1]
+		closed += closedDelta[i+1]
+		day.Open = open
+		day.Closed = closed
+	}
+
+	// Estimate the completion using the average number of issues closed per
+	// day over the whole chart.
+	if m.IsClosed || open == 0 || closed == closedBefore {
+		return burndown, nil
+	}
+	rate := float64(closed-closedBefore) / float64(numDays)
+	remainingDays := int(math.Ceil(float64(open) / rate))
+	burndown.EstimatedCompletion = timeutil.TimeStamp(end.AddDate(0, 0, remainingDays).Unix())
+	return burndown, nil
+}
+
+// GetMilestoneBurndown returns the burndown chart data of a milestone
+func GetMilestoneBurndown(m *Milestone) (*MilestoneBurndown, error) {
+	return getMilestoneBurndown(x, m, time.Now())
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
 
@@ -289,3 +290,133 @@ func TestMilestoneList_LoadTotalTrackedTimes(t *testing.T) {
 
 	assert.Equal(t, miles[0].TotalTrackedTime, int64(3662))
 }
+
+func TestGetMilestoneBurndown(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	defaultUILocation := setting.DefaultUILocation
+	setting.DefaultUILocation = time.UTC
+	defer func() {
+		setting.DefaultUILocation = defaultUILocation
+	}()
+
+	milestone := AssertExistsAndLoadBean(t, &Milestone{ID: 1}).(*Milestone)
+	now := time.Date(2000, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	burndown, err := getMilestoneBurndown(x, milestone, now)
+	assert.NoError(t, err)
+	assert.Len(t, burndown.Days, 10)
+	assert.Equal(t, "2000-01-01", burndown.Days[0].Date.Format("2006-01-02"))
+	for _, day := range burndown.Days {
+		assert.Equal(t, 1, day.Open)
+		assert.Equal(t, 0, day.Closed)
+	}
+	assert.EqualValues(t, 0, burndown.EstimatedCompletion)
+
+	// add a second issue and close the first one on the fifth day
+	_, err = x.ID(1).Cols("milestone_id").Update(&Issue{MilestoneID: 1})
+	assert.NoError(t, err)
+	closedUnix := time.Date(2000, 1, 5, 8, 0, 0, 0, time.UTC).Unix()
+	_, err = x.ID(2).Cols("is_closed", "closed_unix").Update(&Issue{IsClosed: true, ClosedUnix: timeutil.TimeStamp(closedUnix)})
+	assert.NoError(t, err)
+
+	burndown, err = getMilestoneBurndown(x, milestone, now)
+	assert.NoError(t, err)
+	assert.Len(t, burndown.Days, 10)
+	assert.Equal(t, 2, burndown.Days[3].Open)
+	assert.Equal(t, 0, burndown.Days[3].Closed)
+	assert.Equal(t, 1, burndown.Days[4].Open)
+	assert.Equal(t, 1, burndown.Days[4].Closed)
+	assert.Equal(t, 1, burndown.Days[9].Open)
+	assert.Equal(t, 1, burndown.Days[9].Closed)
+	// one issue closed in ten days, so the remaining one needs another ten
+	assert.Equal(t, time.Date(2000, 1, 20, 0, 0, 0, 0, time.UTC).Unix(), int64(burndown.EstimatedCompletion))
+
+	apiBurndown := burndown.APIFormat()
+	assert.EqualValues(t, 1, apiBurndown.MilestoneID)
+	assert.Len(t, apiBurndown.Days, 10)
+	assert.Equal(t, "2000-01-05", apiBurndown.Days[4].Date)
+	assert.NotNil(t, apiBurndown.EstimatedCompletion)
+
+	// the chart is limited to MaxMilestoneBurndownDays
+	burndown, err = getMilestoneBurndown(x, milestone, now.AddDate(2, 0, 0))
+	assert.NoError(t, err)
+	assert.Len(t, burndown.Days, MaxMilestoneBurndownDays)
+	assert.Equal(t, 1, burndown.Days[0].Open)
+	assert.Equal(t, 1, burndown.Days[0].Closed)
+	assert.EqualValues(t, 0, burndown.EstimatedCompletion)
+}
+
+func TestGetAssignableMilestone(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	// milestone of the repository
+	milestone, err := GetAssignableMilestone(1, 1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, milestone.ID)
+
+	// milestone of the organization owning the repository
+	milestone, err = GetAssignableMilestone(3, 4)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, milestone.ID)
+	assert.EqualValues(t, 3, milestone.OrgID)
+
+	_, err = GetAssignableMilestone(1, 4)
+	assert.True(t, IsErrMilestoneNotExist(err))
+	_, err = GetAssignableMilestone(3, 1)
+	assert.True(t, IsErrMilestoneNotExist(err))
+	_, err = GetAssignableMilestone(1, NonexistentID)
+	assert.True(t, IsErrMilestoneNotExist(err))
+}
+
+func TestGetMilestonesByOrgID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	milestones, err := GetMilestonesByOrgID(3, api.StateOpen)
+	assert.NoError(t, err)
+	assert.Len(t, milestones, 1)
+	assert.EqualValues(t, 4, milestones[0].ID)
+
+	milestones, err = GetMilestonesByOrgID(3, api.StateClosed)
+	assert.NoError(t, err)
+	assert.Len(t, milestones, 0)
+
+	milestones, err = GetMilestonesByOrgID(2, api.StateAll)
+	assert.NoError(t, err)
+	assert.Len(t, milestones, 0)
+}
+
+func TestChangeMilestoneAssign_OrgMilestone(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 6}).(*Issue)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	issue.MilestoneID = 4
+	assert.NoError(t, ChangeMilestoneAssign(issue, doer, 0))
+	milestone := AssertExistsAndLoadBean(t, &Milestone{ID: 4}).(*Milestone)
+	assert.EqualValues(t, 1, milestone.NumIssues)
+	CheckConsistencyFor(t, &Milestone{}, &Repository{ID: 3})
+
+	assert.NoError(t, removeRepoFromOrgMilestones(x, 3))
+	assert.EqualValues(t, 0, AssertExistsAndLoadBean(t, &Issue{ID: 6}).(*Issue).MilestoneID)
+	milestone = AssertExistsAndLoadBean(t, &Milestone{ID: 4}).(*Milestone)
+	assert.EqualValues(t, 0, milestone.NumIssues)
+	CheckConsistencyFor(t, &Milestone{})
+}
+
+func TestDeleteOrgMilestone(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 6}).(*Issue)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issue.MilestoneID = 4
+	assert.NoError(t, ChangeMilestoneAssign(issue, doer, 0))
+
+	// the milestone can't be deleted via another organization
+	assert.NoError(t, DeleteOrgMilestone(2, 4))
+	AssertExistsAndLoadBean(t, &Milestone{ID: 4})
+
+	assert.NoError(t, DeleteOrgMilestone(3, 4))
+	AssertNotExistsBean(t, &Milestone{ID: 4})
+	assert.EqualValues(t, 0, AssertExistsAndLoadBean(t, &Issue{ID: 6}).(*Issue).MilestoneID)
+	CheckConsistencyFor(t, &Repository{ID: 3})
+}
@@ -0,0 +1,238 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/go-xorm/xorm"
+)
+
+// IssueRedirect represents that an issue index of a repository should be
+// redirected to an issue that has been moved to another repository
+type IssueRedirect struct {
+	ID              int64 `xorm:"pk autoincr"`
+	RepoID          int64 `xorm:"UNIQUE(s)"`
+	Index           int64 `xorm:"UNIQUE(s)"`
+	RedirectIssueID int64 `xorm:"INDEX"` // issueID to redirect to
+}
+
+// LookupIssueRedirect look up if an issue index of a repository has been moved
+func LookupIssueRedirect(repoID, index int64) (int64, error) {
+	redirect := &IssueRedirect{RepoID: repoID, Index: index}
+	if has, err := x.Get(redirect); err != nil {
+		return 0, err
+	} else if !has {
+		return 0, ErrIssueRedirectNotExist{RepoID: repoID, Index: index}
+	}
+	return redirect.RedirectIssueID, nil
+}
+
+// MoveIssue moves an issue with all its comments, attachments and reactions
+// to another repository. Labels are kept if a label with the same name exists
+// in the new repository and the milestone if it belongs to the organization
+// owning both repositories, assignees without access are dropped.
+// A closed and locked stub issue is left at the old index which redirects to
+// the moved issue. The stub is returned.
+func MoveIssue(doer *User, issue *Issue, newRepo *Repository) (*Issue, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	stub, err := moveIssue(sess, doer, issue, newRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = sess.Commit(); err != nil {
+		return nil, fmt.Errorf("Commit: %v", err)
+	}
+	return stub, nil
+}
+
+func moveIssue(e *xorm.Session, doer *User, issue *Issue, newRepo *Repository) (*Issue, error) {
+	if err := issue.loadRepo(e); err != nil {
+		return nil, err
+	}
+	if issue.IsPull {
+		return nil, ErrIssueMoveNotAllowed{IssueID: issue.ID, Reason: "pull requests cannot be moved"}
+	}
+	if issue.RepoID == newRepo.ID {
+		return nil, ErrIssueMoveNotAllowed{IssueID: issue.ID, Reason: "issue is already in the repository"}
+	}
+	oldRepo := issue.Repo
+	oldIndex := issue.Index
+
+	// Labels are bound to a repository, keep the ones with a matching name.
+	if err := issue.getLabels(e); err != nil {
+		return nil, err
+	}
+	if _, err := e.Delete(&IssueLabel{IssueID: issue.ID}); err != nil {
+		return nil, err
+	}
+	labels := make([]*Label, 0, len(issue.Labels))
+	for _, label := range issue.Labels {
+		label.NumIssues--
+		if issue.IsClosed {
+			label.NumClosedIssues--
+		}
+		if err := updateLabel(e, label); err != nil {
+			return nil, err
+		}
+
+		newLabel, err := getLabelInRepoByName(e, newRepo.ID, label.Name)
+		if err != nil {
+			if IsErrLabelNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if _, err = e.Insert(&IssueLabel{IssueID: issue.ID, LabelID: newLabel.ID}); err != nil {
+			return nil, err
+		}
+		newLabel.NumIssues++
+		if issue.IsClosed {
+			newLabel.NumClosedIssues++
+		}
+		if err = updateLabel(e, newLabel); err != nil {
+			return nil, err
+		}
+		labels = append(labels, newLabel)
+	}
+	issue.Labels = labels
+
+	// Milestones are bound to a repository as well, only milestones of the
+	// organization owning both repositories are kept.
+	if issue.MilestoneID > 0 {
+		keepMilestone := false
+		m, err := getAssignableMilestone(e, issue.RepoID, issue.MilestoneID)
+		if err != nil && !IsErrMilestoneNotExist(err) {
+			return nil, err
+		} else if err == nil && m.RepoID == 0 && m.OrgID == newRepo.OwnerID {
+			keepMilestone = true
+		} else if err == nil {
+			m.NumIssues--
+			if issue.IsClosed {
+				m.NumClosedIssues--
+			}
+			if err = updateMilestone(e, m); err != nil {
+				return nil, err
+			}
+		}
+		if !keepMilestone {
+			issue.MilestoneID = 0
+			issue.Milestone = nil
+		}
+	}
+
+	if err := issue.loadAssignees(e); err != nil {
+		return nil, err
+	}
+	assignees := make([]*User, 0, len(issue.Assignees))
+	for _, assignee := range issue.Assignees {
+		valid, err := canBeAssigned(e, assignee, newRepo)
+		if err != nil {
+			return nil, err
+		}
+		if valid {
+			assignees = append(assignees, assignee)
+			continue
+		}
+		if _, err = e.Delete(&IssueAssignees{IssueID: issue.ID, AssigneeID: assignee.ID}); err != nil {
+			return nil, err
+		}
+	}
+	issue.Assignees = assignees
+
+	var maxIndex int64
+	if _, err := e.Table("issue").Select("coalesce(MAX(`index`),0)").
+		Where("repo_id=?", newRepo.ID).Get(&maxIndex); err != nil {
+		return nil, err
+	}
+	maxDeletedIndex, err := getMaxDeletedIssueIndex(e, newRepo.ID)
+	if err != nil {
+		return nil, err
+	}
+	if maxDeletedIndex > maxIndex {
+		maxIndex = maxDeletedIndex
+	}
+
+	issue.RepoID = newRepo.ID
+	issue.Repo = newRepo
+	issue.Index = maxIndex + 1
+	if _, err := e.ID(issue.ID).Cols("repo_id", "`index`", "milestone_id").Update(issue); err != nil {
+		return nil, err
+	}
+	if _, err := e.Exec("UPDATE `repository` SET num_issues = num_issues + 1 WHERE id = ?", newRepo.ID); err != nil {
+		return nil, err
+	}
+	if err := issue.updateClosedNum(e); err != nil {
+		return nil, err
+	}
+	if _, err := e.Where("issue_id=?", issue.ID).Cols("repo_id").Update(&Notification{RepoID: newRepo.ID}); err != nil {
+		return nil, err
+	}
+
+	// Leave a closed and locked stub behind so old references keep working,
+	// the stub does not tell where a private repository is.
+	content := fmt.Sprintf("This issue has been moved to %s#%d", newRepo.FullName(), issue.Index)
+	if newRepo.IsPrivate {
+		content = "This issue has been moved to another repository"
+	}
+	stub := &Issue{
+		RepoID:     oldRepo.ID,
+		Repo:       oldRepo,
+		Index:      oldIndex,
+		PosterID:   doer.ID,
+		Poster:     doer,
+		Title:      issue.Title,
+		Content:    content,
+		IsClosed:   true,
+		ClosedUnix: timeutil.TimeStampNow(),
+		IsLocked:   true,
+	}
+	if _, err := e.Insert(stub); err != nil {
+		return nil, err
+	}
+	if err := stub.updateClosedNum(e); err != nil {
+		return nil, err
+	}
+
+	if _, err := e.Delete(&IssueRedirect{RepoID: oldRepo.ID, Index: oldIndex}); err != nil {
+		return nil, err
+	}
+	if _, err := e.Insert(&IssueRedirect{
+		RepoID:          oldRepo.ID,
+		Index:           oldIndex,
+		RedirectIssueID: issue.ID,
+	}); err != nil {
+		return nil, err
+	}
+
+	if _, err := createComment(e, &CreateCommentOptions{
+		Type:             CommentTypeIssueMovedTo,
+		Doer:             doer,
+		Repo:             oldRepo,
+		Issue:            stub,
+		DependentIssueID: issue.ID,
+	}); err != nil {
+		return nil, err
+	}
+	if _, err := createComment(e, &CreateCommentOptions{
+		Type:             CommentTypeIssueMovedFrom,
+		Doer:             doer,
+		Repo:             newRepo,
+		Issue:            issue,
+		DependentIssueID: stub.ID,
+	}); err != nil {
+		return nil, err
+	}
+
+	return stub, nil
+}
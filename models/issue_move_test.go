@@ -0,0 +1,56 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoveIssue(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	newRepo := AssertExistsAndLoadBean(t, &Repository{ID: 2}).(*Repository)
+
+	stub, err := MoveIssue(doer, issue, newRepo)
+	assert.NoError(t, err)
+
+	moved := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.EqualValues(t, newRepo.ID, moved.RepoID)
+	assert.EqualValues(t, 3, moved.Index)
+
+	// comments follow the issue
+	AssertExistsAndLoadBean(t, &Comment{ID: 2, IssueID: 1})
+	AssertExistsAndLoadBean(t, &Comment{Type: CommentTypeIssueMovedFrom, IssueID: 1, DependentIssueID: stub.ID})
+
+	// labels of repo1 do not exist in repo2
+	AssertNotExistsBean(t, &IssueLabel{IssueID: 1})
+	label := AssertExistsAndLoadBean(t, &Label{ID: 1}).(*Label)
+	assert.EqualValues(t, 1, label.NumIssues)
+
+	stub = AssertExistsAndLoadBean(t, &Issue{ID: stub.ID, RepoID: 1, Index: 1}).(*Issue)
+	assert.True(t, stub.IsClosed)
+	assert.True(t, stub.IsLocked)
+	// repo2 is private, its name is not disclosed
+	assert.NotContains(t, stub.Content, newRepo.Name)
+	AssertExistsAndLoadBean(t, &Comment{Type: CommentTypeIssueMovedTo, IssueID: stub.ID, DependentIssueID: 1})
+
+	redirectID, err := LookupIssueRedirect(1, 1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, redirectID)
+
+	_, err = LookupIssueRedirect(1, 2)
+	assert.True(t, IsErrIssueRedirectNotExist(err))
+
+	// pull requests cannot be moved
+	pull := AssertExistsAndLoadBean(t, &Issue{ID: 2}).(*Issue)
+	_, err = MoveIssue(doer, pull, newRepo)
+	assert.True(t, IsErrIssueMoveNotAllowed(err))
+
+	CheckConsistencyForAll(t)
+}
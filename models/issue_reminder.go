@@ -0,0 +1,174 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// issueReminderRetryDuration is how long a due reminder which fails to be sent
+// is retried before it is given up
+const issueReminderRetryDuration = 24 * time.Hour
+
+// IssueReminder represents a reminder a user has set on an issue
+type IssueReminder struct {
+	ID          int64              `xorm:"pk autoincr"`
+	UserID      int64              `xorm:"INDEX"`
+	User        *User              `xorm:"-"`
+	IssueID     int64              `xorm:"INDEX"`
+	Issue       *Issue             `xorm:"-"`
+	RemindUnix  timeutil.TimeStamp `xorm:"INDEX"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+func (r *IssueReminder) loadAttributes(e Engine) (err error) {
+	if r.User == nil {
+		if r.User, err = getUserByID(e, r.UserID); err != nil {
+			return err
+		}
+	}
+	if r.Issue == nil {
+		if r.Issue, err = getIssueByID(e, r.IssueID); err != nil {
+			return err
+		}
+	}
+	if r.Issue.Repo == nil {
+		if r.Issue.Repo, err = getRepositoryByID(e, r.Issue.RepoID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadAttributes loads the user and the issue of the reminder
+func (r *IssueReminder) LoadAttributes() error {
+	return r.loadAttributes(x)
+}
+
+// APIFormat converts a IssueReminder to api.IssueReminder
+func (r *IssueReminder) APIFormat() *api.IssueReminder {
+	return &api.IssueReminder{
+		ID:       r.ID,
+		Issue:    r.Issue.APIFormat(),
+		RemindAt: r.RemindUnix.AsTime(),
+		Created:  r.CreatedUnix.AsTime(),
+	}
+}
+
+// CreateIssueReminder sets a reminder for the user on an issue
+func CreateIssueReminder(user *User, issue *Issue, remindUnix timeutil.TimeStamp) (*IssueReminder, error) {
+	r := &IssueReminder{
+		UserID:     user.ID,
+		User:       user,
+		IssueID:    issue.ID,
+		Issue:      issue,
+		RemindUnix: remindUnix,
+	}
+	if _, err := x.Insert(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetIssueReminderByID returns the reminder with the given ID
+func GetIssueReminderByID(id int64) (*IssueReminder, error) {
+	r := new(IssueReminder)
+	has, err := x.ID(id).Get(r)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrIssueReminderNotExist{id}
+	}
+	return r, nil
+}
+
+// GetIssueRemindersByUser returns the pending reminders of a user, the
+// earliest first.
+func GetIssueRemindersByUser(userID int64) ([]*IssueReminder, error) {
+	reminders := make([]*IssueReminder, 0, 10)
+	if err := x.Where("user_id=?", userID).Asc("remind_unix").Find(&reminders); err != nil {
+		return nil, err
+	}
+	for _, r := range reminders {
+		if err := r.loadAttributes(x); err != nil {
+			return nil, err
+		}
+	}
+	return reminders, nil
+}
+
+// DeleteIssueReminder cancels a reminder
+func DeleteIssueReminder(r *IssueReminder) error {
+	_, err := x.ID(r.ID).Delete(new(IssueReminder))
+	return err
+}
+
+// sendIssueReminder notifies the user of a due reminder via a notification
+// and, if the user wants to get them, an email.
+func sendIssueReminder(r *IssueReminder) error {
+	if err := r.loadAttributes(x); err != nil {
+		return err
+	}
+
+	// The user may have lost access to the issue in the meantime.
+	perm, err := getUserRepoPermission(x, r.Issue.Repo, r.User)
+	if err != nil {
+		return err
+	}
+	if !perm.CanReadIssuesOrPulls(r.Issue.IsPull) {
+		return nil
+	}
+
+	notification, err := getIssueNotification(x, r.UserID, r.IssueID)
+	if err != nil {
+		return err
+	}
+	if notification.ID == 0 {
+		err = createIssueNotification(x, r.UserID, r.Issue, r.UserID)
+	} else {
+		err = updateIssueNotification(x, r.UserID, r.IssueID, r.UserID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if setting.Service.EnableNotifyMail && r.User.EmailNotifications() == EmailNotificationsEnabled {
+		SendIssueReminderMail(r.Issue, r.User)
+	}
+	return nil
+}
+
+// SendDueIssueReminders sends all reminders which are due and removes them. The
+// reminders whose user, issue or repository do not exist anymore are removed,
+// the other ones which fail are retried for issueReminderRetryDuration.
+func SendDueIssueReminders() {
+	log.Trace("Doing: SendDueIssueReminders")
+
+	reminders := make([]*IssueReminder, 0, 10)
+	if err := x.Where("remind_unix<=?", timeutil.TimeStampNow()).Find(&reminders); err != nil {
+		log.Error("Find due issue reminders: %v", err)
+		return
+	}
+
+	for _, r := range reminders {
+		if err := sendIssueReminder(r); err != nil {
+			log.Error("sendIssueReminder [%d]: %v", r.ID, err)
+			if !IsErrUserNotExist(err) && !IsErrIssueNotExist(err) && !IsErrRepoNotExist(err) &&
+				time.Since(r.RemindUnix.AsTime()) < issueReminderRetryDuration {
+				continue
+			}
+		}
+		if err := DeleteIssueReminder(r); err != nil {
+			log.Error("DeleteIssueReminder [%d]: %v", r.ID, err)
+		}
+	}
+
+	log.Trace("Finished: SendDueIssueReminders")
+}
@@ -0,0 +1,63 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueReminder(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+
+	due, err := CreateIssueReminder(user, issue, timeutil.TimeStampNow().Add(-60))
+	assert.NoError(t, err)
+	pending, err := CreateIssueReminder(user, issue, timeutil.TimeStampNow().Add(3600))
+	assert.NoError(t, err)
+
+	reminders, err := GetIssueRemindersByUser(user.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, reminders, 2) {
+		assert.EqualValues(t, due.ID, reminders[0].ID)
+		assert.EqualValues(t, issue.ID, reminders[0].Issue.ID)
+	}
+
+	SendDueIssueReminders()
+	AssertNotExistsBean(t, &IssueReminder{ID: due.ID})
+	AssertExistsAndLoadBean(t, &IssueReminder{ID: pending.ID})
+	AssertExistsAndLoadBean(t, &Notification{UserID: user.ID, IssueID: issue.ID, Status: NotificationStatusUnread})
+
+	assert.NoError(t, DeleteIssueReminder(pending))
+	AssertNotExistsBean(t, &IssueReminder{ID: pending.ID})
+}
+
+func TestSendDueIssueReminders_Unloadable(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	otherIssue := AssertExistsAndLoadBean(t, &Issue{ID: 2}).(*Issue)
+
+	// The reminders whose user, issue or repository are gone are removed
+	unknownUser := &IssueReminder{UserID: 10000, IssueID: issue.ID, RemindUnix: timeutil.TimeStampNow()}
+	unknownIssue := &IssueReminder{UserID: user.ID, IssueID: 10000, RemindUnix: timeutil.TimeStampNow()}
+	unknownRepo := &IssueReminder{UserID: user.ID, IssueID: otherIssue.ID, RemindUnix: timeutil.TimeStampNow()}
+	_, err := x.Insert(unknownUser, unknownIssue, unknownRepo)
+	assert.NoError(t, err)
+	_, err = x.ID(otherIssue.ID).Cols("repo_id").Update(&Issue{RepoID: 10000})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, GetCount(t, &IssueReminder{}))
+
+	SendDueIssueReminders()
+	AssertNotExistsBean(t, &IssueReminder{ID: unknownUser.ID})
+	AssertNotExistsBean(t, &IssueReminder{ID: unknownIssue.ID})
+	AssertNotExistsBean(t, &IssueReminder{ID: unknownRepo.ID})
+}
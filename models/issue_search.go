@@ -0,0 +1,103 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strings"
+
+	"xorm.io/builder"
+)
+
+// IssueSearchFilters represents the qualifier filters of an issue search query,
+// e.g. "reviewed-by:user reacted:+1 no:label".
+type IssueSearchFilters struct {
+	ReviewedByID int64
+	Reaction     string
+	NoLabel      bool
+	NoMilestone  bool
+	NoAssignee   bool
+}
+
+// IsEmpty returns true if no filter is set.
+func (f *IssueSearchFilters) IsEmpty() bool {
+	return f == nil || (f.ReviewedByID == 0 && len(f.Reaction) == 0 &&
+		!f.NoLabel && !f.NoMilestone && !f.NoAssignee)
+}
+
+// ParseIssueSearchQuery splits the qualifiers supported by IssueSearchFilters off
+// the query and returns the remaining keyword together with the filters.
+// Tokens which are not a known qualifier are kept in the keyword. The doer is
+// used to resolve "reviewed-by:@me" and may be nil. An ErrUserNotExist is
+// returned if the user of a "reviewed-by" qualifier does not exist.
+func ParseIssueSearchQuery(doer *User, query string) (string, *IssueSearchFilters, error) {
+	filters := &IssueSearchFilters{}
+	keywords := make([]string, 0, 5)
+	for _, token := range strings.Fields(query) {
+		idx := strings.IndexByte(token, ':')
+		if idx <= 0 || idx == len(token)-1 {
+			keywords = append(keywords, token)
+			continue
+		}
+
+		key, value := strings.ToLower(token[:idx]), token[idx+1:]
+		switch key {
+		case "reviewed-by":
+			if value == "@me" {
+				if doer == nil {
+					return "", nil, ErrUserNotExist{Name: value}
+				}
+				filters.ReviewedByID = doer.ID
+				continue
+			}
+			reviewer, err := GetUserByName(value)
+			if err != nil {
+				return "", nil, err
+			}
+			filters.ReviewedByID = reviewer.ID
+		case "reacted":
+			filters.Reaction = value
+		case "no":
+			switch strings.ToLower(value) {
+			case "label":
+				filters.NoLabel = true
+			case "milestone":
+				filters.NoMilestone = true
+			case "assignee":
+				filters.NoAssignee = true
+			default:
+				keywords = append(keywords, token)
+			}
+		default:
+			keywords = append(keywords, token)
+		}
+	}
+	return strings.Join(keywords, " "), filters, nil
+}
+
+func (f *IssueSearchFilters) toCond() builder.Cond {
+	cond := builder.NewCond()
+	if f == nil {
+		return cond
+	}
+
+	if f.ReviewedByID > 0 {
+		cond = cond.And(builder.In("issue.id", builder.Select("issue_id").From("review").
+			Where(builder.Eq{"reviewer_id": f.ReviewedByID}.And(builder.In("type", ReviewTypeApprove, ReviewTypeComment, ReviewTypeReject)))))
+	}
+	if len(f.Reaction) > 0 {
+		cond = cond.And(builder.In("issue.id", builder.Select("issue_id").From("reaction").
+			Where(builder.Eq{"type": f.Reaction, "comment_id": 0})))
+	}
+	if f.NoLabel {
+		cond = cond.And(builder.NotIn("issue.id", builder.Select("issue_id").From("issue_label")))
+	}
+	if f.NoMilestone {
+		cond = cond.And(builder.Eq{"issue.milestone_id": 0}.Or(builder.IsNull{"issue.milestone_id"}))
+	}
+	if f.NoAssignee {
+		cond = cond.And(builder.NotIn("issue.id", builder.Select("issue_id").From("issue_assignees")))
+	}
+	return cond
+}
@@ -0,0 +1,86 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIssueSearchQuery(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	keyword, filters, err := ParseIssueSearchQuery(doer, "fix reviewed-by:user1 bug reacted:+1 no:label no:Milestone no:assignee")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "fix bug", keyword)
+	assert.EqualValues(t, &IssueSearchFilters{
+		ReviewedByID: 1,
+		Reaction:     "+1",
+		NoLabel:      true,
+		NoMilestone:  true,
+		NoAssignee:   true,
+	}, filters)
+
+	keyword, filters, err = ParseIssueSearchQuery(doer, "reviewed-by:@me no:such key: :value")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "no:such key: :value", keyword)
+	assert.EqualValues(t, 2, filters.ReviewedByID)
+
+	keyword, filters, err = ParseIssueSearchQuery(nil, "plain")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "plain", keyword)
+	assert.True(t, filters.IsEmpty())
+
+	_, _, err = ParseIssueSearchQuery(nil, "reviewed-by:@me")
+	assert.True(t, IsErrUserNotExist(err))
+	_, _, err = ParseIssueSearchQuery(doer, "reviewed-by:nonexistent")
+	assert.True(t, IsErrUserNotExist(err))
+}
+
+func TestIssues_SearchFilters(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	_, err := CreateReaction(&ReactionOptions{Type: "heart", Doer: doer, Issue: issue})
+	assert.NoError(t, err)
+
+	for _, test := range []struct {
+		Filters          IssueSearchFilters
+		ExpectedIssueIDs []int64
+	}{
+		{IssueSearchFilters{ReviewedByID: 1}, []int64{3, 2}},
+		{IssueSearchFilters{ReviewedByID: 2}, []int64{3}},
+		// user5 has only been requested to review issue 3
+		{IssueSearchFilters{ReviewedByID: 5}, []int64{}},
+		{IssueSearchFilters{Reaction: "heart"}, []int64{1}},
+		{IssueSearchFilters{Reaction: "laugh"}, []int64{}},
+		{IssueSearchFilters{NoLabel: true}, []int64{3}},
+		{IssueSearchFilters{NoMilestone: true}, []int64{3, 1}},
+		{IssueSearchFilters{NoAssignee: true}, []int64{3, 2}},
+		{IssueSearchFilters{NoLabel: true, ReviewedByID: 1}, []int64{3}},
+	} {
+		filters := test.Filters
+		issues, err := Issues(&IssuesOptions{
+			RepoIDs:  []int64{1},
+			IsClosed: util.OptionalBoolFalse,
+			Filters:  &filters,
+		})
+		assert.NoError(t, err)
+		if assert.Len(t, issues, len(test.ExpectedIssueIDs)) {
+			for i, issue := range issues {
+				assert.EqualValues(t, test.ExpectedIssueIDs[i], issue.ID)
+			}
+		}
+
+		stats, err := GetIssueStats(&IssueStatsOptions{RepoID: 1, Filters: &filters})
+		assert.NoError(t, err)
+		assert.EqualValues(t, len(test.ExpectedIssueIDs), stats.OpenCount)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// IssueSLA represents the response and resolution times of an issue and the
+// targets which have been set by its labels.
+type IssueSLA struct {
+	Issue *Issue
+	// The strictest targets of the labels of the issue, 0 if there is no target
+	ResponseTargetHours   int64
+	ResolutionTargetHours int64
+}
+
+// ResponseTime returns the seconds between the creation of the issue and the
+// first response. If there is no response yet, the seconds until the issue
+// has been closed or until now are returned.
+func (sla *IssueSLA) ResponseTime() int64 {
+	end := sla.Issue.FirstResponseUnix
+	if end == 0 {
+		end = sla.resolvedUnix()
+	}
+	return int64(end - sla.Issue.CreatedUnix)
+}
+
+// ResolutionTime returns the seconds between the creation of the issue and
+// its closing, or until now if the issue is still open.
+func (sla *IssueSLA) ResolutionTime() int64 {
+	return int64(sla.resolvedUnix() - sla.Issue.CreatedUnix)
+}
+
+func (sla *IssueSLA) resolvedUnix() timeutil.TimeStamp {
+	if sla.Issue.IsClosed && sla.Issue.ClosedUnix > 0 {
+		return sla.Issue.ClosedUnix
+	}
+	return timeutil.TimeStampNow()
+}
+
+// IsResponseBreached returns true if the first response took longer than the target.
+func (sla *IssueSLA) IsResponseBreached() bool {
+	return sla.ResponseTargetHours > 0 && sla.ResponseTime() > sla.ResponseTargetHours*3600
+}
+
+// IsResolutionBreached returns true if the resolution took longer than the target.
+func (sla *IssueSLA) IsResolutionBreached() bool {
+	return sla.ResolutionTargetHours > 0 && sla.ResolutionTime() > sla.ResolutionTargetHours*3600
+}
+
+// IsBreached returns true if any target of the issue has been missed.
+func (sla *IssueSLA) IsBreached() bool {
+	return sla.IsResponseBreached() || sla.IsResolutionBreached()
+}
+
+// IssueSLAOptions represents the options to query the SLAs of issues.
+type IssueSLAOptions struct {
+	RepoID   int64
+	IsClosed util.OptionalBool
+	// Only return issues which have missed a target
+	BreachedOnly bool
+}
+
+// GetIssueSLAs returns the SLAs of the issues of a repository which have a
+// label with a response or resolution target, the oldest issues come first.
+func GetIssueSLAs(opts *IssueSLAOptions) ([]*IssueSLA, error) {
+	cond := builder.NewCond().
+		And(builder.Eq{"issue.repo_id": opts.RepoID}).
+		And(builder.In("issue.id", builder.Select("issue_label.issue_id").
+			From("issue_label").
+			Join("INNER", "label", "label.id = issue_label.label_id").
+			Where(builder.Gt{"label.response_target_hours": 0}.
+				Or(builder.Gt{"label.resolution_target_hours": 0}))))
+	switch opts.IsClosed {
+	case util.OptionalBoolTrue:
+		cond = cond.And(builder.Eq{"issue.is_closed": true})
+	case util.OptionalBoolFalse:
+		cond = cond.And(builder.Eq{"issue.is_closed": false})
+	}
+
+	issues := make(IssueList, 0, 10)
+	if err := x.Where(cond).Asc("issue.created_unix").Find(&issues); err != nil {
+		return nil, err
+	}
+	if err := issues.loadLabels(x); err != nil {
+		return nil, err
+	}
+
+	slas := make([]*IssueSLA, 0, len(issues))
+	for _, issue := range issues {
+		sla := &IssueSLA{Issue: issue}
+		for _, label := range issue.Labels {
+			sla.ResponseTargetHours = minTarget(sla.ResponseTargetHours, label.ResponseTargetHours)
+			sla.ResolutionTargetHours = minTarget(sla.ResolutionTargetHours, label.ResolutionTargetHours)
+		}
+		if opts.BreachedOnly && !sla.IsBreached() {
+			continue
+		}
+		slas = append(slas, sla)
+	}
+	return slas, nil
+}
+
+// minTarget returns the stricter of two targets, where 0 means no target.
+func minTarget(a, b int64) int64 {
+	if b <= 0 {
+		return a
+	}
+	if a <= 0 || b < a {
+		return b
+	}
+	return a
+}
+
+// updateFirstResponse records now as the time of the first response to the
+// issue, which is a comment or review by anyone but the poster.
+func (issue *Issue) updateFirstResponse(e Engine, doer *User) error {
+	if issue.FirstResponseUnix > 0 || doer.ID == issue.PosterID {
+		return nil
+	}
+	responseUnix := timeutil.TimeStampNow()
+	if _, err := e.Exec("UPDATE `issue` SET first_response_unix=? WHERE id=? AND first_response_unix=0", responseUnix, issue.ID); err != nil {
+		return err
+	}
+	issue.FirstResponseUnix = responseUnix
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssue_UpdateFirstResponse(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	poster := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	// A comment by the poster is no response
+	_, err := CreateIssueComment(poster, repo, issue, "more details", nil)
+	assert.NoError(t, err)
+	issue = AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.EqualValues(t, 0, issue.FirstResponseUnix)
+
+	comment, err := CreateIssueComment(doer, repo, issue, "a response", nil)
+	assert.NoError(t, err)
+	issue = AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.InDelta(t, int64(comment.CreatedUnix), int64(issue.FirstResponseUnix), 1)
+
+	// Later comments do not change the first response
+	firstResponse := issue.FirstResponseUnix
+	_, err = x.ID(1).Cols("first_response_unix").Update(&Issue{FirstResponseUnix: firstResponse - 100})
+	assert.NoError(t, err)
+	_, err = CreateIssueComment(doer, repo, issue, "another response", nil)
+	assert.NoError(t, err)
+	AssertExistsAndLoadBean(t, &Issue{ID: 1, FirstResponseUnix: firstResponse - 100})
+}
+
+func TestGetIssueSLAs(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	slas, err := GetIssueSLAs(&IssueSLAOptions{RepoID: 1, IsClosed: util.OptionalBoolNone})
+	assert.NoError(t, err)
+	assert.Len(t, slas, 0)
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	label := AssertExistsAndLoadBean(t, &Label{ID: 1}).(*Label)
+	label.ResponseTargetHours = 2
+	label.ResolutionTargetHours = 48
+	assert.NoError(t, UpdateLabel(label, doer))
+
+	// Issue 1 has been answered in time but is open for years
+	_, err = x.ID(1).Cols("first_response_unix").Update(&Issue{FirstResponseUnix: 946684800 + 3600})
+	assert.NoError(t, err)
+
+	slas, err = GetIssueSLAs(&IssueSLAOptions{RepoID: 1, IsClosed: util.OptionalBoolFalse})
+	assert.NoError(t, err)
+	if assert.Len(t, slas, 2) {
+		assert.EqualValues(t, 1, slas[0].Issue.ID)
+		assert.EqualValues(t, 2, slas[0].ResponseTargetHours)
+		assert.EqualValues(t, 48, slas[0].ResolutionTargetHours)
+		assert.EqualValues(t, 3600, slas[0].ResponseTime())
+		assert.False(t, slas[0].IsResponseBreached())
+		assert.True(t, slas[0].IsResolutionBreached())
+
+		assert.EqualValues(t, 2, slas[1].Issue.ID)
+		assert.True(t, slas[1].IsResponseBreached())
+	}
+
+	slas, err = GetIssueSLAs(&IssueSLAOptions{RepoID: 1, IsClosed: util.OptionalBoolTrue, BreachedOnly: true})
+	assert.NoError(t, err)
+	assert.Len(t, slas, 0)
+}
+
+func TestIssueSLA_ClosedIssue(t *testing.T) {
+	sla := &IssueSLA{
+		Issue: &Issue{
+			IsClosed:    true,
+			CreatedUnix: timeutil.TimeStamp(1000),
+			ClosedUnix:  timeutil.TimeStamp(1000 + 5*3600),
+		},
+		ResponseTargetHours:   1,
+		ResolutionTargetHours: 6,
+	}
+	assert.EqualValues(t, 5*3600, sla.ResponseTime())
+	assert.EqualValues(t, 5*3600, sla.ResolutionTime())
+	assert.True(t, sla.IsResponseBreached())
+	assert.False(t, sla.IsResolutionBreached())
+	assert.True(t, sla.IsBreached())
+}
+
+func TestMinTarget(t *testing.T) {
+	assert.EqualValues(t, 0, minTarget(0, 0))
+	assert.EqualValues(t, 5, minTarget(0, 5))
+	assert.EqualValues(t, 5, minTarget(5, 0))
+	assert.EqualValues(t, 3, minTarget(5, 3))
+	assert.EqualValues(t, 3, minTarget(3, 5))
+}
@@ -207,6 +207,13 @@ func TestIssues(t *testing.T) {
 			},
 			[]int64{}, // issues with **both** label 1 and 2, none of these issues matches, TODO: add more tests
 		},
+		{
+			IssuesOptions{
+				RepoIDs:  []int64{1},
+				SortType: "labelpriority",
+			},
+			[]int64{5, 2, 1, 3},
+		},
 	} {
 		issues, err := Issues(&test.Opts)
 		assert.NoError(t, err)
@@ -320,3 +327,23 @@ func TestIssue_SearchIssueIDsByKeyword(t *testing.T) {
 	assert.EqualValues(t, 1, total)
 	assert.EqualValues(t, []int64{1}, ids)
 }
+
+func TestSearchIssueIDsByTitleWords(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	ids, err := SearchIssueIDsByTitleWords("issue2 is a bug", 1, 10)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []int64{2}, ids)
+
+	ids, err = SearchIssueIDsByTitleWords("an issue", 1, 10)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []int64{5, 1, 2, 3}, ids)
+
+	ids, err = SearchIssueIDsByTitleWords("an issue", 1, 2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []int64{5, 1}, ids)
+
+	ids, err = SearchIssueIDsByTitleWords("a b", 1, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+}
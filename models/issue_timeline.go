@@ -0,0 +1,133 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// GetIssueTimeline returns all comments and events of the issue in
+// chronological order. Code comments of pending reviews, references and
+// linked issues the doer is not allowed to see are left out.
+func GetIssueTimeline(issue *Issue, doer *User, since int64) ([]*Comment, error) {
+	comments, err := findComments(x, FindCommentsOptions{
+		IssueID: issue.ID,
+		Since:   since,
+		Type:    CommentTypeUnknown,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	timeline := make([]*Comment, 0, len(comments))
+	for _, comment := range comments {
+		comment.Issue = issue
+		if err = comment.loadTimelineAttributes(x); err != nil {
+			return nil, err
+		}
+		if comment.Review != nil && comment.Review.Type == ReviewTypePending {
+			continue
+		}
+		timeline = append(timeline, comment)
+	}
+	return FilterCrossReferences(timeline, doer)
+}
+
+// loadTimelineAttributes loads the poster and everything referenced by the
+// type of the comment.
+func (c *Comment) loadTimelineAttributes(e Engine) (err error) {
+	if err = c.loadPoster(e); err != nil {
+		return err
+	}
+
+	switch c.Type {
+	case CommentTypeLabel:
+		err = c.LoadLabel()
+	case CommentTypeMilestone:
+		err = c.LoadMilestone()
+	case CommentTypeAssignees, CommentTypeReviewRequest, CommentTypeDismissReview:
+		err = c.LoadAssigneeUser()
+	case CommentTypeAddDependency, CommentTypeRemoveDependency,
+		CommentTypeIssueMovedFrom, CommentTypeIssueMovedTo:
+		err = c.LoadDepIssueDetails()
+		if IsErrIssueNotExist(err) {
+			err = nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	switch c.Type {
+	case CommentTypeCode, CommentTypeReview, CommentTypeReviewRequest:
+		if c.ReviewID > 0 {
+			err = c.loadReview(e)
+			if IsErrReviewNotExist(err) {
+				c.Review, err = nil, nil
+			}
+		}
+	}
+	return err
+}
+
+// TimelineAPIFormat converts a Comment to the api.TimelineComment format,
+// the attributes have to be loaded by GetIssueTimeline.
+func (c *Comment) TimelineAPIFormat() *api.TimelineComment {
+	apiComment := &api.TimelineComment{
+		ID:              c.ID,
+		Type:            c.Type.String(),
+		Poster:          c.Poster.APIFormat(),
+		HTMLURL:         c.HTMLURL(),
+		IssueURL:        c.IssueURL(),
+		PRURL:           c.PRURL(),
+		Body:            c.Content,
+		Created:         c.CreatedUnix.AsTime(),
+		Updated:         c.UpdatedUnix.AsTime(),
+		RemovedAssignee: c.RemovedAssignee,
+		OldTitle:        c.OldTitle,
+		NewTitle:        c.NewTitle,
+		ReviewID:        c.ReviewID,
+	}
+
+	switch c.Type {
+	case CommentTypeLabel:
+		if c.Label != nil {
+			apiComment.Label = c.Label.APIFormat()
+		}
+		apiComment.RemovedLabel = c.Content != "1"
+	case CommentTypeMilestone:
+		if c.OldMilestone != nil {
+			apiComment.OldMilestone = c.OldMilestone.APIFormat()
+		}
+		if c.Milestone != nil {
+			apiComment.Milestone = c.Milestone.APIFormat()
+		}
+	case CommentTypeAssignees, CommentTypeReviewRequest, CommentTypeDismissReview:
+		if c.Assignee != nil {
+			apiComment.Assignee = c.Assignee.APIFormat()
+		}
+	case CommentTypeDeleteBranch:
+		apiComment.OldRef = c.CommitSHA
+	case CommentTypeChangeTargetBranch:
+		apiComment.OldRef = c.OldRef
+		apiComment.NewRef = c.NewRef
+	case CommentTypeCommitRef:
+		apiComment.RefCommitSHA = c.CommitSHA
+	case CommentTypeCode:
+		apiComment.Path = c.TreePath
+		apiComment.Line = c.Line
+	}
+	if c.Review != nil {
+		apiComment.ReviewState = c.Review.Type.APIState()
+	}
+	if c.DependentIssue != nil {
+		apiComment.DependentIssue = c.DependentIssue.APIFormat()
+	}
+	if c.RefIssue != nil {
+		apiComment.RefIssue = c.RefIssue.APIFormat()
+		apiComment.RefCommentID = c.RefCommentID
+	}
+	return apiComment
+}
@@ -0,0 +1,104 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetIssueTimeline(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+
+	timeline, err := GetIssueTimeline(issue, doer, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, timeline, 3) {
+		assert.EqualValues(t, 1, timeline[0].ID)
+		assert.EqualValues(t, 2, timeline[1].ID)
+		assert.EqualValues(t, 3, timeline[2].ID)
+	}
+
+	apiLabel := timeline[0].TimelineAPIFormat()
+	assert.Equal(t, "label", apiLabel.Type)
+	assert.False(t, apiLabel.RemovedLabel)
+	if assert.NotNil(t, apiLabel.Label) {
+		assert.EqualValues(t, 1, apiLabel.Label.ID)
+	}
+
+	apiComment := timeline[1].TimelineAPIFormat()
+	assert.Equal(t, "comment", apiComment.Type)
+	assert.Equal(t, "good work!", apiComment.Body)
+	assert.EqualValues(t, 3, apiComment.Poster.ID)
+
+	// The states of the reviews are the ones of the API
+	for reviewType, state := range map[ReviewType]api.ReviewStateType{
+		ReviewTypeApprove: api.ReviewStateApproved,
+		ReviewTypeReject:  api.ReviewStateRequestChanges,
+		ReviewTypeRequest: api.ReviewStateRequestReview,
+	} {
+		comment := &Comment{Type: CommentTypeReview, Issue: issue, Poster: doer, Review: &Review{Type: reviewType}}
+		assert.Equal(t, state, comment.TimelineAPIFormat().ReviewState)
+	}
+
+	// Code comments of pending reviews are left out
+	issue = AssertExistsAndLoadBean(t, &Issue{ID: 2}).(*Issue)
+	timeline, err = GetIssueTimeline(issue, doer, 0)
+	assert.NoError(t, err)
+	for _, comment := range timeline {
+		assert.NotEqual(t, int64(4), comment.ID)
+	}
+}
+
+func TestGetIssueTimeline_LinkedIssues(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	// repo2 is private and can only be read by its owner user2
+	newRepo := AssertExistsAndLoadBean(t, &Repository{ID: 2}).(*Repository)
+	_, err := x.Insert(&RepoUnit{RepoID: newRepo.ID, Type: UnitTypeIssues, Config: new(IssuesConfig)})
+	assert.NoError(t, err)
+	stub, err := MoveIssue(doer, issue, newRepo)
+	assert.NoError(t, err)
+
+	hasMovedTo := func(timeline []*Comment) bool {
+		for _, comment := range timeline {
+			if comment.Type == CommentTypeIssueMovedTo {
+				return true
+			}
+		}
+		return false
+	}
+
+	timeline, err := GetIssueTimeline(stub, doer, 0)
+	assert.NoError(t, err)
+	assert.True(t, hasMovedTo(timeline))
+
+	user4 := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	timeline, err = GetIssueTimeline(stub, user4, 0)
+	assert.NoError(t, err)
+	assert.False(t, hasMovedTo(timeline))
+
+	timeline, err = GetIssueTimeline(stub, nil, 0)
+	assert.NoError(t, err)
+	assert.False(t, hasMovedTo(timeline))
+}
+
+func TestCommentType_String(t *testing.T) {
+	assert.Equal(t, "comment", CommentTypeComment.String())
+	assert.Equal(t, "review", CommentTypeReview.String())
+	assert.Equal(t, "issue_moved_to", CommentTypeIssueMovedTo.String())
+	assert.Equal(t, "unknown", CommentTypeUnknown.String())
+	assert.Equal(t, "review_request", CommentTypeReviewRequest.String())
+	assert.Equal(t, "dismiss_review", CommentTypeDismissReview.String())
+	assert.Equal(t, "change_target_branch", CommentTypeChangeTargetBranch.String())
+	assert.Len(t, commentStrings, int(CommentTypeChangeTargetBranch)+1)
+}
@@ -0,0 +1,194 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"sort"
+)
+
+// AssigneeWorkload represents the open issues and pull requests which are
+// assigned to a user, broken down by label and milestone.
+type AssigneeWorkload struct {
+	Assignee   *User
+	OpenIssues int64
+	OpenPulls  int64
+	Labels     []*LabelWorkload
+	Milestones []*MilestoneWorkload
+}
+
+// LabelWorkload represents the number of open issues and pull requests with a label.
+type LabelWorkload struct {
+	Label *Label
+	Count int64
+}
+
+// MilestoneWorkload represents the number of open issues and pull requests in a milestone.
+type MilestoneWorkload struct {
+	Milestone *Milestone
+	Count     int64
+}
+
+// GetAssigneeWorkloads returns the workload of every user who is assigned to
+// an open issue or pull request of the given repositories, users with the
+// most open issues and pull requests come first.
+func GetAssigneeWorkloads(repoIDs []int64) ([]*AssigneeWorkload, error) {
+	workloads := make([]*AssigneeWorkload, 0, 10)
+	if len(repoIDs) == 0 {
+		return workloads, nil
+	}
+
+	counts := make([]*struct {
+		AssigneeID int64
+		IsPull     bool
+		Count      int64
+	}, 0, 10)
+	if err := x.Table("issue_assignees").
+		Select("issue_assignees.assignee_id AS assignee_id, issue.is_pull AS is_pull, COUNT(*) AS count").
+		Join("INNER", "issue", "issue.id = issue_assignees.issue_id").
+		In("issue.repo_id", repoIDs).
+		And("issue.is_closed = ?", false).
+		GroupBy("issue_assignees.assignee_id, issue.is_pull").
+		Find(&counts); err != nil {
+		return nil, err
+	}
+
+	workloadMap := make(map[int64]*AssigneeWorkload, len(counts))
+	for _, c := range counts {
+		workload, ok := workloadMap[c.AssigneeID]
+		if !ok {
+			workload = &AssigneeWorkload{}
+			workloadMap[c.AssigneeID] = workload
+		}
+		if c.IsPull {
+			workload.OpenPulls = c.Count
+		} else {
+			workload.OpenIssues = c.Count
+		}
+	}
+	if len(workloadMap) == 0 {
+		return workloads, nil
+	}
+
+	assigneeIDs := make([]int64, 0, len(workloadMap))
+	for id := range workloadMap {
+		assigneeIDs = append(assigneeIDs, id)
+	}
+	assignees, err := GetUsersByIDs(assigneeIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, assignee := range assignees {
+		workloadMap[assignee.ID].Assignee = assignee
+	}
+
+	if err = loadLabelWorkloads(repoIDs, workloadMap); err != nil {
+		return nil, err
+	}
+	if err = loadMilestoneWorkloads(repoIDs, workloadMap); err != nil {
+		return nil, err
+	}
+
+	for _, workload := range workloadMap {
+		// Skip users who have been deleted in the meantime
+		if workload.Assignee != nil {
+			workloads = append(workloads, workload)
+		}
+	}
+	sort.Slice(workloads, func(i, j int) bool {
+		ti := workloads[i].OpenIssues + workloads[i].OpenPulls
+		tj := workloads[j].OpenIssues + workloads[j].OpenPulls
+		if ti != tj {
+			return ti > tj
+		}
+		return workloads[i].Assignee.LowerName < workloads[j].Assignee.LowerName
+	})
+	return workloads, nil
+}
+
+func loadLabelWorkloads(repoIDs []int64, workloadMap map[int64]*AssigneeWorkload) error {
+	counts := make([]*struct {
+		AssigneeID int64
+		LabelID    int64
+		Count      int64
+	}, 0, 10)
+	if err := x.Table("issue_assignees").
+		Select("issue_assignees.assignee_id AS assignee_id, issue_label.label_id AS label_id, COUNT(*) AS count").
+		Join("INNER", "issue", "issue.id = issue_assignees.issue_id").
+		Join("INNER", "issue_label", "issue_label.issue_id = issue.id").
+		In("issue.repo_id", repoIDs).
+		And("issue.is_closed = ?", false).
+		GroupBy("issue_assignees.assignee_id, issue_label.label_id").
+		Find(&counts); err != nil {
+		return err
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+
+	labelIDs := make([]int64, 0, len(counts))
+	for _, c := range counts {
+		labelIDs = append(labelIDs, c.LabelID)
+	}
+	labels := make(map[int64]*Label, len(labelIDs))
+	if err := x.In("id", labelIDs).Find(&labels); err != nil {
+		return err
+	}
+
+	for _, c := range counts {
+		label, ok := labels[c.LabelID]
+		if !ok {
+			continue
+		}
+		workload := workloadMap[c.AssigneeID]
+		workload.Labels = append(workload.Labels, &LabelWorkload{
+			Label: label,
+			Count: c.Count,
+		})
+	}
+	return nil
+}
+
+func loadMilestoneWorkloads(repoIDs []int64, workloadMap map[int64]*AssigneeWorkload) error {
+	counts := make([]*struct {
+		AssigneeID  int64
+		MilestoneID int64
+		Count       int64
+	}, 0, 10)
+	if err := x.Table("issue_assignees").
+		Select("issue_assignees.assignee_id AS assignee_id, issue.milestone_id AS milestone_id, COUNT(*) AS count").
+		Join("INNER", "issue", "issue.id = issue_assignees.issue_id").
+		In("issue.repo_id", repoIDs).
+		And("issue.is_closed = ?", false).
+		And("issue.milestone_id > 0").
+		GroupBy("issue_assignees.assignee_id, issue.milestone_id").
+		Find(&counts); err != nil {
+		return err
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+
+	milestoneIDs := make([]int64, 0, len(counts))
+	for _, c := range counts {
+		milestoneIDs = append(milestoneIDs, c.MilestoneID)
+	}
+	milestones := make(map[int64]*Milestone, len(milestoneIDs))
+	if err := x.In("id", milestoneIDs).Find(&milestones); err != nil {
+		return err
+	}
+
+	for _, c := range counts {
+		milestone, ok := milestones[c.MilestoneID]
+		if !ok {
+			continue
+		}
+		workload := workloadMap[c.AssigneeID]
+		workload.Milestones = append(workload.Milestones, &MilestoneWorkload{
+			Milestone: milestone,
+			Count:     c.Count,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAssigneeWorkloads(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	_, err := x.Insert(&IssueAssignees{AssigneeID: 2, IssueID: 2})
+	assert.NoError(t, err)
+
+	workloads, err := GetAssigneeWorkloads([]int64{1, 3})
+	assert.NoError(t, err)
+	if assert.Len(t, workloads, 2) {
+		assert.EqualValues(t, 1, workloads[0].Assignee.ID)
+		assert.EqualValues(t, 2, workloads[0].OpenIssues)
+		assert.EqualValues(t, 0, workloads[0].OpenPulls)
+		if assert.Len(t, workloads[0].Labels, 1) {
+			assert.EqualValues(t, 1, workloads[0].Labels[0].Label.ID)
+			assert.EqualValues(t, 1, workloads[0].Labels[0].Count)
+		}
+		assert.Len(t, workloads[0].Milestones, 0)
+
+		assert.EqualValues(t, 2, workloads[1].Assignee.ID)
+		assert.EqualValues(t, 0, workloads[1].OpenIssues)
+		assert.EqualValues(t, 1, workloads[1].OpenPulls)
+		if assert.Len(t, workloads[1].Milestones, 1) {
+			assert.EqualValues(t, 1, workloads[1].Milestones[0].Milestone.ID)
+			assert.EqualValues(t, 1, workloads[1].Milestones[0].Count)
+		}
+	}
+
+	workloads, err = GetAssigneeWorkloads([]int64{3})
+	assert.NoError(t, err)
+	if assert.Len(t, workloads, 1) {
+		assert.EqualValues(t, 1, workloads[0].OpenIssues)
+		assert.Len(t, workloads[0].Labels, 0)
+	}
+
+	workloads, err = GetAssigneeWorkloads(nil)
+	assert.NoError(t, err)
+	assert.Len(t, workloads, 0)
+}
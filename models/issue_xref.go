@@ -0,0 +1,232 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-xorm/xorm"
+)
+
+// findCrossReferences returns the issues referenced by "#index" or
+// "owner/repo#index" in the content, which the doer is allowed to read.
+// The issue itself is never returned.
+func (issue *Issue) findCrossReferences(e Engine, doer *User, content string) ([]*Issue, error) {
+	refIssues := make([]*Issue, 0, 5)
+	marked := make(map[int64]bool)
+	for _, m := range issueReferenceKeywordsPat.FindAllStringSubmatch(content, -1) {
+		if len(m[3]) == 0 {
+			continue
+		}
+		index, err := strconv.ParseInt(strings.TrimPrefix(m[3], "#"), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		refRepo := issue.Repo
+		if len(m[1]) > 0 && len(m[2]) > 0 {
+			refRepo, err = getRepositoryByOwnerAndName(e, m[1], m[2])
+			if err != nil {
+				if IsErrRepoNotExist(err) {
+					continue
+				}
+				return nil, err
+			}
+		}
+
+		refIssue, err := getIssueByIndex(e, refRepo.ID, index)
+		if err != nil {
+			if IsErrIssueNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if refIssue.ID == issue.ID || marked[refIssue.ID] {
+			continue
+		}
+		marked[refIssue.ID] = true
+
+		if refRepo.ID != issue.RepoID {
+			perm, err := getUserRepoPermission(e, refRepo, doer)
+			if err != nil {
+				return nil, err
+			}
+			if !perm.CanReadIssuesOrPulls(refIssue.IsPull) {
+				continue
+			}
+		}
+		refIssue.Repo = refRepo
+		refIssues = append(refIssues, refIssue)
+	}
+	return refIssues, nil
+}
+
+// addCrossReferences creates a reference comment on every issue which is
+// referenced by the content of the issue, or of the comment if it is not nil.
+// References which have already been recorded are skipped.
+func (issue *Issue) addCrossReferences(e *xorm.Session, doer *User, comment *Comment) error {
+	if err := issue.loadRepo(e); err != nil {
+		return err
+	}
+
+	var (
+		content   = issue.Content
+		commentID int64
+		refType   = CommentTypeIssueRef
+	)
+	if comment != nil {
+		content = comment.Content
+		commentID = comment.ID
+		refType = CommentTypeCommentRef
+	} else if issue.IsPull {
+		refType = CommentTypePullRef
+	}
+
+	refIssues, err := issue.findCrossReferences(e, doer, content)
+	if err != nil {
+		return err
+	}
+	for _, refIssue := range refIssues {
+		has, err := e.Exist(&Comment{
+			Type:         refType,
+			IssueID:      refIssue.ID,
+			RefIssueID:   issue.ID,
+			RefCommentID: commentID,
+		})
+		if err != nil {
+			return err
+		} else if has {
+			continue
+		}
+
+		if _, err = createComment(e, &CreateCommentOptions{
+			Type:         refType,
+			Doer:         doer,
+			Repo:         refIssue.Repo,
+			Issue:        refIssue,
+			RefRepoID:    issue.RepoID,
+			RefIssueID:   issue.ID,
+			RefCommentID: commentID,
+			RefIsPull:    issue.IsPull,
+		}); err != nil {
+			return fmt.Errorf("createComment [ref_issue_id: %d]: %v", issue.ID, err)
+		}
+	}
+	return nil
+}
+
+// deleteCommentCrossReferences deletes the references which have been created
+// for the mentions in a comment.
+func deleteCommentCrossReferences(e Engine, commentID int64) error {
+	_, err := e.Where("ref_comment_id = ? AND type = ?", commentID, CommentTypeCommentRef).
+		Delete(new(Comment))
+	return err
+}
+
+// IsCrossReference returns true if the comment records that the issue has been
+// mentioned by another issue, pull request or comment.
+func (c *Comment) IsCrossReference() bool {
+	return c.RefIssueID > 0 && (c.Type == CommentTypeIssueRef ||
+		c.Type == CommentTypeCommentRef || c.Type == CommentTypePullRef)
+}
+
+// LoadRefIssue loads the issue and the repository which reference the issue of
+// the comment.
+func (c *Comment) LoadRefIssue() (err error) {
+	if c.RefIssueID == 0 || c.RefIssue != nil {
+		return nil
+	}
+	c.RefIssue, err = getIssueByID(x, c.RefIssueID)
+	if err != nil {
+		return err
+	}
+	if err = c.RefIssue.loadRepo(x); err != nil {
+		return err
+	}
+	c.RefRepo = c.RefIssue.Repo
+	return nil
+}
+
+// RefCommentHTMLURL returns the link to the comment or issue which references
+// the issue of the comment.
+func (c *Comment) RefCommentHTMLURL() string {
+	if c.RefIssue == nil {
+		return ""
+	}
+	if c.RefCommentID == 0 {
+		return c.RefIssue.HTMLURL()
+	}
+	return fmt.Sprintf("%s#%s", c.RefIssue.HTMLURL(), CommentHashTag(c.RefCommentID))
+}
+
+// CanSeeCrossReference returns true if the user is allowed to read the
+// repository which references the issue of the comment. Comments without a
+// reference from another repository can always be seen.
+func (c *Comment) CanSeeCrossReference(user *User) (bool, error) {
+	if c.RefRepoID == 0 {
+		return true, nil
+	}
+	if c.RefRepo == nil {
+		refRepo, err := getRepositoryByID(x, c.RefRepoID)
+		if err != nil {
+			if IsErrRepoNotExist(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		c.RefRepo = refRepo
+	}
+
+	perm, err := getUserRepoPermission(x, c.RefRepo, user)
+	if err != nil {
+		return false, err
+	}
+	if c.Type == CommentTypeCommitRef {
+		return perm.CanRead(UnitTypeCode), nil
+	}
+	return perm.CanReadIssuesOrPulls(c.RefIsPull), nil
+}
+
+// FilterCrossReferences returns the comments without the references and linked
+// issues the user is not allowed to see, the issues of the visible cross
+// references are loaded.
+func FilterCrossReferences(comments []*Comment, user *User) ([]*Comment, error) {
+	filtered := make([]*Comment, 0, len(comments))
+	for _, comment := range comments {
+		switch comment.Type {
+		case CommentTypeAddDependency, CommentTypeRemoveDependency,
+			CommentTypeIssueMovedFrom, CommentTypeIssueMovedTo:
+			canSee, err := comment.CanSeeDependentIssue(user)
+			if err != nil {
+				return nil, err
+			} else if canSee {
+				filtered = append(filtered, comment)
+			}
+			continue
+		}
+
+		if comment.IsCrossReference() {
+			if err := comment.LoadRefIssue(); err != nil {
+				if IsErrIssueNotExist(err) {
+					continue
+				}
+				return nil, err
+			}
+		} else if comment.Type != CommentTypeCommitRef {
+			filtered = append(filtered, comment)
+			continue
+		}
+
+		canSee, err := comment.CanSeeCrossReference(user)
+		if err != nil {
+			return nil, err
+		} else if canSee {
+			filtered = append(filtered, comment)
+		}
+	}
+	return filtered, nil
+}
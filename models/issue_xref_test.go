@@ -0,0 +1,83 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssue_CommentCrossReferences(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+
+	comment, err := CreateIssueComment(doer, repo, issue, "Related to #2, #2 and user3/repo3#1 but not #1", nil)
+	assert.NoError(t, err)
+
+	ref := AssertExistsAndLoadBean(t, &Comment{IssueID: 2, Type: CommentTypeCommentRef, RefCommentID: comment.ID}).(*Comment)
+	assert.EqualValues(t, 1, ref.RefRepoID)
+	assert.EqualValues(t, 1, ref.RefIssueID)
+	assert.False(t, ref.RefIsPull)
+	AssertExistsAndLoadBean(t, &Comment{IssueID: 6, Type: CommentTypeCommentRef, RefCommentID: comment.ID})
+	AssertNotExistsBean(t, &Comment{IssueID: 1, Type: CommentTypeCommentRef, RefCommentID: comment.ID})
+	count, err := x.Where("issue_id = 2 AND ref_comment_id = ?", comment.ID).Count(new(Comment))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	// Editing the comment does not duplicate the references
+	oldContent := comment.Content
+	comment.Content += " and #3"
+	assert.NoError(t, UpdateComment(doer, comment, oldContent))
+	count, err = x.Where("issue_id = 2 AND ref_comment_id = ?", comment.ID).Count(new(Comment))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	AssertExistsAndLoadBean(t, &Comment{IssueID: 3, Type: CommentTypeCommentRef, RefCommentID: comment.ID})
+
+	assert.NoError(t, DeleteComment(doer, comment))
+	AssertNotExistsBean(t, &Comment{Type: CommentTypeCommentRef, RefCommentID: comment.ID})
+
+	// A user who cannot read the private repository does not reference its issues
+	other := AssertExistsAndLoadBean(t, &User{ID: 5}).(*User)
+	comment, err = CreateIssueComment(other, repo, issue, "Related to user3/repo3#1", nil)
+	assert.NoError(t, err)
+	AssertNotExistsBean(t, &Comment{Type: CommentTypeCommentRef, RefCommentID: comment.ID})
+}
+
+func TestFilterCrossReferences(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 6}).(*Issue)
+	assert.NoError(t, issue.ChangeContent(doer, "Blocks user2/repo1#1"))
+	ref := AssertExistsAndLoadBean(t, &Comment{IssueID: 1, Type: CommentTypeIssueRef, RefIssueID: 6}).(*Comment)
+	assert.EqualValues(t, 3, ref.RefRepoID)
+
+	comments, err := FindComments(FindCommentsOptions{IssueID: 1, Type: CommentTypeUnknown})
+	assert.NoError(t, err)
+
+	filtered, err := FilterCrossReferences(comments, doer)
+	assert.NoError(t, err)
+	assert.Len(t, filtered, len(comments))
+	for _, comment := range filtered {
+		if comment.ID == ref.ID {
+			assert.NotNil(t, comment.RefIssue)
+			assert.Equal(t, setting.AppURL+"user3/repo3/issues/1", comment.RefCommentHTMLURL())
+		}
+	}
+
+	// The referencing issue is in a private repository
+	filtered, err = FilterCrossReferences(comments, nil)
+	assert.NoError(t, err)
+	assert.Len(t, filtered, len(comments)-1)
+	for _, comment := range filtered {
+		assert.NotEqual(t, ref.ID, comment.ID)
+	}
+}
@@ -115,6 +115,35 @@ func GetLFSLockByRepoID(repoID int64) (locks []*LFSLock, err error) {
 	return
 }
 
+// GetLFSLockConflict returns the first lock of the repository on one of the
+// paths which is owned by another user, nil if the user can change all the
+// paths.
+func GetLFSLockConflict(repoID, userID int64, paths []string) (*LFSLock, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	locks, err := GetLFSLockByRepoID(repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	lockedPaths := make(map[string]*LFSLock, len(locks))
+	for _, lock := range locks {
+		if lock.OwnerID != userID {
+			lockedPaths[strings.ToLower(lock.Path)] = lock
+		}
+	}
+	if len(lockedPaths) == 0 {
+		return nil, nil
+	}
+	for _, p := range paths {
+		if lock, ok := lockedPaths[strings.ToLower(cleanPath(p))]; ok {
+			return lock, nil
+		}
+	}
+	return nil, nil
+}
+
 // DeleteLFSLockByID deletes a lock by given ID.
 func DeleteLFSLockByID(id int64, u *User, force bool) (*LFSLock, error) {
 	lock, err := GetLFSLockByID(id)
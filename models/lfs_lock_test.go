@@ -0,0 +1,36 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLFSLockConflict(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	owner := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	_, err := CreateLFSLock(&LFSLock{Repo: repo, Owner: owner, Path: "assets/logo.psd"})
+	assert.NoError(t, err)
+
+	lock, err := GetLFSLockConflict(repo.ID, 4, []string{"README.md", "Assets/Logo.psd"})
+	assert.NoError(t, err)
+	if assert.NotNil(t, lock) {
+		assert.Equal(t, "assets/logo.psd", lock.Path)
+	}
+
+	lock, err = GetLFSLockConflict(repo.ID, 4, []string{"README.md"})
+	assert.NoError(t, err)
+	assert.Nil(t, lock)
+
+	// the owner of the lock can change the file
+	lock, err = GetLFSLockConflict(repo.ID, owner.ID, []string{"assets/logo.psd"})
+	assert.NoError(t, err)
+	assert.Nil(t, lock)
+}
@@ -27,10 +27,13 @@ const (
 	mailAuthResetPassword  base.TplName = "auth/reset_passwd"
 	mailAuthRegisterNotify base.TplName = "auth/register_notify"
 
-	mailIssueComment base.TplName = "issue/comment"
-	mailIssueMention base.TplName = "issue/mention"
+	mailIssueComment  base.TplName = "issue/comment"
+	mailIssueMention  base.TplName = "issue/mention"
+	mailIssueReminder base.TplName = "issue/reminder"
 
 	mailNotifyCollaborator base.TplName = "notify/collaborator"
+	mailNotifyRelease      base.TplName = "notify/release"
+	mailNotifyRepoTransfer base.TplName = "notify/repo_transfer"
 )
 
 var templates *template.Template
@@ -215,3 +218,91 @@ func SendIssueMentionMail(issue *Issue, doer *User, content string, comment *Com
 	}
 	mailer.SendAsync(composeIssueCommentMessage(issue, doer, content, comment, mailIssueMention, tos, "issue mention"))
 }
+
+// SendIssueReminderMail sends the mail of a due reminder on an issue to the user.
+func SendIssueReminderMail(issue *Issue, u *User) {
+	subject := "Reminder: " + issue.mailSubject()
+	data := composeTplData(subject, "", issue.HTMLURL())
+	data["Issue"] = issue
+
+	var content bytes.Buffer
+	if err := templates.ExecuteTemplate(&content, string(mailIssueReminder), data); err != nil {
+		log.Error("Template: %v", err)
+		return
+	}
+
+	msg := mailer.NewMessage([]string{u.Email}, subject, content.String())
+	msg.Info = fmt.Sprintf("UID: %d, issue reminder", u.ID)
+
+	mailer.SendAsync(msg)
+}
+
+// SendReleaseMail composes and sends emails about a published release to target receivers.
+func SendReleaseMail(rel *Release, tos []string) {
+	if len(tos) == 0 {
+		return
+	}
+
+	subject := fmt.Sprintf("[%s] Release %s", rel.Repo.FullName(), rel.Title)
+	body := string(markup.RenderByType(markdown.MarkupName, []byte(rel.Note), rel.Repo.HTMLURL(), rel.Repo.ComposeMetas()))
+	data := composeTplData(subject, body, rel.Repo.HTMLURL()+"/releases")
+	data["Release"] = rel
+
+	var content bytes.Buffer
+	if err := templates.ExecuteTemplate(&content, string(mailNotifyRelease), data); err != nil {
+		log.Error("Template: %v", err)
+		return
+	}
+
+	msg := mailer.NewMessageFrom(tos, rel.Publisher.DisplayName(), setting.MailService.FromEmail, subject, content.String())
+	msg.Info = fmt.Sprintf("Subject: %s, release", subject)
+
+	mailer.SendAsync(msg)
+}
+
+// SendRepoTransferNotifyMail sends mail notification to the new owner of the
+// repository, or to the owners of the new owner organization, about the
+// transfer they have to accept.
+func SendRepoTransferNotifyMail(doer, newOwner *User, repo *Repository) {
+	var tos []string
+	if newOwner.IsOrganization() {
+		t, err := newOwner.GetOwnerTeam()
+		if err != nil {
+			log.Error("GetOwnerTeam: %v", err)
+			return
+		}
+		if err = t.GetMembers(); err != nil {
+			log.Error("GetMembers: %v", err)
+			return
+		}
+		for _, u := range t.Members {
+			tos = append(tos, u.Email)
+		}
+	} else {
+		tos = []string{newOwner.Email}
+	}
+	if len(tos) == 0 {
+		return
+	}
+
+	repoName := repo.FullName()
+	subject := fmt.Sprintf("%s would like to transfer %s to %s", doer.DisplayName(), repoName, newOwner.Name)
+	data := map[string]interface{}{
+		"Subject":  subject,
+		"Doer":     doer,
+		"RepoName": repoName,
+		"NewOwner": newOwner,
+		"Link":     repo.HTMLURL(),
+	}
+
+	var content bytes.Buffer
+	if err := templates.ExecuteTemplate(&content, string(mailNotifyRepoTransfer), data); err != nil {
+		log.Error("Template: %v", err)
+		return
+	}
+
+	msg := mailer.NewMessageFrom(tos, doer.DisplayName(), setting.MailService.FromEmail, subject, content.String())
+	msg.Info = fmt.Sprintf("UID: %d, repository transfer", newOwner.ID)
+
+	mailer.SendAsync(msg)
+}
@@ -242,6 +242,90 @@ var migrations = []Migration{
 	NewMigration("remove orphaned repository index statuses", removeLingeringIndexStatus),
 	// v93 -> v94
 	NewMigration("add email notification enabled preference to user", addEmailNotificationEnabledToUser),
+	// v94 -> v95
+	NewMigration("add issue_redirect table", addIssueRedirectTable),
+	// v95 -> v96
+	NewMigration("add deleted_issue table", addDeletedIssueTable),
+	// v96 -> v97
+	NewMigration("add issue_content_history table", addIssueContentHistoryTable),
+	// v97 -> v98
+	NewMigration("add issue_reminder table", addIssueReminderTable),
+	// v98 -> v99
+	NewMigration("add org_id to milestone", addOrgIDToMilestone),
+	// v99 -> v100
+	NewMigration("add mode to watch", addModeToWatch),
+	// v100 -> v101
+	NewMigration("add priority to label", addPriorityToLabel),
+	// v101 -> v102
+	NewMigration("add default_label table", addDefaultLabelTable),
+	// v102 -> v103
+	NewMigration("add cross reference columns to comment", addCrossReferenceColumns),
+	// v103 -> v104
+	NewMigration("add issue sla columns", addIssueSLAColumns),
+	// v104 -> v105
+	NewMigration("add is_draft to pull_request", addIsDraftToPullRequest),
+	// v105 -> v106
+	NewMigration("add enable_approvals_whitelist to protected_branch", addEnableApprovalsWhitelist),
+	// v106 -> v107
+	NewMigration("add require_code_owner_approval to protected_branch", addRequireCodeOwnerApproval),
+	// v107 -> v108
+	NewMigration("add merge queue", addMergeQueue),
+	// v108 -> v109
+	NewMigration("add pull_auto_merge table", addPullAutoMergeTable),
+	// v109 -> v110
+	NewMigration("add dismiss_stale_approvals to protected_branch and dismissed to review", addDismissStaleApprovals),
+	// v110 -> v111
+	NewMigration("add pull_viewed_file table", addPullViewedFileTable),
+	// v111 -> v112
+	NewMigration("add status check settings to protected_branch", addProtectedBranchStatusChecks),
+	// v112 -> v113
+	NewMigration("add allow_maintainer_edit to pull_request", addAllowMaintainerEdit),
+	// v113 -> v114
+	NewMigration("add flow to pull_request", addPullRequestFlow),
+	// v114 -> v115
+	NewMigration("add start_line to comment", addCommentStartLine),
+	// v115 -> v116
+	NewMigration("add resolve_doer_id to comment and block_on_unresolved_conversations to protected_branch", addConversationResolving),
+	// v116 -> v117
+	NewMigration("add block_on_outdated_branch to protected_branch", addBlockOnOutdatedBranch),
+	// v117 -> v118
+	NewMigration("add old_ref and new_ref to comment", addCommentRefs),
+	// v118 -> v119
+	NewMigration("add check_run and check_run_annotation tables", addCheckRunTables),
+	// v119 -> v120
+	NewMigration("add push_mirror table", addPushMirrorTable),
+	// v120 -> v121
+	NewMigration("add last_error to mirror", addMirrorLastError),
+	// v121 -> v122
+	NewMigration("add is_template and template_id to repository", addTemplateToRepo),
+	// v122 -> v123
+	NewMigration("add protected_tag table", addProtectedTagTable),
+	// v123 -> v124
+	NewMigration("add quotas to user", addQuotasToUser),
+	// v124 -> v125
+	NewMigration("add language_stat table", addLanguageStatsTable),
+	// v125 -> v126
+	NewMigration("add contributor statistics tables", addContributorStatsTables),
+	// v126 -> v127
+	NewMigration("add attachment_upload table", addAttachmentUploadTable),
+	// v127 -> v128
+	NewMigration("add repo_transfer table", addRepoTransferTable),
+	// v128 -> v129
+	NewMigration("add repo_housekeeping table", addRepoHousekeepingTable),
+	// v129 -> v130
+	NewMigration("add repository defaults to user", addRepoDefaultsToUser),
+	// v130 -> v131
+	NewMigration("add mirror_ssh_key table", addMirrorSSHKeyTable),
+	// v131 -> v132
+	NewMigration("add scope to access_token", addScopeToAccessToken),
+	// v132 -> v133
+	NewMigration("add public oauth2 clients and oauth2_device_code table", addOAuth2PublicClientsAndDeviceCodes),
+	// v133 -> v134
+	NewMigration("add payload_template to webhook", addPayloadTemplateToWebhook),
+	// v134 -> v135
+	NewMigration("add test merge to merge_queue_entry", addTestMergeToMergeQueueEntry),
+	// v135 -> v136
+	NewMigration("add proc-receive hook to repositories", addProcReceiveHookToRepositories),
 }
 
 // Migrate database to current version
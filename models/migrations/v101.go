@@ -0,0 +1,28 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addDefaultLabelTable(x *xorm.Engine) error {
+	// DefaultLabel see models/issue_label_default.go
+	type DefaultLabel struct {
+		ID          int64 `xorm:"pk autoincr"`
+		OrgID       int64 `xorm:"INDEX"`
+		Name        string
+		Description string
+		Color       string `xorm:"VARCHAR(7)"`
+		Priority    int    `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(DefaultLabel)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addIssueSLAColumns(x *xorm.Engine) error {
+	// Label see models/issue_label.go
+	type Label struct {
+		ResponseTargetHours   int64 `xorm:"NOT NULL DEFAULT 0"`
+		ResolutionTargetHours int64 `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	// Issue see models/issue.go
+	type Issue struct {
+		FirstResponseUnix int64 `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(Label), new(Issue)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+
+	// The first response is the first comment which has not been written by the poster
+	if _, err := x.Exec("UPDATE `issue` SET first_response_unix = " +
+		"(SELECT COALESCE(MIN(`comment`.created_unix), 0) FROM `comment` " +
+		"WHERE `comment`.issue_id = `issue`.id AND `comment`.type = 0 AND `comment`.poster_id <> `issue`.poster_id)"); err != nil {
+		return fmt.Errorf("update first_response_unix: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addEnableApprovalsWhitelist(x *xorm.Engine) error {
+	// ProtectedBranch see models/branches.go
+	type ProtectedBranch struct {
+		EnableApprovalsWhitelist bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	if err := x.Sync2(new(ProtectedBranch)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+
+	// Until now only approvals of whitelisted users or teams have been granted
+	if _, err := x.Exec("UPDATE `protected_branch` SET enable_approvals_whitelist = ? WHERE required_approvals > 0", true); err != nil {
+		return fmt.Errorf("update enable_approvals_whitelist: %v", err)
+	}
+	return nil
+}
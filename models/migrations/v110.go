@@ -0,0 +1,30 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addPullViewedFileTable(x *xorm.Engine) error {
+	// PullViewedFile see models/pull_viewed_file.go
+	type PullViewedFile struct {
+		ID          int64              `xorm:"pk autoincr"`
+		UserID      int64              `xorm:"INDEX(s) NOT NULL"`
+		PullID      int64              `xorm:"INDEX(s) NOT NULL"`
+		TreePath    string             `xorm:"NOT NULL"`
+		DiffHash    string             `xorm:"VARCHAR(40)"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	if err := x.Sync2(new(PullViewedFile)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
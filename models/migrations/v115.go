@@ -0,0 +1,27 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addConversationResolving(x *xorm.Engine) error {
+	// Comment see models/issue_comment.go
+	type Comment struct {
+		ResolveDoerID int64
+	}
+	// ProtectedBranch see models/branches.go
+	type ProtectedBranch struct {
+		BlockOnUnresolvedConversations bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	if err := x.Sync2(new(Comment), new(ProtectedBranch)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
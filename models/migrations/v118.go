@@ -0,0 +1,51 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addCheckRunTables(x *xorm.Engine) error {
+	// CheckRun see models/check_run.go
+	type CheckRun struct {
+		ID            int64  `xorm:"pk autoincr"`
+		RepoID        int64  `xorm:"INDEX(repo_sha)"`
+		SHA           string `xorm:"VARCHAR(64) NOT NULL INDEX(repo_sha)"`
+		Name          string `xorm:"NOT NULL"`
+		Status        string `xorm:"VARCHAR(20) NOT NULL"`
+		Conclusion    string `xorm:"VARCHAR(20)"`
+		ExternalID    string
+		DetailsURL    string `xorm:"TEXT"`
+		Title         string
+		Summary       string `xorm:"TEXT"`
+		CreatorID     int64
+		StartedUnix   timeutil.TimeStamp
+		CompletedUnix timeutil.TimeStamp
+		CreatedUnix   timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix   timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	// CheckRunAnnotation see models/check_run.go
+	type CheckRunAnnotation struct {
+		ID         int64  `xorm:"pk autoincr"`
+		CheckRunID int64  `xorm:"INDEX NOT NULL"`
+		Path       string `xorm:"TEXT NOT NULL"`
+		StartLine  int    `xorm:"NOT NULL"`
+		EndLine    int    `xorm:"NOT NULL"`
+		Level      string `xorm:"VARCHAR(20) NOT NULL"`
+		Title      string
+		Message    string `xorm:"TEXT"`
+	}
+
+	if err := x.Sync2(new(CheckRun), new(CheckRunAnnotation)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
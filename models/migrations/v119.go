@@ -0,0 +1,34 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addPushMirrorTable(x *xorm.Engine) error {
+	// PushMirror see models/repo_push_mirror.go
+	type PushMirror struct {
+		ID             int64  `xorm:"pk autoincr"`
+		RepoID         int64  `xorm:"INDEX"`
+		RemoteName     string `xorm:"NOT NULL"`
+		SyncOnCommit   bool   `xorm:"NOT NULL DEFAULT true"`
+		Interval       time.Duration
+		LastUpdateUnix timeutil.TimeStamp `xorm:"INDEX"`
+		NextUpdateUnix timeutil.TimeStamp `xorm:"INDEX"`
+		LastError      string             `xorm:"TEXT"`
+		CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(PushMirror)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addProtectedTagTable(x *xorm.Engine) error {
+	// ProtectedTag see models/protected_tag.go
+	type ProtectedTag struct {
+		ID               int64 `xorm:"pk autoincr"`
+		RepoID           int64 `xorm:"INDEX"`
+		NamePattern      string
+		WhitelistUserIDs []int64 `xorm:"JSON TEXT"`
+		WhitelistTeamIDs []int64 `xorm:"JSON TEXT"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	if err := x.Sync2(new(ProtectedTag)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
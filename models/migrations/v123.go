@@ -0,0 +1,18 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import "github.com/go-xorm/xorm"
+
+func addQuotasToUser(x *xorm.Engine) error {
+	// User see models/user.go
+	type User struct {
+		MaxGitSize        int64 `xorm:"NOT NULL DEFAULT -1"`
+		MaxLFSSize        int64 `xorm:"NOT NULL DEFAULT -1"`
+		MaxAttachmentSize int64 `xorm:"NOT NULL DEFAULT -1"`
+	}
+
+	return x.Sync2(new(User))
+}
@@ -0,0 +1,34 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addContributorStatsTables(x *xorm.Engine) error {
+	// ContributorStatsStatus see models/repo_contributor_stats.go
+	type ContributorStatsStatus struct {
+		ID        int64  `xorm:"pk autoincr"`
+		RepoID    int64  `xorm:"UNIQUE"`
+		CommitSha string `xorm:"VARCHAR(40)"`
+	}
+
+	// ContributorWeeklyStat see models/repo_contributor_stats.go
+	type ContributorWeeklyStat struct {
+		ID        int64              `xorm:"pk autoincr"`
+		RepoID    int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Email     string             `xorm:"UNIQUE(s) NOT NULL"`
+		Week      timeutil.TimeStamp `xorm:"UNIQUE(s) NOT NULL"`
+		Name      string
+		Commits   int64 `xorm:"NOT NULL DEFAULT 0"`
+		Additions int64 `xorm:"NOT NULL DEFAULT 0"`
+		Deletions int64 `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	return x.Sync2(new(ContributorStatsStatus), new(ContributorWeeklyStat))
+}
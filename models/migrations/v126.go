@@ -0,0 +1,28 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addAttachmentUploadTable(x *xorm.Engine) error {
+	// AttachmentUpload see models/attachment_upload.go
+	type AttachmentUpload struct {
+		ID           int64  `xorm:"pk autoincr"`
+		UUID         string `xorm:"uuid UNIQUE"`
+		ReleaseID    int64  `xorm:"INDEX"`
+		UploaderID   int64  `xorm:"INDEX"`
+		Name         string
+		Size         int64              `xorm:"NOT NULL DEFAULT 0"`
+		UploadedSize int64              `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix  timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	return x.Sync2(new(AttachmentUpload))
+}
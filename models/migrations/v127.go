@@ -0,0 +1,25 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addRepoTransferTable(x *xorm.Engine) error {
+	// RepoTransfer see models/repo_transfer.go
+	type RepoTransfer struct {
+		ID          int64 `xorm:"pk autoincr"`
+		DoerID      int64
+		RecipientID int64 `xorm:"INDEX"`
+		RepoID      int64 `xorm:"UNIQUE"`
+		TeamIDs     []int64
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL created"`
+	}
+
+	return x.Sync2(new(RepoTransfer))
+}
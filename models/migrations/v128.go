@@ -0,0 +1,25 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addRepoHousekeepingTable(x *xorm.Engine) error {
+	// RepoHousekeeping see models/repo_housekeeping.go
+	type RepoHousekeeping struct {
+		ID          int64 `xorm:"pk autoincr"`
+		RepoID      int64 `xorm:"UNIQUE"`
+		IsSuccess   bool
+		Output      string `xorm:"TEXT"`
+		Duration    int64
+		StartedUnix timeutil.TimeStamp `xorm:"INDEX"`
+	}
+
+	return x.Sync2(new(RepoHousekeeping))
+}
@@ -0,0 +1,27 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addRepoDefaultsToUser(x *xorm.Engine) error {
+	// User see models/user.go
+	type User struct {
+		RepoDefaultBranch     string `xorm:"NOT NULL DEFAULT ''"`
+		RepoDefaultVisibility string `xorm:"NOT NULL DEFAULT ''"`
+		RepoDefaultAutoInit   bool   `xorm:"NOT NULL DEFAULT false"`
+		RepoDefaultGitignores string `xorm:"NOT NULL DEFAULT ''"`
+		RepoDefaultLicense    string `xorm:"NOT NULL DEFAULT ''"`
+	}
+
+	if err := x.Sync2(new(User)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addMirrorSSHKeyTable(x *xorm.Engine) error {
+	// MirrorSSHKey see models/repo_mirror_ssh_key.go
+	type MirrorSSHKey struct {
+		ID          int64              `xorm:"pk autoincr"`
+		RepoID      int64              `xorm:"UNIQUE"`
+		PublicKey   string             `xorm:"TEXT NOT NULL"`
+		Fingerprint string             `xorm:"NOT NULL"`
+		PrivateKey  string             `xorm:"TEXT NOT NULL"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(MirrorSSHKey)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
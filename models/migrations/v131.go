@@ -0,0 +1,28 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addScopeToAccessToken(x *xorm.Engine) error {
+	// AccessToken see models/token.go
+	type AccessToken struct {
+		Scope string
+	}
+
+	if err := x.Sync2(new(AccessToken)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+
+	// The existing tokens keep all the permissions they had
+	if _, err := x.Exec("UPDATE `access_token` SET scope = ? WHERE scope IS NULL OR scope = ''", "all"); err != nil {
+		return fmt.Errorf("update scope: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addOAuth2PublicClientsAndDeviceCodes(x *xorm.Engine) error {
+	// Oauth2Application see models/oauth2_application.go, named so that the
+	// mapper gives the name of its table
+	type Oauth2Application struct {
+		ConfidentialClient bool `xorm:"NOT NULL DEFAULT TRUE"`
+	}
+
+	// Oauth2DeviceCode see models/oauth2_device_code.go
+	type Oauth2DeviceCode struct {
+		ID             int64  `xorm:"pk autoincr"`
+		ApplicationID  int64  `xorm:"INDEX"`
+		DeviceCodeHash string `xorm:"UNIQUE"`
+		UserCode       string `xorm:"UNIQUE"`
+		UserID         int64
+		Status         int `xorm:"NOT NULL DEFAULT 0"`
+		Interval       int64
+		LastPolledUnix timeutil.TimeStamp
+		ValidUntil     timeutil.TimeStamp `xorm:"INDEX"`
+		CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(Oauth2Application), new(Oauth2DeviceCode)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addTestMergeToMergeQueueEntry(x *xorm.Engine) error {
+	// MergeQueueEntry see models/pull_merge_queue.go
+	type MergeQueueEntry struct {
+		BaseCommitID  string `xorm:"VARCHAR(40)"`
+		HeadCommitID  string `xorm:"VARCHAR(40)"`
+		MergeCommitID string `xorm:"VARCHAR(40)"`
+	}
+
+	if err := x.Sync2(new(MergeQueueEntry)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
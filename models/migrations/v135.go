@@ -0,0 +1,62 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/go-xorm/xorm"
+	"github.com/unknwon/com"
+)
+
+func addProcReceiveHookToRepositories(x *xorm.Engine) error {
+	type Repository struct {
+		ID      int64
+		OwnerID int64
+		Name    string
+	}
+	type User struct {
+		ID   int64
+		Name string
+	}
+
+	// The proc-receive hook talks to Git through stdin and stdout and can not be delegated
+	hookTpl := fmt.Sprintf("#!/usr/bin/env %s\n\"%s\" hook --config='%s' proc-receive\n", setting.ScriptType, setting.AppPath, setting.CustomConf)
+
+	return x.Where("id > 0").BufferSize(setting.Database.IterateBufferSize).Iterate(new(Repository),
+		func(idx int, bean interface{}) error {
+			repo := bean.(*Repository)
+			user := new(User)
+			has, err := x.Where("id = ?", repo.OwnerID).Get(user)
+			if err != nil {
+				return fmt.Errorf("query owner of repository [repo_id: %d, owner_id: %d]: %v", repo.ID, repo.OwnerID, err)
+			} else if !has {
+				return nil
+			}
+
+			repoPaths := []string{
+				filepath.Join(setting.RepoRootPath, strings.ToLower(user.Name), strings.ToLower(repo.Name)) + ".git",
+				filepath.Join(setting.RepoRootPath, strings.ToLower(user.Name), strings.ToLower(repo.Name)) + ".wiki.git",
+			}
+
+			for _, repoPath := range repoPaths {
+				hookDir := filepath.Join(repoPath, "hooks")
+				if !com.IsDir(hookDir) {
+					continue
+				}
+
+				hookPath := filepath.Join(hookDir, "proc-receive")
+				if err = ioutil.WriteFile(hookPath, []byte(hookTpl), 0777); err != nil {
+					return fmt.Errorf("write hook file '%s': %v", hookPath, err)
+				}
+			}
+			return nil
+		})
+}
@@ -0,0 +1,26 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addIssueRedirectTable(x *xorm.Engine) error {
+	// IssueRedirect see models/issue_move.go
+	type IssueRedirect struct {
+		ID              int64 `xorm:"pk autoincr"`
+		RepoID          int64 `xorm:"UNIQUE(s)"`
+		Index           int64 `xorm:"UNIQUE(s)"`
+		RedirectIssueID int64 `xorm:"INDEX"`
+	}
+
+	if err := x.Sync2(new(IssueRedirect)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
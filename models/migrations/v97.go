@@ -0,0 +1,29 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addIssueReminderTable(x *xorm.Engine) error {
+	// IssueReminder see models/issue_reminder.go
+	type IssueReminder struct {
+		ID          int64              `xorm:"pk autoincr"`
+		UserID      int64              `xorm:"INDEX"`
+		IssueID     int64              `xorm:"INDEX"`
+		RemindUnix  timeutil.TimeStamp `xorm:"INDEX"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(IssueReminder)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addOrgIDToMilestone(x *xorm.Engine) error {
+	// Milestone see models/issue_milestone.go
+	type Milestone struct {
+		OrgID int64 `xorm:"INDEX"`
+	}
+
+	if err := x.Sync2(new(Milestone)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
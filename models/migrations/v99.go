@@ -0,0 +1,23 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addModeToWatch(x *xorm.Engine) error {
+	// Watch see models/repo_watch.go
+	type Watch struct {
+		Mode int8 `xorm:"SMALLINT NOT NULL DEFAULT 1"`
+	}
+
+	if err := x.Sync2(new(Watch)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -41,6 +41,7 @@ type Engine interface {
 	Iterate(interface{}, xorm.IterFunc) error
 	Join(joinOperator string, tablename interface{}, condition string, args ...interface{}) *xorm.Session
 	SQL(interface{}, ...interface{}) *xorm.Session
+	Select(string) *xorm.Session
 	Where(interface{}, ...interface{}) *xorm.Session
 	Asc(colNames ...string) *xorm.Session
 }
@@ -112,6 +113,26 @@ func init() {
 		new(OAuth2Application),
 		new(OAuth2AuthorizationCode),
 		new(OAuth2Grant),
+		new(OAuth2DeviceCode),
+		new(IssueRedirect),
+		new(DeletedIssue),
+		new(IssueContentHistory),
+		new(IssueReminder),
+		new(DefaultLabel),
+		new(MergeQueueEntry),
+		new(PullAutoMerge),
+		new(PullViewedFile),
+		new(CheckRun),
+		new(CheckRunAnnotation),
+		new(PushMirror),
+		new(MirrorSSHKey),
+		new(ProtectedTag),
+		new(LanguageStat),
+		new(ContributorStatsStatus),
+		new(ContributorWeeklyStat),
+		new(AttachmentUpload),
+		new(RepoTransfer),
+		new(RepoHousekeeping),
 	)
 
 	gonicNames := []string{"SSL", "UID"}
@@ -57,28 +57,28 @@ type Notification struct {
 }
 
 // CreateOrUpdateIssueNotifications creates an issue notification
-// for each watcher, or updates it if already exists
-func CreateOrUpdateIssueNotifications(issue *Issue, notificationAuthorID int64) error {
+// for each watcher interested in the action, or updates it if already exists
+func CreateOrUpdateIssueNotifications(issue *Issue, notificationAuthorID int64, opType ActionType) error {
 	sess := x.NewSession()
 	defer sess.Close()
 	if err := sess.Begin(); err != nil {
 		return err
 	}
 
-	if err := createOrUpdateIssueNotifications(sess, issue, notificationAuthorID); err != nil {
+	if err := createOrUpdateIssueNotifications(sess, issue, notificationAuthorID, opType); err != nil {
 		return err
 	}
 
 	return sess.Commit()
 }
 
-func createOrUpdateIssueNotifications(e Engine, issue *Issue, notificationAuthorID int64) error {
+func createOrUpdateIssueNotifications(e Engine, issue *Issue, notificationAuthorID int64, opType ActionType) error {
 	issueWatches, err := getIssueWatchers(e, issue.ID)
 	if err != nil {
 		return err
 	}
 
-	watches, err := getWatchers(e, issue.RepoID)
+	watches, err := getWatchers(e, issue.RepoID, watchModesOfAction(opType)...)
 	if err != nil {
 		return err
 	}
@@ -140,6 +140,18 @@ func createOrUpdateIssueNotifications(e Engine, issue *Issue, notificationAuthor
 	return nil
 }
 
+// CreateOrUpdateIssueNotificationForUser creates an issue notification for
+// the user, or marks the existing notification as unread.
+func CreateOrUpdateIssueNotificationForUser(issue *Issue, userID, notificationAuthorID int64) error {
+	notification, err := getIssueNotification(x, userID, issue.ID)
+	if err != nil {
+		return err
+	} else if notification.ID == 0 {
+		return createIssueNotification(x, userID, issue, notificationAuthorID)
+	}
+	return updateIssueNotification(x, userID, issue.ID, notificationAuthorID)
+}
+
 func getNotificationsByIssueID(e Engine, issueID int64) (notifications []*Notification, err error) {
 	err = e.
 		Where("issue_id = ?", issueID).
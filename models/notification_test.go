@@ -14,7 +14,7 @@ func TestCreateOrUpdateIssueNotifications(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
 
-	assert.NoError(t, CreateOrUpdateIssueNotifications(issue, 2))
+	assert.NoError(t, CreateOrUpdateIssueNotifications(issue, 2, ActionCommentIssue))
 
 	// User 9 is inactive, thus notifications for user 1 and 4 are created
 	notf := AssertExistsAndLoadBean(t, &Notification{UserID: 1, IssueID: issue.ID}).(*Notification)
@@ -25,6 +25,23 @@ func TestCreateOrUpdateIssueNotifications(t *testing.T) {
 	assert.Equal(t, NotificationStatusUnread, notf.Status)
 }
 
+func TestCreateOrUpdateIssueNotifications_WatchMode(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 3}).(*Issue)
+
+	assert.NoError(t, WatchRepoMode(1, issue.RepoID, RepoWatchModeIssues))
+	assert.NoError(t, WatchRepoMode(4, issue.RepoID, RepoWatchModeParticipating))
+
+	// user 1 only wants to know of new issues
+	assert.NoError(t, CreateOrUpdateIssueNotifications(issue, 2, ActionCommentIssue))
+	AssertNotExistsBean(t, &Notification{UserID: 1, IssueID: issue.ID})
+	AssertNotExistsBean(t, &Notification{UserID: 4, IssueID: issue.ID})
+
+	assert.NoError(t, CreateOrUpdateIssueNotifications(issue, 2, ActionCreateIssue))
+	AssertExistsAndLoadBean(t, &Notification{UserID: 1, IssueID: issue.ID})
+	AssertNotExistsBean(t, &Notification{UserID: 4, IssueID: issue.ID})
+}
+
 func TestNotificationsForUser(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
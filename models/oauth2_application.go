@@ -35,6 +35,10 @@ type OAuth2Application struct {
 
 	RedirectURIs []string `xorm:"redirect_uris JSON TEXT"`
 
+	// ConfidentialClient is false for the public clients, like native and
+	// CLI applications, which can't keep a secret and must use PKCE
+	ConfidentialClient bool `xorm:"NOT NULL DEFAULT TRUE"`
+
 	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
 }
@@ -163,9 +167,10 @@ func getOAuth2ApplicationsByUserID(e Engine, userID int64) (apps []*OAuth2Applic
 
 // CreateOAuth2ApplicationOptions holds options to create an oauth2 application
 type CreateOAuth2ApplicationOptions struct {
-	Name         string
-	UserID       int64
-	RedirectURIs []string
+	Name               string
+	UserID             int64
+	RedirectURIs       []string
+	ConfidentialClient bool
 }
 
 // CreateOAuth2Application inserts a new oauth2 application
@@ -176,10 +181,11 @@ func CreateOAuth2Application(opts CreateOAuth2ApplicationOptions) (*OAuth2Applic
 func createOAuth2Application(e Engine, opts CreateOAuth2ApplicationOptions) (*OAuth2Application, error) {
 	clientID := uuid.NewV4().String()
 	app := &OAuth2Application{
-		UID:          opts.UserID,
-		Name:         opts.Name,
-		ClientID:     clientID,
-		RedirectURIs: opts.RedirectURIs,
+		UID:                opts.UserID,
+		Name:               opts.Name,
+		ClientID:           clientID,
+		RedirectURIs:       opts.RedirectURIs,
+		ConfidentialClient: opts.ConfidentialClient,
 	}
 	if _, err := e.Insert(app); err != nil {
 		return nil, err
@@ -189,10 +195,11 @@ func createOAuth2Application(e Engine, opts CreateOAuth2ApplicationOptions) (*OA
 
 // UpdateOAuth2ApplicationOptions holds options to update an oauth2 application
 type UpdateOAuth2ApplicationOptions struct {
-	ID           int64
-	Name         string
-	UserID       int64
-	RedirectURIs []string
+	ID                 int64
+	Name               string
+	UserID             int64
+	RedirectURIs       []string
+	ConfidentialClient bool
 }
 
 // UpdateOAuth2Application updates an oauth2 application
@@ -202,12 +209,13 @@ func UpdateOAuth2Application(opts UpdateOAuth2ApplicationOptions) error {
 
 func updateOAuth2Application(e Engine, opts UpdateOAuth2ApplicationOptions) error {
 	app := &OAuth2Application{
-		ID:           opts.ID,
-		UID:          opts.UserID,
-		Name:         opts.Name,
-		RedirectURIs: opts.RedirectURIs,
+		ID:                 opts.ID,
+		UID:                opts.UserID,
+		Name:               opts.Name,
+		RedirectURIs:       opts.RedirectURIs,
+		ConfidentialClient: opts.ConfidentialClient,
 	}
-	if _, err := e.ID(opts.ID).Update(app); err != nil {
+	if _, err := e.ID(opts.ID).UseBool("confidential_client").Update(app); err != nil {
 		return err
 	}
 	return nil
@@ -237,6 +245,9 @@ func deleteOAuth2Application(sess *xorm.Session, id, userid int64) error {
 	if _, err := sess.Where("application_id = ?", id).Delete(new(OAuth2Grant)); err != nil {
 		return err
 	}
+	if _, err := sess.Where("application_id = ?", id).Delete(new(OAuth2DeviceCode)); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -295,6 +306,11 @@ func (code *OAuth2AuthorizationCode) invalidate(e Engine) error {
 	return err
 }
 
+// HasCodeChallenge returns true if the code was requested with a PKCE code challenge
+func (code *OAuth2AuthorizationCode) HasCodeChallenge() bool {
+	return len(code.CodeChallenge) > 0
+}
+
 // ValidateCodeChallenge validates the given verifier against the saved code challenge. This is part of the PKCE implementation.
 func (code *OAuth2AuthorizationCode) ValidateCodeChallenge(verifier string) bool {
 	return code.validateCodeChallenge(verifier)
@@ -0,0 +1,217 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"code.gitea.io/gitea/modules/secret"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// OAuth2DeviceCodeStatus represents the state of a device authorization request
+type OAuth2DeviceCodeStatus int
+
+const (
+	// OAuth2DeviceCodeStatusPending is a request the user did not answer yet
+	OAuth2DeviceCodeStatusPending OAuth2DeviceCodeStatus = iota
+	// OAuth2DeviceCodeStatusApproved is a request the user approved
+	OAuth2DeviceCodeStatusApproved
+	// OAuth2DeviceCodeStatusDenied is a request the user denied
+	OAuth2DeviceCodeStatusDenied
+)
+
+// userCodeAlphabet contains no vowel, so that the user codes can't spell
+// words, and no character which could be mistaken for another (RFC 8628 6.1)
+const userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+
+// OAuth2DeviceCode is a device authorization request (RFC 8628). The device
+// polls for an access token with the device code while the user approves the
+// request by entering the user code on another device.
+type OAuth2DeviceCode struct {
+	ID             int64              `xorm:"pk autoincr"`
+	ApplicationID  int64              `xorm:"INDEX"`
+	Application    *OAuth2Application `xorm:"-"`
+	DeviceCode     string             `xorm:"-"`
+	DeviceCodeHash string             `xorm:"UNIQUE"` // sha256 of the device code
+	UserCode       string             `xorm:"UNIQUE"`
+	UserID         int64
+	Status         OAuth2DeviceCodeStatus `xorm:"NOT NULL DEFAULT 0"`
+	Interval       int64
+	LastPolledUnix timeutil.TimeStamp
+	ValidUntil     timeutil.TimeStamp `xorm:"INDEX"`
+	CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+}
+
+// TableName sets the table name to `oauth2_device_code`
+func (code *OAuth2DeviceCode) TableName() string {
+	return "oauth2_device_code"
+}
+
+// IsExpired returns true if the request can't be answered or polled anymore
+func (code *OAuth2DeviceCode) IsExpired() bool {
+	return code.ValidUntil < timeutil.TimeStampNow()
+}
+
+// LoadApplication loads the application which requested the authorization
+func (code *OAuth2DeviceCode) LoadApplication() (err error) {
+	if code.Application == nil {
+		code.Application, err = GetOAuth2ApplicationByID(code.ApplicationID)
+	}
+	return
+}
+
+// Answer records the answer of the user to the request, it returns false if
+// the request is not pending anymore, e.g. it has been answered meanwhile
+func (code *OAuth2DeviceCode) Answer(userID int64, approved bool) (bool, error) {
+	answer := &OAuth2DeviceCode{
+		UserID: userID,
+		Status: OAuth2DeviceCodeStatusDenied,
+	}
+	if approved {
+		answer.Status = OAuth2DeviceCodeStatusApproved
+	}
+	affected, err := x.ID(code.ID).
+		Where("status = ? AND valid_until >= ?", OAuth2DeviceCodeStatusPending, timeutil.TimeStampNow()).
+		Cols("user_id", "status").Update(answer)
+	if err != nil || affected == 0 {
+		return false, err
+	}
+	code.UserID = answer.UserID
+	code.Status = answer.Status
+	return true, nil
+}
+
+// Poll records a poll of the device, it returns false if the device polls
+// faster than its interval, which is then increased by 5 seconds (RFC 8628 3.5)
+func (code *OAuth2DeviceCode) Poll() (bool, error) {
+	now := timeutil.TimeStampNow()
+	ok := code.LastPolledUnix == 0 || now >= code.LastPolledUnix.Add(code.Interval)
+	if !ok {
+		code.Interval += 5
+	}
+	code.LastPolledUnix = now
+	_, err := x.ID(code.ID).Cols("interval", "last_polled_unix").Update(code)
+	return ok, err
+}
+
+// Consume deletes the approved request before the tokens are issued to the
+// device, it returns false if the request has already been consumed
+func (code *OAuth2DeviceCode) Consume() (bool, error) {
+	affected, err := x.ID(code.ID).Where("status = ?", OAuth2DeviceCodeStatusApproved).Delete(new(OAuth2DeviceCode))
+	return affected > 0, err
+}
+
+// Delete deletes the request, once it has been answered or has expired
+func (code *OAuth2DeviceCode) Delete() error {
+	_, err := x.ID(code.ID).Delete(new(OAuth2DeviceCode))
+	return err
+}
+
+func hashDeviceCode(deviceCode string) string {
+	h := sha256.Sum256([]byte(deviceCode))
+	return hex.EncodeToString(h[:])
+}
+
+func generateUserCode() (string, error) {
+	code := make([]byte, 0, 9)
+	for i := 0; i < 8; i++ {
+		if i == 4 {
+			code = append(code, '-')
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(userCodeAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		code = append(code, userCodeAlphabet[n.Int64()])
+	}
+	return string(code), nil
+}
+
+// NormalizeUserCode returns the user code in its canonical form, as users
+// may enter it in lower case or without the dash
+func NormalizeUserCode(userCode string) string {
+	userCode = strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(userCode))
+	if len(userCode) != 8 {
+		return userCode
+	}
+	return userCode[:4] + "-" + userCode[4:]
+}
+
+// CreateDeviceCode creates a device authorization request for the application
+func (app *OAuth2Application) CreateDeviceCode() (*OAuth2DeviceCode, error) {
+	// the expired requests are no longer useful
+	if _, err := x.Where("valid_until < ?", timeutil.TimeStampNow()).Delete(new(OAuth2DeviceCode)); err != nil {
+		return nil, err
+	}
+
+	deviceCode, err := secret.New()
+	if err != nil {
+		return nil, err
+	}
+	code := &OAuth2DeviceCode{
+		ApplicationID:  app.ID,
+		Application:    app,
+		DeviceCode:     deviceCode,
+		DeviceCodeHash: hashDeviceCode(deviceCode),
+		Interval:       setting.OAuth2.DeviceCodePollingInterval,
+		ValidUntil:     timeutil.TimeStampNow().Add(setting.OAuth2.DeviceCodeExpirationTime),
+	}
+	// retry a few times if the user code is already used
+	for i := 0; i < 5; i++ {
+		if code.UserCode, err = generateUserCode(); err != nil {
+			return nil, err
+		}
+		has, err := x.Where("user_code = ?", code.UserCode).Exist(new(OAuth2DeviceCode))
+		if err != nil {
+			return nil, err
+		} else if has {
+			continue
+		}
+		if _, err = x.Insert(code); err != nil {
+			return nil, err
+		}
+		return code, nil
+	}
+	return nil, fmt.Errorf("cannot generate a unique user code")
+}
+
+// GetOAuth2DeviceCodeByDeviceCode returns the device authorization request
+// with the given device code, or nil if it does not exist
+func GetOAuth2DeviceCodeByDeviceCode(deviceCode string) (*OAuth2DeviceCode, error) {
+	if len(deviceCode) == 0 {
+		return nil, nil
+	}
+	code := new(OAuth2DeviceCode)
+	if has, err := x.Where("device_code_hash = ?", hashDeviceCode(deviceCode)).Get(code); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return code, nil
+}
+
+// GetOAuth2DeviceCodeByUserCode returns the device authorization request
+// with the given user code, or nil if it does not exist
+func GetOAuth2DeviceCodeByUserCode(userCode string) (*OAuth2DeviceCode, error) {
+	code := new(OAuth2DeviceCode)
+	if has, err := x.Where("user_code = ?", NormalizeUserCode(userCode)).Get(code); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return code, nil
+}
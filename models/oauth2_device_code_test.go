@@ -0,0 +1,112 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOAuth2Application_CreateDeviceCode(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	app := AssertExistsAndLoadBean(t, &OAuth2Application{ID: 2}).(*OAuth2Application)
+	code, err := app.CreateDeviceCode()
+	assert.NoError(t, err)
+	assert.Regexp(t, "^[B-Z]{4}-[B-Z]{4}$", code.UserCode)
+	assert.NotEmpty(t, code.DeviceCode)
+	assert.False(t, code.IsExpired())
+
+	loaded, err := GetOAuth2DeviceCodeByDeviceCode(code.DeviceCode)
+	assert.NoError(t, err)
+	assert.Equal(t, code.ID, loaded.ID)
+	loaded, err = GetOAuth2DeviceCodeByUserCode(code.UserCode[:4] + code.UserCode[5:])
+	assert.NoError(t, err)
+	assert.Equal(t, code.ID, loaded.ID)
+
+	loaded, err = GetOAuth2DeviceCodeByDeviceCode("notadevicecode")
+	assert.NoError(t, err)
+	assert.Nil(t, loaded)
+}
+
+func TestOAuth2DeviceCode_Poll(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	app := AssertExistsAndLoadBean(t, &OAuth2Application{ID: 2}).(*OAuth2Application)
+	code, err := app.CreateDeviceCode()
+	assert.NoError(t, err)
+	interval := code.Interval
+
+	ok, err := code.Poll()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = code.Poll()
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	AssertExistsAndLoadBean(t, &OAuth2DeviceCode{ID: code.ID, Interval: interval + 5})
+}
+
+func TestOAuth2DeviceCode_Answer(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	app := AssertExistsAndLoadBean(t, &OAuth2Application{ID: 2}).(*OAuth2Application)
+	code, err := app.CreateDeviceCode()
+	assert.NoError(t, err)
+
+	answered, err := code.Answer(2, true)
+	assert.NoError(t, err)
+	assert.True(t, answered)
+	AssertExistsAndLoadBean(t, &OAuth2DeviceCode{ID: code.ID, UserID: 2, Status: OAuth2DeviceCodeStatusApproved})
+	assert.NoError(t, code.Delete())
+	AssertNotExistsBean(t, &OAuth2DeviceCode{ID: code.ID})
+}
+
+func TestOAuth2DeviceCode_AnswerOnce(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	app := AssertExistsAndLoadBean(t, &OAuth2Application{ID: 2}).(*OAuth2Application)
+	code, err := app.CreateDeviceCode()
+	assert.NoError(t, err)
+	stale, err := GetOAuth2DeviceCodeByUserCode(code.UserCode)
+	assert.NoError(t, err)
+
+	answered, err := code.Answer(2, false)
+	assert.NoError(t, err)
+	assert.True(t, answered)
+
+	// a denied request can't be approved afterwards
+	answered, err = stale.Answer(2, true)
+	assert.NoError(t, err)
+	assert.False(t, answered)
+	assert.Equal(t, OAuth2DeviceCodeStatusPending, stale.Status)
+	AssertExistsAndLoadBean(t, &OAuth2DeviceCode{ID: code.ID, Status: OAuth2DeviceCodeStatusDenied})
+
+	// a denied request can't be consumed
+	consumed, err := code.Consume()
+	assert.NoError(t, err)
+	assert.False(t, consumed)
+}
+
+func TestOAuth2DeviceCode_Consume(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	app := AssertExistsAndLoadBean(t, &OAuth2Application{ID: 2}).(*OAuth2Application)
+	code, err := app.CreateDeviceCode()
+	assert.NoError(t, err)
+	answered, err := code.Answer(2, true)
+	assert.NoError(t, err)
+	assert.True(t, answered)
+
+	// only one of concurrent polls consumes the request
+	consumed, err := code.Consume()
+	assert.NoError(t, err)
+	assert.True(t, consumed)
+	consumed, err = code.Consume()
+	assert.NoError(t, err)
+	assert.False(t, consumed)
+	AssertNotExistsBean(t, &OAuth2DeviceCode{ID: code.ID})
+}
+
+func TestNormalizeUserCode(t *testing.T) {
+	assert.Equal(t, "BCDF-GHJK", NormalizeUserCode("bcdfghjk"))
+	assert.Equal(t, "BCDF-GHJK", NormalizeUserCode("bcdf - ghjk"))
+	assert.Equal(t, "BCD", NormalizeUserCode("bcd"))
+}
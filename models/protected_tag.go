@@ -0,0 +1,173 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ProtectedTag represents a rule protecting the tags whose names match the
+// pattern, only the whitelisted users and teams can create, move or delete
+// these tags.
+type ProtectedTag struct {
+	ID               int64 `xorm:"pk autoincr"`
+	RepoID           int64 `xorm:"INDEX"`
+	NamePattern      string
+	WhitelistUserIDs []int64 `xorm:"JSON TEXT"`
+	WhitelistTeamIDs []int64 `xorm:"JSON TEXT"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+
+	re *regexp.Regexp `xorm:"-"`
+}
+
+// tagPatternToRegexp converts the name pattern of a protected tag to a
+// regular expression. A pattern enclosed in slashes is a regular expression
+// itself, otherwise it is a glob pattern where * and ? do not match a slash
+// and ** matches anything.
+func tagPatternToRegexp(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		return regexp.Compile(pattern[1 : len(pattern)-1])
+	}
+
+	var buf strings.Builder
+	buf.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				buf.WriteString(".*")
+				i++
+			} else {
+				buf.WriteString("[^/]*")
+			}
+		case '?':
+			buf.WriteString("[^/]")
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	buf.WriteString("$")
+	return regexp.Compile(buf.String())
+}
+
+// ValidateTagPattern returns an error if the name pattern of a protected tag
+// is invalid
+func ValidateTagPattern(pattern string) error {
+	if len(strings.TrimSpace(pattern)) == 0 {
+		return ErrInvalidTagPattern{Pattern: pattern}
+	}
+	if _, err := tagPatternToRegexp(pattern); err != nil {
+		return ErrInvalidTagPattern{Pattern: pattern}
+	}
+	return nil
+}
+
+// MatchString returns true if the name of a tag matches the pattern of the
+// protected tag
+func (pt *ProtectedTag) MatchString(name string) bool {
+	if pt.re == nil {
+		re, err := tagPatternToRegexp(pt.NamePattern)
+		if err != nil {
+			return false
+		}
+		pt.re = re
+	}
+	return pt.re.MatchString(name)
+}
+
+// IsUserAllowed returns true if the user is whitelisted by the protected tag
+func (pt *ProtectedTag) IsUserAllowed(userID int64) (bool, error) {
+	if base.Int64sContains(pt.WhitelistUserIDs, userID) {
+		return true, nil
+	}
+	if len(pt.WhitelistTeamIDs) == 0 {
+		return false, nil
+	}
+	return IsUserInTeams(userID, pt.WhitelistTeamIDs)
+}
+
+// GetProtectedTags returns the protected tags of the repository
+func GetProtectedTags(repoID int64) ([]*ProtectedTag, error) {
+	tags := make([]*ProtectedTag, 0, 5)
+	return tags, x.Where("repo_id = ?", repoID).Asc("id").Find(&tags)
+}
+
+// GetProtectedTagByID returns the protected tag of the repository with the ID
+func GetProtectedTagByID(repoID, id int64) (*ProtectedTag, error) {
+	tag := new(ProtectedTag)
+	has, err := x.ID(id).Where("repo_id = ?", repoID).Get(tag)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrProtectedTagNotExist{ID: id, RepoID: repoID}
+	}
+	return tag, nil
+}
+
+// UpdateProtectedTag creates the protected tag if its ID is zero, otherwise it
+// updates it. Only the users and teams with write access to the repository are
+// kept in the whitelists.
+func UpdateProtectedTag(repo *Repository, pt *ProtectedTag, userIDs, teamIDs []int64) (err error) {
+	if err = ValidateTagPattern(pt.NamePattern); err != nil {
+		return err
+	}
+	if err = repo.GetOwner(); err != nil {
+		return fmt.Errorf("GetOwner: %v", err)
+	}
+
+	if pt.WhitelistUserIDs, err = updateUserWhitelist(repo, pt.WhitelistUserIDs, userIDs); err != nil {
+		return err
+	}
+	if pt.WhitelistTeamIDs, err = updateTeamWhitelist(repo, pt.WhitelistTeamIDs, teamIDs); err != nil {
+		return err
+	}
+	pt.RepoID = repo.ID
+	pt.re = nil
+
+	if pt.ID == 0 {
+		_, err = x.Insert(pt)
+	} else {
+		_, err = x.ID(pt.ID).AllCols().Update(pt)
+	}
+	return err
+}
+
+// DeleteProtectedTag deletes the protected tag
+func DeleteProtectedTag(pt *ProtectedTag) error {
+	_, err := x.ID(pt.ID).Delete(new(ProtectedTag))
+	return err
+}
+
+// IsUserAllowedToControlTag returns true if the user may create, move or delete
+// the tag. The tags matched by protected tags can only be changed by the users
+// whitelisted by one of the matching protected tags.
+func IsUserAllowedToControlTag(repoID int64, tagName string, userID int64) (bool, error) {
+	tags, err := GetProtectedTags(repoID)
+	if err != nil {
+		return false, err
+	}
+
+	isProtected := false
+	for _, tag := range tags {
+		if !tag.MatchString(tagName) {
+			continue
+		}
+		isProtected = true
+		allowed, err := tag.IsUserAllowed(userID)
+		if err != nil {
+			return false, err
+		} else if allowed {
+			return true, nil
+		}
+	}
+	return !isProtected, nil
+}
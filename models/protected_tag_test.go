@@ -0,0 +1,80 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtectedTag_MatchString(t *testing.T) {
+	kases := []struct {
+		pattern string
+		name    string
+		match   bool
+	}{
+		{"v1.0", "v1.0", true},
+		{"v1.0", "v1x0", false},
+		{"v*", "v1.2.3", true},
+		{"v*", "release/v1", false},
+		{"v*", "v1/rc", false},
+		{"release/**", "release/v1/rc", true},
+		{"v?", "v1", true},
+		{"v?", "v10", false},
+		{`/^v[0-9]+$/`, "v10", true},
+		{`/^v[0-9]+$/`, "v1.0", false},
+	}
+	for _, kase := range kases {
+		pt := &ProtectedTag{NamePattern: kase.pattern}
+		assert.Equal(t, kase.match, pt.MatchString(kase.name), "%s %s", kase.pattern, kase.name)
+	}
+}
+
+func TestUpdateProtectedTag(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	err := UpdateProtectedTag(repo, &ProtectedTag{NamePattern: "/[/"}, nil, nil)
+	assert.True(t, IsErrInvalidTagPattern(err))
+
+	// user 4 has no write access to the repository and is not whitelisted
+	pt := &ProtectedTag{NamePattern: "v*"}
+	assert.NoError(t, UpdateProtectedTag(repo, pt, []int64{2, 4}, nil))
+	assert.Equal(t, []int64{2}, pt.WhitelistUserIDs)
+
+	pt, err = GetProtectedTagByID(repo.ID, pt.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "v*", pt.NamePattern)
+
+	_, err = GetProtectedTagByID(2, pt.ID)
+	assert.True(t, IsErrProtectedTagNotExist(err))
+
+	assert.NoError(t, DeleteProtectedTag(pt))
+	AssertNotExistsBean(t, &ProtectedTag{ID: pt.ID})
+}
+
+func TestIsUserAllowedToControlTag(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	assert.NoError(t, UpdateProtectedTag(repo, &ProtectedTag{NamePattern: "v*"}, []int64{2}, nil))
+
+	kases := []struct {
+		tagName string
+		userID  int64
+		allowed bool
+	}{
+		{"v1.0", 2, true},
+		{"v1.0", 4, false},
+		{"release-1", 4, true},
+	}
+	for _, kase := range kases {
+		allowed, err := IsUserAllowedToControlTag(repo.ID, kase.tagName, kase.userID)
+		assert.NoError(t, err)
+		assert.Equal(t, kase.allowed, allowed, "%s %d", kase.tagName, kase.userID)
+	}
+}
@@ -40,6 +40,18 @@ const (
 	PullRequestGit
 )
 
+// PullRequestFlow defines how the head of the pull request has been pushed
+type PullRequestFlow int
+
+// Enumerate all the pull request flows
+const (
+	// PullRequestFlowGithub pull requests have a branch of the head repository as head
+	PullRequestFlowGithub PullRequestFlow = iota
+	// PullRequestFlowAGit pull requests have been pushed to refs/for/<base branch>
+	// and their head is only stored in the reference of the pull request
+	PullRequestFlowAGit
+)
+
 // PullRequestStatus defines pull request status
 type PullRequestStatus int
 
@@ -71,6 +83,12 @@ type PullRequest struct {
 	BaseBranch      string
 	ProtectedBranch *ProtectedBranch `xorm:"-"`
 	MergeBase       string           `xorm:"VARCHAR(40)"`
+	Flow            PullRequestFlow  `xorm:"NOT NULL DEFAULT 0"`
+
+	// IsDraft marks a pull request which is not ready for review and can not be merged
+	IsDraft bool `xorm:"NOT NULL DEFAULT false"`
+	// AllowMaintainerEdit grants users who can write to the base repository push access to the head branch
+	AllowMaintainerEdit bool `xorm:"NOT NULL DEFAULT false"`
 
 	HasMerged      bool               `xorm:"INDEX"`
 	MergedCommitID string             `xorm:"VARCHAR(40)"`
@@ -138,6 +156,9 @@ func (pr *PullRequest) GetDefaultMergeMessage() string {
 			return ""
 		}
 	}
+	if tmpl := pr.getMergeMessageTemplate(MergeStyleMerge); len(tmpl) > 0 {
+		return pr.expandMergeMessageTemplate(tmpl)
+	}
 	return fmt.Sprintf("Merge branch '%s' of %s/%s into %s", pr.HeadBranch, pr.HeadUserName, pr.HeadRepo.Name, pr.BaseBranch)
 }
 
@@ -147,6 +168,9 @@ func (pr *PullRequest) GetDefaultSquashMessage() string {
 		log.Error("LoadIssue: %v", err)
 		return ""
 	}
+	if tmpl := pr.getMergeMessageTemplate(MergeStyleSquash); len(tmpl) > 0 {
+		return pr.expandMergeMessageTemplate(tmpl)
+	}
 	return fmt.Sprintf("%s (#%d)", pr.Issue.Title, pr.Issue.Index)
 }
 
@@ -155,6 +179,15 @@ func (pr *PullRequest) GetGitRefName() string {
 	return fmt.Sprintf("refs/pull/%d/head", pr.Index)
 }
 
+// GetHeadRef returns the full name of the reference of the head commit in the
+// head repository, which is the pull request reference for AGit pull requests.
+func (pr *PullRequest) GetHeadRef() string {
+	if pr.Flow == PullRequestFlowAGit {
+		return pr.GetGitRefName()
+	}
+	return git.BranchPrefix + pr.HeadBranch
+}
+
 // APIFormat assumes following fields have been assigned with valid values:
 // Required - Issue
 // Optional - Merger
@@ -196,26 +229,28 @@ func (pr *PullRequest) apiFormat(e Engine) *api.PullRequest {
 	}
 
 	apiPullRequest := &api.PullRequest{
-		ID:        pr.ID,
-		URL:       pr.Issue.HTMLURL(),
-		Index:     pr.Index,
-		Poster:    apiIssue.Poster,
-		Title:     apiIssue.Title,
-		Body:      apiIssue.Body,
-		Labels:    apiIssue.Labels,
-		Milestone: apiIssue.Milestone,
-		Assignee:  apiIssue.Assignee,
-		Assignees: apiIssue.Assignees,
-		State:     apiIssue.State,
-		Comments:  apiIssue.Comments,
-		HTMLURL:   pr.Issue.HTMLURL(),
-		DiffURL:   pr.Issue.DiffURL(),
-		PatchURL:  pr.Issue.PatchURL(),
-		HasMerged: pr.HasMerged,
-		MergeBase: pr.MergeBase,
-		Deadline:  apiIssue.Deadline,
-		Created:   pr.Issue.CreatedUnix.AsTimePtr(),
-		Updated:   pr.Issue.UpdatedUnix.AsTimePtr(),
+		ID:                  pr.ID,
+		URL:                 pr.Issue.HTMLURL(),
+		Index:               pr.Index,
+		Poster:              apiIssue.Poster,
+		Title:               apiIssue.Title,
+		Body:                apiIssue.Body,
+		Labels:              apiIssue.Labels,
+		Milestone:           apiIssue.Milestone,
+		Assignee:            apiIssue.Assignee,
+		Assignees:           apiIssue.Assignees,
+		State:               apiIssue.State,
+		Comments:            apiIssue.Comments,
+		HTMLURL:             pr.Issue.HTMLURL(),
+		DiffURL:             pr.Issue.DiffURL(),
+		PatchURL:            pr.Issue.PatchURL(),
+		HasMerged:           pr.HasMerged,
+		Draft:               pr.IsDraft,
+		AllowMaintainerEdit: pr.AllowMaintainerEdit,
+		MergeBase:           pr.MergeBase,
+		Deadline:            apiIssue.Deadline,
+		Created:             pr.Issue.CreatedUnix.AsTimePtr(),
+		Updated:             pr.Issue.UpdatedUnix.AsTimePtr(),
 	}
 	baseBranch, err = pr.BaseRepo.GetBranch(pr.BaseBranch)
 	if err != nil {
@@ -246,37 +281,55 @@ func (pr *PullRequest) apiFormat(e Engine) *api.PullRequest {
 		apiPullRequest.Base = apiBaseBranchInfo
 	}
 
-	headBranch, err = pr.HeadRepo.GetBranch(pr.HeadBranch)
-	if err != nil {
-		if git.IsErrBranchNotExist(err) {
-			apiPullRequest.Head = nil
-		} else {
-			log.Error("GetBranch[%s]: %v", pr.HeadBranch, err)
-			return nil
-		}
-	} else {
-		apiHeadBranchInfo := &api.PRBranchInfo{
+	if pr.Flow == PullRequestFlowAGit {
+		apiPullRequest.Head = &api.PRBranchInfo{
 			Name:       pr.HeadBranch,
-			Ref:        pr.HeadBranch,
+			Ref:        pr.GetGitRefName(),
 			RepoID:     pr.HeadRepoID,
 			Repository: pr.HeadRepo.innerAPIFormat(e, AccessModeNone, false),
 		}
-		headCommit, err = headBranch.GetCommit()
+		headGitRepo, err := git.OpenRepository(pr.HeadRepo.RepoPath())
 		if err != nil {
-			if git.IsErrNotExist(err) {
-				apiHeadBranchInfo.Sha = ""
+			log.Error("OpenRepository[%s]: %v", pr.HeadRepo.RepoPath(), err)
+			return nil
+		}
+		// The head commit of AGit pull requests is only referenced by the pull request
+		if apiPullRequest.Head.Sha, err = headGitRepo.GetRefCommitID(pr.GetGitRefName()); err != nil {
+			log.Error("GetRefCommitID[%s]: %v", pr.GetGitRefName(), err)
+		}
+	} else {
+		headBranch, err = pr.HeadRepo.GetBranch(pr.HeadBranch)
+		if err != nil {
+			if git.IsErrBranchNotExist(err) {
+				apiPullRequest.Head = nil
 			} else {
-				log.Error("GetCommit[%s]: %v", headBranch.Name, err)
+				log.Error("GetBranch[%s]: %v", pr.HeadBranch, err)
 				return nil
 			}
 		} else {
-			apiHeadBranchInfo.Sha = headCommit.ID.String()
+			apiHeadBranchInfo := &api.PRBranchInfo{
+				Name:       pr.HeadBranch,
+				Ref:        pr.HeadBranch,
+				RepoID:     pr.HeadRepoID,
+				Repository: pr.HeadRepo.innerAPIFormat(e, AccessModeNone, false),
+			}
+			headCommit, err = headBranch.GetCommit()
+			if err != nil {
+				if git.IsErrNotExist(err) {
+					apiHeadBranchInfo.Sha = ""
+				} else {
+					log.Error("GetCommit[%s]: %v", headBranch.Name, err)
+					return nil
+				}
+			} else {
+				apiHeadBranchInfo.Sha = headCommit.ID.String()
+			}
+			apiPullRequest.Head = apiHeadBranchInfo
 		}
-		apiPullRequest.Head = apiHeadBranchInfo
 	}
 
 	if pr.Status != PullRequestStatusChecking {
-		mergeable := pr.Status != PullRequestStatusConflict && !pr.IsWorkInProgress()
+		mergeable := pr.Status != PullRequestStatusConflict && !pr.IsWorkInProgress() && !pr.IsDraft
 		apiPullRequest.Mergeable = mergeable
 	}
 	if pr.HasMerged {
@@ -325,7 +378,17 @@ func (pr *PullRequest) CanAutoMerge() bool {
 }
 
 // GetLastCommitStatus returns the last commit status for this pull request.
-func (pr *PullRequest) GetLastCommitStatus() (status *CommitStatus, err error) {
+func (pr *PullRequest) GetLastCommitStatus() (*CommitStatus, error) {
+	statusList, err := pr.GetLatestCommitStatuses()
+	if err != nil {
+		return nil, err
+	}
+	return CalcCommitStatus(statusList), nil
+}
+
+// GetLatestCommitStatuses returns the latest commit status of every context
+// for the head commit of this pull request.
+func (pr *PullRequest) GetLatestCommitStatuses() (statusList []*CommitStatus, err error) {
 	if err = pr.GetHeadRepo(); err != nil {
 		return nil, err
 	}
@@ -340,17 +403,12 @@ func (pr *PullRequest) GetLastCommitStatus() (status *CommitStatus, err error) {
 	}
 
 	repo := pr.HeadRepo
-	lastCommitID, err := headGitRepo.GetBranchCommitID(pr.HeadBranch)
+	lastCommitID, err := headGitRepo.GetRefCommitID(pr.GetHeadRef())
 	if err != nil {
 		return nil, err
 	}
 
-	var statusList []*CommitStatus
-	statusList, err = GetLatestCommitStatus(repo, lastCommitID, 0)
-	if err != nil {
-		return nil, err
-	}
-	return CalcCommitStatus(statusList), nil
+	return GetLatestCommitStatus(repo, lastCommitID, 0)
 }
 
 // MergeStyle represents the approach to merge commits into base branch.
@@ -365,6 +423,8 @@ const (
 	MergeStyleRebaseMerge MergeStyle = "rebase-merge"
 	// MergeStyleSquash squash commits into single commit before merging
 	MergeStyleSquash MergeStyle = "squash"
+	// MergeStyleFastForwardOnly fast-forward the base branch without rebasing, fails if the branches diverged
+	MergeStyleFastForwardOnly MergeStyle = "fast-forward-only"
 )
 
 // CheckUserAllowedToMerge checks whether the user is allowed to merge
@@ -794,13 +854,31 @@ func GetUnmergedPullRequest(headRepoID, baseRepoID int64, headBranch, baseBranch
 	return pr, nil
 }
 
+// GetUnmergedAGitPullRequest returns the open AGit pull request of the repository
+// by given head and base branch.
+func GetUnmergedAGitPullRequest(repoID int64, headBranch, baseBranch string) (*PullRequest, error) {
+	pr := new(PullRequest)
+	has, err := x.
+		Where("head_repo_id=? AND head_branch=? AND base_repo_id=? AND base_branch=? AND has_merged=? AND flow=? AND issue.is_closed=?",
+			repoID, headBranch, repoID, baseBranch, false, PullRequestFlowAGit, false).
+		Join("INNER", "issue", "issue.id=pull_request.issue_id").
+		Get(pr)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPullRequestNotExist{0, 0, repoID, repoID, headBranch, baseBranch}
+	}
+
+	return pr, nil
+}
+
 // GetUnmergedPullRequestsByHeadInfo returns all pull requests that are open and has not been merged
 // by given head information (repo and branch).
 func GetUnmergedPullRequestsByHeadInfo(repoID int64, branch string) ([]*PullRequest, error) {
 	prs := make([]*PullRequest, 0, 2)
 	return prs, x.
-		Where("head_repo_id = ? AND head_branch = ? AND has_merged = ? AND issue.is_closed = ?",
-			repoID, branch, false, false).
+		Where("head_repo_id = ? AND head_branch = ? AND has_merged = ? AND issue.is_closed = ? AND flow = ?",
+			repoID, branch, false, false, PullRequestFlowGithub).
 		Join("INNER", "issue", "issue.id = pull_request.issue_id").
 		Find(&prs)
 }
@@ -928,14 +1006,14 @@ func (pr *PullRequest) UpdatePatch() (err error) {
 			log.Error("UpdatePatch: RemoveRemote: %s", err)
 		}
 	}()
-	pr.MergeBase, _, err = headGitRepo.GetMergeBase(tmpRemote, pr.BaseBranch, pr.HeadBranch)
+	pr.MergeBase, _, err = headGitRepo.GetMergeBase(tmpRemote, pr.BaseBranch, pr.GetHeadRef())
 	if err != nil {
 		return fmt.Errorf("GetMergeBase: %v", err)
 	} else if err = pr.Update(); err != nil {
 		return fmt.Errorf("Update: %v", err)
 	}
 
-	patch, err := headGitRepo.GetPatch(pr.MergeBase, pr.HeadBranch)
+	patch, err := headGitRepo.GetPatch(pr.MergeBase, pr.GetHeadRef())
 	if err != nil {
 		return fmt.Errorf("GetPatch: %v", err)
 	}
@@ -951,6 +1029,10 @@ func (pr *PullRequest) UpdatePatch() (err error) {
 // corresponding branches of base repository.
 // FIXME: Only push branches that are actually updates?
 func (pr *PullRequest) PushToBaseRepo() (err error) {
+	// The head of AGit pull requests is pushed to the base repository directly
+	if pr.Flow == PullRequestFlowAGit {
+		return nil
+	}
 	log.Trace("PushToBaseRepo[%d]: pushing commits to base repo '%s'", pr.BaseRepoID, pr.GetGitRefName())
 
 	headRepoPath := pr.HeadRepo.RepoPath()
@@ -1090,37 +1172,14 @@ func AddTestPullRequestTask(doer *User, repoID int64, branch string, isSync bool
 	}
 
 	if isSync {
-		requests := PullRequestList(prs)
-		if err = requests.LoadAttributes(); err != nil {
-			log.Error("PullRequestList.LoadAttributes: %v", err)
-		}
-		if invalidationErr := checkForInvalidation(requests, repoID, doer, branch); invalidationErr != nil {
-			log.Error("checkForInvalidation: %v", invalidationErr)
-		}
-		if err == nil {
-			for _, pr := range prs {
-				pr.Issue.PullRequest = pr
-				if err = pr.Issue.LoadAttributes(); err != nil {
-					log.Error("LoadAttributes: %v", err)
-					continue
-				}
-				if err = PrepareWebhooks(pr.Issue.Repo, HookEventPullRequest, &api.PullRequestPayload{
-					Action:      api.HookIssueSynchronized,
-					Index:       pr.Issue.Index,
-					PullRequest: pr.Issue.PullRequest.APIFormat(),
-					Repository:  pr.Issue.Repo.APIFormat(AccessModeNone),
-					Sender:      doer.APIFormat(),
-				}); err != nil {
-					log.Error("PrepareWebhooks [pull_id: %v]: %v", pr.ID, err)
-					continue
-				}
-				go HookQueue.Add(pr.Issue.Repo.ID)
-			}
-		}
-
+		syncPullRequests(doer, prs, repoID, branch)
 	}
 
 	addHeadRepoTasks(prs)
+	if isSync {
+		applyPathLabels(doer, prs)
+		lintCommitMessages(doer, prs)
+	}
 
 	log.Trace("AddTestPullRequestTask [base_repo_id: %d, base_branch: %s]: finding pull requests", repoID, branch)
 	prs, err = GetUnmergedPullRequestsByBaseInfo(repoID, branch)
@@ -1133,6 +1192,64 @@ func AddTestPullRequestTask(doer *User, repoID int64, branch string, isSync bool
 	}
 }
 
+// AddTestAGitPullRequestTask adds a new test task for the AGit pull request
+// whose head has been updated by the doer.
+func AddTestAGitPullRequestTask(doer *User, pr *PullRequest) {
+	prs := []*PullRequest{pr}
+	syncPullRequests(doer, prs, pr.HeadRepoID, pr.GetGitRefName())
+	addHeadRepoTasks(prs)
+	applyPathLabels(doer, prs)
+	lintCommitMessages(doer, prs)
+}
+
+// applyPathLabels updates the labels of the pull requests according to the
+// files changed by their new heads.
+func applyPathLabels(doer *User, prs []*PullRequest) {
+	for _, pr := range prs {
+		if err := pr.ApplyPathLabels(doer); err != nil {
+			log.Error("ApplyPathLabels[%d]: %v", pr.ID, err)
+		}
+	}
+}
+
+// syncPullRequests notifies about the new commits pushed to the head of the
+// pull requests and invalidates their outdated code comments and approvals.
+func syncPullRequests(doer *User, prs []*PullRequest, repoID int64, headRef string) {
+	requests := PullRequestList(prs)
+	err := requests.LoadAttributes()
+	if err != nil {
+		log.Error("PullRequestList.LoadAttributes: %v", err)
+	}
+	if invalidationErr := checkForInvalidation(requests, repoID, doer, headRef); invalidationErr != nil {
+		log.Error("checkForInvalidation: %v", invalidationErr)
+	}
+	if err == nil {
+		for _, pr := range prs {
+			pr.Issue.PullRequest = pr
+			if err = pr.Issue.LoadAttributes(); err != nil {
+				log.Error("LoadAttributes: %v", err)
+				continue
+			}
+			if err = PrepareWebhooks(pr.Issue.Repo, HookEventPullRequest, &api.PullRequestPayload{
+				Action:      api.HookIssueSynchronized,
+				Index:       pr.Issue.Index,
+				PullRequest: pr.Issue.PullRequest.APIFormat(),
+				Repository:  pr.Issue.Repo.APIFormat(AccessModeNone),
+				Sender:      doer.APIFormat(),
+			}); err != nil {
+				log.Error("PrepareWebhooks [pull_id: %v]: %v", pr.ID, err)
+				continue
+			}
+			go HookQueue.Add(pr.Issue.Repo.ID)
+		}
+	}
+
+	for _, pr := range prs {
+		pr.dismissStaleApprovalsOnPush(doer)
+		pr.cancelAutoMergeOnPush()
+	}
+}
+
 func checkForInvalidation(requests PullRequestList, repoID int64, doer *User, branch string) error {
 	repo, err := GetRepositoryByID(repoID)
 	if err != nil {
@@ -1175,6 +1292,8 @@ func (pr *PullRequest) checkAndUpdateStatus() {
 	if !pullRequestQueue.Exist(pr.ID) {
 		if err := pr.UpdateCols("status, conflicted_files"); err != nil {
 			log.Error("Update[%d]: %v", pr.ID, err)
+		} else if pr.Status == PullRequestStatusMergeable {
+			AddToAutoMergeQueue(pr.ID)
 		}
 	}
 }
@@ -1194,6 +1313,15 @@ func (pr *PullRequest) IsWorkInProgress() bool {
 	return false
 }
 
+// ChangeDraft marks the pull request as a draft or as ready for review.
+func (pr *PullRequest) ChangeDraft(isDraft bool) error {
+	if pr.IsDraft == isDraft {
+		return nil
+	}
+	pr.IsDraft = isDraft
+	return pr.UpdateCols("is_draft")
+}
+
 // IsFilesConflicted determines if the  Pull Request has changes conflicting with the target branch.
 func (pr *PullRequest) IsFilesConflicted() bool {
 	return len(pr.ConflictedFiles) > 0
@@ -1215,6 +1343,21 @@ func (pr *PullRequest) GetWorkInProgressPrefix() string {
 	return ""
 }
 
+// RemoveWorkInProgressPrefix removes the work in progress prefix from the
+// title of the pull request, which makes it mergeable.
+func (pr *PullRequest) RemoveWorkInProgressPrefix(doer *User) (oldTitle string, err error) {
+	prefix := pr.GetWorkInProgressPrefix()
+	if len(prefix) == 0 {
+		return "", nil
+	}
+	oldTitle = pr.Issue.Title
+	title := strings.TrimSpace(strings.TrimPrefix(oldTitle, prefix))
+	if len(title) == 0 {
+		return "", ErrIssueTitleEmpty{ID: pr.IssueID}
+	}
+	return oldTitle, pr.Issue.ChangeTitle(doer, title)
+}
+
 // TestPullRequests checks and tests untested patches of pull requests.
 // TODO: test more pull requests at same time.
 func TestPullRequests() {
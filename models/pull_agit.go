@@ -0,0 +1,26 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/git"
+)
+
+// CanPushWithoutWriteAccess returns true if the user without write access to
+// the code of the repository can push to it, either to create pull requests
+// by pushing to refs/for/<branch> or as a maintainer of the base repository
+// of pull requests allowing edits from maintainers.
+func CanPushWithoutWriteAccess(repo *Repository, user *User, perm Permission) (bool, error) {
+	if CanCreateAGitPullRequest(perm) {
+		return true, nil
+	}
+	return CanMaintainerWriteToRepo(repo, user)
+}
+
+// CanCreateAGitPullRequest returns true if the user with the permission can
+// create pull requests by pushing to refs/for/<branch>
+func CanCreateAGitPullRequest(perm Permission) bool {
+	return git.SupportProcReceive && perm.CanRead(UnitTypeCode) && perm.CanRead(UnitTypePullRequests)
+}
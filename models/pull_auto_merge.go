@@ -0,0 +1,130 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/sync"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// AutoMergeQueue contains the IDs of pull requests whose scheduled automatic
+// merge has to be checked again.
+var AutoMergeQueue = sync.NewUniqueQueue(setting.Repository.PullRequestQueueLength)
+
+// PullAutoMerge represents a pull request scheduled to be merged
+// automatically once its status checks and approvals are green.
+type PullAutoMerge struct {
+	ID          int64              `xorm:"pk autoincr"`
+	PullID      int64              `xorm:"UNIQUE"`
+	DoerID      int64              `xorm:"NOT NULL"`
+	Doer        *User              `xorm:"-"`
+	MergeStyle  MergeStyle         `xorm:"VARCHAR(50)"`
+	Message     string             `xorm:"TEXT"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// LoadDoer loads the user who scheduled the automatic merge
+func (autoMerge *PullAutoMerge) LoadDoer() (err error) {
+	if autoMerge.Doer == nil {
+		autoMerge.Doer, err = GetUserByID(autoMerge.DoerID)
+	}
+	return err
+}
+
+// ScheduleAutoMerge schedules the pull request to be merged automatically
+func ScheduleAutoMerge(doer *User, pr *PullRequest, mergeStyle MergeStyle, message string) (*PullAutoMerge, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	if has, err := sess.Exist(&PullAutoMerge{PullID: pr.ID}); err != nil {
+		return nil, err
+	} else if has {
+		return nil, ErrPullAutoMergeAlreadyScheduled{PullID: pr.ID}
+	}
+
+	autoMerge := &PullAutoMerge{
+		PullID:     pr.ID,
+		DoerID:     doer.ID,
+		Doer:       doer,
+		MergeStyle: mergeStyle,
+		Message:    message,
+	}
+	if _, err := sess.Insert(autoMerge); err != nil {
+		return nil, err
+	}
+	return autoMerge, sess.Commit()
+}
+
+// GetScheduledAutoMerge returns the scheduled automatic merge of the pull request
+func GetScheduledAutoMerge(pullID int64) (*PullAutoMerge, error) {
+	autoMerge := new(PullAutoMerge)
+	has, err := x.Where("pull_id = ?", pullID).Get(autoMerge)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPullAutoMergeNotExist{PullID: pullID}
+	}
+	return autoMerge, nil
+}
+
+// RemoveScheduledAutoMerge cancels the scheduled automatic merge of the pull request
+func RemoveScheduledAutoMerge(pullID int64) error {
+	deleted, err := x.Delete(&PullAutoMerge{PullID: pullID})
+	if err != nil {
+		return err
+	} else if deleted == 0 {
+		return ErrPullAutoMergeNotExist{PullID: pullID}
+	}
+	return nil
+}
+
+// AddToAutoMergeQueue checks the scheduled automatic merge of the pull requests again
+func AddToAutoMergeQueue(pullIDs ...int64) {
+	for _, pullID := range pullIDs {
+		go AutoMergeQueue.Add(pullID)
+	}
+}
+
+// checkScheduledAutoMergesByHeadRepo checks the scheduled automatic merges
+// of the open pull requests from the repository again.
+func checkScheduledAutoMergesByHeadRepo(e Engine, repoID int64) error {
+	pullIDs := make([]int64, 0, 10)
+	if err := e.Table("pull_auto_merge").
+		In("pull_id", builder.Select("id").From("pull_request").
+			Where(builder.Eq{"head_repo_id": repoID, "has_merged": false})).
+		Cols("pull_id").
+		Find(&pullIDs); err != nil {
+		return err
+	}
+	AddToAutoMergeQueue(pullIDs...)
+	return nil
+}
+
+// cancelAutoMergeOnPush cancels the scheduled automatic merge of the pull
+// request after new commits have been pushed if the repository is configured so.
+func (pr *PullRequest) cancelAutoMergeOnPush() {
+	if err := pr.GetBaseRepo(); err != nil {
+		log.Error("GetBaseRepo: %v", err)
+		return
+	}
+	prUnit, err := pr.BaseRepo.GetUnit(UnitTypePullRequests)
+	if err != nil {
+		log.Error("GetUnit: %v", err)
+		return
+	}
+	if !prUnit.PullRequestsConfig().CancelAutoMergeOnPush {
+		return
+	}
+	if err = RemoveScheduledAutoMerge(pr.ID); err != nil && !IsErrPullAutoMergeNotExist(err) {
+		log.Error("RemoveScheduledAutoMerge[%d]: %v", pr.ID, err)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleAutoMerge(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 1}).(*PullRequest)
+
+	_, err := GetScheduledAutoMerge(pr.ID)
+	assert.True(t, IsErrPullAutoMergeNotExist(err))
+
+	_, err = ScheduleAutoMerge(doer, pr, MergeStyleSquash, "Squash")
+	assert.NoError(t, err)
+	_, err = ScheduleAutoMerge(doer, pr, MergeStyleMerge, "")
+	assert.True(t, IsErrPullAutoMergeAlreadyScheduled(err))
+
+	autoMerge, err := GetScheduledAutoMerge(pr.ID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, doer.ID, autoMerge.DoerID)
+	assert.EqualValues(t, MergeStyleSquash, autoMerge.MergeStyle)
+	assert.EqualValues(t, "Squash", autoMerge.Message)
+	assert.NoError(t, autoMerge.LoadDoer())
+	assert.EqualValues(t, doer.ID, autoMerge.Doer.ID)
+
+	assert.NoError(t, RemoveScheduledAutoMerge(pr.ID))
+	assert.True(t, IsErrPullAutoMergeNotExist(RemoveScheduledAutoMerge(pr.ID)))
+	AssertNotExistsBean(t, &PullAutoMerge{PullID: pr.ID})
+}
+
+func TestPullRequest_cancelAutoMergeOnPush(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 1}).(*PullRequest)
+
+	_, err := ScheduleAutoMerge(doer, pr, MergeStyleMerge, "")
+	assert.NoError(t, err)
+
+	pr.cancelAutoMergeOnPush()
+	AssertExistsAndLoadBean(t, &PullAutoMerge{PullID: pr.ID})
+
+	assert.NoError(t, pr.GetBaseRepo())
+	prUnit, err := pr.BaseRepo.GetUnit(UnitTypePullRequests)
+	assert.NoError(t, err)
+	prUnit.PullRequestsConfig().CancelAutoMergeOnPush = true
+	_, err = x.ID(prUnit.ID).Cols("config").Update(prUnit)
+	assert.NoError(t, err)
+
+	pr.BaseRepo = nil
+	pr.cancelAutoMergeOnPush()
+	AssertNotExistsBean(t, &PullAutoMerge{PullID: pr.ID})
+}
@@ -0,0 +1,263 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/modules/git"
+)
+
+// CodeOwnersFiles are the paths where the CODEOWNERS file of a repository is
+// looked up, the first one which exists on the base branch is used.
+var CodeOwnersFiles = []string{"CODEOWNERS", "docs/CODEOWNERS", ".gitea/CODEOWNERS", ".github/CODEOWNERS"}
+
+// configFileMaxSize is the maximum number of bytes read from a configuration
+// file like CODEOWNERS in a repository
+const configFileMaxSize = 1024 * 1024
+
+// CodeOwnerRule represents a rule of a CODEOWNERS file, the users and the
+// members of the teams own the files matched by the pattern.
+type CodeOwnerRule struct {
+	Pattern string
+	Users   []*User
+	Teams   []*Team
+
+	rule *regexp.Regexp
+}
+
+// Match returns true if the path of a file is matched by the rule
+func (rule *CodeOwnerRule) Match(path string) bool {
+	return rule.rule.MatchString(path)
+}
+
+// IsOwner returns true if the user owns the files matched by the rule
+func (rule *CodeOwnerRule) IsOwner(userID int64) bool {
+	for _, user := range rule.Users {
+		if user.ID == userID {
+			return true
+		}
+	}
+	for _, team := range rule.Teams {
+		if team.IsMember(userID) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseCodeOwners parses the content of a CODEOWNERS file of the repository.
+// Every line consists of a pattern followed by the owners of the matched
+// files, which are @user, @org/team or email addresses. Teams have to belong
+// to the organization owning the repository. It returns the rules and a
+// warning for every line or owner which has been skipped.
+func ParseCodeOwners(repo *Repository, content string) ([]*CodeOwnerRule, []string) {
+	rules := make([]*CodeOwnerRule, 0, 10)
+	warnings := make([]string, 0)
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			warnings = append(warnings, fmt.Sprintf("line %d: no owners for %s", lineNum, fields[0]))
+			continue
+		}
+
+		re, err := codeOwnerPatternToRegexp(fields[0])
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("line %d: invalid pattern %s: %v", lineNum, fields[0], err))
+			continue
+		}
+		rule := &CodeOwnerRule{
+			Pattern: fields[0],
+			rule:    re,
+		}
+
+		for _, owner := range fields[1:] {
+			if err := rule.addOwner(repo, owner); err != nil {
+				warnings = append(warnings, fmt.Sprintf("line %d: %v", lineNum, err))
+			}
+		}
+		if len(rule.Users) == 0 && len(rule.Teams) == 0 {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, warnings
+}
+
+func (rule *CodeOwnerRule) addOwner(repo *Repository, owner string) error {
+	if !strings.HasPrefix(owner, "@") {
+		user, err := GetUserByEmail(owner)
+		if err != nil {
+			if IsErrUserNotExist(err) {
+				return fmt.Errorf("unknown owner %s", owner)
+			}
+			return err
+		}
+		rule.Users = append(rule.Users, user)
+		return nil
+	}
+
+	owner = owner[1:]
+	if i := strings.Index(owner, "/"); i >= 0 {
+		if err := repo.getOwner(x); err != nil {
+			return err
+		}
+		if !repo.Owner.IsOrganization() || !strings.EqualFold(owner[:i], repo.Owner.Name) {
+			return fmt.Errorf("team @%s does not belong to %s", owner, repo.Owner.Name)
+		}
+		team, err := GetTeam(repo.OwnerID, owner[i+1:])
+		if err != nil {
+			if err == ErrTeamNotExist {
+				return fmt.Errorf("unknown team @%s", owner)
+			}
+			return err
+		}
+		rule.Teams = append(rule.Teams, team)
+		return nil
+	}
+
+	user, err := GetUserByName(owner)
+	if err != nil {
+		if IsErrUserNotExist(err) {
+			return fmt.Errorf("unknown owner @%s", owner)
+		}
+		return err
+	}
+	rule.Users = append(rule.Users, user)
+	return nil
+}
+
+// codeOwnerPatternToRegexp converts a gitignore style pattern to a regular
+// expression. A pattern matches a path itself and everything below it,
+// patterns without a slash match at any depth.
+func codeOwnerPatternToRegexp(pattern string) (*regexp.Regexp, error) {
+	isDir := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	isAnchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var buf strings.Builder
+	buf.WriteString("^")
+	if !isAnchored {
+		buf.WriteString("(.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				if i+2 < len(pattern) && pattern[i+2] == '/' {
+					// "**/" matches zero or more directories
+					buf.WriteString("(.*/)?")
+					i += 2
+				} else {
+					buf.WriteString(".*")
+					i++
+				}
+			} else {
+				buf.WriteString("[^/]*")
+			}
+		case '?':
+			buf.WriteString("[^/]")
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if isDir {
+		buf.WriteString("/.*")
+	} else {
+		buf.WriteString("(/.*)?")
+	}
+	buf.WriteString("$")
+	return regexp.Compile(buf.String())
+}
+
+// GetCodeOwnerRules returns the rules of the CODEOWNERS file on the base
+// branch which own at least one file changed by the pull request. For every
+// changed file the last matching rule counts.
+func (pr *PullRequest) GetCodeOwnerRules() ([]*CodeOwnerRule, error) {
+	if err := pr.GetBaseRepo(); err != nil {
+		return nil, err
+	}
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return nil, fmt.Errorf("OpenRepository: %v", err)
+	}
+
+	commit, err := gitRepo.GetBranchCommit(pr.BaseBranch)
+	if err != nil {
+		return nil, fmt.Errorf("GetBranchCommit: %v", err)
+	}
+	content, err := readFirstFile(commit, CodeOwnersFiles)
+	if err != nil || len(content) == 0 {
+		return nil, err
+	}
+	rules, _ := ParseCodeOwners(pr.BaseRepo, content)
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	files, err := gitRepo.GetFilesChangedBetween(commit.ID.String(), pr.GetGitRefName())
+	if err != nil {
+		return nil, fmt.Errorf("GetFilesChangedBetween: %v", err)
+	}
+
+	owningRules := make([]*CodeOwnerRule, 0, len(rules))
+	used := make(map[*CodeOwnerRule]bool, len(rules))
+	for _, file := range files {
+		for i := len(rules) - 1; i >= 0; i-- {
+			if rules[i].Match(file) {
+				if !used[rules[i]] {
+					used[rules[i]] = true
+					owningRules = append(owningRules, rules[i])
+				}
+				break
+			}
+		}
+	}
+	return owningRules, nil
+}
+
+// readFirstFile returns the content of the first of the files found in the commit
+func readFirstFile(commit *git.Commit, paths []string) (string, error) {
+	for _, path := range paths {
+		entry, err := commit.GetTreeEntryByPath(path)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				continue
+			}
+			return "", err
+		}
+		if entry.IsDir() {
+			continue
+		}
+
+		reader, err := entry.Blob().DataAsync()
+		if err != nil {
+			return "", err
+		}
+		defer reader.Close()
+		content, err := ioutil.ReadAll(io.LimitReader(reader, configFileMaxSize))
+		if err != nil {
+			return "", err
+		}
+		return string(content), nil
+	}
+	return "", nil
+}
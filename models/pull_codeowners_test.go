@@ -0,0 +1,81 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCodeOwners(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+	rules, warnings := ParseCodeOwners(repo, `# Comment
+*       @user1 # the default owner
+/docs/  user2@example.com @user3/team1
+*.go    @unknown @user5
+/build
+`)
+	assert.Len(t, rules, 3)
+	assert.Len(t, warnings, 2)
+
+	assert.Equal(t, "*", rules[0].Pattern)
+	assert.Len(t, rules[0].Users, 1)
+	assert.EqualValues(t, 1, rules[0].Users[0].ID)
+
+	assert.Len(t, rules[1].Users, 1)
+	assert.EqualValues(t, 2, rules[1].Users[0].ID)
+	assert.Len(t, rules[1].Teams, 1)
+	assert.EqualValues(t, 2, rules[1].Teams[0].ID)
+	assert.True(t, rules[1].IsOwner(2))
+	assert.True(t, rules[1].IsOwner(4))
+	assert.False(t, rules[1].IsOwner(1))
+
+	assert.Len(t, rules[2].Users, 1)
+	assert.EqualValues(t, 5, rules[2].Users[0].ID)
+
+	// Teams have to belong to the owner of the repository
+	repo = AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	rules, warnings = ParseCodeOwners(repo, "* @user3/team1")
+	assert.Len(t, rules, 0)
+	assert.Len(t, warnings, 1)
+}
+
+func TestCodeOwnerRule_Match(t *testing.T) {
+	for _, c := range []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"*", "README.md", true},
+		{"*", "docs/index.md", true},
+		{"*.go", "main.go", true},
+		{"*.go", "models/repo.go", true},
+		{"*.go", "models/repo.gox", false},
+		{"/docs/", "docs/index.md", true},
+		{"/docs/", "src/docs/index.md", false},
+		{"/docs/", "docs", false},
+		{"docs/", "src/docs/index.md", true},
+		{"/build", "build", true},
+		{"/build", "build/logs/out.log", true},
+		{"/build", "src/build", false},
+		{"apps/*.js", "apps/main.js", true},
+		{"apps/*.js", "src/apps/main.js", false},
+		{"**/logs", "deep/in/logs/out.log", true},
+		{"**/logs", "logs/out.log", true},
+		{"docs/**/*.md", "docs/a/b/c.md", true},
+		{"docs/**/*.md", "docs/c.md", true},
+		{"docs/**/*.md", "docs/c.txt", false},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file10.txt", false},
+	} {
+		re, err := codeOwnerPatternToRegexp(c.pattern)
+		assert.NoError(t, err)
+		rule := &CodeOwnerRule{Pattern: c.pattern, rule: re}
+		assert.Equal(t, c.match, rule.Match(c.path), "%s should match %s: %v", c.pattern, c.path, c.match)
+	}
+}
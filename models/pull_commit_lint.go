@@ -0,0 +1,137 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// CommitMessageLintContext is the context of the commit status which reports
+// whether the commit messages of a pull request follow the rules of its base
+// repository. It can be added to the required status checks to block merging.
+const CommitMessageLintContext = "gitea/commit-message-lint"
+
+// IsCommitMessageLintEnabled returns true if any commit message rule is set
+func (cfg *PullRequestsConfig) IsCommitMessageLintEnabled() bool {
+	return len(cfg.CommitMessageSubjectPattern) > 0 ||
+		cfg.CommitMessageMaxLineLength > 0 ||
+		cfg.CommitMessageRequireIssueRef
+}
+
+// LintCommitMessage returns the rules the commit message violates
+func (cfg *PullRequestsConfig) LintCommitMessage(message string) []string {
+	var violations []string
+	lines := strings.Split(strings.TrimRight(message, "\n"), "\n")
+
+	if len(cfg.CommitMessageSubjectPattern) > 0 {
+		re, err := regexp.Compile(cfg.CommitMessageSubjectPattern)
+		if err != nil {
+			log.Error("Invalid commit message subject pattern %q: %v", cfg.CommitMessageSubjectPattern, err)
+		} else if !re.MatchString(lines[0]) {
+			violations = append(violations, fmt.Sprintf("subject does not match %s", cfg.CommitMessageSubjectPattern))
+		}
+	}
+	if cfg.CommitMessageMaxLineLength > 0 {
+		for i, line := range lines {
+			if len([]rune(line)) > cfg.CommitMessageMaxLineLength {
+				violations = append(violations, fmt.Sprintf("line %d is longer than %d characters", i+1, cfg.CommitMessageMaxLineLength))
+				break
+			}
+		}
+	}
+	if cfg.CommitMessageRequireIssueRef && !issueReferenceKeywordsPat.MatchString(message) {
+		violations = append(violations, "no issue is referenced")
+	}
+	return violations
+}
+
+// LintCommitMessages checks the messages of the commits of the pull request
+// against the rules of its base repository and reports the result as a
+// commit status of its head commit. Merge commits are not checked.
+func (pr *PullRequest) LintCommitMessages(doer *User) error {
+	if err := pr.GetBaseRepo(); err != nil {
+		return err
+	}
+	prUnit, err := pr.BaseRepo.GetUnit(UnitTypePullRequests)
+	if err != nil {
+		return err
+	}
+	prConfig := prUnit.PullRequestsConfig()
+	if !prConfig.IsCommitMessageLintEnabled() {
+		return nil
+	}
+	if err = pr.GetHeadRepo(); err != nil {
+		return err
+	} else if pr.HeadRepo == nil {
+		return nil
+	}
+
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+	headCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		return fmt.Errorf("GetRefCommitID: %v", err)
+	}
+	baseCommitID, err := gitRepo.GetBranchCommitID(pr.BaseBranch)
+	if err != nil {
+		return fmt.Errorf("GetBranchCommitID: %v", err)
+	}
+	commits, err := gitRepo.CommitsBetweenIDs(headCommitID, baseCommitID)
+	if err != nil {
+		return fmt.Errorf("CommitsBetweenIDs: %v", err)
+	}
+
+	var description string
+	invalid := 0
+	for e := commits.Front(); e != nil; e = e.Next() {
+		commit := e.Value.(*git.Commit)
+		if commit.ParentCount() > 1 {
+			continue
+		}
+		violations := prConfig.LintCommitMessage(commit.Message())
+		if len(violations) == 0 {
+			continue
+		}
+		// Commits are listed from the newest, the oldest invalid one is described
+		invalid++
+		description = fmt.Sprintf("%s: %s", commit.ID.String()[:10], strings.Join(violations, ", "))
+	}
+
+	status := &CommitStatus{
+		State:       CommitStatusSuccess,
+		Description: "All commit messages follow the rules",
+		Context:     CommitMessageLintContext,
+	}
+	if invalid > 0 {
+		status.State = CommitStatusFailure
+		status.Description = description
+		if invalid > 1 {
+			status.Description = fmt.Sprintf("%s (and %d more commits)", description, invalid-1)
+		}
+	}
+	return NewCommitStatus(NewCommitStatusOptions{
+		Repo:         pr.HeadRepo,
+		Creator:      doer,
+		SHA:          headCommitID,
+		CommitStatus: status,
+	})
+}
+
+// lintCommitMessages checks the commit messages of the new heads of the pull
+// requests.
+func lintCommitMessages(doer *User, prs []*PullRequest) {
+	for _, pr := range prs {
+		if err := pr.LintCommitMessages(doer); err != nil {
+			log.Error("LintCommitMessages[%d]: %v", pr.ID, err)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullRequestsConfig_LintCommitMessage(t *testing.T) {
+	cfg := &PullRequestsConfig{}
+	assert.False(t, cfg.IsCommitMessageLintEnabled())
+	assert.Empty(t, cfg.LintCommitMessage("anything goes"))
+
+	cfg = &PullRequestsConfig{
+		CommitMessageSubjectPattern:  `^(feat|fix): `,
+		CommitMessageMaxLineLength:   20,
+		CommitMessageRequireIssueRef: true,
+	}
+	assert.True(t, cfg.IsCommitMessageLintEnabled())
+	assert.Empty(t, cfg.LintCommitMessage("fix: typo\n\nFixes #12\n"))
+	assert.Equal(t, []string{"subject does not match ^(feat|fix): "}, cfg.LintCommitMessage("typo\n\nFixes #12"))
+	assert.Equal(t, []string{"line 3 is longer than 20 characters"}, cfg.LintCommitMessage("fix: typo\n\nFixes #12 and more details"))
+	assert.Equal(t, []string{"no issue is referenced"}, cfg.LintCommitMessage("feat: new things"))
+	assert.Len(t, cfg.LintCommitMessage("a subject which is too long"), 3)
+}
@@ -0,0 +1,183 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"regexp"
+
+	"code.gitea.io/gitea/modules/git"
+
+	"gopkg.in/yaml.v2"
+)
+
+// LabelerFiles are the paths where the labeler configuration of a repository
+// is looked up, the first one which exists on the base branch is used.
+var LabelerFiles = []string{".gitea/labeler.yml", ".gitea/labeler.yaml", ".github/labeler.yml", ".github/labeler.yaml"}
+
+// LabelerRule represents a label of the labeler configuration, the label is
+// applied to the pull requests changing a file matched by one of the patterns.
+type LabelerRule struct {
+	Label    string
+	Patterns []string
+
+	rules []*regexp.Regexp
+}
+
+// Match returns true if the path of a file is matched by one of the patterns
+func (rule *LabelerRule) Match(path string) bool {
+	for _, re := range rule.rules {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseLabeler parses the content of a labeler configuration. It maps the
+// label names to a gitignore style pattern or a list of them:
+//
+//	documentation:
+//	  - docs/
+//	  - "*.md"
+//	frontend: web_src/**
+func ParseLabeler(content string) ([]*LabelerRule, error) {
+	var config yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(content), &config); err != nil {
+		return nil, err
+	}
+
+	rules := make([]*LabelerRule, 0, len(config))
+	for _, item := range config {
+		label, ok := item.Key.(string)
+		if !ok || len(label) == 0 {
+			return nil, fmt.Errorf("invalid label %v", item.Key)
+		}
+
+		var patterns []string
+		switch value := item.Value.(type) {
+		case string:
+			patterns = []string{value}
+		case []interface{}:
+			for _, v := range value {
+				pattern, ok := v.(string)
+				if !ok {
+					return nil, fmt.Errorf("label %s: invalid pattern %v", label, v)
+				}
+				patterns = append(patterns, pattern)
+			}
+		default:
+			return nil, fmt.Errorf("label %s: patterns have to be a string or a list of strings", label)
+		}
+
+		rule := &LabelerRule{
+			Label:    label,
+			Patterns: patterns,
+			rules:    make([]*regexp.Regexp, 0, len(patterns)),
+		}
+		for _, pattern := range patterns {
+			re, err := codeOwnerPatternToRegexp(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("label %s: invalid pattern %s: %v", label, pattern, err)
+			}
+			rule.rules = append(rule.rules, re)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// ApplyPathLabels adds the labels of the labeler configuration on the base
+// branch which match a file changed by the pull request and removes the
+// configured labels which do not match any changed file anymore. Labels which
+// are not part of the configuration are left untouched.
+func (pr *PullRequest) ApplyPathLabels(doer *User) error {
+	if err := pr.GetBaseRepo(); err != nil {
+		return err
+	}
+	if err := pr.LoadIssue(); err != nil {
+		return err
+	}
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+
+	commit, err := gitRepo.GetBranchCommit(pr.BaseBranch)
+	if err != nil {
+		return fmt.Errorf("GetBranchCommit: %v", err)
+	}
+	content, err := readFirstFile(commit, LabelerFiles)
+	if err != nil || len(content) == 0 {
+		return err
+	}
+	rules, err := ParseLabeler(content)
+	if err != nil {
+		return fmt.Errorf("ParseLabeler: %v", err)
+	}
+
+	files, err := gitRepo.GetFilesChangedBetween(commit.ID.String(), pr.GetGitRefName())
+	if err != nil {
+		return fmt.Errorf("GetFilesChangedBetween: %v", err)
+	}
+	matched := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		for _, file := range files {
+			if rule.Match(file) {
+				matched[rule.Label] = true
+				break
+			}
+		}
+	}
+
+	return pr.Issue.syncLabels(doer, rules, matched)
+}
+
+// syncLabels adds the labels of the rules which are matched to the issue and
+// removes the ones which are not.
+func (issue *Issue) syncLabels(doer *User, rules []*LabelerRule, matched map[string]bool) error {
+	repoLabels, err := GetLabelsByRepoID(issue.RepoID, "")
+	if err != nil {
+		return fmt.Errorf("GetLabelsByRepoID: %v", err)
+	}
+	labels := make(map[string]*Label, len(repoLabels))
+	for _, label := range repoLabels {
+		labels[label.Name] = label
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	changed := false
+	for _, rule := range rules {
+		label, ok := labels[rule.Label]
+		if !ok {
+			continue
+		}
+		hasLabel := hasIssueLabel(sess, issue.ID, label.ID)
+		if matched[rule.Label] && !hasLabel {
+			if err = newIssueLabel(sess, issue, label, doer); err != nil {
+				return fmt.Errorf("newIssueLabel: %v", err)
+			}
+			changed = true
+		} else if !matched[rule.Label] && hasLabel {
+			if err = deleteIssueLabel(sess, issue, label, doer); err != nil {
+				return fmt.Errorf("deleteIssueLabel: %v", err)
+			}
+			changed = true
+		}
+	}
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+
+	if changed {
+		issue.sendLabelUpdatedWebhook(doer)
+	}
+	return nil
+}
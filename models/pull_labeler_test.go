@@ -0,0 +1,55 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLabeler(t *testing.T) {
+	rules, err := ParseLabeler(`documentation:
+  - docs/
+  - "*.md"
+frontend: web_src/**
+`)
+	assert.NoError(t, err)
+	assert.Len(t, rules, 2)
+
+	assert.Equal(t, "documentation", rules[0].Label)
+	assert.Equal(t, []string{"docs/", "*.md"}, rules[0].Patterns)
+	assert.True(t, rules[0].Match("docs/content/page/index.en-us.md"))
+	assert.True(t, rules[0].Match("README.md"))
+	assert.False(t, rules[0].Match("main.go"))
+
+	assert.Equal(t, "frontend", rules[1].Label)
+	assert.True(t, rules[1].Match("web_src/js/index.js"))
+	assert.False(t, rules[1].Match("src/web_src/index.js"))
+
+	_, err = ParseLabeler("documentation:\n  docs: true\n")
+	assert.Error(t, err)
+	_, err = ParseLabeler("documentation: [docs/, [src/]]\n")
+	assert.Error(t, err)
+}
+
+func TestIssue_SyncLabels(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 2}).(*Issue)
+	rules := []*LabelerRule{{Label: "label1"}, {Label: "label2"}, {Label: "unknown"}}
+
+	assert.NoError(t, issue.syncLabels(doer, rules, map[string]bool{"label2": true, "unknown": true}))
+	AssertNotExistsBean(t, &IssueLabel{IssueID: 2, LabelID: 1})
+	AssertExistsAndLoadBean(t, &IssueLabel{IssueID: 2, LabelID: 2})
+	AssertExistsAndLoadBean(t, &Comment{Type: CommentTypeLabel, IssueID: 2, LabelID: 2})
+
+	// Labels which are not configured are left untouched
+	assert.NoError(t, issue.syncLabels(doer, rules[:1], map[string]bool{"label1": true}))
+	AssertExistsAndLoadBean(t, &IssueLabel{IssueID: 2, LabelID: 1})
+	AssertExistsAndLoadBean(t, &IssueLabel{IssueID: 2, LabelID: 2})
+	CheckConsistencyFor(t, &Issue{}, &Label{})
+}
@@ -0,0 +1,67 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+// ChangeAllowMaintainerEdit allows or disallows users who can write to the
+// base repository to push to the head branch of the pull request.
+func (pr *PullRequest) ChangeAllowMaintainerEdit(allow bool) error {
+	if pr.AllowMaintainerEdit == allow {
+		return nil
+	}
+	pr.AllowMaintainerEdit = allow
+	return pr.UpdateCols("allow_maintainer_edit")
+}
+
+// CanMaintainerWriteToRepo returns true if the user can push to any branch of
+// the repository which is the head of an open pull request allowing edits
+// from maintainers of its base repository.
+func CanMaintainerWriteToRepo(repo *Repository, user *User) (bool, error) {
+	return canMaintainerWriteToHead(repo, "", user)
+}
+
+// CanMaintainerWriteToBranch returns true if the branch of the repository is
+// the head of an open pull request allowing edits from maintainers and the
+// user can write to the code of its base repository.
+func CanMaintainerWriteToBranch(repo *Repository, branch string, user *User) (bool, error) {
+	return canMaintainerWriteToHead(repo, branch, user)
+}
+
+func canMaintainerWriteToHead(repo *Repository, branch string, user *User) (bool, error) {
+	if user == nil {
+		return false, nil
+	}
+
+	sess := x.Join("INNER", "issue", "issue.id = pull_request.issue_id").
+		Where("pull_request.head_repo_id = ? AND pull_request.base_repo_id <> ?", repo.ID, repo.ID).
+		And("pull_request.allow_maintainer_edit = ? AND pull_request.has_merged = ? AND issue.is_closed = ?", true, false, false).
+		And("pull_request.flow = ?", PullRequestFlowGithub)
+	if len(branch) > 0 {
+		sess.And("pull_request.head_branch = ?", branch)
+	}
+	prs := make([]*PullRequest, 0, 2)
+	if err := sess.Find(&prs); err != nil {
+		return false, err
+	}
+
+	checked := make(map[int64]bool, len(prs))
+	for _, pr := range prs {
+		if checked[pr.BaseRepoID] {
+			continue
+		}
+		checked[pr.BaseRepoID] = true
+
+		if err := pr.GetBaseRepo(); err != nil {
+			return false, err
+		}
+		perm, err := GetUserRepoPermission(pr.BaseRepo, user)
+		if err != nil {
+			return false, err
+		}
+		if perm.CanWrite(UnitTypeCode) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
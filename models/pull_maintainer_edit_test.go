@@ -0,0 +1,44 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanMaintainerWriteToBranch(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	headRepo := AssertExistsAndLoadBean(t, &Repository{ID: 11}).(*Repository)
+	maintainer := AssertExistsAndLoadBean(t, &User{ID: 12}).(*User)
+	stranger := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+
+	canWrite, err := CanMaintainerWriteToBranch(headRepo, "branch2", maintainer)
+	assert.NoError(t, err)
+	assert.False(t, canWrite)
+
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 3}).(*PullRequest)
+	assert.NoError(t, pr.ChangeAllowMaintainerEdit(true))
+	AssertExistsAndLoadBean(t, &PullRequest{ID: 3, AllowMaintainerEdit: true})
+
+	canWrite, err = CanMaintainerWriteToBranch(headRepo, "branch2", maintainer)
+	assert.NoError(t, err)
+	assert.True(t, canWrite)
+	canWrite, err = CanMaintainerWriteToRepo(headRepo, maintainer)
+	assert.NoError(t, err)
+	assert.True(t, canWrite)
+
+	// Only the head branch of the pull request can be pushed to
+	canWrite, err = CanMaintainerWriteToBranch(headRepo, "master", maintainer)
+	assert.NoError(t, err)
+	assert.False(t, canWrite)
+
+	// Users who can not write to the base repository are no maintainers
+	canWrite, err = CanMaintainerWriteToRepo(headRepo, stranger)
+	assert.NoError(t, err)
+	assert.False(t, canWrite)
+}
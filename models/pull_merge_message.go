@@ -0,0 +1,133 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// getMergeMessageTemplate returns the template of the default commit message
+// of the merge style configured for the base repository.
+func (pr *PullRequest) getMergeMessageTemplate(mergeStyle MergeStyle) string {
+	if err := pr.GetBaseRepo(); err != nil {
+		log.Error("GetBaseRepo: %v", err)
+		return ""
+	}
+	prUnit, err := pr.BaseRepo.GetUnit(UnitTypePullRequests)
+	if err != nil {
+		return ""
+	}
+	switch mergeStyle {
+	case MergeStyleMerge, MergeStyleRebaseMerge:
+		return prUnit.PullRequestsConfig().DefaultMergeMessageTemplate
+	case MergeStyleSquash:
+		return prUnit.PullRequestsConfig().DefaultSquashMessageTemplate
+	}
+	return ""
+}
+
+// expandMergeMessageTemplate replaces the ${Variable} placeholders of the
+// template with the values of the pull request. Unknown variables are kept.
+func (pr *PullRequest) expandMergeMessageTemplate(tmpl string) string {
+	if err := pr.LoadIssue(); err != nil {
+		log.Error("LoadIssue: %v", err)
+		return ""
+	}
+	if err := pr.Issue.LoadPoster(); err != nil {
+		log.Error("LoadPoster: %v", err)
+		return ""
+	}
+	if err := pr.GetBaseRepo(); err != nil {
+		log.Error("GetBaseRepo: %v", err)
+		return ""
+	}
+	if err := pr.BaseRepo.GetOwnerName(); err != nil {
+		log.Error("GetOwnerName: %v", err)
+		return ""
+	}
+	if pr.HeadRepo == nil {
+		if err := pr.GetHeadRepo(); err != nil {
+			log.Error("GetHeadRepo: %v", err)
+			return ""
+		}
+	}
+	// The head repository might have been deleted
+	headRepoName := ""
+	if pr.HeadRepo != nil {
+		headRepoName = pr.HeadRepo.Name
+	}
+
+	return strings.TrimSpace(os.Expand(tmpl, func(key string) string {
+		switch key {
+		case "PullRequestTitle":
+			return pr.Issue.Title
+		case "PullRequestIndex":
+			return fmt.Sprint(pr.Index)
+		case "PullRequestBody":
+			return pr.Issue.Content
+		case "PullRequestPosterName":
+			return pr.Issue.Poster.Name
+		case "PullRequestReference":
+			return fmt.Sprintf("%s/%s#%d", pr.BaseRepo.OwnerName, pr.BaseRepo.Name, pr.Index)
+		case "BaseRepoOwnerName":
+			return pr.BaseRepo.OwnerName
+		case "BaseRepoName":
+			return pr.BaseRepo.Name
+		case "BaseBranch":
+			return pr.BaseBranch
+		case "HeadRepoOwnerName":
+			return pr.HeadUserName
+		case "HeadRepoName":
+			return headRepoName
+		case "HeadBranch":
+			return pr.HeadBranch
+		case "CoAuthors":
+			return pr.getCoAuthorTrailers()
+		}
+		return "${" + key + "}"
+	}))
+}
+
+// getCoAuthorTrailers returns a Co-authored-by trailer for every author of
+// the commits of the pull request except the poster.
+func (pr *PullRequest) getCoAuthorTrailers() string {
+	if len(pr.MergeBase) == 0 {
+		return ""
+	}
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		log.Error("OpenRepository: %v", err)
+		return ""
+	}
+	headCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		log.Error("GetRefCommitID[%s]: %v", pr.GetGitRefName(), err)
+		return ""
+	}
+	commits, err := gitRepo.CommitsBetweenIDs(headCommitID, pr.MergeBase)
+	if err != nil {
+		log.Error("CommitsBetweenIDs: %v", err)
+		return ""
+	}
+
+	seen := map[string]bool{strings.ToLower(pr.Issue.Poster.Email): true}
+	trailers := make([]string, 0, commits.Len())
+	// Oldest commits first
+	for e := commits.Back(); e != nil; e = e.Prev() {
+		author := e.Value.(*git.Commit).Author
+		email := strings.ToLower(author.Email)
+		if seen[email] {
+			continue
+		}
+		seen[email] = true
+		trailers = append(trailers, fmt.Sprintf("Co-authored-by: %s <%s>", author.Name, author.Email))
+	}
+	return strings.Join(trailers, "\n")
+}
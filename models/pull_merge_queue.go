@@ -0,0 +1,161 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// MergeQueueEntry represents a pull request waiting in the merge queue of
+// its base branch. Pull requests are merged in the order they were queued.
+type MergeQueueEntry struct {
+	ID          int64        `xorm:"pk autoincr"`
+	RepoID      int64        `xorm:"INDEX(s)"`
+	BaseBranch  string       `xorm:"INDEX(s)"`
+	PullID      int64        `xorm:"UNIQUE"`
+	PullRequest *PullRequest `xorm:"-"`
+	DoerID      int64
+	Doer        *User              `xorm:"-"`
+	MergeStyle  MergeStyle         `xorm:"VARCHAR(50)"`
+	Message     string             `xorm:"TEXT"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+
+	// The test merge of the head commit of the pull request on top of the
+	// base commit, which is the base branch merged with the pull requests
+	// ahead in the queue. Only the test merge is merged into the base branch
+	// once it has passed the required checks.
+	BaseCommitID  string `xorm:"VARCHAR(40)"`
+	HeadCommitID  string `xorm:"VARCHAR(40)"`
+	MergeCommitID string `xorm:"VARCHAR(40)"`
+}
+
+// TestBranch returns the name of the branch of the test merge, the branch is
+// pushed to the base repository to have it checked.
+func (entry *MergeQueueEntry) TestBranch() string {
+	return fmt.Sprintf("gitea-merge-queue/%s/%d", entry.BaseBranch, entry.PullID)
+}
+
+// LoadAttributes loads the pull request and the user who queued it
+func (entry *MergeQueueEntry) LoadAttributes() (err error) {
+	if entry.PullRequest == nil {
+		if entry.PullRequest, err = GetPullRequestByID(entry.PullID); err != nil {
+			return err
+		}
+	}
+	if entry.Doer == nil {
+		if entry.Doer, err = GetUserByID(entry.DoerID); err != nil {
+			if !IsErrUserNotExist(err) {
+				return err
+			}
+			entry.Doer = NewGhostUser()
+		}
+	}
+	return nil
+}
+
+// APIFormat converts a MergeQueueEntry to api.MergeQueueEntry, position is
+// the place of the entry in the queue starting with 1.
+func (entry *MergeQueueEntry) APIFormat(position int) *api.MergeQueueEntry {
+	return &api.MergeQueueEntry{
+		ID:         entry.ID,
+		Index:      entry.PullRequest.Index,
+		Base:       entry.BaseBranch,
+		Position:   position,
+		MergeStyle: string(entry.MergeStyle),
+		Enqueuer:   entry.Doer.APIFormat(),
+		Created:    entry.CreatedUnix.AsTime(),
+	}
+}
+
+// AddToMergeQueue adds the pull request to the end of the merge queue of its base branch
+func AddToMergeQueue(pr *PullRequest, doer *User, mergeStyle MergeStyle, message string) (*MergeQueueEntry, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	has, err := sess.Exist(&MergeQueueEntry{PullID: pr.ID})
+	if err != nil {
+		return nil, err
+	} else if has {
+		return nil, ErrMergeQueueEntryAlreadyExist{PullID: pr.ID}
+	}
+
+	entry := &MergeQueueEntry{
+		RepoID:      pr.BaseRepoID,
+		BaseBranch:  pr.BaseBranch,
+		PullID:      pr.ID,
+		PullRequest: pr,
+		DoerID:      doer.ID,
+		Doer:        doer,
+		MergeStyle:  mergeStyle,
+		Message:     message,
+	}
+	if _, err = sess.Insert(entry); err != nil {
+		return nil, fmt.Errorf("Insert: %v", err)
+	}
+	return entry, sess.Commit()
+}
+
+// RemoveFromMergeQueue removes the pull request from the merge queue
+func RemoveFromMergeQueue(pullID int64) error {
+	deleted, err := x.Delete(&MergeQueueEntry{PullID: pullID})
+	if err != nil {
+		return err
+	} else if deleted == 0 {
+		return ErrMergeQueueEntryNotExist{PullID: pullID}
+	}
+	return nil
+}
+
+// UpdateMergeQueueEntryTestMerge updates the test merge of the entry
+func UpdateMergeQueueEntryTestMerge(entry *MergeQueueEntry) error {
+	_, err := x.ID(entry.ID).Cols("base_commit_id", "head_commit_id", "merge_commit_id").Update(entry)
+	return err
+}
+
+// GetMergeQueueEntryByPullID returns the merge queue entry of the pull request
+func GetMergeQueueEntryByPullID(pullID int64) (*MergeQueueEntry, error) {
+	entry := new(MergeQueueEntry)
+	has, err := x.Where("pull_id = ?", pullID).Get(entry)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrMergeQueueEntryNotExist{PullID: pullID}
+	}
+	return entry, nil
+}
+
+// GetMergeQueue returns the entries of the merge queue of the branch in the
+// order they will be merged.
+func GetMergeQueue(repoID int64, baseBranch string) ([]*MergeQueueEntry, error) {
+	entries := make([]*MergeQueueEntry, 0, 10)
+	return entries, x.
+		Where("repo_id = ? AND base_branch = ?", repoID, baseBranch).
+		Asc("id").
+		Find(&entries)
+}
+
+// GetMergeQueuePosition returns the place of the pull request in the merge
+// queue starting with 1.
+func GetMergeQueuePosition(entry *MergeQueueEntry) (int, error) {
+	count, err := x.
+		Where("repo_id = ? AND base_branch = ? AND id < ?", entry.RepoID, entry.BaseBranch, entry.ID).
+		Count(new(MergeQueueEntry))
+	return int(count) + 1, err
+}
+
+// GetNonEmptyMergeQueues returns the repository and base branch of every
+// merge queue which has at least one entry.
+func GetNonEmptyMergeQueues() ([]*MergeQueueEntry, error) {
+	entries := make([]*MergeQueueEntry, 0, 10)
+	return entries, x.
+		Distinct("repo_id", "base_branch").
+		Find(&entries)
+}
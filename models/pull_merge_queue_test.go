@@ -0,0 +1,71 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeQueue(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	pr1 := AssertExistsAndLoadBean(t, &PullRequest{ID: 1}).(*PullRequest)
+	pr2 := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+
+	entry1, err := AddToMergeQueue(pr1, doer, MergeStyleMerge, "")
+	assert.NoError(t, err)
+	entry2, err := AddToMergeQueue(pr2, doer, MergeStyleSquash, "Squash")
+	assert.NoError(t, err)
+
+	_, err = AddToMergeQueue(pr1, doer, MergeStyleMerge, "")
+	assert.True(t, IsErrMergeQueueEntryAlreadyExist(err))
+
+	entries, err := GetMergeQueue(pr1.BaseRepoID, pr1.BaseBranch)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 2) {
+		assert.EqualValues(t, pr1.ID, entries[0].PullID)
+		assert.EqualValues(t, pr2.ID, entries[1].PullID)
+		assert.EqualValues(t, MergeStyleSquash, entries[1].MergeStyle)
+	}
+
+	position, err := GetMergeQueuePosition(entry2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, position)
+
+	queues, err := GetNonEmptyMergeQueues()
+	assert.NoError(t, err)
+	if assert.Len(t, queues, 1) {
+		assert.EqualValues(t, pr1.BaseRepoID, queues[0].RepoID)
+		assert.EqualValues(t, pr1.BaseBranch, queues[0].BaseBranch)
+	}
+
+	assert.NoError(t, RemoveFromMergeQueue(pr1.ID))
+	assert.True(t, IsErrMergeQueueEntryNotExist(RemoveFromMergeQueue(pr1.ID)))
+	_, err = GetMergeQueueEntryByPullID(entry1.PullID)
+	assert.True(t, IsErrMergeQueueEntryNotExist(err))
+
+	entry, err := GetMergeQueueEntryByPullID(pr2.ID)
+	assert.NoError(t, err)
+	position, err = GetMergeQueuePosition(entry)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, position)
+
+	assert.NoError(t, entry.LoadAttributes())
+	apiEntry := entry.APIFormat(position)
+	assert.EqualValues(t, pr2.Index, apiEntry.Index)
+	assert.EqualValues(t, doer.ID, apiEntry.Enqueuer.ID)
+	assert.EqualValues(t, "squash", apiEntry.MergeStyle)
+
+	entry.BaseCommitID = "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+	entry.HeadCommitID = "985f0301dba5e7b34be866819cd15ad3d8f508ee"
+	entry.MergeCommitID = "2a47ca4b614a9f5a43abbd5ad851a54a616ffee6"
+	assert.NoError(t, UpdateMergeQueueEntryTestMerge(entry))
+	AssertExistsAndLoadBean(t, &MergeQueueEntry{ID: entry.ID, MergeCommitID: entry.MergeCommitID})
+	assert.Equal(t, fmt.Sprintf("gitea-merge-queue/%s/%d", pr2.BaseBranch, pr2.ID), entry.TestBranch())
+}
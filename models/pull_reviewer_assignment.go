@@ -0,0 +1,189 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"sort"
+
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ReviewerAssignmentStrategy defines how the reviewers of a pull request are
+// chosen among the members of the reviewer team
+type ReviewerAssignmentStrategy string
+
+const (
+	// ReviewerAssignmentRoundRobin chooses the members whose review has been
+	// requested least recently
+	ReviewerAssignmentRoundRobin ReviewerAssignmentStrategy = "round_robin"
+	// ReviewerAssignmentLoadBalance chooses the members with the fewest
+	// pending review requests on open pull requests
+	ReviewerAssignmentLoadBalance ReviewerAssignmentStrategy = "load_balance"
+)
+
+// IsValid returns true if the strategy is known
+func (s ReviewerAssignmentStrategy) IsValid() bool {
+	return s == ReviewerAssignmentRoundRobin || s == ReviewerAssignmentLoadBalance
+}
+
+// IsReviewerAssignmentEnabled returns true if reviewers are assigned
+// automatically to the new pull requests
+func (cfg *PullRequestsConfig) IsReviewerAssignmentEnabled() bool {
+	return cfg.ReviewerTeamID > 0 && cfg.ReviewerCount > 0
+}
+
+// reviewerCandidate is a member of the reviewer team with the statistics used
+// to choose the reviewers
+type reviewerCandidate struct {
+	User *User
+	// LastRequestedUnix is the time the review of the member has last been
+	// requested in the repository
+	LastRequestedUnix timeutil.TimeStamp
+	// PendingRequests is the number of open pull requests of the repository
+	// which wait for a review of the member
+	PendingRequests int
+}
+
+// sortReviewerCandidates orders the candidates by preference according to the
+// strategy, the candidates with the lowest IDs are preferred on ties.
+func sortReviewerCandidates(candidates []*reviewerCandidate, strategy ReviewerAssignmentStrategy) {
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if strategy == ReviewerAssignmentLoadBalance && a.PendingRequests != b.PendingRequests {
+			return a.PendingRequests < b.PendingRequests
+		}
+		if a.LastRequestedUnix != b.LastRequestedUnix {
+			return a.LastRequestedUnix < b.LastRequestedUnix
+		}
+		return a.User.ID < b.User.ID
+	})
+}
+
+// loadReviewerCandidateStats fills the statistics of the candidates from the
+// reviews of the pull requests of the repository.
+func loadReviewerCandidateStats(e Engine, repoID int64, candidates []*reviewerCandidate) error {
+	byUserID := make(map[int64]*reviewerCandidate, len(candidates))
+	userIDs := make([]int64, 0, len(candidates))
+	for _, candidate := range candidates {
+		byUserID[candidate.User.ID] = candidate
+		userIDs = append(userIDs, candidate.User.ID)
+	}
+
+	type reviewWithIssueState struct {
+		Review   `xorm:"extends"`
+		IsClosed bool
+	}
+	reviews := make([]*reviewWithIssueState, 0, 50)
+	if err := e.Table("review").
+		Select("review.*, issue.is_closed").
+		Join("INNER", "issue", "issue.id = review.issue_id").
+		Where("issue.repo_id = ? AND issue.is_pull = ?", repoID, true).
+		In("review.type", ReviewTypeApprove, ReviewTypeReject, ReviewTypeRequest).
+		In("review.reviewer_id", userIDs).
+		Asc("review.issue_id", "review.reviewer_id").
+		Desc("review.updated_unix", "review.id").
+		Find(&reviews); err != nil {
+		return err
+	}
+
+	type issueReviewer struct{ IssueID, ReviewerID int64 }
+	seen := make(map[issueReviewer]bool, len(reviews))
+	for _, review := range reviews {
+		candidate := byUserID[review.ReviewerID]
+		if review.Type == ReviewTypeRequest && review.CreatedUnix > candidate.LastRequestedUnix {
+			candidate.LastRequestedUnix = review.CreatedUnix
+		}
+		// Only the latest review of a reviewer counts
+		key := issueReviewer{review.IssueID, review.ReviewerID}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if review.Type == ReviewTypeRequest && !review.IsClosed {
+			candidate.PendingRequests++
+		}
+	}
+	return nil
+}
+
+// AssignTeamReviewers requests reviews of the pull request from members of the
+// reviewer team of the repository according to the assignment strategy. The
+// members whose review has already been requested count towards the number of
+// reviewers. Draft pull requests are skipped if the repository opts out, the
+// reviewers are then assigned when they are marked as ready for review.
+func (pr *PullRequest) AssignTeamReviewers(doer *User) error {
+	if err := pr.GetBaseRepo(); err != nil {
+		return err
+	}
+	unit, err := pr.BaseRepo.GetUnit(UnitTypePullRequests)
+	if err != nil {
+		return err
+	}
+	cfg := unit.PullRequestsConfig()
+	if !cfg.IsReviewerAssignmentEnabled() || (pr.IsDraft && cfg.ReviewerAssignmentSkipDrafts) {
+		return nil
+	}
+	if err = pr.LoadIssue(); err != nil {
+		return err
+	}
+	pr.Issue.Repo = pr.BaseRepo
+
+	team, err := GetTeamByID(cfg.ReviewerTeamID)
+	if err != nil {
+		if err == ErrTeamNotExist {
+			return nil
+		}
+		return fmt.Errorf("GetTeamByID: %v", err)
+	}
+	if team.OrgID != pr.BaseRepo.OwnerID {
+		return nil
+	}
+	if err = team.GetMembers(); err != nil {
+		return fmt.Errorf("GetMembers: %v", err)
+	}
+
+	needed := cfg.ReviewerCount
+	candidates := make([]*reviewerCandidate, 0, len(team.Members))
+	for _, member := range team.Members {
+		latest, err := GetLatestReviewByReviewer(pr.IssueID, member.ID)
+		if err != nil && !IsErrReviewNotExist(err) {
+			return fmt.Errorf("GetLatestReviewByReviewer: %v", err)
+		} else if err == nil {
+			if latest.Type == ReviewTypeRequest {
+				needed--
+			}
+			continue
+		}
+		if member.ID == pr.Issue.PosterID || !member.IsActive || member.ProhibitLogin {
+			continue
+		}
+		perm, err := GetUserRepoPermission(pr.BaseRepo, member)
+		if err != nil {
+			return fmt.Errorf("GetUserRepoPermission: %v", err)
+		}
+		if !perm.CanRead(UnitTypePullRequests) {
+			continue
+		}
+		candidates = append(candidates, &reviewerCandidate{User: member})
+	}
+	if needed <= 0 || len(candidates) == 0 {
+		return nil
+	}
+
+	if err = loadReviewerCandidateStats(x, pr.BaseRepo.ID, candidates); err != nil {
+		return fmt.Errorf("loadReviewerCandidateStats: %v", err)
+	}
+	sortReviewerCandidates(candidates, cfg.ReviewerAssignmentStrategy)
+	if len(candidates) > needed {
+		candidates = candidates[:needed]
+	}
+	for _, candidate := range candidates {
+		if _, err = AddReviewRequest(pr.Issue, candidate.User, doer); err != nil {
+			return fmt.Errorf("AddReviewRequest: %v", err)
+		}
+	}
+	return nil
+}
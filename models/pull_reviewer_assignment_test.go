@@ -0,0 +1,64 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortReviewerCandidates(t *testing.T) {
+	newCandidates := func() []*reviewerCandidate {
+		return []*reviewerCandidate{
+			{User: &User{ID: 3}, LastRequestedUnix: 200, PendingRequests: 0},
+			{User: &User{ID: 1}, LastRequestedUnix: 300, PendingRequests: 2},
+			{User: &User{ID: 4}, LastRequestedUnix: 0, PendingRequests: 1},
+			{User: &User{ID: 2}, LastRequestedUnix: 0, PendingRequests: 1},
+		}
+	}
+	userIDs := func(candidates []*reviewerCandidate) []int64 {
+		ids := make([]int64, 0, len(candidates))
+		for _, candidate := range candidates {
+			ids = append(ids, candidate.User.ID)
+		}
+		return ids
+	}
+
+	candidates := newCandidates()
+	sortReviewerCandidates(candidates, ReviewerAssignmentRoundRobin)
+	assert.Equal(t, []int64{2, 4, 3, 1}, userIDs(candidates))
+
+	candidates = newCandidates()
+	sortReviewerCandidates(candidates, ReviewerAssignmentLoadBalance)
+	assert.Equal(t, []int64{3, 2, 4, 1}, userIDs(candidates))
+}
+
+func TestLoadReviewerCandidateStats(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 3}).(*Issue)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	user2 := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	user4 := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	_, err := AddReviewRequest(issue, user4, doer)
+	assert.NoError(t, err)
+
+	candidates := []*reviewerCandidate{{User: user2}, {User: user4}}
+	assert.NoError(t, loadReviewerCandidateStats(x, issue.RepoID, candidates))
+	assert.EqualValues(t, 0, candidates[0].PendingRequests)
+	assert.Zero(t, candidates[0].LastRequestedUnix)
+	assert.EqualValues(t, 1, candidates[1].PendingRequests)
+	assert.NotZero(t, candidates[1].LastRequestedUnix)
+}
+
+func TestPullRequest_AssignTeamReviewers(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	// Reviewers are only assigned if the repository configures a team
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+	assert.NoError(t, pr.AssignTeamReviewers(AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)))
+	AssertNotExistsBean(t, &Review{IssueID: pr.IssueID, Type: ReviewTypeRequest, ReviewerID: 2})
+}
@@ -0,0 +1,114 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"sort"
+
+	"code.gitea.io/gitea/modules/git"
+)
+
+// maxSuggestedReviewerFiles is the maximum number of changed files which are
+// blamed to suggest reviewers, blaming is expensive for large pull requests.
+const maxSuggestedReviewerFiles = 50
+
+// DefaultSuggestedReviewersLimit is the number of suggested reviewers shown
+const DefaultSuggestedReviewersLimit = 5
+
+// SuggestedReviewer is a user who last changed some of the lines modified by
+// a pull request
+type SuggestedReviewer struct {
+	User  *User
+	Lines int
+}
+
+// GetSuggestedReviewers returns the users who last changed most of the lines
+// modified or deleted by the pull request according to git blame at its merge
+// base, ordered by the number of lines. The poster and the users who can not
+// read the pull request are left out.
+func (pr *PullRequest) GetSuggestedReviewers(limit int) ([]*SuggestedReviewer, error) {
+	if err := pr.GetBaseRepo(); err != nil {
+		return nil, err
+	}
+	if err := pr.LoadIssue(); err != nil {
+		return nil, err
+	}
+	if len(pr.MergeBase) == 0 {
+		return nil, nil
+	}
+
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return nil, fmt.Errorf("OpenRepository: %v", err)
+	}
+	headCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		return nil, fmt.Errorf("GetRefCommitID: %v", err)
+	}
+	ranges, err := gitRepo.GetChangedLineRanges(pr.MergeBase, headCommitID)
+	if err != nil {
+		return nil, fmt.Errorf("GetChangedLineRanges: %v", err)
+	}
+	files := make([]string, 0, len(ranges))
+	for file := range ranges {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	if len(files) > maxSuggestedReviewerFiles {
+		files = files[:maxSuggestedReviewerFiles]
+	}
+
+	lines := make(map[string]int)
+	for _, file := range files {
+		authors, err := gitRepo.BlameAuthors(pr.MergeBase, file, ranges[file])
+		if err != nil {
+			return nil, fmt.Errorf("BlameAuthors[%s]: %v", file, err)
+		}
+		for email, count := range authors {
+			lines[email] += count
+		}
+	}
+
+	reviewers := make([]*SuggestedReviewer, 0, len(lines))
+	byUserID := make(map[int64]*SuggestedReviewer, len(lines))
+	for email, count := range lines {
+		user, err := GetUserByEmail(email)
+		if err != nil {
+			if IsErrUserNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("GetUserByEmail: %v", err)
+		}
+		if reviewer, ok := byUserID[user.ID]; ok {
+			reviewer.Lines += count
+			continue
+		}
+		if user.ID == pr.Issue.PosterID || !user.IsActive || user.ProhibitLogin {
+			continue
+		}
+		perm, err := GetUserRepoPermission(pr.BaseRepo, user)
+		if err != nil {
+			return nil, fmt.Errorf("GetUserRepoPermission: %v", err)
+		}
+		if !perm.CanRead(UnitTypePullRequests) {
+			continue
+		}
+		reviewer := &SuggestedReviewer{User: user, Lines: count}
+		byUserID[user.ID] = reviewer
+		reviewers = append(reviewers, reviewer)
+	}
+
+	sort.Slice(reviewers, func(i, j int) bool {
+		if reviewers[i].Lines != reviewers[j].Lines {
+			return reviewers[i].Lines > reviewers[j].Lines
+		}
+		return reviewers[i].User.LowerName < reviewers[j].User.LowerName
+	})
+	if limit > 0 && len(reviewers) > limit {
+		reviewers = reviewers[:limit]
+	}
+	return reviewers, nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/git"
+)
+
+// PullRequestSize represents the number of files and lines changed by a pull request
+type PullRequestSize struct {
+	ChangedFiles int
+	Additions    int
+	Deletions    int
+}
+
+// ChangedLines returns the number of added and deleted lines
+func (size *PullRequestSize) ChangedLines() int {
+	return size.Additions + size.Deletions
+}
+
+// IsSizeLimited returns true if the size of the pull requests is limited
+func (cfg *PullRequestsConfig) IsSizeLimited() bool {
+	return cfg.MaxChangedFiles > 0 || cfg.MaxChangedLines > 0
+}
+
+// IsSizeExceeded returns true if the size exceeds one of the limits
+func (cfg *PullRequestsConfig) IsSizeExceeded(size *PullRequestSize) bool {
+	return cfg.MaxChangedFiles > 0 && size.ChangedFiles > cfg.MaxChangedFiles ||
+		cfg.MaxChangedLines > 0 && size.ChangedLines() > cfg.MaxChangedLines
+}
+
+// GetSize returns the number of files and lines changed by the pull request
+// since its merge base without generating the diff.
+func (pr *PullRequest) GetSize() (*PullRequestSize, error) {
+	if err := pr.GetBaseRepo(); err != nil {
+		return nil, err
+	}
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return nil, fmt.Errorf("OpenRepository: %v", err)
+	}
+
+	size := new(PullRequestSize)
+	size.ChangedFiles, size.Additions, size.Deletions, err = gitRepo.GetDiffShortStat(pr.MergeBase, pr.GetGitRefName())
+	if err != nil {
+		return nil, fmt.Errorf("GetDiffShortStat: %v", err)
+	}
+	return size, nil
+}
+
+// CheckSizeLimits returns an ErrPullRequestSizeExceeded if the pull request
+// exceeds the size limits of the repository and oversized pull requests can
+// not be merged.
+func (pr *PullRequest) CheckSizeLimits() error {
+	if err := pr.GetBaseRepo(); err != nil {
+		return err
+	}
+	prUnit, err := pr.BaseRepo.GetUnit(UnitTypePullRequests)
+	if err != nil {
+		return err
+	}
+	prConfig := prUnit.PullRequestsConfig()
+	if !prConfig.BlockOversizedMerge || !prConfig.IsSizeLimited() {
+		return nil
+	}
+
+	size, err := pr.GetSize()
+	if err != nil {
+		return err
+	}
+	if prConfig.IsSizeExceeded(size) {
+		return ErrPullRequestSizeExceeded{ChangedFiles: size.ChangedFiles, ChangedLines: size.ChangedLines()}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullRequestsConfig_IsSizeExceeded(t *testing.T) {
+	size := &PullRequestSize{ChangedFiles: 10, Additions: 80, Deletions: 20}
+	assert.Equal(t, 100, size.ChangedLines())
+
+	cfg := &PullRequestsConfig{}
+	assert.False(t, cfg.IsSizeLimited())
+	assert.False(t, cfg.IsSizeExceeded(size))
+
+	cfg.MaxChangedFiles = 10
+	assert.True(t, cfg.IsSizeLimited())
+	assert.False(t, cfg.IsSizeExceeded(size))
+	cfg.MaxChangedFiles = 9
+	assert.True(t, cfg.IsSizeExceeded(size))
+
+	cfg = &PullRequestsConfig{MaxChangedLines: 100}
+	assert.False(t, cfg.IsSizeExceeded(size))
+	cfg.MaxChangedLines = 99
+	assert.True(t, cfg.IsSizeExceeded(size))
+}
@@ -0,0 +1,79 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+)
+
+// GetParentPullRequest returns the open pull request whose head branch is the
+// base branch of pr, which makes pr stacked on it. It returns nil if there is
+// no such pull request.
+func (pr *PullRequest) GetParentPullRequest() (*PullRequest, error) {
+	prs, err := GetUnmergedPullRequestsByHeadInfo(pr.BaseRepoID, pr.BaseBranch)
+	if err != nil || len(prs) == 0 {
+		return nil, err
+	}
+	parent := prs[0]
+	return parent, parent.LoadIssue()
+}
+
+// GetStackedPullRequests returns the open pull requests whose base branch is
+// the head branch of pr.
+func (pr *PullRequest) GetStackedPullRequests() ([]*PullRequest, error) {
+	if pr.Flow == PullRequestFlowAGit {
+		return nil, nil
+	}
+	return GetUnmergedPullRequestsByBaseInfo(pr.HeadRepoID, pr.HeadBranch)
+}
+
+// ChangeTargetBranch changes the base branch of the pull request and its
+// merge base. The patch has to be updated and tested afterwards.
+func (pr *PullRequest) ChangeTargetBranch(doer *User, targetBranch string) (err error) {
+	if pr.BaseBranch == targetBranch {
+		return nil
+	}
+	if existing, err := GetUnmergedPullRequest(pr.HeadRepoID, pr.BaseRepoID, pr.HeadBranch, targetBranch); err == nil {
+		return ErrPullRequestAlreadyExists{
+			ID:         existing.ID,
+			IssueID:    existing.Index,
+			HeadRepoID: existing.HeadRepoID,
+			BaseRepoID: existing.BaseRepoID,
+			HeadBranch: existing.HeadBranch,
+			BaseBranch: existing.BaseBranch,
+		}
+	} else if !IsErrPullRequestNotExist(err) {
+		return err
+	}
+	if err = pr.LoadIssue(); err != nil {
+		return err
+	}
+	if err = pr.GetBaseRepo(); err != nil {
+		return err
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	oldBranch := pr.BaseBranch
+	pr.BaseBranch = targetBranch
+	if _, err = sess.ID(pr.ID).Cols("base_branch").Update(pr); err != nil {
+		return fmt.Errorf("update base_branch: %v", err)
+	}
+	if _, err = createComment(sess, &CreateCommentOptions{
+		Type:   CommentTypeChangeTargetBranch,
+		Doer:   doer,
+		Repo:   pr.BaseRepo,
+		Issue:  pr.Issue,
+		OldRef: oldBranch,
+		NewRef: targetBranch,
+	}); err != nil {
+		return fmt.Errorf("createComment: %v", err)
+	}
+	return sess.Commit()
+}
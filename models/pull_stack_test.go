@@ -0,0 +1,41 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullRequest_ChangeTargetBranch(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+
+	parent, err := pr.GetParentPullRequest()
+	assert.NoError(t, err)
+	assert.Nil(t, parent)
+
+	assert.NoError(t, pr.ChangeTargetBranch(doer, "branch1"))
+	AssertExistsAndLoadBean(t, &PullRequest{ID: 2, BaseBranch: "branch1"})
+	AssertExistsAndLoadBean(t, &Comment{
+		Type:    CommentTypeChangeTargetBranch,
+		IssueID: pr.IssueID,
+		OldRef:  "master",
+		NewRef:  "branch1",
+	})
+
+	// The merged pull request of branch1 is not a parent
+	parent, err = pr.GetParentPullRequest()
+	assert.NoError(t, err)
+	assert.Nil(t, parent)
+
+	stacked, err := (&PullRequest{HeadRepoID: 1, HeadBranch: "branch1"}).GetStackedPullRequests()
+	assert.NoError(t, err)
+	if assert.Len(t, stacked, 1) {
+		assert.EqualValues(t, 2, stacked[0].ID)
+	}
+}
@@ -107,6 +107,25 @@ func TestGetUnmergedPullRequest(t *testing.T) {
 	assert.True(t, IsErrPullRequestNotExist(err))
 }
 
+func TestGetUnmergedAGitPullRequest(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	_, err := GetUnmergedAGitPullRequest(1, "branch2", "master")
+	assert.True(t, IsErrPullRequestNotExist(err))
+
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+	pr.Flow = PullRequestFlowAGit
+	assert.NoError(t, pr.UpdateCols("flow"))
+	assert.Equal(t, pr.GetGitRefName(), pr.GetHeadRef())
+
+	pr, err = GetUnmergedAGitPullRequest(1, "branch2", "master")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), pr.ID)
+
+	prs, err := GetUnmergedPullRequestsByHeadInfo(1, "branch2")
+	assert.NoError(t, err)
+	assert.Len(t, prs, 0)
+}
+
 func TestGetUnmergedPullRequestsByHeadInfo(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	prs, err := GetUnmergedPullRequestsByHeadInfo(1, "branch2")
@@ -276,3 +295,64 @@ func TestPullRequest_GetWorkInProgressPrefixWorkInProgress(t *testing.T) {
 	pr.Issue.Title = "[wip] " + original
 	assert.Equal(t, "[wip]", pr.GetWorkInProgressPrefix())
 }
+
+func TestPullRequest_RemoveWorkInProgressPrefix(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+	assert.NoError(t, pr.LoadIssue())
+
+	oldTitle, err := pr.RemoveWorkInProgressPrefix(doer)
+	assert.NoError(t, err)
+	assert.Empty(t, oldTitle)
+
+	original := pr.Issue.Title
+	pr.Issue.Title = "WIP:"
+	_, err = pr.RemoveWorkInProgressPrefix(doer)
+	assert.True(t, IsErrIssueTitleEmpty(err))
+
+	pr.Issue.Title = "[WIP] " + original
+	oldTitle, err = pr.RemoveWorkInProgressPrefix(doer)
+	assert.NoError(t, err)
+	assert.Equal(t, "[WIP] "+original, oldTitle)
+	assert.False(t, pr.IsWorkInProgress())
+	AssertExistsAndLoadBean(t, &Issue{ID: pr.IssueID, Title: original})
+}
+
+func TestPullRequest_ChangeDraft(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+	assert.False(t, pr.IsDraft)
+
+	assert.NoError(t, pr.ChangeDraft(true))
+	pr = AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+	assert.True(t, pr.IsDraft)
+
+	assert.NoError(t, pr.LoadIssue())
+	apiPullRequest := pr.APIFormat()
+	assert.True(t, apiPullRequest.Draft)
+	assert.False(t, apiPullRequest.Mergeable)
+
+	assert.NoError(t, pr.ChangeDraft(false))
+	pr = AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+	assert.False(t, pr.IsDraft)
+}
+
+func TestPullRequest_GetDefaultSquashMessage(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 1}).(*PullRequest)
+	assert.Equal(t, "issue2 (#2)", pr.GetDefaultSquashMessage())
+
+	assert.NoError(t, pr.GetBaseRepo())
+	prUnit, err := pr.BaseRepo.GetUnit(UnitTypePullRequests)
+	assert.NoError(t, err)
+	prUnit.PullRequestsConfig().DefaultSquashMessageTemplate = "${PullRequestTitle} (${PullRequestReference})\n\n${PullRequestBody}\n\nFrom ${HeadRepoOwnerName}/${HeadRepoName}:${HeadBranch} by ${PullRequestPosterName}${Unknown}"
+	_, err = x.ID(prUnit.ID).Cols("config").Update(prUnit)
+	assert.NoError(t, err)
+
+	pr = AssertExistsAndLoadBean(t, &PullRequest{ID: 1}).(*PullRequest)
+	assert.Equal(t, "issue2 (user2/repo1#2)\n\ncontent for the second issue\n\nFrom user1/repo1:branch1 by user1${Unknown}", pr.GetDefaultSquashMessage())
+	assert.Equal(t, "Merge branch 'branch1' of user1/repo1 into master", pr.GetDefaultMergeMessage())
+}
@@ -0,0 +1,35 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/git"
+)
+
+// GetCommitsBehind returns the number of commits of the base branch which
+// are not in the head of the pull request.
+func (pr *PullRequest) GetCommitsBehind() (int64, error) {
+	if err := pr.GetBaseRepo(); err != nil {
+		return 0, err
+	}
+	stdout, err := git.NewCommand("rev-list", "--count", pr.GetGitRefName()+".."+git.BranchPrefix+pr.BaseBranch).RunInDir(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return 0, fmt.Errorf("rev-list: %v", err)
+	}
+	return strconv.ParseInt(strings.TrimSpace(stdout), 10, 64)
+}
+
+// GetOutdatedCommitsCount returns the number of commits the head of pr is
+// behind the branch if outdated pull requests can not be merged into it.
+func (protectBranch *ProtectedBranch) GetOutdatedCommitsCount(pr *PullRequest) (int64, error) {
+	if !protectBranch.BlockOnOutdatedBranch {
+		return 0, nil
+	}
+	return pr.GetCommitsBehind()
+}
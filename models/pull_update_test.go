@@ -0,0 +1,21 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtectedBranch_GetOutdatedCommitsCount(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+
+	// Outdated pull requests are not checked if they are not blocked
+	behind, err := (&ProtectedBranch{}).GetOutdatedCommitsCount(pr)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, behind)
+}
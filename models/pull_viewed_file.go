@@ -0,0 +1,61 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// PullViewedFile represents a changed file of a pull request which has been
+// marked as viewed by a reviewer. The mark only counts as long as the diff of
+// the file has the same hash.
+type PullViewedFile struct {
+	ID          int64              `xorm:"pk autoincr"`
+	UserID      int64              `xorm:"INDEX(s) NOT NULL"`
+	PullID      int64              `xorm:"INDEX(s) NOT NULL"`
+	TreePath    string             `xorm:"NOT NULL"`
+	DiffHash    string             `xorm:"VARCHAR(40)"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// GetPullViewedFiles returns the diff hashes of the files of the pull request
+// the user has marked as viewed, mapped by their paths.
+func GetPullViewedFiles(userID, pullID int64) (map[string]string, error) {
+	files := make([]*PullViewedFile, 0, 10)
+	if err := x.Where("user_id = ? AND pull_id = ?", userID, pullID).Find(&files); err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[string]string, len(files))
+	for _, file := range files {
+		hashes[file.TreePath] = file.DiffHash
+	}
+	return hashes, nil
+}
+
+// SetPullFileViewed marks the file of the pull request as viewed by the user
+// with the given diff hash, or removes the mark if viewed is false.
+func SetPullFileViewed(userID, pullID int64, treePath, diffHash string, viewed bool) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := sess.Delete(&PullViewedFile{UserID: userID, PullID: pullID, TreePath: treePath}); err != nil {
+		return err
+	}
+	if viewed {
+		if _, err := sess.Insert(&PullViewedFile{
+			UserID:   userID,
+			PullID:   pullID,
+			TreePath: treePath,
+			DiffHash: diffHash,
+		}); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
@@ -0,0 +1,34 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetPullFileViewed(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, SetPullFileViewed(1, 2, "README.md", "hash1", true))
+	assert.NoError(t, SetPullFileViewed(1, 2, "main.go", "hash2", true))
+	assert.NoError(t, SetPullFileViewed(2, 2, "README.md", "hash3", true))
+
+	files, err := GetPullViewedFiles(1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"README.md": "hash1", "main.go": "hash2"}, files)
+
+	// Marking the file again replaces the hash
+	assert.NoError(t, SetPullFileViewed(1, 2, "README.md", "hash4", true))
+	assert.NoError(t, SetPullFileViewed(1, 2, "main.go", "", false))
+	files, err = GetPullViewedFiles(1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"README.md": "hash4"}, files)
+
+	files, err = GetPullViewedFiles(2, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"README.md": "hash3"}, files)
+}
@@ -0,0 +1,206 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// pushPolicy is the compiled push policy of the instance
+type pushPolicy struct {
+	maxFileSize          int64
+	forbiddenFiles       []*regexp.Regexp
+	forbiddenPatterns    []string
+	requireSignedCommits bool
+	allowedEmailDomains  []string
+}
+
+// getPushPolicy returns the push policy of the settings, nil if it does not
+// enforce anything
+func getPushPolicy() (*pushPolicy, error) {
+	settings := setting.Repository.PushPolicy
+	policy := &pushPolicy{
+		maxFileSize:          settings.MaxFileSize,
+		requireSignedCommits: settings.RequireSignedCommits,
+	}
+	for _, pattern := range settings.ForbiddenFilePatterns {
+		if pattern = strings.TrimSpace(pattern); len(pattern) == 0 {
+			continue
+		}
+		re, err := codeOwnerPatternToRegexp(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid forbidden file pattern %s: %v", pattern, err)
+		}
+		policy.forbiddenFiles = append(policy.forbiddenFiles, re)
+		policy.forbiddenPatterns = append(policy.forbiddenPatterns, pattern)
+	}
+	for _, domain := range settings.AllowedCommitterEmailDomains {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); len(domain) > 0 {
+			policy.allowedEmailDomains = append(policy.allowedEmailDomains, domain)
+		}
+	}
+
+	if policy.maxFileSize < 0 && len(policy.forbiddenFiles) == 0 && !policy.requireSignedCommits && len(policy.allowedEmailDomains) == 0 {
+		return nil, nil
+	}
+	return policy, nil
+}
+
+// checkFilePaths checks the paths of the files added or changed by the commits
+// pushed
+func (policy *pushPolicy) checkFilePaths(repoPath, newCommitID string, env []string) error {
+	if len(policy.forbiddenFiles) == 0 {
+		return nil
+	}
+
+	output, err := git.NewCommand("log", "-z", "--format=", "--name-only", "--no-renames", "--diff-filter=d", newCommitID, "--not", "--all").RunInDirWithEnv(repoPath, env)
+	if err != nil {
+		return fmt.Errorf("list changed files: %v", err)
+	}
+	for _, path := range strings.Split(output, "\x00") {
+		if path = strings.TrimSpace(path); len(path) == 0 {
+			continue
+		}
+		for i, re := range policy.forbiddenFiles {
+			if re.MatchString(path) {
+				return ErrPushPolicyViolation{Reason: fmt.Sprintf("file %s matches the forbidden pattern %s", path, policy.forbiddenPatterns[i])}
+			}
+		}
+	}
+	return nil
+}
+
+// checkFileSizes checks the size of the files pushed, which are the blobs
+// listed by git rev-list --objects
+func (policy *pushPolicy) checkFileSizes(repoPath, newCommitID string, env []string) error {
+	if policy.maxFileSize < 0 {
+		return nil
+	}
+
+	objects, err := git.NewCommand("rev-list", "--objects", newCommitID, "--not", "--all").RunInDirWithEnv(repoPath, env)
+	if err != nil {
+		return fmt.Errorf("list pushed objects: %v", err)
+	}
+	paths := make(map[string]string)
+	var ids strings.Builder
+	for _, line := range strings.Split(objects, "\n") {
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) < 2 || len(fields[1]) == 0 {
+			// Commits and the root trees have no path
+			continue
+		}
+		paths[fields[0]] = fields[1]
+		ids.WriteString(fields[0])
+		ids.WriteByte('\n')
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	var stdout, stderr strings.Builder
+	if err = git.NewCommand("cat-file", "--batch-check=%(objectname) %(objecttype) %(objectsize)").
+		RunInDirTimeoutEnvFullPipeline(env, -1, repoPath, &stdout, &stderr, strings.NewReader(ids.String())); err != nil {
+		return fmt.Errorf("check pushed objects: %v - %s", err, stderr.String())
+	}
+	scanner := bufio.NewScanner(strings.NewReader(stdout.String()))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || fields[1] != "blob" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid size of object %s: %v", fields[0], err)
+		}
+		if size > policy.maxFileSize {
+			return ErrPushPolicyViolation{Reason: fmt.Sprintf("file %s is %s, larger than the limit of %s", paths[fields[0]], base.FileSize(size), base.FileSize(policy.maxFileSize))}
+		}
+	}
+	return nil
+}
+
+// checkCommits checks the signatures and the committers of the commits pushed
+func (policy *pushPolicy) checkCommits(repo *Repository, repoPath, newCommitID string, env []string) error {
+	if !policy.requireSignedCommits && len(policy.allowedEmailDomains) == 0 {
+		return nil
+	}
+
+	commitIDs, err := git.NewCommand("rev-list", newCommitID, "--not", "--all").RunInDirWithEnv(repoPath, env)
+	if err != nil {
+		return fmt.Errorf("list pushed commits: %v", err)
+	}
+	for _, commitID := range strings.Fields(commitIDs) {
+		var stdout, stderr strings.Builder
+		if err = git.NewCommand("cat-file", "commit", commitID).RunInDirTimeoutEnvPipeline(env, -1, repoPath, &stdout, &stderr); err != nil {
+			return fmt.Errorf("read commit %s: %v - %s", commitID, err, stderr.String())
+		}
+		commit, err := git.CommitFromReader(strings.NewReader(stdout.String()))
+		if err != nil {
+			return fmt.Errorf("parse commit %s: %v", commitID, err)
+		}
+
+		if len(policy.allowedEmailDomains) > 0 && !policy.isEmailDomainAllowed(commit.Committer.Email) {
+			return ErrPushPolicyViolation{Reason: fmt.Sprintf("commit %s has a committer email %s outside of the allowed domains %s", base.ShortSha(commitID), commit.Committer.Email, strings.Join(policy.allowedEmailDomains, ", "))}
+		}
+		if policy.requireSignedCommits {
+			if verification := ParseCommitWithSignature(repo, commit); !verification.Verified {
+				return ErrPushPolicyViolation{Reason: fmt.Sprintf("commit %s is not signed with a verified signature", base.ShortSha(commitID))}
+			}
+		}
+	}
+	return nil
+}
+
+// isEmailDomainAllowed returns whether the domain of the email is one of the
+// allowed domains
+func (policy *pushPolicy) isEmailDomainAllowed(email string) bool {
+	i := strings.LastIndex(email, "@")
+	if i == -1 {
+		return false
+	}
+	domain := strings.ToLower(email[i+1:])
+	for _, allowed := range policy.allowedEmailDomains {
+		if domain == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckPushPolicy checks the commits pushed to the repository, which are not
+// in the repository yet, against the push policy of the instance. The objects
+// of the push are accessible with the environment while they are quarantined.
+// It returns ErrPushPolicyViolation for the first violation.
+func CheckPushPolicy(repo *Repository, newCommitID string, env []string) error {
+	return checkPushPolicy(repo, repo.RepoPath(), newCommitID, env)
+}
+
+// CheckWikiPushPolicy checks the commits pushed to the wiki of the repository
+// against the push policy of the instance like CheckPushPolicy.
+func CheckWikiPushPolicy(repo *Repository, newCommitID string, env []string) error {
+	return checkPushPolicy(repo, repo.WikiPath(), newCommitID, env)
+}
+
+func checkPushPolicy(repo *Repository, repoPath, newCommitID string, env []string) error {
+	policy, err := getPushPolicy()
+	if err != nil || policy == nil {
+		return err
+	}
+	if err = policy.checkFilePaths(repoPath, newCommitID, env); err != nil {
+		return err
+	}
+	if err = policy.checkFileSizes(repoPath, newCommitID, env); err != nil {
+		return err
+	}
+	return policy.checkCommits(repo, repoPath, newCommitID, env)
+}
@@ -0,0 +1,104 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// createDanglingCommit creates a commit on top of master which is not
+// referenced by the repository, like the commits of a push being received
+func createDanglingCommit(t *testing.T, repoPath, dir, name, content, committerEmail string) string {
+	writeObject := func(args []string, input string) string {
+		var stdout, stderr strings.Builder
+		assert.NoError(t, git.NewCommand(args...).RunInDirFullPipeline(repoPath, &stdout, &stderr, strings.NewReader(input)), stderr.String())
+		return strings.TrimSpace(stdout.String())
+	}
+	blobID := writeObject([]string{"hash-object", "-w", "--stdin"}, content)
+	dirTreeID := writeObject([]string{"mktree"}, "100644 blob "+blobID+"\t"+name+"\n")
+	treeID := writeObject([]string{"mktree"}, "040000 tree "+dirTreeID+"\t"+dir+"\n")
+
+	env := append(os.Environ(),
+		"GIT_AUTHOR_NAME=User Two", "GIT_AUTHOR_EMAIL="+committerEmail,
+		"GIT_COMMITTER_NAME=User Two", "GIT_COMMITTER_EMAIL="+committerEmail)
+	commitID, err := git.NewCommand("commit-tree", treeID, "-p", "master", "-m", "Add "+dir+"/"+name).RunInDirWithEnv(repoPath, env)
+	assert.NoError(t, err)
+	return strings.TrimSpace(commitID)
+}
+
+func TestCheckPushPolicy(t *testing.T) {
+	PrepareTestEnv(t)
+	defer func(policy struct {
+		MaxFileSize                  int64
+		ForbiddenFilePatterns        []string
+		RequireSignedCommits         bool
+		AllowedCommitterEmailDomains []string
+	}) {
+		setting.Repository.PushPolicy = policy
+	}(setting.Repository.PushPolicy)
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	commitID := createDanglingCommit(t, repo.RepoPath(), "config", ".env", "SECRET=1234\n", "user2@example.com")
+
+	setting.Repository.PushPolicy.MaxFileSize = -1
+	setting.Repository.PushPolicy.ForbiddenFilePatterns = nil
+	setting.Repository.PushPolicy.RequireSignedCommits = false
+	setting.Repository.PushPolicy.AllowedCommitterEmailDomains = nil
+	assert.NoError(t, CheckPushPolicy(repo, commitID, nil))
+
+	setting.Repository.PushPolicy.MaxFileSize = 8
+	err := CheckPushPolicy(repo, commitID, nil)
+	assert.True(t, IsErrPushPolicyViolation(err))
+	assert.Contains(t, err.Error(), "file config/.env is 12B, larger than the limit of 8B")
+	setting.Repository.PushPolicy.MaxFileSize = 12
+	assert.NoError(t, CheckPushPolicy(repo, commitID, nil))
+
+	setting.Repository.PushPolicy.ForbiddenFilePatterns = []string{"*.exe", ".env"}
+	err = CheckPushPolicy(repo, commitID, nil)
+	assert.True(t, IsErrPushPolicyViolation(err))
+	assert.Contains(t, err.Error(), "file config/.env matches the forbidden pattern .env")
+	setting.Repository.PushPolicy.ForbiddenFilePatterns = []string{"/.env"}
+	assert.NoError(t, CheckPushPolicy(repo, commitID, nil))
+
+	setting.Repository.PushPolicy.AllowedCommitterEmailDomains = []string{"example.org"}
+	err = CheckPushPolicy(repo, commitID, nil)
+	assert.True(t, IsErrPushPolicyViolation(err))
+	assert.Contains(t, err.Error(), "committer email user2@example.com")
+	setting.Repository.PushPolicy.AllowedCommitterEmailDomains = []string{"example.org", " Example.com"}
+	assert.NoError(t, CheckPushPolicy(repo, commitID, nil))
+
+	setting.Repository.PushPolicy.RequireSignedCommits = true
+	err = CheckPushPolicy(repo, commitID, nil)
+	assert.True(t, IsErrPushPolicyViolation(err))
+	assert.Contains(t, err.Error(), "is not signed with a verified signature")
+
+	// The commits already in the repository are not checked
+	assert.NoError(t, CheckPushPolicy(repo, "65f1bf27bc3bf70f64657658635e66094edbcb4d", nil))
+}
+
+func TestCheckWikiPushPolicy(t *testing.T) {
+	PrepareTestEnv(t)
+	defer func(patterns []string) {
+		setting.Repository.PushPolicy.ForbiddenFilePatterns = patterns
+	}(setting.Repository.PushPolicy.ForbiddenFilePatterns)
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	commitID := createDanglingCommit(t, repo.WikiPath(), "secrets", "id_rsa", "PRIVATE KEY\n", "user2@example.com")
+
+	setting.Repository.PushPolicy.ForbiddenFilePatterns = nil
+	assert.NoError(t, CheckWikiPushPolicy(repo, commitID, nil))
+
+	setting.Repository.PushPolicy.ForbiddenFilePatterns = []string{"id_rsa"}
+	err := CheckWikiPushPolicy(repo, commitID, nil)
+	assert.True(t, IsErrPushPolicyViolation(err))
+	assert.Contains(t, err.Error(), "file secrets/id_rsa matches the forbidden pattern id_rsa")
+}
@@ -0,0 +1,137 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// QuotaKind represents the kind of files a quota limits the size of
+type QuotaKind string
+
+// enumerates all the kinds of quotas
+const (
+	QuotaGit        QuotaKind = "git"
+	QuotaLFS        QuotaKind = "lfs"
+	QuotaAttachment QuotaKind = "attachment"
+)
+
+// QuotaUsage represents the disk space used by a user or an organization, the
+// repositories and LFS objects are counted for their owner while the
+// attachments are counted for their uploader.
+type QuotaUsage struct {
+	GitSize        int64
+	LFSSize        int64
+	AttachmentSize int64
+}
+
+func quotaLimit(limit, defaultLimit int64) int64 {
+	if limit <= -1 {
+		return defaultLimit
+	}
+	return limit
+}
+
+// GitSizeLimit returns the limit in bytes of the size of the repositories of
+// the user, -1 if it is unlimited.
+func (u *User) GitSizeLimit() int64 {
+	return quotaLimit(u.MaxGitSize, setting.Quota.DefaultGitSizeLimit)
+}
+
+// LFSSizeLimit returns the limit in bytes of the size of the LFS objects of
+// the repositories of the user, -1 if it is unlimited.
+func (u *User) LFSSizeLimit() int64 {
+	return quotaLimit(u.MaxLFSSize, setting.Quota.DefaultLFSSizeLimit)
+}
+
+// AttachmentSizeLimit returns the limit in bytes of the size of the
+// attachments uploaded by the user, -1 if it is unlimited.
+func (u *User) AttachmentSizeLimit() int64 {
+	return quotaLimit(u.MaxAttachmentSize, setting.Quota.DefaultAttachmentSizeLimit)
+}
+
+func (u *User) getGitSize(e Engine) (int64, error) {
+	return e.Where("owner_id = ?", u.ID).SumInt(new(Repository), "size")
+}
+
+func (u *User) getLFSSize(e Engine) (int64, error) {
+	return e.Join("INNER", "repository", "repository.id = lfs_meta_object.repository_id").
+		Where("repository.owner_id = ?", u.ID).
+		SumInt(new(LFSMetaObject), "lfs_meta_object.size")
+}
+
+func (u *User) getAttachmentSize(e Engine) (int64, error) {
+	return e.Where("uploader_id = ?", u.ID).SumInt(new(Attachment), "size")
+}
+
+// GetQuotaUsage returns the disk space used by the user
+func (u *User) GetQuotaUsage() (_ *QuotaUsage, err error) {
+	usage := new(QuotaUsage)
+	if usage.GitSize, err = u.getGitSize(x); err != nil {
+		return nil, err
+	}
+	if usage.LFSSize, err = u.getLFSSize(x); err != nil {
+		return nil, err
+	}
+	if usage.AttachmentSize, err = u.getAttachmentSize(x); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// checkQuota returns ErrQuotaExceeded if the usage and the extra size exceed
+// the limit, the usage is not computed if there is no limit.
+func (u *User) checkQuota(kind QuotaKind, limit int64, getSize func(Engine) (int64, error), extra int64) error {
+	if limit <= -1 {
+		return nil
+	}
+	size, err := getSize(x)
+	if err != nil {
+		return err
+	}
+	if size+extra > limit {
+		return ErrQuotaExceeded{Kind: kind, Limit: limit}
+	}
+	return nil
+}
+
+// CheckGitQuota returns ErrQuotaExceeded if the repositories of the user would
+// exceed the quota with extra bytes more.
+func (u *User) CheckGitQuota(extra int64) error {
+	return u.checkQuota(QuotaGit, u.GitSizeLimit(), u.getGitSize, extra)
+}
+
+// CheckLFSQuota returns ErrQuotaExceeded if the LFS objects of the
+// repositories of the user would exceed the quota with extra bytes more.
+func (u *User) CheckLFSQuota(extra int64) error {
+	return u.checkQuota(QuotaLFS, u.LFSSizeLimit(), u.getLFSSize, extra)
+}
+
+// CheckAttachmentQuota returns ErrQuotaExceeded if the attachments uploaded by
+// the user would exceed the quota with extra bytes more.
+func (u *User) CheckAttachmentQuota(extra int64) error {
+	return u.checkQuota(QuotaAttachment, u.AttachmentSizeLimit(), u.getAttachmentSize, extra)
+}
+
+// APIFormatQuota returns the quotas and the disk space used by the user in
+// the API format
+func (u *User) APIFormatQuota() (*api.Quota, error) {
+	usage, err := u.GetQuotaUsage()
+	if err != nil {
+		return nil, err
+	}
+	return &api.Quota{
+		GitSize:             usage.GitSize,
+		GitSizeLimit:        u.GitSizeLimit(),
+		MaxGitSize:          u.MaxGitSize,
+		LFSSize:             usage.LFSSize,
+		LFSSizeLimit:        u.LFSSizeLimit(),
+		MaxLFSSize:          u.MaxLFSSize,
+		AttachmentSize:      usage.AttachmentSize,
+		AttachmentSizeLimit: u.AttachmentSizeLimit(),
+		MaxAttachmentSize:   u.MaxAttachmentSize,
+	}, nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUser_CheckQuota(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	// unlimited by default
+	assert.EqualValues(t, -1, user.GitSizeLimit())
+	assert.NoError(t, user.CheckGitQuota(1<<30))
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1, OwnerID: user.ID}).(*Repository)
+	repo.Size = 1000
+	_, err := x.ID(repo.ID).Cols("size").Update(repo)
+	assert.NoError(t, err)
+	user.MaxGitSize = 1500
+	assert.NoError(t, user.CheckGitQuota(500))
+	err = user.CheckGitQuota(501)
+	assert.True(t, IsErrQuotaExceeded(err))
+	assert.Equal(t, ErrQuotaExceeded{Kind: QuotaGit, Limit: 1500}, err)
+
+	_, err = NewLFSMetaObject(&LFSMetaObject{Oid: "2e7d2c03a9507ae265ecf5b5356885a53393a2029d241394997265a1a25aefc6", Size: 200, RepositoryID: repo.ID})
+	assert.NoError(t, err)
+	assert.NoError(t, user.CheckLFSQuota(1<<30))
+	user.MaxLFSSize = 250
+	assert.NoError(t, user.CheckLFSQuota(50))
+	assert.True(t, IsErrQuotaExceeded(user.CheckLFSQuota(51)))
+
+	_, err = x.Insert(&Attachment{UUID: "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a20", UploaderID: user.ID, Size: 60})
+	assert.NoError(t, err)
+	defer func(limit int64) {
+		setting.Quota.DefaultAttachmentSizeLimit = limit
+	}(setting.Quota.DefaultAttachmentSizeLimit)
+	setting.Quota.DefaultAttachmentSizeLimit = 100
+	assert.EqualValues(t, 100, user.AttachmentSizeLimit())
+	assert.NoError(t, user.CheckAttachmentQuota(40))
+	assert.True(t, IsErrQuotaExceeded(user.CheckAttachmentQuota(41)))
+	user.MaxAttachmentSize = 0
+	assert.True(t, IsErrQuotaExceeded(user.CheckAttachmentQuota(1)))
+
+	usage, err := user.GetQuotaUsage()
+	assert.NoError(t, err)
+	assert.Equal(t, &QuotaUsage{GitSize: 1000, LFSSize: 200, AttachmentSize: 60}, usage)
+}
@@ -32,13 +32,14 @@ type Release struct {
 	Title            string
 	Sha1             string `xorm:"VARCHAR(40)"`
 	NumCommits       int64
-	NumCommitsBehind int64              `xorm:"-"`
-	Note             string             `xorm:"TEXT"`
-	IsDraft          bool               `xorm:"NOT NULL DEFAULT false"`
-	IsPrerelease     bool               `xorm:"NOT NULL DEFAULT false"`
-	IsTag            bool               `xorm:"NOT NULL DEFAULT false"`
-	Attachments      []*Attachment      `xorm:"-"`
-	CreatedUnix      timeutil.TimeStamp `xorm:"INDEX"`
+	NumCommitsBehind int64               `xorm:"-"`
+	Note             string              `xorm:"TEXT"`
+	IsDraft          bool                `xorm:"NOT NULL DEFAULT false"`
+	IsPrerelease     bool                `xorm:"NOT NULL DEFAULT false"`
+	IsTag            bool                `xorm:"NOT NULL DEFAULT false"`
+	Attachments      []*Attachment       `xorm:"-"`
+	Verification     *CommitVerification `xorm:"-"`
+	CreatedUnix      timeutil.TimeStamp  `xorm:"INDEX"`
 }
 
 func (r *Release) loadAttributes(e Engine) error {
@@ -112,10 +113,17 @@ func IsReleaseExist(repoID int64, tagName string) (bool, error) {
 	return x.Get(&Release{RepoID: repoID, LowerTagName: strings.ToLower(tagName)})
 }
 
-func createTag(gitRepo *git.Repository, rel *Release) error {
+func createTag(gitRepo *git.Repository, rel *Release, doerID int64) error {
 	// Only actual create when publish.
 	if !rel.IsDraft {
 		if !gitRepo.IsTagExist(rel.TagName) {
+			allowed, err := IsUserAllowedToControlTag(rel.RepoID, rel.TagName, doerID)
+			if err != nil {
+				return fmt.Errorf("IsUserAllowedToControlTag: %v", err)
+			} else if !allowed {
+				return ErrProtectedTagName{rel.TagName}
+			}
+
 			commit, err := gitRepo.GetCommit(rel.Target)
 			if err != nil {
 				return fmt.Errorf("GetCommit: %v", err)
@@ -182,7 +190,7 @@ func CreateRelease(gitRepo *git.Repository, rel *Release, attachmentUUIDs []stri
 		return ErrReleaseAlreadyExist{rel.TagName}
 	}
 
-	if err = createTag(gitRepo, rel); err != nil {
+	if err = createTag(gitRepo, rel, rel.PublisherID); err != nil {
 		return err
 	}
 	rel.LowerTagName = strings.ToLower(rel.TagName)
@@ -212,9 +220,49 @@ func CreateRelease(gitRepo *git.Repository, rel *Release, attachmentUUIDs []stri
 			} else {
 				go HookQueue.Add(rel.Repo.ID)
 			}
+
+			if err := rel.mailWatchers(x); err != nil {
+				log.Error("mailWatchers: %v", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// mailWatchers sends an email about the published release to the watchers of
+// the repository which want to be notified of releases.
+func (r *Release) mailWatchers(e Engine) error {
+	if !setting.Service.EnableNotifyMail {
+		return nil
+	}
+
+	watchers, err := getWatchers(e, r.RepoID, RepoWatchModeAll, RepoWatchModeReleases)
+	if err != nil {
+		return fmt.Errorf("getWatchers [repo_id: %d]: %v", r.RepoID, err)
+	}
+
+	tos := make([]string, 0, len(watchers))
+	for _, watcher := range watchers {
+		if watcher.UserID == r.PublisherID {
+			continue
+		}
+		r.Repo.Units = nil
+		if !r.Repo.checkUnitUser(e, watcher.UserID, false, UnitTypeReleases) {
+			continue
+		}
+
+		to, err := getUserByID(e, watcher.UserID)
+		if err != nil {
+			return fmt.Errorf("GetUserByID [%d]: %v", watcher.UserID, err)
+		}
+		if to.IsOrganization() || to.EmailNotifications() != EmailNotificationsEnabled {
+			continue
 		}
+		tos = append(tos, to.Email)
 	}
 
+	SendReleaseMail(r, tos)
 	return nil
 }
 
@@ -387,7 +435,7 @@ func SortReleases(rels []*Release) {
 
 // UpdateRelease updates information of a release.
 func UpdateRelease(doer *User, gitRepo *git.Repository, rel *Release, attachmentUUIDs []string) (err error) {
-	if err = createTag(gitRepo, rel); err != nil {
+	if err = createTag(gitRepo, rel, doer.ID); err != nil {
 		return err
 	}
 	rel.LowerTagName = strings.ToLower(rel.TagName)
@@ -432,6 +480,13 @@ func DeleteReleaseByID(id int64, doer *User, delTag bool) error {
 	}
 
 	if delTag {
+		allowed, err := IsUserAllowedToControlTag(rel.RepoID, rel.TagName, doer.ID)
+		if err != nil {
+			return fmt.Errorf("IsUserAllowedToControlTag: %v", err)
+		} else if !allowed {
+			return ErrProtectedTagName{rel.TagName}
+		}
+
 		_, stderr, err := process.GetManager().ExecDir(-1, repo.RepoPath(),
 			fmt.Sprintf("DeleteReleaseByID (git tag -d): %d", rel.ID),
 			git.GitExecutable, "tag", "-d", rel.TagName)
@@ -32,6 +32,7 @@ import (
 	"code.gitea.io/gitea/modules/options"
 	"code.gitea.io/gitea/modules/process"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/sync"
 	"code.gitea.io/gitea/modules/timeutil"
@@ -165,6 +166,8 @@ type Repository struct {
 
 	IsFork                          bool               `xorm:"INDEX NOT NULL DEFAULT false"`
 	ForkID                          int64              `xorm:"INDEX"`
+	IsTemplate                      bool               `xorm:"INDEX NOT NULL DEFAULT false"`
+	TemplateID                      int64              `xorm:"INDEX"`
 	BaseRepo                        *Repository        `xorm:"-"`
 	Size                            int64              `xorm:"NOT NULL DEFAULT 0"`
 	IndexerStatus                   *RepoIndexerStatus `xorm:"-"`
@@ -275,12 +278,34 @@ func (repo *Repository) innerAPIFormat(e Engine, mode AccessMode, isParent bool)
 		}
 	}
 	hasIssues := false
-	if _, err := repo.getUnit(e, UnitTypeIssues); err == nil {
+	var internalTracker *api.InternalTracker
+	var externalTracker *api.ExternalTracker
+	if unit, err := repo.getUnit(e, UnitTypeIssues); err == nil {
+		config := unit.IssuesConfig()
 		hasIssues = true
+		internalTracker = &api.InternalTracker{
+			EnableTimeTracker:                config.EnableTimetracker,
+			AllowOnlyContributorsToTrackTime: config.AllowOnlyContributorsToTrackTime,
+			EnableIssueDependencies:          config.EnableDependencies,
+		}
+	} else if unit, err := repo.getUnit(e, UnitTypeExternalTracker); err == nil {
+		config := unit.ExternalTrackerConfig()
+		hasIssues = true
+		externalTracker = &api.ExternalTracker{
+			ExternalTrackerURL:    config.ExternalTrackerURL,
+			ExternalTrackerFormat: config.ExternalTrackerFormat,
+			ExternalTrackerStyle:  config.ExternalTrackerStyle,
+		}
 	}
 	hasWiki := false
+	var externalWiki *api.ExternalWiki
 	if _, err := repo.getUnit(e, UnitTypeWiki); err == nil {
 		hasWiki = true
+	} else if unit, err := repo.getUnit(e, UnitTypeExternalWiki); err == nil {
+		hasWiki = true
+		externalWiki = &api.ExternalWiki{
+			ExternalWikiURL: unit.ExternalWikiConfig().ExternalWikiURL,
+		}
 	}
 	hasPullRequests := false
 	ignoreWhitespaceConflicts := false
@@ -288,6 +313,20 @@ func (repo *Repository) innerAPIFormat(e Engine, mode AccessMode, isParent bool)
 	allowRebase := false
 	allowRebaseMerge := false
 	allowSquash := false
+	allowFastForwardOnly := false
+	cancelAutoMergeOnPush := false
+	defaultMergeMessageTemplate := ""
+	defaultSquashMessageTemplate := ""
+	maxChangedFiles := 0
+	maxChangedLines := 0
+	blockOversizedMerge := false
+	commitMessageSubjectPattern := ""
+	commitMessageMaxLineLength := 0
+	commitMessageRequireIssueRef := false
+	reviewerTeamID := int64(0)
+	reviewerAssignmentStrategy := ""
+	reviewerCount := 0
+	reviewerAssignmentSkipDrafts := false
 	if unit, err := repo.getUnit(e, UnitTypePullRequests); err == nil {
 		config := unit.PullRequestsConfig()
 		hasPullRequests = true
@@ -296,43 +335,91 @@ func (repo *Repository) innerAPIFormat(e Engine, mode AccessMode, isParent bool)
 		allowRebase = config.AllowRebase
 		allowRebaseMerge = config.AllowRebaseMerge
 		allowSquash = config.AllowSquash
+		allowFastForwardOnly = config.AllowFastForwardOnly
+		cancelAutoMergeOnPush = config.CancelAutoMergeOnPush
+		defaultMergeMessageTemplate = config.DefaultMergeMessageTemplate
+		defaultSquashMessageTemplate = config.DefaultSquashMessageTemplate
+		maxChangedFiles = config.MaxChangedFiles
+		maxChangedLines = config.MaxChangedLines
+		blockOversizedMerge = config.BlockOversizedMerge
+		commitMessageSubjectPattern = config.CommitMessageSubjectPattern
+		commitMessageMaxLineLength = config.CommitMessageMaxLineLength
+		commitMessageRequireIssueRef = config.CommitMessageRequireIssueRef
+		reviewerTeamID = config.ReviewerTeamID
+		reviewerAssignmentStrategy = string(config.ReviewerAssignmentStrategy)
+		reviewerCount = config.ReviewerCount
+		reviewerAssignmentSkipDrafts = config.ReviewerAssignmentSkipDrafts
+	}
+
+	apiRepo := &api.Repository{
+		ID:                           repo.ID,
+		Owner:                        repo.Owner.APIFormat(),
+		Name:                         repo.Name,
+		FullName:                     repo.FullName(),
+		Description:                  repo.Description,
+		Private:                      repo.IsPrivate,
+		Empty:                        repo.IsEmpty,
+		Archived:                     repo.IsArchived,
+		Size:                         int(repo.Size / 1024),
+		Fork:                         repo.IsFork,
+		Parent:                       parent,
+		Mirror:                       repo.IsMirror,
+		HTMLURL:                      repo.HTMLURL(),
+		SSHURL:                       cloneLink.SSH,
+		CloneURL:                     cloneLink.HTTPS,
+		Website:                      repo.Website,
+		Stars:                        repo.NumStars,
+		Forks:                        repo.NumForks,
+		Watchers:                     repo.NumWatches,
+		OpenIssues:                   repo.NumOpenIssues,
+		DefaultBranch:                repo.DefaultBranch,
+		Created:                      repo.CreatedUnix.AsTime(),
+		Updated:                      repo.UpdatedUnix.AsTime(),
+		Permissions:                  permission,
+		HasIssues:                    hasIssues,
+		InternalTracker:              internalTracker,
+		ExternalTracker:              externalTracker,
+		HasWiki:                      hasWiki,
+		ExternalWiki:                 externalWiki,
+		HasPullRequests:              hasPullRequests,
+		IgnoreWhitespaceConflicts:    ignoreWhitespaceConflicts,
+		AllowMerge:                   allowMerge,
+		AllowRebase:                  allowRebase,
+		AllowRebaseMerge:             allowRebaseMerge,
+		AllowSquash:                  allowSquash,
+		AllowFastForwardOnly:         allowFastForwardOnly,
+		CancelAutoMergeOnPush:        cancelAutoMergeOnPush,
+		DefaultMergeMessageTemplate:  defaultMergeMessageTemplate,
+		DefaultSquashMessageTemplate: defaultSquashMessageTemplate,
+		MaxChangedFiles:              maxChangedFiles,
+		MaxChangedLines:              maxChangedLines,
+		BlockOversizedMerge:          blockOversizedMerge,
+		CommitMessageSubjectPattern:  commitMessageSubjectPattern,
+		CommitMessageMaxLineLength:   commitMessageMaxLineLength,
+		CommitMessageRequireIssueRef: commitMessageRequireIssueRef,
+		ReviewerTeamID:               reviewerTeamID,
+		ReviewerAssignmentStrategy:   reviewerAssignmentStrategy,
+		ReviewerCount:                reviewerCount,
+		ReviewerAssignmentSkipDrafts: reviewerAssignmentSkipDrafts,
+		AvatarURL:                    repo.avatarLink(e),
+	}
+	if repo.IsMirror {
+		if m, err := getMirrorByRepoID(e, repo.ID); err != nil {
+			if err != ErrMirrorNotExist {
+				log.Error("getMirrorByRepoID[%d]: %v", repo.ID, err)
+			}
+		} else {
+			apiRepo.MirrorInterval = m.Interval.String()
+			apiRepo.MirrorLastError = m.LastError
+			if m.UpdatedUnix > 0 {
+				mirrorUpdated := m.UpdatedUnix.AsTime()
+				apiRepo.MirrorUpdated = &mirrorUpdated
+			}
+		}
 	}
-
-	return &api.Repository{
-		ID:                        repo.ID,
-		Owner:                     repo.Owner.APIFormat(),
-		Name:                      repo.Name,
-		FullName:                  repo.FullName(),
-		Description:               repo.Description,
-		Private:                   repo.IsPrivate,
-		Empty:                     repo.IsEmpty,
-		Archived:                  repo.IsArchived,
-		Size:                      int(repo.Size / 1024),
-		Fork:                      repo.IsFork,
-		Parent:                    parent,
-		Mirror:                    repo.IsMirror,
-		HTMLURL:                   repo.HTMLURL(),
-		SSHURL:                    cloneLink.SSH,
-		CloneURL:                  cloneLink.HTTPS,
-		Website:                   repo.Website,
-		Stars:                     repo.NumStars,
-		Forks:                     repo.NumForks,
-		Watchers:                  repo.NumWatches,
-		OpenIssues:                repo.NumOpenIssues,
-		DefaultBranch:             repo.DefaultBranch,
-		Created:                   repo.CreatedUnix.AsTime(),
-		Updated:                   repo.UpdatedUnix.AsTime(),
-		Permissions:               permission,
-		HasIssues:                 hasIssues,
-		HasWiki:                   hasWiki,
-		HasPullRequests:           hasPullRequests,
-		IgnoreWhitespaceConflicts: ignoreWhitespaceConflicts,
-		AllowMerge:                allowMerge,
-		AllowRebase:               allowRebase,
-		AllowRebaseMerge:          allowRebaseMerge,
-		AllowSquash:               allowSquash,
-		AvatarURL:                 repo.avatarLink(e),
-	}
+	apiRepo.Template = repo.IsTemplate
+	apiRepo.TemplateID = repo.TemplateID
+	return apiRepo
 }
 
 func (repo *Repository) getUnits(e Engine) (err error) {
@@ -858,8 +945,8 @@ type MigrateRepoOptions struct {
 }
 
 /*
-	GitHub, GitLab, Gogs: *.wiki.git
-	BitBucket: *.git/wiki
+GitHub, GitLab, Gogs: *.wiki.git
+BitBucket: *.git/wiki
 */
 var commonWikiURLSuffixes = []string{".wiki.git", ".git/wiki"}
 
@@ -985,6 +1072,10 @@ func MigrateRepository(doer, u *User, opts MigrateRepoOptions) (*Repository, err
 	if err != nil && !repo.IsEmpty {
 		UpdateRepoIndexer(repo)
 	}
+	if err == nil && !repo.IsEmpty {
+		UpdateRepoLanguageStats(repo)
+		UpdateRepoCommitGraph(repo)
+	}
 
 	return repo, err
 }
@@ -1006,16 +1097,19 @@ func cleanUpMigrateGitConfig(configPath string) error {
 // createDelegateHooks creates all the hooks scripts for the repo
 func createDelegateHooks(repoPath string) (err error) {
 	var (
-		hookNames = []string{"pre-receive", "update", "post-receive"}
+		hookNames = []string{"pre-receive", "update", "post-receive", "proc-receive"}
 		hookTpls  = []string{
 			fmt.Sprintf("#!/usr/bin/env %s\ndata=$(cat)\nexitcodes=\"\"\nhookname=$(basename $0)\nGIT_DIR=${GIT_DIR:-$(dirname $0)}\n\nfor hook in ${GIT_DIR}/hooks/${hookname}.d/*; do\ntest -x \"${hook}\" || continue\necho \"${data}\" | \"${hook}\"\nexitcodes=\"${exitcodes} $?\"\ndone\n\nfor i in ${exitcodes}; do\n[ ${i} -eq 0 ] || exit ${i}\ndone\n", setting.ScriptType),
 			fmt.Sprintf("#!/usr/bin/env %s\nexitcodes=\"\"\nhookname=$(basename $0)\nGIT_DIR=${GIT_DIR:-$(dirname $0)}\n\nfor hook in ${GIT_DIR}/hooks/${hookname}.d/*; do\ntest -x \"${hook}\" || continue\n\"${hook}\" $1 $2 $3\nexitcodes=\"${exitcodes} $?\"\ndone\n\nfor i in ${exitcodes}; do\n[ ${i} -eq 0 ] || exit ${i}\ndone\n", setting.ScriptType),
 			fmt.Sprintf("#!/usr/bin/env %s\ndata=$(cat)\nexitcodes=\"\"\nhookname=$(basename $0)\nGIT_DIR=${GIT_DIR:-$(dirname $0)}\n\nfor hook in ${GIT_DIR}/hooks/${hookname}.d/*; do\ntest -x \"${hook}\" || continue\necho \"${data}\" | \"${hook}\"\nexitcodes=\"${exitcodes} $?\"\ndone\n\nfor i in ${exitcodes}; do\n[ ${i} -eq 0 ] || exit ${i}\ndone\n", setting.ScriptType),
+			// The proc-receive hook talks to Git through stdin and stdout and can not be delegated
+			fmt.Sprintf("#!/usr/bin/env %s\n\"%s\" hook --config='%s' proc-receive\n", setting.ScriptType, setting.AppPath, setting.CustomConf),
 		}
 		giteaHookTpls = []string{
 			fmt.Sprintf("#!/usr/bin/env %s\n\"%s\" hook --config='%s' pre-receive\n", setting.ScriptType, setting.AppPath, setting.CustomConf),
 			fmt.Sprintf("#!/usr/bin/env %s\n\"%s\" hook --config='%s' update $1 $2 $3\n", setting.ScriptType, setting.AppPath, setting.CustomConf),
 			fmt.Sprintf("#!/usr/bin/env %s\n\"%s\" hook --config='%s' post-receive\n", setting.ScriptType, setting.AppPath, setting.CustomConf),
+			"",
 		}
 	)
 
@@ -1025,6 +1119,13 @@ func createDelegateHooks(repoPath string) (err error) {
 		oldHookPath := filepath.Join(hookDir, hookName)
 		newHookPath := filepath.Join(hookDir, hookName+".d", "gitea")
 
+		if len(giteaHookTpls[i]) == 0 {
+			if err = ioutil.WriteFile(oldHookPath, []byte(hookTpls[i]), 0777); err != nil {
+				return fmt.Errorf("write hook file '%s': %v", oldHookPath, err)
+			}
+			continue
+		}
+
 		if err := os.MkdirAll(filepath.Join(hookDir, hookName+".d"), os.ModePerm); err != nil {
 			return fmt.Errorf("create hooks dir '%s': %v", filepath.Join(hookDir, hookName+".d"), err)
 		}
@@ -1069,7 +1170,7 @@ func CleanUpMigrateInfo(repo *Repository) (*Repository, error) {
 }
 
 // initRepoCommit temporarily changes with work directory.
-func initRepoCommit(tmpPath string, sig *git.Signature) (err error) {
+func initRepoCommit(tmpPath, branch string, sig *git.Signature) (err error) {
 	var stderr string
 	if _, stderr, err = process.GetManager().ExecDir(-1,
 		tmpPath, fmt.Sprintf("initRepoCommit (git add): %s", tmpPath),
@@ -1086,7 +1187,7 @@ func initRepoCommit(tmpPath string, sig *git.Signature) (err error) {
 
 	if _, stderr, err = process.GetManager().ExecDir(-1,
 		tmpPath, fmt.Sprintf("initRepoCommit (git push): %s", tmpPath),
-		git.GitExecutable, "push", "origin", "master"); err != nil {
+		git.GitExecutable, "push", "origin", "HEAD:"+git.BranchPrefix+branch); err != nil {
 		return fmt.Errorf("git push: %s", stderr)
 	}
 	return nil
@@ -1103,6 +1204,8 @@ type CreateRepoOptions struct {
 	IsPrivate   bool
 	IsMirror    bool
 	AutoInit    bool
+	// DefaultBranch is setting.Repository.DefaultBranch if empty
+	DefaultBranch string
 }
 
 func getRepoInitFile(tp, name string) ([]byte, error) {
@@ -1207,6 +1310,16 @@ func initRepository(e Engine, repoPath string, u *User, repo *Repository, opts C
 		return fmt.Errorf("createDelegateHooks: %v", err)
 	}
 
+	defaultBranch := opts.DefaultBranch
+	if len(defaultBranch) == 0 {
+		defaultBranch = setting.Repository.DefaultBranch
+	}
+	// The repository is re-fetched below, the one of the caller is updated too
+	repo.DefaultBranch = defaultBranch
+	if _, err = git.NewCommand("symbolic-ref", "HEAD", git.BranchPrefix+defaultBranch).RunInDir(repoPath); err != nil {
+		return fmt.Errorf("set HEAD to %s: %v", defaultBranch, err)
+	}
+
 	tmpDir := filepath.Join(os.TempDir(), "gitea-"+repo.Name+"-"+com.ToStr(time.Now().Nanosecond()))
 
 	// Initialize repository according to user's choice.
@@ -1223,7 +1336,7 @@ func initRepository(e Engine, repoPath string, u *User, repo *Repository, opts C
 		}
 
 		// Apply changes and commit.
-		if err = initRepoCommit(tmpDir, u.NewGitSig()); err != nil {
+		if err = initRepoCommit(tmpDir, defaultBranch, u.NewGitSig()); err != nil {
 			return fmt.Errorf("initRepoCommit: %v", err)
 		}
 	}
@@ -1238,7 +1351,7 @@ func initRepository(e Engine, repoPath string, u *User, repo *Repository, opts C
 		repo.IsEmpty = true
 	}
 
-	repo.DefaultBranch = "master"
+	repo.DefaultBranch = defaultBranch
 	if err = updateRepository(e, repo, false); err != nil {
 		return fmt.Errorf("updateRepository: %v", err)
 	}
@@ -1394,6 +1507,10 @@ func CreateRepository(doer, u *User, opts CreateRepoOptions) (_ *Repository, err
 			return nil, fmt.Errorf("initRepository: %v", err)
 		}
 
+		if err = initializeDefaultLabels(sess, repo); err != nil {
+			return nil, fmt.Errorf("initializeDefaultLabels: %v", err)
+		}
+
 		_, stderr, err := process.GetManager().ExecDir(-1,
 			repoPath, fmt.Sprintf("CreateRepository(git update-server-info): %s", repoPath),
 			git.GitExecutable, "update-server-info")
@@ -1483,6 +1600,11 @@ func TransferOwnership(doer *User, newOwnerName string, repo *Repository) error
 		return fmt.Errorf("update owner: %v", err)
 	}
 
+	// Milestones of the old owner can't be used anymore.
+	if err := removeRepoFromOrgMilestones(sess, repo.ID); err != nil {
+		return fmt.Errorf("removeRepoFromOrgMilestones: %v", err)
+	}
+
 	// Remove redundant collaborators.
 	collaborators, err := repo.getCollaborators(sess)
 	if err != nil {
@@ -1562,7 +1684,32 @@ func TransferOwnership(doer *User, newOwnerName string, repo *Repository) error
 		return fmt.Errorf("delete repo redirect: %v", err)
 	}
 
-	return sess.Commit()
+	// The repository is not pending any transfer anymore.
+	if _, err = sess.Delete(&RepoTransfer{RepoID: repo.ID}); err != nil {
+		return fmt.Errorf("delete repo transfer: %v", err)
+	}
+
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+
+	payload := &api.RepositoryPayload{
+		Action:     api.HookRepoTransferred,
+		Repository: repo.APIFormat(AccessModeOwner),
+		Changes: &api.ChangesPayload{
+			Owner: &api.ChangesFromPayload{From: owner.Name},
+		},
+		Sender: doer.APIFormat(),
+	}
+	if newOwner.IsOrganization() {
+		payload.Organization = newOwner.APIFormat()
+	}
+	if err = PrepareWebhooks(repo, HookEventRepository, payload); err != nil {
+		log.Error("PrepareWebhooks [repo_id: %d]: %v", repo.ID, err)
+	} else {
+		go HookQueue.Add(repo.ID)
+	}
+	return nil
 }
 
 // ChangeRepositoryName changes all corresponding setting from old repository name to new one.
@@ -1785,23 +1932,49 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		}
 	}
 
+	if _, err = sess.In("pull_id", builder.Select("id").From("pull_request").Where(builder.Eq{"base_repo_id": repoID})).
+		Delete(new(PullAutoMerge)); err != nil {
+		return fmt.Errorf("delete scheduled automatic merges: %v", err)
+	}
+	if _, err = sess.In("pull_id", builder.Select("id").From("pull_request").Where(builder.Eq{"base_repo_id": repoID})).
+		Delete(new(PullViewedFile)); err != nil {
+		return fmt.Errorf("delete viewed files: %v", err)
+	}
+	if _, err = sess.In("check_run_id", builder.Select("id").From("check_run").Where(builder.Eq{"repo_id": repoID})).
+		Delete(new(CheckRunAnnotation)); err != nil {
+		return fmt.Errorf("delete check run annotations: %v", err)
+	}
+
 	if err = deleteBeans(sess,
 		&Access{RepoID: repo.ID},
 		&Action{RepoID: repo.ID},
 		&Watch{RepoID: repoID},
 		&Star{RepoID: repoID},
 		&Mirror{RepoID: repoID},
+		&PushMirror{RepoID: repoID},
+		&MirrorSSHKey{RepoID: repoID},
+		&ProtectedTag{RepoID: repoID},
 		&Milestone{RepoID: repoID},
 		&Release{RepoID: repoID},
 		&Collaboration{RepoID: repoID},
 		&PullRequest{BaseRepoID: repoID},
+		&MergeQueueEntry{RepoID: repoID},
 		&RepoUnit{RepoID: repoID},
 		&RepoRedirect{RedirectRepoID: repoID},
 		&Webhook{RepoID: repoID},
 		&HookTask{RepoID: repoID},
 		&Notification{RepoID: repoID},
 		&CommitStatus{RepoID: repoID},
+		&CheckRun{RepoID: repoID},
 		&RepoIndexerStatus{RepoID: repoID},
+		&LanguageStat{RepoID: repoID},
+		&RepoTransfer{RepoID: repoID},
+		&RepoHousekeeping{RepoID: repoID},
+		&ContributorStatsStatus{RepoID: repoID},
+		&ContributorWeeklyStat{RepoID: repoID},
+		&IssueRedirect{RepoID: repoID},
+		&DeletedIssue{RepoID: repoID},
+		&Comment{RefRepoID: repoID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
@@ -1833,6 +2006,16 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		return err
 	}
 
+	if _, err = sess.In("issue_id", deleteCond).
+		Delete(&IssueContentHistory{}); err != nil {
+		return err
+	}
+
+	if _, err = sess.In("issue_id", deleteCond).
+		Delete(&IssueReminder{}); err != nil {
+		return err
+	}
+
 	attachmentPaths := make([]string, 0, 20)
 	attachments := make([]*Attachment, 0, len(attachmentPaths))
 	if err = sess.Join("INNER", "issue", "issue.id = attachment.issue_id").
@@ -1841,7 +2024,7 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		return err
 	}
 	for j := range attachments {
-		attachmentPaths = append(attachmentPaths, attachments[j].LocalPath())
+		attachmentPaths = append(attachmentPaths, attachments[j].RelativePath())
 	}
 
 	if _, err = sess.In("issue_id", deleteCond).
@@ -1849,10 +2032,19 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		return err
 	}
 
+	orgMilestoneIDs, err := getOrgMilestoneIDsOfRepo(sess, repoID)
+	if err != nil {
+		return err
+	}
+
 	if _, err = sess.Delete(&Issue{RepoID: repoID}); err != nil {
 		return err
 	}
 
+	if err = updateMilestoneCounters(sess, orgMilestoneIDs...); err != nil {
+		return err
+	}
+
 	if _, err = sess.Where("repo_id = ?", repoID).Delete(new(RepoUnit)); err != nil {
 		return err
 	}
@@ -1878,7 +2070,7 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 
 	// Remove attachment files.
 	for i := range attachmentPaths {
-		removeAllWithNotice(sess, "Delete attachment", attachmentPaths[i])
+		removeStorageWithNotice(sess, storage.Attachments, "Delete attachment", attachmentPaths[i])
 	}
 
 	// Remove LFS objects
@@ -1897,8 +2089,8 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 			continue
 		}
 
-		oidPath := filepath.Join(v.Oid[0:2], v.Oid[2:4], v.Oid[4:len(v.Oid)])
-		removeAllWithNotice(sess, "Delete orphaned LFS file", oidPath)
+		oidPath := path.Join(v.Oid[0:2], v.Oid[2:4], v.Oid[4:len(v.Oid)])
+		removeStorageWithNotice(sess, storage.LFS, "Delete orphaned LFS file", oidPath)
 	}
 
 	if _, err := sess.Delete(&LFSMetaObject{RepositoryID: repoID}); err != nil {
@@ -1934,11 +2126,9 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 	}
 
 	if len(repo.Avatar) > 0 {
-		avatarPath := repo.CustomAvatarPath()
-		if com.IsExist(avatarPath) {
-			if err := os.Remove(avatarPath); err != nil {
-				return fmt.Errorf("Failed to remove %s: %v", avatarPath, err)
-			}
+		avatarPath := repo.CustomAvatarRelativePath()
+		if err := storage.RepoAvatars.Delete(avatarPath); err != nil {
+			return fmt.Errorf("Failed to remove %s: %v", avatarPath, err)
 		}
 	}
 
@@ -1948,8 +2138,12 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 
 // GetRepositoryByOwnerAndName returns the repository by given ownername and reponame.
 func GetRepositoryByOwnerAndName(ownerName, repoName string) (*Repository, error) {
+	return getRepositoryByOwnerAndName(x, ownerName, repoName)
+}
+
+func getRepositoryByOwnerAndName(e Engine, ownerName, repoName string) (*Repository, error) {
 	var repo Repository
-	has, err := x.Select("repository.*").
+	has, err := e.Select("repository.*").
 		Join("INNER", "`user`", "`user`.id = repository.owner_id").
 		Where("repository.lower_name = ?", strings.ToLower(repoName)).
 		And("`user`.lower_name = ?", strings.ToLower(ownerName)).
@@ -2393,6 +2587,53 @@ func HasForkedRepo(ownerID, repoID int64) (*Repository, bool) {
 	return repo, has
 }
 
+// GetRepositoryInForkNetwork returns the repository of the owner which
+// belongs to the fork network of the repository, which is the repository
+// itself, a fork of it, its base repository or another fork of its base
+// repository.
+func GetRepositoryInForkNetwork(ownerID int64, repo *Repository) (*Repository, error) {
+	if repo.OwnerID == ownerID {
+		return repo, nil
+	}
+	if forkedRepo, has := HasForkedRepo(ownerID, repo.ID); has {
+		return forkedRepo, nil
+	}
+
+	if repo.IsFork {
+		if err := repo.GetBaseRepo(); err != nil {
+			if !IsErrRepoNotExist(err) {
+				return nil, err
+			}
+		} else if repo.BaseRepo.OwnerID == ownerID {
+			return repo.BaseRepo, nil
+		} else if forkedRepo, has := HasForkedRepo(ownerID, repo.BaseRepo.ID); has {
+			return forkedRepo, nil
+		}
+	}
+	return nil, ErrRepoNotExist{0, ownerID, "", ""}
+}
+
+// GetForkNetwork returns the repositories of the fork network of the
+// repository, which are its base repository and the forks of it.
+func (repo *Repository) GetForkNetwork() ([]*Repository, error) {
+	root := repo
+	if repo.IsFork {
+		if err := repo.GetBaseRepo(); err != nil {
+			if !IsErrRepoNotExist(err) {
+				return nil, err
+			}
+		} else {
+			root = repo.BaseRepo
+		}
+	}
+
+	forks, err := root.GetForks()
+	if err != nil {
+		return nil, err
+	}
+	return append([]*Repository{root}, forks...), nil
+}
+
 // ForkRepository forks a repository
 func ForkRepository(doer, u *User, oldRepo *Repository, name, desc string) (_ *Repository, err error) {
 	forkedRepo, err := oldRepo.GetUserFork(u.ID)
@@ -2524,13 +2765,20 @@ func (repo *Repository) GetUserFork(userID int64) (*Repository, error) {
 	return &forkedRepo, nil
 }
 
-// CustomAvatarPath returns repository custom avatar file path.
-func (repo *Repository) CustomAvatarPath() string {
+// CustomAvatarRelativePath returns the path of the repository custom avatar in
+// the repository avatars storage.
+func (repo *Repository) CustomAvatarRelativePath() string {
+	return repo.Avatar
+}
+
+// IsCustomAvatarExist returns true if the repository custom avatar is stored
+func (repo *Repository) IsCustomAvatarExist() bool {
 	// Avatar empty by default
 	if len(repo.Avatar) == 0 {
-		return ""
+		return false
 	}
-	return filepath.Join(setting.RepositoryAvatarUploadPath, repo.Avatar)
+	_, err := storage.RepoAvatars.Stat(repo.CustomAvatarRelativePath())
+	return err == nil
 }
 
 // generateRandomAvatar generates a random avatar for repository.
@@ -2544,18 +2792,13 @@ func (repo *Repository) generateRandomAvatar(e Engine) error {
 	}
 
 	repo.Avatar = idToString
-	if err = os.MkdirAll(filepath.Dir(repo.CustomAvatarPath()), os.ModePerm); err != nil {
-		return fmt.Errorf("MkdirAll: %v", err)
-	}
-	fw, err := os.Create(repo.CustomAvatarPath())
-	if err != nil {
-		return fmt.Errorf("Create: %v", err)
-	}
-	defer fw.Close()
-
-	if err = png.Encode(fw, img); err != nil {
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, img); err != nil {
 		return fmt.Errorf("Encode: %v", err)
 	}
+	if _, err = storage.RepoAvatars.Save(repo.CustomAvatarRelativePath(), &buf, int64(buf.Len())); err != nil {
+		return fmt.Errorf("Save: %v", err)
+	}
 	log.Info("New random avatar created for repository: %d", repo.ID)
 
 	if _, err := e.ID(repo.ID).Cols("avatar").NoAutoTime().Update(repo); err != nil {
@@ -2586,9 +2829,7 @@ func (repo *Repository) RelAvatarLink() string {
 }
 
 func (repo *Repository) relAvatarLink(e Engine) string {
-	// If no avatar - path is empty
-	avatarPath := repo.CustomAvatarPath()
-	if len(avatarPath) == 0 || !com.IsFile(avatarPath) {
+	if !repo.IsCustomAvatarExist() {
 		switch mode := setting.RepositoryAvatarFallback; mode {
 		case "image":
 			return setting.RepositoryAvatarFallbackImage
@@ -2630,7 +2871,7 @@ func (repo *Repository) UploadAvatar(data []byte) error {
 		return err
 	}
 
-	oldAvatarPath := repo.CustomAvatarPath()
+	oldAvatarPath := repo.CustomAvatarRelativePath()
 
 	// Users can upload the same image to other repo - prefix it with ID
 	// Then repo will be removed - only it avatar file will be removed
@@ -2639,22 +2880,16 @@ func (repo *Repository) UploadAvatar(data []byte) error {
 		return fmt.Errorf("UploadAvatar: Update repository avatar: %v", err)
 	}
 
-	if err := os.MkdirAll(setting.RepositoryAvatarUploadPath, os.ModePerm); err != nil {
-		return fmt.Errorf("UploadAvatar: Failed to create dir %s: %v", setting.RepositoryAvatarUploadPath, err)
-	}
-
-	fw, err := os.Create(repo.CustomAvatarPath())
-	if err != nil {
-		return fmt.Errorf("UploadAvatar: Create file: %v", err)
-	}
-	defer fw.Close()
-
-	if err = png.Encode(fw, *m); err != nil {
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, *m); err != nil {
 		return fmt.Errorf("UploadAvatar: Encode png: %v", err)
 	}
+	if _, err = storage.RepoAvatars.Save(repo.CustomAvatarRelativePath(), &buf, int64(buf.Len())); err != nil {
+		return fmt.Errorf("UploadAvatar: Save avatar: %v", err)
+	}
 
-	if len(oldAvatarPath) > 0 && oldAvatarPath != repo.CustomAvatarPath() {
-		if err := os.Remove(oldAvatarPath); err != nil {
+	if len(oldAvatarPath) > 0 && oldAvatarPath != repo.CustomAvatarRelativePath() {
+		if err := storage.RepoAvatars.Delete(oldAvatarPath); err != nil {
 			return fmt.Errorf("UploadAvatar: Failed to remove old repo avatar %s: %v", oldAvatarPath, err)
 		}
 	}
@@ -2670,7 +2905,7 @@ func (repo *Repository) DeleteAvatar() error {
 		return nil
 	}
 
-	avatarPath := repo.CustomAvatarPath()
+	avatarPath := repo.CustomAvatarRelativePath()
 	log.Trace("DeleteAvatar[%d]: %s", repo.ID, avatarPath)
 
 	sess := x.NewSession()
@@ -2684,13 +2919,8 @@ func (repo *Repository) DeleteAvatar() error {
 		return fmt.Errorf("DeleteAvatar: Update repository avatar: %v", err)
 	}
 
-	if _, err := os.Stat(avatarPath); err == nil {
-		if err := os.Remove(avatarPath); err != nil {
-			return fmt.Errorf("DeleteAvatar: Failed to remove %s: %v", avatarPath, err)
-		}
-	} else {
-		// // Schrodinger: file may or may not exist. See err for details.
-		log.Trace("DeleteAvatar[%d]: %v", err)
+	if err := storage.RepoAvatars.Delete(avatarPath); err != nil {
+		return fmt.Errorf("DeleteAvatar: Failed to remove %s: %v", avatarPath, err)
 	}
 	return sess.Commit()
 }
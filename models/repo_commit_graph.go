@@ -0,0 +1,60 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strconv"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/sync"
+)
+
+// commitGraphQueue contains the IDs of the repositories whose commit-graph
+// file has to be written
+var commitGraphQueue = sync.NewUniqueQueue(setting.Repository.PullRequestQueueLength)
+
+// UpdateRepoCommitGraph adds the repository to the queue of the ones whose
+// commit-graph file has to be written, which speeds up the walks of their
+// commits once they have new ones
+func UpdateRepoCommitGraph(repo *Repository) {
+	go commitGraphQueue.Add(repo.ID)
+}
+
+// InitCommitGraphs starts writing the commit-graph files of the repositories
+// added to the queue
+func InitCommitGraphs() {
+	go processCommitGraphQueue()
+}
+
+func processCommitGraphQueue() {
+	for repoID := range commitGraphQueue.Queue() {
+		log.Trace("processCommitGraphQueue[%s]: writing commit-graph", repoID)
+		commitGraphQueue.Remove(repoID)
+
+		id, err := strconv.ParseInt(repoID, 10, 64)
+		if err != nil {
+			log.Error("processCommitGraphQueue[%s]: %v", repoID, err)
+			continue
+		}
+		if err = writeCommitGraph(id); err != nil {
+			log.Error("writeCommitGraph[%d]: %v", id, err)
+		}
+	}
+}
+
+func writeCommitGraph(repoID int64) error {
+	repo, err := GetRepositoryByID(repoID)
+	if err != nil {
+		if IsErrRepoNotExist(err) {
+			return nil
+		}
+		return err
+	} else if repo.IsEmpty {
+		return nil
+	}
+	return git.WriteCommitGraph(repo.RepoPath())
+}
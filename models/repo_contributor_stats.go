@@ -0,0 +1,283 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/sync"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/go-xorm/xorm"
+)
+
+// contributorStatsPool makes sure the contributor statistics of a repository
+// are only updated once at a time
+var contributorStatsPool = sync.NewExclusivePool()
+
+// ContributorStatsStatus holds the last commit of the default branch of a
+// repository which is counted in the statistics of its contributors
+type ContributorStatsStatus struct {
+	ID        int64  `xorm:"pk autoincr"`
+	RepoID    int64  `xorm:"UNIQUE"`
+	CommitSha string `xorm:"VARCHAR(40)"`
+}
+
+// ContributorWeeklyStat holds the number of commits a contributor has authored
+// in the default branch of a repository during a week, and the number of lines
+// they have added and deleted. The weeks start on Sunday at midnight UTC.
+type ContributorWeeklyStat struct {
+	ID        int64              `xorm:"pk autoincr"`
+	RepoID    int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Email     string             `xorm:"UNIQUE(s) NOT NULL"`
+	Week      timeutil.TimeStamp `xorm:"UNIQUE(s) NOT NULL"`
+	Name      string
+	Commits   int64 `xorm:"NOT NULL DEFAULT 0"`
+	Additions int64 `xorm:"NOT NULL DEFAULT 0"`
+	Deletions int64 `xorm:"NOT NULL DEFAULT 0"`
+}
+
+// ContributorStats represents the statistics of a contributor of a repository
+type ContributorStats struct {
+	Name  string
+	Email string
+	// User is the user whose email is the one of the contributor, nil if
+	// there is none
+	User      *User
+	Commits   int64
+	Additions int64
+	Deletions int64
+	// Weeks are the weeks the contributor has authored commits, in
+	// chronological order
+	Weeks []*ContributorWeeklyStat
+}
+
+// weekDuration is the duration of a week, they are in UTC so they all last as
+// long
+const weekDuration = 7 * 24 * time.Hour
+
+// startOfWeek returns the start of the week of the time, Sunday at midnight UTC
+func startOfWeek(t time.Time) timeutil.TimeStamp {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return timeutil.TimeStamp(day.AddDate(0, 0, -int(day.Weekday())).Unix())
+}
+
+// UpdateContributorStats counts the commits of the default branch of the
+// repository which have been pushed since the last update in the statistics
+// of its contributors, they are computed again if the branch has been
+// force-pushed
+func (repo *Repository) UpdateContributorStats() error {
+	contributorStatsPool.CheckIn(fmt.Sprint(repo.ID))
+	defer contributorStatsPool.CheckOut(fmt.Sprint(repo.ID))
+
+	if repo.IsEmpty {
+		return nil
+	}
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	commitID, err := gitRepo.GetBranchCommitID(repo.DefaultBranch)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	status := &ContributorStatsStatus{RepoID: repo.ID}
+	if _, err = x.Get(status); err != nil {
+		return err
+	} else if status.CommitSha == commitID {
+		return nil
+	}
+
+	revisionRange := commitID
+	incremental := len(status.CommitSha) > 0 && gitRepo.IsAncestor(status.CommitSha, commitID)
+	if incremental {
+		revisionRange = status.CommitSha + ".." + commitID
+	}
+	commits, err := gitRepo.GetCommitsStats(revisionRange)
+	if err != nil {
+		return fmt.Errorf("GetCommitsStats: %v", err)
+	}
+
+	// The commits are in reverse chronological order, the most recent name of
+	// the contributors is kept
+	type weekKey struct {
+		email string
+		week  timeutil.TimeStamp
+	}
+	weeks := make(map[weekKey]*ContributorWeeklyStat)
+	keys := make([]weekKey, 0, len(commits))
+	for _, commit := range commits {
+		key := weekKey{
+			email: strings.ToLower(commit.AuthorEmail),
+			week:  startOfWeek(commit.AuthorTime),
+		}
+		stat, ok := weeks[key]
+		if !ok {
+			stat = &ContributorWeeklyStat{
+				RepoID: repo.ID,
+				Email:  key.email,
+				Week:   key.week,
+				Name:   commit.AuthorName,
+			}
+			weeks[key] = stat
+			keys = append(keys, key)
+		}
+		stat.Commits++
+		stat.Additions += commit.Additions
+		stat.Deletions += commit.Deletions
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if !incremental {
+		if _, err = sess.Delete(&ContributorWeeklyStat{RepoID: repo.ID}); err != nil {
+			return err
+		}
+	}
+	for _, key := range keys {
+		if err = addContributorWeeklyStat(sess, weeks[key]); err != nil {
+			return err
+		}
+	}
+
+	status.CommitSha = commitID
+	if status.ID == 0 {
+		_, err = sess.Insert(status)
+	} else {
+		_, err = sess.ID(status.ID).Cols("commit_sha").Update(status)
+	}
+	if err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// addContributorWeeklyStat adds the statistics to the ones of the same
+// contributor and week if they exist
+func addContributorWeeklyStat(sess *xorm.Session, stat *ContributorWeeklyStat) error {
+	// The email of the contributor may be empty, so the conditions are explicit
+	existing := new(ContributorWeeklyStat)
+	has, err := sess.Where("repo_id = ? AND email = ? AND week = ?", stat.RepoID, stat.Email, stat.Week).Get(existing)
+	if err != nil {
+		return err
+	} else if !has {
+		_, err = sess.Insert(stat)
+		return err
+	}
+
+	_, err = sess.ID(existing.ID).
+		SetExpr("commits", fmt.Sprintf("commits + %d", stat.Commits)).
+		SetExpr("additions", fmt.Sprintf("additions + %d", stat.Additions)).
+		SetExpr("deletions", fmt.Sprintf("deletions + %d", stat.Deletions)).
+		Cols("name").
+		Update(&ContributorWeeklyStat{Name: stat.Name})
+	return err
+}
+
+// GetContributorStats returns the statistics of the contributors of the
+// default branch of the repository ordered by decreasing number of commits,
+// they are updated first
+func (repo *Repository) GetContributorStats() ([]*ContributorStats, error) {
+	if err := repo.UpdateContributorStats(); err != nil {
+		return nil, fmt.Errorf("UpdateContributorStats: %v", err)
+	}
+
+	weeks := make([]*ContributorWeeklyStat, 0, 50)
+	if err := x.Where("repo_id = ?", repo.ID).Asc("week").Find(&weeks); err != nil {
+		return nil, err
+	}
+
+	contributors := make(map[string]*ContributorStats)
+	for _, week := range weeks {
+		contributor, ok := contributors[week.Email]
+		if !ok {
+			contributor = &ContributorStats{
+				Email: week.Email,
+			}
+			contributors[week.Email] = contributor
+		}
+		// The name of the most recent week is the most recent one
+		contributor.Name = week.Name
+		contributor.Commits += week.Commits
+		contributor.Additions += week.Additions
+		contributor.Deletions += week.Deletions
+		contributor.Weeks = append(contributor.Weeks, week)
+	}
+
+	stats := make([]*ContributorStats, 0, len(contributors))
+	for _, contributor := range contributors {
+		if len(contributor.Email) > 0 {
+			user, err := GetUserByEmail(contributor.Email)
+			if err != nil && !IsErrUserNotExist(err) {
+				return nil, err
+			}
+			contributor.User = user
+		}
+		stats = append(stats, contributor)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Commits != stats[j].Commits {
+			return stats[i].Commits > stats[j].Commits
+		}
+		return stats[i].Email < stats[j].Email
+	})
+	return stats, nil
+}
+
+// GetWeeklyStats returns the statistics of all the contributors of the default
+// branch of the repository summed by week, from the week of the first commit or
+// the given number of weeks ago if it is positive to the current week, in
+// chronological order. The weeks without commits are included.
+func (repo *Repository) GetWeeklyStats(weeks int) ([]*ContributorWeeklyStat, error) {
+	if err := repo.UpdateContributorStats(); err != nil {
+		return nil, fmt.Errorf("UpdateContributorStats: %v", err)
+	}
+
+	current := startOfWeek(time.Now())
+	sess := x.Table("contributor_weekly_stat").
+		Select("week, SUM(commits) AS commits, SUM(additions) AS additions, SUM(deletions) AS deletions").
+		Where("repo_id = ?", repo.ID)
+	var first timeutil.TimeStamp
+	if weeks > 0 {
+		first = current.AddDuration(-time.Duration(weeks-1) * weekDuration)
+		sess.And("week >= ?", first)
+	}
+	sums := make([]*ContributorWeeklyStat, 0, weeks)
+	if err := sess.GroupBy("week").Asc("week").Find(&sums); err != nil {
+		return nil, err
+	}
+	if weeks <= 0 {
+		if len(sums) == 0 {
+			return sums, nil
+		}
+		first = sums[0].Week
+	}
+
+	stats := make([]*ContributorWeeklyStat, 0, weeks)
+	for start := first; start <= current; start = start.AddDuration(weekDuration) {
+		stat := &ContributorWeeklyStat{RepoID: repo.ID, Week: start}
+		if len(sums) > 0 && sums[0].Week == start {
+			stat.Commits = sums[0].Commits
+			stat.Additions = sums[0].Additions
+			stat.Deletions = sums[0].Deletions
+			sums = sums[1:]
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetContributorStats(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	stats, err := repo.GetContributorStats()
+	assert.NoError(t, err)
+	if assert.Len(t, stats, 1) {
+		assert.Equal(t, "user1", stats[0].Name)
+		assert.Equal(t, "address1@example.com", stats[0].Email)
+		assert.EqualValues(t, 1, stats[0].Commits)
+		assert.EqualValues(t, 3, stats[0].Additions)
+		assert.EqualValues(t, 0, stats[0].Deletions)
+		if assert.Len(t, stats[0].Weeks, 1) {
+			// The commit has been authored on Sunday 19 March 2017
+			assert.EqualValues(t, 1489881600, stats[0].Weeks[0].Week)
+		}
+	}
+	AssertExistsAndLoadBean(t, &ContributorStatsStatus{RepoID: 1, CommitSha: "65f1bf27bc3bf70f64657658635e66094edbcb4d"})
+
+	// The statistics are not counted twice
+	stats, err = repo.GetContributorStats()
+	assert.NoError(t, err)
+	if assert.Len(t, stats, 1) {
+		assert.EqualValues(t, 1, stats[0].Commits)
+	}
+}
+
+func TestGetWeeklyStats(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	stats, err := repo.GetWeeklyStats(0)
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, stats) {
+		assert.EqualValues(t, 1489881600, stats[0].Week)
+		assert.EqualValues(t, 1, stats[0].Commits)
+		assert.EqualValues(t, 3, stats[0].Additions)
+		for i := 1; i < len(stats); i++ {
+			assert.EqualValues(t, 7*24*60*60, stats[i].Week-stats[i-1].Week)
+			assert.EqualValues(t, 0, stats[i].Commits)
+		}
+	}
+
+	stats, err = repo.GetWeeklyStats(52)
+	assert.NoError(t, err)
+	assert.Len(t, stats, 52)
+}
@@ -0,0 +1,61 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// RepoCreationDefaults the defaults applied to a new repository when they are
+// not chosen at its creation
+type RepoCreationDefaults struct {
+	DefaultBranch string
+	IsPrivate     bool
+	AutoInit      bool
+	Gitignores    string
+	License       string
+}
+
+// GetRepoCreationDefaults returns the defaults of the repositories created by
+// doer for owner, those of an organization taking precedence over the ones of
+// the instance
+func GetRepoCreationDefaults(doer, owner *User) *RepoCreationDefaults {
+	defaults := &RepoCreationDefaults{
+		DefaultBranch: setting.Repository.DefaultBranch,
+		IsPrivate:     visibilityIsPrivate(setting.Repository.DefaultPrivate, doer.LastRepoVisibility),
+		AutoInit:      setting.Repository.DefaultAutoInit,
+	}
+	if !owner.IsOrganization() {
+		return defaults
+	}
+
+	if len(owner.RepoDefaultBranch) > 0 {
+		defaults.DefaultBranch = owner.RepoDefaultBranch
+	}
+	if len(owner.RepoDefaultVisibility) > 0 {
+		defaults.IsPrivate = visibilityIsPrivate(owner.RepoDefaultVisibility, defaults.IsPrivate)
+	}
+	if owner.RepoDefaultAutoInit {
+		defaults.AutoInit = true
+		defaults.Gitignores = owner.RepoDefaultGitignores
+		defaults.License = owner.RepoDefaultLicense
+	}
+	return defaults
+}
+
+// visibilityIsPrivate returns whether a new repository is private according to
+// the default visibility, last being the visibility of the previous one
+func visibilityIsPrivate(visibility string, last bool) bool {
+	switch strings.ToLower(visibility) {
+	case setting.RepoCreatingPrivate:
+		return true
+	case setting.RepoCreatingPublic:
+		return false
+	default:
+		return last
+	}
+}
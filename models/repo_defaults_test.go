@@ -0,0 +1,59 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRepoCreationDefaults(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	org := AssertExistsAndLoadBean(t, &User{ID: 3}).(*User)
+
+	defer func() {
+		setting.Repository.DefaultBranch = "master"
+		setting.Repository.DefaultPrivate = setting.RepoCreatingLastUserVisibility
+		setting.Repository.DefaultAutoInit = false
+	}()
+	setting.Repository.DefaultBranch = "main"
+	setting.Repository.DefaultPrivate = setting.RepoCreatingPrivate
+	setting.Repository.DefaultAutoInit = false
+
+	assert.Equal(t, &RepoCreationDefaults{
+		DefaultBranch: "main",
+		IsPrivate:     true,
+	}, GetRepoCreationDefaults(user, user))
+	assert.Equal(t, &RepoCreationDefaults{
+		DefaultBranch: "main",
+		IsPrivate:     true,
+	}, GetRepoCreationDefaults(user, org))
+
+	// the settings of an organization take precedence
+	org.RepoDefaultBranch = "develop"
+	org.RepoDefaultVisibility = setting.RepoCreatingPublic
+	org.RepoDefaultAutoInit = true
+	org.RepoDefaultGitignores = "Go"
+	org.RepoDefaultLicense = "MIT License"
+	assert.Equal(t, &RepoCreationDefaults{
+		DefaultBranch: "develop",
+		IsPrivate:     false,
+		AutoInit:      true,
+		Gitignores:    "Go",
+		License:       "MIT License",
+	}, GetRepoCreationDefaults(user, org))
+
+	// but not for the repositories of users
+	user.RepoDefaultBranch = "develop"
+	assert.Equal(t, "main", GetRepoCreationDefaults(user, user).DefaultBranch)
+
+	setting.Repository.DefaultPrivate = setting.RepoCreatingLastUserVisibility
+	user.LastRepoVisibility = true
+	assert.True(t, GetRepoCreationDefaults(user, user).IsPrivate)
+}
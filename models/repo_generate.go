@@ -0,0 +1,354 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/unknwon/com"
+)
+
+// TemplateFile is the path of the file listing the files of a template
+// repository whose variables are expanded in the generated repositories. The
+// file itself is not copied to the generated repositories.
+const TemplateFile = ".gitea/template"
+
+// GenerateRepoOptions contains the options to generate a repository from a
+// template repository
+type GenerateRepoOptions struct {
+	Name        string
+	Description string
+	IsPrivate   bool
+	GitContent  bool
+	Topics      bool
+	Labels      bool
+	Webhooks    bool
+}
+
+// IsValid returns true if at least one item of the template is copied
+func (opts GenerateRepoOptions) IsValid() bool {
+	return opts.GitContent || opts.Topics || opts.Labels || opts.Webhooks
+}
+
+// templateVariablePattern matches the ${VARIABLE} placeholders, the variables
+// without braces are left alone since they are common in shell scripts.
+var templateVariablePattern = regexp.MustCompile(`\$\{([A-Z_]+)\}`)
+
+// templateVariables returns the values of the variables which are expanded in
+// the files of a generated repository.
+func templateVariables(templateRepo, repo *Repository) map[string]string {
+	cloneLink := repo.CloneLink()
+	return map[string]string{
+		"REPO_NAME":        repo.Name,
+		"REPO_DESCRIPTION": repo.Description,
+		"REPO_OWNER":       repo.MustOwnerName(),
+		"REPO_LINK":        repo.HTMLURL(),
+		"REPO_HTTPS_URL":   cloneLink.HTTPS,
+		"REPO_SSH_URL":     cloneLink.SSH,
+		"TEMPLATE_NAME":    templateRepo.Name,
+		"TEMPLATE_OWNER":   templateRepo.MustOwnerName(),
+		"TEMPLATE_LINK":    templateRepo.HTMLURL(),
+	}
+}
+
+// expandTemplateVariables replaces the known ${VARIABLE} placeholders of the
+// content, the unknown ones are kept as they are.
+func expandTemplateVariables(content []byte, vars map[string]string) []byte {
+	return templateVariablePattern.ReplaceAllFunc(content, func(match []byte) []byte {
+		if value, ok := vars[string(match[2:len(match)-1])]; ok {
+			return []byte(value)
+		}
+		return match
+	})
+}
+
+// parseTemplateFile parses the gitignore style patterns of the template file,
+// one pattern per line. Empty lines and lines starting with # are ignored.
+func parseTemplateFile(content []byte) ([]*regexp.Regexp, error) {
+	var rules []*regexp.Regexp
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		re, err := codeOwnerPatternToRegexp(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %v", line, err)
+		}
+		rules = append(rules, re)
+	}
+	return rules, scanner.Err()
+}
+
+// expandTemplateFiles expands the variables of the files of the working
+// directory matched by the patterns of the template file and removes the
+// template file.
+func expandTemplateFiles(tmpDir string, vars map[string]string) error {
+	templateFilePath := filepath.Join(tmpDir, filepath.FromSlash(TemplateFile))
+	content, err := ioutil.ReadFile(templateFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err = os.Remove(templateFilePath); err != nil {
+		return err
+	}
+	rules, err := parseTemplateFile(content)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+
+	return filepath.Walk(tmpDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(tmpDir, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		for _, re := range rules {
+			if !re.MatchString(relPath) {
+				continue
+			}
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			return ioutil.WriteFile(path, expandTemplateVariables(data, vars), info.Mode())
+		}
+		return nil
+	})
+}
+
+// generateRepoContent commits the files of the default branch of the template
+// repository, with the variables of the template files expanded, as the
+// initial commit of the generated repository.
+func generateRepoContent(doer *User, templateRepo, repo *Repository) error {
+	tmpDir := filepath.Join(os.TempDir(), "gitea-"+repo.Name+"-"+com.ToStr(time.Now().Nanosecond()))
+	defer os.RemoveAll(tmpDir)
+
+	templateRepoPath := templateRepo.RepoPath()
+	if err := git.Clone(templateRepoPath, tmpDir, git.CloneRepoOptions{
+		Branch: templateRepo.DefaultBranch,
+	}); err != nil {
+		return fmt.Errorf("git clone: %v", err)
+	}
+	if err := os.RemoveAll(filepath.Join(tmpDir, ".git")); err != nil {
+		return fmt.Errorf("remove .git: %v", err)
+	}
+	if err := expandTemplateFiles(tmpDir, templateVariables(templateRepo, repo)); err != nil {
+		return fmt.Errorf("expandTemplateFiles: %v", err)
+	}
+
+	repoPath := repo.RepoPath()
+	if _, stderr, err := process.GetManager().ExecDir(-1,
+		tmpDir, fmt.Sprintf("generateRepoContent (git init): %s", repoPath),
+		git.GitExecutable, "init"); err != nil {
+		return fmt.Errorf("git init: %s", stderr)
+	}
+	if _, stderr, err := process.GetManager().ExecDir(-1,
+		tmpDir, fmt.Sprintf("generateRepoContent (git remote add): %s", repoPath),
+		git.GitExecutable, "remote", "add", "origin", repoPath); err != nil {
+		return fmt.Errorf("git remote add: %s", stderr)
+	}
+	if err := git.AddChanges(tmpDir, true); err != nil {
+		return fmt.Errorf("git add: %v", err)
+	}
+	sig := doer.NewGitSig()
+	if err := git.CommitChanges(tmpDir, git.CommitChangesOptions{
+		Committer: sig,
+		Author:    sig,
+		Message:   "Initial commit",
+	}); err != nil {
+		return fmt.Errorf("git commit: %v", err)
+	}
+	if _, stderr, err := process.GetManager().ExecDir(-1,
+		tmpDir, fmt.Sprintf("generateRepoContent (git push): %s", repoPath),
+		git.GitExecutable, "push", "origin", "HEAD:"+git.BranchPrefix+templateRepo.DefaultBranch); err != nil {
+		return fmt.Errorf("git push: %s", stderr)
+	}
+	return nil
+}
+
+func copyTemplateLabels(e Engine, templateRepo, repo *Repository) error {
+	labels := make([]*Label, 0, 10)
+	if err := e.Where("repo_id = ?", templateRepo.ID).Asc("id").Find(&labels); err != nil {
+		return err
+	}
+	for _, label := range labels {
+		label.ID = 0
+		label.RepoID = repo.ID
+		label.NumIssues = 0
+		label.NumClosedIssues = 0
+		if err := newLabel(e, label); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyTemplateWebhooks(e Engine, templateRepo, repo *Repository) error {
+	webhooks := make([]*Webhook, 0, 5)
+	if err := e.Find(&webhooks, &Webhook{RepoID: templateRepo.ID}); err != nil {
+		return err
+	}
+	for _, w := range webhooks {
+		w.ID = 0
+		w.RepoID = repo.ID
+		if err := createWebhook(e, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GenerateRepository creates a repository for the user/organization from a
+// template repository, the items of the template chosen by the options are
+// copied to the new repository.
+func GenerateRepository(doer, u *User, templateRepo *Repository, opts GenerateRepoOptions) (_ *Repository, err error) {
+	if !doer.IsAdmin && !u.CanCreateRepo() {
+		return nil, ErrReachLimitOfRepo{u.MaxRepoCreation}
+	}
+	if err = templateRepo.GetOwner(); err != nil {
+		return nil, fmt.Errorf("GetOwner: %v", err)
+	}
+
+	repo := &Repository{
+		OwnerID:                         u.ID,
+		Owner:                           u,
+		Name:                            opts.Name,
+		LowerName:                       strings.ToLower(opts.Name),
+		Description:                     opts.Description,
+		IsPrivate:                       opts.IsPrivate,
+		IsEmpty:                         !opts.GitContent || templateRepo.IsEmpty,
+		IsFsckEnabled:                   true,
+		TemplateID:                      templateRepo.ID,
+		CloseIssuesViaCommitInAnyBranch: setting.Repository.DefaultCloseIssuesViaCommitsInAnyBranch,
+	}
+	if !repo.IsEmpty {
+		repo.DefaultBranch = templateRepo.DefaultBranch
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	if err = createRepository(sess, doer, u, repo); err != nil {
+		return nil, err
+	}
+
+	repoPath := RepoPath(u.Name, repo.Name)
+	if com.IsExist(repoPath) {
+		return nil, fmt.Errorf("GenerateRepository: path already exists: %s", repoPath)
+	}
+	if err = git.InitRepository(repoPath, true); err != nil {
+		return nil, fmt.Errorf("InitRepository: %v", err)
+	}
+
+	// The hooks are created after pushing the initial commit, there is no
+	// pusher to run them for.
+	if !repo.IsEmpty {
+		if err = generateRepoContent(doer, templateRepo, repo); err != nil {
+			if err2 := os.RemoveAll(repoPath); err2 != nil {
+				log.Error("generateRepoContent: %v", err)
+				return nil, fmt.Errorf(
+					"delete repo directory %s/%s failed(2): %v", u.Name, repo.Name, err2)
+			}
+			return nil, fmt.Errorf("generateRepoContent: %v", err)
+		}
+	}
+	if err = createDelegateHooks(repoPath); err != nil {
+		return nil, fmt.Errorf("createDelegateHooks: %v", err)
+	}
+
+	if opts.Labels {
+		err = copyTemplateLabels(sess, templateRepo, repo)
+	} else {
+		err = initializeDefaultLabels(sess, repo)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("copy labels: %v", err)
+	}
+	if opts.Webhooks {
+		if err = copyTemplateWebhooks(sess, templateRepo, repo); err != nil {
+			return nil, fmt.Errorf("copyTemplateWebhooks: %v", err)
+		}
+	}
+
+	_, stderr, err := process.GetManager().ExecDir(-1,
+		repoPath, fmt.Sprintf("GenerateRepository(git update-server-info): %s", repoPath),
+		git.GitExecutable, "update-server-info")
+	if err != nil {
+		return nil, fmt.Errorf("git update-server-info: %s", stderr)
+	}
+
+	if err = sess.Commit(); err != nil {
+		return nil, err
+	}
+
+	if opts.Topics && len(templateRepo.Topics) > 0 {
+		if err = SaveTopics(repo.ID, templateRepo.Topics...); err != nil {
+			return repo, fmt.Errorf("SaveTopics: %v", err)
+		}
+		repo.Topics = templateRepo.Topics
+	}
+
+	if err = repo.UpdateSize(); err != nil {
+		log.Error("Failed to update size for repository: %v", err)
+	}
+
+	// Add to hook queue for created repo after session commit.
+	if u.IsOrganization() {
+		go HookQueue.Add(repo.ID)
+	}
+
+	return repo, nil
+}
+
+// GetTemplateRepo returns the template repository the repository has been
+// generated from, nil if it has not been generated or the template has been
+// deleted.
+func (repo *Repository) GetTemplateRepo() (*Repository, error) {
+	if repo.TemplateID == 0 {
+		return nil, nil
+	}
+	templateRepo, err := GetRepositoryByID(repo.TemplateID)
+	if err != nil {
+		if IsErrRepoNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return templateRepo, nil
+}
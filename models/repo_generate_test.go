@@ -0,0 +1,73 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandTemplateVariables(t *testing.T) {
+	vars := map[string]string{"REPO_NAME": "generated", "REPO_OWNER": "user2"}
+	assert.Equal(t, "# generated\nuser2/generated $HOME ${UNKNOWN}",
+		string(expandTemplateVariables([]byte("# ${REPO_NAME}\n${REPO_OWNER}/${REPO_NAME} $HOME ${UNKNOWN}"), vars)))
+}
+
+func TestParseTemplateFile(t *testing.T) {
+	rules, err := parseTemplateFile([]byte("# expanded files\n\nREADME.md\ndocs/**\n"))
+	assert.NoError(t, err)
+	assert.Len(t, rules, 2)
+	assert.True(t, rules[0].MatchString("README.md"))
+	assert.True(t, rules[1].MatchString("docs/index.md"))
+	assert.False(t, rules[1].MatchString("main.go"))
+}
+
+func TestGenerateRepository(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	templateRepo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	templateRepo.IsTemplate = true
+	templateRepo.Topics = []string{"golang"}
+
+	repo, err := GenerateRepository(doer, doer, templateRepo, GenerateRepoOptions{
+		Name:       "generated",
+		GitContent: true,
+		Topics:     true,
+		Labels:     true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, templateRepo.ID, repo.TemplateID)
+	assert.False(t, repo.IsEmpty)
+	assert.Equal(t, "master", repo.DefaultBranch)
+
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	assert.NoError(t, err)
+	commit, err := gitRepo.GetBranchCommit("master")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, commit.ParentCount())
+	_, err = commit.GetBlobByPath("README.md")
+	assert.NoError(t, err)
+
+	templateLabels, err := GetLabelsByRepoID(templateRepo.ID, "")
+	assert.NoError(t, err)
+	labels, err := GetLabelsByRepoID(repo.ID, "")
+	assert.NoError(t, err)
+	assert.Len(t, labels, len(templateLabels))
+	AssertNotExistsBean(t, &Webhook{RepoID: repo.ID})
+
+	generated, err := GetRepositoryByID(repo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"golang"}, generated.Topics)
+
+	_, err = GenerateRepository(doer, doer, templateRepo, GenerateRepoOptions{
+		Name:     "generated",
+		Webhooks: true,
+	})
+	assert.True(t, IsErrRepoAlreadyExist(err))
+}
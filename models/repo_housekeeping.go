@@ -0,0 +1,195 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/sync"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/unknwon/com"
+)
+
+// repoHousekeepingTable holds the repositories whose housekeeping is running
+var repoHousekeepingTable = sync.NewStatusTable()
+
+// RepoHousekeeping represents the last housekeeping of a repository, the
+// garbage collection of the objects of the repository and of its wiki
+type RepoHousekeeping struct {
+	ID        int64 `xorm:"pk autoincr"`
+	RepoID    int64 `xorm:"UNIQUE"`
+	IsSuccess bool
+	Output    string `xorm:"TEXT"`
+	// Duration is the duration of the housekeeping in milliseconds
+	Duration    int64
+	StartedUnix timeutil.TimeStamp `xorm:"INDEX"`
+}
+
+// APIFormat converts the housekeeping to its API format
+func (h *RepoHousekeeping) APIFormat() *api.RepoHousekeeping {
+	return &api.RepoHousekeeping{
+		IsRunning: IsRepoHousekeepingRunning(h.RepoID),
+		Success:   h.IsSuccess,
+		Output:    h.Output,
+		Duration:  h.Duration,
+		Started:   h.StartedUnix.AsTime(),
+	}
+}
+
+// IsRepoHousekeepingRunning returns whether the housekeeping of the repository is running
+func IsRepoHousekeepingRunning(repoID int64) bool {
+	return repoHousekeepingTable.IsRunning(com.ToStr(repoID))
+}
+
+// GetRepoHousekeeping returns the last housekeeping of the repository
+func GetRepoHousekeeping(repoID int64) (*RepoHousekeeping, error) {
+	h := &RepoHousekeeping{RepoID: repoID}
+	has, err := x.Get(h)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrRepoHousekeepingNotExist{RepoID: repoID}
+	}
+	return h, nil
+}
+
+// GetHousekeeping returns the last housekeeping of the repository, nil if it
+// has never run
+func (repo *Repository) GetHousekeeping() *RepoHousekeeping {
+	h, err := GetRepoHousekeeping(repo.ID)
+	if err != nil {
+		if !IsErrRepoHousekeepingNotExist(err) {
+			log.Error("GetRepoHousekeeping[%d]: %v", repo.ID, err)
+		}
+		return nil
+	}
+	return h
+}
+
+// Housekeeping garbage collects the objects of the repository and of its wiki
+// with git gc, the result is recorded whether it succeeds or not. It fails
+// with ErrRepoHousekeepingInProgress if the housekeeping of the repository is
+// already running.
+func (repo *Repository) Housekeeping() (*RepoHousekeeping, error) {
+	if !repoHousekeepingTable.StartIfNotRunning(com.ToStr(repo.ID)) {
+		return nil, ErrRepoHousekeepingInProgress{RepoID: repo.ID}
+	}
+	defer repoHousekeepingTable.Stop(com.ToStr(repo.ID))
+
+	// The local copies of the repository are not changed meanwhile
+	repoWorkingPool.CheckIn(com.ToStr(repo.ID))
+	defer repoWorkingPool.CheckOut(com.ToStr(repo.ID))
+
+	h := &RepoHousekeeping{
+		RepoID:      repo.ID,
+		IsSuccess:   true,
+		StartedUnix: timeutil.TimeStampNow(),
+	}
+	start := time.Now()
+	repoPaths := []string{repo.RepoPath()}
+	if repo.HasWiki() {
+		repoPaths = append(repoPaths, repo.WikiPath())
+	}
+	var output []string
+	for _, repoPath := range repoPaths {
+		log.Trace("Running housekeeping on repository %s", repoPath)
+		stdout, stderr, err := process.GetManager().ExecDir(time.Duration(setting.Git.Timeout.GC)*time.Second, repoPath,
+			"Repository housekeeping", git.GitExecutable, append([]string{"gc"}, setting.Git.GCArgs...)...)
+		output = append(output, strings.TrimSpace(stdout+stderr))
+		if err != nil {
+			h.IsSuccess = false
+			output = append(output, err.Error())
+			log.Warn("Failed to run housekeeping on repository %s: %v - %s", repoPath, err, stderr)
+			break
+		}
+	}
+	h.Output = strings.TrimSpace(strings.Join(output, "\n"))
+	h.Duration = int64(time.Since(start) / time.Millisecond)
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+	if _, err := sess.Delete(&RepoHousekeeping{RepoID: repo.ID}); err != nil {
+		return nil, err
+	}
+	if _, err := sess.Insert(h); err != nil {
+		return nil, err
+	}
+	if h.IsSuccess {
+		// The size of the repository changes but it is not considered as
+		// updated, which would make it due for housekeeping again
+		repoInfoSize, err := git.GetRepoSize(repo.RepoPath())
+		if err != nil {
+			return nil, err
+		}
+		repo.Size = repoInfoSize.Size + repoInfoSize.SizePack
+		if _, err = sess.ID(repo.ID).Cols("size").NoAutoTime().Update(repo); err != nil {
+			return nil, err
+		}
+	}
+	return h, sess.Commit()
+}
+
+// StartHousekeeping runs the housekeeping of the repository in the background,
+// it fails with ErrRepoHousekeepingInProgress if it is already running
+func (repo *Repository) StartHousekeeping() error {
+	if IsRepoHousekeepingRunning(repo.ID) {
+		return ErrRepoHousekeepingInProgress{RepoID: repo.ID}
+	}
+	go func() {
+		if _, err := repo.Housekeeping(); err != nil && !IsErrRepoHousekeepingInProgress(err) {
+			log.Error("Housekeeping[%d]: %v", repo.ID, err)
+		}
+	}()
+	return nil
+}
+
+// RepoHousekeepings runs the housekeeping of the repositories which have been
+// updated since their last housekeeping
+func RepoHousekeepings() {
+	log.Trace("Doing: RepoHousekeepings")
+
+	if err := x.
+		Where("id>0").BufferSize(setting.Database.IterateBufferSize).
+		Iterate(new(Repository),
+			func(idx int, bean interface{}) error {
+				repo := bean.(*Repository)
+				if repo.IsEmpty {
+					return nil
+				}
+				if h := repo.GetHousekeeping(); h != nil && h.StartedUnix >= repo.UpdatedUnix {
+					return nil
+				}
+				if err := repo.GetOwner(); err != nil {
+					log.Error("GetOwner[%d]: %v", repo.ID, err)
+					return nil
+				}
+				h, err := repo.Housekeeping()
+				if err != nil {
+					if !IsErrRepoHousekeepingInProgress(err) {
+						log.Error("Housekeeping[%d]: %v", repo.ID, err)
+					}
+					return nil
+				}
+				if !h.IsSuccess {
+					if err = CreateRepositoryNotice("Failed to run housekeeping on repository (" + repo.FullName() + "): " + h.Output); err != nil {
+						log.Error("CreateRepositoryNotice: %v", err)
+					}
+				}
+				return nil
+			}); err != nil {
+		log.Error("RepoHousekeepings: %v", err)
+	}
+	log.Trace("Finished: RepoHousekeepings")
+}
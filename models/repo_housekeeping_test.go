@@ -0,0 +1,39 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_Housekeeping(t *testing.T) {
+	PrepareTestEnv(t)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	_, err := GetRepoHousekeeping(repo.ID)
+	assert.True(t, IsErrRepoHousekeepingNotExist(err))
+	assert.Nil(t, repo.GetHousekeeping())
+
+	h, err := repo.Housekeeping()
+	assert.NoError(t, err)
+	assert.True(t, h.IsSuccess)
+	assert.False(t, IsRepoHousekeepingRunning(repo.ID))
+	AssertExistsAndLoadBean(t, &RepoHousekeeping{RepoID: repo.ID, IsSuccess: true})
+	// The repository is not considered as updated
+	AssertExistsAndLoadBean(t, &Repository{ID: 1, UpdatedUnix: repo.UpdatedUnix})
+
+	// Only the last housekeeping is kept
+	_, err = repo.Housekeeping()
+	assert.NoError(t, err)
+	AssertCount(t, &RepoHousekeeping{RepoID: repo.ID}, 1)
+
+	repoHousekeepingTable.Start("1")
+	_, err = repo.Housekeeping()
+	assert.True(t, IsErrRepoHousekeepingInProgress(err))
+	assert.True(t, IsErrRepoHousekeepingInProgress(repo.StartHousekeeping()))
+	repoHousekeepingTable.Stop("1")
+}
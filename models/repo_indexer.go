@@ -9,14 +9,13 @@ import (
 	"strconv"
 	"strings"
 
+	"code.gitea.io/gitea/modules/analyze"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/charset"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/indexer"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
-
-	"github.com/ethantkoenig/rupture"
 )
 
 // RepoIndexerStatus status of a repo's entry in the repo indexer
@@ -143,7 +142,7 @@ func updateRepoIndexer(repo *Repository) error {
 		return nil
 	}
 
-	batch := indexer.RepoIndexerBatch()
+	batch := indexer.NewRepoIndexerBatch()
 	for _, update := range changes.Updates {
 		if err := addUpdate(update, repo, batch); err != nil {
 			return err
@@ -191,7 +190,7 @@ func getRepoChanges(repo *Repository, revision string) (*repoChanges, error) {
 	return nonGenesisChanges(repo, revision)
 }
 
-func addUpdate(update fileUpdate, repo *Repository, batch rupture.FlushingBatch) error {
+func addUpdate(update fileUpdate, repo *Repository, batch *indexer.RepoIndexerBatch) error {
 	stdout, err := git.NewCommand("cat-file", "-s", update.BlobSha).
 		RunInDir(repo.RepoPath())
 	if err != nil {
@@ -215,14 +214,15 @@ func addUpdate(update fileUpdate, repo *Repository, batch rupture.FlushingBatch)
 		Filepath: update.Filename,
 		Op:       indexer.RepoIndexerOpUpdate,
 		Data: &indexer.RepoIndexerData{
-			RepoID:  repo.ID,
-			Content: string(charset.ToUTF8DropErrors(fileContents)),
+			RepoID:   repo.ID,
+			Content:  string(charset.ToUTF8DropErrors(fileContents)),
+			Language: analyze.GetCodeLanguage(update.Filename),
 		},
 	}
-	return indexerUpdate.AddToFlushingBatch(batch)
+	return batch.Add(indexerUpdate)
 }
 
-func addDelete(filename string, repo *Repository, batch rupture.FlushingBatch) error {
+func addDelete(filename string, repo *Repository, batch *indexer.RepoIndexerBatch) error {
 	indexerUpdate := indexer.RepoIndexerUpdate{
 		Filepath: filename,
 		Op:       indexer.RepoIndexerOpDelete,
@@ -230,7 +230,7 @@ func addDelete(filename string, repo *Repository, batch rupture.FlushingBatch) e
 			RepoID: repo.ID,
 		},
 	}
-	return indexerUpdate.AddToFlushingBatch(batch)
+	return batch.Add(indexerUpdate)
 }
 
 func isIndexable(entry *git.TreeEntry) bool {
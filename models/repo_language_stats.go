@@ -0,0 +1,215 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/analyze"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/sync"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// languageStatsQueue contains the IDs of the repositories whose language
+// statistics have to be updated
+var languageStatsQueue = sync.NewUniqueQueue(setting.Repository.PullRequestQueueLength)
+
+// LanguageStat describes the size of the files of a language in the default
+// branch of a repository
+type LanguageStat struct {
+	ID          int64              `xorm:"pk autoincr"`
+	RepoID      int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	CommitID    string             `xorm:"VARCHAR(40)"`
+	Language    string             `xorm:"VARCHAR(30) UNIQUE(s) INDEX NOT NULL"`
+	Size        int64              `xorm:"NOT NULL DEFAULT 0"`
+	Percentage  float32            `xorm:"-"`
+	Color       string             `xorm:"-"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+// LanguageStatList defines a list of language statistics
+type LanguageStatList []*LanguageStat
+
+func (stats LanguageStatList) loadAttributes() {
+	var total int64
+	for _, stat := range stats {
+		total += stat.Size
+	}
+	for _, stat := range stats {
+		if total > 0 {
+			stat.Percentage = float32(stat.Size*1000/total) / 10
+		}
+		stat.Color = analyze.GetLanguageColor(stat.Language)
+	}
+}
+
+func (repo *Repository) getLanguageStats(e Engine) (LanguageStatList, error) {
+	stats := make(LanguageStatList, 0, 6)
+	if err := e.Where("`repo_id` = ?", repo.ID).Desc("`size`").Find(&stats); err != nil {
+		return nil, err
+	}
+	stats.loadAttributes()
+	return stats, nil
+}
+
+// GetLanguageStats returns the language statistics of the default branch of
+// the repository, ordered by decreasing size
+func (repo *Repository) GetLanguageStats() (LanguageStatList, error) {
+	return repo.getLanguageStats(x)
+}
+
+// UpdateLanguageStats replaces the language statistics of the repository by
+// the sizes of the languages of the commit
+func (repo *Repository) UpdateLanguageStats(commitID string, sizes map[string]int64) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := sess.Delete(&LanguageStat{RepoID: repo.ID}); err != nil {
+		return err
+	}
+	for language, size := range sizes {
+		if _, err := sess.Insert(&LanguageStat{
+			RepoID:   repo.ID,
+			CommitID: commitID,
+			Language: language,
+			Size:     size,
+		}); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+// getLanguageStatsCommitID returns the ID of the commit the language statistics
+// of the repository have been computed for, an empty string if there are none
+func (repo *Repository) getLanguageStatsCommitID() (string, error) {
+	stat := new(LanguageStat)
+	has, err := x.Where("`repo_id` = ?", repo.ID).Cols("`commit_id`").Get(stat)
+	if err != nil || !has {
+		return "", err
+	}
+	return stat.CommitID, nil
+}
+
+// GetLanguageSizes returns the size of the files of each language in the tree
+// of the commit. The vendored, generated and documentation files are ignored,
+// and so are the data files unless they are detectable, as linguist does, the
+// linguist attributes of the .gitattributes file take precedence.
+func GetLanguageSizes(repoPath string, commit *git.Commit) (map[string]int64, error) {
+	attrs, err := commit.GetAttributes()
+	if err != nil {
+		return nil, fmt.Errorf("GetAttributes: %v", err)
+	}
+
+	stdout, err := git.NewCommand("ls-tree", "--full-tree", "-r", "-l", "-z", commit.ID.String()).
+		RunInDirBytes(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := make(map[string]int64)
+	for _, line := range bytes.Split(stdout, []byte{0}) {
+		// <mode> SP <type> SP <object> SP+ <size> TAB <file>
+		tab := bytes.IndexByte(line, '\t')
+		if tab < 0 {
+			continue
+		}
+		fields := strings.Fields(string(line[:tab]))
+		if len(fields) != 4 || fields[1] != "blob" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			continue
+		}
+		filepath := string(line[tab+1:])
+
+		fileAttrs := attrs.Get(filepath)
+		if analyze.IsVendored(fileAttrs, filepath) ||
+			analyze.IsGenerated(fileAttrs) ||
+			analyze.IsDocumented(fileAttrs, filepath) {
+			continue
+		}
+
+		if language, detectable := analyze.GetLanguage(fileAttrs, filepath); detectable {
+			sizes[language] += size
+		}
+	}
+	return sizes, nil
+}
+
+// UpdateRepoLanguageStats adds the repository to the queue of the ones whose
+// language statistics have to be updated
+func UpdateRepoLanguageStats(repo *Repository) {
+	go languageStatsQueue.Add(repo.ID)
+}
+
+// InitLanguageStats starts updating the language statistics of the
+// repositories added to the queue
+func InitLanguageStats() {
+	go processLanguageStatsQueue()
+}
+
+func processLanguageStatsQueue() {
+	for repoID := range languageStatsQueue.Queue() {
+		log.Trace("processLanguageStatsQueue[%s]: updating language statistics", repoID)
+		languageStatsQueue.Remove(repoID)
+
+		id, err := strconv.ParseInt(repoID, 10, 64)
+		if err != nil {
+			log.Error("processLanguageStatsQueue[%s]: %v", repoID, err)
+			continue
+		}
+		if err = updateLanguageStats(id); err != nil {
+			log.Error("updateLanguageStats[%d]: %v", id, err)
+		}
+	}
+}
+
+func updateLanguageStats(repoID int64) error {
+	repo, err := GetRepositoryByID(repoID)
+	if err != nil {
+		if IsErrRepoNotExist(err) {
+			return nil
+		}
+		return err
+	} else if repo.IsEmpty {
+		return nil
+	}
+
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	commit, err := gitRepo.GetBranchCommit(repo.DefaultBranch)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	commitID, err := repo.getLanguageStatsCommitID()
+	if err != nil {
+		return err
+	} else if commitID == commit.ID.String() {
+		return nil
+	}
+
+	sizes, err := GetLanguageSizes(repo.RepoPath(), commit)
+	if err != nil {
+		return err
+	}
+	return repo.UpdateLanguageStats(commit.ID.String(), sizes)
+}
@@ -0,0 +1,55 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLanguageSizes(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	assert.NoError(t, err)
+	commit, err := gitRepo.GetBranchCommit(repo.DefaultBranch)
+	assert.NoError(t, err)
+
+	// The README is documentation
+	sizes, err := GetLanguageSizes(repo.RepoPath(), commit)
+	assert.NoError(t, err)
+	assert.Empty(t, sizes)
+}
+
+func TestUpdateLanguageStats(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	assert.NoError(t, repo.UpdateLanguageStats("65f1bf27bc3bf70f64657658635e66094edbcb4d", map[string]int64{
+		"Go":         300,
+		"JavaScript": 100,
+	}))
+
+	stats, err := repo.GetLanguageStats()
+	assert.NoError(t, err)
+	if assert.Len(t, stats, 2) {
+		assert.EqualValues(t, "Go", stats[0].Language)
+		assert.EqualValues(t, 75, stats[0].Percentage)
+		assert.EqualValues(t, "JavaScript", stats[1].Language)
+		assert.EqualValues(t, 25, stats[1].Percentage)
+	}
+	commitID, err := repo.getLanguageStatsCommitID()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", commitID)
+
+	assert.NoError(t, repo.UpdateLanguageStats("65f1bf27bc3bf70f64657658635e66094edbcb4d", map[string]int64{}))
+	stats, err = repo.GetLanguageStats()
+	assert.NoError(t, err)
+	assert.Empty(t, stats)
+}
@@ -38,6 +38,9 @@ type Mirror struct {
 
 	UpdatedUnix    timeutil.TimeStamp `xorm:"INDEX"`
 	NextUpdateUnix timeutil.TimeStamp `xorm:"INDEX"`
+	// LastError is the sanitized output of the last failed sync, it is empty
+	// if the last sync succeeded
+	LastError string `xorm:"TEXT"`
 
 	address string `xorm:"-"`
 }
@@ -72,21 +75,21 @@ func (m *Mirror) ScheduleNextUpdate() {
 	}
 }
 
-func remoteAddress(repoPath string) (string, error) {
+func remoteAddress(repoPath, remoteName string) (string, error) {
 	var cmd *git.Command
 	binVersion, err := git.BinVersion()
 	if err != nil {
 		return "", err
 	}
 	if version.Compare(binVersion, "2.7", ">=") {
-		cmd = git.NewCommand("remote", "get-url", "origin")
+		cmd = git.NewCommand("remote", "get-url", remoteName)
 	} else {
-		cmd = git.NewCommand("config", "--get", "remote.origin.url")
+		cmd = git.NewCommand("config", "--get", "remote."+remoteName+".url")
 	}
 
 	result, err := cmd.RunInDir(repoPath)
 	if err != nil {
-		if strings.HasPrefix(err.Error(), "exit status 128 - fatal: No such remote ") {
+		if strings.Contains(err.Error(), "No such remote") {
 			return "", nil
 		}
 		return "", err
@@ -102,7 +105,7 @@ func (m *Mirror) readAddress() {
 		return
 	}
 	var err error
-	m.address, err = remoteAddress(m.Repo.RepoPath())
+	m.address, err = remoteAddress(m.Repo.RepoPath(), "origin")
 	if err != nil {
 		log.Error("remoteAddress: %v", err)
 	}
@@ -111,7 +114,7 @@ func (m *Mirror) readAddress() {
 // sanitizeOutput sanitizes output of a command, replacing occurrences of the
 // repository's remote address with a sanitized version.
 func sanitizeOutput(output, repoPath string) (string, error) {
-	remoteAddr, err := remoteAddress(repoPath)
+	remoteAddr, err := remoteAddress(repoPath, "origin")
 	if err != nil {
 		// if we're unable to load the remote address, then we're unable to
 		// sanitize.
@@ -251,8 +254,15 @@ func (m *Mirror) runSync() ([]*mirrorSyncResult, bool) {
 		gitArgs = append(gitArgs, "--prune")
 	}
 
-	_, stderr, err := process.GetManager().ExecDir(
-		timeout, repoPath, fmt.Sprintf("Mirror.runSync: %s", repoPath),
+	env, cleanup, err := mirrorSSHEnv(m.Repo, m.FullAddress())
+	if err != nil {
+		log.Error("mirrorSSHEnv: %v", err)
+		return nil, false
+	}
+	defer cleanup()
+
+	_, stderr, err := process.GetManager().ExecDirEnv(
+		timeout, repoPath, fmt.Sprintf("Mirror.runSync: %s", repoPath), env,
 		git.GitExecutable, gitArgs...)
 	if err != nil {
 		// sanitize the output, since it may contain the remote address, which may
@@ -262,6 +272,7 @@ func (m *Mirror) runSync() ([]*mirrorSyncResult, bool) {
 			log.Error("sanitizeOutput: %v", err)
 			return nil, false
 		}
+		m.LastError = message
 		desc := fmt.Sprintf("Failed to update mirror repository '%s': %s", repoPath, message)
 		log.Error(desc)
 		if err = CreateRepositoryNotice(desc); err != nil {
@@ -285,8 +296,20 @@ func (m *Mirror) runSync() ([]*mirrorSyncResult, bool) {
 	}
 
 	if m.Repo.HasWiki() {
-		if _, stderr, err := process.GetManager().ExecDir(
-			timeout, wikiPath, fmt.Sprintf("Mirror.runSync: %s", wikiPath),
+		wikiAddress, err := remoteAddress(wikiPath, "origin")
+		if err != nil {
+			log.Error("remoteAddress: %v", err)
+			return nil, false
+		}
+		wikiEnv, wikiCleanup, err := mirrorSSHEnv(m.Repo, wikiAddress)
+		if err != nil {
+			log.Error("mirrorSSHEnv: %v", err)
+			return nil, false
+		}
+		defer wikiCleanup()
+
+		if _, stderr, err := process.GetManager().ExecDirEnv(
+			timeout, wikiPath, fmt.Sprintf("Mirror.runSync: %s", wikiPath), wikiEnv,
 			git.GitExecutable, "remote", "update", "--prune"); err != nil {
 			// sanitize the output, since it may contain the remote address, which may
 			// contain a password
@@ -295,6 +318,7 @@ func (m *Mirror) runSync() ([]*mirrorSyncResult, bool) {
 				log.Error("sanitizeOutput: %v", err)
 				return nil, false
 			}
+			m.LastError = message
 			desc := fmt.Sprintf("Failed to update mirror wiki repository '%s': %s", wikiPath, message)
 			log.Error(desc)
 			if err = CreateRepositoryNotice(desc); err != nil {
@@ -315,6 +339,7 @@ func (m *Mirror) runSync() ([]*mirrorSyncResult, bool) {
 	}
 
 	m.UpdatedUnix = timeutil.TimeStampNow()
+	m.LastError = ""
 	return parseRemoteUpdateOutput(output), true
 }
 
@@ -350,7 +375,7 @@ func DeleteMirrorByRepoID(repoID int64) error {
 	return err
 }
 
-// MirrorUpdate checks and updates mirror repositories.
+// MirrorUpdate checks and updates pull and push mirror repositories.
 func MirrorUpdate() {
 	log.Trace("Doing: MirrorUpdate")
 
@@ -369,6 +394,16 @@ func MirrorUpdate() {
 		}); err != nil {
 		log.Error("MirrorUpdate: %v", err)
 	}
+
+	if err := x.
+		Where("next_update_unix<=?", time.Now().Unix()).
+		And("next_update_unix!=0").
+		Iterate(new(PushMirror), func(idx int, bean interface{}) error {
+			PushMirrorQueue.Add(bean.(*PushMirror).ID)
+			return nil
+		}); err != nil {
+		log.Error("MirrorUpdate: %v", err)
+	}
 }
 
 // SyncMirrors checks and syncs mirrors.
@@ -389,8 +424,16 @@ func SyncMirrors() {
 
 		results, ok := m.runSync()
 		if !ok {
+			if _, err = sess.ID(m.ID).Cols("last_error").Update(m); err != nil {
+				log.Error("UpdateMirror [%s]: %v", repoID, err)
+			}
 			continue
 		}
+		if len(results) > 0 {
+			AddPushMirrorsToQueue(m.RepoID, true)
+			UpdateRepoLanguageStats(m.Repo)
+			UpdateRepoCommitGraph(m.Repo)
+		}
 
 		m.ScheduleNextUpdate()
 		if err = updateMirror(sess, m); err != nil {
@@ -472,7 +515,8 @@ func SyncMirrors() {
 	}
 }
 
-// InitSyncMirrors initializes a go routine to sync the mirrors
+// InitSyncMirrors initializes the go routines to sync the pull and push mirrors
 func InitSyncMirrors() {
 	go SyncMirrors()
+	go SyncPushMirrors()
 }
@@ -0,0 +1,183 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// mirrorSSHKeyBits is the size of the generated RSA keys
+const mirrorSSHKeyBits = 3072
+
+// MirrorSSHKey is the SSH keypair generated for a repository, its pull mirror
+// and push mirrors authenticate with it to the remotes with an SSH address.
+// The public key has to be added to the remotes, as a deploy key for example,
+// and the private key never leaves the server.
+type MirrorSSHKey struct {
+	ID          int64  `xorm:"pk autoincr"`
+	RepoID      int64  `xorm:"UNIQUE"`
+	PublicKey   string `xorm:"TEXT NOT NULL"`
+	Fingerprint string `xorm:"NOT NULL"`
+	// PrivateKey is encrypted with the secret key of the instance
+	PrivateKey  string             `xorm:"TEXT NOT NULL"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// APIFormat converts the mirror SSH key to its API format
+func (k *MirrorSSHKey) APIFormat() *api.MirrorSSHKey {
+	return &api.MirrorSSHKey{
+		PublicKey:   k.PublicKey,
+		Fingerprint: k.Fingerprint,
+		Created:     k.CreatedUnix.AsTime(),
+	}
+}
+
+func (k *MirrorSSHKey) getEncryptionKey() []byte {
+	key := md5.Sum([]byte(setting.SecretKey))
+	return key[:]
+}
+
+// privateKeyPEM returns the decrypted private key in PEM format
+func (k *MirrorSSHKey) privateKeyPEM() ([]byte, error) {
+	encrypted, err := base64.StdEncoding.DecodeString(k.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return aesDecrypt(k.getEncryptionKey(), encrypted)
+}
+
+// GenerateMirrorSSHKey generates a new SSH keypair for the mirrors of the
+// repository, replacing the previous one.
+func GenerateMirrorSSHKey(repoID int64) (*MirrorSSHKey, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, mirrorSSHKeyBits)
+	if err != nil {
+		return nil, fmt.Errorf("GenerateKey: %v", err)
+	}
+	publicKey, err := ssh.NewPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("NewPublicKey: %v", err)
+	}
+
+	k := &MirrorSSHKey{
+		RepoID:      repoID,
+		PublicKey:   strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey))),
+		Fingerprint: ssh.FingerprintSHA256(publicKey),
+	}
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	encrypted, err := aesEncrypt(k.getEncryptionKey(), privateKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("aesEncrypt: %v", err)
+	}
+	k.PrivateKey = base64.StdEncoding.EncodeToString(encrypted)
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return nil, err
+	}
+	if _, err = sess.Delete(&MirrorSSHKey{RepoID: repoID}); err != nil {
+		return nil, err
+	}
+	if _, err = sess.Insert(k); err != nil {
+		return nil, err
+	}
+	return k, sess.Commit()
+}
+
+func getMirrorSSHKey(e Engine, repoID int64) (*MirrorSSHKey, error) {
+	k := new(MirrorSSHKey)
+	has, err := e.Where("repo_id = ?", repoID).Get(k)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrMirrorSSHKeyNotExist{RepoID: repoID}
+	}
+	return k, nil
+}
+
+// GetMirrorSSHKey returns the SSH keypair of the mirrors of the repository.
+func GetMirrorSSHKey(repoID int64) (*MirrorSSHKey, error) {
+	return getMirrorSSHKey(x, repoID)
+}
+
+// DeleteMirrorSSHKey deletes the SSH keypair of the mirrors of the repository.
+func DeleteMirrorSSHKey(repoID int64) error {
+	_, err := x.Delete(&MirrorSSHKey{RepoID: repoID})
+	return err
+}
+
+// IsSSHAddress returns true if the address of a remote is an ssh:// URL
+func IsSSHAddress(addr string) bool {
+	u, err := url.Parse(addr)
+	return err == nil && u.Scheme == "ssh" && len(u.Host) > 0
+}
+
+// mirrorSSHEnv returns the environment of the Git commands syncing the
+// repository with the remote address, which authenticates with the mirror SSH
+// key of the repository, and a function removing the private key written for
+// them. The environment is nil if the address is not an SSH one, an SSH
+// address without a mirror SSH key is an error so that the keys of the server
+// are never used.
+func mirrorSSHEnv(repo *Repository, addr string) ([]string, func(), error) {
+	if !IsSSHAddress(addr) {
+		return nil, func() {}, nil
+	}
+	k, err := GetMirrorSSHKey(repo.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	privateKeyPEM, err := k.privateKeyPEM()
+	if err != nil {
+		return nil, nil, fmt.Errorf("privateKeyPEM: %v", err)
+	}
+
+	f, err := ioutil.TempFile("", "gitea-mirror-key")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		if err := os.Remove(f.Name()); err != nil {
+			log.Error("Remove[%s]: %v", f.Name(), err)
+		}
+	}
+	_, err = f.Write(privateKeyPEM)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	// The host key of a remote is accepted the first time it is connected to
+	// and must not change afterwards
+	knownHostsPath := filepath.Join(setting.AppDataPath, "ssh", "mirror_known_hosts")
+	if err = os.MkdirAll(filepath.Dir(knownHostsPath), os.ModePerm); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	env := append(os.Environ(),
+		fmt.Sprintf(`GIT_SSH_COMMAND=ssh -i "%s" -o IdentitiesOnly=yes -o BatchMode=yes -o StrictHostKeyChecking=accept-new -o UserKnownHostsFile="%s"`, f.Name(), knownHostsPath),
+		"GIT_SSH_VARIANT=ssh")
+	return env, cleanup, nil
+}
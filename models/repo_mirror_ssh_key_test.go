@@ -0,0 +1,80 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestMirrorSSHKey(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	_, err := GetMirrorSSHKey(1)
+	assert.True(t, IsErrMirrorSSHKeyNotExist(err))
+
+	k, err := GenerateMirrorSSHKey(1)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(k.PublicKey, "ssh-rsa "))
+	assert.NotContains(t, k.PrivateKey, "PRIVATE KEY")
+
+	privateKeyPEM, err := k.privateKeyPEM()
+	assert.NoError(t, err)
+	signer, err := ssh.ParsePrivateKey(privateKeyPEM)
+	assert.NoError(t, err)
+	assert.Equal(t, k.Fingerprint, ssh.FingerprintSHA256(signer.PublicKey()))
+
+	regenerated, err := GenerateMirrorSSHKey(1)
+	assert.NoError(t, err)
+	assert.NotEqual(t, k.PublicKey, regenerated.PublicKey)
+	k, err = GetMirrorSSHKey(1)
+	assert.NoError(t, err)
+	assert.Equal(t, regenerated.PublicKey, k.PublicKey)
+
+	assert.NoError(t, DeleteMirrorSSHKey(1))
+	_, err = GetMirrorSSHKey(1)
+	assert.True(t, IsErrMirrorSSHKeyNotExist(err))
+}
+
+func TestMirrorSSHEnv(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	env, cleanup, err := mirrorSSHEnv(repo, "https://example.com/user/repo.git")
+	assert.NoError(t, err)
+	assert.Nil(t, env)
+	cleanup()
+
+	// The keys of the server must not be used
+	_, _, err = mirrorSSHEnv(repo, "ssh://git@example.com/user/repo.git")
+	assert.True(t, IsErrMirrorSSHKeyNotExist(err))
+
+	k, err := GenerateMirrorSSHKey(repo.ID)
+	assert.NoError(t, err)
+	env, cleanup, err = mirrorSSHEnv(repo, "ssh://git@example.com/user/repo.git")
+	assert.NoError(t, err)
+	var sshCommand string
+	for _, v := range env {
+		if strings.HasPrefix(v, "GIT_SSH_COMMAND=") {
+			sshCommand = v
+		}
+	}
+	assert.Contains(t, sshCommand, "IdentitiesOnly=yes")
+	keyPath := strings.SplitN(strings.SplitN(sshCommand, `-i "`, 2)[1], `"`, 2)[0]
+	privateKeyPEM, err := ioutil.ReadFile(keyPath)
+	assert.NoError(t, err)
+	expected, err := k.privateKeyPEM()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, privateKeyPEM)
+
+	cleanup()
+	_, err = os.Stat(keyPath)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,247 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/generate"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/sync"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/go-xorm/xorm"
+	"github.com/unknwon/com"
+)
+
+// PushMirrorQueue holds an UniqueQueue object of the IDs of the push mirrors
+// to sync
+var PushMirrorQueue = sync.NewUniqueQueue(setting.Repository.MirrorQueueLength)
+
+// PushMirror represents a remote repository the branches and tags of a
+// repository are pushed to. The address of the remote, including its
+// credentials, is stored in the Git repository config like the address of
+// pull mirrors.
+type PushMirror struct {
+	ID         int64       `xorm:"pk autoincr"`
+	RepoID     int64       `xorm:"INDEX"`
+	Repo       *Repository `xorm:"-"`
+	RemoteName string      `xorm:"NOT NULL"`
+	// SyncOnCommit pushes to the remote every time the repository changes
+	SyncOnCommit bool `xorm:"NOT NULL DEFAULT true"`
+	// Interval between two pushes, zero disables the periodic pushes
+	Interval time.Duration
+
+	LastUpdateUnix timeutil.TimeStamp `xorm:"INDEX"`
+	NextUpdateUnix timeutil.TimeStamp `xorm:"INDEX"`
+	// LastError is the sanitized output of the last failed push, it is empty
+	// if the last push succeeded
+	LastError   string             `xorm:"TEXT"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+
+	address string `xorm:"-"`
+}
+
+// AfterLoad is invoked from XORM after setting the values of all fields of this object.
+func (m *PushMirror) AfterLoad(session *xorm.Session) {
+	if m == nil {
+		return
+	}
+
+	var err error
+	m.Repo, err = getRepositoryByID(session, m.RepoID)
+	if err != nil {
+		log.Error("getRepositoryByID[%d]: %v", m.ID, err)
+	}
+}
+
+// ScheduleNextUpdate calculates and sets next update time.
+func (m *PushMirror) ScheduleNextUpdate() {
+	if m.Interval != 0 {
+		m.NextUpdateUnix = timeutil.TimeStampNow().AddDuration(m.Interval)
+	} else {
+		m.NextUpdateUnix = 0
+	}
+}
+
+func (m *PushMirror) readAddress() {
+	if len(m.address) > 0 || m.Repo == nil {
+		return
+	}
+	var err error
+	m.address, err = remoteAddress(m.Repo.RepoPath(), m.RemoteName)
+	if err != nil {
+		log.Error("remoteAddress: %v", err)
+	}
+}
+
+// Address returns the address of the remote with credentials censored.
+func (m *PushMirror) Address() string {
+	m.readAddress()
+	return util.SanitizeURLCredentials(m.address, false)
+}
+
+// APIFormat converts the push mirror to its API format
+func (m *PushMirror) APIFormat() *api.PushMirror {
+	apiMirror := &api.PushMirror{
+		ID:            m.ID,
+		RemoteName:    m.RemoteName,
+		RemoteAddress: m.Address(),
+		SyncOnCommit:  m.SyncOnCommit,
+		Interval:      m.Interval.String(),
+		LastError:     m.LastError,
+		Created:       m.CreatedUnix.AsTime(),
+	}
+	if m.LastUpdateUnix > 0 {
+		lastUpdate := m.LastUpdateUnix.AsTime()
+		apiMirror.LastUpdate = &lastUpdate
+	}
+	return apiMirror
+}
+
+// runSync pushes the branches and tags of the repository to the remote, the
+// branches and tags which do not exist anymore are deleted from the remote.
+func (m *PushMirror) runSync() error {
+	repoPath := m.Repo.RepoPath()
+	timeout := time.Duration(setting.Git.Timeout.Mirror) * time.Second
+
+	m.readAddress()
+	env, cleanup, err := mirrorSSHEnv(m.Repo, m.address)
+	if err != nil {
+		return fmt.Errorf("mirrorSSHEnv: %v", err)
+	}
+	defer cleanup()
+
+	_, stderr, err := process.GetManager().ExecDirEnv(
+		timeout, repoPath, fmt.Sprintf("PushMirror.runSync: %s", repoPath), env,
+		git.GitExecutable, "push", "--prune", m.RemoteName,
+		"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*")
+	if err != nil {
+		// sanitize the output, since it may contain the remote address, which may
+		// contain a password
+		return errors.New(util.SanitizeMessage(stderr, m.address))
+	}
+	return nil
+}
+
+// InsertPushMirror adds the remote to the Git repository config and creates the
+// push mirror.
+func InsertPushMirror(m *PushMirror, addr string) (err error) {
+	if m.RemoteName, err = generate.GetRandomString(8); err != nil {
+		return err
+	}
+	m.RemoteName = "remote_mirror_" + m.RemoteName
+
+	repo, err := GetRepositoryByID(m.RepoID)
+	if err != nil {
+		return err
+	}
+	if _, err = git.NewCommand("remote", "add", m.RemoteName, addr).RunInDir(repo.RepoPath()); err != nil {
+		return fmt.Errorf("remote add: %v", err)
+	}
+	m.Repo = repo
+	m.ScheduleNextUpdate()
+	if _, err = x.Insert(m); err != nil {
+		if _, err := git.NewCommand("remote", "remove", m.RemoteName).RunInDir(repo.RepoPath()); err != nil {
+			log.Error("remote remove[%s]: %v", m.RemoteName, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// DeletePushMirror deletes the push mirror and removes the remote from the Git
+// repository config.
+func DeletePushMirror(m *PushMirror) error {
+	if _, err := x.ID(m.ID).Delete(new(PushMirror)); err != nil {
+		return err
+	}
+	if m.Repo == nil {
+		return nil
+	}
+	_, err := git.NewCommand("remote", "remove", m.RemoteName).RunInDir(m.Repo.RepoPath())
+	if err != nil && !strings.Contains(err.Error(), "No such remote") {
+		return fmt.Errorf("remote remove: %v", err)
+	}
+	return nil
+}
+
+// UpdatePushMirror updates the push mirror
+func UpdatePushMirror(m *PushMirror) error {
+	_, err := x.ID(m.ID).AllCols().Update(m)
+	return err
+}
+
+// GetPushMirrorByID returns the push mirror of the repository with the ID.
+func GetPushMirrorByID(repoID, id int64) (*PushMirror, error) {
+	m := new(PushMirror)
+	has, err := x.ID(id).Where("repo_id = ?", repoID).Get(m)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPushMirrorNotExist{ID: id, RepoID: repoID}
+	}
+	return m, nil
+}
+
+// GetPushMirrorsByRepoID returns the push mirrors of the repository.
+func GetPushMirrorsByRepoID(repoID int64) ([]*PushMirror, error) {
+	mirrors := make([]*PushMirror, 0, 5)
+	return mirrors, x.Where("repo_id = ?", repoID).Asc("id").Find(&mirrors)
+}
+
+// AddPushMirrorsToQueue queues the push mirrors of the repository for sync,
+// with onlyOnCommit only the push mirrors syncing on every change are queued.
+func AddPushMirrorsToQueue(repoID int64, onlyOnCommit bool) {
+	sess := x.Where("repo_id = ?", repoID)
+	if onlyOnCommit {
+		sess.And("sync_on_commit = ?", true)
+	}
+	mirrors := make([]*PushMirror, 0, 5)
+	if err := sess.Find(&mirrors); err != nil {
+		log.Error("AddPushMirrorsToQueue [repo_id: %d]: %v", repoID, err)
+		return
+	}
+	for _, m := range mirrors {
+		PushMirrorQueue.Add(m.ID)
+	}
+}
+
+// SyncPushMirrors listens on the queue and pushes to the remotes of the push
+// mirrors.
+func SyncPushMirrors() {
+	for id := range PushMirrorQueue.Queue() {
+		log.Trace("SyncPushMirrors [id: %v]", id)
+		PushMirrorQueue.Remove(id)
+
+		m := new(PushMirror)
+		has, err := x.ID(com.StrTo(id).MustInt64()).Get(m)
+		if err != nil {
+			log.Error("GetPushMirrorByID [%s]: %v", id, err)
+			continue
+		} else if !has || m.Repo == nil {
+			continue
+		}
+
+		m.LastError = ""
+		if err = m.runSync(); err != nil {
+			m.LastError = err.Error()
+			log.Error("Failed to push mirror '%s' of repository '%s': %s", m.Address(), m.Repo.RepoPath(), m.LastError)
+		}
+		m.LastUpdateUnix = timeutil.TimeStampNow()
+		m.ScheduleNextUpdate()
+		if err = UpdatePushMirror(m); err != nil {
+			log.Error("UpdatePushMirror [%s]: %v", id, err)
+		}
+	}
+}
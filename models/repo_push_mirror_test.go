@@ -0,0 +1,50 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushMirror(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	remotePath, err := ioutil.TempDir("", "push-mirror")
+	assert.NoError(t, err)
+	defer os.RemoveAll(remotePath)
+	assert.NoError(t, git.InitRepository(remotePath, true))
+
+	m := &PushMirror{RepoID: 1, SyncOnCommit: true}
+	assert.NoError(t, InsertPushMirror(m, remotePath))
+	assert.Equal(t, remotePath, m.Address())
+	assert.Zero(t, m.NextUpdateUnix)
+
+	mirrors, err := GetPushMirrorsByRepoID(1)
+	assert.NoError(t, err)
+	assert.Len(t, mirrors, 1)
+	assert.Equal(t, m.RemoteName, mirrors[0].RemoteName)
+
+	assert.NoError(t, m.runSync())
+	gitRepo, err := git.OpenRepository(remotePath)
+	assert.NoError(t, err)
+	branches, err := gitRepo.GetBranches()
+	assert.NoError(t, err)
+	assert.Contains(t, branches, "master")
+
+	_, err = GetPushMirrorByID(2, m.ID)
+	assert.True(t, IsErrPushMirrorNotExist(err))
+
+	assert.NoError(t, DeletePushMirror(m))
+	AssertNotExistsBean(t, &PushMirror{ID: m.ID})
+	address, err := remoteAddress(m.Repo.RepoPath(), m.RemoteName)
+	assert.NoError(t, err)
+	assert.Empty(t, address)
+}
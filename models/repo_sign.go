@@ -0,0 +1,62 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// SigningKey returns the ID of the GPG key used to sign the commits created
+// by Gitea in the repository, an empty string if they are not signed.
+func SigningKey(repoPath string) string {
+	switch setting.Repository.Signing.SigningKey {
+	case "", "none":
+		return ""
+	case "default":
+		// The key configured for the repository takes precedence over the one of Gitea
+		value, _ := git.NewCommand("config", "--get", "user.signingkey").RunInDir(repoPath)
+		return strings.TrimSpace(value)
+	}
+	return setting.Repository.Signing.SigningKey
+}
+
+// SignMerge returns the ID of the GPG key to sign the commits created by
+// merging the pull request, an empty string if they are not signed.
+func (pr *PullRequest) SignMerge() (string, error) {
+	if setting.Repository.Signing.Merges == "never" {
+		return "", nil
+	}
+	if err := pr.GetBaseRepo(); err != nil {
+		return "", fmt.Errorf("GetBaseRepo: %v", err)
+	}
+	key := SigningKey(pr.BaseRepo.RepoPath())
+	if len(key) == 0 || setting.Repository.Signing.Merges != "commitssigned" {
+		return key, nil
+	}
+
+	// All the commits of the pull request have to be verified
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return "", fmt.Errorf("OpenRepository: %v", err)
+	}
+	headCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		return "", fmt.Errorf("GetRefCommitID: %v", err)
+	}
+	commits, err := gitRepo.CommitsBetweenIDs(headCommitID, pr.MergeBase)
+	if err != nil {
+		return "", fmt.Errorf("CommitsBetweenIDs: %v", err)
+	}
+	for e := commits.Front(); e != nil; e = e.Next() {
+		if !ParseCommitWithSignature(pr.BaseRepo, e.Value.(*git.Commit)).Verified {
+			return "", nil
+		}
+	}
+	return key, nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigningKey(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer func(signing string) {
+		setting.Repository.Signing.SigningKey = signing
+	}(setting.Repository.Signing.SigningKey)
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	setting.Repository.Signing.SigningKey = "none"
+	assert.Empty(t, SigningKey(repo.RepoPath()))
+
+	setting.Repository.Signing.SigningKey = "0123456789ABCDEF"
+	assert.Equal(t, "0123456789ABCDEF", SigningKey(repo.RepoPath()))
+}
+
+func TestPullRequest_SignMerge(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer func(signing, merges string) {
+		setting.Repository.Signing.SigningKey = signing
+		setting.Repository.Signing.Merges = merges
+	}(setting.Repository.Signing.SigningKey, setting.Repository.Signing.Merges)
+
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 1}).(*PullRequest)
+	setting.Repository.Signing.SigningKey = "0123456789ABCDEF"
+
+	setting.Repository.Signing.Merges = "never"
+	key, err := pr.SignMerge()
+	assert.NoError(t, err)
+	assert.Empty(t, key)
+
+	setting.Repository.Signing.Merges = "always"
+	key, err = pr.SignMerge()
+	assert.NoError(t, err)
+	assert.Equal(t, "0123456789ABCDEF", key)
+}
@@ -11,6 +11,7 @@ import (
 	"image"
 	"image/png"
 	"testing"
+	"time"
 
 	"code.gitea.io/gitea/modules/markup"
 
@@ -122,6 +123,34 @@ func TestGetUserFork(t *testing.T) {
 	assert.Nil(t, repo)
 }
 
+func TestGetRepositoryInForkNetwork(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	// User13 has repo 11 forked from repo10 of user12
+	repo10 := AssertExistsAndLoadBean(t, &Repository{ID: 10}).(*Repository)
+	repo, err := GetRepositoryInForkNetwork(12, repo10)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 10, repo.ID)
+	repo, err = GetRepositoryInForkNetwork(13, repo10)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 11, repo.ID)
+	_, err = GetRepositoryInForkNetwork(2, repo10)
+	assert.True(t, IsErrRepoNotExist(err))
+
+	repo11 := AssertExistsAndLoadBean(t, &Repository{ID: 11}).(*Repository)
+	repo11.IsFork = true
+	repo, err = GetRepositoryInForkNetwork(12, repo11)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 10, repo.ID)
+
+	network, err := repo11.GetForkNetwork()
+	assert.NoError(t, err)
+	if assert.Len(t, network, 2) {
+		assert.EqualValues(t, 10, network[0].ID)
+		assert.EqualValues(t, 11, network[1].ID)
+	}
+}
+
 func TestForkRepository(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 
@@ -212,3 +241,20 @@ func TestDeleteAvatar(t *testing.T) {
 
 	assert.Equal(t, "", repo.Avatar)
 }
+
+func TestRepository_APIFormatMirror(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 5}).(*Repository)
+	assert.True(t, repo.IsMirror)
+
+	apiRepo := repo.APIFormat(AccessModeRead)
+	assert.Empty(t, apiRepo.MirrorInterval)
+	assert.Nil(t, apiRepo.MirrorUpdated)
+
+	_, err := x.Insert(&Mirror{RepoID: repo.ID, Interval: time.Hour, LastError: "fatal: unable to access"})
+	assert.NoError(t, err)
+	apiRepo = repo.APIFormat(AccessModeRead)
+	assert.Equal(t, "1h0m0s", apiRepo.MirrorInterval)
+	assert.NotNil(t, apiRepo.MirrorUpdated)
+	assert.Equal(t, "fatal: unable to access", apiRepo.MirrorLastError)
+}
@@ -0,0 +1,203 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// RepoTransfer represents a pending transfer of a repository to a user or an
+// organization, the ownership of the repository changes once the recipient
+// accepts it.
+type RepoTransfer struct {
+	ID          int64 `xorm:"pk autoincr"`
+	DoerID      int64
+	Doer        *User `xorm:"-"`
+	RecipientID int64 `xorm:"INDEX"`
+	Recipient   *User `xorm:"-"`
+	RepoID      int64 `xorm:"UNIQUE"`
+	// TeamIDs are the teams of the recipient organization which are given
+	// access to the repository once it is transferred
+	TeamIDs     []int64
+	Teams       []*Team            `xorm:"-"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL created"`
+}
+
+// LoadAttributes loads the doer, the recipient and the teams of the transfer
+func (t *RepoTransfer) LoadAttributes() (err error) {
+	if t.Doer == nil {
+		if t.Doer, err = getUserByID(x, t.DoerID); err != nil {
+			if !IsErrUserNotExist(err) {
+				return err
+			}
+			t.Doer = NewGhostUser()
+		}
+	}
+	if t.Recipient == nil {
+		if t.Recipient, err = getUserByID(x, t.RecipientID); err != nil {
+			return err
+		}
+	}
+	if t.Teams == nil && len(t.TeamIDs) > 0 {
+		t.Teams = make([]*Team, 0, len(t.TeamIDs))
+		if err = x.In("id", t.TeamIDs).And("org_id = ?", t.RecipientID).Find(&t.Teams); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CanUserAcceptTransfer returns whether the user can accept the transfer, which
+// is the case of the recipient, of the owners of the recipient organization
+// and of the site administrators.
+func (t *RepoTransfer) CanUserAcceptTransfer(u *User) bool {
+	if err := t.LoadAttributes(); err != nil {
+		log.Error("LoadAttributes: %v", err)
+		return false
+	}
+	return canUserAcceptTransferTo(u, t.Recipient)
+}
+
+func canUserAcceptTransferTo(u, recipient *User) bool {
+	if u.IsAdmin || u.ID == recipient.ID {
+		return true
+	}
+	if !recipient.IsOrganization() {
+		return false
+	}
+	isOwner, err := recipient.IsOwnedBy(u.ID)
+	if err != nil {
+		log.Error("IsOwnedBy: %v", err)
+		return false
+	}
+	return isOwner
+}
+
+// GetPendingRepositoryTransfer returns the pending transfer of the repository
+func GetPendingRepositoryTransfer(repo *Repository) (*RepoTransfer, error) {
+	transfer := &RepoTransfer{RepoID: repo.ID}
+	has, err := x.Get(transfer)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrNoPendingRepoTransfer{RepoID: repo.ID}
+	}
+	return transfer, nil
+}
+
+// StartRepositoryTransfer transfers the repository to the new owner, the teams
+// of the new owner organization are given access to it. The transfer is pending
+// until the new owner accepts it, unless the doer could accept it themselves.
+// It returns whether the repository has been transferred.
+func StartRepositoryTransfer(doer, newOwner *User, repo *Repository, teams []*Team) (bool, error) {
+	if _, err := GetPendingRepositoryTransfer(repo); err == nil {
+		return false, ErrRepoTransferInProgress{Uname: repo.MustOwnerName(), Name: repo.Name}
+	} else if !IsErrNoPendingRepoTransfer(err) {
+		return false, err
+	}
+
+	has, err := IsRepositoryExist(newOwner, repo.Name)
+	if err != nil {
+		return false, fmt.Errorf("IsRepositoryExist: %v", err)
+	} else if has {
+		return false, ErrRepoAlreadyExist{newOwner.Name, repo.Name}
+	}
+
+	transfer := &RepoTransfer{
+		DoerID:      doer.ID,
+		Doer:        doer,
+		RecipientID: newOwner.ID,
+		Recipient:   newOwner,
+		RepoID:      repo.ID,
+		Teams:       teams,
+	}
+	for _, team := range teams {
+		transfer.TeamIDs = append(transfer.TeamIDs, team.ID)
+	}
+	if canUserAcceptTransferTo(doer, newOwner) {
+		return true, transferRepository(transfer, repo)
+	}
+
+	if _, err = x.Insert(transfer); err != nil {
+		return false, err
+	}
+	if setting.MailService != nil {
+		SendRepoTransferNotifyMail(doer, newOwner, repo)
+	}
+	return false, nil
+}
+
+// AcceptRepositoryTransfer transfers the repository to the recipient of its
+// pending transfer
+func AcceptRepositoryTransfer(repo *Repository) error {
+	transfer, err := GetPendingRepositoryTransfer(repo)
+	if err != nil {
+		return err
+	}
+	if err = transfer.LoadAttributes(); err != nil {
+		return err
+	}
+	return transferRepository(transfer, repo)
+}
+
+// CancelRepositoryTransfer cancels the pending transfer of the repository, or
+// rejects it
+func CancelRepositoryTransfer(repo *Repository) error {
+	_, err := x.Delete(&RepoTransfer{RepoID: repo.ID})
+	return err
+}
+
+// transferRepository changes the owner of the repository to the recipient of
+// the transfer, a redirection from its former location is added
+func transferRepository(transfer *RepoTransfer, repo *Repository) error {
+	if err := repo.GetOwner(); err != nil {
+		return err
+	}
+	oldOwnerID := repo.OwnerID
+	if err := TransferOwnership(transfer.Doer, transfer.Recipient.Name, repo); err != nil {
+		return err
+	}
+	if err := NewRepoRedirect(oldOwnerID, repo.ID, repo.Name, repo.Name); err != nil {
+		return fmt.Errorf("NewRepoRedirect: %v", err)
+	}
+
+	for _, team := range transfer.Teams {
+		if err := team.AddRepository(repo); err != nil {
+			return fmt.Errorf("AddRepository: %v", err)
+		}
+	}
+	return nil
+}
+
+// GetUserRepoPermissionWithTransfer returns the permissions of the user to the
+// repository and its pending transfer, if any. The users who can accept the
+// transfer may read the repository to decide whether to accept it.
+func GetUserRepoPermissionWithTransfer(repo *Repository, user *User) (Permission, *RepoTransfer, error) {
+	perm, err := GetUserRepoPermission(repo, user)
+	if err != nil {
+		return perm, nil, err
+	}
+	transfer, err := GetPendingRepositoryTransfer(repo)
+	if err != nil {
+		if IsErrNoPendingRepoTransfer(err) {
+			return perm, nil, nil
+		}
+		return perm, nil, err
+	}
+
+	if user != nil && perm.AccessMode == AccessModeNone && transfer.CanUserAcceptTransfer(user) {
+		if err = repo.getUnits(x); err != nil {
+			return perm, nil, err
+		}
+		perm.AccessMode = AccessModeRead
+		perm.Units = repo.Units
+		perm.UnitsMode = nil
+	}
+	return perm, transfer, nil
+}
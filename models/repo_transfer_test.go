@@ -0,0 +1,70 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepositoryTransfer(t *testing.T) {
+	PrepareTestEnv(t)
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	recipient := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	_, err := GetPendingRepositoryTransfer(repo)
+	assert.True(t, IsErrNoPendingRepoTransfer(err))
+
+	transferred, err := StartRepositoryTransfer(doer, recipient, repo, nil)
+	assert.NoError(t, err)
+	assert.False(t, transferred)
+	AssertExistsAndLoadBean(t, &Repository{ID: 1, OwnerID: 2})
+
+	transfer, err := GetPendingRepositoryTransfer(repo)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, transfer.RecipientID)
+	assert.True(t, transfer.CanUserAcceptTransfer(recipient))
+	assert.False(t, transfer.CanUserAcceptTransfer(doer))
+
+	_, err = StartRepositoryTransfer(doer, recipient, repo, nil)
+	assert.True(t, IsErrRepoTransferInProgress(err))
+
+	// The recipient may read the repository to decide whether to accept it
+	perm, transfer, err := GetUserRepoPermissionWithTransfer(repo, recipient)
+	assert.NoError(t, err)
+	assert.NotNil(t, transfer)
+	assert.True(t, perm.CanRead(UnitTypeCode))
+	assert.False(t, perm.CanWrite(UnitTypeCode))
+
+	assert.NoError(t, CancelRepositoryTransfer(repo))
+	_, err = GetPendingRepositoryTransfer(repo)
+	assert.True(t, IsErrNoPendingRepoTransfer(err))
+
+	transferred, err = StartRepositoryTransfer(doer, recipient, repo, nil)
+	assert.NoError(t, err)
+	assert.False(t, transferred)
+	assert.NoError(t, AcceptRepositoryTransfer(repo))
+	AssertExistsAndLoadBean(t, &Repository{ID: 1, OwnerID: 4})
+	AssertNotExistsBean(t, &RepoTransfer{RepoID: 1})
+	CheckConsistencyFor(t, &Repository{}, &User{})
+}
+
+func TestRepositoryTransfer_ToOwnedOrganization(t *testing.T) {
+	PrepareTestEnv(t)
+
+	// user2 owns the organization org3, the transfer is immediate
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	org := AssertExistsAndLoadBean(t, &User{ID: 3}).(*User)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	transferred, err := StartRepositoryTransfer(doer, org, repo, nil)
+	assert.NoError(t, err)
+	assert.True(t, transferred)
+	AssertExistsAndLoadBean(t, &Repository{ID: 1, OwnerID: 3})
+	AssertNotExistsBean(t, &RepoTransfer{RepoID: 1})
+}
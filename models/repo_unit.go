@@ -93,6 +93,26 @@ type PullRequestsConfig struct {
 	AllowRebase               bool
 	AllowRebaseMerge          bool
 	AllowSquash               bool
+	AllowFastForwardOnly      bool
+	CancelAutoMergeOnPush     bool
+	// Templates of the default commit messages, see expandMergeMessageTemplate
+	DefaultMergeMessageTemplate  string
+	DefaultSquashMessageTemplate string
+	// Size limits of the pull requests, zero means unlimited
+	MaxChangedFiles int
+	MaxChangedLines int
+	// Oversized pull requests can not be merged instead of showing a warning
+	BlockOversizedMerge bool
+	// Rules of the commit messages, see LintCommitMessage
+	CommitMessageSubjectPattern  string
+	CommitMessageMaxLineLength   int
+	CommitMessageRequireIssueRef bool
+	// Automatic assignment of reviewers from a team of the owner organization,
+	// see AssignTeamReviewers
+	ReviewerTeamID               int64
+	ReviewerAssignmentStrategy   ReviewerAssignmentStrategy
+	ReviewerCount                int
+	ReviewerAssignmentSkipDrafts bool
 }
 
 // FromDB fills up a PullRequestsConfig from serialized format.
@@ -110,7 +130,8 @@ func (cfg *PullRequestsConfig) IsMergeStyleAllowed(mergeStyle MergeStyle) bool {
 	return mergeStyle == MergeStyleMerge && cfg.AllowMerge ||
 		mergeStyle == MergeStyleRebase && cfg.AllowRebase ||
 		mergeStyle == MergeStyleRebaseMerge && cfg.AllowRebaseMerge ||
-		mergeStyle == MergeStyleSquash && cfg.AllowSquash
+		mergeStyle == MergeStyleSquash && cfg.AllowSquash ||
+		mergeStyle == MergeStyleFastForwardOnly && cfg.AllowFastForwardOnly
 }
 
 // BeforeSet is invoked from XORM before setting the value of a field of this object.
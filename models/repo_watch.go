@@ -6,11 +6,85 @@ package models
 
 import "fmt"
 
+// RepoWatchMode specifies what a watcher of a repository gets notified of
+type RepoWatchMode int8
+
+const (
+	// RepoWatchModeNone the user is not watching the repository
+	RepoWatchModeNone RepoWatchMode = iota // 0
+	// RepoWatchModeAll the user is notified of all activity
+	RepoWatchModeAll // 1
+	// RepoWatchModeParticipating the user is only notified of issues participated or mentioned in
+	RepoWatchModeParticipating // 2
+	// RepoWatchModeIssues the user is only notified of new issues
+	RepoWatchModeIssues // 3
+	// RepoWatchModeReleases the user is only notified of new releases
+	RepoWatchModeReleases // 4
+)
+
+// RepoWatchModes are the modes a repository can be watched in
+var RepoWatchModes = []RepoWatchMode{
+	RepoWatchModeAll,
+	RepoWatchModeParticipating,
+	RepoWatchModeIssues,
+	RepoWatchModeReleases,
+}
+
+var repoWatchModeNames = map[RepoWatchMode]string{
+	RepoWatchModeNone:          "none",
+	RepoWatchModeAll:           "all",
+	RepoWatchModeParticipating: "participating",
+	RepoWatchModeIssues:        "issues",
+	RepoWatchModeReleases:      "releases",
+}
+
+// String returns the name of the watch mode
+func (mode RepoWatchMode) String() string {
+	return repoWatchModeNames[mode]
+}
+
+// ParseRepoWatchMode returns the watch mode with the given name
+func ParseRepoWatchMode(name string) (RepoWatchMode, bool) {
+	for mode, modeName := range repoWatchModeNames {
+		if modeName == name {
+			return mode, true
+		}
+	}
+	return RepoWatchModeNone, false
+}
+
 // Watch is connection request for receiving repository notification.
 type Watch struct {
-	ID     int64 `xorm:"pk autoincr"`
-	UserID int64 `xorm:"UNIQUE(watch)"`
-	RepoID int64 `xorm:"UNIQUE(watch)"`
+	ID     int64         `xorm:"pk autoincr"`
+	UserID int64         `xorm:"UNIQUE(watch)"`
+	RepoID int64         `xorm:"UNIQUE(watch)"`
+	Mode   RepoWatchMode `xorm:"SMALLINT NOT NULL DEFAULT 1"`
+}
+
+// watchModesOfAction returns the watch modes which include notifications
+// of the given action.
+func watchModesOfAction(opType ActionType) []RepoWatchMode {
+	switch opType {
+	case ActionCreateIssue:
+		return []RepoWatchMode{RepoWatchModeAll, RepoWatchModeIssues}
+	case ActionPushTag:
+		return []RepoWatchMode{RepoWatchModeAll, RepoWatchModeReleases}
+	}
+	return []RepoWatchMode{RepoWatchModeAll}
+}
+
+func getWatchMode(e Engine, userID, repoID int64) RepoWatchMode {
+	watch := new(Watch)
+	has, _ := e.Where("user_id=? AND repo_id=?", userID, repoID).Get(watch)
+	if !has {
+		return RepoWatchModeNone
+	}
+	return watch.Mode
+}
+
+// GetWatchMode returns how the user is watching the given repository.
+func GetWatchMode(userID, repoID int64) RepoWatchMode {
+	return getWatchMode(x, userID, repoID)
 }
 
 func isWatching(e Engine, userID, repoID int64) bool {
@@ -23,44 +97,67 @@ func IsWatching(userID, repoID int64) bool {
 	return isWatching(x, userID, repoID)
 }
 
-func watchRepo(e Engine, userID, repoID int64, watch bool) (err error) {
-	if watch {
-		if isWatching(e, userID, repoID) {
-			return nil
-		}
-		if _, err = e.Insert(&Watch{RepoID: repoID, UserID: userID}); err != nil {
+func watchRepoMode(e Engine, userID, repoID int64, mode RepoWatchMode) (err error) {
+	oldMode := getWatchMode(e, userID, repoID)
+	if oldMode == mode {
+		return nil
+	}
+
+	switch {
+	case oldMode == RepoWatchModeNone:
+		if _, err = e.Insert(&Watch{RepoID: repoID, UserID: userID, Mode: mode}); err != nil {
 			return err
 		}
 		_, err = e.Exec("UPDATE `repository` SET num_watches = num_watches + 1 WHERE id = ?", repoID)
-	} else {
-		if !isWatching(e, userID, repoID) {
-			return nil
-		}
-		if _, err = e.Delete(&Watch{0, userID, repoID}); err != nil {
+	case mode == RepoWatchModeNone:
+		if _, err = e.Delete(&Watch{UserID: userID, RepoID: repoID}); err != nil {
 			return err
 		}
 		_, err = e.Exec("UPDATE `repository` SET num_watches = num_watches - 1 WHERE id = ?", repoID)
+	default:
+		_, err = e.Where("user_id=? AND repo_id=?", userID, repoID).Cols("mode").Update(&Watch{Mode: mode})
 	}
 	return err
 }
 
-// WatchRepo watch or unwatch repository.
+// WatchRepoMode sets how the user is watching a repository, RepoWatchModeNone
+// unwatches it.
+func WatchRepoMode(userID, repoID int64, mode RepoWatchMode) error {
+	return watchRepoMode(x, userID, repoID, mode)
+}
+
+func watchRepo(e Engine, userID, repoID int64, watch bool) (err error) {
+	if watch {
+		if isWatching(e, userID, repoID) {
+			return nil
+		}
+		return watchRepoMode(e, userID, repoID, RepoWatchModeAll)
+	}
+	return watchRepoMode(e, userID, repoID, RepoWatchModeNone)
+}
+
+// WatchRepo watch or unwatch repository. A repository already watched keeps
+// its watch mode.
 func WatchRepo(userID, repoID int64, watch bool) (err error) {
 	return watchRepo(x, userID, repoID, watch)
 }
 
-func getWatchers(e Engine, repoID int64) ([]*Watch, error) {
+func getWatchers(e Engine, repoID int64, modes ...RepoWatchMode) ([]*Watch, error) {
 	watches := make([]*Watch, 0, 10)
-	return watches, e.Where("`watch`.repo_id=?", repoID).
+	sess := e.Where("`watch`.repo_id=?", repoID).
 		And("`user`.is_active=?", true).
 		And("`user`.prohibit_login=?", false).
-		Join("INNER", "`user`", "`user`.id = `watch`.user_id").
-		Find(&watches)
+		Join("INNER", "`user`", "`user`.id = `watch`.user_id")
+	if len(modes) > 0 {
+		sess.In("`watch`.mode", modes)
+	}
+	return watches, sess.Find(&watches)
 }
 
-// GetWatchers returns all watchers of given repository.
-func GetWatchers(repoID int64) ([]*Watch, error) {
-	return getWatchers(x, repoID)
+// GetWatchers returns all watchers of given repository, if modes are given
+// only the ones watching it in one of these modes.
+func GetWatchers(repoID int64, modes ...RepoWatchMode) ([]*Watch, error) {
+	return getWatchers(x, repoID, modes...)
 }
 
 // GetWatchers returns range of users watching given repository.
@@ -75,8 +172,8 @@ func (repo *Repository) GetWatchers(page int) ([]*User, error) {
 }
 
 func notifyWatchers(e Engine, act *Action) error {
-	// Add feeds for user self and all watchers.
-	watches, err := getWatchers(e, act.RepoID)
+	// Add feeds for user self and all watchers interested in the action.
+	watches, err := getWatchers(e, act.RepoID, watchModesOfAction(act.OpType)...)
 	if err != nil {
 		return fmt.Errorf("get watchers: %v", err)
 	}
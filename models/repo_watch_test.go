@@ -34,6 +34,40 @@ func TestWatchRepo(t *testing.T) {
 	CheckConsistencyFor(t, &Repository{ID: repoID})
 }
 
+func TestWatchRepoMode(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	const repoID = 3
+	const userID = 2
+
+	assert.NoError(t, WatchRepoMode(userID, repoID, RepoWatchModeIssues))
+	AssertExistsAndLoadBean(t, &Watch{RepoID: repoID, UserID: userID, Mode: RepoWatchModeIssues})
+	assert.Equal(t, RepoWatchModeIssues, GetWatchMode(userID, repoID))
+	CheckConsistencyFor(t, &Repository{ID: repoID})
+
+	// watching a watched repository keeps its mode
+	assert.NoError(t, WatchRepo(userID, repoID, true))
+	assert.Equal(t, RepoWatchModeIssues, GetWatchMode(userID, repoID))
+
+	assert.NoError(t, WatchRepoMode(userID, repoID, RepoWatchModeReleases))
+	assert.Equal(t, RepoWatchModeReleases, GetWatchMode(userID, repoID))
+	CheckConsistencyFor(t, &Repository{ID: repoID})
+
+	assert.NoError(t, WatchRepoMode(userID, repoID, RepoWatchModeNone))
+	AssertNotExistsBean(t, &Watch{RepoID: repoID, UserID: userID})
+	assert.Equal(t, RepoWatchModeNone, GetWatchMode(userID, repoID))
+	CheckConsistencyFor(t, &Repository{ID: repoID})
+}
+
+func TestParseRepoWatchMode(t *testing.T) {
+	for _, mode := range RepoWatchModes {
+		parsed, ok := ParseRepoWatchMode(mode.String())
+		assert.True(t, ok)
+		assert.Equal(t, mode, parsed)
+	}
+	_, ok := ParseRepoWatchMode("invalid")
+	assert.False(t, ok)
+}
+
 func TestGetWatchers(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 
@@ -49,6 +83,16 @@ func TestGetWatchers(t *testing.T) {
 	watches, err = GetWatchers(NonexistentID)
 	assert.NoError(t, err)
 	assert.Len(t, watches, 0)
+
+	assert.NoError(t, WatchRepoMode(4, repo.ID, RepoWatchModeReleases))
+	watches, err = GetWatchers(repo.ID, watchModesOfAction(ActionCreateIssue)...)
+	assert.NoError(t, err)
+	if assert.Len(t, watches, 1) {
+		assert.EqualValues(t, 1, watches[0].UserID)
+	}
+	watches, err = GetWatchers(repo.ID, watchModesOfAction(ActionPushTag)...)
+	assert.NoError(t, err)
+	assert.Len(t, watches, 2)
 }
 
 func TestRepository_GetWatchers(t *testing.T) {
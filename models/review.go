@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
 
 	"github.com/go-xorm/xorm"
@@ -30,6 +31,8 @@ const (
 	ReviewTypeComment
 	// ReviewTypeReject gives feedback blocking merge
 	ReviewTypeReject
+	// ReviewTypeRequest requests a review from the reviewer
+	ReviewTypeRequest
 )
 
 // Icon returns the corresponding icon for the review type
@@ -39,6 +42,8 @@ func (rt ReviewType) Icon() string {
 		return "eye"
 	case ReviewTypeReject:
 		return "x"
+	case ReviewTypeRequest:
+		return "primitive-dot"
 	case ReviewTypeComment, ReviewTypeUnknown:
 		return "comment"
 	default:
@@ -55,6 +60,8 @@ type Review struct {
 	Issue      *Issue `xorm:"-"`
 	IssueID    int64  `xorm:"index"`
 	Content    string
+	// Dismissed is true if the approval no longer counts, e.g. because new commits have been pushed
+	Dismissed bool `xorm:"NOT NULL DEFAULT false"`
 
 	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
@@ -73,6 +80,30 @@ func (r *Review) LoadCodeComments() error {
 	return r.loadCodeComments(x)
 }
 
+// GetCodeComments returns all the code comments of the review
+func (r *Review) GetCodeComments() ([]*Comment, error) {
+	comments, err := findComments(x, FindCommentsOptions{
+		Type:     CommentTypeCode,
+		ReviewID: r.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = CommentList(comments).loadPosters(x); err != nil {
+		return nil, err
+	}
+	for _, comment := range comments {
+		comment.Review = r
+		if r.Issue != nil {
+			comment.Issue = r.Issue
+		}
+		if err = comment.loadResolveDoer(x); err != nil {
+			return nil, err
+		}
+	}
+	return comments, nil
+}
+
 func (r *Review) loadIssue(e Engine) (err error) {
 	r.Issue, err = getIssueByID(e, r.IssueID)
 	return
@@ -86,6 +117,11 @@ func (r *Review) loadReviewer(e Engine) (err error) {
 	return
 }
 
+// LoadReviewer loads reviewer
+func (r *Review) LoadReviewer() error {
+	return r.loadReviewer(x)
+}
+
 func (r *Review) loadAttributes(e Engine) (err error) {
 	if err = r.loadReviewer(e); err != nil {
 		return
@@ -275,11 +311,81 @@ func UpdateReview(r *Review) error {
 	return nil
 }
 
+// AddReviewRequest requests a review of the pull request from the reviewer,
+// nothing happens if the review of the reviewer has already been requested.
+func AddReviewRequest(issue *Issue, reviewer, doer *User) (*Comment, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	comment, err := addReviewRequest(sess, issue, reviewer, doer)
+	if err != nil {
+		return nil, err
+	}
+	return comment, sess.Commit()
+}
+
+// GetLatestReviewByReviewer returns the latest approval, rejection or review
+// request of the reviewer for the pull request, only this review counts.
+func GetLatestReviewByReviewer(issueID, reviewerID int64) (*Review, error) {
+	return getLatestReviewByReviewer(x, issueID, reviewerID)
+}
+
+func getLatestReviewByReviewer(e Engine, issueID, reviewerID int64) (*Review, error) {
+	latest := new(Review)
+	has, err := e.Where("issue_id = ? AND reviewer_id = ? AND type IN (?, ?, ?)",
+		issueID, reviewerID, ReviewTypeApprove, ReviewTypeReject, ReviewTypeRequest).
+		Desc("updated_unix").
+		Desc("id").
+		Get(latest)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrReviewNotExist{}
+	}
+	return latest, nil
+}
+
+func addReviewRequest(e *xorm.Session, issue *Issue, reviewer, doer *User) (*Comment, error) {
+	// Only the latest review of the reviewer counts
+	latest, err := getLatestReviewByReviewer(e, issue.ID, reviewer.ID)
+	if err != nil && !IsErrReviewNotExist(err) {
+		return nil, err
+	} else if err == nil && latest.Type == ReviewTypeRequest {
+		return nil, nil
+	}
+
+	review, err := createReview(e, CreateReviewOptions{
+		Type:     ReviewTypeRequest,
+		Issue:    issue,
+		Reviewer: reviewer,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = issue.loadRepo(e); err != nil {
+		return nil, err
+	}
+	return createComment(e, &CreateCommentOptions{
+		Type:       CommentTypeReviewRequest,
+		Doer:       doer,
+		Repo:       issue.Repo,
+		Issue:      issue,
+		AssigneeID: reviewer.ID,
+		ReviewID:   review.ID,
+	})
+}
+
 // PullReviewersWithType represents the type used to display a review overview
 type PullReviewersWithType struct {
 	User              `xorm:"extends"`
 	Type              ReviewType
 	ReviewUpdatedUnix timeutil.TimeStamp `xorm:"review_updated_unix"`
+	// Dismissed is true if the latest review of the reviewer is a dismissed approval
+	Dismissed bool `xorm:"-"`
 }
 
 // GetReviewersByPullID gets all reviewers for a pull request with the statuses
@@ -288,17 +394,17 @@ func GetReviewersByPullID(pullID int64) (issueReviewers []*PullReviewersWithType
 	if x.Dialect().DBType() == core.MSSQL {
 		err = x.SQL(`SELECT [user].*, review.type, review.review_updated_unix FROM
 (SELECT review.id, review.type, review.reviewer_id, max(review.updated_unix) as review_updated_unix
-FROM review WHERE review.issue_id=? AND (review.type = ? OR review.type = ?)
+FROM review WHERE review.issue_id=? AND (review.type = ? OR review.type = ? OR review.type = ?)
 GROUP BY review.id, review.type, review.reviewer_id) as review
 INNER JOIN [user] ON review.reviewer_id = [user].id ORDER BY review_updated_unix DESC`,
-			pullID, ReviewTypeApprove, ReviewTypeReject).
+			pullID, ReviewTypeApprove, ReviewTypeReject, ReviewTypeRequest).
 			Find(&irs)
 	} else {
 		err = x.Select("`user`.*, review.type, max(review.updated_unix) as review_updated_unix").
 			Table("review").
 			Join("INNER", "`user`", "review.reviewer_id = `user`.id").
-			Where("review.issue_id = ? AND (review.type = ? OR review.type = ?)",
-				pullID, ReviewTypeApprove, ReviewTypeReject).
+			Where("review.issue_id = ? AND (review.type = ? OR review.type = ? OR review.type = ?)",
+				pullID, ReviewTypeApprove, ReviewTypeReject, ReviewTypeRequest).
 			GroupBy("`user`.id, review.type").
 			OrderBy("review_updated_unix DESC").
 			Find(&irs)
@@ -316,5 +422,202 @@ INNER JOIN [user] ON review.reviewer_id = [user].id ORDER BY review_updated_unix
 		}
 	}
 
+	// Dismissed approvals are still shown, but no longer count
+	dismissed := make([]*Review, 0, len(issueReviewers))
+	if err = x.Where("issue_id = ? AND type = ? AND dismissed = ?", pullID, ReviewTypeApprove, true).
+		Find(&dismissed); err != nil {
+		return nil, err
+	}
+	for _, ir := range issueReviewers {
+		if ir.Type != ReviewTypeApprove {
+			continue
+		}
+		for _, review := range dismissed {
+			if review.ReviewerID == ir.ID && review.UpdatedUnix >= ir.ReviewUpdatedUnix {
+				ir.Dismissed = true
+				break
+			}
+		}
+	}
 	return
 }
+
+// DismissStaleApprovals dismisses the approvals of the pull request because
+// new commits have been pushed by the doer. Every reviewer whose latest review
+// is a dismissed approval gets a timeline event.
+func DismissStaleApprovals(issue *Issue, doer *User) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	approvals := make([]*Review, 0, 5)
+	if err := sess.Where("issue_id = ? AND type = ? AND dismissed = ?", issue.ID, ReviewTypeApprove, false).
+		Find(&approvals); err != nil {
+		return err
+	}
+	if len(approvals) == 0 {
+		return nil
+	}
+
+	if err := issue.loadRepo(sess); err != nil {
+		return err
+	}
+	for _, approval := range approvals {
+		latest, err := getLatestReviewByReviewer(sess, issue.ID, approval.ReviewerID)
+		if err != nil {
+			return err
+		}
+		// The order of the reviews must not change
+		approval.Dismissed = true
+		if _, err = sess.ID(approval.ID).Cols("dismissed").NoAutoTime().Update(approval); err != nil {
+			return err
+		}
+		if latest.ID != approval.ID {
+			continue
+		}
+		if _, err = createComment(sess, &CreateCommentOptions{
+			Type:       CommentTypeDismissReview,
+			Doer:       doer,
+			Repo:       issue.Repo,
+			Issue:      issue,
+			AssigneeID: approval.ReviewerID,
+			ReviewID:   approval.ID,
+		}); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+// dismissStaleApprovalsOnPush dismisses the approvals of the pull request if
+// the protected base branch is configured to do so.
+func (pr *PullRequest) dismissStaleApprovalsOnPush(doer *User) {
+	if err := pr.LoadProtectedBranch(); err != nil {
+		log.Error("LoadProtectedBranch: %v", err)
+		return
+	}
+	if pr.ProtectedBranch == nil || !pr.ProtectedBranch.DismissStaleApprovals {
+		return
+	}
+	if err := pr.LoadIssue(); err != nil {
+		log.Error("LoadIssue: %v", err)
+		return
+	}
+	if err := DismissStaleApprovals(pr.Issue, doer); err != nil {
+		log.Error("DismissStaleApprovals[%d]: %v", pr.ID, err)
+	}
+}
+
+// APIState returns the api.ReviewStateType of the review type
+func (rt ReviewType) APIState() api.ReviewStateType {
+	switch rt {
+	case ReviewTypePending:
+		return api.ReviewStatePending
+	case ReviewTypeApprove:
+		return api.ReviewStateApproved
+	case ReviewTypeComment:
+		return api.ReviewStateComment
+	case ReviewTypeReject:
+		return api.ReviewStateRequestChanges
+	case ReviewTypeRequest:
+		return api.ReviewStateRequestReview
+	default:
+		return api.ReviewStateUnknown
+	}
+}
+
+// ReviewTypeFromAPIState returns the review type of an api.ReviewStateType,
+// ReviewTypeUnknown is returned for unsupported states.
+func ReviewTypeFromAPIState(state api.ReviewStateType) ReviewType {
+	switch state {
+	case api.ReviewStatePending:
+		return ReviewTypePending
+	case api.ReviewStateApproved:
+		return ReviewTypeApprove
+	case api.ReviewStateComment:
+		return ReviewTypeComment
+	case api.ReviewStateRequestChanges:
+		return ReviewTypeReject
+	default:
+		return ReviewTypeUnknown
+	}
+}
+
+// APIFormat converts a Review to the api.PullReview format,
+// the attributes have to be loaded before.
+func (r *Review) APIFormat() (*api.PullReview, error) {
+	codeComments, err := x.Count(&Comment{ReviewID: r.ID, Type: CommentTypeCode})
+	if err != nil {
+		return nil, err
+	}
+	apiReview := &api.PullReview{
+		ID:             r.ID,
+		State:          r.Type.APIState(),
+		Body:           r.Content,
+		CodeComments:   codeComments,
+		HTMLURL:        r.Issue.HTMLURL(),
+		PullRequestURL: r.Issue.APIURL(),
+		Dismissed:      r.Dismissed,
+		Submitted:      r.UpdatedUnix.AsTime(),
+	}
+	if r.Reviewer != nil {
+		apiReview.Reviewer = r.Reviewer.APIFormat()
+	} else {
+		apiReview.Reviewer = NewGhostUser().APIFormat()
+	}
+	return apiReview, nil
+}
+
+// ReviewSummary represents the latest verdicts of the reviewers of a pull request
+type ReviewSummary struct {
+	Approvals        int
+	ChangesRequested int
+}
+
+// GetReviewSummaries returns the review summaries of the given pull request
+// issues mapped by issue ID, only the latest verdict of each reviewer counts.
+func GetReviewSummaries(issueIDs []int64) (map[int64]*ReviewSummary, error) {
+	summaries := make(map[int64]*ReviewSummary, len(issueIDs))
+	if len(issueIDs) == 0 {
+		return summaries, nil
+	}
+
+	reviews := make([]*Review, 0, len(issueIDs))
+	if err := x.In("issue_id", issueIDs).
+		In("type", ReviewTypeApprove, ReviewTypeReject, ReviewTypeRequest).
+		Desc("updated_unix").
+		Desc("id").
+		Find(&reviews); err != nil {
+		return nil, err
+	}
+
+	type reviewerKey struct {
+		IssueID    int64
+		ReviewerID int64
+	}
+	seen := make(map[reviewerKey]bool, len(reviews))
+	for _, review := range reviews {
+		key := reviewerKey{review.IssueID, review.ReviewerID}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if review.Type == ReviewTypeRequest || review.Dismissed {
+			continue
+		}
+
+		summary, ok := summaries[review.IssueID]
+		if !ok {
+			summary = &ReviewSummary{}
+			summaries[review.IssueID] = summary
+		}
+		if review.Type == ReviewTypeApprove {
+			summary.Approvals++
+		} else {
+			summary.ChangesRequested++
+		}
+	}
+	return summaries, nil
+}
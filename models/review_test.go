@@ -3,6 +3,8 @@ package models
 import (
 	"testing"
 
+	api "code.gitea.io/gitea/modules/structs"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -48,7 +50,8 @@ func TestReviewType_Icon(t *testing.T) {
 	assert.Equal(t, "x", ReviewTypeReject.Icon())
 	assert.Equal(t, "comment", ReviewTypeComment.Icon())
 	assert.Equal(t, "comment", ReviewTypeUnknown.Icon())
-	assert.Equal(t, "comment", ReviewType(4).Icon())
+	assert.Equal(t, "primitive-dot", ReviewTypeRequest.Icon())
+	assert.Equal(t, "comment", ReviewType(5).Icon())
 }
 
 func TestFindReviews(t *testing.T) {
@@ -113,6 +116,7 @@ func TestGetReviewersByPullID(t *testing.T) {
 	user2 := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
 	user3 := AssertExistsAndLoadBean(t, &User{ID: 3}).(*User)
 	user4 := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	user5 := AssertExistsAndLoadBean(t, &User{ID: 5}).(*User)
 
 	expectedReviews := []*PullReviewersWithType{}
 	expectedReviews = append(expectedReviews, &PullReviewersWithType{
@@ -129,9 +133,119 @@ func TestGetReviewersByPullID(t *testing.T) {
 			User:              *user4,
 			Type:              ReviewTypeApprove,
 			ReviewUpdatedUnix: 946684810,
+		},
+		&PullReviewersWithType{
+			User:              *user5,
+			Type:              ReviewTypeRequest,
+			ReviewUpdatedUnix: 946684810,
 		})
 
 	allReviews, err := GetReviewersByPullID(issue.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedReviews, allReviews)
 }
+
+func TestGetReviewSummaries(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	summaries, err := GetReviewSummaries([]int64{2, 3, 1})
+	assert.NoError(t, err)
+	assert.Len(t, summaries, 2)
+	assert.Equal(t, &ReviewSummary{Approvals: 1}, summaries[2])
+	assert.Equal(t, &ReviewSummary{Approvals: 1, ChangesRequested: 3}, summaries[3])
+	assert.Nil(t, summaries[1])
+}
+
+func TestReview_APIFormat(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	review, err := GetReviewByID(1)
+	assert.NoError(t, err)
+	assert.NoError(t, review.LoadAttributes())
+	assert.NoError(t, review.Issue.LoadRepo())
+
+	apiReview, err := review.APIFormat()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, apiReview.ID)
+	assert.EqualValues(t, 1, apiReview.Reviewer.ID)
+	assert.Equal(t, api.ReviewStateApproved, apiReview.State)
+	assert.Equal(t, "Demo Review", apiReview.Body)
+	assert.Equal(t, review.Issue.HTMLURL(), apiReview.HTMLURL)
+	assert.Equal(t, ReviewTypeApprove, ReviewTypeFromAPIState(apiReview.State))
+}
+
+func TestAddReviewRequest(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 2}).(*Issue)
+	reviewer := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+
+	comment, err := AddReviewRequest(issue, reviewer, doer)
+	assert.NoError(t, err)
+	assert.NotNil(t, comment)
+	assert.Equal(t, CommentTypeReviewRequest, comment.Type)
+	assert.EqualValues(t, 4, comment.AssigneeID)
+	review := AssertExistsAndLoadBean(t, &Review{ID: comment.ReviewID, IssueID: 2, ReviewerID: 4}).(*Review)
+	assert.Equal(t, ReviewTypeRequest, review.Type)
+
+	// The review has already been requested
+	comment, err = AddReviewRequest(issue, reviewer, doer)
+	assert.NoError(t, err)
+	assert.Nil(t, comment)
+
+	reviewers, err := GetReviewersByPullID(issue.ID)
+	assert.NoError(t, err)
+	for _, r := range reviewers {
+		if r.ID == 4 {
+			assert.Equal(t, ReviewTypeRequest, r.Type)
+		}
+	}
+}
+
+func TestGetLatestReviewByReviewer(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	review, err := GetLatestReviewByReviewer(3, 3)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 7, review.ID)
+	assert.Equal(t, ReviewTypeReject, review.Type)
+
+	// Comments are ignored
+	_, err = GetLatestReviewByReviewer(3, 1)
+	assert.True(t, IsErrReviewNotExist(err))
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 3}).(*Issue)
+	reviewer := AssertExistsAndLoadBean(t, &User{ID: 3}).(*User)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	_, err = AddReviewRequest(issue, reviewer, doer)
+	assert.NoError(t, err)
+
+	review, err = GetLatestReviewByReviewer(3, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, ReviewTypeRequest, review.Type)
+}
+
+func TestDismissStaleApprovals(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 3}).(*Issue)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+
+	assert.NoError(t, DismissStaleApprovals(issue, doer))
+	review := AssertExistsAndLoadBean(t, &Review{ID: 8}).(*Review)
+	assert.True(t, review.Dismissed)
+	AssertExistsAndLoadBean(t, &Comment{Type: CommentTypeDismissReview, IssueID: 3, PosterID: 1, AssigneeID: 4, ReviewID: 8})
+
+	reviewers, err := GetReviewersByPullID(issue.ID)
+	assert.NoError(t, err)
+	for _, r := range reviewers {
+		assert.Equal(t, r.ID == 4, r.Dismissed)
+	}
+
+	// Dismissed approvals are not dismissed again
+	assert.NoError(t, DismissStaleApprovals(issue, doer))
+	count, err := x.Count(&Comment{Type: CommentTypeDismissReview, IssueID: 3})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+}
@@ -0,0 +1,189 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// sshSignatureMagic starts the content of SSH signatures and the data
+	// they sign
+	sshSignatureMagic = "SSHSIG"
+	// sshSignatureNamespace is the namespace of the signatures made by git
+	sshSignatureNamespace = "git"
+)
+
+// sshSignature represents a signature made by ssh-keygen -Y sign, as described
+// in https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.sshsig
+type sshSignature struct {
+	PublicKey     ssh.PublicKey
+	Namespace     string
+	HashAlgorithm string
+	Signature     *ssh.Signature
+}
+
+// isSSHSignature returns whether the armored signature is an SSH signature
+func isSSHSignature(s string) bool {
+	return strings.HasPrefix(strings.TrimSpace(s), "-----BEGIN SSH SIGNATURE-----")
+}
+
+// parseSSHSignature parses the armored SSH signature
+func parseSSHSignature(s string) (*sshSignature, error) {
+	block, _ := pem.Decode([]byte(strings.TrimSpace(s)))
+	if block == nil || block.Type != "SSH SIGNATURE" {
+		return nil, errors.New("failed to read signature armor")
+	}
+	if !bytes.HasPrefix(block.Bytes, []byte(sshSignatureMagic)) {
+		return nil, errors.New("invalid signature magic")
+	}
+
+	var blob struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      []byte
+		HashAlgorithm string
+		Signature     []byte
+	}
+	if err := ssh.Unmarshal(block.Bytes[len(sshSignatureMagic):], &blob); err != nil {
+		return nil, fmt.Errorf("failed to read signature: %v", err)
+	}
+	if blob.Version != 1 {
+		return nil, fmt.Errorf("unsupported signature version %d", blob.Version)
+	}
+	publicKey, err := ssh.ParsePublicKey(blob.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %v", err)
+	}
+	var signature struct {
+		Format string
+		Blob   []byte
+		Rest   []byte `ssh:"rest"`
+	}
+	if err = ssh.Unmarshal(blob.Signature, &signature); err != nil {
+		return nil, fmt.Errorf("failed to read signature blob: %v", err)
+	}
+
+	return &sshSignature{
+		PublicKey:     publicKey,
+		Namespace:     blob.Namespace,
+		HashAlgorithm: blob.HashAlgorithm,
+		Signature: &ssh.Signature{
+			Format: signature.Format,
+			Blob:   signature.Blob,
+		},
+	}, nil
+}
+
+// verify checks that the signature has been made on the payload by git with
+// its public key
+func (sig *sshSignature) verify(payload string) error {
+	if sig.Namespace != sshSignatureNamespace {
+		return fmt.Errorf("unexpected signature namespace %q", sig.Namespace)
+	}
+	var h hash.Hash
+	switch sig.HashAlgorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return fmt.Errorf("unsupported hash algorithm %q", sig.HashAlgorithm)
+	}
+	if _, err := h.Write([]byte(payload)); err != nil {
+		return err
+	}
+
+	signed := ssh.Marshal(struct {
+		Namespace     string
+		Reserved      []byte
+		HashAlgorithm string
+		Hash          []byte
+	}{
+		Namespace:     sig.Namespace,
+		HashAlgorithm: sig.HashAlgorithm,
+		Hash:          h.Sum(nil),
+	})
+	return sig.PublicKey.Verify(append([]byte(sshSignatureMagic), signed...), sig.Signature)
+}
+
+// verifySSHSignature checks the SSH signature of the payload made by the
+// signer, the committer or tagger of the signed object, against the SSH keys
+// of the users.
+func verifySSHSignature(repo *Repository, signature *git.CommitGPGSignature, signer *git.Signature) *CommitVerification {
+	sig, err := parseSSHSignature(signature.Signature)
+	if err != nil {
+		log.Error("parseSSHSignature: %v", err)
+		return &CommitVerification{
+			Verified: false,
+			Reason:   "gpg.error.extract_sign",
+		}
+	}
+
+	committer, err := GetUserByEmail(signer.Email)
+	if err != nil {
+		if !IsErrUserNotExist(err) {
+			log.Error("GetUserByEmail: %v", err)
+		}
+		committer = nil
+	}
+
+	fingerprint := ssh.FingerprintSHA256(sig.PublicKey)
+	keys := make([]*PublicKey, 0, 1)
+	if err = x.Where("fingerprint = ? AND type = ?", fingerprint, KeyTypeUser).Find(&keys); err != nil {
+		log.Error("Find SSH keys: %v", err)
+		return &CommitVerification{
+			Verified: false,
+			Reason:   "gpg.error.failed_retrieval_gpg_keys",
+		}
+	}
+	for _, key := range keys {
+		// The fingerprint of the keys added with ssh-keygen may be computed
+		// differently, so the keys themselves are compared
+		publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key.Content))
+		if err != nil || !bytes.Equal(publicKey.Marshal(), sig.PublicKey.Marshal()) {
+			continue
+		}
+		if err = sig.verify(signature.Payload); err != nil {
+			log.Debug("verify SSH signature with key %d: %v", key.ID, err)
+			return &CommitVerification{
+				Verified: false,
+				Reason:   "gpg.error.invalid_signature",
+			}
+		}
+		owner, err := GetUserByID(key.OwnerID)
+		if err != nil {
+			log.Error("GetUserByID[%d]: %v", key.OwnerID, err)
+			continue
+		}
+		verification := newCommitVerification(repo, owner, fingerprint, signer, committer != nil && committer.ID == owner.ID)
+		verification.SigningSSHKey = key
+		return verification
+	}
+
+	if committer == nil {
+		return &CommitVerification{
+			Verified: false,
+			Reason:   "gpg.error.no_committer_account",
+		}
+	}
+	return &CommitVerification{
+		Verified: false,
+		Reason:   "gpg.error.no_gpg_keys_found",
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSSHSignedPayload = `tree 444a8fa98e219b9ee8585973bba9425676aba452
+author User Two <user2@example.com> 1568239411 +0000
+committer User Two <user2@example.com> 1568239411 +0000
+
+Initial commit
+`
+
+const testSSHSignature = `-----BEGIN SSH SIGNATURE-----
+U1NIU0lHAAAAAQAAADMAAAALc3NoLWVkMjU1MTkAAAAgVPSMDrp05CnpxUpiWSL2ALHKYz
+3KgKuekYmXxfh2seYAAAADZ2l0AAAAAAAAAAZzaGE1MTIAAABTAAAAC3NzaC1lZDI1NTE5
+AAAAQDAniCZhVBeyQ5nAIJzLemhituo45JjxJjkxd0E9UtfOyr/5GljG9PRGV6CxdFHxbk
+QZSYGZLPIUH2FHDFyyJQE=
+-----END SSH SIGNATURE-----
+`
+
+func TestParseSSHSignature(t *testing.T) {
+	sig, err := parseSSHSignature(testSSHSignature)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "ssh-ed25519", sig.PublicKey.Type())
+	assert.EqualValues(t, "git", sig.Namespace)
+	assert.EqualValues(t, "sha512", sig.HashAlgorithm)
+	assert.NoError(t, sig.verify(testSSHSignedPayload))
+	assert.Error(t, sig.verify(testSSHSignedPayload+"\n"))
+
+	_, err = parseSSHSignature("-----BEGIN PGP SIGNATURE-----\n\n-----END PGP SIGNATURE-----\n")
+	assert.Error(t, err)
+}
+
+func TestVerifySSHSignature(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	signer := &git.Signature{Name: "User Two", Email: "user2@example.com"}
+	signature := &git.CommitGPGSignature{
+		Signature: testSSHSignature,
+		Payload:   testSSHSignedPayload,
+	}
+
+	verification := verifySignature(repo, signature, signer)
+	assert.False(t, verification.Verified)
+	assert.EqualValues(t, "gpg.error.no_gpg_keys_found", verification.Reason)
+
+	key := &PublicKey{
+		OwnerID:     2,
+		Name:        "signing key",
+		Fingerprint: "SHA256:PTxHjnk+QQtdul5+sXdFRDRfkocHE75Bxo09neXfdEc",
+		Content:     "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIFT0jA66dOQp6cVKYlki9gCxymM9yoCrnpGJl8X4drHm user2@example.com",
+		Mode:        AccessModeWrite,
+		Type:        KeyTypeUser,
+	}
+	_, err := x.Insert(key)
+	assert.NoError(t, err)
+
+	verification = verifySignature(repo, signature, signer)
+	assert.True(t, verification.IsTrusted())
+	assert.EqualValues(t, 2, verification.SigningUser.ID)
+	assert.EqualValues(t, key.ID, verification.SigningSSHKey.ID)
+	assert.Nil(t, verification.SigningKey)
+
+	// The key of user2 is not the one of the committer
+	verification = verifySignature(repo, signature, &git.Signature{Name: "User Four", Email: "user4@example.com"})
+	assert.True(t, verification.Verified)
+	assert.False(t, verification.IsTrusted())
+
+	signature.Payload += "\n"
+	verification = verifySignature(repo, signature, signer)
+	assert.False(t, verification.Verified)
+	assert.EqualValues(t, "gpg.error.invalid_signature", verification.Reason)
+}
@@ -25,6 +25,7 @@ type AccessToken struct {
 	TokenHash      string `xorm:"UNIQUE"` // sha256 of token
 	TokenSalt      string
 	TokenLastEight string `xorm:"token_last_eight"`
+	Scope          AccessTokenScope
 
 	CreatedUnix       timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix       timeutil.TimeStamp `xorm:"INDEX updated"`
@@ -40,6 +41,12 @@ func (t *AccessToken) AfterLoad() {
 
 // NewAccessToken creates new access token.
 func NewAccessToken(t *AccessToken) error {
+	scope, err := NewAccessTokenScope(t.Scope.Strings()...)
+	if err != nil {
+		return err
+	}
+	t.Scope = scope
+
 	salt, err := generate.GetRandomString(10)
 	if err != nil {
 		return err
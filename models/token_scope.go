@@ -0,0 +1,139 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strings"
+)
+
+// AccessTokenScope is a comma separated list of the scopes of an access token
+type AccessTokenScope string
+
+// The scopes of an access token
+const (
+	AccessTokenScopeAll AccessTokenScope = "all"
+
+	AccessTokenScopeReadRepo  AccessTokenScope = "read:repo"
+	AccessTokenScopeWriteRepo AccessTokenScope = "write:repo"
+
+	AccessTokenScopeReadIssue  AccessTokenScope = "read:issue"
+	AccessTokenScopeWriteIssue AccessTokenScope = "write:issue"
+
+	AccessTokenScopeReadOrg  AccessTokenScope = "read:org"
+	AccessTokenScopeWriteOrg AccessTokenScope = "write:org"
+	AccessTokenScopeAdminOrg AccessTokenScope = "admin:org"
+
+	AccessTokenScopeReadUser  AccessTokenScope = "read:user"
+	AccessTokenScopeWriteUser AccessTokenScope = "write:user"
+
+	AccessTokenScopeReadAdmin  AccessTokenScope = "read:admin"
+	AccessTokenScopeWriteAdmin AccessTokenScope = "write:admin"
+)
+
+// AccessTokenScopes lists all the scopes of an access token, in the order
+// they are displayed and stored
+var AccessTokenScopes = []AccessTokenScope{
+	AccessTokenScopeAll,
+	AccessTokenScopeReadRepo,
+	AccessTokenScopeWriteRepo,
+	AccessTokenScopeReadIssue,
+	AccessTokenScopeWriteIssue,
+	AccessTokenScopeReadOrg,
+	AccessTokenScopeWriteOrg,
+	AccessTokenScopeAdminOrg,
+	AccessTokenScopeReadUser,
+	AccessTokenScopeWriteUser,
+	AccessTokenScopeReadAdmin,
+	AccessTokenScopeWriteAdmin,
+}
+
+// impliedAccessTokenScopes maps the scopes to the other scopes they grant
+var impliedAccessTokenScopes = map[AccessTokenScope][]AccessTokenScope{
+	AccessTokenScopeAll:        AccessTokenScopes[1:],
+	AccessTokenScopeWriteRepo:  {AccessTokenScopeReadRepo},
+	AccessTokenScopeWriteIssue: {AccessTokenScopeReadIssue},
+	AccessTokenScopeWriteOrg:   {AccessTokenScopeReadOrg},
+	AccessTokenScopeAdminOrg:   {AccessTokenScopeWriteOrg, AccessTokenScopeReadOrg},
+	AccessTokenScopeWriteUser:  {AccessTokenScopeReadUser},
+	AccessTokenScopeWriteAdmin: {AccessTokenScopeReadAdmin},
+}
+
+// IsValidAccessTokenScope returns true if the scope is a known single scope
+func IsValidAccessTokenScope(scope string) bool {
+	for _, s := range AccessTokenScopes {
+		if string(s) == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// NewAccessTokenScope joins the given scopes, which are validated, and drops
+// the ones that are implied by the others
+func NewAccessTokenScope(scopes ...string) (AccessTokenScope, error) {
+	selected := make(map[AccessTokenScope]bool, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if len(scope) == 0 {
+			continue
+		}
+		if !IsValidAccessTokenScope(scope) {
+			return "", ErrInvalidAccessTokenScope{scope}
+		}
+		selected[AccessTokenScope(scope)] = true
+	}
+	for scope := range selected {
+		for _, implied := range impliedAccessTokenScopes[scope] {
+			delete(selected, implied)
+		}
+	}
+
+	var names []string
+	for _, scope := range AccessTokenScopes {
+		if selected[scope] {
+			names = append(names, string(scope))
+		}
+	}
+	return AccessTokenScope(strings.Join(names, ",")), nil
+}
+
+// Scopes returns the scopes which were explicitly given to the token
+func (s AccessTokenScope) Scopes() []AccessTokenScope {
+	if len(s) == 0 {
+		return nil
+	}
+	fields := strings.Split(string(s), ",")
+	scopes := make([]AccessTokenScope, 0, len(fields))
+	for _, field := range fields {
+		scopes = append(scopes, AccessTokenScope(strings.TrimSpace(field)))
+	}
+	return scopes
+}
+
+// Strings returns the scopes which were explicitly given to the token, as strings
+func (s AccessTokenScope) Strings() []string {
+	scopes := s.Scopes()
+	names := make([]string, len(scopes))
+	for i, scope := range scopes {
+		names[i] = string(scope)
+	}
+	return names
+}
+
+// Has returns true if the token has the given scope, directly or through
+// another scope which implies it
+func (s AccessTokenScope) Has(scope AccessTokenScope) bool {
+	for _, granted := range s.Scopes() {
+		if granted == scope {
+			return true
+		}
+		for _, implied := range impliedAccessTokenScopes[granted] {
+			if implied == scope {
+				return true
+			}
+		}
+	}
+	return false
+}
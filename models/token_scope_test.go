@@ -0,0 +1,46 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAccessTokenScope(t *testing.T) {
+	for _, c := range []struct {
+		scopes   []string
+		expected AccessTokenScope
+	}{
+		{nil, ""},
+		{[]string{"write:issue", "read:repo", "read:issue"}, "read:repo,write:issue"},
+		{[]string{"admin:org", "read:org", "write:org", " read:user "}, "admin:org,read:user"},
+		{[]string{"read:admin", "all", "write:repo"}, "all"},
+	} {
+		scope, err := NewAccessTokenScope(c.scopes...)
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, scope)
+	}
+
+	_, err := NewAccessTokenScope("read:repo", "write:everything")
+	assert.True(t, IsErrInvalidAccessTokenScope(err))
+}
+
+func TestAccessTokenScope_Has(t *testing.T) {
+	scope := AccessTokenScope("write:repo,admin:org")
+	assert.True(t, scope.Has(AccessTokenScopeReadRepo))
+	assert.True(t, scope.Has(AccessTokenScopeWriteRepo))
+	assert.True(t, scope.Has(AccessTokenScopeWriteOrg))
+	assert.True(t, scope.Has(AccessTokenScopeReadOrg))
+	assert.False(t, scope.Has(AccessTokenScopeReadIssue))
+	assert.False(t, scope.Has(AccessTokenScopeAll))
+
+	scope = AccessTokenScopeAll
+	for _, s := range AccessTokenScopes {
+		assert.True(t, scope.Has(s))
+	}
+	assert.False(t, AccessTokenScope("").Has(AccessTokenScopeReadUser))
+}
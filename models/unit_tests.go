@@ -16,6 +16,7 @@ import (
 
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 
 	"github.com/go-xorm/xorm"
 	"github.com/stretchr/testify/assert"
@@ -60,6 +61,20 @@ func MainTest(m *testing.M, pathToGiteaRoot string) {
 	}
 	setting.AppWorkPath = pathToGiteaRoot
 	setting.StaticRootPath = pathToGiteaRoot
+	setting.AttachmentPath = filepath.Join(setting.AppDataPath, "attachments")
+	setting.LFS.ContentPath = filepath.Join(setting.AppDataPath, "lfs")
+	setting.AvatarUploadPath = filepath.Join(setting.AppDataPath, "avatars")
+	setting.RepositoryAvatarUploadPath = filepath.Join(setting.AppDataPath, "repo-avatars")
+	setting.Repository.Upload.TempPath = filepath.Join(setting.AppDataPath, "tmp", "uploads")
+	setting.Indexer.IssuePath = filepath.Join(setting.AppDataPath, "indexers", "issues.bleve")
+	setting.Indexer.IssueQueueDir = filepath.Join(setting.AppDataPath, "indexers", "issues.queue")
+	setting.SSH.RootPath = filepath.Join(setting.AppDataPath, "ssh")
+	if err = os.MkdirAll(setting.SSH.RootPath, 0700); err != nil {
+		fatalTestError("MkdirAll: %v\n", err)
+	}
+	if err = storage.Init(); err != nil {
+		fatalTestError("storage.Init: %v\n", err)
+	}
 	setting.GravatarSourceURL, err = url.Parse("https://secure.gravatar.com/avatar/")
 	if err != nil {
 		fatalTestError("url.Parse: %v\n", err)
@@ -22,6 +22,10 @@ const (
 	EnvPusherName   = "GITEA_PUSHER_NAME"
 	EnvPusherEmail  = "GITEA_PUSHER_EMAIL"
 	EnvPusherID     = "GITEA_PUSHER_ID"
+	// EnvIsRestrictedPush is set if the pusher has no write access and can
+	// only push to the head branches of pull requests allowing edits from
+	// maintainers or create pull requests by pushing to refs/for/<branch>
+	EnvIsRestrictedPush = "GITEA_IS_RESTRICTED_PUSH"
 )
 
 // CommitToPushCommit transforms a git.Commit to PushCommit type.
@@ -6,6 +6,7 @@
 package models
 
 import (
+	"bytes"
 	"container/list"
 	"crypto/md5"
 	"crypto/sha256"
@@ -27,6 +28,7 @@ import (
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/structs"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
@@ -126,6 +128,11 @@ type User struct {
 	LastRepoVisibility bool
 	// Maximum repository creation limit, -1 means use global default
 	MaxRepoCreation int `xorm:"NOT NULL DEFAULT -1"`
+	// Quotas in bytes of the repositories, LFS objects and attachments, -1
+	// means use global default
+	MaxGitSize        int64 `xorm:"NOT NULL DEFAULT -1"`
+	MaxLFSSize        int64 `xorm:"NOT NULL DEFAULT -1"`
+	MaxAttachmentSize int64 `xorm:"NOT NULL DEFAULT -1"`
 
 	// Permissions
 	IsActive                bool `xorm:"INDEX"` // Activate primary email
@@ -154,6 +161,14 @@ type User struct {
 	MembersIsPublic map[int64]bool      `xorm:"-"`
 	Visibility      structs.VisibleType `xorm:"NOT NULL DEFAULT 0"`
 
+	// Defaults of the repositories created in an organization, see
+	// GetRepoCreationDefaults
+	RepoDefaultBranch     string `xorm:"NOT NULL DEFAULT ''"`
+	RepoDefaultVisibility string `xorm:"NOT NULL DEFAULT ''"`
+	RepoDefaultAutoInit   bool   `xorm:"NOT NULL DEFAULT false"`
+	RepoDefaultGitignores string `xorm:"NOT NULL DEFAULT ''"`
+	RepoDefaultLicense    string `xorm:"NOT NULL DEFAULT ''"`
+
 	// Preferences
 	DiffViewStyle string `xorm:"NOT NULL DEFAULT ''"`
 	Theme         string `xorm:"NOT NULL DEFAULT ''"`
@@ -171,6 +186,15 @@ func (u *User) BeforeUpdate() {
 	if u.MaxRepoCreation < -1 {
 		u.MaxRepoCreation = -1
 	}
+	if u.MaxGitSize < -1 {
+		u.MaxGitSize = -1
+	}
+	if u.MaxLFSSize < -1 {
+		u.MaxLFSSize = -1
+	}
+	if u.MaxAttachmentSize < -1 {
+		u.MaxAttachmentSize = -1
+	}
 
 	// Organization does not need email
 	u.Email = strings.ToLower(u.Email)
@@ -327,9 +351,19 @@ func (u *User) GenerateActivateCode() string {
 	return u.GenerateEmailActivateCode(u.Email)
 }
 
-// CustomAvatarPath returns user custom avatar file path.
-func (u *User) CustomAvatarPath() string {
-	return filepath.Join(setting.AvatarUploadPath, u.Avatar)
+// CustomAvatarRelativePath returns the path of the user custom avatar in the
+// avatars storage.
+func (u *User) CustomAvatarRelativePath() string {
+	return u.Avatar
+}
+
+// IsCustomAvatarExist returns true if the user custom avatar is stored
+func (u *User) IsCustomAvatarExist() bool {
+	if len(u.Avatar) == 0 {
+		return false
+	}
+	_, err := storage.Avatars.Stat(u.CustomAvatarRelativePath())
+	return err == nil
 }
 
 // GenerateRandomAvatar generates a random avatar for user.
@@ -352,23 +386,19 @@ func (u *User) generateRandomAvatar(e Engine) error {
 	if u.Avatar == "" {
 		u.Avatar = fmt.Sprintf("%d", u.ID)
 	}
-	if err = os.MkdirAll(filepath.Dir(u.CustomAvatarPath()), os.ModePerm); err != nil {
-		return fmt.Errorf("MkdirAll: %v", err)
+
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, img); err != nil {
+		return fmt.Errorf("Encode: %v", err)
 	}
-	fw, err := os.Create(u.CustomAvatarPath())
-	if err != nil {
-		return fmt.Errorf("Create: %v", err)
+	if _, err = storage.Avatars.Save(u.CustomAvatarRelativePath(), &buf, int64(buf.Len())); err != nil {
+		return fmt.Errorf("Save: %v", err)
 	}
-	defer fw.Close()
 
 	if _, err := e.ID(u.ID).Cols("avatar").Update(u); err != nil {
 		return err
 	}
 
-	if err = png.Encode(fw, img); err != nil {
-		return fmt.Errorf("Encode: %v", err)
-	}
-
 	log.Info("New random avatar created: %d", u.ID)
 	return nil
 }
@@ -382,12 +412,12 @@ func (u *User) SizedRelAvatarLink(size int) string {
 
 	switch {
 	case u.UseCustomAvatar:
-		if !com.IsFile(u.CustomAvatarPath()) {
+		if !u.IsCustomAvatarExist() {
 			return base.DefaultAvatarLink()
 		}
 		return setting.AppSubURL + "/avatars/" + u.Avatar
 	case setting.DisableGravatar, setting.OfflineMode:
-		if !com.IsFile(u.CustomAvatarPath()) {
+		if !u.IsCustomAvatarExist() {
 			if err := u.GenerateRandomAvatar(); err != nil {
 				log.Error("GenerateRandomAvatar: %v", err)
 			}
@@ -512,29 +542,23 @@ func (u *User) UploadAvatar(data []byte) error {
 		return fmt.Errorf("updateUser: %v", err)
 	}
 
-	if err := os.MkdirAll(setting.AvatarUploadPath, os.ModePerm); err != nil {
-		return fmt.Errorf("Failed to create dir %s: %v", setting.AvatarUploadPath, err)
-	}
-
-	fw, err := os.Create(u.CustomAvatarPath())
-	if err != nil {
-		return fmt.Errorf("Create: %v", err)
-	}
-	defer fw.Close()
-
-	if err = png.Encode(fw, *m); err != nil {
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, *m); err != nil {
 		return fmt.Errorf("Encode: %v", err)
 	}
+	if _, err = storage.Avatars.Save(u.CustomAvatarRelativePath(), &buf, int64(buf.Len())); err != nil {
+		return fmt.Errorf("Failed to save avatar %s: %v", u.CustomAvatarRelativePath(), err)
+	}
 
 	return sess.Commit()
 }
 
 // DeleteAvatar deletes the user's custom avatar.
 func (u *User) DeleteAvatar() error {
-	log.Trace("DeleteAvatar[%d]: %s", u.ID, u.CustomAvatarPath())
+	log.Trace("DeleteAvatar[%d]: %s", u.ID, u.CustomAvatarRelativePath())
 	if len(u.Avatar) > 0 {
-		if err := os.Remove(u.CustomAvatarPath()); err != nil {
-			return fmt.Errorf("Failed to remove %s: %v", u.CustomAvatarPath(), err)
+		if err := storage.Avatars.Delete(u.CustomAvatarRelativePath()); err != nil {
+			return fmt.Errorf("Failed to remove %s: %v", u.CustomAvatarRelativePath(), err)
 		}
 	}
 
@@ -893,6 +917,9 @@ func CreateUser(u *User) (err error) {
 	u.AllowCreateOrganization = setting.Service.DefaultAllowCreateOrganization && !setting.Admin.DisableRegularOrgCreation
 	u.EmailNotificationsPreference = setting.Admin.DefaultEmailNotification
 	u.MaxRepoCreation = -1
+	u.MaxGitSize = -1
+	u.MaxLFSSize = -1
+	u.MaxAttachmentSize = -1
 	u.Theme = setting.UI.DefaultTheme
 
 	if _, err = sess.Insert(u); err != nil {
@@ -1124,6 +1151,10 @@ func deleteUser(e *xorm.Session, u *User) error {
 		&TeamUser{UID: u.ID},
 		&Collaboration{UserID: u.ID},
 		&Stopwatch{UserID: u.ID},
+		&IssueReminder{UserID: u.ID},
+		&PullViewedFile{UserID: u.ID},
+		&OAuth2DeviceCode{UserID: u.ID},
+		&Review{ReviewerID: u.ID, Type: ReviewTypeRequest},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
@@ -1169,11 +1200,9 @@ func deleteUser(e *xorm.Session, u *User) error {
 	}
 
 	if len(u.Avatar) > 0 {
-		avatarPath := u.CustomAvatarPath()
-		if com.IsExist(avatarPath) {
-			if err := os.Remove(avatarPath); err != nil {
-				return fmt.Errorf("Failed to remove %s: %v", avatarPath, err)
-			}
+		avatarPath := u.CustomAvatarRelativePath()
+		if err := storage.Avatars.Delete(avatarPath); err != nil {
+			return fmt.Errorf("Failed to remove %s: %v", avatarPath, err)
 		}
 	}
 
@@ -198,6 +198,26 @@ func TestDeleteUser(t *testing.T) {
 	test(11)
 }
 
+func TestDeleteUser_PerUserRows(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	user := AssertExistsAndLoadBean(t, &User{ID: 8}).(*User)
+
+	_, err := x.Insert(&PullViewedFile{UserID: user.ID, PullID: 2, TreePath: "README.md"})
+	assert.NoError(t, err)
+	_, err = x.Insert(&OAuth2DeviceCode{ApplicationID: 1, DeviceCodeHash: "hash", UserCode: "ABCD-EFGH", UserID: user.ID})
+	assert.NoError(t, err)
+	_, err = x.Insert(&Review{Type: ReviewTypeRequest, ReviewerID: user.ID, IssueID: 3})
+	assert.NoError(t, err)
+
+	assert.NoError(t, DeleteUser(user))
+	AssertNotExistsBean(t, &PullViewedFile{UserID: user.ID})
+	AssertNotExistsBean(t, &OAuth2DeviceCode{UserID: user.ID})
+	AssertNotExistsBean(t, &Review{ReviewerID: user.ID})
+
+	// the review requests of the other users are kept
+	AssertExistsAndLoadBean(t, &Review{ID: 11, ReviewerID: 5, Type: ReviewTypeRequest})
+}
+
 func TestEmailNotificationPreferences(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	for _, test := range []struct {
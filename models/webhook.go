@@ -80,6 +80,8 @@ type HookEvents struct {
 	PullRequest  bool `json:"pull_request"`
 	Repository   bool `json:"repository"`
 	Release      bool `json:"release"`
+	Label        bool `json:"label"`
+	Wiki         bool `json:"wiki"`
 }
 
 // HookEvent represents events that will delivery hook.
@@ -103,21 +105,22 @@ const (
 
 // Webhook represents a web hook object.
 type Webhook struct {
-	ID           int64  `xorm:"pk autoincr"`
-	RepoID       int64  `xorm:"INDEX"`
-	OrgID        int64  `xorm:"INDEX"`
-	URL          string `xorm:"url TEXT"`
-	Signature    string `xorm:"TEXT"`
-	HTTPMethod   string `xorm:"http_method"`
-	ContentType  HookContentType
-	Secret       string `xorm:"TEXT"`
-	Events       string `xorm:"TEXT"`
-	*HookEvent   `xorm:"-"`
-	IsSSL        bool `xorm:"is_ssl"`
-	IsActive     bool `xorm:"INDEX"`
-	HookTaskType HookTaskType
-	Meta         string     `xorm:"TEXT"` // store hook-specific attributes
-	LastStatus   HookStatus // Last delivery status
+	ID              int64  `xorm:"pk autoincr"`
+	RepoID          int64  `xorm:"INDEX"`
+	OrgID           int64  `xorm:"INDEX"`
+	URL             string `xorm:"url TEXT"`
+	Signature       string `xorm:"TEXT"`
+	HTTPMethod      string `xorm:"http_method"`
+	ContentType     HookContentType
+	Secret          string `xorm:"TEXT"`
+	Events          string `xorm:"TEXT"`
+	*HookEvent      `xorm:"-"`
+	IsSSL           bool `xorm:"is_ssl"`
+	IsActive        bool `xorm:"INDEX"`
+	HookTaskType    HookTaskType
+	Meta            string     `xorm:"TEXT"` // store hook-specific attributes
+	LastStatus      HookStatus // Last delivery status
+	PayloadTemplate string     `xorm:"TEXT"` // template of the body of a generic hook
 
 	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
@@ -158,6 +161,15 @@ func (w *Webhook) GetTelegramHook() *TelegramMeta {
 	return s
 }
 
+// GetMatrixHook returns matrix metadata
+func (w *Webhook) GetMatrixHook() *MatrixMeta {
+	s := &MatrixMeta{}
+	if err := json.Unmarshal([]byte(w.Meta), s); err != nil {
+		log.Error("webhook.GetMatrixHook(%d): %v", w.ID, err)
+	}
+	return s
+}
+
 // History returns history of webhook by given conditions.
 func (w *Webhook) History(page int) ([]*HookTask, error) {
 	return HookTasks(w.ID, page)
@@ -224,6 +236,18 @@ func (w *Webhook) HasRepositoryEvent() bool {
 		(w.ChooseEvents && w.HookEvents.Repository)
 }
 
+// HasLabelEvent returns if hook enabled label event.
+func (w *Webhook) HasLabelEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Label)
+}
+
+// HasWikiEvent returns if hook enabled wiki event.
+func (w *Webhook) HasWikiEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Wiki)
+}
+
 func (w *Webhook) eventCheckers() []struct {
 	has func() bool
 	typ HookEventType
@@ -241,6 +265,8 @@ func (w *Webhook) eventCheckers() []struct {
 		{w.HasPullRequestEvent, HookEventPullRequest},
 		{w.HasRepositoryEvent, HookEventRepository},
 		{w.HasReleaseEvent, HookEventRelease},
+		{w.HasLabelEvent, HookEventLabel},
+		{w.HasWikiEvent, HookEventWiki},
 	}
 }
 
@@ -471,6 +497,7 @@ const (
 	DINGTALK
 	TELEGRAM
 	MSTEAMS
+	MATRIX
 )
 
 var hookTaskTypes = map[string]HookTaskType{
@@ -481,6 +508,7 @@ var hookTaskTypes = map[string]HookTaskType{
 	"dingtalk": DINGTALK,
 	"telegram": TELEGRAM,
 	"msteams":  MSTEAMS,
+	"matrix":   MATRIX,
 }
 
 // ToHookTaskType returns HookTaskType by given name.
@@ -505,6 +533,8 @@ func (t HookTaskType) Name() string {
 		return "telegram"
 	case MSTEAMS:
 		return "msteams"
+	case MATRIX:
+		return "matrix"
 	}
 	return ""
 }
@@ -529,6 +559,8 @@ const (
 	HookEventPullRequest         HookEventType = "pull_request"
 	HookEventRepository          HookEventType = "repository"
 	HookEventRelease             HookEventType = "release"
+	HookEventLabel               HookEventType = "label"
+	HookEventWiki                HookEventType = "wiki"
 	HookEventPullRequestApproved HookEventType = "pull_request_approved"
 	HookEventPullRequestRejected HookEventType = "pull_request_rejected"
 	HookEventPullRequestComment  HookEventType = "pull_request_comment"
@@ -689,9 +721,23 @@ func prepareWebhook(e Engine, w *Webhook, repo *Repository, event HookEventType,
 		if err != nil {
 			return fmt.Errorf("GetMSTeamsPayload: %v", err)
 		}
+	case MATRIX:
+		payloader, err = GetMatrixPayload(p, event, w.Meta)
+		if err != nil {
+			return fmt.Errorf("GetMatrixPayload: %v", err)
+		}
 	default:
 		p.SetSecret(w.Secret)
 		payloader = p
+
+		if len(w.PayloadTemplate) > 0 {
+			content, err := RenderWebhookPayloadTemplate(w.PayloadTemplate, event, p)
+			if err != nil {
+				log.Error("RenderWebhookPayloadTemplate [webhook_id: %d]: %v", w.ID, err)
+			} else if content != nil {
+				payloader = &templatedPayload{content: content}
+			}
+		}
 	}
 
 	var signature string
@@ -800,6 +846,15 @@ func (t *HookTask) deliver() error {
 		if err != nil {
 			return err
 		}
+	case http.MethodPut:
+		// the UUID of the task is the transaction ID of the matrix event,
+		// so that the homeserver ignores a message delivered twice
+		req, err = http.NewRequest("PUT", t.URL+"/"+t.UUID, strings.NewReader(t.PayloadContent))
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set("Content-Type", "application/json")
 	default:
 		return fmt.Errorf("Invalid http method for webhook: [%d] %v", t.ID, t.HTTPMethod)
 	}
@@ -821,6 +876,15 @@ func (t *HookTask) deliver() error {
 		t.RequestInfo.Headers[k] = strings.Join(vals, ",")
 	}
 
+	// The access token is not recorded in the history of the webhook.
+	if t.Type == MATRIX {
+		w, err := GetWebhookByID(t.HookID)
+		if err != nil {
+			return fmt.Errorf("GetWebhookByID: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+w.GetMatrixHook().AccessToken)
+	}
+
 	t.ResponseInfo = &HookResponse{
 		Headers: map[string]string{},
 	}
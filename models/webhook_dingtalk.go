@@ -324,6 +324,19 @@ func getDingtalkRepositoryPayload(p *api.RepositoryPayload) (*DingtalkPayload, e
 				Content: title,
 			},
 		}, nil
+	case api.HookRepoTransferred:
+		title = fmt.Sprintf("[%s] Repository transferred from %s", p.Repository.FullName, p.Changes.Owner.From)
+		url = p.Repository.HTMLURL
+		return &DingtalkPayload{
+			MsgType: "actionCard",
+			ActionCard: dingtalk.ActionCard{
+				Text:        title,
+				Title:       title,
+				HideAvatar:  "0",
+				SingleTitle: "view repository",
+				SingleURL:   url,
+			},
+		}, nil
 	}
 
 	return nil, nil
@@ -377,6 +390,54 @@ func getDingtalkReleasePayload(p *api.ReleasePayload) (*DingtalkPayload, error)
 	return nil, nil
 }
 
+func getDingtalkLabelPayload(p *api.LabelPayload) (*DingtalkPayload, error) {
+	var title string
+	switch p.Action {
+	case api.HookLabelCreated:
+		title = fmt.Sprintf("[%s] Label created: %s", p.Repository.FullName, p.Label.Name)
+	case api.HookLabelEdited:
+		title = fmt.Sprintf("[%s] Label edited: %s", p.Repository.FullName, p.Label.Name)
+	case api.HookLabelDeleted:
+		title = fmt.Sprintf("[%s] Label deleted: %s", p.Repository.FullName, p.Label.Name)
+	}
+
+	return &DingtalkPayload{
+		MsgType: "actionCard",
+		ActionCard: dingtalk.ActionCard{
+			Text:        title + "\r\n\r\n" + p.Label.Description,
+			Title:       title,
+			HideAvatar:  "0",
+			SingleTitle: "view labels",
+			SingleURL:   p.Repository.HTMLURL + "/labels",
+		},
+	}, nil
+}
+
+func getDingtalkWikiPayload(p *api.WikiPayload) (*DingtalkPayload, error) {
+	var title string
+	url := p.Repository.HTMLURL + "/wiki/" + WikiNameToSubURL(p.Page)
+	switch p.Action {
+	case api.HookWikiCreated:
+		title = fmt.Sprintf("[%s] Wiki page created: %s", p.Repository.FullName, p.Page)
+	case api.HookWikiEdited:
+		title = fmt.Sprintf("[%s] Wiki page edited: %s", p.Repository.FullName, p.Page)
+	case api.HookWikiDeleted:
+		title = fmt.Sprintf("[%s] Wiki page deleted: %s", p.Repository.FullName, p.Page)
+		url = p.Repository.HTMLURL + "/wiki"
+	}
+
+	return &DingtalkPayload{
+		MsgType: "actionCard",
+		ActionCard: dingtalk.ActionCard{
+			Text:        title + "\r\n\r\n" + p.Comment,
+			Title:       title,
+			HideAvatar:  "0",
+			SingleTitle: "view wiki",
+			SingleURL:   url,
+		},
+	}, nil
+}
+
 // GetDingtalkPayload converts a ding talk webhook into a DingtalkPayload
 func GetDingtalkPayload(p api.Payloader, event HookEventType, meta string) (*DingtalkPayload, error) {
 	s := new(DingtalkPayload)
@@ -402,6 +463,10 @@ func GetDingtalkPayload(p api.Payloader, event HookEventType, meta string) (*Din
 		return getDingtalkRepositoryPayload(p.(*api.RepositoryPayload))
 	case HookEventRelease:
 		return getDingtalkReleasePayload(p.(*api.ReleasePayload))
+	case HookEventLabel:
+		return getDingtalkLabelPayload(p.(*api.LabelPayload))
+	case HookEventWiki:
+		return getDingtalkWikiPayload(p.(*api.WikiPayload))
 	}
 
 	return s, nil
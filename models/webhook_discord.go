@@ -446,6 +446,10 @@ func getDiscordRepositoryPayload(p *api.RepositoryPayload, meta *DiscordMeta) (*
 	case api.HookRepoDeleted:
 		title = fmt.Sprintf("[%s] Repository deleted", p.Repository.FullName)
 		color = warnColor
+	case api.HookRepoTransferred:
+		title = fmt.Sprintf("[%s] Repository transferred from %s", p.Repository.FullName, p.Changes.Owner.From)
+		url = p.Repository.HTMLURL
+		color = successColor
 	}
 
 	return &DiscordPayload{
@@ -503,6 +507,77 @@ func getDiscordReleasePayload(p *api.ReleasePayload, meta *DiscordMeta) (*Discor
 	}, nil
 }
 
+func getDiscordLabelPayload(p *api.LabelPayload, meta *DiscordMeta) (*DiscordPayload, error) {
+	var title string
+	var color int
+	url := p.Repository.HTMLURL + "/labels"
+	switch p.Action {
+	case api.HookLabelCreated:
+		title = fmt.Sprintf("[%s] Label created: %s", p.Repository.FullName, p.Label.Name)
+		color = successColor
+	case api.HookLabelEdited:
+		title = fmt.Sprintf("[%s] Label edited: %s", p.Repository.FullName, p.Label.Name)
+		color = successColor
+	case api.HookLabelDeleted:
+		title = fmt.Sprintf("[%s] Label deleted: %s", p.Repository.FullName, p.Label.Name)
+		color = warnColor
+	}
+
+	return &DiscordPayload{
+		Username:  meta.Username,
+		AvatarURL: meta.IconURL,
+		Embeds: []DiscordEmbed{
+			{
+				Title:       title,
+				Description: p.Label.Description,
+				URL:         url,
+				Color:       color,
+				Author: DiscordEmbedAuthor{
+					Name:    p.Sender.UserName,
+					URL:     setting.AppURL + p.Sender.UserName,
+					IconURL: p.Sender.AvatarURL,
+				},
+			},
+		},
+	}, nil
+}
+
+func getDiscordWikiPayload(p *api.WikiPayload, meta *DiscordMeta) (*DiscordPayload, error) {
+	var title string
+	var color int
+	url := p.Repository.HTMLURL + "/wiki/" + WikiNameToSubURL(p.Page)
+	switch p.Action {
+	case api.HookWikiCreated:
+		title = fmt.Sprintf("[%s] Wiki page created: %s", p.Repository.FullName, p.Page)
+		color = successColor
+	case api.HookWikiEdited:
+		title = fmt.Sprintf("[%s] Wiki page edited: %s", p.Repository.FullName, p.Page)
+		color = successColor
+	case api.HookWikiDeleted:
+		title = fmt.Sprintf("[%s] Wiki page deleted: %s", p.Repository.FullName, p.Page)
+		url = p.Repository.HTMLURL + "/wiki"
+		color = warnColor
+	}
+
+	return &DiscordPayload{
+		Username:  meta.Username,
+		AvatarURL: meta.IconURL,
+		Embeds: []DiscordEmbed{
+			{
+				Title:       title,
+				Description: p.Comment,
+				URL:         url,
+				Color:       color,
+				Author: DiscordEmbedAuthor{
+					Name:    p.Sender.UserName,
+					URL:     setting.AppURL + p.Sender.UserName,
+					IconURL: p.Sender.AvatarURL,
+				},
+			},
+		},
+	}, nil
+}
+
 // GetDiscordPayload converts a discord webhook into a DiscordPayload
 func GetDiscordPayload(p api.Payloader, event HookEventType, meta string) (*DiscordPayload, error) {
 	s := new(DiscordPayload)
@@ -533,6 +608,10 @@ func GetDiscordPayload(p api.Payloader, event HookEventType, meta string) (*Disc
 		return getDiscordRepositoryPayload(p.(*api.RepositoryPayload), discord)
 	case HookEventRelease:
 		return getDiscordReleasePayload(p.(*api.ReleasePayload), discord)
+	case HookEventLabel:
+		return getDiscordLabelPayload(p.(*api.LabelPayload), discord)
+	case HookEventWiki:
+		return getDiscordWikiPayload(p.(*api.WikiPayload), discord)
 	}
 
 	return s, nil
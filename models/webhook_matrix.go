@@ -0,0 +1,350 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// Message formats of a matrix webhook
+const (
+	MatrixMessageFormatted = "formatted"
+	MatrixMessagePlain     = "plain"
+)
+
+type (
+	// MatrixMeta contains the matrix metadata
+	MatrixMeta struct {
+		HomeserverURL string `json:"homeserver_url"`
+		RoomID        string `json:"room_id"`
+		AccessToken   string `json:"access_token"`
+		MessageFormat string `json:"message_format"`
+	}
+
+	// MatrixPayload is a m.room.message event sent to a matrix room
+	MatrixPayload struct {
+		MsgType       string `json:"msgtype"`
+		Body          string `json:"body"`
+		Format        string `json:"format,omitempty"`
+		FormattedBody string `json:"formatted_body,omitempty"`
+	}
+)
+
+// SetSecret sets the matrix secret
+func (p *MatrixPayload) SetSecret(_ string) {}
+
+// JSONPayload Marshals the MatrixPayload to json
+func (p *MatrixPayload) JSONPayload() ([]byte, error) {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return []byte{}, err
+	}
+	return data, nil
+}
+
+// MatrixRoomMessageURL returns the URL of the homeserver endpoint which sends
+// messages to the room, the transaction ID of each message is appended to it
+func MatrixRoomMessageURL(homeserverURL, roomID string) string {
+	return fmt.Sprintf("%s/_matrix/client/r0/rooms/%s/send/m.room.message",
+		strings.TrimSuffix(homeserverURL, "/"), url.PathEscape(roomID))
+}
+
+// MatrixLinkFormatter creates a link compatible with matrix
+func MatrixLinkFormatter(url, text string) string {
+	return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(url), html.EscapeString(text))
+}
+
+// MatrixLinkToRef matrix-formatter link to a repo ref
+func MatrixLinkToRef(repoURL, ref string) string {
+	refName := git.RefEndName(ref)
+	switch {
+	case strings.HasPrefix(ref, git.BranchPrefix):
+		return MatrixLinkFormatter(repoURL+"/src/branch/"+refName, refName)
+	case strings.HasPrefix(ref, git.TagPrefix):
+		return MatrixLinkFormatter(repoURL+"/src/tag/"+refName, refName)
+	default:
+		return MatrixLinkFormatter(repoURL+"/src/commit/"+refName, refName)
+	}
+}
+
+var matrixLinkPattern = regexp.MustCompile(`<a href="([^"]*)">([^<]*)</a>`)
+
+// matrixPlainText converts a formatted message to its plain text body,
+// where the links are written as "text (url)"
+func matrixPlainText(formatted string) string {
+	text := matrixLinkPattern.ReplaceAllString(formatted, "$2 ($1)")
+	text = strings.Replace(text, "<br>", "\n", -1)
+	return html.UnescapeString(text)
+}
+
+func newMatrixPayload(meta *MatrixMeta, formatted string) *MatrixPayload {
+	p := &MatrixPayload{
+		MsgType: "m.notice",
+		Body:    matrixPlainText(formatted),
+	}
+	if meta.MessageFormat != MatrixMessagePlain {
+		p.Format = "org.matrix.custom.html"
+		p.FormattedBody = formatted
+	}
+	return p
+}
+
+func matrixSenderLink(sender *api.User) string {
+	return MatrixLinkFormatter(setting.AppURL+sender.UserName, sender.UserName)
+}
+
+func getMatrixCreatePayload(p *api.CreatePayload) string {
+	repoLink := MatrixLinkFormatter(p.Repo.HTMLURL, p.Repo.FullName)
+	refLink := MatrixLinkToRef(p.Repo.HTMLURL, p.Ref)
+	return fmt.Sprintf("[%s:%s] %s created by %s", repoLink, refLink, p.RefType, matrixSenderLink(p.Sender))
+}
+
+func getMatrixDeletePayload(p *api.DeletePayload) string {
+	repoLink := MatrixLinkFormatter(p.Repo.HTMLURL, p.Repo.FullName)
+	refName := html.EscapeString(git.RefEndName(p.Ref))
+	return fmt.Sprintf("[%s:%s] %s deleted by %s", repoLink, refName, p.RefType, matrixSenderLink(p.Sender))
+}
+
+func getMatrixForkPayload(p *api.ForkPayload) string {
+	baseLink := MatrixLinkFormatter(p.Forkee.HTMLURL, p.Forkee.FullName)
+	forkLink := MatrixLinkFormatter(p.Repo.HTMLURL, p.Repo.FullName)
+	return fmt.Sprintf("%s is forked to %s", baseLink, forkLink)
+}
+
+func getMatrixIssuesPayload(p *api.IssuePayload) string {
+	repoLink := MatrixLinkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	titleLink := MatrixLinkFormatter(fmt.Sprintf("%s/issues/%d", p.Repository.HTMLURL, p.Index),
+		fmt.Sprintf("#%d %s", p.Index, p.Issue.Title))
+	senderLink := matrixSenderLink(p.Sender)
+	switch p.Action {
+	case api.HookIssueOpened:
+		return fmt.Sprintf("[%s] Issue opened: %s by %s", repoLink, titleLink, senderLink)
+	case api.HookIssueClosed:
+		return fmt.Sprintf("[%s] Issue closed: %s by %s", repoLink, titleLink, senderLink)
+	case api.HookIssueReOpened:
+		return fmt.Sprintf("[%s] Issue re-opened: %s by %s", repoLink, titleLink, senderLink)
+	case api.HookIssueEdited:
+		return fmt.Sprintf("[%s] Issue edited: %s by %s", repoLink, titleLink, senderLink)
+	case api.HookIssueAssigned:
+		return fmt.Sprintf("[%s] Issue assigned to %s: %s by %s", repoLink,
+			matrixSenderLink(p.Issue.Assignee), titleLink, senderLink)
+	case api.HookIssueUnassigned:
+		return fmt.Sprintf("[%s] Issue unassigned: %s by %s", repoLink, titleLink, senderLink)
+	case api.HookIssueLabelUpdated:
+		return fmt.Sprintf("[%s] Issue labels updated: %s by %s", repoLink, titleLink, senderLink)
+	case api.HookIssueLabelCleared:
+		return fmt.Sprintf("[%s] Issue labels cleared: %s by %s", repoLink, titleLink, senderLink)
+	case api.HookIssueSynchronized:
+		return fmt.Sprintf("[%s] Issue synchronized: %s by %s", repoLink, titleLink, senderLink)
+	case api.HookIssueMilestoned:
+		return fmt.Sprintf("[%s] Issue milestoned: %s by %s", repoLink, titleLink, senderLink)
+	case api.HookIssueDemilestoned:
+		return fmt.Sprintf("[%s] Issue milestone cleared: %s by %s", repoLink, titleLink, senderLink)
+	}
+	return ""
+}
+
+func getMatrixIssueCommentPayload(p *api.IssueCommentPayload) string {
+	repoLink := MatrixLinkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	title := fmt.Sprintf("#%d %s", p.Issue.Index, p.Issue.Title)
+	titleLink := MatrixLinkFormatter(fmt.Sprintf("%s/issues/%d#%s", p.Repository.HTMLURL, p.Issue.Index, CommentHashTag(p.Comment.ID)), title)
+	senderLink := matrixSenderLink(p.Sender)
+	switch p.Action {
+	case api.HookIssueCommentCreated:
+		return fmt.Sprintf("[%s] New comment on %s by %s", repoLink, titleLink, senderLink)
+	case api.HookIssueCommentEdited:
+		return fmt.Sprintf("[%s] Comment edited on %s by %s", repoLink, titleLink, senderLink)
+	case api.HookIssueCommentDeleted:
+		titleLink = MatrixLinkFormatter(fmt.Sprintf("%s/issues/%d", p.Repository.HTMLURL, p.Issue.Index), title)
+		return fmt.Sprintf("[%s] Comment deleted on %s by %s", repoLink, titleLink, senderLink)
+	}
+	return ""
+}
+
+func getMatrixPushPayload(p *api.PushPayload) string {
+	commitDesc := "1 new commit"
+	if len(p.Commits) != 1 {
+		commitDesc = fmt.Sprintf("%d new commits", len(p.Commits))
+	}
+	if len(p.CompareURL) > 0 {
+		commitDesc = MatrixLinkFormatter(p.CompareURL, commitDesc)
+	}
+
+	repoLink := MatrixLinkFormatter(p.Repo.HTMLURL, p.Repo.FullName)
+	branchLink := MatrixLinkToRef(p.Repo.HTMLURL, p.Ref)
+	text := fmt.Sprintf("[%s:%s] %s pushed by %s", repoLink, branchLink, commitDesc, matrixSenderLink(p.Pusher))
+
+	// for each commit, add a line with its short message
+	for _, commit := range p.Commits {
+		text += fmt.Sprintf("<br>%s: %s - %s", MatrixLinkFormatter(commit.URL, commit.ID[:7]),
+			html.EscapeString(strings.Split(commit.Message, "\n")[0]), html.EscapeString(commit.Author.Name))
+	}
+	return text
+}
+
+func getMatrixPullRequestPayload(p *api.PullRequestPayload) string {
+	repoLink := MatrixLinkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	titleLink := MatrixLinkFormatter(fmt.Sprintf("%s/pulls/%d", p.Repository.HTMLURL, p.Index),
+		fmt.Sprintf("#%d %s", p.Index, p.PullRequest.Title))
+	senderLink := matrixSenderLink(p.Sender)
+	switch p.Action {
+	case api.HookIssueOpened:
+		return fmt.Sprintf("[%s] Pull request opened: %s by %s", repoLink, titleLink, senderLink)
+	case api.HookIssueClosed:
+		if p.PullRequest.HasMerged {
+			return fmt.Sprintf("[%s] Pull request merged: %s by %s", repoLink, titleLink, senderLink)
+		}
+		return fmt.Sprintf("[%s] Pull request closed: %s by %s", repoLink, titleLink, senderLink)
+	case api.HookIssueReOpened:
+		return fmt.Sprintf("[%s] Pull request re-opened: %s by %s", repoLink, titleLink, senderLink)
+	case api.HookIssueEdited:
+		return fmt.Sprintf("[%s] Pull request edited: %s by %s", repoLink, titleLink, senderLink)
+	case api.HookIssueAssigned:
+		list := make([]string, len(p.PullRequest.Assignees))
+		for i, user := range p.PullRequest.Assignees {
+			list[i] = matrixSenderLink(user)
+		}
+		return fmt.Sprintf("[%s] Pull request assigned to %s: %s by %s", repoLink,
+			strings.Join(list, ", "), titleLink, senderLink)
+	case api.HookIssueUnassigned:
+		return fmt.Sprintf("[%s] Pull request unassigned: %s by %s", repoLink, titleLink, senderLink)
+	case api.HookIssueLabelUpdated:
+		return fmt.Sprintf("[%s] Pull request labels updated: %s by %s", repoLink, titleLink, senderLink)
+	case api.HookIssueLabelCleared:
+		return fmt.Sprintf("[%s] Pull request labels cleared: %s by %s", repoLink, titleLink, senderLink)
+	case api.HookIssueSynchronized:
+		return fmt.Sprintf("[%s] Pull request synchronized: %s by %s", repoLink, titleLink, senderLink)
+	case api.HookIssueMilestoned:
+		return fmt.Sprintf("[%s] Pull request milestoned: %s by %s", repoLink, titleLink, senderLink)
+	case api.HookIssueDemilestoned:
+		return fmt.Sprintf("[%s] Pull request milestone cleared: %s by %s", repoLink, titleLink, senderLink)
+	}
+	return ""
+}
+
+func getMatrixPullRequestApprovalPayload(p *api.PullRequestPayload, event HookEventType) string {
+	repoLink := MatrixLinkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	titleLink := MatrixLinkFormatter(fmt.Sprintf("%s/pulls/%d", p.Repository.HTMLURL, p.Index),
+		fmt.Sprintf("#%d %s", p.Index, p.PullRequest.Title))
+	var action string
+	switch event {
+	case HookEventPullRequestApproved:
+		action = "approved"
+	case HookEventPullRequestRejected:
+		action = "changes requested"
+	case HookEventPullRequestComment:
+		action = "comment"
+	}
+	return fmt.Sprintf("[%s] Pull request review %s: %s by %s", repoLink, action, titleLink, matrixSenderLink(p.Sender))
+}
+
+func getMatrixRepositoryPayload(p *api.RepositoryPayload) string {
+	senderLink := matrixSenderLink(p.Sender)
+	switch p.Action {
+	case api.HookRepoCreated:
+		return fmt.Sprintf("[%s] Repository created by %s", MatrixLinkFormatter(p.Repository.HTMLURL, p.Repository.FullName), senderLink)
+	case api.HookRepoDeleted:
+		return fmt.Sprintf("[%s] Repository deleted by %s", html.EscapeString(p.Repository.FullName), senderLink)
+	case api.HookRepoTransferred:
+		return fmt.Sprintf("[%s] Repository transferred from %s by %s", MatrixLinkFormatter(p.Repository.HTMLURL, p.Repository.FullName),
+			html.EscapeString(p.Changes.Owner.From), senderLink)
+	}
+	return ""
+}
+
+func getMatrixReleasePayload(p *api.ReleasePayload) string {
+	repoLink := MatrixLinkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	releaseLink := MatrixLinkFormatter(p.Repository.HTMLURL+"/src/"+p.Release.TagName, p.Release.TagName)
+	senderLink := matrixSenderLink(p.Sender)
+	switch p.Action {
+	case api.HookReleasePublished:
+		return fmt.Sprintf("[%s] Release %s published by %s", repoLink, releaseLink, senderLink)
+	case api.HookReleaseUpdated:
+		return fmt.Sprintf("[%s] Release %s updated by %s", repoLink, releaseLink, senderLink)
+	case api.HookReleaseDeleted:
+		return fmt.Sprintf("[%s] Release %s deleted by %s", repoLink, html.EscapeString(p.Release.TagName), senderLink)
+	}
+	return ""
+}
+
+func getMatrixLabelPayload(p *api.LabelPayload) string {
+	repoLink := MatrixLinkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	labelLink := MatrixLinkFormatter(p.Repository.HTMLURL+"/labels", p.Label.Name)
+	senderLink := matrixSenderLink(p.Sender)
+	switch p.Action {
+	case api.HookLabelCreated:
+		return fmt.Sprintf("[%s] Label %s created by %s", repoLink, labelLink, senderLink)
+	case api.HookLabelEdited:
+		return fmt.Sprintf("[%s] Label %s edited by %s", repoLink, labelLink, senderLink)
+	case api.HookLabelDeleted:
+		return fmt.Sprintf("[%s] Label %s deleted by %s", repoLink, html.EscapeString(p.Label.Name), senderLink)
+	}
+	return ""
+}
+
+func getMatrixWikiPayload(p *api.WikiPayload) string {
+	repoLink := MatrixLinkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	pageLink := MatrixLinkFormatter(p.Repository.HTMLURL+"/wiki/"+WikiNameToSubURL(p.Page), p.Page)
+	senderLink := matrixSenderLink(p.Sender)
+	switch p.Action {
+	case api.HookWikiCreated:
+		return fmt.Sprintf("[%s] Wiki page %s created by %s", repoLink, pageLink, senderLink)
+	case api.HookWikiEdited:
+		return fmt.Sprintf("[%s] Wiki page %s edited by %s", repoLink, pageLink, senderLink)
+	case api.HookWikiDeleted:
+		return fmt.Sprintf("[%s] Wiki page %s deleted by %s", repoLink, html.EscapeString(p.Page), senderLink)
+	}
+	return ""
+}
+
+// GetMatrixPayload converts a matrix webhook into a MatrixPayload
+func GetMatrixPayload(p api.Payloader, event HookEventType, meta string) (*MatrixPayload, error) {
+	matrix := &MatrixMeta{}
+	if len(meta) > 0 {
+		if err := json.Unmarshal([]byte(meta), matrix); err != nil {
+			return nil, errors.New("GetMatrixPayload meta json:" + err.Error())
+		}
+	}
+
+	var text string
+	switch event {
+	case HookEventCreate:
+		text = getMatrixCreatePayload(p.(*api.CreatePayload))
+	case HookEventDelete:
+		text = getMatrixDeletePayload(p.(*api.DeletePayload))
+	case HookEventFork:
+		text = getMatrixForkPayload(p.(*api.ForkPayload))
+	case HookEventIssues:
+		text = getMatrixIssuesPayload(p.(*api.IssuePayload))
+	case HookEventIssueComment:
+		text = getMatrixIssueCommentPayload(p.(*api.IssueCommentPayload))
+	case HookEventPush:
+		text = getMatrixPushPayload(p.(*api.PushPayload))
+	case HookEventPullRequest:
+		text = getMatrixPullRequestPayload(p.(*api.PullRequestPayload))
+	case HookEventPullRequestRejected, HookEventPullRequestApproved, HookEventPullRequestComment:
+		text = getMatrixPullRequestApprovalPayload(p.(*api.PullRequestPayload), event)
+	case HookEventRepository:
+		text = getMatrixRepositoryPayload(p.(*api.RepositoryPayload))
+	case HookEventRelease:
+		text = getMatrixReleasePayload(p.(*api.ReleasePayload))
+	case HookEventLabel:
+		text = getMatrixLabelPayload(p.(*api.LabelPayload))
+	case HookEventWiki:
+		text = getMatrixWikiPayload(p.(*api.WikiPayload))
+	}
+
+	return newMatrixPayload(matrix, text), nil
+}
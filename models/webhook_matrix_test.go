@@ -0,0 +1,44 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatrixRoomMessageURL(t *testing.T) {
+	assert.Equal(t, "https://matrix.org/_matrix/client/r0/rooms/%21room:matrix.org/send/m.room.message",
+		MatrixRoomMessageURL("https://matrix.org/", "!room:matrix.org"))
+}
+
+func TestGetMatrixPayload(t *testing.T) {
+	p := &api.CreatePayload{
+		Ref:     "refs/heads/test",
+		RefType: "branch",
+		Repo: &api.Repository{
+			FullName: "user2/repo1",
+			HTMLURL:  "http://localhost:3000/user2/repo1",
+		},
+		Sender: &api.User{UserName: "user2"},
+	}
+
+	pl, err := GetMatrixPayload(p, HookEventCreate, `{"message_format":"formatted"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "m.notice", pl.MsgType)
+	assert.Equal(t, "org.matrix.custom.html", pl.Format)
+	assert.Contains(t, pl.FormattedBody, `<a href="http://localhost:3000/user2/repo1">user2/repo1</a>`)
+	assert.Contains(t, pl.Body, "user2/repo1 (http://localhost:3000/user2/repo1)")
+	assert.NotContains(t, pl.Body, "<a ")
+
+	pl, err = GetMatrixPayload(p, HookEventCreate, `{"message_format":"plain"}`)
+	assert.NoError(t, err)
+	assert.Empty(t, pl.Format)
+	assert.Empty(t, pl.FormattedBody)
+	assert.Contains(t, pl.Body, "branch created by user2")
+}
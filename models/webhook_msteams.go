@@ -241,6 +241,10 @@ func getMSTeamsPushPayload(p *api.PushPayload) (*MSTeamsPayload, error) {
 						Name:  "Repository:",
 						Value: p.Repo.FullName,
 					},
+					{
+						Name:  "Branch:",
+						Value: branchName,
+					},
 					{
 						Name:  "Commit count:",
 						Value: fmt.Sprintf("%d", len(p.Commits)),
@@ -579,6 +583,10 @@ func getMSTeamsRepositoryPayload(p *api.RepositoryPayload) (*MSTeamsPayload, err
 	case api.HookRepoDeleted:
 		title = fmt.Sprintf("[%s] Repository deleted", p.Repository.FullName)
 		color = warnColor
+	case api.HookRepoTransferred:
+		title = fmt.Sprintf("[%s] Repository transferred from %s", p.Repository.FullName, p.Changes.Owner.From)
+		url = p.Repository.HTMLURL
+		color = successColor
 	}
 
 	return &MSTeamsPayload{
@@ -672,6 +680,116 @@ func getMSTeamsReleasePayload(p *api.ReleasePayload) (*MSTeamsPayload, error) {
 	}, nil
 }
 
+func getMSTeamsLabelPayload(p *api.LabelPayload) (*MSTeamsPayload, error) {
+	var title string
+	var color int
+	switch p.Action {
+	case api.HookLabelCreated:
+		title = fmt.Sprintf("[%s] Label created: %s", p.Repository.FullName, p.Label.Name)
+		color = successColor
+	case api.HookLabelEdited:
+		title = fmt.Sprintf("[%s] Label edited: %s", p.Repository.FullName, p.Label.Name)
+		color = successColor
+	case api.HookLabelDeleted:
+		title = fmt.Sprintf("[%s] Label deleted: %s", p.Repository.FullName, p.Label.Name)
+		color = warnColor
+	}
+
+	return &MSTeamsPayload{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: fmt.Sprintf("%x", color),
+		Title:      title,
+		Summary:    title,
+		Sections: []MSTeamsSection{
+			{
+				ActivityTitle:    p.Sender.FullName,
+				ActivitySubtitle: p.Sender.UserName,
+				ActivityImage:    p.Sender.AvatarURL,
+				Text:             p.Label.Description,
+				Facts: []MSTeamsFact{
+					{
+						Name:  "Repository:",
+						Value: p.Repository.FullName,
+					},
+					{
+						Name:  "Label:",
+						Value: p.Label.Name,
+					},
+				},
+			},
+		},
+		PotentialAction: []MSTeamsAction{
+			{
+				Type: "OpenUri",
+				Name: "View in Gitea",
+				Targets: []MSTeamsActionTarget{
+					{
+						Os:  "default",
+						URI: p.Repository.HTMLURL + "/labels",
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func getMSTeamsWikiPayload(p *api.WikiPayload) (*MSTeamsPayload, error) {
+	var title string
+	var color int
+	url := p.Repository.HTMLURL + "/wiki/" + WikiNameToSubURL(p.Page)
+	switch p.Action {
+	case api.HookWikiCreated:
+		title = fmt.Sprintf("[%s] Wiki page created: %s", p.Repository.FullName, p.Page)
+		color = successColor
+	case api.HookWikiEdited:
+		title = fmt.Sprintf("[%s] Wiki page edited: %s", p.Repository.FullName, p.Page)
+		color = successColor
+	case api.HookWikiDeleted:
+		title = fmt.Sprintf("[%s] Wiki page deleted: %s", p.Repository.FullName, p.Page)
+		url = p.Repository.HTMLURL + "/wiki"
+		color = warnColor
+	}
+
+	return &MSTeamsPayload{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: fmt.Sprintf("%x", color),
+		Title:      title,
+		Summary:    title,
+		Sections: []MSTeamsSection{
+			{
+				ActivityTitle:    p.Sender.FullName,
+				ActivitySubtitle: p.Sender.UserName,
+				ActivityImage:    p.Sender.AvatarURL,
+				Text:             p.Comment,
+				Facts: []MSTeamsFact{
+					{
+						Name:  "Repository:",
+						Value: p.Repository.FullName,
+					},
+					{
+						Name:  "Page:",
+						Value: p.Page,
+					},
+				},
+			},
+		},
+		PotentialAction: []MSTeamsAction{
+			{
+				Type: "OpenUri",
+				Name: "View in Gitea",
+				Targets: []MSTeamsActionTarget{
+					{
+						Os:  "default",
+						URI: url,
+					},
+				},
+			},
+		},
+	}, nil
+}
+
 // GetMSTeamsPayload converts a MSTeams webhook into a MSTeamsPayload
 func GetMSTeamsPayload(p api.Payloader, event HookEventType, meta string) (*MSTeamsPayload, error) {
 	s := new(MSTeamsPayload)
@@ -697,6 +815,10 @@ func GetMSTeamsPayload(p api.Payloader, event HookEventType, meta string) (*MSTe
 		return getMSTeamsRepositoryPayload(p.(*api.RepositoryPayload))
 	case HookEventRelease:
 		return getMSTeamsReleasePayload(p.(*api.ReleasePayload))
+	case HookEventLabel:
+		return getMSTeamsLabelPayload(p.(*api.LabelPayload))
+	case HookEventWiki:
+		return getMSTeamsWikiPayload(p.(*api.WikiPayload))
 	}
 
 	return s, nil
@@ -372,6 +372,9 @@ func getSlackRepositoryPayload(p *api.RepositoryPayload, slack *SlackMeta) (*Sla
 		title = p.Repository.HTMLURL
 	case api.HookRepoDeleted:
 		text = fmt.Sprintf("[%s] Repository deleted by %s", p.Repository.FullName, senderLink)
+	case api.HookRepoTransferred:
+		text = fmt.Sprintf("[%s] Repository transferred from %s by %s", p.Repository.FullName, p.Changes.Owner.From, senderLink)
+		title = p.Repository.HTMLURL
 	}
 
 	return &SlackPayload{
@@ -387,6 +390,58 @@ func getSlackRepositoryPayload(p *api.RepositoryPayload, slack *SlackMeta) (*Sla
 	}, nil
 }
 
+func getSlackLabelPayload(p *api.LabelPayload, slack *SlackMeta) (*SlackPayload, error) {
+	repoLink := SlackLinkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	labelLink := SlackLinkFormatter(p.Repository.HTMLURL+"/labels", p.Label.Name)
+	senderLink := SlackLinkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName)
+	var text string
+
+	switch p.Action {
+	case api.HookLabelCreated:
+		text = fmt.Sprintf("[%s] Label %s created by %s", repoLink, labelLink, senderLink)
+	case api.HookLabelEdited:
+		text = fmt.Sprintf("[%s] Label %s edited by %s", repoLink, labelLink, senderLink)
+	case api.HookLabelDeleted:
+		text = fmt.Sprintf("[%s] Label %s deleted by %s", repoLink, SlackTextFormatter(p.Label.Name), senderLink)
+	}
+
+	return &SlackPayload{
+		Channel:  slack.Channel,
+		Text:     text,
+		Username: slack.Username,
+		IconURL:  slack.IconURL,
+	}, nil
+}
+
+func getSlackWikiPayload(p *api.WikiPayload, slack *SlackMeta) (*SlackPayload, error) {
+	repoLink := SlackLinkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	pageLink := SlackLinkFormatter(p.Repository.HTMLURL+"/wiki/"+WikiNameToSubURL(p.Page), p.Page)
+	senderLink := SlackLinkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName)
+	var text, attachmentText string
+
+	switch p.Action {
+	case api.HookWikiCreated:
+		text = fmt.Sprintf("[%s] Wiki page %s created by %s", repoLink, pageLink, senderLink)
+		attachmentText = SlackTextFormatter(p.Comment)
+	case api.HookWikiEdited:
+		text = fmt.Sprintf("[%s] Wiki page %s edited by %s", repoLink, pageLink, senderLink)
+		attachmentText = SlackTextFormatter(p.Comment)
+	case api.HookWikiDeleted:
+		text = fmt.Sprintf("[%s] Wiki page %s deleted by %s", repoLink, SlackTextFormatter(p.Page), senderLink)
+	}
+
+	return &SlackPayload{
+		Channel:  slack.Channel,
+		Text:     text,
+		Username: slack.Username,
+		IconURL:  slack.IconURL,
+		Attachments: []SlackAttachment{{
+			Color: slack.Color,
+			Text:  attachmentText,
+		}},
+	}, nil
+}
+
 // GetSlackPayload converts a slack webhook into a SlackPayload
 func GetSlackPayload(p api.Payloader, event HookEventType, meta string) (*SlackPayload, error) {
 	s := new(SlackPayload)
@@ -417,6 +472,10 @@ func GetSlackPayload(p api.Payloader, event HookEventType, meta string) (*SlackP
 		return getSlackRepositoryPayload(p.(*api.RepositoryPayload), slack)
 	case HookEventRelease:
 		return getSlackReleasePayload(p.(*api.ReleasePayload), slack)
+	case HookEventLabel:
+		return getSlackLabelPayload(p.(*api.LabelPayload), slack)
+	case HookEventWiki:
+		return getSlackWikiPayload(p.(*api.WikiPayload), slack)
 	}
 
 	return s, nil
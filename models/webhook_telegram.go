@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"html"
+	"net/url"
 	"strings"
 
 	"code.gitea.io/gitea/modules/git"
@@ -45,6 +46,13 @@ func (p *TelegramPayload) JSONPayload() ([]byte, error) {
 	return data, nil
 }
 
+// TelegramSendMessageURL returns the URL of the bot API method which sends
+// messages to the chat
+func TelegramSendMessageURL(botToken, chatID string) string {
+	return fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage?chat_id=%s",
+		url.PathEscape(botToken), url.QueryEscape(chatID))
+}
+
 func getTelegramCreatePayload(p *api.CreatePayload) (*TelegramPayload, error) {
 	// created tag/branch
 	refName := git.RefEndName(p.Ref)
@@ -252,6 +260,20 @@ func getTelegramPullRequestPayload(p *api.PullRequestPayload) (*TelegramPayload,
 	}, nil
 }
 
+func getTelegramPullRequestApprovalPayload(p *api.PullRequestPayload, event HookEventType) (*TelegramPayload, error) {
+	action, err := parseHookPullRequestEventType(event)
+	if err != nil {
+		return nil, err
+	}
+
+	title := fmt.Sprintf(`[<a href="%s">%s</a>] Pull request review %s: <a href="%s">#%d %s</a>`, p.Repository.HTMLURL, p.Repository.FullName,
+		action, p.PullRequest.HTMLURL, p.Index, p.PullRequest.Title)
+
+	return &TelegramPayload{
+		Message: title,
+	}, nil
+}
+
 func getTelegramRepositoryPayload(p *api.RepositoryPayload) (*TelegramPayload, error) {
 	var title string
 	switch p.Action {
@@ -265,6 +287,11 @@ func getTelegramRepositoryPayload(p *api.RepositoryPayload) (*TelegramPayload, e
 		return &TelegramPayload{
 			Message: title,
 		}, nil
+	case api.HookRepoTransferred:
+		title = fmt.Sprintf(`[<a href="%s">%s</a>] Repository transferred from %s`, p.Repository.HTMLURL, p.Repository.FullName, p.Changes.Owner.From)
+		return &TelegramPayload{
+			Message: title,
+		}, nil
 	}
 	return nil, nil
 }
@@ -296,6 +323,44 @@ func getTelegramReleasePayload(p *api.ReleasePayload) (*TelegramPayload, error)
 	return nil, nil
 }
 
+func getTelegramLabelPayload(p *api.LabelPayload) (*TelegramPayload, error) {
+	var title string
+	repoLink := fmt.Sprintf(`<a href="%s">%s</a>`, p.Repository.HTMLURL, p.Repository.FullName)
+	labelLink := fmt.Sprintf(`<a href="%s">%s</a>`, p.Repository.HTMLURL+"/labels", html.EscapeString(p.Label.Name))
+	switch p.Action {
+	case api.HookLabelCreated:
+		title = fmt.Sprintf("[%s] Label %s created by %s", repoLink, labelLink, p.Sender.UserName)
+	case api.HookLabelEdited:
+		title = fmt.Sprintf("[%s] Label %s edited by %s", repoLink, labelLink, p.Sender.UserName)
+	case api.HookLabelDeleted:
+		title = fmt.Sprintf("[%s] Label %s deleted by %s", repoLink, html.EscapeString(p.Label.Name), p.Sender.UserName)
+	}
+
+	return &TelegramPayload{
+		Message: title,
+	}, nil
+}
+
+func getTelegramWikiPayload(p *api.WikiPayload) (*TelegramPayload, error) {
+	var title, text string
+	repoLink := fmt.Sprintf(`<a href="%s">%s</a>`, p.Repository.HTMLURL, p.Repository.FullName)
+	pageLink := fmt.Sprintf(`<a href="%s">%s</a>`, p.Repository.HTMLURL+"/wiki/"+WikiNameToSubURL(p.Page), html.EscapeString(p.Page))
+	switch p.Action {
+	case api.HookWikiCreated:
+		title = fmt.Sprintf("[%s] Wiki page %s created by %s", repoLink, pageLink, p.Sender.UserName)
+		text = html.EscapeString(p.Comment)
+	case api.HookWikiEdited:
+		title = fmt.Sprintf("[%s] Wiki page %s edited by %s", repoLink, pageLink, p.Sender.UserName)
+		text = html.EscapeString(p.Comment)
+	case api.HookWikiDeleted:
+		title = fmt.Sprintf("[%s] Wiki page %s deleted by %s", repoLink, html.EscapeString(p.Page), p.Sender.UserName)
+	}
+
+	return &TelegramPayload{
+		Message: strings.TrimSpace(title + "\n" + text),
+	}, nil
+}
+
 // GetTelegramPayload converts a telegram webhook into a TelegramPayload
 func GetTelegramPayload(p api.Payloader, event HookEventType, meta string) (*TelegramPayload, error) {
 	s := new(TelegramPayload)
@@ -315,10 +380,16 @@ func GetTelegramPayload(p api.Payloader, event HookEventType, meta string) (*Tel
 		return getTelegramPushPayload(p.(*api.PushPayload))
 	case HookEventPullRequest:
 		return getTelegramPullRequestPayload(p.(*api.PullRequestPayload))
+	case HookEventPullRequestRejected, HookEventPullRequestApproved, HookEventPullRequestComment:
+		return getTelegramPullRequestApprovalPayload(p.(*api.PullRequestPayload), event)
 	case HookEventRepository:
 		return getTelegramRepositoryPayload(p.(*api.RepositoryPayload))
 	case HookEventRelease:
 		return getTelegramReleasePayload(p.(*api.ReleasePayload))
+	case HookEventLabel:
+		return getTelegramLabelPayload(p.(*api.LabelPayload))
+	case HookEventWiki:
+		return getTelegramWikiPayload(p.(*api.WikiPayload))
 	}
 
 	return s, nil
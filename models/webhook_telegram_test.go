@@ -0,0 +1,39 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTelegramSendMessageURL(t *testing.T) {
+	assert.Equal(t, "https://api.telegram.org/bot123:abc/sendMessage?chat_id=%40channel",
+		TelegramSendMessageURL("123:abc", "@channel"))
+}
+
+func TestGetTelegramPayload_PullRequestApproved(t *testing.T) {
+	p := &api.PullRequestPayload{
+		Action: api.HookIssueSynchronized,
+		Index:  2,
+		PullRequest: &api.PullRequest{
+			Title:   "Fix bug",
+			HTMLURL: "http://localhost:3000/user2/repo1/pulls/2",
+		},
+		Repository: &api.Repository{
+			FullName: "user2/repo1",
+			HTMLURL:  "http://localhost:3000/user2/repo1",
+		},
+		Sender: &api.User{UserName: "user2"},
+	}
+
+	pl, err := GetTelegramPayload(p, HookEventPullRequestApproved, "")
+	assert.NoError(t, err)
+	assert.Contains(t, pl.Message, "Pull request review approved")
+	assert.Contains(t, pl.Message, "#2 Fix bug")
+}
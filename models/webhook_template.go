@@ -0,0 +1,331 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+const (
+	// maxPayloadTemplateSize is the maximum size of a payload template
+	maxPayloadTemplateSize = 64 * 1024
+	// maxPayloadTemplateDepth is the maximum nesting of the if, range and
+	// with actions and the template calls of a payload template
+	maxPayloadTemplateDepth = 10
+	// maxPayloadTemplateCalls is the maximum number of templates a payload
+	// template calls, counting the templates called by the called templates
+	maxPayloadTemplateCalls = 100
+	// maxRenderedPayloadSize is the maximum size of a rendered payload
+	maxRenderedPayloadSize = 4 * 1024 * 1024
+	// maxPayloadTemplateIterations is the maximum number of iterations of the
+	// range actions of a payload template, counting the nested ones
+	maxPayloadTemplateIterations = 100000
+	// payloadTemplateTimeout is the maximum time a payload template runs
+	payloadTemplateTimeout = 5 * time.Second
+	// payloadTemplateIterateFunc is the function called at each iteration of
+	// the range actions of a payload template
+	payloadTemplateIterateFunc = "_iterate"
+)
+
+// errPayloadTooLarge is returned when a rendered payload exceeds maxRenderedPayloadSize
+var errPayloadTooLarge = fmt.Errorf("the rendered payload exceeds %d bytes", maxRenderedPayloadSize)
+
+// errPayloadTemplateTimeout is returned when a payload template runs longer than payloadTemplateTimeout
+var errPayloadTemplateTimeout = fmt.Errorf("the payload template ran longer than %v", payloadTemplateTimeout)
+
+// errPayloadTemplateIterations is returned when a payload template iterates more than maxPayloadTemplateIterations times
+var errPayloadTemplateIterations = fmt.Errorf("the payload template iterated more than %d times", maxPayloadTemplateIterations)
+
+// templatedPayload is the body of a webhook rendered from its payload template
+type templatedPayload struct {
+	content []byte
+}
+
+// SetSecret sets the secret of the templated payload, which is only used to sign it
+func (p *templatedPayload) SetSecret(_ string) {}
+
+// JSONPayload returns the rendered body
+func (p *templatedPayload) JSONPayload() ([]byte, error) {
+	return p.content, nil
+}
+
+// limitedWriter is a buffer which fails once it exceeds maxRenderedPayloadSize
+// or once it is closed, which stops the template writing to it
+type limitedWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+	// iterations is the number of iterations of the range actions
+	iterations int
+	// err is the reason the writer failed
+	err error
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err == nil && w.buf.Len()+len(p) > maxRenderedPayloadSize {
+		w.err = errPayloadTooLarge
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+	return w.buf.Write(p)
+}
+
+func (w *limitedWriter) close() {
+	w.mu.Lock()
+	w.err = errPayloadTemplateTimeout
+	w.mu.Unlock()
+}
+
+// iterate counts an iteration of a range action, it fails once the template
+// iterated too many times or once the writer is closed so that the templates
+// which write nothing stop as well
+func (w *limitedWriter) iterate() (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.iterations++
+	if w.err == nil && w.iterations > maxPayloadTemplateIterations {
+		w.err = errPayloadTemplateIterations
+	}
+	return "", w.err
+}
+
+// addIterateCalls calls payloadTemplateIterateFunc at the beginning of the
+// range actions of the node
+func addIterateCalls(node parse.Node, call parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n != nil {
+			for _, child := range n.Nodes {
+				addIterateCalls(child, call)
+			}
+		}
+	case *parse.IfNode:
+		addIterateCalls(n.List, call)
+		addIterateCalls(n.ElseList, call)
+	case *parse.RangeNode:
+		addIterateCalls(n.List, call)
+		addIterateCalls(n.ElseList, call)
+		n.List.Nodes = append([]parse.Node{call}, n.List.Nodes...)
+	case *parse.WithNode:
+		addIterateCalls(n.List, call)
+		addIterateCalls(n.ElseList, call)
+	}
+}
+
+// payloadTemplateChecker checks the nesting and the calls of the templates
+// of a payload template, so that they can't expand exponentially
+type payloadTemplateChecker struct {
+	tmpl *template.Template
+	// depths and calls are the nesting and the number of calls of the
+	// templates already checked
+	depths map[string]int
+	calls  map[string]int
+	// checking are the templates being checked, to detect recursive calls
+	checking map[string]bool
+}
+
+// check returns the nesting and the number of calls of a template
+func (c *payloadTemplateChecker) check(name string) (int, int, error) {
+	if depth, ok := c.depths[name]; ok {
+		return depth, c.calls[name], nil
+	}
+	if c.checking[name] {
+		return 0, 0, fmt.Errorf("template %q calls itself", name)
+	}
+	t := c.tmpl.Lookup(name)
+	if t == nil || t.Tree == nil {
+		return 0, 0, nil
+	}
+
+	c.checking[name] = true
+	depth, calls, err := c.node(t.Tree.Root)
+	delete(c.checking, name)
+	if err != nil {
+		return 0, 0, err
+	}
+	c.depths[name], c.calls[name] = depth, calls
+	return depth, calls, nil
+}
+
+// node returns the nesting and the number of calls of a node
+func (c *payloadTemplateChecker) node(node parse.Node) (depth, calls int, err error) {
+	var children []parse.Node
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n != nil {
+			children = n.Nodes
+		}
+	case *parse.IfNode:
+		depth, calls, err = c.branch(&n.BranchNode)
+		return depth + 1, calls, err
+	case *parse.RangeNode:
+		depth, calls, err = c.branch(&n.BranchNode)
+		return depth + 1, calls, err
+	case *parse.WithNode:
+		depth, calls, err = c.branch(&n.BranchNode)
+		return depth + 1, calls, err
+	case *parse.TemplateNode:
+		depth, calls, err = c.check(n.Name)
+		return depth + 1, calls + 1, err
+	}
+
+	for _, child := range children {
+		d, n, err := c.node(child)
+		if err != nil {
+			return 0, 0, err
+		}
+		if d > depth {
+			depth = d
+		}
+		calls += n
+	}
+	return depth, calls, nil
+}
+
+func (c *payloadTemplateChecker) branch(n *parse.BranchNode) (int, int, error) {
+	depth, calls, err := c.node(n.List)
+	if err != nil || n.ElseList == nil {
+		return depth, calls, err
+	}
+	elseDepth, elseCalls, err := c.node(n.ElseList)
+	if elseDepth > depth {
+		depth = elseDepth
+	}
+	return depth, calls + elseCalls, err
+}
+
+func newWebhookPayloadTemplate(text string, event HookEventType) (*template.Template, error) {
+	if len(text) > maxPayloadTemplateSize {
+		return nil, fmt.Errorf("the template exceeds %d bytes", maxPayloadTemplateSize)
+	}
+	funcs := template.FuncMap{
+		"event": func() string {
+			return string(event)
+		},
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		// bound to the writer the template is executed into
+		payloadTemplateIterateFunc: func() (string, error) {
+			return "", nil
+		},
+	}
+	tmpl, err := template.New("payload").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &payloadTemplateChecker{
+		tmpl:     tmpl,
+		depths:   map[string]int{},
+		calls:    map[string]int{},
+		checking: map[string]bool{},
+	}
+	for _, t := range tmpl.Templates() {
+		depth, calls, err := c.check(t.Name())
+		if err != nil {
+			return nil, err
+		}
+		if depth > maxPayloadTemplateDepth {
+			return nil, fmt.Errorf("template %q is nested deeper than %d levels", t.Name(), maxPayloadTemplateDepth)
+		}
+		if calls > maxPayloadTemplateCalls {
+			return nil, fmt.Errorf("template %q calls more than %d templates", t.Name(), maxPayloadTemplateCalls)
+		}
+	}
+
+	iterate, err := template.New("").Funcs(funcs).Parse("{{" + payloadTemplateIterateFunc + "}}")
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			addIterateCalls(t.Tree.Root, iterate.Tree.Root.Nodes[0])
+		}
+	}
+	return tmpl, nil
+}
+
+// executeWebhookPayloadTemplate executes a payload template into a buffer of
+// limited size, and gives up once it iterates too many times or runs longer
+// than payloadTemplateTimeout
+func executeWebhookPayloadTemplate(tmpl *template.Template, fields map[string]interface{}) ([]byte, error) {
+	w := &limitedWriter{}
+	tmpl.Funcs(template.FuncMap{payloadTemplateIterateFunc: w.iterate})
+	done := make(chan error, 1)
+	go func() {
+		done <- tmpl.Execute(w, fields)
+	}()
+
+	timer := time.NewTimer(payloadTemplateTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			if w.err != nil {
+				return nil, w.err
+			}
+			return nil, err
+		}
+		return w.buf.Bytes(), nil
+	case <-timer.C:
+		// the template stops at its next write or iteration
+		w.close()
+		return nil, errPayloadTemplateTimeout
+	}
+}
+
+// CheckWebhookPayloadTemplate checks that the payload template of a webhook can
+// be parsed, and that it is neither too large nor too deeply nested
+func CheckWebhookPayloadTemplate(text string) error {
+	_, err := newWebhookPayloadTemplate(text, "")
+	return err
+}
+
+// RenderWebhookPayloadTemplate renders the body of a webhook for the event from its
+// payload template. The template is executed with the JSON fields of the payload,
+// and a template named after the event is used instead if the payload template
+// defines one. An empty body is returned when nothing but spaces is rendered.
+func RenderWebhookPayloadTemplate(text string, event HookEventType, p api.Payloader) ([]byte, error) {
+	tmpl, err := newWebhookPayloadTemplate(text, event)
+	if err != nil {
+		return nil, err
+	}
+	if t := tmpl.Lookup(string(event)); t != nil {
+		tmpl = t
+	}
+
+	data, err := p.JSONPayload()
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	// numbers are decoded as json.Number so that IDs are not printed as floats
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err = dec.Decode(&fields); err != nil {
+		return nil, err
+	}
+
+	content, err := executeWebhookPayloadTemplate(tmpl, fields)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(content)) == 0 {
+		return nil, nil
+	}
+	return content, nil
+}
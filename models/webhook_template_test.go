@@ -0,0 +1,120 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckWebhookPayloadTemplate(t *testing.T) {
+	assert.NoError(t, CheckWebhookPayloadTemplate(""))
+	assert.NoError(t, CheckWebhookPayloadTemplate(`{"text": {{json .repository.full_name}}, "event": "{{event}}"}`))
+	assert.Error(t, CheckWebhookPayloadTemplate(`{{if .repository}}`))
+	assert.Error(t, CheckWebhookPayloadTemplate(`{{unknown}}`))
+	assert.Error(t, CheckWebhookPayloadTemplate(strings.Repeat("a", maxPayloadTemplateSize+1)))
+
+	// recursive and exponentially expanding templates are refused
+	assert.Error(t, CheckWebhookPayloadTemplate(`{{define "a"}}{{template "b"}}{{end}}{{define "b"}}{{template "a"}}{{end}}`))
+	var buf strings.Builder
+	for i := 0; i < 8; i++ {
+		fmt.Fprintf(&buf, `{{define "t%d"}}{{template "t%d"}}{{template "t%d"}}{{end}}`, i, i+1, i+1)
+	}
+	buf.WriteString(`{{define "t8"}}x{{end}}`)
+	assert.NoError(t, CheckWebhookPayloadTemplate(`{{define "t7"}}{{template "t8"}}{{end}}{{define "t8"}}x{{end}}`))
+	err := CheckWebhookPayloadTemplate(buf.String())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "calls more than 100 templates")
+	}
+	assert.EqualError(t, CheckWebhookPayloadTemplate(strings.Repeat("{{if .}}", 11)+strings.Repeat("{{end}}", 11)),
+		`template "payload" is nested deeper than 10 levels`)
+}
+
+func TestRenderWebhookPayloadTemplate(t *testing.T) {
+	p := &api.LabelPayload{
+		Action: api.HookLabelCreated,
+		Label:  &api.Label{ID: 12345678, Name: `a "quoted" label`},
+		Repository: &api.Repository{
+			FullName: "user2/repo1",
+		},
+		Sender: &api.User{UserName: "user2"},
+	}
+
+	content, err := RenderWebhookPayloadTemplate(`{"event": "{{event}}", "id": {{.label.id}}, "text": {{json .label.name}}}`, HookEventLabel, p)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"event": "label", "id": 12345678, "text": "a \"quoted\" label"}`, string(content))
+
+	// the template named after the event is used instead of the main one
+	content, err = RenderWebhookPayloadTemplate(`main{{define "label"}}label {{.action}}{{end}}`, HookEventLabel, p)
+	assert.NoError(t, err)
+	assert.Equal(t, "label created", string(content))
+
+	content, err = RenderWebhookPayloadTemplate(` {{define "push"}}push{{end}}`, HookEventLabel, p)
+	assert.NoError(t, err)
+	assert.Nil(t, content)
+
+	// the size of the rendered payload is limited
+	push := &api.PushPayload{Commits: make([]*api.PayloadCommit, 20)}
+	for i := range push.Commits {
+		push.Commits[i] = &api.PayloadCommit{Message: strings.Repeat("m", 1000)}
+	}
+	text := strings.Repeat(`{{range $.commits}}`, 3) + `{{json $}}` + strings.Repeat(`{{end}}`, 3)
+	_, err = RenderWebhookPayloadTemplate(text, HookEventPush, push)
+	assert.Equal(t, errPayloadTooLarge, err)
+
+	// so are the iterations of the templates which write nothing
+	text = strings.Repeat(`{{range $.commits}}`, 5) + `{{if false}}x{{end}}` + strings.Repeat(`{{end}}`, 5)
+	_, err = RenderWebhookPayloadTemplate(text, HookEventPush, push)
+	assert.Equal(t, errPayloadTemplateIterations, err)
+	content, err = RenderWebhookPayloadTemplate(`{{range .commits}}{{else}}{{range .commits}}{{end}}{{end}}{{len .commits}}`, HookEventPush, push)
+	assert.NoError(t, err)
+	assert.Equal(t, "20", string(content))
+}
+
+func TestLimitedWriter_Iterate(t *testing.T) {
+	w := &limitedWriter{}
+	_, err := w.iterate()
+	assert.NoError(t, err)
+
+	// the iterations stop once the template timed out
+	w.close()
+	_, err = w.iterate()
+	assert.Equal(t, errPayloadTemplateTimeout, err)
+}
+
+func TestPrepareWebhooks_PayloadTemplate(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	hook := &Webhook{
+		RepoID:          1,
+		URL:             "www.example.com/template",
+		ContentType:     ContentTypeJSON,
+		Events:          `{"push_only":false,"send_everything":true,"choose_events":false,"events":{}}`,
+		IsActive:        true,
+		HookTaskType:    GITEA,
+		PayloadTemplate: `{{define "label"}}{"summary": "{{.sender.login}} {{.action}} {{.label.name}}"}{{end}}`,
+	}
+	assert.NoError(t, CreateWebhook(hook))
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.NoError(t, NewLabel(&Label{RepoID: 1, Name: "templated", Color: "#123456"}, doer))
+	task := AssertExistsAndLoadBean(t, &HookTask{RepoID: 1, HookID: hook.ID, EventType: HookEventLabel}).(*HookTask)
+	assert.Equal(t, `{"summary": "user2 created templated"}`, task.PayloadContent)
+
+	// events without a template of their own get the default payload
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	assert.NoError(t, PrepareWebhooks(repo, HookEventFork, &api.ForkPayload{
+		Forkee: repo.APIFormat(AccessModeNone),
+		Repo:   repo.APIFormat(AccessModeNone),
+		Sender: doer.APIFormat(),
+	}))
+	task = AssertExistsAndLoadBean(t, &HookTask{RepoID: 1, HookID: hook.ID, EventType: HookEventFork}).(*HookTask)
+	assert.Contains(t, task.PayloadContent, `"forkee": {`)
+}
@@ -73,7 +73,7 @@ func TestWebhook_UpdateEvent(t *testing.T) {
 }
 
 func TestWebhook_EventsArray(t *testing.T) {
-	assert.Equal(t, []string{"create", "delete", "fork", "push", "issues", "issue_comment", "pull_request", "repository", "release"},
+	assert.Equal(t, []string{"create", "delete", "fork", "push", "issues", "issue_comment", "pull_request", "repository", "release", "label", "wiki"},
 		(&Webhook{
 			HookEvent: &HookEvent{SendEverything: true},
 		}).EventsArray(),
@@ -198,6 +198,7 @@ func TestToHookTaskType(t *testing.T) {
 	assert.Equal(t, SLACK, ToHookTaskType("slack"))
 	assert.Equal(t, GITEA, ToHookTaskType("gitea"))
 	assert.Equal(t, TELEGRAM, ToHookTaskType("telegram"))
+	assert.Equal(t, MATRIX, ToHookTaskType("matrix"))
 }
 
 func TestHookTaskType_Name(t *testing.T) {
@@ -205,6 +206,7 @@ func TestHookTaskType_Name(t *testing.T) {
 	assert.Equal(t, "slack", SLACK.Name())
 	assert.Equal(t, "gitea", GITEA.Name())
 	assert.Equal(t, "telegram", TELEGRAM.Name())
+	assert.Equal(t, "matrix", MATRIX.Name())
 }
 
 func TestIsValidHookTaskType(t *testing.T) {
@@ -212,6 +214,7 @@ func TestIsValidHookTaskType(t *testing.T) {
 	assert.True(t, IsValidHookTaskType("slack"))
 	assert.True(t, IsValidHookTaskType("gitea"))
 	assert.True(t, IsValidHookTaskType("telegram"))
+	assert.True(t, IsValidHookTaskType("matrix"))
 	assert.False(t, IsValidHookTaskType("invalid"))
 }
 
@@ -270,6 +273,32 @@ func TestPrepareWebhooks(t *testing.T) {
 	}
 }
 
+func TestPrepareWebhooks_LabelEvent(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	hook := &Webhook{
+		RepoID:      1,
+		URL:         "www.example.com/label",
+		ContentType: ContentTypeJSON,
+		Events:      `{"push_only":false,"send_everything":false,"choose_events":true,"events":{"label":true}}`,
+		IsActive:    true,
+	}
+	assert.NoError(t, CreateWebhook(hook))
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	label := &Label{RepoID: 1, Name: "hooked", Color: "#123456"}
+	assert.NoError(t, NewLabel(label, doer))
+	AssertExistsAndLoadBean(t, &HookTask{RepoID: 1, HookID: hook.ID, EventType: HookEventLabel})
+	// the push only webhook does not receive the label event
+	AssertNotExistsBean(t, &HookTask{RepoID: 1, HookID: 1, EventType: HookEventLabel})
+
+	label.Name = "renamed"
+	assert.NoError(t, UpdateLabel(label, doer))
+	task := AssertExistsAndLoadBean(t, &HookTask{RepoID: 1, HookID: hook.ID, EventType: HookEventLabel},
+		Cond("payload_content LIKE ?", "%edited%")).(*HookTask)
+	assert.Contains(t, task.PayloadContent, `"from": "hooked"`)
+}
+
 // TODO TestHookTask_deliver
 
 // TODO TestDeliverHooks
@@ -13,6 +13,7 @@ import (
 
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/sync"
 
 	"github.com/unknwon/com"
@@ -234,17 +235,62 @@ func (repo *Repository) updateWikiPage(doer *User, oldWikiName, newWikiName, con
 
 // AddWikiPage adds a new wiki page with a given wikiPath.
 func (repo *Repository) AddWikiPage(doer *User, wikiName, content, message string) error {
-	return repo.updateWikiPage(doer, "", wikiName, content, message, true)
+	if err := repo.updateWikiPage(doer, "", wikiName, content, message, true); err != nil {
+		return err
+	}
+	repo.prepareWikiWebhooks(doer, &api.WikiPayload{
+		Action:  api.HookWikiCreated,
+		Page:    wikiName,
+		Comment: message,
+	})
+	return nil
 }
 
 // EditWikiPage updates a wiki page identified by its wikiPath,
 // optionally also changing wikiPath.
 func (repo *Repository) EditWikiPage(doer *User, oldWikiName, newWikiName, content, message string) error {
-	return repo.updateWikiPage(doer, oldWikiName, newWikiName, content, message, false)
+	if err := repo.updateWikiPage(doer, oldWikiName, newWikiName, content, message, false); err != nil {
+		return err
+	}
+	payload := &api.WikiPayload{
+		Action:  api.HookWikiEdited,
+		Page:    newWikiName,
+		Comment: message,
+	}
+	if oldWikiName != newWikiName {
+		payload.Changes = &api.ChangesPayload{
+			Title: &api.ChangesFromPayload{From: oldWikiName},
+		}
+	}
+	repo.prepareWikiWebhooks(doer, payload)
+	return nil
 }
 
 // DeleteWikiPage deletes a wiki page identified by its path.
-func (repo *Repository) DeleteWikiPage(doer *User, wikiName string) (err error) {
+func (repo *Repository) DeleteWikiPage(doer *User, wikiName string) error {
+	if err := repo.deleteWikiPage(doer, wikiName); err != nil {
+		return err
+	}
+	repo.prepareWikiWebhooks(doer, &api.WikiPayload{
+		Action: api.HookWikiDeleted,
+		Page:   wikiName,
+	})
+	return nil
+}
+
+// prepareWikiWebhooks sends the wiki event to the webhooks of the repository
+func (repo *Repository) prepareWikiWebhooks(doer *User, payload *api.WikiPayload) {
+	mode, _ := AccessLevel(doer, repo)
+	payload.Repository = repo.APIFormat(mode)
+	payload.Sender = doer.APIFormat()
+	if err := PrepareWebhooks(repo, HookEventWiki, payload); err != nil {
+		log.Error("PrepareWebhooks [repo_id: %d]: %v", repo.ID, err)
+	} else {
+		go HookQueue.Add(repo.ID)
+	}
+}
+
+func (repo *Repository) deleteWikiPage(doer *User, wikiName string) (err error) {
 	wikiWorkingPool.CheckIn(com.ToStr(repo.ID))
 	defer wikiWorkingPool.CheckOut(com.ToStr(repo.ID))
 
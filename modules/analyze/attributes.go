@@ -0,0 +1,51 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package analyze
+
+import (
+	"code.gitea.io/gitea/modules/git"
+)
+
+// The linguist attributes of the .gitattributes files overriding the
+// detection of the kind of the files
+const (
+	AttributeVendored      = "linguist-vendored"
+	AttributeGenerated     = "linguist-generated"
+	AttributeDocumentation = "linguist-documentation"
+	AttributeDetectable    = "linguist-detectable"
+	AttributeLanguage      = "linguist-language"
+)
+
+// IsVendored returns whether the file is vendored code according to its
+// attributes, or to its path if they do not specify it
+func IsVendored(attrs map[string]string, filepath string) bool {
+	return git.IsAttributeSet(attrs, AttributeVendored, IsVendor(filepath))
+}
+
+// IsGenerated returns whether the file is generated according to its
+// attributes
+func IsGenerated(attrs map[string]string) bool {
+	return git.IsAttributeSet(attrs, AttributeGenerated, false)
+}
+
+// IsDocumented returns whether the file is documentation according to its
+// attributes, or to its path if they do not specify it
+func IsDocumented(attrs map[string]string, filepath string) bool {
+	return git.IsAttributeSet(attrs, AttributeDocumentation, IsDocumentation(filepath))
+}
+
+// GetLanguage returns the language of the file according to its attributes,
+// or to its path if they do not specify it, and whether it is counted in the
+// language statistics of the repository
+func GetLanguage(attrs map[string]string, filepath string) (string, bool) {
+	language := attrs[AttributeLanguage]
+	if language == "" || language == git.AttributeSet {
+		language = GetCodeLanguage(filepath)
+	}
+	if language == "" {
+		return "", false
+	}
+	return language, git.IsAttributeSet(attrs, AttributeDetectable, !IsDataLanguage(language))
+}
@@ -0,0 +1,37 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package analyze
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinguistAttributes(t *testing.T) {
+	noAttrs := map[string]string{}
+	assert.True(t, IsVendored(noAttrs, "vendor/lib.go"))
+	assert.False(t, IsVendored(map[string]string{AttributeVendored: git.AttributeUnset}, "vendor/lib.go"))
+	assert.True(t, IsVendored(map[string]string{AttributeVendored: git.AttributeSet}, "lib.go"))
+
+	assert.False(t, IsGenerated(noAttrs))
+	assert.True(t, IsGenerated(map[string]string{AttributeGenerated: git.AttributeSet}))
+
+	assert.True(t, IsDocumented(noAttrs, "docs/index.md"))
+	assert.False(t, IsDocumented(map[string]string{AttributeDocumentation: git.AttributeUnset}, "docs/index.md"))
+
+	language, detectable := GetLanguage(noAttrs, "main.go")
+	assert.Equal(t, "Go", language)
+	assert.True(t, detectable)
+	language, detectable = GetLanguage(map[string]string{AttributeLanguage: "HTML"}, "home.tmpl")
+	assert.Equal(t, "HTML", language)
+	assert.True(t, detectable)
+	_, detectable = GetLanguage(map[string]string{AttributeDetectable: git.AttributeUnset}, "main.go")
+	assert.False(t, detectable)
+	_, detectable = GetLanguage(noAttrs, "unknown")
+	assert.False(t, detectable)
+}
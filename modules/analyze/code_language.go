@@ -0,0 +1,211 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package analyze
+
+import (
+	"path"
+	"strings"
+)
+
+var (
+	// languageFileNames maps the lower case names of the files which do not
+	// have a meaningful extension to their language
+	languageFileNames = map[string]string{
+		"dockerfile":     "Dockerfile",
+		"makefile":       "Makefile",
+		"gnumakefile":    "Makefile",
+		"cmakelists.txt": "CMake",
+		"rakefile":       "Ruby",
+		"gemfile":        "Ruby",
+		"vagrantfile":    "Ruby",
+		"jenkinsfile":    "Groovy",
+	}
+
+	// languageExtensions maps the lower case extensions of the files to their
+	// language
+	languageExtensions = map[string]string{
+		".ahk":         "AutoHotkey",
+		".applescript": "AppleScript",
+		".asm":         "Assembly",
+		".bash":        "Shell",
+		".bat":         "Batchfile",
+		".c":           "C",
+		".cc":          "C++",
+		".cjs":         "JavaScript",
+		".clj":         "Clojure",
+		".cljs":        "Clojure",
+		".cmake":       "CMake",
+		".coffee":      "CoffeeScript",
+		".cpp":         "C++",
+		".cs":          "C#",
+		".css":         "CSS",
+		".cxx":         "C++",
+		".d":           "D",
+		".dart":        "Dart",
+		".el":          "Emacs Lisp",
+		".elm":         "Elm",
+		".erl":         "Erlang",
+		".ex":          "Elixir",
+		".exs":         "Elixir",
+		".f":           "Fortran",
+		".f90":         "Fortran",
+		".fs":          "F#",
+		".go":          "Go",
+		".gradle":      "Gradle",
+		".groovy":      "Groovy",
+		".h":           "C",
+		".hh":          "C++",
+		".hpp":         "C++",
+		".hs":          "Haskell",
+		".htm":         "HTML",
+		".html":        "HTML",
+		".java":        "Java",
+		".jl":          "Julia",
+		".js":          "JavaScript",
+		".json":        "JSON",
+		".jsx":         "JavaScript",
+		".kt":          "Kotlin",
+		".kts":         "Kotlin",
+		".less":        "Less",
+		".lisp":        "Common Lisp",
+		".lua":         "Lua",
+		".m":           "Objective-C",
+		".md":          "Markdown",
+		".mjs":         "JavaScript",
+		".ml":          "OCaml",
+		".mm":          "Objective-C++",
+		".nim":         "Nim",
+		".php":         "PHP",
+		".pl":          "Perl",
+		".pm":          "Perl",
+		".proto":       "Protocol Buffer",
+		".ps1":         "PowerShell",
+		".psm1":        "PowerShell",
+		".py":          "Python",
+		".pyw":         "Python",
+		".r":           "R",
+		".rb":          "Ruby",
+		".rs":          "Rust",
+		".sass":        "Sass",
+		".scala":       "Scala",
+		".scss":        "SCSS",
+		".sh":          "Shell",
+		".sql":         "SQL",
+		".swift":       "Swift",
+		".tex":         "TeX",
+		".tmpl":        "Go Template",
+		".toml":        "TOML",
+		".ts":          "TypeScript",
+		".tsx":         "TypeScript",
+		".vb":          "Visual Basic",
+		".vue":         "Vue",
+		".xml":         "XML",
+		".yaml":        "YAML",
+		".yml":         "YAML",
+		".zsh":         "Shell",
+	}
+
+	// dataLanguages are the languages of the data and prose files, they are
+	// not counted in the language statistics of the repositories unless they
+	// are made detectable by the linguist-detectable attribute
+	dataLanguages = map[string]bool{
+		"JSON":            true,
+		"Markdown":        true,
+		"Protocol Buffer": true,
+		"SQL":             true,
+		"TeX":             true,
+		"TOML":            true,
+		"XML":             true,
+		"YAML":            true,
+	}
+
+	// languageColors maps the languages to the colors GitHub linguist uses
+	languageColors = map[string]string{
+		"AppleScript":   "#101f1f",
+		"Assembly":      "#6e4c13",
+		"AutoHotkey":    "#6594b9",
+		"Batchfile":     "#c1f12e",
+		"C":             "#555555",
+		"C#":            "#178600",
+		"C++":           "#f34b7d",
+		"CMake":         "#da3434",
+		"CSS":           "#563d7c",
+		"Clojure":       "#db5855",
+		"CoffeeScript":  "#244776",
+		"Common Lisp":   "#3fb68b",
+		"D":             "#ba595e",
+		"Dart":          "#00b4ab",
+		"Dockerfile":    "#384d54",
+		"Elixir":        "#6e4a7e",
+		"Elm":           "#60b5cc",
+		"Emacs Lisp":    "#c065db",
+		"Erlang":        "#b83998",
+		"F#":            "#b845fc",
+		"Fortran":       "#4d41b1",
+		"Go":            "#00add8",
+		"Go Template":   "#00add8",
+		"Gradle":        "#02303a",
+		"Groovy":        "#e69f56",
+		"HTML":          "#e34c26",
+		"Haskell":       "#5e5086",
+		"JSON":          "#292929",
+		"Java":          "#b07219",
+		"JavaScript":    "#f1e05a",
+		"Julia":         "#a270ba",
+		"Kotlin":        "#f18e33",
+		"Less":          "#1d365d",
+		"Lua":           "#000080",
+		"Makefile":      "#427819",
+		"Markdown":      "#083fa1",
+		"Nim":           "#37775b",
+		"OCaml":         "#3be133",
+		"Objective-C":   "#438eff",
+		"Objective-C++": "#6866fb",
+		"PHP":           "#4f5d95",
+		"Perl":          "#0298c3",
+		"PowerShell":    "#012456",
+		"Python":        "#3572a5",
+		"R":             "#198ce7",
+		"Ruby":          "#701516",
+		"Rust":          "#dea584",
+		"SCSS":          "#c6538c",
+		"Sass":          "#a53b70",
+		"Scala":         "#c22d40",
+		"Shell":         "#89e051",
+		"Swift":         "#ffac45",
+		"TeX":           "#3d6117",
+		"TypeScript":    "#2b7489",
+		"Visual Basic":  "#945db7",
+		"Vue":           "#2c3e50",
+	}
+)
+
+// defaultLanguageColor is the color of the languages without a known color
+const defaultLanguageColor = "#cccccc"
+
+// GetCodeLanguage returns the language of the file detected from its name,
+// an empty string if it is not known.
+func GetCodeLanguage(filename string) string {
+	name := strings.ToLower(path.Base(filename))
+	if lang, ok := languageFileNames[name]; ok {
+		return lang
+	}
+	return languageExtensions[path.Ext(name)]
+}
+
+// IsDataLanguage returns whether the language is the one of data or prose
+// files, which are not counted in the language statistics by default
+func IsDataLanguage(lang string) bool {
+	return dataLanguages[lang]
+}
+
+// GetLanguageColor returns the color of the language in the language
+// statistics
+func GetLanguageColor(lang string) string {
+	if color, ok := languageColors[lang]; ok {
+		return color
+	}
+	return defaultLanguageColor
+}
@@ -0,0 +1,26 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package analyze
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCodeLanguage(t *testing.T) {
+	assert.Equal(t, "Go", GetCodeLanguage("main.go"))
+	assert.Equal(t, "C++", GetCodeLanguage("src/lib/Foo.CPP"))
+	assert.Equal(t, "Dockerfile", GetCodeLanguage("build/Dockerfile"))
+	assert.Equal(t, "Makefile", GetCodeLanguage("GNUmakefile"))
+	assert.Equal(t, "", GetCodeLanguage("README"))
+	assert.Equal(t, "", GetCodeLanguage("image.png"))
+}
+
+func TestIsDataLanguage(t *testing.T) {
+	assert.True(t, IsDataLanguage(GetCodeLanguage("config.yml")))
+	assert.True(t, IsDataLanguage(GetCodeLanguage("README.md")))
+	assert.False(t, IsDataLanguage(GetCodeLanguage("main.go")))
+}
@@ -0,0 +1,34 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package analyze
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// vendorPathRegexp matches the paths of the files which are usually
+	// vendored, a subset of the ones of linguist
+	vendorPathRegexp = regexp.MustCompile(`(?i)(^|/)(vendor|vendors|node_modules|bower_components|third[-_]?party|3rd[-_]?party|external|deps|\.git|\.github|\.idea|\.vscode)/` +
+		`|(^|/)(jquery|bootstrap|modernizr|d3|moment|underscore|lodash)([.-][^/]*)?\.js$` +
+		`|\.min\.(js|css)$|(^|/)gradlew(\.bat)?$|(^|/)mvnw(\.cmd)?$|(^|/)configure$`)
+
+	// documentationPathRegexp matches the paths of the files which are
+	// usually documentation, a subset of the ones of linguist
+	documentationPathRegexp = regexp.MustCompile(`(?i)(^|/)(docs?|documentation|examples?|samples?|man)/` +
+		`|(^|/)(changelog|changes|contributing|copying|install|license|licence|news|readme|authors)(\.[^/]*)?$`)
+)
+
+// IsVendor returns whether the file is vendored code according to its path
+func IsVendor(filepath string) bool {
+	return vendorPathRegexp.MatchString(filepath)
+}
+
+// IsDocumentation returns whether the file is documentation according to its
+// path
+func IsDocumentation(filepath string) bool {
+	return documentationPathRegexp.MatchString(strings.TrimPrefix(filepath, "/"))
+}
@@ -0,0 +1,27 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package analyze
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsVendor(t *testing.T) {
+	assert.True(t, IsVendor("vendor/github.com/pkg/errors/errors.go"))
+	assert.True(t, IsVendor("web/node_modules/vue/index.js"))
+	assert.True(t, IsVendor("public/js/jquery-3.4.1.js"))
+	assert.True(t, IsVendor("public/js/app.min.js"))
+	assert.False(t, IsVendor("models/vendor.go"))
+	assert.False(t, IsVendor("public/js/index.js"))
+}
+
+func TestIsDocumentation(t *testing.T) {
+	assert.True(t, IsDocumentation("docs/content/install.md"))
+	assert.True(t, IsDocumentation("README.md"))
+	assert.True(t, IsDocumentation("examples/hello.go"))
+	assert.False(t, IsDocumentation("models/docs.go"))
+}
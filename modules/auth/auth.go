@@ -63,6 +63,7 @@ func SignedInID(ctx *macaron.Context, sess session.Store) int64 {
 				uid := CheckOAuthAccessToken(tokenSHA)
 				if uid != 0 {
 					ctx.Data["IsApiToken"] = true
+					ctx.Data["ApiTokenScope"] = models.AccessTokenScopeAll
 				}
 				return uid
 			}
@@ -78,6 +79,7 @@ func SignedInID(ctx *macaron.Context, sess session.Store) int64 {
 				log.Error("UpdateAccessToken: %v", err)
 			}
 			ctx.Data["IsApiToken"] = true
+			ctx.Data["ApiTokenScope"] = t.Scope
 			return t.UID
 		}
 	}
@@ -190,6 +192,7 @@ func SignedInUser(ctx *macaron.Context, sess session.Store) (*models.User, bool)
 			if uid != 0 {
 				var err error
 				ctx.Data["IsApiToken"] = true
+				ctx.Data["ApiTokenScope"] = models.AccessTokenScopeAll
 
 				u, err = models.GetUserByID(uid)
 				if err != nil {
@@ -219,6 +222,7 @@ func SignedInUser(ctx *macaron.Context, sess session.Store) (*models.User, bool)
 				if err = models.UpdateAccessToken(token); err != nil {
 					log.Error("UpdateAccessToken:  %v", err)
 				}
+				ctx.Data["ApiTokenScope"] = token.Scope
 			} else if !models.IsErrAccessTokenNotExist(err) && !models.IsErrAccessTokenEmpty(err) {
 				log.Error("GetAccessTokenBySha: %v", err)
 			}
@@ -40,6 +40,12 @@ type UpdateOrgSettingForm struct {
 	Location        string `binding:"MaxSize(50)"`
 	Visibility      structs.VisibleType
 	MaxRepoCreation int
+
+	RepoDefaultBranch     string `binding:"OmitEmpty;GitRefName;MaxSize(100)"`
+	RepoDefaultVisibility string `binding:"In(,private,public)"`
+	RepoDefaultAutoInit   bool
+	RepoDefaultGitignores string
+	RepoDefaultLicense    string
 }
 
 // Validate validates the fields
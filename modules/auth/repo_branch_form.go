@@ -18,3 +18,14 @@ type NewBranchForm struct {
 func (f *NewBranchForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+// DeleteStaleBranchesForm form for deleting stale branches
+type DeleteStaleBranchesForm struct {
+	Branches []string `binding:"Required"`
+	Days     int      `binding:"Range(0,3650)"`
+}
+
+// Validate validates the fields
+func (f *DeleteStaleBranchesForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
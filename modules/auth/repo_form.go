@@ -35,6 +35,14 @@ type CreateRepoForm struct {
 	Gitignores  string
 	License     string
 	Readme      string
+	// DefaultBranch is the default of the owner if empty
+	DefaultBranch string `binding:"OmitEmpty;GitRefName;MaxSize(100)"`
+
+	RepoTemplate int64
+	GitContent   bool
+	Topics       bool
+	Labels       bool
+	Webhooks     bool
 }
 
 // Validate validates the fields
@@ -96,6 +104,33 @@ func (f MigrateRepoForm) ParseRemoteAddr(user *models.User) (string, error) {
 	return remoteAddr, nil
 }
 
+// ParsePushMirrorAddress checks if the address of a push mirror is a valid
+// HTTP/HTTPS/Git URL, or SSH URL if allowSSH, and returns it with the username
+// and password. The password is ignored for SSH URLs, which authenticate with
+// the mirror SSH key of the repository.
+func ParsePushMirrorAddress(addr, username, password string, allowSSH bool) (string, error) {
+	addr = strings.TrimSpace(addr)
+	isSSH := allowSSH && strings.HasPrefix(addr, "ssh://")
+	if !strings.HasPrefix(addr, "http://") &&
+		!strings.HasPrefix(addr, "https://") &&
+		!strings.HasPrefix(addr, "git://") &&
+		!isSSH {
+		return "", models.ErrInvalidCloneAddr{IsURLError: true}
+	}
+	u, err := url.Parse(addr)
+	if err != nil || u.Host == "" {
+		return "", models.ErrInvalidCloneAddr{IsURLError: true}
+	}
+	if isSSH {
+		if len(username) > 0 {
+			u.User = url.User(username)
+		}
+	} else if len(username)+len(password) > 0 {
+		u.User = url.UserPassword(username, password)
+	}
+	return u.String(), nil
+}
+
 // RepoSettingForm form for changing repository settings
 type RepoSettingForm struct {
 	RepoName       string `binding:"Required;AlphaDashDot;MaxSize(100)"`
@@ -107,26 +142,49 @@ type RepoSettingForm struct {
 	MirrorPassword string
 	Private        bool
 	EnablePrune    bool
+	Template       bool
+
+	// Push mirror settings
+	PushMirrorID           int64
+	PushMirrorAddress      string
+	PushMirrorUsername     string
+	PushMirrorPassword     string
+	PushMirrorInterval     string
+	PushMirrorSyncOnCommit bool
 
 	// Advanced settings
-	EnableWiki                       bool
-	EnableExternalWiki               bool
-	ExternalWikiURL                  string
-	EnableIssues                     bool
-	EnableExternalTracker            bool
-	ExternalTrackerURL               string
-	TrackerURLFormat                 string
-	TrackerIssueStyle                string
-	EnablePulls                      bool
-	PullsIgnoreWhitespace            bool
-	PullsAllowMerge                  bool
-	PullsAllowRebase                 bool
-	PullsAllowRebaseMerge            bool
-	PullsAllowSquash                 bool
-	EnableTimetracker                bool
-	AllowOnlyContributorsToTrackTime bool
-	EnableIssueDependencies          bool
-	IsArchived                       bool
+	EnableWiki                        bool
+	EnableExternalWiki                bool
+	ExternalWikiURL                   string
+	EnableIssues                      bool
+	EnableExternalTracker             bool
+	ExternalTrackerURL                string
+	TrackerURLFormat                  string
+	TrackerIssueStyle                 string
+	EnablePulls                       bool
+	PullsIgnoreWhitespace             bool
+	PullsAllowMerge                   bool
+	PullsAllowRebase                  bool
+	PullsAllowRebaseMerge             bool
+	PullsAllowSquash                  bool
+	PullsAllowFastForwardOnly         bool
+	PullsCancelAutoMergeOnPush        bool
+	PullsDefaultMergeMessageTemplate  string
+	PullsDefaultSquashMessageTemplate string
+	PullsMaxChangedFiles              int
+	PullsMaxChangedLines              int
+	PullsBlockOversizedMerge          bool
+	PullsCommitSubjectPattern         string
+	PullsCommitMaxLineLength          int
+	PullsCommitRequireIssueRef        bool
+	PullsReviewerTeamID               int64
+	PullsReviewerAssignmentStrategy   string
+	PullsReviewerCount                int
+	PullsReviewerSkipDrafts           bool
+	EnableTimetracker                 bool
+	AllowOnlyContributorsToTrackTime  bool
+	EnableIssueDependencies           bool
+	IsArchived                        bool
 
 	// Admin settings
 	EnableHealthCheck                     bool
@@ -147,16 +205,24 @@ func (f *RepoSettingForm) Validate(ctx *macaron.Context, errs binding.Errors) bi
 
 // ProtectBranchForm form for changing protected branch settings
 type ProtectBranchForm struct {
-	Protected               bool
-	EnableWhitelist         bool
-	WhitelistUsers          string
-	WhitelistTeams          string
-	EnableMergeWhitelist    bool
-	MergeWhitelistUsers     string
-	MergeWhitelistTeams     string
-	RequiredApprovals       int64
-	ApprovalsWhitelistUsers string
-	ApprovalsWhitelistTeams string
+	Protected                      bool
+	EnableWhitelist                bool
+	WhitelistUsers                 string
+	WhitelistTeams                 string
+	EnableMergeWhitelist           bool
+	MergeWhitelistUsers            string
+	MergeWhitelistTeams            string
+	RequiredApprovals              int64
+	EnableApprovalsWhitelist       bool
+	RequireCodeOwnerApproval       bool
+	DismissStaleApprovals          bool
+	ApprovalsWhitelistUsers        string
+	ApprovalsWhitelistTeams        string
+	EnableMergeQueue               bool
+	EnableStatusCheck              bool
+	StatusCheckContexts            string
+	BlockOnUnresolvedConversations bool
+	BlockOnOutdatedBranch          bool
 }
 
 // Validate validates the fields
@@ -164,6 +230,18 @@ func (f *ProtectBranchForm) Validate(ctx *macaron.Context, errs binding.Errors)
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// ProtectTagForm form for changing a protected tag
+type ProtectTagForm struct {
+	NamePattern    string `binding:"Required;MaxSize(255)"`
+	WhitelistUsers string
+	WhitelistTeams string
+}
+
+// Validate validates the fields
+func (f *ProtectTagForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 //  __      __      ___.   .__    .__            __
 // /  \    /  \ ____\_ |__ |  |__ |  |__   ____ |  | __
 // \   \/\/   // __ \| __ \|  |  \|  |  \ /  _ \|  |/ /
@@ -183,6 +261,8 @@ type WebhookForm struct {
 	Push         bool
 	PullRequest  bool
 	Repository   bool
+	Label        bool
+	Wiki         bool
 	Active       bool
 }
 
@@ -203,10 +283,11 @@ func (f WebhookForm) ChooseEvents() bool {
 
 // NewWebhookForm form for creating web hook
 type NewWebhookForm struct {
-	PayloadURL  string `binding:"Required;ValidUrl"`
-	HTTPMethod  string `binding:"Required;In(POST,GET)"`
-	ContentType int    `binding:"Required"`
-	Secret      string
+	PayloadURL      string `binding:"Required;ValidUrl"`
+	HTTPMethod      string `binding:"Required;In(POST,GET)"`
+	ContentType     int    `binding:"Required"`
+	Secret          string
+	PayloadTemplate string
 	WebhookForm
 }
 
@@ -295,6 +376,20 @@ func (f *NewMSTeamsHookForm) Validate(ctx *macaron.Context, errs binding.Errors)
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// NewMatrixHookForm form for creating matrix hook
+type NewMatrixHookForm struct {
+	HomeserverURL string `binding:"Required;ValidUrl"`
+	RoomID        string `binding:"Required"`
+	AccessToken   string `binding:"Required"`
+	MessageFormat string `binding:"In(formatted,plain)"`
+	WebhookForm
+}
+
+// Validate validates the fields
+func (f *NewMatrixHookForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // .___
 // |   | ______ ________ __   ____
 // |   |/  ___//  ___/  |  \_/ __ \
@@ -304,14 +399,17 @@ func (f *NewMSTeamsHookForm) Validate(ctx *macaron.Context, errs binding.Errors)
 
 // CreateIssueForm form for creating issue
 type CreateIssueForm struct {
-	Title       string `binding:"Required;MaxSize(255)"`
-	LabelIDs    string `form:"label_ids"`
-	AssigneeIDs string `form:"assignee_ids"`
-	Ref         string `form:"ref"`
-	MilestoneID int64
-	AssigneeID  int64
-	Content     string
-	Files       []string
+	Title               string `binding:"Required;MaxSize(255)"`
+	LabelIDs            string `form:"label_ids"`
+	AssigneeIDs         string `form:"assignee_ids"`
+	Ref                 string `form:"ref"`
+	MilestoneID         int64
+	AssigneeID          int64
+	Content             string
+	Files               []string
+	IssueTemplate       string
+	Draft               bool
+	AllowMaintainerEdit bool
 }
 
 // Validate validates the fields
@@ -399,6 +497,7 @@ type CreateLabelForm struct {
 	Title       string `binding:"Required;MaxSize(50)" locale:"repo.issues.label_title"`
 	Description string `binding:"MaxSize(200)" locale:"repo.issues.label_description"`
 	Color       string `binding:"Required;Size(7)" locale:"repo.issues.label_color"`
+	Priority    int    `locale:"repo.issues.label_priority"`
 }
 
 // Validate validates the fields
@@ -427,10 +526,12 @@ func (f *InitializeLabelsForm) Validate(ctx *macaron.Context, errs binding.Error
 // swagger:model MergePullRequestOption
 type MergePullRequestForm struct {
 	// required: true
-	// enum: merge,rebase,rebase-merge,squash
-	Do                string `binding:"Required;In(merge,rebase,rebase-merge,squash)"`
+	// enum: merge,rebase,rebase-merge,squash,fast-forward-only
+	Do                string `binding:"Required;In(merge,rebase,rebase-merge,squash,fast-forward-only)"`
 	MergeTitleField   string
 	MergeMessageField string
+	// schedule the merge for when the status checks and approvals are green
+	MergeWhenChecksSucceed bool
 }
 
 // Validate validates the fields
@@ -440,12 +541,13 @@ func (f *MergePullRequestForm) Validate(ctx *macaron.Context, errs binding.Error
 
 // CodeCommentForm form for adding code comments for PRs
 type CodeCommentForm struct {
-	Content  string `binding:"Required"`
-	Side     string `binding:"Required;In(previous,proposed)"`
-	Line     int64
-	TreePath string `form:"path" binding:"Required"`
-	IsReview bool   `form:"is_review"`
-	Reply    int64  `form:"reply"`
+	Content   string `binding:"Required"`
+	Side      string `binding:"Required;In(previous,proposed)"`
+	Line      int64
+	StartLine int64  `form:"start_line"`
+	TreePath  string `form:"path" binding:"Required"`
+	IsReview  bool   `form:"is_review"`
+	Reply     int64  `form:"reply"`
 }
 
 // Validate validates the fields
@@ -486,6 +588,30 @@ func (f SubmitReviewForm) HasEmptyContent() bool {
 		len(strings.TrimSpace(f.Content)) == 0
 }
 
+// ApplySuggestionsForm form for applying suggestions of code comments
+type ApplySuggestionsForm struct {
+	CommentIDs string `binding:"Required"`
+	Message    string
+}
+
+// Validate validates the fields
+func (f *ApplySuggestionsForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// ResolveConflictsForm form for resolving the conflicts of a pull request
+type ResolveConflictsForm struct {
+	LastCommitID  string `binding:"Required"`
+	CommitMessage string
+	TreePaths     []string
+	Contents      []string
+}
+
+// Validate validates the fields
+func (f *ResolveConflictsForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // __________       .__
 // \______   \ ____ |  |   ____ _____    ______ ____
 //  |       _// __ \|  | _/ __ \\__  \  /  ___// __ \
@@ -630,6 +756,20 @@ func (f *DeleteRepoFileForm) Validate(ctx *macaron.Context, errs binding.Errors)
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// CherryPickForm form for cherry-picking or reverting a commit on a branch
+type CherryPickForm struct {
+	Mainline      int
+	CommitSummary string `binding:"MaxSize(100)"`
+	CommitMessage string
+	CommitChoice  string `binding:"Required;MaxSize(50)"`
+	NewBranchName string `binding:"GitRefName;MaxSize(100)"`
+}
+
+// Validate validates the fields
+func (f *CherryPickForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // ___________.__                 ___________                     __
 // \__    ___/|__| _____   ____   \__    ___/___________    ____ |  | __ ___________
 // |    |   |  |/     \_/ __ \    |    |  \_  __ \__  \ _/ ___\|  |/ // __ \_  __ \
@@ -94,6 +94,29 @@ func GetInt64(key string, getFunc func() (int64, error)) (int64, error) {
 	}
 }
 
+// IsEnabled returns true if values can be stored in the cache
+func IsEnabled() bool {
+	return conn != nil && setting.CacheService.TTL != 0
+}
+
+// GetString returns the string value of key in the cache, and false if it is
+// not cached
+func GetString(key string) (string, bool) {
+	if !IsEnabled() || !conn.IsExist(key) {
+		return "", false
+	}
+	value, ok := conn.Get(key).(string)
+	return value, ok
+}
+
+// PutString stores the string value of key in the cache
+func PutString(key, value string) error {
+	if !IsEnabled() {
+		return nil
+	}
+	return conn.Put(key, value, int64(setting.CacheService.TTL.Seconds()))
+}
+
 // Remove key from cache
 func Remove(key string) {
 	if conn == nil {
@@ -0,0 +1,218 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package citation
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// FileName is the name of the citation file in the root of a repository
+const FileName = "CITATION.cff"
+
+// Author represents a person or an entity of the authors of a citation
+type Author struct {
+	FamilyNames  string `yaml:"family-names"`
+	GivenNames   string `yaml:"given-names"`
+	NameParticle string `yaml:"name-particle"`
+	NameSuffix   string `yaml:"name-suffix"`
+	// Name is the name of an entity
+	Name        string `yaml:"name"`
+	Affiliation string `yaml:"affiliation"`
+	ORCID       string `yaml:"orcid"`
+}
+
+// Citation represents the metadata of a Citation File Format file
+type Citation struct {
+	CFFVersion     string    `yaml:"cff-version"`
+	Message        string    `yaml:"message"`
+	Title          string    `yaml:"title"`
+	Version        string    `yaml:"version"`
+	DOI            string    `yaml:"doi"`
+	DateReleased   string    `yaml:"date-released"`
+	URL            string    `yaml:"url"`
+	RepositoryCode string    `yaml:"repository-code"`
+	License        string    `yaml:"license"`
+	Abstract       string    `yaml:"abstract"`
+	Keywords       []string  `yaml:"keywords"`
+	Authors        []*Author `yaml:"authors"`
+}
+
+var (
+	dateRegexp   = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})`)
+	bibKeyRegexp = regexp.MustCompile(`[^\pL\pN]+`)
+)
+
+// Parse parses the content of a Citation File Format file, its title is required
+func Parse(r io.Reader) (*Citation, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	c := &Citation{}
+	if err = yaml.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	if c.Title == "" {
+		return nil, fmt.Errorf("title is missing")
+	}
+	return c, nil
+}
+
+// Year returns the year of the release, an empty string if it is unknown
+func (c *Citation) Year() string {
+	if m := dateRegexp.FindStringSubmatch(c.DateReleased); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// Link returns the link of the cited software, its DOI takes precedence
+func (c *Citation) Link() string {
+	switch {
+	case c.DOI != "":
+		return "https://doi.org/" + c.DOI
+	case c.URL != "":
+		return c.URL
+	}
+	return c.RepositoryCode
+}
+
+// familyName returns the family name of a person with its particle, or the
+// name of an entity
+func (a *Author) familyName() string {
+	if a.FamilyNames == "" {
+		return a.Name
+	}
+	return strings.TrimSpace(a.NameParticle + " " + a.FamilyNames)
+}
+
+// initials returns the initials of the given names of a person
+func (a *Author) initials() string {
+	var initials []string
+	for _, name := range strings.Fields(a.GivenNames) {
+		var parts []string
+		for _, part := range strings.Split(name, "-") {
+			if r := []rune(part); len(r) > 0 {
+				parts = append(parts, string(r[0])+".")
+			}
+		}
+		initials = append(initials, strings.Join(parts, "-"))
+	}
+	return strings.Join(initials, " ")
+}
+
+// bibTeXName returns the name of the author as BibTeX expects it
+func (a *Author) bibTeXName() string {
+	if a.FamilyNames == "" {
+		// braces keep the name of an entity from being split
+		return "{" + a.Name + "}"
+	}
+	name := a.familyName()
+	if a.NameSuffix != "" {
+		name += ", " + a.NameSuffix
+	}
+	if a.GivenNames != "" {
+		name += ", " + a.GivenNames
+	}
+	return name
+}
+
+// apaName returns the name of the author as the APA style expects it
+func (a *Author) apaName() string {
+	name := a.familyName()
+	if initials := a.initials(); initials != "" {
+		name += ", " + initials
+	}
+	if a.NameSuffix != "" {
+		name += ", " + a.NameSuffix
+	}
+	return name
+}
+
+// BibTeXKey returns the key of the BibTeX entry, made of the family name of
+// the first author and the year of the release
+func (c *Citation) BibTeXKey() string {
+	var key string
+	if len(c.Authors) > 0 {
+		key = c.Authors[0].familyName()
+	}
+	if key == "" {
+		key = c.Title
+	}
+	key = strings.ToLower(bibKeyRegexp.ReplaceAllString(key, "_"))
+	return strings.Trim(key, "_") + c.Year()
+}
+
+// BibTeX returns the BibTeX entry of the citation
+func (c *Citation) BibTeX() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "@software{%s,\n", c.BibTeXKey())
+	writeField := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&buf, "  %s = {%s},\n", name, value)
+		}
+	}
+
+	var authors = make([]string, 0, len(c.Authors))
+	for _, author := range c.Authors {
+		authors = append(authors, author.bibTeXName())
+	}
+	writeField("author", strings.Join(authors, " and "))
+	writeField("title", c.Title)
+	writeField("version", c.Version)
+	writeField("doi", c.DOI)
+	if c.URL != "" {
+		writeField("url", c.URL)
+	} else {
+		writeField("url", c.RepositoryCode)
+	}
+	if m := dateRegexp.FindStringSubmatch(c.DateReleased); m != nil {
+		writeField("year", m[1])
+		writeField("month", m[2])
+	}
+	writeField("license", c.License)
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// APA returns the reference of the citation in the APA style
+func (c *Citation) APA() string {
+	var names = make([]string, 0, len(c.Authors))
+	for _, author := range c.Authors {
+		names = append(names, author.apaName())
+	}
+
+	var buf strings.Builder
+	switch len(names) {
+	case 0:
+	case 1:
+		buf.WriteString(names[0] + " ")
+	case 2:
+		buf.WriteString(names[0] + ", & " + names[1] + " ")
+	default:
+		buf.WriteString(strings.Join(names[:len(names)-1], ", ") + ", & " + names[len(names)-1] + " ")
+	}
+
+	if year := c.Year(); year != "" {
+		fmt.Fprintf(&buf, "(%s). ", year)
+	} else {
+		buf.WriteString("(n.d.). ")
+	}
+	buf.WriteString(c.Title)
+	if c.Version != "" {
+		fmt.Fprintf(&buf, " (Version %s)", c.Version)
+	}
+	buf.WriteString(" [Computer software].")
+	if link := c.Link(); link != "" {
+		buf.WriteString(" " + link)
+	}
+	return buf.String()
+}
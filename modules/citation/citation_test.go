@@ -0,0 +1,72 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package citation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testCitation = `cff-version: 1.1.0
+message: "If you use this software, please cite it as below."
+authors:
+  - family-names: Druskat
+    given-names: Stephan
+    orcid: https://orcid.org/0000-0003-4925-7248
+  - family-names: Hong
+    name-particle: Chue
+    given-names: Neil P.
+  - name: The Gitea Authors
+title: "Gitea"
+version: 1.10.0
+doi: 10.5281/zenodo.1234
+date-released: 2019-11-14
+repository-code: https://github.com/go-gitea/gitea
+license: MIT
+`
+
+func TestParse(t *testing.T) {
+	c, err := Parse(strings.NewReader(testCitation))
+	assert.NoError(t, err)
+	assert.Equal(t, "Gitea", c.Title)
+	assert.Equal(t, "2019-11-14", c.DateReleased)
+	assert.Equal(t, "2019", c.Year())
+	assert.Equal(t, "https://doi.org/10.5281/zenodo.1234", c.Link())
+	assert.Len(t, c.Authors, 3)
+	assert.Equal(t, "Chue", c.Authors[1].NameParticle)
+
+	_, err = Parse(strings.NewReader("cff-version: 1.1.0\n"))
+	assert.Error(t, err)
+	_, err = Parse(strings.NewReader("title: [unclosed"))
+	assert.Error(t, err)
+}
+
+func TestCitation_BibTeX(t *testing.T) {
+	c, err := Parse(strings.NewReader(testCitation))
+	assert.NoError(t, err)
+	assert.Equal(t, `@software{druskat2019,
+  author = {Druskat, Stephan and Chue Hong, Neil P. and {The Gitea Authors}},
+  title = {Gitea},
+  version = {1.10.0},
+  doi = {10.5281/zenodo.1234},
+  url = {https://github.com/go-gitea/gitea},
+  year = {2019},
+  month = {11},
+  license = {MIT},
+}
+`, c.BibTeX())
+}
+
+func TestCitation_APA(t *testing.T) {
+	c, err := Parse(strings.NewReader(testCitation))
+	assert.NoError(t, err)
+	assert.Equal(t, "Druskat, S., Chue Hong, N. P., & The Gitea Authors (2019). Gitea (Version 1.10.0) [Computer software]. https://doi.org/10.5281/zenodo.1234", c.APA())
+
+	c = &Citation{Title: "Tool", Authors: []*Author{{FamilyNames: "Doe", GivenNames: "Jean-Paul"}}}
+	assert.Equal(t, "Doe, J.-P. (n.d.). Tool [Computer software].", c.APA())
+	assert.Equal(t, "doe", c.BibTeXKey())
+}
@@ -159,6 +159,16 @@ func (ctx *APIContext) CheckForOTP() {
 	}
 }
 
+// HasTokenScope returns true if the request is not authenticated with an
+// access token, or if the token has the given scope
+func (ctx *APIContext) HasTokenScope(scope models.AccessTokenScope) bool {
+	tokenScope, ok := ctx.Data["ApiTokenScope"].(models.AccessTokenScope)
+	if !ok {
+		return true
+	}
+	return tokenScope.Has(scope)
+}
+
 // APIContexter returns apicontext as macaron middleware
 func APIContexter() macaron.Handler {
 	return func(c *Context) {
@@ -60,6 +60,23 @@ func (r *Repository) CanEnableEditor() bool {
 	return r.Permission.CanWrite(models.UnitTypeCode) && r.Repository.CanEnableEditor() && r.IsViewBranch && !r.Repository.IsArchived
 }
 
+// CanProposeByFork returns true if the user cannot write to the repository but
+// can edit its files in the web editor by committing the changes to a fork and
+// opening a pull request
+func (r *Repository) CanProposeByFork(doer *models.User) bool {
+	if doer == nil || r.Permission.CanWrite(models.UnitTypeCode) || !r.Permission.CanRead(models.UnitTypeCode) {
+		return false
+	}
+	if !r.Repository.CanEnableEditor() || !r.IsViewBranch || r.Repository.IsArchived ||
+		r.Repository.OwnerID == doer.ID || !r.Repository.AllowsPulls() {
+		return false
+	}
+	if _, has := models.HasForkedRepo(doer.ID, r.Repository.ID); has {
+		return true
+	}
+	return doer.CanCreateRepo()
+}
+
 // CanCreateBranch returns true if repository is editable and user has proper access level.
 func (r *Repository) CanCreateBranch() bool {
 	return r.Permission.CanWrite(models.UnitTypeCode) && r.Repository.CanCreateBranch()
@@ -186,6 +203,30 @@ func RetrieveBaseRepo(ctx *Context, repo *models.Repository) {
 	}
 }
 
+// RetrieveTemplateRepo retrieves the template repository the repository has
+// been generated from, if the user can read it
+func RetrieveTemplateRepo(ctx *Context, repo *models.Repository) {
+	templateRepo, err := repo.GetTemplateRepo()
+	if err != nil {
+		ctx.ServerError("GetTemplateRepo", err)
+		return
+	} else if templateRepo == nil {
+		return
+	} else if err = templateRepo.GetOwner(); err != nil {
+		ctx.ServerError("TemplateRepo.GetOwner", err)
+		return
+	}
+
+	perm, err := models.GetUserRepoPermission(templateRepo, ctx.User)
+	if err != nil {
+		ctx.ServerError("GetUserRepoPermission", err)
+		return
+	}
+	if perm.CanRead(models.UnitTypeCode) {
+		ctx.Data["TemplateRepo"] = templateRepo
+	}
+}
+
 // ComposeGoGetImport returns go-get-import meta content.
 func ComposeGoGetImport(owner, repo string) string {
 	/// setting.AppUrl is guaranteed to be parse as url
@@ -239,9 +280,10 @@ func repoAssignment(ctx *Context, repo *models.Repository) {
 		return
 	}
 
-	ctx.Repo.Permission, err = models.GetUserRepoPermission(repo, ctx.User)
+	var repoTransfer *models.RepoTransfer
+	ctx.Repo.Permission, repoTransfer, err = models.GetUserRepoPermissionWithTransfer(repo, ctx.User)
 	if err != nil {
-		ctx.ServerError("GetUserRepoPermission", err)
+		ctx.ServerError("GetUserRepoPermissionWithTransfer", err)
 		return
 	}
 
@@ -257,6 +299,15 @@ func repoAssignment(ctx *Context, repo *models.Repository) {
 	ctx.Data["HasAccess"] = true
 	ctx.Data["Permission"] = &ctx.Repo.Permission
 
+	if repoTransfer != nil {
+		if err = repoTransfer.LoadAttributes(); err != nil {
+			ctx.ServerError("LoadAttributes", err)
+			return
+		}
+		ctx.Data["RepoTransfer"] = repoTransfer
+		ctx.Data["CanUserAcceptTransfer"] = ctx.User != nil && repoTransfer.CanUserAcceptTransfer(ctx.User)
+	}
+
 	if repo.IsMirror {
 		var err error
 		ctx.Repo.Mirror, err = models.GetMirrorByRepoID(repo.ID)
@@ -408,7 +459,10 @@ func RepoAssignment() macaron.Handler {
 		ctx.Data["WikiCloneLink"] = repo.WikiCloneLink()
 
 		if ctx.IsSigned {
-			ctx.Data["IsWatchingRepo"] = models.IsWatching(ctx.User.ID, repo.ID)
+			watchMode := models.GetWatchMode(ctx.User.ID, repo.ID)
+			ctx.Data["IsWatchingRepo"] = watchMode != models.RepoWatchModeNone
+			ctx.Data["WatchMode"] = watchMode
+			ctx.Data["WatchModes"] = models.RepoWatchModes
 			ctx.Data["IsStaringRepo"] = models.IsStaring(ctx.User.ID, repo.ID)
 		}
 
@@ -419,6 +473,13 @@ func RepoAssignment() macaron.Handler {
 			}
 		}
 
+		if repo.TemplateID > 0 {
+			RetrieveTemplateRepo(ctx, repo)
+			if ctx.Written() {
+				return
+			}
+		}
+
 		// repo is empty and display enable
 		if ctx.Repo.Repository.IsEmpty {
 			ctx.Data["BranchName"] = ctx.Repo.Repository.DefaultBranch
@@ -23,6 +23,9 @@ const (
 	archiveCleanup         = "archive_cleanup"
 	syncExternalUsers      = "sync_external_users"
 	deletedBranchesCleanup = "deleted_branches_cleanup"
+	issueReminders         = "issue_reminders"
+	attachmentUploads      = "attachment_uploads_cleanup"
+	repoHousekeeping       = "repo_housekeeping"
 )
 
 var c = cron.New()
@@ -116,6 +119,39 @@ func NewContext() {
 			go WithUnique(deletedBranchesCleanup, models.RemoveOldDeletedBranches)()
 		}
 	}
+	if setting.Cron.IssueReminders.Enabled {
+		entry, err = c.AddFunc("Send due issue reminders", setting.Cron.IssueReminders.Schedule, WithUnique(issueReminders, models.SendDueIssueReminders))
+		if err != nil {
+			log.Fatal("Cron[Send due issue reminders]: %v", err)
+		}
+		if setting.Cron.IssueReminders.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go WithUnique(issueReminders, models.SendDueIssueReminders)()
+		}
+	}
+	if setting.Cron.AttachmentUploadsCleanup.Enabled {
+		entry, err = c.AddFunc("Delete stale attachment uploads", setting.Cron.AttachmentUploadsCleanup.Schedule, WithUnique(attachmentUploads, models.DeleteStaleAttachmentUploads))
+		if err != nil {
+			log.Fatal("Cron[Delete stale attachment uploads]: %v", err)
+		}
+		if setting.Cron.AttachmentUploadsCleanup.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go WithUnique(attachmentUploads, models.DeleteStaleAttachmentUploads)()
+		}
+	}
+	if setting.Cron.RepoHousekeeping.Enabled {
+		entry, err = c.AddFunc("Repository housekeeping", setting.Cron.RepoHousekeeping.Schedule, WithUnique(repoHousekeeping, models.RepoHousekeepings))
+		if err != nil {
+			log.Fatal("Cron[Repository housekeeping]: %v", err)
+		}
+		if setting.Cron.RepoHousekeeping.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go WithUnique(repoHousekeeping, models.RepoHousekeepings)()
+		}
+	}
 	c.Start()
 }
 
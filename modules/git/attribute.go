@@ -0,0 +1,183 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bufio"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// The values of the attributes which are set or unset without a value
+const (
+	AttributeSet   = "true"
+	AttributeUnset = "false"
+)
+
+// Attributes represents the attributes set by a .gitattributes file
+type Attributes struct {
+	rules []attributeRule
+}
+
+type attributeRule struct {
+	pattern *regexp.Regexp
+	// attributes maps the names of the attributes to their values, an empty
+	// value means that the attribute is unspecified
+	attributes map[string]string
+}
+
+// ParseAttributes parses the content of a .gitattributes file of the root of
+// a repository
+func ParseAttributes(r io.Reader) (*Attributes, error) {
+	attrs := &Attributes{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		// Macros are not supported, and negative patterns are forbidden
+		if strings.HasPrefix(fields[0], "[attr]") || strings.HasPrefix(fields[0], "!") {
+			continue
+		}
+
+		pattern, err := attributePatternToRegexp(fields[0])
+		if err != nil {
+			continue
+		}
+		rule := attributeRule{
+			pattern:    pattern,
+			attributes: make(map[string]string, len(fields)-1),
+		}
+		for _, field := range fields[1:] {
+			switch {
+			case strings.HasPrefix(field, "-"):
+				rule.attributes[field[1:]] = AttributeUnset
+			case strings.HasPrefix(field, "!"):
+				rule.attributes[field[1:]] = ""
+			case strings.Contains(field, "="):
+				idx := strings.Index(field, "=")
+				rule.attributes[field[:idx]] = field[idx+1:]
+			default:
+				rule.attributes[field] = AttributeSet
+			}
+		}
+		attrs.rules = append(attrs.rules, rule)
+	}
+	return attrs, scanner.Err()
+}
+
+// attributePatternToRegexp converts a pattern of a .gitattributes file to a
+// regular expression matching the paths of the files relative to the root
+func attributePatternToRegexp(pattern string) (*regexp.Regexp, error) {
+	// A pattern without a slash matches the name of the file in any directory
+	anyDir := !strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var buf strings.Builder
+	buf.WriteString("^")
+	if anyDir {
+		buf.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			buf.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "/**") && i+3 == len(pattern):
+			buf.WriteString("/.*")
+			i += 2
+		case c == '*':
+			buf.WriteString("[^/]*")
+		case c == '?':
+			buf.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				buf.WriteString(regexp.QuoteMeta(string(c)))
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			buf.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			buf.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	buf.WriteString("$")
+	return regexp.Compile(buf.String())
+}
+
+// Get returns the attributes of the file, the attributes set by the last
+// matching lines of the .gitattributes file take precedence
+func (attrs *Attributes) Get(filepath string) map[string]string {
+	result := make(map[string]string)
+	if attrs == nil {
+		return result
+	}
+	for _, rule := range attrs.rules {
+		if !rule.pattern.MatchString(filepath) {
+			continue
+		}
+		for name, value := range rule.attributes {
+			if value == "" {
+				delete(result, name)
+			} else {
+				result[name] = value
+			}
+		}
+	}
+	return result
+}
+
+// IsExportIgnored returns whether the file or one of its parent directories
+// has the export-ignore attribute, which excludes them from the archives
+func (attrs *Attributes) IsExportIgnored(filepath string) bool {
+	for p := filepath; p != "" && p != "."; p = path.Dir(p) {
+		if IsAttributeSet(attrs.Get(p), "export-ignore", false) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAttributeSet returns whether the boolean attribute is set in the
+// attributes of a file, def is returned if it is not specified
+func IsAttributeSet(attrs map[string]string, name string, def bool) bool {
+	switch attrs[name] {
+	case AttributeSet:
+		return true
+	case AttributeUnset:
+		return false
+	}
+	return def
+}
+
+// GetAttributes returns the attributes set by the .gitattributes file of the
+// root of the tree of the commit, there are none if it does not exist
+func (c *Commit) GetAttributes() (*Attributes, error) {
+	blob, err := c.GetBlobByPath(".gitattributes")
+	if err != nil {
+		if IsErrNotExist(err) {
+			return &Attributes{}, nil
+		}
+		return nil, err
+	}
+	reader, err := blob.DataAsync()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ParseAttributes(reader)
+}
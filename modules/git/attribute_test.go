@@ -0,0 +1,60 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAttributes(t *testing.T) {
+	attrs, err := ParseAttributes(strings.NewReader(`# comment
+*.js linguist-detectable
+*.min.js -linguist-detectable linguist-generated
+/vendor/** linguist-vendored
+docs/*.md linguist-documentation
+third_party/lib.c linguist-vendored=false
+*.tmpl linguist-language=HTML
+*.[ch] text
+special.js !linguist-detectable
+[attr]binary -diff -merge -text
+`))
+	assert.NoError(t, err)
+
+	var kases = []struct {
+		path   string
+		expect map[string]string
+	}{
+		{"main.go", map[string]string{}},
+		{"web/app.js", map[string]string{"linguist-detectable": AttributeSet}},
+		{"web/app.min.js", map[string]string{"linguist-detectable": AttributeUnset, "linguist-generated": AttributeSet}},
+		{"vendor/lib/lib.go", map[string]string{"linguist-vendored": AttributeSet}},
+		{"src/vendor/lib.go", map[string]string{}},
+		{"docs/index.md", map[string]string{"linguist-documentation": AttributeSet}},
+		{"docs/api/index.md", map[string]string{}},
+		{"third_party/lib.c", map[string]string{"linguist-vendored": AttributeUnset, "text": AttributeSet}},
+		{"templates/home.tmpl", map[string]string{"linguist-language": "HTML"}},
+		{"lib/special.js", map[string]string{}},
+	}
+	for _, kase := range kases {
+		assert.Equal(t, kase.expect, attrs.Get(kase.path), kase.path)
+	}
+}
+
+func TestAttributes_IsExportIgnored(t *testing.T) {
+	attrs, err := ParseAttributes(strings.NewReader(`/docs export-ignore
+*.psd export-ignore
+/docs/keep.md -export-ignore
+`))
+	assert.NoError(t, err)
+
+	assert.True(t, attrs.IsExportIgnored("docs/index.md"))
+	assert.True(t, attrs.IsExportIgnored("docs/keep.md"))
+	assert.True(t, attrs.IsExportIgnored("images/logo.psd"))
+	assert.False(t, attrs.IsExportIgnored("src/docs/index.md"))
+	assert.False(t, attrs.IsExportIgnored("README.md"))
+}
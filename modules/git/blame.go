@@ -11,6 +11,7 @@ import (
 	"os"
 	"os/exec"
 	"regexp"
+	"strings"
 
 	"code.gitea.io/gitea/modules/process"
 )
@@ -19,6 +20,11 @@ import (
 type BlamePart struct {
 	Sha   string
 	Lines []string
+	// PreviousSha and PreviousPath are the parent of the commit and the path
+	// of the file in it, they are empty if the lines were added by a root
+	// commit
+	PreviousSha  string
+	PreviousPath string
 }
 
 // BlameReader returns part of file blame one by one
@@ -28,6 +34,9 @@ type BlameReader struct {
 	output  io.ReadCloser
 	scanner *bufio.Scanner
 	lastSha *string
+	// the previous line of a commit is only output the first time the commit
+	// is met, previous keeps it for the next parts of the commit
+	previous map[string][2]string
 }
 
 var shaLineRegex = regexp.MustCompile("^([a-z0-9]{40})")
@@ -39,7 +48,7 @@ func (r *BlameReader) NextPart() (*BlamePart, error) {
 	scanner := r.scanner
 
 	if r.lastSha != nil {
-		blamePart = &BlamePart{*r.lastSha, make([]string, 0)}
+		blamePart = r.newPart(*r.lastSha)
 	}
 
 	for scanner.Scan() {
@@ -55,7 +64,7 @@ func (r *BlameReader) NextPart() (*BlamePart, error) {
 			sha1 := lines[1]
 
 			if blamePart == nil {
-				blamePart = &BlamePart{sha1, make([]string, 0)}
+				blamePart = r.newPart(sha1)
 			}
 
 			if blamePart.Sha != sha1 {
@@ -66,6 +75,12 @@ func (r *BlameReader) NextPart() (*BlamePart, error) {
 			code := line[1:]
 
 			blamePart.Lines = append(blamePart.Lines, code)
+		} else if strings.HasPrefix(line, "previous ") && blamePart != nil {
+			fields := strings.SplitN(line[9:], " ", 2)
+			if len(fields) == 2 {
+				blamePart.PreviousSha, blamePart.PreviousPath = fields[0], fields[1]
+				r.previous[blamePart.Sha] = [2]string{fields[0], fields[1]}
+			}
 		}
 	}
 
@@ -74,6 +89,14 @@ func (r *BlameReader) NextPart() (*BlamePart, error) {
 	return blamePart, nil
 }
 
+func (r *BlameReader) newPart(sha string) *BlamePart {
+	part := &BlamePart{Sha: sha, Lines: make([]string, 0)}
+	if previous, ok := r.previous[sha]; ok {
+		part.PreviousSha, part.PreviousPath = previous[0], previous[1]
+	}
+	return part
+}
+
 // Close BlameReader - don't run NextPart after invoking that
 func (r *BlameReader) Close() error {
 	process.GetManager().Remove(r.pid)
@@ -119,5 +142,6 @@ func createBlameReader(dir string, command ...string) (*BlameReader, error) {
 		stdout,
 		scanner,
 		nil,
+		make(map[string][2]string),
 	}, nil
 }
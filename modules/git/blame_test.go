@@ -48,7 +48,6 @@ committer-mail <joe2010xtmf@163.com>
 committer-time 1392833071
 committer-tz -0500
 summary Add code of delete user
-previous be0ba9ea88aff8a658d0495d36accf944b74888d gogs.go
 filename gogs.go
 	// Use of this source code is governed by a MIT-style
 4b92a6c2df28054ad766bc262f308db9f6066596 3 4
@@ -61,7 +60,6 @@ committer-mail <joe2010xtmf@163.com>
 committer-time 1392833071
 committer-tz -0500
 summary Add code of delete user
-previous be0ba9ea88aff8a658d0495d36accf944b74888d gogs.go
 filename gogs.go
 	// license that can be found in the LICENSE file.
 	
@@ -106,12 +104,16 @@ func TestReadingBlameOutput(t *testing.T) {
 			[]string{
 				"// Copyright 2014 The Gogs Authors. All rights reserved.",
 			},
+			"be0ba9ea88aff8a658d0495d36accf944b74888d",
+			"gogs.go",
 		},
 		{
 			"ce21ed6c3490cdfad797319cbb1145e2330a8fef",
 			[]string{
 				"// Copyright 2016 The Gitea Authors. All rights reserved.",
 			},
+			"618407c018cdf668ceedde7454c42fb22ba422d8",
+			"main.go",
 		},
 		{
 			"4b92a6c2df28054ad766bc262f308db9f6066596",
@@ -120,6 +122,8 @@ func TestReadingBlameOutput(t *testing.T) {
 				"// license that can be found in the LICENSE file.",
 				"",
 			},
+			"be0ba9ea88aff8a658d0495d36accf944b74888d",
+			"gogs.go",
 		},
 		{
 			"e2aa991e10ffd924a828ec149951f2f20eecead2",
@@ -127,6 +131,8 @@ func TestReadingBlameOutput(t *testing.T) {
 				"// Gitea (git with a cup of tea) is a painless self-hosted Git Service.",
 				"package main // import \"code.gitea.io/gitea\"",
 			},
+			"5fc370e332171b8658caed771b48585576f11737",
+			"main.go",
 		},
 		nil,
 	}
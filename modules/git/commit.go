@@ -15,6 +15,7 @@ import (
 	"strconv"
 	"strings"
 
+	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
 )
 
@@ -93,6 +94,21 @@ func convertCommit(c *object.Commit) *Commit {
 	}
 }
 
+// CommitFromReader reads a commit from the raw content of its object, e.g. the
+// output of git cat-file commit
+func CommitFromReader(r io.Reader) (*Commit, error) {
+	obj := &plumbing.MemoryObject{}
+	obj.SetType(plumbing.CommitObject)
+	if _, err := io.Copy(obj, r); err != nil {
+		return nil, err
+	}
+	c := &object.Commit{}
+	if err := c.Decode(obj); err != nil {
+		return nil, err
+	}
+	return convertCommit(c), nil
+}
+
 // Message returns the commit message. Same as retrieving CommitMessage directly.
 func (c *Commit) Message() string {
 	return c.CommitMessage
@@ -5,7 +5,10 @@
 package git
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"path"
 	"path/filepath"
 	"strings"
 )
@@ -20,18 +23,62 @@ const (
 	TARGZ
 )
 
-// CreateArchive create archive content to the target path
-func (c *Commit) CreateArchive(target string, archiveType ArchiveType) error {
-	var format string
+func (archiveType ArchiveType) format() (string, error) {
 	switch archiveType {
 	case ZIP:
-		format = "zip"
+		return "zip", nil
 	case TARGZ:
-		format = "tar.gz"
-	default:
-		return fmt.Errorf("unknown format: %v", archiveType)
+		return "tar.gz", nil
 	}
+	return "", fmt.Errorf("unknown format: %v", archiveType)
+}
 
-	_, err := NewCommand("archive", "--prefix="+filepath.Base(strings.TrimSuffix(c.repo.Path, ".git"))+"/", "--format="+format, "-o", target, c.ID.String()).RunInDir(c.repo.Path)
+// CreateArchive create archive content to the target path
+func (c *Commit) CreateArchive(target string, archiveType ArchiveType) error {
+	format, err := archiveType.format()
+	if err != nil {
+		return err
+	}
+
+	_, err = NewCommand("archive", "--prefix="+filepath.Base(strings.TrimSuffix(c.repo.Path, ".git"))+"/", "--format="+format, "-o", target, c.ID.String()).RunInDir(c.repo.Path)
 	return err
 }
+
+// WriteSubdirArchive writes the archive of the subdirectory subdir of the
+// commit to w, its content is prefixed with the name of the subdirectory.
+// The files with the export-ignore attribute in the .gitattributes file of
+// the root are excluded, as git only reads the ones of the subdirectory.
+func (c *Commit) WriteSubdirArchive(w io.Writer, subdir string, archiveType ArchiveType) error {
+	format, err := archiveType.format()
+	if err != nil {
+		return err
+	}
+
+	subdir = strings.Trim(subdir, "/")
+	cmd := NewCommand("archive", "--prefix="+path.Base(subdir)+"/", "--format="+format, c.ID.String()+":"+subdir)
+
+	attrs, err := c.GetAttributes()
+	if err != nil {
+		return err
+	}
+	stdout, err := NewCommand("ls-tree", "-r", "-z", "--name-only", c.ID.String(), "--", subdir+"/").RunInDirBytes(c.repo.Path)
+	if err != nil {
+		return err
+	}
+	var excludes []string
+	for _, name := range bytes.Split(stdout, []byte{0}) {
+		if len(name) > 0 && attrs.IsExportIgnored(string(name)) {
+			excludes = append(excludes, ":(exclude,literal)"+strings.TrimPrefix(string(name), subdir+"/"))
+		}
+	}
+	if len(excludes) > 0 {
+		cmd.AddArguments("--")
+		cmd.AddArguments(excludes...)
+	}
+
+	stderr := new(bytes.Buffer)
+	if err = cmd.RunInDirPipeline(c.repo.Path, w, stderr); err != nil {
+		return concatenateError(err, stderr.String())
+	}
+	return nil
+}
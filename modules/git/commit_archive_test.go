@@ -0,0 +1,88 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func archiveFileNames(t *testing.T, data []byte) []string {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.NoError(t, err)
+	var names []string
+	for _, f := range r.File {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestCommit_WriteSubdirArchive(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	bareRepo1, err := OpenRepository(bareRepo1Path)
+	assert.NoError(t, err)
+
+	commit, err := bareRepo1.GetBranchCommit("master")
+	assert.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, commit.WriteSubdirArchive(buf, "foo/nar/", ZIP))
+	assert.EqualValues(t, []string{"nar/", "nar/hello"}, archiveFileNames(t, buf.Bytes()))
+
+	assert.Error(t, commit.WriteSubdirArchive(new(bytes.Buffer), "foo/nar", ArchiveType(0)))
+}
+
+func TestCommit_ArchiveExportIgnore(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "archive")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	repoPath := filepath.Join(tmpDir, "repo1.git")
+	assert.NoError(t, Clone(filepath.Join(testReposDir, "repo1_bare"), repoPath, CloneRepoOptions{Bare: true}))
+
+	// Commit a .gitattributes file excluding a file from the archives
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "attributes"), []byte("/foo/nar/hello export-ignore\n"), 0644))
+	env := append(os.Environ(),
+		"GIT_INDEX_FILE="+filepath.Join(tmpDir, "index"),
+		"GIT_AUTHOR_NAME=Gitea", "GIT_AUTHOR_EMAIL=gitea@example.com",
+		"GIT_COMMITTER_NAME=Gitea", "GIT_COMMITTER_EMAIL=gitea@example.com")
+	run := func(args ...string) string {
+		stdout, err := NewCommand(args...).RunInDirTimeoutEnv(env, time.Minute, repoPath)
+		assert.NoError(t, err)
+		return strings.TrimSpace(string(stdout))
+	}
+	run("read-tree", "master")
+	blobID := run("hash-object", "-w", filepath.Join(tmpDir, "attributes"))
+	run("update-index", "--add", "--cacheinfo", "100644,"+blobID+",.gitattributes")
+	commitID := run("commit-tree", run("write-tree"), "-p", "master", "-m", "export-ignore")
+
+	repo, err := OpenRepository(repoPath)
+	assert.NoError(t, err)
+	commit, err := repo.GetCommit(commitID)
+	assert.NoError(t, err)
+
+	target := filepath.Join(tmpDir, "repo1.zip")
+	assert.NoError(t, commit.CreateArchive(target, ZIP))
+	data, err := ioutil.ReadFile(target)
+	assert.NoError(t, err)
+	names := archiveFileNames(t, data)
+	assert.Contains(t, names, "repo1/foo/link_short")
+	assert.NotContains(t, names, "repo1/foo/nar/hello")
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, commit.WriteSubdirArchive(buf, "foo", ZIP))
+	names = archiveFileNames(t, buf.Bytes())
+	assert.Contains(t, names, "foo/link_short")
+	assert.NotContains(t, names, "foo/nar/hello")
+}
@@ -5,6 +5,7 @@
 package git
 
 import (
+	"bytes"
 	"path/filepath"
 	"testing"
 
@@ -36,3 +37,16 @@ func TestGetFullCommitIDError(t *testing.T) {
 		assert.EqualError(t, err, "object does not exist [id: unknown, rel_path: ]")
 	}
 }
+
+func TestCommitFromReader(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+
+	data, err := NewCommand("cat-file", "commit", "8006ff9adbf0cb94da7dad9e537e53817f9fa5c0").RunInDirBytes(bareRepo1Path)
+	assert.NoError(t, err)
+	commit, err := CommitFromReader(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "8006ff9adbf0cb94da7dad9e537e53817f9fa5c0", commit.ID.String())
+	assert.EqualValues(t, "tris.git@shoddynet.org", commit.Committer.Email)
+	assert.EqualValues(t, "Added symlink directory\n", commit.CommitMessage)
+	assert.EqualValues(t, 1, commit.ParentCount())
+}
@@ -8,6 +8,7 @@ package git
 import (
 	"fmt"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
 
@@ -34,6 +35,19 @@ var (
 	// Could be updated to an absolute path while initialization
 	GitExecutable = "git"
 
+	// SupportProcReceive is true if Git supports the proc-receive hook used
+	// to create pull requests by pushing to refs/for/<branch>
+	SupportProcReceive bool
+
+	// EnableProtocolV2 is true if the git wire protocol version 2 is used, to
+	// serve the repositories to the clients which request it as well as to
+	// fetch from the remotes
+	EnableProtocolV2 bool
+
+	// EnablePartialClone allows the clients to clone the repositories partially
+	// with git clone --filter, e.g. without the blobs, if Git supports it
+	EnablePartialClone = true
+
 	gitVersion string
 )
 
@@ -133,6 +147,44 @@ func Init() error {
 			return fmt.Errorf("Failed to execute 'git config --global gc.writeCommitGraph true': %s", stderr)
 		}
 	}
+
+	if version.Compare(gitVersion, "2.10", ">=") {
+		// Push options are used to change the settings of the repositories and
+		// to set the title and description of the pull requests
+		if _, stderr, err := process.GetManager().Exec("git.Init(git config --global receive.advertisePushOptions true)",
+			GitExecutable, "config", "--global", "receive.advertisePushOptions", "true"); err != nil {
+			return fmt.Errorf("Failed to execute 'git config --global receive.advertisePushOptions true': %s", stderr)
+		}
+	}
+
+	if version.Compare(gitVersion, "2.22", ">=") {
+		// The missing objects of partial clones are fetched by their IDs, only
+		// the reachable ones may be wanted so that the objects which have been
+		// force pushed away can not be fetched
+		value := strconv.FormatBool(EnablePartialClone)
+		for _, configKey := range []string{"uploadpack.allowFilter", "uploadpack.allowReachableSHA1InWant"} {
+			if _, stderr, err := process.GetManager().Exec("git.Init(git config --global "+configKey+")",
+				GitExecutable, "config", "--global", configKey, value); err != nil {
+				return fmt.Errorf("Failed to execute 'git config --global %s %s': %s", configKey, value, stderr)
+			}
+		}
+		// Earlier versions allowed to want any object
+		if stdout, _, _ := process.GetManager().Exec("git.Init(git config --global --get uploadpack.allowAnySHA1InWant)",
+			GitExecutable, "config", "--global", "--get", "uploadpack.allowAnySHA1InWant"); strings.TrimSpace(stdout) != "" {
+			if _, stderr, err := process.GetManager().Exec("git.Init(git config --global --unset-all uploadpack.allowAnySHA1InWant)",
+				GitExecutable, "config", "--global", "--unset-all", "uploadpack.allowAnySHA1InWant"); err != nil {
+				return fmt.Errorf("Failed to execute 'git config --global --unset-all uploadpack.allowAnySHA1InWant': %s", stderr)
+			}
+		}
+	}
+
+	if version.Compare(gitVersion, "2.29", ">=") {
+		if _, stderr, err := process.GetManager().Exec("git.Init(git config --global receive.procReceiveRefs)",
+			GitExecutable, "config", "--global", "receive.procReceiveRefs", strings.TrimSuffix(AGitPullPrefix, "/")); err != nil {
+			return fmt.Errorf("Failed to execute 'git config --global receive.procReceiveRefs %s': %s", strings.TrimSuffix(AGitPullPrefix, "/"), stderr)
+		}
+		SupportProcReceive = true
+	}
 	return nil
 }
 
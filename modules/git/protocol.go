@@ -0,0 +1,49 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+// EnvGitProtocol is the environment variable through which the clients request
+// a version of the git wire protocol, e.g. version=2
+const EnvGitProtocol = "GIT_PROTOCOL"
+
+var gitProtocolPattern = regexp.MustCompile(`^[0-9a-zA-Z]+=[0-9a-zA-Z]+(:[0-9a-zA-Z]+=[0-9a-zA-Z]+)*$`)
+
+// GitProtocolEnv returns the environment variable passing the git wire
+// protocol requested by a client to upload-pack and receive-pack, which is
+// empty if the protocol version 2 is disabled or the request is invalid.
+func GitProtocolEnv(protocol string) string {
+	if !EnableProtocolV2 || !gitProtocolPattern.MatchString(protocol) {
+		return ""
+	}
+	return EnvGitProtocol + "=" + protocol
+}
+
+// ServeEnviron returns the environment of the commands serving a client, the
+// git wire protocol requested by the client is only passed on if it is valid
+// and enabled.
+func ServeEnviron(environ []string, protocol string) []string {
+	env := make([]string, 0, len(environ)+1)
+	for _, kv := range environ {
+		if !strings.HasPrefix(kv, EnvGitProtocol+"=") {
+			env = append(env, kv)
+		}
+	}
+	if protocolEnv := GitProtocolEnv(protocol); len(protocolEnv) > 0 {
+		env = append(env, protocolEnv)
+	}
+	return env
+}
+
+// ServeOSEnviron returns the environment of the process with the git wire
+// protocol requested by the client only if it is valid and enabled
+func ServeOSEnviron() []string {
+	return ServeEnviron(os.Environ(), os.Getenv(EnvGitProtocol))
+}
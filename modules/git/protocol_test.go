@@ -0,0 +1,30 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeEnviron(t *testing.T) {
+	defer func(enabled bool) {
+		EnableProtocolV2 = enabled
+	}(EnableProtocolV2)
+	environ := []string{"GITEA_REPO_NAME=repo1", EnvGitProtocol + "=version=1"}
+
+	EnableProtocolV2 = false
+	assert.EqualValues(t, "", GitProtocolEnv("version=2"))
+	assert.EqualValues(t, []string{"GITEA_REPO_NAME=repo1"}, ServeEnviron(environ, "version=2"))
+
+	EnableProtocolV2 = true
+	assert.EqualValues(t, "GIT_PROTOCOL=version=2", GitProtocolEnv("version=2"))
+	assert.EqualValues(t, "GIT_PROTOCOL=version=2:object=sha1", GitProtocolEnv("version=2:object=sha1"))
+	assert.EqualValues(t, "", GitProtocolEnv(""))
+	assert.EqualValues(t, "", GitProtocolEnv("version=2; rm -rf /"))
+	assert.EqualValues(t, []string{"GITEA_REPO_NAME=repo1", "GIT_PROTOCOL=version=2"}, ServeEnviron(environ, "version=2"))
+	assert.EqualValues(t, []string{"GITEA_REPO_NAME=repo1"}, ServeEnviron(environ, ""))
+}
@@ -4,6 +4,10 @@
 
 package git
 
+// AGitPullPrefix is the prefix of the references pushed to create or update
+// pull requests without a branch, e.g. refs/for/master/topic
+const AGitPullPrefix = "refs/for/"
+
 // Reference represents a Git ref.
 type Reference struct {
 	Name   string
@@ -17,6 +17,8 @@ import (
 	"strings"
 	"time"
 
+	logger "code.gitea.io/gitea/modules/log"
+
 	"github.com/unknwon/com"
 	"gopkg.in/src-d/go-billy.v4/osfs"
 	gogit "gopkg.in/src-d/go-git.v4"
@@ -130,6 +132,8 @@ type CloneRepoOptions struct {
 	Branch     string
 	Shared     bool
 	NoCheckout bool
+	// Filter clones the repository partially, e.g. blob:none
+	Filter string
 }
 
 // Clone clones original repository to target path.
@@ -156,6 +160,10 @@ func Clone(from, to string, opts CloneRepoOptions) (err error) {
 		cmd.AddArguments("--no-checkout")
 	}
 
+	if len(opts.Filter) > 0 {
+		cmd.AddArguments("--filter=" + opts.Filter)
+	}
+
 	if len(opts.Branch) > 0 {
 		cmd.AddArguments("-b", opts.Branch)
 	}
@@ -368,3 +376,45 @@ func GetDivergingCommits(repoPath string, baseBranch string, targetBranch string
 
 	return DivergeObject{ahead, behind}, nil
 }
+
+// GetDivergingCommitsFromRepo returns the number of commits the branches are
+// ahead or behind the branches of the repository at basePath they are paired
+// with in baseBranches, by branch name. The base branches are fetched first.
+func (repo *Repository) GetDivergingCommitsFromRepo(basePath string, baseBranches map[string]string) (map[string]DivergeObject, error) {
+	divergences := make(map[string]DivergeObject, len(baseBranches))
+	if len(baseBranches) == 0 {
+		return divergences, nil
+	}
+
+	tmpRemote := strconv.FormatInt(time.Now().UnixNano(), 10)
+	if err := repo.AddRemote(tmpRemote, basePath, false); err != nil {
+		return nil, fmt.Errorf("AddRemote: %v", err)
+	}
+	defer func() {
+		if err := repo.RemoveRemote(tmpRemote); err != nil {
+			logger.Error("GetDivergingCommitsFromRepo: RemoveRemote: %v", err)
+		}
+	}()
+
+	remoteRefPrefix := "refs/remotes/" + tmpRemote + "/"
+	cmd := NewCommand("fetch", "--no-tags", tmpRemote)
+	fetched := make(map[string]bool, len(baseBranches))
+	for _, baseBranch := range baseBranches {
+		if !fetched[baseBranch] {
+			cmd.AddArguments("+" + BranchPrefix + baseBranch + ":" + remoteRefPrefix + baseBranch)
+			fetched[baseBranch] = true
+		}
+	}
+	if _, err := cmd.RunInDir(repo.Path); err != nil {
+		return nil, fmt.Errorf("fetch: %v", err)
+	}
+
+	for branch, baseBranch := range baseBranches {
+		divergence, err := GetDivergingCommits(repo.Path, remoteRefPrefix+baseBranch, BranchPrefix+branch)
+		if err != nil {
+			return nil, err
+		}
+		divergences[branch] = divergence
+	}
+	return divergences, nil
+}
@@ -4,7 +4,13 @@
 
 package git
 
-import "fmt"
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
 
 // FileBlame return the Blame object of file
 func (repo *Repository) FileBlame(revision, path, file string) ([]byte, error) {
@@ -22,3 +28,80 @@ func (repo *Repository) LineBlame(revision, path, file string, line uint) (*Comm
 	}
 	return repo.GetCommit(res[:40])
 }
+
+// LineRange represents consecutive lines of a file
+type LineRange struct {
+	Start int
+	Count int
+}
+
+var hunkHeaderRegex = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+`)
+
+// GetChangedLineRanges returns the lines of the files at base which are
+// modified or deleted by the changes between base and head, by file name.
+// Added lines are not part of the ranges.
+func (repo *Repository) GetChangedLineRanges(base, head string) (map[string][]LineRange, error) {
+	stdout, err := NewCommand("-c", "core.quotepath=false", "diff", "-U0", "--no-color", "--no-renames", "--no-ext-diff", base, head).RunInDir(repo.Path)
+	if err != nil {
+		return nil, err
+	}
+	return parseChangedLineRanges(stdout), nil
+}
+
+func parseChangedLineRanges(diff string) map[string][]LineRange {
+	ranges := make(map[string][]LineRange)
+	var file string
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "--- "):
+			file = ""
+			if strings.HasPrefix(line, "--- a/") {
+				file = line[6:]
+			}
+		case strings.HasPrefix(line, "@@ ") && len(file) > 0:
+			m := hunkHeaderRegex.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			start, _ := strconv.Atoi(m[1])
+			count := 1
+			if len(m[2]) > 0 {
+				count, _ = strconv.Atoi(m[2])
+			}
+			if count > 0 {
+				ranges[file] = append(ranges[file], LineRange{Start: start, Count: count})
+			}
+		}
+	}
+	return ranges
+}
+
+// BlameAuthors returns the number of lines of the ranges of the file at the
+// revision which have been last changed by each author, by author email.
+func (repo *Repository) BlameAuthors(revision, file string, ranges []LineRange) (map[string]int, error) {
+	args := make([]string, 0, len(ranges)+4)
+	args = append(args, "blame", "--line-porcelain")
+	for _, r := range ranges {
+		args = append(args, fmt.Sprintf("-L%d,+%d", r.Start, r.Count))
+	}
+	args = append(args, revision, "--", file)
+	stdout, err := NewCommand(args...).RunInDir(repo.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	authors := make(map[string]int)
+	scanner := bufio.NewScanner(strings.NewReader(stdout))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "author-mail ") {
+			email := strings.TrimSuffix(strings.TrimPrefix(line[12:], "<"), ">")
+			if len(email) > 0 {
+				authors[strings.ToLower(email)]++
+			}
+		}
+	}
+	return authors, nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChangedLineRanges(t *testing.T) {
+	ranges := parseChangedLineRanges(`diff --git a/README.md b/README.md
+index 4b6fc48..a1b2c3d 100644
+--- a/README.md
++++ b/README.md
+@@ -3 +3 @@ Description
+-old
++new
+@@ -10,0 +11,2 @@ Description
++added
++added
+@@ -20,3 +21 @@ Description
+-a
+-b
+-c
++d
+diff --git a/new.txt b/new.txt
+new file mode 100644
+--- /dev/null
++++ b/new.txt
+@@ -0,0 +1 @@
++new
+`)
+	assert.Equal(t, map[string][]LineRange{
+		"README.md": {{Start: 3, Count: 1}, {Start: 20, Count: 3}},
+	}, ranges)
+}
+
+func TestRepository_BlameAuthors(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	bareRepo1, err := OpenRepository(bareRepo1Path)
+	assert.NoError(t, err)
+
+	authors, err := bareRepo1.BlameAuthors("feaf4ba6bc635fec442f46ddd4512416ec43c2c2", "foo/link_short", []LineRange{{Start: 1, Count: 1}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"tris.git@shoddynet.org": 1}, authors)
+
+	// Lines without author email are not counted
+	authors, err = bareRepo1.BlameAuthors("feaf4ba6bc635fec442f46ddd4512416ec43c2c2", "file2.txt", []LineRange{{Start: 1, Count: 1}})
+	assert.NoError(t, err)
+	assert.Empty(t, authors)
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/mcuadros/go-version"
 	"gopkg.in/src-d/go-git.v4/plumbing"
 )
 
@@ -84,6 +85,31 @@ func (repo *Repository) GetBranches() ([]string, error) {
 	return branchNames, nil
 }
 
+// GetMergedBranches returns the branches whose last commit is reachable from
+// the branch target, including target itself
+func (repo *Repository) GetMergedBranches(target string) ([]string, error) {
+	if version.Compare(gitVersion, "2.7.0", ">=") {
+		stdout, err := NewCommand("for-each-ref", "--format=%(refname:strip=2)", "--merged="+BranchPrefix+target, BranchPrefix).RunInDir(repo.Path)
+		if err != nil {
+			return nil, err
+		}
+		return strings.Fields(stdout), nil
+	}
+
+	stdout, err := NewCommand("branch", "--merged", BranchPrefix+target).RunInDir(repo.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []string
+	for _, line := range strings.Split(stdout, "\n") {
+		if line = strings.TrimSpace(strings.TrimPrefix(line, "*")); len(line) > 0 {
+			branches = append(branches, line)
+		}
+	}
+	return branches, nil
+}
+
 // GetBranch returns a branch by it's name
 func (repo *Repository) GetBranch(branch string) (*Branch, error) {
 	if !repo.IsBranchExist(branch) {
@@ -23,6 +23,19 @@ func TestRepository_GetBranches(t *testing.T) {
 	assert.ElementsMatch(t, []string{"branch1", "branch2", "master"}, branches)
 }
 
+func TestRepository_GetMergedBranches(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	bareRepo1, err := OpenRepository(bareRepo1Path)
+	assert.NoError(t, err)
+
+	branches, err := bareRepo1.GetMergedBranches("master")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"master"}, branches)
+
+	_, err = bareRepo1.GetMergedBranches("missing")
+	assert.Error(t, err)
+}
+
 func BenchmarkRepository_GetBranches(b *testing.B) {
 	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
 	bareRepo1, err := OpenRepository(bareRepo1Path)
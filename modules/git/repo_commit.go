@@ -93,7 +93,9 @@ func (repo *Repository) getCommit(id SHA1) (*Commit, error) {
 			gogitCommit, err = repo.gogitRepo.CommitObject(tagObject.Target)
 		}
 	}
-	if err != nil {
+	if err == plumbing.ErrObjectNotFound {
+		return nil, ErrNotExist{id.String(), ""}
+	} else if err != nil {
 		return nil, err
 	}
 
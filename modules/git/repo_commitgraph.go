@@ -11,10 +11,25 @@ import (
 
 	gitealog "code.gitea.io/gitea/modules/log"
 
+	"github.com/mcuadros/go-version"
 	"gopkg.in/src-d/go-git.v4/plumbing/format/commitgraph"
 	cgobject "gopkg.in/src-d/go-git.v4/plumbing/object/commitgraph"
 )
 
+// WriteCommitGraph writes the commit-graph file of the repository with the
+// commits reachable from its references, if Git supports it. The file is
+// rewritten entirely since the chains of split commit-graph files are not read.
+func WriteCommitGraph(repoPath string) error {
+	gitVersion, err := BinVersion()
+	if err != nil {
+		return err
+	} else if version.Compare(gitVersion, "2.19", "<") {
+		return nil
+	}
+	_, err = NewCommand("commit-graph", "write", "--reachable").RunInDir(repoPath)
+	return err
+}
+
 // CommitNodeIndex returns the index for walking commit graph
 func (r *Repository) CommitNodeIndex() (cgobject.CommitNodeIndex, *os.File) {
 	indexPath := path.Join(r.Path, "objects", "info", "commit-graph")
@@ -0,0 +1,38 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteCommitGraph(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "commit_graph")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	repoPath := filepath.Join(tmpDir, "repo1.git")
+	assert.NoError(t, Clone(filepath.Join(testReposDir, "repo1_bare"), repoPath, CloneRepoOptions{Bare: true}))
+
+	assert.NoError(t, WriteCommitGraph(repoPath))
+	repo, err := OpenRepository(repoPath)
+	assert.NoError(t, err)
+	_, file := repo.CommitNodeIndex()
+	if assert.NotNil(t, file) {
+		file.Close()
+	}
+
+	// The commit-graph is used to walk the commits
+	commit, err := repo.GetBranchCommit("master")
+	assert.NoError(t, err)
+	entries, err := commit.Tree.ListEntries()
+	assert.NoError(t, err)
+	_, _, err = entries.GetCommitsInfo(commit, "", nil)
+	assert.NoError(t, err)
+}
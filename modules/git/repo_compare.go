@@ -10,6 +10,7 @@ import (
 	"container/list"
 	"fmt"
 	"io"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -94,6 +95,151 @@ func (repo *Repository) GetCompareInfo(basePath, baseBranch, headBranch string)
 	return compareInfo, nil
 }
 
+// GetFilesChangedBetween returns the paths of the files which have been changed
+// on head since it has been forked from base.
+func (repo *Repository) GetFilesChangedBetween(base, head string) ([]string, error) {
+	stdout, err := NewCommand("diff", "--name-only", "-z", base+"..."+head).RunInDir(repo.Path)
+	if err != nil {
+		return nil, err
+	}
+	files := strings.Split(stdout, "\x00")
+	if len(files) > 0 && files[len(files)-1] == "" {
+		files = files[:len(files)-1]
+	}
+	return files, nil
+}
+
+// ChangedFile represents a file changed between two commits, OldName is only
+// set for renamed and copied files
+type ChangedFile struct {
+	Name    string
+	OldName string
+}
+
+// GetChangedFiles returns the files changed from base to head in the order of
+// git diff with renames detected. Only the files matching the glob pathspec
+// are returned if it is not empty.
+func (repo *Repository) GetChangedFiles(base, head, glob string) ([]*ChangedFile, error) {
+	args := []string{"diff", "--name-status", "-M", "-z", base, head}
+	if len(glob) > 0 {
+		args = append(args, "--", ":(glob)"+glob)
+	}
+	stdout, err := NewCommand(args...).RunInDir(repo.Path)
+	if err != nil {
+		return nil, err
+	}
+	return parseChangedFiles(stdout), nil
+}
+
+func parseChangedFiles(nameStatus string) []*ChangedFile {
+	fields := strings.Split(nameStatus, "\x00")
+	files := make([]*ChangedFile, 0, len(fields)/2)
+	for i := 0; i+1 < len(fields) && len(fields[i]) > 0; {
+		status := fields[i]
+		if (status[0] == 'R' || status[0] == 'C') && i+2 < len(fields) {
+			files = append(files, &ChangedFile{Name: fields[i+2], OldName: fields[i+1]})
+			i += 3
+			continue
+		}
+		files = append(files, &ChangedFile{Name: fields[i+1]})
+		i += 2
+	}
+	return files
+}
+
+// GetDiffShortStat returns the number of changed files and the number of
+// added and deleted lines on head since it has been forked from base, without
+// generating the diff itself.
+func (repo *Repository) GetDiffShortStat(base, head string) (numFiles, totalAdditions, totalDeletions int, err error) {
+	stdout, err := NewCommand("diff", "--shortstat", base+"..."+head).RunInDir(repo.Path)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return parseDiffShortStat(stdout)
+}
+
+// DiffFileStat represents the number of lines added and deleted in a file,
+// OldName is only set for renamed and copied files
+type DiffFileStat struct {
+	Name      string
+	OldName   string
+	Additions int
+	Deletions int
+	IsBinary  bool
+}
+
+// GetDiffNumStat returns the number of added and deleted lines of each file
+// changed on head since it has been forked from base.
+func (repo *Repository) GetDiffNumStat(base, head string) ([]*DiffFileStat, error) {
+	stdout, err := NewCommand("diff", "--numstat", "-M", "-z", base+"..."+head).RunInDir(repo.Path)
+	if err != nil {
+		return nil, err
+	}
+	return parseDiffNumStat(stdout)
+}
+
+func parseDiffNumStat(numStat string) ([]*DiffFileStat, error) {
+	fields := strings.Split(numStat, "\x00")
+	stats := make([]*DiffFileStat, 0, len(fields))
+	for i := 0; i < len(fields) && len(fields[i]) > 0; i++ {
+		infos := strings.SplitN(fields[i], "\t", 3)
+		if len(infos) != 3 {
+			return nil, fmt.Errorf("unable to parse numstat: %s", fields[i])
+		}
+		stat := &DiffFileStat{Name: infos[2]}
+		if infos[0] == "-" && infos[1] == "-" {
+			stat.IsBinary = true
+		} else {
+			var err error
+			if stat.Additions, err = strconv.Atoi(infos[0]); err != nil {
+				return nil, fmt.Errorf("unable to parse numstat: %s: %v", fields[i], err)
+			}
+			if stat.Deletions, err = strconv.Atoi(infos[1]); err != nil {
+				return nil, fmt.Errorf("unable to parse numstat: %s: %v", fields[i], err)
+			}
+		}
+		// The names of a renamed or copied file follow the numbers
+		if len(stat.Name) == 0 {
+			if i+2 >= len(fields) {
+				return nil, fmt.Errorf("unable to parse numstat: missing names of %s", fields[i])
+			}
+			stat.OldName, stat.Name = fields[i+1], fields[i+2]
+			i += 2
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+var shortStatFormat = regexp.MustCompile(`\s*(\d+) files? changed(?:, (\d+) insertions?\(\+\))?(?:, (\d+) deletions?\(-\))?`)
+
+func parseDiffShortStat(stdout string) (numFiles, totalAdditions, totalDeletions int, err error) {
+	stdout = strings.TrimSpace(stdout)
+	if len(stdout) == 0 {
+		return 0, 0, 0, nil
+	}
+	groups := shortStatFormat.FindStringSubmatch(stdout)
+	if len(groups) != 4 {
+		return 0, 0, 0, fmt.Errorf("unable to parse shortstat: %s", stdout)
+	}
+
+	numFiles, err = strconv.Atoi(groups[1])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("unable to parse shortstat: %s: %v", stdout, err)
+	}
+	if len(groups[2]) > 0 {
+		if totalAdditions, err = strconv.Atoi(groups[2]); err != nil {
+			return 0, 0, 0, fmt.Errorf("unable to parse shortstat: %s: %v", stdout, err)
+		}
+	}
+	if len(groups[3]) > 0 {
+		if totalDeletions, err = strconv.Atoi(groups[3]); err != nil {
+			return 0, 0, 0, fmt.Errorf("unable to parse shortstat: %s: %v", stdout, err)
+		}
+	}
+	return numFiles, totalAdditions, totalDeletions, nil
+}
+
 // GetPatch generates and returns patch data between given revisions.
 func (repo *Repository) GetPatch(base, head string) ([]byte, error) {
 	return NewCommand("diff", "-p", "--binary", base, head).RunInDirBytes(repo.Path)
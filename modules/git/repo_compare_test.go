@@ -28,3 +28,65 @@ func TestGetFormatPatch(t *testing.T) {
 	assert.Regexp(t, "^From 8d92fc95", patch)
 	assert.Contains(t, patch, "Subject: [PATCH] Add file2.txt")
 }
+
+func TestParseDiffShortStat(t *testing.T) {
+	for _, c := range []struct {
+		stdout                         string
+		numFiles, additions, deletions int
+	}{
+		{"", 0, 0, 0},
+		{" 1 file changed, 1 insertion(+)\n", 1, 1, 0},
+		{" 2 files changed, 1 deletion(-)\n", 2, 0, 1},
+		{" 3 files changed, 10 insertions(+), 2 deletions(-)\n", 3, 10, 2},
+		{" 1 file changed, 0 insertions(+), 0 deletions(-)\n", 1, 0, 0},
+	} {
+		numFiles, additions, deletions, err := parseDiffShortStat(c.stdout)
+		assert.NoError(t, err)
+		assert.Equal(t, c.numFiles, numFiles, c.stdout)
+		assert.Equal(t, c.additions, additions, c.stdout)
+		assert.Equal(t, c.deletions, deletions, c.stdout)
+	}
+
+	_, _, _, err := parseDiffShortStat("invalid")
+	assert.Error(t, err)
+}
+
+func TestParseDiffNumStat(t *testing.T) {
+	stats, err := parseDiffNumStat("")
+	assert.NoError(t, err)
+	assert.Empty(t, stats)
+
+	stats, err = parseDiffNumStat("3\t1\tREADME.md\x0010\t0\t\x00docs/old name.md\x00docs/new name.md\x00-\t-\tlogo.png\x00")
+	assert.NoError(t, err)
+	assert.Equal(t, []*DiffFileStat{
+		{Name: "README.md", Additions: 3, Deletions: 1},
+		{Name: "docs/new name.md", OldName: "docs/old name.md", Additions: 10},
+		{Name: "logo.png", IsBinary: true},
+	}, stats)
+
+	_, err = parseDiffNumStat("invalid\x00")
+	assert.Error(t, err)
+}
+
+func TestParseChangedFiles(t *testing.T) {
+	assert.Empty(t, parseChangedFiles(""))
+	assert.Equal(t, []*ChangedFile{
+		{Name: "README.md"},
+		{Name: "docs/new name.md", OldName: "docs/old name.md"},
+		{Name: "main.go"},
+	}, parseChangedFiles("M\x00README.md\x00R087\x00docs/old name.md\x00docs/new name.md\x00D\x00main.go\x00"))
+}
+
+func TestRepository_GetChangedFiles(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	bareRepo1, err := OpenRepository(bareRepo1Path)
+	assert.NoError(t, err)
+
+	files, err := bareRepo1.GetChangedFiles("8006ff9adbf0cb94da7dad9e537e53817f9fa5c0", "37991dec2c8e592043f47155ce4808d4580f9123", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []*ChangedFile{{Name: "foo/broken_link"}, {Name: "foo/link_short"}, {Name: "foo/outside_repo"}}, files)
+
+	files, err = bareRepo1.GetChangedFiles("8006ff9adbf0cb94da7dad9e537e53817f9fa5c0", "37991dec2c8e592043f47155ce4808d4580f9123", "**/link_*")
+	assert.NoError(t, err)
+	assert.Equal(t, []*ChangedFile{{Name: "foo/link_short"}}, files)
+}
@@ -106,3 +106,71 @@ func (repo *Repository) GetCodeActivityStats(fromTime time.Time, branch string)
 
 	return stats, nil
 }
+
+// CommitStats represents the number of lines a commit adds and deletes
+type CommitStats struct {
+	ID          string
+	AuthorName  string
+	AuthorEmail string
+	AuthorTime  time.Time
+	Additions   int64
+	Deletions   int64
+}
+
+// GetCommitsStats returns the statistics of the commits of the revision range
+// in reverse chronological order, merge commits are not included. The names
+// and emails of the authors are mapped by the .mailmap file.
+func (repo *Repository) GetCommitsStats(revisionRange string) ([]*CommitStats, error) {
+	stdout, err := NewCommand("log", "--numstat", "--no-merges", "--pretty=format:---%n%H%n%aN%n%aE%n%at", revisionRange, "--").RunInDirBytes(repo.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []*CommitStats
+	var commit *CommitStats
+	scanner := bufio.NewScanner(bytes.NewReader(stdout))
+	scanner.Split(bufio.ScanLines)
+	p := 0
+	for scanner.Scan() {
+		l := scanner.Text()
+		if l == "---" {
+			p = 1
+			commit = &CommitStats{}
+			commits = append(commits, commit)
+		} else if p == 0 {
+			continue
+		} else {
+			p++
+		}
+		switch p {
+		case 1: // Separator
+		case 2: // Commit sha-1
+			commit.ID = strings.TrimSpace(l)
+		case 3: // Author
+			commit.AuthorName = strings.TrimSpace(l)
+		case 4: // E-mail
+			commit.AuthorEmail = strings.TrimSpace(l)
+		case 5: // Author time
+			if seconds, err := strconv.ParseInt(strings.TrimSpace(l), 10, 64); err == nil {
+				commit.AuthorTime = time.Unix(seconds, 0)
+			}
+		default: // Changed file, binary files have no line counts
+			if parts := strings.Fields(l); len(parts) >= 3 {
+				if c, err := strconv.ParseInt(parts[0], 10, 64); err == nil {
+					commit.Additions += c
+				}
+				if c, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
+					commit.Deletions += c
+				}
+			}
+		}
+	}
+	return commits, scanner.Err()
+}
+
+// IsAncestor returns whether the first commit is an ancestor of the second
+// one, it is not if it does not exist
+func (repo *Repository) IsAncestor(ancestorID, commitID string) bool {
+	stdout, err := NewCommand("merge-base", ancestorID, commitID).RunInDir(repo.Path)
+	return err == nil && strings.TrimSpace(stdout) == ancestorID
+}
@@ -34,3 +34,26 @@ func TestRepository_GetCodeActivityStats(t *testing.T) {
 	assert.EqualValues(t, 3, code.Authors["tris.git@shoddynet.org"])
 	assert.EqualValues(t, 5, code.Authors[""])
 }
+
+func TestRepository_GetCommitsStats(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	bareRepo1, err := OpenRepository(bareRepo1Path)
+	assert.NoError(t, err)
+
+	commits, err := bareRepo1.GetCommitsStats("8006ff9adbf0cb94da7dad9e537e53817f9fa5c0..37991dec2c8e592043f47155ce4808d4580f9123")
+	assert.NoError(t, err)
+	if assert.Len(t, commits, 2) {
+		assert.EqualValues(t, "37991dec2c8e592043f47155ce4808d4580f9123", commits[0].ID)
+		assert.EqualValues(t, "Tris Forster", commits[0].AuthorName)
+		assert.EqualValues(t, "tris.git@shoddynet.org", commits[0].AuthorEmail)
+		assert.EqualValues(t, 1524183916, commits[0].AuthorTime.Unix())
+		assert.EqualValues(t, 1, commits[0].Additions)
+		assert.EqualValues(t, 0, commits[0].Deletions)
+		assert.EqualValues(t, "6fbd69e9823458e6c4a2fc5c0f6bc022b2f2acd1", commits[1].ID)
+		assert.EqualValues(t, 2, commits[1].Additions)
+	}
+
+	assert.True(t, bareRepo1.IsAncestor("8006ff9adbf0cb94da7dad9e537e53817f9fa5c0", "37991dec2c8e592043f47155ce4808d4580f9123"))
+	assert.False(t, bareRepo1.IsAncestor("37991dec2c8e592043f47155ce4808d4580f9123", "8006ff9adbf0cb94da7dad9e537e53817f9fa5c0"))
+	assert.False(t, bareRepo1.IsAncestor("0000000000000000000000000000000000000000", "37991dec2c8e592043f47155ce4808d4580f9123"))
+}
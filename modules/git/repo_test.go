@@ -5,7 +5,9 @@
 package git
 
 import (
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -34,3 +36,24 @@ func TestRepoIsEmpty(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, isEmpty)
 }
+
+func TestGetDivergingCommitsFromRepo(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	clonedPath, err := cloneRepo(bareRepo1Path, testReposDir, "repo1_TestGetDivergingCommitsFromRepo")
+	assert.NoError(t, err)
+	defer os.RemoveAll(clonedPath)
+	repo, err := OpenRepository(clonedPath)
+	assert.NoError(t, err)
+
+	// The base repository is fetched from the cloned repository
+	basePath, err := filepath.Abs(bareRepo1Path)
+	assert.NoError(t, err)
+	divergences, err := repo.GetDivergingCommitsFromRepo(basePath, map[string]string{"master": "branch2"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]DivergeObject{"master": {Ahead: 4, Behind: 1}}, divergences)
+
+	// The remote used to fetch the base branches is removed
+	remotes, err := NewCommand("remote").RunInDir(clonedPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "origin", strings.TrimSpace(remotes))
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net"
 	"net/url"
+	"path"
 	"regexp"
 	"strings"
 )
@@ -38,27 +39,30 @@ func NewSubModuleFile(c *Commit, refURL, refID string) *SubModuleFile {
 	}
 }
 
-func getRefURL(refURL, urlPrefix, parentPath string) string {
+// getRefURL returns the web URL of the repository of a submodule, the URLs of
+// the repositories of this instance, including the relative ones and the SSH
+// ones, are rewritten to be under urlPrefix
+func getRefURL(refURL, urlPrefix, repoFullName, sshDomain string) string {
 	if refURL == "" {
 		return ""
 	}
 
-	refURI := strings.TrimSuffix(refURL, ".git")
+	refURI := strings.TrimSuffix(strings.TrimSuffix(refURL, "/"), ".git")
+	urlPrefix = strings.TrimSuffix(urlPrefix, "/")
 
 	prefixURL, _ := url.Parse(urlPrefix)
 	urlPrefixHostname, _, err := net.SplitHostPort(prefixURL.Host)
 	if err != nil {
 		urlPrefixHostname = prefixURL.Host
 	}
+	if sshDomain == "" {
+		sshDomain = urlPrefixHostname
+	}
 
 	// Relative url prefix check (according to git submodule documentation)
 	if strings.HasPrefix(refURI, "./") || strings.HasPrefix(refURI, "../") {
-		// ...construct and return correct submodule url here...
-		idx := strings.Index(parentPath, "/src/")
-		if idx == -1 {
-			return refURI
-		}
-		return strings.TrimSuffix(urlPrefix, "/") + parentPath[:idx] + "/" + refURI
+		// The url is relative to the one of the parent repository
+		return urlPrefix + path.Join("/", repoFullName, refURI)
 	}
 
 	if !strings.Contains(refURI, "://") {
@@ -69,16 +73,16 @@ func getRefURL(refURL, urlPrefix, parentPath string) string {
 
 			m := match[0]
 			refHostname := m[2]
-			path := m[3]
+			refPath := m[3]
 
-			if !strings.HasPrefix(path, "/") {
-				path = "/" + path
+			if !strings.HasPrefix(refPath, "/") {
+				refPath = "/" + refPath
 			}
 
-			if urlPrefixHostname == refHostname {
-				return prefixURL.Scheme + "://" + urlPrefixHostname + path
+			if urlPrefixHostname == refHostname || sshDomain == refHostname {
+				return urlPrefix + refPath
 			}
-			return "http://" + refHostname + path
+			return "http://" + refHostname + refPath
 		}
 	}
 
@@ -96,25 +100,30 @@ func getRefURL(refURL, urlPrefix, parentPath string) string {
 
 	for _, scheme := range supportedSchemes {
 		if ref.Scheme == scheme {
-			if urlPrefixHostname == refHostname {
-				return prefixURL.Scheme + "://" + prefixURL.Host + ref.Path
-			} else if ref.Scheme == "http" || ref.Scheme == "https" {
+			if ref.Scheme == "http" || ref.Scheme == "https" {
+				if urlPrefixHostname == refHostname {
+					// The path already contains the sub-path of the instance if there is one
+					return prefixURL.Scheme + "://" + prefixURL.Host + ref.Path
+				}
 				if len(ref.User.Username()) > 0 {
 					return ref.Scheme + "://" + fmt.Sprintf("%v", ref.User) + "@" + ref.Host + ref.Path
 				}
 				return ref.Scheme + "://" + ref.Host + ref.Path
-			} else {
-				return "http://" + refHostname + ref.Path
+			} else if urlPrefixHostname == refHostname || sshDomain == refHostname {
+				return urlPrefix + ref.Path
 			}
+			return "http://" + refHostname + ref.Path
 		}
 	}
 
 	return ""
 }
 
-// RefURL guesses and returns reference URL.
-func (sf *SubModuleFile) RefURL(urlPrefix string, parentPath string) string {
-	return getRefURL(sf.refURL, urlPrefix, parentPath)
+// RefURL guesses and returns the web URL of the repository of the submodule,
+// repoFullName is the full name of the parent repository and sshDomain the
+// domain of the SSH URLs of the repositories of this instance
+func (sf *SubModuleFile) RefURL(urlPrefix, repoFullName, sshDomain string) string {
+	return getRefURL(sf.refURL, urlPrefix, repoFullName, sshDomain)
 }
 
 // RefID returns reference ID.
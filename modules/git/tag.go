@@ -12,13 +12,21 @@ import (
 
 // Tag represents a Git tag.
 type Tag struct {
-	Name    string
-	ID      SHA1
-	repo    *Repository
-	Object  SHA1 // The id of this commit object
-	Type    string
-	Tagger  *Signature
-	Message string
+	Name      string
+	ID        SHA1
+	repo      *Repository
+	Object    SHA1 // The id of this commit object
+	Type      string
+	Tagger    *Signature
+	Message   string
+	Signature *CommitGPGSignature
+}
+
+// signatureStarts start the GPG and SSH signatures which are appended to the
+// message of signed tags
+var signatureStarts = [][]byte{
+	[]byte("\n-----BEGIN PGP SIGNATURE-----"),
+	[]byte("\n-----BEGIN SSH SIGNATURE-----"),
 }
 
 // Commit return the commit of the tag reference
@@ -60,7 +68,23 @@ l:
 			}
 			nextline += eol + 1
 		case eol == 0:
-			tag.Message = strings.TrimRight(string(data[nextline+1:]), "\n")
+			message := data[nextline+1:]
+			// The signature is made on the content of the tag which precedes it
+			idx := -1
+			for _, start := range signatureStarts {
+				if i := bytes.LastIndex(data[nextline:], start); i > idx {
+					idx = i
+				}
+			}
+			if idx >= 0 {
+				idx += nextline + 1
+				tag.Signature = &CommitGPGSignature{
+					Signature: string(data[idx:]),
+					Payload:   string(data[:idx]),
+				}
+				message = data[nextline+1 : idx]
+			}
+			tag.Message = strings.TrimRight(string(message), "\n")
 			break l
 		default:
 			break l
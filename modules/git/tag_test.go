@@ -0,0 +1,73 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTagData(t *testing.T) {
+	tag, err := parseTagData([]byte(`object 3b114ab800c6432ad42387ccf6bc8d4388a2885a
+type commit
+tag v1.0
+tagger Jane Doe <jane@example.com> 1568239411 +0200
+
+Version 1.0
+
+With a description
+`))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "3b114ab800c6432ad42387ccf6bc8d4388a2885a", tag.Object.String())
+	assert.EqualValues(t, "commit", tag.Type)
+	assert.EqualValues(t, "jane@example.com", tag.Tagger.Email)
+	assert.EqualValues(t, "Version 1.0\n\nWith a description", tag.Message)
+	assert.Nil(t, tag.Signature)
+
+	payload := `object 3b114ab800c6432ad42387ccf6bc8d4388a2885a
+type commit
+tag v1.1
+tagger Jane Doe <jane@example.com> 1568239411 +0200
+
+Version 1.1
+`
+	signature := `-----BEGIN PGP SIGNATURE-----
+
+iQEzBAABCAAdFiEE4hO7sgQdW4JRCrnGv3EIJ9qtYpIFAl15ko8ACgkQv3EIJ9qt
+YpKXJgf/dmFCgqXiTlFnUVdl3nUa3lJwaLhWNRkpeW8hF5xeS+uXUe8Nh0klbFtD
+=nlTH
+-----END PGP SIGNATURE-----
+`
+	tag, err = parseTagData([]byte(payload + signature))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "Version 1.1", tag.Message)
+	if assert.NotNil(t, tag.Signature) {
+		assert.EqualValues(t, payload, tag.Signature.Payload)
+		assert.EqualValues(t, signature, tag.Signature.Signature)
+	}
+
+	payload = `object 96950877af72eb9d251b8a44cc39c472e0a7d7e0
+type commit
+tag v1.2
+tagger Jane Doe <jane@example.com> 1568239411 +0000
+
+Version 1.2
+`
+	signature = `-----BEGIN SSH SIGNATURE-----
+U1NIU0lHAAAAAQAAADMAAAALc3NoLWVkMjU1MTkAAAAgVPSMDrp05CnpxUpiWSL2ALHKYz
+3KgKuekYmXxfh2seYAAAADZ2l0AAAAAAAAAAZzaGE1MTIAAABTAAAAC3NzaC1lZDI1NTE5
+AAAAQGozpMRR+kEo9KW8cNUVTCvxRk7lH2h5noFvyzu8uPA2De+bV754IMBRV2M7XyHVql
+6T4GsP7eoCM6+vJZ/jUgc=
+-----END SSH SIGNATURE-----
+`
+	tag, err = parseTagData([]byte(payload + signature))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "Version 1.2", tag.Message)
+	if assert.NotNil(t, tag.Signature) {
+		assert.EqualValues(t, payload, tag.Signature.Payload)
+		assert.EqualValues(t, signature, tag.Signature.Signature)
+	}
+}
@@ -0,0 +1,715 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Error is an error of a query, reported in its result
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []Location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+	// Extensions are additional information on the error, like its code
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func (err *Error) Error() string {
+	return err.Message
+}
+
+// Result is the result of a query
+type Result struct {
+	// Data is the value of the query, nil if it could not be executed
+	Data   interface{} `json:"data,omitempty"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+// Params are the parameters of a query
+type Params struct {
+	Schema        *Schema
+	Query         string
+	OperationName string
+	Variables     map[string]interface{}
+	// Context is passed to the resolvers
+	Context interface{}
+	// MaxDepth is the maximum nesting of the fields of the query, unlimited
+	// if it is not positive
+	MaxDepth int
+	// MaxComplexity is the maximum cost of the query, unlimited if it is not
+	// positive. The cost of a field is its own cost plus the cost of its
+	// selection, which is multiplied by the argument first of lists.
+	MaxComplexity int
+	// Validated is called, if set, once the query has been parsed and
+	// validated, before it is executed
+	Validated func()
+}
+
+// orderedMap is an object of the result, whose keys are in the order of the query
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newOrderedMap() *orderedMap {
+	return &orderedMap{values: map[string]interface{}{}}
+}
+
+func (m *orderedMap) set(key string, value interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// MarshalJSON implements json.Marshaler
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Do parses and executes a query
+func Do(p Params) *Result {
+	doc, err := parse(p.Query)
+	if err != nil {
+		return &Result{Errors: []*Error{toError(err)}}
+	}
+
+	if err = checkFragmentCycles(doc); err != nil {
+		return &Result{Errors: []*Error{toError(err)}}
+	}
+
+	op, err := selectOperation(doc, p.OperationName)
+	if err != nil {
+		return &Result{Errors: []*Error{toError(err)}}
+	}
+
+	e := &executor{
+		schema:    p.Schema,
+		doc:       doc,
+		context:   p.Context,
+		variables: map[string]interface{}{},
+		declared:  map[string]bool{},
+	}
+	if err = e.coerceVariables(op, p.Variables); err != nil {
+		return &Result{Errors: []*Error{toError(err)}}
+	}
+
+	v := &validator{executor: e, maxDepth: p.MaxDepth, maxCost: p.MaxComplexity}
+	v.selectionSet(p.Schema.Query, op.selections, 1, 1)
+	if v.depthExceeded() {
+		return &Result{Errors: []*Error{{
+			Message:   fmt.Sprintf("the query exceeds the maximum depth of %d", p.MaxDepth),
+			Locations: []Location{op.loc},
+		}}}
+	}
+	if v.costExceeded() {
+		return &Result{Errors: []*Error{{
+			Message:   fmt.Sprintf("the query exceeds the maximum complexity of %d", p.MaxComplexity),
+			Locations: []Location{op.loc},
+		}}}
+	}
+	if len(v.errors) > 0 {
+		return &Result{Errors: v.errors}
+	}
+	if p.Validated != nil {
+		p.Validated()
+	}
+
+	data := e.executeSelectionSet(p.Schema.Query, nil, op.selections, nil)
+	return &Result{Data: data, Errors: e.errors}
+}
+
+// checkFragmentCycles returns an error if a fragment spreads itself, directly
+// or through other fragments
+func checkFragmentCycles(doc *document) error {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[string]int, len(doc.fragments))
+	var visit func(name string, loc Location) error
+	var visitSelections func(selections []selection) error
+	visit = func(name string, loc Location) error {
+		frag, ok := doc.fragments[name]
+		if !ok {
+			// unknown fragments are reported with the fields
+			return nil
+		}
+		switch state[name] {
+		case visiting:
+			return &Error{Message: fmt.Sprintf("fragment %q spreads itself", name), Locations: []Location{loc}}
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		err := visitSelections(frag.selections)
+		state[name] = visited
+		return err
+	}
+	visitSelections = func(selections []selection) error {
+		for _, sel := range selections {
+			var err error
+			switch sel := sel.(type) {
+			case *fieldNode:
+				err = visitSelections(sel.selections)
+			case *inlineFragment:
+				err = visitSelections(sel.selections)
+			case *fragmentSpread:
+				err = visit(sel.name, sel.loc)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for name, frag := range doc.fragments {
+		if err := visit(name, frag.loc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toError(err error) *Error {
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+	return &Error{Message: err.Error()}
+}
+
+func selectOperation(doc *document, name string) (*operation, error) {
+	var op *operation
+	if len(name) == 0 {
+		if len(doc.operations) > 1 {
+			return nil, &Error{Message: "the operation name is required when the document contains several operations"}
+		}
+		op = doc.operations[0]
+	} else {
+		for _, o := range doc.operations {
+			if o.name == name {
+				op = o
+				break
+			}
+		}
+		if op == nil {
+			return nil, &Error{Message: fmt.Sprintf("unknown operation %q", name)}
+		}
+	}
+	if op.kind != "query" {
+		return nil, &Error{Message: fmt.Sprintf("%s operations are not supported", op.kind), Locations: []Location{op.loc}}
+	}
+	return op, nil
+}
+
+type executor struct {
+	schema    *Schema
+	doc       *document
+	context   interface{}
+	variables map[string]interface{}
+	declared  map[string]bool
+	errors    []*Error
+}
+
+// lookupType returns the type a variable is declared with
+func (e *executor) lookupType(ref *typeRef) (Type, error) {
+	var t Type
+	if ref.ofType != nil {
+		ofType, err := e.lookupType(ref.ofType)
+		if err != nil {
+			return nil, err
+		}
+		t = &List{OfType: ofType}
+	} else {
+		var ok bool
+		if t, ok = e.schema.types[ref.name]; !ok {
+			return nil, fmt.Errorf("unknown type %s", ref.name)
+		}
+		if _, ok = t.(*Scalar); !ok {
+			return nil, fmt.Errorf("type %s can not be used as input", ref.name)
+		}
+	}
+	if ref.nonNull {
+		t = &NonNull{OfType: t}
+	}
+	return t, nil
+}
+
+func (e *executor) coerceVariables(op *operation, values map[string]interface{}) error {
+	for _, def := range op.variables {
+		e.declared[def.name] = true
+		t, err := e.lookupType(def.typ)
+		if err != nil {
+			return &Error{Message: fmt.Sprintf("variable $%s: %v", def.name, err), Locations: []Location{def.loc}}
+		}
+		v, ok := values[def.name]
+		if !ok {
+			if def.defaultValue == nil {
+				if _, ok = t.(*NonNull); ok {
+					return &Error{Message: fmt.Sprintf("variable $%s of type %s is required", def.name, t), Locations: []Location{def.loc}}
+				}
+				continue
+			}
+			v = def.defaultValue
+		}
+		if e.variables[def.name], err = e.coerceValue(t, v); err != nil {
+			return &Error{Message: fmt.Sprintf("variable $%s: %v", def.name, err), Locations: []Location{def.loc}}
+		}
+	}
+	return nil
+}
+
+// coerceValue converts the value of an argument, taken from the query or the
+// variables, to the type t
+func (e *executor) coerceValue(t Type, v value) (interface{}, error) {
+	if name, ok := v.(variable); ok {
+		if !e.declared[string(name)] {
+			return nil, fmt.Errorf("variable $%s is not defined", name)
+		}
+		v = e.variables[string(name)]
+	}
+	if nonNull, ok := t.(*NonNull); ok {
+		if v == nil {
+			return nil, fmt.Errorf("expected a value of type %s, found null", t)
+		}
+		return e.coerceValue(nonNull.OfType, v)
+	}
+	if v == nil {
+		return nil, nil
+	}
+
+	switch t := t.(type) {
+	case *List:
+		var items []value
+		switch v := v.(type) {
+		case listValue:
+			items = v
+		case []interface{}:
+			items = make([]value, len(v))
+			for i := range v {
+				items[i] = v[i]
+			}
+		default:
+			items = []value{v}
+		}
+		list := make([]interface{}, len(items))
+		for i, item := range items {
+			var err error
+			if list[i], err = e.coerceValue(t.OfType, item); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	case *Scalar:
+		switch v.(type) {
+		case enumValue, listValue, objectValue, []interface{}, map[string]interface{}:
+			return nil, fmt.Errorf("expected a value of type %s, found %v", t, v)
+		}
+		if t.ParseValue == nil {
+			return v, nil
+		}
+		return t.ParseValue(v)
+	}
+	return nil, fmt.Errorf("type %s can not be used as input", t)
+}
+
+func (e *executor) hasVariable(name string) bool {
+	_, ok := e.variables[name]
+	return ok
+}
+
+func (e *executor) coerceArguments(f *Field, args []*argument) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(f.Args))
+	given := make(map[string]*argument, len(args))
+	for _, arg := range args {
+		if _, ok := f.Args[arg.name]; !ok {
+			return nil, &Error{Message: fmt.Sprintf("unknown argument %q", arg.name), Locations: []Location{arg.loc}}
+		}
+		given[arg.name] = arg
+	}
+	for name, def := range f.Args {
+		var v value = def.DefaultValue
+		if arg, ok := given[name]; ok {
+			// an argument given a variable which is not set takes its default value
+			if name, isVariable := arg.value.(variable); !isVariable || !e.declared[string(name)] || e.hasVariable(string(name)) {
+				v = arg.value
+			}
+		}
+		coerced, err := e.coerceValue(def.Type, v)
+		if err != nil {
+			return nil, fmt.Errorf("argument %q: %v", name, err)
+		}
+		values[name] = coerced
+	}
+	return values, nil
+}
+
+// skip returns true if the directives @skip or @include exclude a selection
+func (e *executor) skip(directives []*directive) (bool, error) {
+	for _, d := range directives {
+		if d.name != "skip" && d.name != "include" {
+			return false, &Error{Message: fmt.Sprintf("unknown directive @%s", d.name), Locations: []Location{d.loc}}
+		}
+		args, err := e.coerceArguments(&Field{Args: Args{"if": {Type: &NonNull{OfType: Boolean}}}}, d.args)
+		if err != nil {
+			return false, &Error{Message: fmt.Sprintf("directive @%s: %v", d.name, err), Locations: []Location{d.loc}}
+		}
+		if args["if"].(bool) == (d.name == "skip") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// collectedField is a field of a selection set and the other fields with the
+// same response key
+type collectedField struct {
+	key   string
+	nodes []*fieldNode
+}
+
+// collectFields returns the fields of a selection set of an object, the
+// fragments being expanded, in the order of the query
+func (e *executor) collectFields(obj *Object, selections []selection) ([]*collectedField, error) {
+	var fields []*collectedField
+	byKey := map[string]*collectedField{}
+	var collect func(selections []selection) error
+	collect = func(selections []selection) error {
+		for _, sel := range selections {
+			switch sel := sel.(type) {
+			case *fieldNode:
+				if skip, err := e.skip(sel.directives); err != nil {
+					return err
+				} else if skip {
+					continue
+				}
+				key := sel.responseKey()
+				if f, ok := byKey[key]; ok {
+					if f.nodes[0].name != sel.name {
+						return &Error{
+							Message:   fmt.Sprintf("fields %s and %s both have the response key %q", f.nodes[0].name, sel.name, key),
+							Locations: []Location{f.nodes[0].loc, sel.loc},
+						}
+					}
+					f.nodes = append(f.nodes, sel)
+					continue
+				}
+				f := &collectedField{key: key, nodes: []*fieldNode{sel}}
+				byKey[key] = f
+				fields = append(fields, f)
+			case *inlineFragment:
+				if skip, err := e.skip(sel.directives); err != nil {
+					return err
+				} else if skip {
+					continue
+				}
+				if len(sel.typeCondition) > 0 && sel.typeCondition != obj.Name {
+					if err := e.checkTypeCondition(sel.typeCondition, sel.loc); err != nil {
+						return err
+					}
+					continue
+				}
+				if err := collect(sel.selections); err != nil {
+					return err
+				}
+			case *fragmentSpread:
+				if skip, err := e.skip(sel.directives); err != nil {
+					return err
+				} else if skip {
+					continue
+				}
+				frag, ok := e.doc.fragments[sel.name]
+				if !ok {
+					return &Error{Message: fmt.Sprintf("unknown fragment %q", sel.name), Locations: []Location{sel.loc}}
+				}
+				if frag.typeCondition != obj.Name {
+					if err := e.checkTypeCondition(frag.typeCondition, frag.loc); err != nil {
+						return err
+					}
+					continue
+				}
+				if err := collect(frag.selections); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	err := collect(selections)
+	return fields, err
+}
+
+func (e *executor) checkTypeCondition(name string, loc Location) error {
+	if _, ok := e.schema.types[name].(*Object); !ok {
+		return &Error{Message: fmt.Sprintf("unknown type %s", name), Locations: []Location{loc}}
+	}
+	return nil
+}
+
+// subSelections returns the selection sets of all the nodes of a field
+func subSelections(nodes []*fieldNode) []selection {
+	if len(nodes) == 1 {
+		return nodes[0].selections
+	}
+	var selections []selection
+	for _, node := range nodes {
+		selections = append(selections, node.selections...)
+	}
+	return selections
+}
+
+// unwrapType returns the object or scalar a type is made of
+func unwrapType(t Type) Type {
+	for {
+		switch u := t.(type) {
+		case *List:
+			t = u.OfType
+		case *NonNull:
+			t = u.OfType
+		default:
+			return t
+		}
+	}
+}
+
+// maxCost bounds the cost computed by the validator, so that it can't
+// overflow whatever the arguments first of the lists
+const maxCost = math.MaxInt32
+
+// validator checks the fields of a query and computes its depth and cost. It
+// stops as soon as a limit is exceeded, so that a query expanding to a huge
+// number of fields through its fragments is rejected without walking them all.
+type validator struct {
+	*executor
+	maxDepth int
+	maxCost  int
+	depth    int
+	cost     int
+	errors   []*Error
+}
+
+func (v *validator) depthExceeded() bool {
+	return v.maxDepth > 0 && v.depth > v.maxDepth
+}
+
+func (v *validator) costExceeded() bool {
+	return v.maxCost > 0 && v.cost > v.maxCost
+}
+
+// addCost adds the cost of a field multiplied by the arguments first of its
+// parent lists
+func (v *validator) addCost(cost, multiplier int) {
+	if multiplier > maxCost/cost {
+		v.cost = maxCost
+		return
+	}
+	v.cost += cost * multiplier
+	if v.cost > maxCost {
+		v.cost = maxCost
+	}
+}
+
+func (v *validator) addError(err error, loc Location) {
+	e := toError(err)
+	if len(e.Locations) == 0 {
+		e.Locations = []Location{loc}
+	}
+	v.errors = append(v.errors, e)
+}
+
+// selectionSet checks the fields of the selection set, multiplier is the
+// product of the arguments first of the parent lists
+func (v *validator) selectionSet(obj *Object, selections []selection, depth, multiplier int) {
+	if depth > v.depth {
+		v.depth = depth
+	}
+	if v.depthExceeded() {
+		return
+	}
+	fields, err := v.collectFields(obj, selections)
+	if err != nil {
+		v.addError(err, selections[0].location())
+		return
+	}
+
+	for _, f := range fields {
+		if v.depthExceeded() || v.costExceeded() {
+			return
+		}
+		node := f.nodes[0]
+		if node.name == "__typename" {
+			if len(node.args) > 0 || len(node.selections) > 0 {
+				v.addError(fmt.Errorf("field __typename has no arguments nor fields"), node.loc)
+			}
+			continue
+		}
+		field, ok := obj.Fields[node.name]
+		if !ok {
+			v.addError(fmt.Errorf("cannot query field %q on type %s", node.name, obj.Name), node.loc)
+			continue
+		}
+		args, err := v.coerceArguments(field, node.args)
+		if err != nil {
+			v.addError(err, node.loc)
+			continue
+		}
+
+		fieldCost := field.Cost
+		if fieldCost <= 0 {
+			fieldCost = 1
+		}
+		selections := subSelections(f.nodes)
+		switch t := unwrapType(field.Type).(type) {
+		case *Object:
+			if len(selections) == 0 {
+				v.addError(fmt.Errorf("field %q of type %s must have a selection of fields", node.name, field.Type), node.loc)
+				continue
+			}
+			v.addCost(fieldCost, multiplier)
+			childMultiplier := multiplier
+			if _, ok := field.Type.(*List); ok {
+				if first, ok := args["first"].(int); ok && first > 1 {
+					if first > maxCost/multiplier {
+						childMultiplier = maxCost
+					} else {
+						childMultiplier = multiplier * first
+					}
+				}
+			}
+			v.selectionSet(t, selections, depth+1, childMultiplier)
+		default:
+			if len(selections) > 0 {
+				v.addError(fmt.Errorf("field %q of type %s has no fields", node.name, field.Type), node.loc)
+				continue
+			}
+			v.addCost(fieldCost, multiplier)
+		}
+	}
+}
+
+func (e *executor) addError(err error, node *fieldNode, path []interface{}) {
+	gqlErr := &Error{
+		Message:   err.Error(),
+		Locations: []Location{node.loc},
+		Path:      append([]interface{}{}, path...),
+	}
+	if ge, ok := err.(*Error); ok {
+		gqlErr.Extensions = ge.Extensions
+	}
+	e.errors = append(e.errors, gqlErr)
+}
+
+func (e *executor) executeSelectionSet(obj *Object, source interface{}, selections []selection, path []interface{}) *orderedMap {
+	result := newOrderedMap()
+	// the selection set has been validated, so there can't be errors here
+	fields, _ := e.collectFields(obj, selections)
+	for _, f := range fields {
+		node := f.nodes[0]
+		if node.name == "__typename" {
+			result.set(f.key, obj.Name)
+			continue
+		}
+		result.set(f.key, e.executeField(obj.Fields[node.name], source, f.nodes, append(path, f.key)))
+	}
+	return result
+}
+
+func (e *executor) executeField(field *Field, source interface{}, nodes []*fieldNode, path []interface{}) interface{} {
+	args, err := e.coerceArguments(field, nodes[0].args)
+	if err != nil {
+		e.addError(err, nodes[0], path)
+		return nil
+	}
+
+	params := ResolveParams{
+		Context: e.context,
+		Source:  source,
+		Args:    args,
+	}
+	var v interface{}
+	if field.Resolve != nil {
+		v, err = field.Resolve(params)
+	} else {
+		v, err = resolveProperty(source, nodes[0].name)
+	}
+	if err != nil {
+		e.addError(err, nodes[0], path)
+		return nil
+	}
+	return e.completeValue(field.Type, nodes, v, path)
+}
+
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// completeValue converts the resolved value v of a field to the result
+func (e *executor) completeValue(t Type, nodes []*fieldNode, v interface{}, path []interface{}) interface{} {
+	if nonNull, ok := t.(*NonNull); ok {
+		t = nonNull.OfType
+	}
+	if isNil(v) {
+		return nil
+	}
+
+	switch t := t.(type) {
+	case *Scalar:
+		rv := reflect.ValueOf(v)
+		for rv.Kind() == reflect.Ptr {
+			rv = rv.Elem()
+		}
+		serialized, err := t.Serialize(rv.Interface())
+		if err != nil {
+			e.addError(err, nodes[0], path)
+			return nil
+		}
+		return serialized
+	case *List:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			e.addError(fmt.Errorf("expected a list, found %T", v), nodes[0], path)
+			return nil
+		}
+		list := make([]interface{}, rv.Len())
+		for i := range list {
+			list[i] = e.completeValue(t.OfType, nodes, rv.Index(i).Interface(), append(path, i))
+		}
+		return list
+	case *Object:
+		return e.executeSelectionSet(t, v, subSelections(nodes), path)
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testBook struct {
+	ID       int64  `json:"id"`
+	Title    string `json:"title"`
+	PageSize int    `json:"page_size"`
+	Author   *testAuthor
+}
+
+type testAuthor struct {
+	Name  string
+	books []*testBook
+}
+
+func testSchema(t *testing.T) *Schema {
+	author := &testAuthor{Name: "Jane"}
+	for i := 1; i <= 3; i++ {
+		author.books = append(author.books, &testBook{ID: int64(i), Title: fmt.Sprintf("Book %d", i), PageSize: 20 * i, Author: author})
+	}
+
+	authorType := &Object{Name: "Author"}
+	bookType := &Object{
+		Name: "Book",
+		Fields: Fields{
+			"id":       {Type: ID},
+			"title":    {Type: String},
+			"pageSize": {Type: Int},
+			"author":   {Type: authorType},
+			"fail": {
+				Type: String,
+				Resolve: func(p ResolveParams) (interface{}, error) {
+					return nil, fmt.Errorf("failed")
+				},
+			},
+		},
+	}
+	authorType.Fields = Fields{
+		"name": {Type: String},
+		"books": {
+			Type: &List{OfType: bookType},
+			Args: Args{
+				"first": {Type: Int, DefaultValue: 10},
+			},
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				books := p.Source.(*testAuthor).books
+				if first := p.Args["first"].(int); first < len(books) {
+					books = books[:first]
+				}
+				return books, nil
+			},
+		},
+	}
+	query := &Object{
+		Name: "Query",
+		Fields: Fields{
+			"book": {
+				Type: bookType,
+				Args: Args{
+					"id": {Type: &NonNull{OfType: Int}},
+				},
+				Resolve: func(p ResolveParams) (interface{}, error) {
+					for _, b := range author.books {
+						if b.ID == int64(p.Args["id"].(int)) {
+							return b, nil
+						}
+					}
+					return nil, nil
+				},
+			},
+			"author": {
+				Type: authorType,
+				Resolve: func(p ResolveParams) (interface{}, error) {
+					return author, nil
+				},
+			},
+			"echo": {
+				Type: String,
+				Args: Args{
+					"text": {Type: String, DefaultValue: "default"},
+				},
+				Resolve: func(p ResolveParams) (interface{}, error) {
+					return p.Args["text"], nil
+				},
+			},
+		},
+	}
+	schema, err := NewSchema(query)
+	assert.NoError(t, err)
+	return schema
+}
+
+func doJSON(t *testing.T, p Params) string {
+	result, err := json.Marshal(Do(p))
+	assert.NoError(t, err)
+	return string(result)
+}
+
+func TestDo(t *testing.T) {
+	schema := testSchema(t)
+
+	for _, c := range []struct {
+		query     string
+		variables map[string]interface{}
+		expected  string
+	}{
+		{
+			query:    `{ book(id: 2) { id title pageSize } }`,
+			expected: `{"data":{"book":{"id":"2","title":"Book 2","pageSize":40}}}`,
+		},
+		{
+			query: `query Books($first: Int = 1) {
+				# the author and its books
+				writer: author {
+					name
+					books(first: $first) { ...bookFields, __typename }
+				}
+			}
+			fragment bookFields on Book { title author { name } }`,
+			expected: `{"data":{"writer":{"name":"Jane","books":[{"title":"Book 1","author":{"name":"Jane"},"__typename":"Book"}]}}}`,
+		},
+		{
+			query:     `query Books($first: Int = 1) { author { books(first: $first) { id } } }`,
+			variables: map[string]interface{}{"first": float64(2)},
+			expected:  `{"data":{"author":{"books":[{"id":"1"},{"id":"2"}]}}}`,
+		},
+		{
+			query:     `query($skip: Boolean!) { book(id: 1) { title @skip(if: $skip) ... on Book @include(if: true) { id } } }`,
+			variables: map[string]interface{}{"skip": true},
+			expected:  `{"data":{"book":{"id":"1"}}}`,
+		},
+		{
+			query:    `{ a: echo b: echo(text: "say \"hello\"") c: echo(text: null) }`,
+			expected: `{"data":{"a":"default","b":"say \"hello\"","c":null}}`,
+		},
+		{
+			query:    `{ book(id: 1) { title fail } missing: book(id: 5) { title } }`,
+			expected: `{"data":{"book":{"title":"Book 1","fail":null},"missing":null},"errors":[{"message":"failed","locations":[{"line":1,"column":23}],"path":["book","fail"]}]}`,
+		},
+	} {
+		assert.Equal(t, c.expected, doJSON(t, Params{Schema: schema, Query: c.query, Variables: c.variables}), c.query)
+	}
+}
+
+func TestDo_Errors(t *testing.T) {
+	schema := testSchema(t)
+
+	for _, c := range []struct {
+		query    string
+		expected string
+	}{
+		{`{ book(id: 1) { title }`, `Syntax Error: expected name, found <EOF>`},
+		{`{ book(id: "1") { title } }`, `argument "id": Int cannot represent value 1`},
+		{`{ book { title } }`, `argument "id": expected a value of type Int!, found null`},
+		{`{ book(id: 1, page: 2) { title } }`, `unknown argument "page"`},
+		{`{ book(id: 1) { isbn } }`, `cannot query field "isbn" on type Book`},
+		{`{ book(id: 1) }`, `field "book" of type Book must have a selection of fields`},
+		{`{ book(id: 1) { title { name } } }`, `field "title" of type String has no fields`},
+		{`{ book(id: $id) { title } }`, `argument "id": variable $id is not defined`},
+		{`{ book(id: 1) { ...missing } }`, `unknown fragment "missing"`},
+		{`{ author { ...a } } fragment a on Author { books { author { ...a } } }`, `fragment "a" spreads itself`},
+		{`mutation { book(id: 1) { title } }`, `mutation operations are not supported`},
+		{`query A { echo } query B { echo }`, `the operation name is required when the document contains several operations`},
+		{`query($id: Int!) { book(id: $id) { title } }`, `variable $id of type Int! is required`},
+	} {
+		result := Do(Params{Schema: schema, Query: c.query})
+		assert.Nil(t, result.Data, c.query)
+		if assert.Len(t, result.Errors, 1, c.query) {
+			assert.Equal(t, c.expected, result.Errors[0].Message, c.query)
+		}
+	}
+}
+
+func TestDo_Validated(t *testing.T) {
+	schema := testSchema(t)
+
+	validated := false
+	result := Do(Params{Schema: schema, Query: `{ book(id: 1) { isbn } }`, Validated: func() { validated = true }})
+	assert.Len(t, result.Errors, 1)
+	assert.False(t, validated)
+
+	result = Do(Params{Schema: schema, Query: `{ book(id: 1) { title } }`, Validated: func() { validated = true }})
+	assert.Empty(t, result.Errors)
+	assert.True(t, validated)
+}
+
+func TestDo_Limits(t *testing.T) {
+	schema := testSchema(t)
+	query := `{ author { books(first: 3) { author { books(first: 2) { title } } } } }`
+
+	// author(1) + books(1) + 3 * (author(1) + books(1) + 2 * title(1))
+	result := Do(Params{Schema: schema, Query: query, MaxComplexity: 13})
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "the query exceeds the maximum complexity of 13", result.Errors[0].Message)
+	}
+	result = Do(Params{Schema: schema, Query: query, MaxComplexity: 14})
+	assert.Empty(t, result.Errors)
+
+	result = Do(Params{Schema: schema, Query: query, MaxDepth: 4})
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "the query exceeds the maximum depth of 4", result.Errors[0].Message)
+	}
+	result = Do(Params{Schema: schema, Query: query, MaxDepth: 5})
+	assert.Empty(t, result.Errors)
+
+	// the cost of huge lists can't overflow
+	query = `{ author { books(first: 2147483647) { author { books(first: 2147483647) { author { books(first: 2147483647) { title } } } } } } }`
+	result = Do(Params{Schema: schema, Query: query, MaxComplexity: 5000})
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "the query exceeds the maximum complexity of 5000", result.Errors[0].Message)
+	}
+	assert.Nil(t, result.Data)
+}
+
+func TestDo_FragmentsLimits(t *testing.T) {
+	schema := testSchema(t)
+
+	// every fragment doubles the number of fields of the query
+	query := `{ author { ...F0 } }
+fragment F0 on Author { a: books(first: 1) { author { ...F1 } } b: books(first: 1) { author { ...F1 } } }`
+	for i := 1; i < 30; i++ {
+		query += fmt.Sprintf(`
+fragment F%d on Author { a: books(first: 1) { author { ...F%d } } b: books(first: 1) { author { ...F%d } } }`, i, i+1, i+1)
+	}
+	query += `
+fragment F30 on Author { name }`
+
+	result := Do(Params{Schema: schema, Query: query, MaxComplexity: 5000})
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "the query exceeds the maximum complexity of 5000", result.Errors[0].Message)
+	}
+	result = Do(Params{Schema: schema, Query: query, MaxDepth: 10})
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "the query exceeds the maximum depth of 10", result.Errors[0].Message)
+	}
+}
+
+func TestDo_Nesting(t *testing.T) {
+	schema := testSchema(t)
+
+	query := strings.Repeat("{ author ", 1000) + strings.Repeat("}", 1000)
+	result := Do(Params{Schema: schema, Query: query})
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "Syntax Error: the query is nested more than 100 levels deep", result.Errors[0].Message)
+	}
+
+	query = `{ echo(text: ` + strings.Repeat("[", 1000) + strings.Repeat("]", 1000) + `) }`
+	result = Do(Params{Schema: schema, Query: query})
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "Syntax Error: the query is nested more than 100 levels deep", result.Errors[0].Message)
+	}
+
+	query = `query($v: ` + strings.Repeat("[", 1000) + "Int" + strings.Repeat("]", 1000) + `) { author { name } }`
+	result = Do(Params{Schema: schema, Query: query})
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "Syntax Error: the query is nested more than 100 levels deep", result.Errors[0].Message)
+	}
+}
+
+func TestSchema_String(t *testing.T) {
+	assert.Equal(t, `type Query {
+  author: Author
+  book(id: Int!): Book
+  echo(text: String = "default"): String
+}
+
+type Author {
+  books(first: Int = 10): [Book]
+  name: String
+}
+
+type Book {
+  author: Author
+  fail: String
+  id: ID
+  pageSize: Int
+  title: String
+}
+`, testSchema(t).String())
+}
@@ -0,0 +1,426 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+type operation struct {
+	kind       string
+	name       string
+	variables  []*variableDefinition
+	directives []*directive
+	selections []selection
+	loc        Location
+}
+
+type variableDefinition struct {
+	name         string
+	typ          *typeRef
+	defaultValue value
+	loc          Location
+}
+
+// typeRef is the type of a variable, a list if ofType is set, a named type
+// otherwise
+type typeRef struct {
+	name    string
+	ofType  *typeRef
+	nonNull bool
+}
+
+func (t *typeRef) String() string {
+	s := t.name
+	if t.ofType != nil {
+		s = "[" + t.ofType.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+// value is the value of an argument in a query, either a literal of Go type
+// int, float64, string, bool or nil, or one of variable, enumValue, listValue
+// and objectValue
+type value interface{}
+
+type (
+	variable    string
+	enumValue   string
+	listValue   []value
+	objectValue map[string]value
+)
+
+type argument struct {
+	name  string
+	value value
+	loc   Location
+}
+
+type directive struct {
+	name string
+	args []*argument
+	loc  Location
+}
+
+type selection interface {
+	location() Location
+}
+
+type fieldNode struct {
+	alias      string
+	name       string
+	args       []*argument
+	directives []*directive
+	selections []selection
+	loc        Location
+}
+
+func (f *fieldNode) location() Location { return f.loc }
+
+// responseKey returns the key of the field in the result
+func (f *fieldNode) responseKey() string {
+	if len(f.alias) > 0 {
+		return f.alias
+	}
+	return f.name
+}
+
+type fragmentSpread struct {
+	name       string
+	directives []*directive
+	loc        Location
+}
+
+func (f *fragmentSpread) location() Location { return f.loc }
+
+type inlineFragment struct {
+	typeCondition string
+	directives    []*directive
+	selections    []selection
+	loc           Location
+}
+
+func (f *inlineFragment) location() Location { return f.loc }
+
+type fragment struct {
+	name          string
+	typeCondition string
+	directives    []*directive
+	selections    []selection
+	loc           Location
+}
+
+// maxNesting is the maximum nesting of the selection sets, values and types
+// of a query, deeper queries are rejected while they are parsed so that they
+// can't exhaust the stack
+const maxNesting = 100
+
+type parser struct {
+	lexer   *lexer
+	tok     token
+	nesting int
+}
+
+// parse parses a query document
+func parse(query string) (doc *document, err error) {
+	p := &parser{lexer: newLexer(query)}
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*Error)
+			if !ok {
+				panic(r)
+			}
+			doc, err = nil, e
+		}
+	}()
+	p.advance()
+	return p.parseDocument(), nil
+}
+
+// fail aborts the parsing, the error is recovered by parse
+func (p *parser) fail(loc Location, format string, args ...interface{}) {
+	panic(&Error{Message: "Syntax Error: " + fmt.Sprintf(format, args...), Locations: []Location{loc}})
+}
+
+// nest enters a nested selection set, value or type, it has to be followed by
+// a deferred call to unnest
+func (p *parser) nest(loc Location) {
+	p.nesting++
+	if p.nesting > maxNesting {
+		p.fail(loc, "the query is nested more than %d levels deep", maxNesting)
+	}
+}
+
+func (p *parser) unnest() {
+	p.nesting--
+}
+
+func (p *parser) advance() token {
+	prev := p.tok
+	tok, err := p.lexer.next()
+	if err != nil {
+		panic(err)
+	}
+	p.tok = tok
+	return prev
+}
+
+func (p *parser) peek(value string) bool {
+	return p.tok.kind == tokenPunctuator && p.tok.value == value
+}
+
+func (p *parser) skip(value string) bool {
+	if p.peek(value) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(value string) {
+	if !p.skip(value) {
+		p.fail(p.tok.loc, "expected %q, found %s", value, p.tok)
+	}
+}
+
+func (p *parser) parseName() string {
+	if p.tok.kind != tokenName {
+		p.fail(p.tok.loc, "expected name, found %s", p.tok)
+	}
+	return p.advance().value
+}
+
+func (p *parser) parseDocument() *document {
+	doc := &document{fragments: make(map[string]*fragment)}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek("{"):
+			doc.operations = append(doc.operations, &operation{
+				kind:       "query",
+				loc:        p.tok.loc,
+				selections: p.parseSelectionSet(),
+			})
+		case p.tok.kind == tokenName && p.tok.value == "fragment":
+			f := p.parseFragment()
+			if _, ok := doc.fragments[f.name]; ok {
+				p.fail(f.loc, "there can be only one fragment named %q", f.name)
+			}
+			doc.fragments[f.name] = f
+		case p.tok.kind == tokenName:
+			doc.operations = append(doc.operations, p.parseOperation())
+		default:
+			p.fail(p.tok.loc, "unexpected %s", p.tok)
+		}
+	}
+	if len(doc.operations) == 0 {
+		p.fail(p.tok.loc, "the document contains no operation")
+	}
+	return doc
+}
+
+func (p *parser) parseOperation() *operation {
+	op := &operation{loc: p.tok.loc, kind: p.parseName()}
+	switch op.kind {
+	case "query", "mutation", "subscription":
+	default:
+		p.fail(op.loc, "unexpected %q", op.kind)
+	}
+	if p.tok.kind == tokenName {
+		op.name = p.parseName()
+	}
+	if p.skip("(") {
+		for !p.skip(")") {
+			op.variables = append(op.variables, p.parseVariableDefinition())
+		}
+	}
+	op.directives = p.parseDirectives()
+	op.selections = p.parseSelectionSet()
+	return op
+}
+
+func (p *parser) parseVariableDefinition() *variableDefinition {
+	def := &variableDefinition{loc: p.tok.loc}
+	p.expect("$")
+	def.name = p.parseName()
+	p.expect(":")
+	def.typ = p.parseTypeRef()
+	if p.skip("=") {
+		def.defaultValue = p.parseValue(true)
+	}
+	return def
+}
+
+func (p *parser) parseTypeRef() *typeRef {
+	t := &typeRef{}
+	p.nest(p.tok.loc)
+	defer p.unnest()
+	if p.skip("[") {
+		t.ofType = p.parseTypeRef()
+		p.expect("]")
+	} else {
+		t.name = p.parseName()
+	}
+	t.nonNull = p.skip("!")
+	return t
+}
+
+func (p *parser) parseFragment() *fragment {
+	f := &fragment{loc: p.tok.loc}
+	p.advance()
+	f.name = p.parseName()
+	if f.name == "on" {
+		p.fail(f.loc, "a fragment can not be named \"on\"")
+	}
+	if p.parseName() != "on" {
+		p.fail(f.loc, "expected \"on\" after the name of fragment %q", f.name)
+	}
+	f.typeCondition = p.parseName()
+	f.directives = p.parseDirectives()
+	f.selections = p.parseSelectionSet()
+	return f
+}
+
+func (p *parser) parseSelectionSet() []selection {
+	p.nest(p.tok.loc)
+	defer p.unnest()
+	p.expect("{")
+	var selections []selection
+	for !p.skip("}") {
+		selections = append(selections, p.parseSelection())
+	}
+	if len(selections) == 0 {
+		p.fail(p.tok.loc, "a selection set can not be empty")
+	}
+	return selections
+}
+
+func (p *parser) parseSelection() selection {
+	loc := p.tok.loc
+	if p.skip("...") {
+		if p.tok.kind == tokenName && p.tok.value != "on" {
+			return &fragmentSpread{
+				name:       p.parseName(),
+				directives: p.parseDirectives(),
+				loc:        loc,
+			}
+		}
+		f := &inlineFragment{loc: loc}
+		if p.tok.kind == tokenName {
+			p.advance()
+			f.typeCondition = p.parseName()
+		}
+		f.directives = p.parseDirectives()
+		f.selections = p.parseSelectionSet()
+		return f
+	}
+
+	f := &fieldNode{loc: loc, name: p.parseName()}
+	if p.skip(":") {
+		f.alias, f.name = f.name, p.parseName()
+	}
+	f.args = p.parseArguments()
+	f.directives = p.parseDirectives()
+	if p.peek("{") {
+		f.selections = p.parseSelectionSet()
+	}
+	return f
+}
+
+func (p *parser) parseArguments() []*argument {
+	if !p.skip("(") {
+		return nil
+	}
+	var args []*argument
+	for !p.skip(")") {
+		arg := &argument{loc: p.tok.loc, name: p.parseName()}
+		p.expect(":")
+		arg.value = p.parseValue(false)
+		args = append(args, arg)
+	}
+	return args
+}
+
+func (p *parser) parseDirectives() []*directive {
+	var directives []*directive
+	for p.peek("@") {
+		d := &directive{loc: p.tok.loc}
+		p.advance()
+		d.name = p.parseName()
+		d.args = p.parseArguments()
+		directives = append(directives, d)
+	}
+	return directives
+}
+
+// parseValue parses the value of an argument, which can't contain variables
+// if it is constant
+func (p *parser) parseValue(constant bool) value {
+	tok := p.tok
+	p.nest(tok.loc)
+	defer p.unnest()
+	switch tok.kind {
+	case tokenInt:
+		p.advance()
+		i, err := strconv.Atoi(tok.value)
+		if err != nil {
+			p.fail(tok.loc, "invalid integer %s", tok.value)
+		}
+		return i
+	case tokenFloat:
+		p.advance()
+		f, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			p.fail(tok.loc, "invalid float %s", tok.value)
+		}
+		return f
+	case tokenString:
+		p.advance()
+		return tok.value
+	case tokenName:
+		p.advance()
+		switch tok.value {
+		case "true":
+			return true
+		case "false":
+			return false
+		case "null":
+			return nil
+		}
+		return enumValue(tok.value)
+	}
+
+	switch {
+	case p.skip("$"):
+		if constant {
+			p.fail(tok.loc, "unexpected variable in a constant value")
+		}
+		return variable(p.parseName())
+	case p.skip("["):
+		list := listValue{}
+		for !p.skip("]") {
+			list = append(list, p.parseValue(constant))
+		}
+		return list
+	case p.skip("{"):
+		obj := objectValue{}
+		for !p.skip("}") {
+			name := p.parseName()
+			p.expect(":")
+			obj[name] = p.parseValue(constant)
+		}
+		return obj
+	}
+	p.fail(tok.loc, "unexpected %s", tok)
+	return nil
+}
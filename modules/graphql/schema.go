@@ -0,0 +1,397 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package graphql
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Type is the type of a field or of an argument
+type Type interface {
+	// String returns the type as it is written in a schema, e.g. [Issue]
+	String() string
+}
+
+// Scalar is a leaf type
+type Scalar struct {
+	Name        string
+	Description string
+	// Serialize converts a resolved value to its representation in the result
+	Serialize func(v interface{}) (interface{}, error)
+	// ParseValue converts an argument, literal or read from the variables, to
+	// the value passed to the resolvers
+	ParseValue func(v interface{}) (interface{}, error)
+}
+
+func (s *Scalar) String() string {
+	return s.Name
+}
+
+// List is a list of values of a type
+type List struct {
+	OfType Type
+}
+
+func (l *List) String() string {
+	return "[" + l.OfType.String() + "]"
+}
+
+// NonNull is a type which does not accept null, it may only be used for arguments
+type NonNull struct {
+	OfType Type
+}
+
+func (n *NonNull) String() string {
+	return n.OfType.String() + "!"
+}
+
+// Object is a type made of fields
+type Object struct {
+	Name        string
+	Description string
+	Fields      Fields
+}
+
+func (o *Object) String() string {
+	return o.Name
+}
+
+// Fields are the fields of an object by name
+type Fields map[string]*Field
+
+// Field is a field of an object
+type Field struct {
+	Type        Type
+	Description string
+	Args        Args
+	// Resolve returns the value of the field, the property of the source with
+	// the same name is taken if it is nil, see PropertyResolver
+	Resolve ResolveFunc
+	// Cost is the complexity of resolving the field, 1 if it is not set
+	Cost int
+}
+
+// Args are the arguments of a field by name
+type Args map[string]*Argument
+
+// Argument is an argument of a field
+type Argument struct {
+	Type         Type
+	Description  string
+	DefaultValue interface{}
+}
+
+// ResolveParams are the parameters of a resolver
+type ResolveParams struct {
+	// Context is the context given to Do
+	Context interface{}
+	// Source is the value of the object the field is resolved for, nil for
+	// the fields of the query
+	Source interface{}
+	// Args are the arguments given to the field, with their default values
+	Args map[string]interface{}
+}
+
+// ResolveFunc resolves the value of a field
+type ResolveFunc func(p ResolveParams) (interface{}, error)
+
+func toInt(v interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		if f := rv.Float(); f == math.Trunc(f) {
+			return int64(f), nil
+		}
+	}
+	return nil, fmt.Errorf("Int cannot represent value %v", v)
+}
+
+// Built-in scalars
+var (
+	Int = &Scalar{
+		Name:      "Int",
+		Serialize: toInt,
+		ParseValue: func(v interface{}) (interface{}, error) {
+			i, err := toInt(v)
+			if err != nil {
+				return nil, err
+			}
+			return int(i.(int64)), nil
+		},
+	}
+	Float = &Scalar{
+		Name: "Float",
+		Serialize: func(v interface{}) (interface{}, error) {
+			rv := reflect.ValueOf(v)
+			switch rv.Kind() {
+			case reflect.Float32, reflect.Float64:
+				return rv.Float(), nil
+			}
+			i, err := toInt(v)
+			if err != nil {
+				return nil, fmt.Errorf("Float cannot represent value %v", v)
+			}
+			return float64(i.(int64)), nil
+		},
+	}
+	String = &Scalar{
+		Name: "String",
+		Serialize: func(v interface{}) (interface{}, error) {
+			rv := reflect.ValueOf(v)
+			if rv.Kind() != reflect.String {
+				return nil, fmt.Errorf("String cannot represent value %v", v)
+			}
+			return rv.String(), nil
+		},
+	}
+	Boolean = &Scalar{
+		Name: "Boolean",
+		Serialize: func(v interface{}) (interface{}, error) {
+			rv := reflect.ValueOf(v)
+			if rv.Kind() != reflect.Bool {
+				return nil, fmt.Errorf("Boolean cannot represent value %v", v)
+			}
+			return rv.Bool(), nil
+		},
+	}
+	ID = &Scalar{
+		Name: "ID",
+		Serialize: func(v interface{}) (interface{}, error) {
+			if i, err := toInt(v); err == nil {
+				return strconv.FormatInt(i.(int64), 10), nil
+			}
+			return String.Serialize(v)
+		},
+	}
+)
+
+func init() {
+	// the scalars other than Int take their arguments as they are serialized
+	Float.ParseValue = Float.Serialize
+	String.ParseValue = String.Serialize
+	Boolean.ParseValue = Boolean.Serialize
+	ID.ParseValue = ID.Serialize
+}
+
+// Schema is the types of a GraphQL API whose root is the query object
+type Schema struct {
+	Query *Object
+	types map[string]Type
+}
+
+// NewSchema returns the schema of the types reachable from the query object
+func NewSchema(query *Object) (*Schema, error) {
+	s := &Schema{
+		Query: query,
+		types: map[string]Type{},
+	}
+	for _, t := range []*Scalar{Int, Float, String, Boolean, ID} {
+		s.types[t.Name] = t
+	}
+	if err := s.addType(query); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Schema) addType(t Type) error {
+	switch t := t.(type) {
+	case *List:
+		return s.addType(t.OfType)
+	case *NonNull:
+		return s.addType(t.OfType)
+	case *Scalar, *Object:
+	default:
+		return fmt.Errorf("unknown type %v", t)
+	}
+
+	name := t.String()
+	if prev, ok := s.types[name]; ok {
+		if prev != t {
+			return fmt.Errorf("there are several types named %s", name)
+		}
+		return nil
+	}
+	s.types[name] = t
+
+	if obj, ok := t.(*Object); ok {
+		for name, f := range obj.Fields {
+			if f.Type == nil {
+				return fmt.Errorf("field %s of %s has no type", name, obj.Name)
+			}
+			if err := s.addType(f.Type); err != nil {
+				return err
+			}
+			for _, arg := range f.Args {
+				if err := s.addType(arg.Type); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// String returns the schema in the schema definition language
+func (s *Schema) String() string {
+	names := make([]string, 0, len(s.types))
+	for name := range s.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	writeDescription := func(indent, description string) {
+		if len(description) > 0 {
+			b.WriteString(indent + strconv.Quote(description) + "\n")
+		}
+	}
+	for _, name := range names {
+		if t, ok := s.types[name].(*Scalar); ok {
+			switch t {
+			case Int, Float, String, Boolean, ID:
+				continue
+			}
+			writeDescription("", t.Description)
+			fmt.Fprintf(&b, "scalar %s\n\n", t.Name)
+		}
+	}
+
+	objects := []*Object{s.Query}
+	for _, name := range names {
+		if t, ok := s.types[name].(*Object); ok && t != s.Query {
+			objects = append(objects, t)
+		}
+	}
+	for i, obj := range objects {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		writeDescription("", obj.Description)
+		fmt.Fprintf(&b, "type %s {\n", obj.Name)
+		for _, name := range sortedFieldNames(obj.Fields) {
+			f := obj.Fields[name]
+			writeDescription("  ", f.Description)
+			b.WriteString("  " + name)
+			if len(f.Args) > 0 {
+				argNames := make([]string, 0, len(f.Args))
+				for argName := range f.Args {
+					argNames = append(argNames, argName)
+				}
+				sort.Strings(argNames)
+				args := make([]string, len(argNames))
+				for j, argName := range argNames {
+					arg := f.Args[argName]
+					args[j] = argName + ": " + arg.Type.String()
+					if arg.DefaultValue != nil {
+						if str, ok := arg.DefaultValue.(string); ok {
+							args[j] += " = " + strconv.Quote(str)
+						} else {
+							args[j] += fmt.Sprintf(" = %v", arg.DefaultValue)
+						}
+					}
+				}
+				b.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			b.WriteString(": " + f.Type.String() + "\n")
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+func sortedFieldNames(fields Fields) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PropertyResolver returns a resolver taking the property name of the source,
+// which is either a map or a struct whose field has the name or the JSON name
+// name, or its snake case form, e.g. html_url for htmlUrl
+func PropertyResolver(name string) ResolveFunc {
+	return func(p ResolveParams) (interface{}, error) {
+		return resolveProperty(p.Source, name)
+	}
+}
+
+func resolveProperty(source interface{}, name string) (interface{}, error) {
+	if m, ok := source.(map[string]interface{}); ok {
+		return m[name], nil
+	}
+	v := reflect.ValueOf(source)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Struct {
+		if f, ok := findProperty(v, name, toSnakeCase(name)); ok && f.CanInterface() {
+			return f.Interface(), nil
+		}
+	}
+	return nil, fmt.Errorf("%T has no property %s", source, name)
+}
+
+// findProperty looks for the field of the struct v named name, or snakeName
+// in JSON, including the fields of its embedded structs
+func findProperty(v reflect.Value, name, snakeName string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if len(sf.PkgPath) > 0 {
+			continue
+		}
+		jsonName := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if jsonName == name || jsonName == snakeName || strings.EqualFold(sf.Name, name) {
+			return v.Field(i), true
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).Anonymous {
+			continue
+		}
+		f := v.Field(i)
+		if f.Kind() == reflect.Ptr {
+			if f.IsNil() {
+				continue
+			}
+			f = f.Elem()
+		}
+		if f.Kind() == reflect.Struct {
+			if found, ok := findProperty(f, name, snakeName); ok {
+				return found, true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func toSnakeCase(name string) string {
+	var b strings.Builder
+	for i, c := range name {
+		if c >= 'A' && c <= 'Z' {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			c += 'a' - 'A'
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,84 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package elasticsearch
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Bulk(t *testing.T) {
+	var response string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_bulk", r.URL.Path)
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL + "/")
+
+	response = `{"errors":false,"items":[]}`
+	assert.NoError(t, client.Bulk([]byte("{}\n")))
+
+	// deleting a document which is not indexed is not an error
+	response = `{"errors":true,"items":[{"delete":{"_id":"1","status":404}}]}`
+	assert.NoError(t, client.Bulk([]byte("{}\n")))
+
+	response = `{"errors":true,"items":[{"index":{"_id":"2","status":400,"error":{"type":"mapper_parsing_exception"}}}]}`
+	assert.EqualError(t, client.Bulk([]byte("{}\n")), `elasticsearch bulk index of 2: {"type":"mapper_parsing_exception"}`)
+}
+
+func TestClient_Search(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/exists/_search":
+			w.Write([]byte(`{"hits":{"total":{"value":1},"hits":[{}]}}`))
+		case "/broken/_search":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := NewClient(server.URL)
+
+	var resp struct {
+		Hits struct {
+			Total json.RawMessage `json:"total"`
+		} `json:"hits"`
+	}
+	found, err := client.Search("exists", map[string]interface{}{}, &resp)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, `{"value":1}`, string(resp.Hits.Total))
+
+	found, err = client.Search("missing", map[string]interface{}{}, &resp)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	_, err = client.Search("broken", map[string]interface{}{}, &resp)
+	assert.Error(t, err)
+}
+
+func TestParseTotal(t *testing.T) {
+	total, err := ParseTotal(json.RawMessage(`{"value":3,"relation":"eq"}`), 1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, total)
+
+	total, err = ParseTotal(json.RawMessage(`5`), 1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, total)
+
+	total, err = ParseTotal(nil, 2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+
+	_, err = ParseTotal(json.RawMessage(`"many"`), 1)
+	assert.Error(t, err)
+}
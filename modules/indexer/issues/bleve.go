@@ -56,6 +56,14 @@ func newMatchPhraseQuery(matchPhrase, field, analyzer string) *query.MatchPhrase
 	return q
 }
 
+func newMatchQuery(match, field, analyzer string, boost float64) *query.MatchQuery {
+	q := bleve.NewMatchQuery(match)
+	q.FieldVal = field
+	q.Analyzer = analyzer
+	q.SetBoost(boost)
+	return q
+}
+
 const unicodeNormalizeName = "unicodeNormalize"
 
 func addUnicodeNormalizeTokenFilter(m *mapping.IndexMappingImpl) error {
@@ -248,3 +256,37 @@ func (b *BleveIndexer) Search(keyword string, repoID int64, limit, start int) (*
 	}
 	return &ret, nil
 }
+
+// SearchSimilar searches for issues which are similar to the given title.
+// Returns the matching issue IDs ordered by their score
+func (b *BleveIndexer) SearchSimilar(title string, repoID int64, limit int) (*SearchResult, error) {
+	indexerQuery := bleve.NewConjunctionQuery(
+		numericEqualityQuery(repoID, "RepoID"),
+		bleve.NewDisjunctionQuery(
+			newMatchQuery(title, "Title", issueIndexerAnalyzer, 2),
+			newMatchQuery(title, "Content", issueIndexerAnalyzer, 1),
+		))
+	search := bleve.NewSearchRequestOptions(indexerQuery, limit, 0, false)
+
+	result, err := b.indexer.Search(search)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret = SearchResult{
+		Total: int64(result.Total),
+		Hits:  make([]Match, 0, len(result.Hits)),
+	}
+	for _, hit := range result.Hits {
+		id, err := idOfIndexerID(hit.ID)
+		if err != nil {
+			return nil, err
+		}
+		ret.Hits = append(ret.Hits, Match{
+			ID:     id,
+			RepoID: repoID,
+			Score:  hit.Score,
+		})
+	}
+	return &ret, nil
+}
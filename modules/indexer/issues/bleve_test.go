@@ -85,4 +85,31 @@ func TestBleveIndexAndSearch(t *testing.T) {
 		}
 		assert.EqualValues(t, kw.IDs, ids)
 	}
+
+	for _, similar := range []struct {
+		Title string
+		IDs   []int64
+	}{
+		{
+			Title: "Support Chinese in issue search",
+			IDs:   []int64{1, 2},
+		},
+		{
+			Title: "Make CJK optional",
+			IDs:   []int64{2},
+		},
+		{
+			Title: "help wanted",
+			IDs:   []int64{},
+		},
+	} {
+		res, err := indexer.SearchSimilar(similar.Title, 2, 10)
+		assert.NoError(t, err)
+
+		var ids = make([]int64, 0, len(res.Hits))
+		for _, hit := range res.Hits {
+			ids = append(ids, hit.ID)
+		}
+		assert.EqualValues(t, similar.IDs, ids)
+	}
 }
@@ -43,3 +43,22 @@ func (db *DBIndexer) Search(kw string, repoID int64, limit, start int) (*SearchR
 	}
 	return &result, nil
 }
+
+// SearchSimilar searches for issues whose title contains any word of the given title
+func (db *DBIndexer) SearchSimilar(title string, repoID int64, limit int) (*SearchResult, error) {
+	ids, err := models.SearchIssueIDsByTitleWords(title, repoID, limit)
+	if err != nil {
+		return nil, err
+	}
+	var result = SearchResult{
+		Total: int64(len(ids)),
+		Hits:  make([]Match, 0, len(ids)),
+	}
+	for _, id := range ids {
+		result.Hits = append(result.Hits, Match{
+			ID:     id,
+			RepoID: repoID,
+		})
+	}
+	return &result, nil
+}
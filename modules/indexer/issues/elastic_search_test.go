@@ -0,0 +1,76 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issues
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestElasticSearchIndexer(t *testing.T) {
+	var (
+		created  bool
+		bulkBody string
+		query    map[string]interface{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		switch {
+		case r.Method == "HEAD" && r.URL.Path == "/gitea_issues":
+			if !created {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case r.Method == "PUT" && r.URL.Path == "/gitea_issues":
+			created = true
+			w.Write([]byte(`{"acknowledged":true}`))
+		case r.Method == "POST" && r.URL.Path == "/_bulk":
+			assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+			bulkBody = string(body)
+			w.Write([]byte(`{"errors":true,"items":[{"delete":{"_id":"3","status":404}}]}`))
+		case r.Method == "POST" && r.URL.Path == "/gitea_issues/_search":
+			assert.NoError(t, json.Unmarshal(body, &query))
+			w.Write([]byte(`{"hits":{"total":{"value":1},"hits":[{"_score":1.5,"_source":{"id":2,"repo_id":1},"highlight":{"content":["<b>\u0002issue2\u0003</b>"]}}]}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	indexer := NewElasticSearchIndexer(server.URL+"/", "gitea_issues")
+	exist, err := indexer.Init()
+	assert.NoError(t, err)
+	assert.False(t, exist)
+	exist, err = indexer.Init()
+	assert.NoError(t, err)
+	assert.True(t, exist)
+
+	assert.NoError(t, indexer.Index([]*IndexerData{
+		{ID: 2, RepoID: 1, Title: "issue2", Content: "content", Comments: []string{"comment"}},
+	}))
+	assert.Equal(t, `{"index":{"_id":"2","_index":"gitea_issues"}}`+"\n"+
+		`{"id":2,"repo_id":1,"title":"issue2","content":"content","comments":["comment"]}`+"\n", bulkBody)
+
+	assert.NoError(t, indexer.Delete(3))
+	assert.True(t, strings.HasPrefix(bulkBody, `{"delete":{"_id":"3","_index":"gitea_issues"}}`))
+
+	res, err := indexer.Search("issue2", 1, 10, 0)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, res.Total)
+	assert.EqualValues(t, []Match{{
+		ID:         2,
+		RepoID:     1,
+		Score:      1.5,
+		Highlights: map[string][]string{"content": {"<b>\x02issue2\x03</b>"}},
+	}}, res.Hits)
+	assert.EqualValues(t, "&lt;b&gt;<em>issue2</em>&lt;/b&gt;", res.Hits[0].HighlightedFragment("title", "content"))
+	assert.EqualValues(t, 10, query["size"])
+	assert.Contains(t, query, "highlight")
+}
@@ -6,6 +6,10 @@ package issues
 
 import (
 	"fmt"
+	"html"
+	"html/template"
+	"os"
+	"strings"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/log"
@@ -24,11 +28,35 @@ type IndexerData struct {
 	IDs      []int64
 }
 
+// the matched keywords are surrounded by these tags in the highlighted
+// fragments of a match, they are control characters which are unlikely to be
+// found in issues
+const (
+	highlightPreTag  = "\x02"
+	highlightPostTag = "\x03"
+)
+
 // Match represents on search result
 type Match struct {
-	ID     int64   `json:"id"`
-	RepoID int64   `json:"repo_id"`
-	Score  float64 `json:"score"`
+	ID         int64               `json:"id"`
+	RepoID     int64               `json:"repo_id"`
+	Score      float64             `json:"score"`
+	Highlights map[string][]string `json:"highlights,omitempty"`
+}
+
+// HighlightedFragment returns the first highlighted fragment of the given
+// fields as HTML with the matched keywords emphasized, the fields are tried in
+// order. It is empty if the indexer does not support highlighting.
+func (m *Match) HighlightedFragment(fields ...string) template.HTML {
+	for _, field := range fields {
+		if fragments := m.Highlights[field]; len(fragments) > 0 {
+			fragment := html.EscapeString(fragments[0])
+			fragment = strings.Replace(fragment, highlightPreTag, "<em>", -1)
+			fragment = strings.Replace(fragment, highlightPostTag, "</em>", -1)
+			return template.HTML(fragment)
+		}
+	}
+	return ""
 }
 
 // SearchResult represents search results
@@ -43,6 +71,7 @@ type Indexer interface {
 	Index(issue []*IndexerData) error
 	Delete(ids ...int64) error
 	Search(kw string, repoID int64, limit, start int) (*SearchResult, error)
+	SearchSimilar(title string, repoID int64, limit int) (*SearchResult, error)
 }
 
 var (
@@ -64,6 +93,13 @@ func InitIssueIndexer(syncReindex bool) error {
 			return err
 		}
 		populate = !exist
+	case "elasticsearch":
+		issueIndexer = NewElasticSearchIndexer(setting.Indexer.IssueConnStr, setting.Indexer.IssueIndexerName)
+		exist, err := issueIndexer.Init()
+		if err != nil {
+			return err
+		}
+		populate = !exist
 	case "db":
 		issueIndexer = &DBIndexer{}
 		dummyQueue = true
@@ -119,6 +155,63 @@ func InitIssueIndexer(syncReindex bool) error {
 	return nil
 }
 
+// ReindexIssues drops the index of the configured issue indexer and indexes all
+// issues again. It does not use the indexer queue, so it must not be run while
+// the issue indexer is used by a running Gitea instance.
+func ReindexIssues() error {
+	switch setting.Indexer.IssueType {
+	case "bleve":
+		if err := os.RemoveAll(setting.Indexer.IssuePath); err != nil {
+			return err
+		}
+		issueIndexer = NewBleveIndexer(setting.Indexer.IssuePath)
+	case "elasticsearch":
+		indexer := NewElasticSearchIndexer(setting.Indexer.IssueConnStr, setting.Indexer.IssueIndexerName)
+		if err := indexer.Drop(); err != nil {
+			return err
+		}
+		issueIndexer = indexer
+	case "db":
+		return nil
+	default:
+		return fmt.Errorf("unknow issue indexer type: %s", setting.Indexer.IssueType)
+	}
+	if _, err := issueIndexer.Init(); err != nil {
+		return err
+	}
+
+	var batch = make([]*IndexerData, 0, setting.Indexer.IssueQueueBatchNumber)
+	issueIndexerQueue = &syncQueue{indexer: issueIndexer, batch: &batch}
+	populateIssueIndexer()
+	return issueIndexer.Index(batch)
+}
+
+// syncQueue indexes the pushed data in batches directly, it is only used to
+// reindex all issues
+type syncQueue struct {
+	indexer Indexer
+	batch   *[]*IndexerData
+}
+
+// Run does nothing, the data is indexed while it is pushed
+func (q *syncQueue) Run() error {
+	return nil
+}
+
+// Push adds the data to the current batch and indexes the batch once it is full
+func (q *syncQueue) Push(data *IndexerData) error {
+	*q.batch = append(*q.batch, data)
+	if len(*q.batch) < setting.Indexer.IssueQueueBatchNumber {
+		return nil
+	}
+	err := q.indexer.Index(*q.batch)
+	*q.batch = (*q.batch)[:0]
+	if err != nil {
+		log.Error("Index: %v", err)
+	}
+	return err
+}
+
 // populateIssueIndexer populate the issue indexer with issue data
 func populateIssueIndexer() {
 	for page := 1; ; page++ {
@@ -175,6 +268,14 @@ func UpdateIssueIndexer(issue *models.Issue) {
 	})
 }
 
+// DeleteIssueIndexer deletes an issue from the indexer
+func DeleteIssueIndexer(issue *models.Issue) {
+	_ = issueIndexerQueue.Push(&IndexerData{
+		IDs:      []int64{issue.ID},
+		IsDelete: true,
+	})
+}
+
 // DeleteRepoIssueIndexer deletes repo's all issues indexes
 func DeleteRepoIssueIndexer(repo *models.Repository) {
 	var ids []int64
@@ -194,10 +295,22 @@ func DeleteRepoIssueIndexer(repo *models.Repository) {
 	})
 }
 
+// SearchIssues search issues by keywords and repo id, the matches may contain
+// highlighted fragments if the indexer supports them
+func SearchIssues(repoID int64, keyword string, limit, start int) (*SearchResult, error) {
+	return issueIndexer.Search(keyword, repoID, limit, start)
+}
+
+// SearchSimilarIssues search issues which are similar to the given title, the
+// most similar issues come first
+func SearchSimilarIssues(repoID int64, title string, limit int) (*SearchResult, error) {
+	return issueIndexer.SearchSimilar(title, repoID, limit)
+}
+
 // SearchIssuesByKeyword search issue ids by keywords and repo id
 func SearchIssuesByKeyword(repoID int64, keyword string) ([]int64, error) {
 	var issueIDs []int64
-	res, err := issueIndexer.Search(keyword, repoID, 1000, 0)
+	res, err := SearchIssues(repoID, keyword, 1000, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -9,24 +9,26 @@ import (
 
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
-
-	"github.com/blevesearch/bleve"
-	"github.com/blevesearch/bleve/analysis/analyzer/custom"
-	"github.com/blevesearch/bleve/analysis/token/lowercase"
-	"github.com/blevesearch/bleve/analysis/tokenizer/unicode"
-	"github.com/blevesearch/bleve/search/query"
-	"github.com/ethantkoenig/rupture"
 )
 
-const (
-	repoIndexerAnalyzer = "repoIndexerAnalyzer"
-	repoIndexerDocType  = "repoIndexerDocType"
-
-	repoIndexerLatestVersion = 4
-)
+// RepoIndexer represents a search engine storing the contents of the
+// default branches of the repositories
+type RepoIndexer interface {
+	// Init initializes the indexer, it returns true if the index already
+	// exists
+	Init() (bool, error)
+	// Index applies the updates to the index
+	Index(updates []RepoIndexerUpdate) error
+	// DeleteRepo deletes all the files of the repository from the index
+	DeleteRepo(repoID int64) error
+	// Search returns the files of the repositories, all of them if repoIDs is
+	// empty, containing the keyword and written in the language if it is not
+	// empty, together with the number of matching files per language.
+	Search(repoIDs []int64, keyword, language string, page, pageSize int) (int64, []*RepoSearchResult, []*SearchResultLanguages, error)
+}
 
 // repoIndexer (thread-safe) index for repository contents
-var repoIndexer bleve.Index
+var repoIndexer RepoIndexer
 
 // RepoIndexerOp type of operation to perform on repo indexer
 type RepoIndexerOp int
@@ -41,8 +43,9 @@ const (
 
 // RepoIndexerData data stored in the repo indexer
 type RepoIndexerData struct {
-	RepoID  int64
-	Content string
+	RepoID   int64
+	Content  string
+	Language string
 }
 
 // Type returns the document type, for bleve's mapping.Classifier interface.
@@ -57,75 +60,29 @@ type RepoIndexerUpdate struct {
 	Data     *RepoIndexerData
 }
 
-// AddToFlushingBatch adds the update to the given flushing batch.
-func (update RepoIndexerUpdate) AddToFlushingBatch(batch rupture.FlushingBatch) error {
-	id := filenameIndexerID(update.Data.RepoID, update.Filepath)
-	switch update.Op {
-	case RepoIndexerOpUpdate:
-		return batch.Index(id, update.Data)
-	case RepoIndexerOpDelete:
-		return batch.Delete(id)
+// InitRepoIndexer initialize repo indexer
+func InitRepoIndexer(populateIndexer func() error) {
+	switch setting.Indexer.RepoType {
+	case "bleve":
+		repoIndexer = NewBleveRepoIndexer(setting.Indexer.RepoPath)
+	case "elasticsearch":
+		repoIndexer = NewElasticSearchRepoIndexer(setting.Indexer.RepoConnStr, setting.Indexer.RepoIndexerName)
 	default:
-		log.Error("Unrecognized repo indexer op: %d", update.Op)
+		log.Fatal("Unknown repo indexer type: %s", setting.Indexer.RepoType)
 	}
-	return nil
-}
 
-// InitRepoIndexer initialize repo indexer
-func InitRepoIndexer(populateIndexer func() error) {
-	var err error
-	repoIndexer, err = openIndexer(setting.Indexer.RepoPath, repoIndexerLatestVersion)
+	exist, err := repoIndexer.Init()
 	if err != nil {
 		log.Fatal("InitRepoIndexer: %v", err)
 	}
-	if repoIndexer != nil {
+	if exist {
 		return
 	}
-
-	if err = createRepoIndexer(setting.Indexer.RepoPath, repoIndexerLatestVersion); err != nil {
-		log.Fatal("CreateRepoIndexer: %v", err)
-	}
 	if err = populateIndexer(); err != nil {
 		log.Fatal("PopulateRepoIndex: %v", err)
 	}
 }
 
-// createRepoIndexer create a repo indexer if one does not already exist
-func createRepoIndexer(path string, latestVersion int) error {
-	var err error
-	docMapping := bleve.NewDocumentMapping()
-	numericFieldMapping := bleve.NewNumericFieldMapping()
-	numericFieldMapping.IncludeInAll = false
-	docMapping.AddFieldMappingsAt("RepoID", numericFieldMapping)
-
-	textFieldMapping := bleve.NewTextFieldMapping()
-	textFieldMapping.IncludeInAll = false
-	docMapping.AddFieldMappingsAt("Content", textFieldMapping)
-
-	mapping := bleve.NewIndexMapping()
-	if err = addUnicodeNormalizeTokenFilter(mapping); err != nil {
-		return err
-	} else if err = mapping.AddCustomAnalyzer(repoIndexerAnalyzer, map[string]interface{}{
-		"type":          custom.Name,
-		"char_filters":  []string{},
-		"tokenizer":     unicode.Name,
-		"token_filters": []string{unicodeNormalizeName, lowercase.Name},
-	}); err != nil {
-		return err
-	}
-	mapping.DefaultAnalyzer = repoIndexerAnalyzer
-	mapping.AddDocumentMapping(repoIndexerDocType, docMapping)
-	mapping.AddDocumentMapping("_all", bleve.NewDocumentDisabledMapping())
-
-	repoIndexer, err = bleve.New(path, mapping)
-	if err != nil {
-		return err
-	}
-	return rupture.WriteIndexMetadata(path, &rupture.IndexMetadata{
-		Version: latestVersion,
-	})
-}
-
 func filenameIndexerID(repoID int64, filename string) string {
 	return indexerID(repoID) + "_" + filename
 }
@@ -138,26 +95,39 @@ func filenameOfIndexerID(indexerID string) string {
 	return indexerID[index+1:]
 }
 
-// RepoIndexerBatch batch to add updates to
-func RepoIndexerBatch() rupture.FlushingBatch {
-	return rupture.NewFlushingBatch(repoIndexer, maxBatchSize)
+// RepoIndexerBatch collects the updates of the repo indexer, which are sent
+// to the indexer when the batch is full or flushed.
+type RepoIndexerBatch struct {
+	updates []RepoIndexerUpdate
 }
 
-// DeleteRepoFromIndexer delete all of a repo's files from indexer
-func DeleteRepoFromIndexer(repoID int64) error {
-	query := numericEqualityQuery(repoID, "RepoID")
-	searchRequest := bleve.NewSearchRequestOptions(query, 2147483647, 0, false)
-	result, err := repoIndexer.Search(searchRequest)
-	if err != nil {
-		return err
+// NewRepoIndexerBatch returns a new batch to add updates to
+func NewRepoIndexerBatch() *RepoIndexerBatch {
+	return &RepoIndexerBatch{updates: make([]RepoIndexerUpdate, 0, maxBatchSize)}
+}
+
+// Add adds the update to the batch, the batch is flushed if it is full
+func (b *RepoIndexerBatch) Add(update RepoIndexerUpdate) error {
+	b.updates = append(b.updates, update)
+	if len(b.updates) >= maxBatchSize {
+		return b.Flush()
 	}
-	batch := RepoIndexerBatch()
-	for _, hit := range result.Hits {
-		if err = batch.Delete(hit.ID); err != nil {
-			return err
-		}
+	return nil
+}
+
+// Flush sends the updates of the batch to the indexer
+func (b *RepoIndexerBatch) Flush() error {
+	if len(b.updates) == 0 {
+		return nil
 	}
-	return batch.Flush()
+	err := repoIndexer.Index(b.updates)
+	b.updates = b.updates[:0]
+	return err
+}
+
+// DeleteRepoFromIndexer delete all of a repo's files from indexer
+func DeleteRepoFromIndexer(repoID int64) error {
+	return repoIndexer.DeleteRepo(repoID)
 }
 
 // RepoSearchResult result of performing a search in a repo
@@ -167,61 +137,18 @@ type RepoSearchResult struct {
 	EndIndex   int
 	Filename   string
 	Content    string
+	Language   string
 }
 
-// SearchRepoByKeyword searches for files in the specified repo.
-// Returns the matching file-paths
-func SearchRepoByKeyword(repoIDs []int64, keyword string, page, pageSize int) (int64, []*RepoSearchResult, error) {
-	phraseQuery := bleve.NewMatchPhraseQuery(keyword)
-	phraseQuery.FieldVal = "Content"
-	phraseQuery.Analyzer = repoIndexerAnalyzer
-
-	var indexerQuery query.Query
-	if len(repoIDs) > 0 {
-		var repoQueries = make([]query.Query, 0, len(repoIDs))
-		for _, repoID := range repoIDs {
-			repoQueries = append(repoQueries, numericEqualityQuery(repoID, "RepoID"))
-		}
-
-		indexerQuery = bleve.NewConjunctionQuery(
-			bleve.NewDisjunctionQuery(repoQueries...),
-			phraseQuery,
-		)
-	} else {
-		indexerQuery = phraseQuery
-	}
-
-	from := (page - 1) * pageSize
-	searchRequest := bleve.NewSearchRequestOptions(indexerQuery, pageSize, from, false)
-	searchRequest.Fields = []string{"Content", "RepoID"}
-	searchRequest.IncludeLocations = true
-
-	result, err := repoIndexer.Search(searchRequest)
-	if err != nil {
-		return 0, nil, err
-	}
+// SearchResultLanguages the number of files of a language matching a search
+type SearchResultLanguages struct {
+	Language string
+	Count    int
+}
 
-	searchResults := make([]*RepoSearchResult, len(result.Hits))
-	for i, hit := range result.Hits {
-		var startIndex, endIndex int = -1, -1
-		for _, locations := range hit.Locations["Content"] {
-			location := locations[0]
-			locationStart := int(location.Start)
-			locationEnd := int(location.End)
-			if startIndex < 0 || locationStart < startIndex {
-				startIndex = locationStart
-			}
-			if endIndex < 0 || locationEnd > endIndex {
-				endIndex = locationEnd
-			}
-		}
-		searchResults[i] = &RepoSearchResult{
-			RepoID:     int64(hit.Fields["RepoID"].(float64)),
-			StartIndex: startIndex,
-			EndIndex:   endIndex,
-			Filename:   filenameOfIndexerID(hit.ID),
-			Content:    hit.Fields["Content"].(string),
-		}
-	}
-	return int64(result.Total), searchResults, nil
+// SearchRepoByKeyword searches for files in the specified repo, only the
+// files written in the language are returned if it is not empty.
+// Returns the matching file-paths and the number of matches per language
+func SearchRepoByKeyword(repoIDs []int64, keyword, language string, page, pageSize int) (int64, []*RepoSearchResult, []*SearchResultLanguages, error) {
+	return repoIndexer.Search(repoIDs, keyword, language, page, pageSize)
 }
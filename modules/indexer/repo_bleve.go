@@ -0,0 +1,258 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package indexer
+
+import (
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/analysis"
+	"github.com/blevesearch/bleve/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/registry"
+	"github.com/blevesearch/bleve/search/query"
+	"github.com/ethantkoenig/rupture"
+)
+
+const (
+	repoIndexerAnalyzer         = "repoIndexerAnalyzer"
+	repoIndexerLanguageAnalyzer = "repoIndexerLanguageAnalyzer"
+	repoIndexerDocType          = "repoIndexerDocType"
+
+	singleTokenTokenizer = "singleTokenTokenizer"
+
+	repoIndexerLatestVersion = 5
+)
+
+// singleTokenTokenizer emits its whole input as one token, the languages of
+// the files are indexed as such so they can be filtered and counted.
+type singleTokenTokenizerImpl struct{}
+
+func (singleTokenTokenizerImpl) Tokenize(input []byte) analysis.TokenStream {
+	if len(input) == 0 {
+		return analysis.TokenStream{}
+	}
+	return analysis.TokenStream{
+		&analysis.Token{
+			Term:     input,
+			Position: 1,
+			Start:    0,
+			End:      len(input),
+			Type:     analysis.AlphaNumeric,
+		},
+	}
+}
+
+func init() {
+	registry.RegisterTokenizer(singleTokenTokenizer, func(config map[string]interface{}, cache *registry.Cache) (analysis.Tokenizer, error) {
+		return singleTokenTokenizerImpl{}, nil
+	})
+}
+
+// BleveRepoIndexer implements RepoIndexer interface using a bleve index
+type BleveRepoIndexer struct {
+	indexDir string
+	indexer  bleve.Index
+}
+
+// NewBleveRepoIndexer creates a new bleve local indexer
+func NewBleveRepoIndexer(indexDir string) *BleveRepoIndexer {
+	return &BleveRepoIndexer{
+		indexDir: indexDir,
+	}
+}
+
+// Init will initialize the indexer, creating the index if it does not exist
+// or has been created by a previous version
+func (b *BleveRepoIndexer) Init() (bool, error) {
+	var err error
+	b.indexer, err = openIndexer(b.indexDir, repoIndexerLatestVersion)
+	if err != nil {
+		return false, err
+	}
+	if b.indexer != nil {
+		return true, nil
+	}
+
+	return false, b.createIndexer()
+}
+
+// createIndexer create a repo indexer if one does not already exist
+func (b *BleveRepoIndexer) createIndexer() error {
+	var err error
+	docMapping := bleve.NewDocumentMapping()
+	numericFieldMapping := bleve.NewNumericFieldMapping()
+	numericFieldMapping.IncludeInAll = false
+	docMapping.AddFieldMappingsAt("RepoID", numericFieldMapping)
+
+	textFieldMapping := bleve.NewTextFieldMapping()
+	textFieldMapping.IncludeInAll = false
+	docMapping.AddFieldMappingsAt("Content", textFieldMapping)
+
+	languageFieldMapping := bleve.NewTextFieldMapping()
+	languageFieldMapping.IncludeInAll = false
+	languageFieldMapping.Analyzer = repoIndexerLanguageAnalyzer
+	docMapping.AddFieldMappingsAt("Language", languageFieldMapping)
+
+	mapping := bleve.NewIndexMapping()
+	if err = addUnicodeNormalizeTokenFilter(mapping); err != nil {
+		return err
+	} else if err = mapping.AddCustomAnalyzer(repoIndexerAnalyzer, map[string]interface{}{
+		"type":          custom.Name,
+		"char_filters":  []string{},
+		"tokenizer":     unicode.Name,
+		"token_filters": []string{unicodeNormalizeName, lowercase.Name},
+	}); err != nil {
+		return err
+	} else if err = mapping.AddCustomAnalyzer(repoIndexerLanguageAnalyzer, map[string]interface{}{
+		"type":          custom.Name,
+		"char_filters":  []string{},
+		"tokenizer":     singleTokenTokenizer,
+		"token_filters": []string{},
+	}); err != nil {
+		return err
+	}
+	mapping.DefaultAnalyzer = repoIndexerAnalyzer
+	mapping.AddDocumentMapping(repoIndexerDocType, docMapping)
+	mapping.AddDocumentMapping("_all", bleve.NewDocumentDisabledMapping())
+
+	b.indexer, err = bleve.New(b.indexDir, mapping)
+	if err != nil {
+		return err
+	}
+	return rupture.WriteIndexMetadata(b.indexDir, &rupture.IndexMetadata{
+		Version: repoIndexerLatestVersion,
+	})
+}
+
+// Index applies the updates to the index
+func (b *BleveRepoIndexer) Index(updates []RepoIndexerUpdate) error {
+	batch := rupture.NewFlushingBatch(b.indexer, maxBatchSize)
+	for _, update := range updates {
+		id := filenameIndexerID(update.Data.RepoID, update.Filepath)
+		var err error
+		switch update.Op {
+		case RepoIndexerOpUpdate:
+			err = batch.Index(id, update.Data)
+		case RepoIndexerOpDelete:
+			err = batch.Delete(id)
+		default:
+			log.Error("Unrecognized repo indexer op: %d", update.Op)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return batch.Flush()
+}
+
+// DeleteRepo deletes all the files of the repository from the index
+func (b *BleveRepoIndexer) DeleteRepo(repoID int64) error {
+	query := numericEqualityQuery(repoID, "RepoID")
+	searchRequest := bleve.NewSearchRequestOptions(query, 2147483647, 0, false)
+	result, err := b.indexer.Search(searchRequest)
+	if err != nil {
+		return err
+	}
+	batch := rupture.NewFlushingBatch(b.indexer, maxBatchSize)
+	for _, hit := range result.Hits {
+		if err = batch.Delete(hit.ID); err != nil {
+			return err
+		}
+	}
+	return batch.Flush()
+}
+
+// Search searches for files in the specified repos.
+// Returns the matching file-paths and the number of matches per language
+func (b *BleveRepoIndexer) Search(repoIDs []int64, keyword, language string, page, pageSize int) (int64, []*RepoSearchResult, []*SearchResultLanguages, error) {
+	phraseQuery := bleve.NewMatchPhraseQuery(keyword)
+	phraseQuery.FieldVal = "Content"
+	phraseQuery.Analyzer = repoIndexerAnalyzer
+
+	var indexerQuery query.Query
+	if len(repoIDs) > 0 {
+		var repoQueries = make([]query.Query, 0, len(repoIDs))
+		for _, repoID := range repoIDs {
+			repoQueries = append(repoQueries, numericEqualityQuery(repoID, "RepoID"))
+		}
+
+		indexerQuery = bleve.NewConjunctionQuery(
+			bleve.NewDisjunctionQuery(repoQueries...),
+			phraseQuery,
+		)
+	} else {
+		indexerQuery = phraseQuery
+	}
+
+	// the languages are counted for all the files matching the keyword, not
+	// only for the ones written in the language searched for
+	facetRequest := bleve.NewFacetRequest("Language", 10)
+	resultQuery := indexerQuery
+	if len(language) > 0 {
+		languageQuery := bleve.NewTermQuery(language)
+		languageQuery.FieldVal = "Language"
+		resultQuery = bleve.NewConjunctionQuery(indexerQuery, languageQuery)
+	}
+
+	from := (page - 1) * pageSize
+	searchRequest := bleve.NewSearchRequestOptions(resultQuery, pageSize, from, false)
+	searchRequest.Fields = []string{"Content", "RepoID", "Language"}
+	searchRequest.IncludeLocations = true
+	if len(language) == 0 {
+		searchRequest.AddFacet("languages", facetRequest)
+	}
+
+	result, err := b.indexer.Search(searchRequest)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	facetResult := result
+	if len(language) > 0 {
+		facetSearchRequest := bleve.NewSearchRequestOptions(indexerQuery, 1, 0, false)
+		facetSearchRequest.AddFacet("languages", facetRequest)
+		if facetResult, err = b.indexer.Search(facetSearchRequest); err != nil {
+			return 0, nil, nil, err
+		}
+	}
+	var languages []*SearchResultLanguages
+	if facet, ok := facetResult.Facets["languages"]; ok {
+		languages = make([]*SearchResultLanguages, 0, len(facet.Terms))
+		for _, term := range facet.Terms {
+			languages = append(languages, &SearchResultLanguages{
+				Language: term.Term,
+				Count:    term.Count,
+			})
+		}
+	}
+
+	searchResults := make([]*RepoSearchResult, len(result.Hits))
+	for i, hit := range result.Hits {
+		var startIndex, endIndex int = -1, -1
+		for _, locations := range hit.Locations["Content"] {
+			location := locations[0]
+			locationStart := int(location.Start)
+			locationEnd := int(location.End)
+			if startIndex < 0 || locationStart < startIndex {
+				startIndex = locationStart
+			}
+			if endIndex < 0 || locationEnd > endIndex {
+				endIndex = locationEnd
+			}
+		}
+		language, _ := hit.Fields["Language"].(string)
+		searchResults[i] = &RepoSearchResult{
+			RepoID:     int64(hit.Fields["RepoID"].(float64)),
+			StartIndex: startIndex,
+			EndIndex:   endIndex,
+			Filename:   filenameOfIndexerID(hit.ID),
+			Content:    hit.Fields["Content"].(string),
+			Language:   language,
+		}
+	}
+	return int64(result.Total), searchResults, languages, nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package indexer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestElasticSearchRepoIndexer(t *testing.T) {
+	var (
+		created  bool
+		bulkBody string
+		query    map[string]interface{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		switch {
+		case r.Method == "HEAD" && r.URL.Path == "/gitea_codes":
+			if !created {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case r.Method == "PUT" && r.URL.Path == "/gitea_codes":
+			created = true
+			w.Write([]byte(`{"acknowledged":true}`))
+		case r.Method == "POST" && r.URL.Path == "/_bulk":
+			bulkBody = string(body)
+			w.Write([]byte(`{"errors":false,"items":[]}`))
+		case r.Method == "POST" && r.URL.Path == "/gitea_codes/_search":
+			assert.NoError(t, json.Unmarshal(body, &query))
+			w.Write([]byte(`{"hits":{"total":{"value":1},"hits":[{"_source":{"repo_id":1,"filename":"main.go","content":"package main\nfunc main() {}\n","language":"Go"},"highlight":{"content":["package main\n\u0002func\u0003 \u0002main\u0003() {}\n"]}}]},` +
+				`"aggregations":{"languages":{"buckets":[{"key":"Go","doc_count":1},{"key":"C","doc_count":3}]}}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	indexer := NewElasticSearchRepoIndexer(server.URL, "gitea_codes")
+	exist, err := indexer.Init()
+	assert.NoError(t, err)
+	assert.False(t, exist)
+	exist, err = indexer.Init()
+	assert.NoError(t, err)
+	assert.True(t, exist)
+
+	assert.NoError(t, indexer.Index([]RepoIndexerUpdate{
+		{Filepath: "main.go", Op: RepoIndexerOpUpdate, Data: &RepoIndexerData{RepoID: 1, Content: "package main", Language: "Go"}},
+		{Filepath: "old.go", Op: RepoIndexerOpDelete, Data: &RepoIndexerData{RepoID: 1}},
+	}))
+	assert.Equal(t, `{"index":{"_id":"1_main.go","_index":"gitea_codes"}}`+"\n"+
+		`{"repo_id":1,"filename":"main.go","content":"package main","language":"Go"}`+"\n"+
+		`{"delete":{"_id":"1_old.go","_index":"gitea_codes"}}`+"\n", bulkBody)
+
+	total, results, languages, err := indexer.Search([]int64{1}, "func main", "Go", 1, 10)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	if assert.Len(t, results, 1) {
+		assert.EqualValues(t, 1, results[0].RepoID)
+		assert.Equal(t, "main.go", results[0].Filename)
+		assert.Equal(t, "Go", results[0].Language)
+		assert.Equal(t, "func main", results[0].Content[results[0].StartIndex:results[0].EndIndex])
+	}
+	assert.Equal(t, []*SearchResultLanguages{{Language: "Go", Count: 1}, {Language: "C", Count: 3}}, languages)
+	assert.Equal(t, map[string]interface{}{"term": map[string]interface{}{"language": "Go"}}, query["post_filter"])
+}
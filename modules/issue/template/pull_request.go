@@ -0,0 +1,112 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package template
+
+import (
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+var (
+	// PullRequestTemplateCandidates are the paths of the default pull request
+	// template, the first existing one is used.
+	PullRequestTemplateCandidates = []string{
+		"PULL_REQUEST_TEMPLATE.md",
+		"pull_request_template.md",
+		".gitea/PULL_REQUEST_TEMPLATE.md",
+		".gitea/pull_request_template.md",
+		".github/PULL_REQUEST_TEMPLATE.md",
+		".github/pull_request_template.md",
+	}
+	// PullRequestTemplateDirCandidates are the directories containing multiple
+	// pull request templates, the first existing one is used.
+	PullRequestTemplateDirCandidates = []string{
+		".gitea/PULL_REQUEST_TEMPLATE",
+		".gitea/pull_request_template",
+		".github/PULL_REQUEST_TEMPLATE",
+		".github/pull_request_template",
+	}
+)
+
+// ListPullRequestTemplates returns the file names of the markdown files in
+// the pull request template directory of the commit.
+func ListPullRequestTemplates(commit *git.Commit) []string {
+	for _, dirname := range PullRequestTemplateDirCandidates {
+		tree, err := commit.SubTree(dirname)
+		if err != nil {
+			continue
+		}
+		entries, err := tree.ListEntries()
+		if err != nil {
+			continue
+		}
+
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			if entry.IsRegular() && strings.HasSuffix(strings.ToLower(entry.Name()), ".md") {
+				names = append(names, entry.Name())
+			}
+		}
+		if len(names) > 0 {
+			sort.Strings(names)
+			return names
+		}
+	}
+	return nil
+}
+
+// GetPullRequestTemplate returns the content of the template with the file
+// name from the pull request template directory of the commit, or of the
+// default pull request template if the name is empty. An empty string is
+// returned if the template does not exist.
+func GetPullRequestTemplate(commit *git.Commit, name string) (string, error) {
+	if len(name) == 0 {
+		for _, filename := range PullRequestTemplateCandidates {
+			if content, found, err := getTemplateContent(commit, filename); err != nil || found {
+				return content, err
+			}
+		}
+		return "", nil
+	}
+
+	// Templates can not be read from outside of the template directory
+	if path.Base(name) != name || !strings.HasSuffix(strings.ToLower(name), ".md") {
+		return "", nil
+	}
+	for _, dirname := range PullRequestTemplateDirCandidates {
+		if content, found, err := getTemplateContent(commit, path.Join(dirname, name)); err != nil || found {
+			return content, err
+		}
+	}
+	return "", nil
+}
+
+func getTemplateContent(commit *git.Commit, filename string) (string, bool, error) {
+	entry, err := commit.GetTreeEntryByPath(filename)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	if !entry.IsRegular() || entry.Blob().Size() >= setting.UI.MaxDisplayFileSize {
+		return "", false, nil
+	}
+	r, err := entry.Blob().DataAsync()
+	if err != nil {
+		return "", false, err
+	}
+	defer r.Close()
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", false, err
+	}
+	return string(content), true, nil
+}
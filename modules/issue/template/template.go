@@ -0,0 +1,345 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package template
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Field types of an issue form
+const (
+	FieldTypeMarkdown   = "markdown"
+	FieldTypeInput      = "input"
+	FieldTypeTextarea   = "textarea"
+	FieldTypeDropdown   = "dropdown"
+	FieldTypeCheckboxes = "checkboxes"
+)
+
+var fieldIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Template represents an issue form defined by a YAML file like
+// .gitea/ISSUE_TEMPLATE/bug.yml
+type Template struct {
+	FileName string   `yaml:"-"`
+	Name     string   `yaml:"name"`
+	About    string   `yaml:"about"`
+	Title    string   `yaml:"title"`
+	Labels   []string `yaml:"labels"`
+	Fields   []*Field `yaml:"body"`
+}
+
+// Field represents a field of an issue form
+type Field struct {
+	Type        string            `yaml:"type"`
+	ID          string            `yaml:"id"`
+	Attributes  *FieldAttributes  `yaml:"attributes"`
+	Validations *FieldValidations `yaml:"validations"`
+}
+
+// FieldAttributes represents the attributes of a field, which attributes are
+// used depends on the type of the field
+type FieldAttributes struct {
+	Label       string         `yaml:"label"`
+	Description string         `yaml:"description"`
+	Placeholder string         `yaml:"placeholder"`
+	Value       string         `yaml:"value"`
+	Render      string         `yaml:"render"`
+	Multiple    bool           `yaml:"multiple"`
+	Options     []*FieldOption `yaml:"options"`
+}
+
+// FieldOption represents an option of a dropdown or checkboxes field
+type FieldOption struct {
+	Label    string `yaml:"label"`
+	Required bool   `yaml:"required"`
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, an option can be a plain string
+func (o *FieldOption) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var label string
+	if err := unmarshal(&label); err == nil {
+		o.Label = label
+		return nil
+	}
+	type plain FieldOption
+	return unmarshal((*plain)(o))
+}
+
+// FieldValidations represents the validations of the value of a field
+type FieldValidations struct {
+	Required bool   `yaml:"required"`
+	Regex    string `yaml:"regex"`
+}
+
+// ErrInvalidTemplate represents an error of an issue form which does not follow the schema
+type ErrInvalidTemplate struct {
+	FileName string
+	Reason   string
+}
+
+// IsErrInvalidTemplate checks if an error is a ErrInvalidTemplate.
+func IsErrInvalidTemplate(err error) bool {
+	_, ok := err.(ErrInvalidTemplate)
+	return ok
+}
+
+func (err ErrInvalidTemplate) Error() string {
+	return fmt.Sprintf("invalid issue form [file: %s]: %s", err.FileName, err.Reason)
+}
+
+// Reasons for a submitted value to be invalid
+const (
+	ValueRequired      = "required"
+	ValueInvalidFormat = "invalid_format"
+	ValueInvalidOption = "invalid_option"
+)
+
+// ErrInvalidValue represents an error of a submitted value of a field
+type ErrInvalidValue struct {
+	Field  *Field
+	Reason string
+}
+
+// IsErrInvalidValue checks if an error is a ErrInvalidValue.
+func IsErrInvalidValue(err error) bool {
+	_, ok := err.(ErrInvalidValue)
+	return ok
+}
+
+func (err ErrInvalidValue) Error() string {
+	return fmt.Sprintf("invalid value of field %s: %s", err.Field.ID, err.Reason)
+}
+
+// Unmarshal parses and validates the issue form of the given file
+func Unmarshal(fileName string, content []byte) (*Template, error) {
+	t := &Template{}
+	if err := yaml.Unmarshal(content, t); err != nil {
+		return nil, ErrInvalidTemplate{FileName: fileName, Reason: err.Error()}
+	}
+	t.FileName = fileName
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// IsTemplateFile returns true if the file name has the extension of an issue form
+func IsTemplateFile(fileName string) bool {
+	return strings.HasSuffix(fileName, ".yml") || strings.HasSuffix(fileName, ".yaml")
+}
+
+func (t *Template) validate() error {
+	invalid := func(format string, args ...interface{}) error {
+		return ErrInvalidTemplate{FileName: t.FileName, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	if len(strings.TrimSpace(t.Name)) == 0 {
+		return invalid("name is required")
+	}
+	if len(t.Fields) == 0 {
+		return invalid("body is required")
+	}
+
+	ids := make(map[string]bool, len(t.Fields))
+	for i, field := range t.Fields {
+		if field.Attributes == nil {
+			field.Attributes = &FieldAttributes{}
+		}
+		if field.Validations == nil {
+			field.Validations = &FieldValidations{}
+		}
+		if len(field.ID) == 0 {
+			field.ID = "field-" + strconv.Itoa(i)
+		} else if !fieldIDPattern.MatchString(field.ID) {
+			return invalid("id of body[%d] must only contain alphanumeric, dash and underscore characters", i)
+		}
+		if ids[field.ID] {
+			return invalid("id of body[%d] is not unique", i)
+		}
+		ids[field.ID] = true
+
+		switch field.Type {
+		case FieldTypeMarkdown:
+			if len(field.Attributes.Value) == 0 {
+				return invalid("body[%d] of type markdown requires a value", i)
+			}
+			continue
+		case FieldTypeInput, FieldTypeTextarea:
+		case FieldTypeDropdown, FieldTypeCheckboxes:
+			if len(field.Attributes.Options) == 0 {
+				return invalid("body[%d] of type %s requires options", i, field.Type)
+			}
+		default:
+			return invalid("body[%d] has an unknown type %q", i, field.Type)
+		}
+
+		if len(strings.TrimSpace(field.Attributes.Label)) == 0 {
+			return invalid("body[%d] requires a label", i)
+		}
+		if len(field.Validations.Regex) > 0 {
+			if field.Type != FieldTypeInput && field.Type != FieldTypeTextarea {
+				return invalid("body[%d] of type %s does not support a regex", i, field.Type)
+			}
+			if _, err := regexp.Compile(field.Validations.Regex); err != nil {
+				return invalid("regex of body[%d] is invalid: %v", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// FormName returns the name of the HTML form element of the field, options of
+// dropdown and checkboxes fields are submitted by their index
+func (f *Field) FormName() string {
+	return "form-field-" + f.ID
+}
+
+// selectedOptions returns the options of a dropdown or checkboxes field which
+// have been selected in the submitted values
+func (f *Field) selectedOptions(values url.Values) ([]int, error) {
+	selected := make([]int, 0, len(f.Attributes.Options))
+	for _, v := range values[f.FormName()] {
+		idx, err := strconv.Atoi(v)
+		if err != nil || idx < 0 || idx >= len(f.Attributes.Options) {
+			return nil, ErrInvalidValue{Field: f, Reason: ValueInvalidOption}
+		}
+		selected = append(selected, idx)
+	}
+	if f.Type == FieldTypeDropdown && !f.Attributes.Multiple && len(selected) > 1 {
+		return nil, ErrInvalidValue{Field: f, Reason: ValueInvalidOption}
+	}
+	return selected, nil
+}
+
+// FieldValue represents a field of an issue form together with the value which
+// is shown on the form
+type FieldValue struct {
+	*Field
+	Value    string
+	Selected map[int]bool
+}
+
+// FieldValues returns the fields of the form together with the submitted
+// values, or with the default values of the fields if values is nil
+func (t *Template) FieldValues(values url.Values) []*FieldValue {
+	fields := make([]*FieldValue, 0, len(t.Fields))
+	for _, field := range t.Fields {
+		fv := &FieldValue{
+			Field:    field,
+			Selected: make(map[int]bool),
+		}
+		switch {
+		case field.Type == FieldTypeMarkdown || values == nil:
+			fv.Value = field.Attributes.Value
+		case field.Type == FieldTypeDropdown || field.Type == FieldTypeCheckboxes:
+			for _, v := range values[field.FormName()] {
+				if idx, err := strconv.Atoi(v); err == nil {
+					fv.Selected[idx] = true
+				}
+			}
+		default:
+			fv.Value = values.Get(field.FormName())
+		}
+		fields = append(fields, fv)
+	}
+	return fields
+}
+
+// ValidateValues checks the submitted values against the validations of the fields
+func (t *Template) ValidateValues(values url.Values) error {
+	for _, field := range t.Fields {
+		switch field.Type {
+		case FieldTypeInput, FieldTypeTextarea:
+			value := strings.TrimSpace(values.Get(field.FormName()))
+			if len(value) == 0 {
+				if field.Validations.Required {
+					return ErrInvalidValue{Field: field, Reason: ValueRequired}
+				}
+				continue
+			}
+			if len(field.Validations.Regex) > 0 && !regexp.MustCompile(field.Validations.Regex).MatchString(value) {
+				return ErrInvalidValue{Field: field, Reason: ValueInvalidFormat}
+			}
+		case FieldTypeDropdown:
+			selected, err := field.selectedOptions(values)
+			if err != nil {
+				return err
+			}
+			if len(selected) == 0 && field.Validations.Required {
+				return ErrInvalidValue{Field: field, Reason: ValueRequired}
+			}
+		case FieldTypeCheckboxes:
+			selected, err := field.selectedOptions(values)
+			if err != nil {
+				return err
+			}
+			checked := make(map[int]bool, len(selected))
+			for _, idx := range selected {
+				checked[idx] = true
+			}
+			for i, option := range field.Attributes.Options {
+				if option.Required && !checked[i] {
+					return ErrInvalidValue{Field: field, Reason: ValueRequired}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// RenderToMarkdown converts the submitted values into the markdown content of
+// the issue, the values must have been validated before
+func (t *Template) RenderToMarkdown(values url.Values) string {
+	var builder strings.Builder
+	for _, field := range t.Fields {
+		var value string
+		switch field.Type {
+		case FieldTypeMarkdown:
+			// Markdown fields are only shown on the form
+			continue
+		case FieldTypeInput:
+			value = strings.TrimSpace(values.Get(field.FormName()))
+		case FieldTypeTextarea:
+			value = strings.TrimSpace(values.Get(field.FormName()))
+			if len(value) > 0 && len(field.Attributes.Render) > 0 {
+				value = fmt.Sprintf("```%s\n%s\n```", field.Attributes.Render, value)
+			}
+		case FieldTypeDropdown:
+			selected, _ := field.selectedOptions(values)
+			labels := make([]string, 0, len(selected))
+			for _, idx := range selected {
+				labels = append(labels, field.Attributes.Options[idx].Label)
+			}
+			value = strings.Join(labels, ", ")
+		case FieldTypeCheckboxes:
+			selected, _ := field.selectedOptions(values)
+			checked := make(map[int]bool, len(selected))
+			for _, idx := range selected {
+				checked[idx] = true
+			}
+			lines := make([]string, 0, len(field.Attributes.Options))
+			for i, option := range field.Attributes.Options {
+				mark := " "
+				if checked[i] {
+					mark = "x"
+				}
+				lines = append(lines, fmt.Sprintf("- [%s] %s", mark, option.Label))
+			}
+			value = strings.Join(lines, "\n")
+		}
+
+		if len(value) == 0 {
+			value = "_No response_"
+		}
+		fmt.Fprintf(&builder, "### %s\n\n%s\n\n", field.Attributes.Label, value)
+	}
+	return strings.TrimSpace(builder.String())
+}
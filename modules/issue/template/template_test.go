@@ -0,0 +1,147 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package template
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const bugForm = `
+name: Bug report
+about: Report a bug
+title: "[Bug]: "
+labels: ["kind/bug"]
+body:
+  - type: markdown
+    attributes:
+      value: Thanks for taking the time to fill out this bug report!
+  - type: input
+    id: version
+    attributes:
+      label: Version
+    validations:
+      required: true
+      regex: "^[0-9.]+$"
+  - type: textarea
+    id: logs
+    attributes:
+      label: Logs
+      render: shell
+  - type: dropdown
+    id: browsers
+    attributes:
+      label: Browsers
+      multiple: true
+      options:
+        - Firefox
+        - Chrome
+  - type: checkboxes
+    attributes:
+      label: Terms
+      options:
+        - label: I agree to follow the Code of Conduct
+          required: true
+        - label: I searched for existing issues
+`
+
+func TestUnmarshal(t *testing.T) {
+	tmpl, err := Unmarshal("bug.yml", []byte(bugForm))
+	assert.NoError(t, err)
+	assert.Equal(t, "Bug report", tmpl.Name)
+	assert.Equal(t, "[Bug]: ", tmpl.Title)
+	assert.Equal(t, []string{"kind/bug"}, tmpl.Labels)
+	assert.Len(t, tmpl.Fields, 5)
+	assert.Equal(t, "field-0", tmpl.Fields[0].ID)
+	assert.Equal(t, "form-field-version", tmpl.Fields[1].FormName())
+	assert.Equal(t, "Chrome", tmpl.Fields[3].Attributes.Options[1].Label)
+	assert.True(t, tmpl.Fields[4].Attributes.Options[0].Required)
+	assert.Equal(t, "field-4", tmpl.Fields[4].ID)
+
+	for _, content := range []string{
+		"body: [{type: input, attributes: {label: A}}]",
+		"name: A",
+		"name: A\nbody: [{type: unknown, attributes: {label: A}}]",
+		"name: A\nbody: [{type: input}]",
+		"name: A\nbody: [{type: dropdown, attributes: {label: A}}]",
+		"name: A\nbody: [{type: input, id: a, attributes: {label: A}}, {type: input, id: a, attributes: {label: B}}]",
+		"name: A\nbody: [{type: input, id: 'a b', attributes: {label: A}}]",
+		"name: A\nbody: [{type: input, attributes: {label: A}, validations: {regex: '['}}]",
+		"name: [",
+	} {
+		_, err = Unmarshal("invalid.yml", []byte(content))
+		assert.True(t, IsErrInvalidTemplate(err), content)
+	}
+}
+
+func TestTemplate_ValidateValues(t *testing.T) {
+	tmpl, err := Unmarshal("bug.yml", []byte(bugForm))
+	assert.NoError(t, err)
+
+	valid := url.Values{
+		"form-field-version": {"1.9.0"},
+		"form-field-field-4": {"0"},
+	}
+	assert.NoError(t, tmpl.ValidateValues(valid))
+
+	for _, c := range []struct {
+		values url.Values
+		field  string
+		reason string
+	}{
+		{url.Values{"form-field-field-4": {"0"}}, "version", ValueRequired},
+		{url.Values{"form-field-version": {"v1"}, "form-field-field-4": {"0"}}, "version", ValueInvalidFormat},
+		{url.Values{"form-field-version": {"1"}, "form-field-field-4": {"1"}}, "field-4", ValueRequired},
+		{url.Values{"form-field-version": {"1"}, "form-field-field-4": {"0"}, "form-field-browsers": {"2"}}, "browsers", ValueInvalidOption},
+	} {
+		err = tmpl.ValidateValues(c.values)
+		if assert.True(t, IsErrInvalidValue(err)) {
+			assert.Equal(t, c.field, err.(ErrInvalidValue).Field.ID)
+			assert.Equal(t, c.reason, err.(ErrInvalidValue).Reason)
+		}
+	}
+}
+
+func TestTemplate_FieldValues(t *testing.T) {
+	tmpl, err := Unmarshal("bug.yml", []byte(bugForm))
+	assert.NoError(t, err)
+
+	fields := tmpl.FieldValues(nil)
+	assert.Len(t, fields, 5)
+	assert.Equal(t, tmpl.Fields[0].Attributes.Value, fields[0].Value)
+	assert.Empty(t, fields[1].Value)
+
+	fields = tmpl.FieldValues(url.Values{
+		"form-field-version":  {"1.9.0"},
+		"form-field-browsers": {"1"},
+	})
+	assert.Equal(t, "1.9.0", fields[1].Value)
+	assert.Equal(t, map[int]bool{1: true}, fields[3].Selected)
+	assert.Empty(t, fields[4].Selected)
+}
+
+func TestTemplate_RenderToMarkdown(t *testing.T) {
+	tmpl, err := Unmarshal("bug.yml", []byte(bugForm))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "### Version\n\n1.9.0\n\n"+
+		"### Logs\n\n```shell\npanic\n```\n\n"+
+		"### Browsers\n\nFirefox, Chrome\n\n"+
+		"### Terms\n\n- [x] I agree to follow the Code of Conduct\n- [ ] I searched for existing issues",
+		tmpl.RenderToMarkdown(url.Values{
+			"form-field-version":  {" 1.9.0 "},
+			"form-field-logs":     {"panic"},
+			"form-field-browsers": {"0", "1"},
+			"form-field-field-4":  {"0"},
+		}))
+
+	assert.Equal(t, "### Version\n\n_No response_\n\n"+
+		"### Logs\n\n_No response_\n\n"+
+		"### Browsers\n\n_No response_\n\n"+
+		"### Terms\n\n- [ ] I agree to follow the Code of Conduct\n- [ ] I searched for existing issues",
+		tmpl.RenderToMarkdown(url.Values{}))
+}
@@ -4,11 +4,13 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	"hash"
 	"io"
 	"os"
-	"path/filepath"
+	"path"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/storage"
 )
 
 var (
@@ -18,66 +20,46 @@ var (
 
 // ContentStore provides a simple file system based storage.
 type ContentStore struct {
-	BasePath string
+	storage.ObjectStorage
+}
+
+// NewContentStore returns the content store of the configured LFS storage
+func NewContentStore() *ContentStore {
+	return &ContentStore{ObjectStorage: storage.LFS}
 }
 
 // Get takes a Meta object and retrieves the content from the store, returning
 // it as an io.Reader. If fromByte > 0, the reader starts from that byte
 func (s *ContentStore) Get(meta *models.LFSMetaObject, fromByte int64) (io.ReadCloser, error) {
-	path := filepath.Join(s.BasePath, transformKey(meta.Oid))
-
-	f, err := os.Open(path)
+	f, err := s.Open(transformKey(meta.Oid))
 	if err != nil {
 		return nil, err
 	}
 	if fromByte > 0 {
-		_, err = f.Seek(fromByte, os.SEEK_CUR)
+		if _, err = f.Seek(fromByte, io.SeekCurrent); err != nil {
+			f.Close()
+			return nil, err
+		}
 	}
-	return f, err
+	return f, nil
 }
 
 // Put takes a Meta object and an io.Reader and writes the content to the store.
+// The content is only stored if its size and hash match the Meta object.
 func (s *ContentStore) Put(meta *models.LFSMetaObject, r io.Reader) error {
-	path := filepath.Join(s.BasePath, transformKey(meta.Oid))
-	tmpPath := path + ".tmp"
-
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0750); err != nil {
-		return err
-	}
-
-	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0640)
-	if err != nil {
-		return err
-	}
-	defer os.Remove(tmpPath)
-
-	hash := sha256.New()
-	hw := io.MultiWriter(hash, file)
-
-	written, err := io.Copy(hw, r)
-	if err != nil {
-		file.Close()
-		return err
+	hr := &hashingReader{
+		r:            r,
+		hash:         sha256.New(),
+		expectedSize: meta.Size,
+		expectedHash: meta.Oid,
 	}
-	file.Close()
-
-	if written != meta.Size {
-		return errSizeMismatch
-	}
-
-	shaStr := hex.EncodeToString(hash.Sum(nil))
-	if shaStr != meta.Oid {
-		return errHashMismatch
-	}
-
-	return os.Rename(tmpPath, path)
+	_, err := s.Save(transformKey(meta.Oid), hr, meta.Size)
+	return err
 }
 
 // Exists returns true if the object exists in the content store.
 func (s *ContentStore) Exists(meta *models.LFSMetaObject) bool {
-	path := filepath.Join(s.BasePath, transformKey(meta.Oid))
-	if _, err := os.Stat(path); os.IsNotExist(err) {
+	if _, err := s.Stat(transformKey(meta.Oid)); os.IsNotExist(err) {
 		return false
 	}
 	return true
@@ -85,9 +67,7 @@ func (s *ContentStore) Exists(meta *models.LFSMetaObject) bool {
 
 // Verify returns true if the object exists in the content store and size is correct.
 func (s *ContentStore) Verify(meta *models.LFSMetaObject) (bool, error) {
-	path := filepath.Join(s.BasePath, transformKey(meta.Oid))
-
-	fi, err := os.Stat(path)
+	fi, err := s.Stat(transformKey(meta.Oid))
 	if os.IsNotExist(err) || err == nil && fi.Size() != meta.Size {
 		return false, nil
 	} else if err != nil {
@@ -97,10 +77,37 @@ func (s *ContentStore) Verify(meta *models.LFSMetaObject) (bool, error) {
 	return true, nil
 }
 
+// hashingReader fails at the end of the content if its size or hash does not
+// match the expected ones, the storages then discard the content.
+type hashingReader struct {
+	r            io.Reader
+	hash         hash.Hash
+	currentSize  int64
+	expectedSize int64
+	expectedHash string
+}
+
+func (r *hashingReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	if n > 0 {
+		r.currentSize += int64(n)
+		_, _ = r.hash.Write(b[:n])
+	}
+	if err == io.EOF {
+		if r.currentSize != r.expectedSize {
+			return n, errSizeMismatch
+		}
+		if hex.EncodeToString(r.hash.Sum(nil)) != r.expectedHash {
+			return n, errHashMismatch
+		}
+	}
+	return n, err
+}
+
 func transformKey(key string) string {
 	if len(key) < 5 {
 		return key
 	}
 
-	return filepath.Join(key[0:2], key[2:4], key[4:])
+	return path.Join(key[0:2], key[2:4], key[4:])
 }
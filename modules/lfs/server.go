@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
@@ -159,7 +160,7 @@ func getContentHandler(ctx *context.Context) {
 		}
 	}
 
-	contentStore := &ContentStore{BasePath: setting.LFS.ContentPath}
+	contentStore := NewContentStore()
 	content, err := contentStore.Get(meta, fromByte)
 	if err != nil {
 		writeStatus(ctx, 404)
@@ -233,6 +234,16 @@ func PostHandler(ctx *context.Context) {
 		return
 	}
 
+	if err := checkLFSQuota(repository, rv.Oid, rv.Size); err != nil {
+		if models.IsErrQuotaExceeded(err) {
+			writeStatus(ctx, 422)
+		} else {
+			log.Error("Unable to check the LFS quota of %-v: %v", repository, err)
+			writeStatus(ctx, 500)
+		}
+		return
+	}
+
 	meta, err := models.NewLFSMetaObject(&models.LFSMetaObject{Oid: rv.Oid, Size: rv.Size, RepositoryID: repository.ID})
 	if err != nil {
 		writeStatus(ctx, 404)
@@ -242,7 +253,7 @@ func PostHandler(ctx *context.Context) {
 	ctx.Resp.Header().Set("Content-Type", metaMediaType)
 
 	sentStatus := 202
-	contentStore := &ContentStore{BasePath: setting.LFS.ContentPath}
+	contentStore := NewContentStore()
 	if meta.Existing && contentStore.Exists(meta) {
 		sentStatus = 200
 	}
@@ -293,7 +304,7 @@ func BatchHandler(ctx *context.Context) {
 			return
 		}
 
-		contentStore := &ContentStore{BasePath: setting.LFS.ContentPath}
+		contentStore := NewContentStore()
 
 		meta, err := repository.GetLFSMetaObjectByOid(object.Oid)
 		if err == nil && contentStore.Exists(meta) { // Object is found and exists
@@ -301,6 +312,25 @@ func BatchHandler(ctx *context.Context) {
 			continue
 		}
 
+		if bv.Operation == "upload" {
+			if err = checkLFSQuota(repository, object.Oid, object.Size); err != nil {
+				if !models.IsErrQuotaExceeded(err) {
+					log.Error("Unable to check the LFS quota of %-v: %v", repository, err)
+					writeStatus(ctx, 500)
+					return
+				}
+				responseObjects = append(responseObjects, &Representation{
+					Oid:  object.Oid,
+					Size: object.Size,
+					Error: &ObjectError{
+						Code:    422,
+						Message: fmt.Sprintf("the LFS objects of %s exceed their quota of %s", repository.Owner.Name, base.FileSize(err.(models.ErrQuotaExceeded).Limit)),
+					},
+				})
+				continue
+			}
+		}
+
 		// Object is not found
 		meta, err = models.NewLFSMetaObject(&models.LFSMetaObject{Oid: object.Oid, Size: object.Size, RepositoryID: repository.ID})
 		if err == nil {
@@ -317,6 +347,21 @@ func BatchHandler(ctx *context.Context) {
 	logRequest(ctx.Req, 200)
 }
 
+// checkLFSQuota returns ErrQuotaExceeded if uploading the object would grow
+// the LFS objects of the owner of the repository over its quota, the objects
+// already known in the repository are counted already.
+func checkLFSQuota(repository *models.Repository, oid string, size int64) error {
+	if _, err := repository.GetLFSMetaObjectByOid(oid); err == nil {
+		return nil
+	} else if err != models.ErrLFSObjectNotExist {
+		return err
+	}
+	if err := repository.GetOwner(); err != nil {
+		return err
+	}
+	return repository.Owner.CheckLFSQuota(size)
+}
+
 // PutHandler receives data from the client and puts it into the content store
 func PutHandler(ctx *context.Context) {
 	rv := unpack(ctx)
@@ -326,7 +371,7 @@ func PutHandler(ctx *context.Context) {
 		return
 	}
 
-	contentStore := &ContentStore{BasePath: setting.LFS.ContentPath}
+	contentStore := NewContentStore()
 	if err := contentStore.Put(meta, ctx.Req.Body().ReadCloser()); err != nil {
 		ctx.Resp.WriteHeader(500)
 		fmt.Fprintf(ctx.Resp, `{"message":"%s"}`, err)
@@ -358,7 +403,7 @@ func VerifyHandler(ctx *context.Context) {
 		return
 	}
 
-	contentStore := &ContentStore{BasePath: setting.LFS.ContentPath}
+	contentStore := NewContentStore()
 	ok, err := contentStore.Verify(meta)
 	if err != nil {
 		ctx.Resp.WriteHeader(500)
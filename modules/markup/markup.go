@@ -134,3 +134,22 @@ func IsReadmeFile(name string, ext ...string) bool {
 	}
 	return name[:7] == "readme."
 }
+
+// ReadmePriority returns the precedence of name among the README files of a
+// directory, the lowest being preferred, or -1 if name isn't a README file.
+// The extensions in exts come first in their order, then the README files
+// which can be rendered by a registered parser and finally any other one.
+func ReadmePriority(name string, exts []string) int {
+	if !IsReadmeFile(name) {
+		return -1
+	}
+	for i, ext := range exts {
+		if IsReadmeFile(name, strings.ToLower(ext)) {
+			return i
+		}
+	}
+	if GetParserByFileName(name) != nil {
+		return len(exts)
+	}
+	return len(exts) + 1
+}
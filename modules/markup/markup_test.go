@@ -60,3 +60,17 @@ func TestMisc_IsReadmeFile(t *testing.T) {
 		assert.False(t, IsReadmeFile(testCase[0], testCase[1]))
 	}
 }
+
+func TestMisc_ReadmePriority(t *testing.T) {
+	exts := []string{".md", ".txt", ""}
+
+	assert.EqualValues(t, -1, ReadmePriority("test.md", exts))
+	assert.EqualValues(t, -1, ReadmePriority("readmee.md", exts))
+	assert.EqualValues(t, 0, ReadmePriority("README.md", exts))
+	assert.EqualValues(t, 0, ReadmePriority("readme.MD", []string{".MD"}))
+	assert.EqualValues(t, 1, ReadmePriority("readme.txt", exts))
+	assert.EqualValues(t, 2, ReadmePriority("README", exts))
+	assert.EqualValues(t, 3, ReadmePriority("README.markdown", exts))
+	assert.EqualValues(t, 4, ReadmePriority("README.i18n.zh", exts))
+	assert.EqualValues(t, 0, ReadmePriority("README.markdown", nil))
+}
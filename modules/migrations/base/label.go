@@ -7,7 +7,7 @@ package base
 
 // Label defines a standard label informations
 type Label struct {
-	Name        string
-	Color       string
-	Description string
+	Name        string `yaml:"name"`
+	Color       string `yaml:"color"`
+	Description string `yaml:"description"`
 }
@@ -9,11 +9,11 @@ import "time"
 
 // Milestone defines a standard milestone
 type Milestone struct {
-	Title       string
-	Description string
-	Deadline    *time.Time
-	Created     time.Time
-	Updated     *time.Time
-	Closed      *time.Time
-	State       string
+	Title       string     `yaml:"title"`
+	Description string     `yaml:"description"`
+	Deadline    *time.Time `yaml:"deadline"`
+	Created     time.Time  `yaml:"created"`
+	Updated     *time.Time `yaml:"updated"`
+	Closed      *time.Time `yaml:"closed"`
+	State       string     `yaml:"state"`
 }
@@ -7,11 +7,11 @@ package base
 
 // Reactions represents a summary of reactions.
 type Reactions struct {
-	TotalCount int
-	PlusOne    int
-	MinusOne   int
-	Laugh      int
-	Confused   int
-	Heart      int
-	Hooray     int
+	TotalCount int `yaml:"total_count"`
+	PlusOne    int `yaml:"plus_one"`
+	MinusOne   int `yaml:"minus_one"`
+	Laugh      int `yaml:"laugh"`
+	Confused   int `yaml:"confused"`
+	Heart      int `yaml:"heart"`
+	Hooray     int `yaml:"hooray"`
 }
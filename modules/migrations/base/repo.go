@@ -7,13 +7,13 @@ package base
 
 // Repository defines a standard repository information
 type Repository struct {
-	Name         string
-	Owner        string
-	IsPrivate    bool
-	IsMirror     bool
-	Description  string
-	AuthUsername string
-	AuthPassword string
-	CloneURL     string
-	OriginalURL  string
+	Name         string `yaml:"name"`
+	Owner        string `yaml:"owner"`
+	IsPrivate    bool   `yaml:"is_private"`
+	IsMirror     bool   `yaml:"is_mirror"`
+	Description  string `yaml:"description"`
+	AuthUsername string `yaml:"-"`
+	AuthPassword string `yaml:"-"`
+	CloneURL     string `yaml:"clone_url"`
+	OriginalURL  string `yaml:"original_url"`
 }
@@ -0,0 +1,481 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/migrations/base"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/util"
+
+	api "code.gitea.io/gitea/modules/structs"
+	"gopkg.in/yaml.v2"
+)
+
+// The files and directories of a repository dump, relative to its base directory
+const (
+	DumpRepoFile         = "repo.yml"
+	DumpTopicFile        = "topic.yml"
+	DumpMilestoneFile    = "milestone.yml"
+	DumpLabelFile        = "label.yml"
+	DumpReleaseFile      = "release.yml"
+	DumpIssueFile        = "issue.yml"
+	DumpPullRequestFile  = "pull_request.yml"
+	DumpCommentDir       = "comments"
+	DumpReleaseAssetsDir = "release_assets"
+	DumpPatchDir         = "pulls"
+	DumpGitRepo          = "git/repo.git"
+	DumpGitWiki          = "git/repo.wiki.git"
+)
+
+// dumpPageSize is the number of records read from the database at once
+const dumpPageSize = 50
+
+// RepositoryDumper writes the git data and the metadata of a repository
+// into a directory, in a format that RepositoryRestorer reads back
+type RepositoryDumper struct {
+	repo    *models.Repository
+	baseDir string
+	gitRepo *git.Repository
+}
+
+// NewRepositoryDumper creates a dumper of repo into baseDir
+func NewRepositoryDumper(repo *models.Repository, baseDir string) *RepositoryDumper {
+	return &RepositoryDumper{
+		repo:    repo,
+		baseDir: baseDir,
+	}
+}
+
+// DumpRepository writes the git data and the metadata of repo into baseDir
+func DumpRepository(repo *models.Repository, baseDir string) error {
+	return NewRepositoryDumper(repo, baseDir).Dump()
+}
+
+// Dump writes the repository, its wiki, topics, milestones, labels,
+// releases, issues, pull requests and their comments
+func (d *RepositoryDumper) Dump() (err error) {
+	if err = os.MkdirAll(d.baseDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	if err = d.dumpGit(); err != nil {
+		return fmt.Errorf("dumpGit: %v", err)
+	}
+	d.gitRepo, err = git.OpenRepository(d.repo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+
+	if err = d.dumpTopics(); err != nil {
+		return fmt.Errorf("dumpTopics: %v", err)
+	}
+	if err = d.dumpMilestones(); err != nil {
+		return fmt.Errorf("dumpMilestones: %v", err)
+	}
+	if err = d.dumpLabels(); err != nil {
+		return fmt.Errorf("dumpLabels: %v", err)
+	}
+	if err = d.dumpReleases(); err != nil {
+		return fmt.Errorf("dumpReleases: %v", err)
+	}
+	if err = d.dumpIssues(); err != nil {
+		return fmt.Errorf("dumpIssues: %v", err)
+	}
+	if err = d.dumpPullRequests(); err != nil {
+		return fmt.Errorf("dumpPullRequests: %v", err)
+	}
+	return nil
+}
+
+func (d *RepositoryDumper) writeYAML(name string, v interface{}) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	p := filepath.Join(d.baseDir, name)
+	if err = os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p, data, 0644)
+}
+
+func (d *RepositoryDumper) copyFile(name string, r io.Reader) error {
+	p := filepath.Join(d.baseDir, name)
+	if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.Create(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, r)
+	return err
+}
+
+func (d *RepositoryDumper) dumpGit() error {
+	if err := d.repo.GetOwner(); err != nil {
+		return err
+	}
+
+	if err := git.Clone(d.repo.RepoPath(), filepath.Join(d.baseDir, DumpGitRepo), git.CloneRepoOptions{
+		Mirror: true,
+		Quiet:  true,
+	}); err != nil {
+		return fmt.Errorf("Clone: %v", err)
+	}
+	if d.repo.HasWiki() {
+		if err := git.Clone(d.repo.WikiPath(), filepath.Join(d.baseDir, DumpGitWiki), git.CloneRepoOptions{
+			Mirror: true,
+			Quiet:  true,
+		}); err != nil {
+			return fmt.Errorf("Clone wiki: %v", err)
+		}
+	}
+
+	return d.writeYAML(DumpRepoFile, &base.Repository{
+		Name:        d.repo.Name,
+		Owner:       d.repo.Owner.Name,
+		IsPrivate:   d.repo.IsPrivate,
+		IsMirror:    d.repo.IsMirror,
+		Description: d.repo.Description,
+		CloneURL:    DumpGitRepo,
+		OriginalURL: d.repo.HTMLURL(),
+	})
+}
+
+func (d *RepositoryDumper) dumpTopics() error {
+	topics, err := models.FindTopics(&models.FindTopicOptions{
+		RepoID: d.repo.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	var names = make([]string, 0, len(topics))
+	for _, topic := range topics {
+		names = append(names, topic.Name)
+	}
+	return d.writeYAML(DumpTopicFile, names)
+}
+
+func (d *RepositoryDumper) dumpMilestones() error {
+	milestones, err := models.GetMilestonesByRepoID(d.repo.ID, api.StateAll)
+	if err != nil {
+		return err
+	}
+
+	var mss = make([]*base.Milestone, 0, len(milestones))
+	for _, milestone := range milestones {
+		var ms = base.Milestone{
+			Title:       milestone.Name,
+			Description: milestone.Content,
+			State:       "open",
+		}
+		if milestone.DeadlineUnix > 0 {
+			deadline := milestone.DeadlineUnix.AsTime()
+			ms.Deadline = &deadline
+		}
+		if milestone.IsClosed {
+			ms.State = "closed"
+			if milestone.ClosedDateUnix > 0 {
+				closed := milestone.ClosedDateUnix.AsTime()
+				ms.Closed = &closed
+			}
+		}
+		mss = append(mss, &ms)
+	}
+	return d.writeYAML(DumpMilestoneFile, mss)
+}
+
+func (d *RepositoryDumper) dumpLabels() error {
+	labels, err := models.GetLabelsByRepoID(d.repo.ID, "")
+	if err != nil {
+		return err
+	}
+
+	var lbs = make([]*base.Label, 0, len(labels))
+	for _, label := range labels {
+		lbs = append(lbs, convertLabel(label))
+	}
+	return d.writeYAML(DumpLabelFile, lbs)
+}
+
+func convertLabel(label *models.Label) *base.Label {
+	var color = label.Color
+	if len(color) > 0 && color[0] == '#' {
+		color = color[1:]
+	}
+	return &base.Label{
+		Name:        label.Name,
+		Color:       color,
+		Description: label.Description,
+	}
+}
+
+func (d *RepositoryDumper) dumpReleases() error {
+	var rels []*base.Release
+	for page := 1; ; page++ {
+		releases, err := models.GetReleasesByRepoID(d.repo.ID, models.FindReleasesOptions{
+			IncludeDrafts: true,
+		}, page, dumpPageSize)
+		if err != nil {
+			return err
+		}
+		if err = models.GetReleaseAttachments(releases...); err != nil {
+			return err
+		}
+
+		for _, release := range releases {
+			var rel = base.Release{
+				TagName:         release.TagName,
+				TargetCommitish: release.Target,
+				Name:            release.Title,
+				Body:            release.Note,
+				Draft:           release.IsDraft,
+				Prerelease:      release.IsPrerelease,
+				Created:         release.CreatedUnix.AsTime(),
+				Published:       release.CreatedUnix.AsTime(),
+			}
+			for _, attach := range release.Attachments {
+				assetPath := filepath.ToSlash(filepath.Join(DumpReleaseAssetsDir, release.TagName, attach.UUID, attach.Name))
+				if err = d.dumpAttachment(assetPath, attach); err != nil {
+					return fmt.Errorf("dumpAttachment %s: %v", attach.UUID, err)
+				}
+
+				size := int(attach.Size)
+				downloadCount := int(attach.DownloadCount)
+				rel.Assets = append(rel.Assets, base.ReleaseAsset{
+					URL:           assetPath,
+					Name:          attach.Name,
+					Size:          &size,
+					DownloadCount: &downloadCount,
+					Created:       attach.CreatedUnix.AsTime(),
+					Updated:       attach.CreatedUnix.AsTime(),
+				})
+			}
+			rels = append(rels, &rel)
+		}
+
+		if len(releases) < dumpPageSize {
+			break
+		}
+	}
+	return d.writeYAML(DumpReleaseFile, rels)
+}
+
+func (d *RepositoryDumper) dumpAttachment(name string, attach *models.Attachment) error {
+	obj, err := storage.Attachments.Open(attach.RelativePath())
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+	return d.copyFile(name, obj)
+}
+
+func (d *RepositoryDumper) issues(isPull bool, page int) ([]*models.Issue, error) {
+	return models.Issues(&models.IssuesOptions{
+		RepoIDs:  []int64{d.repo.ID},
+		IsPull:   util.OptionalBoolOf(isPull),
+		Page:     page,
+		PageSize: dumpPageSize,
+		SortType: "oldest",
+	})
+}
+
+func (d *RepositoryDumper) dumpComments(issue *models.Issue) error {
+	comments, err := models.FindComments(models.FindCommentsOptions{
+		IssueID: issue.ID,
+		Type:    models.CommentTypeComment,
+	})
+	if err != nil {
+		return err
+	}
+
+	var cms = make([]*base.Comment, 0, len(comments))
+	for _, comment := range comments {
+		if err = comment.LoadPoster(); err != nil {
+			return err
+		}
+		cms = append(cms, &base.Comment{
+			IssueIndex:  issue.Index,
+			PosterID:    comment.PosterID,
+			PosterName:  comment.Poster.Name,
+			PosterEmail: comment.Poster.Email,
+			Created:     comment.CreatedUnix.AsTime(),
+			Content:     comment.Content,
+		})
+	}
+	if len(cms) == 0 {
+		return nil
+	}
+	return d.writeYAML(filepath.Join(DumpCommentDir, fmt.Sprintf("%d.yml", issue.Index)), cms)
+}
+
+func (d *RepositoryDumper) dumpIssues() error {
+	var iss []*base.Issue
+	for page := 1; ; page++ {
+		issues, err := d.issues(false, page)
+		if err != nil {
+			return err
+		}
+
+		for _, issue := range issues {
+			is := &base.Issue{
+				Number:      issue.Index,
+				PosterID:    issue.PosterID,
+				PosterName:  issue.Poster.Name,
+				PosterEmail: issue.Poster.Email,
+				Title:       issue.Title,
+				Content:     issue.Content,
+				State:       "open",
+				IsLocked:    issue.IsLocked,
+				Created:     issue.CreatedUnix.AsTime(),
+				Labels:      convertLabels(issue.Labels),
+			}
+			if issue.Milestone != nil {
+				is.Milestone = issue.Milestone.Name
+			}
+			is.State, is.Closed = issueState(issue)
+			iss = append(iss, is)
+
+			if err = d.dumpComments(issue); err != nil {
+				return err
+			}
+		}
+
+		if len(issues) < dumpPageSize {
+			break
+		}
+	}
+	return d.writeYAML(DumpIssueFile, iss)
+}
+
+func convertLabels(labels []*models.Label) []*base.Label {
+	var lbs = make([]*base.Label, 0, len(labels))
+	for _, label := range labels {
+		lbs = append(lbs, convertLabel(label))
+	}
+	return lbs
+}
+
+func issueState(issue *models.Issue) (string, *time.Time) {
+	if !issue.IsClosed {
+		return "open", nil
+	}
+	if issue.ClosedUnix == 0 {
+		return "closed", nil
+	}
+	closed := issue.ClosedUnix.AsTime()
+	return "closed", &closed
+}
+
+func (d *RepositoryDumper) dumpPullRequests() error {
+	var prs []*base.PullRequest
+	for page := 1; ; page++ {
+		issues, err := d.issues(true, page)
+		if err != nil {
+			return err
+		}
+
+		for _, issue := range issues {
+			pr, err := d.convertPullRequest(issue)
+			if err != nil {
+				return fmt.Errorf("convertPullRequest %d: %v", issue.Index, err)
+			}
+			prs = append(prs, pr)
+
+			if err = d.dumpComments(issue); err != nil {
+				return err
+			}
+		}
+
+		if len(issues) < dumpPageSize {
+			break
+		}
+	}
+	return d.writeYAML(DumpPullRequestFile, prs)
+}
+
+func (d *RepositoryDumper) convertPullRequest(issue *models.Issue) (*base.PullRequest, error) {
+	if err := issue.LoadPullRequest(); err != nil {
+		return nil, err
+	}
+	pr := issue.PullRequest
+
+	var result = base.PullRequest{
+		Number:         issue.Index,
+		Title:          issue.Title,
+		PosterName:     issue.Poster.Name,
+		PosterID:       issue.PosterID,
+		PosterEmail:    issue.Poster.Email,
+		Content:        issue.Content,
+		Created:        issue.CreatedUnix.AsTime(),
+		Labels:         convertLabels(issue.Labels),
+		Merged:         pr.HasMerged,
+		MergeCommitSHA: pr.MergedCommitID,
+		IsLocked:       issue.IsLocked,
+		Base: base.PullRequestBranch{
+			Ref:       pr.BaseBranch,
+			SHA:       pr.MergeBase,
+			RepoName:  d.repo.Name,
+			OwnerName: d.repo.Owner.Name,
+		},
+		Head: base.PullRequestBranch{
+			Ref:       pr.HeadBranch,
+			RepoName:  d.repo.Name,
+			OwnerName: d.repo.Owner.Name,
+		},
+	}
+	if issue.Milestone != nil {
+		result.Milestone = issue.Milestone.Name
+	}
+	result.State, result.Closed = issueState(issue)
+	if pr.HasMerged && pr.MergedUnix > 0 {
+		merged := pr.MergedUnix.AsTime()
+		result.MergedTime = &merged
+	}
+
+	if pr.HeadRepoID != pr.BaseRepoID {
+		if err := pr.GetHeadRepo(); err != nil {
+			return nil, err
+		}
+		if pr.HeadRepo != nil {
+			result.Head.OwnerName = pr.HeadRepo.MustOwner().Name
+			result.Head.RepoName = pr.HeadRepo.Name
+			result.Head.CloneURL = pr.HeadRepo.CloneLink().HTTPS
+		}
+	}
+
+	// the head reference is missing for corrupted pull requests
+	if sha, err := d.gitRepo.GetRefCommitID(pr.GetGitRefName()); err == nil {
+		result.Head.SHA = sha
+	}
+
+	patchPath, err := d.repo.PatchPath(issue.Index)
+	if err != nil {
+		return nil, err
+	}
+	if f, err := os.Open(patchPath); err == nil {
+		defer f.Close()
+		result.PatchURL = filepath.ToSlash(filepath.Join(DumpPatchDir, fmt.Sprintf("%d.patch", issue.Index)))
+		if err = d.copyFile(result.PatchURL, f); err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return &result, nil
+}
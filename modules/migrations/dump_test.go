@@ -0,0 +1,89 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/util"
+
+	api "code.gitea.io/gitea/modules/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDumpRestoreRepository(t *testing.T) {
+	models.PrepareTestEnv(t)
+
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+
+	dir, err := ioutil.TempDir("", "gitea-repo-dump-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, DumpRepository(repo, dir))
+	assert.FileExists(t, filepath.Join(dir, DumpRepoFile))
+	assert.FileExists(t, filepath.Join(dir, DumpIssueFile))
+	assert.DirExists(t, filepath.Join(dir, DumpGitRepo))
+	assert.True(t, IsRepositoryDump(dir))
+
+	restored, err := MigrateRepository(user, user.Name, MigrateOptions{
+		RemoteURL:    dir,
+		Name:         "repo1-restored",
+		Wiki:         true,
+		Issues:       true,
+		Milestones:   true,
+		Labels:       true,
+		Releases:     true,
+		Comments:     true,
+		PullRequests: true,
+	})
+	assert.NoError(t, err)
+	if !assert.NotNil(t, restored) {
+		return
+	}
+	assert.EqualValues(t, repo.HTMLURL(), restored.OriginalURL)
+
+	labels, err := models.GetLabelsByRepoID(restored.ID, "")
+	assert.NoError(t, err)
+	expectedLabels, err := models.GetLabelsByRepoID(repo.ID, "")
+	assert.NoError(t, err)
+	assert.Len(t, labels, len(expectedLabels))
+
+	milestones, err := models.GetMilestonesByRepoID(restored.ID, api.StateAll)
+	assert.NoError(t, err)
+	expectedMilestones, err := models.GetMilestonesByRepoID(repo.ID, api.StateAll)
+	assert.NoError(t, err)
+	assert.Len(t, milestones, len(expectedMilestones))
+
+	for _, isPull := range []bool{false, true} {
+		issues, err := models.Issues(&models.IssuesOptions{
+			RepoIDs:  []int64{restored.ID},
+			IsPull:   util.OptionalBoolOf(isPull),
+			SortType: "oldest",
+		})
+		assert.NoError(t, err)
+		expectedIssues, err := models.Issues(&models.IssuesOptions{
+			RepoIDs:  []int64{repo.ID},
+			IsPull:   util.OptionalBoolOf(isPull),
+			SortType: "oldest",
+		})
+		assert.NoError(t, err)
+		if assert.Len(t, issues, len(expectedIssues)) {
+			for i, issue := range issues {
+				assert.EqualValues(t, expectedIssues[i].Index, issue.Index)
+				assert.EqualValues(t, expectedIssues[i].Title, issue.Title)
+				assert.EqualValues(t, expectedIssues[i].IsClosed, issue.IsClosed)
+				assert.EqualValues(t, expectedIssues[i].Poster.Name, issue.OriginalAuthor)
+			}
+		}
+	}
+
+	assert.NoError(t, models.DeleteRepository(user, user.ID, restored.ID))
+}
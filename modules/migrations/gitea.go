@@ -11,7 +11,6 @@ import (
 	"net/http"
 	"net/url"
 	"os"
-	"path"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -22,6 +21,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/migrations/base"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/timeutil"
 
 	gouuid "github.com/satori/go.uuid"
@@ -42,6 +42,10 @@ type GiteaLocalUploader struct {
 	issues      sync.Map
 	gitRepo     *git.Repository
 	prHeadCache map[string]struct{}
+
+	// allowLocalFiles allows the attachments and patches to be read from
+	// file:// URLs, only the restorer of repository dumps provides them
+	allowLocalFiles bool
 }
 
 // NewGiteaLocalUploader creates an gitea Uploader via gitea API v1
@@ -209,25 +213,14 @@ func (g *GiteaLocalUploader) CreateReleases(releases ...*base.Release) error {
 			}
 
 			// download attachment
-			resp, err := http.Get(asset.URL)
+			rc, err := g.openURL(asset.URL)
 			if err != nil {
 				return err
 			}
-			defer resp.Body.Close()
-
-			localPath := attach.LocalPath()
-			if err = os.MkdirAll(path.Dir(localPath), os.ModePerm); err != nil {
-				return fmt.Errorf("MkdirAll: %v", err)
-			}
-
-			fw, err := os.Create(localPath)
-			if err != nil {
-				return fmt.Errorf("Create: %v", err)
-			}
-			defer fw.Close()
+			defer rc.Close()
 
-			if _, err := io.Copy(fw, resp.Body); err != nil {
-				return err
+			if _, err = storage.Attachments.Save(attach.RelativePath(), rc, -1); err != nil {
+				return fmt.Errorf("Save: %v", err)
 			}
 
 			rel.Attachments = append(rel.Attachments, &attach)
@@ -364,23 +357,25 @@ func (g *GiteaLocalUploader) newPullRequest(pr *base.PullRequest) (*models.PullR
 	}
 
 	// download patch file
-	resp, err := http.Get(pr.PatchURL)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	pullDir := filepath.Join(g.repo.RepoPath(), "pulls")
-	if err = os.MkdirAll(pullDir, os.ModePerm); err != nil {
-		return nil, err
-	}
-	f, err := os.Create(filepath.Join(pullDir, fmt.Sprintf("%d.patch", pr.Number)))
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-	_, err = io.Copy(f, resp.Body)
-	if err != nil {
-		return nil, err
+	if pr.PatchURL != "" {
+		rc, err := g.openURL(pr.PatchURL)
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		pullDir := filepath.Join(g.repo.RepoPath(), "pulls")
+		if err = os.MkdirAll(pullDir, os.ModePerm); err != nil {
+			return nil, err
+		}
+		f, err := os.Create(filepath.Join(pullDir, fmt.Sprintf("%d.patch", pr.Number)))
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		_, err = io.Copy(f, rc)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// set head information
@@ -483,6 +478,22 @@ func (g *GiteaLocalUploader) newPullRequest(pr *base.PullRequest) (*models.PullR
 	return &pullRequest, nil
 }
 
+// openURL opens the content of an attachment or a patch
+func (g *GiteaLocalUploader) openURL(rawurl string) (io.ReadCloser, error) {
+	if strings.HasPrefix(rawurl, "file://") {
+		if !g.allowLocalFiles {
+			return nil, fmt.Errorf("local file %s is not allowed", rawurl)
+		}
+		return os.Open(filepath.FromSlash(strings.TrimPrefix(rawurl, "file://")))
+	}
+
+	resp, err := http.Get(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
 // Rollback when migrating failed, this will rollback all the changes.
 func (g *GiteaLocalUploader) Rollback() error {
 	if g.repo != nil && g.repo.ID > 0 {
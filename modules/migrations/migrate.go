@@ -38,6 +38,7 @@ func MigrateRepository(doer *models.User, ownerName string, opts base.MigrateOpt
 			if err != nil {
 				return nil, err
 			}
+			_, uploader.allowLocalFiles = downloader.(*RepositoryRestorer)
 			break
 		}
 	}
@@ -0,0 +1,208 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migrations/base"
+
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	_ base.Downloader        = &RepositoryRestorer{}
+	_ base.DownloaderFactory = &RepositoryRestorerFactory{}
+)
+
+func init() {
+	RegisterDownloaderFactory(&RepositoryRestorerFactory{})
+}
+
+// RepositoryRestorerFactory defines a factory of restorers of repository dumps
+type RepositoryRestorerFactory struct {
+}
+
+// Match returns true if the migration remote URL is a local directory containing a repository dump
+func (f *RepositoryRestorerFactory) Match(opts base.MigrateOptions) (bool, error) {
+	return IsRepositoryDump(opts.RemoteURL), nil
+}
+
+// New returns a Downloader related to this factory according MigrateOptions
+func (f *RepositoryRestorerFactory) New(opts base.MigrateOptions) (base.Downloader, error) {
+	log.Trace("Create repository restorer: %s", opts.RemoteURL)
+
+	return NewRepositoryRestorer(opts.RemoteURL)
+}
+
+// IsRepositoryDump returns true if baseDir is a directory containing a repository dump
+func IsRepositoryDump(baseDir string) bool {
+	if !filepath.IsAbs(baseDir) {
+		return false
+	}
+	fi, err := os.Stat(filepath.Join(baseDir, DumpRepoFile))
+	return err == nil && fi.Mode().IsRegular()
+}
+
+// RepositoryRestorer implements a Downloader reading a repository dump
+// written by RepositoryDumper
+type RepositoryRestorer struct {
+	baseDir string
+	issues  []*base.Issue
+	prs     []*base.PullRequest
+}
+
+// NewRepositoryRestorer creates a restorer of the repository dump in baseDir
+func NewRepositoryRestorer(baseDir string) (*RepositoryRestorer, error) {
+	baseDir, err := filepath.Abs(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	return &RepositoryRestorer{
+		baseDir: baseDir,
+	}, nil
+}
+
+// readYAML unmarshals the file name of the dump into v, a missing file is left empty
+func (r *RepositoryRestorer) readYAML(name string, v interface{}) error {
+	data, err := ioutil.ReadFile(filepath.Join(r.baseDir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err = yaml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	return nil
+}
+
+// localURL returns the URL of the file name of the dump, which must not lie outside of it
+func (r *RepositoryRestorer) localURL(name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+	p := filepath.Join(r.baseDir, filepath.FromSlash(name))
+	if !strings.HasPrefix(p, r.baseDir+string(filepath.Separator)) {
+		return "", fmt.Errorf("file %s is outside of the dump", name)
+	}
+	return "file://" + filepath.ToSlash(p), nil
+}
+
+// GetRepoInfo returns a repository information
+func (r *RepositoryRestorer) GetRepoInfo() (*base.Repository, error) {
+	var repo base.Repository
+	if err := r.readYAML(DumpRepoFile, &repo); err != nil {
+		return nil, err
+	}
+	repo.CloneURL = filepath.Join(r.baseDir, filepath.FromSlash(DumpGitRepo))
+	return &repo, nil
+}
+
+// GetTopics returns topics
+func (r *RepositoryRestorer) GetTopics() ([]string, error) {
+	var topics []string
+	if err := r.readYAML(DumpTopicFile, &topics); err != nil {
+		return nil, err
+	}
+	return topics, nil
+}
+
+// GetMilestones returns milestones
+func (r *RepositoryRestorer) GetMilestones() ([]*base.Milestone, error) {
+	var milestones []*base.Milestone
+	if err := r.readYAML(DumpMilestoneFile, &milestones); err != nil {
+		return nil, err
+	}
+	return milestones, nil
+}
+
+// GetReleases returns releases
+func (r *RepositoryRestorer) GetReleases() ([]*base.Release, error) {
+	var releases []*base.Release
+	if err := r.readYAML(DumpReleaseFile, &releases); err != nil {
+		return nil, err
+	}
+	var err error
+	for _, release := range releases {
+		for i := range release.Assets {
+			release.Assets[i].URL, err = r.localURL(release.Assets[i].URL)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return releases, nil
+}
+
+// GetLabels returns labels
+func (r *RepositoryRestorer) GetLabels() ([]*base.Label, error) {
+	var labels []*base.Label
+	if err := r.readYAML(DumpLabelFile, &labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// GetIssues returns issues according page and perPage
+func (r *RepositoryRestorer) GetIssues(page, perPage int) ([]*base.Issue, bool, error) {
+	if r.issues == nil {
+		r.issues = make([]*base.Issue, 0, 10)
+		if err := r.readYAML(DumpIssueFile, &r.issues); err != nil {
+			return nil, false, err
+		}
+	}
+
+	start, end := pageBounds(len(r.issues), page, perPage)
+	return r.issues[start:end], end == len(r.issues), nil
+}
+
+// GetComments returns comments according issueNumber
+func (r *RepositoryRestorer) GetComments(issueNumber int64) ([]*base.Comment, error) {
+	var comments []*base.Comment
+	if err := r.readYAML(filepath.Join(DumpCommentDir, fmt.Sprintf("%d.yml", issueNumber)), &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// GetPullRequests returns pull requests according page and perPage
+func (r *RepositoryRestorer) GetPullRequests(page, perPage int) ([]*base.PullRequest, error) {
+	if r.prs == nil {
+		r.prs = make([]*base.PullRequest, 0, 10)
+		if err := r.readYAML(DumpPullRequestFile, &r.prs); err != nil {
+			return nil, err
+		}
+		var err error
+		for _, pr := range r.prs {
+			pr.PatchURL, err = r.localURL(pr.PatchURL)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	start, end := pageBounds(len(r.prs), page, perPage)
+	return r.prs[start:end], nil
+}
+
+// pageBounds returns the bounds of page in a list of total items
+func pageBounds(total, page, perPage int) (int, int) {
+	start := (page - 1) * perPage
+	if start > total {
+		start = total
+	}
+	end := start + perPage
+	if end > total {
+		end = total
+	}
+	return start, end
+}
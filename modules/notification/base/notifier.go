@@ -27,10 +27,14 @@ type Notifier interface {
 	NotifyIssueChangeTitle(doer *models.User, issue *models.Issue, oldTitle string)
 	NotifyIssueChangeLabels(doer *models.User, issue *models.Issue,
 		addedLabels []*models.Label, removedLabels []*models.Label)
+	NotifyIssueMove(doer *models.User, oldRepo *models.Repository, issue *models.Issue)
+	NotifyDeleteIssue(doer *models.User, issue *models.Issue)
 
 	NotifyNewPullRequest(*models.PullRequest)
+	NotifyPullRequestReadyForReview(doer *models.User, pr *models.PullRequest)
 	NotifyMergePullRequest(*models.PullRequest, *models.User, *git.Repository)
 	NotifyPullRequestReview(*models.PullRequest, *models.Review, *models.Comment)
+	NotifyPullRequestReviewRequest(doer *models.User, issue *models.Issue, reviewer *models.User, comment *models.Comment)
 
 	NotifyCreateIssueComment(*models.User, *models.Repository,
 		*models.Issue, *models.Comment)
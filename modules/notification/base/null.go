@@ -38,10 +38,18 @@ func (*NullNotifier) NotifyIssueChangeStatus(doer *models.User, issue *models.Is
 func (*NullNotifier) NotifyNewPullRequest(pr *models.PullRequest) {
 }
 
+// NotifyPullRequestReadyForReview places a place holder function
+func (*NullNotifier) NotifyPullRequestReadyForReview(doer *models.User, pr *models.PullRequest) {
+}
+
 // NotifyPullRequestReview places a place holder function
 func (*NullNotifier) NotifyPullRequestReview(pr *models.PullRequest, r *models.Review, comment *models.Comment) {
 }
 
+// NotifyPullRequestReviewRequest places a place holder function
+func (*NullNotifier) NotifyPullRequestReviewRequest(doer *models.User, issue *models.Issue, reviewer *models.User, comment *models.Comment) {
+}
+
 // NotifyMergePullRequest places a place holder function
 func (*NullNotifier) NotifyMergePullRequest(pr *models.PullRequest, doer *models.User, baseRepo *git.Repository) {
 }
@@ -99,6 +107,14 @@ func (*NullNotifier) NotifyIssueChangeLabels(doer *models.User, issue *models.Is
 	addedLabels []*models.Label, removedLabels []*models.Label) {
 }
 
+// NotifyIssueMove places a place holder function
+func (*NullNotifier) NotifyIssueMove(doer *models.User, oldRepo *models.Repository, issue *models.Issue) {
+}
+
+// NotifyDeleteIssue places a place holder function
+func (*NullNotifier) NotifyDeleteIssue(doer *models.User, issue *models.Issue) {
+}
+
 // NotifyCreateRepository places a place holder function
 func (*NullNotifier) NotifyCreateRepository(doer *models.User, u *models.User, repo *models.Repository) {
 }
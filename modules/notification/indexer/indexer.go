@@ -48,6 +48,18 @@ func (r *indexerNotifier) NotifyNewPullRequest(pr *models.PullRequest) {
 	issue_indexer.UpdateIssueIndexer(pr.Issue)
 }
 
+func (r *indexerNotifier) NotifyIssueMove(doer *models.User, oldRepo *models.Repository, issue *models.Issue) {
+	if err := issue.LoadDiscussComments(); err != nil {
+		log.Error("LoadComments failed: %v", err)
+		return
+	}
+	issue_indexer.UpdateIssueIndexer(issue)
+}
+
+func (r *indexerNotifier) NotifyDeleteIssue(doer *models.User, issue *models.Issue) {
+	issue_indexer.DeleteIssueIndexer(issue)
+}
+
 func (r *indexerNotifier) NotifyUpdateComment(doer *models.User, c *models.Comment, oldContent string) {
 	if c.Type == models.CommentTypeComment {
 		var found bool
@@ -65,6 +65,13 @@ func NotifyNewPullRequest(pr *models.PullRequest) {
 	}
 }
 
+// NotifyPullRequestReadyForReview notifies that a draft pull request is ready for review
+func NotifyPullRequestReadyForReview(doer *models.User, pr *models.PullRequest) {
+	for _, notifier := range notifiers {
+		notifier.NotifyPullRequestReadyForReview(doer, pr)
+	}
+}
+
 // NotifyPullRequestReview notifies new pull request review
 func NotifyPullRequestReview(pr *models.PullRequest, review *models.Review, comment *models.Comment) {
 	for _, notifier := range notifiers {
@@ -72,6 +79,13 @@ func NotifyPullRequestReview(pr *models.PullRequest, review *models.Review, comm
 	}
 }
 
+// NotifyPullRequestReviewRequest notifies that a review of the pull request has been requested from the reviewer
+func NotifyPullRequestReviewRequest(doer *models.User, issue *models.Issue, reviewer *models.User, comment *models.Comment) {
+	for _, notifier := range notifiers {
+		notifier.NotifyPullRequestReviewRequest(doer, issue, reviewer, comment)
+	}
+}
+
 // NotifyUpdateComment notifies update comment to notifiers
 func NotifyUpdateComment(doer *models.User, c *models.Comment, oldContent string) {
 	for _, notifier := range notifiers {
@@ -164,6 +178,20 @@ func NotifyIssueChangeLabels(doer *models.User, issue *models.Issue,
 	}
 }
 
+// NotifyIssueMove notifies an issue moved to another repository to notifiers
+func NotifyIssueMove(doer *models.User, oldRepo *models.Repository, issue *models.Issue) {
+	for _, notifier := range notifiers {
+		notifier.NotifyIssueMove(doer, oldRepo, issue)
+	}
+}
+
+// NotifyDeleteIssue notifies an issue deleted permanently to notifiers
+func NotifyDeleteIssue(doer *models.User, issue *models.Issue) {
+	for _, notifier := range notifiers {
+		notifier.NotifyDeleteIssue(doer, issue)
+	}
+}
+
 // NotifyCreateRepository notifies create repository to notifiers
 func NotifyCreateRepository(doer *models.User, u *models.User, repo *models.Repository) {
 	for _, notifier := range notifiers {
@@ -20,6 +20,7 @@ type (
 	issueNotificationOpts struct {
 		issue                *models.Issue
 		notificationAuthorID int64
+		opType               models.ActionType
 	}
 )
 
@@ -36,7 +37,7 @@ func NewNotifier() base.Notifier {
 
 func (ns *notificationService) Run() {
 	for opts := range ns.issueQueue {
-		if err := models.CreateOrUpdateIssueNotifications(opts.issue, opts.notificationAuthorID); err != nil {
+		if err := models.CreateOrUpdateIssueNotifications(opts.issue, opts.notificationAuthorID, opts.opType); err != nil {
 			log.Error("Was unable to create issue notification: %v", err)
 		}
 	}
@@ -47,6 +48,7 @@ func (ns *notificationService) NotifyCreateIssueComment(doer *models.User, repo
 	ns.issueQueue <- issueNotificationOpts{
 		issue,
 		doer.ID,
+		models.ActionCommentIssue,
 	}
 }
 
@@ -54,13 +56,19 @@ func (ns *notificationService) NotifyNewIssue(issue *models.Issue) {
 	ns.issueQueue <- issueNotificationOpts{
 		issue,
 		issue.Poster.ID,
+		models.ActionCreateIssue,
 	}
 }
 
 func (ns *notificationService) NotifyIssueChangeStatus(doer *models.User, issue *models.Issue, isClosed bool) {
+	opType := models.ActionReopenIssue
+	if isClosed {
+		opType = models.ActionCloseIssue
+	}
 	ns.issueQueue <- issueNotificationOpts{
 		issue,
 		doer.ID,
+		opType,
 	}
 }
 
@@ -68,13 +76,27 @@ func (ns *notificationService) NotifyMergePullRequest(pr *models.PullRequest, do
 	ns.issueQueue <- issueNotificationOpts{
 		pr.Issue,
 		doer.ID,
+		models.ActionMergePullRequest,
 	}
 }
 
 func (ns *notificationService) NotifyNewPullRequest(pr *models.PullRequest) {
+	// Participants are notified once a draft is ready for review
+	if pr.IsDraft {
+		return
+	}
 	ns.issueQueue <- issueNotificationOpts{
 		pr.Issue,
 		pr.Issue.PosterID,
+		models.ActionCreatePullRequest,
+	}
+}
+
+func (ns *notificationService) NotifyPullRequestReadyForReview(doer *models.User, pr *models.PullRequest) {
+	ns.issueQueue <- issueNotificationOpts{
+		pr.Issue,
+		doer.ID,
+		models.ActionCreatePullRequest,
 	}
 }
 
@@ -82,5 +104,12 @@ func (ns *notificationService) NotifyPullRequestReview(pr *models.PullRequest, r
 	ns.issueQueue <- issueNotificationOpts{
 		pr.Issue,
 		r.Reviewer.ID,
+		models.ActionCommentIssue,
+	}
+}
+
+func (ns *notificationService) NotifyPullRequestReviewRequest(doer *models.User, issue *models.Issue, reviewer *models.User, comment *models.Comment) {
+	if err := models.CreateOrUpdateIssueNotificationForUser(issue, reviewer.ID, doer.ID); err != nil {
+		log.Error("CreateOrUpdateIssueNotificationForUser: %v", err)
 	}
 }
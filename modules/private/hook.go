@@ -31,11 +31,14 @@ type HookOptions struct {
 	GitAlternativeObjectDirectories string
 	GitQuarantinePath               string
 	ProtectedBranchID               int64
+	IsRestrictedPush                bool
+	IsWiki                          bool
+	CheckGitQuota                   bool
 }
 
 // HookPreReceive check whether the provided commits are allowed
 func HookPreReceive(ownerName, repoName string, opts HookOptions) (int, string) {
-	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/hook/pre-receive/%s/%s?old=%s&new=%s&ref=%s&userID=%d&gitObjectDirectory=%s&gitAlternativeObjectDirectories=%s&gitQuarantinePath=%s&prID=%d",
+	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/hook/pre-receive/%s/%s?old=%s&new=%s&ref=%s&userID=%d&gitObjectDirectory=%s&gitAlternativeObjectDirectories=%s&gitQuarantinePath=%s&prID=%d&isRestrictedPush=%t&isWiki=%t&checkGitQuota=%t",
 		url.PathEscape(ownerName),
 		url.PathEscape(repoName),
 		url.QueryEscape(opts.OldCommitID),
@@ -46,6 +49,9 @@ func HookPreReceive(ownerName, repoName string, opts HookOptions) (int, string)
 		url.QueryEscape(opts.GitAlternativeObjectDirectories),
 		url.QueryEscape(opts.GitQuarantinePath),
 		opts.ProtectedBranchID,
+		opts.IsRestrictedPush,
+		opts.IsWiki,
+		opts.CheckGitQuota,
 	)
 
 	resp, err := newInternalRequest(reqURL, "GET").Response()
@@ -86,3 +92,88 @@ func HookPostReceive(ownerName, repoName string, opts HookOptions) (map[string]i
 
 	return res, ""
 }
+
+// HookProcReceiveOptions represents the references pushed to refs/for/ which
+// are handled by the proc-receive hook
+type HookProcReceiveOptions struct {
+	OldCommitIDs   []string
+	NewCommitIDs   []string
+	RefFullNames   []string
+	UserID         int64
+	GitPushOptions map[string]string
+}
+
+// HookProcReceiveRefResult represents the result of a reference handled by the proc-receive hook
+type HookProcReceiveRefResult struct {
+	// OriginalRef is the pushed reference
+	OriginalRef string
+	// Ref is the reference which has actually been updated
+	Ref         string
+	OldOID      string
+	NewOID      string
+	IsForcePush bool
+	// IsNotMatched is true if the reference is not handled by Gitea
+	IsNotMatched bool
+	Err          string
+}
+
+// HookProcReceive creates or updates the pull requests of the references pushed to refs/for/
+func HookProcReceive(ownerName, repoName string, opts HookProcReceiveOptions) ([]*HookProcReceiveRefResult, string) {
+	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/hook/proc-receive/%s/%s",
+		url.PathEscape(ownerName),
+		url.PathEscape(repoName))
+
+	body, err := json.Marshal(opts)
+	if err != nil {
+		return nil, fmt.Sprintf("Unable to encode the options: %v", err)
+	}
+	resp, err := newInternalRequest(reqURL, "POST").
+		Header("Content-Type", "application/json").
+		Body(body).
+		Response()
+	if err != nil {
+		return nil, fmt.Sprintf("Unable to contact gitea: %v", err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, decodeJSONError(resp).Err
+	}
+	var results []*HookProcReceiveRefResult
+	if err = json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, fmt.Sprintf("Unable to decode the results: %v", err)
+	}
+
+	return results, ""
+}
+
+// HookPushOptions represents the options given by the pusher with git push -o
+type HookPushOptions struct {
+	UserID         int64
+	GitPushOptions map[string]string
+}
+
+// HookApplyPushOptions changes the settings of the repository according to the push options
+func HookApplyPushOptions(ownerName, repoName string, opts HookPushOptions) string {
+	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/hook/push-options/%s/%s",
+		url.PathEscape(ownerName),
+		url.PathEscape(repoName))
+
+	body, err := json.Marshal(opts)
+	if err != nil {
+		return fmt.Sprintf("Unable to encode the options: %v", err)
+	}
+	resp, err := newInternalRequest(reqURL, "POST").
+		Header("Content-Type", "application/json").
+		Body(body).
+		Response()
+	if err != nil {
+		return fmt.Sprintf("Unable to contact gitea: %v", err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return decodeJSONError(resp).Err
+	}
+	return ""
+}
@@ -51,6 +51,10 @@ type ServCommandResults struct {
 	OwnerName   string
 	RepoName    string
 	RepoID      int64
+	// IsRestrictedPush is true if the user has no write access and can only
+	// push to the head branches of pull requests allowing edits from
+	// maintainers or create pull requests by pushing to refs/for/<branch>
+	IsRestrictedPush bool
 }
 
 // ErrServCommand is an error returned from ServCommmand.
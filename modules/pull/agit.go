@@ -0,0 +1,180 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/private"
+)
+
+// ProcReceive creates or updates the pull requests of the references pushed
+// to refs/for/<base branch>[/<topic>]. The head branch of the pull requests
+// is named after the pusher and the topic, which can also be set by the
+// topic push option.
+func ProcReceive(repo *models.Repository, gitRepo *git.Repository, pusher *models.User, opts *private.HookProcReceiveOptions) ([]*private.HookProcReceiveRefResult, error) {
+	results := make([]*private.HookProcReceiveRefResult, 0, len(opts.RefFullNames))
+	for i, refFullName := range opts.RefFullNames {
+		result := &private.HookProcReceiveRefResult{
+			OriginalRef: refFullName,
+			OldOID:      opts.OldCommitIDs[i],
+			NewOID:      opts.NewCommitIDs[i],
+		}
+		results = append(results, result)
+
+		if !strings.HasPrefix(refFullName, git.AGitPullPrefix) {
+			result.IsNotMatched = true
+			continue
+		}
+		if result.NewOID == git.EmptySHA {
+			result.Err = "pull requests can not be deleted by pushing"
+			continue
+		}
+
+		baseBranch, topic := parseAGitRef(gitRepo, strings.TrimPrefix(refFullName, git.AGitPullPrefix))
+		if !gitRepo.IsBranchExist(baseBranch) {
+			result.Err = fmt.Sprintf("base branch %s does not exist", baseBranch)
+			continue
+		}
+		if len(opts.GitPushOptions["topic"]) > 0 {
+			topic = opts.GitPushOptions["topic"]
+		}
+		if len(topic) == 0 {
+			result.Err = "topic is not set, push to refs/for/<branch>/<topic> or use the push option topic=<topic>"
+			continue
+		}
+		// Different users might use the same topic
+		headBranch := pusher.LowerName + "/" + topic
+
+		pr, err := models.GetUnmergedAGitPullRequest(repo.ID, headBranch, baseBranch)
+		if err != nil {
+			if !models.IsErrPullRequestNotExist(err) {
+				return nil, fmt.Errorf("GetUnmergedAGitPullRequest: %v", err)
+			}
+			if pr, err = createAGitPullRequest(repo, gitRepo, pusher, baseBranch, headBranch, result.NewOID, opts.GitPushOptions); err != nil {
+				return nil, err
+			}
+			result.Ref = pr.GetGitRefName()
+			result.OldOID = git.EmptySHA
+			continue
+		}
+
+		oldCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+		if err != nil {
+			return nil, fmt.Errorf("GetRefCommitID[%s]: %v", pr.GetGitRefName(), err)
+		}
+		if oldCommitID == result.NewOID {
+			result.Err = "the commit is already the head of the pull request"
+			continue
+		}
+		output, err := git.NewCommand("rev-list", "--max-count=1", oldCommitID, "^"+result.NewOID).RunInDir(repo.RepoPath())
+		if err != nil {
+			return nil, fmt.Errorf("Unable to detect force push between %s and %s: %v", oldCommitID, result.NewOID, err)
+		}
+		if len(output) > 0 {
+			if _, ok := opts.GitPushOptions["force-push"]; !ok {
+				result.Err = "the head of the pull request can only be replaced with the push option force-push"
+				continue
+			}
+			result.IsForcePush = true
+		}
+
+		if _, err = git.NewCommand("update-ref", pr.GetGitRefName(), result.NewOID, oldCommitID).RunInDir(repo.RepoPath()); err != nil {
+			return nil, fmt.Errorf("update-ref[%s]: %v", pr.GetGitRefName(), err)
+		}
+		go models.AddTestAGitPullRequestTask(pusher, pr)
+
+		result.Ref = pr.GetGitRefName()
+		result.OldOID = oldCommitID
+		log.Trace("AGit pull request updated: %d with %s", pr.ID, result.NewOID)
+	}
+	return results, nil
+}
+
+// parseAGitRef splits the pushed reference without the refs/for/ prefix into
+// the base branch and the topic. The base branch might contain slashes.
+func parseAGitRef(gitRepo *git.Repository, name string) (baseBranch, topic string) {
+	if gitRepo.IsBranchExist(name) {
+		return name, ""
+	}
+	for i, c := range name {
+		if c == '/' && i < len(name)-1 && gitRepo.IsBranchExist(name[:i]) {
+			return name[:i], name[i+1:]
+		}
+	}
+	return name, ""
+}
+
+// createAGitPullRequest creates a pull request whose head commit is only
+// referenced by the pull request reference. The title and description are
+// taken from the push options or the head commit.
+func createAGitPullRequest(repo *models.Repository, gitRepo *git.Repository, pusher *models.User, baseBranch, headBranch, commitID string, options map[string]string) (*models.PullRequest, error) {
+	title := options["title"]
+	if len(title) == 0 {
+		commit, err := gitRepo.GetCommit(commitID)
+		if err != nil {
+			return nil, fmt.Errorf("GetCommit: %v", err)
+		}
+		title = commit.Summary()
+	}
+
+	mergeBase, _, err := gitRepo.GetMergeBase("", baseBranch, commitID)
+	if err != nil {
+		return nil, fmt.Errorf("GetMergeBase: %v", err)
+	}
+	patch, err := gitRepo.GetPatch(mergeBase, commitID)
+	if err != nil {
+		return nil, fmt.Errorf("GetPatch: %v", err)
+	}
+
+	prIssue := &models.Issue{
+		RepoID:   repo.ID,
+		Title:    title,
+		PosterID: pusher.ID,
+		Poster:   pusher,
+		IsPull:   true,
+		Content:  options["description"],
+	}
+	pr := &models.PullRequest{
+		HeadRepoID:   repo.ID,
+		BaseRepoID:   repo.ID,
+		HeadUserName: repo.OwnerName,
+		HeadBranch:   headBranch,
+		BaseBranch:   baseBranch,
+		HeadRepo:     repo,
+		BaseRepo:     repo,
+		MergeBase:    mergeBase,
+		Type:         models.PullRequestGitea,
+		Flow:         models.PullRequestFlowAGit,
+	}
+	if err = models.NewPullRequest(repo, prIssue, nil, nil, pr, patch, nil); err != nil {
+		return nil, fmt.Errorf("NewPullRequest: %v", err)
+	}
+	if _, err = git.NewCommand("update-ref", pr.GetGitRefName(), commitID).RunInDir(repo.RepoPath()); err != nil {
+		return nil, fmt.Errorf("update-ref[%s]: %v", pr.GetGitRefName(), err)
+	}
+
+	notification.NotifyNewPullRequest(pr)
+	if err = RequestCodeOwnerReviews(pr, pusher); err != nil {
+		log.Error("RequestCodeOwnerReviews: %v", err)
+	}
+	if err = pr.ApplyPathLabels(pusher); err != nil {
+		log.Error("ApplyPathLabels: %v", err)
+	}
+	if err = pr.LintCommitMessages(pusher); err != nil {
+		log.Error("LintCommitMessages: %v", err)
+	}
+	if err = pr.AssignTeamReviewers(pusher); err != nil {
+		log.Error("AssignTeamReviewers: %v", err)
+	}
+
+	log.Trace("AGit pull request created: %d/%d", repo.ID, prIssue.ID)
+	return pr, nil
+}
@@ -0,0 +1,254 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+	"strconv"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+)
+
+// ScheduleAutoMerge schedules the pull request to be merged by the doer once
+// its status checks and approvals are green. It is merged immediately if
+// they already are.
+func ScheduleAutoMerge(doer *models.User, pr *models.PullRequest, mergeStyle models.MergeStyle, message string) error {
+	if err := pr.GetBaseRepo(); err != nil {
+		return fmt.Errorf("GetBaseRepo: %v", err)
+	}
+	prUnit, err := pr.BaseRepo.GetUnit(models.UnitTypePullRequests)
+	if err != nil {
+		return err
+	}
+	if !prUnit.PullRequestsConfig().IsMergeStyleAllowed(mergeStyle) {
+		return models.ErrInvalidMergeStyle{ID: pr.BaseRepoID, Style: mergeStyle}
+	}
+
+	// Approvals are checked again when the pull request is merged
+	if err = pr.LoadProtectedBranch(); err != nil {
+		return fmt.Errorf("LoadProtectedBranch: %v", err)
+	}
+	if pr.ProtectedBranch != nil && !pr.ProtectedBranch.CanUserMerge(doer.ID) {
+		return models.ErrNotAllowedToMerge{Reason: "The branch is protected"}
+	}
+
+	if _, err = models.ScheduleAutoMerge(doer, pr, mergeStyle, message); err != nil {
+		return err
+	}
+	models.AddToAutoMergeQueue(pr.ID)
+	return nil
+}
+
+// CancelAutoMerge cancels the scheduled automatic merge of the pull request
+func CancelAutoMerge(pr *models.PullRequest) error {
+	return models.RemoveScheduledAutoMerge(pr.ID)
+}
+
+// InitAutoMerge starts merging the pull requests whose scheduled automatic merge has to be checked
+func InitAutoMerge() {
+	go processAutoMerges()
+}
+
+func processAutoMerges() {
+	for id := range models.AutoMergeQueue.Queue() {
+		log.Trace("processAutoMerges[%s]: checking automatic merge", id)
+		models.AutoMergeQueue.Remove(id)
+
+		pullID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			log.Error("processAutoMerges[%s]: %v", id, err)
+			continue
+		}
+		if err = handleAutoMerge(pullID); err != nil {
+			log.Error("handleAutoMerge[%d]: %v", pullID, err)
+		}
+	}
+}
+
+// handleAutoMerge merges the pull request if it is scheduled for automatic
+// merge and its checks are green. Pull requests of branches with a merge
+// queue are added to the queue instead.
+func handleAutoMerge(pullID int64) error {
+	autoMerge, err := models.GetScheduledAutoMerge(pullID)
+	if err != nil {
+		if models.IsErrPullAutoMergeNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	pr, err := models.GetPullRequestByID(pullID)
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			return models.RemoveScheduledAutoMerge(pullID)
+		}
+		return err
+	}
+	if err = pr.LoadIssue(); err != nil {
+		return fmt.Errorf("LoadIssue: %v", err)
+	}
+	if pr.HasMerged || pr.Issue.IsClosed {
+		return models.RemoveScheduledAutoMerge(pullID)
+	}
+
+	if err = autoMerge.LoadDoer(); err != nil {
+		if models.IsErrUserNotExist(err) {
+			return models.RemoveScheduledAutoMerge(pullID)
+		}
+		return fmt.Errorf("LoadDoer: %v", err)
+	}
+
+	reason, err := checkAutoMerge(pr, autoMerge)
+	if err != nil {
+		return err
+	} else if len(reason) > 0 {
+		log.Info("Scheduled automatic merge of pull request %d cancelled: %s", pullID, reason)
+		return models.RemoveScheduledAutoMerge(pullID)
+	}
+
+	ready, err := isReadyForAutoMerge(pr)
+	if err != nil || !ready {
+		return err
+	}
+
+	if pr.ProtectedBranch != nil && pr.ProtectedBranch.EnableMergeQueue {
+		if _, err = AddToMergeQueue(pr, autoMerge.Doer, autoMerge.MergeStyle, autoMerge.Message); err != nil && !models.IsErrMergeQueueEntryAlreadyExist(err) {
+			log.Info("Scheduled automatic merge of pull request %d failed: %v", pullID, err)
+		}
+		return models.RemoveScheduledAutoMerge(pullID)
+	}
+
+	baseGitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+	message := autoMerge.Message
+	if len(message) == 0 {
+		if autoMerge.MergeStyle == models.MergeStyleSquash {
+			message = pr.GetDefaultSquashMessage()
+		} else {
+			message = pr.GetDefaultMergeMessage()
+		}
+	}
+	pr.Issue.Repo = pr.BaseRepo
+
+	// The scheduled merge is removed even if the merge fails, otherwise it would be retried on every check
+	if err = models.RemoveScheduledAutoMerge(pullID); err != nil {
+		return err
+	}
+	if err = Merge(pr, autoMerge.Doer, baseGitRepo, autoMerge.MergeStyle, message); err != nil {
+		log.Info("Scheduled automatic merge of pull request %d failed: %v", pullID, err)
+		return nil
+	}
+	notification.NotifyMergePullRequest(pr, autoMerge.Doer, baseGitRepo)
+
+	log.Trace("Pull request merged automatically: %d", pr.ID)
+	return nil
+}
+
+// checkAutoMerge returns the reason why the scheduled automatic merge of the
+// pull request has to be cancelled: the doer may have lost the right to merge
+// since it was scheduled, and failed check runs won't turn green by waiting.
+func checkAutoMerge(pr *models.PullRequest, autoMerge *models.PullAutoMerge) (string, error) {
+	if err := pr.GetBaseRepo(); err != nil {
+		return "", fmt.Errorf("GetBaseRepo: %v", err)
+	}
+	perm, err := models.GetUserRepoPermission(pr.BaseRepo, autoMerge.Doer)
+	if err != nil {
+		return "", fmt.Errorf("GetUserRepoPermission: %v", err)
+	}
+	if !perm.CanWrite(models.UnitTypePullRequests) {
+		return "user is not allowed to merge", nil
+	}
+	prUnit, err := pr.BaseRepo.GetUnit(models.UnitTypePullRequests)
+	if err != nil {
+		return "", fmt.Errorf("GetUnit: %v", err)
+	}
+	if !prUnit.PullRequestsConfig().IsMergeStyleAllowed(autoMerge.MergeStyle) {
+		return "merge style is not allowed", nil
+	}
+	if err = pr.CheckUserAllowedToMerge(autoMerge.Doer); err != nil {
+		if models.IsErrNotAllowedToMerge(err) {
+			return "user is not allowed to merge", nil
+		}
+		return "", fmt.Errorf("CheckUserAllowedToMerge: %v", err)
+	}
+
+	runs, err := pr.GetLatestCheckRuns()
+	if err != nil {
+		return "", fmt.Errorf("GetLatestCheckRuns: %v", err)
+	}
+	for _, run := range runs {
+		if run.IsFailed() {
+			return fmt.Sprintf("check run %s has failed", run.Name), nil
+		}
+	}
+	return "", nil
+}
+
+// isReadyForAutoMerge returns true if the pull request is mergeable and its
+// approvals and status checks are green.
+func isReadyForAutoMerge(pr *models.PullRequest) (bool, error) {
+	if !pr.CanAutoMerge() || pr.IsDraft || pr.IsWorkInProgress() {
+		return false, nil
+	}
+	if err := pr.GetBaseRepo(); err != nil {
+		return false, fmt.Errorf("GetBaseRepo: %v", err)
+	}
+
+	noDeps, err := models.IssueNoDependenciesLeft(pr.Issue)
+	if err != nil || !noDeps {
+		return false, err
+	}
+	if err = pr.CheckSizeLimits(); err != nil {
+		if models.IsErrPullRequestSizeExceeded(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("CheckSizeLimits: %v", err)
+	}
+
+	if err = pr.LoadProtectedBranch(); err != nil {
+		return false, fmt.Errorf("LoadProtectedBranch: %v", err)
+	}
+	if pr.ProtectedBranch != nil {
+		if !pr.ProtectedBranch.HasEnoughApprovals(pr) {
+			return false, nil
+		}
+		missing, err := pr.ProtectedBranch.GetMissingStatusCheckContexts(pr)
+		if err != nil || len(missing) > 0 {
+			return false, err
+		}
+		unresolved, err := pr.ProtectedBranch.GetUnresolvedConversationsCount(pr)
+		if err != nil || unresolved > 0 {
+			return false, err
+		}
+		if !pr.ProtectedBranch.EnableMergeQueue {
+			behind, err := pr.ProtectedBranch.GetOutdatedCommitsCount(pr)
+			if err != nil || behind > 0 {
+				return false, err
+			}
+		}
+	}
+
+	// Wait for the check runs which have not completed yet
+	runs, err := pr.GetLatestCheckRuns()
+	if err != nil {
+		return false, fmt.Errorf("GetLatestCheckRuns: %v", err)
+	}
+	for _, run := range runs {
+		if run.Status != models.CheckRunCompleted {
+			return false, nil
+		}
+	}
+
+	status, err := pr.GetLastCommitStatus()
+	if err != nil {
+		return false, fmt.Errorf("GetLastCommitStatus: %v", err)
+	}
+	return status == nil || status.State == models.CommitStatusSuccess || status.State == models.CommitStatusWarning, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+)
+
+// RequestCodeOwnerReviews requests reviews from the owners of the files
+// changed by the pull request according to the CODEOWNERS file of the base
+// branch. Teams are requested by requesting all of their members.
+func RequestCodeOwnerReviews(pr *models.PullRequest, doer *models.User) error {
+	rules, err := pr.GetCodeOwnerRules()
+	if err != nil {
+		return fmt.Errorf("GetCodeOwnerRules: %v", err)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	if err = pr.LoadIssue(); err != nil {
+		return fmt.Errorf("LoadIssue: %v", err)
+	}
+	if err = pr.GetBaseRepo(); err != nil {
+		return fmt.Errorf("GetBaseRepo: %v", err)
+	}
+	pr.Issue.Repo = pr.BaseRepo
+
+	reviewers := make([]*models.User, 0, 10)
+	seen := make(map[int64]bool)
+	addReviewer := func(user *models.User) {
+		if !seen[user.ID] && user.ID != pr.Issue.PosterID {
+			seen[user.ID] = true
+			reviewers = append(reviewers, user)
+		}
+	}
+	for _, rule := range rules {
+		for _, user := range rule.Users {
+			addReviewer(user)
+		}
+		for _, team := range rule.Teams {
+			if err = team.GetMembers(); err != nil {
+				return fmt.Errorf("GetMembers: %v", err)
+			}
+			for _, member := range team.Members {
+				addReviewer(member)
+			}
+		}
+	}
+
+	for _, reviewer := range reviewers {
+		perm, err := models.GetUserRepoPermission(pr.BaseRepo, reviewer)
+		if err != nil {
+			return fmt.Errorf("GetUserRepoPermission: %v", err)
+		}
+		if !perm.CanRead(models.UnitTypePullRequests) {
+			continue
+		}
+		if _, err = models.AddReviewRequest(pr.Issue, reviewer, doer); err != nil {
+			return fmt.Errorf("AddReviewRequest: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/repofiles"
+)
+
+// loadConflictRepos loads the head and base repository of the pull request
+func loadConflictRepos(pr *models.PullRequest) error {
+	if err := pr.GetHeadRepo(); err != nil {
+		return fmt.Errorf("GetHeadRepo: %v", err)
+	} else if pr.HeadRepo == nil {
+		return models.ErrRepoNotExist{ID: pr.HeadRepoID}
+	}
+	if err := pr.GetBaseRepo(); err != nil {
+		return fmt.Errorf("GetBaseRepo: %v", err)
+	}
+	return nil
+}
+
+// GetConflicts returns the commit ID of the head branch of the pull request
+// and the files whose changes conflict with the base branch
+func GetConflicts(pr *models.PullRequest) (string, []*repofiles.ConflictFile, error) {
+	if err := loadConflictRepos(pr); err != nil {
+		return "", nil, err
+	}
+	return repofiles.GetMergeConflicts(pr.HeadRepo, pr.HeadBranch, pr.BaseRepo, pr.BaseBranch)
+}
+
+// ResolveConflicts merges the base branch into the head branch of the pull
+// request using the resolved content of the conflicting files. The conflicts
+// must have been resolved against the head commit lastCommitID.
+func ResolveConflicts(pr *models.PullRequest, doer *models.User, lastCommitID, message string, files map[string]string) error {
+	if err := loadConflictRepos(pr); err != nil {
+		return err
+	}
+	_, err := repofiles.ResolveMergeConflicts(pr.HeadRepo, doer, &repofiles.ResolveMergeConflictsOptions{
+		LastCommitID: lastCommitID,
+		Branch:       pr.HeadBranch,
+		BaseRepo:     pr.BaseRepo,
+		BaseBranch:   pr.BaseBranch,
+		Message:      message,
+		Files:        files,
+	})
+	return err
+}
+
+// GetDefaultConflictResolutionMessage returns the default message of the
+// merge commit resolving the conflicts of the pull request
+func GetDefaultConflictResolutionMessage(pr *models.PullRequest) string {
+	if pr.BaseRepoID == pr.HeadRepoID {
+		return fmt.Sprintf("Merge branch '%s' into %s", pr.BaseBranch, pr.HeadBranch)
+	}
+	return fmt.Sprintf("Merge branch '%s' of %s into %s", pr.BaseBranch, pr.BaseRepo.FullName(), pr.HeadBranch)
+}
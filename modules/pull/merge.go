@@ -43,6 +43,36 @@ func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repositor
 		return fmt.Errorf("CheckUserAllowedToMerge: %v", err)
 	}
 
+	if err = pr.LoadProtectedBranch(); err != nil {
+		return fmt.Errorf("LoadProtectedBranch: %v", err)
+	}
+	if pr.ProtectedBranch != nil {
+		missing, err := pr.ProtectedBranch.GetMissingStatusCheckContexts(pr)
+		if err != nil {
+			return fmt.Errorf("GetMissingStatusCheckContexts: %v", err)
+		} else if len(missing) > 0 {
+			return models.ErrRequiredStatusChecksMissing{Contexts: missing}
+		}
+		unresolved, err := pr.ProtectedBranch.GetUnresolvedConversationsCount(pr)
+		if err != nil {
+			return fmt.Errorf("GetUnresolvedConversationsCount: %v", err)
+		} else if unresolved > 0 {
+			return models.ErrUnresolvedConversations{Count: unresolved}
+		}
+		// The merge queue merges the pull requests on top of each other
+		if !pr.ProtectedBranch.EnableMergeQueue {
+			behind, err := pr.ProtectedBranch.GetOutdatedCommitsCount(pr)
+			if err != nil {
+				return fmt.Errorf("GetOutdatedCommitsCount: %v", err)
+			} else if behind > 0 {
+				return models.ErrPullRequestOutOfDate{Behind: behind}
+			}
+		}
+	}
+	if err = pr.CheckSizeLimits(); err != nil {
+		return err
+	}
+
 	// Check if merge style is correct and allowed
 	if !prConfig.IsMergeStyleAllowed(mergeStyle) {
 		return models.ErrInvalidMergeStyle{ID: pr.BaseRepo.ID, Style: mergeStyle}
@@ -52,13 +82,49 @@ func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repositor
 		go models.AddTestPullRequestTask(doer, pr.BaseRepo.ID, pr.BaseBranch, false)
 	}()
 
+	tmpBasePath, err := rawMerge(pr, doer, baseGitRepo, mergeStyle, message, "")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := models.RemoveTemporaryPath(tmpBasePath); err != nil {
+			log.Error("Merge: RemoveTemporaryPath: %s", err)
+		}
+	}()
+
+	env, err := mergePushingEnvironment(pr, doer)
+	if err != nil {
+		return err
+	}
+
+	// Push back to upstream.
+	var errbuf strings.Builder
+	if err := git.NewCommand("push", "origin", pr.BaseBranch).RunInDirTimeoutEnvPipeline(env, -1, tmpBasePath, nil, &errbuf); err != nil {
+		return fmt.Errorf("git push: %s", errbuf.String())
+	}
+
+	mergedCommitID, err := baseGitRepo.GetBranchCommitID(pr.BaseBranch)
+	if err != nil {
+		return fmt.Errorf("GetBranchCommit: %v", err)
+	}
+	return recordMerge(pr, doer, mergedCommitID)
+}
+
+// rawMerge merges the pull request into the base branch in a temporary
+// repository, whose HEAD is the merge. The pull request is merged on top of
+// baseCommitID instead of the base branch if it is not empty. The caller has
+// to remove the temporary repository.
+func rawMerge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repository, mergeStyle models.MergeStyle, message, baseCommitID string) (_ string, err error) {
 	// Clone base repo.
 	tmpBasePath, err := models.CreateTemporaryPath("merge")
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	defer func() {
+		if err == nil {
+			return
+		}
 		if err := models.RemoveTemporaryPath(tmpBasePath); err != nil {
 			log.Error("Merge: RemoveTemporaryPath: %s", err)
 		}
@@ -71,7 +137,14 @@ func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repositor
 		NoCheckout: true,
 		Branch:     pr.BaseBranch,
 	}); err != nil {
-		return fmt.Errorf("git clone: %v", err)
+		return "", fmt.Errorf("git clone: %v", err)
+	}
+
+	// Merge on top of the given commit instead of the head of the base branch
+	if len(baseCommitID) > 0 {
+		if _, err := git.NewCommand("update-ref", git.BranchPrefix+pr.BaseBranch, baseCommitID).RunInDir(tmpBasePath); err != nil {
+			return "", fmt.Errorf("git update-ref [%s -> %s]: %v", pr.BaseBranch, baseCommitID, err)
+		}
 	}
 
 	remoteRepoName := "head_repo"
@@ -92,133 +165,168 @@ func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repositor
 	}
 
 	if err := addCacheRepo(tmpBasePath, headRepoPath); err != nil {
-		return fmt.Errorf("addCacheRepo [%s -> %s]: %v", headRepoPath, tmpBasePath, err)
+		return "", fmt.Errorf("addCacheRepo [%s -> %s]: %v", headRepoPath, tmpBasePath, err)
 	}
 
 	var errbuf strings.Builder
 	if err := git.NewCommand("remote", "add", remoteRepoName, headRepoPath).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-		return fmt.Errorf("git remote add [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
+		return "", fmt.Errorf("git remote add [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
 	}
 
+	trackingBranch := path.Join(remoteRepoName, pr.HeadBranch)
+
 	// Fetch head branch
-	if err := git.NewCommand("fetch", remoteRepoName, pr.HeadBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-		return fmt.Errorf("git fetch [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
+	if err := git.NewCommand("fetch", remoteRepoName, pr.GetHeadRef()+":refs/remotes/"+trackingBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+		return "", fmt.Errorf("git fetch [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
 	}
 
-	trackingBranch := path.Join(remoteRepoName, pr.HeadBranch)
 	stagingBranch := fmt.Sprintf("%s_%s", remoteRepoName, pr.HeadBranch)
 
 	// Enable sparse-checkout
 	sparseCheckoutList, err := getDiffTree(tmpBasePath, pr.BaseBranch, trackingBranch)
 	if err != nil {
-		return fmt.Errorf("getDiffTree: %v", err)
+		return "", fmt.Errorf("getDiffTree: %v", err)
 	}
 
 	infoPath := filepath.Join(tmpBasePath, ".git", "info")
 	if err := os.MkdirAll(infoPath, 0700); err != nil {
-		return fmt.Errorf("creating directory failed [%s]: %v", infoPath, err)
+		return "", fmt.Errorf("creating directory failed [%s]: %v", infoPath, err)
 	}
 	sparseCheckoutListPath := filepath.Join(infoPath, "sparse-checkout")
 	if err := ioutil.WriteFile(sparseCheckoutListPath, []byte(sparseCheckoutList), 0600); err != nil {
-		return fmt.Errorf("Writing sparse-checkout file to %s: %v", sparseCheckoutListPath, err)
+		return "", fmt.Errorf("Writing sparse-checkout file to %s: %v", sparseCheckoutListPath, err)
 	}
 
 	// Switch off LFS process (set required, clean and smudge here also)
 	if err := git.NewCommand("config", "--local", "filter.lfs.process", "").RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-		return fmt.Errorf("git config [filter.lfs.process -> <> ]: %v", errbuf.String())
+		return "", fmt.Errorf("git config [filter.lfs.process -> <> ]: %v", errbuf.String())
 	}
 	if err := git.NewCommand("config", "--local", "filter.lfs.required", "false").RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-		return fmt.Errorf("git config [filter.lfs.required -> <false> ]: %v", errbuf.String())
+		return "", fmt.Errorf("git config [filter.lfs.required -> <false> ]: %v", errbuf.String())
 	}
 	if err := git.NewCommand("config", "--local", "filter.lfs.clean", "").RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-		return fmt.Errorf("git config [filter.lfs.clean -> <> ]: %v", errbuf.String())
+		return "", fmt.Errorf("git config [filter.lfs.clean -> <> ]: %v", errbuf.String())
 	}
 	if err := git.NewCommand("config", "--local", "filter.lfs.smudge", "").RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-		return fmt.Errorf("git config [filter.lfs.smudge -> <> ]: %v", errbuf.String())
+		return "", fmt.Errorf("git config [filter.lfs.smudge -> <> ]: %v", errbuf.String())
 	}
 
 	if err := git.NewCommand("config", "--local", "core.sparseCheckout", "true").RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-		return fmt.Errorf("git config [core.sparsecheckout -> true]: %v", errbuf.String())
+		return "", fmt.Errorf("git config [core.sparsecheckout -> true]: %v", errbuf.String())
 	}
 
 	// Read base branch index
 	if err := git.NewCommand("read-tree", "HEAD").RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-		return fmt.Errorf("git read-tree HEAD: %s", errbuf.String())
+		return "", fmt.Errorf("git read-tree HEAD: %s", errbuf.String())
+	}
+
+	// Sign the created commits with the key of Gitea if required
+	signArg := "--no-gpg-sign"
+	signingKey, err := pr.SignMerge()
+	if err != nil {
+		return "", fmt.Errorf("SignMerge: %v", err)
+	}
+	if len(signingKey) > 0 {
+		signArg = "-S" + signingKey
+		if len(setting.Repository.Signing.SigningName) > 0 && len(setting.Repository.Signing.SigningEmail) > 0 {
+			if err := git.NewCommand("config", "--local", "user.name", setting.Repository.Signing.SigningName).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+				return "", fmt.Errorf("git config [user.name]: %s", errbuf.String())
+			}
+			if err := git.NewCommand("config", "--local", "user.email", setting.Repository.Signing.SigningEmail).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+				return "", fmt.Errorf("git config [user.email]: %s", errbuf.String())
+			}
+		}
 	}
 
 	// Merge commits.
 	switch mergeStyle {
 	case models.MergeStyleMerge:
+		errbuf.Reset()
 		if err := git.NewCommand("merge", "--no-ff", "--no-commit", trackingBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git merge --no-ff --no-commit [%s]: %v - %s", tmpBasePath, err, errbuf.String())
+			return "", models.ErrMergeConflicts{Style: mergeStyle, StdErr: errbuf.String()}
 		}
 
 		sig := doer.NewGitSig()
-		if err := git.NewCommand("commit", fmt.Sprintf("--author='%s <%s>'", sig.Name, sig.Email), "-m", message).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git commit [%s]: %v - %s", tmpBasePath, err, errbuf.String())
+		if err := git.NewCommand("commit", fmt.Sprintf("--author='%s <%s>'", sig.Name, sig.Email), signArg, "-m", message).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+			return "", fmt.Errorf("git commit [%s]: %v - %s", tmpBasePath, err, errbuf.String())
 		}
 	case models.MergeStyleRebase:
 		// Checkout head branch
 		if err := git.NewCommand("checkout", "-b", stagingBranch, trackingBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git checkout: %s", errbuf.String())
+			return "", fmt.Errorf("git checkout: %s", errbuf.String())
 		}
 		// Rebase before merging
+		errbuf.Reset()
 		if err := git.NewCommand("rebase", "-q", pr.BaseBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git rebase [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
+			return "", models.ErrRebaseConflicts{Style: mergeStyle, StdErr: errbuf.String()}
 		}
 		// Checkout base branch again
 		if err := git.NewCommand("checkout", pr.BaseBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git checkout: %s", errbuf.String())
+			return "", fmt.Errorf("git checkout: %s", errbuf.String())
 		}
 		// Merge fast forward
 		if err := git.NewCommand("merge", "--ff-only", "-q", stagingBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git merge --ff-only [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
+			return "", fmt.Errorf("git merge --ff-only [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
 		}
 	case models.MergeStyleRebaseMerge:
 		// Checkout head branch
 		if err := git.NewCommand("checkout", "-b", stagingBranch, trackingBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git checkout: %s", errbuf.String())
+			return "", fmt.Errorf("git checkout: %s", errbuf.String())
 		}
 		// Rebase before merging
+		errbuf.Reset()
 		if err := git.NewCommand("rebase", "-q", pr.BaseBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git rebase [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
+			return "", models.ErrRebaseConflicts{Style: mergeStyle, StdErr: errbuf.String()}
 		}
 		// Checkout base branch again
 		if err := git.NewCommand("checkout", pr.BaseBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git checkout: %s", errbuf.String())
+			return "", fmt.Errorf("git checkout: %s", errbuf.String())
 		}
 		// Prepare merge with commit
 		if err := git.NewCommand("merge", "--no-ff", "--no-commit", "-q", stagingBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git merge --no-ff [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
+			return "", fmt.Errorf("git merge --no-ff [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
 		}
 
 		// Set custom message and author and create merge commit
 		sig := doer.NewGitSig()
-		if err := git.NewCommand("commit", fmt.Sprintf("--author='%s <%s>'", sig.Name, sig.Email), "-m", message).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git commit [%s]: %v - %s", tmpBasePath, err, errbuf.String())
+		if err := git.NewCommand("commit", fmt.Sprintf("--author='%s <%s>'", sig.Name, sig.Email), signArg, "-m", message).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+			return "", fmt.Errorf("git commit [%s]: %v - %s", tmpBasePath, err, errbuf.String())
 		}
 
 	case models.MergeStyleSquash:
 		// Merge with squash
+		errbuf.Reset()
 		if err := git.NewCommand("merge", "-q", "--squash", trackingBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git merge --squash [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
+			return "", models.ErrMergeConflicts{Style: mergeStyle, StdErr: errbuf.String()}
 		}
 		sig := pr.Issue.Poster.NewGitSig()
-		if err := git.NewCommand("commit", fmt.Sprintf("--author='%s <%s>'", sig.Name, sig.Email), "-m", message).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
-			return fmt.Errorf("git commit [%s]: %v - %s", tmpBasePath, err, errbuf.String())
+		if err := git.NewCommand("commit", fmt.Sprintf("--author='%s <%s>'", sig.Name, sig.Email), signArg, "-m", message).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+			return "", fmt.Errorf("git commit [%s]: %v - %s", tmpBasePath, err, errbuf.String())
+		}
+	case models.MergeStyleFastForwardOnly:
+		// The base branch has to be an ancestor of the head branch
+		errbuf.Reset()
+		if err := git.NewCommand("merge-base", "--is-ancestor", pr.BaseBranch, trackingBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+			if errbuf.Len() > 0 {
+				return "", fmt.Errorf("git merge-base --is-ancestor [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
+			}
+			return "", models.ErrMergeNotFastForward{BaseBranch: pr.BaseBranch, HeadBranch: pr.HeadBranch}
+		}
+		if err := git.NewCommand("merge", "--ff-only", "-q", trackingBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+			return "", fmt.Errorf("git merge --ff-only [%s -> %s]: %s", headRepoPath, tmpBasePath, errbuf.String())
 		}
 	default:
-		return models.ErrInvalidMergeStyle{ID: pr.BaseRepo.ID, Style: mergeStyle}
+		return "", models.ErrInvalidMergeStyle{ID: pr.BaseRepo.ID, Style: mergeStyle}
 	}
 
 	// OK we should cache our current head and origin/headbranch
 	mergeHeadSHA, err := git.GetFullCommitID(tmpBasePath, "HEAD")
 	if err != nil {
-		return fmt.Errorf("Failed to get full commit id for HEAD: %v", err)
+		return "", fmt.Errorf("Failed to get full commit id for HEAD: %v", err)
 	}
 	mergeBaseSHA, err := git.GetFullCommitID(tmpBasePath, "origin/"+pr.BaseBranch)
 	if err != nil {
-		return fmt.Errorf("Failed to get full commit id for origin/%s: %v", pr.BaseBranch, err)
+		return "", fmt.Errorf("Failed to get full commit id for origin/%s: %v", pr.BaseBranch, err)
 	}
 
 	// Now it's questionable about where this should go - either after or before the push
@@ -226,38 +334,38 @@ func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repositor
 	// the merge as you can always remerge.
 	if setting.LFS.StartServer {
 		if err := LFSPush(tmpBasePath, mergeHeadSHA, mergeBaseSHA, pr); err != nil {
-			return err
+			return "", err
 		}
 	}
+	return tmpBasePath, nil
+}
 
+// mergePushingEnvironment returns the environment to push the merge of the
+// pull request to the base repository
+func mergePushingEnvironment(pr *models.PullRequest, doer *models.User) ([]string, error) {
 	headUser, err := models.GetUserByName(pr.HeadUserName)
 	if err != nil {
 		if !models.IsErrUserNotExist(err) {
 			log.Error("Can't find user: %s for head repository - %v", pr.HeadUserName, err)
-			return err
+			return nil, err
 		}
 		log.Error("Can't find user: %s for head repository - defaulting to doer: %s - %v", pr.HeadUserName, doer.Name, err)
 		headUser = doer
 	}
 
-	env := models.FullPushingEnvironment(
+	return models.FullPushingEnvironment(
 		headUser,
 		doer,
 		pr.BaseRepo,
 		pr.BaseRepo.Name,
 		pr.ID,
-	)
-
-	// Push back to upstream.
-	if err := git.NewCommand("push", "origin", pr.BaseBranch).RunInDirTimeoutEnvPipeline(env, -1, tmpBasePath, nil, &errbuf); err != nil {
-		return fmt.Errorf("git push: %s", errbuf.String())
-	}
-
-	pr.MergedCommitID, err = baseGitRepo.GetBranchCommitID(pr.BaseBranch)
-	if err != nil {
-		return fmt.Errorf("GetBranchCommit: %v", err)
-	}
+	), nil
+}
 
+// recordMerge marks the pull request as merged by the merged commit and
+// notifies about the merge
+func recordMerge(pr *models.PullRequest, doer *models.User, mergedCommitID string) (err error) {
+	pr.MergedCommitID = mergedCommitID
 	pr.MergedUnix = timeutil.TimeStampNow()
 	pr.Merger = doer
 	pr.MergerID = doer.ID
@@ -270,6 +378,14 @@ func Merge(pr *models.PullRequest, doer *models.User, baseGitRepo *git.Repositor
 		log.Error("MergePullRequestAction [%d]: %v", pr.ID, err)
 	}
 
+	if err = models.UpdateIssuesPullRequest(doer, pr); err != nil {
+		log.Error("UpdateIssuesPullRequest [%d]: %v", pr.ID, err)
+	}
+
+	if err = RetargetStackedPullRequests(pr, doer); err != nil {
+		log.Error("RetargetStackedPullRequests [%d]: %v", pr.ID, err)
+	}
+
 	// Reset cached commit count
 	cache.Remove(pr.Issue.Repo.GetCommitsCountCacheKey(pr.BaseBranch, true))
 
@@ -0,0 +1,422 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/sync"
+)
+
+// mergeQueueRetryInterval is the time after which a merge queue waiting for
+// the checks of its first pull request is processed again
+const mergeQueueRetryInterval = 30 * time.Second
+
+// mergeQueue contains the merge queues to process identified by "<repo id>/<branch>"
+var mergeQueue = sync.NewUniqueQueue(setting.Repository.PullRequestQueueLength)
+
+// AddToMergeQueue adds the pull request to the merge queue of its base
+// branch, which has to be enabled by the branch protection.
+func AddToMergeQueue(pr *models.PullRequest, doer *models.User, mergeStyle models.MergeStyle, message string) (*models.MergeQueueEntry, error) {
+	if err := pr.GetBaseRepo(); err != nil {
+		return nil, fmt.Errorf("GetBaseRepo: %v", err)
+	}
+	protectBranch, err := models.GetProtectedBranchBy(pr.BaseRepoID, pr.BaseBranch)
+	if err != nil {
+		return nil, fmt.Errorf("GetProtectedBranchBy: %v", err)
+	} else if protectBranch == nil || !protectBranch.EnableMergeQueue {
+		return nil, models.ErrMergeQueueDisabled{RepoID: pr.BaseRepoID, BaseBranch: pr.BaseBranch}
+	}
+
+	prUnit, err := pr.BaseRepo.GetUnit(models.UnitTypePullRequests)
+	if err != nil {
+		return nil, err
+	}
+	if !prUnit.PullRequestsConfig().IsMergeStyleAllowed(mergeStyle) {
+		return nil, models.ErrInvalidMergeStyle{ID: pr.BaseRepoID, Style: mergeStyle}
+	}
+	if err := pr.CheckUserAllowedToMerge(doer); err != nil {
+		return nil, err
+	}
+
+	entry, err := models.AddToMergeQueue(pr, doer, mergeStyle, message)
+	if err != nil {
+		return nil, err
+	}
+	TriggerMergeQueue(pr.BaseRepoID, pr.BaseBranch)
+	return entry, nil
+}
+
+// RemoveFromMergeQueue removes the pull request from the merge queue of its
+// base branch and deletes its test merge, the test merges of the following
+// pull requests are rebuilt.
+func RemoveFromMergeQueue(pr *models.PullRequest) error {
+	entry, err := models.GetMergeQueueEntryByPullID(pr.ID)
+	if err != nil {
+		return err
+	}
+	if err = pr.GetBaseRepo(); err != nil {
+		return fmt.Errorf("GetBaseRepo: %v", err)
+	}
+	baseGitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+	if err = removeMergeQueueEntry(baseGitRepo, entry); err != nil {
+		return err
+	}
+	TriggerMergeQueue(entry.RepoID, entry.BaseBranch)
+	return nil
+}
+
+// TriggerMergeQueue processes the merge queue of the branch
+func TriggerMergeQueue(repoID int64, branch string) {
+	go mergeQueue.Add(fmt.Sprintf("%d/%s", repoID, branch))
+}
+
+// InitMergeQueue starts processing the merge queues and triggers every merge
+// queue which has entries.
+func InitMergeQueue() {
+	go processMergeQueues()
+
+	queues, err := models.GetNonEmptyMergeQueues()
+	if err != nil {
+		log.Error("GetNonEmptyMergeQueues: %v", err)
+		return
+	}
+	for _, queue := range queues {
+		TriggerMergeQueue(queue.RepoID, queue.BaseBranch)
+	}
+}
+
+func processMergeQueues() {
+	for key := range mergeQueue.Queue() {
+		log.Trace("processMergeQueues[%s]: processing merge queue", key)
+		mergeQueue.Remove(key)
+
+		i := strings.Index(key, "/")
+		repoID, err := strconv.ParseInt(key[:i], 10, 64)
+		if err != nil {
+			log.Error("processMergeQueues[%s]: %v", key, err)
+			continue
+		}
+		if err = processMergeQueue(repoID, key[i+1:]); err != nil {
+			log.Error("processMergeQueue[%s]: %v", key, err)
+		}
+	}
+}
+
+// processMergeQueue merges the pull requests of the merge queue in order.
+// Every pull request is merged on top of the base branch and the pull requests
+// ahead of it in a test merge, which is pushed to a test branch to be checked.
+// The base branch is fast-forwarded to the test merge of the first pull
+// request once it has passed the required checks. Pull requests which can not
+// be merged are removed from the queue.
+func processMergeQueue(repoID int64, branch string) error {
+	repo, err := models.GetRepositoryByID(repoID)
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("GetRepositoryByID: %v", err)
+	}
+	baseGitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+
+	for {
+		entry, wait, err := updateTestMerges(repo, baseGitRepo, branch)
+		if err != nil {
+			return err
+		} else if entry == nil {
+			return nil
+		}
+		var reason string
+		if !wait {
+			if wait, reason, err = checkTestMerge(repo, entry); err != nil {
+				return err
+			}
+		}
+		if wait {
+			time.AfterFunc(mergeQueueRetryInterval, func() {
+				TriggerMergeQueue(repoID, branch)
+			})
+			return nil
+		}
+
+		if len(reason) == 0 {
+			if err = mergeTestMerge(baseGitRepo, entry); err != nil {
+				reason = err.Error()
+			}
+		}
+		if len(reason) > 0 {
+			log.Info("Pull request %d removed from the merge queue: %s", entry.PullID, reason)
+		}
+		if err = removeMergeQueueEntry(baseGitRepo, entry); err != nil {
+			return err
+		}
+	}
+}
+
+// updateTestMerges builds the missing and outdated test merges of the merge
+// queue in order and returns the first entry, nil if the queue is empty. It
+// has to wait if the pull request is still being checked for conflicts. The
+// entries which can not be merged are removed from the queue.
+func updateTestMerges(repo *models.Repository, baseGitRepo *git.Repository, branch string) (*models.MergeQueueEntry, bool, error) {
+	entries, err := models.GetMergeQueue(repo.ID, branch)
+	if err != nil {
+		return nil, false, fmt.Errorf("GetMergeQueue: %v", err)
+	}
+	baseCommitID, err := baseGitRepo.GetBranchCommitID(branch)
+	if err != nil {
+		return nil, false, fmt.Errorf("GetBranchCommitID: %v", err)
+	}
+
+	var first *models.MergeQueueEntry
+	for _, entry := range entries {
+		if err = entry.LoadAttributes(); err != nil && !models.IsErrPullRequestNotExist(err) {
+			return nil, false, fmt.Errorf("LoadAttributes: %v", err)
+		}
+		var reason string
+		wait := false
+		if err != nil {
+			reason = "pull request does not exist"
+		} else if wait, reason, err = checkMergeQueueEntry(entry); err != nil {
+			return nil, false, err
+		}
+		if wait {
+			// The test merges of the following entries depend on this one
+			if first == nil {
+				return entry, true, nil
+			}
+			return first, false, nil
+		}
+
+		if len(reason) == 0 {
+			if err = updateTestMerge(baseGitRepo, entry, baseCommitID); err != nil {
+				reason = err.Error()
+			}
+		}
+		if len(reason) > 0 {
+			log.Info("Pull request %d removed from the merge queue: %s", entry.PullID, reason)
+			if err = removeMergeQueueEntry(baseGitRepo, entry); err != nil {
+				return nil, false, err
+			}
+			continue
+		}
+
+		if first == nil {
+			first = entry
+		}
+		baseCommitID = entry.MergeCommitID
+	}
+	return first, false, nil
+}
+
+// checkMergeQueueEntry returns if the pull request has to wait for the check
+// for conflicts or the reason why it can not be merged.
+func checkMergeQueueEntry(entry *models.MergeQueueEntry) (bool, string, error) {
+	pr := entry.PullRequest
+	if err := pr.LoadIssue(); err != nil {
+		return false, "", fmt.Errorf("LoadIssue: %v", err)
+	}
+	if pr.HasMerged || pr.Issue.IsClosed {
+		return false, "pull request has been closed", nil
+	}
+	if err := pr.LoadProtectedBranch(); err != nil {
+		return false, "", fmt.Errorf("LoadProtectedBranch: %v", err)
+	}
+	if pr.ProtectedBranch == nil || !pr.ProtectedBranch.EnableMergeQueue || pr.BaseBranch != entry.BaseBranch {
+		return false, "merge queue has been disabled", nil
+	}
+	if pr.IsDraft || pr.IsWorkInProgress() {
+		return false, "pull request is not ready for review", nil
+	}
+	if pr.IsChecking() {
+		return true, "", nil
+	} else if !pr.CanAutoMerge() {
+		return false, "pull request has conflicts", nil
+	}
+
+	if err := pr.GetBaseRepo(); err != nil {
+		return false, "", fmt.Errorf("GetBaseRepo: %v", err)
+	}
+	prUnit, err := pr.BaseRepo.GetUnit(models.UnitTypePullRequests)
+	if err != nil {
+		return false, "", fmt.Errorf("GetUnit: %v", err)
+	}
+	if !prUnit.PullRequestsConfig().IsMergeStyleAllowed(entry.MergeStyle) {
+		return false, "merge style is not allowed", nil
+	}
+	if err := pr.CheckUserAllowedToMerge(entry.Doer); err != nil {
+		if models.IsErrNotAllowedToMerge(err) {
+			return false, "user is not allowed to merge", nil
+		}
+		return false, "", fmt.Errorf("CheckUserAllowedToMerge: %v", err)
+	}
+
+	noDeps, err := models.IssueNoDependenciesLeft(pr.Issue)
+	if err != nil {
+		return false, "", fmt.Errorf("IssueNoDependenciesLeft: %v", err)
+	} else if !noDeps {
+		return false, "pull request is blocked by dependencies", nil
+	}
+	if !pr.ProtectedBranch.HasEnoughApprovals(pr) {
+		return false, "pull request has not enough approvals", nil
+	}
+	if unresolved, err := pr.ProtectedBranch.GetUnresolvedConversationsCount(pr); err != nil {
+		return false, "", fmt.Errorf("GetUnresolvedConversationsCount: %v", err)
+	} else if unresolved > 0 {
+		return false, "pull request has unresolved conversations", nil
+	}
+	if err := pr.CheckSizeLimits(); err != nil {
+		if models.IsErrPullRequestSizeExceeded(err) {
+			return false, "pull request exceeds the size limits", nil
+		}
+		return false, "", fmt.Errorf("CheckSizeLimits: %v", err)
+	}
+	return false, "", nil
+}
+
+// updateTestMerge merges the head of the pull request on top of the base
+// commit and pushes the merge to the test branch, unless the test merge is
+// up to date.
+func updateTestMerge(baseGitRepo *git.Repository, entry *models.MergeQueueEntry, baseCommitID string) error {
+	pr := entry.PullRequest
+	headCommitID, err := baseGitRepo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		return fmt.Errorf("GetRefCommitID: %v", err)
+	}
+	if len(entry.MergeCommitID) > 0 && entry.BaseCommitID == baseCommitID && entry.HeadCommitID == headCommitID {
+		return nil
+	}
+
+	message := entry.Message
+	if len(message) == 0 {
+		if entry.MergeStyle == models.MergeStyleSquash {
+			message = pr.GetDefaultSquashMessage()
+		} else {
+			message = pr.GetDefaultMergeMessage()
+		}
+	}
+	tmpBasePath, err := rawMerge(pr, entry.Doer, baseGitRepo, entry.MergeStyle, message, baseCommitID)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := models.RemoveTemporaryPath(tmpBasePath); err != nil {
+			log.Error("updateTestMerge: RemoveTemporaryPath: %s", err)
+		}
+	}()
+
+	mergeCommitID, err := git.GetFullCommitID(tmpBasePath, "HEAD")
+	if err != nil {
+		return fmt.Errorf("Failed to get full commit id for HEAD: %v", err)
+	}
+	env, err := mergePushingEnvironment(pr, entry.Doer)
+	if err != nil {
+		return err
+	}
+	// The test branch is replaced whenever the base or the head changes
+	var errbuf strings.Builder
+	if err := git.NewCommand("push", "-f", "origin", "HEAD:"+git.BranchPrefix+entry.TestBranch()).RunInDirTimeoutEnvPipeline(env, -1, tmpBasePath, nil, &errbuf); err != nil {
+		return fmt.Errorf("git push: %s", errbuf.String())
+	}
+
+	entry.BaseCommitID = baseCommitID
+	entry.HeadCommitID = headCommitID
+	entry.MergeCommitID = mergeCommitID
+	if err = models.UpdateMergeQueueEntryTestMerge(entry); err != nil {
+		return fmt.Errorf("UpdateMergeQueueEntryTestMerge: %v", err)
+	}
+	log.Trace("Test merge of pull request %d pushed to %s: %s", pr.ID, entry.TestBranch(), mergeCommitID)
+	return nil
+}
+
+// checkTestMerge returns if the test merge has to wait for its checks or the
+// reason why it can not be merged.
+func checkTestMerge(repo *models.Repository, entry *models.MergeQueueEntry) (bool, string, error) {
+	statuses, err := models.GetLatestCommitStatus(repo, entry.MergeCommitID, 0)
+	if err != nil {
+		return false, "", fmt.Errorf("GetLatestCommitStatus: %v", err)
+	}
+	if status := models.CalcCommitStatus(statuses); status != nil {
+		switch status.State {
+		case models.CommitStatusPending:
+			return true, "", nil
+		case models.CommitStatusError, models.CommitStatusFailure:
+			return false, "commit status checks of the test merge have failed", nil
+		}
+	}
+	runs, err := models.GetLatestCheckRuns(repo.ID, entry.MergeCommitID)
+	if err != nil {
+		return false, "", fmt.Errorf("GetLatestCheckRuns: %v", err)
+	}
+	pending := false
+	for _, run := range runs {
+		if run.IsFailed() {
+			return false, fmt.Sprintf("check run %s of the test merge has failed", run.Name), nil
+		}
+		// Any check run which has not completed yet is waited for, required or not
+		pending = pending || run.Status != models.CheckRunCompleted
+	}
+	if pending {
+		return true, "", nil
+	}
+
+	// Wait for the required status checks which have not been reported yet
+	missing, err := entry.PullRequest.ProtectedBranch.GetMissingCommitStatusCheckContexts(repo, entry.MergeCommitID)
+	if err != nil {
+		return false, "", fmt.Errorf("GetMissingCommitStatusCheckContexts: %v", err)
+	}
+	return len(missing) > 0, "", nil
+}
+
+// mergeTestMerge fast-forwards the base branch to the checked test merge of
+// the pull request, which fails if the base branch has been changed since.
+func mergeTestMerge(baseGitRepo *git.Repository, entry *models.MergeQueueEntry) error {
+	pr := entry.PullRequest
+	env, err := mergePushingEnvironment(pr, entry.Doer)
+	if err != nil {
+		return err
+	}
+	var errbuf strings.Builder
+	if err := git.NewCommand("push", ".", entry.MergeCommitID+":"+git.BranchPrefix+entry.BaseBranch).RunInDirTimeoutEnvPipeline(env, -1, baseGitRepo.Path, nil, &errbuf); err != nil {
+		return fmt.Errorf("git push: %s", errbuf.String())
+	}
+	go models.AddTestPullRequestTask(entry.Doer, pr.BaseRepoID, pr.BaseBranch, false)
+
+	pr.Issue.Repo = pr.BaseRepo
+	if err = recordMerge(pr, entry.Doer, entry.MergeCommitID); err != nil {
+		return err
+	}
+	notification.NotifyMergePullRequest(pr, entry.Doer, baseGitRepo)
+
+	log.Trace("Pull request merged from the merge queue: %d", pr.ID)
+	return nil
+}
+
+// removeMergeQueueEntry removes the entry from the merge queue and deletes its
+// test branch
+func removeMergeQueueEntry(baseGitRepo *git.Repository, entry *models.MergeQueueEntry) error {
+	if err := models.RemoveFromMergeQueue(entry.PullID); err != nil && !models.IsErrMergeQueueEntryNotExist(err) {
+		return fmt.Errorf("RemoveFromMergeQueue: %v", err)
+	}
+	if len(entry.MergeCommitID) > 0 {
+		if err := baseGitRepo.DeleteBranch(entry.TestBranch(), git.DeleteBranchOptions{Force: true}); err != nil {
+			log.Error("DeleteBranch [%s]: %v", entry.TestBranch(), err)
+		}
+	}
+	return nil
+}
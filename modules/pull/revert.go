@@ -0,0 +1,92 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/repofiles"
+)
+
+// getPreMergeCommitID returns the commit of the base branch the merged commit
+// of the pull request has been created on
+func getPreMergeCommitID(gitRepo *git.Repository, pr *models.PullRequest, mergedCommit *git.Commit) (string, error) {
+	// Merge commits have the previous head of the base branch as first parent
+	steps := 1
+	if mergedCommit.ParentCount() == 1 {
+		headCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+		if err != nil {
+			return "", fmt.Errorf("GetRefCommitID[%s]: %v", pr.GetGitRefName(), err)
+		}
+		headCommit, err := gitRepo.GetCommit(headCommitID)
+		if err != nil {
+			return "", fmt.Errorf("GetCommit: %v", err)
+		}
+		commits, err := gitRepo.CommitsBetweenIDs(headCommitID, pr.MergeBase)
+		if err != nil {
+			return "", fmt.Errorf("CommitsBetweenIDs: %v", err)
+		}
+		// Rebasing keeps the author and message of every commit while
+		// squashing creates a single new commit
+		if commits.Len() > 1 && mergedCommit.Message() == headCommit.Message() &&
+			mergedCommit.Author.Email == headCommit.Author.Email && mergedCommit.Author.When.Equal(headCommit.Author.When) {
+			steps = commits.Len()
+		}
+	}
+
+	commit := mergedCommit
+	for i := 0; i < steps; i++ {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return "", fmt.Errorf("Parent: %v", err)
+		}
+		commit = parent
+	}
+	return commit.ID.String(), nil
+}
+
+// GetDefaultRevertMessage returns the default message of the commit reverting the merged pull request
+func GetDefaultRevertMessage(pr *models.PullRequest) string {
+	return fmt.Sprintf("Revert \"%s\"\n\nThis reverts pull request %s#%d merged as commit %s.",
+		pr.Issue.Title, pr.BaseRepo.FullName(), pr.Index, pr.MergedCommitID)
+}
+
+// Revert reverts the changes of the merged pull request on its base branch
+// with a new commit pushed to the new branch
+func Revert(doer *models.User, pr *models.PullRequest, newBranch string) error {
+	if !pr.HasMerged || len(pr.MergedCommitID) == 0 {
+		return models.ErrCherryPickNotPossible{CommitID: pr.MergedCommitID, Revert: true, Reason: "the pull request has not been merged"}
+	}
+	if err := pr.LoadIssue(); err != nil {
+		return fmt.Errorf("LoadIssue: %v", err)
+	}
+	if err := pr.GetBaseRepo(); err != nil {
+		return fmt.Errorf("GetBaseRepo: %v", err)
+	}
+
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+	mergedCommit, err := gitRepo.GetCommit(pr.MergedCommitID)
+	if err != nil {
+		return fmt.Errorf("GetCommit: %v", err)
+	}
+	baseCommitID, err := getPreMergeCommitID(gitRepo, pr, mergedCommit)
+	if err != nil {
+		return err
+	}
+
+	_, err = repofiles.Revert(pr.BaseRepo, doer, &repofiles.CherryPickOptions{
+		CommitID:     pr.MergedCommitID,
+		BaseCommitID: baseCommitID,
+		OldBranch:    pr.BaseBranch,
+		NewBranch:    newBranch,
+		Message:      GetDefaultRevertMessage(pr),
+	})
+	return err
+}
@@ -6,7 +6,10 @@
 package pull
 
 import (
+	"strings"
+
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/notification"
 	api "code.gitea.io/gitea/modules/structs"
 )
 
@@ -17,9 +20,100 @@ func CreateReview(opts models.CreateReviewOptions) (*models.Review, error) {
 		return nil, err
 	}
 
+	if err := reviewHook(opts.Issue, review, opts.Reviewer); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// SubmitReview submits the pending review of the doer with the given verdict,
+// or creates a new review if the doer has no pending review. It returns the
+// review and the comment which has been added to the conversation.
+func SubmitReview(doer *models.User, issue *models.Issue, reviewType models.ReviewType, content string) (*models.Review, *models.Comment, error) {
+	review, err := models.GetCurrentReview(doer, issue)
+	if err != nil && !models.IsErrReviewNotExist(err) {
+		return nil, nil, err
+	}
+	hasCodeComments := false
+	if err == nil {
+		if err = review.LoadCodeComments(); err != nil {
+			return nil, nil, err
+		}
+		hasCodeComments = len(review.CodeComments) > 0
+	}
+
+	if !hasCodeComments && len(strings.TrimSpace(content)) == 0 &&
+		(reviewType == models.ReviewTypeComment || reviewType == models.ReviewTypeReject) {
+		return nil, nil, models.ErrReviewEmpty{}
+	}
+
+	if review == nil {
+		// No current review. Create a new one!
+		if review, err = models.CreateReview(models.CreateReviewOptions{
+			Type:     reviewType,
+			Issue:    issue,
+			Reviewer: doer,
+			Content:  content,
+		}); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		review.Content = content
+		review.Type = reviewType
+		if err = models.UpdateReview(review); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	comment, err := models.CreateComment(&models.CreateCommentOptions{
+		Type:     models.CommentTypeReview,
+		Doer:     doer,
+		Content:  review.Content,
+		Issue:    issue,
+		Repo:     issue.Repo,
+		ReviewID: review.ID,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = review.Publish(); err != nil {
+		return nil, nil, err
+	}
+
+	if err = reviewHook(issue, review, doer); err != nil {
+		return nil, nil, err
+	}
+	return review, comment, nil
+}
+
+// ReRequestReview requests a review of the pull request again from a reviewer
+// who has already approved or rejected it, which resets the review state of
+// the reviewer. The reviewer is notified about the request.
+func ReRequestReview(doer *models.User, issue *models.Issue, reviewer *models.User) (*models.Comment, error) {
+	latest, err := models.GetLatestReviewByReviewer(issue.ID, reviewer.ID)
+	if err != nil {
+		if models.IsErrReviewNotExist(err) {
+			return nil, models.ErrCannotReRequestReview{IssueID: issue.ID, ReviewerID: reviewer.ID}
+		}
+		return nil, err
+	}
+	if latest.Type != models.ReviewTypeApprove && latest.Type != models.ReviewTypeReject {
+		return nil, models.ErrCannotReRequestReview{IssueID: issue.ID, ReviewerID: reviewer.ID}
+	}
+
+	comment, err := models.AddReviewRequest(issue, reviewer, doer)
+	if err != nil {
+		return nil, err
+	}
+	notification.NotifyPullRequestReviewRequest(doer, issue, reviewer, comment)
+	return comment, nil
+}
+
+// reviewHook prepares the webhooks for a submitted review
+func reviewHook(issue *models.Issue, review *models.Review, reviewer *models.User) error {
 	var reviewHookType models.HookEventType
 
-	switch opts.Type {
+	switch review.Type {
 	case models.ReviewTypeApprove:
 		reviewHookType = models.HookEventPullRequestApproved
 	case models.ReviewTypeComment:
@@ -28,30 +122,38 @@ func CreateReview(opts models.CreateReviewOptions) (*models.Review, error) {
 		reviewHookType = models.HookEventPullRequestRejected
 	default:
 		// unsupported review webhook type here
-		return review, nil
+		return nil
 	}
 
-	pr := opts.Issue.PullRequest
-
+	if err := issue.LoadRepo(); err != nil {
+		return err
+	}
+	if err := issue.LoadPullRequest(); err != nil {
+		return err
+	}
+	pr := issue.PullRequest
 	if err := pr.LoadIssue(); err != nil {
-		return nil, err
+		return err
 	}
 
-	mode, err := models.AccessLevel(opts.Issue.Poster, opts.Issue.Repo)
+	mode, err := models.AccessLevel(reviewer, issue.Repo)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if err := models.PrepareWebhooks(opts.Issue.Repo, reviewHookType, &api.PullRequestPayload{
+	if err := models.PrepareWebhooks(issue.Repo, reviewHookType, &api.PullRequestPayload{
 		Action:      api.HookIssueSynchronized,
-		Index:       opts.Issue.Index,
+		Index:       issue.Index,
 		PullRequest: pr.APIFormat(),
-		Repository:  opts.Issue.Repo.APIFormat(mode),
-		Sender:      opts.Reviewer.APIFormat(),
+		Repository:  issue.Repo.APIFormat(mode),
+		Sender:      reviewer.APIFormat(),
 	}); err != nil {
-		return nil, err
+		return err
 	}
-	go models.HookQueue.Add(opts.Issue.Repo.ID)
+	go models.HookQueue.Add(issue.Repo.ID)
 
-	return review, nil
+	if review.Type == models.ReviewTypeApprove {
+		models.AddToAutoMergeQueue(pr.ID)
+	}
+	return nil
 }
@@ -0,0 +1,50 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// ChangeTargetBranch changes the base branch of the pull request and
+// re-evaluates its diff against the new base branch.
+func ChangeTargetBranch(pr *models.PullRequest, doer *models.User, targetBranch string) error {
+	if err := pr.ChangeTargetBranch(doer, targetBranch); err != nil {
+		return err
+	}
+	if err := pr.UpdatePatch(); err != nil {
+		return fmt.Errorf("UpdatePatch: %v", err)
+	}
+	pr.AddToTaskQueue()
+	return nil
+}
+
+// RetargetStackedPullRequests changes the base branch of the pull requests
+// stacked on the merged pull request to its base branch, which contains the
+// changes of their former base branch now.
+func RetargetStackedPullRequests(pr *models.PullRequest, doer *models.User) error {
+	// Stacked pull requests can only target a branch of their own repository
+	if pr.HeadRepoID != pr.BaseRepoID {
+		return nil
+	}
+	children, err := pr.GetStackedPullRequests()
+	if err != nil {
+		return fmt.Errorf("GetStackedPullRequests: %v", err)
+	}
+	for _, child := range children {
+		if err = ChangeTargetBranch(child, doer, pr.BaseBranch); err != nil {
+			if !models.IsErrPullRequestAlreadyExists(err) {
+				return fmt.Errorf("ChangeTargetBranch[%d]: %v", child.ID, err)
+			}
+			log.Info("Stacked pull request %d can not be retargeted to %s: %v", child.ID, pr.BaseBranch, err)
+			continue
+		}
+		log.Trace("Stacked pull request %d retargeted to %s", child.ID, pr.BaseBranch)
+	}
+	return nil
+}
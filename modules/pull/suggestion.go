@@ -0,0 +1,161 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/repofiles"
+)
+
+// DefaultSuggestionsMessage is the commit message used when suggestions are applied without a message
+const DefaultSuggestionsMessage = "Apply suggestions from code review"
+
+// ApplySuggestions applies the suggestions of the code comments to the head
+// branch of the pull request with a single commit. The commented lines have to
+// be unchanged on the head branch.
+func ApplySuggestions(doer *models.User, pr *models.PullRequest, comments []*models.Comment, message string) error {
+	if len(comments) == 0 {
+		return nil
+	}
+	if err := pr.GetHeadRepo(); err != nil {
+		return fmt.Errorf("GetHeadRepo: %v", err)
+	} else if pr.HeadRepo == nil {
+		return models.ErrSuggestionNotApplicable{CommentID: comments[0].ID, Reason: "head repository does not exist"}
+	}
+
+	// Group the suggestions by file and reject conflicting suggestions
+	suggestions := make(map[string][]*models.Comment)
+	lines := make(map[string]bool)
+	for _, comment := range comments {
+		if comment.Suggestion() == nil {
+			return models.ErrSuggestionNotApplicable{CommentID: comment.ID, Reason: "comment has no suggestion"}
+		}
+		key := fmt.Sprintf("%s:%d", comment.TreePath, comment.Line)
+		if lines[key] {
+			return models.ErrSuggestionNotApplicable{CommentID: comment.ID, Reason: "another suggestion changes the same line"}
+		}
+		lines[key] = true
+		suggestions[comment.TreePath] = append(suggestions[comment.TreePath], comment)
+	}
+
+	t, err := repofiles.NewTemporaryUploadRepository(pr.HeadRepo)
+	if err != nil {
+		return err
+	}
+	defer t.Close()
+	if err := t.Clone(pr.HeadBranch); err != nil {
+		return err
+	}
+	if err := t.SetDefaultIndex(); err != nil {
+		return err
+	}
+	commit, err := t.GetBranchCommit(pr.HeadBranch)
+	if err != nil {
+		return err
+	}
+
+	for treePath, fileComments := range suggestions {
+		entry, err := commit.GetTreeEntryByPath(treePath)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				return models.ErrSuggestionNotApplicable{CommentID: fileComments[0].ID, Reason: "file does not exist"}
+			}
+			return err
+		}
+		if !entry.IsRegular() && !entry.IsExecutable() {
+			return models.ErrSuggestionNotApplicable{CommentID: fileComments[0].ID, Reason: "file is not a regular file"}
+		}
+
+		reader, err := entry.Blob().DataAsync()
+		if err != nil {
+			return err
+		}
+		content, err := ioutil.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return err
+		}
+
+		newContent, err := applyFileSuggestions(string(content), fileComments)
+		if err != nil {
+			return err
+		}
+		objectHash, err := t.HashObject(strings.NewReader(newContent))
+		if err != nil {
+			return err
+		}
+		if err := t.AddObjectToIndex(fmt.Sprintf("%06o", entry.Mode()), objectHash, treePath); err != nil {
+			return err
+		}
+	}
+
+	treeHash, err := t.WriteTree()
+	if err != nil {
+		return err
+	}
+	commitHash, err := t.CommitTree(doer, doer, treeHash, suggestionsCommitMessage(doer, comments, message))
+	if err != nil {
+		return err
+	}
+	return t.Push(doer, commitHash, pr.HeadBranch)
+}
+
+// applyFileSuggestions replaces the commented lines of the content by their
+// suggestions, the line endings of the file are kept.
+func applyFileSuggestions(content string, comments []*models.Comment) (string, error) {
+	lines := strings.Split(content, "\n")
+
+	// Replace from the bottom so the line numbers stay valid
+	sort.Slice(comments, func(i, j int) bool {
+		return comments[i].Line > comments[j].Line
+	})
+	for _, comment := range comments {
+		suggestion := comment.Suggestion()
+		idx := int(comment.Line) - 1
+		if idx >= len(lines) || strings.TrimSuffix(lines[idx], "\r") != suggestion.Original {
+			return "", models.ErrSuggestionNotApplicable{CommentID: comment.ID, Reason: "commented line has been changed"}
+		}
+
+		hasCR := strings.HasSuffix(lines[idx], "\r")
+		replacement := make([]string, len(suggestion.Replacement))
+		for i, line := range suggestion.Replacement {
+			if hasCR {
+				line += "\r"
+			}
+			replacement[i] = line
+		}
+		lines = append(lines[:idx], append(replacement, lines[idx+1:]...)...)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// suggestionsCommitMessage returns the commit message crediting the authors
+// of the suggestions as co-authors.
+func suggestionsCommitMessage(doer *models.User, comments []*models.Comment, message string) string {
+	message = strings.TrimSpace(message)
+	if len(message) == 0 {
+		message = DefaultSuggestionsMessage
+	}
+
+	var trailers strings.Builder
+	seen := map[int64]bool{doer.ID: true}
+	for _, comment := range comments {
+		if seen[comment.PosterID] || comment.Poster == nil {
+			continue
+		}
+		seen[comment.PosterID] = true
+		fmt.Fprintf(&trailers, "\nCo-authored-by: %s <%s>", comment.Poster.GetDisplayName(), comment.Poster.GetEmail())
+	}
+	if trailers.Len() == 0 {
+		return message
+	}
+	return message + "\n" + trailers.String()
+}
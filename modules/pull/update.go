@@ -0,0 +1,112 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// IsUpdateStyleAllowed returns true if the head branch of pull requests can
+// be updated with the merge style, which is either merge or rebase.
+func IsUpdateStyleAllowed(style models.MergeStyle) bool {
+	return style == models.MergeStyleMerge || style == models.MergeStyleRebase
+}
+
+// IsUserAllowedToUpdate returns true if the user can push to the head branch
+// of the pull request, which AGit pull requests do not have.
+func IsUserAllowedToUpdate(pr *models.PullRequest, user *models.User) (bool, error) {
+	if pr.HasMerged || pr.Flow == models.PullRequestFlowAGit {
+		return false, nil
+	}
+	if err := pr.GetHeadRepo(); err != nil {
+		return false, fmt.Errorf("GetHeadRepo: %v", err)
+	} else if pr.HeadRepo == nil || pr.HeadRepo.IsArchived {
+		return false, nil
+	}
+	perm, err := models.GetUserRepoPermission(pr.HeadRepo, user)
+	if err != nil {
+		return false, err
+	}
+	if perm.CanWrite(models.UnitTypeCode) {
+		return true, nil
+	}
+	return models.CanMaintainerWriteToBranch(pr.HeadRepo, pr.HeadBranch, user)
+}
+
+// Update updates the head branch of the pull request with the commits of its
+// base branch, either by merging the base branch into the head branch or by
+// rebasing the head branch onto the base branch.
+func Update(pr *models.PullRequest, doer *models.User, style models.MergeStyle) error {
+	if !IsUpdateStyleAllowed(style) {
+		return models.ErrInvalidMergeStyle{ID: pr.BaseRepoID, Style: style}
+	}
+	if err := loadConflictRepos(pr); err != nil {
+		return err
+	}
+	behind, err := pr.GetCommitsBehind()
+	if err != nil {
+		return fmt.Errorf("GetCommitsBehind: %v", err)
+	} else if behind == 0 {
+		return models.ErrPullRequestUpToDate{ID: pr.ID}
+	}
+	if protected, _ := pr.HeadRepo.IsProtectedBranchForPush(pr.HeadBranch, doer); protected {
+		return models.ErrUserCannotCommit{UserName: doer.LowerName}
+	}
+
+	tmpBasePath, err := models.CreateTemporaryPath("update")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := models.RemoveTemporaryPath(tmpBasePath); err != nil {
+			log.Error("Update: RemoveTemporaryPath: %s", err)
+		}
+	}()
+
+	if err = git.Clone(pr.HeadRepo.RepoPath(), tmpBasePath, git.CloneRepoOptions{
+		Shared: true,
+		Branch: pr.HeadBranch,
+	}); err != nil {
+		return fmt.Errorf("git clone: %v", err)
+	}
+	headCommitID, err := git.GetFullCommitID(tmpBasePath, "HEAD")
+	if err != nil {
+		return fmt.Errorf("GetFullCommitID: %v", err)
+	}
+
+	trackingBranch := "refs/remotes/base_repo/" + pr.BaseBranch
+	var errbuf strings.Builder
+	if err = git.NewCommand("fetch", "--no-tags", pr.BaseRepo.RepoPath(), git.BranchPrefix+pr.BaseBranch+":"+trackingBranch).RunInDirPipeline(tmpBasePath, nil, &errbuf); err != nil {
+		return fmt.Errorf("git fetch [%s -> %s]: %s", pr.BaseRepo.RepoPath(), tmpBasePath, errbuf.String())
+	}
+
+	// The doer is the committer of the merge commit and the rebased commits
+	env := models.PushingEnvironment(doer, pr.HeadRepo)
+	errbuf.Reset()
+	switch style {
+	case models.MergeStyleMerge:
+		if err = git.NewCommand("merge", "--no-ff", "--no-gpg-sign", "-m", GetDefaultConflictResolutionMessage(pr), trackingBranch).RunInDirTimeoutEnvPipeline(env, -1, tmpBasePath, nil, &errbuf); err != nil {
+			return models.ErrMergeConflicts{Style: style, StdErr: errbuf.String()}
+		}
+	case models.MergeStyleRebase:
+		if err = git.NewCommand("rebase", "-q", "--no-gpg-sign", trackingBranch).RunInDirTimeoutEnvPipeline(env, -1, tmpBasePath, nil, &errbuf); err != nil {
+			return models.ErrRebaseConflicts{Style: style, StdErr: errbuf.String()}
+		}
+	}
+
+	// Rebasing rewrites the head branch, which must not have changed meanwhile
+	errbuf.Reset()
+	if err = git.NewCommand("push", "--force-with-lease="+git.BranchPrefix+pr.HeadBranch+":"+headCommitID, "origin", "HEAD:"+git.BranchPrefix+pr.HeadBranch).RunInDirTimeoutEnvPipeline(env, -1, tmpBasePath, nil, &errbuf); err != nil {
+		return fmt.Errorf("git push: %s", errbuf.String())
+	}
+
+	log.Trace("Pull request updated by %s: %d", style, pr.ID)
+	return nil
+}
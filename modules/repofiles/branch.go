@@ -0,0 +1,172 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repofiles
+
+import (
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// DefaultStaleBranchDays is the number of days without commits after which a
+// branch is inactive if none is requested
+const DefaultStaleBranchDays = 90
+
+// StaleBranch a branch which is merged into the default branch or has no
+// recent commits
+type StaleBranch struct {
+	Name   string
+	Commit *git.Commit
+	// IsMerged is true if the branch has no commits which are not in the
+	// default branch
+	IsMerged bool
+	// IsInactive is true if the last commit of the branch is older than the
+	// inactivity period
+	IsInactive         bool
+	IsProtected        bool
+	HasOpenPullRequest bool
+}
+
+// CanDelete returns true if the branch can be deleted by a bulk cleanup
+func (b *StaleBranch) CanDelete() bool {
+	return !b.IsProtected && !b.HasOpenPullRequest
+}
+
+// FindStaleBranches returns the branches of the repository other than the
+// default one which are merged into it, or whose last commit is older than
+// inactiveDays if it is positive
+func FindStaleBranches(repo *models.Repository, gitRepo *git.Repository, inactiveDays int) ([]*StaleBranch, error) {
+	branchNames, err := gitRepo.GetBranches()
+	if err != nil {
+		return nil, fmt.Errorf("GetBranches: %v", err)
+	}
+	protectedBranches, err := repo.GetProtectedBranches()
+	if err != nil {
+		return nil, fmt.Errorf("GetProtectedBranches: %v", err)
+	}
+	isProtected := make(map[string]bool, len(protectedBranches))
+	for _, b := range protectedBranches {
+		isProtected[b.BranchName] = true
+	}
+	mergedBranches, err := gitRepo.GetMergedBranches(repo.DefaultBranch)
+	if err != nil {
+		return nil, fmt.Errorf("GetMergedBranches: %v", err)
+	}
+	isMerged := make(map[string]bool, len(mergedBranches))
+	for _, name := range mergedBranches {
+		isMerged[name] = true
+	}
+
+	inactiveSince := time.Now().AddDate(0, 0, -inactiveDays)
+	stale := make([]*StaleBranch, 0, 10)
+	for _, name := range branchNames {
+		if name == repo.DefaultBranch {
+			continue
+		}
+
+		commit, err := gitRepo.GetBranchCommit(name)
+		if err != nil {
+			return nil, fmt.Errorf("GetBranchCommit[%s]: %v", name, err)
+		}
+		branch := &StaleBranch{
+			Name:        name,
+			Commit:      commit,
+			IsMerged:    isMerged[name],
+			IsInactive:  inactiveDays > 0 && commit.Committer.When.Before(inactiveSince),
+			IsProtected: isProtected[name],
+		}
+		if !branch.IsMerged && !branch.IsInactive {
+			continue
+		}
+
+		if branch.HasOpenPullRequest, err = hasOpenPullRequest(repo, name); err != nil {
+			return nil, err
+		}
+		stale = append(stale, branch)
+	}
+	return stale, nil
+}
+
+// hasOpenPullRequest returns true if an open pull request is made from or
+// into the branch
+func hasOpenPullRequest(repo *models.Repository, branch string) (bool, error) {
+	prs, err := models.GetUnmergedPullRequestsByHeadInfo(repo.ID, branch)
+	if err != nil {
+		return false, fmt.Errorf("GetUnmergedPullRequestsByHeadInfo[%s]: %v", branch, err)
+	}
+	if len(prs) > 0 {
+		return true, nil
+	}
+	prs, err = models.GetUnmergedPullRequestsByBaseInfo(repo.ID, branch)
+	if err != nil {
+		return false, fmt.Errorf("GetUnmergedPullRequestsByBaseInfo[%s]: %v", branch, err)
+	}
+	return len(prs) > 0, nil
+}
+
+// DeleteStaleBranches deletes the branches among names which are stale and
+// can be deleted, and returns the names of the deleted ones
+func DeleteStaleBranches(doer *models.User, repo *models.Repository, gitRepo *git.Repository, names []string, inactiveDays int) ([]string, error) {
+	stale, err := FindStaleBranches(repo, gitRepo, inactiveDays)
+	if err != nil {
+		return nil, err
+	}
+	toDelete := make(map[string]bool, len(names))
+	for _, name := range names {
+		toDelete[name] = true
+	}
+
+	deleted := make([]string, 0, len(names))
+	for _, branch := range stale {
+		if !toDelete[branch.Name] || !branch.CanDelete() {
+			continue
+		}
+		if err := DeleteBranch(doer, repo, gitRepo, branch.Name); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, branch.Name)
+	}
+	return deleted, nil
+}
+
+// DeleteBranch deletes a branch of the repository and records it as deleted
+// by doer so that it can be restored
+func DeleteBranch(doer *models.User, repo *models.Repository, gitRepo *git.Repository, branchName string) error {
+	commit, err := gitRepo.GetBranchCommit(branchName)
+	if err != nil {
+		return fmt.Errorf("GetBranchCommit: %v", err)
+	}
+
+	if err := gitRepo.DeleteBranch(branchName, git.DeleteBranchOptions{
+		Force: true,
+	}); err != nil {
+		return fmt.Errorf("DeleteBranch: %v", err)
+	}
+
+	// Don't return error below this
+	if err := PushUpdate(
+		repo,
+		branchName,
+		models.PushUpdateOptions{
+			RefFullName:  git.BranchPrefix + branchName,
+			OldCommitID:  commit.ID.String(),
+			NewCommitID:  git.EmptySHA,
+			PusherID:     doer.ID,
+			PusherName:   doer.Name,
+			RepoUserName: repo.MustOwner().Name,
+			RepoName:     repo.Name,
+		}); err != nil {
+		log.Error("Update: %v", err)
+	}
+
+	if err := repo.AddDeletedBranch(branchName, commit.ID.String(), doer.ID); err != nil {
+		log.Warn("AddDeletedBranch: %v", err)
+	}
+
+	return nil
+}
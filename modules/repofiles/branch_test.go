@@ -0,0 +1,51 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repofiles
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindStaleBranches(t *testing.T) {
+	models.PrepareTestEnv(t)
+	ctx := test.MockContext(t, "user2/repo1")
+	test.LoadRepo(t, ctx, 1)
+	test.LoadGitRepo(t, ctx)
+
+	branches, err := FindStaleBranches(ctx.Repo.Repository, ctx.Repo.GitRepo, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, branches, 3) {
+		assert.Equal(t, "develop", branches[1].Name)
+		assert.True(t, branches[1].IsMerged)
+		assert.False(t, branches[1].IsInactive)
+		assert.True(t, branches[1].CanDelete())
+	}
+
+	branches, err = FindStaleBranches(ctx.Repo.Repository, ctx.Repo.GitRepo, 1)
+	assert.NoError(t, err)
+	for _, branch := range branches {
+		assert.NotEqual(t, "master", branch.Name)
+		assert.True(t, branch.IsInactive)
+	}
+}
+
+func TestDeleteStaleBranches(t *testing.T) {
+	models.PrepareTestEnv(t)
+	ctx := test.MockContext(t, "user2/repo1")
+	test.LoadRepo(t, ctx, 1)
+	test.LoadUser(t, ctx, 2)
+	test.LoadGitRepo(t, ctx)
+
+	deleted, err := DeleteStaleBranches(ctx.User, ctx.Repo.Repository, ctx.Repo.GitRepo, []string{"develop", "master", "missing"}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"develop"}, deleted)
+	assert.False(t, ctx.Repo.GitRepo.IsBranchExist("develop"))
+	models.AssertExistsAndLoadBean(t, &models.DeletedBranch{RepoID: 1, Name: "develop"})
+}
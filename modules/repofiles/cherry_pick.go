@@ -0,0 +1,198 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repofiles
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/git"
+)
+
+// CherryPickOptions holds the options to cherry-pick or revert a commit
+type CherryPickOptions struct {
+	CommitID string
+	// Mainline is the number of the parent, starting from 1, the changes of
+	// the commit are computed against. It is required for merge commits.
+	Mainline int
+	// BaseCommitID overrides the parent the changes of the commit are
+	// computed against, e.g. to revert a range of commits
+	BaseCommitID string
+	OldBranch    string
+	NewBranch    string
+	Message      string
+}
+
+// GetDefaultCherryPickMessage returns the default message of the commit
+// created by cherry-picking the commit
+func GetDefaultCherryPickMessage(commit *git.Commit) string {
+	return fmt.Sprintf("%s\n\n(cherry picked from commit %s)", strings.TrimSpace(commit.Message()), commit.ID.String())
+}
+
+// GetDefaultRevertMessage returns the default message of the commit created
+// by reverting the commit relative to the given parent
+func GetDefaultRevertMessage(commit *git.Commit, parentID string) string {
+	if commit.ParentCount() > 1 {
+		return fmt.Sprintf("Revert \"%s\"\n\nThis reverts commit %s, reversing\nchanges made to %s.", commit.Summary(), commit.ID.String(), parentID)
+	}
+	return fmt.Sprintf("Revert \"%s\"\n\nThis reverts commit %s.", commit.Summary(), commit.ID.String())
+}
+
+// CherryPick applies the changes of the commit onto the old branch and pushes
+// the new commit, authored by the author of the commit, to the new branch.
+// The ID of the new commit is returned.
+func CherryPick(repo *models.Repository, doer *models.User, opts *CherryPickOptions) (string, error) {
+	return applyCommit(repo, doer, opts, false)
+}
+
+// Revert reverts the changes of the commit on the old branch and pushes the
+// new commit to the new branch. The ID of the new commit is returned.
+func Revert(repo *models.Repository, doer *models.User, opts *CherryPickOptions) (string, error) {
+	return applyCommit(repo, doer, opts, true)
+}
+
+// getCherryPickBaseID returns the ID of the commit the changes of the commit are computed against
+func getCherryPickBaseID(commit *git.Commit, opts *CherryPickOptions, revert bool) (string, error) {
+	if len(opts.BaseCommitID) > 0 {
+		return opts.BaseCommitID, nil
+	}
+	switch {
+	case commit.ParentCount() == 0:
+		return "", models.ErrCherryPickNotPossible{CommitID: opts.CommitID, Revert: revert, Reason: "the commit has no parent"}
+	case commit.ParentCount() > 1 && opts.Mainline == 0:
+		return "", models.ErrCherryPickNotPossible{CommitID: opts.CommitID, Revert: revert, Reason: "the mainline parent of the merge commit is not given"}
+	case opts.Mainline < 0 || opts.Mainline > commit.ParentCount():
+		return "", models.ErrCherryPickNotPossible{CommitID: opts.CommitID, Revert: revert, Reason: "the commit has no such parent"}
+	}
+
+	mainline := opts.Mainline
+	if mainline == 0 {
+		mainline = 1
+	}
+	parentID, err := commit.ParentID(mainline - 1)
+	if err != nil {
+		return "", err
+	}
+	return parentID.String(), nil
+}
+
+// applyCommit applies or reverts the changes of the commit on the old branch
+// and pushes the new commit to the new branch
+func applyCommit(repo *models.Repository, doer *models.User, opts *CherryPickOptions, revert bool) (string, error) {
+	// If no branch name is set, assume master
+	if opts.OldBranch == "" {
+		opts.OldBranch = repo.DefaultBranch
+	}
+	if opts.NewBranch == "" {
+		opts.NewBranch = opts.OldBranch
+	}
+
+	// oldBranch must exist for this operation
+	if _, err := repo.GetBranch(opts.OldBranch); err != nil {
+		return "", err
+	}
+
+	// A NewBranch can be specified for the commit to be created in a new branch.
+	// Check to make sure the branch does not already exist, otherwise we can't proceed.
+	// If we aren't branching to a new branch, make sure user can commit to the given branch
+	if opts.NewBranch != opts.OldBranch {
+		existingBranch, err := repo.GetBranch(opts.NewBranch)
+		if existingBranch != nil {
+			return "", models.ErrBranchAlreadyExists{
+				BranchName: opts.NewBranch,
+			}
+		}
+		if err != nil && !git.IsErrBranchNotExist(err) {
+			return "", err
+		}
+	} else if protected, _ := repo.IsProtectedBranchForPush(opts.OldBranch, doer); protected {
+		return "", models.ErrUserCannotCommit{UserName: doer.LowerName}
+	}
+
+	t, err := NewTemporaryUploadRepository(repo)
+	if err != nil {
+		return "", err
+	}
+	defer t.Close()
+	if err = t.Clone(opts.OldBranch); err != nil {
+		return "", err
+	}
+
+	commit, err := t.GetCommit(opts.CommitID)
+	if err != nil {
+		return "", err
+	}
+	baseID, err := getCherryPickBaseID(commit, opts, revert)
+	if err != nil {
+		return "", err
+	}
+
+	// Reverting applies the changes from the commit back to its parent
+	from, to := baseID, commit.ID.String()
+	if revert {
+		from, to = to, from
+	}
+	conflicts, err := mergeTreesIntoIndex(t, from, "HEAD", to, base.ShortSha(to))
+	if err != nil {
+		if models.IsErrMergeConflictNotResolvable(err) {
+			return "", models.ErrCherryPickConflicts{CommitID: opts.CommitID, Revert: revert, Paths: []string{err.(models.ErrMergeConflictNotResolvable).Path}}
+		}
+		return "", err
+	}
+	if len(conflicts) > 0 {
+		paths := make([]string, 0, len(conflicts))
+		for _, conflict := range conflicts {
+			paths = append(paths, conflict.TreePath)
+		}
+		return "", models.ErrCherryPickConflicts{CommitID: opts.CommitID, Revert: revert, Paths: paths}
+	}
+
+	treeHash, err := t.WriteTree()
+	if err != nil {
+		return "", err
+	}
+	headTreeHash, err := t.GetLastCommitByRef("HEAD^{tree}")
+	if err != nil {
+		return "", err
+	}
+	if treeHash == headTreeHash {
+		reason := "the changes of the commit are already on the branch"
+		if revert {
+			reason = "the changes of the commit are not on the branch"
+		}
+		return "", models.ErrCherryPickNotPossible{CommitID: opts.CommitID, Revert: revert, Reason: reason}
+	}
+
+	message := strings.TrimSpace(opts.Message)
+	author, committer := doer, doer
+	if revert {
+		if len(message) == 0 {
+			message = GetDefaultRevertMessage(commit, baseID)
+		}
+	} else {
+		if len(message) == 0 {
+			message = GetDefaultCherryPickMessage(commit)
+		}
+		// Keep the original author, the doer is the committer
+		author, committer = GetAuthorAndCommitterUsers(&IdentityOptions{
+			Name:  doer.DisplayName(),
+			Email: doer.Email,
+		}, &IdentityOptions{
+			Name:  commit.Author.Name,
+			Email: commit.Author.Email,
+		}, doer)
+	}
+
+	commitHash, err := t.CommitTree(author, committer, treeHash, message)
+	if err != nil {
+		return "", err
+	}
+	if err = t.Push(doer, commitHash, opts.NewBranch); err != nil {
+		return "", err
+	}
+	return commitHash, nil
+}
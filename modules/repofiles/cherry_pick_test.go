@@ -0,0 +1,37 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repofiles
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCherryPick_RootCommit(t *testing.T) {
+	models.PrepareTestEnv(t)
+	ctx := test.MockContext(t, "user2/repo1")
+	test.LoadRepo(t, ctx, 1)
+	test.LoadUser(t, ctx, 2)
+
+	// The initial commit has no parent to compute its changes from
+	_, err := CherryPick(ctx.Repo.Repository, ctx.User, &CherryPickOptions{
+		CommitID:  "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+		OldBranch: "master",
+		NewBranch: "cherry-pick",
+	})
+	assert.True(t, models.IsErrCherryPickNotPossible(err))
+
+	// The new branch must not exist yet
+	_, err = CherryPick(ctx.Repo.Repository, ctx.User, &CherryPickOptions{
+		CommitID:  "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+		OldBranch: "master",
+		NewBranch: "develop",
+	})
+	assert.True(t, models.IsErrBranchAlreadyExists(err))
+}
@@ -0,0 +1,193 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repofiles
+
+import (
+	"bytes"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+)
+
+// ConflictFile represents a file with conflicting changes of a merge
+type ConflictFile struct {
+	TreePath string
+	// Content is the merged content containing the conflict markers
+	Content string
+	mode    string
+}
+
+// ResolveMergeConflictsOptions holds the options to resolve the conflicts of
+// merging the base branch of the base repository into the branch
+type ResolveMergeConflictsOptions struct {
+	LastCommitID string
+	Branch       string
+	BaseRepo     *models.Repository
+	BaseBranch   string
+	Message      string
+	// Files holds the resolved content of the conflicting files by tree path
+	Files     map[string]string
+	Author    *IdentityOptions
+	Committer *IdentityOptions
+}
+
+// mergeTreesIntoIndex performs a three-way merge of the trees of the commits
+// into the index of the temporary repository. Files which merge cleanly are
+// added to the index, the files with conflicting changes are returned.
+func mergeTreesIntoIndex(t *TemporaryUploadRepository, base, ours, theirs, theirsLabel string) ([]*ConflictFile, error) {
+	if err := t.ReadTreeMerge(base, ours, theirs); err != nil {
+		return nil, err
+	}
+
+	unmergedFiles, err := t.LsUnmergedFiles()
+	if err != nil {
+		return nil, err
+	}
+	conflicts := make([]*ConflictFile, 0, len(unmergedFiles))
+	for _, file := range unmergedFiles {
+		// Files which have been added, deleted or changed from or to a
+		// symbolic link or submodule on one side can not be merged by content
+		if len(file.Base) == 0 || len(file.Ours) == 0 || len(file.Theirs) == 0 || file.Mode != "100644" && file.Mode != "100755" {
+			return nil, models.ErrMergeConflictNotResolvable{Path: file.Path}
+		}
+		content, clean, err := t.MergeFile(file, "HEAD", theirsLabel)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.IndexByte(content, 0) != -1 {
+			return nil, models.ErrMergeConflictNotResolvable{Path: file.Path}
+		}
+		if !clean {
+			conflicts = append(conflicts, &ConflictFile{TreePath: file.Path, Content: string(content), mode: file.Mode})
+			continue
+		}
+
+		objectHash, err := t.HashObject(bytes.NewReader(content))
+		if err != nil {
+			return nil, err
+		}
+		if err = t.AddObjectToIndex(file.Mode, objectHash, file.Path); err != nil {
+			return nil, err
+		}
+	}
+	return conflicts, nil
+}
+
+// mergeIntoIndex performs a three-way merge of the base branch of the base
+// repository into the HEAD of the temporary repository in its index. The
+// files with conflicting changes are returned together with the commit ID
+// of the base branch.
+func mergeIntoIndex(t *TemporaryUploadRepository, baseRepo *models.Repository, baseBranch string) (string, []*ConflictFile, error) {
+	baseCommitID, err := t.Fetch(baseRepo.RepoPath(), baseBranch)
+	if err != nil {
+		return "", nil, err
+	}
+	mergeBase, err := t.MergeBase("HEAD", baseCommitID)
+	if err != nil {
+		return "", nil, err
+	}
+	conflicts, err := mergeTreesIntoIndex(t, mergeBase, "HEAD", baseCommitID, baseBranch)
+	if err != nil {
+		return "", nil, err
+	}
+	return baseCommitID, conflicts, nil
+}
+
+// GetMergeConflicts returns the commit ID of the branch and its files which
+// conflict with the changes of the base branch of the base repository
+func GetMergeConflicts(repo *models.Repository, branch string, baseRepo *models.Repository, baseBranch string) (string, []*ConflictFile, error) {
+	t, err := NewTemporaryUploadRepository(repo)
+	if err != nil {
+		return "", nil, err
+	}
+	defer t.Close()
+	if err = t.Clone(branch); err != nil {
+		return "", nil, err
+	}
+	headCommitID, err := t.GetLastCommit()
+	if err != nil {
+		return "", nil, err
+	}
+
+	_, conflicts, err := mergeIntoIndex(t, baseRepo, baseBranch)
+	if err != nil {
+		return "", nil, err
+	}
+	return headCommitID, conflicts, nil
+}
+
+// hasConflictMarkers returns true if the content contains a line starting
+// with a conflict marker
+func hasConflictMarkers(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, "<<<<<<<") || strings.HasPrefix(line, ">>>>>>>") {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveMergeConflicts merges the base branch of the base repository into
+// the branch using the resolved content of the conflicting files and returns
+// the commit ID of the merge commit
+func ResolveMergeConflicts(repo *models.Repository, doer *models.User, opts *ResolveMergeConflictsOptions) (string, error) {
+	if protected, _ := repo.IsProtectedBranchForPush(opts.Branch, doer); protected {
+		return "", models.ErrUserCannotCommit{UserName: doer.LowerName}
+	}
+
+	message := strings.TrimSpace(opts.Message)
+	author, committer := GetAuthorAndCommitterUsers(opts.Committer, opts.Author, doer)
+
+	t, err := NewTemporaryUploadRepository(repo)
+	if err != nil {
+		return "", err
+	}
+	defer t.Close()
+	if err = t.Clone(opts.Branch); err != nil {
+		return "", err
+	}
+	headCommitID, err := t.GetLastCommit()
+	if err != nil {
+		return "", err
+	}
+	// The conflicts have been resolved against the given commit
+	if headCommitID != opts.LastCommitID {
+		return "", models.ErrCommitIDDoesNotMatch{
+			GivenCommitID:   opts.LastCommitID,
+			CurrentCommitID: headCommitID,
+		}
+	}
+
+	baseCommitID, conflicts, err := mergeIntoIndex(t, opts.BaseRepo, opts.BaseBranch)
+	if err != nil {
+		return "", err
+	}
+	for _, conflict := range conflicts {
+		content, ok := opts.Files[conflict.TreePath]
+		if !ok || hasConflictMarkers(content) {
+			return "", models.ErrMergeConflictNotResolved{Path: conflict.TreePath}
+		}
+		objectHash, err := t.HashObject(strings.NewReader(content))
+		if err != nil {
+			return "", err
+		}
+		if err = t.AddObjectToIndex(conflict.mode, objectHash, conflict.TreePath); err != nil {
+			return "", err
+		}
+	}
+
+	treeHash, err := t.WriteTree()
+	if err != nil {
+		return "", err
+	}
+	commitHash, err := t.CommitTree(author, committer, treeHash, message, baseCommitID)
+	if err != nil {
+		return "", err
+	}
+	if err = t.Push(doer, commitHash, opts.Branch); err != nil {
+		return "", err
+	}
+	return commitHash, nil
+}
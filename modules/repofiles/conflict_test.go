@@ -0,0 +1,18 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repofiles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasConflictMarkers(t *testing.T) {
+	assert.False(t, hasConflictMarkers("a\nb\nc\n"))
+	assert.False(t, hasConflictMarkers("a\n  <<<<<<< HEAD\n"))
+	assert.True(t, hasConflictMarkers("a\n<<<<<<< HEAD\nb\n=======\nc\n>>>>>>> master\n"))
+	assert.True(t, hasConflictMarkers("a\n>>>>>>> master"))
+}
@@ -19,7 +19,7 @@ import (
 func GetFileResponseFromCommit(repo *models.Repository, commit *git.Commit, branch, treeName string) (*api.FileResponse, error) {
 	fileContents, _ := GetContents(repo, treeName, branch, false) // ok if fails, then will be nil
 	fileCommitResponse, _ := GetFileCommitResponse(repo, commit)  // ok if fails, then will be nil
-	verification := GetPayloadCommitVerification(commit)
+	verification := GetPayloadCommitVerification(repo, commit)
 	fileResponse := &api.FileResponse{
 		Content:      fileContents,
 		Commit:       fileCommitResponse,
@@ -28,6 +28,24 @@ func GetFileResponseFromCommit(repo *models.Repository, commit *git.Commit, bran
 	return fileResponse, nil
 }
 
+// GetFilesResponseFromCommit Constructs a FilesResponse from a Commit object,
+// the contents of the files whose tree name is empty are nil
+func GetFilesResponseFromCommit(repo *models.Repository, commit *git.Commit, branch string, treeNames []string) (*api.FilesResponse, error) {
+	files := make([]*api.ContentsResponse, len(treeNames))
+	for i, treeName := range treeNames {
+		if treeName != "" {
+			files[i], _ = GetContents(repo, treeName, branch, false) // ok if fails, then will be nil
+		}
+	}
+	fileCommitResponse, _ := GetFileCommitResponse(repo, commit) // ok if fails, then will be nil
+	verification := GetPayloadCommitVerification(repo, commit)
+	return &api.FilesResponse{
+		Files:        files,
+		Commit:       fileCommitResponse,
+		Verification: verification,
+	}, nil
+}
+
 // GetFileCommitResponse Constructs a FileCommitResponse from a Commit object
 func GetFileCommitResponse(repo *models.Repository, commit *git.Commit) (*api.FileCommitResponse, error) {
 	if repo == nil {
@@ -0,0 +1,175 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repofiles
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// ForkDivergence represents how many commits a branch of a fork is ahead or
+// behind its upstream branch
+type ForkDivergence struct {
+	Branch         string
+	UpstreamBranch string
+	Ahead          int
+	Behind         int
+}
+
+// GetUpstreamBranch returns the branch of the upstream repository the branch
+// of the fork is synchronized with, which is the branch of the same name or
+// the default branch for the default branch of the fork. It returns an empty
+// string if there is none.
+func GetUpstreamBranch(repo *models.Repository, branch string) string {
+	if !repo.IsFork || repo.BaseRepo == nil {
+		return ""
+	}
+	if git.IsBranchExist(repo.BaseRepo.RepoPath(), branch) {
+		return branch
+	}
+	if branch == repo.DefaultBranch && git.IsBranchExist(repo.BaseRepo.RepoPath(), repo.BaseRepo.DefaultBranch) {
+		return repo.BaseRepo.DefaultBranch
+	}
+	return ""
+}
+
+// CountForkDivergingCommits determines how many commits the branches of the
+// fork are ahead or behind their upstream branches, by branch name. The
+// branches without upstream branch are omitted.
+func CountForkDivergingCommits(repo *models.Repository, branches []string) (map[string]*ForkDivergence, error) {
+	if err := repo.GetBaseRepo(); err != nil {
+		return nil, fmt.Errorf("GetBaseRepo: %v", err)
+	}
+
+	upstreamBranches := make(map[string]string, len(branches))
+	for _, branch := range branches {
+		if upstreamBranch := GetUpstreamBranch(repo, branch); len(upstreamBranch) > 0 {
+			upstreamBranches[branch] = upstreamBranch
+		}
+	}
+	if len(upstreamBranches) == 0 {
+		return map[string]*ForkDivergence{}, nil
+	}
+
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return nil, err
+	}
+	divergences, err := gitRepo.GetDivergingCommitsFromRepo(repo.BaseRepo.RepoPath(), upstreamBranches)
+	if err != nil {
+		return nil, err
+	}
+
+	forkDivergences := make(map[string]*ForkDivergence, len(divergences))
+	for branch, divergence := range divergences {
+		forkDivergences[branch] = &ForkDivergence{
+			Branch:         branch,
+			UpstreamBranch: upstreamBranches[branch],
+			Ahead:          divergence.Ahead,
+			Behind:         divergence.Behind,
+		}
+	}
+	return forkDivergences, nil
+}
+
+// SyncFork brings the branch of the fork up to date with its upstream branch.
+// The branch is fast-forwarded if it has no commits of its own, the upstream
+// branch is merged into it by the doer otherwise. The ID of the new head of
+// the branch is returned.
+func SyncFork(repo *models.Repository, doer *models.User, branch string) (string, error) {
+	if err := repo.GetBaseRepo(); err != nil {
+		return "", fmt.Errorf("GetBaseRepo: %v", err)
+	}
+	if !repo.IsFork || repo.BaseRepo == nil {
+		return "", models.ErrForkSyncNotPossible{RepoID: repo.ID, Branch: branch, Reason: "the repository is not a fork"}
+	}
+	perm, err := models.GetUserRepoPermission(repo.BaseRepo, doer)
+	if err != nil {
+		return "", err
+	} else if !perm.CanRead(models.UnitTypeCode) {
+		return "", models.ErrForkSyncNotPossible{RepoID: repo.ID, Branch: branch, Reason: "the upstream repository is not accessible"}
+	}
+	if repo.IsMirror || repo.IsArchived {
+		return "", models.ErrForkSyncNotPossible{RepoID: repo.ID, Branch: branch, Reason: "the repository is read-only"}
+	}
+	if _, err = repo.GetBranch(branch); err != nil {
+		return "", err
+	}
+	upstreamBranch := GetUpstreamBranch(repo, branch)
+	if len(upstreamBranch) == 0 {
+		return "", models.ErrForkSyncNotPossible{RepoID: repo.ID, Branch: branch, Reason: "the upstream repository has no such branch"}
+	}
+	if protected, _ := repo.IsProtectedBranchForPush(branch, doer); protected {
+		return "", models.ErrUserCannotCommit{UserName: doer.LowerName}
+	}
+
+	t, err := NewTemporaryUploadRepository(repo)
+	if err != nil {
+		return "", err
+	}
+	defer t.Close()
+	if err = t.Clone(branch); err != nil {
+		return "", err
+	}
+	headCommitID, err := t.GetLastCommit()
+	if err != nil {
+		return "", err
+	}
+	upstreamCommitID, err := t.Fetch(repo.BaseRepo.RepoPath(), upstreamBranch)
+	if err != nil {
+		return "", err
+	}
+	mergeBase, err := t.MergeBase(headCommitID, upstreamCommitID)
+	if err != nil {
+		return "", err
+	}
+
+	switch mergeBase {
+	case upstreamCommitID:
+		return "", models.ErrForkUpToDate{RepoID: repo.ID, Branch: branch}
+	case headCommitID:
+		// The push is not forced so that the branch is not reset if it has
+		// been changed meanwhile
+		if err = t.Push(doer, upstreamCommitID, branch); err != nil {
+			return "", err
+		}
+		log.Trace("Branch %s of %s fast-forwarded to %s", branch, repo.FullName(), upstreamCommitID)
+		return upstreamCommitID, nil
+	}
+
+	upstreamLabel := repo.BaseRepo.FullName() + ":" + upstreamBranch
+	conflicts, err := mergeTreesIntoIndex(t, mergeBase, "HEAD", upstreamCommitID, upstreamLabel)
+	if err != nil {
+		if models.IsErrMergeConflictNotResolvable(err) {
+			return "", models.ErrForkSyncConflicts{Branch: branch, Paths: []string{err.(models.ErrMergeConflictNotResolvable).Path}}
+		}
+		return "", err
+	}
+	if len(conflicts) > 0 {
+		paths := make([]string, 0, len(conflicts))
+		for _, conflict := range conflicts {
+			paths = append(paths, conflict.TreePath)
+		}
+		return "", models.ErrForkSyncConflicts{Branch: branch, Paths: paths}
+	}
+
+	treeHash, err := t.WriteTree()
+	if err != nil {
+		return "", err
+	}
+	message := fmt.Sprintf("Merge branch '%s' of %s into %s", upstreamBranch, repo.BaseRepo.FullName(), branch)
+	commitHash, err := t.CommitTree(doer, doer, treeHash, message, upstreamCommitID)
+	if err != nil {
+		return "", err
+	}
+	if err = t.Push(doer, commitHash, branch); err != nil {
+		return "", err
+	}
+	log.Trace("Branch %s of %s merged with %s", branch, repo.FullName(), upstreamLabel)
+	return commitHash, nil
+}
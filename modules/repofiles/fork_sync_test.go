@@ -0,0 +1,30 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repofiles
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncFork_NotAFork(t *testing.T) {
+	models.PrepareTestEnv(t)
+	ctx := test.MockContext(t, "user2/repo1")
+	test.LoadRepo(t, ctx, 1)
+	test.LoadUser(t, ctx, 2)
+
+	assert.Empty(t, GetUpstreamBranch(ctx.Repo.Repository, "master"))
+
+	_, err := SyncFork(ctx.Repo.Repository, ctx.User, "master")
+	assert.True(t, models.IsErrForkSyncNotPossible(err))
+
+	divergences, err := CountForkDivergingCommits(ctx.Repo.Repository, []string{"master"})
+	assert.NoError(t, err)
+	assert.Empty(t, divergences)
+}
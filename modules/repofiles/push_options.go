@@ -0,0 +1,124 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repofiles
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// The push options which change the settings of the repository, the other
+// push options are left to the other handlers, e.g. the pull requests pushed
+// to refs/for/
+const (
+	PushOptionRepoPrivate     = "repo.private"
+	PushOptionRepoTemplate    = "repo.template"
+	PushOptionRepoDescription = "repo.description"
+	PushOptionRepoWebsite     = "repo.website"
+	PushOptionRepoTopics      = "repo.topics"
+)
+
+// HasRepoPushOptions returns whether the push options change the settings of
+// the repository
+func HasRepoPushOptions(options map[string]string) bool {
+	for key := range options {
+		if strings.HasPrefix(key, "repo.") {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyPushOptions changes the settings of the repository according to the
+// push options given by the doer with git push -o, which must be an
+// administrator of the repository. The options are checked before any of
+// them is applied.
+func ApplyPushOptions(repo *models.Repository, doer *models.User, options map[string]string) error {
+	if !HasRepoPushOptions(options) {
+		return nil
+	}
+	perm, err := models.GetUserRepoPermission(repo, doer)
+	if err != nil {
+		return err
+	} else if !perm.IsAdmin() {
+		return models.ErrUserDoesNotHaveAccessToRepo{UserID: doer.ID, RepoName: repo.LowerName}
+	}
+
+	parseBool := func(key string) (*bool, error) {
+		value, ok := options[key]
+		if !ok {
+			return nil, nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, models.ErrInvalidPushOption{Key: key, Value: value, Reason: "a boolean is expected"}
+		}
+		return &b, nil
+	}
+	private, err := parseBool(PushOptionRepoPrivate)
+	if err != nil {
+		return err
+	}
+	template, err := parseBool(PushOptionRepoTemplate)
+	if err != nil {
+		return err
+	}
+
+	visibilityChanged := false
+	if private != nil && *private != repo.IsPrivate {
+		if repo.IsFork {
+			// Visibility of forked repository is forced sync with base repository.
+			return models.ErrInvalidPushOption{Key: PushOptionRepoPrivate, Value: options[PushOptionRepoPrivate], Reason: "the visibility of a fork can not be changed"}
+		}
+		if setting.Repository.ForcePrivate && !*private && !doer.IsAdmin {
+			return models.ErrInvalidPushOption{Key: PushOptionRepoPrivate, Value: options[PushOptionRepoPrivate], Reason: "the repositories must be private"}
+		}
+		repo.IsPrivate = *private
+		visibilityChanged = true
+	}
+	if template != nil {
+		repo.IsTemplate = *template
+	}
+	if description, ok := options[PushOptionRepoDescription]; ok {
+		if len(description) > 255 {
+			return models.ErrInvalidPushOption{Key: PushOptionRepoDescription, Value: description, Reason: "the description is longer than 255 characters"}
+		}
+		repo.Description = description
+	}
+	if website, ok := options[PushOptionRepoWebsite]; ok {
+		if len(website) > 255 {
+			return models.ErrInvalidPushOption{Key: PushOptionRepoWebsite, Value: website, Reason: "the website is longer than 255 characters"}
+		}
+		repo.Website = website
+	}
+
+	var topics []string
+	value, hasTopics := options[PushOptionRepoTopics]
+	if hasTopics {
+		var invalidTopics []string
+		topics, invalidTopics = models.SanitizeAndValidateTopics(strings.Split(value, ","))
+		if len(invalidTopics) > 0 {
+			return models.ErrInvalidPushOption{Key: PushOptionRepoTopics, Value: value, Reason: fmt.Sprintf("invalid topics %s", strings.Join(invalidTopics, ", "))}
+		} else if len(topics) > 25 {
+			return models.ErrInvalidPushOption{Key: PushOptionRepoTopics, Value: value, Reason: "a repository can not have more than 25 topics"}
+		}
+	}
+
+	if err = models.UpdateRepository(repo, visibilityChanged); err != nil {
+		return fmt.Errorf("UpdateRepository: %v", err)
+	}
+	if hasTopics {
+		if err = models.SaveTopics(repo.ID, topics...); err != nil {
+			return fmt.Errorf("SaveTopics: %v", err)
+		}
+	}
+	log.Trace("Repository settings of %s updated by push options of %s", repo.FullName(), doer.Name)
+	return nil
+}
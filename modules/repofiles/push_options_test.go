@@ -0,0 +1,48 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repofiles
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyPushOptions(t *testing.T) {
+	models.PrepareTestEnv(t)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	owner := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	reader := models.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User)
+
+	// The options of the other handlers are ignored
+	assert.NoError(t, ApplyPushOptions(repo, reader, map[string]string{"topic": "feature", "force-push": ""}))
+
+	err := ApplyPushOptions(repo, reader, map[string]string{PushOptionRepoPrivate: "true"})
+	assert.True(t, models.IsErrUserDoesNotHaveAccessToRepo(err))
+
+	err = ApplyPushOptions(repo, owner, map[string]string{PushOptionRepoPrivate: "yes"})
+	assert.True(t, models.IsErrInvalidPushOption(err))
+	err = ApplyPushOptions(repo, owner, map[string]string{PushOptionRepoTopics: "go,not a topic"})
+	assert.True(t, models.IsErrInvalidPushOption(err))
+	models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1, IsPrivate: false})
+
+	assert.NoError(t, ApplyPushOptions(repo, owner, map[string]string{
+		PushOptionRepoPrivate:     "true",
+		PushOptionRepoTemplate:    "true",
+		PushOptionRepoDescription: "Pushed repository",
+		PushOptionRepoTopics:      "Go, git",
+	}))
+	repo = models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	assert.True(t, repo.IsPrivate)
+	assert.True(t, repo.IsTemplate)
+	assert.EqualValues(t, "Pushed repository", repo.Description)
+	topics, err := models.FindTopics(&models.FindTopicOptions{RepoID: repo.ID})
+	assert.NoError(t, err)
+	if assert.Len(t, topics, 2) {
+		assert.ElementsMatch(t, []string{"go", "git"}, []string{topics[0].Name, topics[1].Name})
+	}
+}
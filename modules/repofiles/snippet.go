@@ -0,0 +1,160 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repofiles
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/charset"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/highlight"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+)
+
+var lineRangePattern = regexp.MustCompile(`^#?L(\d+)(?:-L(\d+))?$`)
+
+// LineRange is a range of lines of a file, numbered from 1
+type LineRange struct {
+	From int
+	To   int
+}
+
+// ParseLineRange parses the anchor of the lines selected in the view of a file,
+// such as "L10" or "L10-L20"
+func ParseLineRange(s string) (LineRange, error) {
+	m := lineRangePattern.FindStringSubmatch(s)
+	if m == nil {
+		return LineRange{}, fmt.Errorf("invalid line range: %s", s)
+	}
+	from, err := strconv.Atoi(m[1])
+	if err != nil {
+		return LineRange{}, fmt.Errorf("invalid line range: %s", s)
+	}
+	to := from
+	if len(m[2]) > 0 {
+		if to, err = strconv.Atoi(m[2]); err != nil {
+			return LineRange{}, fmt.Errorf("invalid line range: %s", s)
+		}
+	}
+	if from > to {
+		from, to = to, from
+	}
+	if from < 1 {
+		return LineRange{}, fmt.Errorf("invalid line range: %s", s)
+	}
+	return LineRange{From: from, To: to}, nil
+}
+
+// Anchor returns the anchor of the lines in the view of a file
+func (r LineRange) Anchor() string {
+	if r.From == r.To {
+		return fmt.Sprintf("L%d", r.From)
+	}
+	return fmt.Sprintf("L%d-L%d", r.From, r.To)
+}
+
+// ErrInvalidSnippet represents an error when the lines can't be taken from a file
+type ErrInvalidSnippet struct {
+	Path   string
+	Reason string
+}
+
+// IsErrInvalidSnippet checks if an error is an ErrInvalidSnippet
+func IsErrInvalidSnippet(err error) bool {
+	_, ok := err.(ErrInvalidSnippet)
+	return ok
+}
+
+func (err ErrInvalidSnippet) Error() string {
+	return fmt.Sprintf("no snippet can be taken from %s: %s", err.Path, err.Reason)
+}
+
+// GetFileSnippet returns the lines of the file treePath at the commit, which is
+// also the one of its permanent link
+func GetFileSnippet(repo *models.Repository, commit *git.Commit, treePath string, lines LineRange) (*api.FileSnippet, error) {
+	entry, err := commit.GetTreeEntryByPath(treePath)
+	if err != nil {
+		return nil, err
+	}
+	if entry.IsDir() || entry.IsSubModule() {
+		return nil, git.ErrNotExist{ID: commit.ID.String(), RelPath: treePath}
+	}
+	blob := entry.Blob()
+	if blob.Size() >= setting.UI.MaxDisplayFileSize {
+		return nil, ErrInvalidSnippet{Path: treePath, Reason: "the file is too large"}
+	}
+
+	dataRc, err := blob.DataAsync()
+	if err != nil {
+		return nil, err
+	}
+	defer dataRc.Close()
+	buf, err := ioutil.ReadAll(dataRc)
+	if err != nil {
+		return nil, err
+	}
+	if !base.IsTextFile(buf) {
+		return nil, ErrInvalidSnippet{Path: treePath, Reason: "the file is not a text file"}
+	}
+
+	fileLines := strings.Split(string(charset.ToUTF8WithFallback(buf)), "\n")
+	if len(fileLines) > 0 && fileLines[len(fileLines)-1] == "" {
+		fileLines = fileLines[:len(fileLines)-1]
+	}
+	if lines.From > len(fileLines) {
+		return nil, ErrInvalidSnippet{Path: treePath, Reason: fmt.Sprintf("the file has only %d lines", len(fileLines))}
+	}
+	if lines.To > len(fileLines) {
+		lines.To = len(fileLines)
+	}
+	snippetLines := fileLines[lines.From-1 : lines.To]
+
+	htmlURL := repo.HTMLURL() + "/src/commit/" + commit.ID.String() + "/" + util.PathEscapeSegments(treePath) + "#" + lines.Anchor()
+	highlightClass := highlight.FileNameToHighlightClass(entry.Name())
+
+	var output bytes.Buffer
+	output.WriteString(`<div class="file-snippet">`)
+	output.WriteString(fmt.Sprintf(`<a class="file-snippet-link" href="%s">%s</a>`, template.HTMLEscapeString(htmlURL), template.HTMLEscapeString(treePath)))
+	output.WriteString(`<table class="code-view"><tbody><tr><td class="lines-num">`)
+	for i := range snippetLines {
+		output.WriteString(fmt.Sprintf(`<span data-line-number="%d"></span>`, lines.From+i))
+	}
+	output.WriteString(fmt.Sprintf(`</td><td class="lines-code"><pre><code class="%s"><ol class="linenums">`, highlightClass))
+	for i, line := range snippetLines {
+		line = template.HTMLEscapeString(line)
+		if i != len(snippetLines)-1 {
+			line += "\n"
+		}
+		output.WriteString(fmt.Sprintf(`<li class="L%[1]d" rel="L%[1]d">%s</li>`, lines.From+i, line))
+	}
+	output.WriteString(`</ol></code></pre></td></tr></tbody></table></div>`)
+
+	language := highlightClass
+	if language == "nohighlight" {
+		language = ""
+	}
+	markdown := fmt.Sprintf("%s\n```%s\n%s\n```\n", htmlURL, language, strings.Join(snippetLines, "\n"))
+
+	return &api.FileSnippet{
+		Path:         treePath,
+		SHA:          commit.ID.String(),
+		StartingLine: lines.From,
+		EndingLine:   lines.To,
+		HTMLURL:      htmlURL,
+		Lines:        snippetLines,
+		HTML:         output.String(),
+		Markdown:     markdown,
+	}, nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repofiles
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLineRange(t *testing.T) {
+	for s, expected := range map[string]LineRange{
+		"L10":     {10, 10},
+		"#L10":    {10, 10},
+		"L10-L20": {10, 20},
+		"L20-L10": {10, 20},
+	} {
+		lines, err := ParseLineRange(s)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, lines)
+	}
+
+	for _, s := range []string{"", "10", "L0", "L10-20", "L-L2", "n10"} {
+		_, err := ParseLineRange(s)
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, "L10", LineRange{10, 10}.Anchor())
+	assert.Equal(t, "L10-L20", LineRange{10, 20}.Anchor())
+}
+
+func TestGetFileSnippet(t *testing.T) {
+	models.PrepareTestEnv(t)
+	ctx := test.MockContext(t, "user2/repo1")
+	test.LoadRepo(t, ctx, 1)
+	test.LoadRepoCommit(t, ctx)
+	test.LoadGitRepo(t, ctx)
+
+	snippet, err := GetFileSnippet(ctx.Repo.Repository, ctx.Repo.Commit, "README.md", LineRange{2, 10})
+	assert.NoError(t, err)
+	assert.Equal(t, "README.md", snippet.Path)
+	assert.Equal(t, ctx.Repo.Commit.ID.String(), snippet.SHA)
+	assert.Equal(t, 2, snippet.StartingLine)
+	assert.Equal(t, 3, snippet.EndingLine)
+	assert.Equal(t, []string{"", "Description for repo1"}, snippet.Lines)
+	assert.Equal(t, "https://try.gitea.io/user2/repo1/src/commit/"+snippet.SHA+"/README.md#L2-L3", snippet.HTMLURL)
+	assert.Contains(t, snippet.HTML, `<li class="L3" rel="L3">Description for repo1</li>`)
+	assert.Equal(t, snippet.HTMLURL+"\n```markdown\n\nDescription for repo1\n```\n", snippet.Markdown)
+
+	_, err = GetFileSnippet(ctx.Repo.Repository, ctx.Repo.Commit, "README.md", LineRange{4, 4})
+	assert.True(t, IsErrInvalidSnippet(err))
+
+	_, err = GetFileSnippet(ctx.Repo.Repository, ctx.Repo.Commit, "missing.md", LineRange{1, 1})
+	assert.True(t, git.IsErrNotExist(err))
+}
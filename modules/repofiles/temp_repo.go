@@ -9,8 +9,10 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"path"
 	"regexp"
 	"strings"
 	"time"
@@ -248,8 +250,9 @@ func (t *TemporaryUploadRepository) GetLastCommitByRef(ref string) (string, erro
 	return strings.TrimSpace(treeHash), nil
 }
 
-// CommitTree creates a commit from a given tree for the user with provided message
-func (t *TemporaryUploadRepository) CommitTree(author, committer *models.User, treeHash string, message string) (string, error) {
+// CommitTree creates a commit from a given tree for the user with provided message.
+// The commit has HEAD and the optional additional parents as parents.
+func (t *TemporaryUploadRepository) CommitTree(author, committer *models.User, treeHash string, message string, parents ...string) (string, error) {
 	commitTimeStr := time.Now().Format(time.RFC3339)
 	authorSig := author.NewGitSig()
 	committerSig := committer.NewGitSig()
@@ -264,11 +267,16 @@ func (t *TemporaryUploadRepository) CommitTree(author, committer *models.User, t
 		"GIT_COMMITTER_EMAIL="+committerSig.Email,
 		"GIT_COMMITTER_DATE="+commitTimeStr,
 	)
+	args := []string{"commit-tree", treeHash, "-p", "HEAD"}
+	for _, parent := range parents {
+		args = append(args, "-p", parent)
+	}
+	args = append(args, "-m", message)
 	commitHash, stderr, err := process.GetManager().ExecDirEnv(5*time.Minute,
 		t.basePath,
 		fmt.Sprintf("commitTree (git commit-tree): %s", t.basePath),
 		env,
-		git.GitExecutable, "commit-tree", treeHash, "-p", "HEAD", "-m", message)
+		git.GitExecutable, args...)
 	if err != nil {
 		return "", fmt.Errorf("git commit-tree: %s", stderr)
 	}
@@ -290,6 +298,130 @@ func (t *TemporaryUploadRepository) Push(doer *models.User, commitHash string, b
 	return nil
 }
 
+// Fetch fetches the branch of the repository at repoPath and returns the commit ID of its head
+func (t *TemporaryUploadRepository) Fetch(repoPath, branch string) (string, error) {
+	if _, stderr, err := process.GetManager().ExecDir(5*time.Minute,
+		t.basePath,
+		fmt.Sprintf("Fetch (git fetch %s %s): %s", repoPath, branch, t.basePath),
+		git.GitExecutable, "fetch", "--no-tags", repoPath, git.BranchPrefix+branch); err != nil {
+		if matched, _ := regexp.MatchString(".*couldn't find remote ref.*", stderr); matched {
+			return "", git.ErrBranchNotExist{
+				Name: branch,
+			}
+		}
+		return "", fmt.Errorf("git fetch: %s", stderr)
+	}
+	return t.GetLastCommitByRef("FETCH_HEAD")
+}
+
+// MergeBase returns the best common ancestor of the two commits
+func (t *TemporaryUploadRepository) MergeBase(commitID1, commitID2 string) (string, error) {
+	mergeBase, stderr, err := process.GetManager().ExecDir(5*time.Minute,
+		t.basePath,
+		fmt.Sprintf("MergeBase (git merge-base %s %s): %s", commitID1, commitID2, t.basePath),
+		git.GitExecutable, "merge-base", commitID1, commitID2)
+	if err != nil {
+		return "", fmt.Errorf("git merge-base: %s", stderr)
+	}
+	return strings.TrimSpace(mergeBase), nil
+}
+
+// ReadTreeMerge performs a three-way merge of the trees of the given commits
+// into the index. Paths which can not be merged trivially are left unmerged.
+func (t *TemporaryUploadRepository) ReadTreeMerge(base, ours, theirs string) error {
+	if _, stderr, err := process.GetManager().ExecDir(5*time.Minute,
+		t.basePath,
+		fmt.Sprintf("ReadTreeMerge (git read-tree -m): %s", t.basePath),
+		git.GitExecutable, "read-tree", "-i", "-m", base, ours, theirs); err != nil {
+		return fmt.Errorf("git read-tree -m: %s", stderr)
+	}
+	return nil
+}
+
+// UnmergedFile represents a path left unmerged in the index. The object IDs
+// are empty if the path does not exist in the respective version.
+type UnmergedFile struct {
+	Path   string
+	Mode   string
+	Base   string
+	Ours   string
+	Theirs string
+}
+
+// LsUnmergedFiles lists the paths left unmerged in the index
+func (t *TemporaryUploadRepository) LsUnmergedFiles() ([]*UnmergedFile, error) {
+	stdOut, stderr, err := process.GetManager().ExecDir(5*time.Minute,
+		t.basePath,
+		fmt.Sprintf("LsUnmergedFiles (git ls-files -u): %s", t.basePath),
+		git.GitExecutable, "ls-files", "-u", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files -u: %s", stderr)
+	}
+
+	files := make([]*UnmergedFile, 0, 5)
+	for _, line := range strings.Split(stdOut, "\000") {
+		// <mode> SP <object> SP <stage> TAB <path>
+		fields := strings.SplitN(line, "\t", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		info := strings.Fields(fields[0])
+		if len(info) != 3 {
+			return nil, fmt.Errorf("Unexpected output of git ls-files -u: %s", line)
+		}
+		if len(files) == 0 || files[len(files)-1].Path != fields[1] {
+			files = append(files, &UnmergedFile{Path: fields[1]})
+		}
+		file := files[len(files)-1]
+		switch info[2] {
+		case "1":
+			file.Base = info[1]
+		case "2":
+			file.Ours = info[1]
+			file.Mode = info[0]
+		case "3":
+			file.Theirs = info[1]
+			if len(file.Mode) == 0 {
+				file.Mode = info[0]
+			}
+		}
+	}
+	return files, nil
+}
+
+// MergeFile performs a three-way merge of the versions of the unmerged file.
+// The merged content contains conflict markers with the given labels if the
+// merge is not clean.
+func (t *TemporaryUploadRepository) MergeFile(file *UnmergedFile, oursLabel, theirsLabel string) (content []byte, clean bool, err error) {
+	versions := []string{file.Ours, file.Base, file.Theirs}
+	filenames := make([]string, len(versions))
+	for i, objectID := range versions {
+		var data []byte
+		if len(objectID) > 0 {
+			if data, err = git.NewCommand("cat-file", "blob", objectID).RunInDirBytes(t.basePath); err != nil {
+				return nil, false, fmt.Errorf("git cat-file: %v", err)
+			}
+		}
+		filenames[i] = path.Join(t.basePath, fmt.Sprintf("merge-file-%d", i))
+		if err = ioutil.WriteFile(filenames[i], data, 0600); err != nil {
+			return nil, false, err
+		}
+	}
+
+	stdOut := new(bytes.Buffer)
+	stdErr := new(bytes.Buffer)
+	err = git.NewCommand("merge-file", "-p", "-L", oursLabel, "-L", "base", "-L", theirsLabel,
+		filenames[0], filenames[1], filenames[2]).RunInDirPipeline(t.basePath, stdOut, stdErr)
+	if err != nil {
+		// The exit code is the number of conflicts, or negative on errors
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() > 0 && exitErr.ExitCode() < 128 {
+			return stdOut.Bytes(), false, nil
+		}
+		return nil, false, fmt.Errorf("git merge-file: %v - %s", err, stdErr)
+	}
+	return stdOut.Bytes(), true, nil
+}
+
 // DiffIndex returns a Diff of the current index to the head
 func (t *TemporaryUploadRepository) DiffIndex() (diff *gitdiff.Diff, err error) {
 	timeout := 5 * time.Minute
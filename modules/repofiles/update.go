@@ -113,8 +113,62 @@ func detectEncodingAndBOM(entry *git.TreeEntry, repo *models.Repository) (string
 	return encoding, false
 }
 
+// ChangeRepoFile holds the change of one of the files of ChangeRepoFilesOptions
+type ChangeRepoFile struct {
+	// Operation is "create", "update" or "delete"
+	Operation    string
+	TreePath     string
+	FromTreePath string
+	Content      string
+	SHA          string
+}
+
+// ChangeRepoFilesOptions holds the options to change several files of a
+// repository in a single commit
+type ChangeRepoFilesOptions struct {
+	LastCommitID string
+	OldBranch    string
+	NewBranch    string
+	Message      string
+	Files        []*ChangeRepoFile
+	Author       *IdentityOptions
+	Committer    *IdentityOptions
+}
+
 // CreateOrUpdateRepoFile adds or updates a file in the given repository
 func CreateOrUpdateRepoFile(repo *models.Repository, doer *models.User, opts *UpdateRepoFileOptions) (*structs.FileResponse, error) {
+	operation := "update"
+	if opts.IsNewFile {
+		operation = "create"
+	}
+	filesResponse, err := ChangeRepoFiles(repo, doer, &ChangeRepoFilesOptions{
+		LastCommitID: opts.LastCommitID,
+		OldBranch:    opts.OldBranch,
+		NewBranch:    opts.NewBranch,
+		Message:      opts.Message,
+		Files: []*ChangeRepoFile{{
+			Operation:    operation,
+			TreePath:     opts.TreePath,
+			FromTreePath: opts.FromTreePath,
+			Content:      opts.Content,
+			SHA:          opts.SHA,
+		}},
+		Author:    opts.Author,
+		Committer: opts.Committer,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &structs.FileResponse{
+		Content:      filesResponse.Files[0],
+		Commit:       filesResponse.Commit,
+		Verification: filesResponse.Verification,
+	}, nil
+}
+
+// ChangeRepoFiles creates, updates and deletes files of the given repository
+// in a single commit
+func ChangeRepoFiles(repo *models.Repository, doer *models.User, opts *ChangeRepoFilesOptions) (*structs.FilesResponse, error) {
 	// If no branch name is set, assume master
 	if opts.OldBranch == "" {
 		opts.OldBranch = repo.DefaultBranch
@@ -128,7 +182,7 @@ func CreateOrUpdateRepoFile(repo *models.Repository, doer *models.User, opts *Up
 		return nil, err
 	}
 
-	// A NewBranch can be specified for the file to be created/updated in a new branch.
+	// A NewBranch can be specified for the files to be changed in a new branch.
 	// Check to make sure the branch does not already exist, otherwise we can't proceed.
 	// If we aren't branching to a new branch, make sure user can commit to the given branch
 	if opts.NewBranch != opts.OldBranch {
@@ -145,23 +199,34 @@ func CreateOrUpdateRepoFile(repo *models.Repository, doer *models.User, opts *Up
 		return nil, models.ErrUserCannotCommit{UserName: doer.LowerName}
 	}
 
-	// If FromTreePath is not set, set it to the opts.TreePath
-	if opts.TreePath != "" && opts.FromTreePath == "" {
-		opts.FromTreePath = opts.TreePath
+	if len(opts.Files) == 0 {
+		return nil, fmt.Errorf("ChangeRepoFiles: no files to change")
 	}
+	treePaths := make([]string, len(opts.Files))
+	for i, file := range opts.Files {
+		switch file.Operation {
+		case "create", "update", "delete":
+		default:
+			return nil, fmt.Errorf("ChangeRepoFiles: invalid operation %q of %s", file.Operation, file.TreePath)
+		}
 
-	// Check that the path given in opts.treePath is valid (not a git path)
-	treePath := CleanUploadFileName(opts.TreePath)
-	if treePath == "" {
-		return nil, models.ErrFilenameInvalid{
-			Path: opts.TreePath,
+		// If FromTreePath is not set, set it to the file.TreePath
+		if file.TreePath != "" && file.FromTreePath == "" {
+			file.FromTreePath = file.TreePath
 		}
-	}
-	// If there is a fromTreePath (we are copying it), also clean it up
-	fromTreePath := CleanUploadFileName(opts.FromTreePath)
-	if fromTreePath == "" && opts.FromTreePath != "" {
-		return nil, models.ErrFilenameInvalid{
-			Path: opts.FromTreePath,
+
+		// Check that the path given in file.TreePath is valid (not a git path)
+		treePaths[i] = CleanUploadFileName(file.TreePath)
+		if treePaths[i] == "" {
+			return nil, models.ErrFilenameInvalid{
+				Path: file.TreePath,
+			}
+		}
+		// If there is a fromTreePath (we are copying it), also clean it up
+		if file.FromTreePath != "" && CleanUploadFileName(file.FromTreePath) == "" {
+			return nil, models.ErrFilenameInvalid{
+				Path: file.FromTreePath,
+			}
 		}
 	}
 
@@ -193,47 +258,161 @@ func CreateOrUpdateRepoFile(repo *models.Repository, doer *models.User, opts *Up
 	} else {
 		lastCommitID, err := t.gitRepo.ConvertToSHA1(opts.LastCommitID)
 		if err != nil {
-			return nil, fmt.Errorf("DeleteRepoFile: Invalid last commit ID: %v", err)
+			return nil, fmt.Errorf("ChangeRepoFiles: Invalid last commit ID: %v", err)
 		}
 		opts.LastCommitID = lastCommitID.String()
+	}
 
+	// All the files are added to the same index, the tree is only written
+	// once all of them have been changed
+	lfsMetaObjects := make([]*models.LFSMetaObject, len(opts.Files))
+	for i, file := range opts.Files {
+		if file.Operation == "delete" {
+			err = deleteFileInIndex(t, commit, opts, file, treePaths[i])
+		} else {
+			lfsMetaObjects[i], err = changeFileInIndex(t, repo, commit, opts, file, treePaths[i])
+		}
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	encoding := "UTF-8"
-	bom := false
+	// Now write the tree
+	treeHash, err := t.WriteTree()
+	if err != nil {
+		return nil, err
+	}
 
-	if !opts.IsNewFile {
-		fromEntry, err := commit.GetTreeEntryByPath(fromTreePath)
+	// Now commit the tree
+	commitHash, err := t.CommitTree(author, committer, treeHash, message)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, lfsMetaObject := range lfsMetaObjects {
+		if lfsMetaObject == nil {
+			continue
+		}
+		// We have an LFS object - create it
+		lfsMetaObject, err = models.NewLFSMetaObject(lfsMetaObject)
 		if err != nil {
 			return nil, err
 		}
-		if opts.SHA != "" {
-			// If a SHA was given and the SHA given doesn't match the SHA of the fromTreePath, throw error
-			if opts.SHA != fromEntry.ID.String() {
-				return nil, models.ErrSHADoesNotMatch{
-					Path:       treePath,
-					GivenSHA:   opts.SHA,
-					CurrentSHA: fromEntry.ID.String(),
+		contentStore := lfs.NewContentStore()
+		if !contentStore.Exists(lfsMetaObject) {
+			if err := contentStore.Put(lfsMetaObject, strings.NewReader(opts.Files[i].Content)); err != nil {
+				if err2 := repo.RemoveLFSMetaObjectByOid(lfsMetaObject.Oid); err2 != nil {
+					return nil, fmt.Errorf("Error whilst removing failed inserted LFS object %s: %v (Prev Error: %v)", lfsMetaObject.Oid, err2, err)
 				}
+				return nil, err
 			}
-		} else if opts.LastCommitID != "" {
-			// If a lastCommitID was given and it doesn't match the commitID of the head of the branch throw
-			// an error, but only if we aren't creating a new branch.
-			if commit.ID.String() != opts.LastCommitID && opts.OldBranch == opts.NewBranch {
-				if changed, err := commit.FileChangedSinceCommit(treePath, opts.LastCommitID); err != nil {
-					return nil, err
-				} else if changed {
-					return nil, models.ErrCommitIDDoesNotMatch{
-						GivenCommitID:   opts.LastCommitID,
-						CurrentCommitID: opts.LastCommitID,
-					}
+		}
+	}
+
+	// Then push this tree to NewBranch
+	if err := t.Push(doer, commitHash, opts.NewBranch); err != nil {
+		return nil, err
+	}
+
+	commit, err = t.GetCommit(commitHash)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, file := range opts.Files {
+		if file.Operation == "delete" {
+			treePaths[i] = ""
+		}
+	}
+	return GetFilesResponseFromCommit(repo, commit, opts.NewBranch, treePaths)
+}
+
+// checkFileIsUnchanged checks that the file at treePath has not been changed
+// since the SHA or the last commit given by the user
+func checkFileIsUnchanged(commit *git.Commit, opts *ChangeRepoFilesOptions, entry *git.TreeEntry, sha, treePath string) error {
+	if sha != "" {
+		// If a SHA was given and the SHA given doesn't match the SHA of the entry, throw error
+		if sha != entry.ID.String() {
+			return models.ErrSHADoesNotMatch{
+				Path:       treePath,
+				GivenSHA:   sha,
+				CurrentSHA: entry.ID.String(),
+			}
+		}
+	} else if opts.LastCommitID != "" {
+		// If a lastCommitID was given and it doesn't match the commitID of the head of the branch throw
+		// an error, but only if we aren't creating a new branch.
+		if commit.ID.String() != opts.LastCommitID && opts.OldBranch == opts.NewBranch {
+			if changed, err := commit.FileChangedSinceCommit(treePath, opts.LastCommitID); err != nil {
+				return err
+			} else if changed {
+				return models.ErrCommitIDDoesNotMatch{
+					GivenCommitID:   opts.LastCommitID,
+					CurrentCommitID: opts.LastCommitID,
 				}
-				// The file wasn't modified, so we are good to delete it
 			}
-		} else {
-			// When updating a file, a lastCommitID or SHA needs to be given to make sure other commits
-			// haven't been made. We throw an error if one wasn't provided.
-			return nil, models.ErrSHAOrCommitIDNotProvided{}
+			// The file wasn't modified, so we are good to change it
+		}
+	} else {
+		// When changing a file, a lastCommitID or SHA needs to be given to make sure other commits
+		// haven't been made. We throw an error if one wasn't provided.
+		return models.ErrSHAOrCommitIDNotProvided{}
+	}
+	return nil
+}
+
+// deleteFileInIndex removes the file from the index of the temporary repository
+func deleteFileInIndex(t *TemporaryUploadRepository, commit *git.Commit, opts *ChangeRepoFilesOptions, file *ChangeRepoFile, treePath string) error {
+	// Get the files in the index
+	filesInIndex, err := t.LsFiles(file.TreePath)
+	if err != nil {
+		return fmt.Errorf("ChangeRepoFiles: %v", err)
+	}
+
+	// Find the file we want to delete in the index
+	inFilelist := false
+	for _, fileInIndex := range filesInIndex {
+		if fileInIndex == file.TreePath {
+			inFilelist = true
+			break
+		}
+	}
+	if !inFilelist {
+		return models.ErrRepoFileDoesNotExist{
+			Path: file.TreePath,
+		}
+	}
+
+	// Get the entry of treePath and check if the SHA given is the same as the file
+	entry, err := commit.GetTreeEntryByPath(treePath)
+	if err != nil {
+		return err
+	}
+	if err := checkFileIsUnchanged(commit, opts, entry, file.SHA, treePath); err != nil {
+		return err
+	}
+
+	// Remove the file from the index
+	return t.RemoveFilesFromIndex(file.TreePath)
+}
+
+// changeFileInIndex creates or updates the file in the index of the temporary
+// repository, the LFS meta object of its content is returned if it must be
+// stored in LFS
+func changeFileInIndex(t *TemporaryUploadRepository, repo *models.Repository, commit *git.Commit, opts *ChangeRepoFilesOptions, file *ChangeRepoFile, treePath string) (*models.LFSMetaObject, error) {
+	isNewFile := file.Operation == "create"
+	fromTreePath := CleanUploadFileName(file.FromTreePath)
+
+	encoding := "UTF-8"
+	bom := false
+
+	if !isNewFile {
+		fromEntry, err := commit.GetTreeEntryByPath(fromTreePath)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkFileIsUnchanged(commit, opts, fromEntry, file.SHA, treePath); err != nil {
+			return nil, err
 		}
 		encoding, bom = detectEncodingAndBOM(fromEntry, repo)
 	}
@@ -277,7 +456,7 @@ func CreateOrUpdateRepoFile(repo *models.Repository, doer *models.User, opts *Up
 				Name:    part,
 				Type:    git.EntryModeTree,
 			}
-		} else if fromTreePath != treePath || opts.IsNewFile {
+		} else if fromTreePath != treePath || isNewFile {
 			// The entry shouldn't exist if we are creating new file or moving to a new path
 			return nil, models.ErrRepoFileAlreadyExists{
 				Path: treePath,
@@ -287,16 +466,16 @@ func CreateOrUpdateRepoFile(repo *models.Repository, doer *models.User, opts *Up
 	}
 
 	// Get the two paths (might be the same if not moving) from the index if they exist
-	filesInIndex, err := t.LsFiles(opts.TreePath, opts.FromTreePath)
+	filesInIndex, err := t.LsFiles(file.TreePath, file.FromTreePath)
 	if err != nil {
 		return nil, fmt.Errorf("UpdateRepoFile: %v", err)
 	}
 	// If is a new file (not updating) then the given path shouldn't exist
-	if opts.IsNewFile {
-		for _, file := range filesInIndex {
-			if file == opts.TreePath {
+	if isNewFile {
+		for _, fileInIndex := range filesInIndex {
+			if fileInIndex == file.TreePath {
 				return nil, models.ErrRepoFileAlreadyExists{
-					Path: opts.TreePath,
+					Path: file.TreePath,
 				}
 			}
 		}
@@ -304,9 +483,9 @@ func CreateOrUpdateRepoFile(repo *models.Repository, doer *models.User, opts *Up
 
 	// Remove the old path from the tree
 	if fromTreePath != treePath && len(filesInIndex) > 0 {
-		for _, file := range filesInIndex {
-			if file == fromTreePath {
-				if err := t.RemoveFilesFromIndex(opts.FromTreePath); err != nil {
+		for _, fileInIndex := range filesInIndex {
+			if fileInIndex == fromTreePath {
+				if err := t.RemoveFilesFromIndex(file.FromTreePath); err != nil {
 					return nil, err
 				}
 			}
@@ -319,7 +498,7 @@ func CreateOrUpdateRepoFile(repo *models.Repository, doer *models.User, opts *Up
 		return nil, err
 	}
 
-	content := opts.Content
+	content := file.Content
 	if bom {
 		content = string(charset.UTF8BOM) + content
 	}
@@ -329,7 +508,7 @@ func CreateOrUpdateRepoFile(repo *models.Repository, doer *models.User, opts *Up
 			result, _, err := transform.String(charsetEncoding.NewEncoder(), content)
 			if err != nil {
 				// Look if we can't encode back in to the original we should just stick with utf-8
-				log.Error("Error re-encoding %s (%s) as %s - will stay as UTF-8: %v", opts.TreePath, opts.FromTreePath, encoding, err)
+				log.Error("Error re-encoding %s (%s) as %s - will stay as UTF-8: %v", file.TreePath, file.FromTreePath, encoding, err)
 				result = content
 			}
 			content = result
@@ -337,17 +516,17 @@ func CreateOrUpdateRepoFile(repo *models.Repository, doer *models.User, opts *Up
 			log.Error("Unknown encoding: %s", encoding)
 		}
 	}
-	// Reset the opts.Content to our adjusted content to ensure that LFS gets the correct content
-	opts.Content = content
+	// Reset the file.Content to our adjusted content to ensure that LFS gets the correct content
+	file.Content = content
 	var lfsMetaObject *models.LFSMetaObject
 
 	if setting.LFS.StartServer && filename2attribute2info[treePath] != nil && filename2attribute2info[treePath]["filter"] == "lfs" {
 		// OK so we are supposed to LFS this data!
-		oid, err := models.GenerateLFSOid(strings.NewReader(opts.Content))
+		oid, err := models.GenerateLFSOid(strings.NewReader(file.Content))
 		if err != nil {
 			return nil, err
 		}
-		lfsMetaObject = &models.LFSMetaObject{Oid: oid, Size: int64(len(opts.Content)), RepositoryID: repo.ID}
+		lfsMetaObject = &models.LFSMetaObject{Oid: oid, Size: int64(len(file.Content)), RepositoryID: repo.ID}
 		content = lfsMetaObject.Pointer()
 	}
 
@@ -361,51 +540,7 @@ func CreateOrUpdateRepoFile(repo *models.Repository, doer *models.User, opts *Up
 	if err := t.AddObjectToIndex("100644", objectHash, treePath); err != nil {
 		return nil, err
 	}
-
-	// Now write the tree
-	treeHash, err := t.WriteTree()
-	if err != nil {
-		return nil, err
-	}
-
-	// Now commit the tree
-	commitHash, err := t.CommitTree(author, committer, treeHash, message)
-	if err != nil {
-		return nil, err
-	}
-
-	if lfsMetaObject != nil {
-		// We have an LFS object - create it
-		lfsMetaObject, err = models.NewLFSMetaObject(lfsMetaObject)
-		if err != nil {
-			return nil, err
-		}
-		contentStore := &lfs.ContentStore{BasePath: setting.LFS.ContentPath}
-		if !contentStore.Exists(lfsMetaObject) {
-			if err := contentStore.Put(lfsMetaObject, strings.NewReader(opts.Content)); err != nil {
-				if err2 := repo.RemoveLFSMetaObjectByOid(lfsMetaObject.Oid); err2 != nil {
-					return nil, fmt.Errorf("Error whilst removing failed inserted LFS object %s: %v (Prev Error: %v)", lfsMetaObject.Oid, err2, err)
-				}
-				return nil, err
-			}
-		}
-	}
-
-	// Then push this tree to NewBranch
-	if err := t.Push(doer, commitHash, opts.NewBranch); err != nil {
-		return nil, err
-	}
-
-	commit, err = t.GetCommit(commitHash)
-	if err != nil {
-		return nil, err
-	}
-
-	file, err := GetFileResponseFromCommit(repo, commit, opts.NewBranch, treePath)
-	if err != nil {
-		return nil, err
-	}
-	return file, nil
+	return lfsMetaObject, nil
 }
 
 // PushUpdate must be called for any push actions in order to
@@ -498,9 +633,12 @@ func PushUpdate(repo *models.Repository, branch string, opts models.PushUpdateOp
 	log.Trace("TriggerTask '%s/%s' by %s", repo.Name, branch, pusher.Name)
 
 	go models.AddTestPullRequestTask(pusher, repo.ID, branch, true)
+	go models.AddPushMirrorsToQueue(repo.ID, true)
+	models.UpdateRepoCommitGraph(repo)
 
 	if opts.RefFullName == git.BranchPrefix+repo.DefaultBranch {
 		models.UpdateRepoIndexer(repo)
+		models.UpdateRepoLanguageStats(repo)
 	}
 	return nil
 }
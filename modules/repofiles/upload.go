@@ -12,7 +12,6 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/lfs"
-	"code.gitea.io/gitea/modules/setting"
 )
 
 // UploadRepoFileOptions contains the uploaded repository file options
@@ -67,10 +66,12 @@ func UploadRepoFiles(repo *models.Repository, doer *models.User, opts *UploadRep
 		return err
 	}
 
+	// The names of the files of an uploaded directory contain their path
+	// relative to opts.TreePath
 	names := make([]string, len(uploads))
 	infos := make([]uploadInfo, len(uploads))
 	for i, upload := range uploads {
-		names[i] = upload.Name
+		names[i] = path.Join(opts.TreePath, upload.Name)
 		infos[i] = uploadInfo{upload: upload}
 	}
 
@@ -88,7 +89,7 @@ func UploadRepoFiles(repo *models.Repository, doer *models.User, opts *UploadRep
 		defer file.Close()
 
 		var objectHash string
-		if filename2attribute2info[uploadInfo.upload.Name] != nil && filename2attribute2info[uploadInfo.upload.Name]["filter"] == "lfs" {
+		if filename2attribute2info[names[i]] != nil && filename2attribute2info[names[i]]["filter"] == "lfs" {
 			// Handle LFS
 			// FIXME: Inefficient! this should probably happen in models.Upload
 			oid, err := models.GenerateLFSOid(file)
@@ -112,7 +113,7 @@ func UploadRepoFiles(repo *models.Repository, doer *models.User, opts *UploadRep
 		}
 
 		// Add the object to the index
-		if err := t.AddObjectToIndex("100644", objectHash, path.Join(opts.TreePath, uploadInfo.upload.Name)); err != nil {
+		if err := t.AddObjectToIndex("100644", objectHash, names[i]); err != nil {
 			return err
 
 		}
@@ -150,7 +151,7 @@ func UploadRepoFiles(repo *models.Repository, doer *models.User, opts *UploadRep
 
 	// OK now we can insert the data into the store - there's no way to clean up the store
 	// once it's in there, it's in there.
-	contentStore := &lfs.ContentStore{BasePath: setting.LFS.ContentPath}
+	contentStore := lfs.NewContentStore()
 	for _, uploadInfo := range infos {
 		if uploadInfo.lfsMetaObject == nil {
 			continue
@@ -19,9 +19,20 @@ import (
 type Result struct {
 	RepoID         int64
 	Filename       string
+	Language       string
 	HighlightClass string
 	LineNumbers    []int
 	FormattedLines gotemplate.HTML
+	Lines          []*ResultLine
+}
+
+// ResultLine a line of a search result, the matched part of the line is
+// Content[HighlightStart:HighlightEnd]
+type ResultLine struct {
+	Number         int
+	Content        string
+	HighlightStart int
+	HighlightEnd   int
 }
 
 func indices(content string, selectionStartIndex, selectionEndIndex int) (int, int) {
@@ -67,14 +78,21 @@ func searchResult(result *indexer.RepoSearchResult, startIndex, endIndex int) (*
 
 	contentLines := strings.SplitAfter(result.Content[startIndex:endIndex], "\n")
 	lineNumbers := make([]int, len(contentLines))
+	lines := make([]*ResultLine, len(contentLines))
 	index := startIndex
 	for i, line := range contentLines {
 		var err error
+		lines[i] = &ResultLine{
+			Number:  startLineNum + i,
+			Content: strings.TrimSuffix(line, "\n"),
+		}
 		if index < result.EndIndex &&
 			result.StartIndex < index+len(line) &&
 			result.StartIndex < result.EndIndex {
 			openActiveIndex := util.Max(result.StartIndex-index, 0)
 			closeActiveIndex := util.Min(result.EndIndex-index, len(line))
+			lines[i].HighlightStart = openActiveIndex
+			lines[i].HighlightEnd = util.Min(closeActiveIndex, len(lines[i].Content))
 			err = writeStrings(&formattedLinesBuffer,
 				`<li>`,
 				html.EscapeString(line[:openActiveIndex]),
@@ -101,31 +119,38 @@ func searchResult(result *indexer.RepoSearchResult, startIndex, endIndex int) (*
 	return &Result{
 		RepoID:         result.RepoID,
 		Filename:       result.Filename,
+		Language:       result.Language,
 		HighlightClass: highlight.FileNameToHighlightClass(result.Filename),
 		LineNumbers:    lineNumbers,
 		FormattedLines: gotemplate.HTML(formattedLinesBuffer.String()),
+		Lines:          lines,
 	}, nil
 }
 
-// PerformSearch perform a search on a repository
-func PerformSearch(repoIDs []int64, keyword string, page, pageSize int) (int, []*Result, error) {
+// PerformSearch perform a search on a repository, only the files written in
+// the language are returned if it is not empty. The number of matching files
+// per language is returned together with the results.
+func PerformSearch(repoIDs []int64, keyword, language string, page, pageSize int) (int, []*Result, []*indexer.SearchResultLanguages, error) {
 	if len(keyword) == 0 {
-		return 0, nil, nil
+		return 0, nil, nil, nil
 	}
 
-	total, results, err := indexer.SearchRepoByKeyword(repoIDs, keyword, page, pageSize)
+	total, results, languages, err := indexer.SearchRepoByKeyword(repoIDs, keyword, language, page, pageSize)
 	if err != nil {
-		return 0, nil, err
+		return 0, nil, nil, err
 	}
 
 	displayResults := make([]*Result, len(results))
 
 	for i, result := range results {
+		if result.StartIndex < 0 || result.EndIndex < 0 {
+			result.StartIndex, result.EndIndex = 0, 0
+		}
 		startIndex, endIndex := indices(result.Content, result.StartIndex, result.EndIndex)
 		displayResults[i], err = searchResult(result, startIndex, endIndex)
 		if err != nil {
-			return 0, nil, err
+			return 0, nil, nil, err
 		}
 	}
-	return int(total), displayResults, nil
+	return int(total), displayResults, languages, nil
 }
@@ -23,6 +23,7 @@ var (
 		MaxGitDiffFiles           int
 		GCArgs                    []string `ini:"GC_ARGS" delim:" "`
 		EnableAutoGitWireProtocol bool
+		DisablePartialClone       bool
 		Timeout                   struct {
 			Default int
 			Migrate int
@@ -64,6 +65,7 @@ func newGit() {
 		log.Fatal("Failed to initialize Git settings", err)
 	}
 	git.DefaultCommandExecutionTimeout = time.Duration(Git.Timeout.Default) * time.Second
+	git.EnablePartialClone = !Git.DisablePartialClone
 
 	binVersion, err := git.BinVersion()
 	if err != nil {
@@ -80,6 +82,7 @@ func newGit() {
 	// Since git wire protocol has been released from git v2.18
 	if Git.EnableAutoGitWireProtocol && version.Compare(binVersion, "2.18", ">=") {
 		git.GlobalCommandArgs = append(git.GlobalCommandArgs, "-c", "protocol.version=2")
+		git.EnableProtocolV2 = true
 		format += ", Wire Protocol %s Enabled"
 		args = append(args, "Version 2") // for focus color
 	}
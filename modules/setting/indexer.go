@@ -21,8 +21,13 @@ var (
 	Indexer = struct {
 		IssueType             string
 		IssuePath             string
+		IssueConnStr          string
+		IssueIndexerName      string
 		RepoIndexerEnabled    bool
+		RepoType              string
 		RepoPath              string
+		RepoConnStr           string
+		RepoIndexerName       string
 		UpdateQueueLength     int
 		MaxIndexerFileSize    int64
 		IssueQueueType        string
@@ -32,6 +37,12 @@ var (
 	}{
 		IssueType:             "bleve",
 		IssuePath:             "indexers/issues.bleve",
+		IssueConnStr:          "",
+		IssueIndexerName:      "gitea_issues",
+		RepoType:              "bleve",
+		RepoPath:              "indexers/repos.bleve",
+		RepoConnStr:           "",
+		RepoIndexerName:       "gitea_codes",
 		IssueQueueType:        LevelQueueType,
 		IssueQueueDir:         "indexers/issues.queue",
 		IssueQueueConnStr:     "",
@@ -39,18 +50,24 @@ var (
 	}
 )
 
-func newIndexerService() {
+// NewIndexerService initializes the indexer settings
+func NewIndexerService() {
 	sec := Cfg.Section("indexer")
 	Indexer.IssueType = sec.Key("ISSUE_INDEXER_TYPE").MustString("bleve")
 	Indexer.IssuePath = sec.Key("ISSUE_INDEXER_PATH").MustString(path.Join(AppDataPath, "indexers/issues.bleve"))
 	if !filepath.IsAbs(Indexer.IssuePath) {
 		Indexer.IssuePath = path.Join(AppWorkPath, Indexer.IssuePath)
 	}
+	Indexer.IssueConnStr = sec.Key("ISSUE_INDEXER_CONN_STR").MustString(Indexer.IssueConnStr)
+	Indexer.IssueIndexerName = sec.Key("ISSUE_INDEXER_NAME").MustString(Indexer.IssueIndexerName)
 	Indexer.RepoIndexerEnabled = sec.Key("REPO_INDEXER_ENABLED").MustBool(false)
+	Indexer.RepoType = sec.Key("REPO_INDEXER_TYPE").MustString("bleve")
 	Indexer.RepoPath = sec.Key("REPO_INDEXER_PATH").MustString(path.Join(AppDataPath, "indexers/repos.bleve"))
 	if !filepath.IsAbs(Indexer.RepoPath) {
 		Indexer.RepoPath = path.Join(AppWorkPath, Indexer.RepoPath)
 	}
+	Indexer.RepoConnStr = sec.Key("REPO_INDEXER_CONN_STR").MustString(Indexer.RepoConnStr)
+	Indexer.RepoIndexerName = sec.Key("REPO_INDEXER_NAME").MustString(Indexer.RepoIndexerName)
 	Indexer.UpdateQueueLength = sec.Key("UPDATE_BUFFER_LEN").MustInt(20)
 	Indexer.MaxIndexerFileSize = sec.Key("MAX_FILE_SIZE").MustInt64(1024 * 1024)
 	Indexer.IssueQueueType = sec.Key("ISSUE_INDEXER_QUEUE_TYPE").MustString(LevelQueueType)
@@ -0,0 +1,27 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+var (
+	// Quota settings, the limits are in bytes and -1 means unlimited. They
+	// are the default quotas of the users and organizations, which can be
+	// overridden by the admins for each of them.
+	Quota = struct {
+		DefaultGitSizeLimit        int64
+		DefaultLFSSizeLimit        int64
+		DefaultAttachmentSizeLimit int64
+	}{
+		DefaultGitSizeLimit:        -1,
+		DefaultLFSSizeLimit:        -1,
+		DefaultAttachmentSizeLimit: -1,
+	}
+)
+
+func newQuota() {
+	sec := Cfg.Section("quota")
+	Quota.DefaultGitSizeLimit = sec.Key("DEFAULT_GIT_SIZE_LIMIT").MustInt64(-1)
+	Quota.DefaultLFSSizeLimit = sec.Key("DEFAULT_LFS_SIZE_LIMIT").MustInt64(-1)
+	Quota.DefaultAttachmentSizeLimit = sec.Key("DEFAULT_ATTACHMENT_SIZE_LIMIT").MustInt64(-1)
+}
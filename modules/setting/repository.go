@@ -21,12 +21,21 @@ const (
 	RepoCreatingPublic             = "public"
 )
 
+// IssueKeywords are the keywords of a language used in commit messages and
+// pull request descriptions to close or reopen issues
+type IssueKeywords struct {
+	CloseKeywords  []string
+	ReopenKeywords []string
+}
+
 // Repository settings
 var (
 	Repository = struct {
 		AnsiCharset                             string
 		ForcePrivate                            bool
 		DefaultPrivate                          string
+		DefaultBranch                           string
+		DefaultAutoInit                         bool
 		MaxCreationLimit                        int
 		MirrorQueueLength                       int
 		PullRequestQueueLength                  int
@@ -35,6 +44,7 @@ var (
 		AccessControlAllowOrigin                string
 		UseCompatSSHURI                         bool
 		DefaultCloseIssuesViaCommitsInAnyBranch bool
+		ReadmeExtensions                        []string
 
 		// Repository editor settings
 		Editor struct {
@@ -63,12 +73,35 @@ var (
 
 		// Issue Setting
 		Issue struct {
-			LockReasons []string
+			LockReasons    []string
+			CloseKeywords  []string
+			ReopenKeywords []string
+			// Additional keywords by locale, e.g. de-DE
+			LocaleKeywords map[string]IssueKeywords `ini:"-"`
 		} `ini:"repository.issue"`
+
+		// Signing settings
+		Signing struct {
+			SigningKey   string
+			SigningName  string
+			SigningEmail string
+			Merges       string
+			TrustModel   string
+		} `ini:"repository.signing"`
+
+		// Push policy settings, enforced on the commits pushed to branches and tags
+		PushPolicy struct {
+			MaxFileSize                  int64
+			ForbiddenFilePatterns        []string
+			RequireSignedCommits         bool
+			AllowedCommitterEmailDomains []string
+		} `ini:"repository.push_policy"`
 	}{
 		AnsiCharset:                             "",
 		ForcePrivate:                            false,
 		DefaultPrivate:                          RepoCreatingLastUserVisibility,
+		DefaultBranch:                           "master",
+		DefaultAutoInit:                         false,
 		MaxCreationLimit:                        -1,
 		MirrorQueueLength:                       1000,
 		PullRequestQueueLength:                  1000,
@@ -77,6 +110,7 @@ var (
 		AccessControlAllowOrigin:                "",
 		UseCompatSSHURI:                         false,
 		DefaultCloseIssuesViaCommitsInAnyBranch: false,
+		ReadmeExtensions:                        strings.Split(".md,.adoc,.rst,.txt,", ","),
 
 		// Repository editor settings
 		Editor: struct {
@@ -118,9 +152,42 @@ var (
 
 		// Issue settings
 		Issue: struct {
-			LockReasons []string
+			LockReasons    []string
+			CloseKeywords  []string
+			ReopenKeywords []string
+			LocaleKeywords map[string]IssueKeywords `ini:"-"`
 		}{
 			LockReasons: strings.Split("Too heated,Off-topic,Spam,Resolved", ","),
+			// Same as GitHub. See
+			// https://help.github.com/articles/closing-issues-via-commit-messages
+			CloseKeywords:  strings.Split("close,closes,closed,fix,fixes,fixed,resolve,resolves,resolved", ","),
+			ReopenKeywords: strings.Split("reopen,reopens,reopened", ","),
+		},
+
+		// Signing settings
+		Signing: struct {
+			SigningKey   string
+			SigningName  string
+			SigningEmail string
+			Merges       string
+			TrustModel   string
+		}{
+			SigningKey: "default",
+			Merges:     "always",
+			TrustModel: "committer",
+		},
+
+		// Push policy settings
+		PushPolicy: struct {
+			MaxFileSize                  int64
+			ForbiddenFilePatterns        []string
+			RequireSignedCommits         bool
+			AllowedCommitterEmailDomains []string
+		}{
+			MaxFileSize:                  -1,
+			ForbiddenFilePatterns:        []string{},
+			RequireSignedCommits:         false,
+			AllowedCommitterEmailDomains: []string{},
 		},
 	}
 	RepoRootPath string
@@ -158,9 +225,25 @@ func newRepository() {
 		log.Fatal("Failed to map Repository.Local settings: %v", err)
 	} else if err = Cfg.Section("repository.pull-request").MapTo(&Repository.PullRequest); err != nil {
 		log.Fatal("Failed to map Repository.PullRequest settings: %v", err)
+	} else if err = Cfg.Section("repository.signing").MapTo(&Repository.Signing); err != nil {
+		log.Fatal("Failed to map Repository.Signing settings: %v", err)
+	} else if err = Cfg.Section("repository.push_policy").MapTo(&Repository.PushPolicy); err != nil {
+		log.Fatal("Failed to map Repository.PushPolicy settings: %v", err)
+	}
+
+	Repository.Issue.LocaleKeywords = make(map[string]IssueKeywords)
+	for _, sec := range Cfg.ChildSections("repository.issue.keywords") {
+		var keywords IssueKeywords
+		if err = sec.MapTo(&keywords); err != nil {
+			log.Fatal("Failed to map %s settings: %v", sec.Name(), err)
+		}
+		Repository.Issue.LocaleKeywords[strings.TrimPrefix(sec.Name(), "repository.issue.keywords.")] = keywords
 	}
 
 	if !filepath.IsAbs(Repository.Upload.TempPath) {
 		Repository.Upload.TempPath = path.Join(AppWorkPath, Repository.Upload.TempPath)
 	}
+	if len(Repository.DefaultBranch) == 0 {
+		Repository.DefaultBranch = "master"
+	}
 }
@@ -290,6 +290,10 @@ var (
 		DefaultPagingNum       int
 		DefaultGitTreesPerPage int
 		DefaultMaxBlobSize     int64
+		EnableGraphQL          bool
+		GraphQLMaxComplexity   int
+		GraphQLMaxDepth        int
+		GraphQLMaxQueryLength  int
 	}{
 		EnableSwagger:          true,
 		SwaggerURL:             "",
@@ -297,6 +301,10 @@ var (
 		DefaultPagingNum:       30,
 		DefaultGitTreesPerPage: 1000,
 		DefaultMaxBlobSize:     10485760,
+		EnableGraphQL:          true,
+		GraphQLMaxComplexity:   5000,
+		GraphQLMaxDepth:        10,
+		GraphQLMaxQueryLength:  10240,
 	}
 
 	OAuth2 = struct {
@@ -304,6 +312,8 @@ var (
 		AccessTokenExpirationTime  int64
 		RefreshTokenExpirationTime int64
 		InvalidateRefreshTokens    bool
+		DeviceCodeExpirationTime   int64
+		DeviceCodePollingInterval  int64
 		JWTSecretBytes             []byte `ini:"-"`
 		JWTSecretBase64            string `ini:"JWT_SECRET"`
 	}{
@@ -311,6 +321,8 @@ var (
 		AccessTokenExpirationTime:  3600,
 		RefreshTokenExpirationTime: 730,
 		InvalidateRefreshTokens:    false,
+		DeviceCodeExpirationTime:   900,
+		DeviceCodePollingInterval:  5,
 	}
 
 	U2F = struct {
@@ -910,8 +922,23 @@ func NewContext() {
 	u.Path = path.Join(u.Path, "api", "swagger")
 	API.SwaggerURL = u.String()
 
+	// The limits of the GraphQL queries can not be disabled
+	if API.GraphQLMaxComplexity < 1 {
+		log.Warn("API.GraphQLMaxComplexity is too low")
+		API.GraphQLMaxComplexity = 5000
+	}
+	if API.GraphQLMaxDepth < 1 {
+		log.Warn("API.GraphQLMaxDepth is too low")
+		API.GraphQLMaxDepth = 10
+	}
+	if API.GraphQLMaxQueryLength < 1 {
+		log.Warn("API.GraphQLMaxQueryLength is too low")
+		API.GraphQLMaxQueryLength = 10240
+	}
+
 	newCron()
 	newGit()
+	newQuota()
 
 	sec = Cfg.Section("mirror")
 	Mirror.MinInterval = sec.Key("MIN_INTERVAL").MustDuration(10 * time.Minute)
@@ -1042,5 +1069,6 @@ func NewServices() {
 	newRegisterMailService()
 	newNotifyMailService()
 	newWebhookService()
-	newIndexerService()
+	NewIndexerService()
+	NewStorageService()
 }
@@ -0,0 +1,71 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// enumerates all the storage types
+const (
+	LocalStorageType = "local"
+	S3StorageType    = "s3"
+)
+
+// S3StorageConfig represents the settings of an S3 compatible object storage
+type S3StorageConfig struct {
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+	Bucket          string
+	Location        string
+	BasePath        string
+	UseSSL          bool
+}
+
+var (
+	// Storage settings, the local storage keeps the LFS objects, attachments
+	// and avatars in their own paths while the S3 storage keeps them in one
+	// bucket under different prefixes
+	Storage = struct {
+		Type string
+		S3   S3StorageConfig
+		// ServeDirect redirects the downloads of the attachments to the
+		// storage if it supports it
+		ServeDirect bool
+	}{
+		Type: LocalStorageType,
+		S3: S3StorageConfig{
+			Bucket:   "gitea",
+			Location: "us-east-1",
+			UseSSL:   true,
+		},
+	}
+)
+
+// NewStorageService initializes the storage settings
+func NewStorageService() {
+	sec := Cfg.Section("storage")
+	Storage.Type = strings.ToLower(sec.Key("STORAGE_TYPE").MustString(LocalStorageType))
+	switch Storage.Type {
+	case LocalStorageType:
+	case S3StorageType:
+		Storage.S3.Endpoint = sec.Key("S3_ENDPOINT").String()
+		Storage.S3.AccessKeyID = sec.Key("S3_ACCESS_KEY_ID").String()
+		Storage.S3.SecretAccessKey = sec.Key("S3_SECRET_ACCESS_KEY").String()
+		Storage.S3.Bucket = sec.Key("S3_BUCKET").MustString(Storage.S3.Bucket)
+		Storage.S3.Location = sec.Key("S3_LOCATION").MustString(Storage.S3.Location)
+		Storage.S3.BasePath = strings.Trim(sec.Key("S3_BASE_PATH").String(), "/")
+		Storage.S3.UseSSL = sec.Key("S3_USE_SSL").MustBool(Storage.S3.UseSSL)
+		Storage.ServeDirect = sec.Key("SERVE_DIRECT").MustBool(false)
+		if len(Storage.S3.Endpoint) == 0 {
+			log.Fatal("S3_ENDPOINT is required with the s3 storage type")
+		}
+	default:
+		log.Fatal("Unknown storage type: %s", Storage.Type)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"syscall"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 
@@ -70,6 +71,15 @@ func sessionHandler(session ssh.Session) {
 		"SSH_ORIGINAL_COMMAND="+command,
 		"SKIP_MINWINSVC=1",
 	)
+	// The clients request the git wire protocol version 2 through the
+	// environment of the session
+	for _, kv := range session.Environ() {
+		if strings.HasPrefix(kv, git.EnvGitProtocol+"=") {
+			if protocolEnv := git.GitProtocolEnv(strings.TrimPrefix(kv, git.EnvGitProtocol+"=")); len(protocolEnv) > 0 {
+				cmd.Env = append(cmd.Env, protocolEnv)
+			}
+		}
+	}
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -0,0 +1,79 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+var _ ObjectStorage = &LocalStorage{}
+
+// LocalStorage represents a storage keeping the objects as files of a directory
+type LocalStorage struct {
+	dir string
+}
+
+// NewLocalStorage creates the storage of the directory, creating the
+// directory if needed
+func NewLocalStorage(dir string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{dir: dir}, nil
+}
+
+func (l *LocalStorage) filePath(p string) string {
+	return filepath.Join(l.dir, filepath.FromSlash(filepath.Clean("/"+p)))
+}
+
+// Open opens the file of the object
+func (l *LocalStorage) Open(p string) (Object, error) {
+	return os.Open(l.filePath(p))
+}
+
+// Save writes the content to a temporary file which is renamed to the file
+// of the object once complete
+func (l *LocalStorage) Save(p string, r io.Reader, size int64) (int64, error) {
+	filePath := l.filePath(p)
+	if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+		return 0, err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(filePath), filepath.Base(filePath)+".tmp")
+	if err != nil {
+		return 0, err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	written, err := io.Copy(tmp, r)
+	if err != nil {
+		tmp.Close()
+		return written, err
+	}
+	if err = tmp.Close(); err != nil {
+		return written, err
+	}
+	if err = os.Chmod(tmpPath, 0640); err != nil {
+		return written, err
+	}
+	return written, os.Rename(tmpPath, filePath)
+}
+
+// Stat returns the information of the file of the object
+func (l *LocalStorage) Stat(p string) (os.FileInfo, error) {
+	return os.Stat(l.filePath(p))
+}
+
+// Delete removes the file of the object
+func (l *LocalStorage) Delete(p string) error {
+	if err := os.Remove(l.filePath(p)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
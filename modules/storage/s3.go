@@ -0,0 +1,190 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/minio/minio-go/v6"
+)
+
+var (
+	_ ObjectStorage = &S3Storage{}
+	_ URLStorage    = &S3Storage{}
+)
+
+// quoteEscaper escapes the quoted strings of the headers
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// S3Storage represents a storage keeping the objects in a bucket of an S3
+// compatible object storage like Amazon S3 or MinIO, the content is streamed
+// through Gitea.
+type S3Storage struct {
+	cfg    setting.S3StorageConfig
+	client *minio.Client
+	// partSize is the size of the parts of the multipart uploads, the default
+	// one of the client is used if it is 0
+	partSize uint64
+}
+
+// NewS3Storage creates the storage of the bucket, creating the bucket if it
+// does not exist
+func NewS3Storage(cfg setting.S3StorageConfig) (*S3Storage, error) {
+	if len(cfg.Endpoint) == 0 || len(cfg.Bucket) == 0 {
+		return nil, errors.New("the endpoint and the bucket are required")
+	}
+	client, err := minio.NewWithRegion(cfg.Endpoint, cfg.AccessKeyID, cfg.SecretAccessKey, cfg.UseSSL, cfg.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := client.BucketExists(cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("bucket %s: %v", cfg.Bucket, err)
+	}
+	if !exists {
+		if err = client.MakeBucket(cfg.Bucket, cfg.Location); err != nil {
+			return nil, fmt.Errorf("create bucket %s: %v", cfg.Bucket, err)
+		}
+	}
+	return &S3Storage{cfg: cfg, client: client}, nil
+}
+
+// objectKey returns the key of the object in the bucket
+func (s *S3Storage) objectKey(p string) string {
+	return strings.TrimPrefix(path.Join(s.cfg.BasePath, path.Clean("/"+p)), "/")
+}
+
+// s3Error returns the error of a failed request, the missing objects are
+// reported as os.ErrNotExist
+func s3Error(op, key string, err error) error {
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NotFound":
+		return &os.PathError{Op: op, Path: key, Err: os.ErrNotExist}
+	}
+	return &os.PathError{Op: op, Path: key, Err: err}
+}
+
+// Open opens the object, its content is requested when it is read
+func (s *S3Storage) Open(p string) (Object, error) {
+	key := s.objectKey(p)
+	fi, err := s.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.client.GetObject(s.cfg.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, s3Error("open", key, err)
+	}
+	return &s3Object{Object: obj, key: key, info: fi.(*s3FileInfo)}, nil
+}
+
+// Save uploads the content as the object, in parts if it is large. The content
+// of unknown size is buffered in a temporary file first rather than in memory.
+func (s *S3Storage) Save(p string, r io.Reader, size int64) (int64, error) {
+	if size < 0 {
+		tmp, err := ioutil.TempFile("", "gitea-s3")
+		if err != nil {
+			return 0, err
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+
+		if size, err = io.Copy(tmp, r); err != nil {
+			return 0, err
+		}
+		if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+			return 0, err
+		}
+		r = tmp
+	}
+
+	key := s.objectKey(p)
+	written, err := s.client.PutObject(s.cfg.Bucket, key, r, size, minio.PutObjectOptions{
+		ContentType: "application/octet-stream",
+		PartSize:    s.partSize,
+	})
+	if err != nil {
+		return written, s3Error("save", key, err)
+	}
+	return written, nil
+}
+
+// Stat returns the information of the object
+func (s *S3Storage) Stat(p string) (os.FileInfo, error) {
+	key := s.objectKey(p)
+	info, err := s.client.StatObject(s.cfg.Bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, s3Error("stat", key, err)
+	}
+	return &s3FileInfo{
+		name:    path.Base(key),
+		size:    info.Size,
+		modTime: info.LastModified,
+	}, nil
+}
+
+// URL returns a presigned URL of the object, the object is downloaded as an
+// attachment of the name
+func (s *S3Storage) URL(p, name string, expires time.Duration) (*url.URL, error) {
+	reqParams := make(url.Values)
+	reqParams.Set("response-content-disposition", fmt.Sprintf(`attachment; filename="%s"`, quoteEscaper.Replace(name)))
+	return s.client.PresignedGetObject(s.cfg.Bucket, s.objectKey(p), expires, reqParams)
+}
+
+// Delete deletes the object
+func (s *S3Storage) Delete(p string) error {
+	key := s.objectKey(p)
+	if err := s.client.RemoveObject(s.cfg.Bucket, key); err != nil {
+		if err = s3Error("delete", key, err); !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// s3FileInfo represents the information of an object of an S3 storage
+type s3FileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (fi *s3FileInfo) Name() string       { return fi.name }
+func (fi *s3FileInfo) Size() int64        { return fi.size }
+func (fi *s3FileInfo) Mode() os.FileMode  { return 0644 }
+func (fi *s3FileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *s3FileInfo) IsDir() bool        { return false }
+func (fi *s3FileInfo) Sys() interface{}   { return nil }
+
+// s3Object represents an object of an S3 storage, its content is requested
+// from the current offset on the first read after opening or seeking.
+type s3Object struct {
+	*minio.Object
+	key  string
+	info *s3FileInfo
+}
+
+func (o *s3Object) Read(p []byte) (int, error) {
+	n, err := o.Object.Read(p)
+	if err != nil && err != io.EOF {
+		return n, s3Error("read", o.key, err)
+	}
+	return n, err
+}
+
+func (o *s3Object) Stat() (os.FileInfo, error) {
+	return o.info, nil
+}
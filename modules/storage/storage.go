@@ -0,0 +1,105 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// Object represents an object of a storage which is read like a file
+type Object interface {
+	io.ReadCloser
+	io.Seeker
+	Stat() (os.FileInfo, error)
+}
+
+// ObjectStorage represents a storage of objects identified by slash separated
+// paths. The errors of the missing objects satisfy os.IsNotExist.
+type ObjectStorage interface {
+	// Open opens the object for reading
+	Open(path string) (Object, error)
+	// Save stores the content of the reader as the object, the object is
+	// replaced atomically and left untouched if the reader fails. The size is
+	// -1 if it is unknown.
+	Save(path string, r io.Reader, size int64) (int64, error)
+	// Stat returns the information of the object
+	Stat(path string) (os.FileInfo, error)
+	// Delete deletes the object, deleting a missing object is not an error
+	Delete(path string) error
+}
+
+// URLStorage is implemented by the storages whose objects can be downloaded
+// directly from them rather than through Gitea
+type URLStorage interface {
+	// URL returns a URL downloading the object as a file of the name, which
+	// expires after the duration
+	URL(path, name string, expires time.Duration) (*url.URL, error)
+}
+
+var (
+	// LFS represents the storage of the Git LFS objects
+	LFS ObjectStorage
+	// Attachments represents the storage of the attachments of issues and releases
+	Attachments ObjectStorage
+	// Avatars represents the storage of the custom avatars of the users
+	Avatars ObjectStorage
+	// RepoAvatars represents the storage of the custom avatars of the repositories
+	RepoAvatars ObjectStorage
+)
+
+// NewStorage creates the storage of the type configured in the settings, the
+// local storage keeps the objects in the directory and the S3 storage keeps
+// them under the prefix.
+func NewStorage(dir, prefix string) (ObjectStorage, error) {
+	switch setting.Storage.Type {
+	case setting.LocalStorageType:
+		return NewLocalStorage(dir)
+	case setting.S3StorageType:
+		cfg := setting.Storage.S3
+		cfg.BasePath = path.Join(cfg.BasePath, prefix)
+		return NewS3Storage(cfg)
+	default:
+		return nil, fmt.Errorf("unknown storage type: %s", setting.Storage.Type)
+	}
+}
+
+// Init initializes the storages of the LFS objects, attachments and avatars
+func Init() (err error) {
+	if LFS, err = NewStorage(setting.LFS.ContentPath, "lfs"); err != nil {
+		return fmt.Errorf("LFS storage: %v", err)
+	}
+	if Attachments, err = NewStorage(setting.AttachmentPath, "attachments"); err != nil {
+		return fmt.Errorf("attachments storage: %v", err)
+	}
+	if Avatars, err = NewStorage(setting.AvatarUploadPath, "avatars"); err != nil {
+		return fmt.Errorf("avatars storage: %v", err)
+	}
+	if RepoAvatars, err = NewStorage(setting.RepositoryAvatarUploadPath, "repo-avatars"); err != nil {
+		return fmt.Errorf("repository avatars storage: %v", err)
+	}
+	return nil
+}
+
+// Copy copies the object of the source storage to the destination storage
+func Copy(dst ObjectStorage, dstPath string, src ObjectStorage, srcPath string) (int64, error) {
+	obj, err := src.Open(srcPath)
+	if err != nil {
+		return 0, err
+	}
+	defer obj.Close()
+
+	size := int64(-1)
+	if fi, err := obj.Stat(); err == nil {
+		size = fi.Size()
+	}
+	return dst.Save(dstPath, obj, size)
+}
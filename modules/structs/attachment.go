@@ -25,3 +25,29 @@ type Attachment struct {
 type EditAttachmentOptions struct {
 	Name string `json:"name"`
 }
+
+// AttachmentUpload an attachment of a release uploaded in chunks
+// swagger:model
+type AttachmentUpload struct {
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+	// size of the attachment in bytes
+	Size int64 `json:"size"`
+	// number of bytes already uploaded, the next chunk starts at this offset
+	Offset int64 `json:"offset"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CreateAttachmentUploadOptions options for starting the upload of an
+// attachment in chunks
+// swagger:model
+type CreateAttachmentUploadOptions struct {
+	// required: true
+	Name string `json:"name" binding:"Required"`
+	// size of the attachment in bytes
+	// required: true
+	Size int64 `json:"size"`
+}
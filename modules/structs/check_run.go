@@ -0,0 +1,93 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// CheckRun represents a run of an external check of a commit
+type CheckRun struct {
+	ID      int64  `json:"id"`
+	URL     string `json:"url"`
+	HeadSHA string `json:"head_sha"`
+	Name    string `json:"name"`
+	// enum: queued,in_progress,completed
+	Status string `json:"status"`
+	// enum: success,failure,neutral,cancelled,timed_out,action_required,skipped
+	Conclusion string          `json:"conclusion"`
+	ExternalID string          `json:"external_id"`
+	DetailsURL string          `json:"details_url"`
+	Output     *CheckRunOutput `json:"output"`
+	Creator    *User           `json:"creator"`
+	// swagger:strfmt date-time
+	Started *time.Time `json:"started_at"`
+	// swagger:strfmt date-time
+	Completed *time.Time `json:"completed_at"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CheckRunOutput represents the result of a check run
+type CheckRunOutput struct {
+	Title string `json:"title"`
+	// summary of the result, rendered as markdown
+	Summary string `json:"summary"`
+	// annotations of the check run, only used to create or update check runs
+	Annotations []*CheckRunAnnotation `json:"annotations,omitempty"`
+}
+
+// CheckRunAnnotation represents a message of a check run about lines of a file
+type CheckRunAnnotation struct {
+	// path of the file relative to the root of the repository
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line"`
+	// last line of annotations spanning several lines, defaults to the start line
+	EndLine int `json:"end_line"`
+	// enum: notice,warning,failure
+	Level   string `json:"annotation_level"`
+	Title   string `json:"title"`
+	Message string `json:"message"`
+}
+
+// CreateCheckRunOption options to create a check run
+type CreateCheckRunOption struct {
+	// required: true
+	Name string `json:"name" binding:"Required"`
+	// required: true
+	HeadSHA string `json:"head_sha" binding:"Required"`
+	// defaults to queued, or to completed if a conclusion is given
+	// enum: queued,in_progress,completed
+	Status string `json:"status"`
+	// required if the status is completed
+	// enum: success,failure,neutral,cancelled,timed_out,action_required,skipped
+	Conclusion string          `json:"conclusion"`
+	ExternalID string          `json:"external_id"`
+	DetailsURL string          `json:"details_url"`
+	Output     *CheckRunOutput `json:"output"`
+	// swagger:strfmt date-time
+	Started *time.Time `json:"started_at"`
+	// swagger:strfmt date-time
+	Completed *time.Time `json:"completed_at"`
+}
+
+// EditCheckRunOption options to update a check run, the annotations are
+// added to the existing ones
+type EditCheckRunOption struct {
+	Name *string `json:"name"`
+	// enum: queued,in_progress,completed
+	Status *string `json:"status"`
+	// enum: success,failure,neutral,cancelled,timed_out,action_required,skipped
+	Conclusion *string         `json:"conclusion"`
+	ExternalID *string         `json:"external_id"`
+	DetailsURL *string         `json:"details_url"`
+	Output     *CheckRunOutput `json:"output"`
+	// swagger:strfmt date-time
+	Started *time.Time `json:"started_at"`
+	// swagger:strfmt date-time
+	Completed *time.Time `json:"completed_at"`
+}
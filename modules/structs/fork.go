@@ -9,3 +9,18 @@ type CreateForkOption struct {
 	// organization name, if forking into an organization
 	Organization *string `json:"organization"`
 }
+
+// ForkSyncInfo represents how many commits a branch of a fork is ahead or
+// behind its upstream branch
+type ForkSyncInfo struct {
+	Branch         string `json:"branch"`
+	UpstreamBranch string `json:"upstream_branch"`
+	Ahead          int    `json:"ahead"`
+	Behind         int    `json:"behind"`
+}
+
+// SyncForkOption options for synchronizing a branch of a fork with upstream
+type SyncForkOption struct {
+	// name of the branch, the default branch if empty
+	Branch string `json:"branch"`
+}
@@ -89,10 +89,13 @@ type PayloadCommit struct {
 
 // PayloadCommitVerification represents the GPG verification of a commit
 type PayloadCommitVerification struct {
-	Verified  bool   `json:"verified"`
-	Reason    string `json:"reason"`
-	Signature string `json:"signature"`
-	Payload   string `json:"payload"`
+	Verified bool `json:"verified"`
+	// TrustStatus is trusted or untrusted if the signature is verified,
+	// according to the trust model of the instance
+	TrustStatus string `json:"trust_status"`
+	Reason      string `json:"reason"`
+	Signature   string `json:"signature"`
+	Payload     string `json:"payload"`
 }
 
 var (
@@ -281,6 +284,81 @@ func (p *ReleasePayload) JSONPayload() ([]byte, error) {
 	return json.MarshalIndent(p, "", "  ")
 }
 
+// .____          ___.          .__
+// |    |   _____ \_ |__   ____ |  |
+// |    |   \__  \ | __ \_/ __ \|  |
+// |    |___ / __ \| \_\ \  ___/|  |__
+// |_______ (____  /___  /\___  >____/
+//         \/    \/    \/     \/
+
+// HookLabelAction defines hook label action type
+type HookLabelAction string
+
+// all label actions
+const (
+	HookLabelCreated HookLabelAction = "created"
+	HookLabelEdited  HookLabelAction = "edited"
+	HookLabelDeleted HookLabelAction = "deleted"
+)
+
+// LabelPayload represents a payload information of label event.
+type LabelPayload struct {
+	Secret     string          `json:"secret"`
+	Action     HookLabelAction `json:"action"`
+	Label      *Label          `json:"label"`
+	Changes    *ChangesPayload `json:"changes,omitempty"`
+	Repository *Repository     `json:"repository"`
+	Sender     *User           `json:"sender"`
+}
+
+// SetSecret modifies the secret of the LabelPayload
+func (p *LabelPayload) SetSecret(secret string) {
+	p.Secret = secret
+}
+
+// JSONPayload implements Payload
+func (p *LabelPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+//  __      __.__ __   .__
+// /  \    /  \__|  | _|__|
+// \   \/\/   /  |  |/ /  |
+//  \        /|  |    <|  |
+//   \__/\  / |__|__|_ \__|
+//        \/          \/
+
+// HookWikiAction defines hook wiki action type
+type HookWikiAction string
+
+// all wiki actions
+const (
+	HookWikiCreated HookWikiAction = "created"
+	HookWikiEdited  HookWikiAction = "edited"
+	HookWikiDeleted HookWikiAction = "deleted"
+)
+
+// WikiPayload represents a payload information of wiki event.
+type WikiPayload struct {
+	Secret     string          `json:"secret"`
+	Action     HookWikiAction  `json:"action"`
+	Page       string          `json:"page"`
+	Comment    string          `json:"comment"`
+	Changes    *ChangesPayload `json:"changes,omitempty"`
+	Repository *Repository     `json:"repository"`
+	Sender     *User           `json:"sender"`
+}
+
+// SetSecret modifies the secret of the WikiPayload
+func (p *WikiPayload) SetSecret(secret string) {
+	p.Secret = secret
+}
+
+// JSONPayload implements Payload
+func (p *WikiPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
 // __________             .__
 // \______   \__ __  _____|  |__
 //  |     ___/  |  \/  ___/  |  \
@@ -398,6 +476,9 @@ type ChangesFromPayload struct {
 type ChangesPayload struct {
 	Title *ChangesFromPayload `json:"title,omitempty"`
 	Body  *ChangesFromPayload `json:"body,omitempty"`
+	Name  *ChangesFromPayload `json:"name,omitempty"`
+	Color *ChangesFromPayload `json:"color,omitempty"`
+	Owner *ChangesFromPayload `json:"owner,omitempty"`
 }
 
 // __________      .__  .__    __________                                     __
@@ -443,15 +524,18 @@ const (
 	HookRepoCreated HookRepoAction = "created"
 	// HookRepoDeleted deleted
 	HookRepoDeleted HookRepoAction = "deleted"
+	// HookRepoTransferred transferred to another owner
+	HookRepoTransferred HookRepoAction = "transferred"
 )
 
 // RepositoryPayload payload for repository webhooks
 type RepositoryPayload struct {
-	Secret       string         `json:"secret"`
-	Action       HookRepoAction `json:"action"`
-	Repository   *Repository    `json:"repository"`
-	Organization *User          `json:"organization"`
-	Sender       *User          `json:"sender"`
+	Secret       string          `json:"secret"`
+	Action       HookRepoAction  `json:"action"`
+	Repository   *Repository     `json:"repository"`
+	Organization *User           `json:"organization"`
+	Changes      *ChangesPayload `json:"changes,omitempty"`
+	Sender       *User           `json:"sender"`
 }
 
 // SetSecret modifies the secret of the RepositoryPayload
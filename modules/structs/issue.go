@@ -113,3 +113,47 @@ type EditPriorityOption struct {
 	// required:true
 	Priority int `json:"priority"`
 }
+
+// MoveIssueOption options for moving an issue to another repository
+type MoveIssueOption struct {
+	// owner of the repository to move the issue to
+	// required:true
+	Owner string `json:"owner" binding:"Required"`
+	// name of the repository to move the issue to
+	// required:true
+	Repo string `json:"repo" binding:"Required"`
+}
+
+// IssueReminder represents a reminder a user has set on an issue
+type IssueReminder struct {
+	ID    int64  `json:"id"`
+	Issue *Issue `json:"issue"`
+	// swagger:strfmt date-time
+	RemindAt time.Time `json:"remind_at"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// CreateIssueReminderOption options for setting a reminder on an issue
+type CreateIssueReminderOption struct {
+	// swagger:strfmt date-time
+	RemindAt *time.Time `json:"remind_at"`
+	// remind after the given number of days, used if remind_at is not set
+	Days int64 `json:"days"`
+}
+
+// IssueSLA represents the response and resolution times of an issue and the
+// targets set by its labels
+type IssueSLA struct {
+	Issue *Issue `json:"issue"`
+	// swagger:strfmt date-time
+	FirstResponse *time.Time `json:"first_response_at"`
+	// seconds until the first response, or until now if there is none yet
+	ResponseTime        int64 `json:"response_time"`
+	ResponseTargetHours int64 `json:"response_target_hours"`
+	ResponseBreached    bool  `json:"response_breached"`
+	// seconds until the issue has been closed, or until now if it is open
+	ResolutionTime        int64 `json:"resolution_time"`
+	ResolutionTargetHours int64 `json:"resolution_target_hours"`
+	ResolutionBreached    bool  `json:"resolution_breached"`
+}
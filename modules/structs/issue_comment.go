@@ -35,3 +35,52 @@ type EditIssueCommentOption struct {
 	// required: true
 	Body string `json:"body" binding:"Required"`
 }
+
+// IssueContentRevision represents a revision of the body of an issue or comment
+type IssueContentRevision struct {
+	ID     int64  `json:"id"`
+	Editor *User  `json:"editor"`
+	Body   string `json:"body"`
+	// whether this is the body the issue or comment has been created with
+	IsFirst bool `json:"is_first"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// TimelineComment represents an event on the timeline of an issue or pull request
+type TimelineComment struct {
+	ID int64 `json:"id"`
+	// type of the event, e.g. "comment", "label", "assignees" or "review"
+	Type     string `json:"type"`
+	HTMLURL  string `json:"html_url"`
+	PRURL    string `json:"pull_request_url"`
+	IssueURL string `json:"issue_url"`
+	Poster   *User  `json:"user"`
+	Body     string `json:"body"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+
+	Label           *Label     `json:"label,omitempty"`
+	RemovedLabel    bool       `json:"removed_label,omitempty"`
+	OldMilestone    *Milestone `json:"old_milestone,omitempty"`
+	Milestone       *Milestone `json:"milestone,omitempty"`
+	Assignee        *User      `json:"assignee,omitempty"`
+	RemovedAssignee bool       `json:"removed_assignee,omitempty"`
+	OldTitle        string     `json:"old_title,omitempty"`
+	NewTitle        string     `json:"new_title,omitempty"`
+	OldRef          string     `json:"old_ref,omitempty"`
+	NewRef          string     `json:"new_ref,omitempty"`
+	DependentIssue  *Issue     `json:"dependent_issue,omitempty"`
+
+	// the issue or pull request which references this one
+	RefIssue     *Issue `json:"ref_issue,omitempty"`
+	RefCommentID int64  `json:"ref_comment_id,omitempty"`
+	RefCommitSHA string `json:"ref_commit_sha,omitempty"`
+
+	ReviewID    int64           `json:"review_id,omitempty"`
+	ReviewState ReviewStateType `json:"review_state,omitempty"`
+	Path        string          `json:"path,omitempty"`
+	Line        int64           `json:"line,omitempty"`
+}
@@ -13,7 +13,12 @@ type Label struct {
 	// example: 00aabb
 	Color       string `json:"color"`
 	Description string `json:"description"`
+	Priority    int    `json:"priority"`
 	URL         string `json:"url"`
+	// hours until the first response to an issue with the label is due, 0 if there is no target
+	ResponseTargetHours int64 `json:"response_target_hours"`
+	// hours until an issue with the label should be resolved, 0 if there is no target
+	ResolutionTargetHours int64 `json:"resolution_target_hours"`
 }
 
 // CreateLabelOption options for creating a label
@@ -24,6 +29,11 @@ type CreateLabelOption struct {
 	// example: #00aabb
 	Color       string `json:"color" binding:"Required;Size(7)"`
 	Description string `json:"description"`
+	Priority    int    `json:"priority"`
+	// hours until the first response to an issue with the label is due
+	ResponseTargetHours int64 `json:"response_target_hours"`
+	// hours until an issue with the label should be resolved
+	ResolutionTargetHours int64 `json:"resolution_target_hours"`
 }
 
 // EditLabelOption options for editing a label
@@ -31,6 +41,11 @@ type EditLabelOption struct {
 	Name        *string `json:"name"`
 	Color       *string `json:"color"`
 	Description *string `json:"description"`
+	Priority    *int    `json:"priority"`
+	// hours until the first response to an issue with the label is due, 0 removes the target
+	ResponseTargetHours *int64 `json:"response_target_hours"`
+	// hours until an issue with the label should be resolved, 0 removes the target
+	ResolutionTargetHours *int64 `json:"resolution_target_hours"`
 }
 
 // IssueLabelsOption a collection of labels
@@ -37,3 +37,19 @@ type EditMilestoneOption struct {
 	State       *string    `json:"state"`
 	Deadline    *time.Time `json:"due_on"`
 }
+
+// MilestoneBurndownDay the number of open and closed issues of a milestone at the end of a day
+type MilestoneBurndownDay struct {
+	// swagger:strfmt date
+	Date   string `json:"date"`
+	Open   int    `json:"open"`
+	Closed int    `json:"closed"`
+}
+
+// MilestoneBurndown the progress of a milestone over time
+type MilestoneBurndown struct {
+	MilestoneID int64                   `json:"milestone_id"`
+	Days        []*MilestoneBurndownDay `json:"days"`
+	// swagger:strfmt date-time
+	EstimatedCompletion *time.Time `json:"estimated_completion"`
+}
@@ -39,3 +39,24 @@ type EditOrgOption struct {
 	// enum: public,limited,private
 	Visibility string `json:"visibility" binding:"In(,public,limited,private)"`
 }
+
+// AssigneeWorkload represents the open issues and pull requests assigned to a user
+type AssigneeWorkload struct {
+	Assignee   *User                `json:"assignee"`
+	OpenIssues int64                `json:"open_issues"`
+	OpenPulls  int64                `json:"open_pulls"`
+	Labels     []*LabelWorkload     `json:"labels"`
+	Milestones []*MilestoneWorkload `json:"milestones"`
+}
+
+// LabelWorkload represents the number of open issues and pull requests with a label
+type LabelWorkload struct {
+	Label *Label `json:"label"`
+	Count int64  `json:"count"`
+}
+
+// MilestoneWorkload represents the number of open issues and pull requests in a milestone
+type MilestoneWorkload struct {
+	Milestone *Milestone `json:"milestone"`
+	Count     int64      `json:"count"`
+}
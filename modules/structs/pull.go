@@ -29,6 +29,10 @@ type PullRequest struct {
 
 	Mergeable bool `json:"mergeable"`
 	HasMerged bool `json:"merged"`
+	// whether the pull request is a draft, which can not be merged
+	Draft bool `json:"draft"`
+	// whether users who can write to the base repository can push to the head branch
+	AllowMaintainerEdit bool `json:"allow_maintainer_edit"`
 	// swagger:strfmt date-time
 	Merged         *time.Time `json:"merged_at"`
 	MergedCommitID *string    `json:"merge_commit_sha"`
@@ -76,6 +80,13 @@ type CreatePullRequestOption struct {
 	Labels    []int64  `json:"labels"`
 	// swagger:strfmt date-time
 	Deadline *time.Time `json:"due_date"`
+	// create the pull request as a draft, which is not ready for review
+	Draft bool `json:"draft"`
+	// allow users who can write to the base repository to push to the head branch
+	AllowMaintainerEdit bool `json:"allow_maintainer_edit"`
+	// file name of the pull request template in the template directory used
+	// if the body is empty, the default pull request template is used otherwise
+	Template string `json:"template"`
 }
 
 // EditPullRequestOption options when modify pull request
@@ -89,4 +100,12 @@ type EditPullRequestOption struct {
 	State     *string  `json:"state"`
 	// swagger:strfmt date-time
 	Deadline *time.Time `json:"due_date"`
+	// false marks a draft pull request as ready for review
+	Draft *bool `json:"draft"`
+	// allow users who can write to the base repository to push to the head
+	// branch, can only be changed by the poster
+	AllowMaintainerEdit *bool `json:"allow_maintainer_edit"`
+	// name of the new base branch, e.g. the head branch of another pull
+	// request to stack this one on
+	Base string `json:"base"`
 }
@@ -0,0 +1,23 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// MergeQueueEntry represents a pull request waiting in the merge queue of its base branch
+type MergeQueueEntry struct {
+	ID int64 `json:"id"`
+	// index of the pull request
+	Index int64  `json:"number"`
+	Base  string `json:"base"`
+	// place in the queue starting with 1
+	Position   int    `json:"position"`
+	MergeStyle string `json:"merge_style"`
+	Enqueuer   *User  `json:"enqueuer"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
@@ -0,0 +1,119 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// ReviewStateType review state type
+type ReviewStateType string
+
+const (
+	// ReviewStateApproved pr is approved
+	ReviewStateApproved ReviewStateType = "APPROVED"
+	// ReviewStatePending pr state is pending
+	ReviewStatePending ReviewStateType = "PENDING"
+	// ReviewStateComment is a comment review
+	ReviewStateComment ReviewStateType = "COMMENT"
+	// ReviewStateRequestChanges changes for pr are requested
+	ReviewStateRequestChanges ReviewStateType = "REQUEST_CHANGES"
+	// ReviewStateRequestReview review is requested from user
+	ReviewStateRequestReview ReviewStateType = "REQUEST_REVIEW"
+	// ReviewStateUnknown state of pr is unknown
+	ReviewStateUnknown ReviewStateType = ""
+)
+
+// PullReview represents a pull request review
+type PullReview struct {
+	ID             int64           `json:"id"`
+	Reviewer       *User           `json:"user"`
+	State          ReviewStateType `json:"state"`
+	Body           string          `json:"body"`
+	CodeComments   int64           `json:"comments_count"`
+	HTMLURL        string          `json:"html_url"`
+	PullRequestURL string          `json:"pull_request_url"`
+	// true if the approval no longer counts because new commits have been pushed
+	Dismissed bool `json:"dismissed"`
+	// swagger:strfmt date-time
+	Submitted time.Time `json:"submitted_at"`
+}
+
+// CreatePullReviewOptions are options to create a pull review
+type CreatePullReviewOptions struct {
+	// the review is kept pending if no event is given
+	Event    ReviewStateType           `json:"event"`
+	Body     string                    `json:"body"`
+	Comments []CreatePullReviewComment `json:"comments"`
+}
+
+// CreatePullReviewComment represent a review comment for creation api
+type CreatePullReviewComment struct {
+	// the tree path
+	Path string `json:"path"`
+	Body string `json:"body"`
+	// if comment to old file line or 0
+	OldLineNum int64 `json:"old_position"`
+	// if comment to new file line or 0
+	NewLineNum int64 `json:"new_position"`
+	// first line of a comment spanning several lines of the old file or 0
+	StartOldLineNum int64 `json:"old_start_position"`
+	// first line of a comment spanning several lines of the new file or 0
+	StartNewLineNum int64 `json:"new_start_position"`
+}
+
+// PullReviewComment represents a code comment of a pull request review
+type PullReviewComment struct {
+	ID       int64  `json:"id"`
+	Body     string `json:"body"`
+	Reviewer *User  `json:"user"`
+	ReviewID int64  `json:"pull_request_review_id"`
+	Path     string `json:"path"`
+	CommitID string `json:"commit_id"`
+	DiffHunk string `json:"diff_hunk"`
+	// line of the old file or 0
+	OldLineNum int64 `json:"old_position"`
+	// line of the new file or 0
+	NewLineNum int64 `json:"new_position"`
+	// first line of a comment spanning several lines of the old file or 0
+	StartOldLineNum int64 `json:"old_start_position"`
+	// first line of a comment spanning several lines of the new file or 0
+	StartNewLineNum int64 `json:"new_start_position"`
+	// the user who resolved the conversation of the comment, null if it is not resolved
+	Resolver       *User  `json:"resolver"`
+	HTMLURL        string `json:"html_url"`
+	PullRequestURL string `json:"pull_request_url"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// SubmitPullReviewOptions are options to submit a pending pull review
+type SubmitPullReviewOptions struct {
+	Event ReviewStateType `json:"event"`
+	Body  string          `json:"body"`
+}
+
+// PullViewedFile represents a changed file of a pull request marked as viewed by the user
+type PullViewedFile struct {
+	Path string `json:"path"`
+	// true if the file has been changed since it has been marked as viewed
+	Outdated bool `json:"outdated"`
+}
+
+// SuggestedReviewer represents a user who last changed some of the lines modified by a pull request
+type SuggestedReviewer struct {
+	User *User `json:"user"`
+	// number of the modified lines last changed by the user
+	Lines int `json:"lines"`
+}
+
+// MarkPullFileViewedOption options to mark a changed file of a pull request as viewed
+type MarkPullFileViewedOption struct {
+	// required: true
+	Path   string `json:"path" binding:"Required"`
+	Viewed bool   `json:"viewed"`
+}
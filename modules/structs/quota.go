@@ -0,0 +1,40 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// Quota represents the disk space quotas and usage of a user or an
+// organization, the sizes are in bytes
+type Quota struct {
+	// size of the repositories owned by the user
+	GitSize int64 `json:"git_size"`
+	// limit of the size of the repositories in effect, -1 if unlimited
+	GitSizeLimit int64 `json:"git_size_limit"`
+	// limit of the size of the repositories set for the user, -1 if the
+	// default limit of the server is used
+	MaxGitSize int64 `json:"max_git_size"`
+	// size of the LFS objects of the repositories owned by the user
+	LFSSize int64 `json:"lfs_size"`
+	// limit of the size of the LFS objects in effect, -1 if unlimited
+	LFSSizeLimit int64 `json:"lfs_size_limit"`
+	// limit of the size of the LFS objects set for the user, -1 if the
+	// default limit of the server is used
+	MaxLFSSize int64 `json:"max_lfs_size"`
+	// size of the attachments uploaded by the user
+	AttachmentSize int64 `json:"attachment_size"`
+	// limit of the size of the attachments in effect, -1 if unlimited
+	AttachmentSizeLimit int64 `json:"attachment_size_limit"`
+	// limit of the size of the attachments set for the user, -1 if the
+	// default limit of the server is used
+	MaxAttachmentSize int64 `json:"max_attachment_size"`
+}
+
+// EditQuotaOption options when editing the quotas of a user or an
+// organization, the sizes are in bytes and -1 uses the default limit of the
+// server
+type EditQuotaOption struct {
+	MaxGitSize        *int64 `json:"max_git_size"`
+	MaxLFSSize        *int64 `json:"max_lfs_size"`
+	MaxAttachmentSize *int64 `json:"max_attachment_size"`
+}
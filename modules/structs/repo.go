@@ -42,17 +42,70 @@ type Repository struct {
 	// swagger:strfmt date-time
 	Created time.Time `json:"created_at"`
 	// swagger:strfmt date-time
-	Updated                   time.Time   `json:"updated_at"`
-	Permissions               *Permission `json:"permissions,omitempty"`
-	HasIssues                 bool        `json:"has_issues"`
-	HasWiki                   bool        `json:"has_wiki"`
-	HasPullRequests           bool        `json:"has_pull_requests"`
-	IgnoreWhitespaceConflicts bool        `json:"ignore_whitespace_conflicts"`
-	AllowMerge                bool        `json:"allow_merge_commits"`
-	AllowRebase               bool        `json:"allow_rebase"`
-	AllowRebaseMerge          bool        `json:"allow_rebase_explicit"`
-	AllowSquash               bool        `json:"allow_squash_merge"`
-	AvatarURL                 string      `json:"avatar_url"`
+	Updated                      time.Time        `json:"updated_at"`
+	Permissions                  *Permission      `json:"permissions,omitempty"`
+	HasIssues                    bool             `json:"has_issues"`
+	InternalTracker              *InternalTracker `json:"internal_tracker,omitempty"`
+	ExternalTracker              *ExternalTracker `json:"external_tracker,omitempty"`
+	HasWiki                      bool             `json:"has_wiki"`
+	ExternalWiki                 *ExternalWiki    `json:"external_wiki,omitempty"`
+	HasPullRequests              bool             `json:"has_pull_requests"`
+	IgnoreWhitespaceConflicts    bool             `json:"ignore_whitespace_conflicts"`
+	AllowMerge                   bool             `json:"allow_merge_commits"`
+	AllowRebase                  bool             `json:"allow_rebase"`
+	AllowRebaseMerge             bool             `json:"allow_rebase_explicit"`
+	AllowSquash                  bool             `json:"allow_squash_merge"`
+	AllowFastForwardOnly         bool             `json:"allow_fast_forward_only_merge"`
+	CancelAutoMergeOnPush        bool             `json:"cancel_auto_merge_on_push"`
+	DefaultMergeMessageTemplate  string           `json:"default_merge_message_template"`
+	DefaultSquashMessageTemplate string           `json:"default_squash_message_template"`
+	MaxChangedFiles              int              `json:"max_changed_files"`
+	MaxChangedLines              int              `json:"max_changed_lines"`
+	BlockOversizedMerge          bool             `json:"block_oversized_merge"`
+	CommitMessageSubjectPattern  string           `json:"commit_message_subject_pattern"`
+	CommitMessageMaxLineLength   int              `json:"commit_message_max_line_length"`
+	CommitMessageRequireIssueRef bool             `json:"commit_message_require_issue_ref"`
+	ReviewerTeamID               int64            `json:"reviewer_team_id"`
+	ReviewerAssignmentStrategy   string           `json:"reviewer_assignment_strategy"`
+	ReviewerCount                int              `json:"reviewer_count"`
+	ReviewerAssignmentSkipDrafts bool             `json:"reviewer_assignment_skip_drafts"`
+	AvatarURL                    string           `json:"avatar_url"`
+	// interval between two syncs of a mirror, `0s` if it is only synced manually
+	MirrorInterval string `json:"mirror_interval,omitempty"`
+	// swagger:strfmt date-time
+	MirrorUpdated *time.Time `json:"mirror_updated,omitempty"`
+	// output of the last sync of a mirror if it failed
+	MirrorLastError string `json:"mirror_last_error,omitempty"`
+	// whether new repositories can be generated from the repository
+	Template bool `json:"template"`
+	// id of the template repository the repository has been generated from, `0` if it has not been generated
+	TemplateID int64 `json:"template_id,omitempty"`
+}
+
+// InternalTracker the settings of the built-in issue tracker of a repository
+type InternalTracker struct {
+	// whether the time spent on issues can be tracked
+	EnableTimeTracker bool `json:"enable_time_tracker"`
+	// whether only the contributors can track time
+	AllowOnlyContributorsToTrackTime bool `json:"allow_only_contributors_to_track_time"`
+	// whether issues and pull requests can depend on each other
+	EnableIssueDependencies bool `json:"enable_issue_dependencies"`
+}
+
+// ExternalTracker the settings of the external issue tracker of a repository
+type ExternalTracker struct {
+	// URL of the external issue tracker
+	ExternalTrackerURL string `json:"external_tracker_url"`
+	// URL of the issues of the external tracker, use the placeholders {user}, {repo} and {index} for the owner name, the repository name and the issue index
+	ExternalTrackerFormat string `json:"external_tracker_format"`
+	// style of the references to the issues of the external tracker, either `numeric` or `alphanumeric`
+	ExternalTrackerStyle string `json:"external_tracker_style"`
+}
+
+// ExternalWiki the settings of the external wiki of a repository
+type ExternalWiki struct {
+	// URL of the external wiki
+	ExternalWikiURL string `json:"external_wiki_url"`
 }
 
 // CreateRepoOption options when creating repository
@@ -65,16 +118,18 @@ type CreateRepoOption struct {
 	Name string `json:"name" binding:"Required;AlphaDashDot;MaxSize(100)"`
 	// Description of the repository to create
 	Description string `json:"description" binding:"MaxSize(255)"`
-	// Whether the repository is private
-	Private bool `json:"private"`
-	// Whether the repository should be auto-intialized?
-	AutoInit bool `json:"auto_init"`
-	// Gitignores to use
+	// Whether the repository is private, the default of the owner if not set
+	Private *bool `json:"private,omitempty"`
+	// Whether the repository should be auto-intialized? The default of the owner if not set
+	AutoInit *bool `json:"auto_init,omitempty"`
+	// Gitignores to use, the ones of the owner if the repository is auto-initialized by default
 	Gitignores string `json:"gitignores"`
-	// License to use
+	// License to use, the one of the owner if the repository is auto-initialized by default
 	License string `json:"license"`
 	// Readme of the repository to create
 	Readme string `json:"readme"`
+	// Name of the default branch of the repository, the default of the owner if empty
+	DefaultBranch string `json:"default_branch" binding:"OmitEmpty;GitRefName;MaxSize(100)"`
 }
 
 // EditRepoOption options when editing a repository's properties
@@ -93,8 +148,14 @@ type EditRepoOption struct {
 	Private *bool `json:"private,omitempty"`
 	// either `true` to enable issues for this repository or `false` to disable them.
 	HasIssues *bool `json:"has_issues,omitempty"`
+	// set this structure to configure the built-in issue tracker. `has_issues` must be `true`.
+	InternalTracker *InternalTracker `json:"internal_tracker,omitempty"`
+	// set this structure to use an external issue tracker instead of the built-in one. `has_issues` must be `true`.
+	ExternalTracker *ExternalTracker `json:"external_tracker,omitempty"`
 	// either `true` to enable the wiki for this repository or `false` to disable it.
 	HasWiki *bool `json:"has_wiki,omitempty"`
+	// set this structure to use an external wiki instead of the built-in one. `has_wiki` must be `true`.
+	ExternalWiki *ExternalWiki `json:"external_wiki,omitempty"`
 	// sets the default branch for this repository.
 	DefaultBranch *string `json:"default_branch,omitempty"`
 	// either `true` to allow pull requests, or `false` to prevent pull request.
@@ -109,8 +170,66 @@ type EditRepoOption struct {
 	AllowRebaseMerge *bool `json:"allow_rebase_explicit,omitempty"`
 	// either `true` to allow squash-merging pull requests, or `false` to prevent squash-merging. `has_pull_requests` must be `true`.
 	AllowSquash *bool `json:"allow_squash_merge,omitempty"`
+	// either `true` to allow fast-forwarding the base branch without rebasing, or `false` to prevent it. `has_pull_requests` must be `true`.
+	AllowFastForwardOnly *bool `json:"allow_fast_forward_only_merge,omitempty"`
+	// either `true` to cancel scheduled automatic merges of pull requests when new commits are pushed, or `false` to keep them. `has_pull_requests` must be `true`.
+	CancelAutoMergeOnPush *bool `json:"cancel_auto_merge_on_push,omitempty"`
+	// template of the default message of merge commits, `${PullRequestTitle}` and the other variables are expanded. `has_pull_requests` must be `true`.
+	DefaultMergeMessageTemplate *string `json:"default_merge_message_template,omitempty"`
+	// template of the default message of squashed commits, `${PullRequestTitle}` and the other variables are expanded. `has_pull_requests` must be `true`.
+	DefaultSquashMessageTemplate *string `json:"default_squash_message_template,omitempty"`
+	// maximum number of files changed by a pull request, `0` means unlimited. `has_pull_requests` must be `true`.
+	MaxChangedFiles *int `json:"max_changed_files,omitempty"`
+	// maximum number of lines changed by a pull request, `0` means unlimited. `has_pull_requests` must be `true`.
+	MaxChangedLines *int `json:"max_changed_lines,omitempty"`
+	// either `true` to block merging pull requests which exceed the size limits, or `false` to only show a warning. `has_pull_requests` must be `true`.
+	BlockOversizedMerge *bool `json:"block_oversized_merge,omitempty"`
+	// regular expression the subject of the commit messages of pull requests must match, empty to disable the rule. `has_pull_requests` must be `true`.
+	CommitMessageSubjectPattern *string `json:"commit_message_subject_pattern,omitempty"`
+	// maximum length of the lines of the commit messages of pull requests, `0` means unlimited. `has_pull_requests` must be `true`.
+	CommitMessageMaxLineLength *int `json:"commit_message_max_line_length,omitempty"`
+	// either `true` to require the commit messages of pull requests to reference an issue, or `false` to not require it. `has_pull_requests` must be `true`.
+	CommitMessageRequireIssueRef *bool `json:"commit_message_require_issue_ref,omitempty"`
+	// id of the team of the owner organization whose members are assigned as reviewers of new pull requests, `0` to disable the assignment. `has_pull_requests` must be `true`.
+	ReviewerTeamID *int64 `json:"reviewer_team_id,omitempty"`
+	// either `round_robin` to assign the members whose review has been requested least recently, or `load_balance` to assign the members with the fewest pending review requests. `has_pull_requests` must be `true`.
+	ReviewerAssignmentStrategy *string `json:"reviewer_assignment_strategy,omitempty"`
+	// number of reviewers assigned to new pull requests. `has_pull_requests` must be `true`.
+	ReviewerCount *int `json:"reviewer_count,omitempty"`
+	// either `true` to assign the reviewers of draft pull requests when they are marked as ready for review, or `false` to assign them when they are opened. `has_pull_requests` must be `true`.
+	ReviewerAssignmentSkipDrafts *bool `json:"reviewer_assignment_skip_drafts,omitempty"`
 	// set to `true` to archive this repository.
 	Archived *bool `json:"archived,omitempty"`
+	// interval between two syncs of a mirror like `8h`, `0` to only sync it manually. The repository must be a mirror.
+	MirrorInterval *string `json:"mirror_interval,omitempty"`
+	// either `true` to allow generating new repositories from this repository, or `false` to prevent it.
+	Template *bool `json:"template,omitempty"`
+}
+
+// GenerateRepoOption options when generating a repository from a template
+// swagger:model
+type GenerateRepoOption struct {
+	// The organization or person who will own the new repository
+	//
+	// required: true
+	Owner string `json:"owner"`
+	// Name of the repository to create
+	//
+	// required: true
+	// unique: true
+	Name string `json:"name" binding:"Required;AlphaDashDot;MaxSize(100)"`
+	// Description of the repository to create
+	Description string `json:"description" binding:"MaxSize(255)"`
+	// Whether the repository is private
+	Private bool `json:"private"`
+	// include git content of the default branch in the template repo, the variables of the files listed in `.gitea/template` are expanded
+	GitContent bool `json:"git_content"`
+	// include topics in the template repo
+	Topics bool `json:"topics"`
+	// include labels in the template repo, the default labels are used otherwise
+	Labels bool `json:"labels"`
+	// include webhooks in the template repo
+	Webhooks bool `json:"webhooks"`
 }
 
 // MigrateRepoOption options for migrating a repository from an external service
@@ -127,3 +246,16 @@ type MigrateRepoOption struct {
 	Private     bool   `json:"private"`
 	Description string `json:"description"`
 }
+
+// TransferRepoOption options when transferring a repository's ownership
+// swagger:model
+type TransferRepoOption struct {
+	// The user or organization who will own the repository, the transfer is
+	// pending until they accept it
+	//
+	// required: true
+	NewOwner string `json:"new_owner" binding:"Required"`
+	// ID of the teams of the new owner organization which will have access
+	// to the repository
+	TeamIDs *[]int64 `json:"team_ids"`
+}
@@ -0,0 +1,36 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// FileBlame the lines of a file grouped by the commits which last changed
+// them
+type FileBlame struct {
+	Path string `json:"path"`
+	// the commit the file is blamed at
+	SHA    string        `json:"sha"`
+	Ranges []*BlameRange `json:"ranges"`
+}
+
+// BlameRange consecutive lines of a file last changed by the same commit
+type BlameRange struct {
+	StartingLine int          `json:"starting_line"`
+	EndingLine   int          `json:"ending_line"`
+	Commit       *BlameCommit `json:"commit"`
+	// the parent of the commit and the path of the file in it, to blame the
+	// file prior to the commit. They are empty if the lines were added by a
+	// root commit.
+	PreviousSHA  string   `json:"previous_sha,omitempty"`
+	PreviousPath string   `json:"previous_path,omitempty"`
+	Lines        []string `json:"lines"`
+}
+
+// BlameCommit the commit which last changed lines of a file
+type BlameCommit struct {
+	*CommitMeta
+	HTMLURL   string      `json:"html_url"`
+	Message   string      `json:"message"`
+	Author    *CommitUser `json:"author"`
+	Committer *CommitUser `json:"committer"`
+}
@@ -9,3 +9,30 @@ type Branch struct {
 	Name   string         `json:"name"`
 	Commit *PayloadCommit `json:"commit"`
 }
+
+// StaleBranch represents a branch which is merged into the default branch or
+// has no recent commits
+type StaleBranch struct {
+	Name   string         `json:"name"`
+	Commit *PayloadCommit `json:"commit"`
+	// true if all the commits of the branch are in the default branch
+	IsMerged bool `json:"is_merged"`
+	// true if the branch has no commits for the requested number of days
+	IsInactive         bool `json:"is_inactive"`
+	IsProtected        bool `json:"is_protected"`
+	HasOpenPullRequest bool `json:"has_open_pull_request"`
+	// false if the branch is protected or has an open pull request
+	CanDelete bool `json:"can_delete"`
+}
+
+// DeleteStaleBranchesOption options for deleting stale branches
+type DeleteStaleBranchesOption struct {
+	// names of the branches to delete, the ones which are not stale, are
+	// protected or have an open pull request are kept
+	//
+	// required: true
+	Branches []string `json:"branches" binding:"Required"`
+	// number of days without commits after which a branch is inactive, 0 to
+	// only delete merged branches
+	Days int `json:"days"`
+}
@@ -0,0 +1,36 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// CodeSearchResults results of a search in the code of the repositories
+type CodeSearchResults struct {
+	TotalCount int64                 `json:"total_count"`
+	Items      []*CodeSearchResult   `json:"items"`
+	Languages  []*CodeSearchLanguage `json:"languages"`
+}
+
+// CodeSearchResult a file matching a code search
+type CodeSearchResult struct {
+	Path       string                  `json:"path"`
+	Language   string                  `json:"language"`
+	Repository *Repository             `json:"repository"`
+	HTMLURL    string                  `json:"html_url"`
+	Lines      []*CodeSearchResultLine `json:"lines"`
+}
+
+// CodeSearchResultLine a line of a file matching a code search, the matched
+// part of the line is content[highlight_start:highlight_end]
+type CodeSearchResultLine struct {
+	Number         int    `json:"number"`
+	Content        string `json:"content"`
+	HighlightStart int    `json:"highlight_start"`
+	HighlightEnd   int    `json:"highlight_end"`
+}
+
+// CodeSearchLanguage the number of files of a language matching a code search
+type CodeSearchLanguage struct {
+	Language string `json:"language"`
+	Count    int    `json:"count"`
+}
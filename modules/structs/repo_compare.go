@@ -0,0 +1,30 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// Compare represents the comparison of two branches, tags or commits, which
+// might belong to different repositories of the same fork network
+type Compare struct {
+	BaseCommit     string         `json:"base_commit"`
+	HeadCommit     string         `json:"head_commit"`
+	MergeBase      string         `json:"merge_base"`
+	TotalCommits   int            `json:"total_commits"`
+	Commits        []*Commit      `json:"commits"`
+	TotalFiles     int            `json:"total_files"`
+	TotalAdditions int            `json:"total_additions"`
+	TotalDeletions int            `json:"total_deletions"`
+	Files          []*CompareFile `json:"files"`
+}
+
+// CompareFile represents the number of lines added and deleted in a file
+// changed by a comparison
+type CompareFile struct {
+	Filename string `json:"filename"`
+	// only set for renamed and copied files
+	PreviousFilename string `json:"previous_filename,omitempty"`
+	Additions        int    `json:"additions"`
+	Deletions        int    `json:"deletions"`
+	IsBinary         bool   `json:"is_binary"`
+}
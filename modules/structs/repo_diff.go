@@ -0,0 +1,75 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// Diff represents the changes between two commits
+type Diff struct {
+	// number of all changed files, even if the files are paginated
+	TotalFiles int `json:"total_files"`
+	// number of added lines, only of the included files if the files are paginated
+	TotalAdditions int `json:"total_additions"`
+	// number of deleted lines, only of the included files if the files are paginated
+	TotalDeletions int         `json:"total_deletions"`
+	Files          []*DiffFile `json:"files"`
+	// true if not all changed files are included
+	IsIncomplete bool `json:"is_incomplete"`
+}
+
+// DiffFile represents the changes of a file
+type DiffFile struct {
+	Name    string `json:"name"`
+	OldName string `json:"old_name"`
+	// one of added, changed, deleted or renamed
+	Status      string `json:"status"`
+	Additions   int    `json:"additions"`
+	Deletions   int    `json:"deletions"`
+	IsBinary    bool   `json:"is_binary"`
+	IsSubmodule bool   `json:"is_submodule"`
+	// true if the file is marked as generated by the .gitattributes file
+	IsGenerated bool `json:"is_generated"`
+	// true if the file is marked as vendored by the .gitattributes file
+	IsVendored bool `json:"is_vendored"`
+	// true if not all changed lines are included
+	IsIncomplete bool           `json:"is_incomplete"`
+	Sections     []*DiffSection `json:"sections"`
+}
+
+// DiffSection represents a hunk of the changes of a file. Depending on the
+// requested style either the lines of the unified view or the rows of the
+// split view are given.
+type DiffSection struct {
+	Header     string           `json:"header"`
+	Lines      []*DiffLine      `json:"lines,omitempty"`
+	SplitLines []*DiffSplitLine `json:"split_lines,omitempty"`
+}
+
+// DiffLine represents a line of the changes of a file
+type DiffLine struct {
+	// one of plain, add or del
+	Type string `json:"type"`
+	// line number in the old file, 0 for added lines
+	OldLine int `json:"old_line"`
+	// line number in the new file, 0 for deleted lines
+	NewLine int    `json:"new_line"`
+	Content string `json:"content"`
+	// the content split into the changed and unchanged parts if the line
+	// replaces or is replaced by another line
+	Segments []*DiffSegment `json:"segments,omitempty"`
+}
+
+// DiffSegment represents a part of the content of a changed line
+type DiffSegment struct {
+	Text    string `json:"text"`
+	Changed bool   `json:"changed"`
+}
+
+// DiffSplitLine represents a row of the split view, a deleted line is shown
+// next to the added line replacing it
+type DiffSplitLine struct {
+	// the line of the old file, null for added lines without a deleted counterpart
+	Left *DiffLine `json:"left"`
+	// the line of the new file, null for deleted lines without an added counterpart
+	Right *DiffLine `json:"right"`
+}
@@ -0,0 +1,22 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// RepoHousekeeping the last housekeeping of a repository, the garbage
+// collection of the objects of the repository and of its wiki
+type RepoHousekeeping struct {
+	// whether a housekeeping of the repository is running
+	IsRunning bool   `json:"is_running"`
+	Success   bool   `json:"success"`
+	Output    string `json:"output"`
+	// the duration of the housekeeping in milliseconds
+	Duration int64 `json:"duration"`
+	// swagger:strfmt date-time
+	Started time.Time `json:"started_at"`
+}
@@ -0,0 +1,50 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// PushMirror represents a remote repository the branches and tags of a
+// repository are pushed to
+type PushMirror struct {
+	ID         int64  `json:"id"`
+	RemoteName string `json:"remote_name"`
+	// address of the remote repository with the credentials censored
+	RemoteAddress string `json:"remote_address"`
+	SyncOnCommit  bool   `json:"sync_on_commit"`
+	// interval between two pushes, `0s` if the pushes are not periodic
+	Interval string `json:"interval"`
+	// swagger:strfmt date-time
+	LastUpdate *time.Time `json:"last_update"`
+	// output of the last push if it failed
+	LastError string `json:"last_error"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created"`
+}
+
+// CreatePushMirrorOption options to add a push mirror to a repository
+type CreatePushMirrorOption struct {
+	// http(s) or git URL of the remote repository
+	// required: true
+	RemoteAddress  string `json:"remote_address" binding:"Required"`
+	RemoteUsername string `json:"remote_username"`
+	RemotePassword string `json:"remote_password"`
+	// interval between two pushes like `8h`, `0` disables the periodic pushes, defaults to the default mirror interval
+	Interval string `json:"interval"`
+	// push to the remote every time the repository changes
+	SyncOnCommit bool `json:"sync_on_commit"`
+}
+
+// MirrorSSHKey represents the SSH key the mirrors of a repository authenticate
+// with to the remotes with an ssh:// address
+type MirrorSSHKey struct {
+	// the public key to add to the remotes, as a deploy key for example
+	PublicKey   string `json:"public_key"`
+	Fingerprint string `json:"fingerprint"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
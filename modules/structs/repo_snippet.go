@@ -0,0 +1,22 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// FileSnippet a range of lines of a file at a commit
+type FileSnippet struct {
+	Path string `json:"path"`
+	// the commit the ref was resolved to
+	SHA          string `json:"sha"`
+	StartingLine int    `json:"starting_line"`
+	EndingLine   int    `json:"ending_line"`
+	// the permanent link to the lines in the web interface
+	HTMLURL string   `json:"html_url"`
+	Lines   []string `json:"lines"`
+	// the lines as HTML, to embed them in a web page
+	HTML string `json:"html"`
+	// the permanent link followed by the lines in a code block, to paste
+	// them in an issue or a comment
+	Markdown string `json:"markdown"`
+}
@@ -0,0 +1,34 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// ContributorStats the statistics of the commits a contributor has authored in
+// the default branch of a repository
+type ContributorStats struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	// the user whose email is the one of the contributor, null if there is none
+	Author    *User `json:"author"`
+	Commits   int64 `json:"commits"`
+	Additions int64 `json:"additions"`
+	Deletions int64 `json:"deletions"`
+	// the weeks the contributor has authored commits in chronological order
+	Weeks []*WeeklyStats `json:"weeks"`
+}
+
+// WeeklyStats the number of commits authored during a week and the number of
+// lines they add and delete
+type WeeklyStats struct {
+	// the start of the week, Sunday at midnight UTC
+	// swagger:strfmt date-time
+	Week      time.Time `json:"week"`
+	Commits   int64     `json:"commits"`
+	Additions int64     `json:"additions"`
+	Deletions int64     `json:"deletions"`
+}
@@ -0,0 +1,41 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// TagProtection represents a rule protecting the tags of a repository whose
+// names match a pattern
+type TagProtection struct {
+	ID int64 `json:"id"`
+	// glob pattern, or regular expression enclosed in slashes, matching the names of the protected tags
+	NamePattern string `json:"name_pattern"`
+	// users allowed to create, move and delete the protected tags
+	WhitelistUsernames []string `json:"whitelist_usernames"`
+	// teams allowed to create, move and delete the protected tags
+	WhitelistTeams []string `json:"whitelist_teams"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CreateTagProtectionOption options to protect the tags of a repository
+type CreateTagProtectionOption struct {
+	// required: true
+	NamePattern        string   `json:"name_pattern" binding:"Required"`
+	WhitelistUsernames []string `json:"whitelist_usernames"`
+	WhitelistTeams     []string `json:"whitelist_teams"`
+}
+
+// EditTagProtectionOption options to edit a tag protection, the omitted
+// fields are left unchanged
+type EditTagProtectionOption struct {
+	NamePattern        *string  `json:"name_pattern"`
+	WhitelistUsernames []string `json:"whitelist_usernames"`
+	WhitelistTeams     []string `json:"whitelist_teams"`
+}
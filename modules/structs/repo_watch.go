@@ -16,4 +16,6 @@ type WatchInfo struct {
 	CreatedAt     time.Time   `json:"created_at"`
 	URL           string      `json:"url"`
 	RepositoryURL string      `json:"repository_url"`
+	// what the user gets notified of, one of all, participating, issues and releases
+	Mode string `json:"mode"`
 }
@@ -21,6 +21,8 @@ type AccessToken struct {
 	Name           string `json:"name"`
 	Token          string `json:"sha1"`
 	TokenLastEight string `json:"token_last_eight"`
+	// the scopes granted to the token
+	Scopes []string `json:"scopes"`
 }
 
 // AccessTokenList represents a list of API access token.
@@ -31,4 +33,8 @@ type AccessTokenList []*AccessToken
 // swagger:parameters userCreateToken
 type CreateAccessTokenOption struct {
 	Name string `json:"name" binding:"Required"`
+	// the scopes granted to the token, at least one of all, read:repo,
+	// write:repo, read:issue, write:issue, read:org, write:org, admin:org,
+	// read:user, write:user, read:admin and write:admin
+	Scopes []string `json:"scopes"`
 }
@@ -198,6 +198,7 @@ func NewFuncMap() []template.FuncMap {
 		"DefaultTheme": func() string {
 			return setting.UI.DefaultTheme
 		},
+		"RenderLabelName": RenderLabelName,
 		"dict": func(values ...interface{}) (map[string]interface{}, error) {
 			if len(values) == 0 {
 				return nil, errors.New("invalid dict call")
@@ -250,6 +251,17 @@ func Str2html(raw string) template.HTML {
 	return template.HTML(markup.Sanitize(raw))
 }
 
+// RenderLabelName renders the escaped name of a label, separating the scope of
+// a scoped label from its value
+func RenderLabelName(label *models.Label) template.HTML {
+	scope := label.ExclusiveScope()
+	if len(scope) == 0 {
+		return template.HTML(html.EscapeString(label.Name))
+	}
+	return template.HTML(fmt.Sprintf(`<span class="label-scope">%s</span>%s`,
+		html.EscapeString(scope), html.EscapeString(label.Name[len(scope)+2:])))
+}
+
 // Escape escapes a HTML string
 func Escape(raw string) string {
 	return html.EscapeString(raw)
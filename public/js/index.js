@@ -456,6 +456,14 @@ function initCommentForm() {
                     }
                 }
             } else {
+                // Only one label of an exclusive scope can be checked
+                const scope = $(this).data('scope');
+                if (scope) {
+                    $(this).siblings('.item.checked').filter(function () {
+                        return $(this).data('scope') === scope;
+                    }).click();
+                }
+
                 $(this).addClass('checked');
                 $(this).find('.octicon').addClass('octicon-check');
                 if (hasLabelUpdateAction) {
@@ -751,6 +759,7 @@ function initRepository() {
             $('#label-modal-id').val($(this).data('id'));
             $('.edit-label .new-label-input').val($(this).data('title'));
             $('.edit-label .new-label-desc-input').val($(this).data('description'));
+            $('.edit-label .new-label-priority-input').val($(this).data('priority'));
             $('.edit-label .color-picker').val($(this).data('color'));
             $('.minicolors-swatch-color').css("background-color", $(this).data('color'));
             $('.edit-label.modal').modal({
@@ -1058,6 +1067,7 @@ function initPullRequestReview() {
         .on('mouseleave', function() {
             $(this).closest('tr').removeClass('focus-lines-new focus-lines-old');
         });
+    let lastCodeCommentLine = null;
     $('.add-code-comment').on('click', function(e) {
         // https://github.com/go-gitea/gitea/issues/4745
         if ($(e.target).hasClass('btn-add-single')) {
@@ -1068,6 +1078,13 @@ function initPullRequestReview() {
         const side = $(this).data('side');
         const idx = $(this).data('idx');
         const path = $(this).data('path');
+        // Shift-click comments on all the lines from the previously clicked line of the same side
+        let startIdx = '';
+        if (e.shiftKey && lastCodeCommentLine && lastCodeCommentLine.path === path
+            && lastCodeCommentLine.side === side && lastCodeCommentLine.idx < idx) {
+            startIdx = lastCodeCommentLine.idx;
+        }
+        lastCodeCommentLine = {path: path, side: side, idx: idx};
         const form = $('#pull_review_add_comment').html();
         const tr = $(this).closest('tr');
         let ntr = tr.next();
@@ -1089,8 +1106,133 @@ function initPullRequestReview() {
             td.find("input[name='side']").val(side === "left" ? "previous":"proposed");
             td.find("input[name='path']").val(path);
         }
+        commentCloud.find("input[name='start_line']").val(startIdx);
+        const tbody = tr.closest('tbody');
+        tbody.find('tr.selected-range').removeClass('commented-range selected-range');
+        if (startIdx) {
+            tbody.find('td.lines-num-' + (side === 'left' ? 'old' : 'new')).each(function() {
+                const lineNum = $(this).data('line-num');
+                if (lineNum >= startIdx && lineNum <= idx) {
+                    $(this).closest('tr').not('.commented-range').addClass('commented-range selected-range');
+                }
+            });
+        }
         commentCloud.find('textarea').focus();
     });
+
+    $('.show-resolved-conversation, .hide-resolved-conversation').on('click', function() {
+        const commentCloud = $(this).closest('.comment-code-cloud');
+        commentCloud.find('.conversation').toggleClass('hide');
+        commentCloud.find('.show-resolved-conversation, .hide-resolved-conversation').toggleClass('hide');
+    });
+
+    $('.suggestion-batch').on('change', function() {
+        const ids = $('.suggestion-batch:checked').map(function() {
+            return $(this).val();
+        }).get();
+        const form = $('#apply-suggestions-form');
+        form.find("input[name='comment_ids']").val(ids.join(','));
+        form.toggleClass('hide', ids.length === 0);
+    });
+
+    $('.viewed-file-checkbox input').on('change', function() {
+        const $checkbox = $(this).closest('.viewed-file-checkbox');
+        const viewed = this.checked;
+        $.post($checkbox.data('url'), {
+            "_csrf": csrf,
+            "path": $checkbox.data('path'),
+            "diff_hash": $checkbox.data('hash'),
+            "viewed": viewed
+        });
+        // Viewed files are collapsed
+        $checkbox.closest('.diff-file-box').find('.file-body-segment').toggleClass('hide', viewed);
+    });
+}
+
+function initRepoTemplate() {
+    const $template = $('#repo_template');
+    if ($template.length === 0) {
+        return;
+    }
+
+    // The items of the template replace the initialization of the repository
+    const toggle = function () {
+        const hasTemplate = $template.val() !== '' && $template.val() !== '0';
+        $('#template_units').toggle(hasTemplate);
+        $('#non_template').toggle(!hasTemplate);
+    };
+    $template.change(toggle);
+    toggle();
+}
+
+function initImageDiff() {
+    function imageInfo(info) {
+        let text = info.human_size;
+        if (info.width > 0 && info.height > 0) {
+            text = info.width + ' × ' + info.height + ' px, ' + text;
+        }
+        return text;
+    }
+
+    function imagePanel(label, info) {
+        const $panel = $('<div class="image-diff-panel"></div>');
+        $panel.append($('<div class="ui small header"></div>').text(label));
+        $panel.append($('<img>').attr('src', info.url));
+        $panel.append($('<div class="text grey"></div>').text(imageInfo(info)));
+        return $panel;
+    }
+
+    function overlayView(mode, data) {
+        const $frame = $('<div class="image-diff-frame"></div>');
+        const $after = $('<img class="after">').attr('src', data.after.url);
+        const $before = $('<img class="before">').attr('src', data.before.url);
+        const $slider = $('<input type="range" min="0" max="100" value="50">');
+        if (mode === 'swipe') {
+            const $beforeWrapper = $('<div class="image-diff-swipe-before"></div>').append($before);
+            $frame.append($after, $beforeWrapper);
+            $slider.on('input change', function() {
+                $beforeWrapper.css('width', this.value + '%');
+            });
+        } else {
+            $frame.append($before, $after);
+            $after.css('opacity', 0.5);
+            $slider.on('input change', function() {
+                $after.css('opacity', this.value / 100);
+            });
+        }
+        return $('<div></div>').append($frame, $('<div class="image-diff-slider"></div>').append($slider));
+    }
+
+    $('.image-diff').each(function() {
+        const $imageDiff = $(this);
+        const $container = $imageDiff.find('.image-diff-container');
+        $.getJSON($imageDiff.data('url'), {
+            path: $imageDiff.data('path')
+        }, function(data) {
+            function render(mode) {
+                $container.empty();
+                if (mode === 'side-by-side' || !data.before || !data.after) {
+                    if (data.before) {
+                        $container.append(imagePanel($imageDiff.data('before-label'), data.before));
+                    }
+                    if (data.after) {
+                        $container.append(imagePanel($imageDiff.data('after-label'), data.after));
+                    }
+                    return;
+                }
+                $container.append(overlayView(mode, data));
+            }
+
+            $imageDiff.find('.image-diff-size-delta').removeClass('hide').find('span').text(data.human_size_delta);
+            $imageDiff.find('.buttons .button').on('click', function() {
+                $(this).addClass('active').siblings().removeClass('active');
+                render($(this).data('mode'));
+            });
+            render('side-by-side');
+        }).fail(function() {
+            $container.empty();
+        });
+    });
 }
 
 function assingMenuAttributes(menu) {
@@ -1791,6 +1933,20 @@ function initCodeView() {
             deSelect();
         });
 
+        // The permalink keeps the selected lines, "y" switches to it like on GitHub
+        $(document).on('click', '.file-actions a.permalink', function () {
+            $(this).attr('href', $(this).attr('href').split('#')[0] + window.location.hash);
+        });
+        $(document).on('keypress', function (e) {
+            if (e.key !== 'y' || $(e.target).is('input, textarea, select, [contenteditable]')) {
+                return;
+            }
+            const $permalink = $('.file-actions a.permalink');
+            if ($permalink.length > 0 && window.history.replaceState) {
+                window.history.replaceState(null, null, $permalink.attr('href').split('#')[0] + window.location.hash);
+            }
+        });
+
         $(window).on('hashchange', function () {
             let m = window.location.hash.match(/^#(L\d+)-(L\d+)$/);
             const $list = $('.code-view ol.linenums > li');
@@ -1953,6 +2109,89 @@ function initWipTitle() {
     });
 }
 
+function initIssueSuggestions() {
+    const $suggestions = $('.issue-suggestions');
+    if ($suggestions.length === 0) {
+        return;
+    }
+
+    const $list = $suggestions.find('.list');
+    let timeout = null;
+    $('#issue_title').on('input', function () {
+        const title = $(this).val().trim();
+        clearTimeout(timeout);
+        if (title.length < 3) {
+            $suggestions.hide();
+            return;
+        }
+        timeout = setTimeout(function () {
+            $.get($suggestions.data('url'), {q: title}, function (issues) {
+                $list.empty();
+                $.each(issues, function (_i, issue) {
+                    $('<a class="item" target="_blank">')
+                        .attr('href', issue.html_url)
+                        .append($('<i class="octicon">').addClass(issue.state === 'closed' ? 'octicon-issue-closed red' : 'octicon-issue-opened green'))
+                        .append(' #' + issue.number + ' ')
+                        .append($('<span>').text(issue.title))
+                        .appendTo($list);
+                });
+                $suggestions.toggle(issues.length > 0);
+            });
+        }, 500);
+    });
+}
+
+function initIssueContentHistory() {
+    const $modal = $('#content-history-modal');
+    if ($modal.length === 0) {
+        return;
+    }
+
+    $('.content-history-menu').each(function () {
+        const $menu = $(this);
+        const url = $menu.data('url');
+        const commentId = $menu.data('comment-id');
+        $menu.dropdown({
+            action: 'hide',
+            onShow: function () {
+                if ($menu.data('loaded')) {
+                    return;
+                }
+                $menu.data('loaded', true);
+                $.get(url + '/content-history/list', {comment_id: commentId}, function (data) {
+                    const $items = $menu.find('.menu').empty();
+                    $.each(data.results, function (_i, item) {
+                        $('<div class="item">')
+                            .attr('data-id', item.id)
+                            .append($('<img class="ui avatar image">').attr('src', item.avatar))
+                            .append($('<strong>').text(item.name))
+                            .append(' ' + htmlEncode(item.action) + ' ')
+                            .append(item.time)
+                            .appendTo($items);
+                    });
+                });
+            }
+        });
+        $menu.on('click', '.menu .item', function () {
+            $.get(url + '/content-history/detail', {id: $(this).data('id')}, function (data) {
+                $modal.find('.content-history-diff').html(data.diffHTML);
+                $modal.find('.content-history-purge').data('comment-id', data.commentID);
+                $modal.modal('show');
+            });
+        });
+    });
+
+    $modal.find('.content-history-purge').click(function () {
+        const $this = $(this);
+        $.post($this.data('url'), {
+            _csrf: csrf,
+            comment_id: $this.data('comment-id')
+        }).done(function () {
+            window.location.reload();
+        });
+    });
+}
+
 $(document).ready(function () {
     csrf = $('meta[name=_csrf]').attr("content");
     suburl = $('meta[name=_suburl]').attr("content");
@@ -2013,8 +2252,13 @@ $(document).ready(function () {
     const $dropzone = $('#dropzone');
     if ($dropzone.length > 0) {
         const filenameDict = {};
+        // the files of the dropped or chosen directories keep their path
+        // relative to the directory the files are uploaded to
+        const filePath = function (file) {
+            return file.fullPath || file.name;
+        };
 
-        new Dropzone("#dropzone", {
+        const dropzone = new Dropzone("#dropzone", {
             url: $dropzone.data('upload-url'),
             headers: {"X-Csrf-Token": csrf},
             maxFiles: $dropzone.data('max-file'),
@@ -2026,24 +2270,38 @@ $(document).ready(function () {
             dictFileTooBig: $dropzone.data('file-too-big'),
             dictRemoveFile: $dropzone.data('remove-file'),
             init: function () {
+                this.on("sending", function (file, _xhr, formData) {
+                    if (file.fullPath) {
+                        formData.append("full_path", file.fullPath);
+                    }
+                });
                 this.on("success", function (file, data) {
-                    filenameDict[file.name] = data.uuid;
+                    filenameDict[filePath(file)] = data.uuid;
                     const input = $('<input id="' + data.uuid + '" name="files" type="hidden">').val(data.uuid);
                     $('.files').append(input);
                 });
                 this.on("removedfile", function (file) {
-                    if (file.name in filenameDict) {
-                        $('#' + filenameDict[file.name]).remove();
+                    if (filePath(file) in filenameDict) {
+                        $('#' + filenameDict[filePath(file)]).remove();
                     }
                     if ($dropzone.data('remove-url') && $dropzone.data('csrf')) {
                         $.post($dropzone.data('remove-url'), {
-                            file: filenameDict[file.name],
+                            file: filenameDict[filePath(file)],
                             _csrf: $dropzone.data('csrf')
                         });
                     }
                 })
             },
         });
+
+        $('#dropzone-directory').on('change', function () {
+            for (let i = 0; i < this.files.length; i++) {
+                const file = this.files[i];
+                file.fullPath = file.webkitRelativePath;
+                dropzone.addFile(file);
+            }
+            $(this).val('');
+        });
     }
 
     // Emojify
@@ -2191,7 +2449,11 @@ $(document).ready(function () {
     initU2FRegister();
     initIssueList();
     initWipTitle();
+    initIssueSuggestions();
+    initIssueContentHistory();
     initPullRequestReview();
+    initImageDiff();
+    initRepoTemplate();
 
     // Repo clone url.
     if ($('#repo-clone-url').length > 0) {
@@ -3103,6 +3365,7 @@ function cancelCodeComment(btn) {
         form.addClass('hide');
         form.parent().find('button.comment-form-reply').show();
     } else {
+        form.closest('tbody').find('tr.selected-range').removeClass('commented-range selected-range');
         form.closest('.comment-code-cloud').remove()
     }
 }
@@ -30,6 +30,27 @@ func Repos(ctx *context.Context) {
 	})
 }
 
+// RunRepoHousekeeping starts the housekeeping of one repository
+func RunRepoHousekeeping(ctx *context.Context) {
+	repo, err := models.GetRepositoryByID(ctx.QueryInt64("id"))
+	if err != nil {
+		ctx.ServerError("GetRepositoryByID", err)
+		return
+	}
+
+	if err = repo.StartHousekeeping(); err != nil {
+		if !models.IsErrRepoHousekeepingInProgress(err) {
+			ctx.ServerError("StartHousekeeping", err)
+			return
+		}
+		ctx.Flash.Error(ctx.Tr("admin.repos.housekeeping_running", repo.FullName()))
+	} else {
+		log.Trace("Repository housekeeping started: %s", repo.FullName())
+		ctx.Flash.Success(ctx.Tr("admin.repos.housekeeping_started", repo.FullName()))
+	}
+	ctx.Redirect(setting.AppSubURL + "/admin/repos?page=" + ctx.Query("page") + "&sort=" + ctx.Query("sort"))
+}
+
 // DeleteRepo delete one repository
 func DeleteRepo(ctx *context.Context) {
 	repo, err := models.GetRepositoryByID(ctx.QueryInt64("id"))
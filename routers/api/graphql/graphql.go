@@ -0,0 +1,185 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package graphql implements the GraphQL API, which is read only and exposes
+// the users, the repositories and their issues and pull requests
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/context"
+	gql "code.gitea.io/gitea/modules/graphql"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	"gitea.com/macaron/macaron"
+)
+
+// persistedQueryCacheKeyPrefix is the prefix of the cache keys of the
+// persisted queries, followed by their SHA-256 hash
+const persistedQueryCacheKeyPrefix = "graphql-persisted-query-"
+
+// maxRequestBodySize is the maximum size of the body of a POST request,
+// which contains the query and its variables
+const maxRequestBodySize = 1 << 20
+
+// errRequestTooLarge is returned when the body of a request exceeds maxRequestBodySize
+var errRequestTooLarge = fmt.Errorf("the request body exceeds %d bytes", maxRequestBodySize)
+
+// RegisterRoutes registers the routes of the GraphQL API
+func RegisterRoutes(m *macaron.Macaron) {
+	if !setting.API.EnableGraphQL {
+		return
+	}
+	m.Group("/graphql", func() {
+		m.Combo("").Get(Query).Post(Query)
+		m.Get("/schema", Schema)
+	}, context.APIContexter())
+}
+
+// request is a GraphQL request, in the body of a POST request or in the
+// parameters of a GET request
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	Extensions    struct {
+		PersistedQuery *struct {
+			Version    int    `json:"version"`
+			SHA256Hash string `json:"sha256Hash"`
+		} `json:"persistedQuery"`
+	} `json:"extensions"`
+}
+
+func parseRequest(ctx *context.APIContext) (*request, error) {
+	req := &request{}
+	if ctx.Req.Method == "POST" {
+		body, err := ioutil.ReadAll(io.LimitReader(ctx.Req.Request.Body, maxRequestBodySize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(body) > maxRequestBodySize {
+			return nil, errRequestTooLarge
+		}
+		return req, json.Unmarshal(body, req)
+	}
+
+	req.Query = ctx.Query("query")
+	req.OperationName = ctx.Query("operationName")
+	if variables := ctx.Query("variables"); len(variables) > 0 {
+		if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+			return nil, err
+		}
+	}
+	if extensions := ctx.Query("extensions"); len(extensions) > 0 {
+		if err := json.Unmarshal([]byte(extensions), &req.Extensions); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// persistedQuery returns the query of a request, which may be given by the
+// hash of a query sent previously. Queries are persisted in the cache, so
+// that clients can send only their hash once they have been sent; persist
+// is true if the query of the request should be persisted once validated.
+func persistedQuery(req *request) (query string, persist bool, gqlErr *gql.Error) {
+	pq := req.Extensions.PersistedQuery
+	if pq == nil {
+		return req.Query, false, nil
+	}
+	if !cache.IsEnabled() {
+		return "", false, &gql.Error{
+			Message:    "PersistedQueryNotSupported",
+			Extensions: map[string]interface{}{"code": "PERSISTED_QUERY_NOT_SUPPORTED"},
+		}
+	}
+
+	hash := strings.ToLower(pq.SHA256Hash)
+	if len(req.Query) == 0 {
+		query, ok := cache.GetString(persistedQueryCacheKeyPrefix + hash)
+		if !ok {
+			return "", false, &gql.Error{
+				Message:    "PersistedQueryNotFound",
+				Extensions: map[string]interface{}{"code": "PERSISTED_QUERY_NOT_FOUND"},
+			}
+		}
+		return query, false, nil
+	}
+
+	sum := sha256.Sum256([]byte(req.Query))
+	if hex.EncodeToString(sum[:]) != hash {
+		return "", false, &gql.Error{Message: "the hash of the persisted query does not match the query"}
+	}
+	return req.Query, true, nil
+}
+
+// Query executes a GraphQL query
+func Query(ctx *context.APIContext) {
+	if !ctx.HasTokenScope(models.AccessTokenScopeReadRepo) {
+		ctx.JSON(http.StatusForbidden, &gql.Result{Errors: []*gql.Error{{Message: "the access token does not have the read:repo scope"}}})
+		return
+	}
+
+	req, err := parseRequest(ctx)
+	if err != nil {
+		if err == errRequestTooLarge {
+			ctx.JSON(http.StatusRequestEntityTooLarge, &gql.Result{Errors: []*gql.Error{{Message: err.Error()}}})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, &gql.Result{Errors: []*gql.Error{{Message: "invalid request: " + err.Error()}}})
+		return
+	}
+	if len(req.Query) > setting.API.GraphQLMaxQueryLength {
+		ctx.JSON(http.StatusRequestEntityTooLarge, &gql.Result{Errors: []*gql.Error{{
+			Message: fmt.Sprintf("the query exceeds the maximum length of %d", setting.API.GraphQLMaxQueryLength),
+		}}})
+		return
+	}
+
+	query, persist, gqlErr := persistedQuery(req)
+	if gqlErr != nil {
+		ctx.JSON(http.StatusOK, &gql.Result{Errors: []*gql.Error{gqlErr}})
+		return
+	}
+	if len(strings.TrimSpace(query)) == 0 {
+		ctx.JSON(http.StatusBadRequest, &gql.Result{Errors: []*gql.Error{{Message: "no query was given"}}})
+		return
+	}
+
+	params := gql.Params{
+		Schema:        schema,
+		Query:         query,
+		OperationName: req.OperationName,
+		Variables:     req.Variables,
+		Context:       ctx,
+		MaxDepth:      setting.API.GraphQLMaxDepth,
+		MaxComplexity: setting.API.GraphQLMaxComplexity,
+	}
+	// only the valid queries of signed in users are persisted, so that
+	// anonymous users can't fill the cache
+	if persist && ctx.IsSigned {
+		params.Validated = func() {
+			if err := cache.PutString(persistedQueryCacheKeyPrefix+strings.ToLower(req.Extensions.PersistedQuery.SHA256Hash), query); err != nil {
+				log.Error("PutString: %v", err)
+			}
+		}
+	}
+	ctx.JSON(http.StatusOK, gql.Do(params))
+}
+
+// Schema returns the schema of the GraphQL API in the schema definition language
+func Schema(ctx *context.APIContext) {
+	ctx.PlainText(http.StatusOK, []byte(schema.String()))
+}
@@ -0,0 +1,631 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package graphql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	gql "code.gitea.io/gitea/modules/graphql"
+	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/routers/api/v1/convert"
+)
+
+// repository is a repository the user has access to
+type repository struct {
+	*api.Repository
+	repo *models.Repository
+	perm models.Permission
+}
+
+// pullRequest is a pull request with the issue it is made of
+type pullRequest struct {
+	*api.PullRequest
+	issueID int64
+}
+
+var dateTime = &gql.Scalar{
+	Name:        "DateTime",
+	Description: "A date and time in RFC 3339 format",
+	Serialize: func(v interface{}) (interface{}, error) {
+		t, ok := v.(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("DateTime cannot represent value %v", v)
+		}
+		if t.IsZero() {
+			return nil, nil
+		}
+		return t.Format(time.RFC3339), nil
+	},
+}
+
+var (
+	userType        = &gql.Object{Name: "User", Description: "A user or an organization"}
+	repositoryType  = &gql.Object{Name: "Repository"}
+	issueType       = &gql.Object{Name: "Issue"}
+	pullRequestType = &gql.Object{Name: "PullRequest"}
+	labelType       = &gql.Object{Name: "Label"}
+	commentType     = &gql.Object{Name: "Comment"}
+	queryType       = &gql.Object{Name: "Query"}
+
+	schema *gql.Schema
+)
+
+// pageArgs are the arguments of the fields returning a page of a list
+func pageArgs() gql.Args {
+	return gql.Args{
+		"first": {Type: gql.Int, DefaultValue: 10, Description: "The number of items of a page, at most the max response items"},
+		"page":  {Type: gql.Int, DefaultValue: 1},
+	}
+}
+
+// page returns the page and the page size given to a field
+func page(p gql.ResolveParams) (int, int) {
+	page, _ := p.Args["page"].(int)
+	if page <= 0 {
+		page = 1
+	}
+	first, _ := p.Args["first"].(int)
+	return page, convert.ToCorrectPageSize(first)
+}
+
+func apiContext(p gql.ResolveParams) *context.APIContext {
+	return p.Context.(*context.APIContext)
+}
+
+// checkTokenScope returns an error if the request is authenticated with an
+// access token which does not have the scope, as the REST API would refuse it
+func checkTokenScope(p gql.ResolveParams, scope models.AccessTokenScope) error {
+	if !apiContext(p).HasTokenScope(scope) {
+		return fmt.Errorf("the access token does not have the %s scope", scope)
+	}
+	return nil
+}
+
+func init() {
+	userType.Fields = gql.Fields{
+		"id":        {Type: gql.ID},
+		"login":     {Type: gql.String},
+		"fullName":  {Type: gql.String},
+		"email":     {Type: gql.String},
+		"avatarUrl": {Type: gql.String},
+		"isAdmin":   {Type: gql.Boolean},
+		"createdAt": {Type: dateTime, Resolve: gql.PropertyResolver("created")},
+		"repositories": {
+			Type:        &gql.List{OfType: repositoryType},
+			Description: "The repositories owned by the user that the viewer has access to",
+			Args:        pageArgs(),
+			Resolve:     resolveUserRepositories,
+		},
+	}
+
+	repositoryType.Fields = gql.Fields{
+		"id":              {Type: gql.ID},
+		"name":            {Type: gql.String},
+		"fullName":        {Type: gql.String},
+		"description":     {Type: gql.String},
+		"owner":           {Type: userType},
+		"parent":          {Type: repositoryType, Description: "The repository the repository is forked from", Resolve: resolveRepositoryParent},
+		"empty":           {Type: gql.Boolean},
+		"private":         {Type: gql.Boolean},
+		"fork":            {Type: gql.Boolean},
+		"mirror":          {Type: gql.Boolean},
+		"archived":        {Type: gql.Boolean},
+		"size":            {Type: gql.Int},
+		"htmlUrl":         {Type: gql.String},
+		"sshUrl":          {Type: gql.String},
+		"cloneUrl":        {Type: gql.String},
+		"website":         {Type: gql.String},
+		"defaultBranch":   {Type: gql.String},
+		"starsCount":      {Type: gql.Int},
+		"forksCount":      {Type: gql.Int},
+		"watchersCount":   {Type: gql.Int},
+		"openIssuesCount": {Type: gql.Int},
+		"createdAt":       {Type: dateTime},
+		"updatedAt":       {Type: dateTime},
+		"labels": {
+			Type:    &gql.List{OfType: labelType},
+			Resolve: resolveRepositoryLabels,
+		},
+		"issue": {
+			Type: issueType,
+			Args: gql.Args{
+				"number": {Type: &gql.NonNull{OfType: gql.Int}},
+			},
+			Resolve: resolveRepositoryIssue,
+		},
+		"issues": {
+			Type: &gql.List{OfType: issueType},
+			Args: withPageArgs(gql.Args{
+				"state":  {Type: gql.String, DefaultValue: "open", Description: "open, closed or all"},
+				"query":  {Type: gql.String, Description: "Keywords the issues are searched for"},
+				"labels": {Type: &gql.List{OfType: gql.String}, Description: "The names of the labels the issues have"},
+			}),
+			Resolve: resolveRepositoryIssues,
+		},
+		"pullRequest": {
+			Type: pullRequestType,
+			Args: gql.Args{
+				"number": {Type: &gql.NonNull{OfType: gql.Int}},
+			},
+			Resolve: resolveRepositoryPullRequest,
+		},
+		"pullRequests": {
+			Type: &gql.List{OfType: pullRequestType},
+			Args: withPageArgs(gql.Args{
+				"state":  {Type: gql.String, DefaultValue: "open", Description: "open, closed or all"},
+				"labels": {Type: &gql.List{OfType: gql.String}, Description: "The names of the labels the pull requests have"},
+			}),
+			Resolve: resolveRepositoryPullRequests,
+		},
+	}
+
+	issueType.Fields = gql.Fields{
+		"id":           {Type: gql.ID},
+		"number":       {Type: gql.Int},
+		"title":        {Type: gql.String},
+		"body":         {Type: gql.String},
+		"state":        {Type: gql.String},
+		"author":       {Type: userType, Resolve: gql.PropertyResolver("user")},
+		"labels":       {Type: &gql.List{OfType: labelType}},
+		"assignees":    {Type: &gql.List{OfType: userType}},
+		"commentCount": {Type: gql.Int, Resolve: gql.PropertyResolver("comments")},
+		"createdAt":    {Type: dateTime},
+		"updatedAt":    {Type: dateTime},
+		"closedAt":     {Type: dateTime},
+		"comments": {
+			Type: &gql.List{OfType: commentType},
+			Args: pageArgs(),
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return resolveComments(p, p.Source.(*api.Issue).ID)
+			},
+		},
+	}
+
+	pullRequestType.Fields = gql.Fields{
+		"id":           {Type: gql.ID},
+		"number":       {Type: gql.Int},
+		"title":        {Type: gql.String},
+		"body":         {Type: gql.String},
+		"state":        {Type: gql.String},
+		"author":       {Type: userType, Resolve: gql.PropertyResolver("user")},
+		"labels":       {Type: &gql.List{OfType: labelType}},
+		"assignees":    {Type: &gql.List{OfType: userType}},
+		"commentCount": {Type: gql.Int, Resolve: gql.PropertyResolver("comments")},
+		"htmlUrl":      {Type: gql.String},
+		"diffUrl":      {Type: gql.String},
+		"patchUrl":     {Type: gql.String},
+		"draft":        {Type: gql.Boolean},
+		"mergeable":    {Type: gql.Boolean},
+		"merged":       {Type: gql.Boolean},
+		"mergedAt":     {Type: dateTime},
+		"mergedBy":     {Type: userType},
+		"mergeCommit":  {Type: gql.String, Resolve: gql.PropertyResolver("merge_commit_sha")},
+		"baseBranch": {
+			Type: gql.String,
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				if branch := p.Source.(*pullRequest).Base; branch != nil {
+					return branch.Ref, nil
+				}
+				return nil, nil
+			},
+		},
+		"headBranch": {
+			Type: gql.String,
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				if branch := p.Source.(*pullRequest).Head; branch != nil {
+					return branch.Ref, nil
+				}
+				return nil, nil
+			},
+		},
+		"createdAt": {Type: dateTime},
+		"updatedAt": {Type: dateTime},
+		"closedAt":  {Type: dateTime},
+		"comments": {
+			Type: &gql.List{OfType: commentType},
+			Args: pageArgs(),
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return resolveComments(p, p.Source.(*pullRequest).issueID)
+			},
+		},
+	}
+
+	labelType.Fields = gql.Fields{
+		"id":          {Type: gql.ID},
+		"name":        {Type: gql.String},
+		"color":       {Type: gql.String},
+		"description": {Type: gql.String},
+	}
+
+	commentType.Fields = gql.Fields{
+		"id":        {Type: gql.ID},
+		"body":      {Type: gql.String},
+		"htmlUrl":   {Type: gql.String},
+		"author":    {Type: userType, Resolve: gql.PropertyResolver("user")},
+		"createdAt": {Type: dateTime},
+		"updatedAt": {Type: dateTime},
+	}
+
+	queryType.Fields = gql.Fields{
+		"viewer": {
+			Type:        userType,
+			Description: "The signed in user",
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				ctx := apiContext(p)
+				if !ctx.IsSigned {
+					return nil, nil
+				}
+				if err := checkTokenScope(p, models.AccessTokenScopeReadUser); err != nil {
+					return nil, err
+				}
+				return convert.ToUser(ctx.User, true, true), nil
+			},
+		},
+		"user": {
+			Type: userType,
+			Args: gql.Args{
+				"login": {Type: &gql.NonNull{OfType: gql.String}},
+			},
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				if err := checkTokenScope(p, models.AccessTokenScopeReadUser); err != nil {
+					return nil, err
+				}
+				u, err := models.GetUserByName(p.Args["login"].(string))
+				if err != nil {
+					if models.IsErrUserNotExist(err) {
+						return nil, nil
+					}
+					return nil, err
+				}
+				return toUser(apiContext(p), u), nil
+			},
+		},
+		"repository": {
+			Type: repositoryType,
+			Args: gql.Args{
+				"owner": {Type: &gql.NonNull{OfType: gql.String}},
+				"name":  {Type: &gql.NonNull{OfType: gql.String}},
+			},
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				repo, err := models.GetRepositoryByOwnerAndName(p.Args["owner"].(string), p.Args["name"].(string))
+				if err != nil {
+					if models.IsErrRepoNotExist(err) {
+						return nil, nil
+					}
+					return nil, err
+				}
+				return toRepository(apiContext(p), repo)
+			},
+		},
+		"searchRepositories": {
+			Type: &gql.List{OfType: repositoryType},
+			Args: withPageArgs(gql.Args{
+				"query": {Type: &gql.NonNull{OfType: gql.String}},
+			}),
+			Resolve: resolveSearchRepositories,
+		},
+		"searchUsers": {
+			Type: &gql.List{OfType: userType},
+			Args: withPageArgs(gql.Args{
+				"query": {Type: &gql.NonNull{OfType: gql.String}},
+			}),
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				if err := checkTokenScope(p, models.AccessTokenScopeReadUser); err != nil {
+					return nil, err
+				}
+				ctx := apiContext(p)
+				page, pageSize := page(p)
+				users, _, err := models.SearchUsers(&models.SearchUserOptions{
+					Keyword:  strings.TrimSpace(p.Args["query"].(string)),
+					Type:     models.UserTypeIndividual,
+					Page:     page,
+					PageSize: pageSize,
+				})
+				if err != nil {
+					return nil, err
+				}
+				results := make([]*api.User, len(users))
+				for i := range users {
+					results[i] = convert.ToUser(users[i], ctx.IsSigned, ctx.User != nil && ctx.User.IsAdmin)
+				}
+				return results, nil
+			},
+		},
+	}
+
+	var err error
+	if schema, err = gql.NewSchema(queryType); err != nil {
+		panic(err)
+	}
+}
+
+func withPageArgs(args gql.Args) gql.Args {
+	for name, arg := range pageArgs() {
+		args[name] = arg
+	}
+	return args
+}
+
+func toUser(ctx *context.APIContext, u *models.User) *api.User {
+	return convert.ToUser(u, ctx.IsSigned, ctx.User != nil && (ctx.User.ID == u.ID || ctx.User.IsAdmin))
+}
+
+// toRepository returns the repository if the user has access to it, nil otherwise
+func toRepository(ctx *context.APIContext, repo *models.Repository) (*repository, error) {
+	perm, err := models.GetUserRepoPermission(repo, ctx.User)
+	if err != nil {
+		return nil, err
+	}
+	if !perm.HasAccess() {
+		return nil, nil
+	}
+	if err = repo.GetOwner(); err != nil {
+		return nil, err
+	}
+	return &repository{
+		Repository: repo.APIFormat(perm.AccessMode),
+		repo:       repo,
+		perm:       perm,
+	}, nil
+}
+
+func resolveUserRepositories(p gql.ResolveParams) (interface{}, error) {
+	ctx := apiContext(p)
+	u := p.Source.(*api.User)
+	page, pageSize := page(p)
+	private := ctx.IsSigned && (ctx.User.ID == u.ID || ctx.User.IsAdmin)
+	repos, err := models.GetUserRepositories(u.ID, private, page, pageSize, "")
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*repository, 0, len(repos))
+	for _, repo := range repos {
+		r, err := toRepository(ctx, repo)
+		if err != nil {
+			return nil, err
+		}
+		if r != nil {
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
+
+func resolveSearchRepositories(p gql.ResolveParams) (interface{}, error) {
+	ctx := apiContext(p)
+	page, pageSize := page(p)
+	opts := &models.SearchRepoOptions{
+		Keyword:     strings.TrimSpace(p.Args["query"].(string)),
+		Page:        page,
+		PageSize:    pageSize,
+		Collaborate: util.OptionalBoolNone,
+		Private:     ctx.IsSigned,
+		UserIsAdmin: ctx.IsUserSiteAdmin(),
+	}
+	if ctx.IsSigned {
+		opts.UserID = ctx.User.ID
+	}
+	repos, _, err := models.SearchRepository(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*repository, 0, len(repos))
+	for _, repo := range repos {
+		r, err := toRepository(ctx, repo)
+		if err != nil {
+			return nil, err
+		}
+		if r != nil {
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
+
+func resolveRepositoryParent(p gql.ResolveParams) (interface{}, error) {
+	r := p.Source.(*repository)
+	if !r.repo.IsFork {
+		return nil, nil
+	}
+	parent, err := models.GetRepositoryByID(r.repo.ForkID)
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return toRepository(apiContext(p), parent)
+}
+
+func resolveRepositoryLabels(p gql.ResolveParams) (interface{}, error) {
+	if err := checkTokenScope(p, models.AccessTokenScopeReadIssue); err != nil {
+		return nil, err
+	}
+	r := p.Source.(*repository)
+	if !r.perm.CanRead(models.UnitTypeIssues) && !r.perm.CanRead(models.UnitTypePullRequests) {
+		return nil, nil
+	}
+	labels, err := models.GetLabelsByRepoID(r.repo.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*api.Label, len(labels))
+	for i := range labels {
+		results[i] = labels[i].APIFormat()
+	}
+	return results, nil
+}
+
+func resolveRepositoryIssue(p gql.ResolveParams) (interface{}, error) {
+	if err := checkTokenScope(p, models.AccessTokenScopeReadIssue); err != nil {
+		return nil, err
+	}
+	r := p.Source.(*repository)
+	if !r.perm.CanRead(models.UnitTypeIssues) {
+		return nil, nil
+	}
+	issue, err := models.GetIssueByIndex(r.repo.ID, int64(p.Args["number"].(int)))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if issue.IsPull {
+		return nil, nil
+	}
+	return issue.APIFormat(), nil
+}
+
+// findIssues returns the issues or the pull requests of a repository
+// matching the arguments of the field
+func findIssues(p gql.ResolveParams, repo *models.Repository, isPull bool) ([]*models.Issue, error) {
+	opts := &models.IssuesOptions{
+		RepoIDs: []int64{repo.ID},
+		IsPull:  util.OptionalBoolOf(isPull),
+	}
+	opts.Page, opts.PageSize = page(p)
+
+	switch p.Args["state"] {
+	case "closed":
+		opts.IsClosed = util.OptionalBoolTrue
+	case "all":
+		opts.IsClosed = util.OptionalBoolNone
+	default:
+		opts.IsClosed = util.OptionalBoolFalse
+	}
+
+	if labels, ok := p.Args["labels"].([]interface{}); ok && len(labels) > 0 {
+		names := make([]string, 0, len(labels))
+		for _, label := range labels {
+			if name, ok := label.(string); ok {
+				names = append(names, name)
+			}
+		}
+		labelIDs, err := models.GetLabelIDsInRepoByNames(repo.ID, names)
+		if err != nil {
+			return nil, err
+		}
+		if len(labelIDs) == 0 {
+			return nil, nil
+		}
+		opts.LabelIDs = labelIDs
+	}
+
+	if query, _ := p.Args["query"].(string); len(strings.TrimSpace(query)) > 0 {
+		issueIDs, err := issue_indexer.SearchIssuesByKeyword(repo.ID, strings.TrimSpace(query))
+		if err != nil {
+			return nil, err
+		}
+		if len(issueIDs) == 0 {
+			return nil, nil
+		}
+		opts.IssueIDs = issueIDs
+	}
+
+	return models.Issues(opts)
+}
+
+func resolveRepositoryIssues(p gql.ResolveParams) (interface{}, error) {
+	if err := checkTokenScope(p, models.AccessTokenScopeReadIssue); err != nil {
+		return nil, err
+	}
+	r := p.Source.(*repository)
+	if !r.perm.CanRead(models.UnitTypeIssues) {
+		return nil, nil
+	}
+	issues, err := findIssues(p, r.repo, false)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*api.Issue, len(issues))
+	for i := range issues {
+		results[i] = issues[i].APIFormat()
+	}
+	return results, nil
+}
+
+func toPullRequest(pr *models.PullRequest) (*pullRequest, error) {
+	if err := pr.GetBaseRepo(); err != nil {
+		return nil, err
+	}
+	if err := pr.GetHeadRepo(); err != nil {
+		return nil, err
+	}
+	return &pullRequest{
+		PullRequest: pr.APIFormat(),
+		issueID:     pr.IssueID,
+	}, nil
+}
+
+func resolveRepositoryPullRequest(p gql.ResolveParams) (interface{}, error) {
+	r := p.Source.(*repository)
+	if !r.perm.CanRead(models.UnitTypePullRequests) {
+		return nil, nil
+	}
+	pr, err := models.GetPullRequestByIndex(r.repo.ID, int64(p.Args["number"].(int)))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return toPullRequest(pr)
+}
+
+func resolveRepositoryPullRequests(p gql.ResolveParams) (interface{}, error) {
+	r := p.Source.(*repository)
+	if !r.perm.CanRead(models.UnitTypePullRequests) {
+		return nil, nil
+	}
+	issues, err := findIssues(p, r.repo, true)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*pullRequest, 0, len(issues))
+	for _, issue := range issues {
+		if err = issue.LoadPullRequest(); err != nil {
+			return nil, err
+		}
+		pr, err := toPullRequest(issue.PullRequest)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, pr)
+	}
+	return results, nil
+}
+
+// resolveComments returns a page of the comments of an issue, whose
+// repository has been checked to be readable by the user
+func resolveComments(p gql.ResolveParams, issueID int64) (interface{}, error) {
+	page, pageSize := page(p)
+	comments, err := models.FindComments(models.FindCommentsOptions{
+		IssueID:  issueID,
+		Type:     models.CommentTypeComment,
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = models.CommentList(comments).LoadPosters(); err != nil {
+		return nil, err
+	}
+	results := make([]*api.Comment, len(comments))
+	for i := range comments {
+		results[i] = comments[i].APIFormat()
+	}
+	return results, nil
+}
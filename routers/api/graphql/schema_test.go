@@ -0,0 +1,79 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package graphql
+
+import (
+	"encoding/json"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	gql "code.gitea.io/gitea/modules/graphql"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func executeQuery(t *testing.T, userID int64, query string) string {
+	ctx := test.MockContext(t, "api/graphql")
+	if userID > 0 {
+		test.LoadUser(t, ctx, userID)
+		ctx.IsSigned = true
+	}
+	result, err := json.Marshal(gql.Do(gql.Params{
+		Schema:  schema,
+		Query:   query,
+		Context: &context.APIContext{Context: ctx},
+	}))
+	assert.NoError(t, err)
+	return string(result)
+}
+
+func TestSchema_Repository(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	query := `{
+		repository(owner: "user2", name: "repo1") {
+			fullName
+			owner { login }
+			issue(number: 1) { title author { login } labels { name } }
+			issues(state: "all", first: 2) { number }
+		}
+		private: repository(owner: "user2", name: "repo2") { name labels { name } }
+	}`
+	assert.Equal(t, `{"data":{"repository":{"fullName":"user2/repo1","owner":{"login":"user2"},`+
+		`"issue":{"title":"issue1","author":{"login":"user1"},"labels":[{"name":"label1"}]},`+
+		`"issues":[{"number":4},{"number":1}]},"private":null}}`, executeQuery(t, 0, query))
+	assert.Contains(t, executeQuery(t, 2, query), `"private":{"name":"repo2","labels":null}`)
+}
+
+func TestSchema_Viewer(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	query := `{ viewer { login repositories(first: 1) { name } } }`
+	assert.Equal(t, `{"data":{"viewer":null}}`, executeQuery(t, 0, query))
+	assert.Regexp(t, `^{"data":{"viewer":{"login":"user2","repositories":\[{"name":"[^"]+"}\]}}}$`, executeQuery(t, 2, query))
+}
+
+func TestSchema_TokenScope(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	ctx := test.MockContext(t, "api/graphql")
+	test.LoadUser(t, ctx, 2)
+	ctx.IsSigned = true
+	ctx.Data["ApiTokenScope"] = models.AccessTokenScopeReadRepo
+	result := gql.Do(gql.Params{
+		Schema:  schema,
+		Query:   `{ viewer { login } repository(owner: "user2", name: "repo1") { name issue(number: 1) { title } } }`,
+		Context: &context.APIContext{Context: ctx},
+	})
+	data, err := json.Marshal(result.Data)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"viewer":null,"repository":{"name":"repo1","issue":null}}`, string(data))
+	if assert.Len(t, result.Errors, 2) {
+		assert.Equal(t, "the access token does not have the read:user scope", result.Errors[0].Message)
+		assert.Equal(t, "the access token does not have the read:issue scope", result.Errors[1].Message)
+	}
+}
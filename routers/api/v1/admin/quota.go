@@ -0,0 +1,100 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/user"
+)
+
+// GetUserQuota api for getting the quotas and the disk space used by a user
+func GetUserQuota(ctx *context.APIContext) {
+	// swagger:operation GET /admin/users/{username}/quota admin adminGetUserQuota
+	// ---
+	// summary: Get the quotas and the disk space used by a user or an organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: username of the user or organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Quota"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	u := user.GetUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	quota, err := u.APIFormatQuota()
+	if err != nil {
+		ctx.Error(500, "APIFormatQuota", err)
+		return
+	}
+	ctx.JSON(200, quota)
+}
+
+// EditUserQuota api for editing the quotas of a user
+func EditUserQuota(ctx *context.APIContext, form api.EditQuotaOption) {
+	// swagger:operation PATCH /admin/users/{username}/quota admin adminEditUserQuota
+	// ---
+	// summary: Edit the quotas of a user or an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: username of the user or organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditQuotaOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Quota"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	u := user.GetUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.MaxGitSize != nil {
+		u.MaxGitSize = *form.MaxGitSize
+	}
+	if form.MaxLFSSize != nil {
+		u.MaxLFSSize = *form.MaxLFSSize
+	}
+	if form.MaxAttachmentSize != nil {
+		u.MaxAttachmentSize = *form.MaxAttachmentSize
+	}
+	if err := models.UpdateUserCols(u, "max_git_size", "max_lfs_size", "max_attachment_size"); err != nil {
+		ctx.Error(500, "UpdateUserCols", err)
+		return
+	}
+	log.Trace("Quotas updated by admin (%s): %s", ctx.User.Name, u.Name)
+
+	quota, err := u.APIFormatQuota()
+	if err != nil {
+		ctx.Error(500, "APIFormatQuota", err)
+		return
+	}
+	ctx.JSON(200, quota)
+}
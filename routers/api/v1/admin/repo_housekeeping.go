@@ -0,0 +1,112 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// getRepoByParams returns the repository of the path, it responds with an
+// error if it does not exist
+func getRepoByParams(ctx *context.APIContext) *models.Repository {
+	repo, err := models.GetRepositoryByOwnerAndName(ctx.Params(":owner"), ctx.Params(":repo"))
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRepositoryByOwnerAndName", err)
+		}
+		return nil
+	}
+	return repo
+}
+
+// GetRepoHousekeeping returns the last housekeeping of a repository
+func GetRepoHousekeeping(ctx *context.APIContext) {
+	// swagger:operation GET /admin/repos/{owner}/{repo}/housekeeping admin adminGetRepoHousekeeping
+	// ---
+	// summary: Get the last housekeeping of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoHousekeeping"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	repo := getRepoByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	h, err := models.GetRepoHousekeeping(repo.ID)
+	if err != nil {
+		if !models.IsErrRepoHousekeepingNotExist(err) {
+			ctx.Error(http.StatusInternalServerError, "GetRepoHousekeeping", err)
+		} else if models.IsRepoHousekeepingRunning(repo.ID) {
+			ctx.JSON(http.StatusOK, &api.RepoHousekeeping{IsRunning: true})
+		} else {
+			ctx.NotFound()
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, h.APIFormat())
+}
+
+// RunRepoHousekeeping starts the housekeeping of a repository
+func RunRepoHousekeeping(ctx *context.APIContext) {
+	// swagger:operation POST /admin/repos/{owner}/{repo}/housekeeping admin adminRunRepoHousekeeping
+	// ---
+	// summary: Start the housekeeping of a repository
+	// description: The objects of the repository and of its wiki are garbage collected in the background,
+	//   the pushes to the repository are rejected meanwhile.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	repo := getRepoByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := repo.StartHousekeeping(); err != nil {
+		ctx.Error(http.StatusConflict, "", err)
+		return
+	}
+	ctx.Status(http.StatusAccepted)
+}
@@ -59,6 +59,7 @@
 package v1
 
 import (
+	"fmt"
 	"strings"
 
 	"code.gitea.io/gitea/models"
@@ -86,7 +87,7 @@ func sudo() macaron.Handler {
 		}
 
 		if len(sudo) > 0 {
-			if ctx.IsSigned && ctx.User.IsAdmin {
+			if ctx.IsSigned && ctx.User.IsAdmin && ctx.HasTokenScope(models.AccessTokenScopeWriteAdmin) {
 				user, err := models.GetUserByName(sudo)
 				if err != nil {
 					if models.IsErrUserNotExist(err) {
@@ -155,9 +156,9 @@ func repoAssignment() macaron.Handler {
 		repo.Owner = owner
 		ctx.Repo.Repository = repo
 
-		ctx.Repo.Permission, err = models.GetUserRepoPermission(repo, ctx.User)
+		ctx.Repo.Permission, _, err = models.GetUserRepoPermissionWithTransfer(repo, ctx.User)
 		if err != nil {
-			ctx.Error(500, "GetUserRepoPermission", err)
+			ctx.Error(500, "GetUserRepoPermissionWithTransfer", err)
 			return
 		}
 
@@ -186,6 +187,24 @@ func reqToken() macaron.Handler {
 	}
 }
 
+// reqTokenScope requires the access token, if the request is authenticated
+// with one, to have the read scope for GET and HEAD requests and the write
+// scope for the others
+func reqTokenScope(read, write models.AccessTokenScope) macaron.Handler {
+	return func(ctx *context.APIContext) {
+		scope := write
+		if ctx.Req.Method == "GET" || ctx.Req.Method == "HEAD" {
+			scope = read
+		}
+		if !ctx.HasTokenScope(scope) {
+			ctx.JSON(403, map[string]string{
+				"message": fmt.Sprintf("The access token does not have the %s scope.", scope),
+			})
+			return
+		}
+	}
+}
+
 func reqBasicAuth() macaron.Handler {
 	return func(ctx *context.APIContext) {
 		if !ctx.Context.IsBasicAuth {
@@ -525,7 +544,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Combo("/:id").Delete(user.DeleteAccessToken)
 				}, reqBasicAuth())
 			})
-		})
+		}, reqTokenScope(models.AccessTokenScopeReadUser, models.AccessTokenScopeWriteUser))
 
 		m.Group("/users", func() {
 			m.Group("/:username", func() {
@@ -542,7 +561,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 
 				m.Get("/subscriptions", user.GetWatchedRepos)
 			})
-		}, reqToken())
+		}, reqToken(), reqTokenScope(models.AccessTokenScopeReadUser, models.AccessTokenScopeWriteUser))
 
 		m.Group("/user", func() {
 			m.Get("", user.GetAuthenticatedUser)
@@ -583,19 +602,24 @@ func RegisterRoutes(m *macaron.Macaron) {
 			})
 			m.Get("/times", repo.ListMyTrackedTimes)
 
+			m.Group("/reminders", func() {
+				m.Get("", repo.ListMyIssueReminders)
+				m.Delete("/:id", repo.DeleteMyIssueReminder)
+			})
+
 			m.Get("/subscriptions", user.GetMyWatchedRepos)
 
 			m.Get("/teams", org.ListUserTeams)
-		}, reqToken())
+		}, reqToken(), reqTokenScope(models.AccessTokenScopeReadUser, models.AccessTokenScopeWriteUser))
 
 		// Repositories
-		m.Post("/org/:org/repos", reqToken(), bind(api.CreateRepoOption{}), repo.CreateOrgRepo)
+		m.Post("/org/:org/repos", reqToken(), reqTokenScope(models.AccessTokenScopeReadRepo, models.AccessTokenScopeWriteRepo), bind(api.CreateRepoOption{}), repo.CreateOrgRepo)
 
 		m.Group("/repos", func() {
 			m.Get("/search", repo.Search)
-		})
+		}, reqTokenScope(models.AccessTokenScopeReadRepo, models.AccessTokenScopeWriteRepo))
 
-		m.Combo("/repositories/:id", reqToken()).Get(repo.GetByID)
+		m.Combo("/repositories/:id", reqToken(), reqTokenScope(models.AccessTokenScopeReadRepo, models.AccessTokenScopeWriteRepo)).Get(repo.GetByID)
 
 		m.Group("/repos", func() {
 			m.Post("/migrate", reqToken(), bind(auth.MigrateRepoForm{}), repo.Migrate)
@@ -630,12 +654,26 @@ func RegisterRoutes(m *macaron.Macaron) {
 				}, reqToken(), reqAdmin())
 				m.Get("/raw/*", context.RepoRefByType(context.RepoRefAny), reqRepoReader(models.UnitTypeCode), repo.GetRawFile)
 				m.Get("/archive/*", reqRepoReader(models.UnitTypeCode), repo.GetArchive)
+				m.Get("/code/search", reqRepoReader(models.UnitTypeCode), repo.SearchCode)
+				m.Get("/blame/*", reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo(false), repo.GetFileBlame)
+				m.Get("/snippets/*", reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo(false), repo.GetFileSnippet)
+				m.Get("/languages", reqRepoReader(models.UnitTypeCode), repo.GetLanguages)
+				m.Group("/stats", func() {
+					m.Get("/contributors", repo.GetContributorStats)
+					m.Get("/commit_activity", repo.GetCommitActivity)
+					m.Get("/code_frequency", repo.GetCodeFrequency)
+				}, reqRepoReader(models.UnitTypeCode))
 				m.Combo("/forks").Get(repo.ListForks).
 					Post(reqToken(), reqRepoReader(models.UnitTypeCode), bind(api.CreateForkOption{}), repo.CreateFork)
+				m.Combo("/sync_fork", reqRepoReader(models.UnitTypeCode)).Get(repo.GetForkSyncInfo).
+					Post(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeCode), bind(api.SyncForkOption{}), repo.SyncFork)
+				m.Post("/generate", reqToken(), reqRepoReader(models.UnitTypeCode), bind(api.GenerateRepoOption{}), repo.Generate)
 				m.Group("/branches", func() {
 					m.Get("", repo.ListBranches)
 					m.Get("/*", context.RepoRefByType(context.RepoRefBranch), repo.GetBranch)
 				}, reqRepoReader(models.UnitTypeCode))
+				m.Combo("/stale_branches", reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo(false)).Get(repo.ListStaleBranches).
+					Delete(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeCode), bind(api.DeleteStaleBranchesOption{}), repo.DeleteStaleBranches)
 				m.Group("/tags", func() {
 					m.Get("", repo.ListTags)
 				}, reqRepoReader(models.UnitTypeCode))
@@ -645,66 +683,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Combo("/:id").Get(repo.GetDeployKey).
 						Delete(repo.DeleteDeploykey)
 				}, reqToken(), reqAdmin())
-				m.Group("/times", func() {
-					m.Combo("").Get(repo.ListTrackedTimesByRepository)
-					m.Combo("/:timetrackingusername").Get(repo.ListTrackedTimesByUser)
-				}, mustEnableIssues)
-				m.Group("/issues", func() {
-					m.Combo("").Get(repo.ListIssues).
-						Post(reqToken(), mustNotBeArchived, bind(api.CreateIssueOption{}), repo.CreateIssue)
-					m.Group("/comments", func() {
-						m.Get("", repo.ListRepoIssueComments)
-						m.Combo("/:id", reqToken()).
-							Patch(mustNotBeArchived, bind(api.EditIssueCommentOption{}), repo.EditIssueComment).
-							Delete(repo.DeleteIssueComment)
-					})
-					m.Group("/:index", func() {
-						m.Combo("").Get(repo.GetIssue).
-							Patch(reqToken(), bind(api.EditIssueOption{}), repo.EditIssue)
-
-						m.Group("/comments", func() {
-							m.Combo("").Get(repo.ListIssueComments).
-								Post(reqToken(), mustNotBeArchived, bind(api.CreateIssueCommentOption{}), repo.CreateIssueComment)
-							m.Combo("/:id", reqToken()).Patch(bind(api.EditIssueCommentOption{}), repo.EditIssueCommentDeprecated).
-								Delete(repo.DeleteIssueCommentDeprecated)
-						})
-
-						m.Group("/labels", func() {
-							m.Combo("").Get(repo.ListIssueLabels).
-								Post(reqToken(), bind(api.IssueLabelsOption{}), repo.AddIssueLabels).
-								Put(reqToken(), bind(api.IssueLabelsOption{}), repo.ReplaceIssueLabels).
-								Delete(reqToken(), repo.ClearIssueLabels)
-							m.Delete("/:id", reqToken(), repo.DeleteIssueLabel)
-						})
-
-						m.Group("/times", func() {
-							m.Combo("").Get(repo.ListTrackedTimes).
-								Post(reqToken(), bind(api.AddTimeOption{}), repo.AddTime)
-						})
-
-						m.Combo("/deadline").Post(reqToken(), bind(api.EditDeadlineOption{}), repo.UpdateIssueDeadline)
-						m.Group("/stopwatch", func() {
-							m.Post("/start", reqToken(), repo.StartIssueStopwatch)
-							m.Post("/stop", reqToken(), repo.StopIssueStopwatch)
-						})
-					})
-				}, mustEnableIssuesOrPulls)
-				m.Group("/labels", func() {
-					m.Combo("").Get(repo.ListLabels).
-						Post(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), bind(api.CreateLabelOption{}), repo.CreateLabel)
-					m.Combo("/:id").Get(repo.GetLabel).
-						Patch(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), bind(api.EditLabelOption{}), repo.EditLabel).
-						Delete(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), repo.DeleteLabel)
-				})
 				m.Post("/markdown", bind(api.MarkdownOption{}), misc.Markdown)
 				m.Post("/markdown/raw", misc.MarkdownRaw)
-				m.Group("/milestones", func() {
-					m.Combo("").Get(repo.ListMilestones).
-						Post(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), bind(api.CreateMilestoneOption{}), repo.CreateMilestone)
-					m.Combo("/:id").Get(repo.GetMilestone).
-						Patch(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), bind(api.EditMilestoneOption{}), repo.EditMilestone).
-						Delete(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), repo.DeleteMilestone)
-				})
 				m.Get("/stargazers", repo.ListStargazers)
 				m.Get("/subscribers", repo.ListSubscribers)
 				m.Group("/subscription", func() {
@@ -714,35 +694,86 @@ func RegisterRoutes(m *macaron.Macaron) {
 				})
 				m.Group("/releases", func() {
 					m.Combo("").Get(repo.ListReleases).
-						Post(reqToken(), reqRepoWriter(models.UnitTypeReleases), context.ReferencesGitRepo(false), bind(api.CreateReleaseOption{}), repo.CreateRelease)
+						Post(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeReleases), context.ReferencesGitRepo(false), bind(api.CreateReleaseOption{}), repo.CreateRelease)
 					m.Group("/:id", func() {
 						m.Combo("").Get(repo.GetRelease).
-							Patch(reqToken(), reqRepoWriter(models.UnitTypeReleases), context.ReferencesGitRepo(false), bind(api.EditReleaseOption{}), repo.EditRelease).
-							Delete(reqToken(), reqRepoWriter(models.UnitTypeReleases), repo.DeleteRelease)
+							Patch(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeReleases), context.ReferencesGitRepo(false), bind(api.EditReleaseOption{}), repo.EditRelease).
+							Delete(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeReleases), repo.DeleteRelease)
 						m.Group("/assets", func() {
 							m.Combo("").Get(repo.ListReleaseAttachments).
-								Post(reqToken(), reqRepoWriter(models.UnitTypeReleases), repo.CreateReleaseAttachment)
+								Post(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeReleases), repo.CreateReleaseAttachment)
+							m.Group("/uploads", func() {
+								m.Post("", bind(api.CreateAttachmentUploadOptions{}), repo.CreateReleaseAttachmentUpload)
+								m.Combo("/:uuid").Get(repo.GetReleaseAttachmentUpload).
+									Patch(repo.UploadReleaseAttachmentChunk).
+									Delete(repo.DeleteReleaseAttachmentUpload)
+							}, reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeReleases))
 							m.Combo("/:asset").Get(repo.GetReleaseAttachment).
-								Patch(reqToken(), reqRepoWriter(models.UnitTypeReleases), bind(api.EditAttachmentOptions{}), repo.EditReleaseAttachment).
-								Delete(reqToken(), reqRepoWriter(models.UnitTypeReleases), repo.DeleteReleaseAttachment)
+								Patch(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeReleases), bind(api.EditAttachmentOptions{}), repo.EditReleaseAttachment).
+								Delete(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeReleases), repo.DeleteReleaseAttachment)
 						})
 					})
 				}, reqRepoReader(models.UnitTypeReleases))
 				m.Post("/mirror-sync", reqToken(), reqRepoWriter(models.UnitTypeCode), repo.MirrorSync)
+				m.Group("/transfer", func() {
+					m.Combo("").Post(reqOwner(), bind(api.TransferRepoOption{}), repo.Transfer).
+						Delete(reqOwner(), repo.CancelTransfer)
+					m.Post("/accept", repo.AcceptTransfer)
+					m.Post("/reject", repo.RejectTransfer)
+				}, reqToken())
+				m.Group("/push_mirrors", func() {
+					m.Combo("").Get(repo.ListPushMirrors).
+						Post(bind(api.CreatePushMirrorOption{}), repo.AddPushMirror)
+					m.Combo("/:id").Get(repo.GetPushMirror).
+						Delete(repo.DeletePushMirror)
+				}, reqToken(), reqAdmin())
+				m.Post("/push_mirrors-sync", reqToken(), reqAdmin(), repo.PushMirrorSync)
+				m.Combo("/mirror_ssh_key", reqToken(), reqAdmin()).Get(repo.GetMirrorSSHKey).
+					Post(repo.GenerateMirrorSSHKey).
+					Delete(repo.DeleteMirrorSSHKey)
+				m.Group("/tag_protections", func() {
+					m.Combo("").Get(repo.ListTagProtections).
+						Post(bind(api.CreateTagProtectionOption{}), repo.CreateTagProtection)
+					m.Combo("/:id").Get(repo.GetTagProtection).
+						Patch(bind(api.EditTagProtectionOption{}), repo.EditTagProtection).
+						Delete(repo.DeleteTagProtection)
+				}, reqToken(), reqAdmin())
 				m.Get("/editorconfig/:filename", context.RepoRef(), reqRepoReader(models.UnitTypeCode), repo.GetEditorconfig)
 				m.Group("/pulls", func() {
 					m.Combo("").Get(bind(api.ListPullRequestsOptions{}), repo.ListPullRequests).
 						Post(reqToken(), mustNotBeArchived, bind(api.CreatePullRequestOption{}), repo.CreatePullRequest)
 					m.Group("/:index", func() {
 						m.Combo("").Get(repo.GetPullRequest).
-							Patch(reqToken(), reqRepoWriter(models.UnitTypePullRequests), bind(api.EditPullRequestOption{}), repo.EditPullRequest)
+							Patch(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypePullRequests), bind(api.EditPullRequestOption{}), repo.EditPullRequest)
+						m.Get("/files", repo.GetPullRequestDiff)
+						m.Combo("/files/viewed").Get(reqToken(), repo.ListPullViewedFiles).
+							Put(reqToken(), mustNotBeArchived, bind(api.MarkPullFileViewedOption{}), repo.MarkPullFileViewed)
+						m.Get("/suggested_reviewers", repo.ListPullSuggestedReviewers)
 						m.Combo("/merge").Get(repo.IsPullRequestMerged).
-							Post(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypePullRequests), bind(auth.MergePullRequestForm{}), repo.MergePullRequest)
+							Post(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypePullRequests), bind(auth.MergePullRequestForm{}), repo.MergePullRequest).
+							Delete(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypePullRequests), repo.CancelScheduledAutoMerge)
+						m.Post("/ready", reqToken(), mustNotBeArchived, repo.MarkPullRequestReady)
+						m.Post("/update", reqToken(), mustNotBeArchived, repo.UpdatePullRequest)
+						m.Combo("/merge_queue").Get(repo.GetPullMergeQueueEntry).
+							Post(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypePullRequests), bind(auth.MergePullRequestForm{}), repo.AddPullToMergeQueue).
+							Delete(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypePullRequests), repo.RemovePullFromMergeQueue)
+						m.Group("/reviews", func() {
+							m.Combo("").Get(repo.ListPullReviews).
+								Post(reqToken(), mustNotBeArchived, bind(api.CreatePullReviewOptions{}), repo.CreatePullReview)
+							m.Combo("/:id").Get(repo.GetPullReview).
+								Post(reqToken(), mustNotBeArchived, bind(api.SubmitPullReviewOptions{}), repo.SubmitPullReview)
+							m.Get("/:id/comments", repo.ListPullReviewComments)
+							m.Post("/:id/rerequest", reqToken(), mustNotBeArchived, repo.ReRequestPullReview)
+						})
+						m.Combo("/comments/:id/resolve", reqToken(), mustNotBeArchived).Post(repo.ResolvePullReviewComment).
+							Delete(repo.UnresolvePullReviewComment)
 					})
 				}, mustAllowPulls, reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo(false))
+				m.Get("/merge_queue", mustAllowPulls, reqRepoReader(models.UnitTypeCode), repo.ListMergeQueue)
+				m.Get("/compare/*", reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo(false), repo.CompareDiff)
 				m.Group("/statuses", func() {
 					m.Combo("/:sha").Get(repo.GetCommitStatuses).
-						Post(reqToken(), bind(api.CreateStatusOption{}), repo.NewCommitStatus)
+						Post(reqToken(), mustNotBeArchived, bind(api.CreateStatusOption{}), repo.NewCommitStatus)
 				}, reqRepoReader(models.UnitTypeCode))
 				m.Group("/commits", func() {
 					m.Get("", repo.GetAllCommits)
@@ -750,8 +781,17 @@ func RegisterRoutes(m *macaron.Macaron) {
 						// TODO: Add m.Get("") for single commit (https://developer.github.com/v3/repos/commits/#get-a-single-commit)
 						m.Get("/status", repo.GetCombinedCommitStatusByRef)
 						m.Get("/statuses", repo.GetCommitStatusesByRef)
+						m.Get("/check-runs", repo.ListCheckRunsByRef)
 					})
 				}, reqRepoReader(models.UnitTypeCode))
+				m.Group("/check-runs", func() {
+					m.Post("", reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeCode), bind(api.CreateCheckRunOption{}), repo.CreateCheckRun)
+					m.Group("/:id", func() {
+						m.Combo("").Get(repo.GetCheckRun).
+							Patch(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeCode), bind(api.EditCheckRunOption{}), repo.EditCheckRun)
+						m.Get("/annotations", repo.ListCheckRunAnnotations)
+					})
+				}, reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo(false))
 				m.Group("/git", func() {
 					m.Group("/commits", func() {
 						m.Get("/:sha", repo.GetSingleCommit)
@@ -764,28 +804,103 @@ func RegisterRoutes(m *macaron.Macaron) {
 				}, reqRepoReader(models.UnitTypeCode))
 				m.Group("/contents", func() {
 					m.Get("", repo.GetContentsList)
+					m.Post("", mustNotBeArchived, reqRepoWriter(models.UnitTypeCode), reqToken(), bind(api.ChangeFilesOptions{}), repo.ChangeFiles)
 					m.Get("/*", repo.GetContents)
 					m.Group("/*", func() {
 						m.Post("", bind(api.CreateFileOptions{}), repo.CreateFile)
 						m.Put("", bind(api.UpdateFileOptions{}), repo.UpdateFile)
 						m.Delete("", bind(api.DeleteFileOptions{}), repo.DeleteFile)
-					}, reqRepoWriter(models.UnitTypeCode), reqToken())
+					}, mustNotBeArchived, reqRepoWriter(models.UnitTypeCode), reqToken())
 				}, reqRepoReader(models.UnitTypeCode))
 				m.Group("/topics", func() {
 					m.Combo("").Get(repo.ListTopics).
-						Put(reqToken(), reqAdmin(), bind(api.RepoTopicOptions{}), repo.UpdateTopics)
+						Put(reqToken(), mustNotBeArchived, reqAdmin(), bind(api.RepoTopicOptions{}), repo.UpdateTopics)
 					m.Group("/:topic", func() {
-						m.Combo("").Put(reqToken(), repo.AddTopic).
-							Delete(reqToken(), repo.DeleteTopic)
+						m.Combo("").Put(reqToken(), mustNotBeArchived, repo.AddTopic).
+							Delete(reqToken(), mustNotBeArchived, repo.DeleteTopic)
 					}, reqAdmin())
 				}, reqAnyRepoReader())
 			}, repoAssignment())
-		})
+		}, reqTokenScope(models.AccessTokenScopeReadRepo, models.AccessTokenScopeWriteRepo))
+
+		m.Group("/repos", func() {
+			m.Group("/:username/:reponame", func() {
+				m.Group("/times", func() {
+					m.Combo("").Get(repo.ListTrackedTimesByRepository)
+					m.Combo("/:timetrackingusername").Get(repo.ListTrackedTimesByUser)
+				}, mustEnableIssues)
+				m.Group("/issues", func() {
+					m.Combo("").Get(repo.ListIssues).
+						Post(reqToken(), mustNotBeArchived, bind(api.CreateIssueOption{}), repo.CreateIssue)
+					m.Get("/suggestions", repo.SuggestIssues)
+					m.Get("/sla", repo.ListIssueSLAs)
+					m.Group("/comments", func() {
+						m.Get("", repo.ListRepoIssueComments)
+						m.Combo("/:id", reqToken()).
+							Patch(mustNotBeArchived, bind(api.EditIssueCommentOption{}), repo.EditIssueComment).
+							Delete(mustNotBeArchived, repo.DeleteIssueComment)
+						m.Combo("/:id/history").Get(repo.ListIssueCommentContentHistory).
+							Delete(reqToken(), reqAdmin(), repo.PurgeIssueCommentContentHistory)
+					})
+					m.Group("/:index", func() {
+						m.Combo("").Get(repo.GetIssue).
+							Patch(reqToken(), mustNotBeArchived, bind(api.EditIssueOption{}), repo.EditIssue).
+							Delete(reqToken(), mustNotBeArchived, reqAdmin(), repo.DeleteIssue)
+						m.Combo("/history").Get(repo.ListIssueContentHistory).
+							Delete(reqToken(), reqAdmin(), repo.PurgeIssueContentHistory)
+						m.Post("/reminders", reqToken(), mustNotBeArchived, bind(api.CreateIssueReminderOption{}), repo.CreateIssueReminder)
+
+						m.Group("/comments", func() {
+							m.Combo("").Get(repo.ListIssueComments).
+								Post(reqToken(), mustNotBeArchived, bind(api.CreateIssueCommentOption{}), repo.CreateIssueComment)
+							m.Combo("/:id", reqToken(), mustNotBeArchived).Patch(bind(api.EditIssueCommentOption{}), repo.EditIssueCommentDeprecated).
+								Delete(repo.DeleteIssueCommentDeprecated)
+						})
+						m.Get("/timeline", repo.ListIssueTimeline)
+
+						m.Group("/labels", func() {
+							m.Combo("").Get(repo.ListIssueLabels).
+								Post(reqToken(), mustNotBeArchived, bind(api.IssueLabelsOption{}), repo.AddIssueLabels).
+								Put(reqToken(), mustNotBeArchived, bind(api.IssueLabelsOption{}), repo.ReplaceIssueLabels).
+								Delete(reqToken(), mustNotBeArchived, repo.ClearIssueLabels)
+							m.Delete("/:id", reqToken(), mustNotBeArchived, repo.DeleteIssueLabel)
+						})
+
+						m.Group("/times", func() {
+							m.Combo("").Get(repo.ListTrackedTimes).
+								Post(reqToken(), mustNotBeArchived, bind(api.AddTimeOption{}), repo.AddTime)
+						})
+
+						m.Combo("/deadline").Post(reqToken(), mustNotBeArchived, bind(api.EditDeadlineOption{}), repo.UpdateIssueDeadline)
+						m.Post("/move", reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeIssues), bind(api.MoveIssueOption{}), repo.MoveIssue)
+						m.Group("/stopwatch", func() {
+							m.Post("/start", reqToken(), mustNotBeArchived, repo.StartIssueStopwatch)
+							m.Post("/stop", reqToken(), mustNotBeArchived, repo.StopIssueStopwatch)
+						})
+					})
+				}, mustEnableIssuesOrPulls)
+				m.Group("/labels", func() {
+					m.Combo("").Get(repo.ListLabels).
+						Post(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), bind(api.CreateLabelOption{}), repo.CreateLabel)
+					m.Combo("/:id").Get(repo.GetLabel).
+						Patch(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), bind(api.EditLabelOption{}), repo.EditLabel).
+						Delete(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), repo.DeleteLabel)
+				})
+				m.Group("/milestones", func() {
+					m.Combo("").Get(repo.ListMilestones).
+						Post(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), bind(api.CreateMilestoneOption{}), repo.CreateMilestone)
+					m.Combo("/:id").Get(repo.GetMilestone).
+						Patch(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), bind(api.EditMilestoneOption{}), repo.EditMilestone).
+						Delete(reqToken(), mustNotBeArchived, reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), repo.DeleteMilestone)
+					m.Get("/:id/burndown", repo.GetMilestoneBurndown)
+				})
+			}, repoAssignment())
+		}, reqTokenScope(models.AccessTokenScopeReadIssue, models.AccessTokenScopeWriteIssue))
 
 		// Organizations
-		m.Get("/user/orgs", reqToken(), org.ListMyOrgs)
-		m.Get("/users/:username/orgs", org.ListUserOrgs)
-		m.Post("/orgs", reqToken(), bind(api.CreateOrgOption{}), org.Create)
+		m.Get("/user/orgs", reqToken(), reqTokenScope(models.AccessTokenScopeReadOrg, models.AccessTokenScopeWriteOrg), org.ListMyOrgs)
+		m.Get("/users/:username/orgs", reqTokenScope(models.AccessTokenScopeReadOrg, models.AccessTokenScopeWriteOrg), org.ListUserOrgs)
+		m.Post("/orgs", reqToken(), reqTokenScope(models.AccessTokenScopeReadOrg, models.AccessTokenScopeWriteOrg), bind(api.CreateOrgOption{}), org.Create)
 		m.Group("/orgs/:orgname", func() {
 			m.Get("/repos", user.ListOrgRepos)
 			m.Combo("").Get(org.Get).
@@ -802,16 +917,32 @@ func RegisterRoutes(m *macaron.Macaron) {
 					Put(reqToken(), reqOrgMembership(), org.PublicizeMember).
 					Delete(reqToken(), reqOrgMembership(), org.ConcealMember)
 			})
-			m.Combo("/teams", reqToken(), reqOrgMembership()).Get(org.ListTeams).
+			m.Combo("/teams", reqToken(), reqTokenScope(models.AccessTokenScopeReadOrg, models.AccessTokenScopeAdminOrg), reqOrgMembership()).Get(org.ListTeams).
 				Post(reqOrgOwnership(), bind(api.CreateTeamOption{}), org.CreateTeam)
+			m.Get("/workload", reqToken(), reqOrgMembership(), org.ListAssigneeWorkloads)
+			m.Group("/milestones", func() {
+				m.Combo("").Get(org.ListMilestones).
+					Post(reqToken(), reqOrgOwnership(), bind(api.CreateMilestoneOption{}), org.CreateMilestone)
+				m.Combo("/:id").Get(org.GetMilestone).
+					Patch(reqToken(), reqOrgOwnership(), bind(api.EditMilestoneOption{}), org.EditMilestone).
+					Delete(reqToken(), reqOrgOwnership(), org.DeleteMilestone)
+				m.Get("/:id/burndown", org.GetMilestoneBurndown)
+			})
+			m.Group("/labels", func() {
+				m.Combo("").Get(org.ListDefaultLabels).
+					Post(reqToken(), reqOrgOwnership(), bind(api.CreateLabelOption{}), org.CreateDefaultLabel)
+				m.Combo("/:id").Get(org.GetDefaultLabel).
+					Patch(reqToken(), reqOrgOwnership(), bind(api.EditLabelOption{}), org.EditDefaultLabel).
+					Delete(reqToken(), reqOrgOwnership(), org.DeleteDefaultLabel)
+			})
 			m.Group("/hooks", func() {
 				m.Combo("").Get(org.ListHooks).
 					Post(bind(api.CreateHookOption{}), org.CreateHook)
 				m.Combo("/:id").Get(org.GetHook).
 					Patch(bind(api.EditHookOption{}), org.EditHook).
 					Delete(org.DeleteHook)
-			}, reqToken(), reqOrgOwnership())
-		}, orgAssignment(true))
+			}, reqToken(), reqTokenScope(models.AccessTokenScopeReadOrg, models.AccessTokenScopeAdminOrg), reqOrgOwnership())
+		}, orgAssignment(true), reqTokenScope(models.AccessTokenScopeReadOrg, models.AccessTokenScopeWriteOrg))
 		m.Group("/teams/:teamid", func() {
 			m.Combo("").Get(org.GetTeam).
 				Patch(reqOrgOwnership(), bind(api.EditTeamOption{}), org.EditTeam).
@@ -829,7 +960,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 					Put(org.AddTeamRepository).
 					Delete(org.RemoveTeamRepository)
 			})
-		}, orgAssignment(false, true), reqToken(), reqTeamMembership())
+		}, orgAssignment(false, true), reqToken(), reqTokenScope(models.AccessTokenScopeReadOrg, models.AccessTokenScopeAdminOrg), reqTeamMembership())
 
 		m.Any("/*", func(ctx *context.APIContext) {
 			ctx.NotFound()
@@ -837,12 +968,21 @@ func RegisterRoutes(m *macaron.Macaron) {
 
 		m.Group("/admin", func() {
 			m.Get("/orgs", admin.GetAllOrgs)
+			m.Group("/labels", func() {
+				m.Combo("").Get(admin.ListDefaultLabels).
+					Post(bind(api.CreateLabelOption{}), admin.CreateDefaultLabel)
+				m.Combo("/:id").Get(admin.GetDefaultLabel).
+					Patch(bind(api.EditLabelOption{}), admin.EditDefaultLabel).
+					Delete(admin.DeleteDefaultLabel)
+			})
 			m.Group("/users", func() {
 				m.Get("", admin.GetAllUsers)
 				m.Post("", bind(api.CreateUserOption{}), admin.CreateUser)
 				m.Group("/:username", func() {
 					m.Combo("").Patch(bind(api.EditUserOption{}), admin.EditUser).
 						Delete(admin.DeleteUser)
+					m.Combo("/quota").Get(admin.GetUserQuota).
+						Patch(bind(api.EditQuotaOption{}), admin.EditUserQuota)
 					m.Group("/keys", func() {
 						m.Post("", bind(api.CreateKeyOption{}), admin.CreatePublicKey)
 						m.Delete("/:id", admin.DeleteUserPublicKey)
@@ -852,11 +992,15 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Post("/repos", bind(api.CreateRepoOption{}), admin.CreateRepo)
 				})
 			})
-		}, reqToken(), reqSiteAdmin())
+			m.Combo("/repos/:owner/:repo/housekeeping").Get(admin.GetRepoHousekeeping).
+				Post(admin.RunRepoHousekeeping)
+		}, reqToken(), reqTokenScope(models.AccessTokenScopeReadAdmin, models.AccessTokenScopeWriteAdmin), reqSiteAdmin())
 
 		m.Group("/topics", func() {
 			m.Get("/search", repo.TopicSearch)
-		})
+		}, reqTokenScope(models.AccessTokenScopeReadRepo, models.AccessTokenScopeWriteRepo))
+
+		m.Get("/code/search", reqTokenScope(models.AccessTokenScopeReadRepo, models.AccessTokenScopeWriteRepo), repo.SearchCodeGlobal)
 	}, securityHeaders(), context.APIContexter(), sudo())
 }
 
@@ -12,6 +12,7 @@ import (
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/repofiles"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
 
@@ -35,6 +36,19 @@ func ToBranch(repo *models.Repository, b *git.Branch, c *git.Commit) *api.Branch
 	}
 }
 
+// ToStaleBranch convert a repofiles.StaleBranch to an api.StaleBranch
+func ToStaleBranch(repo *models.Repository, b *repofiles.StaleBranch) *api.StaleBranch {
+	return &api.StaleBranch{
+		Name:               b.Name,
+		Commit:             ToCommit(repo, b.Commit),
+		IsMerged:           b.IsMerged,
+		IsInactive:         b.IsInactive,
+		IsProtected:        b.IsProtected,
+		HasOpenPullRequest: b.HasOpenPullRequest,
+		CanDelete:          b.CanDelete(),
+	}
+}
+
 // ToTag convert a git.Tag to an api.Tag
 func ToTag(repo *models.Repository, t *git.Tag) *api.Tag {
 	return &api.Tag{
@@ -77,23 +91,32 @@ func ToCommit(repo *models.Repository, c *git.Commit) *api.PayloadCommit {
 			UserName: committerUsername,
 		},
 		Timestamp:    c.Author.When,
-		Verification: ToVerification(c),
+		Verification: ToVerification(repo, c),
 	}
 }
 
 // ToVerification convert a git.Commit.Signature to an api.PayloadCommitVerification
-func ToVerification(c *git.Commit) *api.PayloadCommitVerification {
-	verif := models.ParseCommitWithSignature(c)
+func ToVerification(repo *models.Repository, c *git.Commit) *api.PayloadCommitVerification {
+	return toPayloadVerification(models.ParseCommitWithSignature(repo, c), c.Signature)
+}
+
+// ToTagVerification convert a git.Tag.Signature to an api.PayloadCommitVerification
+func ToTagVerification(repo *models.Repository, t *git.Tag) *api.PayloadCommitVerification {
+	return toPayloadVerification(models.ParseTagWithSignature(repo, t), t.Signature)
+}
+
+func toPayloadVerification(verif *models.CommitVerification, sig *git.CommitGPGSignature) *api.PayloadCommitVerification {
 	var signature, payload string
-	if c.Signature != nil {
-		signature = c.Signature.Signature
-		payload = c.Signature.Payload
+	if sig != nil {
+		signature = sig.Signature
+		payload = sig.Payload
 	}
 	return &api.PayloadCommitVerification{
-		Verified:  verif.Verified,
-		Reason:    verif.Reason,
-		Signature: signature,
-		Payload:   payload,
+		Verified:    verif.Verified,
+		TrustStatus: verif.TrustStatus,
+		Reason:      verif.Reason,
+		Signature:   signature,
+		Payload:     payload,
 	}
 }
 
@@ -167,6 +190,9 @@ func ToHook(repoLink string, w *models.Webhook) *api.Hook {
 		config["icon_url"] = s.IconURL
 		config["color"] = s.Color
 	}
+	if len(w.PayloadTemplate) > 0 {
+		config["payload_template"] = w.PayloadTemplate
+	}
 
 	return &api.Hook{
 		ID:      w.ID,
@@ -259,7 +285,7 @@ func ToAnnotatedTag(repo *models.Repository, t *git.Tag, c *git.Commit) *api.Ann
 		Message:      t.Message,
 		URL:          util.URLJoin(repo.APIURL(), "git/tags", t.ID.String()),
 		Tagger:       ToCommitUser(t.Tagger),
-		Verification: ToVerification(c),
+		Verification: ToTagVerification(repo, t),
 	}
 }
 
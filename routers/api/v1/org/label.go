@@ -0,0 +1,209 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ListDefaultLabels list the default labels of an organization
+func ListDefaultLabels(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/labels organization orgListDefaultLabels
+	// ---
+	// summary: List an organization's default labels, which are created in its new repositories
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/LabelList"
+	labels, err := models.GetDefaultLabels(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.Error(500, "GetDefaultLabels", err)
+		return
+	}
+
+	apiLabels := make([]*api.Label, len(labels))
+	for i := range labels {
+		apiLabels[i] = labels[i].APIFormat()
+	}
+	ctx.JSON(200, &apiLabels)
+}
+
+// GetDefaultLabel get a default label of an organization
+func GetDefaultLabel(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/labels/{id} organization orgGetDefaultLabel
+	// ---
+	// summary: Get a default label of an organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the label
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Label"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	label := getOrgDefaultLabel(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(200, label.APIFormat())
+}
+
+// CreateDefaultLabel create a default label for an organization
+func CreateDefaultLabel(ctx *context.APIContext, form api.CreateLabelOption) {
+	// swagger:operation POST /orgs/{org}/labels organization orgCreateDefaultLabel
+	// ---
+	// summary: Create a default label for an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateLabelOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Label"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	label := &models.DefaultLabel{
+		OrgID:       ctx.Org.Organization.ID,
+		Name:        form.Name,
+		Color:       form.Color,
+		Description: form.Description,
+		Priority:    form.Priority,
+	}
+	if err := models.NewDefaultLabel(label); err != nil {
+		ctx.Error(500, "NewDefaultLabel", err)
+		return
+	}
+	ctx.JSON(201, label.APIFormat())
+}
+
+// EditDefaultLabel modify a default label of an organization
+func EditDefaultLabel(ctx *context.APIContext, form api.EditLabelOption) {
+	// swagger:operation PATCH /orgs/{org}/labels/{id} organization orgEditDefaultLabel
+	// ---
+	// summary: Update a default label of an organization
+	// description: Labels which have already been created in repositories are not changed.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the label to edit
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditLabelOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Label"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	label := getOrgDefaultLabel(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Name != nil {
+		label.Name = *form.Name
+	}
+	if form.Color != nil {
+		label.Color = *form.Color
+	}
+	if form.Description != nil {
+		label.Description = *form.Description
+	}
+	if form.Priority != nil {
+		label.Priority = *form.Priority
+	}
+	if err := models.UpdateDefaultLabel(label); err != nil {
+		ctx.Error(500, "UpdateDefaultLabel", err)
+		return
+	}
+	ctx.JSON(200, label.APIFormat())
+}
+
+// DeleteDefaultLabel delete a default label of an organization
+func DeleteDefaultLabel(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/labels/{id} organization orgDeleteDefaultLabel
+	// ---
+	// summary: Delete a default label of an organization
+	// description: Labels which have already been created in repositories are kept.
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the label to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	if err := models.DeleteDefaultLabel(ctx.Org.Organization.ID, ctx.ParamsInt64(":id")); err != nil {
+		ctx.Error(500, "DeleteDefaultLabel", err)
+		return
+	}
+	ctx.Status(204)
+}
+
+func getOrgDefaultLabel(ctx *context.APIContext) *models.DefaultLabel {
+	label, err := models.GetDefaultLabel(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrLabelNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetDefaultLabel", err)
+		}
+		return nil
+	}
+	return label
+}
@@ -0,0 +1,265 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ListMilestones list the milestones of an organization
+func ListMilestones(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/milestones organization orgListMilestones
+	// ---
+	// summary: List an organization's milestones, which all its repositories can use
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: state
+	//   in: query
+	//   description: Milestone state, Recognised values are open, closed and all. Defaults to "open"
+	//   type: string
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/MilestoneList"
+	milestones, err := models.GetMilestonesByOrgID(ctx.Org.Organization.ID, api.StateType(ctx.Query("state")))
+	if err != nil {
+		ctx.Error(500, "GetMilestonesByOrgID", err)
+		return
+	}
+
+	apiMilestones := make([]*api.Milestone, len(milestones))
+	for i := range milestones {
+		apiMilestones[i] = milestones[i].APIFormat()
+	}
+	ctx.JSON(200, &apiMilestones)
+}
+
+// GetMilestone get a milestone of an organization
+func GetMilestone(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/milestones/{id} organization orgGetMilestone
+	// ---
+	// summary: Get a milestone of an organization
+	// description: The issue counts cover the issues of all repositories of the organization.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the milestone
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Milestone"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	milestone := getOrgMilestone(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(200, milestone.APIFormat())
+}
+
+// GetMilestoneBurndown get the burndown chart data of a milestone of an organization
+func GetMilestoneBurndown(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/milestones/{id}/burndown organization orgGetMilestoneBurndown
+	// ---
+	// summary: Get the number of open and closed issues of a milestone of an organization per day and its estimated completion
+	// description: The chart covers at most the last 365 days up to today or the day the milestone has been closed.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the milestone
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/MilestoneBurndown"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	milestone := getOrgMilestone(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	burndown, err := models.GetMilestoneBurndown(milestone)
+	if err != nil {
+		ctx.Error(500, "GetMilestoneBurndown", err)
+		return
+	}
+	ctx.JSON(200, burndown.APIFormat())
+}
+
+// CreateMilestone create a milestone for an organization
+func CreateMilestone(ctx *context.APIContext, form api.CreateMilestoneOption) {
+	// swagger:operation POST /orgs/{org}/milestones organization orgCreateMilestone
+	// ---
+	// summary: Create a milestone for an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateMilestoneOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Milestone"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	if form.Deadline == nil {
+		defaultDeadline, _ := time.ParseInLocation("2006-01-02", "9999-12-31", time.Local)
+		form.Deadline = &defaultDeadline
+	}
+
+	milestone := &models.Milestone{
+		OrgID:        ctx.Org.Organization.ID,
+		Name:         form.Title,
+		Content:      form.Description,
+		DeadlineUnix: timeutil.TimeStamp(form.Deadline.Unix()),
+	}
+
+	if err := models.NewMilestone(milestone); err != nil {
+		ctx.Error(500, "NewMilestone", err)
+		return
+	}
+	ctx.JSON(201, milestone.APIFormat())
+}
+
+// EditMilestone modify a milestone of an organization
+func EditMilestone(ctx *context.APIContext, form api.EditMilestoneOption) {
+	// swagger:operation PATCH /orgs/{org}/milestones/{id} organization orgEditMilestone
+	// ---
+	// summary: Update a milestone of an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the milestone
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditMilestoneOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Milestone"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	milestone := getOrgMilestone(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if len(form.Title) > 0 {
+		milestone.Name = form.Title
+	}
+	if form.Description != nil {
+		milestone.Content = *form.Description
+	}
+	if form.Deadline != nil && !form.Deadline.IsZero() {
+		milestone.DeadlineUnix = timeutil.TimeStamp(form.Deadline.Unix())
+	}
+
+	if form.State != nil {
+		isClosed := api.StateType(*form.State) == api.StateClosed
+		if isClosed && !milestone.IsClosed {
+			milestone.ClosedDateUnix = timeutil.TimeStampNow()
+		}
+		if err := models.ChangeMilestoneStatus(milestone, isClosed); err != nil {
+			ctx.Error(500, "ChangeMilestoneStatus", err)
+			return
+		}
+	} else if err := models.UpdateMilestone(milestone); err != nil {
+		ctx.Error(500, "UpdateMilestone", err)
+		return
+	}
+	ctx.JSON(200, milestone.APIFormat())
+}
+
+// DeleteMilestone delete a milestone of an organization
+func DeleteMilestone(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/milestones/{id} organization orgDeleteMilestone
+	// ---
+	// summary: Delete a milestone of an organization
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the milestone to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	if err := models.DeleteOrgMilestone(ctx.Org.Organization.ID, ctx.ParamsInt64(":id")); err != nil {
+		ctx.Error(500, "DeleteOrgMilestone", err)
+		return
+	}
+	ctx.Status(204)
+}
+
+func getOrgMilestone(ctx *context.APIContext) *models.Milestone {
+	milestone, err := models.GetMilestoneByOrgID(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrMilestoneNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetMilestoneByOrgID", err)
+		}
+		return nil
+	}
+	return milestone
+}
@@ -0,0 +1,78 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/convert"
+)
+
+// ListAssigneeWorkloads list the open issues and pull requests per assignee
+func ListAssigneeWorkloads(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/workload organization orgListAssigneeWorkloads
+	// ---
+	// summary: List the open issues and pull requests of an organization per assignee
+	// description: Only the repositories the authenticated user has access to are counted.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AssigneeWorkloadList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	env, err := ctx.Org.Organization.AccessibleReposEnv(ctx.User.ID)
+	if err != nil {
+		ctx.Error(500, "AccessibleReposEnv", err)
+		return
+	}
+	count, err := env.CountRepos()
+	if err != nil {
+		ctx.Error(500, "CountRepos", err)
+		return
+	}
+	repoIDs, err := env.RepoIDs(1, int(count))
+	if err != nil {
+		ctx.Error(500, "RepoIDs", err)
+		return
+	}
+
+	workloads, err := models.GetAssigneeWorkloads(repoIDs)
+	if err != nil {
+		ctx.Error(500, "GetAssigneeWorkloads", err)
+		return
+	}
+
+	apiWorkloads := make([]*api.AssigneeWorkload, len(workloads))
+	for i, workload := range workloads {
+		apiWorkloads[i] = &api.AssigneeWorkload{
+			Assignee:   convert.ToUser(workload.Assignee, ctx.IsSigned, ctx.User.IsAdmin),
+			OpenIssues: workload.OpenIssues,
+			OpenPulls:  workload.OpenPulls,
+			Labels:     make([]*api.LabelWorkload, len(workload.Labels)),
+			Milestones: make([]*api.MilestoneWorkload, len(workload.Milestones)),
+		}
+		for j, l := range workload.Labels {
+			apiWorkloads[i].Labels[j] = &api.LabelWorkload{
+				Label: l.Label.APIFormat(),
+				Count: l.Count,
+			}
+		}
+		for j, m := range workload.Milestones {
+			apiWorkloads[i].Milestones[j] = &api.MilestoneWorkload{
+				Milestone: m.Milestone.APIFormat(),
+				Count:     m.Count,
+			}
+		}
+	}
+	ctx.JSON(200, &apiWorkloads)
+}
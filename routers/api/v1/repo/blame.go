@@ -0,0 +1,142 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/routers/api/v1/convert"
+)
+
+// GetFileBlame returns the commits which last changed the lines of a file
+func GetFileBlame(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/blame/{filepath} repository repoGetFileBlame
+	// ---
+	// summary: Get the commits which last changed the lines of a file
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: filepath
+	//   in: path
+	//   description: path of the file to blame
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: query
+	//   description: "The name of the commit/branch/tag. Default the repository’s default branch (usually master)"
+	//   type: string
+	//   required: false
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/FileBlame"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	if ctx.Repo.Repository.IsEmpty {
+		ctx.NotFound()
+		return
+	}
+
+	treePath := ctx.Params("*")
+	ref := ctx.QueryTrim("ref")
+	if len(ref) == 0 {
+		ref = ctx.Repo.Repository.DefaultBranch
+	}
+
+	commit, err := ctx.Repo.GitRepo.GetCommit(ref)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCommit", err)
+		}
+		return
+	}
+	entry, err := commit.GetTreeEntryByPath(treePath)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetTreeEntryByPath", err)
+		}
+		return
+	}
+	if entry.IsDir() || entry.IsSubModule() {
+		ctx.NotFound()
+		return
+	}
+
+	blameReader, err := git.CreateBlameReader(ctx.Repo.Repository.RepoPath(), commit.ID.String(), treePath)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "CreateBlameReader", err)
+		return
+	}
+	defer blameReader.Close()
+
+	repoAPIURL := ctx.Repo.Repository.APIURL()
+	repoHTMLURL := ctx.Repo.Repository.HTMLURL()
+	blame := &api.FileBlame{
+		Path:   treePath,
+		SHA:    commit.ID.String(),
+		Ranges: make([]*api.BlameRange, 0),
+	}
+	commits := make(map[string]*api.BlameCommit)
+	line := 1
+	for {
+		part, err := blameReader.NextPart()
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "NextPart", err)
+			return
+		}
+		if part == nil {
+			break
+		}
+
+		blameCommit, ok := commits[part.Sha]
+		if !ok {
+			c, err := ctx.Repo.GitRepo.GetCommit(part.Sha)
+			if err != nil {
+				ctx.Error(http.StatusInternalServerError, "GetCommit", err)
+				return
+			}
+			blameCommit = &api.BlameCommit{
+				CommitMeta: &api.CommitMeta{
+					URL: util.URLJoin(repoAPIURL, "git/commits", part.Sha),
+					SHA: part.Sha,
+				},
+				HTMLURL:   util.URLJoin(repoHTMLURL, "commit", part.Sha),
+				Message:   c.CommitMessage,
+				Author:    convert.ToCommitUser(c.Author),
+				Committer: convert.ToCommitUser(c.Committer),
+			}
+			commits[part.Sha] = blameCommit
+		}
+
+		blame.Ranges = append(blame.Ranges, &api.BlameRange{
+			StartingLine: line,
+			EndingLine:   line + len(part.Lines) - 1,
+			Commit:       blameCommit,
+			PreviousSHA:  part.PreviousSha,
+			PreviousPath: part.PreviousPath,
+			Lines:        part.Lines,
+		})
+		line += len(part.Lines)
+	}
+
+	ctx.JSON(http.StatusOK, blame)
+}
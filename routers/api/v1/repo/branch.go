@@ -6,8 +6,11 @@
 package repo
 
 import (
+	"net/http"
+
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/repofiles"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/routers/api/v1/convert"
 )
@@ -103,3 +106,97 @@ func ListBranches(ctx *context.APIContext) {
 
 	ctx.JSON(200, &apiBranches)
 }
+
+// ListStaleBranches list the branches of a repository which are merged into
+// the default branch or have no recent commits
+func ListStaleBranches(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/stale_branches repository repoListStaleBranches
+	// ---
+	// summary: List a repository's branches which are merged into the default branch or have no recent commits
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: days
+	//   in: query
+	//   description: number of days without commits after which a branch is inactive, 0 to only list merged branches (default 90)
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/StaleBranchList"
+	days := repofiles.DefaultStaleBranchDays
+	if len(ctx.Query("days")) > 0 {
+		days = ctx.QueryInt("days")
+	}
+
+	branches, err := repofiles.FindStaleBranches(ctx.Repo.Repository, ctx.Repo.GitRepo, days)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindStaleBranches", err)
+		return
+	}
+
+	apiBranches := make([]*api.StaleBranch, len(branches))
+	for i := range branches {
+		apiBranches[i] = convert.ToStaleBranch(ctx.Repo.Repository, branches[i])
+	}
+
+	ctx.JSON(http.StatusOK, &apiBranches)
+}
+
+// DeleteStaleBranches delete branches of a repository which are merged into
+// the default branch or have no recent commits
+func DeleteStaleBranches(ctx *context.APIContext, form api.DeleteStaleBranchesOption) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/stale_branches repository repoDeleteStaleBranches
+	// ---
+	// summary: Delete branches which are merged into the default branch or have no recent commits
+	// description: Protected branches and branches with an open pull request are never deleted.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/DeleteStaleBranchesOption"
+	// responses:
+	//   "200":
+	//     description: names of the deleted branches
+	//     schema:
+	//       type: array
+	//       items:
+	//         type: string
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	if ctx.Repo.Repository.IsMirror {
+		ctx.Error(http.StatusForbidden, "DeleteStaleBranches", "the branches of a mirror can not be deleted")
+		return
+	}
+
+	deleted, err := repofiles.DeleteStaleBranches(ctx.User, ctx.Repo.Repository, ctx.Repo.GitRepo, form.Branches, form.Days)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteStaleBranches", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deleted)
+}
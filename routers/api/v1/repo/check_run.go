@@ -0,0 +1,376 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// CreateCheckRun creates a check run of a commit
+func CreateCheckRun(ctx *context.APIContext, form api.CreateCheckRunOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/check-runs repository repoCreateCheckRun
+	// ---
+	// summary: Create a check run of a commit
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateCheckRunOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/CheckRun"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	commit, err := ctx.Repo.GitRepo.GetCommit(form.HeadSHA)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "GetCommit", err)
+		} else {
+			ctx.Error(500, "GetCommit", err)
+		}
+		return
+	}
+
+	run := &models.CheckRun{
+		SHA:        commit.ID.String(),
+		Name:       form.Name,
+		Status:     models.CheckRunStatus(form.Status),
+		Conclusion: models.CheckRunConclusion(form.Conclusion),
+		ExternalID: form.ExternalID,
+		DetailsURL: form.DetailsURL,
+	}
+	if form.Started != nil {
+		run.StartedUnix = timeutil.TimeStamp(form.Started.Unix())
+	}
+	if form.Completed != nil {
+		run.CompletedUnix = timeutil.TimeStamp(form.Completed.Unix())
+	}
+	annotations, err := applyCheckRunOutput(run, form.Output)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+	if err = validateCheckRun(run); err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	if err = models.CreateCheckRun(ctx.Repo.Repository, ctx.User, run, annotations); err != nil {
+		ctx.Error(500, "CreateCheckRun", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, run.APIFormat())
+}
+
+// GetCheckRun gets a check run
+func GetCheckRun(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/check-runs/{id} repository repoGetCheckRun
+	// ---
+	// summary: Get a check run
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the check run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CheckRun"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	run := getCheckRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(200, run.APIFormat())
+}
+
+// EditCheckRun updates a check run
+func EditCheckRun(ctx *context.APIContext, form api.EditCheckRunOption) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/check-runs/{id} repository repoEditCheckRun
+	// ---
+	// summary: Update a check run, the annotations are added to the existing ones
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the check run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditCheckRunOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CheckRun"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	run := getCheckRun(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Name != nil {
+		run.Name = *form.Name
+	}
+	if form.Status != nil {
+		run.Status = models.CheckRunStatus(*form.Status)
+	}
+	if form.Conclusion != nil {
+		run.Conclusion = models.CheckRunConclusion(*form.Conclusion)
+	}
+	if form.ExternalID != nil {
+		run.ExternalID = *form.ExternalID
+	}
+	if form.DetailsURL != nil {
+		run.DetailsURL = *form.DetailsURL
+	}
+	if form.Started != nil {
+		run.StartedUnix = timeutil.TimeStamp(form.Started.Unix())
+	}
+	if form.Completed != nil {
+		run.CompletedUnix = timeutil.TimeStamp(form.Completed.Unix())
+	}
+	annotations, err := applyCheckRunOutput(run, form.Output)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+	if err = validateCheckRun(run); err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	if err = models.UpdateCheckRun(run, annotations); err != nil {
+		ctx.Error(500, "UpdateCheckRun", err)
+		return
+	}
+	ctx.JSON(200, run.APIFormat())
+}
+
+// ListCheckRunAnnotations lists the annotations of a check run
+func ListCheckRunAnnotations(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/check-runs/{id}/annotations repository repoListCheckRunAnnotations
+	// ---
+	// summary: List the annotations of a check run
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the check run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CheckRunAnnotationList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	run := getCheckRun(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	annotations, err := models.GetCheckRunAnnotations(run.ID)
+	if err != nil {
+		ctx.Error(500, "GetCheckRunAnnotations", err)
+		return
+	}
+	apiAnnotations := make([]*api.CheckRunAnnotation, 0, len(annotations))
+	for _, a := range annotations {
+		apiAnnotations = append(apiAnnotations, a.APIFormat())
+	}
+	ctx.JSON(200, &apiAnnotations)
+}
+
+// ListCheckRunsByRef lists the latest check runs of a commit
+func ListCheckRunsByRef(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/commits/{ref}/check-runs repository repoListCheckRunsByRef
+	// ---
+	// summary: List the latest check run of each name of a commit, by branch/tag/commit reference
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: path
+	//   description: name of branch/tag/commit
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CheckRunList"
+	sha := ctx.Params("ref")
+	for _, reftype := range []string{"heads", "tags"} {
+		refSHA, lastMethodName, err := searchRefCommitByType(ctx, reftype, sha)
+		if err != nil {
+			ctx.Error(500, lastMethodName, err)
+			return
+		}
+		if refSHA != "" {
+			sha = refSHA
+			break
+		}
+	}
+
+	runs, err := models.GetLatestCheckRuns(ctx.Repo.Repository.ID, sha)
+	if err != nil {
+		ctx.Error(500, "GetLatestCheckRuns", err)
+		return
+	}
+	apiRuns := make([]*api.CheckRun, 0, len(runs))
+	for _, run := range runs {
+		run.Repo = ctx.Repo.Repository
+		apiRuns = append(apiRuns, run.APIFormat())
+	}
+	ctx.JSON(200, &apiRuns)
+}
+
+func getCheckRun(ctx *context.APIContext) *models.CheckRun {
+	run, err := models.GetCheckRunByID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrCheckRunNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetCheckRunByID", err)
+		}
+		return nil
+	}
+	run.Repo = ctx.Repo.Repository
+	return run
+}
+
+// applyCheckRunOutput sets the title and summary of the output and returns
+// its annotations
+func applyCheckRunOutput(run *models.CheckRun, output *api.CheckRunOutput) ([]*models.CheckRunAnnotation, error) {
+	if output == nil {
+		return nil, nil
+	}
+	run.Title = output.Title
+	run.Summary = output.Summary
+
+	annotations := make([]*models.CheckRunAnnotation, 0, len(output.Annotations))
+	for _, a := range output.Annotations {
+		annotation := &models.CheckRunAnnotation{
+			Path:      a.Path,
+			StartLine: a.StartLine,
+			EndLine:   a.EndLine,
+			Level:     models.CheckRunAnnotationLevel(a.Level),
+			Title:     a.Title,
+			Message:   a.Message,
+		}
+		if len(annotation.Path) == 0 || annotation.StartLine <= 0 || len(annotation.Message) == 0 {
+			return nil, errors.New("annotations require a path, a start line and a message")
+		}
+		if annotation.EndLine < annotation.StartLine {
+			annotation.EndLine = annotation.StartLine
+		}
+		if !annotation.Level.IsValid() {
+			return nil, fmt.Errorf("invalid annotation level: %s", a.Level)
+		}
+		annotations = append(annotations, annotation)
+	}
+	return annotations, nil
+}
+
+// validateCheckRun checks the status and conclusion of the check run. A
+// conclusion completes the check run.
+func validateCheckRun(run *models.CheckRun) error {
+	if len(run.Conclusion) > 0 {
+		if !run.Conclusion.IsValid() {
+			return fmt.Errorf("invalid conclusion: %s", run.Conclusion)
+		}
+		run.Status = models.CheckRunCompleted
+	}
+	if len(run.Status) == 0 {
+		run.Status = models.CheckRunQueued
+	} else if !run.Status.IsValid() {
+		return fmt.Errorf("invalid status: %s", run.Status)
+	}
+	if run.Status == models.CheckRunCompleted && len(run.Conclusion) == 0 {
+		return errors.New("completed check runs require a conclusion")
+	}
+
+	now := timeutil.TimeStampNow()
+	if run.Status != models.CheckRunQueued && run.StartedUnix == 0 {
+		run.StartedUnix = now
+	}
+	if run.Status == models.CheckRunCompleted && run.CompletedUnix == 0 {
+		run.CompletedUnix = now
+	}
+	return nil
+}
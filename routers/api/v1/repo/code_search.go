@@ -0,0 +1,233 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/indexer"
+	"code.gitea.io/gitea/modules/search"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/routers/api/v1/convert"
+)
+
+// SearchCode searches the code of the default branch of a repository
+func SearchCode(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/code/search repository repoSearchCode
+	// ---
+	// summary: Search the code of the default branch of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: q
+	//   in: query
+	//   description: keyword to search for
+	//   type: string
+	//   required: true
+	// - name: language
+	//   in: query
+	//   description: only return the files written in this language
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results, maximum page size is 50
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CodeSearchResults"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	if !setting.Indexer.RepoIndexerEnabled {
+		ctx.NotFound()
+		return
+	}
+
+	repos := map[int64]*models.Repository{ctx.Repo.Repository.ID: ctx.Repo.Repository}
+	searchCode(ctx, []int64{ctx.Repo.Repository.ID}, repos)
+}
+
+// SearchCodeGlobal searches the code of the default branches of all the
+// repositories the user can read
+func SearchCodeGlobal(ctx *context.APIContext) {
+	// swagger:operation GET /code/search repository searchCode
+	// ---
+	// summary: Search the code of the default branches of the repositories
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: q
+	//   in: query
+	//   description: keyword to search for
+	//   type: string
+	//   required: true
+	// - name: language
+	//   in: query
+	//   description: only return the files written in this language
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results, maximum page size is 50
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CodeSearchResults"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	if !setting.Indexer.RepoIndexerEnabled {
+		ctx.NotFound()
+		return
+	}
+
+	// site admins search all the repositories
+	if ctx.User != nil && ctx.User.IsAdmin {
+		searchCode(ctx, nil, nil)
+		return
+	}
+
+	var userID int64
+	if ctx.User != nil {
+		userID = ctx.User.ID
+	}
+	repoIDs, err := models.FindUserAccessibleRepoIDs(userID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindUserAccessibleRepoIDs", err)
+		return
+	}
+	repos, err := models.GetRepositoriesMapByIDs(repoIDs)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRepositoriesMapByIDs", err)
+		return
+	}
+
+	// the code unit may be disabled or restricted to some users even if the
+	// repository is accessible, as checked by the search of a repository
+	repoIDs = make([]int64, 0, len(repos))
+	for id, repo := range repos {
+		perm, err := models.GetUserRepoPermission(repo, ctx.User)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
+			return
+		}
+		if perm.CanRead(models.UnitTypeCode) {
+			repoIDs = append(repoIDs, id)
+		} else {
+			delete(repos, id)
+		}
+	}
+	if len(repoIDs) == 0 {
+		ctx.JSON(http.StatusOK, &api.CodeSearchResults{
+			Items:     []*api.CodeSearchResult{},
+			Languages: []*api.CodeSearchLanguage{},
+		})
+		return
+	}
+	searchCode(ctx, repoIDs, repos)
+}
+
+// searchCode searches the code of the repositories, all of them if repoIDs is
+// empty, and responds with the results. The repositories of the results are
+// loaded if they are not in repos.
+func searchCode(ctx *context.APIContext, repoIDs []int64, repos map[int64]*models.Repository) {
+	keyword := strings.TrimSpace(ctx.Query("q"))
+	page := ctx.QueryInt("page")
+	if page <= 0 {
+		page = 1
+	}
+
+	total, results, languages, err := search.PerformSearch(repoIDs, keyword, strings.TrimSpace(ctx.Query("language")),
+		page, convert.ToCorrectPageSize(ctx.QueryInt("limit")))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "PerformSearch", err)
+		return
+	}
+
+	if repos == nil {
+		repos = make(map[int64]*models.Repository, len(results))
+	}
+	apiRepos := make(map[int64]*api.Repository, len(results))
+	items := make([]*api.CodeSearchResult, 0, len(results))
+	for _, result := range results {
+		repo, ok := repos[result.RepoID]
+		if !ok {
+			if repo, err = models.GetRepositoryByID(result.RepoID); err != nil {
+				ctx.Error(http.StatusInternalServerError, "GetRepositoryByID", err)
+				return
+			}
+			repos[result.RepoID] = repo
+		}
+		apiRepo, ok := apiRepos[repo.ID]
+		if !ok {
+			if err = repo.GetOwner(); err != nil {
+				ctx.Error(http.StatusInternalServerError, "GetOwner", err)
+				return
+			}
+			accessMode, err := models.AccessLevel(ctx.User, repo)
+			if err != nil {
+				ctx.Error(http.StatusInternalServerError, "AccessLevel", err)
+				return
+			}
+			apiRepo = repo.APIFormat(accessMode)
+			apiRepos[repo.ID] = apiRepo
+		}
+
+		lines := make([]*api.CodeSearchResultLine, len(result.Lines))
+		for i, line := range result.Lines {
+			lines[i] = &api.CodeSearchResultLine{
+				Number:         line.Number,
+				Content:        line.Content,
+				HighlightStart: line.HighlightStart,
+				HighlightEnd:   line.HighlightEnd,
+			}
+		}
+		items = append(items, &api.CodeSearchResult{
+			Path:       result.Filename,
+			Language:   result.Language,
+			Repository: apiRepo,
+			HTMLURL: repo.HTMLURL() + "/src/branch/" + util.PathEscapeSegments(repo.DefaultBranch) +
+				"/" + util.PathEscapeSegments(result.Filename),
+			Lines: lines,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, &api.CodeSearchResults{
+		TotalCount: int64(total),
+		Items:      items,
+		Languages:  toCodeSearchLanguages(languages),
+	})
+}
+
+func toCodeSearchLanguages(languages []*indexer.SearchResultLanguages) []*api.CodeSearchLanguage {
+	apiLanguages := make([]*api.CodeSearchLanguage, len(languages))
+	for i, language := range languages {
+		apiLanguages[i] = &api.CodeSearchLanguage{
+			Language: language.Language,
+			Count:    language.Count,
+		}
+	}
+	return apiLanguages
+}
@@ -0,0 +1,150 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"io"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// CompareDiff compares two branches, tags or commits, which might belong to
+// different forks
+func CompareDiff(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/compare/{basehead} repository repoCompareDiff
+	// ---
+	// summary: Get the commits and the changes of a branch, tag or commit compared to another one
+	// description: "The changes are downloaded as a diff or as patches formatted by `git format-patch` if `basehead` ends with `.diff` or `.patch`"
+	// produces:
+	// - application/json
+	// - text/plain
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: basehead
+	//   in: path
+	//   description: "compared refs in the format `[<base owner>:]<base ref>...[<head owner>:]<head ref>[.diff|.patch]`, a ref is a branch, a tag or a commit, the head ref might belong to another repository of the fork network"
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Compare"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	basehead := ctx.Params("*")
+	var diffType string
+	for _, t := range []string{"diff", "patch"} {
+		if strings.HasSuffix(basehead, "."+t) {
+			basehead = strings.TrimSuffix(basehead, "."+t)
+			diffType = t
+			break
+		}
+	}
+
+	infos := strings.Split(basehead, "...")
+	if len(infos) != 2 {
+		ctx.NotFound()
+		return
+	}
+
+	_, headRepo, headGitRepo, compareInfo, baseRef, headRef := parseCompareInfo(ctx, infos[0], infos[1], false)
+	if ctx.Written() {
+		return
+	}
+
+	if len(diffType) > 0 {
+		ctx.Resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	switch diffType {
+	case "diff":
+		patch, err := headGitRepo.GetPatch(compareInfo.MergeBase, headRef)
+		if err != nil {
+			ctx.Error(500, "GetPatch", err)
+			return
+		}
+		if _, err = ctx.Resp.Write(patch); err != nil {
+			log.Error("Write: %v", err)
+		}
+		return
+	case "patch":
+		patch, err := headGitRepo.GetFormatPatch(compareInfo.MergeBase, headRef)
+		if err != nil {
+			ctx.Error(500, "GetFormatPatch", err)
+			return
+		}
+		if _, err = io.Copy(ctx.Resp, patch); err != nil {
+			log.Error("io.Copy: %v", err)
+		}
+		return
+	}
+
+	baseCommit, err := ctx.Repo.GitRepo.GetCommit(baseRef)
+	if err != nil {
+		ctx.Error(500, "GetCommit", err)
+		return
+	}
+	headCommit, err := headGitRepo.GetCommit(headRef)
+	if err != nil {
+		ctx.Error(500, "GetCommit", err)
+		return
+	}
+
+	numFiles, totalAdditions, totalDeletions, err := headGitRepo.GetDiffShortStat(compareInfo.MergeBase, headRef)
+	if err != nil {
+		ctx.Error(500, "GetDiffShortStat", err)
+		return
+	}
+	stats, err := headGitRepo.GetDiffNumStat(compareInfo.MergeBase, headRef)
+	if err != nil {
+		ctx.Error(500, "GetDiffNumStat", err)
+		return
+	}
+	files := make([]*api.CompareFile, 0, len(stats))
+	for _, stat := range stats {
+		files = append(files, &api.CompareFile{
+			Filename:         stat.Name,
+			PreviousFilename: stat.OldName,
+			Additions:        stat.Additions,
+			Deletions:        stat.Deletions,
+			IsBinary:         stat.IsBinary,
+		})
+	}
+
+	userCache := make(map[string]*models.User)
+	commits := make([]*api.Commit, 0, compareInfo.Commits.Len())
+	for e := compareInfo.Commits.Front(); e != nil; e = e.Next() {
+		apiCommit, err := toCommit(ctx, headRepo, e.Value.(*git.Commit), userCache)
+		if err != nil {
+			ctx.Error(500, "toCommit", err)
+			return
+		}
+		commits = append(commits, apiCommit)
+	}
+
+	ctx.JSON(200, &api.Compare{
+		BaseCommit:     baseCommit.ID.String(),
+		HeadCommit:     headCommit.ID.String(),
+		MergeBase:      compareInfo.MergeBase,
+		TotalCommits:   len(commits),
+		Commits:        commits,
+		TotalFiles:     numFiles,
+		TotalAdditions: totalAdditions,
+		TotalDeletions: totalDeletions,
+		Files:          files,
+	})
+}
@@ -7,6 +7,7 @@ package repo
 
 import (
 	"encoding/base64"
+	"fmt"
 	"net/http"
 
 	"code.gitea.io/gitea/models"
@@ -82,7 +83,7 @@ func GetArchive(ctx *context.APIContext) {
 	//   required: true
 	// - name: archive
 	//   in: path
-	//   description: archive to download, consisting of a git reference and archive
+	//   description: archive to download, consisting of a git reference, an optional subdirectory and archive
 	//   type: string
 	//   required: true
 	// responses:
@@ -366,6 +367,101 @@ func DeleteFile(ctx *context.APIContext, apiOpts api.DeleteFileOptions) {
 	}
 }
 
+// ChangeFiles handles API call for creating, updating and deleting several files in a single commit
+func ChangeFiles(ctx *context.APIContext, apiOpts api.ChangeFilesOptions) {
+	// swagger:operation POST /repos/{owner}/{repo}/contents repository repoChangeFiles
+	// ---
+	// summary: Create, update or delete several files of a repository in a single commit
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/ChangeFilesOptions"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/FilesResponse"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	if !CanWriteFiles(ctx.Repo) {
+		ctx.Error(http.StatusInternalServerError, "ChangeFiles", models.ErrUserDoesNotHaveAccessToRepo{
+			UserID:   ctx.User.ID,
+			RepoName: ctx.Repo.Repository.LowerName,
+		})
+		return
+	}
+
+	files := make([]*repofiles.ChangeRepoFile, len(apiOpts.Files))
+	for i, file := range apiOpts.Files {
+		content, err := base64.StdEncoding.DecodeString(file.Content)
+		if err != nil {
+			ctx.Error(http.StatusUnprocessableEntity, "DecodeContent", err)
+			return
+		}
+		files[i] = &repofiles.ChangeRepoFile{
+			Operation:    file.Operation,
+			TreePath:     file.Path,
+			FromTreePath: file.FromPath,
+			Content:      string(content),
+			SHA:          file.SHA,
+		}
+	}
+	if len(files) == 0 {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("No files to change"))
+		return
+	}
+
+	opts := &repofiles.ChangeRepoFilesOptions{
+		Message:   apiOpts.Message,
+		OldBranch: apiOpts.BranchName,
+		NewBranch: apiOpts.NewBranchName,
+		Files:     files,
+		Committer: &repofiles.IdentityOptions{
+			Name:  apiOpts.Committer.Name,
+			Email: apiOpts.Committer.Email,
+		},
+		Author: &repofiles.IdentityOptions{
+			Name:  apiOpts.Author.Name,
+			Email: apiOpts.Author.Email,
+		},
+	}
+
+	if opts.Message == "" {
+		if len(files) > 1 {
+			opts.Message = ctx.Tr("repo.editor.change_files", len(files))
+		} else {
+			switch files[0].Operation {
+			case "create":
+				opts.Message = ctx.Tr("repo.editor.add", files[0].TreePath)
+			case "update":
+				opts.Message = ctx.Tr("repo.editor.update", files[0].TreePath)
+			default:
+				opts.Message = ctx.Tr("repo.editor.delete", files[0].TreePath)
+			}
+		}
+	}
+
+	if filesResponse, err := repofiles.ChangeRepoFiles(ctx.Repo.Repository, ctx.User, opts); err != nil {
+		ctx.Error(http.StatusInternalServerError, "ChangeFiles", err)
+	} else {
+		ctx.JSON(http.StatusCreated, filesResponse)
+	}
+}
+
 // GetContents Get the metadata and contents (if a file) of an entry in a repository, or a list of entries if a dir
 func GetContents(ctx *context.APIContext) {
 	// swagger:operation GET /repos/{owner}/{repo}/contents/{filepath} repository repoGetContents
@@ -5,8 +5,12 @@
 package repo
 
 import (
+	"net/http"
+
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/repofiles"
 	api "code.gitea.io/gitea/modules/structs"
 )
 
@@ -104,3 +108,164 @@ func CreateFork(ctx *context.APIContext, form api.CreateForkOption) {
 	}
 	ctx.JSON(202, fork.APIFormat(models.AccessModeOwner))
 }
+
+// getForkSyncInfo returns how many commits the branch of the fork is ahead or
+// behind its upstream branch, it responds with an error if there is none
+func getForkSyncInfo(ctx *context.APIContext, branch string) *api.ForkSyncInfo {
+	if !ctx.Repo.Repository.IsFork {
+		ctx.Error(http.StatusUnprocessableEntity, "", "the repository is not a fork")
+		return nil
+	}
+	if !git.IsBranchExist(ctx.Repo.Repository.RepoPath(), branch) {
+		ctx.NotFound()
+		return nil
+	}
+	divergences, err := repofiles.CountForkDivergingCommits(ctx.Repo.Repository, []string{branch})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "CountForkDivergingCommits", err)
+		return nil
+	}
+	divergence, ok := divergences[branch]
+	if !ok {
+		ctx.Error(http.StatusUnprocessableEntity, "", "the upstream repository has no such branch")
+		return nil
+	}
+	return &api.ForkSyncInfo{
+		Branch:         divergence.Branch,
+		UpstreamBranch: divergence.UpstreamBranch,
+		Ahead:          divergence.Ahead,
+		Behind:         divergence.Behind,
+	}
+}
+
+// GetForkSyncInfo returns how many commits a branch of a fork is ahead or
+// behind its upstream branch
+func GetForkSyncInfo(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/sync_fork repository repoGetForkSyncInfo
+	// ---
+	// summary: Get how many commits a branch of a fork is ahead or behind its upstream branch
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the fork
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the fork
+	//   type: string
+	//   required: true
+	// - name: branch
+	//   in: query
+	//   description: name of the branch, the default branch if empty
+	//   type: string
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ForkSyncInfo"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	branch := ctx.Query("branch")
+	if len(branch) == 0 {
+		branch = ctx.Repo.Repository.DefaultBranch
+	}
+	if !canReadUpstream(ctx) {
+		return
+	}
+	info := getForkSyncInfo(ctx, branch)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, info)
+}
+
+// SyncFork brings a branch of a fork up to date with its upstream branch
+func SyncFork(ctx *context.APIContext, opts api.SyncForkOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/sync_fork repository repoSyncFork
+	// ---
+	// summary: Synchronize a branch of a fork with its upstream branch
+	// description: The branch is fast-forwarded if it has no commits of its own, the upstream branch is merged into it otherwise.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the fork
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the fork
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/SyncForkOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ForkSyncInfo"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	branch := opts.Branch
+	if len(branch) == 0 {
+		branch = ctx.Repo.Repository.DefaultBranch
+	}
+	if !canReadUpstream(ctx) {
+		return
+	}
+	if _, err := repofiles.SyncFork(ctx.Repo.Repository, ctx.User, branch); err != nil {
+		switch {
+		case git.IsErrBranchNotExist(err):
+			ctx.NotFound(err)
+		case models.IsErrUserCannotCommit(err):
+			ctx.Error(http.StatusForbidden, "", err)
+		case models.IsErrForkUpToDate(err), models.IsErrForkSyncConflicts(err):
+			ctx.Error(http.StatusConflict, "", err)
+		case models.IsErrForkSyncNotPossible(err):
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		default:
+			ctx.Error(http.StatusInternalServerError, "SyncFork", err)
+		}
+		return
+	}
+
+	info := getForkSyncInfo(ctx, branch)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, info)
+}
+
+// canReadUpstream returns whether the user can read the code of the upstream
+// repository of the fork, it responds with an error otherwise
+func canReadUpstream(ctx *context.APIContext) bool {
+	if err := ctx.Repo.Repository.GetBaseRepo(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetBaseRepo", err)
+		return false
+	}
+	if ctx.Repo.Repository.BaseRepo == nil {
+		return true
+	}
+	perm, err := models.GetUserRepoPermission(ctx.Repo.Repository.BaseRepo, ctx.User)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
+		return false
+	}
+	if !perm.CanRead(models.UnitTypeCode) {
+		ctx.NotFound()
+		return false
+	}
+	return true
+}
@@ -53,7 +53,7 @@ func ListIssues(ctx *context.APIContext) {
 	//   type: integer
 	// - name: q
 	//   in: query
-	//   description: search string
+	//   description: "search string, which may contain the qualifiers `reviewed-by:<username|@me>`, `reacted:<reaction>`, `no:label`, `no:milestone` and `no:assignee`"
 	//   type: string
 	// responses:
 	//   "200":
@@ -74,9 +74,18 @@ func ListIssues(ctx *context.APIContext) {
 	if strings.IndexByte(keyword, 0) >= 0 {
 		keyword = ""
 	}
+	keyword, filters, err := models.ParseIssueSearchQuery(ctx.User, keyword)
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			ctx.JSON(200, []*api.Issue{})
+		} else {
+			ctx.Error(500, "ParseIssueSearchQuery", err)
+		}
+		return
+	}
+
 	var issueIDs []int64
 	var labelIDs []int64
-	var err error
 	if len(keyword) > 0 {
 		issueIDs, err = issue_indexer.SearchIssuesByKeyword(ctx.Repo.Repository.ID, keyword)
 	}
@@ -99,6 +108,7 @@ func ListIssues(ctx *context.APIContext) {
 			IsClosed: isClosed,
 			IssueIDs: issueIDs,
 			LabelIDs: labelIDs,
+			Filters:  filters,
 		})
 	}
 
@@ -116,6 +126,88 @@ func ListIssues(ctx *context.APIContext) {
 	ctx.JSON(200, &apiIssues)
 }
 
+// SuggestIssues list the issues of a repository which are similar to a title
+func SuggestIssues(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issues/suggestions issue issueSuggestIssues
+	// ---
+	// summary: List a repository's issues which may be duplicates of a new issue with the given title
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: q
+	//   in: query
+	//   description: title of the new issue
+	//   type: string
+	//   required: true
+	// - name: limit
+	//   in: query
+	//   description: maximum number of suggested issues, defaults to 5
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueList"
+	title := strings.TrimSpace(ctx.Query("q"))
+	if len(title) == 0 || strings.IndexByte(title, 0) >= 0 {
+		ctx.JSON(200, []*api.Issue{})
+		return
+	}
+	limit := ctx.QueryInt("limit")
+	if limit <= 0 {
+		limit = 5
+	} else if limit > setting.API.MaxResponseItems {
+		limit = setting.API.MaxResponseItems
+	}
+
+	// Pull requests are indexed as well, so search for more matches than needed
+	res, err := issue_indexer.SearchSimilarIssues(ctx.Repo.Repository.ID, title, limit*2)
+	if err != nil {
+		ctx.Error(500, "SearchSimilarIssues", err)
+		return
+	}
+	issueIDs := make([]int64, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		issueIDs = append(issueIDs, hit.ID)
+	}
+
+	issues, err := models.GetIssuesByIDs(issueIDs)
+	if err != nil {
+		ctx.Error(500, "GetIssuesByIDs", err)
+		return
+	}
+	issuesMap := make(map[int64]*models.Issue, len(issues))
+	for _, issue := range issues {
+		issuesMap[issue.ID] = issue
+	}
+
+	// Keep the order of the search result, the most similar issues come first
+	suggestions := make(models.IssueList, 0, limit)
+	for _, id := range issueIDs {
+		if issue, ok := issuesMap[id]; ok && !issue.IsPull && len(suggestions) < limit {
+			suggestions = append(suggestions, issue)
+		}
+	}
+	if err = suggestions.LoadAttributes(); err != nil {
+		ctx.Error(500, "LoadAttributes", err)
+		return
+	}
+
+	apiIssues := make([]*api.Issue, len(suggestions))
+	for i := range suggestions {
+		apiIssues[i] = suggestions[i].APIFormat()
+	}
+	ctx.JSON(200, &apiIssues)
+}
+
 // GetIssue get an issue of a repository
 func GetIssue(ctx *context.APIContext) {
 	// swagger:operation GET /repos/{owner}/{repo}/issues/{index} issue issueGetIssue
@@ -182,6 +274,8 @@ func CreateIssue(ctx *context.APIContext, form api.CreateIssueOption) {
 	// responses:
 	//   "201":
 	//     "$ref": "#/responses/Issue"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	var deadlineUnix timeutil.TimeStamp
 	if form.Deadline != nil && ctx.Repo.CanWrite(models.UnitTypeIssues) {
@@ -220,6 +314,9 @@ func CreateIssue(ctx *context.APIContext, form api.CreateIssueOption) {
 		if models.IsErrUserDoesNotHaveAccessToRepo(err) {
 			ctx.Error(400, "UserDoesNotHaveAccessToRepo", err)
 			return
+		} else if models.IsErrLabelExclusiveScopeConflict(err) {
+			ctx.Error(422, "", err)
+			return
 		}
 		ctx.Error(500, "NewIssue", err)
 		return
@@ -446,6 +543,156 @@ func UpdateIssueDeadline(ctx *context.APIContext, form api.EditDeadlineOption) {
 	ctx.JSON(201, api.IssueDeadline{Deadline: &deadline})
 }
 
+// MoveIssue moves an issue to another repository
+func MoveIssue(ctx *context.APIContext, form api.MoveIssueOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/issues/{index}/move issue issueMoveIssue
+	// ---
+	// summary: Move an issue to another repository. A closed stub redirecting to the moved issue is left behind.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue to move
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/MoveIssueOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Issue"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetIssueByIndex", err)
+		}
+		return
+	}
+	issue.Repo = ctx.Repo.Repository
+
+	newRepo, err := models.GetRepositoryByOwnerAndName(form.Owner, form.Repo)
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetRepositoryByOwnerAndName", err)
+		}
+		return
+	}
+
+	perm, err := models.GetUserRepoPermission(newRepo, ctx.User)
+	if err != nil {
+		ctx.Error(500, "GetUserRepoPermission", err)
+		return
+	}
+	// The existence of a repository the user can not read is not disclosed
+	if !perm.HasAccess() {
+		ctx.NotFound()
+		return
+	}
+	if !perm.CanWrite(models.UnitTypeIssues) {
+		ctx.Error(403, "", "User does not have write access to issues of the target repository")
+		return
+	}
+	if newRepo.IsArchived {
+		ctx.Error(422, "", "Target repository is archived")
+		return
+	}
+
+	if _, err = models.MoveIssue(ctx.User, issue, newRepo); err != nil {
+		if models.IsErrIssueMoveNotAllowed(err) {
+			ctx.Error(422, "", err)
+		} else {
+			ctx.Error(500, "MoveIssue", err)
+		}
+		return
+	}
+
+	notification.NotifyIssueMove(ctx.User, ctx.Repo.Repository, issue)
+
+	issue, err = models.GetIssueByID(issue.ID)
+	if err != nil {
+		ctx.Error(500, "GetIssueByID", err)
+		return
+	}
+	ctx.JSON(201, issue.APIFormat())
+}
+
+// DeleteIssue delete an issue or pull request permanently
+func DeleteIssue(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/issues/{index} issue issueDelete
+	// ---
+	// summary: Delete an issue or pull request permanently
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetIssueByIndex", err)
+		}
+		return
+	}
+	issue.Repo = ctx.Repo.Repository
+
+	if err = models.DeleteIssue(ctx.User, issue); err != nil {
+		ctx.Error(500, "DeleteIssue", err)
+		return
+	}
+
+	notification.NotifyDeleteIssue(ctx.User, issue)
+
+	ctx.Status(204)
+}
+
 // StartIssueStopwatch creates a stopwatch for the given issue.
 func StartIssueStopwatch(ctx *context.APIContext) {
 	// swagger:operation POST /repos/{owner}/{repo}/issues/{index}/stopwatch/start issue issueStartStopWatch
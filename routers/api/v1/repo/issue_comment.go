@@ -81,6 +81,68 @@ func ListIssueComments(ctx *context.APIContext) {
 	ctx.JSON(200, &apiComments)
 }
 
+// ListIssueTimeline list all the comments and events of an issue
+func ListIssueTimeline(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issues/{index}/timeline issue issueGetTimeline
+	// ---
+	// summary: List all comments and events on an issue in chronological order
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: since
+	//   in: query
+	//   description: if provided, only events updated since the specified time are returned.
+	//   type: string
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/TimelineList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	var since time.Time
+	if len(ctx.Query("since")) > 0 {
+		since, _ = time.Parse(time.RFC3339, ctx.Query("since"))
+	}
+
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetIssueByIndex", err)
+		}
+		return
+	}
+	issue.Repo = ctx.Repo.Repository
+
+	comments, err := models.GetIssueTimeline(issue, ctx.User, since.Unix())
+	if err != nil {
+		ctx.Error(500, "GetIssueTimeline", err)
+		return
+	}
+
+	apiComments := make([]*api.TimelineComment, len(comments))
+	for i := range comments {
+		apiComments[i] = comments[i].TimelineAPIFormat()
+	}
+	ctx.JSON(200, &apiComments)
+}
+
 // ListRepoIssueComments returns all issue-comments for a repo
 func ListRepoIssueComments(ctx *context.APIContext) {
 	// swagger:operation GET /repos/{owner}/{repo}/issues/comments issue issueGetRepoComments
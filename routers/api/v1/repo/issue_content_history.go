@@ -0,0 +1,215 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ListIssueContentHistory list the revisions of the body of an issue
+func ListIssueContentHistory(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issues/{index}/history issue issueListContentHistory
+	// ---
+	// summary: List the revisions of the body of an issue, newest first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueContentRevisionList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	issue := getIssueForContentHistory(ctx)
+	if ctx.Written() {
+		return
+	}
+	listIssueContentHistory(ctx, issue.ID, 0)
+}
+
+// PurgeIssueContentHistory delete the revisions of the body of an issue
+func PurgeIssueContentHistory(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/issues/{index}/history issue issuePurgeContentHistory
+	// ---
+	// summary: Delete the revisions of the body of an issue
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	issue := getIssueForContentHistory(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := models.PurgeIssueContentHistory(issue.ID, 0); err != nil {
+		ctx.Error(500, "PurgeIssueContentHistory", err)
+		return
+	}
+	ctx.Status(204)
+}
+
+// ListIssueCommentContentHistory list the revisions of the body of a comment
+func ListIssueCommentContentHistory(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issues/comments/{id}/history issue issueListCommentContentHistory
+	// ---
+	// summary: List the revisions of the body of a comment, newest first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the comment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueContentRevisionList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	comment := getCommentForContentHistory(ctx)
+	if ctx.Written() {
+		return
+	}
+	listIssueContentHistory(ctx, comment.IssueID, comment.ID)
+}
+
+// PurgeIssueCommentContentHistory delete the revisions of the body of a comment
+func PurgeIssueCommentContentHistory(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/issues/comments/{id}/history issue issuePurgeCommentContentHistory
+	// ---
+	// summary: Delete the revisions of the body of a comment
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the comment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	comment := getCommentForContentHistory(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := models.PurgeIssueContentHistory(comment.IssueID, comment.ID); err != nil {
+		ctx.Error(500, "PurgeIssueContentHistory", err)
+		return
+	}
+	ctx.Status(204)
+}
+
+func getIssueForContentHistory(ctx *context.APIContext) *models.Issue {
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetIssueByIndex", err)
+		}
+		return nil
+	}
+	return issue
+}
+
+func getCommentForContentHistory(ctx *context.APIContext) *models.Comment {
+	comment, err := models.GetCommentByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrCommentNotExist(err) {
+			ctx.NotFound(err)
+		} else {
+			ctx.Error(500, "GetCommentByID", err)
+		}
+		return nil
+	}
+	if err = comment.LoadIssue(); err != nil {
+		ctx.Error(500, "LoadIssue", err)
+		return nil
+	}
+	if comment.Issue.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound()
+		return nil
+	}
+	return comment
+}
+
+func listIssueContentHistory(ctx *context.APIContext, issueID, commentID int64) {
+	list, err := models.GetIssueContentHistoryList(issueID, commentID)
+	if err != nil {
+		ctx.Error(500, "GetIssueContentHistoryList", err)
+		return
+	}
+
+	apiList := make([]*api.IssueContentRevision, len(list))
+	for i := range list {
+		apiList[i] = list[i].APIFormat()
+	}
+	ctx.JSON(200, &apiList)
+}
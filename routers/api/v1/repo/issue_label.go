@@ -95,6 +95,8 @@ func AddIssueLabels(ctx *context.APIContext, form api.IssueLabelsOption) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/LabelList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
 	if err != nil {
 		if models.IsErrIssueNotExist(err) {
@@ -117,7 +119,11 @@ func AddIssueLabels(ctx *context.APIContext, form api.IssueLabelsOption) {
 	}
 
 	if err = issue.AddLabels(ctx.User, labels); err != nil {
-		ctx.Error(500, "AddLabels", err)
+		if models.IsErrLabelExclusiveScopeConflict(err) {
+			ctx.Error(422, "", err)
+		} else {
+			ctx.Error(500, "AddLabels", err)
+		}
 		return
 	}
 
@@ -233,6 +239,8 @@ func ReplaceIssueLabels(ctx *context.APIContext, form api.IssueLabelsOption) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/LabelList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
 	if err != nil {
 		if models.IsErrIssueNotExist(err) {
@@ -255,7 +263,11 @@ func ReplaceIssueLabels(ctx *context.APIContext, form api.IssueLabelsOption) {
 	}
 
 	if err := issue.ReplaceLabels(labels, ctx.User); err != nil {
-		ctx.Error(500, "ReplaceLabels", err)
+		if models.IsErrLabelExclusiveScopeConflict(err) {
+			ctx.Error(422, "", err)
+		} else {
+			ctx.Error(500, "ReplaceLabels", err)
+		}
 		return
 	}
 
@@ -0,0 +1,149 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// CreateIssueReminder set a reminder on an issue for the authenticated user
+func CreateIssueReminder(ctx *context.APIContext, form api.CreateIssueReminderOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/issues/{index}/reminders issue issueCreateReminder
+	// ---
+	// summary: Set a reminder on an issue for the authenticated user
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateIssueReminderOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/IssueReminder"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetIssueByIndex", err)
+		}
+		return
+	}
+
+	var remindAt time.Time
+	if form.RemindAt != nil {
+		remindAt = *form.RemindAt
+	} else if form.Days > 0 {
+		remindAt = time.Now().AddDate(0, 0, int(form.Days))
+	} else {
+		ctx.Error(422, "", "Either remind_at or days must be given")
+		return
+	}
+	if !remindAt.After(time.Now()) {
+		ctx.Error(422, "", "The reminder must be in the future")
+		return
+	}
+
+	reminder, err := models.CreateIssueReminder(ctx.User, issue, timeutil.TimeStamp(remindAt.Unix()))
+	if err != nil {
+		ctx.Error(500, "CreateIssueReminder", err)
+		return
+	}
+	if err = reminder.LoadAttributes(); err != nil {
+		ctx.Error(500, "LoadAttributes", err)
+		return
+	}
+	ctx.JSON(201, reminder.APIFormat())
+}
+
+// ListMyIssueReminders list the pending issue reminders of the authenticated user
+func ListMyIssueReminders(ctx *context.APIContext) {
+	// swagger:operation GET /user/reminders user userCurrentListIssueReminders
+	// ---
+	// summary: List the pending issue reminders of the authenticated user
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueReminderList"
+	reminders, err := models.GetIssueRemindersByUser(ctx.User.ID)
+	if err != nil {
+		ctx.Error(500, "GetIssueRemindersByUser", err)
+		return
+	}
+
+	apiReminders := make([]*api.IssueReminder, len(reminders))
+	for i := range reminders {
+		apiReminders[i] = reminders[i].APIFormat()
+	}
+	ctx.JSON(200, &apiReminders)
+}
+
+// DeleteMyIssueReminder cancel an issue reminder of the authenticated user
+func DeleteMyIssueReminder(ctx *context.APIContext) {
+	// swagger:operation DELETE /user/reminders/{id} user userCurrentDeleteIssueReminder
+	// ---
+	// summary: Cancel an issue reminder of the authenticated user
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the reminder to cancel
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	reminder, err := models.GetIssueReminderByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrIssueReminderNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetIssueReminderByID", err)
+		}
+		return
+	}
+	if reminder.UserID != ctx.User.ID {
+		ctx.NotFound()
+		return
+	}
+
+	if err = models.DeleteIssueReminder(reminder); err != nil {
+		ctx.Error(500, "DeleteIssueReminder", err)
+		return
+	}
+	ctx.Status(204)
+}
@@ -0,0 +1,81 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// ListIssueSLAs list the response and resolution times of the issues which have a target
+func ListIssueSLAs(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issues/sla issue issueListSLAs
+	// ---
+	// summary: List the response and resolution times of the issues whose labels have targets
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: state
+	//   in: query
+	//   description: whether issue is open or closed, one of "open", "closed" or "all". Defaults to "open"
+	//   type: string
+	// - name: breached
+	//   in: query
+	//   description: if true, only issues which have missed their response or resolution target are returned
+	//   type: boolean
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueSLAList"
+	var isClosed util.OptionalBool
+	switch ctx.Query("state") {
+	case "closed":
+		isClosed = util.OptionalBoolTrue
+	case "all":
+		isClosed = util.OptionalBoolNone
+	default:
+		isClosed = util.OptionalBoolFalse
+	}
+
+	slas, err := models.GetIssueSLAs(&models.IssueSLAOptions{
+		RepoID:       ctx.Repo.Repository.ID,
+		IsClosed:     isClosed,
+		BreachedOnly: ctx.QueryBool("breached"),
+	})
+	if err != nil {
+		ctx.Error(500, "GetIssueSLAs", err)
+		return
+	}
+
+	apiSLAs := make([]*api.IssueSLA, len(slas))
+	for i, sla := range slas {
+		sla.Issue.Repo = ctx.Repo.Repository
+		apiSLAs[i] = &api.IssueSLA{
+			Issue:                 sla.Issue.APIFormat(),
+			ResponseTime:          sla.ResponseTime(),
+			ResponseTargetHours:   sla.ResponseTargetHours,
+			ResponseBreached:      sla.IsResponseBreached(),
+			ResolutionTime:        sla.ResolutionTime(),
+			ResolutionTargetHours: sla.ResolutionTargetHours,
+			ResolutionBreached:    sla.IsResolutionBreached(),
+		}
+		if sla.Issue.FirstResponseUnix > 0 {
+			firstResponse := sla.Issue.FirstResponseUnix.AsTime()
+			apiSLAs[i].FirstResponse = &firstResponse
+		}
+	}
+	ctx.JSON(200, &apiSLAs)
+}
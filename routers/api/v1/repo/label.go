@@ -128,8 +128,12 @@ func CreateLabel(ctx *context.APIContext, form api.CreateLabelOption) {
 		Color:       form.Color,
 		RepoID:      ctx.Repo.Repository.ID,
 		Description: form.Description,
+		Priority:    form.Priority,
+
+		ResponseTargetHours:   form.ResponseTargetHours,
+		ResolutionTargetHours: form.ResolutionTargetHours,
 	}
-	if err := models.NewLabel(label); err != nil {
+	if err := models.NewLabel(label, ctx.User); err != nil {
 		ctx.Error(500, "NewLabel", err)
 		return
 	}
@@ -188,7 +192,16 @@ func EditLabel(ctx *context.APIContext, form api.EditLabelOption) {
 	if form.Description != nil {
 		label.Description = *form.Description
 	}
-	if err := models.UpdateLabel(label); err != nil {
+	if form.Priority != nil {
+		label.Priority = *form.Priority
+	}
+	if form.ResponseTargetHours != nil {
+		label.ResponseTargetHours = *form.ResponseTargetHours
+	}
+	if form.ResolutionTargetHours != nil {
+		label.ResolutionTargetHours = *form.ResolutionTargetHours
+	}
+	if err := models.UpdateLabel(label, ctx.User); err != nil {
 		ctx.ServerError("UpdateLabel", err)
 		return
 	}
@@ -220,7 +233,7 @@ func DeleteLabel(ctx *context.APIContext) {
 	// responses:
 	//   "204":
 	//     "$ref": "#/responses/empty"
-	if err := models.DeleteLabel(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id")); err != nil {
+	if err := models.DeleteLabel(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"), ctx.User); err != nil {
 		ctx.Error(500, "DeleteLabel", err)
 		return
 	}
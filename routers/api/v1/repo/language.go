@@ -0,0 +1,46 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+)
+
+// GetLanguages returns the size of the code of each language of the default
+// branch of a repository
+func GetLanguages(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/languages repository repoGetLanguages
+	// ---
+	// summary: Get the size of the code of each language of the default branch of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/LanguageStatistics"
+	stats, err := ctx.Repo.Repository.GetLanguageStats()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetLanguageStats", err)
+		return
+	}
+
+	sizes := make(map[string]int64, len(stats))
+	for _, stat := range stats {
+		sizes[stat.Language] = stat.Size
+	}
+	ctx.JSON(http.StatusOK, sizes)
+}
@@ -13,11 +13,14 @@ import (
 	"code.gitea.io/gitea/modules/auth"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
+	issue_template "code.gitea.io/gitea/modules/issue/template"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/pull"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/routers/api/v1/convert"
+	"code.gitea.io/gitea/services/gitdiff"
 )
 
 // ListPullRequests returns a list of all PRs
@@ -154,6 +157,105 @@ func GetPullRequest(ctx *context.APIContext) {
 	ctx.JSON(200, pr.APIFormat())
 }
 
+// GetPullRequestDiff returns the changes of a PR
+func GetPullRequestDiff(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/files repository repoGetPullRequestDiff
+	// ---
+	// summary: Get the changed files of a pull request
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: style
+	//   in: query
+	//   description: the sections contain the lines of the unified view or the rows of the split view
+	//   type: string
+	//   enum: [unified, split]
+	// - name: stat_only
+	//   in: query
+	//   description: only return the totals of the changes without generating the diff of the files
+	//   type: boolean
+	// - name: path
+	//   in: query
+	//   description: only return the files matching the glob pattern, e.g. `**/*.go`
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of the files, the files are paginated if the page, limit or path is given
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of the files
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Diff"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetPullRequestByIndex", err)
+		}
+		return
+	}
+
+	if ctx.QueryBool("stat_only") {
+		size, err := pr.GetSize()
+		if err != nil {
+			ctx.Error(500, "GetSize", err)
+			return
+		}
+		ctx.JSON(200, &api.Diff{
+			TotalFiles:     size.ChangedFiles,
+			TotalAdditions: size.Additions,
+			TotalDeletions: size.Deletions,
+			Files:          []*api.DiffFile{},
+		})
+		return
+	}
+
+	// Paginated diffs only generate the diff of the files of the page
+	if len(ctx.Query("path")) > 0 || ctx.QueryInt("page") > 0 || ctx.QueryInt("limit") > 0 {
+		pageSize := convert.ToCorrectPageSize(ctx.QueryInt("limit"))
+		diff, total, err := gitdiff.GetPullRequestDiffPage(pr, "", ctx.Query("path"), ctx.QueryInt("page"), pageSize)
+		if err != nil {
+			ctx.Error(500, "GetPullRequestDiffPage", err)
+			return
+		}
+		apiDiff := diff.APIFormat(ctx.Query("style") == "split")
+		apiDiff.TotalFiles = total
+		ctx.SetLinkHeader(total, pageSize)
+		ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", total))
+		ctx.JSON(200, apiDiff)
+		return
+	}
+
+	diff, err := gitdiff.GetPullRequestDiff(pr, "")
+	if err != nil {
+		ctx.Error(500, "GetPullRequestDiff", err)
+		return
+	}
+	ctx.JSON(200, diff.APIFormat(ctx.Query("style") == "split"))
+}
+
 // CreatePullRequest does what it says
 func CreatePullRequest(ctx *context.APIContext, form api.CreatePullRequestOption) {
 	// swagger:operation POST /repos/{owner}/{repo}/pulls repository repoCreatePullRequest
@@ -181,6 +283,8 @@ func CreatePullRequest(ctx *context.APIContext, form api.CreatePullRequestOption
 	// responses:
 	//   "201":
 	//     "$ref": "#/responses/PullRequest"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 	var (
 		repo        = ctx.Repo.Repository
 		labelIDs    []int64
@@ -188,8 +292,20 @@ func CreatePullRequest(ctx *context.APIContext, form api.CreatePullRequestOption
 		milestoneID int64
 	)
 
+	// user should have permission to read the pull requests of the base repository
+	if !ctx.Repo.CanReadIssuesOrPulls(true) {
+		if log.IsTrace() {
+			log.Trace("Permission Denied: User %-v cannot create/read pull requests in Repo %-v\nUser in baseRepo has Permissions: %-+v",
+				ctx.User,
+				repo,
+				ctx.Repo.Permission)
+		}
+		ctx.NotFound("Can't read pulls")
+		return
+	}
+
 	// Get repo/branch information
-	headUser, headRepo, headGitRepo, compareInfo, baseBranch, headBranch := parseCompareInfo(ctx, form)
+	headUser, headRepo, headGitRepo, compareInfo, baseBranch, headBranch := parseCompareInfo(ctx, form.Base, form.Head, true)
 	if ctx.Written() {
 		return
 	}
@@ -228,12 +344,12 @@ func CreatePullRequest(ctx *context.APIContext, form api.CreatePullRequestOption
 	}
 
 	if form.Milestone > 0 {
-		milestone, err := models.GetMilestoneByRepoID(ctx.Repo.Repository.ID, milestoneID)
+		milestone, err := models.GetAssignableMilestone(ctx.Repo.Repository.ID, milestoneID)
 		if err != nil {
 			if models.IsErrMilestoneNotExist(err) {
 				ctx.NotFound()
 			} else {
-				ctx.Error(500, "GetMilestoneByRepoID", err)
+				ctx.Error(500, "GetAssignableMilestone", err)
 			}
 			return
 		}
@@ -241,6 +357,18 @@ func CreatePullRequest(ctx *context.APIContext, form api.CreatePullRequestOption
 		milestoneID = milestone.ID
 	}
 
+	if len(strings.TrimSpace(form.Body)) == 0 {
+		defaultCommit, err := ctx.Repo.GitRepo.GetBranchCommit(ctx.Repo.Repository.DefaultBranch)
+		if err != nil {
+			ctx.Error(500, "GetBranchCommit", err)
+			return
+		}
+		if form.Body, err = issue_template.GetPullRequestTemplate(defaultCommit, form.Template); err != nil {
+			ctx.Error(500, "GetPullRequestTemplate", err)
+			return
+		}
+	}
+
 	patch, err := headGitRepo.GetPatch(compareInfo.MergeBase, headBranch)
 	if err != nil {
 		ctx.Error(500, "GetPatch", err)
@@ -264,15 +392,17 @@ func CreatePullRequest(ctx *context.APIContext, form api.CreatePullRequestOption
 		DeadlineUnix: deadlineUnix,
 	}
 	pr := &models.PullRequest{
-		HeadRepoID:   headRepo.ID,
-		BaseRepoID:   repo.ID,
-		HeadUserName: headUser.Name,
-		HeadBranch:   headBranch,
-		BaseBranch:   baseBranch,
-		HeadRepo:     headRepo,
-		BaseRepo:     repo,
-		MergeBase:    compareInfo.MergeBase,
-		Type:         models.PullRequestGitea,
+		HeadRepoID:          headRepo.ID,
+		BaseRepoID:          repo.ID,
+		HeadUserName:        headUser.Name,
+		HeadBranch:          headBranch,
+		BaseBranch:          baseBranch,
+		HeadRepo:            headRepo,
+		BaseRepo:            repo,
+		MergeBase:           compareInfo.MergeBase,
+		Type:                models.PullRequestGitea,
+		IsDraft:             form.Draft,
+		AllowMaintainerEdit: form.AllowMaintainerEdit,
 	}
 
 	// Get all assignee IDs
@@ -290,6 +420,9 @@ func CreatePullRequest(ctx *context.APIContext, form api.CreatePullRequestOption
 		if models.IsErrUserDoesNotHaveAccessToRepo(err) {
 			ctx.Error(400, "UserDoesNotHaveAccessToRepo", err)
 			return
+		} else if models.IsErrLabelExclusiveScopeConflict(err) {
+			ctx.Error(422, "", err)
+			return
 		}
 		ctx.Error(500, "NewPullRequest", err)
 		return
@@ -299,6 +432,18 @@ func CreatePullRequest(ctx *context.APIContext, form api.CreatePullRequestOption
 	}
 
 	notification.NotifyNewPullRequest(pr)
+	if err := pull.RequestCodeOwnerReviews(pr, ctx.User); err != nil {
+		log.Error("RequestCodeOwnerReviews: %v", err)
+	}
+	if err := pr.ApplyPathLabels(ctx.User); err != nil {
+		log.Error("ApplyPathLabels: %v", err)
+	}
+	if err := pr.LintCommitMessages(ctx.User); err != nil {
+		log.Error("LintCommitMessages: %v", err)
+	}
+	if err := pr.AssignTeamReviewers(ctx.User); err != nil {
+		log.Error("AssignTeamReviewers: %v", err)
+	}
 
 	log.Trace("Pull request created: %d/%d", repo.ID, prIssue.ID)
 	ctx.JSON(201, pr.APIFormat())
@@ -337,6 +482,12 @@ func EditPullRequest(ctx *context.APIContext, form api.EditPullRequestOption) {
 	// responses:
 	//   "201":
 	//     "$ref": "#/responses/PullRequest"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
 	if err != nil {
 		if models.IsErrPullRequestNotExist(err) {
@@ -359,6 +510,11 @@ func EditPullRequest(ctx *context.APIContext, form api.EditPullRequestOption) {
 		ctx.Status(403)
 		return
 	}
+	// Only the poster can grant maintainers access to the head branch
+	if form.AllowMaintainerEdit != nil && *form.AllowMaintainerEdit != pr.AllowMaintainerEdit && !issue.IsPoster(ctx.User.ID) {
+		ctx.Status(403)
+		return
+	}
 
 	if len(form.Title) > 0 {
 		issue.Title = form.Title
@@ -437,6 +593,45 @@ func EditPullRequest(ctx *context.APIContext, form api.EditPullRequestOption) {
 		notification.NotifyIssueChangeStatus(ctx.User, issue, api.StateClosed == api.StateType(*form.State))
 	}
 
+	if form.Draft != nil && *form.Draft != pr.IsDraft {
+		if err = pr.ChangeDraft(*form.Draft); err != nil {
+			ctx.Error(500, "ChangeDraft", err)
+			return
+		}
+		if !pr.IsDraft {
+			notification.NotifyPullRequestReadyForReview(ctx.User, pr)
+			if err = pr.AssignTeamReviewers(ctx.User); err != nil {
+				log.Error("AssignTeamReviewers: %v", err)
+			}
+		}
+	}
+
+	if form.AllowMaintainerEdit != nil {
+		if err = pr.ChangeAllowMaintainerEdit(*form.AllowMaintainerEdit); err != nil {
+			ctx.Error(500, "ChangeAllowMaintainerEdit", err)
+			return
+		}
+	}
+
+	if len(form.Base) > 0 && form.Base != pr.BaseBranch {
+		if pr.HasMerged || issue.IsClosed {
+			ctx.Error(http.StatusUnprocessableEntity, "", "pull request is closed")
+			return
+		}
+		if !ctx.Repo.GitRepo.IsBranchExist(form.Base) || (pr.HeadRepoID == pr.BaseRepoID && form.Base == pr.HeadBranch) {
+			ctx.Error(http.StatusUnprocessableEntity, "", "invalid base branch")
+			return
+		}
+		if err = pull.ChangeTargetBranch(pr, ctx.User, form.Base); err != nil {
+			if models.IsErrPullRequestAlreadyExists(err) {
+				ctx.Error(http.StatusConflict, "", err.Error())
+			} else {
+				ctx.Error(500, "ChangeTargetBranch", err)
+			}
+			return
+		}
+	}
+
 	// Refetch from database
 	pr, err = models.GetPullRequestByIndex(ctx.Repo.Repository.ID, pr.Index)
 	if err != nil {
@@ -528,8 +723,12 @@ func MergePullRequest(ctx *context.APIContext, form auth.MergePullRequestForm) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/empty"
+	//   "202":
+	//     "$ref": "#/responses/empty"
 	//   "405":
 	//     "$ref": "#/responses/empty"
+	//   "409":
+	//     "$ref": "#/responses/error"
 	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
 	if err != nil {
 		if models.IsErrPullRequestNotExist(err) {
@@ -565,11 +764,25 @@ func MergePullRequest(ctx *context.APIContext, form auth.MergePullRequestForm) {
 		return
 	}
 
-	if !pr.CanAutoMerge() || pr.HasMerged || pr.IsWorkInProgress() {
+	if form.MergeWhenChecksSucceed {
+		scheduleAutoMerge(ctx, pr, form)
+		return
+	}
+
+	if !pr.CanAutoMerge() || pr.HasMerged || pr.IsWorkInProgress() || pr.IsDraft {
 		ctx.Status(405)
 		return
 	}
 
+	if err = pr.LoadProtectedBranch(); err != nil {
+		ctx.Error(500, "LoadProtectedBranch", err)
+		return
+	}
+	if pr.ProtectedBranch != nil && pr.ProtectedBranch.EnableMergeQueue {
+		ctx.Error(405, "", "pull requests to this branch are merged by the merge queue")
+		return
+	}
+
 	if len(form.Do) == 0 {
 		form.Do = string(models.MergeStyleMerge)
 	}
@@ -590,11 +803,16 @@ func MergePullRequest(ctx *context.APIContext, form auth.MergePullRequestForm) {
 	}
 
 	if err := pull.Merge(pr, ctx.User, ctx.Repo.GitRepo, models.MergeStyle(form.Do), message); err != nil {
-		if models.IsErrInvalidMergeStyle(err) {
+		switch {
+		case models.IsErrInvalidMergeStyle(err):
 			ctx.Status(405)
-			return
+		case models.IsErrRequiredStatusChecksMissing(err), models.IsErrPullRequestSizeExceeded(err), models.IsErrUnresolvedConversations(err), models.IsErrPullRequestOutOfDate(err):
+			ctx.Error(http.StatusMethodNotAllowed, "", err.Error())
+		case models.IsErrMergeConflicts(err), models.IsErrRebaseConflicts(err), models.IsErrMergeNotFastForward(err):
+			ctx.Error(http.StatusConflict, "", err.Error())
+		default:
+			ctx.Error(500, "Merge", err)
 		}
-		ctx.Error(500, "Merge", err)
 		return
 	}
 
@@ -602,18 +820,264 @@ func MergePullRequest(ctx *context.APIContext, form auth.MergePullRequestForm) {
 	ctx.Status(200)
 }
 
-func parseCompareInfo(ctx *context.APIContext, form api.CreatePullRequestOption) (*models.User, *models.Repository, *git.Repository, *git.CompareInfo, string, string) {
+// scheduleAutoMerge schedules the pull request to be merged once its checks succeed
+func scheduleAutoMerge(ctx *context.APIContext, pr *models.PullRequest, form auth.MergePullRequestForm) {
+	if pr.HasMerged || pr.IsWorkInProgress() || pr.IsDraft {
+		ctx.Status(405)
+		return
+	}
+
+	if len(form.Do) == 0 {
+		form.Do = string(models.MergeStyleMerge)
+	}
+	// The default message is generated when the pull request is merged
+	message := strings.TrimSpace(form.MergeTitleField)
+	form.MergeMessageField = strings.TrimSpace(form.MergeMessageField)
+	if len(message) > 0 && len(form.MergeMessageField) > 0 {
+		message += "\n\n" + form.MergeMessageField
+	}
+
+	if err := pull.ScheduleAutoMerge(ctx.User, pr, models.MergeStyle(form.Do), message); err != nil {
+		switch {
+		case models.IsErrInvalidMergeStyle(err), models.IsErrNotAllowedToMerge(err):
+			ctx.Status(405)
+		case models.IsErrPullAutoMergeAlreadyScheduled(err):
+			ctx.Error(http.StatusConflict, "", err.Error())
+		default:
+			ctx.Error(500, "ScheduleAutoMerge", err)
+		}
+		return
+	}
+
+	log.Trace("Pull request scheduled for automatic merge: %d", pr.ID)
+	ctx.Status(http.StatusAccepted)
+}
+
+// CancelScheduledAutoMerge cancels the scheduled automatic merge of a pull request
+func CancelScheduledAutoMerge(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/pulls/{index}/merge repository repoCancelScheduledAutoMerge
+	// ---
+	// summary: Cancel the scheduled automatic merge of a pull request
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetPullRequestByIndex", err)
+		}
+		return
+	}
+
+	if err = pull.CancelAutoMerge(pr); err != nil {
+		if models.IsErrPullAutoMergeNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "CancelAutoMerge", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// MarkPullRequestReady marks a draft or work in progress pull request as ready for review
+func MarkPullRequestReady(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/ready repository repoMarkPullRequestReady
+	// ---
+	// summary: Mark a pull request as ready for review, which removes its draft state and the work in progress prefix of its title
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullRequest"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetPullRequestByIndex", err)
+		}
+		return
+	}
+	if err = pr.LoadIssue(); err != nil {
+		ctx.Error(500, "LoadIssue", err)
+		return
+	}
+	issue := pr.Issue
+	issue.Repo = ctx.Repo.Repository
+	if !issue.IsPoster(ctx.User.ID) && !ctx.Repo.CanWrite(models.UnitTypePullRequests) {
+		ctx.Status(http.StatusForbidden)
+		return
+	}
+
+	if pr.IsDraft {
+		if err = pr.ChangeDraft(false); err != nil {
+			ctx.Error(500, "ChangeDraft", err)
+			return
+		}
+		notification.NotifyPullRequestReadyForReview(ctx.User, pr)
+		if err = pr.AssignTeamReviewers(ctx.User); err != nil {
+			log.Error("AssignTeamReviewers: %v", err)
+		}
+	}
+	oldTitle, err := pr.RemoveWorkInProgressPrefix(ctx.User)
+	if err != nil {
+		if models.IsErrIssueTitleEmpty(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err.Error())
+		} else {
+			ctx.Error(500, "RemoveWorkInProgressPrefix", err)
+		}
+		return
+	} else if len(oldTitle) > 0 {
+		notification.NotifyIssueChangeTitle(ctx.User, issue, oldTitle)
+	}
+	ctx.JSON(200, pr.APIFormat())
+}
+
+// UpdatePullRequest updates the head branch of a pull request with its base branch
+func UpdatePullRequest(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/update repository repoUpdatePullRequest
+	// ---
+	// summary: Merge the base branch of a pull request into its head branch or rebase the head branch
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request to update
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: style
+	//   in: query
+	//   description: how to update the head branch, merge by default
+	//   type: string
+	//   enum: [merge, rebase]
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetPullRequestByIndex", err)
+		}
+		return
+	}
+	if err = pr.LoadIssue(); err != nil {
+		ctx.Error(500, "LoadIssue", err)
+		return
+	}
+	if pr.Issue.IsClosed {
+		ctx.Error(http.StatusUnprocessableEntity, "", "pull request is closed")
+		return
+	}
+
+	allowed, err := pull.IsUserAllowedToUpdate(pr, ctx.User)
+	if err != nil {
+		ctx.Error(500, "IsUserAllowedToUpdate", err)
+		return
+	} else if !allowed {
+		ctx.Status(http.StatusForbidden)
+		return
+	}
+
+	style := models.MergeStyle(ctx.Query("style"))
+	if len(style) == 0 {
+		style = models.MergeStyleMerge
+	}
+	if err = pull.Update(pr, ctx.User, style); err != nil {
+		switch {
+		case models.IsErrInvalidMergeStyle(err), models.IsErrPullRequestUpToDate(err):
+			ctx.Error(http.StatusUnprocessableEntity, "", err.Error())
+		case models.IsErrUserCannotCommit(err):
+			ctx.Status(http.StatusForbidden)
+		case models.IsErrMergeConflicts(err), models.IsErrRebaseConflicts(err):
+			ctx.Error(http.StatusConflict, "", err.Error())
+		default:
+			ctx.Error(500, "Update", err)
+		}
+		return
+	}
+
+	log.Trace("Pull request updated: %d", pr.ID)
+	ctx.Status(200)
+}
+
+// parseCompareInfo parses the compared refs and returns the head user, the head
+// repository and the comparison. The refs must be branches if onlyBranches,
+// otherwise they can also be tags or commits, which are then returned as
+// their full SHA.
+func parseCompareInfo(ctx *context.APIContext, base, head string, onlyBranches bool) (*models.User, *models.Repository, *git.Repository, *git.CompareInfo, string, string) {
 	baseRepo := ctx.Repo.Repository
 
 	// Get compared branches information
-	// format: <base branch>...[<head repo>:]<head branch>
+	// format: [<base owner>:]<base branch> and [<head owner>:]<head branch>
 	// base<-head: master...head:feature
+	// across forks: base:master...head:feature
 	// same repo: master...feature
 
-	// TODO: Validate form first?
-
-	baseBranch := form.Base
-
 	var (
 		headUser   *models.User
 		headBranch string
@@ -621,8 +1085,18 @@ func parseCompareInfo(ctx *context.APIContext, form api.CreatePullRequestOption)
 		err        error
 	)
 
+	// The owner of the base branch is the owner of the repository
+	baseBranch := base
+	if baseInfos := strings.Split(base, ":"); len(baseInfos) == 2 {
+		if !strings.EqualFold(baseInfos[0], baseRepo.Owner.Name) {
+			ctx.NotFound()
+			return nil, nil, nil, nil, "", ""
+		}
+		baseBranch = baseInfos[1]
+	}
+
 	// If there is no head repository, it means pull request between same repository.
-	headInfos := strings.Split(form.Head, ":")
+	headInfos := strings.Split(head, ":")
 	if len(headInfos) == 1 {
 		isSameRepo = true
 		headUser = ctx.Repo.Owner
@@ -639,6 +1113,7 @@ func parseCompareInfo(ctx *context.APIContext, form api.CreatePullRequestOption)
 			return nil, nil, nil, nil, "", ""
 		}
 		headBranch = headInfos[1]
+		isSameRepo = headUser.ID == baseRepo.OwnerID
 
 	} else {
 		ctx.NotFound()
@@ -646,19 +1121,22 @@ func parseCompareInfo(ctx *context.APIContext, form api.CreatePullRequestOption)
 	}
 
 	ctx.Repo.PullRequest.SameRepo = isSameRepo
-	log.Info("Base branch: %s", baseBranch)
-	log.Info("Repo path: %s", ctx.Repo.GitRepo.Path)
 	// Check if base branch is valid.
-	if !ctx.Repo.GitRepo.IsBranchExist(baseBranch) {
+	var ok bool
+	if baseBranch, ok = resolveCompareRef(ctx.Repo.GitRepo, baseBranch, onlyBranches); !ok {
 		ctx.NotFound("IsBranchExist")
 		return nil, nil, nil, nil, "", ""
 	}
 
-	// Check if current user has fork of repository or in the same repository.
-	headRepo, has := models.HasForkedRepo(headUser.ID, baseRepo.ID)
-	if !has && !isSameRepo {
-		log.Trace("parseCompareInfo[%d]: does not have fork or in same repository", baseRepo.ID)
-		ctx.NotFound("HasForkedRepo")
+	// The head repository has to belong to the fork network of the base repository
+	headRepo, err := models.GetRepositoryInForkNetwork(headUser.ID, baseRepo)
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			log.Trace("parseCompareInfo[%d]: %s has no repository in the fork network", baseRepo.ID, headUser.Name)
+			ctx.NotFound("GetRepositoryInForkNetwork")
+		} else {
+			ctx.ServerError("GetRepositoryInForkNetwork", err)
+		}
 		return nil, nil, nil, nil, "", ""
 	}
 
@@ -674,23 +1152,6 @@ func parseCompareInfo(ctx *context.APIContext, form api.CreatePullRequestOption)
 		}
 	}
 
-	// user should have permission to read baseRepo's codes and pulls, NOT headRepo's
-	permBase, err := models.GetUserRepoPermission(baseRepo, ctx.User)
-	if err != nil {
-		ctx.ServerError("GetUserRepoPermission", err)
-		return nil, nil, nil, nil, "", ""
-	}
-	if !permBase.CanReadIssuesOrPulls(true) || !permBase.CanRead(models.UnitTypeCode) {
-		if log.IsTrace() {
-			log.Trace("Permission Denied: User %-v cannot create/read pull requests or cannot read code in Repo %-v\nUser in baseRepo has Permissions: %-+v",
-				ctx.User,
-				baseRepo,
-				permBase)
-		}
-		ctx.NotFound("Can't read pulls or can't read UnitTypeCode")
-		return nil, nil, nil, nil, "", ""
-	}
-
 	// user should have permission to read headrepo's codes
 	permHead, err := models.GetUserRepoPermission(headRepo, ctx.User)
 	if err != nil {
@@ -709,7 +1170,7 @@ func parseCompareInfo(ctx *context.APIContext, form api.CreatePullRequestOption)
 	}
 
 	// Check if head branch is valid.
-	if !headGitRepo.IsBranchExist(headBranch) {
+	if headBranch, ok = resolveCompareRef(headGitRepo, headBranch, onlyBranches); !ok {
 		ctx.NotFound()
 		return nil, nil, nil, nil, "", ""
 	}
@@ -722,3 +1183,22 @@ func parseCompareInfo(ctx *context.APIContext, form api.CreatePullRequestOption)
 
 	return headUser, headRepo, headGitRepo, compareInfo, baseBranch, headBranch
 }
+
+// resolveCompareRef returns the ref to compare if it is a branch of the
+// repository or, unless onlyBranches, a tag or a commit, which is returned as
+// its full SHA so that it does not depend on the repository it is used in
+func resolveCompareRef(gitRepo *git.Repository, ref string, onlyBranches bool) (string, bool) {
+	if gitRepo.IsBranchExist(ref) {
+		return ref, true
+	} else if onlyBranches {
+		return "", false
+	}
+	if gitRepo.IsTagExist(ref) {
+		return ref, true
+	}
+	commit, err := gitRepo.GetCommit(ref)
+	if err != nil {
+		return "", false
+	}
+	return commit.ID.String(), true
+}
@@ -0,0 +1,256 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/pull"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ListMergeQueue lists the pull requests in the merge queue of a branch
+func ListMergeQueue(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/merge_queue repository repoListMergeQueue
+	// ---
+	// summary: List the pull requests in the merge queue of a branch in the order they will be merged
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: branch
+	//   in: query
+	//   description: name of the base branch
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/MergeQueueEntryList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	branch := ctx.Query("branch")
+	if len(branch) == 0 {
+		ctx.Error(http.StatusUnprocessableEntity, "", "branch is required")
+		return
+	}
+
+	entries, err := models.GetMergeQueue(ctx.Repo.Repository.ID, branch)
+	if err != nil {
+		ctx.Error(500, "GetMergeQueue", err)
+		return
+	}
+
+	apiEntries := make([]*api.MergeQueueEntry, 0, len(entries))
+	for i, entry := range entries {
+		if err = entry.LoadAttributes(); err != nil {
+			ctx.Error(500, "LoadAttributes", err)
+			return
+		}
+		apiEntries = append(apiEntries, entry.APIFormat(i+1))
+	}
+	ctx.JSON(200, &apiEntries)
+}
+
+// GetPullMergeQueueEntry gets the merge queue entry of a pull request
+func GetPullMergeQueueEntry(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/merge_queue repository repoGetPullMergeQueueEntry
+	// ---
+	// summary: Get the place of a pull request in the merge queue
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/MergeQueueEntry"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	pr := getPullRequestForReview(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	entry, err := models.GetMergeQueueEntryByPullID(pr.ID)
+	if err != nil {
+		if models.IsErrMergeQueueEntryNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetMergeQueueEntryByPullID", err)
+		}
+		return
+	}
+	entry.PullRequest = pr
+	mergeQueueEntryJSON(ctx, http.StatusOK, entry)
+}
+
+// AddPullToMergeQueue adds a pull request to the merge queue
+func AddPullToMergeQueue(ctx *context.APIContext, form auth.MergePullRequestForm) {
+	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/merge_queue repository repoAddPullToMergeQueue
+	// ---
+	// summary: Add a pull request to the merge queue of its base branch
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     $ref: "#/definitions/MergePullRequestOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/MergeQueueEntry"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "405":
+	//     "$ref": "#/responses/empty"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	pr := getPullRequestForReview(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if pr.Issue.IsClosed {
+		ctx.NotFound()
+		return
+	}
+	if !pr.CanAutoMerge() || pr.HasMerged || pr.IsWorkInProgress() || pr.IsDraft {
+		ctx.Status(405)
+		return
+	}
+
+	if len(form.Do) == 0 {
+		form.Do = string(models.MergeStyleMerge)
+	}
+	message := strings.TrimSpace(form.MergeTitleField)
+	if len(message) == 0 {
+		if models.MergeStyle(form.Do) == models.MergeStyleSquash {
+			message = pr.GetDefaultSquashMessage()
+		} else {
+			message = pr.GetDefaultMergeMessage()
+		}
+	}
+	form.MergeMessageField = strings.TrimSpace(form.MergeMessageField)
+	if len(form.MergeMessageField) > 0 {
+		message += "\n\n" + form.MergeMessageField
+	}
+
+	entry, err := pull.AddToMergeQueue(pr, ctx.User, models.MergeStyle(form.Do), message)
+	if err != nil {
+		switch {
+		case models.IsErrMergeQueueDisabled(err), models.IsErrInvalidMergeStyle(err), models.IsErrNotAllowedToMerge(err):
+			ctx.Error(http.StatusUnprocessableEntity, "", err.Error())
+		case models.IsErrMergeQueueEntryAlreadyExist(err):
+			ctx.Error(http.StatusConflict, "", err.Error())
+		default:
+			ctx.Error(500, "AddToMergeQueue", err)
+		}
+		return
+	}
+	mergeQueueEntryJSON(ctx, http.StatusCreated, entry)
+}
+
+// RemovePullFromMergeQueue removes a pull request from the merge queue
+func RemovePullFromMergeQueue(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/pulls/{index}/merge_queue repository repoRemovePullFromMergeQueue
+	// ---
+	// summary: Remove a pull request from the merge queue
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	pr := getPullRequestForReview(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := pull.RemoveFromMergeQueue(pr); err != nil {
+		if models.IsErrMergeQueueEntryNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "RemoveFromMergeQueue", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func mergeQueueEntryJSON(ctx *context.APIContext, status int, entry *models.MergeQueueEntry) {
+	if err := entry.LoadAttributes(); err != nil {
+		ctx.Error(500, "LoadAttributes", err)
+		return
+	}
+	position, err := models.GetMergeQueuePosition(entry)
+	if err != nil {
+		ctx.Error(500, "GetMergeQueuePosition", err)
+		return
+	}
+	ctx.JSON(status, entry.APIFormat(position))
+}
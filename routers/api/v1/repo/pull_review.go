@@ -0,0 +1,827 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/pull"
+	api "code.gitea.io/gitea/modules/structs"
+	comment_service "code.gitea.io/gitea/services/comments"
+	"code.gitea.io/gitea/services/gitdiff"
+)
+
+// ListPullReviews lists all reviews of a pull request
+func ListPullReviews(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/reviews repository repoListPullReviews
+	// ---
+	// summary: List all reviews for a pull request.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullReviewList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	pr := getPullRequestForReview(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	reviews, err := models.FindReviews(models.FindReviewOptions{
+		Type:    models.ReviewTypeUnknown,
+		IssueID: pr.IssueID,
+	})
+	if err != nil {
+		ctx.Error(500, "FindReviews", err)
+		return
+	}
+
+	apiReviews := make([]*api.PullReview, 0, len(reviews))
+	for _, review := range reviews {
+		// Pending reviews are only visible to their reviewer
+		if review.Type == models.ReviewTypePending && (ctx.User == nil || review.ReviewerID != ctx.User.ID) {
+			continue
+		}
+		apiReview, err := reviewAPIFormat(review, pr.Issue)
+		if err != nil {
+			ctx.Error(500, "APIFormat", err)
+			return
+		}
+		apiReviews = append(apiReviews, apiReview)
+	}
+	ctx.JSON(200, &apiReviews)
+}
+
+// GetPullReview gets a specific review of a pull request
+func GetPullReview(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/reviews/{id} repository repoGetPullReview
+	// ---
+	// summary: Get a specific review for a pull request.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the review
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullReview"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	pr, review := getPullReview(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	apiReview, err := reviewAPIFormat(review, pr.Issue)
+	if err != nil {
+		ctx.Error(500, "APIFormat", err)
+		return
+	}
+	ctx.JSON(200, apiReview)
+}
+
+// ListPullReviewComments lists all code comments of a review of a pull request
+func ListPullReviewComments(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/reviews/{id}/comments repository repoListPullReviewComments
+	// ---
+	// summary: List all code comments of a review of a pull request.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the review
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullReviewCommentList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	pr, review := getPullReview(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	review.Issue = pr.Issue
+	comments, err := review.GetCodeComments()
+	if err != nil {
+		ctx.Error(500, "GetCodeComments", err)
+		return
+	}
+	apiComments := make([]*api.PullReviewComment, len(comments))
+	for i, comment := range comments {
+		apiComments[i] = comment.APIFormatReviewComment()
+	}
+	ctx.JSON(200, apiComments)
+}
+
+// ResolvePullReviewComment marks the conversation of a code comment as resolved
+func ResolvePullReviewComment(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/comments/{id}/resolve repository repoResolvePullReviewComment
+	// ---
+	// summary: Mark the conversation of a code comment of a pull request as resolved.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the code comment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	markPullReviewConversation(ctx, true)
+}
+
+// UnresolvePullReviewComment marks the conversation of a code comment as unresolved
+func UnresolvePullReviewComment(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/pulls/{index}/comments/{id}/resolve repository repoUnresolvePullReviewComment
+	// ---
+	// summary: Mark the conversation of a code comment of a pull request as unresolved.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the code comment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	markPullReviewConversation(ctx, false)
+}
+
+func markPullReviewConversation(ctx *context.APIContext, isResolve bool) {
+	pr := getPullRequestForReview(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	comment, err := models.GetCommentByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrCommentNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetCommentByID", err)
+		}
+		return
+	}
+	if comment.IssueID != pr.IssueID || comment.Type != models.CommentTypeCode {
+		ctx.NotFound()
+		return
+	}
+	if canMark, err := models.CanMarkConversation(pr.Issue, ctx.User); err != nil {
+		ctx.Error(500, "CanMarkConversation", err)
+		return
+	} else if !canMark {
+		ctx.Error(http.StatusForbidden, "", "you are not allowed to resolve the conversations of this pull request")
+		return
+	}
+
+	if err = models.MarkConversation(comment, ctx.User, isResolve); err != nil {
+		ctx.Error(500, "MarkConversation", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// CreatePullReview creates a review for a pull request
+func CreatePullReview(ctx *context.APIContext, opts api.CreatePullReviewOptions) {
+	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/reviews repository repoCreatePullReview
+	// ---
+	// summary: Create a review for a pull request.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CreatePullReviewOptions"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullReview"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	pr := getPullRequestForReview(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	reviewType := models.ReviewTypePending
+	if opts.Event != api.ReviewStateUnknown {
+		reviewType = preparePullReviewType(ctx, pr.Issue, opts.Event)
+		if ctx.Written() {
+			return
+		}
+	}
+
+	var review *models.Review
+	var err error
+	if len(opts.Comments) > 0 || reviewType == models.ReviewTypePending {
+		// Code comments are always added to the pending review of the doer
+		if review, err = models.GetCurrentReview(ctx.User, pr.Issue); err != nil {
+			if !models.IsErrReviewNotExist(err) {
+				ctx.Error(500, "GetCurrentReview", err)
+				return
+			}
+			if review, err = pull.CreateReview(models.CreateReviewOptions{
+				Type:     models.ReviewTypePending,
+				Reviewer: ctx.User,
+				Issue:    pr.Issue,
+			}); err != nil {
+				ctx.Error(500, "CreateReview", err)
+				return
+			}
+		}
+
+		for _, c := range opts.Comments {
+			line, startLine := c.NewLineNum, c.StartNewLineNum
+			if c.OldLineNum > 0 {
+				line, startLine = c.OldLineNum*-1, c.StartOldLineNum*-1
+			}
+			if _, err = comment_service.CreateCodeComment(
+				ctx.User,
+				ctx.Repo.Repository,
+				pr.Issue,
+				c.Body,
+				c.Path,
+				startLine,
+				line,
+				review.ID,
+			); err != nil {
+				if models.IsErrInvalidCodeCommentRange(err) {
+					ctx.Error(http.StatusUnprocessableEntity, "", err.Error())
+				} else {
+					ctx.Error(500, "CreateCodeComment", err)
+				}
+				return
+			}
+		}
+	}
+
+	if reviewType != models.ReviewTypePending {
+		review = submitPullReview(ctx, pr, reviewType, opts.Body)
+		if ctx.Written() {
+			return
+		}
+	} else if len(opts.Body) > 0 {
+		review.Content = opts.Body
+		if err = models.UpdateReview(review); err != nil {
+			ctx.Error(500, "UpdateReview", err)
+			return
+		}
+	}
+
+	apiReview, err := reviewAPIFormat(review, pr.Issue)
+	if err != nil {
+		ctx.Error(500, "APIFormat", err)
+		return
+	}
+	ctx.JSON(200, apiReview)
+}
+
+// SubmitPullReview submits a pending review of a pull request
+func SubmitPullReview(ctx *context.APIContext, opts api.SubmitPullReviewOptions) {
+	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/reviews/{id} repository repoSubmitPullReview
+	// ---
+	// summary: Submit a pending review of a pull request.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the review
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/SubmitPullReviewOptions"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullReview"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	pr, review := getPullReview(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if review.Type != models.ReviewTypePending || review.ReviewerID != ctx.User.ID {
+		ctx.Error(http.StatusUnprocessableEntity, "", "only your own pending reviews can be submitted")
+		return
+	}
+
+	reviewType := preparePullReviewType(ctx, pr.Issue, opts.Event)
+	if ctx.Written() {
+		return
+	}
+	if reviewType == models.ReviewTypePending {
+		ctx.Error(http.StatusUnprocessableEntity, "", "a pending review can not be submitted as pending")
+		return
+	}
+
+	review = submitPullReview(ctx, pr, reviewType, opts.Body)
+	if ctx.Written() {
+		return
+	}
+
+	apiReview, err := reviewAPIFormat(review, pr.Issue)
+	if err != nil {
+		ctx.Error(500, "APIFormat", err)
+		return
+	}
+	ctx.JSON(200, apiReview)
+}
+
+// getPullRequestForReview returns the pull request of the index parameter
+// with its issue loaded.
+// ReRequestPullReview requests a review again from the reviewer of a review
+func ReRequestPullReview(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/reviews/{id}/rerequest repository repoReRequestPullReview
+	// ---
+	// summary: Request a new review from the reviewer of an approval or rejection, their current review no longer counts.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the review
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	pr, review := getPullReview(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	// The poster and writers can request reviews
+	if pr.Issue.PosterID != ctx.User.ID && !ctx.Repo.CanWrite(models.UnitTypePullRequests) {
+		ctx.Error(http.StatusForbidden, "", "Only the poster and writers can request reviews")
+		return
+	}
+	if pr.Issue.IsClosed {
+		ctx.Error(http.StatusUnprocessableEntity, "", "The pull request is closed")
+		return
+	}
+
+	if err := review.LoadReviewer(); err != nil {
+		if models.IsErrUserNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "LoadReviewer", err)
+		}
+		return
+	}
+
+	if _, err := pull.ReRequestReview(ctx.User, pr.Issue, review.Reviewer); err != nil {
+		if models.IsErrCannotReRequestReview(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", "A review can only be requested again from a reviewer who has approved or rejected the pull request")
+			return
+		}
+		ctx.Error(500, "ReRequestReview", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListPullViewedFiles lists the changed files of a pull request marked as viewed by the user
+func ListPullViewedFiles(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/files/viewed repository repoListPullViewedFiles
+	// ---
+	// summary: List the changed files of a pull request marked as viewed by the authenticated user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullViewedFileList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	pr := getPullRequestForReview(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	viewedFiles, err := models.GetPullViewedFiles(ctx.User.ID, pr.ID)
+	if err != nil {
+		ctx.Error(500, "GetPullViewedFiles", err)
+		return
+	}
+	diff, err := gitdiff.GetPullRequestDiff(pr, "")
+	if err != nil {
+		ctx.Error(500, "GetPullRequestDiff", err)
+		return
+	}
+
+	apiFiles := make([]*api.PullViewedFile, 0, len(viewedFiles))
+	for _, file := range diff.Files {
+		diffHash, ok := viewedFiles[file.Name]
+		if !ok {
+			continue
+		}
+		apiFiles = append(apiFiles, &api.PullViewedFile{
+			Path:     file.Name,
+			Outdated: diffHash != file.GetDiffHash(),
+		})
+	}
+	ctx.JSON(200, apiFiles)
+}
+
+// MarkPullFileViewed marks a changed file of a pull request as viewed or not viewed by the user
+func MarkPullFileViewed(ctx *context.APIContext, opts api.MarkPullFileViewedOption) {
+	// swagger:operation PUT /repos/{owner}/{repo}/pulls/{index}/files/viewed repository repoMarkPullFileViewed
+	// ---
+	// summary: Mark a changed file of a pull request as viewed or not viewed by the authenticated user
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/MarkPullFileViewedOption"
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	pr := getPullRequestForReview(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	diffHash := ""
+	if opts.Viewed {
+		diff, err := gitdiff.GetPullRequestDiff(pr, "")
+		if err != nil {
+			ctx.Error(500, "GetPullRequestDiff", err)
+			return
+		}
+		for _, file := range diff.Files {
+			if file.Name == opts.Path {
+				diffHash = file.GetDiffHash()
+				break
+			}
+		}
+		if len(diffHash) == 0 {
+			ctx.Error(http.StatusUnprocessableEntity, "", "The file is not changed by the pull request")
+			return
+		}
+	}
+
+	if err := models.SetPullFileViewed(ctx.User.ID, pr.ID, opts.Path, diffHash, opts.Viewed); err != nil {
+		ctx.Error(500, "SetPullFileViewed", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListPullSuggestedReviewers lists the users who last changed the lines modified by a pull request
+func ListPullSuggestedReviewers(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/suggested_reviewers repository repoListPullSuggestedReviewers
+	// ---
+	// summary: List the users who last changed the lines modified by a pull request according to git blame
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: limit
+	//   in: query
+	//   description: maximum number of suggested reviewers, defaults to 5
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/SuggestedReviewerList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	pr := getPullRequestForReview(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	limit := ctx.QueryInt("limit")
+	if limit <= 0 {
+		limit = models.DefaultSuggestedReviewersLimit
+	}
+	reviewers, err := pr.GetSuggestedReviewers(limit)
+	if err != nil {
+		ctx.Error(500, "GetSuggestedReviewers", err)
+		return
+	}
+
+	apiReviewers := make([]*api.SuggestedReviewer, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		apiReviewers = append(apiReviewers, &api.SuggestedReviewer{
+			User:  reviewer.User.APIFormat(),
+			Lines: reviewer.Lines,
+		})
+	}
+	ctx.JSON(200, &apiReviewers)
+}
+
+func getPullRequestForReview(ctx *context.APIContext) *models.PullRequest {
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetPullRequestByIndex", err)
+		}
+		return nil
+	}
+	if err = pr.LoadIssue(); err != nil {
+		ctx.Error(500, "LoadIssue", err)
+		return nil
+	}
+	pr.Issue.Repo = ctx.Repo.Repository
+	return pr
+}
+
+// getPullReview returns the pull request and the review of the id parameter,
+// pending reviews of other users are not found.
+func getPullReview(ctx *context.APIContext) (*models.PullRequest, *models.Review) {
+	pr := getPullRequestForReview(ctx)
+	if ctx.Written() {
+		return nil, nil
+	}
+
+	review, err := models.GetReviewByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrReviewNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetReviewByID", err)
+		}
+		return nil, nil
+	}
+	if review.IssueID != pr.IssueID ||
+		(review.Type == models.ReviewTypePending && (ctx.User == nil || review.ReviewerID != ctx.User.ID)) {
+		ctx.NotFound()
+		return nil, nil
+	}
+	return pr, review
+}
+
+// preparePullReviewType returns the review type of an event, the doer can
+// not approve or request changes on their own pull request.
+func preparePullReviewType(ctx *context.APIContext, issue *models.Issue, event api.ReviewStateType) models.ReviewType {
+	reviewType := models.ReviewTypeFromAPIState(event)
+	switch reviewType {
+	case models.ReviewTypeUnknown:
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("unknown review event: %s", event))
+	case models.ReviewTypeApprove, models.ReviewTypeReject:
+		if issue.PosterID == ctx.User.ID {
+			ctx.Error(http.StatusUnprocessableEntity, "", "approve or request changes on your own pull request is not allowed")
+		}
+	}
+	return reviewType
+}
+
+// submitPullReview submits the pending review of the doer or creates a new one
+func submitPullReview(ctx *context.APIContext, pr *models.PullRequest, reviewType models.ReviewType, body string) *models.Review {
+	review, comment, err := pull.SubmitReview(ctx.User, pr.Issue, reviewType, body)
+	if err != nil {
+		if models.IsErrReviewEmpty(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err.Error())
+		} else {
+			ctx.Error(500, "SubmitReview", err)
+		}
+		return nil
+	}
+	notification.NotifyPullRequestReview(pr, review, comment)
+	return review
+}
+
+func reviewAPIFormat(review *models.Review, issue *models.Issue) (*api.PullReview, error) {
+	review.Issue = issue
+	if err := review.LoadReviewer(); err != nil && !models.IsErrUserNotExist(err) {
+		return nil, err
+	}
+	return review.APIFormat()
+}
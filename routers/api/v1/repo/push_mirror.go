@@ -0,0 +1,319 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ListPushMirrors lists the push mirrors of a repository
+func ListPushMirrors(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/push_mirrors repository repoListPushMirrors
+	// ---
+	// summary: List the push mirrors of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PushMirrorList"
+	mirrors, err := models.GetPushMirrorsByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Error(500, "GetPushMirrorsByRepoID", err)
+		return
+	}
+	apiMirrors := make([]*api.PushMirror, 0, len(mirrors))
+	for _, m := range mirrors {
+		apiMirrors = append(apiMirrors, m.APIFormat())
+	}
+	ctx.JSON(200, &apiMirrors)
+}
+
+// GetPushMirror gets a push mirror of a repository
+func GetPushMirror(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/push_mirrors/{id} repository repoGetPushMirror
+	// ---
+	// summary: Get a push mirror of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the push mirror
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PushMirror"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	m := getPushMirror(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(200, m.APIFormat())
+}
+
+// AddPushMirror adds a push mirror to a repository
+func AddPushMirror(ctx *context.APIContext, form api.CreatePushMirrorOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/push_mirrors repository repoAddPushMirror
+	// ---
+	// summary: Add a push mirror to a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreatePushMirrorOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/PushMirror"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	interval := setting.Mirror.DefaultInterval
+	if len(form.Interval) > 0 {
+		var err error
+		interval, err = time.ParseDuration(form.Interval)
+		if err != nil || (interval != 0 && interval < setting.Mirror.MinInterval) {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("invalid interval, it must be 0 or at least %s", setting.Mirror.MinInterval))
+			return
+		}
+	}
+
+	hasSSHKey := true
+	if _, err := models.GetMirrorSSHKey(ctx.Repo.Repository.ID); err != nil {
+		if !models.IsErrMirrorSSHKeyNotExist(err) {
+			ctx.Error(500, "GetMirrorSSHKey", err)
+			return
+		}
+		hasSSHKey = false
+	}
+
+	address, err := auth.ParsePushMirrorAddress(form.RemoteAddress, form.RemoteUsername, form.RemotePassword, hasSSHKey)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", "Invalid remote address, only http(s):// or git:// locations, or ssh:// ones if the repository has a mirror SSH key, can be pushed to.")
+		return
+	}
+
+	m := &models.PushMirror{
+		RepoID:       ctx.Repo.Repository.ID,
+		Interval:     interval,
+		SyncOnCommit: form.SyncOnCommit,
+	}
+	if err = models.InsertPushMirror(m, address); err != nil {
+		ctx.Error(500, "InsertPushMirror", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, m.APIFormat())
+}
+
+// DeletePushMirror removes a push mirror from a repository
+func DeletePushMirror(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/push_mirrors/{id} repository repoDeletePushMirror
+	// ---
+	// summary: Remove a push mirror from a repository
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the push mirror
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	m := getPushMirror(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := models.DeletePushMirror(m); err != nil {
+		ctx.Error(500, "DeletePushMirror", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// PushMirrorSync pushes a repository to all its push mirrors
+func PushMirrorSync(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/push_mirrors-sync repository repoPushMirrorSync
+	// ---
+	// summary: Push a repository to all its push mirrors
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/empty"
+	go models.AddPushMirrorsToQueue(ctx.Repo.Repository.ID, false)
+	ctx.Status(200)
+}
+
+func getPushMirror(ctx *context.APIContext) *models.PushMirror {
+	m, err := models.GetPushMirrorByID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrPushMirrorNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetPushMirrorByID", err)
+		}
+		return nil
+	}
+	return m
+}
+
+// GetMirrorSSHKey gets the SSH key the mirrors of a repository authenticate with
+func GetMirrorSSHKey(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/mirror_ssh_key repository repoGetMirrorSSHKey
+	// ---
+	// summary: Get the public SSH key the mirrors of a repository authenticate with to the remotes with an ssh:// address
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/MirrorSSHKey"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	k, err := models.GetMirrorSSHKey(ctx.Repo.Repository.ID)
+	if err != nil {
+		if models.IsErrMirrorSSHKeyNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetMirrorSSHKey", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, k.APIFormat())
+}
+
+// GenerateMirrorSSHKey generates the SSH key the mirrors of a repository
+// authenticate with
+func GenerateMirrorSSHKey(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/mirror_ssh_key repository repoGenerateMirrorSSHKey
+	// ---
+	// summary: Generate the SSH key the mirrors of a repository authenticate with, replacing the previous one
+	// description: The public key has to be added to the remotes, as a deploy key for example. The private key never leaves the server.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/MirrorSSHKey"
+	k, err := models.GenerateMirrorSSHKey(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Error(500, "GenerateMirrorSSHKey", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, k.APIFormat())
+}
+
+// DeleteMirrorSSHKey deletes the SSH key the mirrors of a repository
+// authenticate with
+func DeleteMirrorSSHKey(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/mirror_ssh_key repository repoDeleteMirrorSSHKey
+	// ---
+	// summary: Delete the SSH key the mirrors of a repository authenticate with
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	if err := models.DeleteMirrorSSHKey(ctx.Repo.Repository.ID); err != nil {
+		ctx.Error(500, "DeleteMirrorSSHKey", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -145,6 +145,8 @@ func CreateRelease(ctx *context.APIContext, form api.CreateReleaseOption) {
 	// responses:
 	//   "201":
 	//     "$ref": "#/responses/Release"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
 	rel, err := models.GetRelease(ctx.Repo.Repository.ID, form.TagName)
 	if err != nil {
 		if !models.IsErrReleaseNotExist(err) {
@@ -171,6 +173,8 @@ func CreateRelease(ctx *context.APIContext, form api.CreateReleaseOption) {
 		if err := models.CreateRelease(ctx.Repo.GitRepo, rel, nil); err != nil {
 			if models.IsErrReleaseAlreadyExist(err) {
 				ctx.Status(409)
+			} else if models.IsErrProtectedTagName(err) {
+				ctx.Error(403, "CreateRelease", err)
 			} else {
 				ctx.Error(500, "CreateRelease", err)
 			}
@@ -232,6 +236,8 @@ func EditRelease(ctx *context.APIContext, form api.EditReleaseOption) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/Release"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
 	id := ctx.ParamsInt64(":id")
 	rel, err := models.GetReleaseByID(id)
 	if err != nil && !models.IsErrReleaseNotExist(err) {
@@ -263,7 +269,11 @@ func EditRelease(ctx *context.APIContext, form api.EditReleaseOption) {
 		rel.IsPrerelease = *form.IsPrerelease
 	}
 	if err := models.UpdateRelease(ctx.User, ctx.Repo.GitRepo, rel, nil); err != nil {
-		ctx.Error(500, "UpdateRelease", err)
+		if models.IsErrProtectedTagName(err) {
+			ctx.Error(403, "UpdateRelease", err)
+		} else {
+			ctx.Error(500, "UpdateRelease", err)
+		}
 		return
 	}
 
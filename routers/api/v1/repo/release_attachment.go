@@ -145,6 +145,8 @@ func CreateReleaseAttachment(ctx *context.APIContext) {
 	// responses:
 	//   "201":
 	//     "$ref": "#/responses/Attachment"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	// Check if attachments are enabled
 	if !setting.AttachmentEnabled {
@@ -168,6 +170,15 @@ func CreateReleaseAttachment(ctx *context.APIContext) {
 	}
 	defer file.Close()
 
+	if err = ctx.User.CheckAttachmentQuota(header.Size); err != nil {
+		if models.IsErrQuotaExceeded(err) {
+			ctx.Error(422, "", err)
+		} else {
+			ctx.Error(500, "CheckAttachmentQuota", err)
+		}
+		return
+	}
+
 	buf := make([]byte, 1024)
 	n, _ := file.Read(buf)
 	if n > 0 {
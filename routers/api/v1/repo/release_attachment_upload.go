@@ -0,0 +1,348 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/upload"
+)
+
+// getRepoRelease returns the release of the path of the repository, it
+// responds with an error if it does not exist
+func getRepoRelease(ctx *context.APIContext) *models.Release {
+	release, err := models.GetReleaseByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrReleaseNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetReleaseByID", err)
+		}
+		return nil
+	}
+	if release.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound()
+		return nil
+	}
+	return release
+}
+
+// getReleaseAttachmentUpload returns the upload of the path, it responds with
+// an error if it does not exist
+func getReleaseAttachmentUpload(ctx *context.APIContext) *models.AttachmentUpload {
+	release := getRepoRelease(ctx)
+	if ctx.Written() {
+		return nil
+	}
+	attachUpload, err := models.GetAttachmentUploadByUUID(ctx.Params(":uuid"))
+	if err != nil {
+		if models.IsErrAttachmentUploadNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetAttachmentUploadByUUID", err)
+		}
+		return nil
+	}
+	if attachUpload.ReleaseID != release.ID {
+		ctx.NotFound()
+		return nil
+	}
+	return attachUpload
+}
+
+// CreateReleaseAttachmentUpload starts the upload of a release attachment in
+// chunks
+func CreateReleaseAttachmentUpload(ctx *context.APIContext, form api.CreateAttachmentUploadOptions) {
+	// swagger:operation POST /repos/{owner}/{repo}/releases/{id}/assets/uploads repository repoCreateReleaseAttachmentUpload
+	// ---
+	// summary: Start the upload of a release attachment in chunks
+	// description: The chunks are uploaded in order, an interrupted upload is resumed from its offset.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the release
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateAttachmentUploadOptions"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/AttachmentUpload"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	if !setting.AttachmentEnabled {
+		ctx.NotFound("Attachment is not enabled")
+		return
+	}
+	release := getRepoRelease(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Size < 0 {
+		ctx.Error(http.StatusUnprocessableEntity, "", errors.New("the size must not be negative"))
+		return
+	}
+	if err := ctx.User.CheckAttachmentQuota(form.Size); err != nil {
+		if models.IsErrQuotaExceeded(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "CheckAttachmentQuota", err)
+		}
+		return
+	}
+
+	attachUpload := &models.AttachmentUpload{
+		ReleaseID:  release.ID,
+		UploaderID: ctx.User.ID,
+		Name:       form.Name,
+		Size:       form.Size,
+	}
+	if err := models.NewAttachmentUpload(attachUpload); err != nil {
+		ctx.Error(http.StatusInternalServerError, "NewAttachmentUpload", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, attachUpload.APIFormat())
+}
+
+// GetReleaseAttachmentUpload returns the state of the upload of a release
+// attachment
+func GetReleaseAttachmentUpload(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/releases/{id}/assets/uploads/{uuid} repository repoGetReleaseAttachmentUpload
+	// ---
+	// summary: Get the upload of a release attachment, its offset is the one to resume it from
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the release
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: uuid
+	//   in: path
+	//   description: uuid of the upload
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AttachmentUpload"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	attachUpload := getReleaseAttachmentUpload(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, attachUpload.APIFormat())
+}
+
+// UploadReleaseAttachmentChunk appends a chunk to the upload of a release
+// attachment, the attachment is created once it is complete
+func UploadReleaseAttachmentChunk(ctx *context.APIContext) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/releases/{id}/assets/uploads/{uuid} repository repoUploadReleaseAttachmentChunk
+	// ---
+	// summary: Upload the next chunk of a release attachment
+	// description: The attachment is created once all its content has been uploaded.
+	// consumes:
+	// - application/octet-stream
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the release
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: uuid
+	//   in: path
+	//   description: uuid of the upload
+	//   type: string
+	//   required: true
+	// - name: offset
+	//   in: query
+	//   description: offset of the chunk in the attachment, it must be the offset of the upload
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   description: content of the chunk
+	//   schema:
+	//     type: string
+	//     format: binary
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AttachmentUpload"
+	//   "201":
+	//     "$ref": "#/responses/Attachment"
+	//   "400":
+	//     "$ref": "#/responses/error"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "413":
+	//     "$ref": "#/responses/error"
+	attachUpload := getReleaseAttachmentUpload(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	// The quota of the uploader is checked again for the whole attachment,
+	// its other attachments may have been uploaded meanwhile
+	uploader, err := models.GetUserByID(attachUpload.UploaderID)
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetUserByID", err)
+		}
+		return
+	}
+	if err = uploader.CheckAttachmentQuota(attachUpload.Size); err != nil {
+		if models.IsErrQuotaExceeded(err) {
+			ctx.Error(http.StatusRequestEntityTooLarge, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "CheckAttachmentQuota", err)
+		}
+		return
+	}
+
+	var body io.Reader = ctx.Req.Request.Body
+	if ctx.QueryInt64("offset") == 0 && attachUpload.Size > 0 {
+		// Check if the filetype is allowed by the settings
+		buf := make([]byte, 1024)
+		n, _ := io.ReadFull(body, buf)
+		buf = buf[:n]
+		if err := upload.VerifyAllowedContentType(buf, strings.Split(setting.AttachmentAllowedTypes, ",")); err != nil {
+			ctx.Error(http.StatusBadRequest, "DetectContentType", err)
+			return
+		}
+		body = io.MultiReader(bytes.NewReader(buf), body)
+	}
+
+	if err = attachUpload.WriteChunk(ctx.QueryInt64("offset"), body); err != nil {
+		switch {
+		case models.IsErrAttachmentUploadNotExist(err):
+			ctx.NotFound()
+		case models.IsErrAttachmentUploadOffset(err):
+			ctx.Error(http.StatusConflict, "", err)
+		case models.IsErrAttachmentUploadTooLarge(err):
+			ctx.Error(http.StatusRequestEntityTooLarge, "", err)
+		default:
+			ctx.Error(http.StatusInternalServerError, "WriteChunk", err)
+		}
+		return
+	}
+	if !attachUpload.IsComplete() {
+		ctx.JSON(http.StatusOK, attachUpload.APIFormat())
+		return
+	}
+
+	attach, err := attachUpload.Complete()
+	if err != nil {
+		if models.IsErrAttachmentUploadNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "Complete", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, attach.APIFormat())
+}
+
+// DeleteReleaseAttachmentUpload cancels the upload of a release attachment
+func DeleteReleaseAttachmentUpload(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/releases/{id}/assets/uploads/{uuid} repository repoDeleteReleaseAttachmentUpload
+	// ---
+	// summary: Cancel the upload of a release attachment
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the release
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: uuid
+	//   in: path
+	//   description: uuid of the upload
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	attachUpload := getReleaseAttachmentUpload(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := models.DeleteAttachmentUpload(attachUpload); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteAttachmentUpload", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
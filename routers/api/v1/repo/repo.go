@@ -8,17 +8,21 @@ package repo
 import (
 	"fmt"
 	"net/http"
+	"regexp"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/auth"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/markup"
 	"code.gitea.io/gitea/modules/migrations"
 	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/validation"
 	"code.gitea.io/gitea/routers/api/v1/convert"
 )
 
@@ -200,17 +204,32 @@ func Search(ctx *context.APIContext) {
 
 // CreateUserRepo create a repository for a user
 func CreateUserRepo(ctx *context.APIContext, owner *models.User, opt api.CreateRepoOption) {
-	if opt.AutoInit && opt.Readme == "" {
+	defaults := models.GetRepoCreationDefaults(ctx.User, owner)
+	if opt.Private == nil {
+		opt.Private = &defaults.IsPrivate
+	}
+	if opt.AutoInit == nil {
+		opt.AutoInit = &defaults.AutoInit
+		if defaults.AutoInit && opt.Gitignores == "" && opt.License == "" {
+			opt.Gitignores = defaults.Gitignores
+			opt.License = defaults.License
+		}
+	}
+	if opt.DefaultBranch == "" {
+		opt.DefaultBranch = defaults.DefaultBranch
+	}
+	if *opt.AutoInit && opt.Readme == "" {
 		opt.Readme = "Default"
 	}
 	repo, err := models.CreateRepository(ctx.User, owner, models.CreateRepoOptions{
-		Name:        opt.Name,
-		Description: opt.Description,
-		Gitignores:  opt.Gitignores,
-		License:     opt.License,
-		Readme:      opt.Readme,
-		IsPrivate:   opt.Private,
-		AutoInit:    opt.AutoInit,
+		Name:          opt.Name,
+		Description:   opt.Description,
+		Gitignores:    opt.Gitignores,
+		License:       opt.License,
+		Readme:        opt.Readme,
+		IsPrivate:     *opt.Private,
+		AutoInit:      *opt.AutoInit,
+		DefaultBranch: opt.DefaultBranch,
 	})
 	if err != nil {
 		if models.IsErrRepoAlreadyExist(err) {
@@ -317,6 +336,114 @@ func CreateOrgRepo(ctx *context.APIContext, opt api.CreateRepoOption) {
 	CreateUserRepo(ctx, org, opt)
 }
 
+// Generate Create a repository using a template
+func Generate(ctx *context.APIContext, form api.GenerateRepoOption) {
+	// swagger:operation POST /repos/{template_owner}/{template_repo}/generate repository generateRepo
+	// ---
+	// summary: Create a repository using a template
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: template_owner
+	//   in: path
+	//   description: name of the template repository owner
+	//   type: string
+	//   required: true
+	// - name: template_repo
+	//   in: path
+	//   description: name of the template repository
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/GenerateRepoOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Repository"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     description: The repository with the same name already exists.
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	if !ctx.Repo.Repository.IsTemplate {
+		ctx.Error(http.StatusUnprocessableEntity, "", "this is not a template repo")
+		return
+	}
+
+	opts := models.GenerateRepoOptions{
+		Name:        form.Name,
+		Description: form.Description,
+		IsPrivate:   form.Private || setting.Repository.ForcePrivate,
+		GitContent:  form.GitContent,
+		Topics:      form.Topics,
+		Labels:      form.Labels,
+		Webhooks:    form.Webhooks,
+	}
+	if !opts.IsValid() {
+		ctx.Error(http.StatusUnprocessableEntity, "", "must select at least one template item")
+		return
+	}
+
+	ctxUser := ctx.User
+	if form.Owner != ctxUser.Name {
+		var err error
+		ctxUser, err = models.GetUserByName(form.Owner)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+			}
+			return
+		}
+
+		if !ctx.User.IsAdmin {
+			if !ctxUser.IsOrganization() {
+				ctx.Error(http.StatusForbidden, "", "Only admin can generate repository for other user.")
+				return
+			}
+			isOwner, err := ctxUser.IsOwnedBy(ctx.User.ID)
+			if err != nil {
+				ctx.Error(http.StatusInternalServerError, "IsOwnedBy", err)
+				return
+			} else if !isOwner {
+				ctx.Error(http.StatusForbidden, "", "Given user is not owner of organization.")
+				return
+			}
+		}
+	}
+
+	repo, err := models.GenerateRepository(ctx.User, ctxUser, ctx.Repo.Repository, opts)
+	if err != nil {
+		if models.IsErrRepoAlreadyExist(err) {
+			ctx.Error(http.StatusConflict, "", "The repository with the same name already exists.")
+		} else if models.IsErrNameReserved(err) ||
+			models.IsErrNamePatternNotAllowed(err) ||
+			models.IsErrReachLimitOfRepo(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			if repo != nil {
+				if err = models.DeleteRepository(ctx.User, ctxUser.ID, repo.ID); err != nil {
+					log.Error("DeleteRepository: %v", err)
+				}
+			}
+			ctx.Error(http.StatusInternalServerError, "GenerateRepository", err)
+		}
+		return
+	}
+	log.Trace("Repository generated [%d]: %s/%s", repo.ID, ctxUser.Name, repo.Name)
+
+	notification.NotifyCreateRepository(ctx.User, ctxUser, repo)
+
+	ctx.JSON(http.StatusCreated, repo.APIFormat(models.AccessModeOwner))
+}
+
 // Migrate migrate remote git repository to gitea
 func Migrate(ctx *context.APIContext, form auth.MigrateRepoForm) {
 	// swagger:operation POST /repos/migrate repository repoMigrate
@@ -562,6 +689,12 @@ func Edit(ctx *context.APIContext, opts api.EditRepoOption) {
 		}
 	}
 
+	if opts.MirrorInterval != nil {
+		if err := updateMirrorInterval(ctx, opts); err != nil {
+			return
+		}
+	}
+
 	ctx.JSON(http.StatusOK, ctx.Repo.Repository.APIFormat(ctx.Repo.AccessMode))
 }
 
@@ -635,6 +768,10 @@ func updateBasicProperties(ctx *context.APIContext, opts api.EditRepoOption) err
 		repo.IsPrivate = *opts.Private
 	}
 
+	if opts.Template != nil {
+		repo.IsTemplate = *opts.Template
+	}
+
 	if err := models.UpdateRepository(repo, visibilityChanged); err != nil {
 		ctx.Error(http.StatusInternalServerError, "UpdateRepository", err)
 		return err
@@ -667,27 +804,60 @@ func updateRepoUnits(ctx *context.APIContext, opts api.EditRepoOption) error {
 			units = append(units, *unit)
 		}
 	} else if *opts.HasIssues {
-		// We don't currently allow setting individual issue settings through the API,
-		// only can enable/disable issues, so when enabling issues,
-		// we either get the existing config which means it was already enabled,
-		// or create a new config since it doesn't exist.
-		unit, err := repo.GetUnit(models.UnitTypeIssues)
-		var config *models.IssuesConfig
-		if err != nil {
-			// Unit type doesn't exist so we make a new config file with default values
-			config = &models.IssuesConfig{
-				EnableTimetracker:                true,
-				AllowOnlyContributorsToTrackTime: true,
-				EnableDependencies:               true,
+		if opts.ExternalTracker != nil {
+			if !validation.IsValidExternalURL(opts.ExternalTracker.ExternalTrackerURL) {
+				err := fmt.Errorf("invalid external tracker URL: %s", opts.ExternalTracker.ExternalTrackerURL)
+				ctx.Error(http.StatusUnprocessableEntity, "ExternalTrackerURL", err)
+				return err
+			}
+			if len(opts.ExternalTracker.ExternalTrackerFormat) != 0 && !validation.IsValidExternalTrackerURLFormat(opts.ExternalTracker.ExternalTrackerFormat) {
+				err := fmt.Errorf("invalid external tracker URL format: %s", opts.ExternalTracker.ExternalTrackerFormat)
+				ctx.Error(http.StatusUnprocessableEntity, "ExternalTrackerFormat", err)
+				return err
+			}
+			style := opts.ExternalTracker.ExternalTrackerStyle
+			if len(style) == 0 {
+				style = markup.IssueNameStyleNumeric
+			} else if style != markup.IssueNameStyleNumeric && style != markup.IssueNameStyleAlphanumeric {
+				err := fmt.Errorf("invalid external tracker style: %s", style)
+				ctx.Error(http.StatusUnprocessableEntity, "ExternalTrackerStyle", err)
+				return err
 			}
+			units = append(units, models.RepoUnit{
+				RepoID: repo.ID,
+				Type:   models.UnitTypeExternalTracker,
+				Config: &models.ExternalTrackerConfig{
+					ExternalTrackerURL:    opts.ExternalTracker.ExternalTrackerURL,
+					ExternalTrackerFormat: opts.ExternalTracker.ExternalTrackerFormat,
+					ExternalTrackerStyle:  style,
+				},
+			})
 		} else {
-			config = unit.IssuesConfig()
+			// Without an external tracker the built-in one is enabled, keeping
+			// its existing config unless new settings were provided.
+			var config *models.IssuesConfig
+			if opts.InternalTracker != nil {
+				config = &models.IssuesConfig{
+					EnableTimetracker:                opts.InternalTracker.EnableTimeTracker,
+					AllowOnlyContributorsToTrackTime: opts.InternalTracker.AllowOnlyContributorsToTrackTime,
+					EnableDependencies:               opts.InternalTracker.EnableIssueDependencies,
+				}
+			} else if unit, err := repo.GetUnit(models.UnitTypeIssues); err == nil {
+				config = unit.IssuesConfig()
+			} else {
+				// Unit type doesn't exist so we make a new config file with default values
+				config = &models.IssuesConfig{
+					EnableTimetracker:                true,
+					AllowOnlyContributorsToTrackTime: true,
+					EnableDependencies:               true,
+				}
+			}
+			units = append(units, models.RepoUnit{
+				RepoID: repo.ID,
+				Type:   models.UnitTypeIssues,
+				Config: config,
+			})
 		}
-		units = append(units, models.RepoUnit{
-			RepoID: repo.ID,
-			Type:   models.UnitTypeIssues,
-			Config: config,
-		})
 	}
 
 	if opts.HasWiki == nil {
@@ -698,16 +868,26 @@ func updateRepoUnits(ctx *context.APIContext, opts api.EditRepoOption) error {
 			units = append(units, *unit)
 		}
 	} else if *opts.HasWiki {
-		// We don't currently allow setting individual wiki settings through the API,
-		// only can enable/disable the wiki, so when enabling the wiki,
-		// we either get the existing config which means it was already enabled,
-		// or create a new config since it doesn't exist.
-		config := &models.UnitConfig{}
-		units = append(units, models.RepoUnit{
-			RepoID: repo.ID,
-			Type:   models.UnitTypeWiki,
-			Config: config,
-		})
+		if opts.ExternalWiki != nil {
+			if !validation.IsValidExternalURL(opts.ExternalWiki.ExternalWikiURL) {
+				err := fmt.Errorf("invalid external wiki URL: %s", opts.ExternalWiki.ExternalWikiURL)
+				ctx.Error(http.StatusUnprocessableEntity, "ExternalWikiURL", err)
+				return err
+			}
+			units = append(units, models.RepoUnit{
+				RepoID: repo.ID,
+				Type:   models.UnitTypeExternalWiki,
+				Config: &models.ExternalWikiConfig{
+					ExternalWikiURL: opts.ExternalWiki.ExternalWikiURL,
+				},
+			})
+		} else {
+			units = append(units, models.RepoUnit{
+				RepoID: repo.ID,
+				Type:   models.UnitTypeWiki,
+				Config: new(models.UnitConfig),
+			})
+		}
 	}
 
 	if opts.HasPullRequests == nil {
@@ -749,6 +929,73 @@ func updateRepoUnits(ctx *context.APIContext, opts api.EditRepoOption) error {
 		if opts.AllowSquash != nil {
 			config.AllowSquash = *opts.AllowSquash
 		}
+		if opts.AllowFastForwardOnly != nil {
+			config.AllowFastForwardOnly = *opts.AllowFastForwardOnly
+		}
+		if opts.CancelAutoMergeOnPush != nil {
+			config.CancelAutoMergeOnPush = *opts.CancelAutoMergeOnPush
+		}
+		if opts.DefaultMergeMessageTemplate != nil {
+			config.DefaultMergeMessageTemplate = strings.TrimSpace(*opts.DefaultMergeMessageTemplate)
+		}
+		if opts.DefaultSquashMessageTemplate != nil {
+			config.DefaultSquashMessageTemplate = strings.TrimSpace(*opts.DefaultSquashMessageTemplate)
+		}
+		if opts.MaxChangedFiles != nil {
+			config.MaxChangedFiles = *opts.MaxChangedFiles
+		}
+		if opts.MaxChangedLines != nil {
+			config.MaxChangedLines = *opts.MaxChangedLines
+		}
+		if opts.BlockOversizedMerge != nil {
+			config.BlockOversizedMerge = *opts.BlockOversizedMerge
+		}
+		if opts.CommitMessageSubjectPattern != nil {
+			pattern := strings.TrimSpace(*opts.CommitMessageSubjectPattern)
+			if _, err := regexp.Compile(pattern); err != nil {
+				ctx.Error(http.StatusUnprocessableEntity, "CommitMessageSubjectPattern", err)
+				return err
+			}
+			config.CommitMessageSubjectPattern = pattern
+		}
+		if opts.CommitMessageMaxLineLength != nil {
+			config.CommitMessageMaxLineLength = *opts.CommitMessageMaxLineLength
+		}
+		if opts.CommitMessageRequireIssueRef != nil {
+			config.CommitMessageRequireIssueRef = *opts.CommitMessageRequireIssueRef
+		}
+		if opts.ReviewerTeamID != nil {
+			if *opts.ReviewerTeamID > 0 {
+				team, err := models.GetTeamByID(*opts.ReviewerTeamID)
+				if err == nil && team.OrgID != repo.OwnerID {
+					err = models.ErrTeamNotExist
+				}
+				if err != nil {
+					if err == models.ErrTeamNotExist {
+						ctx.Error(http.StatusUnprocessableEntity, "ReviewerTeamID", err)
+					} else {
+						ctx.Error(http.StatusInternalServerError, "GetTeamByID", err)
+					}
+					return err
+				}
+			}
+			config.ReviewerTeamID = *opts.ReviewerTeamID
+		}
+		if opts.ReviewerAssignmentStrategy != nil {
+			strategy := models.ReviewerAssignmentStrategy(*opts.ReviewerAssignmentStrategy)
+			if !strategy.IsValid() {
+				err := fmt.Errorf("invalid reviewer assignment strategy: %s", strategy)
+				ctx.Error(http.StatusUnprocessableEntity, "ReviewerAssignmentStrategy", err)
+				return err
+			}
+			config.ReviewerAssignmentStrategy = strategy
+		}
+		if opts.ReviewerCount != nil {
+			config.ReviewerCount = *opts.ReviewerCount
+		}
+		if opts.ReviewerAssignmentSkipDrafts != nil {
+			config.ReviewerAssignmentSkipDrafts = *opts.ReviewerAssignmentSkipDrafts
+		}
 
 		units = append(units, models.RepoUnit{
 			RepoID: repo.ID,
@@ -761,6 +1008,8 @@ func updateRepoUnits(ctx *context.APIContext, opts api.EditRepoOption) error {
 		ctx.Error(http.StatusInternalServerError, "UpdateRepositoryUnits", err)
 		return err
 	}
+	// The units loaded with the repository are outdated, the response reloads them
+	repo.Units = nil
 
 	log.Trace("Repository advanced settings updated: %s/%s", owner.Name, repo.Name)
 	return nil
@@ -795,6 +1044,37 @@ func updateRepoArchivedState(ctx *context.APIContext, opts api.EditRepoOption) e
 	return nil
 }
 
+// updateMirrorInterval updates the interval between two syncs of a mirror
+func updateMirrorInterval(ctx *context.APIContext, opts api.EditRepoOption) error {
+	repo := ctx.Repo.Repository
+	if !repo.IsMirror {
+		err := fmt.Errorf("repo is not a mirror, cannot change the mirror interval")
+		ctx.Error(http.StatusUnprocessableEntity, err.Error(), err)
+		return err
+	}
+
+	interval, err := time.ParseDuration(*opts.MirrorInterval)
+	if err != nil || (interval != 0 && interval < setting.Mirror.MinInterval) {
+		err = fmt.Errorf("invalid mirror interval, it must be 0 or at least %s", setting.Mirror.MinInterval)
+		ctx.Error(http.StatusUnprocessableEntity, err.Error(), err)
+		return err
+	}
+
+	mirror, err := models.GetMirrorByRepoID(repo.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetMirrorByRepoID", err)
+		return err
+	}
+	mirror.Interval = interval
+	mirror.ScheduleNextUpdate()
+	if err = models.UpdateMirror(mirror); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateMirror", err)
+		return err
+	}
+	log.Trace("Repository mirror interval updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+	return nil
+}
+
 // Delete one repository
 func Delete(ctx *context.APIContext) {
 	// swagger:operation DELETE /repos/{owner}/{repo} repository repoDelete
@@ -862,10 +1142,19 @@ func MirrorSync(ctx *context.APIContext) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 	repo := ctx.Repo.Repository
 
 	if !ctx.Repo.CanWrite(models.UnitTypeCode) {
 		ctx.Error(403, "MirrorSync", "Must have write access")
+		return
+	}
+	if !repo.IsMirror {
+		ctx.Error(http.StatusUnprocessableEntity, "", "Repository is not a mirror")
+		return
 	}
 
 	go models.MirrorQueue.Add(repo.ID)
@@ -0,0 +1,93 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/repofiles"
+)
+
+// GetFileSnippet returns lines of a file with their permanent link
+func GetFileSnippet(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/snippets/{filepath} repository repoGetFileSnippet
+	// ---
+	// summary: Get lines of a file with their permanent link, resolving the ref to a commit
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: filepath
+	//   in: path
+	//   description: path of the file
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: query
+	//   description: "The name of the commit/branch/tag. Default the repository’s default branch (usually master)"
+	//   type: string
+	//   required: false
+	// - name: lines
+	//   in: query
+	//   description: the lines to get, as in the anchor of the view of the file, e.g. L10-L20 or L10
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/FileSnippet"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	if ctx.Repo.Repository.IsEmpty {
+		ctx.NotFound()
+		return
+	}
+
+	lines, err := repofiles.ParseLineRange(ctx.QueryTrim("lines"))
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "ParseLineRange", err)
+		return
+	}
+
+	ref := ctx.QueryTrim("ref")
+	if len(ref) == 0 {
+		ref = ctx.Repo.Repository.DefaultBranch
+	}
+	commit, err := ctx.Repo.GitRepo.GetCommit(ref)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCommit", err)
+		}
+		return
+	}
+
+	snippet, err := repofiles.GetFileSnippet(ctx.Repo.Repository, commit, ctx.Params("*"), lines)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else if repofiles.IsErrInvalidSnippet(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "GetFileSnippet", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetFileSnippet", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, snippet)
+}
@@ -0,0 +1,140 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/convert"
+)
+
+// GetContributorStats returns the statistics of the contributors of the default
+// branch of a repository
+func GetContributorStats(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/stats/contributors repository repoGetContributorStats
+	// ---
+	// summary: Get the weekly statistics of the contributors of the default branch of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ContributorStatsList"
+	stats, err := ctx.Repo.Repository.GetContributorStats()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetContributorStats", err)
+		return
+	}
+
+	apiStats := make([]*api.ContributorStats, len(stats))
+	for i, contributor := range stats {
+		apiStats[i] = &api.ContributorStats{
+			Name:      contributor.Name,
+			Email:     contributor.Email,
+			Commits:   contributor.Commits,
+			Additions: contributor.Additions,
+			Deletions: contributor.Deletions,
+			Weeks:     make([]*api.WeeklyStats, len(contributor.Weeks)),
+		}
+		if contributor.User != nil {
+			apiStats[i].Author = convert.ToUser(contributor.User, ctx.IsSigned, ctx.User != nil && ctx.User.IsAdmin)
+		}
+		for j, week := range contributor.Weeks {
+			apiStats[i].Weeks[j] = &api.WeeklyStats{
+				Week:      week.Week.AsTime().UTC(),
+				Commits:   week.Commits,
+				Additions: week.Additions,
+				Deletions: week.Deletions,
+			}
+		}
+	}
+	ctx.JSON(http.StatusOK, apiStats)
+}
+
+// commitActivityWeeks is the number of weeks of the commit activity
+const commitActivityWeeks = 52
+
+// GetCommitActivity returns the number of commits per week of the default
+// branch of a repository during the last year
+func GetCommitActivity(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/stats/commit_activity repository repoGetCommitActivity
+	// ---
+	// summary: Get the weekly commit activity of the default branch of a repository during the last year
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WeeklyStatsList"
+	getWeeklyStats(ctx, commitActivityWeeks)
+}
+
+// GetCodeFrequency returns the number of lines added and deleted per week in
+// the default branch of a repository
+func GetCodeFrequency(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/stats/code_frequency repository repoGetCodeFrequency
+	// ---
+	// summary: Get the weekly additions and deletions of the default branch of a repository since its first commit
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WeeklyStatsList"
+	getWeeklyStats(ctx, 0)
+}
+
+// getWeeklyStats responds with the weekly statistics of the default branch of
+// the repository during the last weeks, all of them if weeks is not positive
+func getWeeklyStats(ctx *context.APIContext, weeks int) {
+	stats, err := ctx.Repo.Repository.GetWeeklyStats(weeks)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetWeeklyStats", err)
+		return
+	}
+
+	apiStats := make([]*api.WeeklyStats, len(stats))
+	for i, week := range stats {
+		apiStats[i] = &api.WeeklyStats{
+			Week:      week.Week.AsTime().UTC(),
+			Commits:   week.Commits,
+			Additions: week.Additions,
+			Deletions: week.Deletions,
+		}
+	}
+	ctx.JSON(http.StatusOK, apiStats)
+}
@@ -0,0 +1,320 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ListTagProtections lists the tag protections of a repository
+func ListTagProtections(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/tag_protections repository repoListTagProtections
+	// ---
+	// summary: List the tag protections of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/TagProtectionList"
+	protectedTags, err := models.GetProtectedTags(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Error(500, "GetProtectedTags", err)
+		return
+	}
+	apiTags := make([]*api.TagProtection, 0, len(protectedTags))
+	for _, pt := range protectedTags {
+		apiTag, err := toTagProtection(pt)
+		if err != nil {
+			ctx.Error(500, "toTagProtection", err)
+			return
+		}
+		apiTags = append(apiTags, apiTag)
+	}
+	ctx.JSON(200, &apiTags)
+}
+
+// GetTagProtection gets a tag protection of a repository
+func GetTagProtection(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/tag_protections/{id} repository repoGetTagProtection
+	// ---
+	// summary: Get a tag protection of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the tag protection
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/TagProtection"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	pt := getTagProtection(ctx)
+	if ctx.Written() {
+		return
+	}
+	writeTagProtection(ctx, 200, pt)
+}
+
+// CreateTagProtection protects the tags of a repository matching a pattern
+func CreateTagProtection(ctx *context.APIContext, form api.CreateTagProtectionOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/tag_protections repository repoCreateTagProtection
+	// ---
+	// summary: Protect the tags of a repository matching a pattern
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateTagProtectionOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/TagProtection"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	pt := &models.ProtectedTag{NamePattern: form.NamePattern}
+	updateTagProtection(ctx, http.StatusCreated, pt, form.WhitelistUsernames, form.WhitelistTeams)
+}
+
+// EditTagProtection edits a tag protection of a repository
+func EditTagProtection(ctx *context.APIContext, form api.EditTagProtectionOption) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/tag_protections/{id} repository repoEditTagProtection
+	// ---
+	// summary: Edit a tag protection of a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the tag protection
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditTagProtectionOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/TagProtection"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	pt := getTagProtection(ctx)
+	if ctx.Written() {
+		return
+	}
+	if form.NamePattern != nil {
+		pt.NamePattern = *form.NamePattern
+	}
+
+	current, err := toTagProtection(pt)
+	if err != nil {
+		ctx.Error(500, "toTagProtection", err)
+		return
+	}
+	if form.WhitelistUsernames == nil {
+		form.WhitelistUsernames = current.WhitelistUsernames
+	}
+	if form.WhitelistTeams == nil {
+		form.WhitelistTeams = current.WhitelistTeams
+	}
+	updateTagProtection(ctx, 200, pt, form.WhitelistUsernames, form.WhitelistTeams)
+}
+
+// DeleteTagProtection removes a tag protection from a repository
+func DeleteTagProtection(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/tag_protections/{id} repository repoDeleteTagProtection
+	// ---
+	// summary: Remove a tag protection from a repository
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the tag protection
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	pt := getTagProtection(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := models.DeleteProtectedTag(pt); err != nil {
+		ctx.Error(500, "DeleteProtectedTag", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func getTagProtection(ctx *context.APIContext) *models.ProtectedTag {
+	pt, err := models.GetProtectedTagByID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrProtectedTagNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(500, "GetProtectedTagByID", err)
+		}
+		return nil
+	}
+	return pt
+}
+
+// updateTagProtection resolves the whitelisted users and teams, saves the tag
+// protection and writes it with the status.
+func updateTagProtection(ctx *context.APIContext, status int, pt *models.ProtectedTag, usernames, teamNames []string) {
+	userIDs := make([]int64, 0, len(usernames))
+	for _, name := range usernames {
+		u, err := models.GetUserByName(name)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("user %s does not exist", name))
+			} else {
+				ctx.Error(500, "GetUserByName", err)
+			}
+			return
+		}
+		userIDs = append(userIDs, u.ID)
+	}
+
+	teamIDs := make([]int64, 0, len(teamNames))
+	if len(teamNames) > 0 && !ctx.Repo.Owner.IsOrganization() {
+		ctx.Error(http.StatusUnprocessableEntity, "", "Teams can only be whitelisted in the repositories of organizations.")
+		return
+	}
+	for _, name := range teamNames {
+		team, err := models.GetTeam(ctx.Repo.Owner.ID, name)
+		if err != nil {
+			if err == models.ErrTeamNotExist {
+				ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("team %s does not exist", name))
+			} else {
+				ctx.Error(500, "GetTeam", err)
+			}
+			return
+		}
+		teamIDs = append(teamIDs, team.ID)
+	}
+
+	if err := models.UpdateProtectedTag(ctx.Repo.Repository, pt, userIDs, teamIDs); err != nil {
+		if models.IsErrInvalidTagPattern(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(500, "UpdateProtectedTag", err)
+		}
+		return
+	}
+	writeTagProtection(ctx, status, pt)
+}
+
+func writeTagProtection(ctx *context.APIContext, status int, pt *models.ProtectedTag) {
+	apiTag, err := toTagProtection(pt)
+	if err != nil {
+		ctx.Error(500, "toTagProtection", err)
+		return
+	}
+	ctx.JSON(status, apiTag)
+}
+
+// toTagProtection converts the protected tag to its API format with the names
+// of the whitelisted users and teams
+func toTagProtection(pt *models.ProtectedTag) (*api.TagProtection, error) {
+	users, err := models.GetUsersByIDs(pt.WhitelistUserIDs)
+	if err != nil {
+		return nil, fmt.Errorf("GetUsersByIDs: %v", err)
+	}
+	usernames := make([]string, 0, len(users))
+	for _, u := range users {
+		usernames = append(usernames, u.Name)
+	}
+
+	teamNames := make([]string, 0, len(pt.WhitelistTeamIDs))
+	for _, id := range pt.WhitelistTeamIDs {
+		team, err := models.GetTeamByID(id)
+		if err != nil {
+			if err == models.ErrTeamNotExist {
+				continue
+			}
+			return nil, fmt.Errorf("GetTeamByID: %v", err)
+		}
+		teamNames = append(teamNames, team.Name)
+	}
+
+	return &api.TagProtection{
+		ID:                 pt.ID,
+		NamePattern:        pt.NamePattern,
+		WhitelistUsernames: usernames,
+		WhitelistTeams:     teamNames,
+		Created:            pt.CreatedUnix.AsTime(),
+		Updated:            pt.UpdatedUnix.AsTime(),
+	}, nil
+}
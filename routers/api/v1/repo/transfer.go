@@ -0,0 +1,241 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// Transfer transfers the ownership of a repository
+func Transfer(ctx *context.APIContext, opts api.TransferRepoOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/transfer repository repoTransfer
+	// ---
+	// summary: Transfer a repository's ownership
+	// description: The transfer is pending until the new owner accepts it, unless the user could accept it.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo to transfer
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo to transfer
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   description: "Transfer Options"
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/TransferRepoOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Repository"
+	//   "202":
+	//     "$ref": "#/responses/Repository"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	newOwner, err := models.GetUserByName(opts.NewOwner)
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+		}
+		return
+	}
+
+	var teams []*models.Team
+	if opts.TeamIDs != nil {
+		if !newOwner.IsOrganization() {
+			ctx.Error(http.StatusUnprocessableEntity, "", "repositories can only be added to the teams of organizations")
+			return
+		}
+		for _, teamID := range *opts.TeamIDs {
+			team, err := models.GetTeamByID(teamID)
+			if err != nil {
+				if err == models.ErrTeamNotExist {
+					ctx.Error(http.StatusUnprocessableEntity, "", err)
+				} else {
+					ctx.Error(http.StatusInternalServerError, "GetTeamByID", err)
+				}
+				return
+			}
+			if team.OrgID != newOwner.ID {
+				ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("team %d does not belong to %s", teamID, newOwner.Name))
+				return
+			}
+			teams = append(teams, team)
+		}
+	}
+
+	transferred, err := models.StartRepositoryTransfer(ctx.User, newOwner, ctx.Repo.Repository, teams)
+	if err != nil {
+		if models.IsErrRepoAlreadyExist(err) || models.IsErrRepoTransferInProgress(err) {
+			ctx.Error(http.StatusConflict, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "StartRepositoryTransfer", err)
+		}
+		return
+	}
+
+	if !transferred {
+		log.Trace("Repository transfer requested: %s/%s -> %s", ctx.Repo.Owner.Name, ctx.Repo.Repository.Name, newOwner.Name)
+		ctx.JSON(http.StatusAccepted, ctx.Repo.Repository.APIFormat(models.AccessModeAdmin))
+		return
+	}
+	log.Trace("Repository transferred: %s/%s -> %s", ctx.Repo.Owner.Name, ctx.Repo.Repository.Name, newOwner.Name)
+	ctx.JSON(http.StatusCreated, ctx.Repo.Repository.APIFormat(models.AccessModeAdmin))
+}
+
+// getPendingRepoTransfer returns the pending transfer of the repository if the
+// user can accept it, it responds with an error otherwise
+func getPendingRepoTransfer(ctx *context.APIContext) *models.RepoTransfer {
+	repoTransfer, err := models.GetPendingRepositoryTransfer(ctx.Repo.Repository)
+	if err != nil {
+		if models.IsErrNoPendingRepoTransfer(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPendingRepositoryTransfer", err)
+		}
+		return nil
+	}
+	if !repoTransfer.CanUserAcceptTransfer(ctx.User) {
+		ctx.Error(http.StatusForbidden, "", "the user cannot accept the transfer")
+		return nil
+	}
+	return repoTransfer
+}
+
+// AcceptTransfer accepts the pending transfer of a repository
+func AcceptTransfer(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/transfer/accept repository acceptRepoTransfer
+	// ---
+	// summary: Accept a repository transfer
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo to transfer
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo to transfer
+	//   type: string
+	//   required: true
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/Repository"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	if getPendingRepoTransfer(ctx); ctx.Written() {
+		return
+	}
+	if err := models.AcceptRepositoryTransfer(ctx.Repo.Repository); err != nil {
+		if models.IsErrRepoAlreadyExist(err) {
+			ctx.Error(http.StatusConflict, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "AcceptRepositoryTransfer", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusAccepted, ctx.Repo.Repository.APIFormat(models.AccessModeAdmin))
+}
+
+// RejectTransfer rejects the pending transfer of a repository
+func RejectTransfer(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/transfer/reject repository rejectRepoTransfer
+	// ---
+	// summary: Reject a repository transfer
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo to transfer
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo to transfer
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Repository"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	if getPendingRepoTransfer(ctx); ctx.Written() {
+		return
+	}
+	if err := models.CancelRepositoryTransfer(ctx.Repo.Repository); err != nil {
+		ctx.Error(http.StatusInternalServerError, "CancelRepositoryTransfer", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ctx.Repo.Repository.APIFormat(ctx.Repo.AccessMode))
+}
+
+// CancelTransfer cancels the pending transfer of a repository
+func CancelTransfer(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/transfer repository cancelRepoTransfer
+	// ---
+	// summary: Cancel the pending transfer of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo to transfer
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo to transfer
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	if _, err := models.GetPendingRepositoryTransfer(ctx.Repo.Repository); err != nil {
+		if models.IsErrNoPendingRepoTransfer(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPendingRepositoryTransfer", err)
+		}
+		return
+	}
+	if err := models.CancelRepositoryTransfer(ctx.Repo.Repository); err != nil {
+		ctx.Error(http.StatusInternalServerError, "CancelRepositoryTransfer", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
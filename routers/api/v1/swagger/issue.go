@@ -36,6 +36,41 @@ type swaggerResponseCommentList struct {
 	Body []api.Comment `json:"body"`
 }
 
+// TimelineList
+// swagger:response TimelineList
+type swaggerResponseTimelineList struct {
+	// in:body
+	Body []api.TimelineComment `json:"body"`
+}
+
+// IssueSLAList
+// swagger:response IssueSLAList
+type swaggerResponseIssueSLAList struct {
+	// in:body
+	Body []api.IssueSLA `json:"body"`
+}
+
+// IssueReminder
+// swagger:response IssueReminder
+type swaggerResponseIssueReminder struct {
+	// in:body
+	Body api.IssueReminder `json:"body"`
+}
+
+// IssueReminderList
+// swagger:response IssueReminderList
+type swaggerResponseIssueReminderList struct {
+	// in:body
+	Body []api.IssueReminder `json:"body"`
+}
+
+// IssueContentRevisionList
+// swagger:response IssueContentRevisionList
+type swaggerResponseIssueContentRevisionList struct {
+	// in:body
+	Body []api.IssueContentRevision `json:"body"`
+}
+
 // Label
 // swagger:response Label
 type swaggerResponseLabel struct {
@@ -64,6 +99,13 @@ type swaggerResponseMilestoneList struct {
 	Body []api.Milestone `json:"body"`
 }
 
+// MilestoneBurndown
+// swagger:response MilestoneBurndown
+type swaggerResponseMilestoneBurndown struct {
+	// in:body
+	Body api.MilestoneBurndown `json:"body"`
+}
+
 // TrackedTime
 // swagger:response TrackedTime
 type swaggerResponseTrackedTime struct {
@@ -37,6 +37,10 @@ type swaggerParameterBodies struct {
 	EditIssueOption api.EditIssueOption
 	// in:body
 	EditDeadlineOption api.EditDeadlineOption
+	// in:body
+	MoveIssueOption api.MoveIssueOption
+	// in:body
+	CreateIssueReminderOption api.CreateIssueReminderOption
 
 	// in:body
 	CreateIssueCommentOption api.CreateIssueCommentOption
@@ -72,6 +76,12 @@ type swaggerParameterBodies struct {
 	// in:body
 	EditPullRequestOption api.EditPullRequestOption
 	// in:body
+	CreatePullReviewOptions api.CreatePullReviewOptions
+	// in:body
+	SubmitPullReviewOptions api.SubmitPullReviewOptions
+	// in:body
+	MarkPullFileViewedOption api.MarkPullFileViewedOption
+	// in:body
 	MergePullRequestOption auth.MergePullRequestForm
 
 	// in:body
@@ -85,10 +95,29 @@ type swaggerParameterBodies struct {
 	EditRepoOption api.EditRepoOption
 	// in:body
 	CreateForkOption api.CreateForkOption
+	// in:body
+	GenerateRepoOption api.GenerateRepoOption
+	// in:body
+	TransferRepoOption api.TransferRepoOption
+	// in:body
+	SyncForkOption api.SyncForkOption
 
 	// in:body
 	CreateStatusOption api.CreateStatusOption
 
+	// in:body
+	CreateCheckRunOption api.CreateCheckRunOption
+	// in:body
+	EditCheckRunOption api.EditCheckRunOption
+
+	// in:body
+	CreatePushMirrorOption api.CreatePushMirrorOption
+
+	// in:body
+	CreateTagProtectionOption api.CreateTagProtectionOption
+	// in:body
+	EditTagProtectionOption api.EditTagProtectionOption
+
 	// in:body
 	CreateTeamOption api.CreateTeamOption
 	// in:body
@@ -109,6 +138,9 @@ type swaggerParameterBodies struct {
 	// in:body
 	EditAttachmentOptions api.EditAttachmentOptions
 
+	// in:body
+	CreateAttachmentUploadOptions api.CreateAttachmentUploadOptions
+
 	// in:body
 	CreateFileOptions api.CreateFileOptions
 
@@ -118,6 +150,15 @@ type swaggerParameterBodies struct {
 	// in:body
 	DeleteFileOptions api.DeleteFileOptions
 
+	// in:body
+	ChangeFilesOptions api.ChangeFilesOptions
+
 	// in:body
 	RepoTopicOptions api.RepoTopicOptions
+
+	// in:body
+	EditQuotaOption api.EditQuotaOption
+
+	// in:body
+	DeleteStaleBranchesOption api.DeleteStaleBranchesOption
 }
@@ -35,3 +35,10 @@ type swaggerResponseTeamList struct {
 	// in:body
 	Body []api.Team `json:"body"`
 }
+
+// AssigneeWorkloadList
+// swagger:response AssigneeWorkloadList
+type swaggerResponseAssigneeWorkloadList struct {
+	// in:body
+	Body []api.AssigneeWorkload `json:"body"`
+}
@@ -36,6 +36,20 @@ type swaggerResponseBranchList struct {
 	Body []api.Branch `json:"body"`
 }
 
+// StaleBranchList
+// swagger:response StaleBranchList
+type swaggerResponseStaleBranchList struct {
+	// in:body
+	Body []api.StaleBranch `json:"body"`
+}
+
+// ForkSyncInfo
+// swagger:response ForkSyncInfo
+type swaggerResponseForkSyncInfo struct {
+	// in:body
+	Body api.ForkSyncInfo `json:"body"`
+}
+
 // TagList
 // swagger:response TagList
 type swaggerResponseTagList struct {
@@ -127,6 +141,125 @@ type swaggerResponsePullRequestList struct {
 	Body []api.PullRequest `json:"body"`
 }
 
+// Diff
+// swagger:response Diff
+type swaggerResponseDiff struct {
+	// in:body
+	Body api.Diff `json:"body"`
+}
+
+// Compare
+// swagger:response Compare
+type swaggerResponseCompare struct {
+	// in:body
+	Body api.Compare `json:"body"`
+}
+
+// PullReview
+// swagger:response PullReview
+type swaggerResponsePullReview struct {
+	// in:body
+	Body api.PullReview `json:"body"`
+}
+
+// PullReviewCommentList
+// swagger:response PullReviewCommentList
+type swaggerResponsePullReviewCommentList struct {
+	// in:body
+	Body []api.PullReviewComment `json:"body"`
+}
+
+// PullViewedFileList
+// swagger:response PullViewedFileList
+type swaggerResponsePullViewedFileList struct {
+	// in:body
+	Body []api.PullViewedFile `json:"body"`
+}
+
+// SuggestedReviewerList
+// swagger:response SuggestedReviewerList
+type swaggerResponseSuggestedReviewerList struct {
+	// in:body
+	Body []api.SuggestedReviewer `json:"body"`
+}
+
+// PullReviewList
+// swagger:response PullReviewList
+type swaggerResponsePullReviewList struct {
+	// in:body
+	Body []api.PullReview `json:"body"`
+}
+
+// MergeQueueEntry
+// swagger:response MergeQueueEntry
+type swaggerResponseMergeQueueEntry struct {
+	// in:body
+	Body api.MergeQueueEntry `json:"body"`
+}
+
+// MergeQueueEntryList
+// swagger:response MergeQueueEntryList
+type swaggerResponseMergeQueueEntryList struct {
+	// in:body
+	Body []api.MergeQueueEntry `json:"body"`
+}
+
+// CheckRun
+// swagger:response CheckRun
+type swaggerResponseCheckRun struct {
+	// in:body
+	Body api.CheckRun `json:"body"`
+}
+
+// CheckRunList
+// swagger:response CheckRunList
+type swaggerResponseCheckRunList struct {
+	// in:body
+	Body []api.CheckRun `json:"body"`
+}
+
+// CheckRunAnnotationList
+// swagger:response CheckRunAnnotationList
+type swaggerResponseCheckRunAnnotationList struct {
+	// in:body
+	Body []api.CheckRunAnnotation `json:"body"`
+}
+
+// PushMirror
+// swagger:response PushMirror
+type swaggerResponsePushMirror struct {
+	// in:body
+	Body api.PushMirror `json:"body"`
+}
+
+// PushMirrorList
+// swagger:response PushMirrorList
+type swaggerResponsePushMirrorList struct {
+	// in:body
+	Body []api.PushMirror `json:"body"`
+}
+
+// MirrorSSHKey
+// swagger:response MirrorSSHKey
+type swaggerResponseMirrorSSHKey struct {
+	// in:body
+	Body api.MirrorSSHKey `json:"body"`
+}
+
+// TagProtection
+// swagger:response TagProtection
+type swaggerResponseTagProtection struct {
+	// in:body
+	Body api.TagProtection `json:"body"`
+}
+
+// TagProtectionList
+// swagger:response TagProtectionList
+type swaggerResponseTagProtectionList struct {
+	// in:body
+	Body []api.TagProtection `json:"body"`
+}
+
 // Status
 // swagger:response Status
 type swaggerResponseStatus struct {
@@ -155,6 +288,27 @@ type swaggerResponseSearchResults struct {
 	Body api.SearchResults `json:"body"`
 }
 
+// FileBlame
+// swagger:response FileBlame
+type swaggerResponseFileBlame struct {
+	// in:body
+	Body api.FileBlame `json:"body"`
+}
+
+// FileSnippet
+// swagger:response FileSnippet
+type swaggerResponseFileSnippet struct {
+	// in:body
+	Body api.FileSnippet `json:"body"`
+}
+
+// CodeSearchResults
+// swagger:response CodeSearchResults
+type swaggerResponseCodeSearchResults struct {
+	// in:body
+	Body api.CodeSearchResults `json:"body"`
+}
+
 // AttachmentList
 // swagger:response AttachmentList
 type swaggerResponseAttachmentList struct {
@@ -169,6 +323,13 @@ type swaggerResponseAttachment struct {
 	Body api.Attachment `json:"body"`
 }
 
+// AttachmentUpload
+// swagger:response AttachmentUpload
+type swaggerResponseAttachmentUpload struct {
+	// in:body
+	Body api.AttachmentUpload `json:"body"`
+}
+
 // GitTreeResponse
 // swagger:response GitTreeResponse
 type swaggerGitTreeResponse struct {
@@ -240,6 +401,13 @@ type swaggerContentsListResponse struct {
 	Body []api.ContentsResponse `json:"body"`
 }
 
+// FilesResponse
+// swagger:response FilesResponse
+type swaggerFilesResponse struct {
+	//in: body
+	Body api.FilesResponse `json:"body"`
+}
+
 // FileDeleteResponse
 // swagger:response FileDeleteResponse
 type swaggerFileDeleteResponse struct {
@@ -260,3 +428,31 @@ type swaggerTopicNames struct {
 	//in: body
 	Body api.TopicName `json:"body"`
 }
+
+// LanguageStatistics
+// swagger:response LanguageStatistics
+type swaggerResponseLanguageStatistics struct {
+	// in:body
+	Body map[string]int64 `json:"body"`
+}
+
+// RepoHousekeeping
+// swagger:response RepoHousekeeping
+type swaggerResponseRepoHousekeeping struct {
+	// in:body
+	Body api.RepoHousekeeping `json:"body"`
+}
+
+// ContributorStatsList
+// swagger:response ContributorStatsList
+type swaggerResponseContributorStatsList struct {
+	// in:body
+	Body []api.ContributorStats `json:"body"`
+}
+
+// WeeklyStatsList
+// swagger:response WeeklyStatsList
+type swaggerResponseWeeklyStatsList struct {
+	// in:body
+	Body []api.WeeklyStats `json:"body"`
+}
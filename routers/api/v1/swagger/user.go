@@ -16,6 +16,13 @@ type swaggerResponseUser struct {
 	Body api.User `json:"body"`
 }
 
+// Quota
+// swagger:response Quota
+type swaggerResponseQuota struct {
+	// in:body
+	Body api.Quota `json:"body"`
+}
+
 // UserList
 // swagger:response UserList
 type swaggerResponseUserList struct {
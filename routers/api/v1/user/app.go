@@ -42,6 +42,7 @@ func ListAccessTokens(ctx *context.APIContext) {
 			ID:             tokens[i].ID,
 			Name:           tokens[i].Name,
 			TokenLastEight: tokens[i].TokenLastEight,
+			Scopes:         tokens[i].Scope.Strings(),
 		}
 	}
 	ctx.JSON(200, &apiTokens)
@@ -68,15 +69,46 @@ func CreateAccessToken(ctx *context.APIContext, form api.CreateAccessTokenOption
 	//     type: object
 	//     required:
 	//       - name
+	//       - scopes
 	//     properties:
 	//       name:
 	//         type: string
+	//       scopes:
+	//         type: array
+	//         items:
+	//           type: string
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/AccessToken"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	// The password of the user is required, otherwise an access token sent as
+	// the password could create a token with more scopes than its own
+	if true == ctx.Data["IsApiToken"] {
+		ctx.Error(403, "", "access tokens can not be created with an access token")
+		return
+	}
+
+	scope, err := models.NewAccessTokenScope(form.Scopes...)
+	if err != nil {
+		if models.IsErrInvalidAccessTokenScope(err) {
+			ctx.Error(422, "", err)
+		} else {
+			ctx.Error(500, "NewAccessTokenScope", err)
+		}
+		return
+	} else if len(scope) == 0 {
+		ctx.Error(422, "", "at least one scope is required")
+		return
+	}
+
 	t := &models.AccessToken{
-		UID:  ctx.User.ID,
-		Name: form.Name,
+		UID:   ctx.User.ID,
+		Name:  form.Name,
+		Scope: scope,
 	}
 	if t.Name == "drone" {
 		t.Name = "drone-legacy-use-oauth2-instead"
@@ -90,6 +122,7 @@ func CreateAccessToken(ctx *context.APIContext, form api.CreateAccessTokenOption
 		Token:          t.Token,
 		ID:             t.ID,
 		TokenLastEight: t.TokenLastEight,
+		Scopes:         t.Scope.Strings(),
 	})
 }
 
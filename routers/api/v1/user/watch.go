@@ -92,7 +92,8 @@ func IsWatching(ctx *context.APIContext) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/WatchInfo"
-	if models.IsWatching(ctx.User.ID, ctx.Repo.Repository.ID) {
+	mode := models.GetWatchMode(ctx.User.ID, ctx.Repo.Repository.ID)
+	if mode != models.RepoWatchModeNone {
 		ctx.JSON(200, api.WatchInfo{
 			Subscribed:    true,
 			Ignored:       false,
@@ -100,6 +101,7 @@ func IsWatching(ctx *context.APIContext) {
 			CreatedAt:     ctx.Repo.Repository.CreatedUnix.AsTime(),
 			URL:           subscriptionURL(ctx.Repo.Repository),
 			RepositoryURL: repositoryURL(ctx.Repo.Repository),
+			Mode:          mode.String(),
 		})
 	} else {
 		ctx.NotFound()
@@ -122,10 +124,27 @@ func Watch(ctx *context.APIContext) {
 	//   description: name of the repo
 	//   type: string
 	//   required: true
+	// - name: mode
+	//   in: query
+	//   description: what to get notified of, one of all, participating, issues and releases.
+	//     Defaults to all for repos not watched yet, a watched repo keeps its mode.
+	//   type: string
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/WatchInfo"
-	err := models.WatchRepo(ctx.User.ID, ctx.Repo.Repository.ID, true)
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	var err error
+	if modeName := ctx.Query("mode"); len(modeName) > 0 {
+		mode, ok := models.ParseRepoWatchMode(modeName)
+		if !ok || mode == models.RepoWatchModeNone {
+			ctx.Error(422, "", "Invalid watch mode")
+			return
+		}
+		err = models.WatchRepoMode(ctx.User.ID, ctx.Repo.Repository.ID, mode)
+	} else {
+		err = models.WatchRepo(ctx.User.ID, ctx.Repo.Repository.ID, true)
+	}
 	if err != nil {
 		ctx.Error(500, "WatchRepo", err)
 		return
@@ -137,6 +156,7 @@ func Watch(ctx *context.APIContext) {
 		CreatedAt:     ctx.Repo.Repository.CreatedUnix.AsTime(),
 		URL:           subscriptionURL(ctx.Repo.Repository),
 		RepositoryURL: repositoryURL(ctx.Repo.Repository),
+		Mode:          models.GetWatchMode(ctx.User.ID, ctx.Repo.Repository.ID).String(),
 	})
 
 }
@@ -65,6 +65,10 @@ func CheckCreateHookOption(ctx *context.APIContext, form *api.CreateHookOption)
 		ctx.Error(422, "", "Invalid content type")
 		return false
 	}
+	if err := models.CheckWebhookPayloadTemplate(form.Config["payload_template"]); err != nil {
+		ctx.Error(422, "", "Invalid payload template: "+err.Error())
+		return false
+	}
 	return true
 }
 
@@ -111,11 +115,16 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID
 				PullRequest:  com.IsSliceContainsStr(form.Events, string(models.HookEventPullRequest)),
 				Repository:   com.IsSliceContainsStr(form.Events, string(models.HookEventRepository)),
 				Release:      com.IsSliceContainsStr(form.Events, string(models.HookEventRelease)),
+				Label:        com.IsSliceContainsStr(form.Events, string(models.HookEventLabel)),
+				Wiki:         com.IsSliceContainsStr(form.Events, string(models.HookEventWiki)),
 			},
 		},
 		IsActive:     form.Active,
 		HookTaskType: models.ToHookTaskType(form.Type),
 	}
+	if w.HookTaskType == models.GITEA {
+		w.PayloadTemplate = form.Config["payload_template"]
+	}
 	if w.HookTaskType == models.SLACK {
 		channel, ok := form.Config["channel"]
 		if !ok {
@@ -199,6 +208,13 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webho
 			}
 			w.ContentType = models.ToHookContentType(ct)
 		}
+		if tmpl, ok := form.Config["payload_template"]; ok && w.HookTaskType == models.GITEA {
+			if err := models.CheckWebhookPayloadTemplate(tmpl); err != nil {
+				ctx.Error(422, "", "Invalid payload template: "+err.Error())
+				return false
+			}
+			w.PayloadTemplate = tmpl
+		}
 
 		if w.HookTaskType == models.SLACK {
 			if channel, ok := form.Config["channel"]; ok {
@@ -236,6 +252,8 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webho
 	w.PullRequest = com.IsSliceContainsStr(form.Events, string(models.HookEventPullRequest))
 	w.Repository = com.IsSliceContainsStr(form.Events, string(models.HookEventRepository))
 	w.Release = com.IsSliceContainsStr(form.Events, string(models.HookEventRelease))
+	w.Label = com.IsSliceContainsStr(form.Events, string(models.HookEventLabel))
+	w.Wiki = com.IsSliceContainsStr(form.Events, string(models.HookEventWiki))
 
 	if err := w.UpdateEvent(); err != nil {
 		ctx.Error(500, "UpdateEvent", err)
@@ -12,6 +12,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/indexer"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/search"
 	"code.gitea.io/gitea/modules/setting"
@@ -283,6 +284,7 @@ func ExploreCode(ctx *context.Context) {
 	ctx.Data["PageIsExploreCode"] = true
 
 	keyword := strings.TrimSpace(ctx.Query("q"))
+	language := strings.TrimSpace(ctx.Query("l"))
 	page := ctx.QueryInt("page")
 	if page <= 0 {
 		page = 1
@@ -309,8 +311,9 @@ func ExploreCode(ctx *context.Context) {
 	}
 
 	var (
-		total         int
-		searchResults []*search.Result
+		total                 int
+		searchResults         []*search.Result
+		searchResultLanguages []*indexer.SearchResultLanguages
 	)
 
 	// if non-admin login user, we need check UnitTypeCode at first
@@ -332,14 +335,14 @@ func ExploreCode(ctx *context.Context) {
 
 		ctx.Data["RepoMaps"] = rightRepoMap
 
-		total, searchResults, err = search.PerformSearch(repoIDs, keyword, page, setting.UI.RepoSearchPagingNum)
+		total, searchResults, searchResultLanguages, err = search.PerformSearch(repoIDs, keyword, language, page, setting.UI.RepoSearchPagingNum)
 		if err != nil {
 			ctx.ServerError("SearchResults", err)
 			return
 		}
 		// if non-login user or isAdmin, no need to check UnitTypeCode
 	} else if (ctx.User == nil && len(repoIDs) > 0) || isAdmin {
-		total, searchResults, err = search.PerformSearch(repoIDs, keyword, page, setting.UI.RepoSearchPagingNum)
+		total, searchResults, searchResultLanguages, err = search.PerformSearch(repoIDs, keyword, language, page, setting.UI.RepoSearchPagingNum)
 		if err != nil {
 			ctx.ServerError("SearchResults", err)
 			return
@@ -369,12 +372,15 @@ func ExploreCode(ctx *context.Context) {
 	}
 
 	ctx.Data["Keyword"] = keyword
+	ctx.Data["Language"] = language
 	ctx.Data["SearchResults"] = searchResults
+	ctx.Data["SearchResultLanguages"] = searchResultLanguages
 	ctx.Data["RequireHighlightJS"] = true
 	ctx.Data["PageIsViewCode"] = true
 
 	pager := context.NewPagination(total, setting.UI.RepoSearchPagingNum, page, 5)
 	pager.SetDefaultParams(ctx)
+	pager.AddParam(ctx, "l", "Language")
 	ctx.Data["Page"] = pager
 
 	ctx.HTML(200, tplExploreCode)
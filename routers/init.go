@@ -19,8 +19,10 @@ import (
 	"code.gitea.io/gitea/modules/mailer"
 	"code.gitea.io/gitea/modules/markup"
 	"code.gitea.io/gitea/modules/markup/external"
+	pull_service "code.gitea.io/gitea/modules/pull"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/ssh"
+	"code.gitea.io/gitea/modules/storage"
 
 	"gitea.com/macaron/macaron"
 )
@@ -40,6 +42,9 @@ func checkRunMode() {
 // NewServices init new services
 func NewServices() {
 	setting.NewServices()
+	if err := storage.Init(); err != nil {
+		log.Fatal("Failed to initialize the storages: %v", err)
+	}
 	mailer.NewContext()
 	_ = cache.NewContext()
 }
@@ -98,9 +103,13 @@ func GlobalInit() {
 			log.Fatal("Failed to initialize issue indexer: %v", err)
 		}
 		models.InitRepoIndexer()
+		models.InitLanguageStats()
+		models.InitCommitGraphs()
 		models.InitSyncMirrors()
 		models.InitDeliverHooks()
 		models.InitTestPullRequests()
+		pull_service.InitMergeQueue()
+		pull_service.InitAutoMerge()
 	}
 	if setting.EnableSQLite3 {
 		log.Info("SQLite3 Supported")
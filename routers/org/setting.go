@@ -31,6 +31,9 @@ func Settings(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("org.settings")
 	ctx.Data["PageIsSettingsOptions"] = true
 	ctx.Data["CurrentVisibility"] = ctx.Org.Organization.Visibility
+	ctx.Data["Gitignores"] = models.Gitignores
+	ctx.Data["Licenses"] = models.Licenses
+	ctx.Data["InstanceDefaultBranch"] = setting.Repository.DefaultBranch
 	ctx.HTML(200, tplSettingsOptions)
 }
 
@@ -39,6 +42,9 @@ func SettingsPost(ctx *context.Context, form auth.UpdateOrgSettingForm) {
 	ctx.Data["Title"] = ctx.Tr("org.settings")
 	ctx.Data["PageIsSettingsOptions"] = true
 	ctx.Data["CurrentVisibility"] = ctx.Org.Organization.Visibility
+	ctx.Data["Gitignores"] = models.Gitignores
+	ctx.Data["Licenses"] = models.Licenses
+	ctx.Data["InstanceDefaultBranch"] = setting.Repository.DefaultBranch
 
 	if ctx.HasError() {
 		ctx.HTML(200, tplSettingsOptions)
@@ -83,6 +89,11 @@ func SettingsPost(ctx *context.Context, form auth.UpdateOrgSettingForm) {
 	org.Website = form.Website
 	org.Location = form.Location
 	org.Visibility = form.Visibility
+	org.RepoDefaultBranch = form.RepoDefaultBranch
+	org.RepoDefaultVisibility = form.RepoDefaultVisibility
+	org.RepoDefaultAutoInit = form.RepoDefaultAutoInit
+	org.RepoDefaultGitignores = form.RepoDefaultGitignores
+	org.RepoDefaultLicense = form.RepoDefaultLicense
 	if err := models.UpdateUser(org); err != nil {
 		ctx.ServerError("UpdateUser", err)
 		return
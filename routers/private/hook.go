@@ -9,18 +9,90 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/private"
+	"code.gitea.io/gitea/modules/pull"
 	"code.gitea.io/gitea/modules/repofiles"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
 
 	"gitea.com/macaron/macaron"
 )
 
+// getDirSize returns the total size of the files in the directory, 0 if the
+// directory is not known
+func getDirSize(dir string) int64 {
+	if len(dir) == 0 {
+		return 0
+	}
+	var size int64
+	_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// checkOwnerGitQuota checks that the objects quarantined by the push do not grow
+// the repositories of the owner over its quota, the size of the objects is known
+// while they are quarantined. It writes the error response and returns false if
+// the quota is exceeded.
+func checkOwnerGitQuota(ctx *macaron.Context, repo *models.Repository, gitQuarantinePath string) bool {
+	if err := repo.GetOwner(); err != nil {
+		log.Error("Unable to get owner of %-v Error: %v", repo, err)
+		ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"err": err.Error(),
+		})
+		return false
+	}
+	if err := repo.Owner.CheckGitQuota(getDirSize(gitQuarantinePath)); err != nil {
+		if models.IsErrQuotaExceeded(err) {
+			log.Warn("Forbidden: %-v exceeds the git quota of %s", repo, repo.OwnerName)
+			ctx.JSON(http.StatusForbidden, map[string]interface{}{
+				"err": fmt.Sprintf("the repositories of %s exceed their quota of %s", repo.OwnerName, base.FileSize(err.(models.ErrQuotaExceeded).Limit)),
+			})
+			return false
+		}
+		log.Error("Unable to check the git quota of %s Error: %v", repo.OwnerName, err)
+		ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"err": err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// checkPushPolicy checks the commits pushed to the ref against the push policy
+// with check, it writes the error response and returns false if they violate it
+func checkPushPolicy(ctx *macaron.Context, repo *models.Repository, refFullName, newCommitID string, env []string, check func(*models.Repository, string, []string) error) bool {
+	if err := check(repo, newCommitID, env); err != nil {
+		if models.IsErrPushPolicyViolation(err) {
+			log.Warn("Forbidden: Push of %s to %s in %-v violates the push policy: %v", newCommitID, refFullName, repo, err)
+			ctx.JSON(http.StatusForbidden, map[string]interface{}{
+				"err": err.Error(),
+			})
+			return false
+		}
+		log.Error("Unable to check the push policy for: %s in %-v Error: %v", newCommitID, repo, err)
+		ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"err": err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
 // HookPreReceive checks whether a individual commit is acceptable
 func HookPreReceive(ctx *macaron.Context) {
 	ownerName := ctx.Params(":owner")
@@ -33,6 +105,30 @@ func HookPreReceive(ctx *macaron.Context) {
 	gitAlternativeObjectDirectories := ctx.QueryTrim("gitAlternativeObjectDirectories")
 	gitQuarantinePath := ctx.QueryTrim("gitQuarantinePath")
 	prID := ctx.QueryInt64("prID")
+	isRestrictedPush := ctx.QueryBool("isRestrictedPush")
+	isWiki := ctx.QueryBool("isWiki")
+	checkGitQuota := ctx.QueryBool("checkGitQuota")
+
+	// the environment giving access to the objects of the push, which are
+	// quarantined until the pre-receive hook accepts it
+	env := os.Environ()
+	if gitAlternativeObjectDirectories != "" {
+		env = append(env,
+			private.GitAlternativeObjectDirectories+"="+gitAlternativeObjectDirectories)
+	}
+	if gitObjectDirectory != "" {
+		env = append(env,
+			private.GitObjectDirectory+"="+gitObjectDirectory)
+	}
+	if gitQuarantinePath != "" {
+		env = append(env,
+			private.GitQuarantinePath+"="+gitQuarantinePath)
+	}
+
+	// The wiki pages edited on the web are pushed with the name of the wiki
+	if isWiki {
+		repoName = strings.TrimSuffix(repoName, ".wiki")
+	}
 
 	branchName := strings.TrimPrefix(refFullName, git.BranchPrefix)
 	repo, err := models.GetRepositoryByOwnerAndName(ownerName, repoName)
@@ -44,6 +140,145 @@ func HookPreReceive(ctx *macaron.Context) {
 		return
 	}
 	repo.OwnerName = ownerName
+
+	// The branches of the wiki are not protected, only the quota and the push policy apply to them
+	if isWiki {
+		if checkGitQuota && !checkOwnerGitQuota(ctx, repo, gitQuarantinePath) {
+			return
+		}
+		if newCommitID != git.EmptySHA && !checkPushPolicy(ctx, repo, refFullName, newCommitID, env, models.CheckWikiPushPolicy) {
+			return
+		}
+		ctx.PlainText(http.StatusOK, []byte("ok"))
+		return
+	}
+
+	// The objects of the repository are being garbage collected
+	if models.IsRepoHousekeepingRunning(repo.ID) {
+		log.Warn("Unavailable: housekeeping of %-v is running", repo)
+		ctx.JSON(http.StatusServiceUnavailable, map[string]interface{}{
+			"err": fmt.Sprintf("repository %s/%s is under maintenance, please try again later", ownerName, repoName),
+		})
+		return
+	}
+
+	// The branches and tags of archived repositories are read-only, this also
+	// covers the pushes of Gitea itself like merges of pull requests
+	if repo.IsArchived && (strings.HasPrefix(refFullName, git.BranchPrefix) || strings.HasPrefix(refFullName, git.TagPrefix)) {
+		log.Warn("Forbidden: %-v is archived", repo)
+		ctx.JSON(http.StatusForbidden, map[string]interface{}{
+			"err": fmt.Sprintf("repository %s/%s is archived", ownerName, repoName),
+		})
+		return
+	}
+
+	// Protected tags can only be created, moved or deleted by the whitelisted users
+	if strings.HasPrefix(refFullName, git.TagPrefix) {
+		tagName := strings.TrimPrefix(refFullName, git.TagPrefix)
+		allowed, err := models.IsUserAllowedToControlTag(repo.ID, tagName, userID)
+		if err != nil {
+			log.Error("Unable to check if user %d can control tag: %s in %-v Error: %v", userID, tagName, repo, err)
+			ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"err": err.Error(),
+			})
+			return
+		}
+		if !allowed {
+			log.Warn("Forbidden: User %d cannot create, update or delete protected tag: %s in %-v", userID, tagName, repo)
+			ctx.JSON(http.StatusForbidden, map[string]interface{}{
+				"err": fmt.Sprintf("tag %s is protected", tagName),
+			})
+			return
+		}
+	}
+
+	// The pushes growing the repositories of the owner over its quota are rejected
+	// whatever the ref, the hook asks for the check once per push
+	if checkGitQuota && !checkOwnerGitQuota(ctx, repo, gitQuarantinePath) {
+		return
+	}
+
+	// Users without write access can only push to the head branches of pull requests allowing edits from maintainers,
+	// and to refs/for/ to create pull requests
+	isBranch := strings.HasPrefix(refFullName, git.BranchPrefix)
+	if isRestrictedPush && !isBranch && !strings.HasPrefix(refFullName, git.AGitPullPrefix) {
+		log.Warn("Forbidden: User %d cannot push to: %s in %-v", userID, refFullName, repo)
+		ctx.JSON(http.StatusForbidden, map[string]interface{}{
+			"err": fmt.Sprintf("%s can not be pushed to without write access to the repository", refFullName),
+		})
+		return
+	} else if isRestrictedPush && isBranch {
+		user, err := models.GetUserByID(userID)
+		if err != nil {
+			log.Error("Unable to get user: %d Error: %v", userID, err)
+			ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"err": err.Error(),
+			})
+			return
+		}
+		canWrite, err := models.CanMaintainerWriteToBranch(repo, branchName, user)
+		if err != nil {
+			log.Error("Unable to check if %-v can write to branch: %s in %-v Error: %v", user, branchName, repo, err)
+			ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"err": err.Error(),
+			})
+			return
+		}
+		if !canWrite {
+			log.Warn("Forbidden: User %d cannot push to branch: %s in %-v", userID, branchName, repo)
+			ctx.JSON(http.StatusForbidden, map[string]interface{}{
+				"err": fmt.Sprintf("branch %s can not be pushed to without write access to the repository", branchName),
+			})
+			return
+		}
+	}
+
+	// Files locked with Git LFS by other users can not be changed
+	if setting.LFS.StartServer && strings.HasPrefix(refFullName, git.BranchPrefix) && newCommitID != git.EmptySHA {
+		// The files changed by the commits which are not in the repository yet
+		output, err := git.NewCommand("log", "--format=", "--name-only", "--no-renames", newCommitID, "--not", "--all").RunInDirWithEnv(repo.RepoPath(), env)
+		if err != nil {
+			log.Error("Unable to list the files changed by: %s in %-v Error: %v", newCommitID, repo, err)
+			ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"err": fmt.Sprintf("Fail to list the changed files: %v", err),
+			})
+			return
+		}
+		var paths []string
+		for _, p := range strings.Split(output, "\n") {
+			if p = strings.TrimSpace(p); len(p) > 0 {
+				paths = append(paths, p)
+			}
+		}
+		lock, err := models.GetLFSLockConflict(repo.ID, userID, paths)
+		if err != nil {
+			log.Error("Unable to check the LFS locks in %-v Error: %v", repo, err)
+			ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"err": err.Error(),
+			})
+			return
+		}
+		if lock != nil {
+			log.Warn("Forbidden: User %d cannot change file: %s locked by user %d in %-v", userID, lock.Path, lock.OwnerID, repo)
+			ctx.JSON(http.StatusForbidden, map[string]interface{}{
+				"err": fmt.Sprintf("file %s is locked by %s", lock.Path, lock.Owner.DisplayName()),
+			})
+			return
+		}
+	}
+
+	// The commits pushed to branches, tags and refs/for/ have to comply with the push policy
+	if (isBranch || strings.HasPrefix(refFullName, git.TagPrefix) || strings.HasPrefix(refFullName, git.AGitPullPrefix)) && newCommitID != git.EmptySHA {
+		if !checkPushPolicy(ctx, repo, refFullName, newCommitID, env, models.CheckPushPolicy) {
+			return
+		}
+	}
+
+	if !isBranch {
+		ctx.PlainText(http.StatusOK, []byte("ok"))
+		return
+	}
+
 	protectBranch, err := models.GetProtectedBranchBy(repo.ID, branchName)
 	if err != nil {
 		log.Error("Unable to get protected branch: %s in %-v Error: %v", branchName, repo, err)
@@ -64,20 +299,6 @@ func HookPreReceive(ctx *macaron.Context) {
 
 		// detect force push
 		if git.EmptySHA != oldCommitID {
-			env := os.Environ()
-			if gitAlternativeObjectDirectories != "" {
-				env = append(env,
-					private.GitAlternativeObjectDirectories+"="+gitAlternativeObjectDirectories)
-			}
-			if gitObjectDirectory != "" {
-				env = append(env,
-					private.GitObjectDirectory+"="+gitObjectDirectory)
-			}
-			if gitQuarantinePath != "" {
-				env = append(env,
-					private.GitQuarantinePath+"="+gitQuarantinePath)
-			}
-
 			output, err := git.NewCommand("rev-list", "--max-count=1", oldCommitID, "^"+newCommitID).RunInDirWithEnv(repo.RepoPath(), env)
 			if err != nil {
 				log.Error("Unable to detect force push between: %s and %s in %-v Error: %v", oldCommitID, newCommitID, repo, err)
@@ -123,6 +344,108 @@ func HookPreReceive(ctx *macaron.Context) {
 	ctx.PlainText(http.StatusOK, []byte("ok"))
 }
 
+// HookProcReceive creates or updates the pull requests of the references pushed to refs/for/
+func HookProcReceive(ctx *macaron.Context, opts private.HookProcReceiveOptions) {
+	ownerName := ctx.Params(":owner")
+	repoName := ctx.Params(":repo")
+
+	repo, err := models.GetRepositoryByOwnerAndName(ownerName, repoName)
+	if err != nil {
+		log.Error("Unable to get repository: %s/%s Error: %v", ownerName, repoName, err)
+		ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+	repo.OwnerName = ownerName
+
+	pusher, err := models.GetUserByID(opts.UserID)
+	if err != nil {
+		log.Error("Unable to get user: %d Error: %v", opts.UserID, err)
+		ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+	perm, err := models.GetUserRepoPermission(repo, pusher)
+	if err != nil {
+		log.Error("Unable to get permissions for %-v in %-v Error: %v", pusher, repo, err)
+		ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+	if !models.CanCreateAGitPullRequest(perm) {
+		log.Warn("Forbidden: User %d cannot create pull requests in %-v", opts.UserID, repo)
+		ctx.JSON(http.StatusForbidden, map[string]interface{}{
+			"err": fmt.Sprintf("pull requests can not be created in %s/%s", ownerName, repoName),
+		})
+		return
+	}
+
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		log.Error("Unable to open repository: %s/%s Error: %v", ownerName, repoName, err)
+		ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+	results, err := pull.ProcReceive(repo, gitRepo, pusher, &opts)
+	if err != nil {
+		log.Error("Unable to handle the pushed pull requests in %-v Error: %v", repo, err)
+		ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, results)
+}
+
+// HookApplyPushOptions changes the settings of the repository according to the
+// options given with git push -o
+func HookApplyPushOptions(ctx *macaron.Context, opts private.HookPushOptions) {
+	ownerName := ctx.Params(":owner")
+	repoName := ctx.Params(":repo")
+
+	repo, err := models.GetRepositoryByOwnerAndName(ownerName, repoName)
+	if err != nil {
+		log.Error("Unable to get repository: %s/%s Error: %v", ownerName, repoName, err)
+		ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+	pusher, err := models.GetUserByID(opts.UserID)
+	if err != nil {
+		log.Error("Unable to get user: %d Error: %v", opts.UserID, err)
+		ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	if err = repofiles.ApplyPushOptions(repo, pusher, opts.GitPushOptions); err != nil {
+		switch {
+		case models.IsErrUserDoesNotHaveAccessToRepo(err):
+			ctx.JSON(http.StatusForbidden, map[string]interface{}{
+				"err": fmt.Sprintf("the settings of %s/%s can only be changed by its administrators", ownerName, repoName),
+			})
+		case models.IsErrInvalidPushOption(err):
+			ctx.JSON(http.StatusUnprocessableEntity, map[string]interface{}{
+				"err": err.Error(),
+			})
+		default:
+			log.Error("Unable to apply the push options to %-v Error: %v", repo, err)
+			ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"err": err.Error(),
+			})
+		}
+		return
+	}
+	ctx.PlainText(http.StatusOK, []byte("ok"))
+}
+
 // HookPostReceive updates services and users
 func HookPostReceive(ctx *macaron.Context) {
 	ownerName := ctx.Params(":owner")
@@ -9,8 +9,10 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/private"
 	"code.gitea.io/gitea/modules/setting"
 
+	"gitea.com/macaron/binding"
 	"gitea.com/macaron/macaron"
 )
 
@@ -79,6 +81,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Post("/ssh/:id/update/:repoid", UpdatePublicKeyInRepo)
 		m.Get("/hook/pre-receive/:owner/:repo", HookPreReceive)
 		m.Get("/hook/post-receive/:owner/:repo", HookPostReceive)
+		m.Post("/hook/proc-receive/:owner/:repo", binding.Bind(private.HookProcReceiveOptions{}), HookProcReceive)
+		m.Post("/hook/push-options/:owner/:repo", binding.Bind(private.HookPushOptions{}), HookApplyPushOptions)
 		m.Get("/serv/none/:keyid", ServNoCommand)
 		m.Get("/serv/command/:keyid/:owner/:repo", ServCommand)
 	}, CheckInternalToken)
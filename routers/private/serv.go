@@ -245,7 +245,23 @@ func ServCommand(ctx *macaron.Context) {
 
 			userMode := perm.UnitAccessMode(unitType)
 
-			if userMode < mode {
+			// Maintainers of the base repository of pull requests allowing edits can
+			// push to their head branches and readers can create pull requests by
+			// pushing to refs/for/<branch>, which is checked by the pre-receive hook
+			if userMode < mode && mode == models.AccessModeWrite && unitType == models.UnitTypeCode {
+				results.IsRestrictedPush, err = models.CanPushWithoutWriteAccess(repo, user, perm)
+				if err != nil {
+					log.Error("Unable to check if %-v can push without write access to %-v Error: %v", user, repo, err)
+					ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+						"results": results,
+						"type":    "InternalServerError",
+						"err":     fmt.Sprintf("Unable to check permissions for user %d:%s in %s/%s Error: %v", user.ID, user.Name, results.OwnerName, results.RepoName, err),
+					})
+					return
+				}
+			}
+
+			if userMode < mode && !results.IsRestrictedPush {
 				ctx.JSON(http.StatusUnauthorized, map[string]interface{}{
 					"results": results,
 					"type":    "ErrUnauthorized",
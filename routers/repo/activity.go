@@ -13,7 +13,8 @@ import (
 )
 
 const (
-	tplActivity base.TplName = "repo/activity"
+	tplActivity             base.TplName = "repo/activity"
+	tplActivityContributors base.TplName = "repo/activity_contributors"
 )
 
 // Activity render the page to show repository latest changes
@@ -83,3 +84,105 @@ func ActivityAuthors(ctx *context.Context) {
 
 	ctx.JSON(200, authors)
 }
+
+// contributorGraphWeeks is the number of weeks shown in the graphs of the
+// contributors
+const contributorGraphWeeks = 52
+
+// contributorGraphBar is a bar of a graph of the weekly commits
+type contributorGraphBar struct {
+	Week    time.Time
+	Commits int64
+	// Height is the height of the bar in percents of the one of the week
+	// with the most commits
+	Height int64
+}
+
+// contributorGraph is the graph of the weekly commits of a contributor
+type contributorGraph struct {
+	*models.ContributorStats
+	Bars []*contributorGraphBar
+}
+
+// newContributorGraphBars returns the bars of the weeks of the graphs ending
+// with the current one
+func newContributorGraphBars() []*contributorGraphBar {
+	now := time.Now().UTC()
+	week := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	week = week.AddDate(0, 0, -int(week.Weekday())-7*(contributorGraphWeeks-1))
+	bars := make([]*contributorGraphBar, contributorGraphWeeks)
+	for i := range bars {
+		bars[i] = &contributorGraphBar{Week: week.AddDate(0, 0, 7*i)}
+	}
+	return bars
+}
+
+// addContributorGraphWeeks adds the commits of the weeks to the bars of the
+// same weeks
+func addContributorGraphWeeks(bars []*contributorGraphBar, weeks []*models.ContributorWeeklyStat) {
+	for _, week := range weeks {
+		i := int(week.Week.AsTime().Sub(bars[0].Week) / (7 * 24 * time.Hour))
+		if i >= 0 && i < len(bars) {
+			bars[i].Commits += week.Commits
+		}
+	}
+}
+
+// setContributorGraphHeights sets the heights of the bars of the graphs
+// relatively to the maximum number of weekly commits
+func setContributorGraphHeights(bars []*contributorGraphBar, max int64) {
+	for _, bar := range bars {
+		if max > 0 {
+			bar.Height = bar.Commits * 100 / max
+		}
+	}
+}
+
+// ActivityContributors renders the graphs of the weekly commits of the
+// contributors of the default branch during the last year
+func ActivityContributors(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.activity.contributors")
+	ctx.Data["PageIsActivity"] = true
+	ctx.Data["PageIsContributors"] = true
+
+	stats, err := ctx.Repo.Repository.GetContributorStats()
+	if err != nil {
+		ctx.ServerError("GetContributorStats", err)
+		return
+	}
+
+	totalBars := newContributorGraphBars()
+	contributors := make([]*contributorGraph, len(stats))
+	var max int64
+	for i, contributor := range stats {
+		contributors[i] = &contributorGraph{
+			ContributorStats: contributor,
+			Bars:             newContributorGraphBars(),
+		}
+		addContributorGraphWeeks(contributors[i].Bars, contributor.Weeks)
+		addContributorGraphWeeks(totalBars, contributor.Weeks)
+		for _, bar := range contributors[i].Bars {
+			if bar.Commits > max {
+				max = bar.Commits
+			}
+		}
+	}
+	// The graphs of the contributors share the same scale
+	for _, contributor := range contributors {
+		setContributorGraphHeights(contributor.Bars, max)
+	}
+	var totalMax int64
+	for _, bar := range totalBars {
+		if bar.Commits > totalMax {
+			totalMax = bar.Commits
+		}
+	}
+	setContributorGraphHeights(totalBars, totalMax)
+
+	ctx.Data["Contributors"] = contributors
+	ctx.Data["TotalBars"] = totalBars
+	ctx.Data["DateFrom"] = totalBars[0].Week.Format("January 2, 2006")
+	ctx.Data["DateUntil"] = time.Now().Format("January 2, 2006")
+
+	ctx.HTML(200, tplActivityContributors)
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
@@ -37,6 +38,15 @@ func UploadAttachment(ctx *context.Context) {
 	}
 	defer file.Close()
 
+	if err = ctx.User.CheckAttachmentQuota(header.Size); err != nil {
+		if models.IsErrQuotaExceeded(err) {
+			ctx.Error(400, ctx.Tr("repo.attachment.quota_exceeded", base.FileSize(err.(models.ErrQuotaExceeded).Limit)))
+		} else {
+			ctx.Error(500, fmt.Sprintf("CheckAttachmentQuota: %v", err))
+		}
+		return
+	}
+
 	buf := make([]byte, 1024)
 	n, _ := file.Read(buf)
 	if n > 0 {
@@ -63,3 +73,49 @@ func UploadAttachment(ctx *context.Context) {
 		"uuid": attach.UUID,
 	})
 }
+
+// GetAttachment serves the content of an attachment, the download is
+// redirected to the storage if it is configured to serve the attachments
+// directly
+func GetAttachment(ctx *context.Context) {
+	attach, err := models.GetAttachmentByUUID(ctx.Params(":uuid"))
+	if err != nil {
+		if models.IsErrAttachmentNotExist(err) {
+			ctx.Error(404)
+		} else {
+			ctx.ServerError("GetAttachmentByUUID", err)
+		}
+		return
+	}
+
+	directURL, err := attach.DirectURL()
+	if err != nil {
+		ctx.ServerError("DirectURL", err)
+		return
+	}
+	if directURL != nil {
+		if err := attach.IncreaseDownloadCount(); err != nil {
+			ctx.ServerError("Update", err)
+			return
+		}
+		ctx.Redirect(directURL.String())
+		return
+	}
+
+	fr, err := attach.Open()
+	if err != nil {
+		ctx.ServerError("Open", err)
+		return
+	}
+	defer fr.Close()
+
+	if err := attach.IncreaseDownloadCount(); err != nil {
+		ctx.ServerError("Update", err)
+		return
+	}
+
+	if err = ServeData(ctx, attach.Name, fr); err != nil {
+		ctx.ServerError("ServeData", err)
+		return
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"code.gitea.io/gitea/modules/markup"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
 )
 
 const (
@@ -86,7 +87,7 @@ func RefBlame(ctx *context.Context) {
 		}
 	}
 	ctx.Data["LatestCommit"] = latestCommit
-	ctx.Data["LatestCommitVerification"] = models.ParseCommitWithSignature(latestCommit)
+	ctx.Data["LatestCommitVerification"] = models.ParseCommitWithSignature(ctx.Repo.Repository, latestCommit)
 	ctx.Data["LatestCommitUser"] = models.ValidateCommitWithEmail(latestCommit)
 
 	statuses, err := models.GetLatestCommitStatus(ctx.Repo.Repository, ctx.Repo.Commit.ID.String(), 0)
@@ -188,8 +189,52 @@ func RefBlame(ctx *context.Context) {
 	ctx.HTML(200, tplBlame)
 }
 
+// blameAgeLevels is the number of levels of the heat coloring of the lines
+// by the age of their commit
+const blameAgeLevels = 10
+
+// blameAges returns the age levels of the commits, from 0 for the newest
+// commits of the file to blameAgeLevels-1 for the oldest ones.
+func blameAges(commitNames map[string]models.UserCommit) map[string]int {
+	var oldest, newest int64
+	for _, commit := range commitNames {
+		if commit.Commit == nil {
+			continue
+		}
+		when := commit.Author.When.Unix()
+		if oldest == 0 || when < oldest {
+			oldest = when
+		}
+		if when > newest {
+			newest = when
+		}
+	}
+
+	ages := make(map[string]int, len(commitNames))
+	for sha, commit := range commitNames {
+		if commit.Commit == nil || newest == oldest {
+			ages[sha] = 0
+			continue
+		}
+		ages[sha] = int((newest - commit.Author.When.Unix()) * (blameAgeLevels - 1) / (newest - oldest))
+	}
+	return ages
+}
+
+// renderBlameCommitMessage renders the summary of a commit message, linking
+// the issues and commits it references, the rest of it links to the commit
+func renderBlameCommitMessage(ctx *context.Context, summary, commitLink string) string {
+	rendered, err := markup.RenderCommitMessage([]byte(gotemplate.HTMLEscapeString(summary)), ctx.Repo.RepoLink, commitLink, ctx.Repo.Repository.ComposeMetas())
+	if err != nil {
+		log.Error("RenderCommitMessage: %v", err)
+		return fmt.Sprintf(`<a href="%s">%s</a>`, commitLink, html.EscapeString(summary))
+	}
+	return string(rendered)
+}
+
 func renderBlame(ctx *context.Context, blameParts []git.BlamePart, commitNames map[string]models.UserCommit) {
 	repoLink := ctx.Repo.RepoLink
+	ages := blameAges(commitNames)
 
 	var lines = make([]string, 0)
 
@@ -208,6 +253,7 @@ func renderBlame(ctx *context.Context, blameParts []git.BlamePart, commitNames m
 				attr = " bottom-line"
 			}
 			commit := commitNames[part.Sha]
+			attr = fmt.Sprintf(" blame-age-%d%s", ages[part.Sha], attr)
 			if index == 0 {
 				// User avatar image
 				avatar := ""
@@ -221,7 +267,13 @@ func renderBlame(ctx *context.Context, blameParts []git.BlamePart, commitNames m
 				} else {
 					avatar = fmt.Sprintf(`<img class="ui avatar image" src="%s" title="%s"/>`, html.EscapeString(base.AvatarLink(commit.Author.Email)), html.EscapeString(commit.Author.Name))
 				}
-				commitInfo.WriteString(fmt.Sprintf(`<div class="blame-info%s"><div class="blame-data"><div class="blame-avatar">%s</div><div class="blame-message"><a href="%s/commit/%s" title="%[5]s">%[5]s</a></div><div class="blame-time">%s</div></div></div>`, attr, avatar, repoLink, part.Sha, html.EscapeString(commit.CommitMessage), commitSince))
+				// Link to the blame of the file before the commit
+				prior := ""
+				if len(part.PreviousSha) > 0 {
+					prior = fmt.Sprintf(`<a class="blame-prior poping up" href="%s/blame/commit/%s/%s" data-content="%s" data-variation="tiny inverted"><i class="octicon octicon-versions"></i></a>`, repoLink, part.PreviousSha, util.PathEscapeSegments(part.PreviousPath), ctx.Tr("repo.blame_prior"))
+				}
+				message := renderBlameCommitMessage(ctx, commit.Summary(), fmt.Sprintf("%s/commit/%s", repoLink, part.Sha))
+				commitInfo.WriteString(fmt.Sprintf(`<div class="blame-info%s"><div class="blame-data"><div class="blame-avatar">%s</div><div class="blame-message" title="%s">%s</div><div class="blame-time">%s</div><div class="blame-prior-link">%s</div></div></div>`, attr, avatar, html.EscapeString(commit.Summary()), message, commitSince, prior))
 			} else {
 				commitInfo.WriteString(fmt.Sprintf(`<div class="blame-info%s">&#8203;</div>`, attr))
 			}
@@ -6,6 +6,7 @@
 package repo
 
 import (
+	"fmt"
 	"strings"
 
 	"code.gitea.io/gitea/models"
@@ -19,7 +20,8 @@ import (
 )
 
 const (
-	tplBranch base.TplName = "repo/branch/list"
+	tplBranch      base.TplName = "repo/branch/list"
+	tplStaleBranch base.TplName = "repo/branch/stale"
 )
 
 // Branch contains the branch information
@@ -32,6 +34,9 @@ type Branch struct {
 	CommitsAhead      int
 	CommitsBehind     int
 	LatestPullRequest *models.PullRequest
+	// UpstreamDivergence is set for the branches of forks which have an
+	// upstream branch
+	UpstreamDivergence *repofiles.ForkDivergence
 }
 
 // Branches render repository branch page
@@ -46,9 +51,89 @@ func Branches(ctx *context.Context) {
 	ctx.Data["PageIsBranches"] = true
 
 	ctx.Data["Branches"] = loadBranches(ctx)
+	if ctx.Written() {
+		return
+	}
 	ctx.HTML(200, tplBranch)
 }
 
+// StaleBranches render the page of the branches merged into the default branch
+// or without recent commits
+func StaleBranches(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.branch.stale_branches")
+	ctx.Data["IsRepoToolbarBranches"] = true
+	ctx.Data["DefaultBranch"] = ctx.Repo.Repository.DefaultBranch
+	ctx.Data["CanDelete"] = ctx.Repo.CanWrite(models.UnitTypeCode) && !ctx.Repo.Repository.IsMirror && !ctx.Repo.Repository.IsArchived
+	ctx.Data["PageIsViewCode"] = true
+	ctx.Data["PageIsBranches"] = true
+
+	days := repofiles.DefaultStaleBranchDays
+	if len(ctx.Query("days")) > 0 {
+		days = ctx.QueryInt("days")
+	}
+	ctx.Data["Days"] = days
+
+	branches, err := repofiles.FindStaleBranches(ctx.Repo.Repository, ctx.Repo.GitRepo, days)
+	if err != nil {
+		ctx.ServerError("FindStaleBranches", err)
+		return
+	}
+	ctx.Data["Branches"] = branches
+
+	ctx.HTML(200, tplStaleBranch)
+}
+
+// DeleteStaleBranchesPost deletes the selected stale branches
+func DeleteStaleBranchesPost(ctx *context.Context, form auth.DeleteStaleBranchesForm) {
+	link := fmt.Sprintf("%s/branches/stale?days=%d", ctx.Repo.RepoLink, form.Days)
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(link)
+		return
+	}
+	if ctx.Repo.Repository.IsMirror {
+		ctx.NotFound("DeleteStaleBranchesPost", nil)
+		return
+	}
+
+	deleted, err := repofiles.DeleteStaleBranches(ctx.User, ctx.Repo.Repository, ctx.Repo.GitRepo, form.Branches, form.Days)
+	if err != nil {
+		log.Error("DeleteStaleBranches: %v", err)
+		ctx.Flash.Error(ctx.Tr("repo.branch.stale_deletion_failed", len(deleted)))
+		ctx.Redirect(link)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.branch.stale_deletion_success", len(deleted)))
+	ctx.Redirect(link)
+}
+
+// SyncForkPost brings a branch of the fork up to date with its upstream branch
+func SyncForkPost(ctx *context.Context) {
+	branchName := ctx.Query("branch")
+	if _, err := repofiles.SyncFork(ctx.Repo.Repository, ctx.User, branchName); err != nil {
+		switch {
+		case models.IsErrForkUpToDate(err):
+			ctx.Flash.Info(ctx.Tr("repo.branch.sync_fork_up_to_date", branchName))
+		case models.IsErrForkSyncConflicts(err):
+			ctx.Flash.Error(ctx.Tr("repo.branch.sync_fork_conflicts", branchName, strings.Join(err.(models.ErrForkSyncConflicts).Paths, ", ")))
+		case models.IsErrUserCannotCommit(err):
+			ctx.Flash.Error(ctx.Tr("repo.editor.protected_branch", branchName))
+		case models.IsErrForkSyncNotPossible(err), git.IsErrBranchNotExist(err):
+			log.Debug("SyncFork: %v", err)
+			ctx.Flash.Error(ctx.Tr("repo.branch.sync_fork_failed", branchName))
+		default:
+			ctx.ServerError("SyncFork", err)
+			return
+		}
+		ctx.Redirect(ctx.Repo.RepoLink + "/branches")
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.branch.sync_fork_success", branchName))
+	ctx.Redirect(ctx.Repo.RepoLink + "/branches")
+}
+
 // DeleteBranchPost responses for delete merged branch
 func DeleteBranchPost(ctx *context.Context) {
 	defer redirect(ctx)
@@ -119,39 +204,10 @@ func redirect(ctx *context.Context) {
 }
 
 func deleteBranch(ctx *context.Context, branchName string) error {
-	commit, err := ctx.Repo.GitRepo.GetBranchCommit(branchName)
-	if err != nil {
-		log.Error("GetBranchCommit: %v", err)
-		return err
-	}
-
-	if err := ctx.Repo.GitRepo.DeleteBranch(branchName, git.DeleteBranchOptions{
-		Force: true,
-	}); err != nil {
+	if err := repofiles.DeleteBranch(ctx.User, ctx.Repo.Repository, ctx.Repo.GitRepo, branchName); err != nil {
 		log.Error("DeleteBranch: %v", err)
 		return err
 	}
-
-	// Don't return error below this
-	if err := repofiles.PushUpdate(
-		ctx.Repo.Repository,
-		branchName,
-		models.PushUpdateOptions{
-			RefFullName:  git.BranchPrefix + branchName,
-			OldCommitID:  commit.ID.String(),
-			NewCommitID:  git.EmptySHA,
-			PusherID:     ctx.User.ID,
-			PusherName:   ctx.User.Name,
-			RepoUserName: ctx.Repo.Owner.Name,
-			RepoName:     ctx.Repo.Repository.Name,
-		}); err != nil {
-		log.Error("Update: %v", err)
-	}
-
-	if err := ctx.Repo.Repository.AddDeletedBranch(branchName, commit.ID.String(), ctx.User.ID); err != nil {
-		log.Warn("AddDeletedBranch: %v", err)
-	}
-
 	return nil
 }
 
@@ -213,6 +269,13 @@ func loadBranches(ctx *context.Context) []*Branch {
 		}
 	}
 
+	if ctx.Repo.Repository.IsFork {
+		if err = loadUpstreamDivergences(ctx, branches); err != nil {
+			ctx.ServerError("loadUpstreamDivergences", err)
+			return nil
+		}
+	}
+
 	if ctx.Repo.CanWrite(models.UnitTypeCode) {
 		deletedBranches, err := getDeletedBranches(ctx)
 		if err != nil {
@@ -225,6 +288,35 @@ func loadBranches(ctx *context.Context) []*Branch {
 	return branches
 }
 
+// loadUpstreamDivergences loads how many commits the branches of the fork are
+// ahead or behind their upstream branches, if the user can read the upstream
+// repository
+func loadUpstreamDivergences(ctx *context.Context, branches []*Branch) error {
+	if err := ctx.Repo.Repository.GetBaseRepo(); err != nil {
+		return err
+	}
+	perm, err := models.GetUserRepoPermission(ctx.Repo.Repository.BaseRepo, ctx.User)
+	if err != nil {
+		return err
+	} else if !perm.CanRead(models.UnitTypeCode) {
+		return nil
+	}
+
+	names := make([]string, 0, len(branches))
+	for _, branch := range branches {
+		names = append(names, branch.Name)
+	}
+	divergences, err := repofiles.CountForkDivergingCommits(ctx.Repo.Repository, names)
+	if err != nil {
+		return err
+	}
+	for _, branch := range branches {
+		branch.UpstreamDivergence = divergences[branch.Name]
+	}
+	ctx.Data["CanSyncFork"] = ctx.Repo.CanWrite(models.UnitTypeCode) && !ctx.Repo.Repository.IsMirror && !ctx.Repo.Repository.IsArchived
+	return nil
+}
+
 func getDeletedBranches(ctx *context.Context) ([]*Branch, error) {
 	branches := []*Branch{}
 
@@ -0,0 +1,207 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/repofiles"
+	"code.gitea.io/gitea/modules/util"
+)
+
+const (
+	tplCherryPick base.TplName = "repo/editor/cherry_pick"
+)
+
+// prepareCherryPick loads the commit to cherry-pick or revert and the data
+// shared by the page and its form submission
+func prepareCherryPick(ctx *context.Context, revert bool) *git.Commit {
+	commit, err := ctx.Repo.GitRepo.GetCommit(ctx.Params(":sha"))
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound("GetCommit", err)
+		} else {
+			ctx.ServerError("GetCommit", err)
+		}
+		return nil
+	}
+	// Only merge commits can be reverted relative to one of their parents
+	if commit.ParentCount() == 0 || !revert && commit.ParentCount() > 1 {
+		ctx.NotFound("CherryPick", nil)
+		return nil
+	}
+
+	branches, err := ctx.Repo.GitRepo.GetBranches()
+	if err != nil {
+		ctx.ServerError("GetBranches", err)
+		return nil
+	}
+	parents := make([]string, commit.ParentCount())
+	for i := range parents {
+		parentID, err := commit.ParentID(i)
+		if err != nil {
+			ctx.ServerError("ParentID", err)
+			return nil
+		}
+		parents[i] = parentID.String()
+	}
+
+	ctx.Data["PageIsCherryPick"] = true
+	ctx.Data["PageIsRevert"] = revert
+	if revert {
+		ctx.Data["Title"] = ctx.Tr("repo.editor.revert", base.ShortSha(commit.ID.String()))
+		ctx.Data["CherryPickLink"] = ctx.Repo.RepoLink + "/_revert/" + commit.ID.String()
+	} else {
+		ctx.Data["Title"] = ctx.Tr("repo.editor.cherry_pick", base.ShortSha(commit.ID.String()))
+		ctx.Data["CherryPickLink"] = ctx.Repo.RepoLink + "/_cherrypick/" + commit.ID.String()
+	}
+	ctx.Data["CommitID"] = commit.ID.String()
+	ctx.Data["CherryPickCommit"] = commit
+	ctx.Data["CherryPickParents"] = parents
+	ctx.Data["Branches"] = branches
+	ctx.Data["BranchLink"] = ctx.Repo.RepoLink + "/src/branch/" + util.PathEscapeSegments(ctx.Repo.BranchName)
+	return commit
+}
+
+func cherryPick(ctx *context.Context, revert bool) {
+	commit := prepareCherryPick(ctx, revert)
+	if ctx.Written() {
+		return
+	}
+	canCommit := renderCommitRights(ctx)
+
+	var message string
+	if revert {
+		message = repofiles.GetDefaultRevertMessage(commit, ctx.Data["CherryPickParents"].([]string)[0])
+	} else {
+		message = repofiles.GetDefaultCherryPickMessage(commit)
+	}
+	ctx.Data["commit_summary"], ctx.Data["commit_message"] = splitMergeMessage(message)
+	// Reverts are proposed with a pull request by default
+	if canCommit && !revert {
+		ctx.Data["commit_choice"] = frmCommitChoiceDirect
+	} else {
+		ctx.Data["commit_choice"] = frmCommitChoiceNewBranch
+	}
+	ctx.Data["new_branch_name"] = GetUniquePatchBranchName(ctx)
+	ctx.Data["mainline"] = 1
+
+	ctx.HTML(200, tplCherryPick)
+}
+
+// CherryPick renders the page to cherry-pick a commit onto a branch
+func CherryPick(ctx *context.Context) {
+	cherryPick(ctx, false)
+}
+
+// Revert renders the page to revert a commit on a branch
+func Revert(ctx *context.Context) {
+	cherryPick(ctx, true)
+}
+
+func cherryPickPost(ctx *context.Context, form auth.CherryPickForm, revert bool) {
+	commit := prepareCherryPick(ctx, revert)
+	if ctx.Written() {
+		return
+	}
+	canCommit := renderCommitRights(ctx)
+	branchName := ctx.Repo.BranchName
+	if form.CommitChoice == frmCommitChoiceNewBranch {
+		branchName = form.NewBranchName
+	}
+
+	ctx.Data["commit_summary"] = form.CommitSummary
+	ctx.Data["commit_message"] = form.CommitMessage
+	ctx.Data["commit_choice"] = form.CommitChoice
+	ctx.Data["new_branch_name"] = form.NewBranchName
+	ctx.Data["mainline"] = form.Mainline
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplCherryPick)
+		return
+	}
+
+	// Cannot commit to a an existing branch if user doesn't have rights
+	if branchName == ctx.Repo.BranchName && !canCommit {
+		ctx.Data["Err_NewBranchName"] = true
+		ctx.Data["commit_choice"] = frmCommitChoiceNewBranch
+		ctx.RenderWithErr(ctx.Tr("repo.editor.cannot_commit_to_protected_branch", branchName), tplCherryPick, &form)
+		return
+	}
+
+	message := strings.TrimSpace(form.CommitSummary)
+	if len(message) == 0 {
+		message = commit.Summary()
+	}
+	form.CommitMessage = strings.TrimSpace(form.CommitMessage)
+	if len(form.CommitMessage) > 0 {
+		message += "\n\n" + form.CommitMessage
+	}
+
+	opts := &repofiles.CherryPickOptions{
+		CommitID:  commit.ID.String(),
+		Mainline:  form.Mainline,
+		OldBranch: ctx.Repo.BranchName,
+		NewBranch: branchName,
+		Message:   message,
+	}
+	var err error
+	if revert {
+		_, err = repofiles.Revert(ctx.Repo.Repository, ctx.User, opts)
+	} else {
+		_, err = repofiles.CherryPick(ctx.Repo.Repository, ctx.User, opts)
+	}
+	if err != nil {
+		switch {
+		case models.IsErrCherryPickConflicts(err):
+			paths := strings.Join(err.(models.ErrCherryPickConflicts).Paths, ", ")
+			if revert {
+				ctx.RenderWithErr(ctx.Tr("repo.editor.revert_conflicts", ctx.Repo.BranchName, paths), tplCherryPick, &form)
+			} else {
+				ctx.RenderWithErr(ctx.Tr("repo.editor.cherry_pick_conflicts", ctx.Repo.BranchName, paths), tplCherryPick, &form)
+			}
+		case models.IsErrCherryPickNotPossible(err):
+			if revert {
+				ctx.RenderWithErr(ctx.Tr("repo.editor.revert_not_possible", err.(models.ErrCherryPickNotPossible).Reason), tplCherryPick, &form)
+			} else {
+				ctx.RenderWithErr(ctx.Tr("repo.editor.cherry_pick_not_possible", err.(models.ErrCherryPickNotPossible).Reason), tplCherryPick, &form)
+			}
+		case git.IsErrBranchNotExist(err):
+			ctx.RenderWithErr(ctx.Tr("repo.editor.branch_does_not_exist", err.(git.ErrBranchNotExist).Name), tplCherryPick, &form)
+		case models.IsErrBranchAlreadyExists(err):
+			ctx.Data["Err_NewBranchName"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.editor.branch_already_exists", err.(models.ErrBranchAlreadyExists).BranchName), tplCherryPick, &form)
+		case models.IsErrUserCannotCommit(err):
+			ctx.Data["Err_NewBranchName"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.editor.cannot_commit_to_protected_branch", branchName), tplCherryPick, &form)
+		default:
+			ctx.ServerError("CherryPick", err)
+		}
+		return
+	}
+
+	log.Trace("Commit %s cherry-picked onto %s (revert: %v): %d", commit.ID, branchName, revert, ctx.Repo.Repository.ID)
+	if form.CommitChoice == frmCommitChoiceNewBranch {
+		ctx.Redirect(ctx.Repo.RepoLink + "/compare/" + util.PathEscapeSegments(ctx.Repo.BranchName) + "..." + util.PathEscapeSegments(form.NewBranchName))
+	} else {
+		ctx.Redirect(ctx.Repo.RepoLink + "/commits/branch/" + util.PathEscapeSegments(branchName))
+	}
+}
+
+// CherryPickPost applies the changes of a commit onto a branch
+func CherryPickPost(ctx *context.Context, form auth.CherryPickForm) {
+	cherryPickPost(ctx, form, false)
+}
+
+// RevertPost reverts the changes of a commit on a branch
+func RevertPost(ctx *context.Context, form auth.CherryPickForm) {
+	cherryPickPost(ctx, form, true)
+}
@@ -0,0 +1,103 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/modules/citation"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// getCitation returns the parsed citation file of the root of the tree of the
+// commit, nil if there is none
+func getCitation(commit *git.Commit) (*citation.Citation, error) {
+	entry, err := commit.GetTreeEntryByPath(citation.FileName)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	} else if entry.IsDir() || entry.Blob().Size() > setting.UI.MaxDisplayFileSize {
+		return nil, nil
+	}
+
+	dataRc, err := entry.Blob().DataAsync()
+	if err != nil {
+		return nil, err
+	}
+	defer dataRc.Close()
+	return citation.Parse(dataRc)
+}
+
+// renderCitation shows the panel to cite the repository if the root of the
+// tree has a valid citation file
+func renderCitation(ctx *context.Context) {
+	c, err := getCitation(ctx.Repo.Commit)
+	if err != nil {
+		log.Debug("getCitation[%s]: %v", ctx.Repo.Repository.FullName(), err)
+		return
+	}
+	if c != nil {
+		ctx.Data["Citation"] = c
+	}
+}
+
+// Citation exports the citation of the repository in the BibTeX format or in
+// the APA style, depending on the extension of the reference
+func Citation(ctx *context.Context) {
+	var (
+		uri         = ctx.Params("*")
+		ext         string
+		contentType string
+	)
+	switch {
+	case strings.HasSuffix(uri, ".bib"):
+		ext, contentType = ".bib", "application/x-bibtex; charset=utf-8"
+	case strings.HasSuffix(uri, ".txt"):
+		ext, contentType = ".txt", "text/plain; charset=utf-8"
+	default:
+		ctx.NotFound("Citation", nil)
+		return
+	}
+	refName := strings.TrimSuffix(uri, ext)
+
+	commit, err := getArchiveCommit(ctx.Repo.GitRepo, refName)
+	if err != nil {
+		ctx.ServerError("getArchiveCommit", err)
+		return
+	} else if commit == nil {
+		ctx.NotFound("Citation", nil)
+		return
+	}
+
+	c, err := getCitation(commit)
+	if err != nil {
+		ctx.NotFound("getCitation", err)
+		return
+	} else if c == nil {
+		ctx.NotFound("Citation", nil)
+		return
+	}
+
+	var content string
+	if ext == ".bib" {
+		content = c.BibTeX()
+	} else {
+		content = c.APA() + "\n"
+	}
+	ctx.Resp.Header().Set("Content-Type", contentType)
+	ctx.Resp.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, ctx.Repo.Repository.Name+ext))
+	ctx.Resp.WriteHeader(http.StatusOK)
+	if _, err = io.WriteString(ctx.Resp, content); err != nil {
+		log.Error("Write citation: %v", err)
+	}
+}
@@ -5,6 +5,7 @@
 package repo
 
 import (
+	"fmt"
 	"path"
 	"strings"
 
@@ -26,8 +27,9 @@ func ParseCompareInfo(ctx *context.Context) (*models.User, *models.Repository, *
 	baseRepo := ctx.Repo.Repository
 
 	// Get compared branches information
-	// format: <base branch>...[<head repo>:]<head branch>
+	// format: [<base owner>:]<base branch>...[<head owner>:]<head branch>
 	// base<-head: master...head:feature
+	// across forks: base:master...head:feature
 	// same repo: master...feature
 
 	var (
@@ -45,7 +47,15 @@ func ParseCompareInfo(ctx *context.Context) (*models.User, *models.Repository, *
 		return nil, nil, nil, nil, "", ""
 	}
 
+	// The owner of the base branch is the owner of the repository
 	baseBranch := infos[0]
+	if baseInfos := strings.Split(baseBranch, ":"); len(baseInfos) == 2 {
+		if !strings.EqualFold(baseInfos[0], baseRepo.OwnerName) {
+			ctx.NotFound("CompareAndPullRequest", nil)
+			return nil, nil, nil, nil, "", ""
+		}
+		baseBranch = baseInfos[1]
+	}
 	ctx.Data["BaseBranch"] = baseBranch
 
 	// If there is no head repository, it means compare between same repository.
@@ -94,10 +104,15 @@ func ParseCompareInfo(ctx *context.Context) (*models.User, *models.Repository, *
 	ctx.Data["BaseIsBranch"] = baseIsBranch
 	ctx.Data["BaseIsTag"] = baseIsTag
 
-	// Check if current user has fork of repository or in the same repository.
-	headRepo, has := models.HasForkedRepo(headUser.ID, baseRepo.ID)
-	if !has && !isSameRepo {
-		ctx.Data["PageIsComparePull"] = false
+	// The head repository has to belong to the fork network of the base repository
+	headRepo, err := models.GetRepositoryInForkNetwork(headUser.ID, baseRepo)
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.NotFound("GetRepositoryInForkNetwork", nil)
+		} else {
+			ctx.ServerError("GetRepositoryInForkNetwork", err)
+		}
+		return nil, nil, nil, nil, "", ""
 	}
 
 	var headGitRepo *git.Repository
@@ -248,7 +263,7 @@ func PrepareCompareDiff(
 	}
 
 	compareInfo.Commits = models.ValidateCommitsWithEmails(compareInfo.Commits)
-	compareInfo.Commits = models.ParseCommitsWithSignature(compareInfo.Commits)
+	compareInfo.Commits = models.ParseCommitsWithSignature(ctx.Repo.Repository, compareInfo.Commits)
 	compareInfo.Commits = models.ParseCommitsWithStatus(compareInfo.Commits, headRepo)
 	ctx.Data["Commits"] = compareInfo.Commits
 	ctx.Data["CommitCount"] = compareInfo.Commits.Len()
@@ -280,6 +295,34 @@ func PrepareCompareDiff(
 	return false
 }
 
+// getReadableForkNetwork returns the repositories of the fork network of the
+// current repository whose code can be read by the user
+func getReadableForkNetwork(ctx *context.Context) ([]*models.Repository, error) {
+	network, err := ctx.Repo.Repository.GetForkNetwork()
+	if err != nil {
+		return nil, fmt.Errorf("GetForkNetwork: %v", err)
+	}
+
+	repos := make([]*models.Repository, 0, len(network))
+	for _, repo := range network {
+		if repo.IsEmpty {
+			continue
+		}
+		perm, err := models.GetUserRepoPermission(repo, ctx.User)
+		if err != nil {
+			return nil, fmt.Errorf("GetUserRepoPermission: %v", err)
+		}
+		if !perm.CanRead(models.UnitTypeCode) {
+			continue
+		}
+		if err = repo.GetOwner(); err != nil {
+			return nil, fmt.Errorf("GetOwner: %v", err)
+		}
+		repos = append(repos, repo)
+	}
+	return repos, nil
+}
+
 // CompareDiff show different from one commit to another commit
 func CompareDiff(ctx *context.Context) {
 	headUser, headRepo, headGitRepo, compareInfo, baseBranch, headBranch := ParseCompareInfo(ctx)
@@ -300,6 +343,13 @@ func CompareDiff(ctx *context.Context) {
 		}
 		ctx.Data["HeadBranches"] = headBranches
 
+		headRepos, err := getReadableForkNetwork(ctx)
+		if err != nil {
+			ctx.ServerError("getReadableForkNetwork", err)
+			return
+		}
+		ctx.Data["HeadRepos"] = headRepos
+
 		pr, err := models.GetUnmergedPullRequest(headRepo.ID, ctx.Repo.Repository.ID, headBranch, baseBranch)
 		if err != nil {
 			if !models.IsErrPullRequestNotExist(err) {
@@ -331,7 +381,7 @@ func CompareDiff(ctx *context.Context) {
 	ctx.Data["RequireHighlightJS"] = true
 	ctx.Data["RequireTribute"] = true
 	ctx.Data["PullRequestWorkInProgressPrefixes"] = setting.Repository.PullRequest.WorkInProgressPrefixes
-	setTemplateIfExists(ctx, pullRequestTemplateKey, pullRequestTemplateCandidates)
+	setPullRequestTemplate(ctx)
 	renderAttachmentSettings(ctx)
 
 	ctx.HTML(200, tplCompare)
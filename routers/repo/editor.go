@@ -7,6 +7,7 @@ package repo
 import (
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"path"
 	"path/filepath"
 	"strings"
@@ -32,6 +33,11 @@ const (
 
 	frmCommitChoiceDirect    string = "direct"
 	frmCommitChoiceNewBranch string = "commit-to-new-branch"
+
+	// commitMessageTemplateFile is the file of a repository holding the
+	// template of the commit messages written in the web editor, like the
+	// commit.template option of git
+	commitMessageTemplateFile = ".gitmessage"
 )
 
 func renderCommitRights(ctx *context.Context) bool {
@@ -43,6 +49,42 @@ func renderCommitRights(ctx *context.Context) bool {
 	return canCommit
 }
 
+// setCommitMessageTemplate fills the commit summary and message of the commit
+// form with the first line and the rest of the commit message template of the
+// repository if it has one. The lines starting with a # are comments which are
+// left out.
+func setCommitMessageTemplate(ctx *context.Context) {
+	ctx.Data["commit_summary"] = ""
+	ctx.Data["commit_message"] = ""
+
+	blob, err := ctx.Repo.Commit.GetBlobByPath(commitMessageTemplateFile)
+	if err != nil {
+		if !git.IsErrNotExist(err) {
+			log.Error("GetBlobByPath: %v", err)
+		}
+		return
+	} else if blob.Size() > setting.UI.MaxDisplayFileSize {
+		return
+	}
+	content, err := blob.GetBlobContent()
+	if err != nil {
+		log.Error("GetBlobContent: %v", err)
+		return
+	}
+
+	lines := make([]string, 0, 10)
+	for _, line := range strings.Split(strings.Replace(content, "\r", "", -1), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	message := strings.SplitN(strings.TrimSpace(strings.Join(lines, "\n")), "\n", 2)
+	ctx.Data["commit_summary"] = strings.TrimSpace(message[0])
+	if len(message) > 1 {
+		ctx.Data["commit_message"] = strings.TrimSpace(message[1])
+	}
+}
+
 // getParentTreeFields returns list of parent tree names and corresponding tree paths
 // based on given tree path.
 func getParentTreeFields(treePath string) (treeNames []string, treePaths []string) {
@@ -131,14 +173,22 @@ func editFile(ctx *context.Context, isNewFile bool) {
 	ctx.Data["TreeNames"] = treeNames
 	ctx.Data["TreePaths"] = treePaths
 	ctx.Data["BranchLink"] = ctx.Repo.RepoLink + "/src/" + ctx.Repo.BranchNameSubURL()
-	ctx.Data["commit_summary"] = ""
-	ctx.Data["commit_message"] = ""
+	setCommitMessageTemplate(ctx)
 	if canCommit {
 		ctx.Data["commit_choice"] = frmCommitChoiceDirect
 	} else {
 		ctx.Data["commit_choice"] = frmCommitChoiceNewBranch
 	}
-	ctx.Data["new_branch_name"] = GetUniquePatchBranchName(ctx)
+	if !ctx.Repo.CanWrite(models.UnitTypeCode) {
+		// the changes are committed to a fork
+		if fork := renderProposalFork(ctx); fork != nil {
+			ctx.Data["new_branch_name"] = getUniquePatchBranchName(ctx.User, fork)
+		} else {
+			ctx.Data["new_branch_name"] = GetUniquePatchBranchName(ctx)
+		}
+	} else {
+		ctx.Data["new_branch_name"] = GetUniquePatchBranchName(ctx)
+	}
 	ctx.Data["last_commit"] = ctx.Repo.CommitID
 	ctx.Data["MarkdownFileExts"] = strings.Join(setting.Markdown.FileExtensions, ",")
 	ctx.Data["LineWrapExtensions"] = strings.Join(setting.Repository.Editor.LineWrapExtensions, ",")
@@ -161,6 +211,10 @@ func NewFile(ctx *context.Context) {
 func editFilePost(ctx *context.Context, form auth.EditRepoFileForm, isNewFile bool) {
 	canCommit := renderCommitRights(ctx)
 	treeNames, treePaths := getParentTreeFields(form.TreePath)
+	isProposingByFork := !ctx.Repo.CanWrite(models.UnitTypeCode)
+	if isProposingByFork {
+		form.CommitChoice = frmCommitChoiceNewBranch
+	}
 	branchName := ctx.Repo.BranchName
 	if form.CommitChoice == frmCommitChoiceNewBranch {
 		branchName = form.NewBranchName
@@ -183,6 +237,9 @@ func editFilePost(ctx *context.Context, form auth.EditRepoFileForm, isNewFile bo
 	ctx.Data["MarkdownFileExts"] = strings.Join(setting.Markdown.FileExtensions, ",")
 	ctx.Data["LineWrapExtensions"] = strings.Join(setting.Repository.Editor.LineWrapExtensions, ",")
 	ctx.Data["PreviewableFileModes"] = strings.Join(setting.Repository.Editor.PreviewableFileModes, ",")
+	if isProposingByFork {
+		renderProposalFork(ctx)
+	}
 
 	if ctx.HasError() {
 		ctx.HTML(200, tplEditFile)
@@ -212,9 +269,19 @@ func editFilePost(ctx *context.Context, form auth.EditRepoFileForm, isNewFile bo
 		message += "\n\n" + form.CommitMessage
 	}
 
-	if _, err := repofiles.CreateOrUpdateRepoFile(ctx.Repo.Repository, ctx.User, &repofiles.UpdateRepoFileOptions{
+	repo := ctx.Repo.Repository
+	oldBranch := ctx.Repo.BranchName
+	if isProposingByFork {
+		repo = prepareProposalFork(ctx, branchName, tplEditFile, &form)
+		if ctx.Written() {
+			return
+		}
+		oldBranch = branchName
+	}
+
+	if _, err := repofiles.CreateOrUpdateRepoFile(repo, ctx.User, &repofiles.UpdateRepoFileOptions{
 		LastCommitID: form.LastCommit,
-		OldBranch:    ctx.Repo.BranchName,
+		OldBranch:    oldBranch,
 		NewBranch:    branchName,
 		FromTreePath: ctx.Repo.TreePath,
 		TreePath:     form.TreePath,
@@ -267,9 +334,15 @@ func editFilePost(ctx *context.Context, form auth.EditRepoFileForm, isNewFile bo
 		} else {
 			ctx.RenderWithErr(ctx.Tr("repo.editor.fail_to_update_file", form.TreePath, err), tplEditFile, &form)
 		}
+		if isProposingByFork {
+			deleteProposalBranch(repo, branchName)
+		}
+		return
 	}
 
-	if form.CommitChoice == frmCommitChoiceNewBranch {
+	if isProposingByFork {
+		ctx.Redirect(ctx.Repo.RepoLink + "/compare/" + util.PathEscapeSegments(ctx.Repo.BranchName) + "..." + url.PathEscape(ctx.User.Name) + ":" + util.PathEscapeSegments(branchName))
+	} else if form.CommitChoice == frmCommitChoiceNewBranch {
 		ctx.Redirect(ctx.Repo.RepoLink + "/compare/" + ctx.Repo.BranchName + "..." + form.NewBranchName)
 	} else {
 		ctx.Redirect(ctx.Repo.RepoLink + "/src/branch/" + util.PathEscapeSegments(branchName) + "/" + util.PathEscapeSegments(form.TreePath))
@@ -332,8 +405,7 @@ func DeleteFile(ctx *context.Context) {
 	ctx.Data["TreePath"] = treePath
 	canCommit := renderCommitRights(ctx)
 
-	ctx.Data["commit_summary"] = ""
-	ctx.Data["commit_message"] = ""
+	setCommitMessageTemplate(ctx)
 	ctx.Data["last_commit"] = ctx.Repo.CommitID
 	if canCommit {
 		ctx.Data["commit_choice"] = frmCommitChoiceDirect
@@ -484,8 +556,7 @@ func UploadFile(ctx *context.Context) {
 	ctx.Data["TreeNames"] = treeNames
 	ctx.Data["TreePaths"] = treePaths
 	ctx.Data["BranchLink"] = ctx.Repo.RepoLink + "/src/" + ctx.Repo.BranchNameSubURL()
-	ctx.Data["commit_summary"] = ""
-	ctx.Data["commit_message"] = ""
+	setCommitMessageTemplate(ctx)
 	if canCommit {
 		ctx.Data["commit_choice"] = frmCommitChoiceDirect
 	} else {
@@ -631,7 +702,13 @@ func UploadFileToServer(ctx *context.Context) {
 		}
 	}
 
-	name := cleanUploadFileName(header.Filename)
+	// The files of an uploaded directory are sent with their path relative to
+	// the directory the files are uploaded to
+	name := header.Filename
+	if fullPath := ctx.Query("full_path"); len(fullPath) > 0 {
+		name = fullPath
+	}
+	name = cleanUploadFileName(name)
 	if len(name) == 0 {
 		ctx.Error(500, "Upload file name is invalid")
 		return
@@ -665,15 +742,78 @@ func RemoveUploadFileFromServer(ctx *context.Context, form auth.RemoveUploadFile
 	ctx.Status(204)
 }
 
+// renderProposalFork sets the name of the fork the changes proposed by the user
+// are committed to, it returns the fork if the user has already forked the
+// repository
+func renderProposalFork(ctx *context.Context) *models.Repository {
+	ctx.Data["IsProposingByFork"] = true
+	if fork, has := models.HasForkedRepo(ctx.User.ID, ctx.Repo.Repository.ID); has {
+		ctx.Data["ForkFullName"] = ctx.User.Name + "/" + fork.Name
+		return fork
+	}
+	ctx.Data["ForkFullName"] = ctx.User.Name + "/" + ctx.Repo.Repository.Name
+	return nil
+}
+
+// prepareProposalFork returns the fork of the repository of the user, forking
+// it if needed, with a new branch starting at the current branch of the
+// repository to commit the changes proposed by the user to
+func prepareProposalFork(ctx *context.Context, branchName string, tpl base.TplName, form interface{}) *models.Repository {
+	fork, has := models.HasForkedRepo(ctx.User.ID, ctx.Repo.Repository.ID)
+	if !has {
+		var err error
+		fork, err = models.ForkRepository(ctx.User, ctx.User, ctx.Repo.Repository, ctx.Repo.Repository.Name, ctx.Repo.Repository.Description)
+		if err != nil {
+			if models.IsErrRepoAlreadyExist(err) {
+				ctx.RenderWithErr(ctx.Tr("repo.editor.fork_name_already_exists", ctx.Repo.Repository.Name), tpl, form)
+			} else {
+				ctx.ServerError("ForkRepository", err)
+			}
+			return nil
+		}
+		log.Trace("Repository forked to propose changes[%d]: %s/%s", ctx.Repo.Repository.ID, ctx.User.Name, fork.Name)
+	}
+
+	if _, err := fork.GetBranch(branchName); err == nil {
+		ctx.Data["Err_NewBranchName"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.editor.branch_already_exists_in_fork", branchName), tpl, form)
+		return nil
+	} else if !git.IsErrBranchNotExist(err) {
+		ctx.ServerError("GetBranch", err)
+		return nil
+	}
+
+	// The branch is fetched into the fork rather than pushed to it so that the
+	// hooks of the fork are not run for commits it may not have yet.
+	if _, err := git.NewCommand("fetch", "--no-tags", ctx.Repo.Repository.RepoPath(),
+		git.BranchPrefix+ctx.Repo.BranchName+":"+git.BranchPrefix+branchName).RunInDir(fork.RepoPath()); err != nil {
+		ctx.ServerError("FetchBranchIntoFork", err)
+		return nil
+	}
+	return fork
+}
+
+// deleteProposalBranch deletes the branch created in the fork by
+// prepareProposalFork when the changes could not be committed to it
+func deleteProposalBranch(fork *models.Repository, branchName string) {
+	if _, err := git.NewCommand("branch", "-D", branchName).RunInDir(fork.RepoPath()); err != nil {
+		log.Error("Failed to delete branch %s of %s: %v", branchName, fork.FullName(), err)
+	}
+}
+
 // GetUniquePatchBranchName Gets a unique branch name for a new patch branch
 // It will be in the form of <username>-patch-<num> where <num> is the first branch of this format
 // that doesn't already exist. If we exceed 1000 tries or an error is thrown, we just return "" so the user has to
 // type in the branch name themselves (will be an empty field)
 func GetUniquePatchBranchName(ctx *context.Context) string {
-	prefix := ctx.User.LowerName + "-patch-"
+	return getUniquePatchBranchName(ctx.User, ctx.Repo.Repository)
+}
+
+func getUniquePatchBranchName(doer *models.User, repo *models.Repository) string {
+	prefix := doer.LowerName + "-patch-"
 	for i := 1; i <= 1000; i++ {
 		branchName := fmt.Sprintf("%s%d", prefix, i)
-		if _, err := ctx.Repo.Repository.GetBranch(branchName); err != nil {
+		if _, err := repo.GetBranch(branchName); err != nil {
 			if git.IsErrBranchNotExist(err) {
 				return branchName
 			}
@@ -203,6 +203,14 @@ func HTTP(ctx *context.Context) {
 						return
 					}
 				}
+				requiredScope := models.AccessTokenScopeWriteRepo
+				if isPull {
+					requiredScope = models.AccessTokenScopeReadRepo
+				}
+				if !token.Scope.Has(requiredScope) {
+					ctx.HandleText(http.StatusForbidden, fmt.Sprintf("the access token does not have the %s scope", requiredScope))
+					return
+				}
 				token.UpdatedUnix = timeutil.TimeStampNow()
 				if err = models.UpdateAccessToken(token); err != nil {
 					ctx.ServerError("UpdateAccessToken", err)
@@ -247,7 +255,19 @@ func HTTP(ctx *context.Context) {
 			return
 		}
 
-		if !perm.CanAccess(accessMode, unitType) {
+		// Maintainers of the base repository of pull requests allowing edits can
+		// push to their head branches and readers can create pull requests by
+		// pushing to refs/for/<branch>, which is checked by the pre-receive hook
+		isRestrictedPush := false
+		if !perm.CanAccess(accessMode, unitType) && !isPull && !isWiki {
+			isRestrictedPush, err = models.CanPushWithoutWriteAccess(repo, authUser, perm)
+			if err != nil {
+				ctx.ServerError("CanPushWithoutWriteAccess", err)
+				return
+			}
+		}
+
+		if !perm.CanAccess(accessMode, unitType) && !isRestrictedPush {
 			ctx.HandleText(http.StatusForbidden, "User permission denied")
 			return
 		}
@@ -263,6 +283,7 @@ func HTTP(ctx *context.Context) {
 			models.EnvPusherName + "=" + authUser.Name,
 			models.EnvPusherID + fmt.Sprintf("=%d", authUser.ID),
 			models.ProtectedBranchRepoID + fmt.Sprintf("=%d", repo.ID),
+			models.EnvIsRestrictedPush + fmt.Sprintf("=%t", isRestrictedPush),
 		}
 
 		if !authUser.KeepEmailPrivate {
@@ -420,9 +441,7 @@ func serviceRPC(h serviceHandler, service string) {
 	var stderr bytes.Buffer
 	cmd := exec.Command(git.GitExecutable, service, "--stateless-rpc", h.dir)
 	cmd.Dir = h.dir
-	if service == "receive-pack" {
-		cmd.Env = append(os.Environ(), h.environ...)
-	}
+	cmd.Env = append(os.Environ(), h.environ...)
 	cmd.Stdout = h.w
 	cmd.Stdin = reqBody
 	cmd.Stderr = &stderr
@@ -468,7 +487,7 @@ func getInfoRefs(h serviceHandler) {
 	h.setHeaderNoCache()
 	if hasAccess(getServiceType(h.r), h, false) {
 		service := getServiceType(h.r)
-		refs, err := git.NewCommand(service, "--stateless-rpc", "--advertise-refs", ".").RunInDirBytes(h.dir)
+		refs, err := git.NewCommand(service, "--stateless-rpc", "--advertise-refs", ".").RunInDirTimeoutEnv(append(os.Environ(), h.environ...), -1, h.dir)
 		if err != nil {
 			log.Error(fmt.Sprintf("%v - %s", err, string(refs)))
 		}
@@ -561,7 +580,10 @@ func HTTPBackend(ctx *context.Context, cfg *serviceConfig) http.HandlerFunc {
 					return
 				}
 
-				route.handler(serviceHandler{cfg, w, r, dir, file, cfg.Env})
+				// The clients request the git wire protocol version 2 with the
+				// Git-Protocol header
+				environ := git.ServeEnviron(cfg.Env, r.Header.Get("Git-Protocol"))
+				route.handler(serviceHandler{cfg, w, r, dir, file, environ})
 				return
 			}
 		}
@@ -9,8 +9,10 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"html/template"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -20,6 +22,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
 	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
+	issue_template "code.gitea.io/gitea/modules/issue/template"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup/markdown"
 	"code.gitea.io/gitea/modules/notification"
@@ -31,9 +34,10 @@ import (
 )
 
 const (
-	tplIssues    base.TplName = "repo/issue/list"
-	tplIssueNew  base.TplName = "repo/issue/new"
-	tplIssueView base.TplName = "repo/issue/view"
+	tplIssues      base.TplName = "repo/issue/list"
+	tplIssueNew    base.TplName = "repo/issue/new"
+	tplIssueChoose base.TplName = "repo/issue/choose"
+	tplIssueView   base.TplName = "repo/issue/view"
 
 	tplReactions base.TplName = "repo/issue/view_content/reactions"
 
@@ -52,6 +56,13 @@ var (
 		".github/ISSUE_TEMPLATE.md",
 		".github/issue_template.md",
 	}
+	// IssueFormCandidates directories of issue forms
+	IssueFormCandidates = []string{
+		".gitea/ISSUE_TEMPLATE",
+		".gitea/issue_template",
+		".github/ISSUE_TEMPLATE",
+		".github/issue_template",
+	}
 )
 
 // MustAllowUserComment checks to make sure if an issue is locked.
@@ -142,13 +153,30 @@ func issues(ctx *context.Context, milestoneID int64, isPullOption util.OptionalB
 		keyword = ""
 	}
 
+	searchKeyword, filters, err := models.ParseIssueSearchQuery(ctx.User, keyword)
+	if err != nil {
+		if !models.IsErrUserNotExist(err) {
+			ctx.ServerError("ParseIssueSearchQuery", err)
+			return
+		}
+		forceEmpty = true
+	}
+
 	var issueIDs []int64
-	if len(keyword) > 0 {
-		issueIDs, err = issue_indexer.SearchIssuesByKeyword(repo.ID, keyword)
+	// the fragments of the issues matching the keyword, if the indexer supports highlighting
+	var highlights = make(map[int64]template.HTML)
+	if !forceEmpty && len(searchKeyword) > 0 {
+		res, err := issue_indexer.SearchIssues(repo.ID, searchKeyword, 1000, 0)
 		if err != nil {
 			ctx.ServerError("issueIndexer.Search", err)
 			return
 		}
+		for i := range res.Hits {
+			issueIDs = append(issueIDs, res.Hits[i].ID)
+			if fragment := res.Hits[i].HighlightedFragment("content", "comments"); len(fragment) > 0 {
+				highlights[res.Hits[i].ID] = fragment
+			}
+		}
 		if len(issueIDs) == 0 {
 			forceEmpty = true
 		}
@@ -167,6 +195,7 @@ func issues(ctx *context.Context, milestoneID int64, isPullOption util.OptionalB
 			PosterID:    posterID,
 			IsPull:      isPullOption,
 			IssueIDs:    issueIDs,
+			Filters:     filters,
 		})
 		if err != nil {
 			ctx.ServerError("GetIssueStats", err)
@@ -203,6 +232,7 @@ func issues(ctx *context.Context, milestoneID int64, isPullOption util.OptionalB
 			LabelIDs:    labelIDs,
 			SortType:    sortType,
 			IssueIDs:    issueIDs,
+			Filters:     filters,
 		})
 		if err != nil {
 			ctx.ServerError("Issues", err)
@@ -232,7 +262,20 @@ func issues(ctx *context.Context, milestoneID int64, isPullOption util.OptionalB
 		}
 	}
 
+	if isPullOption == util.OptionalBoolTrue {
+		issueIDs := make([]int64, 0, len(issues))
+		for _, issue := range issues {
+			issueIDs = append(issueIDs, issue.ID)
+		}
+		ctx.Data["ReviewSummaries"], err = models.GetReviewSummaries(issueIDs)
+		if err != nil {
+			ctx.ServerError("GetReviewSummaries", err)
+			return
+		}
+	}
+
 	ctx.Data["Issues"] = issues
+	ctx.Data["IssueHighlights"] = highlights
 	ctx.Data["CommitStatus"] = commitStatus
 
 	// Get assignees.
@@ -302,13 +345,18 @@ func Issues(ctx *context.Context) {
 
 	issues(ctx, ctx.QueryInt64("milestone"), util.OptionalBoolOf(isPullList))
 
-	var err error
-	// Get milestones.
-	ctx.Data["Milestones"], err = models.GetMilestonesByRepoID(ctx.Repo.Repository.ID, api.StateType(ctx.Query("state")))
+	// Get milestones, including the ones of the organization.
+	milestones, err := models.GetMilestonesByRepoID(ctx.Repo.Repository.ID, api.StateType(ctx.Query("state")))
 	if err != nil {
 		ctx.ServerError("GetAllRepoMilestones", err)
 		return
 	}
+	orgMilestones, err := models.GetMilestonesByOrgID(ctx.Repo.Repository.OwnerID, api.StateType(ctx.Query("state")))
+	if err != nil {
+		ctx.ServerError("GetMilestonesByOrgID", err)
+		return
+	}
+	ctx.Data["Milestones"] = append(milestones, orgMilestones...)
 
 	perm, err := models.GetUserRepoPermission(ctx.Repo.Repository, ctx.User)
 	if err != nil {
@@ -320,19 +368,31 @@ func Issues(ctx *context.Context) {
 	ctx.HTML(200, tplIssues)
 }
 
-// RetrieveRepoMilestonesAndAssignees find all the milestones and assignees of a repository
+// RetrieveRepoMilestonesAndAssignees find all the milestones and assignees of a repository,
+// the milestones of the organization owning the repository are included.
 func RetrieveRepoMilestonesAndAssignees(ctx *context.Context, repo *models.Repository) {
-	var err error
-	ctx.Data["OpenMilestones"], err = models.GetMilestones(repo.ID, -1, false, "")
+	openMilestones, err := models.GetMilestones(repo.ID, -1, false, "")
 	if err != nil {
 		ctx.ServerError("GetMilestones", err)
 		return
 	}
-	ctx.Data["ClosedMilestones"], err = models.GetMilestones(repo.ID, -1, true, "")
+	closedMilestones, err := models.GetMilestones(repo.ID, -1, true, "")
 	if err != nil {
 		ctx.ServerError("GetMilestones", err)
 		return
 	}
+	openOrgMilestones, err := models.GetMilestonesByOrgID(repo.OwnerID, api.StateOpen)
+	if err != nil {
+		ctx.ServerError("GetMilestonesByOrgID", err)
+		return
+	}
+	closedOrgMilestones, err := models.GetMilestonesByOrgID(repo.OwnerID, api.StateClosed)
+	if err != nil {
+		ctx.ServerError("GetMilestonesByOrgID", err)
+		return
+	}
+	ctx.Data["OpenMilestones"] = append(openMilestones, openOrgMilestones...)
+	ctx.Data["ClosedMilestones"] = append(closedMilestones, closedOrgMilestones...)
 
 	ctx.Data["Assignees"], err = repo.GetAssignees()
 	if err != nil {
@@ -412,6 +472,94 @@ func setTemplateIfExists(ctx *context.Context, ctxDataKey string, possibleFiles
 	}
 }
 
+// getIssueForms returns the valid issue forms of the first candidate directory
+// which contains any, invalid forms are skipped.
+func getIssueForms(ctx *context.Context) []*issue_template.Template {
+	if ctx.Repo.Commit == nil {
+		var err error
+		ctx.Repo.Commit, err = ctx.Repo.GitRepo.GetBranchCommit(ctx.Repo.Repository.DefaultBranch)
+		if err != nil {
+			return nil
+		}
+	}
+
+	for _, dirname := range IssueFormCandidates {
+		tree, err := ctx.Repo.Commit.SubTree(dirname)
+		if err != nil {
+			continue
+		}
+		entries, err := tree.ListEntries()
+		if err != nil {
+			log.Error("ListEntries: %s: %v", dirname, err)
+			continue
+		}
+
+		forms := make([]*issue_template.Template, 0, len(entries))
+		for _, entry := range entries {
+			if !entry.IsRegular() || !issue_template.IsTemplateFile(entry.Name()) ||
+				entry.Blob().Size() >= setting.UI.MaxDisplayFileSize {
+				continue
+			}
+			content, err := entry.Blob().GetBlobContent()
+			if err != nil {
+				log.Error("GetBlobContent: %s/%s: %v", dirname, entry.Name(), err)
+				continue
+			}
+			form, err := issue_template.Unmarshal(entry.Name(), []byte(content))
+			if err != nil {
+				log.Warn("Repository %s has an invalid issue form: %v", ctx.Repo.Repository.FullName(), err)
+				continue
+			}
+			forms = append(forms, form)
+		}
+		if len(forms) > 0 {
+			return forms
+		}
+	}
+	return nil
+}
+
+func findIssueForm(forms []*issue_template.Template, fileName string) *issue_template.Template {
+	for _, form := range forms {
+		if form.FileName == fileName {
+			return form
+		}
+	}
+	return nil
+}
+
+// setIssueFormData prepares the fields of an issue form to be rendered with the
+// submitted values, or with the defaults of the form if values is nil
+func setIssueFormData(ctx *context.Context, form *issue_template.Template, values url.Values) {
+	fields := form.FieldValues(values)
+	for _, field := range fields {
+		if field.Type == issue_template.FieldTypeMarkdown {
+			field.Value = markdown.RenderString(field.Value, ctx.Repo.RepoLink, ctx.Repo.Repository.ComposeMetas())
+		}
+	}
+	ctx.Data["IssueForm"] = form
+	ctx.Data["IssueFormFields"] = fields
+	ctx.Data["issue_template"] = form.FileName
+}
+
+// checkIssueFormLabels marks the labels of an issue form as selected
+func checkIssueFormLabels(ctx *context.Context, labels []*models.Label, form *issue_template.Template) {
+	names := make(map[string]bool, len(form.Labels))
+	for _, name := range form.Labels {
+		names[name] = true
+	}
+
+	labelIDs := make([]string, 0, len(form.Labels))
+	for _, label := range labels {
+		if names[label.Name] {
+			label.IsChecked = true
+			labelIDs = append(labelIDs, com.ToStr(label.ID))
+		}
+	}
+	ctx.Data["HasSelectedLabel"] = len(labelIDs) > 0
+	ctx.Data["label_ids"] = strings.Join(labelIDs, ",")
+}
+
 // NewIssue render creating issue page
 func NewIssue(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.issues.new")
@@ -434,13 +582,32 @@ func NewIssue(ctx *context.Context) {
 		}
 	}
 
-	setTemplateIfExists(ctx, issueTemplateKey, IssueTemplateCandidates)
+	var issueForm *issue_template.Template
+	if forms := getIssueForms(ctx); len(forms) > 0 && len(body) == 0 {
+		fileName := ctx.Query("template")
+		if len(fileName) == 0 && !ctx.QueryBool("blank") {
+			ctx.Data["IssueForms"] = forms
+			ctx.HTML(200, tplIssueChoose)
+			return
+		}
+		if issueForm = findIssueForm(forms, fileName); issueForm != nil {
+			setIssueFormData(ctx, issueForm, nil)
+			ctx.Data["title"] = issueForm.Title
+		}
+	}
+
+	if issueForm == nil {
+		setTemplateIfExists(ctx, issueTemplateKey, IssueTemplateCandidates)
+	}
 	renderAttachmentSettings(ctx)
 
-	RetrieveRepoMetas(ctx, ctx.Repo.Repository)
+	labels := RetrieveRepoMetas(ctx, ctx.Repo.Repository)
 	if ctx.Written() {
 		return
 	}
+	if issueForm != nil {
+		checkIssueFormLabels(ctx, labels, issueForm)
+	}
 
 	ctx.HTML(200, tplIssueNew)
 }
@@ -482,9 +649,9 @@ func ValidateRepoMetas(ctx *context.Context, form auth.CreateIssueForm, isPull b
 	// Check milestone.
 	milestoneID := form.MilestoneID
 	if milestoneID > 0 {
-		ctx.Data["Milestone"], err = repo.GetMilestoneByID(milestoneID)
+		ctx.Data["Milestone"], err = models.GetAssignableMilestone(repo.ID, milestoneID)
 		if err != nil {
-			ctx.ServerError("GetMilestoneByID", err)
+			ctx.ServerError("GetAssignableMilestone", err)
 			return nil, nil, 0
 		}
 		ctx.Data["milestone_id"] = milestoneID
@@ -546,6 +713,16 @@ func NewIssuePost(ctx *context.Context, form auth.CreateIssueForm) {
 		return
 	}
 
+	var issueForm *issue_template.Template
+	if len(form.IssueTemplate) > 0 {
+		issueForm = findIssueForm(getIssueForms(ctx), form.IssueTemplate)
+		if issueForm == nil {
+			ctx.NotFound("findIssueForm", nil)
+			return
+		}
+		setIssueFormData(ctx, issueForm, ctx.Req.Form)
+	}
+
 	if setting.AttachmentEnabled {
 		attachments = form.Files
 	}
@@ -560,13 +737,37 @@ func NewIssuePost(ctx *context.Context, form auth.CreateIssueForm) {
 		return
 	}
 
+	content := form.Content
+	if issueForm != nil {
+		if err := issueForm.ValidateValues(ctx.Req.Form); err != nil {
+			if issue_template.IsErrInvalidValue(err) {
+				errValue := err.(issue_template.ErrInvalidValue)
+				ctx.RenderWithErr(ctx.Tr("repo.issues.new.form_field_"+errValue.Reason, errValue.Field.Attributes.Label), tplIssueNew, form)
+				return
+			}
+			ctx.ServerError("ValidateValues", err)
+			return
+		}
+		content = issueForm.RenderToMarkdown(ctx.Req.Form)
+
+		// The labels of an issue form are applied even if the poster cannot label issues
+		if !ctx.Repo.CanWrite(models.UnitTypeIssues) && len(issueForm.Labels) > 0 {
+			var err error
+			labelIDs, err = models.GetLabelIDsInRepoByNames(repo.ID, issueForm.Labels)
+			if err != nil {
+				ctx.ServerError("GetLabelIDsInRepoByNames", err)
+				return
+			}
+		}
+	}
+
 	issue := &models.Issue{
 		RepoID:      repo.ID,
 		Title:       form.Title,
 		PosterID:    ctx.User.ID,
 		Poster:      ctx.User,
 		MilestoneID: milestoneID,
-		Content:     form.Content,
+		Content:     content,
 		Ref:         form.Ref,
 	}
 	if err := models.NewIssue(repo, issue, labelIDs, assigneeIDs, attachments); err != nil {
@@ -603,6 +804,34 @@ func commentTag(repo *models.Repository, poster *models.User, issue *models.Issu
 
 // ViewIssue render issue view page
 func ViewIssue(ctx *context.Context) {
+	// Issues moved to another repository redirect to their new location,
+	// the users who can not read the issues of that repository get the stub
+	// left at the old index instead.
+	redirectIssueID, err := models.LookupIssueRedirect(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err == nil {
+		issue, err := models.GetIssueByID(redirectIssueID)
+		if err != nil {
+			ctx.ServerError("GetIssueByID", err)
+			return
+		}
+		if err = issue.LoadRepo(); err != nil {
+			ctx.ServerError("LoadRepo", err)
+			return
+		}
+		perm, err := models.GetUserRepoPermission(issue.Repo, ctx.User)
+		if err != nil {
+			ctx.ServerError("GetUserRepoPermission", err)
+			return
+		}
+		if perm.CanRead(models.UnitTypeIssues) {
+			ctx.Redirect(issue.HTMLURL())
+			return
+		}
+	} else if !models.IsErrIssueRedirectNotExist(err) {
+		ctx.ServerError("LookupIssueRedirect", err)
+		return
+	}
+
 	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
 	if err != nil {
 		if models.IsErrIssueNotExist(err) {
@@ -771,6 +1000,13 @@ func ViewIssue(ctx *context.Context) {
 	// Check if the user can use the dependencies
 	ctx.Data["CanCreateIssueDependencies"] = ctx.Repo.CanCreateIssueDependencies(ctx.User)
 
+	// Hide the references from repositories the user cannot read.
+	issue.Comments, err = models.FilterCrossReferences(issue.Comments, ctx.User)
+	if err != nil {
+		ctx.ServerError("FilterCrossReferences", err)
+		return
+	}
+
 	// Render comments and and fetch participants.
 	participants[0] = issue.Poster
 	for _, comment = range issue.Comments {
@@ -834,7 +1070,8 @@ func ViewIssue(ctx *context.Context) {
 			if comment.MilestoneID > 0 && comment.Milestone == nil {
 				comment.Milestone = ghostMilestone
 			}
-		} else if comment.Type == models.CommentTypeAssignees {
+		} else if comment.Type == models.CommentTypeAssignees || comment.Type == models.CommentTypeReviewRequest ||
+			comment.Type == models.CommentTypeDismissReview {
 			if err = comment.LoadAssigneeUser(); err != nil {
 				ctx.ServerError("LoadAssigneeUser", err)
 				return
@@ -844,6 +1081,15 @@ func ViewIssue(ctx *context.Context) {
 				ctx.ServerError("LoadDepIssueDetails", err)
 				return
 			}
+		} else if comment.Type == models.CommentTypeIssueMovedFrom || comment.Type == models.CommentTypeIssueMovedTo {
+			if err = comment.LoadDepIssueDetails(); err != nil {
+				ctx.ServerError("LoadDepIssueDetails", err)
+				return
+			}
+			if err = comment.DependentIssue.LoadRepo(); err != nil {
+				ctx.ServerError("LoadRepo", err)
+				return
+			}
 		} else if comment.Type == models.CommentTypeCode || comment.Type == models.CommentTypeReview {
 			if err = comment.LoadReview(); err != nil && !models.IsErrReviewNotExist(err) {
 				ctx.ServerError("LoadReview", err)
@@ -919,10 +1165,16 @@ func ViewIssue(ctx *context.Context) {
 				ctx.Data["MergeStyle"] = models.MergeStyleRebaseMerge
 			} else if prConfig.AllowSquash {
 				ctx.Data["MergeStyle"] = models.MergeStyleSquash
+			} else if prConfig.AllowFastForwardOnly {
+				ctx.Data["MergeStyle"] = models.MergeStyleFastForwardOnly
 			} else {
 				ctx.Data["MergeStyle"] = ""
 			}
 		}
+		if !issue.IsClosed {
+			ctx.Data["DefaultMergeMessage"], ctx.Data["DefaultMergeMessageBody"] = splitMergeMessage(pull.GetDefaultMergeMessage())
+			ctx.Data["DefaultSquashMessage"], ctx.Data["DefaultSquashMessageBody"] = splitMergeMessage(pull.GetDefaultSquashMessage())
+		}
 		if err = pull.LoadProtectedBranch(); err != nil {
 			ctx.ServerError("LoadProtectedBranch", err)
 			return
@@ -931,14 +1183,109 @@ func ViewIssue(ctx *context.Context) {
 			cnt := pull.ProtectedBranch.GetGrantedApprovalsCount(pull)
 			ctx.Data["IsBlockedByApprovals"] = pull.ProtectedBranch.RequiredApprovals > 0 && cnt < pull.ProtectedBranch.RequiredApprovals
 			ctx.Data["GrantedApprovals"] = cnt
+			if pull.ProtectedBranch.RequireCodeOwnerApproval {
+				missing, err := models.GetMissingCodeOwnerApprovals(pull)
+				if err != nil {
+					ctx.ServerError("GetMissingCodeOwnerApprovals", err)
+					return
+				}
+				patterns := make([]string, 0, len(missing))
+				for _, rule := range missing {
+					patterns = append(patterns, rule.Pattern)
+				}
+				ctx.Data["IsBlockedByCodeOwners"] = len(missing) > 0
+				ctx.Data["MissingCodeOwnerPatterns"] = strings.Join(patterns, ", ")
+			}
+			missingContexts, err := pull.ProtectedBranch.GetMissingStatusCheckContexts(pull)
+			if err != nil {
+				ctx.ServerError("GetMissingStatusCheckContexts", err)
+				return
+			}
+			ctx.Data["IsBlockedByStatusChecks"] = len(missingContexts) > 0
+			ctx.Data["MissingStatusCheckContexts"] = strings.Join(missingContexts, ", ")
+			unresolved, err := pull.ProtectedBranch.GetUnresolvedConversationsCount(pull)
+			if err != nil {
+				ctx.ServerError("GetUnresolvedConversationsCount", err)
+				return
+			}
+			ctx.Data["IsBlockedByConversations"] = unresolved > 0
+			ctx.Data["UnresolvedConversations"] = unresolved
+			ctx.Data["IsMergeQueueEnabled"] = pull.ProtectedBranch.EnableMergeQueue
 		}
+		if entry, err := models.GetMergeQueueEntryByPullID(pull.ID); err == nil {
+			if ctx.Data["MergeQueuePosition"], err = models.GetMergeQueuePosition(entry); err != nil {
+				ctx.ServerError("GetMergeQueuePosition", err)
+				return
+			}
+			ctx.Data["MergeQueueEntry"] = entry
+		} else if !models.IsErrMergeQueueEntryNotExist(err) {
+			ctx.ServerError("GetMergeQueueEntryByPullID", err)
+			return
+		}
+		if autoMerge, err := models.GetScheduledAutoMerge(pull.ID); err == nil {
+			if err = autoMerge.LoadDoer(); err != nil {
+				if !models.IsErrUserNotExist(err) {
+					ctx.ServerError("LoadDoer", err)
+					return
+				}
+				autoMerge.Doer = models.NewGhostUser()
+			}
+			ctx.Data["AutoMerge"] = autoMerge
+		} else if !models.IsErrPullAutoMergeNotExist(err) {
+			ctx.ServerError("GetScheduledAutoMerge", err)
+			return
+		}
+		if prConfig.IsSizeLimited() && !issue.IsClosed {
+			size, err := pull.GetSize()
+			if err != nil {
+				ctx.ServerError("GetSize", err)
+				return
+			}
+			if prConfig.IsSizeExceeded(size) {
+				ctx.Data["IsBlockedBySize"] = prConfig.BlockOversizedMerge
+				ctx.Data["IsPullSizeExceeded"] = !prConfig.BlockOversizedMerge
+			}
+			ctx.Data["PullSize"] = size
+		}
+		ctx.Data["AllowScheduleAutoMerge"] = ctx.IsSigned && ctx.Repo.CanWrite(models.UnitTypeCode) &&
+			(pull.ProtectedBranch == nil || pull.ProtectedBranch.CanUserMerge(ctx.User.ID))
+		ctx.Data["CanRevertPull"] = ctx.IsSigned && pull.HasMerged && len(pull.MergedCommitID) > 0 &&
+			ctx.Repo.CanWrite(models.UnitTypeCode) && !ctx.Repo.Repository.IsArchived
 		ctx.Data["IsPullBranchDeletable"] = canDelete && pull.HeadRepo != nil && git.IsBranchExist(pull.HeadRepo.RepoPath(), pull.HeadBranch)
+		if pull.IsFilesConflicted() {
+			ctx.Data["CanResolveConflicts"] = canPushToPullHead(ctx, issue, pull)
+			if ctx.Written() {
+				return
+			}
+		}
+		if !issue.IsClosed {
+			// The reference of broken pull requests might not exist
+			if behind, err := pull.GetCommitsBehind(); err != nil {
+				log.Error("GetCommitsBehind: %v", err)
+			} else if behind > 0 {
+				ctx.Data["CommitsBehind"] = behind
+				ctx.Data["IsBlockedByOutdatedBranch"] = pull.ProtectedBranch != nil &&
+					pull.ProtectedBranch.BlockOnOutdatedBranch && !pull.ProtectedBranch.EnableMergeQueue
+				ctx.Data["CanUpdatePullHead"] = canPushToPullHead(ctx, issue, pull)
+				if ctx.Written() {
+					return
+				}
+			}
+		}
 
 		ctx.Data["PullReviewersWithType"], err = models.GetReviewersByPullID(issue.ID)
 		if err != nil {
 			ctx.ServerError("GetReviewersByPullID", err)
 			return
 		}
+		ctx.Data["CanReRequestReview"] = ctx.IsSigned && !issue.IsClosed && !ctx.Repo.Repository.IsArchived &&
+			(issue.PosterID == ctx.User.ID || ctx.Repo.CanWrite(models.UnitTypePullRequests))
+		if !issue.IsClosed {
+			// Suggestions are only a hint, the pull request is shown without them
+			if ctx.Data["SuggestedReviewers"], err = pull.GetSuggestedReviewers(models.DefaultSuggestedReviewersLimit); err != nil {
+				log.Error("GetSuggestedReviewers: %v", err)
+			}
+		}
 	}
 
 	// Get Dependencies
@@ -953,6 +1300,14 @@ func ViewIssue(ctx *context.Context) {
 		return
 	}
 
+	contentHistoryCounts, err := models.GetIssueContentHistoryCounts(issue.ID)
+	if err != nil {
+		ctx.ServerError("GetIssueContentHistoryCounts", err)
+		return
+	}
+	ctx.Data["ContentHistoryCounts"] = contentHistoryCounts
+	ctx.Data["IssueContentEditCount"] = contentHistoryCounts[0]
+
 	ctx.Data["Participants"] = participants
 	ctx.Data["NumParticipants"] = len(participants)
 	ctx.Data["Issue"] = issue
@@ -1058,6 +1413,27 @@ func UpdateIssueTitle(ctx *context.Context) {
 	})
 }
 
+// DeleteIssue deletes an issue or pull request permanently
+func DeleteIssue(ctx *context.Context) {
+	issue := GetActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DeleteIssue(ctx.User, issue); err != nil {
+		ctx.ServerError("DeleteIssue", err)
+		return
+	}
+
+	notification.NotifyDeleteIssue(ctx.User, issue)
+
+	if issue.IsPull {
+		ctx.Redirect(ctx.Repo.RepoLink+"/pulls", http.StatusSeeOther)
+		return
+	}
+	ctx.Redirect(ctx.Repo.RepoLink+"/issues", http.StatusSeeOther)
+}
+
 // UpdateIssueContent change issue's content
 func UpdateIssueContent(ctx *context.Context) {
 	issue := GetActionIssue(ctx)
@@ -0,0 +1,139 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"html"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// getContentHistoryCommentID returns the comment ID given by the query,
+// checking that the comment belongs to the issue. 0 refers to the issue itself.
+func getContentHistoryCommentID(ctx *context.Context, issue *models.Issue) int64 {
+	commentID := ctx.QueryInt64("comment_id")
+	if commentID == 0 {
+		return 0
+	}
+	comment, err := models.GetCommentByID(commentID)
+	if err != nil {
+		ctx.NotFoundOrServerError("GetCommentByID", models.IsErrCommentNotExist, err)
+		return 0
+	}
+	if comment.IssueID != issue.ID {
+		ctx.NotFound("CommentNotInIssue", nil)
+		return 0
+	}
+	return commentID
+}
+
+// GetContentHistoryList returns the revisions of the content of an issue or comment
+func GetContentHistoryList(ctx *context.Context) {
+	issue := GetActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+	commentID := getContentHistoryCommentID(ctx, issue)
+	if ctx.Written() {
+		return
+	}
+
+	list, err := models.GetIssueContentHistoryList(issue.ID, commentID)
+	if err != nil {
+		ctx.ServerError("GetIssueContentHistoryList", err)
+		return
+	}
+
+	results := make([]map[string]interface{}, 0, len(list))
+	for _, h := range list {
+		action := ctx.Tr("repo.issues.content_history.edited")
+		if h.IsFirstCreated {
+			action = ctx.Tr("repo.issues.content_history.created")
+		}
+		results = append(results, map[string]interface{}{
+			"id":     h.ID,
+			"avatar": h.Poster.RelAvatarLink(),
+			"name":   h.Poster.GetDisplayName(),
+			"action": action,
+			"time":   string(timeutil.TimeSinceUnix(h.EditedUnix, ctx.Data["Lang"].(string))),
+		})
+	}
+	ctx.JSON(200, map[string]interface{}{
+		"results": results,
+	})
+}
+
+// GetContentHistoryDetail returns the changes of a revision as HTML diff
+func GetContentHistoryDetail(ctx *context.Context) {
+	issue := GetActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	history, err := models.GetIssueContentHistoryByID(ctx.QueryInt64("id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetIssueContentHistoryByID", models.IsErrIssueContentHistoryNotExist, err)
+		return
+	}
+	if history.IssueID != issue.ID {
+		ctx.NotFound("HistoryNotInIssue", nil)
+		return
+	}
+
+	var prevContent string
+	prev, err := history.GetPrevious()
+	if err != nil {
+		ctx.ServerError("GetPrevious", err)
+		return
+	} else if prev != nil {
+		prevContent = prev.ContentText
+	}
+
+	dmp := diffmatchpatch.New()
+	diffs := dmp.DiffCleanupSemantic(dmp.DiffMain(prevContent, history.ContentText, true))
+
+	var diffHTML strings.Builder
+	for _, diff := range diffs {
+		text := html.EscapeString(diff.Text)
+		switch diff.Type {
+		case diffmatchpatch.DiffInsert:
+			diffHTML.WriteString(`<span class="added-code">` + text + `</span>`)
+		case diffmatchpatch.DiffDelete:
+			diffHTML.WriteString(`<span class="removed-code">` + text + `</span>`)
+		default:
+			diffHTML.WriteString(text)
+		}
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"commentID": history.CommentID,
+		"diffHTML":  diffHTML.String(),
+	})
+}
+
+// PurgeContentHistory deletes the revisions of the content of an issue or comment
+func PurgeContentHistory(ctx *context.Context) {
+	issue := GetActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+	commentID := getContentHistoryCommentID(ctx, issue)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.PurgeIssueContentHistory(issue.ID, commentID); err != nil {
+		ctx.ServerError("PurgeIssueContentHistory", err)
+		return
+	}
+	ctx.JSON(200, map[string]interface{}{
+		"ok": true,
+	})
+}
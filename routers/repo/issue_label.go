@@ -87,8 +87,9 @@ func NewLabel(ctx *context.Context, form auth.CreateLabelForm) {
 		Name:        form.Title,
 		Description: form.Description,
 		Color:       form.Color,
+		Priority:    form.Priority,
 	}
-	if err := models.NewLabel(l); err != nil {
+	if err := models.NewLabel(l, ctx.User); err != nil {
 		ctx.ServerError("NewLabel", err)
 		return
 	}
@@ -111,7 +112,8 @@ func UpdateLabel(ctx *context.Context, form auth.CreateLabelForm) {
 	l.Name = form.Title
 	l.Description = form.Description
 	l.Color = form.Color
-	if err := models.UpdateLabel(l); err != nil {
+	l.Priority = form.Priority
+	if err := models.UpdateLabel(l, ctx.User); err != nil {
 		ctx.ServerError("UpdateLabel", err)
 		return
 	}
@@ -120,7 +122,7 @@ func UpdateLabel(ctx *context.Context, form auth.CreateLabelForm) {
 
 // DeleteLabel delete a label
 func DeleteLabel(ctx *context.Context) {
-	if err := models.DeleteLabel(ctx.Repo.Repository.ID, ctx.QueryInt64("id")); err != nil {
+	if err := models.DeleteLabel(ctx.Repo.Repository.ID, ctx.QueryInt64("id"), ctx.User); err != nil {
 		ctx.Flash.Error("DeleteLabel: " + err.Error())
 	} else {
 		ctx.Flash.Success(ctx.Tr("repo.issues.label_deletion_success"))
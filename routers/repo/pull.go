@@ -19,6 +19,7 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
+	issue_template "code.gitea.io/gitea/modules/issue/template"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/pull"
@@ -30,24 +31,38 @@ import (
 )
 
 const (
-	tplFork        base.TplName = "repo/pulls/fork"
-	tplCompareDiff base.TplName = "repo/diff/compare"
-	tplPullCommits base.TplName = "repo/pulls/commits"
-	tplPullFiles   base.TplName = "repo/pulls/files"
+	tplFork          base.TplName = "repo/pulls/fork"
+	tplCompareDiff   base.TplName = "repo/diff/compare"
+	tplPullCommits   base.TplName = "repo/pulls/commits"
+	tplPullFiles     base.TplName = "repo/pulls/files"
+	tplPullConflicts base.TplName = "repo/pulls/conflicts"
 
 	pullRequestTemplateKey = "PullRequestTemplate"
 )
 
-var (
-	pullRequestTemplateCandidates = []string{
-		"PULL_REQUEST_TEMPLATE.md",
-		"pull_request_template.md",
-		".gitea/PULL_REQUEST_TEMPLATE.md",
-		".gitea/pull_request_template.md",
-		".github/PULL_REQUEST_TEMPLATE.md",
-		".github/pull_request_template.md",
+// setPullRequestTemplate prefills the description of a new pull request with
+// the template chosen by the "template" query or the default template.
+func setPullRequestTemplate(ctx *context.Context) {
+	if ctx.Repo.Commit == nil {
+		var err error
+		ctx.Repo.Commit, err = ctx.Repo.GitRepo.GetBranchCommit(ctx.Repo.Repository.DefaultBranch)
+		if err != nil {
+			return
+		}
 	}
-)
+
+	name := ctx.Query("template")
+	content, err := issue_template.GetPullRequestTemplate(ctx.Repo.Commit, name)
+	if err != nil {
+		log.Error("GetPullRequestTemplate: %v", err)
+		return
+	}
+	if len(content) > 0 {
+		ctx.Data[pullRequestTemplateKey] = content
+		ctx.Data["PullRequestTemplateName"] = name
+	}
+	ctx.Data["PullRequestTemplates"] = issue_template.ListPullRequestTemplates(ctx.Repo.Commit)
+}
 
 func getForkRepository(ctx *context.Context) *models.Repository {
 	forkRepo, err := models.GetRepositoryByID(ctx.ParamsInt64(":repoid"))
@@ -322,6 +337,11 @@ func PrepareViewPullInfo(ctx *context.Context, issue *models.Issue) *git.Compare
 
 	setMergeTarget(ctx, pull)
 
+	if ctx.Data["ParentPullRequest"], err = pull.GetParentPullRequest(); err != nil {
+		ctx.ServerError("GetParentPullRequest", err)
+		return nil
+	}
+
 	var headGitRepo *git.Repository
 	var headBranchExist bool
 	// HeadRepo may be missing
@@ -332,12 +352,12 @@ func PrepareViewPullInfo(ctx *context.Context, issue *models.Issue) *git.Compare
 			return nil
 		}
 
-		headBranchExist = headGitRepo.IsBranchExist(pull.HeadBranch)
+		headBranchExist = git.IsReferenceExist(headGitRepo.Path, pull.GetHeadRef())
 
 		if headBranchExist {
-			sha, err := headGitRepo.GetBranchCommitID(pull.HeadBranch)
+			sha, err := headGitRepo.GetRefCommitID(pull.GetHeadRef())
 			if err != nil {
-				ctx.ServerError("GetBranchCommitID", err)
+				ctx.ServerError("GetRefCommitID", err)
 				return nil
 			}
 
@@ -350,6 +370,14 @@ func PrepareViewPullInfo(ctx *context.Context, issue *models.Issue) *git.Compare
 				ctx.Data["LatestCommitStatuses"] = commitStatuses
 				ctx.Data["LatestCommitStatus"] = models.CalcCommitStatus(commitStatuses)
 			}
+			checkRuns, err := models.GetLatestCheckRuns(repo.ID, sha)
+			if err != nil {
+				ctx.ServerError("GetLatestCheckRuns", err)
+				return nil
+			}
+			if len(checkRuns) > 0 {
+				ctx.Data["LatestCheckRuns"] = checkRuns
+			}
 		}
 	}
 
@@ -362,7 +390,7 @@ func PrepareViewPullInfo(ctx *context.Context, issue *models.Issue) *git.Compare
 	}
 
 	compareInfo, err := headGitRepo.GetCompareInfo(models.RepoPath(repo.Owner.Name, repo.Name),
-		pull.BaseBranch, pull.HeadBranch)
+		pull.BaseBranch, pull.GetHeadRef())
 	if err != nil {
 		if strings.Contains(err.Error(), "fatal: Not a valid object name") {
 			ctx.Data["IsPullRequestBroken"] = true
@@ -428,7 +456,7 @@ func ViewPullCommits(ctx *context.Context) {
 	}
 
 	commits = models.ValidateCommitsWithEmails(commits)
-	commits = models.ParseCommitsWithSignature(commits)
+	commits = models.ParseCommitsWithSignature(ctx.Repo.Repository, commits)
 	commits = models.ParseCommitsWithStatus(commits, ctx.Repo.Repository)
 	ctx.Data["Commits"] = commits
 	ctx.Data["CommitCount"] = commits.Len()
@@ -502,9 +530,9 @@ func ViewPullFiles(ctx *context.Context) {
 			return
 		}
 
-		headCommitID, err := headGitRepo.GetBranchCommitID(pull.HeadBranch)
+		headCommitID, err := headGitRepo.GetRefCommitID(pull.GetHeadRef())
 		if err != nil {
-			ctx.ServerError("GetBranchCommitID", err)
+			ctx.ServerError("GetRefCommitID", err)
 			return
 		}
 
@@ -531,6 +559,23 @@ func ViewPullFiles(ctx *context.Context) {
 		ctx.ServerError("LoadComments", err)
 		return
 	}
+	checkRuns, err := models.GetLatestCheckRuns(ctx.Repo.Repository.ID, endCommitID)
+	if err != nil {
+		ctx.ServerError("GetLatestCheckRuns", err)
+		return
+	}
+	annotations, err := models.GetCheckRunAnnotationsByPath(checkRuns)
+	if err != nil {
+		ctx.ServerError("GetCheckRunAnnotationsByPath", err)
+		return
+	}
+	diff.LoadAnnotations(annotations)
+	if ctx.IsSigned {
+		if ctx.Data["ViewedFiles"], err = models.GetPullViewedFiles(ctx.User.ID, pull.ID); err != nil {
+			ctx.ServerError("GetPullViewedFiles", err)
+			return
+		}
+	}
 
 	ctx.Data["Diff"] = diff
 	ctx.Data["DiffNotAvailable"] = diff.NumFiles() == 0
@@ -556,9 +601,274 @@ func ViewPullFiles(ctx *context.Context) {
 		ctx.ServerError("GetCurrentReview", err)
 		return
 	}
+	ctx.Data["CanApplySuggestions"] = canPushToPullHead(ctx, issue, pull)
+	if ctx.Written() {
+		return
+	}
+	if ctx.Data["CanMarkConversation"], err = models.CanMarkConversation(issue, ctx.User); err != nil {
+		ctx.ServerError("CanMarkConversation", err)
+		return
+	}
 	ctx.HTML(200, tplPullFiles)
 }
 
+// imageInfoJSON returns the JSON representation of an image of an image diff
+func imageInfoJSON(ctx *context.Context, info *gitdiff.ImageInfo) map[string]interface{} {
+	if info == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"url":        ctx.Repo.RepoLink + "/raw/commit/" + info.CommitID + "/" + util.PathEscapeSegments(info.Path),
+		"size":       info.Size,
+		"human_size": base.FileSize(info.Size),
+		"width":      info.Width,
+		"height":     info.Height,
+	}
+}
+
+// ViewPullImageDiff returns the images before and after the changes of the
+// pull request to a file, which are compared in the files tab.
+func ViewPullImageDiff(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pull := issue.PullRequest
+
+	treePath := ctx.Query("path")
+	if len(treePath) == 0 {
+		ctx.Error(400)
+		return
+	}
+
+	headCommitID, err := ctx.Repo.GitRepo.GetRefCommitID(pull.GetGitRefName())
+	if err != nil {
+		ctx.ServerError("GetRefCommitID", err)
+		return
+	}
+	mergeBase := pull.MergeBase
+	if !pull.HasMerged {
+		if mergeBase, _, err = ctx.Repo.GitRepo.GetMergeBase("", pull.BaseBranch, headCommitID); err != nil {
+			ctx.ServerError("GetMergeBase", err)
+			return
+		}
+	}
+
+	diff, err := gitdiff.GetImageDiff(ctx.Repo.GitRepo, mergeBase, headCommitID, ctx.Query("old_path"), treePath)
+	if err != nil {
+		ctx.ServerError("GetImageDiff", err)
+		return
+	}
+	if diff.Before == nil && diff.After == nil {
+		ctx.NotFound("GetImageDiff", nil)
+		return
+	}
+
+	delta := diff.SizeDelta()
+	humanDelta := base.FileSize(delta)
+	if delta < 0 {
+		humanDelta = "-" + base.FileSize(-delta)
+	} else if delta > 0 {
+		humanDelta = "+" + humanDelta
+	}
+	ctx.JSON(200, map[string]interface{}{
+		"before":           imageInfoJSON(ctx, diff.Before),
+		"after":            imageInfoJSON(ctx, diff.After),
+		"size_delta":       delta,
+		"human_size_delta": humanDelta,
+	})
+}
+
+// ResolvePullConflicts shows the editor to resolve the conflicts of the pull request
+func ResolvePullConflicts(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+	issueURL := fmt.Sprintf("%s/pulls/%d", ctx.Repo.RepoLink, issue.Index)
+	if !canPushToPullHead(ctx, issue, pr) {
+		if !ctx.Written() {
+			ctx.NotFound("ResolvePullConflicts", nil)
+		}
+		return
+	}
+
+	lastCommitID, conflicts, err := pull.GetConflicts(pr)
+	if err != nil {
+		if git.IsErrBranchNotExist(err) {
+			ctx.NotFound("GetConflicts", nil)
+		} else if models.IsErrMergeConflictNotResolvable(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.resolve_conflicts_not_resolvable", err.(models.ErrMergeConflictNotResolvable).Path))
+			ctx.Redirect(issueURL)
+		} else {
+			ctx.ServerError("GetConflicts", err)
+		}
+		return
+	}
+	if len(conflicts) == 0 {
+		ctx.Flash.Info(ctx.Tr("repo.pulls.resolve_conflicts_none"))
+		ctx.Redirect(issueURL)
+		return
+	}
+
+	ctx.Data["Title"] = ctx.Tr("repo.pulls.resolve_conflicts")
+	ctx.Data["PageIsPullList"] = true
+	ctx.Data["IssueLink"] = issueURL
+	ctx.Data["LastCommitID"] = lastCommitID
+	ctx.Data["ConflictFiles"] = conflicts
+	ctx.Data["DefaultCommitMessage"] = pull.GetDefaultConflictResolutionMessage(pr)
+	ctx.HTML(200, tplPullConflicts)
+}
+
+// ResolvePullConflictsPost commits the resolved conflicts to the head branch of the pull request
+func ResolvePullConflictsPost(ctx *context.Context, form auth.ResolveConflictsForm) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+	issueURL := fmt.Sprintf("%s/pulls/%d", ctx.Repo.RepoLink, issue.Index)
+	if !canPushToPullHead(ctx, issue, pr) {
+		if !ctx.Written() {
+			ctx.NotFound("ResolvePullConflictsPost", nil)
+		}
+		return
+	}
+	if ctx.HasError() || len(form.TreePaths) != len(form.Contents) {
+		ctx.Error(400)
+		return
+	}
+
+	files := make(map[string]string, len(form.TreePaths))
+	for i, treePath := range form.TreePaths {
+		// Browsers submit textarea content with CRLF line endings
+		files[treePath] = strings.Replace(form.Contents[i], "\r\n", "\n", -1)
+	}
+	message := strings.TrimSpace(form.CommitMessage)
+	if len(message) == 0 {
+		message = pull.GetDefaultConflictResolutionMessage(pr)
+	}
+
+	if err := pull.ResolveConflicts(pr, ctx.User, form.LastCommitID, message, files); err != nil {
+		switch {
+		case git.IsErrBranchNotExist(err):
+			ctx.NotFound("ResolveConflicts", nil)
+			return
+		case models.IsErrCommitIDDoesNotMatch(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.resolve_conflicts_outdated"))
+		case models.IsErrUserCannotCommit(err):
+			ctx.Flash.Error(ctx.Tr("repo.editor.cannot_commit_to_protected_branch", pr.HeadBranch))
+		case models.IsErrMergeConflictNotResolvable(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.resolve_conflicts_not_resolvable", err.(models.ErrMergeConflictNotResolvable).Path))
+			ctx.Redirect(issueURL)
+			return
+		case models.IsErrMergeConflictNotResolved(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.resolve_conflicts_not_resolved", err.(models.ErrMergeConflictNotResolved).Path))
+		default:
+			ctx.ServerError("ResolveConflicts", err)
+			return
+		}
+		ctx.Redirect(issueURL + "/conflicts")
+		return
+	}
+
+	log.Trace("Pull request conflicts resolved: %d", pr.ID)
+	ctx.Flash.Success(ctx.Tr("repo.pulls.resolve_conflicts_success"))
+	ctx.Redirect(issueURL)
+}
+
+// UpdatePullRequest updates the head branch of the pull request with the
+// commits of its base branch by merging or rebasing
+func UpdatePullRequest(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+	issueURL := fmt.Sprintf("%s/pulls/%d", ctx.Repo.RepoLink, issue.Index)
+	if !canPushToPullHead(ctx, issue, pr) {
+		if !ctx.Written() {
+			ctx.NotFound("UpdatePullRequest", nil)
+		}
+		return
+	}
+
+	style := models.MergeStyle(ctx.Query("style"))
+	if len(style) == 0 {
+		style = models.MergeStyleMerge
+	}
+	if err := pull.Update(pr, ctx.User, style); err != nil {
+		switch {
+		case models.IsErrInvalidMergeStyle(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
+		case models.IsErrPullRequestUpToDate(err):
+			ctx.Flash.Info(ctx.Tr("repo.pulls.update_branch_up_to_date"))
+		case models.IsErrUserCannotCommit(err):
+			ctx.Flash.Error(ctx.Tr("repo.editor.cannot_commit_to_protected_branch", pr.HeadBranch))
+		case models.IsErrMergeConflicts(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.update_branch_conflicts"))
+		case models.IsErrRebaseConflicts(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.update_branch_rebase_conflicts"))
+		default:
+			ctx.ServerError("Update", err)
+			return
+		}
+		ctx.Redirect(issueURL)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.pulls.update_branch_success"))
+	ctx.Redirect(issueURL)
+}
+
+// RevertPullRequest reverts the changes of a merged pull request on a new
+// branch and proposes them with a new pull request
+func RevertPullRequest(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+	issueURL := fmt.Sprintf("%s/pulls/%d", ctx.Repo.RepoLink, issue.Index)
+	if !pr.HasMerged {
+		ctx.NotFound("RevertPullRequest", nil)
+		return
+	}
+
+	// Find an unused branch name for the revert
+	branchName := fmt.Sprintf("revert-%d", pr.Index)
+	for i := 2; ; i++ {
+		if _, err := ctx.Repo.Repository.GetBranch(branchName); err != nil {
+			if git.IsErrBranchNotExist(err) {
+				break
+			}
+			ctx.ServerError("GetBranch", err)
+			return
+		}
+		branchName = fmt.Sprintf("revert-%d-%d", pr.Index, i)
+	}
+
+	if err := pull.Revert(ctx.User, pr, branchName); err != nil {
+		switch {
+		case models.IsErrCherryPickConflicts(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.revert_conflicts", strings.Join(err.(models.ErrCherryPickConflicts).Paths, ", ")))
+		case models.IsErrCherryPickNotPossible(err):
+			ctx.Flash.Error(ctx.Tr("repo.editor.revert_not_possible", err.(models.ErrCherryPickNotPossible).Reason))
+		case git.IsErrBranchNotExist(err):
+			ctx.Flash.Error(ctx.Tr("repo.editor.branch_does_not_exist", err.(git.ErrBranchNotExist).Name))
+		default:
+			ctx.ServerError("Revert", err)
+			return
+		}
+		ctx.Redirect(issueURL)
+		return
+	}
+
+	log.Trace("Pull request reverted: %d on branch %s", pr.ID, branchName)
+	ctx.Redirect(ctx.Repo.RepoLink + "/compare/" + util.PathEscapeSegments(pr.BaseBranch) + "..." + util.PathEscapeSegments(branchName))
+}
+
 // MergePullRequest response for merging pull request
 func MergePullRequest(ctx *context.Context, form auth.MergePullRequestForm) {
 	issue := checkPullInfo(ctx)
@@ -572,7 +882,7 @@ func MergePullRequest(ctx *context.Context, form auth.MergePullRequestForm) {
 
 	pr := issue.PullRequest
 
-	if !pr.CanAutoMerge() || pr.HasMerged {
+	if pr.HasMerged || (!pr.CanAutoMerge() && !form.MergeWhenChecksSucceed) {
 		ctx.NotFound("MergePullRequest", nil)
 		return
 	}
@@ -583,12 +893,47 @@ func MergePullRequest(ctx *context.Context, form auth.MergePullRequestForm) {
 		return
 	}
 
+	if pr.IsDraft {
+		ctx.Flash.Error(ctx.Tr("repo.pulls.no_merge_draft"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+		return
+	}
+
 	if ctx.HasError() {
 		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
 		ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
 		return
 	}
 
+	if form.MergeWhenChecksSucceed {
+		// The default message is generated when the pull request is merged
+		message := strings.TrimSpace(form.MergeTitleField)
+		form.MergeMessageField = strings.TrimSpace(form.MergeMessageField)
+		if len(message) > 0 && len(form.MergeMessageField) > 0 {
+			message += "\n\n" + form.MergeMessageField
+		}
+
+		pr.Issue = issue
+		pr.Issue.Repo = ctx.Repo.Repository
+		if err := pull.ScheduleAutoMerge(ctx.User, pr, models.MergeStyle(form.Do), message); err != nil {
+			if models.IsErrInvalidMergeStyle(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
+				ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+				return
+			} else if models.IsErrNotAllowedToMerge(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.auto_merge_not_allowed"))
+				ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+				return
+			} else if !models.IsErrPullAutoMergeAlreadyScheduled(err) {
+				ctx.ServerError("ScheduleAutoMerge", err)
+				return
+			}
+		}
+		ctx.Flash.Success(ctx.Tr("repo.pulls.auto_merge_scheduled"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+		return
+	}
+
 	message := strings.TrimSpace(form.MergeTitleField)
 	if len(message) == 0 {
 		if models.MergeStyle(form.Do) == models.MergeStyleMerge {
@@ -621,13 +966,49 @@ func MergePullRequest(ctx *context.Context, form auth.MergePullRequestForm) {
 		return
 	}
 
+	if err = pr.LoadProtectedBranch(); err != nil {
+		ctx.ServerError("LoadProtectedBranch", err)
+		return
+	}
+	if pr.ProtectedBranch != nil && pr.ProtectedBranch.EnableMergeQueue {
+		if _, err = pull.AddToMergeQueue(pr, ctx.User, models.MergeStyle(form.Do), message); err != nil {
+			if models.IsErrInvalidMergeStyle(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
+				ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+				return
+			} else if !models.IsErrMergeQueueEntryAlreadyExist(err) {
+				ctx.ServerError("AddToMergeQueue", err)
+				return
+			}
+		}
+		ctx.Flash.Success(ctx.Tr("repo.pulls.merge_queue_added"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+		return
+	}
+
 	if err = pull.Merge(pr, ctx.User, ctx.Repo.GitRepo, models.MergeStyle(form.Do), message); err != nil {
-		if models.IsErrInvalidMergeStyle(err) {
+		switch {
+		case models.IsErrInvalidMergeStyle(err):
 			ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
-			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+		case models.IsErrMergeConflicts(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.merge_conflict"))
+		case models.IsErrRebaseConflicts(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.rebase_conflict"))
+		case models.IsErrMergeNotFastForward(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.merge_not_fast_forward"))
+		case models.IsErrRequiredStatusChecksMissing(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.required_status_checks_missing", strings.Join(err.(models.ErrRequiredStatusChecksMissing).Contexts, ", ")))
+		case models.IsErrPullRequestSizeExceeded(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.size_limits_exceeded"))
+		case models.IsErrUnresolvedConversations(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.unresolved_conversations", err.(models.ErrUnresolvedConversations).Count))
+		case models.IsErrPullRequestOutOfDate(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.out_of_date", err.(models.ErrPullRequestOutOfDate).Behind))
+		default:
+			ctx.ServerError("Merge", err)
 			return
 		}
-		ctx.ServerError("Merge", err)
+		ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
 		return
 	}
 
@@ -642,6 +1023,81 @@ func MergePullRequest(ctx *context.Context, form auth.MergePullRequestForm) {
 	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
 }
 
+// RemoveFromMergeQueue removes the pull request from the merge queue
+func RemoveFromMergeQueue(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+
+	entry, err := models.GetMergeQueueEntryByPullID(pr.ID)
+	if err != nil {
+		if models.IsErrMergeQueueEntryNotExist(err) {
+			ctx.NotFound("GetMergeQueueEntryByPullID", nil)
+		} else {
+			ctx.ServerError("GetMergeQueueEntryByPullID", err)
+		}
+		return
+	}
+
+	// The user who queued the pull request and writers can remove it
+	if entry.DoerID != ctx.User.ID && !ctx.Repo.CanWrite(models.UnitTypeCode) {
+		ctx.NotFound("RemoveFromMergeQueue", nil)
+		return
+	}
+
+	if err = pull.RemoveFromMergeQueue(pr); err != nil && !models.IsErrMergeQueueEntryNotExist(err) {
+		ctx.ServerError("RemoveFromMergeQueue", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.pulls.merge_queue_removed"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+}
+
+// CancelAutoMerge cancels the scheduled automatic merge of the pull request
+func CancelAutoMerge(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+
+	autoMerge, err := models.GetScheduledAutoMerge(pr.ID)
+	if err != nil {
+		if models.IsErrPullAutoMergeNotExist(err) {
+			ctx.NotFound("GetScheduledAutoMerge", nil)
+		} else {
+			ctx.ServerError("GetScheduledAutoMerge", err)
+		}
+		return
+	}
+
+	// The user who scheduled the merge and writers can cancel it
+	if autoMerge.DoerID != ctx.User.ID && !ctx.Repo.CanWrite(models.UnitTypeCode) {
+		ctx.NotFound("CancelAutoMerge", nil)
+		return
+	}
+
+	if err = pull.CancelAutoMerge(pr); err != nil && !models.IsErrPullAutoMergeNotExist(err) {
+		ctx.ServerError("CancelAutoMerge", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.pulls.auto_merge_canceled"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+}
+
+// splitMergeMessage splits a commit message into its title and its body
+func splitMergeMessage(message string) (string, string) {
+	parts := strings.SplitN(message, "\n", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+}
+
 func stopTimerIfAvailable(user *models.User, issue *models.Issue) error {
 
 	if models.StopwatchExists(user.ID, issue.ID) {
@@ -721,15 +1177,17 @@ func CompareAndPullRequestPost(ctx *context.Context, form auth.CreateIssueForm)
 		Content:     form.Content,
 	}
 	pullRequest := &models.PullRequest{
-		HeadRepoID:   headRepo.ID,
-		BaseRepoID:   repo.ID,
-		HeadUserName: headUser.Name,
-		HeadBranch:   headBranch,
-		BaseBranch:   baseBranch,
-		HeadRepo:     headRepo,
-		BaseRepo:     repo,
-		MergeBase:    prInfo.MergeBase,
-		Type:         models.PullRequestGitea,
+		HeadRepoID:          headRepo.ID,
+		BaseRepoID:          repo.ID,
+		HeadUserName:        headUser.Name,
+		HeadBranch:          headBranch,
+		BaseBranch:          baseBranch,
+		HeadRepo:            headRepo,
+		BaseRepo:            repo,
+		MergeBase:           prInfo.MergeBase,
+		Type:                models.PullRequestGitea,
+		IsDraft:             form.Draft,
+		AllowMaintainerEdit: form.AllowMaintainerEdit && headRepo.ID != repo.ID,
 	}
 	// FIXME: check error in the case two people send pull request at almost same time, give nice error prompt
 	// instead of 500.
@@ -747,11 +1205,86 @@ func CompareAndPullRequestPost(ctx *context.Context, form auth.CreateIssueForm)
 	}
 
 	notification.NotifyNewPullRequest(pullRequest)
+	if err := pull.RequestCodeOwnerReviews(pullRequest, ctx.User); err != nil {
+		log.Error("RequestCodeOwnerReviews: %v", err)
+	}
+	if err := pullRequest.ApplyPathLabels(ctx.User); err != nil {
+		log.Error("ApplyPathLabels: %v", err)
+	}
+	if err := pullRequest.LintCommitMessages(ctx.User); err != nil {
+		log.Error("LintCommitMessages: %v", err)
+	}
+	if err := pullRequest.AssignTeamReviewers(ctx.User); err != nil {
+		log.Error("AssignTeamReviewers: %v", err)
+	}
 
 	log.Trace("Pull request created: %d/%d", repo.ID, pullIssue.ID)
 	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pullIssue.Index))
 }
 
+// MarkPullReadyForReview marks a draft or work in progress pull request as
+// ready for review, the work in progress prefix is removed from its title
+func MarkPullReadyForReview(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !issue.IsPoster(ctx.User.ID) && !ctx.Repo.CanWrite(models.UnitTypePullRequests) {
+		ctx.Error(403)
+		return
+	}
+
+	pr := issue.PullRequest
+	issue.Repo = ctx.Repo.Repository
+	pr.Issue = issue
+	if pr.IsDraft {
+		if err := pr.ChangeDraft(false); err != nil {
+			ctx.ServerError("ChangeDraft", err)
+			return
+		}
+		notification.NotifyPullRequestReadyForReview(ctx.User, pr)
+		if err := pr.AssignTeamReviewers(ctx.User); err != nil {
+			log.Error("AssignTeamReviewers: %v", err)
+		}
+	}
+
+	// The title is not changed if only the prefix would be left
+	if oldTitle, err := pr.RemoveWorkInProgressPrefix(ctx.User); err != nil {
+		if !models.IsErrIssueTitleEmpty(err) {
+			ctx.ServerError("RemoveWorkInProgressPrefix", err)
+			return
+		}
+		ctx.Flash.Error(ctx.Tr("repo.pulls.remove_wip_prefix_empty_title"))
+	} else if len(oldTitle) > 0 {
+		notification.NotifyIssueChangeTitle(ctx.User, issue, oldTitle)
+	}
+	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+}
+
+// SetAllowMaintainerEdit allows or disallows users who can write to the base
+// repository to push to the head branch of the pull request
+func SetAllowMaintainerEdit(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !issue.IsPoster(ctx.User.ID) {
+		ctx.Error(403)
+		return
+	}
+
+	pr := issue.PullRequest
+	if pr.HeadRepoID == pr.BaseRepoID {
+		ctx.NotFound("SetAllowMaintainerEdit", nil)
+		return
+	}
+	if err := pr.ChangeAllowMaintainerEdit(ctx.QueryBool("allow_maintainer_edit")); err != nil {
+		ctx.ServerError("ChangeAllowMaintainerEdit", err)
+		return
+	}
+	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+}
+
 // TriggerTask response for a trigger task request
 func TriggerTask(ctx *context.Context) {
 	pusherID := ctx.QueryInt64("pusher")
@@ -973,7 +1506,7 @@ func DownloadPullPatch(ctx *context.Context) {
 		return
 	}
 
-	patch, err := headGitRepo.GetFormatPatch(pr.MergeBase, pr.HeadBranch)
+	patch, err := headGitRepo.GetFormatPatch(pr.MergeBase, pr.GetHeadRef())
 	if err != nil {
 		ctx.ServerError("GetFormatPatch", err)
 		return
@@ -6,6 +6,8 @@ package repo
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/auth"
@@ -40,8 +42,10 @@ func CreateCodeComment(ctx *context.Context, form auth.CodeCommentForm) {
 		}
 	}()
 	signedLine := form.Line
+	signedStartLine := form.StartLine
 	if form.Side == "previous" {
 		signedLine *= -1
+		signedStartLine *= -1
 	}
 
 	review := new(models.Review)
@@ -76,10 +80,15 @@ func CreateCodeComment(ctx *context.Context, form auth.CodeCommentForm) {
 		issue,
 		form.Content,
 		form.TreePath,
+		signedStartLine,
 		signedLine,
 		review.ID,
 	)
 	if err != nil {
+		if models.IsErrInvalidCodeCommentRange(err) {
+			ctx.Flash.Error(ctx.Tr("repo.diff.comment.invalid_range"))
+			return
+		}
 		ctx.ServerError("CreateCodeComment", err)
 		return
 	}
@@ -91,6 +100,54 @@ func CreateCodeComment(ctx *context.Context, form auth.CodeCommentForm) {
 	log.Trace("Comment created: %d/%d/%d", ctx.Repo.Repository.ID, issue.ID, comment.ID)
 }
 
+// ResolveConversation marks the conversation of a code comment as resolved
+func ResolveConversation(ctx *context.Context) {
+	markConversation(ctx, true)
+}
+
+// UnresolveConversation marks the conversation of a code comment as unresolved
+func UnresolveConversation(ctx *context.Context) {
+	markConversation(ctx, false)
+}
+
+func markConversation(ctx *context.Context, isResolve bool) {
+	issue := GetActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !issue.IsPull {
+		ctx.NotFound("MarkConversation", nil)
+		return
+	}
+
+	comment, err := models.GetCommentByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrCommentNotExist(err) {
+			ctx.NotFound("GetCommentByID", err)
+		} else {
+			ctx.ServerError("GetCommentByID", err)
+		}
+		return
+	}
+	if comment.IssueID != issue.ID || comment.Type != models.CommentTypeCode {
+		ctx.NotFound("MarkConversation", nil)
+		return
+	}
+	if canMark, err := models.CanMarkConversation(issue, ctx.User); err != nil {
+		ctx.ServerError("CanMarkConversation", err)
+		return
+	} else if !canMark {
+		ctx.Error(403)
+		return
+	}
+
+	if err = models.MarkConversation(comment, ctx.User, isResolve); err != nil {
+		ctx.ServerError("MarkConversation", err)
+		return
+	}
+	ctx.Redirect(comment.CodeCommentURL())
+}
+
 // SubmitReview creates a review out of the existing pending review or creates a new one if no pending review exist
 func SubmitReview(ctx *context.Context, form auth.SubmitReviewForm) {
 	issue := GetActionIssue(ctx)
@@ -105,9 +162,6 @@ func SubmitReview(ctx *context.Context, form auth.SubmitReviewForm) {
 		ctx.Redirect(fmt.Sprintf("%s/pulls/%d/files", ctx.Repo.RepoLink, issue.Index))
 		return
 	}
-	var review *models.Review
-	var err error
-
 	reviewType := form.ReviewType()
 
 	switch reviewType {
@@ -134,69 +188,190 @@ func SubmitReview(ctx *context.Context, form auth.SubmitReviewForm) {
 		}
 	}
 
-	review, err = models.GetCurrentReview(ctx.User, issue)
-	if err == nil {
-		review.Issue = issue
-		if errl := review.LoadCodeComments(); errl != nil {
-			ctx.ServerError("LoadCodeComments", err)
+	review, comm, err := pull_service.SubmitReview(ctx.User, issue, reviewType, form.Content)
+	if err != nil {
+		if models.IsErrReviewEmpty(err) {
+			ctx.Flash.Error(ctx.Tr("repo.issues.review.content.empty"))
+			ctx.Redirect(fmt.Sprintf("%s/pulls/%d/files", ctx.Repo.RepoLink, issue.Index))
 			return
 		}
+		ctx.ServerError("SubmitReview", err)
+		return
 	}
 
-	if ((err == nil && len(review.CodeComments) == 0) ||
-		(err != nil && models.IsErrReviewNotExist(err))) &&
-		form.HasEmptyContent() {
-		ctx.Flash.Error(ctx.Tr("repo.issues.review.content.empty"))
-		ctx.Redirect(fmt.Sprintf("%s/pulls/%d/files", ctx.Repo.RepoLink, issue.Index))
+	pr, err := issue.GetPullRequest()
+	if err != nil {
+		ctx.ServerError("GetPullRequest", err)
 		return
 	}
+	notification.NotifyPullRequestReview(pr, review, comm)
 
+	ctx.Redirect(fmt.Sprintf("%s/pulls/%d#%s", ctx.Repo.RepoLink, issue.Index, comm.HashTag()))
+}
+
+// canPushToPullHead returns true if the doer can commit suggestions or
+// conflict resolutions to the head branch of the open pull request.
+func canPushToPullHead(ctx *context.Context, issue *models.Issue, pr *models.PullRequest) bool {
+	// The head of AGit pull requests is not a branch
+	if !ctx.IsSigned || issue.IsClosed || pr.HasMerged || ctx.Repo.Repository.IsArchived || pr.Flow == models.PullRequestFlowAGit {
+		return false
+	}
+	if err := pr.GetHeadRepo(); err != nil {
+		ctx.ServerError("GetHeadRepo", err)
+		return false
+	} else if pr.HeadRepo == nil || pr.HeadRepo.IsArchived {
+		return false
+	}
+	perm, err := models.GetUserRepoPermission(pr.HeadRepo, ctx.User)
+	if err != nil {
+		ctx.ServerError("GetUserRepoPermission", err)
+		return false
+	}
+	if perm.CanWrite(models.UnitTypeCode) {
+		return true
+	}
+	canWrite, err := models.CanMaintainerWriteToBranch(pr.HeadRepo, pr.HeadBranch, ctx.User)
 	if err != nil {
-		if !models.IsErrReviewNotExist(err) {
-			ctx.ServerError("GetCurrentReview", err)
+		ctx.ServerError("CanMaintainerWriteToBranch", err)
+		return false
+	}
+	return canWrite
+}
+
+// ApplySuggestions commits the suggestions of code comments to the head branch of the pull request
+func ApplySuggestions(ctx *context.Context, form auth.ApplySuggestionsForm) {
+	issue := GetActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !issue.IsPull {
+		ctx.NotFound("ApplySuggestions", nil)
+		return
+	}
+	filesURL := fmt.Sprintf("%s/pulls/%d/files", ctx.Repo.RepoLink, issue.Index)
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(filesURL)
+		return
+	}
+
+	pr, err := issue.GetPullRequest()
+	if err != nil {
+		ctx.ServerError("GetPullRequest", err)
+		return
+	}
+	if !canPushToPullHead(ctx, issue, pr) {
+		if !ctx.Written() {
+			ctx.NotFound("ApplySuggestions", nil)
+		}
+		return
+	}
+
+	var comments []*models.Comment
+	for _, id := range strings.Split(form.CommentIDs, ",") {
+		commentID, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64)
+		if err != nil {
+			ctx.NotFound("ApplySuggestions", nil)
+			return
+		}
+		comment, err := models.GetCommentByID(commentID)
+		if err != nil {
+			if models.IsErrCommentNotExist(err) {
+				ctx.NotFound("ApplySuggestions", nil)
+			} else {
+				ctx.ServerError("GetCommentByID", err)
+			}
+			return
+		}
+		if comment.IssueID != issue.ID || comment.Type != models.CommentTypeCode {
+			ctx.NotFound("ApplySuggestions", nil)
 			return
 		}
-		// No current review. Create a new one!
-		if review, err = models.CreateReview(models.CreateReviewOptions{
-			Type:     reviewType,
-			Issue:    issue,
-			Reviewer: ctx.User,
-			Content:  form.Content,
-		}); err != nil {
-			ctx.ServerError("CreateReview", err)
+		if err = comment.LoadReview(); err != nil && !models.IsErrReviewNotExist(err) {
+			ctx.ServerError("LoadReview", err)
 			return
 		}
-	} else {
-		review.Content = form.Content
-		review.Type = reviewType
-		if err = models.UpdateReview(review); err != nil {
-			ctx.ServerError("UpdateReview", err)
+		// Pending comments are only visible to their reviewer
+		if comment.Review != nil && comment.Review.Type == models.ReviewTypePending && comment.Review.ReviewerID != ctx.User.ID {
+			ctx.NotFound("ApplySuggestions", nil)
+			return
+		}
+		if err = comment.LoadPoster(); err != nil {
+			ctx.ServerError("LoadPoster", err)
+			return
+		}
+		comments = append(comments, comment)
+	}
+
+	if err = pull_service.ApplySuggestions(ctx.User, pr, comments, form.Message); err != nil {
+		if models.IsErrSuggestionNotApplicable(err) {
+			ctx.Flash.Error(ctx.Tr("repo.diff.suggestion.not_applicable", err.(models.ErrSuggestionNotApplicable).Reason))
+			ctx.Redirect(filesURL)
 			return
 		}
+		ctx.ServerError("ApplySuggestions", err)
+		return
 	}
-	comm, err := models.CreateComment(&models.CreateCommentOptions{
-		Type:     models.CommentTypeReview,
-		Doer:     ctx.User,
-		Content:  review.Content,
-		Issue:    issue,
-		Repo:     issue.Repo,
-		ReviewID: review.ID,
-	})
-	if err != nil || comm == nil {
-		ctx.ServerError("CreateComment", err)
+
+	log.Trace("Suggestions applied: %d/%d", ctx.Repo.Repository.ID, issue.ID)
+	ctx.Flash.Success(ctx.Tr("repo.diff.suggestion.applied"))
+	ctx.Redirect(filesURL)
+}
+
+// ReRequestReview requests a review of the pull request again from a reviewer who has already approved or rejected it
+func ReRequestReview(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
 		return
 	}
-	if err = review.Publish(); err != nil {
-		ctx.ServerError("Publish", err)
+	issueURL := fmt.Sprintf("%s/pulls/%d", ctx.Repo.RepoLink, issue.Index)
+
+	// The poster and writers can request reviews
+	if issue.PosterID != ctx.User.ID && !ctx.Repo.CanWrite(models.UnitTypePullRequests) {
+		ctx.NotFound("ReRequestReview", nil)
 		return
 	}
 
-	pr, err := issue.GetPullRequest()
+	reviewer, err := models.GetUserByID(ctx.QueryInt64("reviewer_id"))
 	if err != nil {
-		ctx.ServerError("GetPullRequest", err)
+		if models.IsErrUserNotExist(err) {
+			ctx.NotFound("GetUserByID", nil)
+		} else {
+			ctx.ServerError("GetUserByID", err)
+		}
 		return
 	}
-	notification.NotifyPullRequestReview(pr, review, comm)
 
-	ctx.Redirect(fmt.Sprintf("%s/pulls/%d#%s", ctx.Repo.RepoLink, issue.Index, comm.HashTag()))
+	if _, err = pull_service.ReRequestReview(ctx.User, issue, reviewer); err != nil {
+		if models.IsErrCannotReRequestReview(err) {
+			ctx.Flash.Error(ctx.Tr("repo.issues.review.rerequest_not_allowed", reviewer.GetDisplayName()))
+			ctx.Redirect(issueURL)
+			return
+		}
+		ctx.ServerError("ReRequestReview", err)
+		return
+	}
+
+	log.Trace("Review re-requested: %d/%d from %d", ctx.Repo.Repository.ID, issue.ID, reviewer.ID)
+	ctx.Flash.Success(ctx.Tr("repo.issues.review.rerequested", reviewer.GetDisplayName()))
+	ctx.Redirect(issueURL)
+}
+
+// MarkPullFileViewed marks a changed file of the pull request as viewed or not viewed by the user
+func MarkPullFileViewed(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	treePath := ctx.Query("path")
+	if len(treePath) == 0 {
+		ctx.Error(400)
+		return
+	}
+	if err := models.SetPullFileViewed(ctx.User.ID, issue.PullRequest.ID, treePath, ctx.Query("diff_hash"), ctx.QueryBool("viewed")); err != nil {
+		ctx.ServerError("SetPullFileViewed", err)
+		return
+	}
+	ctx.Status(204)
 }
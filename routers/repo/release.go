@@ -50,6 +50,22 @@ func calReleaseNumCommitsBehind(repoCtx *context.Repository, release *models.Rel
 	return nil
 }
 
+// calReleaseVerification verifies the signature of the tag of the release if
+// it is a signed annotated tag.
+func calReleaseVerification(repoCtx *context.Repository, release *models.Release) error {
+	if release.IsDraft || !repoCtx.GitRepo.IsTagExist(release.TagName) {
+		return nil
+	}
+	tag, err := repoCtx.GitRepo.GetTag(release.TagName)
+	if err != nil {
+		return fmt.Errorf("GetTag: %v", err)
+	}
+	if tag.Signature != nil {
+		release.Verification = models.ParseTagWithSignature(repoCtx.Repository, tag)
+	}
+	return nil
+}
+
 // Releases render releases list page
 func Releases(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.release.releases")
@@ -115,6 +131,10 @@ func Releases(ctx *context.Context) {
 			ctx.ServerError("calReleaseNumCommitsBehind", err)
 			return
 		}
+		if err := calReleaseVerification(ctx.Repo, r); err != nil {
+			ctx.ServerError("calReleaseVerification", err)
+			return
+		}
 		r.Note = markdown.RenderString(r.Note, ctx.Repo.RepoLink, ctx.Repo.Repository.ComposeMetas())
 	}
 
@@ -182,6 +202,8 @@ func NewReleasePost(ctx *context.Context, form auth.NewReleaseForm) {
 				ctx.RenderWithErr(ctx.Tr("repo.release.tag_name_already_exist"), tplReleaseNew, &form)
 			case models.IsErrInvalidTagName(err):
 				ctx.RenderWithErr(ctx.Tr("repo.release.tag_name_invalid"), tplReleaseNew, &form)
+			case models.IsErrProtectedTagName(err):
+				ctx.RenderWithErr(ctx.Tr("repo.release.tag_name_protected"), tplReleaseNew, &form)
 			default:
 				ctx.ServerError("CreateRelease", err)
 			}
@@ -204,7 +226,11 @@ func NewReleasePost(ctx *context.Context, form auth.NewReleaseForm) {
 
 		if err = models.UpdateRelease(ctx.User, ctx.Repo.GitRepo, rel, attachmentUUIDs); err != nil {
 			ctx.Data["Err_TagName"] = true
-			ctx.ServerError("UpdateRelease", err)
+			if models.IsErrProtectedTagName(err) {
+				ctx.RenderWithErr(ctx.Tr("repo.release.tag_name_protected"), tplReleaseNew, &form)
+			} else {
+				ctx.ServerError("UpdateRelease", err)
+			}
 			return
 		}
 	}
@@ -282,7 +308,11 @@ func EditReleasePost(ctx *context.Context, form auth.EditReleaseForm) {
 	rel.IsDraft = len(form.Draft) > 0
 	rel.IsPrerelease = form.Prerelease
 	if err = models.UpdateRelease(ctx.User, ctx.Repo.GitRepo, rel, attachmentUUIDs); err != nil {
-		ctx.ServerError("UpdateRelease", err)
+		if models.IsErrProtectedTagName(err) {
+			ctx.RenderWithErr(ctx.Tr("repo.release.tag_name_protected"), tplReleaseNew, &form)
+		} else {
+			ctx.ServerError("UpdateRelease", err)
+		}
 		return
 	}
 	ctx.Redirect(ctx.Repo.RepoLink + "/releases")
@@ -291,7 +321,11 @@ func EditReleasePost(ctx *context.Context, form auth.EditReleaseForm) {
 // DeleteRelease delete a release
 func DeleteRelease(ctx *context.Context) {
 	if err := models.DeleteReleaseByID(ctx.QueryInt64("id"), ctx.User, true); err != nil {
-		ctx.Flash.Error("DeleteReleaseByID: " + err.Error())
+		if models.IsErrProtectedTagName(err) {
+			ctx.Flash.Error(ctx.Tr("repo.release.tag_name_protected"))
+		} else {
+			ctx.Flash.Error("DeleteReleaseByID: " + err.Error())
+		}
 	} else {
 		ctx.Flash.Success(ctx.Tr("repo.release.deletion_success"))
 	}
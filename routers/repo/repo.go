@@ -6,8 +6,10 @@ package repo
 
 import (
 	"fmt"
+	"mime"
 	"os"
 	"path"
+	"regexp"
 	"strings"
 
 	"code.gitea.io/gitea/models"
@@ -44,6 +46,14 @@ func MustBeEditable(ctx *context.Context) {
 	}
 }
 
+// MustBeAbleToEditOrFork check that the user can write to the repo or can
+// propose changes to it from a fork
+func MustBeAbleToEditOrFork(ctx *context.Context) {
+	if !ctx.Repo.CanWrite(models.UnitTypeCode) && !ctx.Repo.CanProposeByFork(ctx.User) {
+		ctx.NotFound(ctx.Req.RequestURI, nil)
+	}
+}
+
 // MustBeAbleToUpload check that repo can be uploaded to
 func MustBeAbleToUpload(ctx *context.Context) {
 	if !setting.Repository.Upload.Enabled {
@@ -92,19 +102,6 @@ func checkContextUser(ctx *context.Context, uid int64) *models.User {
 	return org
 }
 
-func getRepoPrivate(ctx *context.Context) bool {
-	switch strings.ToLower(setting.Repository.DefaultPrivate) {
-	case setting.RepoCreatingLastUserVisibility:
-		return ctx.User.LastRepoVisibility
-	case setting.RepoCreatingPrivate:
-		return true
-	case setting.RepoCreatingPublic:
-		return false
-	default:
-		return ctx.User.LastRepoVisibility
-	}
-}
-
 // Create render creating repository page
 func Create(ctx *context.Context) {
 	if !ctx.User.CanCreateRepo() {
@@ -118,7 +115,6 @@ func Create(ctx *context.Context) {
 	ctx.Data["Licenses"] = models.Licenses
 	ctx.Data["Readmes"] = models.Readmes
 	ctx.Data["readme"] = "Default"
-	ctx.Data["private"] = getRepoPrivate(ctx)
 	ctx.Data["IsForcedPrivate"] = setting.Repository.ForcePrivate
 
 	ctxUser := checkContextUser(ctx, ctx.QueryInt64("org"))
@@ -127,9 +123,78 @@ func Create(ctx *context.Context) {
 	}
 	ctx.Data["ContextUser"] = ctxUser
 
+	defaults := models.GetRepoCreationDefaults(ctx.User, ctxUser)
+	ctx.Data["private"] = defaults.IsPrivate
+	ctx.Data["auto_init"] = defaults.AutoInit
+	ctx.Data["gitignores"] = defaults.Gitignores
+	ctx.Data["license"] = defaults.License
+	ctx.Data["DefaultBranchPlaceholder"] = defaults.DefaultBranch
+
+	loadRepoTemplates(ctx)
+	if ctx.Written() {
+		return
+	}
+	if templateID := ctx.QueryInt64("template_id"); templateID > 0 {
+		ctx.Data["repo_template"] = templateID
+		ctx.Data["git_content"] = true
+	}
+
 	ctx.HTML(200, tplCreate)
 }
 
+// loadRepoTemplates lists the template repositories the user can generate
+// repositories from
+func loadRepoTemplates(ctx *context.Context) {
+	templates, _, err := models.SearchRepositoryByName(&models.SearchRepoOptions{
+		UserID:   ctx.User.ID,
+		Private:  true,
+		Template: util.OptionalBoolTrue,
+		PageSize: 50,
+		OrderBy:  models.SearchOrderByAlphabetically,
+	})
+	if err != nil {
+		ctx.ServerError("SearchRepositoryByName", err)
+		return
+	}
+	ctx.Data["Templates"] = templates
+}
+
+// generateRepo generates the repository from the template chosen in the form
+func generateRepo(ctx *context.Context, ctxUser *models.User, form *auth.CreateRepoForm) (*models.Repository, error) {
+	templateRepo, err := models.GetRepositoryByID(form.RepoTemplate)
+	if err != nil && !models.IsErrRepoNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		perm, err := models.GetUserRepoPermission(templateRepo, ctx.User)
+		if err != nil {
+			return nil, err
+		}
+		if !perm.CanRead(models.UnitTypeCode) {
+			templateRepo = nil
+		}
+	}
+	if templateRepo == nil || !templateRepo.IsTemplate {
+		ctx.RenderWithErr(ctx.Tr("repo.template.invalid"), tplCreate, form)
+		return nil, nil
+	}
+
+	opts := models.GenerateRepoOptions{
+		Name:        form.RepoName,
+		Description: form.Description,
+		IsPrivate:   form.Private || setting.Repository.ForcePrivate,
+		GitContent:  form.GitContent,
+		Topics:      form.Topics,
+		Labels:      form.Labels,
+		Webhooks:    form.Webhooks,
+	}
+	if !opts.IsValid() {
+		ctx.RenderWithErr(ctx.Tr("repo.template.one_item"), tplCreate, form)
+		return nil, nil
+	}
+	return models.GenerateRepository(ctx.User, ctxUser, templateRepo, opts)
+}
+
 func handleCreateError(ctx *context.Context, owner *models.User, err error, name string, tpl base.TplName, form interface{}) {
 	switch {
 	case migrations.IsRateLimitError(err):
@@ -163,21 +228,41 @@ func CreatePost(ctx *context.Context, form auth.CreateRepoForm) {
 		return
 	}
 	ctx.Data["ContextUser"] = ctxUser
+	defaults := models.GetRepoCreationDefaults(ctx.User, ctxUser)
+	ctx.Data["DefaultBranchPlaceholder"] = defaults.DefaultBranch
+	if len(form.DefaultBranch) == 0 {
+		form.DefaultBranch = defaults.DefaultBranch
+	}
+
+	loadRepoTemplates(ctx)
+	if ctx.Written() {
+		return
+	}
 
 	if ctx.HasError() {
 		ctx.HTML(200, tplCreate)
 		return
 	}
 
-	repo, err := models.CreateRepository(ctx.User, ctxUser, models.CreateRepoOptions{
-		Name:        form.RepoName,
-		Description: form.Description,
-		Gitignores:  form.Gitignores,
-		License:     form.License,
-		Readme:      form.Readme,
-		IsPrivate:   form.Private || setting.Repository.ForcePrivate,
-		AutoInit:    form.AutoInit,
-	})
+	var repo *models.Repository
+	var err error
+	if form.RepoTemplate > 0 {
+		repo, err = generateRepo(ctx, ctxUser, &form)
+		if ctx.Written() {
+			return
+		}
+	} else {
+		repo, err = models.CreateRepository(ctx.User, ctxUser, models.CreateRepoOptions{
+			Name:          form.RepoName,
+			Description:   form.Description,
+			Gitignores:    form.Gitignores,
+			License:       form.License,
+			Readme:        form.Readme,
+			IsPrivate:     form.Private || setting.Repository.ForcePrivate,
+			AutoInit:      form.AutoInit,
+			DefaultBranch: form.DefaultBranch,
+		})
+	}
 	if err == nil {
 		notification.NotifyCreateRepository(ctx.User, ctxUser, repo)
 
@@ -198,7 +283,6 @@ func CreatePost(ctx *context.Context, form auth.CreateRepoForm) {
 // Migrate render migration of repository page
 func Migrate(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("new_migrate")
-	ctx.Data["private"] = getRepoPrivate(ctx)
 	ctx.Data["IsForcedPrivate"] = setting.Repository.ForcePrivate
 	ctx.Data["mirror"] = ctx.Query("mirror") == "1"
 	ctx.Data["wiki"] = ctx.Query("wiki") == "1"
@@ -214,6 +298,7 @@ func Migrate(ctx *context.Context) {
 		return
 	}
 	ctx.Data["ContextUser"] = ctxUser
+	ctx.Data["private"] = models.GetRepoCreationDefaults(ctx.User, ctxUser).IsPrivate
 
 	ctx.HTML(200, tplMigrate)
 }
@@ -327,7 +412,16 @@ func Action(ctx *context.Context) {
 	var err error
 	switch ctx.Params(":action") {
 	case "watch":
-		err = models.WatchRepo(ctx.User.ID, ctx.Repo.Repository.ID, true)
+		if modeName := ctx.Query("mode"); len(modeName) > 0 {
+			mode, ok := models.ParseRepoWatchMode(modeName)
+			if !ok {
+				ctx.Error(400)
+				return
+			}
+			err = models.WatchRepoMode(ctx.User.ID, ctx.Repo.Repository.ID, mode)
+		} else {
+			err = models.WatchRepo(ctx.User.ID, ctx.Repo.Repository.ID, true)
+		}
 	case "unwatch":
 		err = models.WatchRepo(ctx.User.ID, ctx.Repo.Repository.ID, false)
 	case "star":
@@ -343,6 +437,9 @@ func Action(ctx *context.Context) {
 		ctx.Repo.Repository.Description = ctx.Query("desc")
 		ctx.Repo.Repository.Website = ctx.Query("site")
 		err = models.UpdateRepository(ctx.Repo.Repository, false)
+	case "accept_transfer", "reject_transfer":
+		acceptOrRejectRepoTransfer(ctx, ctx.Params(":action") == "accept_transfer")
+		return
 	}
 
 	if err != nil {
@@ -353,6 +450,44 @@ func Action(ctx *context.Context) {
 	ctx.RedirectToFirst(ctx.Query("redirect_to"), ctx.Repo.RepoLink)
 }
 
+func acceptOrRejectRepoTransfer(ctx *context.Context, accept bool) {
+	repoTransfer, err := models.GetPendingRepositoryTransfer(ctx.Repo.Repository)
+	if err != nil {
+		if models.IsErrNoPendingRepoTransfer(err) {
+			ctx.NotFound("GetPendingRepositoryTransfer", err)
+		} else {
+			ctx.ServerError("GetPendingRepositoryTransfer", err)
+		}
+		return
+	}
+	if !repoTransfer.CanUserAcceptTransfer(ctx.User) {
+		ctx.NotFound("CanUserAcceptTransfer", nil)
+		return
+	}
+
+	if !accept {
+		if err = models.CancelRepositoryTransfer(ctx.Repo.Repository); err != nil {
+			ctx.ServerError("CancelRepositoryTransfer", err)
+			return
+		}
+		ctx.Flash.Success(ctx.Tr("repo.transfer.rejected"))
+		ctx.Redirect(setting.AppSubURL + "/")
+		return
+	}
+
+	if err = models.AcceptRepositoryTransfer(ctx.Repo.Repository); err != nil {
+		if models.IsErrRepoAlreadyExist(err) {
+			ctx.Flash.Error(ctx.Tr("repo.settings.new_owner_has_same_repo"))
+			ctx.Redirect(ctx.Repo.RepoLink)
+		} else {
+			ctx.ServerError("AcceptRepositoryTransfer", err)
+		}
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.settings.transfer_succeed"))
+	ctx.Redirect(setting.AppSubURL + "/" + repoTransfer.Recipient.Name + "/" + ctx.Repo.Repository.Name)
+}
+
 // RedirectDownload return a file based on the following infos:
 func RedirectDownload(ctx *context.Context) {
 	var (
@@ -418,32 +553,44 @@ func Download(ctx *context.Context) {
 		}
 	}
 
-	// Get corresponding commit.
-	var (
-		commit *git.Commit
-		err    error
-	)
-	gitRepo := ctx.Repo.GitRepo
-	if gitRepo.IsBranchExist(refName) {
-		commit, err = gitRepo.GetBranchCommit(refName)
-		if err != nil {
-			ctx.ServerError("GetBranchCommit", err)
-			return
+	// Get corresponding commit, the longest reference may be followed by a subdirectory.
+	var subdir string
+	commit, err := getArchiveCommit(ctx.Repo.GitRepo, refName)
+	for i := strings.LastIndex(refName, "/"); commit == nil && err == nil && i > 0; i = strings.LastIndex(refName[:i], "/") {
+		commit, err = getArchiveCommit(ctx.Repo.GitRepo, refName[:i])
+		if commit != nil {
+			refName, subdir = refName[:i], strings.Trim(refName[i+1:], "/")
 		}
-	} else if gitRepo.IsTagExist(refName) {
-		commit, err = gitRepo.GetTagCommit(refName)
+	}
+	if err != nil {
+		ctx.ServerError("getArchiveCommit", err)
+		return
+	} else if commit == nil {
+		ctx.NotFound("Download", nil)
+		return
+	}
+
+	if subdir != "" {
+		entry, err := commit.GetTreeEntryByPath(subdir)
 		if err != nil {
-			ctx.ServerError("GetTagCommit", err)
+			if git.IsErrNotExist(err) {
+				ctx.NotFound("GetTreeEntryByPath", err)
+			} else {
+				ctx.ServerError("GetTreeEntryByPath", err)
+			}
 			return
-		}
-	} else if len(refName) >= 4 && len(refName) <= 40 {
-		commit, err = gitRepo.GetCommit(refName)
-		if err != nil {
-			ctx.NotFound("GetCommit", nil)
+		} else if !entry.IsDir() {
+			ctx.NotFound("GetTreeEntryByPath", nil)
 			return
 		}
-	} else {
-		ctx.NotFound("Download", nil)
+
+		// The archives of subdirectories are streamed instead of cached.
+		name := archiveName(ctx.Repo.Repository.Name + "-" + refName + "-" + path.Base(subdir) + ext)
+		ctx.Resp.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+		ctx.Resp.Header().Set("Content-Type", "application/octet-stream")
+		if err := commit.WriteSubdirArchive(ctx.Resp, subdir, archiveType); err != nil {
+			log.Error("WriteSubdirArchive %s: %v", subdir, err)
+		}
 		return
 	}
 
@@ -455,5 +602,42 @@ func Download(ctx *context.Context) {
 		}
 	}
 
-	ctx.ServeFile(archivePath, ctx.Repo.Repository.Name+"-"+refName+ext)
+	f, err := os.Open(archivePath)
+	if err != nil {
+		ctx.ServerError("Download -> Open "+archivePath, err)
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		ctx.ServerError("Download -> Stat "+archivePath, err)
+		return
+	}
+	ctx.ServeContent(archiveName(ctx.Repo.Repository.Name+"-"+refName+ext), f, fi.ModTime())
+}
+
+// archiveName returns the file name of an archive, the slashes of the
+// references are replaced
+func archiveName(name string) string {
+	return strings.Replace(name, "/", "-", -1)
+}
+
+// archiveCommitIDPattern matches the full or abbreviated commit IDs
+var archiveCommitIDPattern = regexp.MustCompile(`^[0-9a-f]{4,40}$`)
+
+// getArchiveCommit returns the commit of the branch, tag or commit ID
+// refName, or nil if there is none
+func getArchiveCommit(gitRepo *git.Repository, refName string) (*git.Commit, error) {
+	if gitRepo.IsBranchExist(refName) {
+		return gitRepo.GetBranchCommit(refName)
+	} else if gitRepo.IsTagExist(refName) {
+		return gitRepo.GetTagCommit(refName)
+	} else if archiveCommitIDPattern.MatchString(refName) {
+		commit, err := gitRepo.GetCommit(refName)
+		if git.IsErrNotExist(err) {
+			return nil, nil
+		}
+		return commit, err
+	}
+	return nil, nil
 }
@@ -25,7 +25,6 @@ import (
 	"code.gitea.io/gitea/modules/validation"
 	"code.gitea.io/gitea/routers/utils"
 
-	"github.com/unknwon/com"
 	"mvdan.cc/xurls/v2"
 )
 
@@ -37,6 +36,7 @@ const (
 	tplGithookEdit     base.TplName = "repo/settings/githook_edit"
 	tplDeployKeys      base.TplName = "repo/settings/deploy_keys"
 	tplProtectedBranch base.TplName = "repo/settings/protected_branch"
+	tplProtectedTags   base.TplName = "repo/settings/tags"
 )
 
 var validFormAddress *regexp.Regexp
@@ -46,6 +46,26 @@ func Settings(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.settings")
 	ctx.Data["PageIsSettingsOptions"] = true
 	ctx.Data["ForcePrivate"] = setting.Repository.ForcePrivate
+	ctx.Data["DefaultMirrorInterval"] = setting.Mirror.DefaultInterval
+	pushMirrors, err := models.GetPushMirrorsByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetPushMirrorsByRepoID", err)
+		return
+	}
+	ctx.Data["PushMirrors"] = pushMirrors
+	mirrorSSHKey, err := models.GetMirrorSSHKey(ctx.Repo.Repository.ID)
+	if err != nil && !models.IsErrMirrorSSHKeyNotExist(err) {
+		ctx.ServerError("GetMirrorSSHKey", err)
+		return
+	}
+	ctx.Data["MirrorSSHKey"] = mirrorSSHKey
+	if ctx.Repo.Owner.IsOrganization() {
+		if err = ctx.Repo.Owner.GetTeams(); err != nil {
+			ctx.ServerError("GetTeams", err)
+			return
+		}
+		ctx.Data["ReviewerTeams"] = ctx.Repo.Owner.Teams
+	}
 	ctx.HTML(200, tplSettingsOptions)
 }
 
@@ -111,6 +131,7 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 		}
 
 		repo.IsPrivate = form.Private
+		repo.IsTemplate = form.Template
 		if err := models.UpdateRepository(repo, visibilityChanged); err != nil {
 			ctx.ServerError("UpdateRepository", err)
 			return
@@ -163,13 +184,23 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 			return
 		}
 
-		if u.Opaque != "" || !(u.Scheme == "http" || u.Scheme == "https" || u.Scheme == "git") {
+		hasSSHKey := hasMirrorSSHKey(ctx)
+		if ctx.Written() {
+			return
+		}
+		isSSH := hasSSHKey && u.Scheme == "ssh"
+		if u.Opaque != "" || !(u.Scheme == "http" || u.Scheme == "https" || u.Scheme == "git" || isSSH) {
 			ctx.Data["Err_MirrorAddress"] = true
 			ctx.RenderWithErr(ctx.Tr("repo.mirror_address_protocol_invalid"), tplSettingsOptions, &form)
 			return
 		}
 
-		if form.MirrorUsername != "" || form.MirrorPassword != "" {
+		if isSSH {
+			// SSH remotes authenticate with the mirror SSH key
+			if form.MirrorUsername != "" {
+				u.User = url.User(form.MirrorUsername)
+			}
+		} else if form.MirrorUsername != "" || form.MirrorPassword != "" {
 			u.User = url.UserPassword(form.MirrorUsername, form.MirrorPassword)
 		}
 
@@ -207,6 +238,101 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 		ctx.Flash.Info(ctx.Tr("repo.settings.mirror_sync_in_progress"))
 		ctx.Redirect(repo.Link() + "/settings")
 
+	case "push-mirror-add":
+		// This section doesn't require repo_name/RepoName to be set in the form, don't show it
+		// as an error on the UI for this action
+		ctx.Data["Err_RepoName"] = nil
+
+		interval := setting.Mirror.DefaultInterval
+		if len(form.PushMirrorInterval) > 0 {
+			var err error
+			interval, err = time.ParseDuration(form.PushMirrorInterval)
+			if err != nil || (interval != 0 && interval < setting.Mirror.MinInterval) {
+				ctx.Flash.Error(ctx.Tr("repo.mirror_interval_invalid"))
+				ctx.Redirect(repo.Link() + "/settings")
+				return
+			}
+		}
+
+		hasSSHKey := hasMirrorSSHKey(ctx)
+		if ctx.Written() {
+			return
+		}
+		address, err := auth.ParsePushMirrorAddress(form.PushMirrorAddress, form.PushMirrorUsername, form.PushMirrorPassword, hasSSHKey)
+		if err != nil {
+			ctx.Flash.Error(ctx.Tr("repo.settings.push_mirror_address_invalid"))
+			ctx.Redirect(repo.Link() + "/settings")
+			return
+		}
+
+		if err = models.InsertPushMirror(&models.PushMirror{
+			RepoID:       repo.ID,
+			Interval:     interval,
+			SyncOnCommit: form.PushMirrorSyncOnCommit,
+		}, address); err != nil {
+			ctx.ServerError("InsertPushMirror", err)
+			return
+		}
+		log.Trace("Push mirror added: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
+		ctx.Redirect(repo.Link() + "/settings")
+
+	case "push-mirror-sync":
+		m, err := models.GetPushMirrorByID(repo.ID, form.PushMirrorID)
+		if err != nil {
+			if models.IsErrPushMirrorNotExist(err) {
+				ctx.NotFound("GetPushMirrorByID", err)
+			} else {
+				ctx.ServerError("GetPushMirrorByID", err)
+			}
+			return
+		}
+
+		go models.PushMirrorQueue.Add(m.ID)
+		ctx.Flash.Info(ctx.Tr("repo.settings.push_mirror_sync_in_progress", m.Address()))
+		ctx.Redirect(repo.Link() + "/settings")
+
+	case "push-mirror-remove":
+		m, err := models.GetPushMirrorByID(repo.ID, form.PushMirrorID)
+		if err != nil {
+			if models.IsErrPushMirrorNotExist(err) {
+				ctx.NotFound("GetPushMirrorByID", err)
+			} else {
+				ctx.ServerError("GetPushMirrorByID", err)
+			}
+			return
+		}
+
+		if err = models.DeletePushMirror(m); err != nil {
+			ctx.ServerError("DeletePushMirror", err)
+			return
+		}
+		log.Trace("Push mirror removed: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
+		ctx.Redirect(repo.Link() + "/settings")
+
+	case "mirror-ssh-key-generate":
+		if _, err := models.GenerateMirrorSSHKey(repo.ID); err != nil {
+			ctx.ServerError("GenerateMirrorSSHKey", err)
+			return
+		}
+		log.Trace("Mirror SSH key generated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.mirror_ssh_key_generate_success"))
+		ctx.Redirect(repo.Link() + "/settings")
+
+	case "mirror-ssh-key-remove":
+		if err := models.DeleteMirrorSSHKey(repo.ID); err != nil {
+			ctx.ServerError("DeleteMirrorSSHKey", err)
+			return
+		}
+		log.Trace("Mirror SSH key removed: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.mirror_ssh_key_remove_success"))
+		ctx.Redirect(repo.Link() + "/settings")
+
 	case "advanced":
 		var units []models.RepoUnit
 
@@ -281,15 +407,49 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 		}
 
 		if form.EnablePulls {
+			if _, err := regexp.Compile(strings.TrimSpace(form.PullsCommitSubjectPattern)); err != nil {
+				ctx.Flash.Error(ctx.Tr("repo.settings.pulls.commit_subject_pattern_error"))
+				ctx.Redirect(repo.Link() + "/settings")
+				return
+			}
+			if form.PullsReviewerTeamID > 0 {
+				team, err := models.GetTeamByID(form.PullsReviewerTeamID)
+				if err != nil && err != models.ErrTeamNotExist {
+					ctx.ServerError("GetTeamByID", err)
+					return
+				} else if err != nil || team.OrgID != repo.OwnerID {
+					ctx.Flash.Error(ctx.Tr("repo.settings.pulls.reviewer_team_error"))
+					ctx.Redirect(repo.Link() + "/settings")
+					return
+				}
+			}
+			reviewerAssignmentStrategy := models.ReviewerAssignmentStrategy(form.PullsReviewerAssignmentStrategy)
+			if !reviewerAssignmentStrategy.IsValid() {
+				reviewerAssignmentStrategy = models.ReviewerAssignmentRoundRobin
+			}
 			units = append(units, models.RepoUnit{
 				RepoID: repo.ID,
 				Type:   models.UnitTypePullRequests,
 				Config: &models.PullRequestsConfig{
-					IgnoreWhitespaceConflicts: form.PullsIgnoreWhitespace,
-					AllowMerge:                form.PullsAllowMerge,
-					AllowRebase:               form.PullsAllowRebase,
-					AllowRebaseMerge:          form.PullsAllowRebaseMerge,
-					AllowSquash:               form.PullsAllowSquash,
+					IgnoreWhitespaceConflicts:    form.PullsIgnoreWhitespace,
+					AllowMerge:                   form.PullsAllowMerge,
+					AllowRebase:                  form.PullsAllowRebase,
+					AllowRebaseMerge:             form.PullsAllowRebaseMerge,
+					AllowSquash:                  form.PullsAllowSquash,
+					AllowFastForwardOnly:         form.PullsAllowFastForwardOnly,
+					CancelAutoMergeOnPush:        form.PullsCancelAutoMergeOnPush,
+					DefaultMergeMessageTemplate:  strings.TrimSpace(form.PullsDefaultMergeMessageTemplate),
+					DefaultSquashMessageTemplate: strings.TrimSpace(form.PullsDefaultSquashMessageTemplate),
+					MaxChangedFiles:              form.PullsMaxChangedFiles,
+					MaxChangedLines:              form.PullsMaxChangedLines,
+					BlockOversizedMerge:          form.PullsBlockOversizedMerge,
+					CommitMessageSubjectPattern:  strings.TrimSpace(form.PullsCommitSubjectPattern),
+					CommitMessageMaxLineLength:   form.PullsCommitMaxLineLength,
+					CommitMessageRequireIssueRef: form.PullsCommitRequireIssueRef,
+					ReviewerTeamID:               form.PullsReviewerTeamID,
+					ReviewerAssignmentStrategy:   reviewerAssignmentStrategy,
+					ReviewerCount:                form.PullsReviewerCount,
+					ReviewerAssignmentSkipDrafts: form.PullsReviewerSkipDrafts,
 				},
 			})
 		}
@@ -364,35 +524,53 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 			return
 		}
 
-		newOwner := ctx.Query("new_owner_name")
-		isExist, err := models.IsUserExist(0, newOwner)
+		newOwner, err := models.GetUserByName(ctx.Query("new_owner_name"))
 		if err != nil {
-			ctx.ServerError("IsUserExist", err)
-			return
-		} else if !isExist {
-			ctx.RenderWithErr(ctx.Tr("form.enterred_invalid_owner_name"), tplSettingsOptions, nil)
+			if models.IsErrUserNotExist(err) {
+				ctx.RenderWithErr(ctx.Tr("form.enterred_invalid_owner_name"), tplSettingsOptions, nil)
+			} else {
+				ctx.ServerError("GetUserByName", err)
+			}
 			return
 		}
 
-		oldOwnerID := ctx.Repo.Owner.ID
-		if err = models.TransferOwnership(ctx.User, newOwner, repo); err != nil {
+		transferred, err := models.StartRepositoryTransfer(ctx.User, newOwner, repo, nil)
+		if err != nil {
 			if models.IsErrRepoAlreadyExist(err) {
 				ctx.RenderWithErr(ctx.Tr("repo.settings.new_owner_has_same_repo"), tplSettingsOptions, nil)
+			} else if models.IsErrRepoTransferInProgress(err) {
+				ctx.RenderWithErr(ctx.Tr("repo.settings.transfer_in_progress"), tplSettingsOptions, nil)
 			} else {
-				ctx.ServerError("TransferOwnership", err)
+				ctx.ServerError("StartRepositoryTransfer", err)
 			}
 			return
 		}
 
-		err = models.NewRepoRedirect(oldOwnerID, repo.ID, repo.Name, repo.Name)
-		if err != nil {
-			ctx.ServerError("NewRepoRedirect", err)
+		if !transferred {
+			log.Trace("Repository transfer requested: %s/%s -> %s", ctx.Repo.Owner.Name, repo.Name, newOwner.Name)
+			ctx.Flash.Success(ctx.Tr("repo.settings.transfer_pending", newOwner.Name))
+			ctx.Redirect(ctx.Repo.RepoLink + "/settings")
 			return
 		}
 
-		log.Trace("Repository transferred: %s/%s -> %s", ctx.Repo.Owner.Name, repo.Name, newOwner)
+		log.Trace("Repository transferred: %s/%s -> %s", ctx.Repo.Owner.Name, repo.Name, newOwner.Name)
 		ctx.Flash.Success(ctx.Tr("repo.settings.transfer_succeed"))
-		ctx.Redirect(setting.AppSubURL + "/" + newOwner + "/" + repo.Name)
+		ctx.Redirect(setting.AppSubURL + "/" + newOwner.Name + "/" + repo.Name)
+
+	case "cancel_transfer":
+		if !ctx.Repo.IsOwner() {
+			ctx.Error(404)
+			return
+		}
+
+		if err := models.CancelRepositoryTransfer(repo); err != nil {
+			ctx.ServerError("CancelRepositoryTransfer", err)
+			return
+		}
+
+		log.Trace("Repository transfer cancelled: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+		ctx.Flash.Success(ctx.Tr("repo.settings.transfer_abort_success"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
 
 	case "delete":
 		if !ctx.Repo.IsOwner() {
@@ -478,6 +656,19 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 	}
 }
 
+// hasMirrorSSHKey returns true if the repository has a mirror SSH key, which
+// allows the mirrors to have SSH addresses
+func hasMirrorSSHKey(ctx *context.Context) bool {
+	_, err := models.GetMirrorSSHKey(ctx.Repo.Repository.ID)
+	if err != nil {
+		if !models.IsErrMirrorSSHKeyNotExist(err) {
+			ctx.ServerError("GetMirrorSSHKey", err)
+		}
+		return false
+	}
+	return true
+}
+
 // Collaboration render a repository's collaboration page
 func Collaboration(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.settings")
@@ -732,7 +923,7 @@ func DeleteDeployKey(ctx *context.Context) {
 
 func init() {
 	var err error
-	validFormAddress, err = xurls.StrictMatchingScheme(`(https?)|(git)://`)
+	validFormAddress, err = xurls.StrictMatchingScheme(`(https?)|(git)|(ssh)://`)
 	if err != nil {
 		panic(err)
 	}
@@ -746,7 +937,7 @@ func UpdateAvatarSetting(ctx *context.Context, form auth.AvatarForm) error {
 		// No avatar is uploaded and we not removing it here.
 		// No random avatar generated here.
 		// Just exit, no action.
-		if !com.IsFile(ctxRepo.CustomAvatarPath()) {
+		if !ctxRepo.IsCustomAvatarExist() {
 			log.Trace("No avatar was uploaded for repo: %d. Default icon will appear instead.", ctxRepo.ID)
 		}
 		return nil
@@ -0,0 +1,62 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplSettingsLFSLocks base.TplName = "repo/settings/lfs_locks"
+)
+
+// LFSLocks renders the Git LFS locks of the repository
+func LFSLocks(ctx *context.Context) {
+	if !setting.LFS.StartServer {
+		ctx.NotFound("LFSLocks", nil)
+		return
+	}
+	ctx.Data["Title"] = ctx.Tr("repo.settings.lfs_locks")
+	ctx.Data["PageIsSettingsLFSLocks"] = true
+
+	locks, err := models.GetLFSLockByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetLFSLockByRepoID", err)
+		return
+	}
+	ctx.Data["LFSLocks"] = locks
+
+	ctx.HTML(200, tplSettingsLFSLocks)
+}
+
+// LFSLockDelete forces the deletion of a Git LFS lock of the repository
+func LFSLockDelete(ctx *context.Context) {
+	if !setting.LFS.StartServer {
+		ctx.NotFound("LFSLockDelete", nil)
+		return
+	}
+
+	lock, err := models.GetLFSLockByID(ctx.QueryInt64("id"))
+	if err != nil && !models.IsErrLFSLockNotExist(err) {
+		ctx.ServerError("GetLFSLockByID", err)
+		return
+	}
+	if err == nil && lock.RepoID == ctx.Repo.Repository.ID {
+		if _, err = models.DeleteLFSLockByID(lock.ID, ctx.User, true); err != nil {
+			ctx.Flash.Error("DeleteLFSLockByID: " + err.Error())
+		} else {
+			log.Trace("LFS lock of %s deleted in %s/%s", lock.Path, ctx.Repo.Owner.Name, ctx.Repo.Repository.Name)
+			ctx.Flash.Success(ctx.Tr("repo.settings.lfs_locks.deletion_success", lock.Path))
+		}
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": ctx.Repo.RepoLink + "/settings/lfs/locks",
+	})
+}
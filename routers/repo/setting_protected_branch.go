@@ -125,6 +125,7 @@ func SettingsProtectedBranch(c *context.Context) {
 	c.Data["whitelist_users"] = strings.Join(base.Int64sToStrings(protectBranch.WhitelistUserIDs), ",")
 	c.Data["merge_whitelist_users"] = strings.Join(base.Int64sToStrings(protectBranch.MergeWhitelistUserIDs), ",")
 	c.Data["approvals_whitelist_users"] = strings.Join(base.Int64sToStrings(protectBranch.ApprovalsWhitelistUserIDs), ",")
+	c.Data["status_check_contexts"] = strings.Join(protectBranch.StatusCheckContexts, "\n")
 
 	if c.Repo.Owner.IsOrganization() {
 		teams, err := c.Repo.Owner.TeamsWithAccessToRepo(c.Repo.Repository.ID, models.AccessModeRead)
@@ -169,6 +170,7 @@ func SettingsProtectedBranchPost(ctx *context.Context, f auth.ProtectBranchForm)
 		if f.RequiredApprovals < 0 {
 			ctx.Flash.Error(ctx.Tr("repo.settings.protected_branch_required_approvals_min"))
 			ctx.Redirect(fmt.Sprintf("%s/settings/branches/%s", ctx.Repo.RepoLink, branch))
+			return
 		}
 
 		var whitelistUsers, whitelistTeams, mergeWhitelistUsers, mergeWhitelistTeams, approvalsWhitelistUsers, approvalsWhitelistTeams []int64
@@ -187,6 +189,20 @@ func SettingsProtectedBranchPost(ctx *context.Context, f auth.ProtectBranchForm)
 			mergeWhitelistTeams, _ = base.StringsToInt64s(strings.Split(f.MergeWhitelistTeams, ","))
 		}
 		protectBranch.RequiredApprovals = f.RequiredApprovals
+		protectBranch.EnableApprovalsWhitelist = f.EnableApprovalsWhitelist
+		protectBranch.RequireCodeOwnerApproval = f.RequireCodeOwnerApproval
+		protectBranch.DismissStaleApprovals = f.DismissStaleApprovals
+		protectBranch.EnableMergeQueue = f.EnableMergeQueue
+		protectBranch.EnableStatusCheck = f.EnableStatusCheck
+		var statusCheckContexts []string
+		for _, context := range strings.Split(f.StatusCheckContexts, "\n") {
+			if context = strings.TrimSpace(context); len(context) > 0 {
+				statusCheckContexts = append(statusCheckContexts, context)
+			}
+		}
+		protectBranch.StatusCheckContexts = statusCheckContexts
+		protectBranch.BlockOnUnresolvedConversations = f.BlockOnUnresolvedConversations
+		protectBranch.BlockOnOutdatedBranch = f.BlockOnOutdatedBranch
 		if strings.TrimSpace(f.ApprovalsWhitelistUsers) != "" {
 			approvalsWhitelistUsers, _ = base.StringsToInt64s(strings.Split(f.ApprovalsWhitelistUsers, ","))
 		}
@@ -0,0 +1,154 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// setProtectedTagsContext sets the protected tags of the repository and the
+// users and teams which can be whitelisted
+func setProtectedTagsContext(ctx *context.Context) error {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.tags")
+	ctx.Data["PageIsSettingsTags"] = true
+
+	protectedTags, err := models.GetProtectedTags(ctx.Repo.Repository.ID)
+	if err != nil {
+		return fmt.Errorf("GetProtectedTags: %v", err)
+	}
+	ctx.Data["ProtectedTags"] = protectedTags
+
+	users, err := ctx.Repo.Repository.GetWriters()
+	if err != nil {
+		return fmt.Errorf("GetWriters: %v", err)
+	}
+	ctx.Data["Users"] = users
+
+	if ctx.Repo.Owner.IsOrganization() {
+		teams, err := ctx.Repo.Owner.TeamsWithAccessToRepo(ctx.Repo.Repository.ID, models.AccessModeRead)
+		if err != nil {
+			return fmt.Errorf("TeamsWithAccessToRepo: %v", err)
+		}
+		ctx.Data["Teams"] = teams
+	}
+	return nil
+}
+
+// ProtectedTags renders the protected tags settings page
+func ProtectedTags(ctx *context.Context) {
+	if err := setProtectedTagsContext(ctx); err != nil {
+		ctx.ServerError("setProtectedTagsContext", err)
+		return
+	}
+
+	ctx.HTML(200, tplProtectedTags)
+}
+
+// ProtectedTagsPost adds a protected tag to the repository
+func ProtectedTagsPost(ctx *context.Context, form auth.ProtectTagForm) {
+	updateProtectedTag(ctx, &models.ProtectedTag{}, form)
+}
+
+// EditProtectedTag renders the protected tags settings page with the form
+// editing one of the protected tags
+func EditProtectedTag(ctx *context.Context) {
+	if err := setProtectedTagsContext(ctx); err != nil {
+		ctx.ServerError("setProtectedTagsContext", err)
+		return
+	}
+
+	pt := getProtectedTag(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["ProtectedTag"] = pt
+	ctx.Data["name_pattern"] = pt.NamePattern
+	ctx.Data["whitelist_users"] = strings.Join(base.Int64sToStrings(pt.WhitelistUserIDs), ",")
+	ctx.Data["whitelist_teams"] = strings.Join(base.Int64sToStrings(pt.WhitelistTeamIDs), ",")
+
+	ctx.HTML(200, tplProtectedTags)
+}
+
+// EditProtectedTagPost updates a protected tag of the repository
+func EditProtectedTagPost(ctx *context.Context, form auth.ProtectTagForm) {
+	pt := getProtectedTag(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["ProtectedTag"] = pt
+	updateProtectedTag(ctx, pt, form)
+}
+
+// DeleteProtectedTagPost deletes a protected tag of the repository
+func DeleteProtectedTagPost(ctx *context.Context) {
+	pt, err := models.GetProtectedTagByID(ctx.Repo.Repository.ID, ctx.QueryInt64("id"))
+	if err != nil {
+		ctx.Flash.Error("GetProtectedTagByID: " + err.Error())
+	} else if err = models.DeleteProtectedTag(pt); err != nil {
+		ctx.Flash.Error("DeleteProtectedTag: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.settings.tags.deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": ctx.Repo.RepoLink + "/settings/tags",
+	})
+}
+
+func getProtectedTag(ctx *context.Context) *models.ProtectedTag {
+	pt, err := models.GetProtectedTagByID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrProtectedTagNotExist(err) {
+			ctx.NotFound("GetProtectedTagByID", err)
+		} else {
+			ctx.ServerError("GetProtectedTagByID", err)
+		}
+		return nil
+	}
+	return pt
+}
+
+func updateProtectedTag(ctx *context.Context, pt *models.ProtectedTag, form auth.ProtectTagForm) {
+	if err := setProtectedTagsContext(ctx); err != nil {
+		ctx.ServerError("setProtectedTagsContext", err)
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplProtectedTags)
+		return
+	}
+
+	var whitelistUsers, whitelistTeams []int64
+	if strings.TrimSpace(form.WhitelistUsers) != "" {
+		whitelistUsers, _ = base.StringsToInt64s(strings.Split(form.WhitelistUsers, ","))
+	}
+	if strings.TrimSpace(form.WhitelistTeams) != "" {
+		whitelistTeams, _ = base.StringsToInt64s(strings.Split(form.WhitelistTeams, ","))
+	}
+
+	pt.NamePattern = strings.TrimSpace(form.NamePattern)
+	if err := models.UpdateProtectedTag(ctx.Repo.Repository, pt, whitelistUsers, whitelistTeams); err != nil {
+		if models.IsErrInvalidTagPattern(err) {
+			ctx.Data["Err_NamePattern"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.tags.invalid_pattern"), tplProtectedTags, &form)
+		} else {
+			ctx.ServerError("UpdateProtectedTag", err)
+		}
+		return
+	}
+
+	log.Trace("Protected tag updated: %s in %s/%s", pt.NamePattern, ctx.Repo.Owner.Name, ctx.Repo.Repository.Name)
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.tags.update_success", pt.NamePattern))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/tags")
+}
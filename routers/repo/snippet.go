@@ -0,0 +1,47 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"io"
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/repofiles"
+)
+
+// Snippet returns the lines of a file to embed them in a web page, as HTML or
+// as JSON with their permanent link
+func Snippet(ctx *context.Context) {
+	lines, err := repofiles.ParseLineRange(ctx.QueryTrim("lines"))
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	snippet, err := repofiles.GetFileSnippet(ctx.Repo.Repository, ctx.Repo.Commit, ctx.Repo.TreePath, lines)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound("GetFileSnippet", nil)
+		} else if repofiles.IsErrInvalidSnippet(err) {
+			ctx.Error(http.StatusUnprocessableEntity, err.Error())
+		} else {
+			ctx.ServerError("GetFileSnippet", err)
+		}
+		return
+	}
+
+	if ctx.Query("format") == "json" {
+		ctx.JSON(http.StatusOK, snippet)
+		return
+	}
+	ctx.Resp.Header().Set("Content-Type", "text/html; charset=utf-8")
+	ctx.Resp.WriteHeader(http.StatusOK)
+	if _, err = io.WriteString(ctx.Resp, snippet.HTML); err != nil {
+		log.Error("Write snippet: %v", err)
+	}
+}
@@ -53,33 +53,21 @@ func renderDirectory(ctx *context.Context, treeLink string) {
 		ctx.ServerError("GetCommitsInfo", err)
 		return
 	}
+	// The SSH URLs of the submodules of this instance are linked to the web
+	// pages of their repositories
+	ctx.Data["SSHDomain"] = setting.SSH.Domain
 
-	// 3 for the extensions in exts[] in order
-	// the last one is for a readme that doesn't
-	// strictly match an extension
-	var readmeFiles [4]*git.Blob
-	var exts = []string{".md", ".txt", ""} // sorted by priority
+	var readmeFile *git.Blob
+	readmePriority := -1
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
 		}
 
-		for i, ext := range exts {
-			if markup.IsReadmeFile(entry.Name(), ext) {
-				readmeFiles[i] = entry.Blob()
-			}
-		}
-
-		if markup.IsReadmeFile(entry.Name()) {
-			readmeFiles[3] = entry.Blob()
-		}
-	}
-
-	var readmeFile *git.Blob
-	for _, f := range readmeFiles {
-		if f != nil {
-			readmeFile = f
-			break
+		priority := markup.ReadmePriority(entry.Name(), setting.Repository.ReadmeExtensions)
+		if priority >= 0 && (readmeFile == nil || priority < readmePriority) {
+			readmeFile = entry.Blob()
+			readmePriority = priority
 		}
 	}
 
@@ -179,7 +167,7 @@ func renderDirectory(ctx *context.Context, treeLink string) {
 	// Show latest commit info of repository in table header,
 	// or of directory if not in root directory.
 	ctx.Data["LatestCommit"] = latestCommit
-	ctx.Data["LatestCommitVerification"] = models.ParseCommitWithSignature(latestCommit)
+	ctx.Data["LatestCommitVerification"] = models.ParseCommitWithSignature(ctx.Repo.Repository, latestCommit)
 	ctx.Data["LatestCommitUser"] = models.ValidateCommitWithEmail(latestCommit)
 
 	statuses, err := models.GetLatestCommitStatus(ctx.Repo.Repository, ctx.Repo.Commit.ID.String(), 0)
@@ -193,6 +181,9 @@ func renderDirectory(ctx *context.Context, treeLink string) {
 	if ctx.Repo.CanWrite(models.UnitTypeCode) && ctx.Repo.IsViewBranch {
 		ctx.Data["CanAddFile"] = !ctx.Repo.Repository.IsArchived
 		ctx.Data["CanUploadFile"] = setting.Repository.Upload.Enabled && !ctx.Repo.Repository.IsArchived
+	} else if ctx.Repo.CanProposeByFork(ctx.User) {
+		// the new file is committed to a fork
+		ctx.Data["CanAddFile"] = true
 	}
 }
 
@@ -327,6 +318,9 @@ func renderFile(ctx *context.Context, entry *git.TreeEntry, treeLink, rawLink st
 			if ctx.Repo.CanEnableEditor() {
 				ctx.Data["CanEditFile"] = true
 				ctx.Data["EditFileTooltip"] = ctx.Tr("repo.editor.edit_this_file")
+			} else if ctx.Repo.CanProposeByFork(ctx.User) {
+				ctx.Data["CanEditFile"] = true
+				ctx.Data["EditFileTooltip"] = ctx.Tr("repo.editor.edit_this_file_in_fork")
 			} else if !ctx.Repo.IsViewBranch {
 				ctx.Data["EditFileTooltip"] = ctx.Tr("repo.editor.must_be_on_a_branch")
 			} else if !ctx.Repo.CanWrite(models.UnitTypeCode) {
@@ -419,6 +413,18 @@ func renderCode(ctx *context.Context) {
 		return
 	}
 
+	// The language statistics are only computed for the default branch
+	if len(ctx.Repo.TreePath) == 0 && ctx.Repo.BranchName == ctx.Repo.Repository.DefaultBranch {
+		ctx.Data["LanguageStats"], err = ctx.Repo.Repository.GetLanguageStats()
+		if err != nil {
+			ctx.ServerError("GetLanguageStats", err)
+			return
+		}
+	}
+	if len(ctx.Repo.TreePath) == 0 {
+		renderCitation(ctx)
+	}
+
 	if entry.IsDir() {
 		renderDirectory(ctx, treeLink)
 	} else {
@@ -144,6 +144,8 @@ func ParseHookEvent(form auth.WebhookForm) *models.HookEvent {
 			Push:         form.Push,
 			PullRequest:  form.PullRequest,
 			Repository:   form.Repository,
+			Label:        form.Label,
+			Wiki:         form.Wiki,
 		},
 	}
 }
@@ -174,15 +176,22 @@ func WebHooksNewPost(ctx *context.Context, form auth.NewWebhookForm) {
 	}
 
 	w := &models.Webhook{
-		RepoID:       orCtx.RepoID,
-		URL:          form.PayloadURL,
-		HTTPMethod:   form.HTTPMethod,
-		ContentType:  contentType,
-		Secret:       form.Secret,
-		HookEvent:    ParseHookEvent(form.WebhookForm),
-		IsActive:     form.Active,
-		HookTaskType: models.GITEA,
-		OrgID:        orCtx.OrgID,
+		RepoID:          orCtx.RepoID,
+		URL:             form.PayloadURL,
+		HTTPMethod:      form.HTTPMethod,
+		ContentType:     contentType,
+		Secret:          form.Secret,
+		HookEvent:       ParseHookEvent(form.WebhookForm),
+		IsActive:        form.Active,
+		HookTaskType:    models.GITEA,
+		OrgID:           orCtx.OrgID,
+		PayloadTemplate: form.PayloadTemplate,
+	}
+	if err := models.CheckWebhookPayloadTemplate(w.PayloadTemplate); err != nil {
+		ctx.Data["Webhook"] = w
+		ctx.Data["Err_PayloadTemplate"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.payload_template_invalid", err), orCtx.NewTemplate, &form)
+		return
 	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -369,7 +378,7 @@ func TelegramHooksNewPost(ctx *context.Context, form auth.NewTelegramHookForm) {
 
 	w := &models.Webhook{
 		RepoID:       orCtx.RepoID,
-		URL:          fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage?chat_id=%s", form.BotToken, form.ChatID),
+		URL:          models.TelegramSendMessageURL(form.BotToken, form.ChatID),
 		ContentType:  models.ContentTypeJSON,
 		HookEvent:    ParseHookEvent(form.WebhookForm),
 		IsActive:     form.Active,
@@ -389,6 +398,58 @@ func TelegramHooksNewPost(ctx *context.Context, form auth.NewTelegramHookForm) {
 	ctx.Redirect(orCtx.Link)
 }
 
+// MatrixHooksNewPost response for creating matrix hook
+func MatrixHooksNewPost(ctx *context.Context, form auth.NewMatrixHookForm) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksNew"] = true
+	ctx.Data["Webhook"] = models.Webhook{HookEvent: &models.HookEvent{}}
+
+	orCtx, err := getOrgRepoCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getOrgRepoCtx", err)
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(200, orCtx.NewTemplate)
+		return
+	}
+
+	meta, err := json.Marshal(&models.MatrixMeta{
+		HomeserverURL: form.HomeserverURL,
+		RoomID:        form.RoomID,
+		AccessToken:   form.AccessToken,
+		MessageFormat: form.MessageFormat,
+	})
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return
+	}
+
+	w := &models.Webhook{
+		RepoID:       orCtx.RepoID,
+		URL:          models.MatrixRoomMessageURL(form.HomeserverURL, form.RoomID),
+		ContentType:  models.ContentTypeJSON,
+		HTTPMethod:   "PUT",
+		HookEvent:    ParseHookEvent(form.WebhookForm),
+		IsActive:     form.Active,
+		HookTaskType: models.MATRIX,
+		Meta:         string(meta),
+		OrgID:        orCtx.OrgID,
+	}
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.CreateWebhook(w); err != nil {
+		ctx.ServerError("CreateWebhook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
+	ctx.Redirect(orCtx.Link)
+}
+
 // MSTeamsHooksNewPost response for creating MS Teams hook
 func MSTeamsHooksNewPost(ctx *context.Context, form auth.NewMSTeamsHookForm) {
 	ctx.Data["Title"] = ctx.Tr("repo.settings")
@@ -521,6 +582,8 @@ func checkWebhook(ctx *context.Context) (*orgRepoCtx, *models.Webhook) {
 		ctx.Data["DiscordHook"] = w.GetDiscordHook()
 	case models.TELEGRAM:
 		ctx.Data["TelegramHook"] = w.GetTelegramHook()
+	case models.MATRIX:
+		ctx.Data["MatrixHook"] = w.GetMatrixHook()
 	}
 
 	ctx.Data["History"], err = w.History(1)
@@ -573,6 +636,12 @@ func WebHooksEditPost(ctx *context.Context, form auth.NewWebhookForm) {
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
 	w.HTTPMethod = form.HTTPMethod
+	w.PayloadTemplate = form.PayloadTemplate
+	if err := models.CheckWebhookPayloadTemplate(w.PayloadTemplate); err != nil {
+		ctx.Data["Err_PayloadTemplate"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.payload_template_invalid", err), orCtx.NewTemplate, &form)
+		return
+	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -773,7 +842,49 @@ func TelegramHooksEditPost(ctx *context.Context, form auth.NewTelegramHookForm)
 		return
 	}
 	w.Meta = string(meta)
-	w.URL = fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage?chat_id=%s", form.BotToken, form.ChatID)
+	w.URL = models.TelegramSendMessageURL(form.BotToken, form.ChatID)
+	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.IsActive = form.Active
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.UpdateWebhook(w); err != nil {
+		ctx.ServerError("UpdateWebhook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
+	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
+}
+
+// MatrixHooksEditPost response for editing matrix hook
+func MatrixHooksEditPost(ctx *context.Context, form auth.NewMatrixHookForm) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksEdit"] = true
+
+	orCtx, w := checkWebhook(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Webhook"] = w
+
+	if ctx.HasError() {
+		ctx.HTML(200, orCtx.NewTemplate)
+		return
+	}
+	meta, err := json.Marshal(&models.MatrixMeta{
+		HomeserverURL: form.HomeserverURL,
+		RoomID:        form.RoomID,
+		AccessToken:   form.AccessToken,
+		MessageFormat: form.MessageFormat,
+	})
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return
+	}
+	w.Meta = string(meta)
+	w.URL = models.MatrixRoomMessageURL(form.HomeserverURL, form.RoomID)
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
 	if err := w.UpdateEvent(); err != nil {
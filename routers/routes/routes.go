@@ -7,9 +7,11 @@ package routes
 import (
 	"bytes"
 	"encoding/gob"
+	"fmt"
 	"net/http"
 	"os"
 	"path"
+	"strings"
 	"text/template"
 	"time"
 
@@ -23,10 +25,12 @@ import (
 	"code.gitea.io/gitea/modules/options"
 	"code.gitea.io/gitea/modules/public"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/templates"
 	"code.gitea.io/gitea/modules/validation"
 	"code.gitea.io/gitea/routers"
 	"code.gitea.io/gitea/routers/admin"
+	"code.gitea.io/gitea/routers/api/graphql"
 	apiv1 "code.gitea.io/gitea/routers/api/v1"
 	"code.gitea.io/gitea/routers/dev"
 	"code.gitea.io/gitea/routers/org"
@@ -106,6 +110,51 @@ func RouterHandler(level log.Level) func(ctx *macaron.Context) {
 	}
 }
 
+// storageHandler serves the objects of the storage under the URL prefix, the
+// storage is looked up on every request since it is initialized after the
+// handler is registered.
+func storageHandler(prefix string, getStorage func() storage.ObjectStorage, expiresAfter time.Duration) macaron.Handler {
+	prefix = "/" + prefix + "/"
+	return func(ctx *macaron.Context) {
+		if ctx.Req.Method != "GET" && ctx.Req.Method != "HEAD" {
+			return
+		}
+		if !strings.HasPrefix(ctx.Req.URL.Path, prefix) {
+			return
+		}
+
+		rPath := strings.TrimPrefix(path.Clean("/"+strings.TrimPrefix(ctx.Req.URL.Path, prefix)), "/")
+		if len(rPath) == 0 {
+			http.NotFound(ctx.Resp, ctx.Req.Request)
+			return
+		}
+
+		obj, err := getStorage().Open(rPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				http.NotFound(ctx.Resp, ctx.Req.Request)
+				return
+			}
+			log.Error("Unable to open %s%s: %v", prefix, rPath, err)
+			http.Error(ctx.Resp, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		defer obj.Close()
+
+		fi, err := obj.Stat()
+		if err != nil {
+			log.Error("Unable to stat %s%s: %v", prefix, rPath, err)
+			http.Error(ctx.Resp, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		if expiresAfter > 0 {
+			ctx.Resp.Header().Set("Cache-Control", fmt.Sprintf("public,max-age=%d", int64(expiresAfter.Seconds())))
+		}
+		http.ServeContent(ctx.Resp, ctx.Req.Request, path.Base(rPath), fi.ModTime(), obj)
+	}
+}
+
 // NewMacaron initializes Macaron instance.
 func NewMacaron() *macaron.Macaron {
 	gob.Register(&u2f.Challenge{})
@@ -148,22 +197,8 @@ func NewMacaron() *macaron.Macaron {
 			ExpiresAfter: time.Hour * 6,
 		},
 	))
-	m.Use(public.StaticHandler(
-		setting.AvatarUploadPath,
-		&public.Options{
-			Prefix:       "avatars",
-			SkipLogging:  setting.DisableRouterLog,
-			ExpiresAfter: time.Hour * 6,
-		},
-	))
-	m.Use(public.StaticHandler(
-		setting.RepositoryAvatarUploadPath,
-		&public.Options{
-			Prefix:       "repo-avatars",
-			SkipLogging:  setting.DisableRouterLog,
-			ExpiresAfter: time.Hour * 6,
-		},
-	))
+	m.Use(storageHandler("avatars", func() storage.ObjectStorage { return storage.Avatars }, time.Hour*6))
+	m.Use(storageHandler("repo-avatars", func() storage.ObjectStorage { return storage.RepoAvatars }, time.Hour*6))
 
 	m.Use(templates.HTMLRenderer())
 	models.InitMailRender(templates.Mailer())
@@ -325,6 +360,9 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Post("/authorize", bindIgnErr(auth.AuthorizationForm{}), user.AuthorizeOAuth)
 	}, ignSignInAndCsrf, reqSignIn)
 	m.Post("/login/oauth/access_token", bindIgnErr(auth.AccessTokenForm{}), ignSignInAndCsrf, user.AccessTokenOAuth)
+	m.Post("/login/oauth/device_authorization", bindIgnErr(auth.DeviceAuthorizationForm{}), ignSignInAndCsrf, user.DeviceAuthorizationOAuth)
+	m.Combo("/login/device", reqSignIn).Get(user.DeviceAuthorization).
+		Post(bindIgnErr(auth.DeviceGrantForm{}), user.DeviceAuthorizationPost)
 
 	m.Group("/user/settings", func() {
 		m.Get("", userSetting.Profile)
@@ -435,6 +473,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Group("/repos", func() {
 			m.Get("", admin.Repos)
 			m.Post("/delete", admin.DeleteRepo)
+			m.Post("/housekeeping", admin.RunRepoHousekeeping)
 		})
 
 		m.Group("/hooks", func() {
@@ -448,6 +487,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Post("/dingtalk/new", bindIgnErr(auth.NewDingtalkHookForm{}), repo.DingtalkHooksNewPost)
 			m.Post("/telegram/new", bindIgnErr(auth.NewTelegramHookForm{}), repo.TelegramHooksNewPost)
 			m.Post("/msteams/new", bindIgnErr(auth.NewMSTeamsHookForm{}), repo.MSTeamsHooksNewPost)
+			m.Post("/matrix/new", bindIgnErr(auth.NewMatrixHookForm{}), repo.MatrixHooksNewPost)
 			m.Get("/:id", repo.WebHooksEdit)
 			m.Post("/gitea/:id", bindIgnErr(auth.NewWebhookForm{}), repo.WebHooksEditPost)
 			m.Post("/gogs/:id", bindIgnErr(auth.NewWebhookForm{}), repo.GogsHooksEditPost)
@@ -455,7 +495,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Post("/discord/:id", bindIgnErr(auth.NewDiscordHookForm{}), repo.DiscordHooksEditPost)
 			m.Post("/dingtalk/:id", bindIgnErr(auth.NewDingtalkHookForm{}), repo.DingtalkHooksEditPost)
 			m.Post("/telegram/:id", bindIgnErr(auth.NewTelegramHookForm{}), repo.TelegramHooksEditPost)
-			m.Post("/msteams/:id", bindIgnErr(auth.NewMSTeamsHookForm{}), repo.MSTeamsHooksNewPost)
+			m.Post("/msteams/:id", bindIgnErr(auth.NewMSTeamsHookForm{}), repo.MSTeamsHooksEditPost)
+			m.Post("/matrix/:id", bindIgnErr(auth.NewMatrixHookForm{}), repo.MatrixHooksEditPost)
 		})
 
 		m.Group("/auths", func() {
@@ -481,34 +522,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Get("/following", user.Following)
 		})
 
-		m.Get("/attachments/:uuid", func(ctx *context.Context) {
-			attach, err := models.GetAttachmentByUUID(ctx.Params(":uuid"))
-			if err != nil {
-				if models.IsErrAttachmentNotExist(err) {
-					ctx.Error(404)
-				} else {
-					ctx.ServerError("GetAttachmentByUUID", err)
-				}
-				return
-			}
-
-			fr, err := os.Open(attach.LocalPath())
-			if err != nil {
-				ctx.ServerError("Open", err)
-				return
-			}
-			defer fr.Close()
-
-			if err := attach.IncreaseDownloadCount(); err != nil {
-				ctx.ServerError("Update", err)
-				return
-			}
-
-			if err = repo.ServeData(ctx, attach.Name, fr); err != nil {
-				ctx.ServerError("ServeData", err)
-				return
-			}
-		})
+		m.Get("/attachments/:uuid", repo.GetAttachment)
 	}, ignSignIn)
 
 	m.Group("", func() {
@@ -588,6 +602,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Post("/discord/new", bindIgnErr(auth.NewDiscordHookForm{}), repo.DiscordHooksNewPost)
 					m.Post("/dingtalk/new", bindIgnErr(auth.NewDingtalkHookForm{}), repo.DingtalkHooksNewPost)
 					m.Post("/telegram/new", bindIgnErr(auth.NewTelegramHookForm{}), repo.TelegramHooksNewPost)
+					m.Post("/msteams/new", bindIgnErr(auth.NewMSTeamsHookForm{}), repo.MSTeamsHooksNewPost)
+					m.Post("/matrix/new", bindIgnErr(auth.NewMatrixHookForm{}), repo.MatrixHooksNewPost)
 					m.Get("/:id", repo.WebHooksEdit)
 					m.Post("/gitea/:id", bindIgnErr(auth.NewWebhookForm{}), repo.WebHooksEditPost)
 					m.Post("/gogs/:id", bindIgnErr(auth.NewGogshookForm{}), repo.GogsHooksEditPost)
@@ -595,6 +611,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Post("/discord/:id", bindIgnErr(auth.NewDiscordHookForm{}), repo.DiscordHooksEditPost)
 					m.Post("/dingtalk/:id", bindIgnErr(auth.NewDingtalkHookForm{}), repo.DingtalkHooksEditPost)
 					m.Post("/telegram/:id", bindIgnErr(auth.NewTelegramHookForm{}), repo.TelegramHooksEditPost)
+					m.Post("/msteams/:id", bindIgnErr(auth.NewMSTeamsHookForm{}), repo.MSTeamsHooksEditPost)
+					m.Post("/matrix/:id", bindIgnErr(auth.NewMatrixHookForm{}), repo.MatrixHooksEditPost)
 				})
 
 				m.Route("/delete", "GET,POST", org.SettingsDelete)
@@ -635,6 +653,13 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Combo("/*").Get(repo.SettingsProtectedBranch).
 					Post(bindIgnErr(auth.ProtectBranchForm{}), context.RepoMustNotBeArchived(), repo.SettingsProtectedBranchPost)
 			}, repo.MustBeNotEmpty)
+			m.Group("/tags", func() {
+				m.Combo("").Get(repo.ProtectedTags).
+					Post(bindIgnErr(auth.ProtectTagForm{}), repo.ProtectedTagsPost)
+				m.Post("/delete", repo.DeleteProtectedTagPost)
+				m.Combo("/:id").Get(repo.EditProtectedTag).
+					Post(bindIgnErr(auth.ProtectTagForm{}), repo.EditProtectedTagPost)
+			})
 
 			m.Group("/hooks", func() {
 				m.Get("", repo.Webhooks)
@@ -647,6 +672,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Post("/dingtalk/new", bindIgnErr(auth.NewDingtalkHookForm{}), repo.DingtalkHooksNewPost)
 				m.Post("/telegram/new", bindIgnErr(auth.NewTelegramHookForm{}), repo.TelegramHooksNewPost)
 				m.Post("/msteams/new", bindIgnErr(auth.NewMSTeamsHookForm{}), repo.MSTeamsHooksNewPost)
+				m.Post("/matrix/new", bindIgnErr(auth.NewMatrixHookForm{}), repo.MatrixHooksNewPost)
 				m.Get("/:id", repo.WebHooksEdit)
 				m.Post("/:id/test", repo.TestWebhook)
 				m.Post("/gitea/:id", bindIgnErr(auth.NewWebhookForm{}), repo.WebHooksEditPost)
@@ -656,6 +682,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Post("/dingtalk/:id", bindIgnErr(auth.NewDingtalkHookForm{}), repo.DingtalkHooksEditPost)
 				m.Post("/telegram/:id", bindIgnErr(auth.NewTelegramHookForm{}), repo.TelegramHooksEditPost)
 				m.Post("/msteams/:id", bindIgnErr(auth.NewMSTeamsHookForm{}), repo.MSTeamsHooksEditPost)
+				m.Post("/matrix/:id", bindIgnErr(auth.NewMatrixHookForm{}), repo.MatrixHooksEditPost)
 
 				m.Group("/git", func() {
 					m.Get("", repo.GitHooks)
@@ -670,8 +697,14 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Post("/delete", repo.DeleteDeployKey)
 			})
 
+			m.Group("/lfs/locks", func() {
+				m.Get("", repo.LFSLocks)
+				m.Post("/delete", repo.LFSLockDelete)
+			})
+
 		}, func(ctx *context.Context) {
 			ctx.Data["PageIsSettings"] = true
+			ctx.Data["LFSStartServer"] = setting.LFS.StartServer
 		})
 	}, reqSignIn, context.RepoAssignment(), context.UnitTypes(), reqRepoAdmin, context.RepoRef())
 
@@ -704,6 +737,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Post("/reactions/:action", bindIgnErr(auth.ReactionForm{}), repo.ChangeIssueReaction)
 				m.Post("/lock", reqRepoIssueWriter, bindIgnErr(auth.IssueLockForm{}), repo.LockIssue)
 				m.Post("/unlock", reqRepoIssueWriter, repo.UnlockIssue)
+				m.Post("/delete", reqRepoAdmin, repo.DeleteIssue)
+				m.Post("/content-history/purge", reqRepoAdmin, repo.PurgeContentHistory)
 			}, context.RepoMustNotBeArchived())
 
 			m.Post("/labels", reqRepoIssuesOrPullsWriter, repo.UpdateIssueLabel)
@@ -737,18 +772,26 @@ func RegisterRoutes(m *macaron.Macaron) {
 			Get(repo.SetDiffViewStyle, repo.CompareDiff).
 			Post(context.RepoMustNotBeArchived(), reqRepoPullsReader, repo.MustAllowPulls, bindIgnErr(auth.CreateIssueForm{}), repo.CompareAndPullRequestPost)
 
+		m.Group("", func() {
+			m.Combo("/_edit/*").Get(repo.EditFile).
+				Post(bindIgnErr(auth.EditRepoFileForm{}), repo.EditFilePost)
+			m.Combo("/_new/*").Get(repo.NewFile).
+				Post(bindIgnErr(auth.EditRepoFileForm{}), repo.NewFilePost)
+			m.Post("/_preview/*", bindIgnErr(auth.EditPreviewDiffForm{}), repo.DiffPreviewPost)
+		}, context.RepoMustNotBeArchived(), reqRepoCodeReader, repo.MustBeNotEmpty,
+			context.RepoRefByType(context.RepoRefBranch), repo.MustBeEditable, repo.MustBeAbleToEditOrFork)
+
 		m.Group("", func() {
 			m.Group("", func() {
-				m.Combo("/_edit/*").Get(repo.EditFile).
-					Post(bindIgnErr(auth.EditRepoFileForm{}), repo.EditFilePost)
-				m.Combo("/_new/*").Get(repo.NewFile).
-					Post(bindIgnErr(auth.EditRepoFileForm{}), repo.NewFilePost)
-				m.Post("/_preview/*", bindIgnErr(auth.EditPreviewDiffForm{}), repo.DiffPreviewPost)
 				m.Combo("/_delete/*").Get(repo.DeleteFile).
 					Post(bindIgnErr(auth.DeleteRepoFileForm{}), repo.DeleteFilePost)
 				m.Combo("/_upload/*", repo.MustBeAbleToUpload).
 					Get(repo.UploadFile).
 					Post(bindIgnErr(auth.UploadRepoFileForm{}), repo.UploadFilePost)
+				m.Combo("/_cherrypick/:sha([a-f0-9]{7,40})/*").Get(repo.CherryPick).
+					Post(bindIgnErr(auth.CherryPickForm{}), repo.CherryPickPost)
+				m.Combo("/_revert/:sha([a-f0-9]{7,40})/*").Get(repo.Revert).
+					Post(bindIgnErr(auth.CherryPickForm{}), repo.RevertPost)
 			}, context.RepoRefByType(context.RepoRefBranch), repo.MustBeEditable)
 			m.Group("", func() {
 				m.Post("/upload-file", repo.UploadFileToServer)
@@ -764,6 +807,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 			}, bindIgnErr(auth.NewBranchForm{}))
 			m.Post("/delete", repo.DeleteBranchPost)
 			m.Post("/restore", repo.RestoreBranchPost)
+			m.Post("/sync", repo.SyncForkPost)
+			m.Post("/stale", bindIgnErr(auth.DeleteStaleBranchesForm{}), repo.DeleteStaleBranchesPost)
 		}, context.RepoMustNotBeArchived(), reqRepoCodeWriter, repo.MustBeNotEmpty)
 
 	}, reqSignIn, context.RepoAssignment(), context.UnitTypes())
@@ -805,6 +850,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Group("", func() {
 			m.Get("/^:type(issues|pulls)$", repo.Issues)
 			m.Get("/^:type(issues|pulls)$/:index", repo.ViewIssue)
+			m.Get("/issues/:index/content-history/list", repo.GetContentHistoryList)
+			m.Get("/issues/:index/content-history/detail", repo.GetContentHistoryDetail)
 			m.Get("/labels/", reqRepoIssuesOrPullsReader, repo.RetrieveLabels, repo.Labels)
 			m.Get("/milestones", reqRepoIssuesOrPullsReader, repo.Milestones)
 		}, context.RepoRef())
@@ -827,6 +874,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Get("/raw/*", repo.WikiRaw)
 		}, repo.MustEnableWiki)
 
+		m.Get("/activity/contributors", context.RepoRef(), repo.MustBeNotEmpty, reqRepoCodeReader, repo.ActivityContributors)
 		m.Group("/activity", func() {
 			m.Get("", repo.Activity)
 			m.Get("/:period", repo.Activity)
@@ -838,9 +886,11 @@ func RegisterRoutes(m *macaron.Macaron) {
 		}, context.RepoRef(), repo.MustBeNotEmpty, context.RequireRepoReaderOr(models.UnitTypeCode))
 
 		m.Get("/archive/*", repo.MustBeNotEmpty, reqRepoCodeReader, repo.Download)
+		m.Get("/citation/*", repo.MustBeNotEmpty, reqRepoCodeReader, repo.Citation)
 
 		m.Group("/branches", func() {
 			m.Get("", repo.Branches)
+			m.Get("/stale", repo.StaleBranches)
 		}, repo.MustBeNotEmpty, context.RepoRef(), reqRepoCodeReader)
 
 		m.Group("/pulls/:index", func() {
@@ -849,12 +899,26 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Get("/commits", context.RepoRef(), repo.ViewPullCommits)
 			m.Post("/merge", context.RepoMustNotBeArchived(), reqRepoPullsWriter, bindIgnErr(auth.MergePullRequestForm{}), repo.MergePullRequest)
 			m.Post("/cleanup", context.RepoMustNotBeArchived(), context.RepoRef(), repo.CleanUpPullRequest)
+			m.Post("/ready", context.RepoMustNotBeArchived(), repo.MarkPullReadyForReview)
+			m.Post("/allow_maintainer_edit", context.RepoMustNotBeArchived(), repo.SetAllowMaintainerEdit)
+			m.Post("/merge_queue/remove", context.RepoMustNotBeArchived(), repo.RemoveFromMergeQueue)
+			m.Post("/auto_merge/cancel", context.RepoMustNotBeArchived(), repo.CancelAutoMerge)
+			m.Post("/reviews/rerequest", context.RepoMustNotBeArchived(), repo.ReRequestReview)
+			m.Post("/revert", context.RepoMustNotBeArchived(), reqRepoCodeWriter, repo.RevertPullRequest)
+			m.Combo("/conflicts", reqSignIn, context.RepoMustNotBeArchived()).Get(repo.ResolvePullConflicts).
+				Post(bindIgnErr(auth.ResolveConflictsForm{}), repo.ResolvePullConflictsPost)
+			m.Post("/update", reqSignIn, context.RepoMustNotBeArchived(), repo.UpdatePullRequest)
 			m.Group("/files", func() {
 				m.Get("", context.RepoRef(), repo.SetEditorconfigIfExists, repo.SetDiffViewStyle, repo.SetWhitespaceBehavior, repo.ViewPullFiles)
 				m.Group("/reviews", func() {
 					m.Post("/comments", bindIgnErr(auth.CodeCommentForm{}), repo.CreateCodeComment)
+					m.Post("/comments/:id/resolve", reqSignIn, repo.ResolveConversation)
+					m.Post("/comments/:id/unresolve", reqSignIn, repo.UnresolveConversation)
 					m.Post("/submit", bindIgnErr(auth.SubmitReviewForm{}), repo.SubmitReview)
 				}, context.RepoMustNotBeArchived())
+				m.Post("/suggestions/apply", context.RepoMustNotBeArchived(), bindIgnErr(auth.ApplySuggestionsForm{}), repo.ApplySuggestions)
+				m.Post("/viewed", reqSignIn, repo.MarkPullFileViewed)
+				m.Get("/image_diff", repo.ViewPullImageDiff)
 			})
 		}, repo.MustAllowPulls)
 
@@ -876,6 +940,12 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Get("/*", context.RepoRefByType(context.RepoRefLegacy), repo.SingleDownload)
 		}, repo.MustBeNotEmpty, reqRepoCodeReader)
 
+		m.Group("/snippet", func() {
+			m.Get("/branch/*", context.RepoRefByType(context.RepoRefBranch), repo.Snippet)
+			m.Get("/tag/*", context.RepoRefByType(context.RepoRefTag), repo.Snippet)
+			m.Get("/commit/*", context.RepoRefByType(context.RepoRefCommit), repo.Snippet)
+		}, repo.MustBeNotEmpty, reqRepoCodeReader)
+
 		m.Group("/commits", func() {
 			m.Get("/branch/*", context.RepoRefByType(context.RepoRefBranch), repo.RefCommits)
 			m.Get("/tag/*", context.RepoRefByType(context.RepoRefTag), repo.RefCommits)
@@ -961,6 +1031,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 	handlers = append(handlers, ignSignIn)
 	m.Group("/api", func() {
 		apiv1.RegisterRoutes(m)
+		graphql.RegisterRoutes(m)
 	}, handlers...)
 
 	m.Group("/api/internal", func() {
@@ -7,6 +7,7 @@ package user
 import (
 	"encoding/base64"
 	"fmt"
+	"html"
 	"net/url"
 	"strings"
 
@@ -23,8 +24,9 @@ import (
 )
 
 const (
-	tplGrantAccess base.TplName = "user/auth/grant"
-	tplGrantError  base.TplName = "user/auth/grant_error"
+	tplGrantAccess         base.TplName = "user/auth/grant"
+	tplGrantError          base.TplName = "user/auth/grant_error"
+	tplDeviceAuthorization base.TplName = "user/auth/device"
 )
 
 // TODO move error and responses to SDK or models
@@ -77,8 +79,19 @@ const (
 	AccessTokenErrorCodeUnsupportedGrantType = "unsupported_grant_type"
 	// AccessTokenErrorCodeInvalidScope represents an error code specified in RFC 6749
 	AccessTokenErrorCodeInvalidScope = "invalid_scope"
+	// AccessTokenErrorCodeAuthorizationPending represents an error code specified in RFC 8628
+	AccessTokenErrorCodeAuthorizationPending = "authorization_pending"
+	// AccessTokenErrorCodeSlowDown represents an error code specified in RFC 8628
+	AccessTokenErrorCodeSlowDown = "slow_down"
+	// AccessTokenErrorCodeAccessDenied represents an error code specified in RFC 8628
+	AccessTokenErrorCodeAccessDenied = "access_denied"
+	// AccessTokenErrorCodeExpiredToken represents an error code specified in RFC 8628
+	AccessTokenErrorCodeExpiredToken = "expired_token"
 )
 
+// deviceCodeGrantType is the grant type of the device authorization grant (RFC 8628)
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
 // AccessTokenError represents an error response specified in RFC 6749
 type AccessTokenError struct {
 	ErrorCode        AccessTokenErrorCode `json:"error" form:"error"`
@@ -108,8 +121,24 @@ type AccessTokenResponse struct {
 	RefreshToken string    `json:"refresh_token"`
 }
 
-func newAccessTokenResponse(grant *models.OAuth2Grant) (*AccessTokenResponse, *AccessTokenError) {
-	if setting.OAuth2.InvalidateRefreshTokens {
+// DeviceAuthorizationResponse represents a successful device authorization response (RFC 8628)
+type DeviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+// rotatesRefreshTokens returns true if the refresh tokens issued to the
+// application can be used only once, which they always can for public clients
+func rotatesRefreshTokens(app *models.OAuth2Application) bool {
+	return setting.OAuth2.InvalidateRefreshTokens || !app.ConfidentialClient
+}
+
+func newAccessTokenResponse(grant *models.OAuth2Grant, rotate bool) (*AccessTokenResponse, *AccessTokenError) {
+	if rotate {
 		if err := grant.IncreaseCounter(); err != nil {
 			return nil, &AccessTokenError{
 				ErrorCode:        AccessTokenErrorCodeInvalidGrant,
@@ -210,9 +239,28 @@ func AuthorizeOAuth(ctx *context.Context, form auth.AuthorizationForm) {
 	}
 
 	// pkce support
+	if len(form.CodeChallenge) > 0 && len(form.CodeChallengeMethod) == 0 {
+		// the method defaults to plain, see https://tools.ietf.org/html/rfc7636#section-4.3
+		form.CodeChallengeMethod = "plain"
+	}
+	if !app.ConfidentialClient && form.CodeChallengeMethod != "S256" {
+		handleAuthorizeError(ctx, AuthorizeError{
+			ErrorCode:        ErrorCodeInvalidRequest,
+			ErrorDescription: "PKCE with the S256 code challenge method is required for public clients",
+			State:            form.State,
+		}, form.RedirectURI)
+		return
+	}
 	switch form.CodeChallengeMethod {
-	case "S256":
-	case "plain":
+	case "S256", "plain":
+		if len(form.CodeChallenge) == 0 {
+			handleAuthorizeError(ctx, AuthorizeError{
+				ErrorCode:        ErrorCodeInvalidRequest,
+				ErrorDescription: "the code challenge is missing",
+				State:            form.State,
+			}, form.RedirectURI)
+			return
+		}
 		if err := ctx.Session.Set("CodeChallengeMethod", form.CodeChallengeMethod); err != nil {
 			handleAuthorizeError(ctx, AuthorizeError{
 				ErrorCode:        ErrorCodeServerError,
@@ -221,7 +269,7 @@ func AuthorizeOAuth(ctx *context.Context, form auth.AuthorizationForm) {
 			}, form.RedirectURI)
 			return
 		}
-		if err := ctx.Session.Set("CodeChallengeMethod", form.CodeChallenge); err != nil {
+		if err := ctx.Session.Set("CodeChallenge", form.CodeChallenge); err != nil {
 			handleAuthorizeError(ctx, AuthorizeError{
 				ErrorCode:        ErrorCodeServerError,
 				ErrorDescription: "cannot set code challenge",
@@ -230,7 +278,15 @@ func AuthorizeOAuth(ctx *context.Context, form auth.AuthorizationForm) {
 			return
 		}
 	case "":
-		break
+		// don't reuse the challenge of a previous request
+		if err := ctx.Session.Delete("CodeChallengeMethod"); err != nil {
+			handleServerError(ctx, form.State, form.RedirectURI)
+			return
+		}
+		if err := ctx.Session.Delete("CodeChallenge"); err != nil {
+			handleServerError(ctx, form.State, form.RedirectURI)
+			return
+		}
 	default:
 		handleAuthorizeError(ctx, AuthorizeError{
 			ErrorCode:        ErrorCodeInvalidRequest,
@@ -329,31 +385,60 @@ func GrantApplicationOAuth(ctx *context.Context, form auth.GrantApplicationForm)
 	ctx.Redirect(redirect.String(), 302)
 }
 
+// clientCredentialsFromHeader returns the client id and secret given by the
+// basic auth header of the request, if any
+func clientCredentialsFromHeader(ctx *context.Context) (clientID, clientSecret string, acErr *AccessTokenError) {
+	authHeader := ctx.Req.Header.Get("Authorization")
+	authContent := strings.SplitN(authHeader, " ", 2)
+	if len(authContent) != 2 || authContent[0] != "Basic" {
+		return "", "", nil
+	}
+	payload, err := base64.StdEncoding.DecodeString(authContent[1])
+	if err != nil {
+		return "", "", &AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+			ErrorDescription: "cannot parse basic auth header",
+		}
+	}
+	pair := strings.SplitN(string(payload), ":", 2)
+	if len(pair) != 2 {
+		return "", "", &AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+			ErrorDescription: "cannot parse basic auth header",
+		}
+	}
+	return pair[0], pair[1], nil
+}
+
+// authenticateClient returns the application of the client, whose secret is
+// validated unless it is a public client
+func authenticateClient(clientID, clientSecret string) (*models.OAuth2Application, *AccessTokenError) {
+	app, err := models.GetOAuth2ApplicationByClientID(clientID)
+	if err != nil {
+		return nil, &AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidClient,
+			ErrorDescription: fmt.Sprintf("cannot load client with client id: '%s'", clientID),
+		}
+	}
+	if app.ConfidentialClient && !app.ValidateClientSecret([]byte(clientSecret)) {
+		return nil, &AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeUnauthorizedClient,
+			ErrorDescription: "client is not authorized",
+		}
+	}
+	return app, nil
+}
+
 // AccessTokenOAuth manages all access token requests by the client
 func AccessTokenOAuth(ctx *context.Context, form auth.AccessTokenForm) {
 	if form.ClientID == "" {
-		authHeader := ctx.Req.Header.Get("Authorization")
-		authContent := strings.SplitN(authHeader, " ", 2)
-		if len(authContent) == 2 && authContent[0] == "Basic" {
-			payload, err := base64.StdEncoding.DecodeString(authContent[1])
-			if err != nil {
-				handleAccessTokenError(ctx, AccessTokenError{
-					ErrorCode:        AccessTokenErrorCodeInvalidRequest,
-					ErrorDescription: "cannot parse basic auth header",
-				})
-				return
-			}
-			pair := strings.SplitN(string(payload), ":", 2)
-			if len(pair) != 2 {
-				handleAccessTokenError(ctx, AccessTokenError{
-					ErrorCode:        AccessTokenErrorCodeInvalidRequest,
-					ErrorDescription: "cannot parse basic auth header",
-				})
-				return
-			}
-			form.ClientID = pair[0]
-			form.ClientSecret = pair[1]
+		clientID, clientSecret, acErr := clientCredentialsFromHeader(ctx)
+		if acErr != nil {
+			handleAccessTokenError(ctx, *acErr)
+			return
 		}
+		form.ClientID = clientID
+		form.ClientSecret = clientSecret
 	}
 	switch form.GrantType {
 	case "refresh_token":
@@ -362,17 +447,20 @@ func AccessTokenOAuth(ctx *context.Context, form auth.AccessTokenForm) {
 	case "authorization_code":
 		handleAuthorizationCode(ctx, form)
 		return
+	case deviceCodeGrantType:
+		handleDeviceCode(ctx, form)
+		return
 	default:
 		handleAccessTokenError(ctx, AccessTokenError{
 			ErrorCode:        AccessTokenErrorCodeUnsupportedGrantType,
-			ErrorDescription: "Only refresh_token or authorization_code grant type is supported",
+			ErrorDescription: "Only refresh_token, authorization_code or " + deviceCodeGrantType + " grant type is supported",
 		})
 	}
 }
 
 func handleRefreshToken(ctx *context.Context, form auth.AccessTokenForm) {
 	token, err := models.ParseOAuth2Token(form.RefreshToken)
-	if err != nil {
+	if err != nil || token.Type != models.TypeRefreshToken {
 		handleAccessTokenError(ctx, AccessTokenError{
 			ErrorCode:        AccessTokenErrorCodeUnauthorizedClient,
 			ErrorDescription: "client is not authorized",
@@ -388,17 +476,48 @@ func handleRefreshToken(ctx *context.Context, form auth.AccessTokenForm) {
 		})
 		return
 	}
+	// public clients may omit their client id, the client is then the one
+	// the grant was issued to, only confidential clients have to authenticate
+	if form.ClientID == "" {
+		grantApp, err := models.GetOAuth2ApplicationByID(grant.ApplicationID)
+		if err != nil {
+			handleAccessTokenError(ctx, AccessTokenError{
+				ErrorCode:        AccessTokenErrorCodeInvalidGrant,
+				ErrorDescription: "grant does not exist",
+			})
+			return
+		}
+		form.ClientID = grantApp.ClientID
+	}
+	app, tokenErr := authenticateClient(form.ClientID, form.ClientSecret)
+	if tokenErr != nil {
+		handleAccessTokenError(ctx, *tokenErr)
+		return
+	}
+	if grant.ApplicationID != app.ID {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidGrant,
+			ErrorDescription: "grant does not exist",
+		})
+		return
+	}
 
 	// check if token got already used
-	if setting.OAuth2.InvalidateRefreshTokens && (grant.Counter != token.Counter || token.Counter == 0) {
+	rotate := rotatesRefreshTokens(app)
+	if rotate && (grant.Counter != token.Counter || token.Counter == 0) {
+		// the token may have been stolen, revoke the grant so that none of
+		// the tokens issued for it can be used anymore
+		if err := models.RevokeOAuth2Grant(grant.ID, grant.UserID); err != nil {
+			log.Error("RevokeOAuth2Grant: %v", err)
+		}
 		handleAccessTokenError(ctx, AccessTokenError{
 			ErrorCode:        AccessTokenErrorCodeUnauthorizedClient,
-			ErrorDescription: "token was already used",
+			ErrorDescription: "token was already used, the grant has been revoked",
 		})
-		log.Warn("A client tried to use a refresh token for grant_id = %d was used twice!", grant.ID)
+		log.Warn("A client tried to use a refresh token for grant_id = %d was used twice! The grant has been revoked.", grant.ID)
 		return
 	}
-	accessToken, tokenErr := newAccessTokenResponse(grant)
+	accessToken, tokenErr := newAccessTokenResponse(grant, rotate)
 	if tokenErr != nil {
 		handleAccessTokenError(ctx, *tokenErr)
 		return
@@ -407,19 +526,9 @@ func handleRefreshToken(ctx *context.Context, form auth.AccessTokenForm) {
 }
 
 func handleAuthorizationCode(ctx *context.Context, form auth.AccessTokenForm) {
-	app, err := models.GetOAuth2ApplicationByClientID(form.ClientID)
-	if err != nil {
-		handleAccessTokenError(ctx, AccessTokenError{
-			ErrorCode:        AccessTokenErrorCodeInvalidClient,
-			ErrorDescription: fmt.Sprintf("cannot load client with client id: '%s'", form.ClientID),
-		})
-		return
-	}
-	if !app.ValidateClientSecret([]byte(form.ClientSecret)) {
-		handleAccessTokenError(ctx, AccessTokenError{
-			ErrorCode:        AccessTokenErrorCodeUnauthorizedClient,
-			ErrorDescription: "client is not authorized",
-		})
+	app, tokenErr := authenticateClient(form.ClientID, form.ClientSecret)
+	if tokenErr != nil {
+		handleAccessTokenError(ctx, *tokenErr)
 		return
 	}
 	if form.RedirectURI != "" && !app.ContainsRedirectURI(form.RedirectURI) {
@@ -437,8 +546,10 @@ func handleAuthorizationCode(ctx *context.Context, form auth.AccessTokenForm) {
 		})
 		return
 	}
-	// check if code verifier authorizes the client, PKCE support
-	if !authorizationCode.ValidateCodeChallenge(form.CodeVerifier) {
+	// check if code verifier authorizes the client, PKCE support, which
+	// public clients must use as they have no secret
+	if (!app.ConfidentialClient && !authorizationCode.HasCodeChallenge()) ||
+		!authorizationCode.ValidateCodeChallenge(form.CodeVerifier) {
 		handleAccessTokenError(ctx, AccessTokenError{
 			ErrorCode:        AccessTokenErrorCodeUnauthorizedClient,
 			ErrorDescription: "client is not authorized",
@@ -459,8 +570,9 @@ func handleAuthorizationCode(ctx *context.Context, form auth.AccessTokenForm) {
 			ErrorCode:        AccessTokenErrorCodeInvalidRequest,
 			ErrorDescription: "cannot proceed your request",
 		})
+		return
 	}
-	resp, tokenErr := newAccessTokenResponse(authorizationCode.Grant)
+	resp, tokenErr := newAccessTokenResponse(authorizationCode.Grant, rotatesRefreshTokens(app))
 	if tokenErr != nil {
 		handleAccessTokenError(ctx, *tokenErr)
 		return
@@ -469,6 +581,210 @@ func handleAuthorizationCode(ctx *context.Context, form auth.AccessTokenForm) {
 	ctx.JSON(200, resp)
 }
 
+func handleDeviceCode(ctx *context.Context, form auth.AccessTokenForm) {
+	app, tokenErr := authenticateClient(form.ClientID, form.ClientSecret)
+	if tokenErr != nil {
+		handleAccessTokenError(ctx, *tokenErr)
+		return
+	}
+	code, err := models.GetOAuth2DeviceCodeByDeviceCode(form.DeviceCode)
+	if err != nil || code == nil || code.ApplicationID != app.ID {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidGrant,
+			ErrorDescription: "invalid device code",
+		})
+		return
+	}
+	if code.IsExpired() {
+		if err := code.Delete(); err != nil {
+			log.Error("Delete: %v", err)
+		}
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeExpiredToken,
+			ErrorDescription: "the device code has expired",
+		})
+		return
+	}
+
+	switch code.Status {
+	case models.OAuth2DeviceCodeStatusDenied:
+		if err := code.Delete(); err != nil {
+			log.Error("Delete: %v", err)
+		}
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeAccessDenied,
+			ErrorDescription: "the user denied the authorization request",
+		})
+		return
+	case models.OAuth2DeviceCodeStatusPending:
+		ok, err := code.Poll()
+		if err != nil {
+			handleAccessTokenError(ctx, AccessTokenError{
+				ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+				ErrorDescription: "cannot proceed your request",
+			})
+			return
+		}
+		if !ok {
+			handleAccessTokenError(ctx, AccessTokenError{
+				ErrorCode:        AccessTokenErrorCodeSlowDown,
+				ErrorDescription: fmt.Sprintf("poll at most every %d seconds", code.Interval),
+			})
+			return
+		}
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeAuthorizationPending,
+			ErrorDescription: "the user has not answered the authorization request yet",
+		})
+		return
+	}
+
+	// remove the device code to deny duplicate usage, only one of concurrent
+	// polls gets the tokens
+	consumed, err := code.Consume()
+	if err != nil {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+			ErrorDescription: "cannot proceed your request",
+		})
+		return
+	} else if !consumed {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidGrant,
+			ErrorDescription: "invalid device code",
+		})
+		return
+	}
+	grant, err := app.GetGrantByUserID(code.UserID)
+	if err == nil && grant == nil {
+		grant, err = app.CreateGrant(code.UserID)
+	}
+	if err != nil {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+			ErrorDescription: "cannot proceed your request",
+		})
+		return
+	}
+	resp, tokenErr := newAccessTokenResponse(grant, rotatesRefreshTokens(app))
+	if tokenErr != nil {
+		handleAccessTokenError(ctx, *tokenErr)
+		return
+	}
+	ctx.JSON(200, resp)
+}
+
+// DeviceAuthorizationOAuth starts the device authorization grant of a client (RFC 8628)
+func DeviceAuthorizationOAuth(ctx *context.Context, form auth.DeviceAuthorizationForm) {
+	if form.ClientID == "" {
+		clientID, clientSecret, acErr := clientCredentialsFromHeader(ctx)
+		if acErr != nil {
+			handleAccessTokenError(ctx, *acErr)
+			return
+		}
+		form.ClientID = clientID
+		form.ClientSecret = clientSecret
+	}
+	app, tokenErr := authenticateClient(form.ClientID, form.ClientSecret)
+	if tokenErr != nil {
+		handleAccessTokenError(ctx, *tokenErr)
+		return
+	}
+	code, err := app.CreateDeviceCode()
+	if err != nil {
+		log.Error("CreateDeviceCode: %v", err)
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+			ErrorDescription: "cannot proceed your request",
+		})
+		return
+	}
+	verificationURI := setting.AppURL + "login/device"
+	ctx.JSON(200, &DeviceAuthorizationResponse{
+		DeviceCode:              code.DeviceCode,
+		UserCode:                code.UserCode,
+		VerificationURI:         verificationURI,
+		VerificationURIComplete: verificationURI + "?user_code=" + url.QueryEscape(code.UserCode),
+		ExpiresIn:               setting.OAuth2.DeviceCodeExpirationTime,
+		Interval:                code.Interval,
+	})
+}
+
+// loadPendingDeviceCode returns the device authorization request with the
+// given user code, and renders an error if it can't be answered
+func loadPendingDeviceCode(ctx *context.Context, userCode string) *models.OAuth2DeviceCode {
+	code, err := models.GetOAuth2DeviceCodeByUserCode(userCode)
+	if err != nil {
+		ctx.ServerError("GetOAuth2DeviceCodeByUserCode", err)
+		return nil
+	}
+	if code == nil || code.IsExpired() || code.Status != models.OAuth2DeviceCodeStatusPending {
+		ctx.Data["user_code"] = userCode
+		ctx.Data["Err_UserCode"] = true
+		ctx.RenderWithErr(ctx.Tr("auth.device_code_invalid"), tplDeviceAuthorization, nil)
+		return nil
+	}
+	if err := code.LoadApplication(); err != nil {
+		ctx.ServerError("LoadApplication", err)
+		return nil
+	}
+	if err := code.Application.LoadUser(); err != nil {
+		ctx.ServerError("LoadUser", err)
+		return nil
+	}
+	return code
+}
+
+// DeviceAuthorization shows the page where the user enters the code displayed
+// by a device, and then the authorization request of the device
+func DeviceAuthorization(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("auth.device_authorization")
+
+	userCode := ctx.Query("user_code")
+	if len(userCode) == 0 {
+		ctx.HTML(200, tplDeviceAuthorization)
+		return
+	}
+	code := loadPendingDeviceCode(ctx, userCode)
+	if code == nil {
+		return
+	}
+	ctx.Data["DeviceCode"] = code
+	ctx.Data["ApplicationUserLink"] = "<a href=\"" + setting.AppURL + code.Application.User.LowerName + "\">@" + code.Application.User.Name + "</a>"
+	ctx.HTML(200, tplDeviceAuthorization)
+}
+
+// DeviceAuthorizationPost records the answer of the user to the authorization request of a device
+func DeviceAuthorizationPost(ctx *context.Context, form auth.DeviceGrantForm) {
+	ctx.Data["Title"] = ctx.Tr("auth.device_authorization")
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplDeviceAuthorization)
+		return
+	}
+	code := loadPendingDeviceCode(ctx, form.UserCode)
+	if code == nil {
+		return
+	}
+	answered, err := code.Answer(ctx.User.ID, form.Granted)
+	if err != nil {
+		ctx.ServerError("Answer", err)
+		return
+	} else if !answered {
+		// the request has been answered or has expired in the meantime
+		ctx.Data["user_code"] = form.UserCode
+		ctx.Data["Err_UserCode"] = true
+		ctx.RenderWithErr(ctx.Tr("auth.device_code_invalid"), tplDeviceAuthorization, nil)
+		return
+	}
+	if form.Granted {
+		ctx.Flash.Success(ctx.Tr("auth.device_authorization_granted", html.EscapeString(code.Application.Name)))
+	} else {
+		ctx.Flash.Info(ctx.Tr("auth.device_authorization_denied", html.EscapeString(code.Application.Name)))
+	}
+	ctx.Redirect(setting.AppSubURL + "/login/device")
+}
+
 func handleAccessTokenError(ctx *context.Context, acErr AccessTokenError) {
 	ctx.JSON(400, acErr)
 }
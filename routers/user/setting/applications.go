@@ -39,9 +39,25 @@ func ApplicationsPost(ctx *context.Context, form auth.NewAccessTokenForm) {
 		return
 	}
 
+	scope, err := models.NewAccessTokenScope(form.Scopes...)
+	if err != nil {
+		if models.IsErrInvalidAccessTokenScope(err) {
+			ctx.Flash.Error(ctx.Tr("settings.token_scope_invalid", err.(models.ErrInvalidAccessTokenScope).Scope))
+			ctx.Redirect(setting.AppSubURL + "/user/settings/applications")
+			return
+		}
+		ctx.ServerError("NewAccessTokenScope", err)
+		return
+	} else if len(scope) == 0 {
+		ctx.Flash.Error(ctx.Tr("settings.token_scopes_required"))
+		ctx.Redirect(setting.AppSubURL + "/user/settings/applications")
+		return
+	}
+
 	t := &models.AccessToken{
-		UID:  ctx.User.ID,
-		Name: form.Name,
+		UID:   ctx.User.ID,
+		Name:  form.Name,
+		Scope: scope,
 	}
 	if err := models.NewAccessToken(t); err != nil {
 		ctx.ServerError("NewAccessToken", err)
@@ -74,6 +90,7 @@ func loadApplicationsData(ctx *context.Context) {
 		return
 	}
 	ctx.Data["Tokens"] = tokens
+	ctx.Data["AccessTokenScopes"] = models.AccessTokenScopes
 	ctx.Data["EnableOAuth2"] = setting.OAuth2.Enable
 	if setting.OAuth2.Enable {
 		ctx.Data["Applications"], err = models.GetOAuth2ApplicationsByUserID(ctx.User.ID)
@@ -15,8 +15,17 @@ import (
 	"code.gitea.io/gitea/services/gitdiff"
 )
 
-// CreateCodeComment creates a plain code comment at the specified line / path
-func CreateCodeComment(doer *models.User, repo *models.Repository, issue *models.Issue, content, treePath string, line, reviewID int64) (*models.Comment, error) {
+// CreateCodeComment creates a plain code comment at the specified line / path.
+// A non zero start line makes the comment span the lines from the start line
+// to the line, both have to be on the same side.
+func CreateCodeComment(doer *models.User, repo *models.Repository, issue *models.Issue, content, treePath string, startLine, line, reviewID int64) (*models.Comment, error) {
+	if startLine == line {
+		startLine = 0
+	}
+	if startLine != 0 && ((startLine < 0) != (line < 0) || (line > 0 && startLine > line) || (line < 0 && startLine < line)) {
+		return nil, models.ErrInvalidCodeCommentRange{StartLine: startLine, Line: line}
+	}
+
 	var commitID, patch string
 	pr, err := models.GetPullRequestByIssueID(issue.ID)
 	if err != nil {
@@ -52,18 +61,25 @@ func CreateCodeComment(doer *models.User, repo *models.Repository, issue *models
 		if err := gitdiff.GetRawDiffForFile(gitRepo.Path, pr.MergeBase, headCommitID, gitdiff.RawDiffNormal, treePath, patchBuf); err != nil {
 			return nil, fmt.Errorf("GetRawDiffForLine[%s, %s, %s, %s]: %v", err, gitRepo.Path, pr.MergeBase, headCommitID, treePath)
 		}
-		patch = gitdiff.CutDiffAroundLine(patchBuf, int64((&models.Comment{Line: line}).UnsignedLine()), line < 0, setting.UI.CodeCommentLines)
+		// The patch contains all the commented lines
+		comment := &models.Comment{Line: line, StartLine: startLine}
+		numberOfLines := setting.UI.CodeCommentLines
+		if rangeLines := int(comment.UnsignedLine()-comment.UnsignedStartLine()) + 1; rangeLines > numberOfLines {
+			numberOfLines = rangeLines
+		}
+		patch = gitdiff.CutDiffAroundLine(patchBuf, int64(comment.UnsignedLine()), line < 0, numberOfLines)
 	}
 	return models.CreateComment(&models.CreateCommentOptions{
-		Type:      models.CommentTypeCode,
-		Doer:      doer,
-		Repo:      repo,
-		Issue:     issue,
-		Content:   content,
-		LineNum:   line,
-		TreePath:  treePath,
-		CommitSHA: commitID,
-		ReviewID:  reviewID,
-		Patch:     patch,
+		Type:         models.CommentTypeCode,
+		Doer:         doer,
+		Repo:         repo,
+		Issue:        issue,
+		Content:      content,
+		LineNum:      line,
+		StartLineNum: startLine,
+		TreePath:     treePath,
+		CommitSHA:    commitID,
+		ReviewID:     reviewID,
+		Patch:        patch,
 	})
 }
@@ -21,12 +21,15 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/analyze"
+	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/charset"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/highlight"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/process"
 	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
 
 	"github.com/sergi/go-diff/diffmatchpatch"
 	"github.com/unknwon/com"
@@ -63,6 +66,12 @@ type DiffLine struct {
 	Type     DiffLineType
 	Content  string
 	Comments []*models.Comment
+	// InLeftCommentRange and InRightCommentRange are true if the line of
+	// the previous or proposed changes is commented by a multi-line comment
+	InLeftCommentRange  bool
+	InRightCommentRange bool
+	// Annotations of check runs about the line of the proposed changes
+	Annotations []*models.CheckRunAnnotation
 }
 
 // GetType returns the type of a DiffLine.
@@ -83,6 +92,11 @@ func (d *DiffLine) GetCommentSide() string {
 	return d.Comments[0].DiffSide()
 }
 
+// IsInCommentRange returns true if the line is commented by a multi-line comment
+func (d *DiffLine) IsInCommentRange() bool {
+	return d.InLeftCommentRange || d.InRightCommentRange
+}
+
 // GetLineTypeMarker returns the line type marker
 func (d *DiffLine) GetLineTypeMarker() string {
 	if strings.IndexByte(" +-", d.Content[0]) > -1 {
@@ -221,6 +235,154 @@ func (diffSection *DiffSection) GetComputedInlineDiffFor(diffLine *DiffLine) tem
 	return diffToHTML(diffRecord, diffLine.Type)
 }
 
+// getInlineDiff returns the changes between a deleted line and the added line replacing it
+func getInlineDiff(delLine, addLine *DiffLine) []diffmatchpatch.Diff {
+	diffRecord := diffMatchPatch.DiffMain(delLine.Content[1:], addLine.Content[1:], true)
+	return diffMatchPatch.DiffCleanupEfficiency(diffRecord)
+}
+
+// DiffSplitLine represents a row of the split view of a DiffSection. Deleted
+// lines are shown next to the added lines replacing them, unchanged lines
+// are shown on both sides.
+type DiffSplitLine struct {
+	Left  *DiffLine
+	Right *DiffLine
+}
+
+// GetSplitLines returns the rows of the split view of the section
+func (diffSection *DiffSection) GetSplitLines() []*DiffSplitLine {
+	lines := make([]*DiffSplitLine, 0, len(diffSection.Lines))
+	var dels, adds []*DiffLine
+	flush := func() {
+		for i := 0; i < len(dels) || i < len(adds); i++ {
+			line := &DiffSplitLine{}
+			if i < len(dels) {
+				line.Left = dels[i]
+			}
+			if i < len(adds) {
+				line.Right = adds[i]
+			}
+			lines = append(lines, line)
+		}
+		dels, adds = nil, nil
+	}
+
+	for _, diffLine := range diffSection.Lines {
+		switch diffLine.Type {
+		case DiffLineDel:
+			// Deletions after additions start a new block of changes
+			if len(adds) > 0 {
+				flush()
+			}
+			dels = append(dels, diffLine)
+		case DiffLineAdd:
+			adds = append(adds, diffLine)
+		default:
+			flush()
+			lines = append(lines, &DiffSplitLine{Left: diffLine, Right: diffLine})
+		}
+	}
+	flush()
+	return lines
+}
+
+// IsChanged returns true if the left line is replaced by the right line
+func (line *DiffSplitLine) IsChanged() bool {
+	return line.Left != nil && line.Right != nil && line.Left != line.Right
+}
+
+// GetType returns the type of the row, the type of a changed row is the one of
+// the right line.
+func (line *DiffSplitLine) GetType() int {
+	if line.Right != nil {
+		return line.Right.GetType()
+	}
+	return line.Left.GetType()
+}
+
+// GetLeftIdx returns the line number of the left line, 0 if there is none
+func (line *DiffSplitLine) GetLeftIdx() int {
+	if line.Left == nil {
+		return 0
+	}
+	return line.Left.LeftIdx
+}
+
+// GetRightIdx returns the line number of the right line, 0 if there is none
+func (line *DiffSplitLine) GetRightIdx() int {
+	if line.Right == nil {
+		return 0
+	}
+	return line.Right.RightIdx
+}
+
+// CanCommentLeft returns whether or not the left line can get commented
+func (line *DiffSplitLine) CanCommentLeft() bool {
+	return line.GetLeftIdx() > 0 && line.Left.CanComment()
+}
+
+// CanCommentRight returns whether or not the right line can get commented
+func (line *DiffSplitLine) CanCommentRight() bool {
+	return line.GetRightIdx() > 0 && line.Right.CanComment()
+}
+
+// GetLeftComments returns the comments of the previous changes shown below the left line
+func (line *DiffSplitLine) GetLeftComments() []*models.Comment {
+	if line.Left == nil || line.Left.GetCommentSide() != "previous" {
+		return nil
+	}
+	return line.Left.Comments
+}
+
+// GetRightComments returns the comments of the proposed changes shown below the right line
+func (line *DiffSplitLine) GetRightComments() []*models.Comment {
+	if line.Right == nil || line.Right.GetCommentSide() != "proposed" {
+		return nil
+	}
+	return line.Right.Comments
+}
+
+// GetRightAnnotations returns the annotations of check runs shown below the right line
+func (line *DiffSplitLine) GetRightAnnotations() []*models.CheckRunAnnotation {
+	if line.Right == nil {
+		return nil
+	}
+	return line.Right.Annotations
+}
+
+// IsLeftInCommentRange returns true if the left line is commented by a multi-line comment
+func (line *DiffSplitLine) IsLeftInCommentRange() bool {
+	return line.Left != nil && line.Left.InLeftCommentRange
+}
+
+// IsRightInCommentRange returns true if the right line is commented by a multi-line comment
+func (line *DiffSplitLine) IsRightInCommentRange() bool {
+	return line.Right != nil && line.Right.InRightCommentRange
+}
+
+// GetLeftContent returns the content of the left line with the changes highlighted
+func (line *DiffSplitLine) GetLeftContent() template.HTML {
+	if line.GetLeftIdx() == 0 {
+		return ""
+	}
+	return line.getContent(line.Left)
+}
+
+// GetRightContent returns the content of the right line with the changes highlighted
+func (line *DiffSplitLine) GetRightContent() template.HTML {
+	if line.GetRightIdx() == 0 {
+		return ""
+	}
+	return line.getContent(line.Right)
+}
+
+func (line *DiffSplitLine) getContent(diffLine *DiffLine) template.HTML {
+	if line.IsChanged() && !setting.Git.DisableDiffHighlight {
+		return diffToHTML(getInlineDiff(line.Left, line.Right), diffLine.Type)
+	}
+	return template.HTML(getLineContent(diffLine.Content[1:]))
+}
+
 // DiffFile represents a file diff.
 type DiffFile struct {
 	Name               string
@@ -234,6 +396,8 @@ type DiffFile struct {
 	IsLFSFile          bool
 	IsRenamed          bool
 	IsSubmodule        bool
+	IsGenerated        bool
+	IsVendored         bool
 	Sections           []*DiffSection
 	IsIncomplete       bool
 }
@@ -243,6 +407,20 @@ func (diffFile *DiffFile) GetType() int {
 	return int(diffFile.Type)
 }
 
+// GetDiffHash returns a hash of the changes of the file, which changes as
+// soon as the file is changed differently.
+func (diffFile *DiffFile) GetDiffHash() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%s\n%s\n%d\n%t\n", diffFile.OldName, diffFile.Name, diffFile.Type, diffFile.IsBin)
+	for _, section := range diffFile.Sections {
+		for _, line := range section.Lines {
+			buf.WriteString(line.Content)
+			buf.WriteByte('\n')
+		}
+	}
+	return base.EncodeSha1(buf.String())
+}
+
 // GetHighlightClass returns highlight class for a filename.
 func (diffFile *DiffFile) GetHighlightClass() string {
 	return highlight.FileNameToHighlightClass(diffFile.Name)
@@ -255,6 +433,111 @@ type Diff struct {
 	IsIncomplete                 bool
 }
 
+var diffLineTypeNames = map[DiffLineType]string{
+	DiffLinePlain: "plain",
+	DiffLineAdd:   "add",
+	DiffLineDel:   "del",
+}
+
+var diffFileTypeNames = map[DiffFileType]string{
+	DiffFileAdd:    "added",
+	DiffFileChange: "changed",
+	DiffFileDel:    "deleted",
+	DiffFileRename: "renamed",
+}
+
+// APIFormat converts the diff to the api.Diff format, the sections contain
+// the rows of the split view if split is true and the lines of the unified
+// view otherwise.
+func (diff *Diff) APIFormat(split bool) *api.Diff {
+	apiDiff := &api.Diff{
+		TotalFiles:     diff.NumFiles(),
+		TotalAdditions: diff.TotalAddition,
+		TotalDeletions: diff.TotalDeletion,
+		Files:          make([]*api.DiffFile, 0, len(diff.Files)),
+		IsIncomplete:   diff.IsIncomplete,
+	}
+	for _, diffFile := range diff.Files {
+		apiFile := &api.DiffFile{
+			Name:         diffFile.Name,
+			OldName:      diffFile.OldName,
+			Status:       diffFileTypeNames[diffFile.Type],
+			Additions:    diffFile.Addition,
+			Deletions:    diffFile.Deletion,
+			IsBinary:     diffFile.IsBin,
+			IsSubmodule:  diffFile.IsSubmodule,
+			IsGenerated:  diffFile.IsGenerated,
+			IsVendored:   diffFile.IsVendored,
+			IsIncomplete: diffFile.IsIncomplete,
+			Sections:     make([]*api.DiffSection, 0, len(diffFile.Sections)),
+		}
+		for _, section := range diffFile.Sections {
+			apiFile.Sections = append(apiFile.Sections, section.apiFormat(split))
+		}
+		apiDiff.Files = append(apiDiff.Files, apiFile)
+	}
+	return apiDiff
+}
+
+func (diffSection *DiffSection) apiFormat(split bool) *api.DiffSection {
+	apiSection := &api.DiffSection{}
+	if split {
+		for _, line := range diffSection.GetSplitLines() {
+			if line.GetType() == int(DiffLineSection) {
+				apiSection.Header = line.Left.Content
+				continue
+			}
+			apiLine := &api.DiffSplitLine{}
+			if line.Left != nil {
+				apiLine.Left = line.Left.apiFormat()
+			}
+			if line.Right != nil {
+				apiLine.Right = line.Right.apiFormat()
+			}
+			if line.IsChanged() {
+				diffRecord := getInlineDiff(line.Left, line.Right)
+				apiLine.Left.Segments = diffToSegments(diffRecord, DiffLineDel)
+				apiLine.Right.Segments = diffToSegments(diffRecord, DiffLineAdd)
+			}
+			apiSection.SplitLines = append(apiSection.SplitLines, apiLine)
+		}
+		return apiSection
+	}
+
+	for _, line := range diffSection.Lines {
+		if line.Type == DiffLineSection {
+			apiSection.Header = line.Content
+			continue
+		}
+		apiSection.Lines = append(apiSection.Lines, line.apiFormat())
+	}
+	return apiSection
+}
+
+func (d *DiffLine) apiFormat() *api.DiffLine {
+	return &api.DiffLine{
+		Type:    diffLineTypeNames[d.Type],
+		OldLine: d.LeftIdx,
+		NewLine: d.RightIdx,
+		Content: d.Content[1:],
+	}
+}
+
+// diffToSegments returns the parts of the content of the line of the given type
+func diffToSegments(diffs []diffmatchpatch.Diff, lineType DiffLineType) []*api.DiffSegment {
+	segments := make([]*api.DiffSegment, 0, len(diffs))
+	for i := range diffs {
+		switch {
+		case diffs[i].Type == diffmatchpatch.DiffInsert && lineType == DiffLineAdd,
+			diffs[i].Type == diffmatchpatch.DiffDelete && lineType == DiffLineDel:
+			segments = append(segments, &api.DiffSegment{Text: diffs[i].Text, Changed: true})
+		case diffs[i].Type == diffmatchpatch.DiffEqual:
+			segments = append(segments, &api.DiffSegment{Text: diffs[i].Text})
+		}
+	}
+	return segments
+}
+
 // LoadComments loads comments into each line
 func (diff *Diff) LoadComments(issue *models.Issue, currentUser *models.User) error {
 	allComments, err := models.FetchCodeComments(issue, currentUser)
@@ -263,8 +546,24 @@ func (diff *Diff) LoadComments(issue *models.Issue, currentUser *models.User) er
 	}
 	for _, file := range diff.Files {
 		if lineCommits, ok := allComments[file.Name]; ok {
+			var rangeComments []*models.Comment
+			for _, comments := range lineCommits {
+				for _, comment := range comments {
+					if comment.IsMultiLine() {
+						rangeComments = append(rangeComments, comment)
+					}
+				}
+			}
 			for _, section := range file.Sections {
 				for _, line := range section.Lines {
+					for _, comment := range rangeComments {
+						if line.LeftIdx > 0 && comment.IsInLineRange(int64(line.LeftIdx*-1)) {
+							line.InLeftCommentRange = true
+						}
+						if line.RightIdx > 0 && comment.IsInLineRange(int64(line.RightIdx)) {
+							line.InRightCommentRange = true
+						}
+					}
 					if comments, ok := lineCommits[int64(line.LeftIdx*-1)]; ok {
 						line.Comments = append(line.Comments, comments...)
 					}
@@ -281,6 +580,30 @@ func (diff *Diff) LoadComments(issue *models.Issue, currentUser *models.User) er
 	return nil
 }
 
+// LoadAnnotations attaches the annotations of check runs, mapped by paths, to
+// the lines of the proposed changes they end at. Annotations of lines which
+// are not part of the diff are not shown.
+func (diff *Diff) LoadAnnotations(annotations map[string][]*models.CheckRunAnnotation) {
+	for _, file := range diff.Files {
+		fileAnnotations, ok := annotations[file.Name]
+		if !ok {
+			continue
+		}
+		for _, section := range file.Sections {
+			for _, line := range section.Lines {
+				if line.RightIdx <= 0 {
+					continue
+				}
+				for _, a := range fileAnnotations {
+					if a.LastLine() == line.RightIdx {
+						line.Annotations = append(line.Annotations, a)
+					}
+				}
+			}
+		}
+	}
+}
+
 // NumFiles returns number of files changes in a diff.
 func (diff *Diff) NumFiles() int {
 	return len(diff.Files)
@@ -674,6 +997,13 @@ func GetDiffRange(repoPath, beforeCommitID, afterCommitID string, maxLines, maxL
 // Passing the empty string as beforeCommitID returns a diff from the parent commit.
 // The whitespaceBehavior is either an empty string or a git flag
 func GetDiffRangeWithWhitespaceBehavior(repoPath, beforeCommitID, afterCommitID string, maxLines, maxLineCharacters, maxFiles int, whitespaceBehavior string) (*Diff, error) {
+	return GetDiffRangeForFiles(repoPath, beforeCommitID, afterCommitID, maxLines, maxLineCharacters, maxFiles, whitespaceBehavior, nil)
+}
+
+// GetDiffRangeForFiles builds a Diff between two commits of a repository
+// which only contains the given files, or all files if there are none.
+// The file names are matched literally.
+func GetDiffRangeForFiles(repoPath, beforeCommitID, afterCommitID string, maxLines, maxLineCharacters, maxFiles int, whitespaceBehavior string, files []string) (*Diff, error) {
 	gitRepo, err := git.OpenRepository(repoPath)
 	if err != nil {
 		return nil, err
@@ -684,23 +1014,30 @@ func GetDiffRangeWithWhitespaceBehavior(repoPath, beforeCommitID, afterCommitID
 		return nil, err
 	}
 
-	var cmd *exec.Cmd
+	var args []string
+	if len(files) > 0 {
+		args = append(args, "--literal-pathspecs")
+	}
 	if len(beforeCommitID) == 0 && commit.ParentCount() == 0 {
-		cmd = exec.Command(git.GitExecutable, "show", afterCommitID)
+		args = append(args, "show", afterCommitID)
 	} else {
 		actualBeforeCommitID := beforeCommitID
 		if len(actualBeforeCommitID) == 0 {
 			parentCommit, _ := commit.Parent(0)
 			actualBeforeCommitID = parentCommit.ID.String()
 		}
-		diffArgs := []string{"diff", "-M"}
+		args = append(args, "diff", "-M")
 		if len(whitespaceBehavior) != 0 {
-			diffArgs = append(diffArgs, whitespaceBehavior)
+			args = append(args, whitespaceBehavior)
 		}
-		diffArgs = append(diffArgs, actualBeforeCommitID)
-		diffArgs = append(diffArgs, afterCommitID)
-		cmd = exec.Command(git.GitExecutable, diffArgs...)
+		args = append(args, actualBeforeCommitID)
+		args = append(args, afterCommitID)
+	}
+	if len(files) > 0 {
+		args = append(args, "--")
+		args = append(args, files...)
 	}
+	cmd := exec.Command(git.GitExecutable, args...)
 	cmd.Dir = repoPath
 	cmd.Stderr = os.Stderr
 
@@ -725,9 +1062,31 @@ func GetDiffRangeWithWhitespaceBehavior(repoPath, beforeCommitID, afterCommitID
 		return nil, fmt.Errorf("Wait: %v", err)
 	}
 
+	attrs, err := commit.GetAttributes()
+	if err != nil {
+		return nil, fmt.Errorf("GetAttributes: %v", err)
+	}
+	diff.setLinguistAttributes(attrs)
+
 	return diff, nil
 }
 
+// setLinguistAttributes marks the files which are generated or vendored
+// according to the .gitattributes file, their changes are not counted in the
+// totals of the diff. Unlike for the language statistics the vendored files
+// have to be marked explicitly, the changes of the dependencies matter.
+func (diff *Diff) setLinguistAttributes(attrs *git.Attributes) {
+	for _, diffFile := range diff.Files {
+		fileAttrs := attrs.Get(diffFile.Name)
+		diffFile.IsGenerated = analyze.IsGenerated(fileAttrs)
+		diffFile.IsVendored = git.IsAttributeSet(fileAttrs, analyze.AttributeVendored, false)
+		if diffFile.IsGenerated || diffFile.IsVendored {
+			diff.TotalAddition -= diffFile.Addition
+			diff.TotalDeletion -= diffFile.Deletion
+		}
+	}
+}
+
 // RawDiffType type of a raw diff.
 type RawDiffType string
 
@@ -796,6 +1155,74 @@ func GetRawDiffForFile(repoPath, startCommit, endCommit string, diffType RawDiff
 	return nil
 }
 
+// GetPullRequestDiff builds a Diff representing the changes of the pull
+// request between its merge base and its head commit.
+func GetPullRequestDiff(pr *models.PullRequest, whitespaceBehavior string) (*Diff, error) {
+	if err := pr.GetBaseRepo(); err != nil {
+		return nil, fmt.Errorf("GetBaseRepo: %v", err)
+	}
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return nil, fmt.Errorf("OpenRepository: %v", err)
+	}
+	headCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		return nil, fmt.Errorf("GetRefCommitID: %v", err)
+	}
+	return GetDiffRangeWithWhitespaceBehavior(pr.BaseRepo.RepoPath(), pr.MergeBase, headCommitID,
+		setting.Git.MaxGitDiffLines, setting.Git.MaxGitDiffLineCharacters, setting.Git.MaxGitDiffFiles,
+		whitespaceBehavior)
+}
+
+// GetPullRequestDiffPage returns the diff of a page of the files changed by
+// the pull request whose paths match the glob pathspec, and the total number
+// of matching files. Large pull requests can be processed page by page
+// without generating the diff of all files at once.
+func GetPullRequestDiffPage(pr *models.PullRequest, whitespaceBehavior, glob string, page, pageSize int) (*Diff, int, error) {
+	if err := pr.GetBaseRepo(); err != nil {
+		return nil, 0, fmt.Errorf("GetBaseRepo: %v", err)
+	}
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return nil, 0, fmt.Errorf("OpenRepository: %v", err)
+	}
+	headCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetRefCommitID: %v", err)
+	}
+	changedFiles, err := gitRepo.GetChangedFiles(pr.MergeBase, headCommitID, glob)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetChangedFiles: %v", err)
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	start := (page - 1) * pageSize
+	if start >= len(changedFiles) {
+		return &Diff{Files: []*DiffFile{}}, len(changedFiles), nil
+	}
+	end := start + pageSize
+	if end > len(changedFiles) {
+		end = len(changedFiles)
+	}
+	files := make([]string, 0, 2*(end-start))
+	for _, file := range changedFiles[start:end] {
+		files = append(files, file.Name)
+		if len(file.OldName) > 0 {
+			files = append(files, file.OldName)
+		}
+	}
+
+	diff, err := GetDiffRangeForFiles(pr.BaseRepo.RepoPath(), pr.MergeBase, headCommitID,
+		setting.Git.MaxGitDiffLines, setting.Git.MaxGitDiffLineCharacters, pageSize,
+		whitespaceBehavior, files)
+	if err != nil {
+		return nil, 0, err
+	}
+	return diff, len(changedFiles), nil
+}
+
 // GetDiffCommit builds a Diff representing the given commitID.
 func GetDiffCommit(repoPath, commitID string, maxLines, maxLineCharacters, maxFiles int) (*Diff, error) {
 	return GetDiffRange(repoPath, "", commitID, maxLines, maxLineCharacters, maxFiles)
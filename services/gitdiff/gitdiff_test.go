@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/setting"
 
 	dmp "github.com/sergi/go-diff/diffmatchpatch"
@@ -195,3 +196,105 @@ func TestDiffLine_GetCommentSide(t *testing.T) {
 	assert.Equal(t, "previous", (&DiffLine{Comments: []*models.Comment{{Line: -3}}}).GetCommentSide())
 	assert.Equal(t, "proposed", (&DiffLine{Comments: []*models.Comment{{Line: 3}}}).GetCommentSide())
 }
+
+func TestDiffSection_GetSplitLines(t *testing.T) {
+	var diff = `diff --git "a/README.md" "b/README.md"
+--- a/README.md
++++ b/README.md
+@@ -1,4 +1,5 @@
+ # gitea-github-migrator
+-Latest Release
+-Docker Pulls
++Latest release
++ Build Status
+ cut off
++added`
+	result, err := ParsePatch(setting.Git.MaxGitDiffLines, setting.Git.MaxGitDiffLineCharacters, setting.Git.MaxGitDiffFiles, strings.NewReader(diff))
+	assert.NoError(t, err)
+	lines := result.Files[0].Sections[0].GetSplitLines()
+	assert.Len(t, lines, 6)
+
+	assert.Equal(t, int(DiffLineSection), lines[0].GetType())
+	assert.False(t, lines[1].IsChanged())
+	assert.Equal(t, 1, lines[1].GetLeftIdx())
+	assert.Equal(t, 1, lines[1].GetRightIdx())
+
+	assert.True(t, lines[2].IsChanged())
+	assert.Equal(t, 2, lines[2].GetLeftIdx())
+	assert.Equal(t, 2, lines[2].GetRightIdx())
+	assertEqual(t, "Latest <span class=\"removed-code\">R</span>elease", lines[2].GetLeftContent())
+	assertEqual(t, "Latest <span class=\"added-code\">r</span>elease", lines[2].GetRightContent())
+	assert.True(t, lines[3].IsChanged())
+
+	assert.Nil(t, lines[5].Left)
+	assert.Equal(t, 0, lines[5].GetLeftIdx())
+	assertEqual(t, "", lines[5].GetLeftContent())
+	assertEqual(t, "added", lines[5].GetRightContent())
+
+	apiDiff := result.APIFormat(true)
+	section := apiDiff.Files[0].Sections[0]
+	assert.Equal(t, "@@ -1,4 +1,5 @@", section.Header)
+	assert.Len(t, section.SplitLines, 5)
+	assert.Equal(t, "del", section.SplitLines[1].Left.Type)
+	assert.Equal(t, "add", section.SplitLines[1].Right.Type)
+	assert.Len(t, section.SplitLines[1].Right.Segments, 3)
+	assert.True(t, section.SplitLines[1].Right.Segments[1].Changed)
+	assert.Equal(t, "r", section.SplitLines[1].Right.Segments[1].Text)
+	assert.Nil(t, section.SplitLines[4].Left)
+
+	apiDiff = result.APIFormat(false)
+	assert.Len(t, apiDiff.Files[0].Sections[0].Lines, 7)
+	assert.Nil(t, apiDiff.Files[0].Sections[0].SplitLines)
+}
+
+func TestDiffFile_GetDiffHash(t *testing.T) {
+	diff := setupDefaultDiff()
+	hash := diff.Files[0].GetDiffHash()
+	assert.Len(t, hash, 40)
+	assert.Equal(t, hash, setupDefaultDiff().Files[0].GetDiffHash())
+
+	diff.Files[0].Sections[0].Lines[0].Content = "+changed"
+	assert.NotEqual(t, hash, diff.Files[0].GetDiffHash())
+}
+
+func TestDiff_SetLinguistAttributes(t *testing.T) {
+	var patch = `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1,2 +1,2 @@
+ package main
+-// old
++// new
+diff --git a/bindata.go b/bindata.go
+--- a/bindata.go
++++ b/bindata.go
+@@ -1,1 +1,3 @@
+ package main
++var a = 1
++var b = 2
+diff --git a/vendor/lib.go b/vendor/lib.go
+--- a/vendor/lib.go
++++ b/vendor/lib.go
+@@ -1,2 +1,1 @@
+ package lib
+-var c = 3
+`
+	diff, err := ParsePatch(setting.Git.MaxGitDiffLines, setting.Git.MaxGitDiffLineCharacters, setting.Git.MaxGitDiffFiles, strings.NewReader(patch))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, diff.TotalAddition)
+	assert.Equal(t, 2, diff.TotalDeletion)
+
+	attrs, err := git.ParseAttributes(strings.NewReader("bindata.go linguist-generated\n"))
+	assert.NoError(t, err)
+	diff.setLinguistAttributes(attrs)
+	assert.False(t, diff.Files[0].IsGenerated)
+	assert.True(t, diff.Files[1].IsGenerated)
+	// vendored by its path only, which is not enough in diffs
+	assert.False(t, diff.Files[2].IsVendored)
+	assert.Equal(t, 1, diff.TotalAddition)
+	assert.Equal(t, 2, diff.TotalDeletion)
+
+	apiDiff := diff.APIFormat(false)
+	assert.True(t, apiDiff.Files[1].IsGenerated)
+	assert.Equal(t, 1, apiDiff.TotalAdditions)
+}
@@ -0,0 +1,103 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitdiff
+
+import (
+	"fmt"
+	"image"
+	// Register the decoders of the image formats shown in diffs
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+
+	"code.gitea.io/gitea/modules/git"
+)
+
+// ImageInfo represents an image file at a commit
+type ImageInfo struct {
+	CommitID string
+	Path     string
+	Size     int64
+	// Width and Height are zero if the format of the image can not be decoded
+	Width  int
+	Height int
+}
+
+// ImageDiff represents an image file changed between two commits
+type ImageDiff struct {
+	// Before is nil if the image has been added
+	Before *ImageInfo
+	// After is nil if the image has been deleted
+	After *ImageInfo
+}
+
+// SizeDelta returns the difference of the sizes in bytes of the image after
+// and before the change
+func (diff *ImageDiff) SizeDelta() int64 {
+	var delta int64
+	if diff.After != nil {
+		delta += diff.After.Size
+	}
+	if diff.Before != nil {
+		delta -= diff.Before.Size
+	}
+	return delta
+}
+
+// readImageInfo reads the dimensions of the image, the dimensions are left
+// empty if the image format is unknown.
+func readImageInfo(info *ImageInfo, r io.Reader) {
+	config, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return
+	}
+	info.Width = config.Width
+	info.Height = config.Height
+}
+
+func getImageInfo(gitRepo *git.Repository, commitID, path string) (*ImageInfo, error) {
+	commit, err := gitRepo.GetCommit(commitID)
+	if err != nil {
+		return nil, fmt.Errorf("GetCommit: %v", err)
+	}
+	blob, err := commit.GetBlobByPath(path)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("GetBlobByPath: %v", err)
+	}
+
+	info := &ImageInfo{
+		CommitID: commit.ID.String(),
+		Path:     path,
+		Size:     blob.Size(),
+	}
+	dataRc, err := blob.DataAsync()
+	if err != nil {
+		return nil, fmt.Errorf("DataAsync: %v", err)
+	}
+	defer dataRc.Close()
+	readImageInfo(info, dataRc)
+	return info, nil
+}
+
+// GetImageDiff returns the image at oldPath in the before commit and the image
+// at path in the after commit.
+func GetImageDiff(gitRepo *git.Repository, beforeCommitID, afterCommitID, oldPath, path string) (*ImageDiff, error) {
+	if len(oldPath) == 0 {
+		oldPath = path
+	}
+	before, err := getImageInfo(gitRepo, beforeCommitID, oldPath)
+	if err != nil {
+		return nil, err
+	}
+	after, err := getImageInfo(gitRepo, afterCommitID, path)
+	if err != nil {
+		return nil, err
+	}
+	return &ImageDiff{Before: before, After: after}, nil
+}
@@ -0,0 +1,39 @@
+// Copyright 2019 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitdiff
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadImageInfo(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 30, 20))))
+
+	info := &ImageInfo{}
+	readImageInfo(info, &buf)
+	assert.Equal(t, 30, info.Width)
+	assert.Equal(t, 20, info.Height)
+
+	info = &ImageInfo{}
+	readImageInfo(info, strings.NewReader("<svg></svg>"))
+	assert.Zero(t, info.Width)
+	assert.Zero(t, info.Height)
+}
+
+func TestImageDiff_SizeDelta(t *testing.T) {
+	diff := &ImageDiff{Before: &ImageInfo{Size: 300}, After: &ImageInfo{Size: 200}}
+	assert.EqualValues(t, -100, diff.SizeDelta())
+	diff.Before = nil
+	assert.EqualValues(t, 200, diff.SizeDelta())
+	diff = &ImageDiff{Before: &ImageInfo{Size: 300}}
+	assert.EqualValues(t, -300, diff.SizeDelta())
+}